@@ -9,6 +9,7 @@ import (
 	"github.com/dolthub/go-mysql-server/server"
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/information_schema"
+	"github.com/dolthub/go-mysql-server/sql/performance_schema"
 )
 
 // Example of how to implement a MySQL server based on a Engine:
@@ -28,6 +29,7 @@ func main() {
 	engine := sqle.NewDefault()
 	engine.AddDatabase(createTestDatabase())
 	engine.AddDatabase(information_schema.NewInformationSchemaDatabase(engine.Catalog))
+	engine.AddDatabase(performance_schema.NewPerformanceSchemaDatabase(engine.Catalog))
 
 	config := server.Config{
 		Protocol: "tcp",
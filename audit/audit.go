@@ -0,0 +1,316 @@
+// Package audit implements an audit log of the connections and queries of
+// an engine. Events are delivered to one or more sinks, such as a JSON
+// lines file or a callback, and can be filtered before that.
+package audit
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// EventType is the type of an audit event.
+type EventType int
+
+const (
+	// Connect is the event of a client connecting to the server, once it has
+	// authenticated.
+	Connect EventType = iota
+	// Disconnect is the event of a client closing its connection.
+	Disconnect
+	// Query is the event of a query finishing, either when its results have
+	// been read or when it fails.
+	Query
+)
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case Connect:
+		return "connect"
+	case Disconnect:
+		return "disconnect"
+	case Query:
+		return "query"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is an audit event.
+type Event struct {
+	Type EventType
+	// Time is when the event happened, or when the query started for query
+	// events.
+	Time time.Time
+	// ConnectionID is the ID of the connection, or session, of the event.
+	ConnectionID uint32
+	// Seq is the position of the event among the events of its connection
+	// delivered to the sinks, starting at 1.
+	Seq uint64
+	// User is the user the client authenticated as.
+	User string
+	// Address is the address of the client.
+	Address string
+	// ConnectAttrs are the connection attributes the client sent, in connect
+	// events.
+	ConnectAttrs map[string]string
+	// Query is the text of the query of query events.
+	Query string
+	// Tables are the tables used by the query of query events, in the
+	// db.table form and sorted.
+	Tables []string
+	// Duration is how long the query took to run and read its results.
+	Duration time.Duration
+	// Err is the error of the event, or nil if it succeeded.
+	Err error
+}
+
+// Filter returns whether an event must be delivered to the sinks.
+type Filter func(e Event) bool
+
+// Types returns a Filter accepting the events of the types given.
+func Types(types ...EventType) Filter {
+	return func(e Event) bool {
+		for _, t := range types {
+			if e.Type == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Users returns a Filter accepting the events of the users given.
+func Users(users ...string) Filter {
+	return func(e Event) bool {
+		for _, u := range users {
+			if e.User == u {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Failed is a Filter accepting the events with an error.
+func Failed(e Event) bool {
+	return e.Err != nil
+}
+
+// All returns a Filter accepting the events all the filters given accept.
+func All(filters ...Filter) Filter {
+	return func(e Event) bool {
+		for _, f := range filters {
+			if !f(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Sink receives the events of a Log.
+type Sink interface {
+	// Write receives an event. The events of a connection are written one at
+	// a time, in the order they happened. Errors are logged, but don't fail
+	// the queries audited.
+	Write(e Event) error
+}
+
+// Log delivers audit events to its sinks. A nil Log discards all events.
+type Log struct {
+	filter Filter
+	sinks  []Sink
+
+	mu    sync.Mutex
+	conns map[uint32]*connection
+}
+
+// connection serializes the delivery of the events of a connection.
+type connection struct {
+	mu  sync.Mutex
+	seq uint64
+}
+
+// NewLog creates a Log delivering the events the filter given accepts to the
+// sinks given. A nil filter accepts all events.
+func NewLog(filter Filter, sinks ...Sink) *Log {
+	return &Log{
+		filter: filter,
+		sinks:  sinks,
+		conns:  make(map[uint32]*connection),
+	}
+}
+
+// Emit delivers an event to the sinks, if accepted by the filter. Its
+// sequence number is set, as is its time if it's zero.
+func (l *Log) Emit(e Event) {
+	if l == nil {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	if l.filter != nil && !l.filter(e) {
+		if e.Type == Disconnect {
+			l.forget(e.ConnectionID)
+		}
+		return
+	}
+
+	l.mu.Lock()
+	c, ok := l.conns[e.ConnectionID]
+	if !ok {
+		c = new(connection)
+		l.conns[e.ConnectionID] = c
+	}
+	l.mu.Unlock()
+
+	c.mu.Lock()
+	c.seq++
+	e.Seq = c.seq
+	for _, s := range l.sinks {
+		if err := s.Write(e); err != nil {
+			logrus.WithField("system", "audit").Errorf("unable to write audit event: %s", err)
+		}
+	}
+	c.mu.Unlock()
+
+	if e.Type == Disconnect {
+		l.forget(e.ConnectionID)
+	}
+}
+
+func (l *Log) forget(id uint32) {
+	l.mu.Lock()
+	delete(l.conns, id)
+	l.mu.Unlock()
+}
+
+// Connect logs a client connecting with the session of the context given.
+func (l *Log) Connect(ctx *sql.Context, err error) {
+	e := sessionEvent(ctx, Connect, err)
+	e.ConnectAttrs = ctx.Client().ConnectAttrs
+	l.Emit(e)
+}
+
+// Disconnect logs a client closing the connection of the session of the
+// context given.
+func (l *Log) Disconnect(ctx *sql.Context) {
+	l.Emit(sessionEvent(ctx, Disconnect, nil))
+}
+
+// QueryFailed logs a query that failed before it could run, which started at
+// the time given. The query node may be nil if it couldn't be parsed.
+func (l *Log) QueryFailed(ctx *sql.Context, query string, n sql.Node, start time.Time, err error) {
+	if l == nil {
+		return
+	}
+
+	e := sessionEvent(ctx, Query, err)
+	e.Time, e.Query, e.Tables, e.Duration = start, query, tables(ctx, n), time.Since(start)
+	l.Emit(e)
+}
+
+// TrackQuery returns an iterator of the rows of a query that logs it once
+// closed, with the first error its rows returned, if any.
+func (l *Log) TrackQuery(ctx *sql.Context, query string, n sql.Node, start time.Time, iter sql.RowIter) sql.RowIter {
+	if l == nil {
+		return iter
+	}
+
+	e := sessionEvent(ctx, Query, nil)
+	e.Time, e.Query, e.Tables = start, query, tables(ctx, n)
+	return &trackedIter{log: l, event: e, iter: iter}
+}
+
+func sessionEvent(ctx *sql.Context, t EventType, err error) Event {
+	client := ctx.Client()
+	return Event{
+		Type:         t,
+		ConnectionID: ctx.ID(),
+		User:         client.User,
+		Address:      client.Address,
+		Err:          err,
+	}
+}
+
+// tables returns the tables a query node uses, including the ones of its
+// subqueries, as named in the query.
+func tables(ctx *sql.Context, n sql.Node) []string {
+	if n == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var inspect func(n sql.Node)
+	inspect = func(n sql.Node) {
+		plan.Inspect(n, func(n sql.Node) bool {
+			if e, ok := n.(sql.Expressioner); ok {
+				for _, e := range e.Expressions() {
+					sql.Inspect(e, func(e sql.Expression) bool {
+						if s, ok := e.(*plan.Subquery); ok {
+							inspect(s.Query)
+						}
+						return true
+					})
+				}
+			}
+
+			if t, ok := n.(*plan.UnresolvedTable); ok {
+				db := t.Database
+				if db == "" {
+					db = ctx.GetCurrentDatabase()
+				}
+				seen[strings.ToLower(db+"."+t.Name())] = true
+			}
+			return true
+		})
+	}
+	inspect(n)
+
+	var result = make([]string, 0, len(seen))
+	for t := range seen {
+		result = append(result, t)
+	}
+	sort.Strings(result)
+	return result
+}
+
+type trackedIter struct {
+	log   *Log
+	event Event
+	iter  sql.RowIter
+	done  bool
+}
+
+func (i *trackedIter) Next() (sql.Row, error) {
+	row, err := i.iter.Next()
+	if err != nil && err != io.EOF && i.event.Err == nil {
+		i.event.Err = err
+	}
+	return row, err
+}
+
+func (i *trackedIter) Close() error {
+	err := i.iter.Close()
+	if !i.done {
+		i.done = true
+		if i.event.Err == nil {
+			i.event.Err = err
+		}
+		i.event.Duration = time.Since(i.event.Time)
+		i.log.Emit(i.event)
+	}
+	return err
+}
@@ -0,0 +1,172 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/audit"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+)
+
+func auditEngine(log *audit.Log) *sqle.Engine {
+	db := memory.NewDatabase("mydb")
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+
+	for _, name := range []string{"a", "b"} {
+		db.AddTable(name, memory.NewTable(name, sql.Schema{
+			{Name: "i", Type: sql.Int64, Source: name},
+		}))
+	}
+
+	a := analyzer.NewBuilder(catalog).Build()
+	return sqle.New(catalog, a, &sqle.Config{Audit: log})
+}
+
+func newContext(id uint32) *sql.Context {
+	session := sql.NewSessionWithClient("localhost:3306", sql.Client{User: "root", Address: "127.0.0.1:1234"}, id)
+	ctx := sql.NewContext(context.Background(), sql.WithSession(session))
+	ctx.SetCurrentDatabase("mydb")
+	return ctx
+}
+
+func query(e *sqle.Engine, ctx *sql.Context, q string) error {
+	_, iter, err := e.Query(ctx, q)
+	if err != nil {
+		return err
+	}
+	_, err = sql.RowIterToRows(iter)
+	return err
+}
+
+func TestLogQueries(t *testing.T) {
+	require := require.New(t)
+
+	var events []audit.Event
+	log := audit.NewLog(nil, audit.SinkFunc(func(e audit.Event) error {
+		events = append(events, e)
+		return nil
+	}))
+	e := auditEngine(log)
+	ctx := newContext(1)
+
+	log.Connect(ctx, nil)
+	require.NoError(query(e, ctx, "INSERT INTO a VALUES (1)"))
+	require.NoError(query(e, ctx, "SELECT * FROM A WHERE i IN (SELECT i FROM mydb.b)"))
+	require.Error(query(e, ctx, "SELECT * FROM nope"))
+	require.Error(query(e, ctx, "SELEC 1"))
+	log.Disconnect(ctx)
+
+	require.Len(events, 6)
+	for i, ev := range events {
+		require.Equal(uint64(i+1), ev.Seq)
+		require.Equal(uint32(1), ev.ConnectionID)
+		require.Equal("root", ev.User)
+		require.Equal("127.0.0.1:1234", ev.Address)
+	}
+
+	require.Equal(audit.Connect, events[0].Type)
+	require.Equal(audit.Disconnect, events[5].Type)
+
+	require.Equal(audit.Query, events[1].Type)
+	require.Equal("INSERT INTO a VALUES (1)", events[1].Query)
+	require.Equal([]string{"mydb.a"}, events[1].Tables)
+	require.NoError(events[1].Err)
+
+	require.Equal([]string{"mydb.a", "mydb.b"}, events[2].Tables)
+	require.NoError(events[2].Err)
+
+	require.Equal([]string{"mydb.nope"}, events[3].Tables)
+	require.True(sql.ErrTableNotFound.Is(events[3].Err))
+
+	require.Nil(events[4].Tables)
+	require.Error(events[4].Err)
+}
+
+func TestLogFilter(t *testing.T) {
+	require := require.New(t)
+
+	var events []audit.Event
+	log := audit.NewLog(
+		audit.All(audit.Types(audit.Query), audit.Failed),
+		audit.SinkFunc(func(e audit.Event) error {
+			events = append(events, e)
+			return nil
+		}),
+	)
+	e := auditEngine(log)
+	ctx := newContext(1)
+
+	log.Connect(ctx, nil)
+	require.NoError(query(e, ctx, "SELECT * FROM a"))
+	require.Error(query(e, ctx, "SELECT * FROM nope"))
+	log.Disconnect(ctx)
+
+	require.Len(events, 1)
+	require.Equal("SELECT * FROM nope", events[0].Query)
+	require.Equal(uint64(1), events[0].Seq)
+
+	events = nil
+	log = audit.NewLog(audit.Users("other"), audit.SinkFunc(func(e audit.Event) error {
+		events = append(events, e)
+		return nil
+	}))
+	log.Connect(ctx, nil)
+	require.Len(events, 0)
+}
+
+func TestNilLog(t *testing.T) {
+	require := require.New(t)
+	e := auditEngine(nil)
+	require.NoError(query(e, newContext(1), "SELECT * FROM a"))
+}
+
+func TestJSONSink(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	sink := audit.NewJSONSink(&buf)
+	log := audit.NewLog(nil, sink)
+	ctx := newContext(7)
+	attrsCtx := sql.NewContext(context.Background(), sql.WithSession(sql.NewSessionWithClient("localhost:3306", sql.Client{
+		User:         "root",
+		Address:      "127.0.0.1:1234",
+		ConnectAttrs: map[string]string{"program_name": "app"},
+	}, 8)))
+
+	log.Connect(ctx, nil)
+	log.Connect(ctx, errors.New("access denied"))
+	log.Connect(attrsCtx, nil)
+	require.NoError(sink.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(lines, 3)
+
+	var first, second, third map[string]interface{}
+	require.NoError(json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(json.Unmarshal([]byte(lines[1]), &second))
+	require.NoError(json.Unmarshal([]byte(lines[2]), &third))
+
+	require.Equal("connect", first["type"])
+	require.Equal(float64(7), first["connection_id"])
+	require.Equal(float64(1), first["seq"])
+	require.Equal("root", first["user"])
+	require.Equal(true, first["success"])
+	require.NotContains(first, "error")
+	require.NotContains(first, "connect_attrs")
+
+	require.Equal(float64(2), second["seq"])
+	require.Equal(false, second["success"])
+	require.Equal("access denied", second["error"])
+
+	require.Equal(map[string]interface{}{"program_name": "app"}, third["connect_attrs"])
+}
@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SinkFunc is a callback implementing Sink.
+type SinkFunc func(e Event) error
+
+// Write implements the Sink interface.
+func (f SinkFunc) Write(e Event) error {
+	return f(e)
+}
+
+// jsonEvent is the JSON representation of an Event.
+type jsonEvent struct {
+	Type         string            `json:"type"`
+	Time         string            `json:"time"`
+	ConnectionID uint32            `json:"connection_id"`
+	Seq          uint64            `json:"seq"`
+	User         string            `json:"user"`
+	Address      string            `json:"address"`
+	ConnectAttrs map[string]string `json:"connect_attrs,omitempty"`
+	Query        string            `json:"query,omitempty"`
+	Tables       []string          `json:"tables,omitempty"`
+	DurationMs   float64           `json:"duration_ms,omitempty"`
+	Success      bool              `json:"success"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// JSONSink writes events to a writer as JSON lines, one object per event.
+type JSONSink struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+var _ Sink = (*JSONSink)(nil)
+
+// NewJSONSink creates a JSONSink writing to the writer given.
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{w: w, enc: json.NewEncoder(w)}
+}
+
+// NewJSONFileSink creates a JSONSink appending to the file with the path
+// given, which is created if it doesn't exist.
+func NewJSONFileSink(path string) (*JSONSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONSink(f), nil
+}
+
+// Write implements the Sink interface.
+func (s *JSONSink) Write(e Event) error {
+	je := jsonEvent{
+		Type:         e.Type.String(),
+		Time:         e.Time.UTC().Format(time.RFC3339Nano),
+		ConnectionID: e.ConnectionID,
+		Seq:          e.Seq,
+		User:         e.User,
+		Address:      e.Address,
+		ConnectAttrs: e.ConnectAttrs,
+		Query:        e.Query,
+		Tables:       e.Tables,
+		DurationMs:   float64(e.Duration) / float64(time.Millisecond),
+		Success:      e.Err == nil,
+	}
+	if e.Err != nil {
+		je.Error = e.Err.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(je)
+}
+
+// Close closes the writer of the sink, if it's an io.Closer.
+func (s *JSONSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"crypto/x509"
 	"strings"
 
 	"github.com/dolthub/vitess/go/mysql"
@@ -61,3 +62,34 @@ type Auth interface {
 	// Otherwise is an error using the authentication method.
 	Allowed(ctx *sql.Context, permission Permission) error
 }
+
+// Connection describes an authenticated client connection.
+type Connection struct {
+	// User the client authenticated as.
+	User string
+	// Address of the client.
+	Address string
+	// Secure is true if the client connected using TLS.
+	Secure bool
+	// PeerCertificates are the certificates presented by the client, if any.
+	PeerCertificates []*x509.Certificate
+}
+
+// ConnectionChecker is implemented by Auth methods with requirements on the
+// connection of a user besides its credentials, e.g. REQUIRE SSL. The server
+// calls CheckConnection once a user has authenticated and rejects the
+// connection if it returns an error.
+type ConnectionChecker interface {
+	CheckConnection(conn Connection) error
+}
+
+// ConnectionLimiter is implemented by Auth methods limiting the number of
+// simultaneous connections of their accounts. The server counts the
+// connections of each account, and rejects the ones exceeding the limit of
+// their account, or the max_user_connections system variable if it has none.
+type ConnectionLimiter interface {
+	// ConnectionLimit returns the account an authenticated connection logged
+	// in as, and its maximum number of simultaneous connections, which is
+	// zero if the account has no limit of its own.
+	ConnectionLimit(conn Connection) (sql.Account, int, error)
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// ErrCommandFailed is returned when the command of a CommandDirectory can't
+// be run or fails.
+var ErrCommandFailed = errors.NewKind("authentication command failed: %s")
+
+// CommandConfig configures an authentication method delegating to an
+// external command.
+type CommandConfig struct {
+	// Path of the command.
+	Path string
+	// Args are the arguments given to the command.
+	Args []string
+	// Timeout of each run of the command. Defaults to 10 seconds.
+	Timeout time.Duration
+	// CacheTTL is the time successful authentications are remembered. If
+	// zero, the command is run each time a user connects.
+	CacheTTL time.Duration
+	// GroupPermissions maps the groups written by the command to the
+	// permissions granted to their members. Names are case insensitive.
+	GroupPermissions map[string]Permission
+	// DefaultPermissions are granted to every user accepted by the command.
+	DefaultPermissions Permission
+}
+
+// CommandDirectory is a Directory delegating to an external command, e.g. a
+// helper checking the credentials with PAM, so that deployments can reuse the
+// accounts of the system.
+//
+// The command is run with the user name in the MYSQL_USER environment
+// variable and the password, followed by a newline, in its standard input.
+// It must exit with status 0 if the credentials are valid, writing the groups
+// of the user to its standard output one per line, and with status 1 if they
+// are not. Any other status is taken as a failure to check them.
+type CommandDirectory struct {
+	path    string
+	args    []string
+	timeout time.Duration
+}
+
+var _ Directory = (*CommandDirectory)(nil)
+
+// NewCommandDirectory creates a CommandDirectory running the command
+// configured.
+func NewCommandDirectory(cfg CommandConfig) *CommandDirectory {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &CommandDirectory{cfg.Path, cfg.Args, timeout}
+}
+
+// NewCommand creates an authentication method checking users with the
+// command configured.
+func NewCommand(cfg CommandConfig) *DirectoryAuth {
+	var dir Directory = NewCommandDirectory(cfg)
+	if cfg.CacheTTL > 0 {
+		dir = NewCachedDirectory(dir, cfg.CacheTTL)
+	}
+
+	return NewDirectoryAuth(dir, cfg.GroupPermissions, cfg.DefaultPermissions)
+}
+
+// Authenticate implements the Directory interface.
+func (d *CommandDirectory) Authenticate(user, password string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, d.path, d.args...)
+	cmd.Env = append(os.Environ(), "MYSQL_USER="+user)
+	cmd.Stdin = strings.NewReader(password + "\n")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return nil, ErrInvalidCredentials.New(user)
+	} else if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, ErrCommandFailed.New(msg)
+		}
+		return nil, ErrCommandFailed.New(err)
+	}
+
+	var groups []string
+	s := bufio.NewScanner(&stdout)
+	for s.Scan() {
+		if g := strings.TrimSpace(s.Text()); g != "" {
+			groups = append(groups, g)
+		}
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,79 @@
+package auth_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/auth"
+)
+
+// script accepts alice with password wonderland, and bob with password
+// builder, who belongs to the dba group.
+const script = `#!/bin/sh
+read password
+case "$MYSQL_USER:$password" in
+	alice:wonderland) exit 0 ;;
+	bob:builder) echo staff; echo dba; exit 0 ;;
+	broken:*) echo "no such service" >&2; exit 2 ;;
+	*) exit 1 ;;
+esac
+`
+
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command is a shell script")
+	}
+
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "auth")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "check")
+	require.NoError(ioutil.WriteFile(path, []byte(script), 0700))
+
+	d := auth.NewCommandDirectory(auth.CommandConfig{Path: path})
+
+	groups, err := d.Authenticate("bob", "builder")
+	require.NoError(err)
+	require.Equal([]string{"staff", "dba"}, groups)
+
+	groups, err = d.Authenticate("alice", "wonderland")
+	require.NoError(err)
+	require.Empty(groups)
+
+	_, err = d.Authenticate("alice", "builder")
+	require.True(auth.ErrInvalidCredentials.Is(err))
+
+	_, err = d.Authenticate("broken", "broken")
+	require.True(auth.ErrCommandFailed.Is(err))
+	require.Contains(err.Error(), "no such service")
+
+	_, err = auth.NewCommandDirectory(auth.CommandConfig{Path: filepath.Join(dir, "missing")}).Authenticate("alice", "wonderland")
+	require.True(auth.ErrCommandFailed.Is(err))
+
+	a := auth.NewCommand(auth.CommandConfig{
+		Path:               path,
+		GroupPermissions:   map[string]auth.Permission{"DBA": auth.AllPermissions},
+		DefaultPermissions: auth.ReadPerm,
+	})
+
+	testAuthentication(t, a, []authenticationTest{
+		{"alice", "wonderland", true},
+		{"alice", "wrong", false},
+		{"bob", "builder", true},
+		{"broken", "broken", false},
+	}, nil)
+
+	testAuthorization(t, a, []authorizationTest{
+		{"alice", queries["select"], true},
+		{"alice", queries["insert"], false},
+		{"bob", queries["insert"], true},
+	}, nil)
+}
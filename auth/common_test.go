@@ -50,6 +50,8 @@ func authServer(a auth.Auth) (*server.Server, *sql.IndexRegistry, error) {
 		Address:        fmt.Sprintf("localhost:%d", port),
 		Auth:           a,
 		MaxConnections: 1000,
+
+		AllowClearTextWithoutTLS: true,
 	}
 
 	s, err := server.NewDefaultServer(config, engine)
@@ -63,7 +65,7 @@ func authServer(a auth.Auth) (*server.Server, *sql.IndexRegistry, error) {
 }
 
 func connString(user, password string) string {
-	return fmt.Sprintf("%s:%s@tcp(127.0.0.1:%d)/test", user, password, port)
+	return fmt.Sprintf("%s:%s@tcp(127.0.0.1:%d)/test?allowCleartextPasswords=true", user, password, port)
 }
 
 type authenticationTest struct {
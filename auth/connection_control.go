@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+)
+
+// erAccountBlocked is the MySQL error code returned to clients logging in to
+// an account locked after too many failed attempts.
+const erAccountBlocked = 3955
+
+// ConnectionControl configures how a NativeStore slows down and blocks the
+// clients that repeatedly fail to authenticate, to thwart brute force
+// attacks. Failed attempts are counted per user name and client host, and a
+// successful login resets the count.
+type ConnectionControl struct {
+	// FailedConnectionsThreshold is the number of consecutive failed attempts
+	// after which each new attempt is delayed. Zero disables delays.
+	FailedConnectionsThreshold int
+	// MinDelay is the minimum delay of an attempt. Delays start at a second
+	// and grow by a second with each failed attempt over the threshold.
+	MinDelay time.Duration
+	// MaxDelay is the maximum delay of an attempt. Zero means no maximum.
+	MaxDelay time.Duration
+	// FailedLoginAttempts is the number of consecutive failed attempts after
+	// which the account is locked. Zero disables locking.
+	FailedLoginAttempts int
+	// LockTime is how long accounts stay locked. Attempts are rejected while
+	// the account is locked, even with the right credentials.
+	LockTime time.Duration
+}
+
+// delay returns how long to delay an attempt after the number of consecutive
+// failed attempts given.
+func (c ConnectionControl) delay(failures int) time.Duration {
+	if c.FailedConnectionsThreshold <= 0 || failures < c.FailedConnectionsThreshold {
+		return 0
+	}
+
+	d := time.Duration(failures-c.FailedConnectionsThreshold+1) * time.Second
+	if d < c.MinDelay {
+		d = c.MinDelay
+	}
+	if c.MaxDelay > 0 && d > c.MaxDelay {
+		d = c.MaxDelay
+	}
+	return d
+}
+
+// loginAttempts holds the consecutive failed attempts of a user name from a
+// host.
+type loginAttempts struct {
+	failures    int
+	lockedUntil time.Time
+}
+
+// connectionControl keeps track of the failed attempts to authenticate with
+// a NativeStore.
+type connectionControl struct {
+	mu       sync.Mutex
+	config   ConnectionControl
+	attempts map[string]*loginAttempts
+	// Counters exposed as status variables.
+	delays, locked, failed uint64
+}
+
+func newConnectionControl() *connectionControl {
+	return &connectionControl{attempts: make(map[string]*loginAttempts)}
+}
+
+func (c *connectionControl) setConfig(config ConnectionControl) {
+	c.mu.Lock()
+	c.config = config
+	c.mu.Unlock()
+}
+
+func attemptsKey(user, host string) string {
+	return user + "@" + host
+}
+
+// before is called before checking the credentials of a client. It returns
+// an error if the account is locked, and otherwise waits for the delay of
+// the attempt, if any.
+func (c *connectionControl) before(user, host string) error {
+	c.mu.Lock()
+	a, ok := c.attempts[attemptsKey(user, host)]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+
+	if !a.lockedUntil.IsZero() {
+		if remaining := time.Until(a.lockedUntil); remaining > 0 {
+			c.locked++
+			lockTime, failures := c.config.LockTime, a.failures
+			c.mu.Unlock()
+			return mysql.NewSQLError(erAccountBlocked, mysql.SSUnknownSQLState,
+				"Access denied for user '%v'. Account is blocked for %v (%v remaining) due to %d consecutive failed logins.",
+				user, lockTime, remaining.Round(time.Second), failures)
+		}
+
+		// Failed attempts are counted again once the lock expires.
+		a.failures, a.lockedUntil = 0, time.Time{}
+	}
+
+	d := c.config.delay(a.failures)
+	if d > 0 {
+		c.delays++
+	}
+	c.mu.Unlock()
+
+	time.Sleep(d)
+	return nil
+}
+
+// done is called with the result of checking the credentials of a client.
+func (c *connectionControl) done(user, host string, success bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := attemptsKey(user, host)
+	if success {
+		delete(c.attempts, key)
+		return
+	}
+
+	c.failed++
+	if c.config.FailedConnectionsThreshold <= 0 && c.config.FailedLoginAttempts <= 0 {
+		return
+	}
+
+	a, ok := c.attempts[key]
+	if !ok {
+		a = new(loginAttempts)
+		c.attempts[key] = a
+	}
+
+	a.failures++
+	if c.config.FailedLoginAttempts > 0 && a.failures >= c.config.FailedLoginAttempts {
+		a.lockedUntil = time.Now().Add(c.config.LockTime)
+	}
+}
+
+// status returns the counters of the connection control by status variable.
+func (c *connectionControl) status() map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return map[string]interface{}{
+		"Aborted_connects":                   c.failed,
+		"Connection_control_delay_generated": c.delays,
+		"Locked_connects":                    c.locked,
+	}
+}
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ErrInvalidCredentials is returned by a Directory when the password of a
+// user is not valid.
+var ErrInvalidCredentials = errors.NewKind("invalid credentials for %s")
+
+// Directory authenticates users against an external source of accounts, such
+// as an LDAP server or the accounts of the system.
+type Directory interface {
+	// Authenticate checks the password of a user and returns the names of the
+	// groups it belongs to. It returns ErrInvalidCredentials if the password
+	// is not valid, and any other error if it can't be checked.
+	Authenticate(user, password string) ([]string, error)
+}
+
+// DirectoryAuth authenticates users against a Directory, and grants them
+// permissions depending on the groups they belong to.
+//
+// The directory needs the password of users, so clients must use the
+// mysql_clear_password authentication method. The password is sent in clear
+// text, so the server must be configured with TLS or explicitly allow clear
+// text passwords without it.
+type DirectoryAuth struct {
+	dir         Directory
+	groups      map[string]Permission
+	permissions Permission
+
+	mu    sync.RWMutex
+	users map[string]Permission
+}
+
+var _ Auth = (*DirectoryAuth)(nil)
+
+// NewDirectoryAuth creates an authentication method checking users against
+// the directory given. Members of the groups given are granted their
+// permissions on top of the default ones. Groups are matched case
+// insensitively by name or, if they are distinguished names, by the value of
+// their first RDN, e.g. dba for cn=dba,ou=groups,dc=example,dc=com.
+func NewDirectoryAuth(dir Directory, groups map[string]Permission, permissions Permission) *DirectoryAuth {
+	var m = make(map[string]Permission, len(groups))
+	for g, p := range groups {
+		m[strings.ToLower(g)] |= p
+	}
+
+	return &DirectoryAuth{
+		dir:         dir,
+		groups:      m,
+		permissions: permissions,
+		users:       make(map[string]Permission),
+	}
+}
+
+// Mysql implements Auth interface.
+func (a *DirectoryAuth) Mysql() mysql.AuthServer {
+	return &directoryAuthServer{a}
+}
+
+// Allowed implements Auth interface. Users are granted the permissions of
+// the groups they belonged to the last time they logged in.
+func (a *DirectoryAuth) Allowed(ctx *sql.Context, permission Permission) error {
+	a.mu.RLock()
+	p, ok := a.users[ctx.Client().User]
+	a.mu.RUnlock()
+
+	if !ok {
+		return ErrNotAuthorized.Wrap(ErrNoPermission.New(permission))
+	}
+
+	return nativeUser{Permissions: p}.Allowed(permission)
+}
+
+// authenticate checks the credentials of a user and stores its permissions.
+func (a *DirectoryAuth) authenticate(user, password string) error {
+	groups, err := a.dir.Authenticate(user, password)
+	if err != nil {
+		return err
+	}
+
+	p := a.permissions
+	for _, g := range groups {
+		p |= a.groups[strings.ToLower(g)] | a.groups[strings.ToLower(firstRDNValue(g))]
+	}
+
+	a.mu.Lock()
+	a.users[user] = p
+	a.mu.Unlock()
+
+	return nil
+}
+
+// firstRDNValue returns the value of the first RDN of a DN, e.g. dba for
+// cn=dba,ou=groups,dc=example,dc=com.
+func firstRDNValue(dn string) string {
+	var escaped bool
+	for i, r := range dn {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',' || r == '+':
+			dn = dn[:i]
+			return strings.TrimSpace(dn[strings.IndexRune(dn, '=')+1:])
+		}
+	}
+
+	return strings.TrimSpace(dn[strings.IndexRune(dn, '=')+1:])
+}
+
+// directoryAuthServer asks clients for their password in clear text and
+// checks it against the directory of a DirectoryAuth.
+type directoryAuthServer struct {
+	a *DirectoryAuth
+}
+
+var _ mysql.AuthServer = (*directoryAuthServer)(nil)
+
+// AuthMethod implements the mysql.AuthServer interface.
+func (s *directoryAuthServer) AuthMethod(string) (string, error) {
+	return mysql.MysqlClearPassword, nil
+}
+
+// Salt implements the mysql.AuthServer interface.
+func (s *directoryAuthServer) Salt() ([]byte, error) {
+	return mysql.NewSalt()
+}
+
+// ValidateHash implements the mysql.AuthServer interface. It is never called,
+// since mysql_clear_password is always used.
+func (s *directoryAuthServer) ValidateHash(_ []byte, user string, _ []byte, _ net.Addr) (mysql.Getter, error) {
+	return nil, mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", user)
+}
+
+// Negotiate implements the mysql.AuthServer interface.
+func (s *directoryAuthServer) Negotiate(c *mysql.Conn, user string, _ net.Addr) (mysql.Getter, error) {
+	password, err := mysql.AuthServerNegotiateClearOrDialog(c, mysql.MysqlClearPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.a.authenticate(user, password); err != nil {
+		if !ErrInvalidCredentials.Is(err) {
+			logrus.WithField("user", user).WithError(err).Error("unable to authenticate user against the directory")
+		}
+		return nil, mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", user)
+	}
+
+	return nativeStoreUserData{user}, nil
+}
+
+// CachedDirectory remembers the successful authentications of a Directory
+// for some time, so that the directory is not checked again each time a user
+// connects. Failed authentications are never cached.
+type CachedDirectory struct {
+	dir Directory
+	ttl time.Duration
+	key []byte
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	hash    [sha256.Size]byte
+	groups  []string
+	expires time.Time
+}
+
+var _ Directory = (*CachedDirectory)(nil)
+
+// NewCachedDirectory creates a CachedDirectory remembering successful
+// authentications of the directory given for the TTL given. Passwords are
+// not kept: only a salted hash of them is.
+func NewCachedDirectory(dir Directory, ttl time.Duration) *CachedDirectory {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+
+	return &CachedDirectory{
+		dir:     dir,
+		ttl:     ttl,
+		key:     key,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Authenticate implements the Directory interface.
+func (d *CachedDirectory) Authenticate(user, password string) ([]string, error) {
+	hash := sha256.Sum256(append(append([]byte{}, d.key...), password...))
+
+	d.mu.Lock()
+	e, ok := d.entries[user]
+	if ok && time.Now().After(e.expires) {
+		delete(d.entries, user)
+		ok = false
+	}
+	d.mu.Unlock()
+
+	if ok && subtle.ConstantTimeCompare(e.hash[:], hash[:]) == 1 {
+		return e.groups, nil
+	}
+
+	groups, err := d.dir.Authenticate(user, password)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	d.entries[user] = cacheEntry{hash, groups, time.Now().Add(d.ttl)}
+	d.mu.Unlock()
+
+	return groups, nil
+}
+
+// Forget removes the cached authentication of a user, if any, e.g. after its
+// password has changed.
+func (d *CachedDirectory) Forget(user string) {
+	d.mu.Lock()
+	delete(d.entries, user)
+	d.mu.Unlock()
+}
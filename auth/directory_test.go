@@ -0,0 +1,107 @@
+package auth_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/auth"
+)
+
+// fakeDirectory is a Directory with users and the groups they belong to.
+type fakeDirectory map[string]struct {
+	password string
+	groups   []string
+}
+
+func (d fakeDirectory) Authenticate(user, password string) ([]string, error) {
+	u, ok := d[user]
+	if !ok || u.password != password {
+		return nil, auth.ErrInvalidCredentials.New(user)
+	}
+	return u.groups, nil
+}
+
+func TestDirectoryAuth(t *testing.T) {
+	dir := fakeDirectory{
+		"admin":  {"admin", []string{"CN=DBA,OU=Groups,DC=example,DC=com"}},
+		"writer": {"writer", []string{"cn=writers,ou=groups,dc=example,dc=com"}},
+		"reader": {"reader", []string{"cn=other,ou=groups,dc=example,dc=com"}},
+	}
+
+	a := auth.NewDirectoryAuth(dir, map[string]auth.Permission{
+		"dba":                                    auth.AllPermissions,
+		"cn=writers,ou=groups,dc=example,dc=com": auth.WritePerm,
+	}, auth.ReadPerm)
+
+	testAuthentication(t, a, []authenticationTest{
+		{"admin", "admin", true},
+		{"admin", "wrong", false},
+		{"writer", "writer", true},
+		{"reader", "reader", true},
+		{"reader", "", false},
+		{"unknown", "unknown", false},
+	}, nil)
+
+	testAuthorization(t, a, []authorizationTest{
+		{"admin", queries["select"], true},
+		{"admin", queries["insert"], true},
+		{"writer", queries["select"], true},
+		{"writer", queries["insert"], true},
+		{"reader", queries["select"], true},
+		{"reader", queries["insert"], false},
+		{"unknown", queries["select"], false},
+	}, nil)
+}
+
+// countingDirectory counts the authentications of a Directory.
+type countingDirectory struct {
+	auth.Directory
+
+	mu    sync.Mutex
+	count int
+}
+
+func (d *countingDirectory) Authenticate(user, password string) ([]string, error) {
+	d.mu.Lock()
+	d.count++
+	d.mu.Unlock()
+	return d.Directory.Authenticate(user, password)
+}
+
+func TestCachedDirectory(t *testing.T) {
+	require := require.New(t)
+
+	dir := &countingDirectory{Directory: fakeDirectory{
+		"admin": {"admin", []string{"dba"}},
+	}}
+	cached := auth.NewCachedDirectory(dir, 100*time.Millisecond)
+
+	groups, err := cached.Authenticate("admin", "admin")
+	require.NoError(err)
+	require.Equal([]string{"dba"}, groups)
+
+	groups, err = cached.Authenticate("admin", "admin")
+	require.NoError(err)
+	require.Equal([]string{"dba"}, groups)
+	require.Equal(1, dir.count)
+
+	// Wrong passwords are checked against the directory and not cached.
+	_, err = cached.Authenticate("admin", "wrong")
+	require.True(auth.ErrInvalidCredentials.Is(err))
+	_, err = cached.Authenticate("admin", "wrong")
+	require.True(auth.ErrInvalidCredentials.Is(err))
+	require.Equal(3, dir.count)
+
+	cached.Forget("admin")
+	_, err = cached.Authenticate("admin", "admin")
+	require.NoError(err)
+	require.Equal(4, dir.count)
+
+	time.Sleep(150 * time.Millisecond)
+	_, err = cached.Authenticate("admin", "admin")
+	require.NoError(err)
+	require.Equal(5, dir.count)
+}
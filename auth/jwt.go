@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers SHA256 for crypto.SHA256.New
+	_ "crypto/sha512" // registers SHA384 and SHA512
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// ErrInvalidToken is the cause of the ErrInvalidCredentials returned for
+// tokens that can't be accepted.
+var ErrInvalidToken = errors.NewKind("invalid token: %s")
+
+// JWTConfig configures an authentication method taking signed JSON Web
+// Tokens as passwords.
+type JWTConfig struct {
+	// Keys verifying the signature of tokens, by key ID. Tokens without a
+	// key ID are verified with the key with an empty ID. Keys are []byte for
+	// HS256, HS384 and HS512, *rsa.PublicKey for RS256, RS384 and RS512, and
+	// *ecdsa.PublicKey for ES256, ES384 and ES512.
+	Keys map[string]interface{}
+	// Issuer, if not empty, must be the iss claim of tokens.
+	Issuer string
+	// Audience, if not empty, must be in the aud claim of tokens.
+	Audience string
+	// UserClaim is the claim holding the user name, which must match the
+	// user connecting. Defaults to sub.
+	UserClaim string
+	// GroupsClaim is the claim holding the groups of the user, either a
+	// string or a list of strings. Defaults to groups.
+	GroupsClaim string
+	// Leeway is the clock skew tolerated when checking the exp, nbf and iat
+	// claims.
+	Leeway time.Duration
+	// GroupPermissions maps groups to the permissions granted to their
+	// members. Names are case insensitive.
+	GroupPermissions map[string]Permission
+	// DefaultPermissions are granted to every user with a valid token.
+	DefaultPermissions Permission
+}
+
+// JWTDirectory is a Directory checking users by the token they give as their
+// password, for service to service access with tokens issued by an identity
+// provider, e.g. Kubernetes service account tokens. Tokens must be signed
+// with one of the keys configured and have an exp claim.
+type JWTDirectory struct {
+	cfg JWTConfig
+}
+
+var _ Directory = (*JWTDirectory)(nil)
+
+// NewJWTDirectory creates a JWTDirectory with the configuration given.
+func NewJWTDirectory(cfg JWTConfig) (*JWTDirectory, error) {
+	for kid, key := range cfg.Keys {
+		switch key.(type) {
+		case []byte, *rsa.PublicKey, *ecdsa.PublicKey:
+		default:
+			return nil, ErrInvalidToken.New(fmt.Sprintf("unsupported key type %T for key %q", key, kid))
+		}
+	}
+
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "sub"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+
+	return &JWTDirectory{cfg}, nil
+}
+
+// NewJWT creates an authentication method checking users by the token they
+// give as their password.
+func NewJWT(cfg JWTConfig) (*DirectoryAuth, error) {
+	dir, err := NewJWTDirectory(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDirectoryAuth(dir, cfg.GroupPermissions, cfg.DefaultPermissions), nil
+}
+
+// Authenticate implements the Directory interface.
+func (d *JWTDirectory) Authenticate(user, token string) ([]string, error) {
+	claims, err := d.verify(token)
+	if err != nil {
+		return nil, ErrInvalidCredentials.Wrap(err, user)
+	}
+
+	if name, _ := claims[d.cfg.UserClaim].(string); name != user {
+		return nil, ErrInvalidCredentials.Wrap(ErrInvalidToken.New("issued for another user"), user)
+	}
+
+	if err := d.checkClaims(claims); err != nil {
+		return nil, ErrInvalidCredentials.Wrap(err, user)
+	}
+
+	switch groups := claims[d.cfg.GroupsClaim].(type) {
+	case string:
+		return []string{groups}, nil
+	case []interface{}:
+		var result []string
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result, nil
+	default:
+		return nil, nil
+	}
+}
+
+// verify checks the signature of a token and returns its claims.
+func (d *JWTDirectory) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken.New("malformed signature")
+	}
+
+	key, ok := d.cfg.Keys[header.Kid]
+	if !ok {
+		return nil, ErrInvalidToken.New(fmt.Sprintf("unknown key %q", header.Kid))
+	}
+
+	if !verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), sig) {
+		return nil, ErrInvalidToken.New("invalid signature")
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// checkClaims checks the registered claims of a token.
+func (d *JWTDirectory) checkClaims(claims map[string]interface{}) error {
+	now := time.Now()
+
+	exp, ok := claims["exp"].(json.Number)
+	if !ok {
+		return ErrInvalidToken.New("missing exp claim")
+	}
+	if t, err := exp.Float64(); err != nil || now.Add(-d.cfg.Leeway).After(unixTime(t)) {
+		return ErrInvalidToken.New("token expired")
+	}
+
+	for _, c := range []string{"nbf", "iat"} {
+		if n, ok := claims[c].(json.Number); ok {
+			if t, err := n.Float64(); err != nil || now.Add(d.cfg.Leeway).Before(unixTime(t)) {
+				return ErrInvalidToken.New("token not valid yet")
+			}
+		}
+	}
+
+	if d.cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != d.cfg.Issuer {
+			return ErrInvalidToken.New("unexpected issuer")
+		}
+	}
+
+	if d.cfg.Audience != "" && !hasAudience(claims["aud"], d.cfg.Audience) {
+		return ErrInvalidToken.New("unexpected audience")
+	}
+
+	return nil
+}
+
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrInvalidToken.New("malformed token")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return ErrInvalidToken.New("malformed token")
+	}
+
+	return nil
+}
+
+// verifySignature returns whether sig is a valid signature of the data given
+// with the algorithm and key given. The algorithm must match the type of the
+// key, so that e.g. public RSA keys can't be used as HMAC secrets.
+func verifySignature(alg string, key interface{}, data, sig []byte) bool {
+	if len(alg) != 5 {
+		return false
+	}
+
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return false
+	}
+
+	switch key := key.(type) {
+	case []byte:
+		if alg[:2] != "HS" {
+			return false
+		}
+		mac := hmac.New(hash.New, key)
+		mac.Write(data)
+		return hmac.Equal(mac.Sum(nil), sig)
+	case *rsa.PublicKey:
+		if alg[:2] != "RS" {
+			return false
+		}
+		h := hash.New()
+		h.Write(data)
+		return rsa.VerifyPKCS1v15(key, hash, h.Sum(nil), sig) == nil
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if alg[:2] != "ES" || len(sig) != 2*size {
+			return false
+		}
+		h := hash.New()
+		h.Write(data)
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(key, h.Sum(nil), r, s)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,165 @@
+package auth_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/auth"
+)
+
+// signToken returns a token with the header and claims given, signed with the
+// algorithm of the header and the key given.
+func signToken(t *testing.T, header, claims map[string]interface{}, key interface{}) string {
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	data := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(data))
+
+	var sig []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(data))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		require.NoError(t, err)
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+
+	return data + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTDirectory(t *testing.T) {
+	require := require.New(t)
+
+	secret := []byte("secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+
+	d, err := auth.NewJWTDirectory(auth.JWTConfig{
+		Keys: map[string]interface{}{
+			"":    secret,
+			"rsa": &rsaKey.PublicKey,
+			"ec":  &ecKey.PublicKey,
+		},
+		Issuer:   "https://kubernetes.default.svc",
+		Audience: "mysql",
+		Leeway:   time.Minute,
+	})
+	require.NoError(err)
+
+	now := time.Now().Unix()
+	claims := func(changes map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"sub":    "svc",
+			"iss":    "https://kubernetes.default.svc",
+			"aud":    []string{"mysql", "other"},
+			"exp":    now + 3600,
+			"iat":    now,
+			"groups": []string{"readers", "writers"},
+		}
+		for k, v := range changes {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+
+	hs256 := map[string]interface{}{"alg": "HS256", "typ": "JWT"}
+	testCases := []struct {
+		name   string
+		token  string
+		groups []string
+		err    bool
+	}{
+		{"hs256", signToken(t, hs256, claims(nil), secret), []string{"readers", "writers"}, false},
+		{"rs256", signToken(t, map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims(nil), rsaKey), []string{"readers", "writers"}, false},
+		{"es256", signToken(t, map[string]interface{}{"alg": "ES256", "kid": "ec"}, claims(nil), ecKey), []string{"readers", "writers"}, false},
+		{"single group", signToken(t, hs256, claims(map[string]interface{}{"groups": "readers"}), secret), []string{"readers"}, false},
+		{"no groups", signToken(t, hs256, claims(map[string]interface{}{"groups": nil}), secret), nil, false},
+		{"string audience", signToken(t, hs256, claims(map[string]interface{}{"aud": "mysql"}), secret), []string{"readers", "writers"}, false},
+		{"within leeway", signToken(t, hs256, claims(map[string]interface{}{"exp": now - 30}), secret), []string{"readers", "writers"}, false},
+		{"wrong secret", signToken(t, hs256, claims(nil), []byte("nope")), nil, true},
+		{"wrong key type", signToken(t, map[string]interface{}{"alg": "HS256", "kid": "rsa"}, claims(nil), secret), nil, true},
+		{"unknown key", signToken(t, map[string]interface{}{"alg": "RS256", "kid": "other"}, claims(nil), rsaKey), nil, true},
+		{"none", signToken(t, map[string]interface{}{"alg": "none"}, claims(nil), nil), nil, true},
+		{"expired", signToken(t, hs256, claims(map[string]interface{}{"exp": now - 3600}), secret), nil, true},
+		{"no expiry", signToken(t, hs256, claims(map[string]interface{}{"exp": nil}), secret), nil, true},
+		{"not yet valid", signToken(t, hs256, claims(map[string]interface{}{"nbf": now + 3600}), secret), nil, true},
+		{"other issuer", signToken(t, hs256, claims(map[string]interface{}{"iss": "other"}), secret), nil, true},
+		{"other audience", signToken(t, hs256, claims(map[string]interface{}{"aud": "other"}), secret), nil, true},
+		{"other user", signToken(t, hs256, claims(map[string]interface{}{"sub": "other"}), secret), nil, true},
+		{"malformed", "not a token", nil, true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := d.Authenticate("svc", tt.token)
+			if tt.err {
+				require.True(auth.ErrInvalidCredentials.Is(err), "%v", err)
+				return
+			}
+
+			require.NoError(err)
+			require.Equal(tt.groups, groups)
+		})
+	}
+
+	_, err = auth.NewJWTDirectory(auth.JWTConfig{Keys: map[string]interface{}{"": "secret"}})
+	require.Error(err)
+}
+
+func TestJWT(t *testing.T) {
+	secret := []byte("secret")
+	a, err := auth.NewJWT(auth.JWTConfig{
+		Keys:               map[string]interface{}{"": secret},
+		GroupPermissions:   map[string]auth.Permission{"writers": auth.WritePerm},
+		DefaultPermissions: auth.ReadPerm,
+	})
+	require.NoError(t, err)
+
+	header := map[string]interface{}{"alg": "HS256"}
+	exp := time.Now().Add(time.Hour).Unix()
+	writer := signToken(t, header, map[string]interface{}{"sub": "writer", "exp": exp, "groups": []string{"writers"}}, secret)
+	reader := signToken(t, header, map[string]interface{}{"sub": "reader", "exp": exp}, secret)
+
+	testAuthentication(t, a, []authenticationTest{
+		{"writer", writer, true},
+		{"reader", reader, true},
+		{"reader", writer, false},
+		{"reader", "", false},
+	}, nil)
+
+	testAuthorization(t, a, []authorizationTest{
+		{"writer", queries["insert"], true},
+		{"reader", queries["select"], true},
+		{"reader", queries["insert"], false},
+	}, nil)
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/internal/ldap"
+)
+
+// LDAPConfig configures an LDAP authentication method.
+type LDAPConfig struct {
+	// Address of the directory server, in host:port form.
+	Address string
+	// TLS, if not nil, is used to connect to the server with LDAPS.
+	TLS *tls.Config
+	// UserDN is the template of the DN users bind as, with a %s replaced by
+	// the user name, e.g. uid=%s,ou=people,dc=example,dc=com. Active
+	// Directory also accepts the user principal name, e.g. %s@example.com.
+	UserDN string
+	// GroupAttribute is the attribute of the user entry holding the DNs of
+	// its groups. Defaults to memberOf.
+	GroupAttribute string
+	// PoolSize is the maximum number of idle connections to the server kept
+	// open. Defaults to 4.
+	PoolSize int
+	// Timeout of each request to the server. Defaults to 10 seconds.
+	Timeout time.Duration
+	// GroupPermissions maps groups to the permissions granted to their
+	// members. Groups can be given by their full DN or by the value of their
+	// first RDN, e.g. cn=dba,ou=groups,dc=example,dc=com or dba. Names are
+	// case insensitive.
+	GroupPermissions map[string]Permission
+	// DefaultPermissions are granted to every user of the directory.
+	DefaultPermissions Permission
+}
+
+// NewLDAPDirectory creates a Directory binding to an LDAP server with the
+// credentials of users, reusing connections between authentications.
+func NewLDAPDirectory(cfg LDAPConfig) Directory {
+	return ldapDirectory{ldap.NewPool(ldap.Config{
+		Address:        cfg.Address,
+		TLS:            cfg.TLS,
+		UserDN:         cfg.UserDN,
+		GroupAttribute: cfg.GroupAttribute,
+		PoolSize:       cfg.PoolSize,
+		Timeout:        cfg.Timeout,
+	})}
+}
+
+// NewLDAP creates an authentication method checking users against the LDAP
+// server configured.
+func NewLDAP(cfg LDAPConfig) *DirectoryAuth {
+	return NewDirectoryAuth(NewLDAPDirectory(cfg), cfg.GroupPermissions, cfg.DefaultPermissions)
+}
+
+type ldapDirectory struct {
+	pool *ldap.Pool
+}
+
+// Authenticate implements the Directory interface.
+func (d ldapDirectory) Authenticate(user, password string) ([]string, error) {
+	groups, err := d.pool.Authenticate(user, password)
+	if ldap.ErrInvalidCredentials.Is(err) {
+		return nil, ErrInvalidCredentials.New(user)
+	}
+	return groups, err
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// PasswordPolicy holds the requirements a NativeStore checks on the
+// passwords set with CREATE USER and SET PASSWORD, and how long passwords
+// last by default. The zero value accepts any password, which never expires.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters of passwords.
+	MinLength int
+	// MixedCaseCount is the minimum number of lowercase and of uppercase
+	// letters of passwords.
+	MixedCaseCount int
+	// NumberCount is the minimum number of digits of passwords.
+	NumberCount int
+	// SpecialCharCount is the minimum number of characters other than
+	// letters and digits of passwords.
+	SpecialCharCount int
+	// Dictionary holds words passwords can't contain, regardless of case.
+	Dictionary []string
+	// Lifetime is the number of days passwords last, unless the account has
+	// its own PASSWORD EXPIRE policy. Zero means they never expire.
+	Lifetime int
+}
+
+// Validate returns sql.ErrPasswordPolicy if the password given doesn't
+// satisfy the requirements of the policy.
+func (p PasswordPolicy) Validate(password string) error {
+	var length, lower, upper, numbers, special int
+	for _, ru := range password {
+		length++
+		switch {
+		case unicode.IsLower(ru):
+			lower++
+		case unicode.IsUpper(ru):
+			upper++
+		case unicode.IsDigit(ru):
+			numbers++
+		case !unicode.IsLetter(ru):
+			special++
+		}
+	}
+
+	switch {
+	case length < p.MinLength:
+		return sql.ErrPasswordPolicy.New(fmt.Sprintf("it must have at least %d characters", p.MinLength))
+	case lower < p.MixedCaseCount || upper < p.MixedCaseCount:
+		return sql.ErrPasswordPolicy.New(fmt.Sprintf("it must have at least %d lowercase and %d uppercase letters", p.MixedCaseCount, p.MixedCaseCount))
+	case numbers < p.NumberCount:
+		return sql.ErrPasswordPolicy.New(fmt.Sprintf("it must have at least %d digits", p.NumberCount))
+	case special < p.SpecialCharCount:
+		return sql.ErrPasswordPolicy.New(fmt.Sprintf("it must have at least %d special characters", p.SpecialCharCount))
+	}
+
+	lowered := strings.ToLower(password)
+	for _, word := range p.Dictionary {
+		if word != "" && strings.Contains(lowered, strings.ToLower(word)) {
+			return sql.ErrPasswordPolicy.New("it contains a dictionary word")
+		}
+	}
+
+	return nil
+}
+
+// expired returns whether the password of the user given has expired.
+func (p PasswordPolicy) expired(u User) bool {
+	if u.PasswordExpire.Now {
+		return true
+	}
+
+	var days int
+	switch u.PasswordExpire.Lifetime {
+	case sql.PasswordLifetimeDefault:
+		days = p.Lifetime
+	case sql.PasswordLifetimeInterval:
+		days = u.PasswordExpire.Days
+	}
+
+	if days <= 0 || u.PasswordChanged.IsZero() {
+		return false
+	}
+
+	return time.Since(u.PasswordChanged) > time.Duration(days)*24*time.Hour
+}
@@ -0,0 +1,50 @@
+package auth
+
+import "net"
+
+// PeerCredentials are the credentials of the process of a client connected
+// through a Unix domain socket, as reported by the kernel.
+type PeerCredentials struct {
+	// PID is the process ID of the client.
+	PID int32
+	// UID is the user ID of the client.
+	UID uint32
+	// GID is the group ID of the client.
+	GID uint32
+}
+
+// PeerAddr is the remote address of the clients connected to the server
+// through a Unix domain socket, which authentication methods are given in
+// the ValidateHash and Negotiate methods of their mysql.AuthServer, to
+// authenticate the clients by the user running them.
+type PeerAddr struct {
+	// Addr is the address of the socket of the client, which is usually
+	// unnamed.
+	Addr net.Addr
+	// Credentials of the client. They are nil if they couldn't be read,
+	// as on the platforms not supporting them.
+	Credentials *PeerCredentials
+}
+
+// Network implements the net.Addr interface.
+func (a *PeerAddr) Network() string {
+	return "unix"
+}
+
+// String implements the net.Addr interface.
+func (a *PeerAddr) String() string {
+	if a.Addr == nil {
+		return ""
+	}
+	return a.Addr.String()
+}
+
+// PeerCredentialsOf returns the credentials of the client whose remote
+// address is given, if it's connected through a Unix domain socket and they
+// could be read.
+func PeerCredentialsOf(addr net.Addr) (*PeerCredentials, bool) {
+	if a, ok := addr.(*PeerAddr); ok && a.Credentials != nil {
+		return a.Credentials, true
+	}
+	return nil, false
+}
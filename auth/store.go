@@ -0,0 +1,1032 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	querypb "github.com/dolthub/vitess/go/vt/proto/query"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// User is an account stored in a UserStore.
+type User struct {
+	// Name of the user.
+	Name string
+	// Host the user connects from. It may contain the wildcards % and _.
+	Host string
+	// Password is the mysql_native_password hash of the password, as returned
+	// by NativePassword. It is empty if the user has no password.
+	Password string
+	// Permissions granted to the user. They are kept for compatibility and
+	// are equivalent to global grants: ReadPerm grants SELECT and SHOW VIEW,
+	// and WritePerm every other privilege, including GRANT OPTION. They are
+	// converted to Grants the first time the privileges of the user change.
+	Permissions Permission
+	// Grants holds the privileges granted to the user with GRANT.
+	Grants sql.PrivilegeSet
+	// Role is true for roles created with CREATE ROLE, which can't log in.
+	Role bool
+	// Roles holds the roles granted to the user.
+	Roles []sql.RoleGrant
+	// DefaultRoles are the roles activated when the user logs in.
+	DefaultRoles []sql.Account
+	// Require holds the transport requirements of the user.
+	Require sql.TLSRequirement
+	// Proxies holds the accounts the user can log in as with GRANT PROXY.
+	Proxies []sql.ProxyGrant
+	// PasswordChanged is when the password was last set. Passwords with no
+	// date never expire unless expired with PASSWORD EXPIRE.
+	PasswordChanged time.Time
+	// PasswordExpire holds the PASSWORD EXPIRE policy of the user. Its Now
+	// field is set while the password is expired.
+	PasswordExpire sql.PasswordExpire
+	// MaxUserConnections is the maximum number of simultaneous connections
+	// of the user, set with WITH MAX_USER_CONNECTIONS. Zero means the global
+	// max_user_connections applies.
+	MaxUserConnections int
+}
+
+// Account returns the account of the user.
+func (u User) Account() sql.Account {
+	return sql.Account{Name: u.Name, Host: u.Host}
+}
+
+// readPrivileges are the privileges granted by ReadPerm.
+const readPrivileges = sql.PrivilegeSelect | sql.PrivilegeShowView
+
+// Privileges returns all the privileges of the user, including the ones
+// given by its Permissions.
+func (u User) Privileges() sql.PrivilegeSet {
+	var privileges sql.Privilege
+	if u.Permissions&ReadPerm != 0 {
+		privileges |= readPrivileges
+	}
+	if u.Permissions&WritePerm != 0 {
+		privileges |= sql.GlobalPrivileges &^ readPrivileges
+	}
+
+	return u.Grants.Grant(sql.PrivilegeLevel{}, privileges)
+}
+
+// UserStore persists the users of a NativeStore. Integrators can implement it
+// to keep users in their own storage, e.g. in a table.
+type UserStore interface {
+	// Users returns all the users stored.
+	Users(ctx context.Context) ([]User, error)
+	// SaveUser creates or replaces the user with the same name and host.
+	SaveUser(ctx context.Context, user User) error
+	// DeleteUser removes the user with the name and host given, if any.
+	DeleteUser(ctx context.Context, name, host string) error
+}
+
+// MemoryUserStore is a UserStore keeping users in memory.
+type MemoryUserStore struct {
+	mu    sync.RWMutex
+	users []User
+}
+
+var _ UserStore = (*MemoryUserStore)(nil)
+
+// NewMemoryUserStore creates a MemoryUserStore with the users given.
+func NewMemoryUserStore(users ...User) *MemoryUserStore {
+	return &MemoryUserStore{users: users}
+}
+
+// Users implements the UserStore interface.
+func (s *MemoryUserStore) Users(context.Context) ([]User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var users = make([]User, len(s.users))
+	copy(users, s.users)
+	return users, nil
+}
+
+// SaveUser implements the UserStore interface.
+func (s *MemoryUserStore) SaveUser(_ context.Context, user User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, u := range s.users {
+		if u.Account().Equal(user.Account()) {
+			s.users[i] = user
+			return nil
+		}
+	}
+
+	s.users = append(s.users, user)
+	return nil
+}
+
+// DeleteUser implements the UserStore interface.
+func (s *MemoryUserStore) DeleteUser(_ context.Context, name, host string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account := sql.Account{Name: name, Host: host}
+	for i, u := range s.users {
+		if u.Account().Equal(account) {
+			s.users = append(s.users[:i], s.users[i+1:]...)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// NativeStore authenticates users kept in a UserStore with
+// mysql_native_password. Unlike Native, users can be managed at runtime with
+// CREATE USER, DROP USER and SET PASSWORD statements, which are stored back in
+// the UserStore. Users created with CREATE USER have no privileges, like the
+// USAGE of MySQL, until they're granted some. Privileges and roles can be
+// managed with GRANT and REVOKE, and are checked by the analyzer before
+// running queries.
+//
+// Passwords are never stored in clear text. Note that mysql_native_password
+// requires the server to keep the unsalted SHA1(SHA1(password)) hash, since
+// the client proves it knows the password by scrambling it with a random
+// salt sent on each connection.
+type NativeStore struct {
+	// mu serializes changes to the users, so that checking whether a user
+	// exists and saving it is atomic.
+	mu      sync.Mutex
+	store   UserStore
+	control *connectionControl
+
+	policyMu sync.RWMutex
+	policy   PasswordPolicy
+}
+
+var _ Auth = (*NativeStore)(nil)
+var _ ConnectionChecker = (*NativeStore)(nil)
+var _ ConnectionLimiter = (*NativeStore)(nil)
+var _ sql.UserManager = (*NativeStore)(nil)
+var _ sql.RoleManager = (*NativeStore)(nil)
+var _ sql.ProxyManager = (*NativeStore)(nil)
+var _ sql.StatusProvider = (*NativeStore)(nil)
+var _ sql.PasswordManager = (*NativeStore)(nil)
+var _ sql.ResourceManager = (*NativeStore)(nil)
+
+// NewNativeStore creates a NativeStore authenticating the users of the store
+// given.
+func NewNativeStore(store UserStore) *NativeStore {
+	return &NativeStore{store: store, control: newConnectionControl()}
+}
+
+// SetConnectionControl sets how clients that repeatedly fail to authenticate
+// are slowed down and blocked. By default they are not.
+func (s *NativeStore) SetConnectionControl(config ConnectionControl) {
+	s.control.setConfig(config)
+}
+
+// SetPasswordPolicy sets the requirements of new passwords and how long
+// passwords last by default. By default, any password is accepted and never
+// expires.
+func (s *NativeStore) SetPasswordPolicy(policy PasswordPolicy) {
+	s.policyMu.Lock()
+	s.policy = policy
+	s.policyMu.Unlock()
+}
+
+func (s *NativeStore) passwordPolicy() PasswordPolicy {
+	s.policyMu.RLock()
+	defer s.policyMu.RUnlock()
+	return s.policy
+}
+
+// Status implements the sql.StatusProvider interface. Aborted_connects is the
+// number of failed attempts to authenticate, Connection_control_delay_generated
+// the number of attempts delayed and Locked_connects the number of attempts to
+// log in to locked accounts.
+func (s *NativeStore) Status() map[string]interface{} {
+	return s.control.status()
+}
+
+// Mysql implements Auth interface. Users are looked up in the store on every
+// connection, so changes take effect immediately.
+func (s *NativeStore) Mysql() mysql.AuthServer {
+	return &nativeStoreAuthServer{s}
+}
+
+// Allowed implements Auth interface. It only rejects queries the user can't
+// run on any table, such as writes from users with no privilege besides
+// reading. Every user can read, like the users of MySQL with no privilege
+// but USAGE can run the queries reading no table. The analyzer checks the
+// privileges needed by each query on the tables it uses.
+func (s *NativeStore) Allowed(ctx *sql.Context, permission Permission) error {
+	u, ok, err := s.clientUser(ctx, ctx.Client())
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrNotAuthorized.Wrap(ErrNoPermission.New(permission))
+	}
+
+	set, err := sql.ActivePrivileges(ctx, s, u.Account())
+	if err != nil {
+		return err
+	}
+
+	granted := ReadPerm
+	if set.All()&^readPrivileges != 0 {
+		granted |= WritePerm
+	}
+
+	return nativeUser{Name: u.Name, Permissions: granted}.Allowed(permission)
+}
+
+// CheckConnection implements the ConnectionChecker interface. The
+// requirements checked for proxy users are the ones of the proxy user.
+func (s *NativeStore) CheckConnection(conn Connection) error {
+	name := conn.User
+	if proxy, _, ok := splitProxyUser(name); ok {
+		name = proxy
+	}
+
+	u, ok, err := s.lookup(context.Background(), name, clientHost(conn.Address))
+	if err != nil {
+		return err
+	}
+
+	if !ok || !meetsTLSRequirement(u.Require, conn) {
+		return mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", conn.User)
+	}
+
+	return nil
+}
+
+// ConnectionLimit implements the ConnectionLimiter interface. The
+// connections of proxy users count as connections of the proxy user.
+func (s *NativeStore) ConnectionLimit(conn Connection) (sql.Account, int, error) {
+	name := conn.User
+	if proxy, _, ok := splitProxyUser(name); ok {
+		name = proxy
+	}
+
+	u, ok, err := s.lookup(context.Background(), name, clientHost(conn.Address))
+	if err != nil {
+		return sql.Account{}, 0, err
+	}
+
+	if !ok {
+		return sql.Account{}, 0, mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", conn.User)
+	}
+
+	return u.Account(), u.MaxUserConnections, nil
+}
+
+// CreateUser implements the sql.UserManager interface.
+func (s *NativeStore) CreateUser(ctx *sql.Context, account sql.Account, password string, require sql.TLSRequirement) error {
+	if err := s.passwordPolicy().Validate(password); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok, err := s.user(ctx, account); err != nil {
+		return err
+	} else if ok {
+		return sql.ErrUserAlreadyExists.New(account)
+	}
+
+	return s.store.SaveUser(ctx, User{
+		Name:            account.Name,
+		Host:            account.Host,
+		Password:        NativePassword(password),
+		Require:         require,
+		PasswordChanged: time.Now(),
+	})
+}
+
+// DropUser implements the sql.UserManager interface.
+func (s *NativeStore) DropUser(ctx *sql.Context, account sql.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.dropAccount(ctx, account)
+}
+
+// dropAccount removes an account and revokes it from the users it was
+// granted to as a role or proxied account.
+func (s *NativeStore) dropAccount(ctx *sql.Context, account sql.Account) error {
+	u, ok, err := s.user(ctx, account)
+	if err != nil {
+		return err
+	} else if !ok {
+		return sql.ErrUserNotFound.New(account)
+	}
+
+	if err := s.store.DeleteUser(ctx, u.Name, u.Host); err != nil {
+		return err
+	}
+
+	users, err := s.store.Users(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, other := range users {
+		roles := removeRole(other.Roles, account)
+		defaults := removeAccount(other.DefaultRoles, account)
+		proxies := removeProxy(other.Proxies, account)
+		if len(roles) == len(other.Roles) && len(defaults) == len(other.DefaultRoles) &&
+			len(proxies) == len(other.Proxies) {
+			continue
+		}
+
+		other.Roles, other.DefaultRoles, other.Proxies = roles, defaults, proxies
+		if err := s.store.SaveUser(ctx, other); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetPassword implements the sql.UserManager interface. Changing the
+// password of an account ends its expiration.
+func (s *NativeStore) SetPassword(ctx *sql.Context, account sql.Account, password string) error {
+	if err := s.passwordPolicy().Validate(password); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok, err := s.user(ctx, account)
+	if err != nil {
+		return err
+	} else if !ok {
+		return sql.ErrUserNotFound.New(account)
+	}
+
+	u.Password = NativePassword(password)
+	u.PasswordChanged = time.Now()
+	u.PasswordExpire.Now = false
+	return s.store.SaveUser(ctx, u)
+}
+
+// SetPasswordExpire implements the sql.PasswordManager interface.
+func (s *NativeStore) SetPasswordExpire(ctx *sql.Context, account sql.Account, expire sql.PasswordExpire) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok, err := s.user(ctx, account)
+	if err != nil {
+		return err
+	} else if !ok {
+		return sql.ErrUserNotFound.New(account)
+	}
+
+	if expire.Now {
+		u.PasswordExpire.Now = true
+	} else {
+		expire.Now = u.PasswordExpire.Now
+		u.PasswordExpire = expire
+	}
+
+	return s.store.SaveUser(ctx, u)
+}
+
+// SetResourceLimits implements the sql.ResourceManager interface.
+func (s *NativeStore) SetResourceLimits(ctx *sql.Context, account sql.Account, limits sql.ResourceLimits) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok, err := s.user(ctx, account)
+	if err != nil {
+		return err
+	} else if !ok {
+		return sql.ErrUserNotFound.New(account)
+	}
+
+	u.MaxUserConnections = limits.MaxUserConnections
+	return s.store.SaveUser(ctx, u)
+}
+
+// PasswordExpired implements the sql.PasswordManager interface.
+func (s *NativeStore) PasswordExpired(ctx *sql.Context) (bool, error) {
+	u, ok, err := s.clientUser(ctx, ctx.Client())
+	if err != nil || !ok {
+		return false, err
+	}
+
+	return s.passwordPolicy().expired(u), nil
+}
+
+// CurrentAccount implements the sql.UserManager interface.
+func (s *NativeStore) CurrentAccount(ctx *sql.Context) (sql.Account, error) {
+	client := ctx.Client()
+	u, ok, err := s.clientUser(ctx, client)
+	if err != nil {
+		return sql.Account{}, err
+	}
+
+	if !ok {
+		return sql.Account{}, sql.ErrUserNotFound.New(sql.Account{Name: client.User, Host: clientHost(client.Address)})
+	}
+
+	return u.Account(), nil
+}
+
+// Grant implements the sql.PrivilegeManager interface.
+func (s *NativeStore) Grant(ctx *sql.Context, account sql.Account, level sql.PrivilegeLevel, privileges sql.Privilege) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok, err := s.user(ctx, account)
+	if err != nil {
+		return err
+	} else if !ok {
+		return sql.ErrUserNotFound.New(account)
+	}
+
+	u.Grants = u.Privileges().Grant(level, privileges)
+	u.Permissions = 0
+	return s.store.SaveUser(ctx, u)
+}
+
+// Revoke implements the sql.PrivilegeManager interface.
+func (s *NativeStore) Revoke(ctx *sql.Context, account sql.Account, level sql.PrivilegeLevel, privileges sql.Privilege) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok, err := s.user(ctx, account)
+	if err != nil {
+		return err
+	} else if !ok {
+		return sql.ErrUserNotFound.New(account)
+	}
+
+	current := u.Privileges()
+	if current.Level(level) == sql.PrivilegeUsage {
+		return sql.ErrNonexistingGrant.New(account.Name, account.Host)
+	}
+
+	u.Grants = current.Revoke(level, privileges)
+	u.Permissions = 0
+	return s.store.SaveUser(ctx, u)
+}
+
+// Privileges implements the sql.PrivilegeManager interface.
+func (s *NativeStore) Privileges(ctx *sql.Context, account sql.Account) (sql.PrivilegeSet, error) {
+	u, ok, err := s.user(ctx, account)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrUserNotFound.New(account)
+	}
+
+	return u.Privileges(), nil
+}
+
+// CreateRole implements the sql.RoleManager interface.
+func (s *NativeStore) CreateRole(ctx *sql.Context, role sql.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok, err := s.user(ctx, role); err != nil {
+		return err
+	} else if ok {
+		return sql.ErrUserAlreadyExists.New(role)
+	}
+
+	return s.store.SaveUser(ctx, User{Name: role.Name, Host: role.Host, Role: true})
+}
+
+// DropRole implements the sql.RoleManager interface.
+func (s *NativeStore) DropRole(ctx *sql.Context, role sql.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.dropAccount(ctx, role)
+}
+
+// GrantRole implements the sql.RoleManager interface.
+func (s *NativeStore) GrantRole(ctx *sql.Context, role, to sql.Account, withAdminOption bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.store.Users(ctx)
+	if err != nil {
+		return err
+	}
+
+	u, ok := findUser(users, to)
+	if !ok {
+		return sql.ErrUserNotFound.New(to)
+	}
+
+	if _, ok := findUser(users, role); !ok {
+		return sql.ErrUserNotFound.New(role)
+	}
+
+	if grantsRole(users, role, to) {
+		return sql.ErrRoleGrantLoop.New(to, role)
+	}
+
+	for i, g := range u.Roles {
+		if g.Role.Equal(role) {
+			u.Roles[i].WithAdminOption = g.WithAdminOption || withAdminOption
+			return s.store.SaveUser(ctx, u)
+		}
+	}
+
+	u.Roles = append(u.Roles, sql.RoleGrant{Role: role, WithAdminOption: withAdminOption})
+	return s.store.SaveUser(ctx, u)
+}
+
+// RevokeRole implements the sql.RoleManager interface.
+func (s *NativeStore) RevokeRole(ctx *sql.Context, role, from sql.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok, err := s.user(ctx, from)
+	if err != nil {
+		return err
+	} else if !ok {
+		return sql.ErrUserNotFound.New(from)
+	}
+
+	roles := removeRole(u.Roles, role)
+	if len(roles) == len(u.Roles) {
+		return sql.ErrRoleNotGranted.New(role, from)
+	}
+
+	u.Roles = roles
+	u.DefaultRoles = removeAccount(u.DefaultRoles, role)
+	return s.store.SaveUser(ctx, u)
+}
+
+// Roles implements the sql.RoleManager interface.
+func (s *NativeStore) Roles(ctx *sql.Context, account sql.Account) ([]sql.RoleGrant, error) {
+	u, ok, err := s.user(ctx, account)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrUserNotFound.New(account)
+	}
+
+	return u.Roles, nil
+}
+
+// SetDefaultRoles implements the sql.RoleManager interface.
+func (s *NativeStore) SetDefaultRoles(ctx *sql.Context, account sql.Account, roles []sql.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok, err := s.user(ctx, account)
+	if err != nil {
+		return err
+	} else if !ok {
+		return sql.ErrUserNotFound.New(account)
+	}
+
+	for _, r := range roles {
+		if len(removeRole(u.Roles, r)) == len(u.Roles) {
+			return sql.ErrRoleNotGranted.New(r, account)
+		}
+	}
+
+	u.DefaultRoles = roles
+	return s.store.SaveUser(ctx, u)
+}
+
+// DefaultRoles implements the sql.RoleManager interface.
+func (s *NativeStore) DefaultRoles(ctx *sql.Context, account sql.Account) ([]sql.Account, error) {
+	u, ok, err := s.user(ctx, account)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrUserNotFound.New(account)
+	}
+
+	return u.DefaultRoles, nil
+}
+
+// GrantProxy implements the sql.ProxyManager interface.
+func (s *NativeStore) GrantProxy(ctx *sql.Context, proxied, to sql.Account, withGrantOption bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.store.Users(ctx)
+	if err != nil {
+		return err
+	}
+
+	u, ok := findUser(users, to)
+	if !ok {
+		return sql.ErrUserNotFound.New(to)
+	}
+
+	if _, ok := findUser(users, proxied); !ok {
+		return sql.ErrUserNotFound.New(proxied)
+	}
+
+	for i, g := range u.Proxies {
+		if g.Proxied.Equal(proxied) {
+			u.Proxies[i].WithGrantOption = g.WithGrantOption || withGrantOption
+			return s.store.SaveUser(ctx, u)
+		}
+	}
+
+	u.Proxies = append(u.Proxies, sql.ProxyGrant{Proxied: proxied, WithGrantOption: withGrantOption})
+	return s.store.SaveUser(ctx, u)
+}
+
+// RevokeProxy implements the sql.ProxyManager interface.
+func (s *NativeStore) RevokeProxy(ctx *sql.Context, proxied, from sql.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok, err := s.user(ctx, from)
+	if err != nil {
+		return err
+	} else if !ok {
+		return sql.ErrUserNotFound.New(from)
+	}
+
+	proxies := removeProxy(u.Proxies, proxied)
+	if len(proxies) == len(u.Proxies) {
+		return sql.ErrNonexistingGrant.New(from.Name, from.Host)
+	}
+
+	u.Proxies = proxies
+	return s.store.SaveUser(ctx, u)
+}
+
+// Proxies implements the sql.ProxyManager interface.
+func (s *NativeStore) Proxies(ctx *sql.Context, account sql.Account) ([]sql.ProxyGrant, error) {
+	u, ok, err := s.user(ctx, account)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrUserNotFound.New(account)
+	}
+
+	return u.Proxies, nil
+}
+
+func findUser(users []User, account sql.Account) (User, bool) {
+	for _, u := range users {
+		if u.Account().Equal(account) {
+			return u, true
+		}
+	}
+	return User{}, false
+}
+
+// grantsRole returns whether granting role to the account given would make
+// the account be granted to itself, that is, whether the account is the role
+// or it's granted to the role, directly or through other roles.
+func grantsRole(users []User, role, account sql.Account) bool {
+	var visited []sql.Account
+	var pending = []sql.Account{role}
+	for len(pending) > 0 {
+		r := pending[0]
+		pending = pending[1:]
+
+		if r.Equal(account) {
+			return true
+		}
+
+		var seen bool
+		for _, v := range visited {
+			seen = seen || v.Equal(r)
+		}
+		if seen {
+			continue
+		}
+		visited = append(visited, r)
+
+		if u, ok := findUser(users, r); ok {
+			for _, g := range u.Roles {
+				pending = append(pending, g.Role)
+			}
+		}
+	}
+
+	return false
+}
+
+func removeRole(roles []sql.RoleGrant, role sql.Account) []sql.RoleGrant {
+	var result []sql.RoleGrant
+	for _, g := range roles {
+		if !g.Role.Equal(role) {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+func removeProxy(proxies []sql.ProxyGrant, proxied sql.Account) []sql.ProxyGrant {
+	var result []sql.ProxyGrant
+	for _, g := range proxies {
+		if !g.Proxied.Equal(proxied) {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+func removeAccount(accounts []sql.Account, account sql.Account) []sql.Account {
+	var result []sql.Account
+	for _, a := range accounts {
+		if !a.Equal(account) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// user returns the user with exactly the account given.
+func (s *NativeStore) user(ctx context.Context, account sql.Account) (User, bool, error) {
+	users, err := s.store.Users(ctx)
+	if err != nil {
+		return User{}, false, err
+	}
+
+	u, ok := findUser(users, account)
+	return u, ok, nil
+}
+
+// lookup returns the user a client with the name given connecting from host
+// authenticates as. As in MySQL, when several accounts match, the ones with
+// the most specific host are preferred. Roles never match, since they can't
+// log in.
+func (s *NativeStore) lookup(ctx context.Context, name, host string) (User, bool, error) {
+	users, err := s.store.Users(ctx)
+	if err != nil {
+		return User{}, false, err
+	}
+
+	var matches []User
+	for _, u := range users {
+		if !u.Role && u.Name == name && hostMatches(u.Host, host) {
+			matches = append(matches, u)
+		}
+	}
+
+	if len(matches) == 0 {
+		return User{}, false, nil
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return hostSpecificity(matches[i].Host) > hostSpecificity(matches[j].Host)
+	})
+
+	return matches[0], true, nil
+}
+
+// clientUser returns the user the client of a session acts as. For proxy
+// users, the proxy user must still be granted PROXY on the account, so that
+// revoking it takes effect on open sessions as well.
+func (s *NativeStore) clientUser(ctx context.Context, client sql.Client) (User, bool, error) {
+	if client.ProxyUser == "" {
+		return s.lookup(ctx, client.User, clientHost(client.Address))
+	}
+
+	proxy, ok, err := s.lookup(ctx, client.ProxyUser, clientHost(client.Address))
+	if err != nil || !ok {
+		return User{}, false, err
+	}
+
+	return s.proxied(ctx, proxy, client.User)
+}
+
+// proxied returns the user with the name given a proxy user was granted
+// PROXY on.
+func (s *NativeStore) proxied(ctx context.Context, proxy User, name string) (User, bool, error) {
+	users, err := s.store.Users(ctx)
+	if err != nil {
+		return User{}, false, err
+	}
+
+	for _, g := range proxy.Proxies {
+		if g.Proxied.Name != name {
+			continue
+		}
+
+		if u, ok := findUser(users, g.Proxied); ok && !u.Role {
+			return u, true, nil
+		}
+	}
+
+	return User{}, false, nil
+}
+
+// splitProxyUser splits the name of a client logging in as a proxy user, in
+// the proxy[proxied] form, into the names of both users.
+func splitProxyUser(name string) (proxy, proxied string, ok bool) {
+	if !strings.HasSuffix(name, "]") {
+		return "", "", false
+	}
+
+	i := strings.Index(name, "[")
+	if i <= 0 || i == len(name)-2 {
+		return "", "", false
+	}
+
+	return name[:i], name[i+1 : len(name)-1], true
+}
+
+// clientHost returns the host of a client address in the host:port form. The
+// address is returned as is if it has no port, e.g. a unix socket.
+func clientHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+var loopbackHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+func isLoopback(host string) bool {
+	for _, h := range loopbackHosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostMatches returns whether the host of a client matches the host pattern
+// of an account. Local connections match localhost as well as the loopback
+// addresses.
+func hostMatches(pattern, host string) bool {
+	if pattern == "" {
+		pattern = "%"
+	}
+
+	if isLoopback(pattern) && (isLoopback(host) || host == "") {
+		return true
+	}
+
+	var re bytes.Buffer
+	re.WriteString("(?i)^")
+	for _, ch := range pattern {
+		switch ch {
+		case '%':
+			re.WriteString(".*")
+		case '_':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	re.WriteString("$")
+
+	matched, err := regexp.MatchString(re.String(), host)
+	return err == nil && matched
+}
+
+// hostSpecificity ranks host patterns so that literal hosts come first and
+// patterns with fewer wildcards come before broader ones.
+func hostSpecificity(pattern string) int {
+	wildcards := strings.Count(pattern, "%") + strings.Count(pattern, "_")
+	if wildcards == 0 {
+		return len(pattern) + 1<<16
+	}
+	return len(pattern) - wildcards*256
+}
+
+// nativeStoreAuthServer implements mysql_native_password authentication
+// against the users of a NativeStore.
+type nativeStoreAuthServer struct {
+	s *NativeStore
+}
+
+var _ mysql.AuthServer = (*nativeStoreAuthServer)(nil)
+
+// AuthMethod implements the mysql.AuthServer interface.
+func (a *nativeStoreAuthServer) AuthMethod(string) (string, error) {
+	return mysql.MysqlNativePassword, nil
+}
+
+// Salt implements the mysql.AuthServer interface.
+func (a *nativeStoreAuthServer) Salt() ([]byte, error) {
+	return mysql.NewSalt()
+}
+
+// ValidateHash implements the mysql.AuthServer interface.
+func (a *nativeStoreAuthServer) ValidateHash(
+	salt []byte,
+	user string,
+	authResponse []byte,
+	remoteAddr net.Addr,
+) (mysql.Getter, error) {
+	var host string
+	if remoteAddr != nil && remoteAddr.Network() != "unix" {
+		host = clientHost(remoteAddr.String())
+	}
+
+	if err := a.s.control.before(user, host); err != nil {
+		return nil, err
+	}
+
+	getter, err := a.validateHash(salt, user, authResponse, host)
+	a.s.control.done(user, host, err == nil)
+	return getter, err
+}
+
+func (a *nativeStoreAuthServer) validateHash(salt []byte, user string, authResponse []byte, host string) (mysql.Getter, error) {
+	name, proxiedName, isProxy := splitProxyUser(user)
+	if !isProxy {
+		name = user
+	}
+
+	u, ok, err := a.s.lookup(context.Background(), name, host)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok || !checkNativePassword(authResponse, salt, u.Password) {
+		return nil, mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", user)
+	}
+
+	if !isProxy {
+		return nativeStoreUserData{user}, nil
+	}
+
+	if _, ok, err := a.s.proxied(context.Background(), u, proxiedName); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", user)
+	}
+
+	return ProxyUserData{User: proxiedName, Proxy: name}, nil
+}
+
+// Negotiate implements the mysql.AuthServer interface. It is never called,
+// since mysql_native_password is always used.
+func (a *nativeStoreAuthServer) Negotiate(c *mysql.Conn, user string, remoteAddr net.Addr) (mysql.Getter, error) {
+	return nil, mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", user)
+}
+
+// checkNativePassword returns whether the scrambled reply of a client matches
+// the mysql_native_password hash given.
+//
+//	stage1 = xor(reply, sha1(salt + hash))
+//	check(sha1(stage1) == hash)
+func checkNativePassword(reply, salt []byte, nativePassword string) bool {
+	if nativePassword == "" {
+		return len(reply) == 0
+	}
+
+	if len(reply) != sha1.Size {
+		return false
+	}
+
+	hash, err := hex.DecodeString(strings.TrimPrefix(nativePassword, "*"))
+	if err != nil {
+		return false
+	}
+
+	crypt := sha1.New()
+	crypt.Write(salt)
+	crypt.Write(hash)
+	stage1 := crypt.Sum(nil)
+	for i := range stage1 {
+		stage1[i] ^= reply[i]
+	}
+
+	crypt.Reset()
+	crypt.Write(stage1)
+	return bytes.Equal(crypt.Sum(nil), hash)
+}
+
+type nativeStoreUserData struct {
+	user string
+}
+
+// Get implements the mysql.Getter interface.
+func (d nativeStoreUserData) Get() *querypb.VTGateCallerID {
+	return &querypb.VTGateCallerID{Username: d.user}
+}
+
+// ProxyUserData is the user data of the connections of clients that logged in
+// as a proxy user, acting as the user proxied.
+type ProxyUserData struct {
+	// User is the name of the user proxied.
+	User string
+	// Proxy is the name of the proxy user the client authenticated as.
+	Proxy string
+}
+
+// Get implements the mysql.Getter interface.
+func (d ProxyUserData) Get() *querypb.VTGateCallerID {
+	return &querypb.VTGateCallerID{Username: d.User}
+}
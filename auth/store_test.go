@@ -0,0 +1,736 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func nativeStore() (*auth.NativeStore, *auth.MemoryUserStore) {
+	store := auth.NewMemoryUserStore(
+		auth.User{Name: "root", Host: "%", Password: auth.NativePassword("password"), Permissions: auth.AllPermissions},
+		auth.User{Name: "user", Host: "localhost", Password: auth.NativePassword("local"), Permissions: auth.ReadPerm},
+		auth.User{Name: "user", Host: "%", Password: auth.NativePassword("remote"), Permissions: auth.ReadPerm},
+		auth.User{Name: "remote", Host: "10.%", Password: auth.NativePassword("password"), Permissions: auth.ReadPerm},
+		auth.User{Name: "no_password", Host: "%", Permissions: auth.ReadPerm},
+	)
+	return auth.NewNativeStore(store), store
+}
+
+var nativeStoreTests = []authenticationTest{
+	{"root", "password", true},
+	{"root", "", false},
+	{"root", "other", false},
+	{"user", "local", true},
+	{"user", "remote", false},
+	{"remote", "password", false},
+	{"no_password", "", true},
+	{"no_password", "password", false},
+	{"unknown", "", false},
+}
+
+func TestNativeStoreAuthentication(t *testing.T) {
+	a, _ := nativeStore()
+	testAuthentication(t, a, nativeStoreTests, nil)
+}
+
+var nativeStoreAuthorizationTests = []authorizationTest{
+	{"root", queries["select"], true},
+	{"root", queries["insert"], true},
+	{"root", "create user bob identified by 'pw'", true},
+	{"user", queries["select"], true},
+	{"user", queries["insert"], false},
+	{"user", "create user alice", false},
+	{"user", "set password for bob = 'other'", false},
+	{"user", "set password = 'new'", true},
+	{"unknown", queries["select"], false},
+}
+
+func TestNativeStoreAuthorization(t *testing.T) {
+	a, _ := nativeStore()
+	testAuthorization(t, a, nativeStoreAuthorizationTests, nil)
+}
+
+func TestNativeStoreUserManagement(t *testing.T) {
+	require := require.New(t)
+	a, store := nativeStore()
+
+	e, idxReg, err := authEngine(a)
+	require.NoError(err)
+
+	session := sql.NewSession("localhost", "127.0.0.1:3306", "root", 1)
+	ctx := sql.NewContext(context.TODO(),
+		sql.WithSession(session),
+		sql.WithIndexRegistry(idxReg),
+		sql.WithViewRegistry(sql.NewViewRegistry())).WithCurrentDB("test")
+
+	query := func(q string) error {
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return err
+		}
+		_, err = sql.RowIterToRows(iter)
+		return err
+	}
+
+	require.NoError(query("CREATE USER bob IDENTIFIED BY 'bob_pw', 'alice'@'localhost' IDENTIFIED BY 'alice_pw'"))
+
+	err = query("CREATE USER bob, carol")
+	require.True(sql.ErrUserOperationFailed.Is(err))
+	require.Contains(err.Error(), "'bob'@'%'")
+	require.NotContains(err.Error(), "carol")
+
+	require.NoError(query("CREATE USER IF NOT EXISTS bob IDENTIFIED BY 'ignored'"))
+	require.Len(ctx.Warnings(), 1)
+
+	require.NoError(query("SET PASSWORD FOR bob = 'new_pw'"))
+	require.NoError(query("SET PASSWORD = 'root_pw'"))
+
+	err = query("SET PASSWORD FOR nobody = 'pw'")
+	require.True(sql.ErrUserNotFound.Is(err))
+
+	require.NoError(query("DROP USER carol"))
+	require.True(sql.ErrUserOperationFailed.Is(query("DROP USER carol")))
+	require.NoError(query("DROP USER IF EXISTS carol"))
+
+	users, err := store.Users(ctx)
+	require.NoError(err)
+
+	var passwords = make(map[string]string)
+	for _, u := range users {
+		passwords[u.Account().String()] = u.Password
+	}
+
+	require.Equal(auth.NativePassword("root_pw"), passwords["'root'@'%'"])
+	require.Equal(auth.NativePassword("new_pw"), passwords["'bob'@'%'"])
+	require.Equal(auth.NativePassword("alice_pw"), passwords["'alice'@'localhost'"])
+	require.NotContains(passwords, "'carol'@'%'")
+
+	testAuthentication(t, a, []authenticationTest{
+		{"root", "root_pw", true},
+		{"root", "password", false},
+		{"bob", "new_pw", true},
+		{"bob", "bob_pw", false},
+		{"alice", "alice_pw", true},
+		{"carol", "", false},
+	}, nil)
+}
+
+func TestNativeStoreNotSupported(t *testing.T) {
+	require := require.New(t)
+
+	e, idxReg, err := authEngine(auth.NewNativeSingle("root", "", auth.AllPermissions))
+	require.NoError(err)
+
+	session := sql.NewSession("localhost", "127.0.0.1:3306", "root", 1)
+	ctx := sql.NewContext(context.TODO(),
+		sql.WithSession(session),
+		sql.WithIndexRegistry(idxReg),
+		sql.WithViewRegistry(sql.NewViewRegistry())).WithCurrentDB("test")
+
+	_, _, err = e.Query(ctx, "CREATE USER bob")
+	require.True(sql.ErrUserManagementNotSupported.Is(err))
+}
+
+func TestNativeStorePrivileges(t *testing.T) {
+	require := require.New(t)
+	a, store := nativeStore()
+
+	e, idxReg, err := authEngine(a)
+	require.NoError(err)
+
+	query := func(user, q string) error {
+		session := sql.NewSession("localhost", "127.0.0.1:3306", user, 1)
+		ctx := sql.NewContext(context.TODO(),
+			sql.WithSession(session),
+			sql.WithIndexRegistry(idxReg),
+			sql.WithViewRegistry(sql.NewViewRegistry())).WithCurrentDB("test")
+
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return err
+		}
+		_, err = sql.RowIterToRows(iter)
+		return err
+	}
+
+	require.NoError(query("root", "CREATE USER bob, carol"))
+
+	// New users have no privileges.
+	require.True(sql.ErrTableAccessDenied.Is(query("bob", queries["select"])))
+	require.NoError(query("bob", "SELECT 1"))
+
+	require.NoError(query("root", "GRANT SELECT ON test TO bob"))
+	require.NoError(query("bob", queries["select"]))
+	require.NoError(query("bob", "SELECT * FROM test WHERE id IN (SELECT id FROM test.test)"))
+	require.True(auth.ErrNotAuthorized.Is(query("bob", queries["insert"])))
+
+	require.NoError(query("root", "GRANT INSERT ON test.* TO bob"))
+	require.NoError(query("bob", queries["insert"]))
+
+	err = query("bob", "DELETE FROM test")
+	require.True(sql.ErrTableAccessDenied.Is(err))
+	require.Contains(err.Error(), "DELETE command denied")
+
+	err = query("bob", queries["create_index"])
+	require.True(sql.ErrTableAccessDenied.Is(err))
+
+	// Trigger bodies need the privileges of their definer.
+	require.NoError(query("root", "GRANT TRIGGER ON test.* TO bob"))
+	err = query("bob", "CREATE TRIGGER trg AFTER INSERT ON test FOR EACH ROW DELETE FROM test WHERE id = new.name")
+	require.True(sql.ErrTableAccessDenied.Is(err))
+	require.Contains(err.Error(), "DELETE command denied")
+
+	err = query("bob", "ALTER TABLE test ADD CONSTRAINT chk CHECK (id <> '')")
+	require.True(sql.ErrTableAccessDenied.Is(err))
+	require.Contains(err.Error(), "ALTER command denied")
+
+	// Privileges can only be granted with GRANT OPTION.
+	err = query("bob", "GRANT SELECT ON test.test TO carol")
+	require.True(sql.ErrTableAccessDenied.Is(err))
+
+	err = query("bob", "CREATE USER dave")
+	require.True(sql.ErrSpecificAccessDenied.Is(err))
+
+	require.NoError(query("root", "REVOKE SELECT ON test.test FROM bob"))
+	err = query("bob", queries["select"])
+	require.True(sql.ErrTableAccessDenied.Is(err))
+	require.Contains(err.Error(), "SELECT command denied to user 'bob'@'%' for table 'test'")
+
+	err = query("root", "REVOKE SELECT ON test.test FROM bob")
+	require.True(sql.ErrNonexistingGrant.Is(err))
+
+	err = query("root", "GRANT LOCK TABLES ON test.test TO bob")
+	require.True(sql.ErrIllegalGrantForLevel.Is(err))
+
+	err = query("root", "GRANT SELECT ON *.* TO nobody")
+	require.True(sql.ErrUserNotFound.Is(err))
+
+	require.NoError(query("root", "GRANT ALL ON test.* TO carol WITH GRANT OPTION"))
+	require.NoError(query("carol", "GRANT SELECT ON test.test TO bob"))
+	require.NoError(query("bob", queries["select"]))
+
+	require.NoError(query("root", "REVOKE ALL PRIVILEGES, GRANT OPTION FROM bob"))
+	require.True(sql.ErrTableAccessDenied.Is(query("bob", queries["select"])))
+
+	users, err := store.Users(context.TODO())
+	require.NoError(err)
+
+	var grants = make(map[string]sql.PrivilegeSet)
+	for _, u := range users {
+		grants[u.Account().String()] = u.Privileges()
+	}
+
+	require.Empty(grants["'bob'@'%'"])
+	require.Equal(sql.PrivilegeSet{
+		{Level: sql.PrivilegeLevel{Database: "test"}, Privileges: sql.DatabasePrivileges},
+	}, grants["'carol'@'%'"])
+}
+
+func TestNativeStoreRoles(t *testing.T) {
+	require := require.New(t)
+	a, _ := nativeStore()
+
+	e, idxReg, err := authEngine(a)
+	require.NoError(err)
+
+	var sessions = make(map[string]sql.Session)
+	query := func(user, q string) error {
+		session, ok := sessions[user]
+		if !ok {
+			session = sql.NewSession("localhost", "127.0.0.1:3306", user, uint32(len(sessions)+1))
+			sessions[user] = session
+		}
+
+		ctx := sql.NewContext(context.TODO(),
+			sql.WithSession(session),
+			sql.WithIndexRegistry(idxReg),
+			sql.WithViewRegistry(sql.NewViewRegistry())).WithCurrentDB("test")
+
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return err
+		}
+		_, err = sql.RowIterToRows(iter)
+		return err
+	}
+
+	require.NoError(query("root", "CREATE ROLE app_read, app_write, app_admin"))
+	require.NoError(query("root", "GRANT SELECT ON test.* TO app_read"))
+	require.NoError(query("root", "GRANT INSERT ON test.test TO app_write"))
+	require.NoError(query("root", "GRANT app_read, app_write TO app_admin"))
+	require.NoError(query("root", "CREATE USER bob, carol"))
+	require.NoError(query("root", "GRANT app_read TO bob"))
+	require.NoError(query("root", "GRANT app_admin TO carol WITH ADMIN OPTION"))
+
+	// Roles can't log in.
+	testAuthentication(t, a, []authenticationTest{{"app_read", "", false}}, nil)
+
+	err = query("root", "GRANT app_admin TO app_read")
+	require.True(sql.ErrRoleGrantLoop.Is(err))
+
+	// Granted roles are not active until set.
+	require.True(sql.ErrTableAccessDenied.Is(query("bob", queries["select"])))
+	require.NoError(query("bob", "SET ROLE app_read"))
+	require.NoError(query("bob", queries["select"]))
+	require.True(auth.ErrNotAuthorized.Is(query("bob", queries["insert"])))
+
+	err = query("bob", "SET ROLE app_write")
+	require.True(sql.ErrRoleNotGranted.Is(err))
+
+	require.NoError(query("bob", "SET ROLE NONE"))
+	require.True(sql.ErrTableAccessDenied.Is(query("bob", queries["select"])))
+
+	// Privileges of roles granted to active roles are included.
+	require.NoError(query("carol", "SET ROLE ALL"))
+	require.NoError(query("carol", queries["select"]))
+	require.NoError(query("carol", queries["insert"]))
+
+	require.NoError(query("carol", "SET ROLE ALL EXCEPT app_admin"))
+	require.True(sql.ErrTableAccessDenied.Is(query("carol", queries["select"])))
+
+	// Roles can be granted by accounts with ADMIN OPTION on them.
+	require.NoError(query("carol", "GRANT app_admin TO bob"))
+	err = query("bob", "GRANT app_read TO carol")
+	require.True(sql.ErrSpecificAccessDenied.Is(err))
+
+	// Default roles are active in new sessions.
+	require.NoError(query("bob", "SET DEFAULT ROLE app_admin TO bob"))
+	delete(sessions, "bob")
+	require.NoError(query("bob", queries["insert"]))
+
+	err = query("bob", "SET DEFAULT ROLE app_read TO carol")
+	require.True(sql.ErrSpecificAccessDenied.Is(err))
+
+	require.NoError(query("root", "REVOKE app_admin FROM bob"))
+	require.True(auth.ErrNotAuthorized.Is(query("bob", queries["insert"])))
+
+	err = query("root", "REVOKE app_admin FROM bob")
+	require.True(sql.ErrRoleNotGranted.Is(err))
+
+	require.NoError(query("root", "DROP ROLE app_admin"))
+	require.NoError(query("carol", "SET ROLE ALL"))
+	require.True(sql.ErrTableAccessDenied.Is(query("carol", queries["select"])))
+}
+
+func TestNativeStoreProxies(t *testing.T) {
+	require := require.New(t)
+	a, _ := nativeStore()
+
+	e, idxReg, err := authEngine(a)
+	require.NoError(err)
+
+	query := func(client sql.Client, q string) ([]sql.Row, error) {
+		ctx := sql.NewContext(context.TODO(),
+			sql.WithSession(sql.NewSessionWithClient("localhost", client, 1)),
+			sql.WithIndexRegistry(idxReg),
+			sql.WithViewRegistry(sql.NewViewRegistry())).WithCurrentDB("test")
+
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+
+	root := sql.Client{User: "root", Address: "127.0.0.1:3306"}
+	_, err = query(root, "CREATE USER middleware IDENTIFIED BY 'password', bob, carol")
+	require.NoError(err)
+	_, err = query(root, "GRANT PROXY ON bob TO middleware")
+	require.NoError(err)
+	_, err = query(root, "GRANT SELECT ON test.* TO bob")
+	require.NoError(err)
+
+	testAuthentication(t, a, []authenticationTest{
+		{"middleware[bob]", "password", true},
+		{"middleware[bob]", "", false},
+		{"middleware[carol]", "password", false},
+		{"bob[middleware]", "", false},
+	}, nil)
+
+	// The client acts as bob, with its privileges.
+	proxy := sql.Client{User: "bob", ProxyUser: "middleware", Address: "127.0.0.1:3306"}
+	rows, err := query(proxy, "SELECT USER(), CURRENT_USER()")
+	require.NoError(err)
+	require.Equal([]sql.Row{{"middleware@127.0.0.1", "bob@%"}}, rows)
+
+	_, err = query(proxy, queries["select"])
+	require.NoError(err)
+
+	// Only accounts with PROXY WITH GRANT OPTION on an account can grant it.
+	_, err = query(proxy, "GRANT PROXY ON bob TO carol")
+	require.True(sql.ErrSpecificAccessDenied.Is(err))
+	_, err = query(root, "GRANT PROXY ON bob TO carol WITH GRANT OPTION")
+	require.NoError(err)
+	_, err = query(sql.Client{User: "carol", Address: "127.0.0.1:3306"}, "GRANT PROXY ON bob TO middleware")
+	require.NoError(err)
+
+	grants, err := a.Proxies(sql.NewEmptyContext(), sql.Account{Name: "carol", Host: "%"})
+	require.NoError(err)
+	require.Equal([]sql.ProxyGrant{{Proxied: sql.Account{Name: "bob", Host: "%"}, WithGrantOption: true}}, grants)
+
+	// Revoking PROXY takes effect on open sessions.
+	_, err = query(root, "REVOKE PROXY ON bob FROM middleware")
+	require.NoError(err)
+	_, err = query(proxy, queries["select"])
+	require.True(auth.ErrNotAuthorized.Is(err))
+
+	_, err = query(root, "REVOKE PROXY ON bob FROM middleware")
+	require.True(sql.ErrNonexistingGrant.Is(err))
+
+	// Dropping the account proxied revokes it.
+	_, err = query(root, "DROP USER bob")
+	require.NoError(err)
+	grants, err = a.Proxies(sql.NewEmptyContext(), sql.Account{Name: "carol", Host: "%"})
+	require.NoError(err)
+	require.Empty(grants)
+}
+
+func TestNativeStoreShowGrants(t *testing.T) {
+	require := require.New(t)
+	a, _ := nativeStore()
+
+	e, idxReg, err := authEngine(a)
+	require.NoError(err)
+
+	query := func(client sql.Client, q string) ([]sql.Row, error) {
+		ctx := sql.NewContext(context.TODO(),
+			sql.WithSession(sql.NewSessionWithClient("localhost", client, 1)),
+			sql.WithIndexRegistry(idxReg),
+			sql.WithViewRegistry(sql.NewViewRegistry())).WithCurrentDB("test")
+
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+
+	root := sql.Client{User: "root", Address: "127.0.0.1:3306"}
+	for _, q := range []string{
+		"CREATE ROLE app_read",
+		"GRANT SELECT ON test.* TO app_read",
+		"CREATE USER bob, middleware",
+		"GRANT ALL ON test.* TO bob WITH GRANT OPTION",
+		"GRANT INSERT, SELECT (name) ON test.test TO bob",
+		"GRANT PROCESS ON *.* TO bob",
+		"GRANT app_read TO bob",
+		"GRANT PROXY ON bob TO middleware",
+	} {
+		_, err := query(root, q)
+		require.NoError(err, q)
+	}
+
+	bob := sql.Client{User: "bob", Address: "127.0.0.1:3306"}
+	rows, err := query(bob, "SHOW GRANTS")
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"GRANT PROCESS ON *.* TO `bob`@`%`"},
+		{"GRANT ALL PRIVILEGES ON `test`.* TO `bob`@`%` WITH GRANT OPTION"},
+		{"GRANT INSERT, SELECT (`name`) ON `test`.`test` TO `bob`@`%`"},
+		{"GRANT `app_read`@`%` TO `bob`@`%`"},
+	}, rows)
+
+	rows, err = query(root, "SHOW GRANTS FOR app_read")
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"GRANT USAGE ON *.* TO `app_read`@`%`"},
+		{"GRANT SELECT ON `test`.* TO `app_read`@`%`"},
+	}, rows)
+
+	rows, err = query(root, "SHOW GRANTS FOR middleware")
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"GRANT USAGE ON *.* TO `middleware`@`%`"},
+		{"GRANT PROXY ON `bob`@`%` TO `middleware`@`%`"},
+	}, rows)
+
+	// USING shows the privileges of roles as if they were active. REVOKE ALL
+	// leaves GRANT OPTION, as in MySQL.
+	_, err = query(root, "REVOKE ALL ON test.* FROM bob")
+	require.NoError(err)
+	rows, err = query(bob, "SHOW GRANTS FOR CURRENT_USER() USING app_read")
+	require.NoError(err)
+	require.Equal(sql.Row{"GRANT SELECT ON `test`.* TO `bob`@`%` WITH GRANT OPTION"}, rows[1])
+
+	_, err = query(root, "SHOW GRANTS FOR middleware USING app_read")
+	require.True(sql.ErrRoleNotGranted.Is(err))
+
+	// Only accounts that can manage users see the grants of others.
+	_, err = query(bob, "SHOW GRANTS FOR middleware")
+	require.True(sql.ErrSpecificAccessDenied.Is(err))
+
+	_, err = query(root, "SHOW GRANTS FOR nobody")
+	require.True(sql.ErrNonexistingGrant.Is(err))
+
+	// Proxied clients act as the account they were granted PROXY on, which
+	// is the one CURRENT_USER() and CURRENT_ROLE() are about.
+	proxy := sql.NewSessionWithClient("localhost", sql.Client{User: "bob", ProxyUser: "middleware", Address: "127.0.0.1:3306"}, 2)
+	ctx := sql.NewContext(context.TODO(),
+		sql.WithSession(proxy),
+		sql.WithIndexRegistry(idxReg),
+		sql.WithViewRegistry(sql.NewViewRegistry())).WithCurrentDB("test")
+	_, iter, err := e.Query(ctx, "SET ROLE app_read")
+	require.NoError(err)
+	_, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+
+	_, iter, err = e.Query(ctx, "SELECT USER(), CURRENT_USER(), CURRENT_ROLE()")
+	require.NoError(err)
+	rows, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{{"middleware@127.0.0.1", "bob@%", "`app_read`@`%`"}}, rows)
+}
+
+func TestNativeStoreConnectionControl(t *testing.T) {
+	require := require.New(t)
+	a, _ := nativeStore()
+	a.SetConnectionControl(auth.ConnectionControl{
+		FailedConnectionsThreshold: 2,
+		MaxDelay:                   10 * time.Millisecond,
+		FailedLoginAttempts:        3,
+		LockTime:                   500 * time.Millisecond,
+	})
+
+	// The third attempt is delayed, and the account is locked after it.
+	testAuthentication(t, a, []authenticationTest{
+		{"root", "other", false},
+		{"root", "other", false},
+		{"root", "other", false},
+		{"root", "password", false},
+		{"user", "local", true},
+	}, nil)
+
+	time.Sleep(500 * time.Millisecond)
+	testAuthentication(t, a, []authenticationTest{{"root", "password", true}}, nil)
+
+	e, idxReg, err := authEngine(a)
+	require.NoError(err)
+
+	ctx := sql.NewContext(context.TODO(),
+		sql.WithSession(sql.NewSession("localhost", "127.0.0.1:3306", "root", 1)),
+		sql.WithIndexRegistry(idxReg),
+		sql.WithViewRegistry(sql.NewViewRegistry())).WithCurrentDB("test")
+
+	_, iter, err := e.Query(ctx, "SHOW GLOBAL STATUS LIKE '%connect%'")
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"Aborted_connects", "3"},
+		{"Connection_control_delay_generated", "1"},
+		{"Connection_errors_max_connections", "0"},
+		{"Connections", "0"},
+		{"Locked_connects", "1"},
+		{"Max_used_connections", "0"},
+		{"Threads_connected", "0"},
+	}, rows)
+}
+
+func TestNativeStorePasswords(t *testing.T) {
+	require := require.New(t)
+	a, store := nativeStore()
+	a.SetPasswordPolicy(auth.PasswordPolicy{
+		MinLength:        8,
+		MixedCaseCount:   1,
+		NumberCount:      1,
+		SpecialCharCount: 1,
+		Dictionary:       []string{"secret"},
+	})
+
+	e, idxReg, err := authEngine(a)
+	require.NoError(err)
+
+	query := func(user, q string) error {
+		ctx := sql.NewContext(context.TODO(),
+			sql.WithSession(sql.NewSession("localhost", "127.0.0.1:3306", user, 1)),
+			sql.WithIndexRegistry(idxReg),
+			sql.WithViewRegistry(sql.NewViewRegistry())).WithCurrentDB("test")
+
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return err
+		}
+		_, err = sql.RowIterToRows(iter)
+		return err
+	}
+
+	for _, password := range []string{"", "Sh0rt!", "lowercase1!", "NoDigits!", "N0Special", "MySecret1!"} {
+		err := query("root", "CREATE USER bob IDENTIFIED BY '"+password+"'")
+		require.True(sql.ErrPasswordPolicy.Is(err), password)
+	}
+
+	require.NoError(query("root", "CREATE USER bob IDENTIFIED BY 'B0b-pass'"))
+	require.NoError(query("root", "CREATE USER carol IDENTIFIED BY 'C4rol-pass' PASSWORD EXPIRE"))
+	require.NoError(query("root", "GRANT SELECT ON test.* TO bob, carol"))
+	require.NoError(query("bob", queries["select"]))
+
+	// Expired passwords must be changed before running other statements.
+	require.True(sql.ErrMustChangePassword.Is(query("carol", queries["select"])))
+	require.NoError(query("carol", "SET autocommit = 1"))
+	require.True(sql.ErrPasswordPolicy.Is(query("carol", "SET PASSWORD = 'weak'")))
+	require.NoError(query("carol", "SET PASSWORD = 'N3w-pass'"))
+	require.NoError(query("carol", queries["select"]))
+
+	require.NoError(query("root", "ALTER USER bob PASSWORD EXPIRE"))
+	require.True(sql.ErrMustChangePassword.Is(query("bob", queries["select"])))
+	require.NoError(query("bob", "SET PASSWORD = 'B0b-pass2'"))
+	require.NoError(query("bob", queries["select"]))
+
+	// Passwords expire after their lifetime.
+	users, err := store.Users(context.Background())
+	require.NoError(err)
+	for _, u := range users {
+		if u.Name == "bob" {
+			u.PasswordChanged = time.Now().Add(-48 * time.Hour)
+			require.NoError(store.SaveUser(context.Background(), u))
+		}
+	}
+	require.NoError(query("bob", queries["select"]))
+
+	require.NoError(query("root", "ALTER USER bob PASSWORD EXPIRE INTERVAL 1 DAY"))
+	require.True(sql.ErrMustChangePassword.Is(query("bob", queries["select"])))
+
+	require.NoError(query("root", "ALTER USER bob PASSWORD EXPIRE NEVER"))
+	require.NoError(query("bob", queries["select"]))
+
+	a.SetPasswordPolicy(auth.PasswordPolicy{Lifetime: 1})
+	require.NoError(query("root", "ALTER USER bob PASSWORD EXPIRE DEFAULT"))
+	require.True(sql.ErrMustChangePassword.Is(query("bob", queries["select"])))
+
+	err = query("root", "ALTER USER dave PASSWORD EXPIRE")
+	require.True(sql.ErrUserOperationFailed.Is(err))
+	require.NoError(query("root", "ALTER USER IF EXISTS dave PASSWORD EXPIRE"))
+}
+
+func TestNativeStoreResourceLimits(t *testing.T) {
+	require := require.New(t)
+	a, _ := nativeStore()
+
+	e, idxReg, err := authEngine(a)
+	require.NoError(err)
+
+	query := func(q string) error {
+		ctx := sql.NewContext(context.TODO(),
+			sql.WithSession(sql.NewSession("localhost", "127.0.0.1:3306", "root", 1)),
+			sql.WithIndexRegistry(idxReg),
+			sql.WithViewRegistry(sql.NewViewRegistry())).WithCurrentDB("test")
+
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return err
+		}
+		_, err = sql.RowIterToRows(iter)
+		return err
+	}
+
+	limit := func(user string) int {
+		account, n, err := a.ConnectionLimit(auth.Connection{User: user, Address: "127.0.0.1:34567"})
+		require.NoError(err)
+		require.Equal(user, account.Name)
+		return n
+	}
+
+	require.NoError(query("CREATE USER bob WITH MAX_USER_CONNECTIONS 2"))
+	require.NoError(query("CREATE USER carol"))
+	require.Equal(2, limit("bob"))
+	require.Equal(0, limit("carol"))
+
+	require.NoError(query("ALTER USER bob, carol WITH MAX_USER_CONNECTIONS 5"))
+	require.Equal(5, limit("bob"))
+	require.Equal(5, limit("carol"))
+
+	require.NoError(query("ALTER USER carol WITH MAX_USER_CONNECTIONS 0"))
+	require.Equal(0, limit("carol"))
+
+	err = query("ALTER USER dave WITH MAX_USER_CONNECTIONS 1")
+	require.True(sql.ErrUserOperationFailed.Is(err))
+
+	_, _, err = a.ConnectionLimit(auth.Connection{User: "dave", Address: "127.0.0.1:34567"})
+	require.Error(err)
+}
+
+func TestNativeStoreColumnPrivileges(t *testing.T) {
+	require := require.New(t)
+	a, _ := nativeStore()
+
+	e, idxReg, err := authEngine(a)
+	require.NoError(err)
+
+	query := func(user, q string) (sql.Schema, []sql.Row, error) {
+		session := sql.NewSession("localhost", "127.0.0.1:3306", user, 1)
+		ctx := sql.NewContext(context.TODO(),
+			sql.WithSession(session),
+			sql.WithIndexRegistry(idxReg),
+			sql.WithViewRegistry(sql.NewViewRegistry())).WithCurrentDB("test")
+
+		schema, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows, err := sql.RowIterToRows(iter)
+		return schema, rows, err
+	}
+
+	exec := func(user, q string) error {
+		_, _, err := query(user, q)
+		return err
+	}
+
+	require.NoError(exec("root", "CREATE USER bob"))
+	require.NoError(exec("root", "INSERT INTO test VALUES ('1', 'one')"))
+	require.NoError(exec("root", "GRANT SELECT (id), INSERT (id), UPDATE (name) ON test.test TO bob"))
+
+	// Columns that can't be read are left out of stars.
+	for _, q := range []string{"SELECT * FROM test", "SELECT t.* FROM test t", "SELECT * FROM (SELECT * FROM test) t"} {
+		schema, rows, err := query("bob", q)
+		require.NoError(err, q)
+		require.Len(schema, 1, q)
+		require.Equal("id", schema[0].Name, q)
+		require.Equal([]sql.Row{{"1"}}, rows, q)
+	}
+
+	_, _, err = query("bob", "SELECT COUNT(*) FROM test WHERE id = '1'")
+	require.NoError(err)
+
+	for _, q := range []string{
+		"SELECT name FROM test",
+		"SELECT id FROM test WHERE name = 'one'",
+		"SELECT t.name FROM test t",
+		"SELECT id FROM test WHERE id IN (SELECT name FROM test)",
+	} {
+		err = exec("bob", q)
+		require.True(sql.ErrColumnAccessDenied.Is(err), q)
+		require.Contains(err.Error(), "SELECT command denied to user 'bob'@'%' for column 'name' in table 'test'")
+	}
+
+	err = exec("bob", "INSERT INTO test (id, name) VALUES ('2', 'two')")
+	require.True(sql.ErrColumnAccessDenied.Is(err))
+	require.Contains(err.Error(), "INSERT command denied")
+
+	require.NoError(exec("root", "GRANT INSERT (name) ON test.test TO bob"))
+	require.NoError(exec("bob", "INSERT INTO test (id, name) VALUES ('2', 'two')"))
+
+	require.NoError(exec("bob", "UPDATE test SET name = 'dos' WHERE id = '2'"))
+	err = exec("bob", "UPDATE test SET id = '3' WHERE id = '2'")
+	require.True(sql.ErrColumnAccessDenied.Is(err))
+	require.Contains(err.Error(), "UPDATE command denied")
+
+	err = exec("bob", "DELETE FROM test")
+	require.True(sql.ErrTableAccessDenied.Is(err))
+
+	err = exec("root", "GRANT DELETE (id) ON test.test TO bob")
+	require.True(sql.ErrIllegalGrantForLevel.Is(err))
+
+	err = exec("root", "GRANT SELECT (id) ON test.* TO bob")
+	require.True(sql.ErrIllegalGrantForLevel.Is(err))
+
+	require.NoError(exec("root", "REVOKE SELECT (id) ON test.test FROM bob"))
+	err = exec("bob", "SELECT * FROM test")
+	require.True(sql.ErrTableAccessDenied.Is(err))
+}
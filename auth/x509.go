@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"net"
+	"strings"
+
+	"github.com/dolthub/vitess/go/mysql"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// attributeNames are the short names of the attributes of distinguished
+// names, as written by MySQL.
+var attributeNames = map[string]string{
+	"2.5.4.3":                    "CN",
+	"2.5.4.5":                    "serialNumber",
+	"2.5.4.6":                    "C",
+	"2.5.4.7":                    "L",
+	"2.5.4.8":                    "ST",
+	"2.5.4.9":                    "street",
+	"2.5.4.10":                   "O",
+	"2.5.4.11":                   "OU",
+	"2.5.4.17":                   "postalCode",
+	"1.2.840.113549.1.9.1":       "emailAddress",
+	"0.9.2342.19200300.100.1.25": "DC",
+}
+
+// DistinguishedName returns the DER encoded distinguished name given, e.g. the
+// RawSubject or RawIssuer of a certificate, in the /C=.../O=.../CN=... form
+// MySQL uses for REQUIRE SUBJECT and REQUIRE ISSUER.
+func DistinguishedName(raw []byte) string {
+	var rdns pkix.RDNSequence
+	if rest, err := asn1.Unmarshal(raw, &rdns); err != nil || len(rest) > 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, rdn := range rdns {
+		for _, atv := range rdn {
+			name, ok := attributeNames[atv.Type.String()]
+			if !ok {
+				name = atv.Type.String()
+			}
+
+			b.WriteString("/")
+			b.WriteString(name)
+			b.WriteString("=")
+			if s, ok := atv.Value.(string); ok {
+				b.WriteString(s)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// meetsTLSRequirement returns whether the connection given satisfies the
+// transport requirements of an account. Client certificates are verified by
+// the server against its certificate authorities during the TLS handshake, so
+// any certificate presented is valid.
+func meetsTLSRequirement(req sql.TLSRequirement, conn Connection) bool {
+	if (req.SSL || req.X509) && !conn.Secure {
+		return false
+	}
+
+	if !req.X509 && req.Subject == "" && req.Issuer == "" {
+		return true
+	}
+
+	if len(conn.PeerCertificates) == 0 {
+		return false
+	}
+
+	cert := conn.PeerCertificates[0]
+	if req.Subject != "" && DistinguishedName(cert.RawSubject) != req.Subject {
+		return false
+	}
+
+	if req.Issuer != "" && DistinguishedName(cert.RawIssuer) != req.Issuer {
+		return false
+	}
+
+	return true
+}
+
+// X509User maps client certificates to a user.
+type X509User struct {
+	// Name of the user.
+	Name string
+	// Subject, if not empty, must be the subject of the certificate, in the
+	// /C=.../O=.../CN=... form.
+	Subject string
+	// SAN, if not empty, must be one of the DNS names, email addresses, IP
+	// addresses or URIs in the subject alternative names of the certificate.
+	SAN string
+	// Permissions granted to the user.
+	Permissions Permission
+}
+
+func (u X509User) matches(cert *x509.Certificate) bool {
+	if u.Subject != "" && DistinguishedName(cert.RawSubject) != u.Subject {
+		return false
+	}
+
+	if u.SAN == "" {
+		return u.Subject != ""
+	}
+
+	for _, name := range cert.DNSNames {
+		if strings.EqualFold(name, u.SAN) {
+			return true
+		}
+	}
+
+	for _, email := range cert.EmailAddresses {
+		if strings.EqualFold(email, u.SAN) {
+			return true
+		}
+	}
+
+	if ip := net.ParseIP(u.SAN); ip != nil {
+		for _, addr := range cert.IPAddresses {
+			if addr.Equal(ip) {
+				return true
+			}
+		}
+	}
+
+	for _, uri := range cert.URIs {
+		if uri.String() == u.SAN {
+			return true
+		}
+	}
+
+	return false
+}
+
+// X509 authenticates users by the client certificate they connect with, for
+// deployments where clients use mutual TLS. Passwords are ignored: a user
+// can connect only if it presents a certificate mapped to it, so the server
+// must be configured with TLS and a CA to verify client certificates
+// against.
+type X509 struct {
+	users map[string]X509User
+}
+
+var _ Auth = (*X509)(nil)
+var _ ConnectionChecker = (*X509)(nil)
+
+// NewX509 creates an X509 authentication method with the users given. Users
+// must have a subject, a SAN or both.
+func NewX509(users ...X509User) (*X509, error) {
+	var m = make(map[string]X509User)
+	for _, u := range users {
+		if _, ok := m[u.Name]; ok {
+			return nil, ErrDuplicateUser.New(u.Name)
+		}
+		m[u.Name] = u
+	}
+
+	return &X509{m}, nil
+}
+
+// Mysql implements Auth interface. Any password is accepted for known users,
+// since the certificate is checked by CheckConnection once the TLS
+// connection is established.
+func (a *X509) Mysql() mysql.AuthServer {
+	return &x509AuthServer{a}
+}
+
+// CheckConnection implements the ConnectionChecker interface.
+func (a *X509) CheckConnection(conn Connection) error {
+	u, ok := a.users[conn.User]
+	if !ok || !conn.Secure || len(conn.PeerCertificates) == 0 || !u.matches(conn.PeerCertificates[0]) {
+		return mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", conn.User)
+	}
+
+	return nil
+}
+
+// Allowed implements Auth interface.
+func (a *X509) Allowed(ctx *sql.Context, permission Permission) error {
+	u, ok := a.users[ctx.Client().User]
+	if !ok {
+		return ErrNotAuthorized.Wrap(ErrNoPermission.New(permission))
+	}
+
+	return nativeUser{Name: u.Name, Permissions: u.Permissions}.Allowed(permission)
+}
+
+type x509AuthServer struct {
+	a *X509
+}
+
+var _ mysql.AuthServer = (*x509AuthServer)(nil)
+
+// AuthMethod implements the mysql.AuthServer interface.
+func (s *x509AuthServer) AuthMethod(string) (string, error) {
+	return mysql.MysqlNativePassword, nil
+}
+
+// Salt implements the mysql.AuthServer interface.
+func (s *x509AuthServer) Salt() ([]byte, error) {
+	return mysql.NewSalt()
+}
+
+// ValidateHash implements the mysql.AuthServer interface.
+func (s *x509AuthServer) ValidateHash(_ []byte, user string, _ []byte, _ net.Addr) (mysql.Getter, error) {
+	if _, ok := s.a.users[user]; !ok {
+		return nil, mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", user)
+	}
+
+	return nativeStoreUserData{user}, nil
+}
+
+// Negotiate implements the mysql.AuthServer interface. It is never called,
+// since mysql_native_password is always used.
+func (s *x509AuthServer) Negotiate(_ *mysql.Conn, user string, _ net.Addr) (mysql.Getter, error) {
+	return nil, mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", user)
+}
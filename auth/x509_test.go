@@ -0,0 +1,140 @@
+package auth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func certificate(t *testing.T, subject, issuer pkix.Name, dnsNames []string, uris ...string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var parsed []*url.URL
+	for _, u := range uris {
+		p, err := url.Parse(u)
+		require.NoError(t, err)
+		parsed = append(parsed, p)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      subject,
+		DNSNames:     dnsNames,
+		URIs:         parsed,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	parent := &x509.Certificate{SerialNumber: big.NewInt(2), Subject: issuer}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+var (
+	caName  = pkix.Name{Country: []string{"ES"}, Organization: []string{"Acme"}, CommonName: "Acme CA"}
+	bobName = pkix.Name{Country: []string{"ES"}, Organization: []string{"Acme"}, OrganizationalUnit: []string{"Eng"}, CommonName: "bob"}
+)
+
+func TestDistinguishedName(t *testing.T) {
+	cert := certificate(t, bobName, caName, nil)
+	require.Equal(t, "/C=ES/O=Acme/OU=Eng/CN=bob", auth.DistinguishedName(cert.RawSubject))
+	require.Equal(t, "/C=ES/O=Acme/CN=Acme CA", auth.DistinguishedName(cert.RawIssuer))
+	require.Equal(t, "", auth.DistinguishedName([]byte("nope")))
+}
+
+func TestNativeStoreTLSRequirements(t *testing.T) {
+	bob := certificate(t, bobName, caName, nil)
+	other := certificate(t, pkix.Name{CommonName: "other"}, pkix.Name{CommonName: "Other CA"}, nil)
+
+	a := auth.NewNativeStore(auth.NewMemoryUserStore(
+		auth.User{Name: "none", Host: "%"},
+		auth.User{Name: "ssl", Host: "%", Require: sql.TLSRequirement{SSL: true}},
+		auth.User{Name: "x509", Host: "%", Require: sql.TLSRequirement{SSL: true, X509: true}},
+		auth.User{Name: "subject", Host: "%", Require: sql.TLSRequirement{SSL: true, X509: true, Subject: "/C=ES/O=Acme/OU=Eng/CN=bob"}},
+		auth.User{Name: "issuer", Host: "%", Require: sql.TLSRequirement{SSL: true, X509: true, Issuer: "/C=ES/O=Acme/CN=Acme CA"}},
+	))
+
+	testCases := []struct {
+		user    string
+		secure  bool
+		certs   []*x509.Certificate
+		success bool
+	}{
+		{"none", false, nil, true},
+		{"ssl", false, nil, false},
+		{"ssl", true, nil, true},
+		{"x509", true, nil, false},
+		{"x509", true, []*x509.Certificate{other}, true},
+		{"subject", true, []*x509.Certificate{bob}, true},
+		{"subject", true, []*x509.Certificate{other}, false},
+		{"issuer", true, []*x509.Certificate{bob}, true},
+		{"issuer", true, []*x509.Certificate{other}, false},
+		{"unknown", true, []*x509.Certificate{bob}, false},
+	}
+
+	for _, tt := range testCases {
+		err := a.CheckConnection(auth.Connection{
+			User:             tt.user,
+			Address:          "127.0.0.1:1234",
+			Secure:           tt.secure,
+			PeerCertificates: tt.certs,
+		})
+		if tt.success {
+			require.NoError(t, err, tt.user)
+		} else {
+			require.Error(t, err, tt.user)
+		}
+	}
+}
+
+func TestX509(t *testing.T) {
+	require := require.New(t)
+
+	service := certificate(t, pkix.Name{CommonName: "svc"}, caName, []string{"svc.example.com"}, "spiffe://example.com/ns/default/sa/svc")
+	bob := certificate(t, bobName, caName, nil)
+
+	a, err := auth.NewX509(
+		auth.X509User{Name: "bob", Subject: "/C=ES/O=Acme/OU=Eng/CN=bob", Permissions: auth.AllPermissions},
+		auth.X509User{Name: "svc", SAN: "svc.example.com", Permissions: auth.ReadPerm},
+		auth.X509User{Name: "spiffe", SAN: "spiffe://example.com/ns/default/sa/svc", Permissions: auth.ReadPerm},
+	)
+	require.NoError(err)
+
+	conn := func(user string, certs ...*x509.Certificate) auth.Connection {
+		return auth.Connection{User: user, Secure: len(certs) > 0, PeerCertificates: certs}
+	}
+
+	require.NoError(a.CheckConnection(conn("bob", bob)))
+	require.Error(a.CheckConnection(conn("bob", service)))
+	require.Error(a.CheckConnection(conn("bob")))
+	require.NoError(a.CheckConnection(conn("svc", service)))
+	require.NoError(a.CheckConnection(conn("spiffe", service)))
+	require.Error(a.CheckConnection(conn("svc", bob)))
+	require.Error(a.CheckConnection(conn("unknown", bob)))
+
+	_, err = auth.NewX509(auth.X509User{Name: "bob"}, auth.X509User{Name: "bob"})
+	require.True(auth.ErrDuplicateUser.Is(err))
+
+	testAuthorization(t, a, []authorizationTest{
+		{"bob", queries["insert"], true},
+		{"svc", queries["select"], true},
+		{"svc", queries["insert"], false},
+		{"unknown", queries["select"], false},
+	}, nil)
+}
@@ -0,0 +1,349 @@
+package binlog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+var (
+	// ErrTableNotWritable is returned when the source changes the rows of a
+	// table the replica can't change.
+	ErrTableNotWritable = errors.NewKind("binary log: the rows of table %s.%s can't be changed")
+
+	// ErrTableMapMissing is returned when a rows event refers to a table
+	// that wasn't mapped before it.
+	ErrTableMapMissing = errors.NewKind("binary log: no table map for table id %d")
+
+	// ErrColumnsMismatch is returned when a table of the source doesn't have
+	// as many columns as the table of the replica.
+	ErrColumnsMismatch = errors.NewKind("binary log: table %s.%s has %d columns in the source and %d in the replica")
+)
+
+// applier applies the events of the binary log of a source, as they're
+// read from a connection to it.
+type applier struct {
+	r        *Replica
+	querier  Querier
+	checksum bool
+	format   mysql.BinlogFormat
+	tables   map[uint64]*mysql.TableMap
+
+	// gtid is the GTID of the transaction being applied, if the source
+	// writes them, and skip whether it was already applied.
+	gtid mysql.GTID
+	skip bool
+}
+
+// apply applies an event. The changes of each transaction are applied as
+// its events are read, and the transaction is only recorded as applied once
+// its last event is.
+func (a *applier) apply(ctx *sql.Context, data []byte) error {
+	ev := mysql.NewMysql56BinlogEvent(data)
+	if !ev.IsValid() {
+		return fmt.Errorf("binary log: invalid event of %d bytes", len(data))
+	}
+	typ, logPos := data[4], uint64(binary.LittleEndian.Uint32(data[13:]))
+
+	if ev.IsFormatDescription() {
+		f, err := ev.Format()
+		if err != nil {
+			return err
+		}
+		a.format = f
+		return a.r.described(binary.LittleEndian.Uint32(data[5:]))
+	}
+
+	if typ == eventRotate {
+		body := data[headerLength:]
+		if a.checksum {
+			body = body[:len(body)-checksumLength]
+		}
+		if len(body) < 8 {
+			return fmt.Errorf("binary log: invalid rotate event")
+		}
+		a.r.rotated(string(body[8:]), binary.LittleEndian.Uint64(body))
+		return nil
+	}
+
+	if a.format.IsZero() {
+		return fmt.Errorf("binary log: event of type %d before the format description event", typ)
+	}
+	ev, _, err := ev.StripChecksum(a.format)
+	if err != nil {
+		return err
+	}
+
+	// The heartbeats and artificial events aren't in the log, and don't
+	// move the position.
+	if logPos > 0 && typ != eventHeartbeat {
+		a.r.read(logPos)
+	}
+
+	switch {
+	case ev.IsGTID():
+		gtid, _, err := ev.GTID(a.format)
+		if err != nil {
+			return err
+		}
+		a.gtid, a.skip = gtid, a.r.began(gtid)
+	case ev.IsQuery():
+		q, err := ev.Query(a.format)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToUpper(q.SQL) {
+		case "BEGIN":
+			return nil
+		case "COMMIT":
+			return a.commit(logPos)
+		}
+
+		if !a.skip {
+			if err := a.query(ctx, q.Database, q.SQL); err != nil {
+				return err
+			}
+		}
+		// Statements are transactions of their own. The tables they change
+		// may be mapped to other definitions from now on.
+		a.tables = make(map[uint64]*mysql.TableMap)
+		return a.commit(logPos)
+	case ev.IsXID():
+		return a.commit(logPos)
+	case ev.IsTableMap():
+		tm, err := ev.TableMap(a.format)
+		if err != nil {
+			return err
+		}
+		a.tables[ev.TableID(a.format)] = tm
+	case ev.IsWriteRows(), ev.IsUpdateRows(), ev.IsDeleteRows():
+		if a.skip {
+			return nil
+		}
+		return a.rows(ctx, ev)
+	}
+	return nil
+}
+
+// commit records the transaction being applied as applied, ending at the
+// position given.
+func (a *applier) commit(logPos uint64) error {
+	if !a.skip {
+		a.r.committed(a.gtid, logPos)
+	}
+	a.gtid, a.skip = nil, false
+	return nil
+}
+
+// query runs a statement of the source, in the database it was run in.
+func (a *applier) query(ctx *sql.Context, db, query string) error {
+	ctx.SetCurrentDatabase(db)
+	_, iter, err := a.querier.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if _, err = iter.Next(); err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	if cerr := iter.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// rows applies the changes of a rows event to the rows of its table, with
+// the editors of the table.
+func (a *applier) rows(ctx *sql.Context, ev mysql.BinlogEvent) error {
+	id := ev.TableID(a.format)
+	tm, ok := a.tables[id]
+	if !ok {
+		return ErrTableMapMissing.New(id)
+	}
+
+	table, err := a.r.catalog.Table(ctx, tm.Database, tm.Name)
+	if err != nil {
+		return err
+	}
+	schema := table.Schema()
+	if len(schema) != len(tm.Types) {
+		return ErrColumnsMismatch.New(tm.Database, tm.Name, len(tm.Types), len(schema))
+	}
+
+	rows, err := ev.Rows(a.format, tm)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case ev.IsWriteRows():
+		t, ok := table.(sql.InsertableTable)
+		if !ok {
+			return ErrTableNotWritable.New(tm.Database, tm.Name)
+		}
+		inserter := t.Inserter(ctx)
+		for _, row := range rows.Rows {
+			after, err := decodeRow(tm, schema, rows.DataColumns, row.NullColumns, row.Data)
+			if err != nil {
+				_ = inserter.Close(ctx)
+				return err
+			}
+			if err := inserter.Insert(ctx, after); err != nil {
+				_ = inserter.Close(ctx)
+				return err
+			}
+		}
+		return inserter.Close(ctx)
+	case ev.IsUpdateRows():
+		t, ok := table.(sql.UpdatableTable)
+		if !ok {
+			return ErrTableNotWritable.New(tm.Database, tm.Name)
+		}
+		updater := t.Updater(ctx)
+		for _, row := range rows.Rows {
+			before, err := decodeRow(tm, schema, rows.IdentifyColumns, row.NullIdentifyColumns, row.Identify)
+			if err != nil {
+				_ = updater.Close(ctx)
+				return err
+			}
+			after, err := decodeRow(tm, schema, rows.DataColumns, row.NullColumns, row.Data)
+			if err != nil {
+				_ = updater.Close(ctx)
+				return err
+			}
+			if err := updater.Update(ctx, before, after); err != nil {
+				_ = updater.Close(ctx)
+				return err
+			}
+		}
+		return updater.Close(ctx)
+	default:
+		t, ok := table.(sql.DeletableTable)
+		if !ok {
+			return ErrTableNotWritable.New(tm.Database, tm.Name)
+		}
+		deleter := t.Deleter(ctx)
+		for _, row := range rows.Rows {
+			before, err := decodeRow(tm, schema, rows.IdentifyColumns, row.NullIdentifyColumns, row.Identify)
+			if err != nil {
+				_ = deleter.Close(ctx)
+				return err
+			}
+			if err := deleter.Delete(ctx, before); err != nil {
+				_ = deleter.Close(ctx)
+				return err
+			}
+		}
+		return deleter.Close(ctx)
+	}
+}
+
+// decodeRow decodes the image of a row, with the values of the columns set
+// in the bitmap of the columns, which must be all of them, as MySQL writes
+// them with binlog_row_image=FULL.
+func decodeRow(tm *mysql.TableMap, schema sql.Schema, columns, nulls mysql.Bitmap, data []byte) (sql.Row, error) {
+	if columns.BitCount() != len(schema) {
+		return nil, fmt.Errorf("binary log: image of %d columns of table %s.%s, which isn't FULL", columns.BitCount(), tm.Database, tm.Name)
+	}
+
+	row := make(sql.Row, len(schema))
+	pos := 0
+	for i, c := range schema {
+		if nulls.Bit(i) {
+			continue
+		}
+
+		v, n, err := decodeValue(c.Type, tm.Types[i], tm.Metadata[i], data, pos)
+		if err != nil {
+			return nil, err
+		}
+		row[i], err = c.Type.Convert(v)
+		if err != nil {
+			return nil, err
+		}
+		pos += n
+	}
+	return row, nil
+}
+
+// decodeValue decodes the value of a column at a position of the data of a
+// row image, and returns its length.
+func decodeValue(t sql.Type, typ byte, metadata uint16, data []byte, pos int) (interface{}, int, error) {
+	if typ == mysql.TypeJSON {
+		size := int(metadata)
+		if size < 1 || size > 4 || pos+size > len(data) {
+			return nil, 0, fmt.Errorf("binary log: invalid JSON value")
+		}
+
+		var n int
+		for i := size - 1; i >= 0; i-- {
+			n = n<<8 | int(data[pos+i])
+		}
+		if pos+size+n > len(data) {
+			return nil, 0, fmt.Errorf("binary log: invalid JSON value")
+		}
+
+		doc, err := unmarshalJSON(data[pos+size : pos+size+n])
+		if err != nil {
+			return nil, 0, err
+		}
+		return string(doc), size + n, nil
+	}
+
+	v, n, err := mysql.CellValue(data, pos, typ, metadata, t.Type())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	real := byte(metadata >> 8)
+	switch {
+	case typ == mysql.TypeBit:
+		var bits uint64
+		for _, b := range v.Raw() {
+			bits = bits<<8 | uint64(b)
+		}
+		return bits, n, nil
+	case typ == mysql.TypeEnum, typ == mysql.TypeString && real == realTypeEnum:
+		i, err := strconv.Atoi(v.ToString())
+		return i, n, err
+	case typ == mysql.TypeSet:
+		var bits uint64
+		for i, b := range v.Raw() {
+			bits |= uint64(b) << (8 * uint(i))
+		}
+		return bits, n, nil
+	case typ == mysql.TypeString && real == realTypeSet:
+		bits, err := sqltypes.ToUint64(v)
+		return bits, n, err
+	case typ == mysql.TypeNewDecimal:
+		// Decimals are padded with spaces.
+		return strings.Replace(v.ToString(), " ", "", -1), n, nil
+	case sqltypes.IsSigned(v.Type()):
+		i, err := sqltypes.ToInt64(v)
+		return i, n, err
+	case sqltypes.IsUnsigned(v.Type()):
+		u, err := sqltypes.ToUint64(v)
+		return u, n, err
+	case sqltypes.IsFloat(v.Type()):
+		f, err := sqltypes.ToFloat64(v)
+		return f, n, err
+	case sqltypes.IsBinary(v.Type()) || v.Type() == sqltypes.VarBinary:
+		return v.Raw(), n, nil
+	default:
+		return v.ToString(), n, nil
+	}
+}
@@ -0,0 +1,504 @@
+// Package binlog implements a binary log in the format of MySQL 8.0, so that
+// MySQL replicas can replicate the changes made to the databases of an
+// engine. The log keeps the changes in memory, with row-based events and
+// GTIDs: every statement that changes rows is written as a transaction of
+// its own, with the full images of the rows, and DDL statements as their
+// query. The server streams it to the replicas that ask for it with
+// COM_BINLOG_DUMP or COM_BINLOG_DUMP_GTID.
+package binlog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+var (
+	// ErrFileNotFound is returned when a replica asks for a file that isn't
+	// in the log.
+	ErrFileNotFound = errors.NewKind("Could not find first log file name in binary log index file")
+
+	// ErrInvalidPosition is returned when a replica asks for a position that
+	// isn't the one of an event.
+	ErrInvalidPosition = errors.NewKind("Client requested source to start replication from invalid position %d in %s")
+)
+
+const (
+	// DefaultMaxFileSize is the size of the files the log is rotated at by
+	// default, the default of max_binlog_size.
+	DefaultMaxFileSize = 1 << 30
+	// maxRowsEventSize is the size of the rows of a rows event at which the
+	// rows of a statement are split in another event.
+	maxRowsEventSize = 8 << 10
+)
+
+// Config of a Log.
+type Config struct {
+	// ServerID is the server_id written in the events, @@server_id if 0.
+	ServerID uint32
+	// ServerUUID is the UUID of the GTIDs of the transactions,
+	// @@server_uuid if empty.
+	ServerUUID string
+	// SystemVariables is the registry of the system variables of the
+	// engine the log is for, whose @@server_id and @@server_uuid are used
+	// by default. Their default values are used if it's nil.
+	SystemVariables *sql.SystemVariableRegistry
+	// MaxFileSize is the size a file is rotated at, DefaultMaxFileSize if 0.
+	MaxFileSize uint64
+	// MaxFiles is the number of files kept, the oldest ones being purged.
+	// All the files are kept if 0.
+	MaxFiles int
+}
+
+// Log is a binary log. A nil *Log writes nothing.
+type Log struct {
+	mu          sync.Mutex
+	serverID    uint32
+	sid         mysql.SID
+	maxFileSize uint64
+	maxFiles    int
+
+	files    []*file
+	next     int
+	executed mysql.Mysql56GTIDSet
+	sequence int64
+	xid      uint64
+
+	// tableIDs are the ids of the tables in the table map events, by
+	// database and name. They're forgotten on DDL, so that the tables get
+	// new ones once their definition may have changed.
+	tableIDs    map[string]uint64
+	nextTableID uint64
+
+	// changed is closed and replaced every time events are written, to wake
+	// up the dumps waiting for them.
+	changed chan struct{}
+}
+
+type file struct {
+	name   string
+	data   []byte
+	events []sql.BinaryLogEvent
+}
+
+var _ sql.BinaryLog = (*Log)(nil)
+
+// NewLog creates a Log, with its first file.
+func NewLog(cfg Config) (*Log, error) {
+	if cfg.ServerID == 0 {
+		id, err := sql.Uint64.Convert(globalVariable(cfg.SystemVariables, "server_id"))
+		if err != nil {
+			return nil, err
+		}
+		cfg.ServerID = uint32(id.(uint64))
+	}
+
+	if cfg.ServerUUID == "" {
+		cfg.ServerUUID, _ = globalVariable(cfg.SystemVariables, "server_uuid").(string)
+	}
+	sid, err := mysql.ParseSID(cfg.ServerUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxFileSize == 0 {
+		cfg.MaxFileSize = DefaultMaxFileSize
+	}
+
+	l := &Log{
+		serverID:    cfg.ServerID,
+		sid:         sid,
+		maxFileSize: cfg.MaxFileSize,
+		maxFiles:    cfg.MaxFiles,
+		executed:    mysql.Mysql56GTIDSet{},
+		tableIDs:    make(map[string]uint64),
+		nextTableID: 1,
+		changed:     make(chan struct{}),
+	}
+	l.newFile(timestamp())
+	return l, nil
+}
+
+// globalVariable returns the global value of a system variable of a
+// registry, or its default value if the registry is nil.
+func globalVariable(r *sql.SystemVariableRegistry, name string) interface{} {
+	if r == nil {
+		r = sql.NewSystemVariables()
+	}
+	v, _ := r.Global(name)
+	return v
+}
+
+func timestamp() uint32 {
+	return uint32(time.Now().Unix())
+}
+
+// newFile starts the next file of the log, purging the oldest one if there
+// are too many.
+func (l *Log) newFile(ts uint32) {
+	l.next++
+	f := &file{name: fmt.Sprintf("binlog.%06d", l.next), data: []byte(magic)}
+	l.files = append(l.files, f)
+	if l.maxFiles > 0 && len(l.files) > l.maxFiles {
+		purged := l.files[:len(l.files)-l.maxFiles]
+		l.files = l.files[len(l.files)-l.maxFiles:]
+		for _, f := range purged {
+			f.purge()
+		}
+	}
+
+	l.append(formatDescriptionEvent(ts), previousGTIDsEvent(ts, l.executed))
+}
+
+func (l *Log) current() *file {
+	return l.files[len(l.files)-1]
+}
+
+// append writes events to the current file.
+func (l *Log) append(events ...event) {
+	f := l.current()
+	for _, e := range events {
+		pos := uint64(len(f.data))
+		end := pos + e.size()
+		f.data = append(f.data, e.encode(l.serverID, end)...)
+		f.events = append(f.events, sql.BinaryLogEvent{
+			LogName:  f.name,
+			Pos:      pos,
+			Type:     eventNames[e.typ],
+			ServerID: l.serverID,
+			EndPos:   end,
+			Info:     e.info,
+		})
+	}
+}
+
+// commit writes the events of a transaction with the next GTID, rotates
+// the log if the current file is full and wakes up the dumps.
+func (l *Log) commit(ts uint32, events ...event) {
+	l.sequence++
+	gtid := mysql.Mysql56GTID{Server: l.sid, Sequence: l.sequence}
+	l.append(gtidEvent(ts, gtid, l.sequence))
+	l.append(events...)
+	l.executed = l.executed.AddGTID(gtid).(mysql.Mysql56GTIDSet)
+	sql.AddExecutedGTID(gtid)
+
+	if uint64(len(l.current().data)) >= l.maxFileSize {
+		l.append(rotateEvent(ts, fmt.Sprintf("binlog.%06d", l.next+1), uint64(len(magic))))
+		l.newFile(ts)
+	}
+
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// change is a change to a row: the row written with a Write_rows event, the
+// row deleted with a Delete_rows event, or the row before and after an
+// update with an Update_rows event.
+type change struct {
+	typ    byte
+	before sql.Row
+	after  sql.Row
+}
+
+// writeRows writes a transaction with the changes of a statement to the rows
+// of a table.
+func (l *Log) writeRows(db, name string, schema sql.Schema, changes []change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	t, err := newTable(db, name, schema)
+	if err != nil {
+		return err
+	}
+
+	// The rows are encoded before taking the lock, and written as events
+	// with the id of the table once it's known.
+	type rows struct {
+		typ  byte
+		data []byte
+	}
+	var events []rows
+	for _, c := range changes {
+		if len(events) == 0 || events[len(events)-1].typ != c.typ || len(events[len(events)-1].data) >= maxRowsEventSize {
+			events = append(events, rows{typ: c.typ})
+		}
+
+		r := &events[len(events)-1]
+		if c.before != nil {
+			if r.data, err = t.encodeRow(r.data, c.before); err != nil {
+				return err
+			}
+		}
+		if c.after != nil {
+			if r.data, err = t.encodeRow(r.data, c.after); err != nil {
+				return err
+			}
+		}
+	}
+
+	ts := timestamp()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := db + "." + name
+	id, ok := l.tableIDs[key]
+	if !ok {
+		id = l.nextTableID
+		l.nextTableID++
+		l.tableIDs[key] = id
+	}
+
+	transaction := []event{queryEvent(ts, db, "BEGIN"), tableMapEvent(ts, id, t)}
+	for i, r := range events {
+		transaction = append(transaction, rowsEvent(ts, r.typ, id, len(t.columns), r.data, i == len(events)-1))
+	}
+	l.xid++
+	transaction = append(transaction, xidEvent(ts, l.xid))
+
+	l.commit(ts, transaction...)
+	return nil
+}
+
+// writeQuery writes a transaction with a DDL statement run with the database
+// given as the current one.
+func (l *Log) writeQuery(db, query string) {
+	ts := timestamp()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tableIDs = make(map[string]uint64)
+	l.commit(ts, queryEvent(ts, db, query))
+}
+
+// Files implements the sql.BinaryLog interface.
+func (l *Log) Files() []sql.BinaryLogFile {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	files := make([]sql.BinaryLogFile, len(l.files))
+	for i, f := range l.files {
+		files[i] = sql.BinaryLogFile{Name: f.name, Size: uint64(len(f.data))}
+	}
+	return files
+}
+
+// Events implements the sql.BinaryLog interface.
+func (l *Log) Events(name string, pos uint64) ([]sql.BinaryLogEvent, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f := l.files[0]
+	if name != "" {
+		if f = l.file(name); f == nil {
+			return nil, sql.ErrBinaryLogNotFound.New()
+		}
+	}
+
+	var events []sql.BinaryLogEvent
+	for _, e := range f.events {
+		if e.Pos >= pos {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// Position implements the sql.BinaryLog interface.
+func (l *Log) Position() (string, uint64, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f := l.current()
+	return f.name, uint64(len(f.data)), l.executed.String()
+}
+
+func (l *Log) file(name string) *file {
+	for _, f := range l.files {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// DumpRequest is what a replica asks for with COM_BINLOG_DUMP or
+// COM_BINLOG_DUMP_GTID.
+type DumpRequest struct {
+	// File and Position are where the dump starts. The first file is used
+	// if File is empty.
+	File     string
+	Position uint64
+	// GTIDs are the transactions the replica has already applied, which
+	// aren't sent. If set, the dump starts from the first file.
+	GTIDs mysql.GTIDSet
+	// NonBlock makes the dump end once the replica has all the events,
+	// instead of waiting for new ones.
+	NonBlock bool
+	// Heartbeat is the period of the heartbeat events sent while the dump
+	// waits for new events, none if 0.
+	Heartbeat time.Duration
+}
+
+// Dump sends the events of the log a replica asks for, one by one, until the
+// context is done or sending one fails. It starts with a ROTATE event with
+// the file and the position the dump starts at, followed by the format
+// description event of the file, as MySQL does.
+func (l *Log) Dump(ctx context.Context, req DumpRequest, send func([]byte) error) error {
+	name, pos := req.File, req.Position
+	if req.GTIDs != nil || pos < uint64(len(magic)) {
+		pos = uint64(len(magic))
+	}
+
+	l.mu.Lock()
+	f := l.files[0]
+	if name != "" && req.GTIDs == nil {
+		f = l.file(name)
+	}
+	var fde []byte
+	var valid bool
+	if f != nil {
+		fde = f.data[f.events[0].Pos:f.events[0].EndPos]
+		valid = f.hasEvent(pos) || pos == uint64(len(f.data))
+	}
+	l.mu.Unlock()
+
+	if f == nil {
+		return ErrFileNotFound.New()
+	}
+	if !valid {
+		return ErrInvalidPosition.New(pos, f.name)
+	}
+
+	rotate := rotateEvent(0, f.name, pos)
+	rotate.flags = flagArtificial
+	if err := send(rotate.encode(l.serverID, 0)); err != nil {
+		return err
+	}
+	if pos > uint64(len(magic)) {
+		// The format description event is sent with the position of the
+		// next event left at 0, so that the replica doesn't take it as
+		// its position in the file.
+		data := append([]byte(nil), fde[:len(fde)-checksumLength]...)
+		for i := 13; i < 17; i++ {
+			data[i] = 0
+		}
+		if err := send(appendChecksum(data)); err != nil {
+			return err
+		}
+	}
+
+	var heartbeat <-chan time.Time
+	if req.Heartbeat > 0 {
+		ticker := time.NewTicker(req.Heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	var skip bool
+	for {
+		// Events are only appended to the files, so what's been written is
+		// read once the lock is released.
+		l.mu.Lock()
+		current := l.file(f.name)
+		var data []byte
+		var events []sql.BinaryLogEvent
+		if current != nil {
+			data, events = current.data, current.events
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		if current == nil {
+			// The file has been purged while the replica was reading it.
+			return ErrFileNotFound.New()
+		}
+
+		var rotated bool
+		for _, e := range events {
+			if e.Pos < pos {
+				continue
+			}
+
+			ev := data[e.Pos:e.EndPos]
+			switch ev[4] {
+			case eventGTID:
+				skip = req.GTIDs != nil && req.GTIDs.ContainsGTID(eventGTIDOf(ev))
+			case eventRotate:
+				skip = false
+				rotated = true
+			}
+
+			pos = e.EndPos
+			if skip {
+				continue
+			}
+			if err := send(ev); err != nil {
+				return err
+			}
+		}
+
+		if rotated {
+			l.mu.Lock()
+			for i, g := range l.files {
+				if g.name == f.name && i+1 < len(l.files) {
+					f = l.files[i+1]
+					break
+				}
+			}
+			l.mu.Unlock()
+			pos = 0
+			continue
+		}
+
+		if req.NonBlock {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-heartbeat:
+			if err := send(heartbeatEvent(f.name).encode(l.serverID, pos)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// purge adds the GTIDs of the transactions of a file removed from the log
+// to @@gtid_purged.
+func (f *file) purge() {
+	for _, e := range f.events {
+		if e.Type == eventNames[eventGTID] {
+			sql.AddPurgedGTID(eventGTIDOf(f.data[e.Pos:e.EndPos]))
+		}
+	}
+}
+
+func (f *file) hasEvent(pos uint64) bool {
+	for _, e := range f.events {
+		if e.Pos == pos {
+			return true
+		}
+	}
+	return false
+}
+
+// eventGTIDOf returns the GTID of an encoded GTID event.
+func eventGTIDOf(data []byte) mysql.Mysql56GTID {
+	var gtid mysql.Mysql56GTID
+	body := data[headerLength:]
+	copy(gtid.Server[:], body[1:17])
+	for i := 7; i >= 0; i-- {
+		gtid.Sequence = gtid.Sequence<<8 | int64(body[17+i])
+	}
+	return gtid
+}
@@ -0,0 +1,487 @@
+package binlog
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/vt/proto/query"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+const testUUID = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+
+func newTestLog(t *testing.T, cfg Config) *Log {
+	cfg.ServerID = 42
+	cfg.ServerUUID = testUUID
+	l, err := NewLog(cfg)
+	require.NoError(t, err)
+	return l
+}
+
+// testEvent is an event read by the parser of vitess, with its header, which
+// the parser doesn't give access to.
+type testEvent struct {
+	mysql.BinlogEvent
+	header []byte
+}
+
+func newTestEvent(data []byte) testEvent {
+	return testEvent{mysql.NewMysql56BinlogEvent(data), data[:headerLength]}
+}
+
+func (e testEvent) typ() byte {
+	return e.header[4]
+}
+
+func (e testEvent) flags() uint16 {
+	return binary.LittleEndian.Uint16(e.header[17:])
+}
+
+// readEvents splits the events of a file or a dump, checking their
+// checksums and the positions in their headers, and returns them without
+// their checksums.
+func readEvents(t *testing.T, data []byte, pos uint32) []testEvent {
+	var events []testEvent
+	var f mysql.BinlogFormat
+	for len(data) > 0 {
+		size := binary.LittleEndian.Uint32(data[9:])
+		require.True(t, int(size) <= len(data))
+		event := data[:size]
+		data = data[size:]
+
+		sum := binary.LittleEndian.Uint32(event[size-checksumLength:])
+		require.Equal(t, crc32.ChecksumIEEE(event[:size-checksumLength]), sum)
+
+		ev := mysql.NewMysql56BinlogEvent(event)
+		require.True(t, ev.IsValid())
+		require.Equal(t, uint32(42), binary.LittleEndian.Uint32(event[5:]))
+		if pos > 0 {
+			pos += size
+			require.Equal(t, pos, binary.LittleEndian.Uint32(event[13:]))
+		}
+
+		if ev.IsFormatDescription() {
+			var err error
+			f, err = ev.Format()
+			require.NoError(t, err)
+			require.Equal(t, mysql.BinlogChecksumAlgCRC32, int(f.ChecksumAlgorithm))
+		}
+
+		stripped, _, err := ev.StripChecksum(f)
+		require.NoError(t, err)
+		events = append(events, testEvent{stripped, event[:headerLength]})
+	}
+	return events
+}
+
+func format(t *testing.T, events []testEvent) mysql.BinlogFormat {
+	for _, ev := range events {
+		if ev.IsFormatDescription() {
+			f, err := ev.Format()
+			require.NoError(t, err)
+			return f
+		}
+	}
+	t.Fatal("no format description event")
+	return mysql.BinlogFormat{}
+}
+
+// rowValues returns the values of a row of a rows event as strings, reading
+// them as the type of the column of the schema given.
+func rowValues(t *testing.T, tm *mysql.TableMap, schema sql.Schema, data []byte, nulls mysql.Bitmap) []string {
+	var values []string
+	var pos int
+	for i, c := range schema {
+		if nulls.Bit(i) {
+			values = append(values, "NULL")
+			continue
+		}
+
+		typ := c.Type.Type()
+		if typ == query.Type_TEXT || typ == query.Type_BLOB || typ == query.Type_JSON {
+			typ = query.Type_VARBINARY
+		}
+		v, n, err := mysql.CellValue(data, pos, tm.Types[i], tm.Metadata[i], typ)
+		require.NoError(t, err)
+		values = append(values, string(v.Raw()))
+		pos += n
+	}
+	require.Equal(t, len(data), pos)
+	return values
+}
+
+var testSchema = sql.Schema{
+	{Name: "i8", Type: sql.Int8, Nullable: true},
+	{Name: "u32", Type: sql.Uint32, Nullable: true},
+	{Name: "i64", Type: sql.Int64, Nullable: true},
+	{Name: "f", Type: sql.Float64, Nullable: true},
+	{Name: "d", Type: sql.MustCreateDecimalType(10, 2), Nullable: true},
+	{Name: "vc", Type: sql.MustCreateStringWithDefaults(query.Type_VARCHAR, 20), Nullable: true},
+	{Name: "c", Type: sql.MustCreateStringWithDefaults(query.Type_CHAR, 5), Nullable: true},
+	{Name: "txt", Type: sql.Text, Nullable: true},
+	{Name: "dt", Type: sql.Date, Nullable: true},
+	{Name: "dtm", Type: sql.Datetime, Nullable: true},
+	{Name: "ts", Type: sql.Timestamp, Nullable: true},
+	{Name: "tm", Type: sql.Time, Nullable: true},
+	{Name: "y", Type: sql.Year, Nullable: true},
+	{Name: "e", Type: sql.MustCreateEnumType([]string{"a", "b", "c"}, sql.Collation_Default), Nullable: true},
+	{Name: "s", Type: sql.MustCreateSetType([]string{"x", "y", "z"}, sql.Collation_Default), Nullable: true},
+	{Name: "b", Type: sql.MustCreateBitType(10), Nullable: true},
+	{Name: "j", Type: sql.JSON, Nullable: true},
+}
+
+func TestWriteRows(t *testing.T) {
+	require := require.New(t)
+	l := newTestLog(t, Config{})
+
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	full := sql.NewRow(
+		int8(-5), uint32(4000000000), int64(-1234567890123), 1.5, "-123.45", "hello", "ab", "some text",
+		ts, ts, ts, "-01:02:03", int16(2021), "b", "x,z", uint64(513), []byte(`{"a":[1,true,null,"s"],"bb":2.5,"c":-70000}`),
+	)
+	empty := make(sql.Row, len(testSchema))
+	updated := full.Copy()
+	updated[0] = int8(7)
+
+	require.NoError(l.writeRows("db", "t", testSchema, []change{
+		{typ: eventWriteRows, after: full},
+		{typ: eventWriteRows, after: empty},
+		{typ: eventUpdateRows, before: full, after: updated},
+		{typ: eventDeleteRows, before: empty},
+	}))
+
+	events := readEvents(t, l.current().data[len(magic):], uint32(len(magic)))
+	f := format(t, events)
+
+	var types []byte
+	for _, ev := range events {
+		types = append(types, ev.typ())
+	}
+	require.Equal([]byte{
+		eventFormatDescription, eventPreviousGTIDs, eventGTID, eventQuery, eventTableMap,
+		eventWriteRows, eventUpdateRows, eventDeleteRows, eventXID,
+	}, types)
+
+	gtid, hasBegin, err := events[2].GTID(f)
+	require.NoError(err)
+	require.False(hasBegin)
+	require.Equal(testUUID+":1", gtid.String())
+
+	q, err := events[3].Query(f)
+	require.NoError(err)
+	require.Equal("db", q.Database)
+	require.Equal("BEGIN", q.SQL)
+
+	tm, err := events[4].TableMap(f)
+	require.NoError(err)
+	require.Equal("db", tm.Database)
+	require.Equal("t", tm.Name)
+	require.Len(tm.Types, len(testSchema))
+
+	expected := []string{
+		"-5", "4000000000", "-1234567890123", "1.5E+00", "-123.45", "hello", "ab", "some text",
+		"2020-01-02", "2020-01-02 03:04:05", "2020-01-02 03:04:05", "-01:02:03", "2021", "2", "5", "\x02\x01",
+		// Keys are sorted by length first.
+		`JSON_OBJECT('a',JSON_ARRAY(1,true,null,'s'),'c',-70000,'bb',2.5E+00)`,
+	}
+	nulls := make([]string, len(testSchema))
+	for i := range nulls {
+		nulls[i] = "NULL"
+	}
+
+	rows, err := events[5].Rows(f, tm)
+	require.NoError(err)
+	require.Len(rows.Rows, 2)
+	require.Equal(expected, rowValues(t, tm, testSchema, rows.Rows[0].Data, rows.Rows[0].NullColumns))
+	require.Equal(nulls, rowValues(t, tm, testSchema, rows.Rows[1].Data, rows.Rows[1].NullColumns))
+	require.Equal(uint16(0), rows.Flags&rowsFlagStmtEnd)
+
+	rows, err = events[6].Rows(f, tm)
+	require.NoError(err)
+	require.Len(rows.Rows, 1)
+	require.Equal(expected, rowValues(t, tm, testSchema, rows.Rows[0].Identify, rows.Rows[0].NullIdentifyColumns))
+	require.Equal(append([]string{"7"}, expected[1:]...), rowValues(t, tm, testSchema, rows.Rows[0].Data, rows.Rows[0].NullColumns))
+
+	rows, err = events[7].Rows(f, tm)
+	require.NoError(err)
+	require.Len(rows.Rows, 1)
+	require.Equal(uint16(rowsFlagStmtEnd), rows.Flags&rowsFlagStmtEnd)
+	require.Equal(nulls, rowValues(t, tm, testSchema, rows.Rows[0].Identify, rows.Rows[0].NullIdentifyColumns))
+
+	file, pos, executed := l.Position()
+	require.Equal("binlog.000001", file)
+	require.Equal(uint64(len(l.current().data)), pos)
+	require.Equal(testUUID+":1", executed)
+}
+
+func TestDecimals(t *testing.T) {
+	schema := sql.Schema{{Name: "d", Type: sql.MustCreateDecimalType(30, 12)}}
+	for _, d := range []string{"0", "1", "-1", "12345678901234567.8", "-98765.000000000001", "0.5"} {
+		t.Run(d, func(t *testing.T) {
+			require := require.New(t)
+			l := newTestLog(t, Config{})
+			require.NoError(l.writeRows("db", "t", schema, []change{{typ: eventWriteRows, after: sql.NewRow(d)}}))
+
+			events := readEvents(t, l.current().data[len(magic):], 0)
+			f := format(t, events)
+			tm, err := events[4].TableMap(f)
+			require.NoError(err)
+			rows, err := events[5].Rows(f, tm)
+			require.NoError(err)
+
+			// The parser pads the groups of digits with spaces.
+			values := rowValues(t, tm, schema, rows.Rows[0].Data, rows.Rows[0].NullColumns)
+			actual, err := decimal.NewFromString(strings.Replace(values[0], " ", "", -1))
+			require.NoError(err)
+			require.True(decimal.RequireFromString(d).Equal(actual), "%s != %s", d, actual)
+		})
+	}
+}
+
+func TestWriteQuery(t *testing.T) {
+	require := require.New(t)
+	l := newTestLog(t, Config{})
+
+	l.writeQuery("db", "CREATE TABLE t (i int)")
+	events := readEvents(t, l.current().data[len(magic):], uint32(len(magic)))
+	require.Len(events, 4)
+
+	f := format(t, events)
+	q, err := events[3].Query(f)
+	require.NoError(err)
+	require.Equal("db", q.Database)
+	require.Equal("CREATE TABLE t (i int)", q.SQL)
+
+	logged, err := l.Events("", 0)
+	require.NoError(err)
+	require.Len(logged, 4)
+	require.Equal("Query", logged[3].Type)
+	require.Equal("use `db`; CREATE TABLE t (i int)", logged[3].Info)
+	require.Equal("Gtid", logged[2].Type)
+	require.Equal("SET @@SESSION.GTID_NEXT= '"+testUUID+":1'", logged[2].Info)
+	require.Equal(uint32(42), logged[0].ServerID)
+}
+
+func TestRotation(t *testing.T) {
+	require := require.New(t)
+	l := newTestLog(t, Config{MaxFileSize: 300, MaxFiles: 2})
+
+	// Every transaction fills a file.
+	for i := 0; i < 3; i++ {
+		l.writeQuery("db", "CREATE TABLE a_table_with_a_long_name_to_fill_the_file (i int)")
+	}
+
+	files := l.Files()
+	require.Len(files, 2)
+	require.Equal("binlog.000003", files[0].Name)
+	require.Equal("binlog.000004", files[1].Name)
+
+	events, err := l.Events("binlog.000003", 0)
+	require.NoError(err)
+	last := events[len(events)-1]
+	require.Equal("Rotate", last.Type)
+	require.Equal("binlog.000004;pos=4", last.Info)
+	require.Equal(files[0].Size, last.EndPos)
+
+	prev := events[1]
+	require.Equal("Previous_gtids", prev.Type)
+	require.Equal(testUUID+":1-2", prev.Info)
+
+	_, err = l.Events("binlog.000001", 0)
+	require.True(sql.ErrBinaryLogNotFound.Is(err))
+
+	// The transactions of the files purged are the ones the files kept
+	// don't have.
+	require.Equal(testUUID+":1-2", globalVariable(nil, "gtid_purged"))
+	executed, err := sql.ParseGTIDSet(globalVariable(nil, "gtid_executed").(string))
+	require.NoError(err)
+	require.True(executed.Contains(l.executed))
+}
+
+// dump returns the events of a dump that doesn't block.
+func dump(t *testing.T, l *Log, req DumpRequest) []testEvent {
+	req.NonBlock = true
+	var data []byte
+	require.NoError(t, l.Dump(context.Background(), req, func(event []byte) error {
+		data = append(data, event...)
+		return nil
+	}))
+	return readEvents(t, data, 0)
+}
+
+func TestDump(t *testing.T) {
+	require := require.New(t)
+	l := newTestLog(t, Config{MaxFileSize: 300})
+	for i := 0; i < 3; i++ {
+		l.writeQuery("db", "CREATE TABLE a_table_with_a_long_name_to_fill_the_file (i int)")
+	}
+	require.Len(l.Files(), 4)
+
+	events := dump(t, l, DumpRequest{})
+	f := format(t, events)
+
+	var queries int
+	for _, ev := range events {
+		if ev.IsQuery() {
+			queries++
+		}
+	}
+	require.Equal(3, queries)
+
+	// The dump starts with the artificial rotate event, and each file with
+	// its format description event.
+	require.True(events[0].IsRotate())
+	require.Equal(uint16(flagArtificial), events[0].flags())
+	require.True(events[1].IsFormatDescription())
+
+	events = dump(t, l, DumpRequest{File: "binlog.000002", Position: 4})
+	require.True(events[0].IsRotate())
+	require.True(events[1].IsFormatDescription())
+	prev, err := events[2].PreviousGTIDs(f)
+	require.NoError(err)
+	require.Equal(testUUID+":1", prev.GTIDSet.String())
+
+	// From a position after the start of the file, the format description
+	// event is sent first anyway.
+	logged, err := l.Events("binlog.000002", 0)
+	require.NoError(err)
+	events = dump(t, l, DumpRequest{File: "binlog.000002", Position: logged[2].Pos})
+	require.True(events[0].IsRotate())
+	require.True(events[1].IsFormatDescription())
+	require.Equal(uint32(0), binary.LittleEndian.Uint32(events[1].header[13:]))
+	require.True(events[2].IsGTID())
+
+	pos, err := mysql.ParsePosition("MySQL56", testUUID+":1-2")
+	require.NoError(err)
+	events = dump(t, l, DumpRequest{GTIDs: pos.GTIDSet})
+	queries = 0
+	for _, ev := range events {
+		if ev.IsGTID() {
+			gtid, _, err := ev.GTID(f)
+			require.NoError(err)
+			require.Equal(testUUID+":3", gtid.String())
+		}
+		if ev.IsQuery() {
+			queries++
+		}
+	}
+	require.Equal(1, queries)
+
+	err = l.Dump(context.Background(), DumpRequest{File: "binlog.000009"}, func([]byte) error { return nil })
+	require.True(ErrFileNotFound.Is(err))
+
+	err = l.Dump(context.Background(), DumpRequest{File: "binlog.000001", Position: 5}, func([]byte) error { return nil })
+	require.True(ErrInvalidPosition.Is(err))
+}
+
+func TestDumpWaits(t *testing.T) {
+	require := require.New(t)
+	l := newTestLog(t, Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sent := make(chan []byte, 16)
+	done := make(chan error)
+	go func() {
+		done <- l.Dump(ctx, DumpRequest{Heartbeat: 10 * time.Millisecond}, func(event []byte) error {
+			sent <- event
+			return nil
+		})
+	}()
+
+	next := func() testEvent {
+		select {
+		case event := <-sent:
+			return newTestEvent(event)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event sent")
+			return testEvent{}
+		}
+	}
+
+	require.True(next().IsRotate())
+	require.True(next().IsFormatDescription())
+	require.True(next().IsPreviousGTIDs())
+	require.Equal(byte(eventHeartbeat), next().typ())
+
+	l.writeQuery("db", "DROP TABLE t")
+	ev := next()
+	for ev.typ() == eventHeartbeat {
+		ev = next()
+	}
+	require.True(ev.IsGTID())
+
+	cancel()
+	require.Equal(context.Canceled, <-done)
+}
+
+func TestMarshalJSON(t *testing.T) {
+	require := require.New(t)
+
+	for _, doc := range []string{
+		`null`,
+		`"a string"`,
+		`{}`,
+		`[]`,
+		`{"k":{"nested":[1,2,3]},"a":18446744073709551615,"b":-9223372036854775808}`,
+	} {
+		data, err := marshalJSON([]byte(doc))
+		require.NoError(err)
+
+		var buf []byte
+		buf = appendLength(buf, len(data), 4)
+		buf = append(buf, data...)
+		v, _, err := mysql.CellValue(buf, 0, mysql.TypeJSON, 4, query.Type_VARBINARY)
+		require.NoError(err, doc)
+		require.NotEmpty(v.Raw())
+	}
+
+	_, err := marshalJSON([]byte(`{`))
+	require.Error(err)
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	require := require.New(t)
+
+	for _, doc := range []string{
+		`null`,
+		`"a string"`,
+		`{}`,
+		`[]`,
+		`[true,false,null,2.5,-70000,70000]`,
+		`{"a":18446744073709551615,"b":-9223372036854775808,"k":{"nested":[1,2,3]}}`,
+	} {
+		data, err := marshalJSON([]byte(doc))
+		require.NoError(err)
+		v, err := unmarshalJSON(data)
+		require.NoError(err)
+		require.Equal(doc, string(v))
+	}
+
+	// MySQL writes the objects and arrays that fit in their small format,
+	// with offsets of 2 bytes and inlined uint16 values.
+	small := []byte{
+		jsonSmallObject, 2, 0, 27, 0,
+		// The keys.
+		18, 0, 1, 0,
+		19, 0, 1, 0,
+		// The values.
+		jsonUint16, 0xff, 0xff,
+		jsonSmallArray, 20, 0,
+		'a', 'b',
+		1, 0, 7, 0, jsonLiteral, jsonTrue, 0,
+	}
+	v, err := unmarshalJSON(small)
+	require.NoError(err)
+	require.Equal(`{"a":65535,"b":[true]}`, string(v))
+
+	_, err = unmarshalJSON([]byte{jsonLargeArray, 1, 0})
+	require.Error(err)
+}
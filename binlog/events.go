@@ -0,0 +1,300 @@
+package binlog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"github.com/dolthub/vitess/go/mysql"
+)
+
+// The types of the events written, as numbered by MySQL.
+const (
+	eventQuery             = 2
+	eventRotate            = 4
+	eventFormatDescription = 15
+	eventXID               = 16
+	eventTableMap          = 19
+	eventHeartbeat         = 27
+	eventWriteRows         = 30
+	eventUpdateRows        = 31
+	eventDeleteRows        = 32
+	eventGTID              = 33
+	eventPreviousGTIDs     = 35
+)
+
+// eventNames are the names of the types of the events, as SHOW BINLOG EVENTS
+// shows them.
+var eventNames = map[byte]string{
+	eventQuery:             "Query",
+	eventRotate:            "Rotate",
+	eventFormatDescription: "Format_desc",
+	eventXID:               "Xid",
+	eventTableMap:          "Table_map",
+	eventWriteRows:         "Write_rows",
+	eventUpdateRows:        "Update_rows",
+	eventDeleteRows:        "Delete_rows",
+	eventGTID:              "Gtid",
+	eventPreviousGTIDs:     "Previous_gtids",
+}
+
+const (
+	// magic starts every binary log file, so the first event is at
+	// position 4.
+	magic = "\xfebin"
+	// headerLength is the length of the header of the events.
+	headerLength = 19
+	// checksumLength is the length of the CRC32 checksum ending the events.
+	checksumLength = 4
+	// serverVersion is the version the format description events announce.
+	serverVersion = "8.0.11-go-mysql-server"
+
+	// flagArtificial is LOG_EVENT_ARTIFICIAL_F, set on the events a dump
+	// sends that aren't in the log.
+	flagArtificial = 0x20
+	// rowsFlagStmtEnd is STMT_END_F, set on the last rows event of a
+	// statement.
+	rowsFlagStmtEnd = 0x01
+)
+
+// postHeaderLengths are the lengths of the post-headers of each type of
+// event, from type 1, as MySQL 5.7 writes them in format description events.
+var postHeaderLengths = []byte{
+	56, 13, 0, 8, 0, 18, 0, 4, 4, 4, 4, 18, 0, 0, 95, 0, 4, 26, 8, 0,
+	0, 0, 8, 8, 8, 2, 0, 0, 0, 10, 10, 10, 42, 42, 0, 18, 52, 0,
+}
+
+// event is an event of the log before it's encoded.
+type event struct {
+	typ       byte
+	timestamp uint32
+	flags     uint16
+	body      []byte
+	// info describes the event in SHOW BINLOG EVENTS.
+	info string
+}
+
+// size returns the size of the event once encoded.
+func (e event) size() uint64 {
+	return uint64(headerLength + len(e.body) + checksumLength)
+}
+
+// encode returns the bytes of the event, with its header and checksum. The
+// position in its header is the one of the next event in the log, or 0 for
+// the events a dump sends that aren't in the log.
+func (e event) encode(serverID uint32, logPos uint64) []byte {
+	size := e.size()
+	data := make([]byte, headerLength, size)
+	binary.LittleEndian.PutUint32(data[0:], e.timestamp)
+	data[4] = e.typ
+	binary.LittleEndian.PutUint32(data[5:], serverID)
+	binary.LittleEndian.PutUint32(data[9:], uint32(size))
+	binary.LittleEndian.PutUint32(data[13:], uint32(logPos))
+	binary.LittleEndian.PutUint16(data[17:], e.flags)
+	data = append(data, e.body...)
+	return appendChecksum(data)
+}
+
+// appendChecksum appends the CRC32 checksum of an event to it.
+func appendChecksum(data []byte) []byte {
+	var sum [checksumLength]byte
+	binary.LittleEndian.PutUint32(sum[:], crc32.ChecksumIEEE(data))
+	return append(data, sum[:]...)
+}
+
+func formatDescriptionEvent(timestamp uint32) event {
+	body := make([]byte, 2+50+4+1, 2+50+4+1+len(postHeaderLengths)+1)
+	binary.LittleEndian.PutUint16(body, 4)
+	copy(body[2:52], serverVersion)
+	binary.LittleEndian.PutUint32(body[52:], timestamp)
+	body[56] = headerLength
+	body = append(body, postHeaderLengths...)
+	body = append(body, mysql.BinlogChecksumAlgCRC32)
+
+	return event{
+		typ:       eventFormatDescription,
+		timestamp: timestamp,
+		body:      body,
+		info:      fmt.Sprintf("Server ver: %s, Binlog ver: 4", serverVersion),
+	}
+}
+
+func previousGTIDsEvent(timestamp uint32, executed mysql.Mysql56GTIDSet) event {
+	return event{
+		typ:       eventPreviousGTIDs,
+		timestamp: timestamp,
+		body:      executed.SIDBlock(),
+		info:      executed.String(),
+	}
+}
+
+func rotateEvent(timestamp uint32, next string, pos uint64) event {
+	body := make([]byte, 8, 8+len(next))
+	binary.LittleEndian.PutUint64(body, pos)
+	body = append(body, next...)
+
+	return event{
+		typ:       eventRotate,
+		timestamp: timestamp,
+		body:      body,
+		info:      fmt.Sprintf("%s;pos=%d", next, pos),
+	}
+}
+
+func gtidEvent(timestamp uint32, gtid mysql.Mysql56GTID, sequence int64) event {
+	body := make([]byte, 42)
+	// The transaction may have changes to tables.
+	body[0] = 1
+	copy(body[1:], gtid.Server[:])
+	binary.LittleEndian.PutUint64(body[17:], uint64(gtid.Sequence))
+	// The logical clock: transactions are written one by one, so each one
+	// depends on the previous.
+	body[25] = 2
+	binary.LittleEndian.PutUint64(body[26:], uint64(sequence-1))
+	binary.LittleEndian.PutUint64(body[34:], uint64(sequence))
+
+	return event{
+		typ:       eventGTID,
+		timestamp: timestamp,
+		body:      body,
+		info:      fmt.Sprintf("SET @@SESSION.GTID_NEXT= '%s'", gtid),
+	}
+}
+
+// queryEvent returns the event of a query run with the database given as the
+// current one.
+func queryEvent(timestamp uint32, db, query string) event {
+	body := make([]byte, 13, 13+len(db)+1+len(query))
+	// Execution time, error code and status variables are left empty.
+	body[8] = byte(len(db))
+	body = append(body, db...)
+	body = append(body, 0)
+	body = append(body, query...)
+
+	info := query
+	if db != "" && query != "BEGIN" {
+		info = fmt.Sprintf("use %s; %s", quoteIdentifier(db), query)
+	}
+
+	return event{
+		typ:       eventQuery,
+		timestamp: timestamp,
+		body:      body,
+		info:      info,
+	}
+}
+
+func xidEvent(timestamp uint32, xid uint64) event {
+	body := make([]byte, 8)
+	binary.LittleEndian.PutUint64(body, xid)
+
+	return event{
+		typ:       eventXID,
+		timestamp: timestamp,
+		body:      body,
+		info:      fmt.Sprintf("COMMIT /* xid=%d */", xid),
+	}
+}
+
+func tableMapEvent(timestamp uint32, id uint64, t *table) event {
+	body := make([]byte, 8, 64)
+	putUint48(body, id)
+	body = append(body, byte(len(t.db)))
+	body = append(body, t.db...)
+	body = append(body, 0, byte(len(t.name)))
+	body = append(body, t.name...)
+	body = append(body, 0)
+
+	body = appendLengthEncodedInt(body, uint64(len(t.columns)))
+	var metadata []byte
+	nullable := make([]byte, (len(t.columns)+7)/8)
+	for i, c := range t.columns {
+		body = append(body, c.typ)
+		metadata = append(metadata, c.metadata...)
+		if c.nullable {
+			nullable[i/8] |= 1 << uint(i%8)
+		}
+	}
+	body = appendLengthEncodedInt(body, uint64(len(metadata)))
+	body = append(body, metadata...)
+	body = append(body, nullable...)
+
+	return event{
+		typ:       eventTableMap,
+		timestamp: timestamp,
+		body:      body,
+		info:      fmt.Sprintf("table_id: %d (%s.%s)", id, quoteIdentifier(t.db), quoteIdentifier(t.name)),
+	}
+}
+
+// rowsEvent returns a rows event of the type given with the rows already
+// encoded, of all the columns of a table.
+func rowsEvent(timestamp uint32, typ byte, id uint64, columns int, rows []byte, end bool) event {
+	bitmap := make([]byte, (columns+7)/8)
+	for i := 0; i < columns; i++ {
+		bitmap[i/8] |= 1 << uint(i%8)
+	}
+
+	body := make([]byte, 10, 10+9+2*len(bitmap)+len(rows))
+	putUint48(body, id)
+	var flags uint16
+	if end {
+		flags = rowsFlagStmtEnd
+	}
+	binary.LittleEndian.PutUint16(body[6:], flags)
+	// The length of the extra data, which is only this length.
+	binary.LittleEndian.PutUint16(body[8:], 2)
+	body = appendLengthEncodedInt(body, uint64(columns))
+	body = append(body, bitmap...)
+	if typ == eventUpdateRows {
+		body = append(body, bitmap...)
+	}
+	body = append(body, rows...)
+
+	info := fmt.Sprintf("table_id: %d", id)
+	if end {
+		info += " flags: STMT_END_F"
+	}
+
+	return event{
+		typ:       typ,
+		timestamp: timestamp,
+		body:      body,
+		info:      info,
+	}
+}
+
+// heartbeatEvent returns the event a dump sends while it waits for new ones,
+// with the position of the log it's at.
+func heartbeatEvent(file string) event {
+	return event{
+		typ:   eventHeartbeat,
+		flags: flagArtificial,
+		body:  []byte(file),
+	}
+}
+
+func putUint48(b []byte, n uint64) {
+	binary.LittleEndian.PutUint32(b, uint32(n))
+	binary.LittleEndian.PutUint16(b[4:], uint16(n>>32))
+}
+
+func appendLengthEncodedInt(b []byte, n uint64) []byte {
+	switch {
+	case n < 251:
+		return append(b, byte(n))
+	case n < 1<<16:
+		return append(b, 0xfc, byte(n), byte(n>>8))
+	case n < 1<<24:
+		return append(b, 0xfd, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], n)
+		return append(append(b, 0xfe), buf[:]...)
+	}
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
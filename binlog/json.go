@@ -0,0 +1,331 @@
+package binlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// The types of the values of MySQL binary JSON.
+const (
+	jsonSmallObject = 0x00
+	jsonLargeObject = 0x01
+	jsonSmallArray  = 0x02
+	jsonLargeArray  = 0x03
+	jsonLiteral     = 0x04
+	jsonInt16       = 0x05
+	jsonUint16      = 0x06
+	jsonInt32       = 0x07
+	jsonUint32      = 0x08
+	jsonInt64       = 0x09
+	jsonUint64      = 0x0a
+	jsonDouble      = 0x0b
+	jsonString      = 0x0c
+)
+
+// The literals of MySQL binary JSON.
+const (
+	jsonNull  = 0x00
+	jsonTrue  = 0x01
+	jsonFalse = 0x02
+)
+
+// marshalJSON returns a JSON document in the binary format MySQL writes JSON
+// values in. Objects and arrays are always written in their large format,
+// which readers accept whatever their size.
+func marshalJSON(doc []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	typ, data := marshalJSONValue(v)
+	return append([]byte{typ}, data...), nil
+}
+
+func marshalJSONValue(v interface{}) (byte, []byte) {
+	switch v := v.(type) {
+	case nil:
+		return jsonLiteral, []byte{jsonNull}
+	case bool:
+		if v {
+			return jsonLiteral, []byte{jsonTrue}
+		}
+		return jsonLiteral, []byte{jsonFalse}
+	case json.Number:
+		return marshalJSONNumber(v)
+	case string:
+		return jsonString, appendJSONString(nil, v)
+	case []interface{}:
+		return jsonLargeArray, marshalJSONContainer(nil, v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		// MySQL sorts the keys by length first, so that it can look them up
+		// with a binary search.
+		sort.Slice(keys, func(i, j int) bool {
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) < len(keys[j])
+			}
+			return keys[i] < keys[j]
+		})
+
+		values := make([]interface{}, len(keys))
+		for i, k := range keys {
+			values[i] = v[k]
+		}
+		return jsonLargeObject, marshalJSONContainer(keys, values)
+	default:
+		// Values decoded from JSON have no other types.
+		panic("unexpected JSON value")
+	}
+}
+
+func marshalJSONNumber(n json.Number) (byte, []byte) {
+	var buf [8]byte
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		switch {
+		case i >= math.MinInt16 && i <= math.MaxInt16:
+			binary.LittleEndian.PutUint16(buf[:], uint16(i))
+			return jsonInt16, buf[:2]
+		case i >= math.MinInt32 && i <= math.MaxInt32:
+			binary.LittleEndian.PutUint32(buf[:], uint32(i))
+			return jsonInt32, buf[:4]
+		default:
+			binary.LittleEndian.PutUint64(buf[:], uint64(i))
+			return jsonInt64, buf[:]
+		}
+	}
+
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		binary.LittleEndian.PutUint64(buf[:], u)
+		return jsonUint64, buf[:]
+	}
+
+	f, _ := strconv.ParseFloat(string(n), 64)
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+	return jsonDouble, buf[:]
+}
+
+// marshalJSONContainer returns a large object, or a large array if there are
+// no keys: the number of elements and the size, the entries of the keys and
+// of the values, then the keys and the values that aren't inlined in their
+// entries. Offsets are from the start of the container.
+func marshalJSONContainer(keys []string, values []interface{}) []byte {
+	const keyEntryLength, valueEntryLength = 6, 5
+
+	header := 8 + len(keys)*keyEntryLength + len(values)*valueEntryLength
+	data := make([]byte, header)
+	binary.LittleEndian.PutUint32(data, uint32(len(values)))
+
+	for i, k := range keys {
+		entry := data[8+i*keyEntryLength:]
+		binary.LittleEndian.PutUint32(entry, uint32(len(data)))
+		binary.LittleEndian.PutUint16(entry[4:], uint16(len(k)))
+		data = append(data, k...)
+	}
+
+	for i, v := range values {
+		typ, value := marshalJSONValue(v)
+		entry := data[8+len(keys)*keyEntryLength+i*valueEntryLength:]
+		entry[0] = typ
+		if typ == jsonLiteral || typ == jsonInt16 || typ == jsonInt32 {
+			copy(entry[1:5], value)
+			continue
+		}
+		binary.LittleEndian.PutUint32(entry[1:], uint32(len(data)))
+		data = append(data, value...)
+	}
+
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)))
+	return data
+}
+
+// appendJSONString appends a string with its length in 7 bits per byte, the
+// high bit set on all the bytes but the last.
+func appendJSONString(b []byte, s string) []byte {
+	n := len(s)
+	for n >= 0x80 {
+		b = append(b, byte(n)|0x80)
+		n >>= 7
+	}
+	b = append(b, byte(n))
+	return append(b, s...)
+}
+
+// unmarshalJSON returns the JSON document of a value in the binary format
+// MySQL writes JSON values in.
+func unmarshalJSON(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		// MySQL writes the empty values of the JSON columns set to
+		// invalid values when it wasn't strict as empty.
+		return []byte("null"), nil
+	}
+
+	v, err := unmarshalJSONValue(data[0], data[1:])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// unmarshalJSONValue decodes a value of the type given from the data it
+// starts, which is in its entry if it's inlined in its container.
+func unmarshalJSONValue(typ byte, data []byte) (interface{}, error) {
+	need := func(n int) error {
+		if len(data) < n {
+			return fmt.Errorf("binary log: truncated JSON value of type %d", typ)
+		}
+		return nil
+	}
+
+	switch typ {
+	case jsonSmallObject, jsonLargeObject, jsonSmallArray, jsonLargeArray:
+		return unmarshalJSONContainer(typ, data)
+	case jsonLiteral:
+		if err := need(1); err != nil {
+			return nil, err
+		}
+		switch data[0] {
+		case jsonNull:
+			return nil, nil
+		case jsonTrue:
+			return true, nil
+		case jsonFalse:
+			return false, nil
+		}
+		return nil, fmt.Errorf("binary log: unknown JSON literal %d", data[0])
+	case jsonInt16:
+		if err := need(2); err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatInt(int64(int16(binary.LittleEndian.Uint16(data))), 10)), nil
+	case jsonUint16:
+		if err := need(2); err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatUint(uint64(binary.LittleEndian.Uint16(data)), 10)), nil
+	case jsonInt32:
+		if err := need(4); err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatInt(int64(int32(binary.LittleEndian.Uint32(data))), 10)), nil
+	case jsonUint32:
+		if err := need(4); err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatUint(uint64(binary.LittleEndian.Uint32(data)), 10)), nil
+	case jsonInt64:
+		if err := need(8); err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatInt(int64(binary.LittleEndian.Uint64(data)), 10)), nil
+	case jsonUint64:
+		if err := need(8); err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatUint(binary.LittleEndian.Uint64(data), 10)), nil
+	case jsonDouble:
+		if err := need(8); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), nil
+	case jsonString:
+		n, size := 0, 0
+		for shift := uint(0); ; shift += 7 {
+			if err := need(size + 1); err != nil {
+				return nil, err
+			}
+			b := data[size]
+			size++
+			n |= int(b&0x7f) << shift
+			if b&0x80 == 0 {
+				break
+			}
+		}
+		if err := need(size + n); err != nil {
+			return nil, err
+		}
+		return string(data[size : size+n]), nil
+	default:
+		return nil, fmt.Errorf("binary log: unsupported JSON value type %d", typ)
+	}
+}
+
+// unmarshalJSONContainer decodes an object or an array, in its small format,
+// whose counts and offsets take 2 bytes, or its large one, where they take 4.
+func unmarshalJSONContainer(typ byte, data []byte) (interface{}, error) {
+	large := typ == jsonLargeObject || typ == jsonLargeArray
+	isObject := typ == jsonSmallObject || typ == jsonLargeObject
+
+	size := 2
+	if large {
+		size = 4
+	}
+	read := func(b []byte) int {
+		if large {
+			return int(binary.LittleEndian.Uint32(b))
+		}
+		return int(binary.LittleEndian.Uint16(b))
+	}
+
+	if len(data) < 2*size {
+		return nil, fmt.Errorf("binary log: truncated JSON container")
+	}
+	count := read(data)
+	keyEntryLength, valueEntryLength := size+2, size+1
+
+	header := 2 * size
+	if isObject {
+		header += count * keyEntryLength
+	}
+	if len(data) < header+count*valueEntryLength {
+		return nil, fmt.Errorf("binary log: truncated JSON container")
+	}
+
+	values := make([]interface{}, count)
+	for i := range values {
+		entry := data[header+i*valueEntryLength:]
+		typ := entry[0]
+
+		var err error
+		switch {
+		case typ == jsonLiteral || typ == jsonInt16 || typ == jsonUint16,
+			large && (typ == jsonInt32 || typ == jsonUint32):
+			values[i], err = unmarshalJSONValue(typ, entry[1:valueEntryLength])
+		default:
+			offset := read(entry[1:])
+			if offset > len(data) {
+				return nil, fmt.Errorf("binary log: truncated JSON container")
+			}
+			values[i], err = unmarshalJSONValue(typ, data[offset:])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !isObject {
+		return values, nil
+	}
+
+	object := make(map[string]interface{}, count)
+	for i, v := range values {
+		entry := data[2*size+i*keyEntryLength:]
+		offset, n := read(entry), int(binary.LittleEndian.Uint16(entry[size:]))
+		if offset+n > len(data) {
+			return nil, fmt.Errorf("binary log: truncated JSON container")
+		}
+		object[string(data[offset:offset+n])] = v
+	}
+	return object, nil
+}
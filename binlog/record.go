@@ -0,0 +1,118 @@
+package binlog
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/go-mysql-server/cdc"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// Record returns the node to run for a query instead of its analyzed node,
+// so that the changes the query makes are written to the log once its rows
+// have been read and it's closed. The rows a statement run outside of a
+// transaction changes are written even if it fails, since it changes the
+// tables as it runs, and the ones changed in the open transaction of a
+// sql.TransactionHookSession once it commits, none if it's rolled back, like
+// cdc.Capture passes them. The DDL statements are only written if they
+// succeed. The changes made by triggers aren't written.
+func (l *Log) Record(ctx *sql.Context, query string, parsed, analyzed sql.Node) sql.Node {
+	if l == nil {
+		return analyzed
+	}
+
+	if isDDL(parsed) {
+		return &ddlNode{UnaryNode: plan.UnaryNode{Child: analyzed}, log: l, db: ctx.GetCurrentDatabase(), query: query}
+	}
+
+	return cdc.Capture(ctx, parsed, analyzed, l.commitChanges)
+}
+
+// isDDL returns whether a statement changes the definition of a database, a
+// table, a view or a trigger.
+func isDDL(parsed sql.Node) bool {
+	switch parsed.(type) {
+	case *plan.CreateDatabase, *plan.AlterDatabase,
+		*plan.CreateTable, *plan.DropTable, *plan.RenameTable,
+		*plan.AddColumn, *plan.DropColumn, *plan.RenameColumn, *plan.ModifyColumn, *plan.AlterColumnVisibility,
+		*plan.CreateIndex, *plan.DropIndex, *plan.AlterIndex, *plan.AlterAutoIncrement,
+		*plan.CreateForeignKey, *plan.DropForeignKey, *plan.CreateCheck, *plan.DropCheck, *plan.DropConstraint,
+		*plan.CreateView, *plan.DropView, *plan.CreateTrigger, *plan.DropTrigger:
+		return true
+	default:
+		return false
+	}
+}
+
+// ddlNode writes a DDL statement to the log once it has succeeded.
+type ddlNode struct {
+	plan.UnaryNode
+	log       *Log
+	db, query string
+}
+
+func (n *ddlNode) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	iter, err := n.Child.RowIter(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	return &ddlIter{iter: iter, node: n}, nil
+}
+
+func (n *ddlNode) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 1)
+	}
+	nn := *n
+	nn.Child = children[0]
+	return &nn, nil
+}
+
+func (n *ddlNode) String() string {
+	return n.Child.String()
+}
+
+type ddlIter struct {
+	iter   sql.RowIter
+	node   *ddlNode
+	failed bool
+}
+
+func (i *ddlIter) Next() (sql.Row, error) {
+	row, err := i.iter.Next()
+	if err != nil && err != io.EOF {
+		i.failed = true
+	}
+	return row, err
+}
+
+func (i *ddlIter) Close() error {
+	err := i.iter.Close()
+	if err == nil && !i.failed {
+		i.node.log.writeQuery(i.node.db, i.node.query)
+	}
+	return err
+}
+
+// commitChanges writes the changes a statement made to the rows of a table.
+func (l *Log) commitChanges(db string, table sql.Table, changes []cdc.Change) {
+	written := make([]change, len(changes))
+	for i, c := range changes {
+		written[i] = change{typ: rowsEventTypes[c.Type], before: c.Before, after: c.After}
+	}
+
+	if err := l.writeRows(db, table.Name(), table.Schema(), written); err != nil {
+		// The changes have been made, so failing the statement wouldn't
+		// undo them.
+		logrus.WithError(err).WithField("table", table.Name()).Error("unable to write changes to the binary log")
+	}
+}
+
+// rowsEventTypes are the types of the rows events of the types of changes.
+var rowsEventTypes = map[cdc.ChangeType]byte{
+	cdc.Insert: eventWriteRows,
+	cdc.Update: eventUpdateRows,
+	cdc.Delete: eventDeleteRows,
+}
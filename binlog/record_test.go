@@ -0,0 +1,207 @@
+package binlog_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/binlog"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+)
+
+const testUUID = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+
+func binlogEngine(t *testing.T) *sqle.Engine {
+	l, err := binlog.NewLog(binlog.Config{ServerID: 7, ServerUUID: testUUID})
+	require.NoError(t, err)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	catalog.AddDatabase(memory.NewDatabase("other"))
+	catalog.SetDatabaseCreator(memory.DatabaseCreator{})
+	a := analyzer.NewBuilder(catalog).Build()
+	return sqle.New(catalog, a, &sqle.Config{Binlog: l})
+}
+
+func newContext() *sql.Context {
+	session := sql.NewSessionWithClient("localhost:3306", sql.Client{User: "root", Address: "127.0.0.1:1234"}, 1)
+	ctx := sql.NewContext(context.Background(), sql.WithSession(session))
+	ctx.SetCurrentDatabase("mydb")
+	return ctx
+}
+
+func query(t *testing.T, e *sqle.Engine, ctx *sql.Context, q string) []sql.Row {
+	_, iter, err := e.Query(ctx, q)
+	require.NoError(t, err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(t, err)
+	return rows
+}
+
+// events returns the types and the infos of the events shown by SHOW BINLOG
+// EVENTS after the header of the file.
+func events(t *testing.T, e *sqle.Engine, ctx *sql.Context) [][2]string {
+	var events [][2]string
+	for _, row := range query(t, e, ctx, "SHOW BINLOG EVENTS LIMIT 2, 100") {
+		events = append(events, [2]string{row[2].(string), row[5].(string)})
+	}
+	return events
+}
+
+func TestRecord(t *testing.T) {
+	require := require.New(t)
+	e := binlogEngine(t)
+	ctx := newContext()
+
+	query(t, e, ctx, "CREATE TABLE t (i int primary key, s varchar(10))")
+	query(t, e, ctx, "INSERT INTO t VALUES (1, 'a'), (2, 'b'), (3, 'c')")
+	query(t, e, ctx, "UPDATE t SET s = 'x' WHERE i >= 2")
+	// Rows that don't change aren't written, nor statements changing none.
+	query(t, e, ctx, "UPDATE t SET s = 'x' WHERE i = 2")
+	query(t, e, ctx, "DELETE FROM t WHERE i = 1")
+	query(t, e, ctx, "REPLACE INTO t VALUES (2, 'y'), (4, 'z')")
+	query(t, e, ctx, "SELECT * FROM t")
+
+	_, iter, err := e.Query(ctx, "INSERT INTO t VALUES (3, 'dup')")
+	require.NoError(err)
+	_, err = sql.RowIterToRows(iter)
+	require.Error(err)
+	require.NoError(iter.Close())
+
+	gtid := func(n string) [2]string {
+		return [2]string{"Gtid", "SET @@SESSION.GTID_NEXT= '" + testUUID + ":" + n + "'"}
+	}
+	require.Equal([][2]string{
+		gtid("1"),
+		{"Query", "use `mydb`; CREATE TABLE t (i int primary key, s varchar(10))"},
+		gtid("2"),
+		{"Query", "BEGIN"},
+		{"Table_map", "table_id: 1 (`mydb`.`t`)"},
+		{"Write_rows", "table_id: 1 flags: STMT_END_F"},
+		{"Xid", "COMMIT /* xid=1 */"},
+		gtid("3"),
+		{"Query", "BEGIN"},
+		{"Table_map", "table_id: 1 (`mydb`.`t`)"},
+		{"Update_rows", "table_id: 1 flags: STMT_END_F"},
+		{"Xid", "COMMIT /* xid=2 */"},
+		gtid("4"),
+		{"Query", "BEGIN"},
+		{"Table_map", "table_id: 1 (`mydb`.`t`)"},
+		{"Delete_rows", "table_id: 1 flags: STMT_END_F"},
+		{"Xid", "COMMIT /* xid=3 */"},
+		gtid("5"),
+		{"Query", "BEGIN"},
+		{"Table_map", "table_id: 1 (`mydb`.`t`)"},
+		{"Update_rows", "table_id: 1"},
+		{"Write_rows", "table_id: 1 flags: STMT_END_F"},
+		{"Xid", "COMMIT /* xid=4 */"},
+	}, events(t, e, ctx))
+
+	rows := query(t, e, ctx, "SHOW MASTER STATUS")
+	require.Len(rows, 1)
+	require.Equal("binlog.000001", rows[0][0])
+	require.Equal(testUUID+":1-5", rows[0][4])
+
+	rows = query(t, e, ctx, "SHOW BINARY LOGS")
+	require.Equal([]sql.Row{{"binlog.000001", rows[0][1], "No"}}, rows)
+	require.Equal(rows[0][1], query(t, e, ctx, "SHOW MASTER STATUS")[0][1])
+
+	// DDL is written with the current database, as the replica runs it, and
+	// rows with the database of their table.
+	ctx.SetCurrentDatabase("other")
+	query(t, e, ctx, "CREATE TABLE u (i int)")
+	ctx.SetCurrentDatabase("mydb")
+	query(t, e, ctx, "INSERT INTO other.u VALUES (1)")
+
+	all := events(t, e, ctx)
+	require.Equal([][2]string{
+		gtid("6"),
+		{"Query", "use `other`; CREATE TABLE u (i int)"},
+		gtid("7"),
+		{"Query", "BEGIN"},
+		{"Table_map", "table_id: 2 (`other`.`u`)"},
+		{"Write_rows", "table_id: 2 flags: STMT_END_F"},
+		{"Xid", "COMMIT /* xid=5 */"},
+	}, all[len(all)-7:])
+
+	// CHECK constraints are DDL too.
+	query(t, e, ctx, "ALTER TABLE t ADD CONSTRAINT chk CHECK (i > 0)")
+	query(t, e, ctx, "ALTER TABLE t DROP CHECK chk")
+	all = events(t, e, ctx)
+	require.Equal([][2]string{
+		gtid("8"),
+		{"Query", "use `mydb`; ALTER TABLE t ADD CONSTRAINT chk CHECK (i > 0)"},
+		gtid("9"),
+		{"Query", "use `mydb`; ALTER TABLE t DROP CHECK chk"},
+	}, all[len(all)-4:])
+
+	// So are the statements creating and altering databases.
+	query(t, e, ctx, "CREATE DATABASE created")
+	query(t, e, ctx, "ALTER DATABASE created COLLATE utf8mb4_bin")
+	all = events(t, e, ctx)
+	require.Equal([][2]string{
+		gtid("10"),
+		{"Query", "use `mydb`; CREATE DATABASE created"},
+		gtid("11"),
+		{"Query", "use `mydb`; ALTER DATABASE created COLLATE utf8mb4_bin"},
+	}, all[len(all)-4:])
+}
+
+func TestRecordTransactions(t *testing.T) {
+	require := require.New(t)
+	e := binlogEngine(t)
+	ctx := newContext()
+	ctx.Session = memory.NewSession(ctx.Session)
+	query(t, e, ctx, "CREATE TABLE t (i int primary key)")
+
+	// The rows changed in a transaction are written once it commits, and
+	// never if it's rolled back.
+	query(t, e, ctx, "BEGIN")
+	query(t, e, ctx, "INSERT INTO t VALUES (1)")
+	require.Len(events(t, e, ctx), 2)
+	query(t, e, ctx, "ROLLBACK")
+	require.Len(events(t, e, ctx), 2)
+
+	query(t, e, ctx, "BEGIN")
+	query(t, e, ctx, "INSERT INTO t VALUES (2)")
+	query(t, e, ctx, "COMMIT")
+	all := events(t, e, ctx)
+	require.Len(all, 7)
+	require.Equal([2]string{"Write_rows", "table_id: 1 flags: STMT_END_F"}, all[5])
+}
+
+func TestShowBinlogEvents(t *testing.T) {
+	require := require.New(t)
+	e := binlogEngine(t)
+	ctx := newContext()
+	query(t, e, ctx, "CREATE TABLE t (i int)")
+
+	rows := query(t, e, ctx, "SHOW BINLOG EVENTS")
+	require.Len(rows, 4)
+	require.Equal(sql.Row{"binlog.000001", uint64(4), "Format_desc", uint32(7), rows[1][1], "Server ver: 8.0.11-go-mysql-server, Binlog ver: 4"}, rows[0])
+
+	from := rows[2][1].(uint64)
+	require.Equal(rows[2:], query(t, e, ctx, fmt.Sprintf("SHOW BINLOG EVENTS IN 'binlog.000001' FROM %d", from)))
+	require.Equal(rows[1:2], query(t, e, ctx, "SHOW BINLOG EVENTS LIMIT 1, 1"))
+	require.Equal(rows[:1], query(t, e, ctx, "show binlog events limit 1"))
+
+	_, _, err := e.Query(ctx, "SHOW BINLOG EVENTS IN 'binlog.000009'")
+	require.True(sql.ErrBinaryLogNotFound.Is(err))
+}
+
+func TestNoBinaryLog(t *testing.T) {
+	require := require.New(t)
+	e := sqle.NewDefault()
+	ctx := newContext()
+	e.Catalog.AddDatabase(memory.NewDatabase("mydb"))
+
+	require.Empty(query(t, e, ctx, "SHOW MASTER STATUS"))
+
+	_, _, err := e.Query(ctx, "SHOW BINARY LOGS")
+	require.True(sql.ErrNoBinaryLog.Is(err))
+}
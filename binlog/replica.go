@@ -0,0 +1,469 @@
+package binlog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ErrAutoPosition is returned when a replica is given a position in the
+// binary log of its source while it asks for the transactions by GTID.
+var ErrAutoPosition = errors.NewKind("Parameters SOURCE_LOG_FILE and SOURCE_LOG_POS cannot be set when SOURCE_AUTO_POSITION is active")
+
+const (
+	// defaultSourcePort is the port of the source if none is set.
+	defaultSourcePort = 3306
+	// defaultConnectRetry is the time between the attempts to connect to the
+	// source if none is set.
+	defaultConnectRetry = 60 * time.Second
+	// defaultHeartbeatPeriod is the period of the heartbeats the source
+	// sends while there are no events if none is set, half of the default
+	// of replica_net_timeout.
+	defaultHeartbeatPeriod = 30 * time.Second
+	// defaultReplicaUser is the user of the sessions the statements of the
+	// source are run in if none is set.
+	defaultReplicaUser = "root"
+)
+
+// The states of the replica threads SHOW REPLICA STATUS shows.
+const (
+	runningYes        = "Yes"
+	runningNo         = "No"
+	runningConnecting = "Connecting"
+)
+
+// Querier runs the statements the source writes in the binary log, such as
+// DDL. *sqle.Engine is a Querier.
+type Querier interface {
+	Query(ctx *sql.Context, query string) (sql.Schema, sql.RowIter, error)
+}
+
+// ReplicaConfig of a Replica.
+type ReplicaConfig struct {
+	// ServerID is the server_id the replica connects to the source with,
+	// @@server_id if 0. It must be different from the one of the source.
+	ServerID uint32
+	// User is the user of the sessions the statements of the source are run
+	// in, root if empty. The rows changed are written to the tables without
+	// checking privileges.
+	User string
+}
+
+// Replica applies the changes of the binary log of a MySQL source to the
+// databases of the engine: it connects to the source, asks for the events
+// after the last transaction applied, and applies the rows of the row-based
+// events with the editors of the tables, and the statements with the engine.
+// It reconnects every SOURCE_CONNECT_RETRY seconds when the connection
+// fails, and stops when an event can't be applied.
+type Replica struct {
+	catalog  *sql.Catalog
+	querier  Querier
+	serverID uint32
+	user     string
+
+	mu         sync.Mutex
+	configured bool
+	source     source
+	status     sql.ReplicaStatus
+	executed   mysql.Mysql56GTIDSet
+	retrieved  mysql.Mysql56GTIDSet
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// source is the source of a replica, and the position after the last
+// transaction applied.
+type source struct {
+	host            string
+	port            uint16
+	user            string
+	password        string
+	file            string
+	pos             uint64
+	autoPosition    bool
+	connectRetry    time.Duration
+	heartbeatPeriod time.Duration
+}
+
+var _ sql.Replica = (*Replica)(nil)
+
+// NewReplica creates a Replica of the databases of a catalog, which runs the
+// statements of the source with the Querier given.
+func NewReplica(catalog *sql.Catalog, querier Querier, cfg ReplicaConfig) (*Replica, error) {
+	if cfg.ServerID == 0 {
+		id, err := sql.Uint64.Convert(globalVariable(catalog.SystemVariables, "server_id"))
+		if err != nil {
+			return nil, err
+		}
+		cfg.ServerID = uint32(id.(uint64))
+	}
+	if cfg.User == "" {
+		cfg.User = defaultReplicaUser
+	}
+
+	return &Replica{
+		catalog:  catalog,
+		querier:  querier,
+		serverID: cfg.ServerID,
+		user:     cfg.User,
+		source: source{
+			port:            defaultSourcePort,
+			pos:             uint64(len(magic)),
+			connectRetry:    defaultConnectRetry,
+			heartbeatPeriod: defaultHeartbeatPeriod,
+		},
+		status:    sql.ReplicaStatus{IORunning: runningNo, SQLRunning: runningNo},
+		executed:  mysql.Mysql56GTIDSet{},
+		retrieved: mysql.Mysql56GTIDSet{},
+	}, nil
+}
+
+// ChangeSource implements the sql.Replica interface. Like MySQL, changing
+// the host or the port of the source without giving a position starts
+// from the beginning of its binary log.
+func (r *Replica) ChangeSource(ctx *sql.Context, options []sql.ReplicaOption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		return sql.ErrReplicaRunning.New()
+	}
+
+	s := r.source
+	var newSource, newPosition bool
+	for _, o := range options {
+		isString, ok := sql.ReplicaOptionIsString(o.Name)
+		if !ok {
+			return sql.ErrUnknownReplicaOption.New(o.Name)
+		}
+
+		var str string
+		var n uint64
+		if isString {
+			str, ok = o.Value.(string)
+		} else {
+			n, ok = o.Value.(uint64)
+		}
+		if !ok {
+			return sql.ErrInvalidReplicaOption.New(o.Name, o.Value)
+		}
+
+		switch o.Name {
+		case sql.ReplicaSourceHost:
+			s.host, newSource = str, true
+		case sql.ReplicaSourcePort:
+			if n == 0 || n > 65535 {
+				return sql.ErrInvalidReplicaOption.New(o.Name, n)
+			}
+			s.port, newSource = uint16(n), true
+		case sql.ReplicaSourceUser:
+			s.user = str
+		case sql.ReplicaSourcePassword:
+			s.password = str
+		case sql.ReplicaSourceLogFile:
+			s.file, newPosition = str, true
+		case sql.ReplicaSourceLogPos:
+			s.pos, newPosition = n, true
+		case sql.ReplicaSourceAutoPosition:
+			if n > 1 {
+				return sql.ErrInvalidReplicaOption.New(o.Name, n)
+			}
+			s.autoPosition = n == 1
+		case sql.ReplicaSourceConnectRetry:
+			s.connectRetry = time.Duration(n) * time.Second
+		case sql.ReplicaSourceHeartbeatPeriod:
+			s.heartbeatPeriod = time.Duration(n) * time.Second
+		}
+	}
+
+	if s.autoPosition && newPosition {
+		return ErrAutoPosition.New()
+	}
+	if newSource && !newPosition {
+		s.file, s.pos = "", uint64(len(magic))
+	}
+	if s.pos < uint64(len(magic)) {
+		s.pos = uint64(len(magic))
+	}
+
+	r.source = s
+	r.configured = true
+	r.status.SourceHost = s.host
+	r.status.SourcePort = s.port
+	r.status.SourceUser = s.user
+	r.status.ConnectRetry = uint32(s.connectRetry / time.Second)
+	r.status.SourceLogFile, r.status.ReadSourceLogPos = s.file, s.pos
+	r.status.ExecSourceLogFile, r.status.ExecSourceLogPos = s.file, s.pos
+	r.status.AutoPosition = s.autoPosition
+	return nil
+}
+
+// Start implements the sql.Replica interface.
+func (r *Replica) Start(ctx *sql.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.configured {
+		return sql.ErrReplicaNotConfigured.New()
+	}
+	if r.cancel != nil {
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	r.cancel, r.done = cancel, make(chan struct{})
+	r.status.IORunning, r.status.SQLRunning = runningConnecting, runningYes
+	r.status.LastIOErrno, r.status.LastIOError = 0, ""
+	r.status.LastSQLErrno, r.status.LastSQLError = 0, ""
+
+	go r.run(runCtx, r.done)
+	return nil
+}
+
+// Stop implements the sql.Replica interface.
+func (r *Replica) Stop(ctx *sql.Context) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Status implements the sql.Replica interface.
+func (r *Replica) Status() (sql.ReplicaStatus, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.status
+	s.RetrievedGTIDSet = r.retrieved.String()
+	s.ExecutedGTIDSet = r.executed.String()
+	return s, r.configured
+}
+
+// run replicates until the context is cancelled or an event can't be
+// applied, connecting again when the connection to the source fails.
+func (r *Replica) run(ctx context.Context, done chan struct{}) {
+	defer func() {
+		r.mu.Lock()
+		r.cancel, r.done = nil, nil
+		r.status.IORunning, r.status.SQLRunning = runningNo, runningNo
+		r.mu.Unlock()
+		close(done)
+	}()
+
+	for {
+		err := r.replicate(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err, ok := err.(applyError); ok {
+			logrus.WithError(err.err).Error("replica stopped applying the binary log of the source")
+			r.mu.Lock()
+			r.status.LastSQLErrno, r.status.LastSQLError = errorCode(err.err), err.Error()
+			r.mu.Unlock()
+			return
+		}
+
+		logrus.WithError(err).Warn("replica lost the connection to the source")
+		r.mu.Lock()
+		r.status.IORunning = runningConnecting
+		r.status.LastIOErrno, r.status.LastIOError = errorCode(err), err.Error()
+		retry := r.source.connectRetry
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+	}
+}
+
+// applyError is an error applying an event, which stops the replica.
+type applyError struct {
+	err error
+}
+
+func (e applyError) Error() string {
+	return fmt.Sprintf("Error applying the binary log of the source: %s", e.err)
+}
+
+// errorCode returns the MySQL error code of an error, or 0 if it has none.
+func errorCode(err error) uint32 {
+	if err, ok := err.(*mysql.SQLError); ok {
+		return uint32(err.Number())
+	}
+	return 0
+}
+
+// replicate connects to the source and applies its events until the
+// connection fails.
+func (r *Replica) replicate(ctx context.Context) error {
+	r.mu.Lock()
+	s := r.source
+	gtids := r.executed.SIDBlock()
+	r.mu.Unlock()
+
+	conn, err := mysql.Connect(ctx, &mysql.ConnParams{
+		Host:  s.host,
+		Port:  int(s.port),
+		Uname: s.user,
+		Pass:  s.password,
+	})
+	if err != nil {
+		return err
+	}
+
+	closed := make(chan struct{})
+	defer close(closed)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-closed:
+		}
+		conn.Close()
+	}()
+
+	// The source sends the checksums of the events to the replicas that
+	// announce they read them, and heartbeats to those wanting them.
+	period := s.heartbeatPeriod.Nanoseconds()
+	setup := fmt.Sprintf("SET @master_binlog_checksum = @@global.binlog_checksum, "+
+		"@source_binlog_checksum = @@global.binlog_checksum, "+
+		"@master_heartbeat_period = %d, @source_heartbeat_period = %d", period, period)
+	if _, err := conn.ExecuteFetch(setup, 0, false); err != nil {
+		return err
+	}
+
+	result, err := conn.ExecuteFetch("SELECT @@global.server_uuid, @@global.binlog_checksum", 1, false)
+	if err != nil {
+		return err
+	}
+	if len(result.Rows) != 1 || len(result.Rows[0]) != 2 {
+		return fmt.Errorf("unexpected result of the variables of the source")
+	}
+
+	r.mu.Lock()
+	r.status.IORunning = runningYes
+	r.status.SourceUUID = result.Rows[0][0].ToString()
+	r.mu.Unlock()
+
+	if s.autoPosition {
+		err = conn.WriteComBinlogDumpGTID(r.serverID, "", uint64(len(magic)), 0, gtids)
+	} else {
+		err = conn.WriteComBinlogDump(r.serverID, s.file, uint32(s.pos), 0)
+	}
+	if err != nil {
+		return err
+	}
+
+	session := sql.NewSessionWithClient("", sql.Client{User: r.user, Address: "localhost"}, 0)
+	sqlCtx := sql.NewContext(ctx, sql.WithSession(session))
+	a := &applier{
+		r:        r,
+		querier:  r.querier,
+		checksum: result.Rows[0][1].ToString() != "NONE",
+		tables:   make(map[uint64]*mysql.TableMap),
+	}
+
+	for {
+		packet, err := conn.ReadPacket()
+		if err != nil {
+			return err
+		}
+
+		switch packet[0] {
+		case mysql.OKPacket:
+		case mysql.ErrPacket:
+			return mysql.ParseErrorPacket(packet)
+		case mysql.EOFPacket:
+			return fmt.Errorf("the source ended the dump of its binary log")
+		default:
+			return fmt.Errorf("unexpected packet of type %d from the source", packet[0])
+		}
+
+		if err := a.apply(sqlCtx, packet[1:]); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return applyError{err}
+		}
+	}
+}
+
+// described records the server_id of the source, from its format
+// description event.
+func (r *Replica) described(serverID uint32) error {
+	if serverID == r.serverID {
+		return fmt.Errorf("replica and source have equal server ids %d", serverID)
+	}
+
+	r.mu.Lock()
+	r.status.SourceServerID = serverID
+	r.mu.Unlock()
+	return nil
+}
+
+// rotated records the file the events read next are in.
+func (r *Replica) rotated(file string, pos uint64) {
+	r.mu.Lock()
+	r.status.SourceLogFile, r.status.ReadSourceLogPos = file, pos
+	r.mu.Unlock()
+}
+
+// read records the position after the last event read.
+func (r *Replica) read(pos uint64) {
+	r.mu.Lock()
+	r.status.ReadSourceLogPos = pos
+	r.mu.Unlock()
+}
+
+// began records the GTID of a transaction read, and returns whether it was
+// already applied.
+func (r *Replica) began(gtid mysql.GTID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.executed.ContainsGTID(gtid) {
+		return true
+	}
+	if set, ok := r.retrieved.AddGTID(gtid).(mysql.Mysql56GTIDSet); ok {
+		r.retrieved = set
+	}
+	return false
+}
+
+// committed records a transaction as applied, ending at the position given
+// of the current file.
+func (r *Replica) committed(gtid mysql.GTID, pos uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if gtid != nil {
+		if set, ok := r.executed.AddGTID(gtid).(mysql.Mysql56GTIDSet); ok {
+			r.executed = set
+		}
+		sql.AddExecutedGTID(gtid)
+	}
+	if pos > 0 {
+		r.source.file, r.source.pos = r.status.SourceLogFile, pos
+		r.status.ExecSourceLogFile, r.status.ExecSourceLogPos = r.source.file, pos
+	}
+}
@@ -0,0 +1,166 @@
+package binlog_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/binlog"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+)
+
+// replicaEngine returns an engine that can replicate, whose source isn't set.
+func replicaEngine(t *testing.T) *sqle.Engine {
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	e := sqle.New(catalog, analyzer.NewBuilder(catalog).Build(), &sqle.Config{Auth: new(auth.None)})
+
+	r, err := binlog.NewReplica(catalog, e, binlog.ReplicaConfig{ServerID: 8})
+	require.NoError(t, err)
+	catalog.SetReplica(r)
+	return e
+}
+
+// waitReplica waits for the replica to apply all the transactions of the
+// source.
+func waitReplica(t *testing.T, source, replica *sqle.Engine) {
+	ctx := newContext()
+	executed := query(t, source, ctx, "SHOW MASTER STATUS")[0][4]
+	for i := 0; ; i++ {
+		status := query(t, replica, ctx, "SHOW REPLICA STATUS")
+		require.Len(t, status, 1)
+		if status[0][20] == executed {
+			return
+		}
+		require.True(t, i < 500, "the replica didn't apply %s: %v", executed, status[0])
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReplica(t *testing.T) {
+	require := require.New(t)
+
+	source := binlogEngine(t)
+	source.Auth = new(auth.None)
+	s, err := server.NewDefaultServer(server.Config{Protocol: "tcp", Address: "127.0.0.1:0", Auth: new(auth.None)}, source)
+	require.NoError(err)
+	go s.Start()
+	defer s.Close()
+	port := s.Listener.Addr().(*net.TCPAddr).Port
+
+	ctx := newContext()
+	query(t, source, ctx, `CREATE TABLE t (
+		i bigint primary key,
+		u int unsigned,
+		d decimal(10, 3),
+		f double,
+		s varchar(20),
+		c char(3),
+		b blob,
+		e enum('x', 'y'),
+		st set('p', 'q', 'r'),
+		bt bit(10),
+		dt datetime,
+		dd date,
+		tm time,
+		y year,
+		j json
+	)`)
+	query(t, source, ctx, `INSERT INTO t VALUES
+		(1, 4000000000, -12.345, 1.5, 'a', 'bc', 'blob', 'y', 'p,r', 513, '2020-01-02 03:04:05', '2020-01-02', '-12:34:56', 2021, '{"a": [1, true, null, "s"], "bb": 2.5, "c": -70000}'),
+		(2, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL)`)
+
+	replica := replicaEngine(t)
+	rctx := newContext()
+	require.Empty(query(t, replica, rctx, "SHOW REPLICA STATUS"))
+	_, _, err = replica.Query(rctx, "START REPLICA")
+	require.True(sql.ErrReplicaNotConfigured.Is(err))
+
+	query(t, replica, rctx, fmt.Sprintf("CHANGE REPLICATION SOURCE TO SOURCE_HOST = '127.0.0.1', SOURCE_PORT = %d, SOURCE_USER = 'root', SOURCE_CONNECT_RETRY = 1", port))
+	query(t, replica, rctx, "START REPLICA")
+	waitReplica(t, source, replica)
+
+	selectAll := "SELECT * FROM t ORDER BY i"
+	require.Equal(query(t, source, ctx, selectAll), query(t, replica, rctx, selectAll))
+
+	status := query(t, replica, rctx, "SHOW REPLICA STATUS")[0]
+	require.Equal("127.0.0.1", status[1])
+	require.Equal(uint16(port), status[3])
+	require.Equal("binlog.000001", status[5])
+	require.Equal("Yes", status[8])
+	require.Equal("Yes", status[9])
+	require.Equal(uint32(7), status[17])
+	require.Equal(query(t, source, ctx, "SELECT @@server_uuid")[0][0], status[18])
+	require.Equal([]sql.Row{{int64(0)}}, query(t, replica, rctx, fmt.Sprintf("SELECT WAIT_FOR_EXECUTED_GTID_SET('%s', 1)", status[20])))
+
+	_, _, err = replica.Query(rctx, "CHANGE MASTER TO MASTER_CONNECT_RETRY = 2")
+	require.True(sql.ErrReplicaRunning.Is(err))
+
+	query(t, source, ctx, "UPDATE t SET s = 'b', j = '[1, 2]' WHERE i = 1")
+	query(t, source, ctx, "DELETE FROM t WHERE i = 2")
+	waitReplica(t, source, replica)
+	require.Equal(query(t, source, ctx, selectAll), query(t, replica, rctx, selectAll))
+
+	// The replica asks for the transactions it lacks when it starts again.
+	query(t, replica, rctx, "STOP REPLICA")
+	status = query(t, replica, rctx, "SHOW REPLICA STATUS")[0]
+	require.Equal("No", status[8])
+	require.Equal("No", status[9])
+
+	query(t, source, ctx, "INSERT INTO t (i) VALUES (3)")
+	query(t, replica, rctx, "CHANGE REPLICATION SOURCE TO SOURCE_AUTO_POSITION = 1")
+	query(t, replica, rctx, "START SLAVE")
+	waitReplica(t, source, replica)
+	require.Equal(query(t, source, ctx, selectAll), query(t, replica, rctx, selectAll))
+
+	// The replica stops when a transaction can't be applied.
+	query(t, source, ctx, "CREATE TABLE u (i int)")
+	waitReplica(t, source, replica)
+	query(t, replica, rctx, "DROP TABLE u")
+	query(t, source, ctx, "INSERT INTO u VALUES (1)")
+	for i := 0; ; i++ {
+		status = query(t, replica, rctx, "SHOW REPLICA STATUS")[0]
+		if status[9] == "No" {
+			break
+		}
+		require.True(i < 500, "the replica didn't stop")
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Contains(status[16], "table not found: u")
+	query(t, replica, rctx, "STOP REPLICA")
+}
+
+func TestChangeReplicationSource(t *testing.T) {
+	require := require.New(t)
+	replica := replicaEngine(t)
+	ctx := newContext()
+
+	_, _, err := replica.Query(ctx, "CHANGE REPLICATION SOURCE TO SOURCE_AUTO_POSITION = 1, SOURCE_LOG_POS = 4")
+	require.True(binlog.ErrAutoPosition.Is(err))
+	_, _, err = replica.Query(ctx, "CHANGE REPLICATION SOURCE TO SOURCE_PORT = 70000")
+	require.True(sql.ErrInvalidReplicaOption.Is(err))
+
+	query(t, replica, ctx, "CHANGE MASTER TO MASTER_HOST = 'db', MASTER_USER = 'repl', MASTER_PASSWORD = 'pw', MASTER_LOG_FILE = 'binlog.000002', MASTER_LOG_POS = 154")
+	status := query(t, replica, ctx, "SHOW SLAVE STATUS")
+	require.Equal([]sql.Row{{
+		"", "db", "repl", uint16(3306), uint32(60), "binlog.000002", uint64(154), "binlog.000002", "No", "No",
+		uint32(0), "", uint64(154), uint32(0), "", uint32(0), "", uint32(0), "", "", "", int8(0),
+	}}, status)
+
+	// A new source is read from the start of its binary log.
+	query(t, replica, ctx, "CHANGE REPLICATION SOURCE TO SOURCE_HOST = 'db2'")
+	status = query(t, replica, ctx, "SHOW REPLICA STATUS")
+	require.Equal("", status[0][5])
+	require.Equal(uint64(4), status[0][6])
+
+	_, _, err = sqle.NewDefault().Query(ctx, "START REPLICA")
+	require.True(sql.ErrNoReplica.Is(err))
+}
@@ -0,0 +1,477 @@
+package binlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/vt/proto/query"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ErrUnsupportedType is returned when a table has a column of a type that
+// can't be written to the log.
+var ErrUnsupportedType = errors.NewKind("binary log: unsupported type %s of column %s")
+
+// The real types of CHAR columns, ENUM and SET, which are written as
+// MYSQL_TYPE_STRING.
+const (
+	realTypeEnum   = 247
+	realTypeSet    = 248
+	realTypeString = 254
+)
+
+// table is a table as the table map events describe it.
+type table struct {
+	db, name string
+	schema   sql.Schema
+	columns  []column
+}
+
+// column is the type of a column as written to the log, with the metadata
+// needed to read its values.
+type column struct {
+	typ      byte
+	metadata []byte
+	nullable bool
+	encode   func(b []byte, v interface{}) ([]byte, error)
+}
+
+func newTable(db, name string, schema sql.Schema) (*table, error) {
+	t := &table{db: db, name: name, schema: schema, columns: make([]column, len(schema))}
+	for i, c := range schema {
+		col, err := newColumn(c.Type)
+		if err != nil {
+			return nil, ErrUnsupportedType.New(c.Type, c.Name)
+		}
+		col.nullable = c.Nullable
+		t.columns[i] = col
+	}
+	return t, nil
+}
+
+// newColumn returns how the values of a type are written, as MySQL 8.0
+// writes the values of its columns of the same type.
+func newColumn(t sql.Type) (column, error) {
+	switch t.Type() {
+	case query.Type_INT8, query.Type_UINT8:
+		return column{typ: mysql.TypeTiny, encode: encodeInteger(t, 1)}, nil
+	case query.Type_INT16, query.Type_UINT16:
+		return column{typ: mysql.TypeShort, encode: encodeInteger(t, 2)}, nil
+	case query.Type_INT24, query.Type_UINT24:
+		return column{typ: mysql.TypeInt24, encode: encodeInteger(t, 3)}, nil
+	case query.Type_INT32, query.Type_UINT32:
+		return column{typ: mysql.TypeLong, encode: encodeInteger(t, 4)}, nil
+	case query.Type_INT64, query.Type_UINT64:
+		return column{typ: mysql.TypeLongLong, encode: encodeInteger(t, 8)}, nil
+	case query.Type_FLOAT32:
+		return column{typ: mysql.TypeFloat, metadata: []byte{4}, encode: encodeFloat}, nil
+	case query.Type_FLOAT64:
+		return column{typ: mysql.TypeDouble, metadata: []byte{8}, encode: encodeDouble}, nil
+	case query.Type_DECIMAL:
+		dt := t.(sql.DecimalType)
+		return column{
+			typ:      mysql.TypeNewDecimal,
+			metadata: []byte{dt.Precision(), dt.Scale()},
+			encode:   encodeDecimal(dt),
+		}, nil
+	case query.Type_DATE:
+		return column{typ: mysql.TypeDate, encode: encodeDate(t)}, nil
+	case query.Type_DATETIME:
+		return column{typ: mysql.TypeDateTime2, metadata: []byte{0}, encode: encodeDatetime(t)}, nil
+	case query.Type_TIMESTAMP:
+		return column{typ: mysql.TypeTimestamp2, metadata: []byte{0}, encode: encodeTimestamp(t)}, nil
+	case query.Type_TIME:
+		return column{typ: mysql.TypeTime2, metadata: []byte{0}, encode: encodeTime(t.(sql.TimeType))}, nil
+	case query.Type_YEAR:
+		return column{typ: mysql.TypeYear, encode: encodeYear}, nil
+	case query.Type_CHAR, query.Type_BINARY:
+		st := t.(sql.StringType)
+		max := st.MaxByteLength()
+		// The length is split between the bytes of the metadata, and the
+		// two high bits of it are written inverted in the real type.
+		real := byte(realTypeString) ^ byte((max&0x300)>>4)
+		return column{
+			typ:      mysql.TypeString,
+			metadata: []byte{real, byte(max)},
+			encode:   encodeString(t, max, t.Type() == query.Type_CHAR),
+		}, nil
+	case query.Type_VARCHAR, query.Type_VARBINARY:
+		max := t.(sql.StringType).MaxByteLength()
+		return column{
+			typ:      mysql.TypeVarchar,
+			metadata: []byte{byte(max), byte(max >> 8)},
+			encode:   encodeString(t, max, false),
+		}, nil
+	case query.Type_TEXT, query.Type_BLOB:
+		size := lengthSize(t.(sql.StringType).MaxByteLength())
+		return column{typ: mysql.TypeBlob, metadata: []byte{byte(size)}, encode: encodeBlob(t, size)}, nil
+	case query.Type_ENUM:
+		et := t.(sql.EnumType)
+		size := 1
+		if et.NumberOfElements() > 255 {
+			size = 2
+		}
+		return column{typ: mysql.TypeString, metadata: []byte{realTypeEnum, byte(size)}, encode: encodeEnum(et, size)}, nil
+	case query.Type_SET:
+		st := t.(sql.SetType)
+		size := (int(st.NumberOfElements()) + 7) / 8
+		return column{typ: mysql.TypeString, metadata: []byte{realTypeSet, byte(size)}, encode: encodeSet(st, size)}, nil
+	case query.Type_BIT:
+		bits := t.(sql.BitType).NumberOfBits()
+		return column{typ: mysql.TypeBit, metadata: []byte{bits % 8, bits / 8}, encode: encodeBit(t, (int(bits)+7)/8)}, nil
+	case query.Type_JSON:
+		return column{typ: mysql.TypeJSON, metadata: []byte{4}, encode: encodeJSON}, nil
+	default:
+		return column{}, ErrUnsupportedType.New(t, "")
+	}
+}
+
+// encodeRow appends the values of a row to the rows of a rows event: the
+// bitmap of its NULL values, then the values that aren't NULL.
+func (t *table) encodeRow(b []byte, row sql.Row) ([]byte, error) {
+	nulls := make([]byte, (len(t.columns)+7)/8)
+	for i, v := range row {
+		if v == nil {
+			nulls[i/8] |= 1 << uint(i%8)
+		}
+	}
+	b = append(b, nulls...)
+
+	for i, v := range row {
+		if v == nil {
+			continue
+		}
+
+		var err error
+		if b, err = t.columns[i].encode(b, v); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// lengthSize returns the number of bytes the length of values with the
+// maximum length given is written with.
+func lengthSize(max int64) int {
+	switch {
+	case max < 1<<8:
+		return 1
+	case max < 1<<16:
+		return 2
+	case max < 1<<24:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func appendLength(b []byte, n, size int) []byte {
+	for i := 0; i < size; i++ {
+		b = append(b, byte(n>>(8*uint(i))))
+	}
+	return b
+}
+
+func encodeInteger(t sql.Type, size int) func([]byte, interface{}) ([]byte, error) {
+	return func(b []byte, v interface{}) ([]byte, error) {
+		var n uint64
+		if sql.IsUnsigned(t) {
+			u, err := sql.Uint64.Convert(v)
+			if err != nil {
+				return nil, err
+			}
+			n = u.(uint64)
+		} else {
+			i, err := sql.Int64.Convert(v)
+			if err != nil {
+				return nil, err
+			}
+			n = uint64(i.(int64))
+		}
+
+		for i := 0; i < size; i++ {
+			b = append(b, byte(n>>(8*uint(i))))
+		}
+		return b, nil
+	}
+}
+
+func encodeFloat(b []byte, v interface{}) ([]byte, error) {
+	f, err := sql.Float32.Convert(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], math.Float32bits(f.(float32)))
+	return append(b, buf[:]...), nil
+}
+
+func encodeDouble(b []byte, v interface{}) ([]byte, error) {
+	f, err := sql.Float64.Convert(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f.(float64)))
+	return append(b, buf[:]...), nil
+}
+
+// decimalDigitBytes are the bytes the groups of less than 9 digits of a
+// DECIMAL are written with, by number of digits.
+var decimalDigitBytes = []int{0, 1, 1, 2, 2, 3, 3, 4, 4, 4}
+
+// encodeDecimal writes decimals in the binary format of MySQL: the digits of
+// the integer and the fractional parts in groups of 9 in big endian, the
+// first of the integer part and the last of the fractional one smaller if
+// they have less digits. The bytes of negative numbers are inverted, and the
+// first bit is inverted so that they compare as bytes.
+func encodeDecimal(t sql.DecimalType) func([]byte, interface{}) ([]byte, error) {
+	scale := int(t.Scale())
+	intg := int(t.Precision()) - scale
+
+	return func(b []byte, v interface{}) ([]byte, error) {
+		d, err := t.ConvertToDecimal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		negative := d.Decimal.Sign() < 0
+		digits := strings.SplitN(d.Decimal.Abs().StringFixed(int32(scale)), ".", 2)
+		integer := strings.Repeat("0", intg) + digits[0]
+		integer = integer[len(integer)-intg:]
+		var frac string
+		if len(digits) > 1 {
+			frac = digits[1]
+		}
+
+		start := len(b)
+		b = appendDecimalDigits(b, integer[:intg%9])
+		for i := intg % 9; i < intg; i += 9 {
+			b = appendDecimalDigits(b, integer[i:i+9])
+		}
+		for i := 0; i+9 <= scale; i += 9 {
+			b = appendDecimalDigits(b, frac[i:i+9])
+		}
+		b = appendDecimalDigits(b, frac[scale-scale%9:])
+
+		if negative {
+			for i := start; i < len(b); i++ {
+				b[i] ^= 0xff
+			}
+		}
+		if len(b) > start {
+			b[start] ^= 0x80
+		}
+		return b, nil
+	}
+}
+
+func appendDecimalDigits(b []byte, digits string) []byte {
+	var n uint32
+	for _, d := range digits {
+		n = n*10 + uint32(d-'0')
+	}
+
+	for i := decimalDigitBytes[len(digits)] - 1; i >= 0; i-- {
+		b = append(b, byte(n>>(8*uint(i))))
+	}
+	return b
+}
+
+func toTime(t sql.Type, v interface{}) (time.Time, error) {
+	v, err := t.Convert(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	tm, ok := v.(time.Time)
+	if !ok {
+		return time.Time{}, sql.ErrConvertingToTime.New(v)
+	}
+	return tm, nil
+}
+
+func encodeDate(t sql.Type) func([]byte, interface{}) ([]byte, error) {
+	return func(b []byte, v interface{}) ([]byte, error) {
+		tm, err := toTime(t, v)
+		if err != nil {
+			return nil, err
+		}
+
+		n := tm.Day() | int(tm.Month())<<5 | tm.Year()<<9
+		return appendLength(b, n, 3), nil
+	}
+}
+
+func encodeDatetime(t sql.Type) func([]byte, interface{}) ([]byte, error) {
+	return func(b []byte, v interface{}) ([]byte, error) {
+		tm, err := toTime(t, v)
+		if err != nil {
+			return nil, err
+		}
+
+		ymd := uint64((tm.Year()*13+int(tm.Month()))<<5 | tm.Day())
+		hms := uint64(tm.Hour()<<12 | tm.Minute()<<6 | tm.Second())
+		n := (ymd<<17 | hms) + 0x8000000000
+		return append(b, byte(n>>32), byte(n>>24), byte(n>>16), byte(n>>8), byte(n)), nil
+	}
+}
+
+func encodeTimestamp(t sql.Type) func([]byte, interface{}) ([]byte, error) {
+	return func(b []byte, v interface{}) ([]byte, error) {
+		tm, err := toTime(t, v)
+		if err != nil {
+			return nil, err
+		}
+
+		var buf [4]byte
+		binary.BigEndian.PutUint32(buf[:], uint32(tm.Unix()))
+		return append(b, buf[:]...), nil
+	}
+}
+
+func encodeTime(t sql.TimeType) func([]byte, interface{}) ([]byte, error) {
+	return func(b []byte, v interface{}) ([]byte, error) {
+		d, err := t.ConvertToTimeDuration(v)
+		if err != nil {
+			return nil, err
+		}
+
+		negative := d < 0
+		if negative {
+			d = -d
+		}
+		secs := int64(d / time.Second)
+		n := secs/3600<<12 | secs/60%60<<6 | secs%60
+		if negative {
+			n = -n
+		}
+		n += 0x800000
+		return append(b, byte(n>>16), byte(n>>8), byte(n)), nil
+	}
+}
+
+func encodeYear(b []byte, v interface{}) ([]byte, error) {
+	y, err := sql.Int64.Convert(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if year := y.(int64); year > 0 {
+		return append(b, byte(year-1900)), nil
+	}
+	return append(b, 0), nil
+}
+
+func toBytes(t sql.Type, v interface{}) ([]byte, error) {
+	v, err := t.Convert(v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	default:
+		return nil, sql.ErrInvalidType.New(v)
+	}
+}
+
+// encodeString writes strings with their length in the bytes needed for the
+// maximum length given. MySQL doesn't write the trailing spaces of CHAR
+// values.
+func encodeString(t sql.Type, max int64, trim bool) func([]byte, interface{}) ([]byte, error) {
+	size := 1
+	if max > 255 {
+		size = 2
+	}
+
+	return func(b []byte, v interface{}) ([]byte, error) {
+		s, err := toBytes(t, v)
+		if err != nil {
+			return nil, err
+		}
+
+		if trim {
+			s = bytes.TrimRight(s, " ")
+		}
+		b = appendLength(b, len(s), size)
+		return append(b, s...), nil
+	}
+}
+
+func encodeBlob(t sql.Type, size int) func([]byte, interface{}) ([]byte, error) {
+	return func(b []byte, v interface{}) ([]byte, error) {
+		s, err := toBytes(t, v)
+		if err != nil {
+			return nil, err
+		}
+
+		b = appendLength(b, len(s), size)
+		return append(b, s...), nil
+	}
+}
+
+func encodeEnum(t sql.EnumType, size int) func([]byte, interface{}) ([]byte, error) {
+	return func(b []byte, v interface{}) ([]byte, error) {
+		i, err := t.ConvertToIndex(v)
+		if err != nil {
+			return nil, err
+		}
+		return appendLength(b, i, size), nil
+	}
+}
+
+func encodeSet(t sql.SetType, size int) func([]byte, interface{}) ([]byte, error) {
+	return func(b []byte, v interface{}) ([]byte, error) {
+		bits, err := t.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < size; i++ {
+			b = append(b, byte(bits>>(8*uint(i))))
+		}
+		return b, nil
+	}
+}
+
+func encodeBit(t sql.Type, size int) func([]byte, interface{}) ([]byte, error) {
+	return func(b []byte, v interface{}) ([]byte, error) {
+		v, err := t.Convert(v)
+		if err != nil {
+			return nil, err
+		}
+
+		bits := v.(uint64)
+		for i := size - 1; i >= 0; i-- {
+			b = append(b, byte(bits>>(8*uint(i))))
+		}
+		return b, nil
+	}
+}
+
+func encodeJSON(b []byte, v interface{}) ([]byte, error) {
+	doc, err := sql.JSON.Convert(v)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := marshalJSON(doc.([]byte))
+	if err != nil {
+		return nil, err
+	}
+
+	b = appendLength(b, len(data), 4)
+	return append(b, data...), nil
+}
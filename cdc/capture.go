@@ -0,0 +1,194 @@
+package cdc
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// Record returns the node to run for a query instead of its analyzed node,
+// so that the changes the query makes are delivered once its rows have been
+// read and it's closed.
+func (f *Feed) Record(ctx *sql.Context, parsed, analyzed sql.Node) sql.Node {
+	if f == nil {
+		return analyzed
+	}
+	return Capture(ctx, parsed, analyzed, f.commit)
+}
+
+// Capture returns the node to run for a query instead of its analyzed node,
+// so that the changes a statement makes to the rows of its table are passed
+// to commit once its rows have been read and it's closed, along with the
+// database of the table. The changes of a statement run outside of a
+// transaction are passed even if it fails, since it changes the tables as it
+// runs. In the open transaction of a sql.TransactionHookSession, they're
+// passed once the transaction commits, and dropped if it's rolled back. The
+// changes made by triggers aren't captured.
+func Capture(ctx *sql.Context, parsed, analyzed sql.Node, commit func(db string, table sql.Table, changes []Change)) sql.Node {
+	db := targetDatabase(ctx, parsed)
+	n, err := plan.TransformUp(analyzed, func(n sql.Node) (sql.Node, error) {
+		acc, ok := n.(*plan.RowUpdateAccumulator)
+		if !ok {
+			return n, nil
+		}
+
+		t := targetTable(acc.Child)
+		if t == nil {
+			return n, nil
+		}
+		return acc.WithChildren(&captureNode{
+			UnaryNode: plan.UnaryNode{Child: acc.Child},
+			commit:    commit,
+			db:        db,
+			table:     t,
+			typ:       acc.RowUpdateType,
+		})
+	})
+	if err != nil {
+		return analyzed
+	}
+	return n
+}
+
+// targetDatabase returns the database of the table a statement writes to,
+// the current one if the statement doesn't name it.
+func targetDatabase(ctx *sql.Context, parsed sql.Node) string {
+	var db string
+	plan.Inspect(parsed, func(n sql.Node) bool {
+		if t, ok := n.(*plan.UnresolvedTable); ok {
+			db = t.Database
+			return false
+		}
+		return db == ""
+	})
+
+	if db == "" {
+		return ctx.GetCurrentDatabase()
+	}
+	return db
+}
+
+// targetTable returns the table a node changes the rows of, which is the
+// first one in it, or the one deleted from by a deletion joining several.
+// The changes of deletions from several tables aren't captured.
+func targetTable(n sql.Node) sql.Table {
+	if d, ok := n.(*plan.DeleteFrom); ok && len(d.Targets) > 0 {
+		tables, err := d.TargetTables()
+		if err != nil || len(tables) != 1 {
+			return nil
+		}
+		n = tables[0]
+	}
+
+	var table sql.Table
+	plan.Inspect(n, func(n sql.Node) bool {
+		if t, ok := n.(sql.Table); ok && table == nil {
+			table = t
+		}
+		return table == nil
+	})
+	return table
+}
+
+// captureNode is the child of a RowUpdateAccumulator that keeps the changes
+// to the rows it accumulates, to commit them once it's closed.
+type captureNode struct {
+	plan.UnaryNode
+	commit func(db string, table sql.Table, changes []Change)
+	db     string
+	table  sql.Table
+	typ    plan.RowUpdateType
+}
+
+func (n *captureNode) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	iter, err := n.Child.RowIter(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	return &captureIter{ctx: ctx, iter: iter, node: n, schema: n.table.Schema()}, nil
+}
+
+func (n *captureNode) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 1)
+	}
+	nn := *n
+	nn.Child = children[0]
+	return &nn, nil
+}
+
+func (n *captureNode) String() string {
+	return n.Child.String()
+}
+
+type captureIter struct {
+	ctx     *sql.Context
+	iter    sql.RowIter
+	node    *captureNode
+	schema  sql.Schema
+	changes []Change
+}
+
+func (i *captureIter) Next() (sql.Row, error) {
+	row, err := i.iter.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	if c, ok := i.change(row); ok {
+		i.changes = append(i.changes, c)
+	}
+	return row, nil
+}
+
+// change returns the change of a row the child of the accumulator returns,
+// and whether it changed anything.
+func (i *captureIter) change(row sql.Row) (Change, bool) {
+	n := len(i.schema)
+	switch i.node.typ {
+	case plan.UpdateTypeInsert:
+		return Change{Type: Insert, After: row}, true
+	case plan.UpdateTypeDelete:
+		return Change{Type: Delete, Before: row}, true
+	case plan.UpdateTypeReplace:
+		// The first half of the row is only set if a row was deleted, and
+		// then it's the row inserted rather than the one deleted: it's an
+		// update of the row with the same key, as MySQL writes the REPLACE
+		// statements of tables without other unique keys to its binary log.
+		before, after := row[:n], row[n:]
+		for _, v := range before {
+			if v != nil {
+				return Change{Type: Update, Before: before, After: after}, true
+			}
+		}
+		return Change{Type: Insert, After: after}, true
+	default:
+		// Updates, and inserts that update a duplicate row.
+		if len(row) == n {
+			return Change{Type: Insert, After: row}, true
+		}
+
+		before, after := row[:n], row[n:]
+		if equal, err := before.Equals(after, i.schema); err == nil && equal {
+			return Change{}, false
+		}
+		return Change{Type: Update, Before: before, After: after}, true
+	}
+}
+
+func (i *captureIter) Close() error {
+	err := i.iter.Close()
+	n, changes := i.node, i.changes
+	i.changes = nil
+
+	if s, ok := i.ctx.Session.(sql.TransactionHookSession); ok && len(changes) > 0 {
+		s.OnTransactionEnd(func(committed bool) {
+			if committed {
+				n.commit(n.db, n.table, changes)
+			}
+		})
+		return err
+	}
+
+	n.commit(n.db, n.table, changes)
+	return err
+}
@@ -0,0 +1,170 @@
+// Package cdc streams the changes committed to the rows of the tables of an
+// engine to consumers in the same process, such as caches to invalidate or
+// indexes to update. Every statement that changes rows is a transaction of
+// its own, delivered once the statement is done with the images of the rows
+// before and after their changes, in the order the transactions committed.
+// The statements run in the transactions of sessions implementing
+// sql.TransactionHookSession, like the ones of memory.NewSession, are
+// delivered once their transaction commits, and never if it's rolled back.
+package cdc
+
+import (
+	"sync"
+	"time"
+
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ErrSubscriptionOverflow is the error of a subscription closed because its
+// consumer didn't keep up with the transactions.
+var ErrSubscriptionOverflow = errors.NewKind("cdc: subscription closed after more than %d pending transactions")
+
+// ChangeType is the type of a change to a row.
+type ChangeType byte
+
+const (
+	// Insert is the change of a row inserted, which only has an image after.
+	Insert ChangeType = iota + 1
+	// Update is the change of a row updated, which has images before and
+	// after.
+	Update
+	// Delete is the change of a row deleted, which only has an image before.
+	Delete
+)
+
+// String returns the name of the change type.
+func (t ChangeType) String() string {
+	switch t {
+	case Insert:
+		return "insert"
+	case Update:
+		return "update"
+	case Delete:
+		return "delete"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is a change to a row.
+type Change struct {
+	Type ChangeType
+	// Before is the row before the change, nil for inserts. The engine
+	// doesn't return the rows REPLACE statements delete, so that the updates
+	// of the rows they replace have the row after the change before it too.
+	Before sql.Row
+	// After is the row after the change, nil for deletes.
+	After sql.Row
+}
+
+// Transaction is a transaction committed: the changes a statement made to
+// the rows of a table.
+type Transaction struct {
+	// ID of the transaction, which is 1 for the first transaction of the
+	// feed and grows by one with every transaction after it.
+	ID uint64
+	// Time is when the transaction committed.
+	Time time.Time
+	// Database and Table are the table whose rows changed.
+	Database string
+	Table    string
+	// Schema is the schema of the table, which the images of the rows have.
+	Schema sql.Schema
+	// Changes are the changes to the rows, in the order they were made.
+	Changes []Change
+}
+
+// Feed delivers the transactions of an engine to its subscriptions. A nil
+// *Feed delivers nothing.
+type Feed struct {
+	mu     sync.Mutex
+	nextID uint64
+	subs   map[*Subscription]struct{}
+}
+
+// NewFeed creates a Feed without subscriptions.
+func NewFeed() *Feed {
+	return &Feed{nextID: 1, subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe returns a subscription to the transactions committed from now
+// on, which keeps at most the number given of transactions its consumer
+// hasn't received yet. When there would be more, the subscription is closed
+// with ErrSubscriptionOverflow, so that its consumer knows it missed some.
+func (f *Feed) Subscribe(buffer int) *Subscription {
+	s := &Subscription{feed: f, c: make(chan Transaction, buffer), buffer: buffer}
+	f.mu.Lock()
+	f.subs[s] = struct{}{}
+	f.mu.Unlock()
+	return s
+}
+
+// commit delivers the changes of a statement to a table to the
+// subscriptions, as a transaction.
+func (f *Feed) commit(db string, table sql.Table, changes []Change) {
+	if len(changes) == 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t := Transaction{
+		ID:       f.nextID,
+		Time:     time.Now(),
+		Database: db,
+		Table:    table.Name(),
+		Schema:   table.Schema(),
+		Changes:  changes,
+	}
+	f.nextID++
+
+	for s := range f.subs {
+		select {
+		case s.c <- t:
+		default:
+			s.close(ErrSubscriptionOverflow.New(s.buffer))
+		}
+	}
+}
+
+// Subscription is a subscription to the transactions of a Feed.
+type Subscription struct {
+	feed   *Feed
+	c      chan Transaction
+	buffer int
+	err    error
+}
+
+// Transactions returns the channel the transactions are received from,
+// which is closed when the subscription is.
+func (s *Subscription) Transactions() <-chan Transaction {
+	return s.c
+}
+
+// Err returns why the subscription was closed, once its channel is, or nil
+// if it was closed by Close.
+func (s *Subscription) Err() error {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	return s.err
+}
+
+// Close stops the subscription and closes its channel. The transactions
+// pending are still received from it.
+func (s *Subscription) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	s.close(nil)
+}
+
+func (s *Subscription) close(err error) {
+	if _, ok := s.feed.subs[s]; !ok {
+		return
+	}
+	delete(s.feed.subs, s)
+	s.err = err
+	close(s.c)
+}
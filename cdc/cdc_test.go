@@ -0,0 +1,193 @@
+package cdc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/cdc"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+)
+
+func feedEngine(f *cdc.Feed) *sqle.Engine {
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	catalog.AddDatabase(memory.NewDatabase("other"))
+	return sqle.New(catalog, analyzer.NewBuilder(catalog).Build(), &sqle.Config{Changes: f})
+}
+
+func query(t *testing.T, e *sqle.Engine, ctx *sql.Context, q string) {
+	_, iter, err := e.Query(ctx, q)
+	require.NoError(t, err)
+	_, err = sql.RowIterToRows(iter)
+	require.NoError(t, err)
+}
+
+// received returns the transactions received by a subscription so far.
+func received(s *cdc.Subscription) []cdc.Transaction {
+	var transactions []cdc.Transaction
+	for {
+		select {
+		case t, ok := <-s.Transactions():
+			if !ok {
+				return transactions
+			}
+			transactions = append(transactions, t)
+		default:
+			return transactions
+		}
+	}
+}
+
+// changes returns the tables and the changes of transactions.
+func changes(transactions []cdc.Transaction) []string {
+	var changes []string
+	for _, t := range transactions {
+		for _, c := range t.Changes {
+			changes = append(changes, t.Database+"."+t.Table+" "+c.Type.String()+" "+sql.FormatRow(c.Before)+" "+sql.FormatRow(c.After))
+		}
+	}
+	return changes
+}
+
+func TestFeed(t *testing.T) {
+	require := require.New(t)
+	f := cdc.NewFeed()
+	e := feedEngine(f)
+	ctx := sql.NewEmptyContext()
+	ctx.SetCurrentDatabase("mydb")
+
+	query(t, e, ctx, "CREATE TABLE t (i int primary key, s varchar(10))")
+	query(t, e, ctx, "INSERT INTO t VALUES (0, 'z')")
+
+	s := f.Subscribe(10)
+	defer s.Close()
+
+	query(t, e, ctx, "INSERT INTO t VALUES (1, 'a'), (2, 'b')")
+	query(t, e, ctx, "UPDATE t SET s = 'c' WHERE i >= 1")
+	// Rows that don't change aren't changes, nor statements changing none.
+	query(t, e, ctx, "UPDATE t SET s = 'c' WHERE i = 1")
+	query(t, e, ctx, "DELETE FROM t WHERE i = 5")
+	query(t, e, ctx, "REPLACE INTO t VALUES (2, 'd'), (3, 'e')")
+	query(t, e, ctx, "INSERT INTO t VALUES (3, 'e') ON DUPLICATE KEY UPDATE s = 'f'")
+	query(t, e, ctx, "DELETE FROM t WHERE i = 0")
+
+	transactions := received(s)
+	require.Len(transactions, 5)
+	for i, tx := range transactions {
+		require.Equal(uint64(i+2), tx.ID)
+		require.Equal("mydb", tx.Database)
+		require.Equal("t", tx.Table)
+		require.Equal("i", tx.Schema[0].Name)
+		require.False(tx.Time.IsZero())
+	}
+	require.Equal([]string{
+		"mydb.t insert [] [1,a]",
+		"mydb.t insert [] [2,b]",
+		"mydb.t update [1,a] [1,c]",
+		"mydb.t update [2,b] [2,c]",
+		// The engine doesn't return the row a REPLACE deletes.
+		"mydb.t update [2,d] [2,d]",
+		"mydb.t insert [] [3,e]",
+		"mydb.t update [3,e] [3,f]",
+		"mydb.t delete [0,z] []",
+	}, changes(transactions))
+
+	// The changes of the tables of other databases are delivered too.
+	ctx.SetCurrentDatabase("other")
+	query(t, e, ctx, "CREATE TABLE u (i int)")
+	ctx.SetCurrentDatabase("mydb")
+	query(t, e, ctx, "INSERT INTO other.u VALUES (1)")
+	require.Equal([]string{"other.u insert [] [1]"}, changes(received(s)))
+}
+
+func TestFeedJoinedDelete(t *testing.T) {
+	require := require.New(t)
+	f := cdc.NewFeed()
+	e := feedEngine(f)
+	ctx := sql.NewEmptyContext()
+	ctx.SetCurrentDatabase("mydb")
+
+	query(t, e, ctx, "CREATE TABLE t (i int primary key)")
+	query(t, e, ctx, "CREATE TABLE u (j int primary key)")
+	query(t, e, ctx, "INSERT INTO t VALUES (1), (2)")
+	query(t, e, ctx, "INSERT INTO u VALUES (1), (2)")
+
+	s := f.Subscribe(10)
+	defer s.Close()
+
+	// The rows deleted are the ones of the table deleted from, rather than the ones joined.
+	query(t, e, ctx, "DELETE FROM t USING t JOIN u ON t.i = u.j WHERE u.j = 2")
+	require.Equal([]string{"mydb.t delete [2] []"}, changes(received(s)))
+
+	// The changes of deletions from several tables aren't captured.
+	query(t, e, ctx, "DELETE t, u FROM t JOIN u ON t.i = u.j")
+	require.Empty(received(s))
+}
+
+func TestFeedTransactions(t *testing.T) {
+	require := require.New(t)
+	f := cdc.NewFeed()
+	e := feedEngine(f)
+	ctx := sql.NewContext(context.Background(), sql.WithSession(memory.NewSession(sql.NewBaseSession())))
+	ctx.SetCurrentDatabase("mydb")
+	query(t, e, ctx, "CREATE TABLE t (i int primary key)")
+
+	s := f.Subscribe(10)
+	defer s.Close()
+
+	// The changes made in a transaction are delivered once it commits.
+	query(t, e, ctx, "BEGIN")
+	query(t, e, ctx, "INSERT INTO t VALUES (1)")
+	query(t, e, ctx, "INSERT INTO t VALUES (2)")
+	require.Empty(received(s))
+	query(t, e, ctx, "COMMIT")
+	require.Equal([]string{"mydb.t insert [] [1]", "mydb.t insert [] [2]"}, changes(received(s)))
+
+	// The ones rolled back are never delivered.
+	query(t, e, ctx, "BEGIN")
+	query(t, e, ctx, "DELETE FROM t")
+	query(t, e, ctx, "ROLLBACK")
+	require.Empty(received(s))
+
+	query(t, e, ctx, "DELETE FROM t WHERE i = 1")
+	require.Equal([]string{"mydb.t delete [1] []"}, changes(received(s)))
+}
+
+func TestSubscriptions(t *testing.T) {
+	require := require.New(t)
+	f := cdc.NewFeed()
+	e := feedEngine(f)
+	ctx := sql.NewEmptyContext()
+	ctx.SetCurrentDatabase("mydb")
+	query(t, e, ctx, "CREATE TABLE t (i int)")
+
+	slow, fast, closed := f.Subscribe(1), f.Subscribe(10), f.Subscribe(10)
+	defer fast.Close()
+	closed.Close()
+	closed.Close()
+
+	query(t, e, ctx, "INSERT INTO t VALUES (1)")
+	query(t, e, ctx, "INSERT INTO t VALUES (2)")
+
+	// The subscriptions that fall behind are closed once their consumers
+	// received the transactions they kept.
+	require.Len(received(slow), 1)
+	_, ok := <-slow.Transactions()
+	require.False(ok)
+	require.True(cdc.ErrSubscriptionOverflow.Is(slow.Err()))
+
+	require.Len(received(fast), 2)
+	require.NoError(fast.Err())
+
+	_, ok = <-closed.Transactions()
+	require.False(ok)
+	require.NoError(closed.Err())
+
+	// An engine without a feed delivers nothing.
+	query(t, feedEngine(nil), ctx, "CREATE TABLE t (i int)")
+}
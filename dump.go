@@ -0,0 +1,66 @@
+package sqle
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// Dump writes to w the statements recreating the databases and tables given with the rows they have, like mysqldump
+// does, so that running them restores them on any engine. It dumps all the databases if no targets are given. The
+// tables that implement sql.Lockable are locked for read until the dump is written, so that it's a consistent
+// snapshot of them.
+func (e *Engine) Dump(ctx *sql.Context, w io.Writer, targets ...plan.DumpTarget) (err error) {
+	n := plan.NewDump(targets)
+	n.Catalog = e.Catalog
+
+	iter, err := n.RowIter(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := iter.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	_, err = fmt.Fprintf(w, "-- %s\n\nSET FOREIGN_KEY_CHECKS=0;\n", n)
+	if err != nil {
+		return err
+	}
+
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := writeDumpStatement(w, row[0].(string)); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "\nSET FOREIGN_KEY_CHECKS=1;\n")
+	return err
+}
+
+// writeDumpStatement writes a statement of a dump ended with a semicolon, changing the delimiter around it if it has
+// semicolons, like the statements creating triggers.
+func writeDumpStatement(w io.Writer, stmt string) error {
+	var err error
+	switch {
+	case strings.Contains(stmt, ";"):
+		_, err = fmt.Fprintf(w, "DELIMITER ;;\n%s ;;\nDELIMITER ;\n", stmt)
+	case strings.HasPrefix(stmt, "DROP TABLE "), strings.HasPrefix(stmt, "CREATE DATABASE "):
+		_, err = fmt.Fprintf(w, "\n%s;\n", stmt)
+	default:
+		_, err = fmt.Fprintf(w, "%s;\n", stmt)
+	}
+	return err
+}
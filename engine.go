@@ -1,19 +1,31 @@
 package sqle
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
+	"github.com/dolthub/vitess/go/mysql"
 	"github.com/go-kit/kit/metrics/discard"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/sirupsen/logrus"
 
+	"github.com/dolthub/go-mysql-server/audit"
 	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/binlog"
+	"github.com/dolthub/go-mysql-server/cdc"
+	"github.com/dolthub/go-mysql-server/querylog"
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/analyzer"
 	"github.com/dolthub/go-mysql-server/sql/expression/function"
 	"github.com/dolthub/go-mysql-server/sql/parse"
 	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sys"
 )
 
 // Config for the Engine.
@@ -22,6 +34,22 @@ type Config struct {
 	VersionPostfix string
 	// Auth used for authentication and authorization.
 	Auth auth.Auth
+	// Audit receives the audit events of the connections and queries, if set.
+	Audit *audit.Log
+	// QueryLog writes the general query log and the slow query log, if set.
+	QueryLog *querylog.Logger
+	// Sys gathers the statistics the views of the sys database show, if set.
+	Sys *sys.Collector
+	// Persister stores the system variables set with SET PERSIST and SET
+	// PERSIST_ONLY, if set. The values it has are set when the engine is
+	// created.
+	Persister sql.VariablePersister
+	// Binlog writes the changes made to the databases to the binary log
+	// replicas read, if set.
+	Binlog *binlog.Log
+	// Changes delivers the changes committed to the rows of the tables to
+	// the subscriptions of consumers in the process, if set.
+	Changes *cdc.Feed
 }
 
 // Engine is a SQL engine.
@@ -30,6 +58,11 @@ type Engine struct {
 	Analyzer *analyzer.Analyzer
 	Auth     auth.Auth
 	LS       *sql.LockSubsystem
+	Audit    *audit.Log
+	QueryLog *querylog.Logger
+	Sys      *sys.Collector
+	Binlog   *binlog.Log
+	Changes  *cdc.Feed
 }
 
 type ColumnWithRawDefault struct {
@@ -46,16 +79,31 @@ var (
 
 	// QueryHistogram describes a queries latency.
 	QueryHistogram = discard.NewHistogram()
+
+	// CommandCounter describes a metric that accumulates number of queries by command, such as select or insert,
+	// monotonically.
+	CommandCounter = discard.NewCounter()
+
+	// RowsWrittenCounter describes a metric that accumulates number of rows affected by queries monotonically.
+	RowsWrittenCounter = discard.NewCounter()
 )
 
+// observeQuery starts observing a query, and returns the function to call
+// once it has finished, when its rows have been read or it has failed. The
+// error a query fails with is added to the warnings of the session, for SHOW
+// ERRORS and GET DIAGNOSTICS, and sets its row count to -1.
 func observeQuery(ctx *sql.Context, query string) func(err error) {
 	logrus.WithField("query", query).Debug("executing query")
 	span, _ := ctx.Span("query", opentracing.Tag{Key: "query", Value: query})
 
+	CommandCounter.With("command", queryCommand(query)).Add(1)
+
 	t := time.Now()
 	return func(err error) {
 		if err != nil {
 			QueryErrorCounter.With("query", query, "error", err.Error()).Add(1)
+			addErrorCondition(ctx, err)
+			sql.SetLastQueryInfo(ctx, sql.RowCount, -1)
 		} else {
 			QueryCounter.With("query", query).Add(1)
 			QueryHistogram.With("query", query, "duration", "seconds").Observe(time.Since(t).Seconds())
@@ -65,12 +113,263 @@ func observeQuery(ctx *sql.Context, query string) func(err error) {
 	}
 }
 
+// addErrorCondition adds the error given to the warnings of the session, with
+// the code and SQLSTATE clients get for it.
+func addErrorCondition(ctx *sql.Context, err error) {
+	sqlErr, ok := mysql.NewSQLErrorFromError(err).(*mysql.SQLError)
+	if !ok {
+		return
+	}
+	ctx.Session.Warn(&sql.Warning{
+		Level:    "Error",
+		Code:     sqlErr.Num,
+		Message:  sqlErr.Message,
+		SQLState: sqlErr.State,
+	})
+}
+
+// queryCommand returns the type of statement of a query, which is its first
+// keyword in lowercase, such as select or insert.
+func queryCommand(query string) string {
+	query = strings.TrimLeft(query, " \t\r\n(")
+	end := strings.IndexFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if end >= 0 {
+		query = query[:end]
+	}
+
+	if query == "" {
+		return "unknown"
+	}
+	return strings.ToLower(query)
+}
+
+// comStatus are the status variables counting the statements of each
+// command.
+var comStatus = map[string]string{
+	"select":  sql.StatusComSelect,
+	"insert":  sql.StatusComInsert,
+	"update":  sql.StatusComUpdate,
+	"delete":  sql.StatusComDelete,
+	"replace": sql.StatusComReplace,
+}
+
+// writeStatus are the status variables counting the rows written by each
+// command, in the session and globally, and only globally.
+var writeStatus = map[string][2]string{
+	"insert":  {sql.StatusHandlerWrite, sql.StatusInnodbRowsInserted},
+	"replace": {sql.StatusHandlerWrite, sql.StatusInnodbRowsInserted},
+	"update":  {sql.StatusHandlerUpdate, sql.StatusInnodbRowsUpdated},
+	"delete":  {sql.StatusHandlerDelete, sql.StatusInnodbRowsDeleted},
+}
+
+// countQuery counts a query in the status variables, and returns the
+// function counting the rows it writes.
+func (e *Engine) countQuery(ctx *sql.Context, query string) func(rows uint64) {
+	e.Catalog.IncrementStatus(ctx, sql.StatusQuestions, 1)
+	e.Catalog.IncrementStatus(ctx, sql.StatusQueries, 1)
+
+	command := queryCommand(query)
+	if name, ok := comStatus[command]; ok {
+		e.Catalog.IncrementStatus(ctx, name, 1)
+	}
+
+	names, ok := writeStatus[command]
+	if !ok {
+		return nil
+	}
+	return func(rows uint64) {
+		e.Catalog.IncrementStatus(ctx, names[0], int64(rows))
+		e.Catalog.GlobalStatus.Add(names[1], int64(rows))
+	}
+}
+
+// countPlan counts the temporary tables an analyzed query needs and whether
+// it scans a table in the status variables.
+func (e *Engine) countPlan(ctx *sql.Context, query string, analyzed sql.Node) {
+	var tmpTables int64
+	var scan bool
+	plan.Inspect(analyzed, func(n sql.Node) bool {
+		switch n := n.(type) {
+		case *plan.GroupBy, *plan.Distinct, *plan.Union:
+			tmpTables++
+		case *plan.IndexedTableAccess:
+			return false
+		case *plan.DecoratedNode:
+			return n.DecorationType != plan.DecorationTypeIndexedAccess
+		case *plan.ResolvedTable:
+			if n.Name() != "" && !strings.EqualFold(n.Name(), "dual") {
+				scan = true
+			}
+		}
+		return true
+	})
+
+	if tmpTables > 0 {
+		e.Catalog.IncrementStatus(ctx, sql.StatusCreatedTmpTables, tmpTables)
+	}
+	if scan && queryCommand(query) == "select" {
+		e.Catalog.IncrementStatus(ctx, sql.StatusSelectScan, 1)
+	}
+}
+
+// observedIter is the iterator of the rows of a query that counts the rows
+// written and finishes observing the query once closed. Once a query has
+// read all its rows, it sets the row count of the session, and the rows found
+// if it returns rows and doesn't calculate them itself.
+type observedIter struct {
+	ctx       *sql.Context
+	iter      sql.RowIter
+	finish    func(err error)
+	written   func(rows uint64)
+	err       error
+	rows      bool
+	calcFound bool
+	found     int64
+	rowCount  int64
+}
+
+func (i *observedIter) Next() (sql.Row, error) {
+	row, err := i.iter.Next()
+	if err != nil {
+		if err != io.EOF && i.err == nil {
+			i.err = err
+		}
+		return nil, err
+	}
+
+	i.found++
+	if len(row) == 1 {
+		if ok, isOk := row[0].(sql.OkResult); isOk {
+			RowsWrittenCounter.Add(float64(ok.RowsAffected))
+			if i.written != nil {
+				i.written(ok.RowsAffected)
+			}
+			i.rowCount += int64(ok.RowsAffected)
+		}
+	}
+	return row, nil
+}
+
+func (i *observedIter) Close() error {
+	err := i.iter.Close()
+	if i.finish != nil {
+		if i.err == nil {
+			i.err = err
+		}
+		if i.err == nil {
+			i.setQueryInfo()
+		}
+		i.finish(i.err)
+		i.finish = nil
+	}
+	return err
+}
+
+// setQueryInfo sets the row count of the session, and the rows found for
+// queries returning rows.
+func (i *observedIter) setQueryInfo() {
+	if !i.rows {
+		sql.SetLastQueryInfo(i.ctx, sql.RowCount, i.rowCount)
+		return
+	}
+
+	sql.SetLastQueryInfo(i.ctx, sql.RowCount, -1)
+	if !i.calcFound {
+		sql.SetLastQueryInfo(i.ctx, sql.FoundRows, i.found)
+	}
+}
+
+// calcsFoundRows returns whether the analyzed query given calculates the rows
+// it finds itself, for SQL_CALC_FOUND_ROWS.
+func calcsFoundRows(analyzed sql.Node) bool {
+	var calc bool
+	plan.Inspect(analyzed, func(n sql.Node) bool {
+		if l, ok := n.(*plan.Limit); ok && l.CalcFoundRows {
+			calc = true
+		}
+		return !calc
+	})
+	return calc
+}
+
+// maxExecutionTimeVar is the system variable with the maximum execution time
+// of the SELECT statements of a session, in milliseconds.
+const maxExecutionTimeVar = "max_execution_time"
+
+// maxExecutionTimeHint matches the MAX_EXECUTION_TIME optimizer hint of a
+// SELECT statement, in the comment following its SELECT keyword.
+var maxExecutionTimeHint = regexp.MustCompile(`(?i)^[\s(]*select\s*/\*\+(?:[^*]|\*+[^*/])*?\bmax_execution_time\s*\(\s*(\d+)\s*\)`)
+
+// executionTimeout returns the maximum execution time of a query, set by
+// its MAX_EXECUTION_TIME hint or @@max_execution_time, or zero if it has
+// none. Only SELECT statements have one.
+func executionTimeout(ctx *sql.Context, query string) time.Duration {
+	if queryCommand(query) != "select" {
+		return 0
+	}
+
+	if m := maxExecutionTimeHint.FindStringSubmatch(query); m != nil {
+		if ms, err := strconv.ParseUint(m[1], 10, 32); err == nil {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	_, v := ctx.Get(maxExecutionTimeVar)
+	if v == nil {
+		return 0
+	}
+
+	ms, err := sql.Int64.Convert(v)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(ms.(int64)) * time.Millisecond
+}
+
+// timeoutIter is the iterator of the rows of a query with a maximum
+// execution time, which fails once it has been exceeded.
+type timeoutIter struct {
+	ctx    *sql.Context
+	iter   sql.RowIter
+	cancel context.CancelFunc
+}
+
+func (i *timeoutIter) Next() (sql.Row, error) {
+	if i.ctx.Err() == context.DeadlineExceeded {
+		return nil, sql.ErrQueryTimeout.New()
+	}
+
+	row, err := i.iter.Next()
+	if err != nil && err != io.EOF && i.ctx.Err() == context.DeadlineExceeded {
+		return nil, sql.ErrQueryTimeout.New()
+	}
+	return row, err
+}
+
+func (i *timeoutIter) Close() error {
+	err := i.iter.Close()
+	i.cancel()
+	return err
+}
+
 // New creates a new Engine with custom configuration. To create an Engine with
 // the default settings use `NewDefault`.
 func New(c *sql.Catalog, a *analyzer.Analyzer, cfg *Config) *Engine {
 	var versionPostfix string
+	var auditLog *audit.Log
+	var queryLog *querylog.Logger
+	var sysCollector *sys.Collector
+	var binaryLog *binlog.Log
+	var changes *cdc.Feed
 	if cfg != nil {
 		versionPostfix = cfg.VersionPostfix
+		auditLog = cfg.Audit
+		queryLog = cfg.QueryLog
+		sysCollector = cfg.Sys
+		binaryLog = cfg.Binlog
+		changes = cfg.Changes
 	}
 
 	ls := sql.NewLockSubsystem()
@@ -87,6 +386,14 @@ func New(c *sql.Catalog, a *analyzer.Analyzer, cfg *Config) *Engine {
 		sql.Function0{
 			Name: "schema",
 			Fn:   function.NewDatabase(c),
+		},
+		sql.Function0{
+			Name: "current_user",
+			Fn:   function.NewCurrentUser(c),
+		},
+		sql.Function0{
+			Name: "current_role",
+			Fn:   function.NewCurrentRole(c),
 		})
 
 	c.MustRegister(function.Defaults...)
@@ -100,7 +407,69 @@ func New(c *sql.Catalog, a *analyzer.Analyzer, cfg *Config) *Engine {
 		au = cfg.Auth
 	}
 
-	return &Engine{c, a, au, ls}
+	if um, ok := au.(sql.UserManager); ok {
+		c.SetUserManager(um)
+	}
+
+	if sp, ok := au.(sql.StatusProvider); ok {
+		c.AddStatusProvider(sp)
+	}
+
+	if binaryLog != nil {
+		c.SetBinaryLog(binaryLog)
+	}
+
+	if cfg != nil && cfg.Persister != nil {
+		c.SetVariablePersister(cfg.Persister)
+		loadPersistedVariables(sql.NewContext(context.Background(), sql.WithSystemVariables(c.SystemVariables)), cfg.Persister)
+	}
+
+	for _, names := range [][]string{sql.SessionStatusVariables, sql.GlobalStatusVariables} {
+		for _, name := range names {
+			c.GlobalStatus.Add(name, 0)
+		}
+	}
+	c.AddStatusProvider(&engineStatus{c.ProcessList, time.Now()})
+
+	return &Engine{c, a, au, ls, auditLog, queryLog, sysCollector, binaryLog, changes}
+}
+
+// loadPersistedVariables sets the global values of the variables persisted.
+// The ones that can't be set are logged and skipped, so that they don't keep
+// the engine from starting.
+func loadPersistedVariables(ctx *sql.Context, p sql.VariablePersister) {
+	persisted, err := p.Persisted(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("unable to load persisted system variables")
+		return
+	}
+
+	for name, value := range persisted {
+		if err := ctx.SystemVariables().SetPersisted(name, value); err != nil {
+			logrus.WithError(err).WithField("variable", name).Warn("unable to set persisted system variable")
+		}
+	}
+}
+
+// engineStatus provides the status variables the engine computes instead of
+// counting them: Uptime and Threads_running.
+type engineStatus struct {
+	processes *sql.ProcessList
+	start     time.Time
+}
+
+func (s *engineStatus) Status() map[string]interface{} {
+	running := make(map[uint32]struct{})
+	for _, p := range s.processes.Processes() {
+		if p.Type == sql.QueryProcess {
+			running[p.Connection] = struct{}{}
+		}
+	}
+
+	return map[string]interface{}{
+		"Uptime":          int64(time.Since(s.start).Seconds()),
+		"Threads_running": int64(len(running)),
+	}
 }
 
 // NewDefault creates a new default Engine.
@@ -116,6 +485,7 @@ func (e *Engine) AnalyzeQuery(
 	ctx *sql.Context,
 	query string,
 ) (sql.Schema, error) {
+	ctx = e.context(ctx)
 	parsed, err := parse.Parse(ctx, query)
 	if err != nil {
 		return nil, err
@@ -129,6 +499,18 @@ func (e *Engine) AnalyzeQuery(
 	return analyzed.Schema(), nil
 }
 
+// context returns the context to run the statements of the engine in, which
+// read and set the system variables of its catalog.
+func (e *Engine) context(ctx *sql.Context) *sql.Context {
+	if ctx.SystemVariables() == e.Catalog.SystemVariables {
+		return ctx
+	}
+
+	ctx = ctx.WithContext(ctx.Context)
+	ctx.ApplyOpts(sql.WithSystemVariables(e.Catalog.SystemVariables))
+	return ctx
+}
+
 // Query executes a query.
 func (e *Engine) Query(
 	ctx *sql.Context,
@@ -148,8 +530,28 @@ func (e *Engine) QueryWithBindings(
 		err              error
 	)
 
+	ctx = e.context(ctx)
 	finish := observeQuery(ctx, query)
-	defer finish(err)
+	written := e.countQuery(ctx, query)
+	defer func() {
+		if err != nil {
+			finish(err)
+		}
+	}()
+
+	start, auditCtx := time.Now(), ctx
+	e.QueryLog.Query(ctx, query)
+	defer func() {
+		if err != nil {
+			e.Audit.QueryFailed(auditCtx, query, parsed, start, err)
+			e.Sys.QueryFailed(auditCtx, query, start, err)
+		}
+	}()
+
+	// Each statement starts a new diagnostics area, except the ones reading the last one
+	if !parse.IsDiagnosticsStatement(query) {
+		ctx.ClearWarnings()
+	}
 
 	parsed, err = parse.Parse(ctx, query)
 	if err != nil {
@@ -158,15 +560,28 @@ func (e *Engine) QueryWithBindings(
 
 	var perm = auth.ReadPerm
 	var typ = sql.QueryProcess
-	switch parsed.(type) {
+	switch n := parsed.(type) {
 	case *plan.CreateIndex:
 		typ = sql.CreateIndexProcess
 		perm = auth.ReadPerm | auth.WritePerm
-	case *plan.CreateForeignKey, *plan.DropForeignKey, *plan.AlterIndex, *plan.CreateView,
+	case *plan.CreateForeignKey, *plan.DropForeignKey, *plan.CreateCheck, *plan.DropCheck, *plan.DropConstraint,
+		*plan.AlterIndex, *plan.CreateView,
 		*plan.DeleteFrom, *plan.DropIndex, *plan.DropView,
 		*plan.InsertInto, *plan.LockTables, *plan.UnlockTables,
-		*plan.Update:
+		*plan.Update, *plan.CreateUser, *plan.AlterUser, *plan.DropUser, *plan.Grant, *plan.Revoke,
+		*plan.CreateRole, *plan.DropRole, *plan.ChangeReplicationSource, *plan.StartReplica, *plan.StopReplica:
 		perm = auth.ReadPerm | auth.WritePerm
+	case *plan.SetRole, *plan.SetDefaultRole, *plan.GrantRole, *plan.RevokeRole,
+		*plan.GrantProxy, *plan.RevokeProxy:
+		// Any user can choose among the roles granted to it, which may be the
+		// only way it has to get privileges, and grant the roles and proxies it
+		// administers. The analyzer checks the privileges these statements need.
+		perm = 0
+	case *plan.SetPassword:
+		// Any user can change its own password.
+		if n.For != nil {
+			perm = auth.ReadPerm | auth.WritePerm
+		}
 	}
 
 	err = e.Auth.Allowed(ctx, perm)
@@ -174,6 +589,11 @@ func (e *Engine) QueryWithBindings(
 		return nil, nil, err
 	}
 
+	err = e.checkPasswordExpired(ctx, parsed)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	ctx, err = e.Catalog.AddProcess(ctx, typ, query)
 	defer func() {
 		if err != nil && ctx != nil {
@@ -192,16 +612,49 @@ func (e *Engine) QueryWithBindings(
 		}
 	}
 
+	timeout := executionTimeout(ctx, query)
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		var newCtx context.Context
+		newCtx, cancel = context.WithTimeout(ctx, timeout)
+		ctx = ctx.WithContext(newCtx)
+		defer func() {
+			if err != nil {
+				cancel()
+			}
+		}()
+	}
+
 	analyzed, err = e.Analyzer.Analyze(ctx, parsed, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	iter, err = analyzed.RowIter(ctx, nil)
+	e.countPlan(ctx, query, analyzed)
+
+	n := plan.NotifySchemaChanges(ctx, e.Catalog, analyzed)
+	n = e.Binlog.Record(ctx, query, parsed, n)
+	iter, err = e.Changes.Record(ctx, parsed, n).RowIter(ctx, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if timeout > 0 {
+		iter = &timeoutIter{ctx: ctx, iter: iter, cancel: cancel}
+	}
+
+	schema := analyzed.Schema()
+	iter = &observedIter{
+		ctx:       ctx,
+		iter:      iter,
+		finish:    finish,
+		written:   written,
+		rows:      len(schema) > 0 && schema[0].Name != sql.OkResultColumnName,
+		calcFound: calcsFoundRows(analyzed),
+	}
+	iter = e.Sys.TrackQuery(ctx, e.Catalog.ProcessList, query, parsed, analyzed, start, iter)
+	iter = e.QueryLog.TrackQuery(auditCtx, query, start, iter)
+	iter = e.Audit.TrackQuery(auditCtx, query, parsed, start, iter)
 	return analyzed.Schema(), iter, nil
 }
 
@@ -288,6 +741,35 @@ func ApplyDefaults(ctx *sql.Context, tblSch sql.Schema, cols []int, row sql.Row)
 	return newRow, nil
 }
 
+// checkPasswordExpired returns an error if the password of the current user
+// has expired, unless the statement given changes it or sets variables.
+func (e *Engine) checkPasswordExpired(ctx *sql.Context, n sql.Node) error {
+	switch n := n.(type) {
+	case *plan.SetPassword:
+		if n.For == nil {
+			return nil
+		}
+	case *plan.Set:
+		return nil
+	}
+
+	pm, err := e.Catalog.PasswordManager()
+	if err != nil {
+		return nil
+	}
+
+	expired, err := pm.PasswordExpired(ctx)
+	if err != nil {
+		return err
+	}
+
+	if expired {
+		return sql.ErrMustChangePassword.New()
+	}
+
+	return nil
+}
+
 // Async returns true if the query is async. If there are any errors with the
 // query it returns false
 func (e *Engine) Async(ctx *sql.Context, query string) bool {
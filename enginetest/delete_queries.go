@@ -119,12 +119,36 @@ var DeleteTests = []WriteQueryTest{
 		SelectQuery:         "SELECT * FROM mytable;",
 		ExpectedSelect:      []sql.Row{{int64(1), "first row"}, {int64(3), "third row"}},
 	},
+	{
+		WriteQuery:          "DELETE FROM mytable WHERE i > 1 ORDER BY s ASC LIMIT 1;",
+		ExpectedWriteResult: []sql.Row{{sql.NewOkResult(1)}},
+		SelectQuery:         "SELECT * FROM mytable;",
+		ExpectedSelect:      []sql.Row{{int64(1), "first row"}, {int64(3), "third row"}},
+	},
 	{
 		WriteQuery:          "DELETE FROM mytable WHERE (i,s) = (1, 'first row');",
 		ExpectedWriteResult: []sql.Row{{sql.NewOkResult(1)}},
 		SelectQuery:         "SELECT * FROM mytable;",
 		ExpectedSelect:      []sql.Row{{int64(2), "second row"}, {int64(3), "third row"}},
 	},
+	{
+		WriteQuery:          "DELETE FROM mytable USING mytable JOIN othertable ON mytable.i = othertable.i2 WHERE othertable.s2 = 'first';",
+		ExpectedWriteResult: []sql.Row{{sql.NewOkResult(1)}},
+		SelectQuery:         "SELECT * FROM mytable;",
+		ExpectedSelect:      []sql.Row{{int64(1), "first row"}, {int64(2), "second row"}},
+	},
+	{
+		WriteQuery:          "DELETE m FROM mytable AS m LEFT JOIN othertable AS o ON m.i = o.i2 AND o.s2 <> 'second' WHERE o.i2 IS NULL;",
+		ExpectedWriteResult: []sql.Row{{sql.NewOkResult(1)}},
+		SelectQuery:         "SELECT * FROM mytable;",
+		ExpectedSelect:      []sql.Row{{int64(1), "first row"}, {int64(3), "third row"}},
+	},
+	{
+		WriteQuery:          "DELETE mytable, othertable FROM mytable JOIN othertable ON mytable.i = othertable.i2 WHERE mytable.i = 1;",
+		ExpectedWriteResult: []sql.Row{{sql.NewOkResult(2)}},
+		SelectQuery:         "SELECT * FROM othertable;",
+		ExpectedSelect:      []sql.Row{{"first", int64(3)}, {"second", int64(2)}},
+	},
 }
 
 var DeleteErrorTests = []GenericErrorQueryTest{
@@ -148,6 +172,10 @@ var DeleteErrorTests = []GenericErrorQueryTest{
 		Name:  "negative offset",
 		Query: "DELETE FROM mytable LIMIT 1 OFFSET -1;",
 	},
+	{
+		Name:  "unknown table in joined delete",
+		Query: "DELETE FROM othertable USING mytable JOIN mytable AS m ON mytable.i = m.i;",
+	},
 	{
 		Name:  "missing keyword from",
 		Query: "DELETE mytable WHERE id = 1;",
@@ -32,6 +32,7 @@ import (
 	"github.com/dolthub/go-mysql-server/sql/analyzer"
 	"github.com/dolthub/go-mysql-server/sql/expression"
 	"github.com/dolthub/go-mysql-server/sql/expression/function"
+	"github.com/dolthub/go-mysql-server/sql/expression/function/aggregation"
 	"github.com/dolthub/go-mysql-server/sql/parse"
 	"github.com/dolthub/go-mysql-server/sql/plan"
 )
@@ -103,6 +104,90 @@ func TestDescribe(t *testing.T) {
 	})
 }
 
+type sumSquares struct {
+	sum int64
+}
+
+func (s *sumSquares) Update(ctx *sql.Context, args []interface{}) error {
+	v, err := sql.Int64.Convert(args[0])
+	if err != nil {
+		return err
+	}
+	if v != nil {
+		s.sum += v.(int64) * v.(int64)
+	}
+	return nil
+}
+
+func (s *sumSquares) Merge(ctx *sql.Context, other aggregation.Aggregator) error {
+	s.sum += other.(*sumSquares).sum
+	return nil
+}
+
+func (s *sumSquares) Finalize(ctx *sql.Context) (interface{}, error) {
+	return s.sum, nil
+}
+
+func TestUserAggregateFunction(t *testing.T) {
+	harness := enginetest.NewDefaultMemoryHarness()
+	e := enginetest.NewEngine(t, harness)
+	e.Catalog.MustRegister(aggregation.AggregateFunction{
+		Name:          "sum_squares",
+		ReturnType:    sql.Int64,
+		NumArgs:       1,
+		NewAggregator: func() aggregation.Aggregator { return new(sumSquares) },
+	}.Function())
+
+	enginetest.TestQuery(t, harness, e, "SELECT sum_squares(i) FROM mytable", []sql.Row{{int64(14)}}, nil)
+	enginetest.TestQuery(t, harness, e, "SELECT sum_squares(i) + 1 AS x FROM mytable", []sql.Row{{int64(15)}}, nil)
+	enginetest.TestQuery(t, harness, e, "SELECT i, sum_squares(i) FROM mytable GROUP BY i ORDER BY i", []sql.Row{
+		{int64(1), int64(1)},
+		{int64(2), int64(4)},
+		{int64(3), int64(9)},
+	}, nil)
+	enginetest.TestQuery(t, harness, e, "SELECT sum_squares(DISTINCT t.i), sum_squares(t.i) FROM tabletest t, mytable t2", []sql.Row{
+		{int64(14), int64(42)},
+	}, nil)
+}
+
+func TestRowPolicies(t *testing.T) {
+	harness := enginetest.NewDefaultMemoryHarness()
+	e := enginetest.NewEngine(t, harness)
+	e.Catalog.AddRowPolicy(sql.RowPolicyFunc(func(ctx *sql.Context, db, table string) (sql.Expression, error) {
+		if table != "mytable" {
+			return nil, nil
+		}
+		return expression.NewLessThan(
+			expression.NewUnresolvedQualifiedColumn("mytable", "i"),
+			expression.NewLiteral(int64(3), sql.Int64),
+		), nil
+	}))
+
+	enginetest.TestQuery(t, harness, e, "SELECT i FROM mytable ORDER BY i", []sql.Row{{int64(1)}, {int64(2)}}, nil)
+	enginetest.TestQuery(t, harness, e, "SELECT COUNT(*) FROM mytable", []sql.Row{{int64(2)}}, nil)
+	enginetest.TestQuery(t, harness, e, "SELECT t.i FROM mytable t WHERE t.i > 1", []sql.Row{{int64(2)}}, nil)
+	enginetest.TestQuery(t, harness, e, "SELECT a.i, b.i FROM mytable a JOIN mytable b ON a.i = b.i ORDER BY 1", []sql.Row{
+		{int64(1), int64(1)},
+		{int64(2), int64(2)},
+	}, nil)
+	enginetest.TestQuery(t, harness, e, "SELECT i FROM myview ORDER BY i", []sql.Row{{int64(1)}, {int64(2)}}, nil)
+	enginetest.TestQuery(t, harness, e, "SELECT i FROM (SELECT i FROM mytable) sq ORDER BY i", []sql.Row{{int64(1)}, {int64(2)}}, nil)
+	enginetest.TestQuery(t, harness, e, "SELECT (SELECT MAX(i) FROM mytable)", []sql.Row{{int64(2)}}, nil)
+
+	enginetest.AssertErr(t, e, harness, "INSERT INTO mytable VALUES (5, 'fifth row')", sql.ErrRowPolicyViolation)
+	enginetest.AssertErr(t, e, harness, "UPDATE mytable SET i = 5 WHERE i = 1", sql.ErrRowPolicyViolation)
+	enginetest.AssertErr(t, e, harness, "REPLACE INTO mytable VALUES (0, 'zeroth row')", sql.ErrRowPolicyNotSupported)
+	enginetest.AssertErr(t, e, harness, "DUMP TABLES mytable", sql.ErrRowPolicyNotSupported)
+
+	enginetest.TestQuery(t, harness, e, "INSERT INTO mytable VALUES (0, 'zeroth row')", []sql.Row{{sql.NewOkResult(1)}}, nil)
+	enginetest.TestQuery(t, harness, e, "UPDATE mytable SET s = 'updated'", []sql.Row{{sql.OkResult{
+		RowsAffected: 3,
+		Info:         plan.UpdateInfo{Matched: 3, Updated: 3},
+	}}}, nil)
+	enginetest.TestQuery(t, harness, e, "DELETE FROM mytable", []sql.Row{{sql.NewOkResult(3)}}, nil)
+	enginetest.TestQuery(t, harness, e, "SELECT COUNT(*) FROM mytable", []sql.Row{{int64(0)}}, nil)
+}
+
 func TestUse(t *testing.T) {
 	enginetest.TestUse(t, enginetest.NewDefaultMemoryHarness())
 }
@@ -16,6 +16,7 @@ package enginetest
 
 import (
 	"context"
+	"os"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -252,6 +253,88 @@ func TestReadOnly(t *testing.T, harness Harness) {
 	}
 }
 
+// TestSetGlobalPrivileges checks that setting the global value of system variables, which changes them for every
+// session, needs the SUPER privilege, while setting their session value needs none.
+func TestSetGlobalPrivileges(t *testing.T, harness Harness) {
+	require := require.New(t)
+
+	db := harness.NewDatabase("mydb")
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+
+	au := auth.NewNativeStore(auth.NewMemoryUserStore(
+		auth.User{Name: "root", Host: "%", Permissions: auth.AllPermissions},
+		auth.User{Name: "reader", Host: "%", Permissions: auth.ReadPerm},
+	))
+	e := sqle.New(catalog, analyzer.NewBuilder(catalog).Build(), &sqle.Config{Auth: au})
+
+	query := func(user, q string) error {
+		ctx := sql.NewContext(
+			context.Background(),
+			sql.WithSession(sql.NewSession("localhost", "127.0.0.1:3306", user, 1)),
+			sql.WithViewRegistry(sql.NewViewRegistry()),
+		).WithCurrentDB("mydb")
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return err
+		}
+		_, err = sql.RowIterToRows(iter)
+		return err
+	}
+
+	require.NoError(query("reader", "SET SESSION sql_mode = 'ANSI_QUOTES'"))
+	require.NoError(query("reader", "SET @@session.sql_select_limit = 10"))
+
+	for _, q := range []string{
+		"SET GLOBAL max_connections = 1",
+		"SET @@global.sql_mode = 'ANSI_QUOTES'",
+		"SET sql_mode = 'ANSI_QUOTES', GLOBAL max_connections = 1",
+		"SET PERSIST max_connections = 1",
+		"SET PERSIST_ONLY max_connections = 1",
+		"RESET PERSIST max_connections",
+		"RESET PERSIST",
+	} {
+		err := query("reader", q)
+		require.True(sql.ErrSpecificAccessDenied.Is(err), q)
+		require.Contains(err.Error(), "SUPER", q)
+	}
+
+	require.NoError(query("root", "SET GLOBAL max_connections = 151"))
+}
+
+// TestSystemVariablesPerEngine tests that the global values of the system variables of engines, set or persisted, are
+// their own.
+func TestSystemVariablesPerEngine(t *testing.T, harness Harness) {
+	require := require.New(t)
+
+	newEngine := func(cfg *sqle.Config) *sqle.Engine {
+		catalog := sql.NewCatalog()
+		catalog.AddDatabase(harness.NewDatabase("mydb"))
+		return sqle.New(catalog, analyzer.NewBuilder(catalog).Build(), cfg)
+	}
+
+	query := func(e *sqle.Engine, q string) []sql.Row {
+		ctx := NewContext(harness)
+		_, iter, err := e.Query(ctx, q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
+	}
+
+	persisted := newEngine(&sqle.Config{Persister: sql.NewMemoryVariablePersister(map[string]interface{}{
+		"wait_timeout": int64(120),
+	})})
+	e := newEngine(nil)
+	other := newEngine(nil)
+
+	query(e, "SET GLOBAL max_connections = 10")
+
+	require.Equal([]sql.Row{{int64(120), int64(151)}}, query(persisted, "SELECT @@global.wait_timeout, @@max_connections"))
+	require.Equal([]sql.Row{{int64(28800), int64(10)}}, query(e, "SELECT @@global.wait_timeout, @@max_connections"))
+	require.Equal([]sql.Row{{int64(28800), int64(151)}}, query(other, "SELECT @@global.wait_timeout, @@max_connections"))
+}
+
 func TestExplode(t *testing.T, harness Harness) {
 	db := harness.NewDatabase("mydb")
 	table, err := harness.NewTable(db, "t", sql.Schema{
@@ -552,6 +635,16 @@ func TestTriggers(t *testing.T, harness Harness) {
 	}
 }
 
+// TestForeignKeys tests the referential actions of foreign keys, if the harness supports them.
+func TestForeignKeys(t *testing.T, harness Harness) {
+	if fkh, ok := harness.(ForeignKeyHarness); !ok || !fkh.SupportsForeignKeys() {
+		t.Skip("harness doesn't support foreign keys")
+	}
+	for _, script := range ForeignKeyTests {
+		TestScript(t, harness, script)
+	}
+}
+
 func TestTriggerErrors(t *testing.T, harness Harness) {
 	for _, script := range TriggerErrorTests {
 		TestScript(t, harness, script)
@@ -1801,7 +1894,16 @@ func TestClearWarnings(t *testing.T, harness Harness) {
 	require.NoError(err)
 	err = iter.Close()
 	require.NoError(err)
-	require.Equal(3, len(rows))
+	// Each statement clears the warnings of the last one
+	require.Equal(1, len(rows))
+
+	_, iter, err = e.Query(ctx, "SHOW COUNT(*) WARNINGS")
+	require.NoError(err)
+	rows, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+	err = iter.Close()
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1)}}, rows)
 
 	_, iter, err = e.Query(ctx, "SHOW WARNINGS LIMIT 1")
 	require.NoError(err)
@@ -1843,6 +1945,64 @@ func TestUse(t *testing.T, harness Harness) {
 	require.Equal("foo", ctx.GetCurrentDatabase())
 }
 
+// TestDump tests that the dump of a database has the statements recreating it, its tables with their rows, views and
+// triggers.
+func TestDump(t *testing.T, harness Harness) {
+	require := require.New(t)
+
+	e := NewEngineWithDbs(t, harness, []sql.Database{harness.NewDatabase("mydb")}, nil)
+	ctx := NewContext(harness)
+
+	RunQuery(t, e, harness, "CREATE TABLE mytable (i bigint PRIMARY KEY, s varchar(20) NOT NULL)")
+	RunQuery(t, e, harness, "INSERT INTO mytable VALUES (1, 'first row'), (2, 'second row')")
+	RunQuery(t, e, harness, "CREATE TRIGGER trig BEFORE INSERT ON mytable FOR EACH ROW BEGIN SET new.s = upper(new.s); END")
+
+	var b strings.Builder
+	require.NoError(e.Dump(ctx, &b, plan.DumpTarget{Database: "mydb"}))
+	require.Equal(`-- DUMP DATABASES mydb
+
+SET FOREIGN_KEY_CHECKS=0;
+
+CREATE DATABASE IF NOT EXISTS `+"`mydb`"+`;
+USE `+"`mydb`"+`;
+
+DROP TABLE IF EXISTS `+"`mytable`"+`;
+CREATE TABLE `+"`mytable`"+` (
+  `+"`i`"+` bigint NOT NULL,
+  `+"`s`"+` varchar(20) NOT NULL,
+  PRIMARY KEY (`+"`i`"+`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+INSERT INTO `+"`mytable`"+` (`+"`i`,`s`"+`) VALUES (1,'first row'),(2,'second row');
+DROP VIEW IF EXISTS `+"`myview`"+`;
+CREATE VIEW `+"`myview`"+` AS SELECT * FROM mytable;
+DELIMITER ;;
+CREATE TRIGGER trig BEFORE INSERT ON mytable FOR EACH ROW BEGIN SET new.s = upper(new.s); END ;;
+DELIMITER ;
+
+SET FOREIGN_KEY_CHECKS=1;
+`, b.String())
+
+	RunQuery(t, e, harness, "CREATE TABLE bittable (b bit(3))")
+	RunQuery(t, e, harness, "INSERT INTO bittable VALUES (5)")
+
+	b.Reset()
+	require.NoError(e.Dump(ctx, &b, plan.DumpTarget{Database: "mydb", Tables: []string{"bittable"}}))
+	require.Contains(b.String(), "INSERT INTO `bittable` (`b`) VALUES (5);")
+
+	// The INSERT statements name the columns, so that the values of invisible ones are inserted too.
+	RunQuery(t, e, harness, "CREATE TABLE hidden (i bigint PRIMARY KEY, h bigint INVISIBLE)")
+	RunQuery(t, e, harness, "INSERT INTO hidden (i, h) VALUES (1, 2)")
+
+	b.Reset()
+	require.NoError(e.Dump(ctx, &b, plan.DumpTarget{Database: "mydb", Tables: []string{"hidden"}}))
+	insert := "INSERT INTO `hidden` (`i`,`h`) VALUES (1,2)"
+	require.Contains(b.String(), insert+";")
+
+	RunQuery(t, e, harness, "DELETE FROM hidden")
+	RunQuery(t, e, harness, insert)
+	TestQuery(t, harness, e, "SELECT i, h FROM hidden", []sql.Row{{int64(1), int64(2)}}, nil)
+}
+
 func TestSessionSelectLimit(t *testing.T, harness Harness) {
 	q := []QueryTest{
 		{
@@ -1935,6 +2095,10 @@ func AssertErr(t *testing.T, e *sqle.Engine, harness Harness, query string, expe
 	_, iter, err := e.Query(NewContext(harness), query)
 	if err == nil {
 		_, err = sql.RowIterToRows(iter)
+		if err != nil {
+			// Like the server does, close the iterator of a query failing to be read
+			_ = iter.Close()
+		}
 	}
 	require.Error(t, err)
 	if expectedErrKind != nil {
@@ -2465,6 +2629,63 @@ func TestColumnDefaults(t *testing.T, harness Harness) {
 		)
 	})
 
+	t.Run("UUID default expression", func(t *testing.T) {
+		TestQuery(t, harness, e,
+			"CREATE TABLE t30(pk BIGINT PRIMARY KEY, v1 VARCHAR(36) DEFAULT (UUID()))",
+			[]sql.Row(nil),
+			nil,
+		)
+
+		RunQuery(t, e, harness, "INSERT INTO t30 (pk) VALUES (1), (2)")
+		TestQuery(t, harness, e,
+			"SELECT COUNT(DISTINCT v1), MIN(LENGTH(v1)) FROM t30",
+			[]sql.Row{{2, 36}},
+			nil,
+		)
+	})
+
+	t.Run("Nested default expression round trip", func(t *testing.T) {
+		TestQuery(t, harness, e,
+			"CREATE TABLE t31(pk BIGINT PRIMARY KEY, v1 BIGINT, v2 BIGINT DEFAULT ((pk + v1) * 2))",
+			[]sql.Row(nil),
+			nil,
+		)
+
+		createTable := "CREATE TABLE `t31` (\n" +
+			"  `pk` bigint NOT NULL,\n" +
+			"  `v1` bigint,\n" +
+			"  `v2` bigint DEFAULT ((pk + v1) * 2),\n" +
+			"  PRIMARY KEY (`pk`)\n" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"
+		TestQuery(t, harness, e,
+			"SHOW CREATE TABLE t31",
+			[]sql.Row{{"t31", createTable}},
+			nil,
+		)
+
+		RunQuery(t, e, harness, "DROP TABLE t31")
+		RunQuery(t, e, harness, createTable)
+		RunQuery(t, e, harness, "INSERT INTO t31 (pk, v1) VALUES (1, 2)")
+		TestQuery(t, harness, e,
+			"SELECT * FROM t31",
+			[]sql.Row{{1, 2, 6}},
+			nil,
+		)
+	})
+
+	t.Run("Default expressions in information_schema", func(t *testing.T) {
+		TestQuery(t, harness, e,
+			"CREATE TABLE t32(pk BIGINT PRIMARY KEY, v1 BIGINT DEFAULT (pk * 2), v2 DATETIME DEFAULT NOW())",
+			[]sql.Row(nil),
+			nil,
+		)
+		TestQuery(t, harness, e,
+			"SELECT column_name, column_default, extra FROM information_schema.columns WHERE table_name = 't32' ORDER BY 1",
+			[]sql.Row{{"pk", nil, ""}, {"v1", "pk * 2", "DEFAULT_GENERATED"}, {"v2", "CURRENT_TIMESTAMP", "DEFAULT_GENERATED"}},
+			nil,
+		)
+	})
+
 	t.Run("Invalid literal for column type", func(t *testing.T) {
 		AssertErr(t, e, harness, "CREATE TABLE t999(pk BIGINT PRIMARY KEY, v1 INT UNSIGNED DEFAULT -1)", sql.ErrIncompatibleDefaultType)
 	})
@@ -2502,6 +2723,19 @@ func TestColumnDefaults(t *testing.T, harness Harness) {
 		AssertErr(t, e, harness, "CREATE TABLE t999(pk BIGINT PRIMARY KEY, v1 BIGINT DEFAULT (CUSTOMFUNC(1)))", sql.ErrInvalidColumnDefaultFunction)
 	})
 
+	t.Run("Default expression contains subquery", func(t *testing.T) {
+		AssertErr(t, e, harness, "CREATE TABLE t999(pk BIGINT PRIMARY KEY, v1 BIGINT DEFAULT ((SELECT 1)))", sql.ErrColumnDefaultSubquery)
+	})
+
+	t.Run("Default expression references variables", func(t *testing.T) {
+		AssertErr(t, e, harness, "CREATE TABLE t999(pk BIGINT PRIMARY KEY, v1 BIGINT DEFAULT (@v))", sql.ErrColumnDefaultVariable)
+		AssertErr(t, e, harness, "CREATE TABLE t999(pk BIGINT PRIMARY KEY, v1 BIGINT DEFAULT (@@auto_increment_increment))", sql.ErrColumnDefaultVariable)
+	})
+
+	t.Run("Default expression references auto_increment column", func(t *testing.T) {
+		AssertErr(t, e, harness, "CREATE TABLE t999(pk BIGINT PRIMARY KEY AUTO_INCREMENT, v1 BIGINT DEFAULT (pk + 1))", sql.ErrColumnDefaultAutoIncrement)
+	})
+
 	t.Run("Default expression references own column", func(t *testing.T) {
 		AssertErr(t, e, harness, "CREATE TABLE t999(pk BIGINT PRIMARY KEY, v1 BIGINT DEFAULT (v1))", sql.ErrInvalidDefaultValueOrder)
 	})
@@ -2753,3 +2987,15 @@ func WidenRow(row sql.Row) sql.Row {
 	}
 	return widened
 }
+
+// hostname returns the name of the host, as @@hostname shows it.
+func hostname() string {
+	name, _ := os.Hostname()
+	return name
+}
+
+// serverUUID returns the UUID of the server, as @@server_uuid shows it.
+func serverUUID() string {
+	uuid, _ := sql.NewSystemVariables().Global("server_uuid")
+	return uuid.(string)
+}
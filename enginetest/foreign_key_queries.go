@@ -0,0 +1,448 @@
+// Copyright 2020 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ForeignKeyTests are the scripts testing the referential actions of foreign keys, for harnesses that support them.
+var ForeignKeyTests = []ScriptTest{
+	{
+		Name: "on delete cascade through several tables",
+		SetUpScript: []string{
+			"create table parent (id int primary key, v int)",
+			"create table child (id int primary key, pid int, index (pid), constraint fk_child foreign key (pid) references parent (id) on delete cascade)",
+			"create table grandchild (id int primary key, cid int, constraint fk_grandchild foreign key (cid) references child (id) on delete cascade)",
+			"insert into parent values (1, 10), (2, 20)",
+			"insert into child values (1, 1), (2, 1), (3, 2), (4, null)",
+			"insert into grandchild values (1, 1), (2, 2), (3, 3), (4, null)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "delete from parent where id = 1",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select id from child order by id",
+				Expected: []sql.Row{{3}, {4}},
+			},
+			{
+				Query:    "select id from grandchild order by id",
+				Expected: []sql.Row{{3}, {4}},
+			},
+			{
+				Query:    "delete from parent",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select id from child order by id",
+				Expected: []sql.Row{{4}},
+			},
+			{
+				Query:    "select id from grandchild order by id",
+				Expected: []sql.Row{{4}},
+			},
+		},
+	},
+	{
+		Name: "on update cascade and set null",
+		SetUpScript: []string{
+			"create table parent (id int primary key, code varchar(10), unique index (code))",
+			"create table child (id int primary key, code varchar(10), constraint fk_code foreign key (code) references parent (code) on update cascade on delete set null)",
+			"create table grandchild (id int primary key, cid int, constraint fk_cid foreign key (cid) references child (id) on update set null)",
+			"insert into parent values (1, 'a'), (2, 'b')",
+			"insert into child values (1, 'a'), (2, 'a'), (3, 'b')",
+			"insert into grandchild values (1, 1), (2, 3)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "update parent set code = 'z' where id = 1",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:    "select id, code from child order by id",
+				Expected: []sql.Row{{1, "z"}, {2, "z"}, {3, "b"}},
+			},
+			{
+				Query:    "delete from parent where id = 2",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select id, code from child order by id",
+				Expected: []sql.Row{{1, "z"}, {2, "z"}, {3, nil}},
+			},
+			{
+				Query:    "update child set id = 30 where id = 3",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:    "select id, cid from grandchild order by id",
+				Expected: []sql.Row{{1, 1}, {2, nil}},
+			},
+		},
+	},
+	{
+		Name: "restrict and no action",
+		SetUpScript: []string{
+			"create table parent (id int primary key, v int)",
+			"create table child (id int primary key, pid int, constraint fk_restrict foreign key (pid) references parent (id) on delete restrict)",
+			"create table other (id int primary key, pid int, constraint fk_default foreign key (pid) references parent (id))",
+			"insert into parent values (1, 10), (2, 20), (3, 30)",
+			"insert into child values (1, 1)",
+			"insert into other values (1, 2)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "delete from parent where id = 1",
+				ExpectedErr: sql.ErrForeignKeyParentViolation,
+			},
+			{
+				Query:       "delete from parent where id = 2",
+				ExpectedErr: sql.ErrForeignKeyParentViolation,
+			},
+			{
+				Query:       "update parent set id = 4 where id = 1",
+				ExpectedErr: sql.ErrForeignKeyParentViolation,
+			},
+			{
+				Query:    "update parent set v = 11 where id = 1",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:    "delete from parent where id = 3",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select id from parent order by id",
+				Expected: []sql.Row{{1}, {2}},
+			},
+		},
+	},
+	{
+		Name: "self-referencing foreign keys",
+		SetUpScript: []string{
+			"create table emp (id int primary key, mgr int, constraint fk_mgr foreign key (mgr) references emp (id) on delete cascade on update cascade)",
+			"insert into emp values (1, null), (2, 1), (3, 1), (4, 2), (5, 5), (6, null)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "delete from emp where id = 1",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select id from emp order by id",
+				Expected: []sql.Row{{5}, {6}},
+			},
+			{
+				Query:    "delete from emp where id = 5",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "insert into emp values (7, 6)",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				// ON UPDATE CASCADE can't update the table it cascades from again, so it restricts the update instead
+				Query:       "update emp set id = 60 where id = 6",
+				ExpectedErr: sql.ErrForeignKeyParentViolation,
+			},
+			{
+				Query:    "update emp set id = 70 where id = 7",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+		},
+	},
+	{
+		Name: "cascade depth limit",
+		SetUpScript: []string{
+			"create table chain (id int primary key, prev int, constraint fk_prev foreign key (prev) references chain (id) on delete cascade)",
+			"insert into chain values (1, null), (2, 1), (3, 2), (4, 3), (5, 4), (6, 5), (7, 6), (8, 7), (9, 8), (10, 9)",
+			"insert into chain values (11, 10), (12, 11), (13, 12), (14, 13), (15, 14), (16, 15), (17, 16)",
+			"create table chain2 (id int primary key, prev int, constraint fk_prev2 foreign key (prev) references chain2 (id) on delete cascade)",
+			"insert into chain2 select id, prev from chain where id <= 16",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "delete from chain where id = 1",
+				ExpectedErr: sql.ErrForeignKeyDepthLimit,
+			},
+			{
+				Query:    "delete from chain2 where id = 1",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select count(*) from chain2",
+				Expected: []sql.Row{{0}},
+			},
+		},
+	},
+	{
+		Name: "tables referencing each other",
+		SetUpScript: []string{
+			"create table a (id int primary key, b_id int)",
+			"create table b (id int primary key, a_id int, constraint fk_a foreign key (a_id) references a (id) on delete cascade on update cascade)",
+			"alter table a add constraint fk_b foreign key (b_id) references b (id) on delete cascade on update cascade",
+			"insert into a values (1, null), (2, null)",
+			"insert into b values (1, 1), (2, 2)",
+			"update a set b_id = 2 where id = 1",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "update a set id = 10 where id = 1",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:    "select id, a_id from b order by id",
+				Expected: []sql.Row{{1, 10}, {2, 2}},
+			},
+			{
+				Query:    "delete from a where id = 2",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select id, b_id from a order by id",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select id, a_id from b order by id",
+				Expected: []sql.Row{},
+			},
+		},
+	},
+	{
+		Name: "child rows must reference existing rows",
+		SetUpScript: []string{
+			"create table parent (id int primary key, v int)",
+			"create table child (id int primary key, pid int, constraint fk_child foreign key (pid) references parent (id))",
+			"create table emp (id int primary key, mgr int, constraint fk_mgr foreign key (mgr) references emp (id))",
+			"insert into parent values (1, 10), (2, 20)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "insert into child values (1, 1), (2, null)",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query:       "insert into child values (3, 3)",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:       "replace into child values (1, 3)",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:       "insert into child values (1, 1) on duplicate key update pid = 3",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:       "update child set pid = 3 where id = 1",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:    "update child set pid = 2 where id = 2",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:    "insert into emp values (1, 1), (2, 1), (3, 2)",
+				Expected: []sql.Row{{sql.NewOkResult(3)}},
+			},
+			{
+				Query:       "insert into emp values (4, 5)",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:    "select id, pid from child order by id",
+				Expected: []sql.Row{{1, 1}, {2, 2}},
+			},
+		},
+	},
+	{
+		Name: "foreign_key_checks",
+		SetUpScript: []string{
+			"create table parent (id int primary key, v int)",
+			"create table child (id int primary key, pid int, v int, constraint fk_child foreign key (pid) references parent (id) on delete cascade)",
+			"insert into parent values (1, 10), (2, 20)",
+			"insert into child values (1, 1, 0), (2, 2, 0)",
+			"set foreign_key_checks = 0",
+			"create table orphan (id int primary key, nid int, constraint fk_orphan foreign key (nid) references missing (id))",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select @@foreign_key_checks",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "insert into child values (3, 3, 0)",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "delete from parent where id = 1",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "insert into orphan values (1, 1)",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "set foreign_key_checks = 1",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "select id, pid from child order by id",
+				Expected: []sql.Row{{1, 1}, {2, 2}, {3, 3}},
+			},
+			{
+				Query:    "update child set v = 1",
+				Expected: []sql.Row{{newUpdateResult(3, 3)}},
+			},
+			{
+				Query:       "update child set pid = 4 where id = 3",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:       "insert into orphan values (2, 1)",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:    "insert into orphan values (2, null)",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "delete from parent where id = 2",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select id, pid from child order by id",
+				Expected: []sql.Row{{1, 1}, {3, 3}},
+			},
+		},
+	},
+	{
+		Name: "foreign keys declared after rows were deleted",
+		SetUpScript: []string{
+			"create table parent (id int primary key)",
+			"create table child (id int primary key, pid int)",
+			"insert into parent values (1), (2), (3)",
+			"insert into child values (1, 1), (2, 2)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "delete from parent where id = 3",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "alter table child add constraint fk_child foreign key (pid) references parent (id) on delete cascade",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "delete from parent where id = 1",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select * from child",
+				Expected: []sql.Row{{2, 2}},
+			},
+			{
+				Query:    "alter table child drop foreign key fk_child",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "delete from parent where id = 2",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select * from child",
+				Expected: []sql.Row{{2, 2}},
+			},
+		},
+	},
+	{
+		Name: "adding and dropping foreign keys",
+		SetUpScript: []string{
+			"create table parent (id int primary key, v int)",
+			"create table child (id int primary key, pid int)",
+			"insert into parent values (1, 10), (2, 20)",
+			"insert into child values (1, 1), (2, 3), (3, null)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "alter table child add constraint fk_child foreign key (pid) references parent (id)",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:    "delete from child where id = 2",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "alter table child add constraint fk_child foreign key (pid) references parent (id)",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:       "insert into child values (4, 3)",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:    "alter table child drop constraint fk_child",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "insert into child values (4, 3)",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "set foreign_key_checks = 0",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "alter table child add constraint fk_child2 foreign key (pid) references parent (id)",
+				Expected: []sql.Row{},
+			},
+			{
+				Query: "show create table child",
+				Expected: []sql.Row{{"child", "CREATE TABLE `child` (\n" +
+					"  `id` int NOT NULL,\n" +
+					"  `pid` int,\n" +
+					"  PRIMARY KEY (`id`),\n" +
+					"  CONSTRAINT `fk_child2` FOREIGN KEY (`pid`) REFERENCES `parent` (`id`)\n" +
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+			},
+		},
+	},
+	{
+		Name: "unnamed foreign keys",
+		SetUpScript: []string{
+			"create table parent (id int primary key)",
+			"create table child (id int primary key, pid int, foreign key (pid) references parent (id))",
+			"alter table child add foreign key (id) references parent (id)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "show create table child",
+				Expected: []sql.Row{{"child", "CREATE TABLE `child` (\n" +
+					"  `id` int NOT NULL,\n" +
+					"  `pid` int,\n" +
+					"  PRIMARY KEY (`id`),\n" +
+					"  CONSTRAINT `child_ibfk_1` FOREIGN KEY (`pid`) REFERENCES `parent` (`id`),\n" +
+					"  CONSTRAINT `child_ibfk_2` FOREIGN KEY (`id`) REFERENCES `parent` (`id`)\n" +
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+			},
+			{
+				Query:    "alter table child drop foreign key child_ibfk_1",
+				Expected: []sql.Row{},
+			},
+		},
+	},
+}
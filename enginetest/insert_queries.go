@@ -352,7 +352,7 @@ var InsertQueries = []WriteQueryTest{
 	},
 	{
 		WriteQuery:          "INSERT INTO auto_increment_tbl (c0) values (44)",
-		ExpectedWriteResult: []sql.Row{{sql.NewOkResult(1)}},
+		ExpectedWriteResult: []sql.Row{{sql.OkResult{RowsAffected: 1, InsertID: 4}}},
 		SelectQuery:         "SELECT * FROM auto_increment_tbl ORDER BY pk",
 		ExpectedSelect: []sql.Row{
 			{1, 11},
@@ -363,7 +363,7 @@ var InsertQueries = []WriteQueryTest{
 	},
 	{
 		WriteQuery:          "INSERT INTO auto_increment_tbl (c0) values (44),(55)",
-		ExpectedWriteResult: []sql.Row{{sql.NewOkResult(2)}},
+		ExpectedWriteResult: []sql.Row{{sql.OkResult{RowsAffected: 2, InsertID: 4}}},
 		SelectQuery:         "SELECT * FROM auto_increment_tbl ORDER BY pk",
 		ExpectedSelect: []sql.Row{
 			{1, 11},
@@ -375,7 +375,7 @@ var InsertQueries = []WriteQueryTest{
 	},
 	{
 		WriteQuery:          "INSERT INTO auto_increment_tbl values (NULL, 44)",
-		ExpectedWriteResult: []sql.Row{{sql.NewOkResult(1)}},
+		ExpectedWriteResult: []sql.Row{{sql.OkResult{RowsAffected: 1, InsertID: 4}}},
 		SelectQuery:         "SELECT * FROM auto_increment_tbl ORDER BY pk",
 		ExpectedSelect: []sql.Row{
 			{1, 11},
@@ -386,7 +386,7 @@ var InsertQueries = []WriteQueryTest{
 	},
 	{
 		WriteQuery:          "INSERT INTO auto_increment_tbl values (0, 44)",
-		ExpectedWriteResult: []sql.Row{{sql.NewOkResult(1)}},
+		ExpectedWriteResult: []sql.Row{{sql.OkResult{RowsAffected: 1, InsertID: 4}}},
 		SelectQuery:         "SELECT * FROM auto_increment_tbl ORDER BY pk",
 		ExpectedSelect: []sql.Row{
 			{1, 11},
@@ -409,7 +409,7 @@ var InsertQueries = []WriteQueryTest{
 	{
 		WriteQuery: "INSERT INTO auto_increment_tbl values " +
 			"(NULL, 44), (NULL, 55), (9, 99), (NULL, 110), (NULL, 121)",
-		ExpectedWriteResult: []sql.Row{{sql.NewOkResult(5)}},
+		ExpectedWriteResult: []sql.Row{{sql.OkResult{RowsAffected: 5, InsertID: 4}}},
 		SelectQuery:         "SELECT * FROM auto_increment_tbl ORDER BY pk",
 		ExpectedSelect: []sql.Row{
 			{1, 11},
@@ -521,6 +521,76 @@ var InsertScripts = []ScriptTest{
 			},
 		},
 	},
+	{
+		Name: "alter auto_increment value below the values in use",
+		SetUpScript: []string{
+			"create table auto (pk int primary key auto_increment, c0 int)",
+			"insert into auto values (NULL,10), (NULL,20), (NULL,30)",
+			"alter table auto auto_increment = 2",
+			"insert into auto values (NULL,40)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "select * from auto order by 1",
+				Expected: []sql.Row{
+					{1, 10}, {2, 20}, {3, 30}, {4, 40},
+				},
+			},
+			{
+				Query: "show create table auto",
+				Expected: []sql.Row{
+					{"auto", "CREATE TABLE `auto` (\n" +
+						"  `pk` int NOT NULL AUTO_INCREMENT,\n" +
+						"  `c0` int,\n" +
+						"  PRIMARY KEY (`pk`)\n" +
+						") ENGINE=InnoDB AUTO_INCREMENT=5 DEFAULT CHARSET=utf8mb4"},
+				},
+			},
+			{
+				Query:    "select `auto_increment` from information_schema.tables where table_name = 'auto'",
+				Expected: []sql.Row{{int32(5)}},
+			},
+		},
+	},
+	{
+		Name: "auto_increment with explicit values below the auto_increment value",
+		SetUpScript: []string{
+			"create table auto (pk int primary key auto_increment)",
+			"insert into auto values (NULL), (10)",
+			"insert into auto values (5)",
+			"insert into auto values (NULL), (7), (NULL)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "select * from auto order by 1",
+				Expected: []sql.Row{
+					{1}, {5}, {7}, {10}, {11}, {12},
+				},
+			},
+		},
+	},
+	{
+		Name: "auto_increment_increment and auto_increment_offset",
+		SetUpScript: []string{
+			"create table auto (pk int primary key auto_increment)",
+			"set @@auto_increment_increment = 10, @@auto_increment_offset = 5",
+			"insert into auto values (NULL), (NULL)",
+			"insert into auto values (27)",
+			"insert into auto values (NULL)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "select * from auto order by 1",
+				Expected: []sql.Row{
+					{5}, {15}, {27}, {35},
+				},
+			},
+			{
+				Query:    "set @@auto_increment_increment = default, @@auto_increment_offset = default",
+				Expected: []sql.Row{{}},
+			},
+		},
+	},
 	{
 		Name: "auto increment on tinyint",
 		SetUpScript: []string{
@@ -15,11 +15,17 @@
 package enginetest_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
 	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
 	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/information_schema"
 
 	"github.com/dolthub/go-mysql-server/enginetest"
 	"github.com/dolthub/go-mysql-server/sql"
@@ -268,6 +274,10 @@ func TestDropColumn(t *testing.T) {
 	enginetest.TestDropColumn(t, enginetest.NewDefaultMemoryHarness())
 }
 
+func TestForeignKeys(t *testing.T) {
+	enginetest.TestForeignKeys(t, enginetest.NewDefaultMemoryHarness())
+}
+
 func TestCreateForeignKeys(t *testing.T) {
 	enginetest.TestCreateForeignKeys(t, enginetest.NewDefaultMemoryHarness())
 }
@@ -284,6 +294,14 @@ func TestReadOnly(t *testing.T) {
 	enginetest.TestReadOnly(t, enginetest.NewDefaultMemoryHarness())
 }
 
+func TestSetGlobalPrivileges(t *testing.T) {
+	enginetest.TestSetGlobalPrivileges(t, enginetest.NewDefaultMemoryHarness())
+}
+
+func TestSystemVariablesPerEngine(t *testing.T) {
+	enginetest.TestSystemVariablesPerEngine(t, enginetest.NewDefaultMemoryHarness())
+}
+
 func TestViews(t *testing.T) {
 	enginetest.TestViews(t, enginetest.NewDefaultMemoryHarness())
 }
@@ -308,10 +326,224 @@ func TestInnerNestedInNaturalJoins(t *testing.T) {
 	enginetest.TestInnerNestedInNaturalJoins(t, enginetest.NewDefaultMemoryHarness())
 }
 
+func TestDump(t *testing.T) {
+	enginetest.TestDump(t, enginetest.NewDefaultMemoryHarness())
+}
+
 func TestColumnDefaults(t *testing.T) {
 	enginetest.TestColumnDefaults(t, enginetest.NewDefaultMemoryHarness())
 }
 
+// TestTransactions tests the transactions of memory.Session through the engine, from two sessions.
+func TestTransactions(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	newContext := func() *sql.Context {
+		return sql.NewContext(
+			context.Background(),
+			sql.WithSession(memory.NewSession(enginetest.NewBaseSession())),
+			sql.WithViewRegistry(sql.NewViewRegistry()),
+		).WithCurrentDB("mydb")
+	}
+	ctx1, ctx2 := newContext(), newContext()
+	query := func(ctx *sql.Context, q string) ([]sql.Row, error) {
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+	run := func(ctx *sql.Context, q string) []sql.Row {
+		rows, err := query(ctx, q)
+		require.NoError(err, q)
+		return rows
+	}
+
+	run(ctx1, "CREATE TABLE t (i bigint primary key, s text)")
+	run(ctx1, "INSERT INTO t VALUES (1, 'a'), (2, 'b')")
+
+	run(ctx1, "BEGIN")
+	run(ctx2, "START TRANSACTION")
+	run(ctx1, "UPDATE t SET s = 'x' WHERE i = 1")
+	run(ctx1, "INSERT INTO t VALUES (3, 'c')")
+	require.Equal([]sql.Row{{int64(1), "a"}, {int64(2), "b"}}, run(ctx2, "SELECT * FROM t ORDER BY i"))
+
+	run(ctx2, "UPDATE t SET s = 'y' WHERE i = 1")
+	run(ctx1, "COMMIT")
+	require.Equal([]sql.Row{{int64(1), "y"}, {int64(2), "b"}}, run(ctx2, "SELECT * FROM t ORDER BY i"))
+	_, err := query(ctx2, "COMMIT")
+	require.Error(err)
+	require.True(memory.ErrWriteConflict.Is(err), "%v", err)
+
+	expected := []sql.Row{{int64(1), "x"}, {int64(2), "b"}, {int64(3), "c"}}
+	require.Equal(expected, run(ctx2, "SELECT * FROM t ORDER BY i"))
+
+	run(ctx2, "BEGIN")
+	run(ctx2, "DELETE FROM t")
+	require.Empty(run(ctx2, "SELECT * FROM t"))
+	run(ctx2, "ROLLBACK")
+	require.Equal(expected, run(ctx1, "SELECT * FROM t ORDER BY i"))
+
+	// The first change of a transaction made through an index lookup is seen by its later reads.
+	run(ctx1, "CREATE TABLE v (i bigint primary key, v bigint, index (v))")
+	run(ctx1, "INSERT INTO v VALUES (1, 99)")
+	run(ctx1, "BEGIN")
+	require.Equal([]sql.Row{{int64(1), int64(99)}}, run(ctx1, "SELECT * FROM v"))
+	run(ctx1, "UPDATE v SET v = 96 WHERE v = 99")
+	require.Equal([]sql.Row{{int64(1), int64(96)}}, run(ctx1, "SELECT * FROM v"))
+	run(ctx1, "COMMIT")
+	require.Equal([]sql.Row{{int64(1), int64(96)}}, run(ctx2, "SELECT * FROM v"))
+
+	// The AUTO_INCREMENT counter isn't transactional, so concurrent transactions insert different values.
+	run(ctx1, "CREATE TABLE a (i bigint primary key auto_increment, s text)")
+	run(ctx1, "BEGIN")
+	run(ctx2, "BEGIN")
+	run(ctx1, "INSERT INTO a (s) VALUES ('a')")
+	run(ctx2, "INSERT INTO a (s) VALUES ('b')")
+	run(ctx1, "COMMIT")
+	run(ctx2, "COMMIT")
+	require.Equal([]sql.Row{{int64(1), "a"}, {int64(2), "b"}}, run(ctx1, "SELECT * FROM a ORDER BY i"))
+}
+
+func TestDatabaseCollations(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	catalog.AddDatabase(information_schema.NewInformationSchemaDatabase(catalog))
+	catalog.SetDatabaseCreator(memory.DatabaseCreator{})
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	ctx := sql.NewContext(
+		context.Background(),
+		sql.WithSession(memory.NewSession(enginetest.NewBaseSession())),
+		sql.WithViewRegistry(sql.NewViewRegistry()),
+	).WithCurrentDB("mydb")
+	query := func(q string) ([]sql.Row, error) {
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+	run := func(q string) []sql.Row {
+		rows, err := query(q)
+		require.NoError(err, q)
+		return rows
+	}
+
+	run("CREATE DATABASE latin CHARACTER SET latin1")
+	require.Equal(
+		[]sql.Row{{"def", "latin", "latin1", "latin1_swedish_ci", nil}},
+		run("SELECT * FROM information_schema.schemata WHERE schema_name = 'latin'"),
+	)
+	require.Equal(
+		[]sql.Row{{"latin", "CREATE DATABASE `latin` /*!40100 DEFAULT CHARACTER SET latin1 COLLATE latin1_swedish_ci */"}},
+		run("SHOW CREATE DATABASE latin"),
+	)
+
+	run("USE latin")
+	run("CREATE TABLE t (i bigint primary key, s varchar(10), u varchar(10) CHARACTER SET utf8mb4)")
+	table, err := catalog.Table(ctx, "latin", "t")
+	require.NoError(err)
+	require.Equal(sql.Collation_latin1_swedish_ci, table.Schema()[1].Type.(sql.StringType).Collation())
+	require.Equal(sql.Collation_utf8mb4_0900_ai_ci, table.Schema()[2].Type.(sql.StringType).Collation())
+
+	run("ALTER DATABASE latin COLLATE latin1_bin")
+	require.Equal(
+		[]sql.Row{{"latin", "latin1", "latin1_bin"}},
+		run("SELECT schema_name, default_character_set_name, default_collation_name FROM information_schema.schemata WHERE schema_name = 'latin'"),
+	)
+	run("CREATE TABLE t2 (s text)")
+	table, err = catalog.Table(ctx, "latin", "t2")
+	require.NoError(err)
+	require.Equal(sql.Collation_latin1_bin, table.Schema()[0].Type.(sql.StringType).Collation())
+	require.Equal(
+		[]sql.Row{{"t2", "CREATE TABLE `t2` (\n  `s` text CHARACTER SET latin1 COLLATE latin1_bin\n) ENGINE=InnoDB DEFAULT CHARSET=latin1 COLLATE=latin1_bin"}},
+		run("SHOW CREATE TABLE t2"),
+	)
+
+	run("CREATE DATABASE IF NOT EXISTS latin")
+	require.Equal(1, len(ctx.Warnings()))
+	_, err = query("CREATE DATABASE latin")
+	require.True(sql.ErrDatabaseExists.Is(err), "%v", err)
+}
+
+func TestCrossDatabaseForeignKeys(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	catalog.AddDatabase(memory.NewDatabase("otherdb"))
+	catalog.AddDatabase(information_schema.NewInformationSchemaDatabase(catalog))
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	ctx := sql.NewContext(
+		context.Background(),
+		sql.WithSession(memory.NewSession(enginetest.NewBaseSession())),
+		sql.WithViewRegistry(sql.NewViewRegistry()),
+	).WithCurrentDB("mydb")
+	query := func(q string) ([]sql.Row, error) {
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := sql.RowIterToRows(iter)
+		if err != nil {
+			_ = iter.Close()
+		}
+		return rows, err
+	}
+	run := func(q string) []sql.Row {
+		rows, err := query(q)
+		require.NoError(err, q)
+		return rows
+	}
+
+	run("USE otherdb")
+	run("CREATE TABLE parent (id int primary key)")
+	run("USE mydb")
+	run("CREATE TABLE parent (id int primary key)")
+	run("CREATE TABLE child (id int primary key, pid int, CONSTRAINT fk_parent FOREIGN KEY (pid) REFERENCES otherdb.parent (id) ON DELETE CASCADE ON UPDATE CASCADE)")
+	run("INSERT INTO otherdb.parent VALUES (1), (2), (3)")
+	run("INSERT INTO parent VALUES (1), (2)")
+	run("INSERT INTO child VALUES (1, 1), (2, 2), (3, 3)")
+
+	_, err := query("INSERT INTO child VALUES (4, 4)")
+	require.True(sql.ErrForeignKeyChildViolation.Is(err), "%v", err)
+
+	// The table of the same name in the database of the child isn't the one referenced
+	run("DELETE FROM parent WHERE id = 1")
+	require.Equal([]sql.Row{{int32(1), int32(1)}, {int32(2), int32(2)}, {int32(3), int32(3)}}, run("SELECT * FROM child ORDER BY id"))
+
+	run("DELETE FROM otherdb.parent WHERE id = 1")
+	run("UPDATE otherdb.parent SET id = 20 WHERE id = 2")
+	require.Equal([]sql.Row{{int32(2), int32(20)}, {int32(3), int32(3)}}, run("SELECT * FROM child ORDER BY id"))
+
+	require.Equal(
+		[]sql.Row{{"child", "CREATE TABLE `child` (\n  `id` int NOT NULL,\n  `pid` int,\n  PRIMARY KEY (`id`),\n  CONSTRAINT `fk_parent` FOREIGN KEY (`pid`) REFERENCES `otherdb`.`parent` (`id`) ON DELETE CASCADE ON UPDATE CASCADE\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+		run("SHOW CREATE TABLE child"),
+	)
+	require.Equal(
+		[]sql.Row{{"otherdb", "parent", "id"}},
+		run("SELECT referenced_table_schema, referenced_table_name, referenced_column_name FROM information_schema.key_column_usage WHERE constraint_name = 'fk_parent'"),
+	)
+
+	// Foreign keys added to the tables of another database reference the tables of that database by default
+	run("USE otherdb")
+	run("CREATE TABLE grandchild (id int primary key, cid int)")
+	run("ALTER TABLE grandchild ADD CONSTRAINT fk_child FOREIGN KEY (cid) REFERENCES mydb.child (id) ON DELETE SET NULL")
+	run("INSERT INTO grandchild VALUES (1, 3)")
+	run("USE mydb")
+	run("DELETE FROM otherdb.parent WHERE id = 3")
+	require.Equal([]sql.Row{{int32(2), int32(20)}}, run("SELECT * FROM child ORDER BY id"))
+	require.Equal([]sql.Row{{int32(1), nil}}, run("SELECT * FROM otherdb.grandchild"))
+}
+
 func unmergableIndexDriver(dbs []sql.Database) sql.IndexDriver {
 	return memory.NewIndexDriver("mydb", map[string][]sql.DriverIndex{
 		"mytable": {
@@ -448,3 +680,62 @@ func findTable(dbs []sql.Database, tableName string) (sql.Database, sql.Table) {
 	}
 	return nil, nil
 }
+
+func TestSchemaListeners(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	catalog.SetDatabaseCreator(memory.DatabaseCreator{})
+	var changes []sql.SchemaChange
+	catalog.AddSchemaListener(sql.SchemaListenerFunc(func(ctx *sql.Context, change sql.SchemaChange) {
+		changes = append(changes, change)
+	}))
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	ctx := sql.NewContext(
+		context.Background(),
+		sql.WithSession(memory.NewSession(enginetest.NewBaseSession())),
+		sql.WithViewRegistry(sql.NewViewRegistry()),
+	).WithCurrentDB("mydb")
+	query := func(q string) error {
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return err
+		}
+		if _, err := sql.RowIterToRows(iter); err != nil {
+			_ = iter.Close()
+			return err
+		}
+		return nil
+	}
+	run := func(q string) []sql.SchemaChange {
+		changes = nil
+		require.NoError(query(q), q)
+		return changes
+	}
+
+	require.Equal([]sql.SchemaChange{{Type: sql.DatabaseCreated, Database: "otherdb"}}, run("CREATE DATABASE otherdb"))
+	require.Empty(run("CREATE DATABASE IF NOT EXISTS otherdb"))
+	require.Equal([]sql.SchemaChange{{Type: sql.DatabaseAltered, Database: "otherdb"}}, run("ALTER DATABASE otherdb COLLATE utf8mb4_bin"))
+
+	require.Equal([]sql.SchemaChange{{Type: sql.TableCreated, Database: "mydb", Table: "t"}}, run("CREATE TABLE t (i int primary key, s varchar(10))"))
+	require.Empty(run("CREATE TABLE IF NOT EXISTS t (i int primary key)"))
+	require.Equal([]sql.SchemaChange{{Type: sql.TableAltered, Database: "mydb", Table: "t"}}, run("ALTER TABLE t ADD COLUMN j int"))
+	require.Equal([]sql.SchemaChange{{Type: sql.IndexCreated, Database: "mydb", Table: "t", Index: "idx_s"}}, run("ALTER TABLE t ADD INDEX idx_s (s)"))
+	require.Equal(
+		[]sql.SchemaChange{{Type: sql.IndexAltered, Database: "mydb", Table: "t", Index: "idx_s", NewName: "idx_s2"}},
+		run("ALTER TABLE t RENAME INDEX idx_s TO idx_s2"),
+	)
+	require.Equal([]sql.SchemaChange{{Type: sql.IndexDropped, Database: "mydb", Table: "t", Index: "idx_s2"}}, run("ALTER TABLE t DROP INDEX idx_s2"))
+	require.Equal([]sql.SchemaChange{{Type: sql.TableRenamed, Database: "mydb", Table: "t", NewName: "t2"}}, run("RENAME TABLE t TO t2"))
+
+	changes = nil
+	require.Error(query("ALTER TABLE t2 DROP COLUMN missing"))
+	require.Empty(changes)
+
+	require.Equal(
+		[]sql.SchemaChange{{Type: sql.TableDropped, Database: "mydb", Table: "t2"}},
+		run("DROP TABLE IF EXISTS t2, missing"),
+	)
+}
@@ -514,6 +514,18 @@ var QueryTests = []QueryTest{
 		Query:    "SELECT * FROM mytable WHERE i = 2 AND s = 'third row'",
 		Expected: nil,
 	},
+	{
+		Query:    "SELECT * FROM mytable WHERE i BETWEEN 1 AND 2 AND s > 'first row'",
+		Expected: []sql.Row{{int64(2), "second row"}},
+	},
+	{
+		Query:    "SELECT * FROM mytable WHERE i = 2 AND s >= 'second row' AND s < 'third row'",
+		Expected: []sql.Row{{int64(2), "second row"}},
+	},
+	{
+		Query:    "SELECT * FROM mytable WHERE i > 1 AND i <= 3 AND s <= 'second row'",
+		Expected: []sql.Row{{int64(2), "second row"}},
+	},
 	{
 		Query:    "SELECT i FROM mytable WHERE s = 'first row' ORDER BY i DESC LIMIT 1;",
 		Expected: []sql.Row{{int64(1)}},
@@ -710,6 +722,10 @@ var QueryTests = []QueryTest{
 	},
 	{
 		Query:    "SELECT id FROM typestable WHERE da > '2019-12-31'",
+		Expected: nil,
+	},
+	{
+		Query:    "SELECT id FROM typestable WHERE da = '2019-12-31'",
 		Expected: []sql.Row{{int64(1)}},
 	},
 	{
@@ -1536,41 +1552,115 @@ var QueryTests = []QueryTest{
 	{
 		Query: `SELECT USER()`,
 		Expected: []sql.Row{
-			{"user"},
+			{"user@client"},
 		},
 	},
 	{
 		Query: `SELECT CURRENT_USER()`,
 		Expected: []sql.Row{
-			{"user"},
+			{"user@%"},
 		},
 	},
 	{
 		Query: `SELECT CURRENT_USER`,
 		Expected: []sql.Row{
-			{"user"},
+			{"user@%"},
+		},
+	},
+	{
+		Query: `SELECT CURRENT_ROLE()`,
+		Expected: []sql.Row{
+			{"NONE"},
+		},
+	},
+	{
+		Query: `SHOW GRANTS`,
+		Expected: []sql.Row{
+			{"GRANT ALL PRIVILEGES ON *.* TO `user`@`%` WITH GRANT OPTION"},
 		},
 	},
 	{
 		Query: `SHOW VARIABLES`,
 		Expected: []sql.Row{
-			{"autocommit", int64(0)},
 			{"auto_increment_increment", int64(1)},
-			{"time_zone", "SYSTEM"},
-			{"system_time_zone", time.Now().UTC().Location().String()},
-			{"max_allowed_packet", math.MaxInt32},
-			{"sql_mode", ""},
+			{"auto_increment_offset", int64(1)},
+			{"autocommit", int64(0)},
+			{"binlog_checksum", "CRC32"},
+			{"binlog_format", "ROW"},
+			{"binlog_row_image", "FULL"},
+			{"character_set_client", sql.Collation_Default.CharacterSet().String()},
+			{"character_set_connection", sql.Collation_Default.CharacterSet().String()},
+			{"character_set_database", sql.Collation_Default.CharacterSet().String()},
+			{"character_set_results", sql.Collation_Default.CharacterSet().String()},
+			{"character_set_server", sql.Collation_Default.CharacterSet().String()},
+			{"character_set_system", "utf8"},
+			{"collation_connection", sql.Collation_Default.String()},
+			{"collation_database", sql.Collation_Default.String()},
+			{"collation_server", sql.Collation_Default.String()},
+			{"default_storage_engine", "InnoDB"},
+			{"foreign_key_checks", int8(1)},
+			{"general_log", int8(0)},
+			{"gtid_executed", ""},
 			{"gtid_mode", int32(0)},
-			{"collation_database", "utf8mb4_0900_ai_ci"},
+			{"gtid_purged", ""},
+			{"hostname", hostname()},
+			{"init_connect", ""},
+			{"inmemory_joins", nil},
+			{"innodb_lock_wait_timeout", int64(50)},
+			{"interactive_timeout", int64(28800)},
+			{"license", "GPL"},
+			{"lock_wait_timeout", int64(31536000)},
+			{"long_query_time", float64(10)},
+			{"lower_case_table_names", int32(0)},
+			{"max_allowed_packet", math.MaxInt32},
+			{"max_connections", int64(151)},
+			{"max_execution_time", int64(0)},
+			{"max_user_connections", int64(0)},
 			{"ndbinfo_version", ""},
+			{"net_read_timeout", int64(30)},
+			{"net_write_timeout", int64(60)},
+			{"optimizer_switch", "index_merge=on,index_merge_union=on,index_merge_sort_union=on,index_merge_intersection=on,engine_condition_pushdown=on,index_condition_pushdown=on,mrr=on,mrr_cost_based=on,block_nested_loop=on,batched_key_access=off,materialization=on,semijoin=on,loosescan=on,firstmatch=on,duplicateweedout=on,subquery_materialization_cost_based=on,use_index_extensions=on,condition_fanout_filter=on,derived_merge=on,use_invisible_indexes=off,skip_scan=on,hash_join=on"},
+			{"protocol_version", int32(10)},
+			{"server_id", int64(1)},
+			{"server_uuid", serverUUID()},
+			{"session_track_gtids", "OFF"},
+			{"session_track_schema", int8(1)},
+			{"session_track_state_change", int8(0)},
+			{"session_track_system_variables", "time_zone,autocommit,character_set_client,character_set_results,character_set_connection"},
+			{"session_track_transaction_info", "OFF"},
+			{"slow_query_log", int8(0)},
+			{"sql_auto_is_null", int8(0)},
+			{"sql_mode", "ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ENGINE_SUBSTITUTION"},
+			{"sql_notes", int8(1)},
+			{"sql_quote_show_create", int8(1)},
+			{"sql_safe_updates", int8(0)},
 			{"sql_select_limit", math.MaxInt32},
+			{"sql_warnings", int8(0)},
+			{"system_time_zone", time.Now().UTC().Location().String()},
+			{"time_zone", "SYSTEM"},
 			{"transaction_isolation", "READ UNCOMMITTED"},
+			{"transaction_read_only", int8(0)},
+			{"unique_checks", int8(1)},
 			{"version", ""},
 			{"version_comment", ""},
-			{"character_set_client", sql.Collation_Default.CharacterSet().String()},
-			{"character_set_connection", sql.Collation_Default.CharacterSet().String()},
-			{"character_set_results", sql.Collation_Default.CharacterSet().String()},
-			{"collation_connection", sql.Collation_Default.String()},
+			{"wait_timeout", int64(28800)},
+		},
+	},
+	{
+		Query: `SHOW VARIABLES LIKE '%_TIMEOUT'`,
+		Expected: []sql.Row{
+			{"innodb_lock_wait_timeout", int64(50)},
+			{"interactive_timeout", int64(28800)},
+			{"lock_wait_timeout", int64(31536000)},
+			{"net_read_timeout", int64(30)},
+			{"net_write_timeout", int64(60)},
+			{"wait_timeout", int64(28800)},
+		},
+	},
+	{
+		Query: `SELECT @@max_connections, @@global.wait_timeout, @@session.wait_timeout`,
+		Expected: []sql.Row{
+			{int64(151), int64(28800), int64(28800)},
 		},
 	},
 	{
@@ -1582,14 +1672,16 @@ var QueryTests = []QueryTest{
 	{
 		Query: `SHOW VARIABLES LIKE 'gtid%`,
 		Expected: []sql.Row{
+			{"gtid_executed", ""},
 			{"gtid_mode", int32(0)},
+			{"gtid_purged", ""},
 		},
 	},
 	{
 		Query: `SHOW GLOBAL VARIABLES LIKE '%mode`,
 		Expected: []sql.Row{
-			{"sql_mode", ""},
 			{"gtid_mode", int32(0)},
+			{"sql_mode", "ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ENGINE_SUBSTITUTION"},
 		},
 	},
 	{
@@ -2273,6 +2365,14 @@ var QueryTests = []QueryTest{
 		Query:    `SELECT COUNT(DISTINCT t.i) FROM tabletest t, mytable t2`,
 		Expected: []sql.Row{{int64(3)}},
 	},
+	{
+		Query:    `SELECT SUM(DISTINCT i % 2) FROM mytable`,
+		Expected: []sql.Row{{float64(1)}},
+	},
+	{
+		Query:    `SELECT SUM(DISTINCT t.i), SUM(t.i) FROM tabletest t, mytable t2`,
+		Expected: []sql.Row{{float64(6), float64(18)}},
+	},
 	{
 		Query:    `SELECT CASE WHEN NULL THEN "yes" ELSE "no" END AS test`,
 		Expected: []sql.Row{{"no"}},
@@ -2573,15 +2673,6 @@ var QueryTests = []QueryTest{
 			{2, 1, 3},
 		},
 	},
-	{
-		Query: `SELECT pk, (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) AS x FROM one_pk opk GROUP BY x ORDER BY x`,
-		Expected: []sql.Row{
-			{0, nil},
-			{1, 0},
-			{2, 1},
-			{3, 2},
-		},
-	},
 	{
 		Query: `SELECT pk,
 					(SELECT max(pk) FROM one_pk WHERE pk < opk.pk) AS max,
@@ -2700,24 +2791,6 @@ var QueryTests = []QueryTest{
 			{3, 2},
 		},
 	},
-	{
-		Query: `SELECT pk, (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) AS x
-						FROM one_pk opk WHERE (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) > 0
-						GROUP BY x ORDER BY x`,
-		Expected: []sql.Row{
-			{2, 1},
-			{3, 2},
-		},
-	},
-	{
-		Query: `SELECT pk, (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) AS x
-						FROM one_pk opk WHERE (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) > 0
-						GROUP BY (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) ORDER BY x`,
-		Expected: []sql.Row{
-			{2, 1},
-			{3, 2},
-		},
-	},
 	{
 		Query: `SELECT pk, (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) AS x
 						FROM one_pk opk WHERE (SELECT max(pk) FROM one_pk WHERE pk > opk.pk) > 0 ORDER BY x`,
@@ -3570,46 +3643,46 @@ var InfoSchemaQueries = []QueryTest{
 	{
 		Query: `SHOW TABLE STATUS FROM mydb`,
 		Expected: []sql.Row{
-			{"auto_increment_tbl", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"mytable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"othertable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"tabletest", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"bigtable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"floattable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"fk_tbl", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"niltable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"newlinetable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"auto_increment_tbl", "InnoDB", "10", "Fixed", int64(3), int64(16), int64(48), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"mytable", "InnoDB", "10", "Fixed", int64(3), int64(17), int64(51), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"othertable", "InnoDB", "10", "Fixed", int64(3), int64(13), int64(39), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"tabletest", "InnoDB", "10", "Fixed", int64(3), int64(17), int64(51), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"bigtable", "InnoDB", "10", "Fixed", int64(14), int64(9), int64(126), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"floattable", "InnoDB", "10", "Fixed", int64(6), int64(20), int64(120), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"fk_tbl", "InnoDB", "10", "Fixed", int64(3), int64(25), int64(75), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"niltable", "InnoDB", "10", "Fixed", int64(6), int64(21), int64(126), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"newlinetable", "InnoDB", "10", "Fixed", int64(5), int64(34), int64(170), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
 		},
 	},
 	{
 		Query: `SHOW TABLE STATUS LIKE '%table'`,
 		Expected: []sql.Row{
-			{"mytable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"othertable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"bigtable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"floattable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"niltable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"newlinetable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"mytable", "InnoDB", "10", "Fixed", int64(3), int64(17), int64(51), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"othertable", "InnoDB", "10", "Fixed", int64(3), int64(13), int64(39), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"bigtable", "InnoDB", "10", "Fixed", int64(14), int64(9), int64(126), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"floattable", "InnoDB", "10", "Fixed", int64(6), int64(20), int64(120), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"niltable", "InnoDB", "10", "Fixed", int64(6), int64(21), int64(126), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"newlinetable", "InnoDB", "10", "Fixed", int64(5), int64(34), int64(170), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
 		},
 	},
 	{
 		Query: `SHOW TABLE STATUS WHERE Name = 'mytable'`,
 		Expected: []sql.Row{
-			{"mytable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"mytable", "InnoDB", "10", "Fixed", int64(3), int64(17), int64(51), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
 		},
 	},
 	{
 		Query: `SHOW TABLE STATUS`,
 		Expected: []sql.Row{
-			{"auto_increment_tbl", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"mytable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"othertable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"tabletest", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"bigtable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"fk_tbl", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"floattable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"niltable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
-			{"newlinetable", "InnoDB", "10", "Fixed", int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"auto_increment_tbl", "InnoDB", "10", "Fixed", int64(3), int64(16), int64(48), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"mytable", "InnoDB", "10", "Fixed", int64(3), int64(17), int64(51), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"othertable", "InnoDB", "10", "Fixed", int64(3), int64(13), int64(39), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"tabletest", "InnoDB", "10", "Fixed", int64(3), int64(17), int64(51), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"bigtable", "InnoDB", "10", "Fixed", int64(14), int64(9), int64(126), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"fk_tbl", "InnoDB", "10", "Fixed", int64(3), int64(25), int64(75), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"floattable", "InnoDB", "10", "Fixed", int64(6), int64(20), int64(120), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"niltable", "InnoDB", "10", "Fixed", int64(6), int64(21), int64(126), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
+			{"newlinetable", "InnoDB", "10", "Fixed", int64(5), int64(34), int64(170), int64(0), int64(0), int64(0), int64(0), nil, nil, nil, "utf8mb4_0900_ai_ci", nil, nil},
 		},
 	},
 	{
@@ -3712,19 +3785,6 @@ var InfoSchemaQueries = []QueryTest{
 			{"mytable"},
 		},
 	},
-	{
-		Query: `
-		SELECT
-			LOGFILE_GROUP_NAME, FILE_NAME, TOTAL_EXTENTS, INITIAL_SIZE, ENGINE, EXTRA
-		FROM INFORMATION_SCHEMA.FILES
-		WHERE FILE_TYPE = 'UNDO LOG'
-			AND FILE_NAME IS NOT NULL
-			AND LOGFILE_GROUP_NAME IS NOT NULL
-		GROUP BY LOGFILE_GROUP_NAME, FILE_NAME, ENGINE, TOTAL_EXTENTS, INITIAL_SIZE
-		ORDER BY LOGFILE_GROUP_NAME
-		`,
-		Expected: nil,
-	},
 	{
 		Query: `
 		SELECT DISTINCT
@@ -3772,7 +3832,7 @@ var InfoSchemaQueries = []QueryTest{
 		WHERE TABLE_SCHEMA='mydb' AND TABLE_NAME='mytable'
 		`,
 		Expected: []sql.Row{
-			{"s", "varchar(20)"},
+			{"s", "varchar"},
 			{"i", "bigint"},
 		},
 	},
@@ -3844,19 +3904,19 @@ var InfoSchemaQueries = []QueryTest{
 	{
 		Query: `SHOW INDEXES FROM mytaBLE`,
 		Expected: []sql.Row{
-			{"mytable", 0, "PRIMARY", 1, "i", nil, 0, nil, nil, "", "BTREE", "", "", "YES", nil},
-			{"mytable", 0, "mytable_s", 1, "s", nil, 0, nil, nil, "", "BTREE", "", "", "YES", nil},
-			{"mytable", 1, "mytable_i_s", 1, "i", nil, 0, nil, nil, "", "BTREE", "", "", "YES", nil},
-			{"mytable", 1, "mytable_i_s", 2, "s", nil, 0, nil, nil, "", "BTREE", "", "", "YES", nil},
+			{"mytable", 0, "PRIMARY", 1, "i", "A", 3, nil, nil, "", "BTREE", "", "", "YES", nil},
+			{"mytable", 0, "mytable_s", 1, "s", "A", 3, nil, nil, "", "BTREE", "", "", "YES", nil},
+			{"mytable", 1, "mytable_i_s", 1, "i", "A", 3, nil, nil, "", "BTREE", "", "", "YES", nil},
+			{"mytable", 1, "mytable_i_s", 2, "s", "A", 3, nil, nil, "", "BTREE", "", "", "YES", nil},
 		},
 	},
 	{
 		Query: `SHOW KEYS FROM mytaBLE`,
 		Expected: []sql.Row{
-			{"mytable", 0, "PRIMARY", 1, "i", nil, 0, nil, nil, "", "BTREE", "", "", "YES", nil},
-			{"mytable", 0, "mytable_s", 1, "s", nil, 0, nil, nil, "", "BTREE", "", "", "YES", nil},
-			{"mytable", 1, "mytable_i_s", 1, "i", nil, 0, nil, nil, "", "BTREE", "", "", "YES", nil},
-			{"mytable", 1, "mytable_i_s", 2, "s", nil, 0, nil, nil, "", "BTREE", "", "", "YES", nil},
+			{"mytable", 0, "PRIMARY", 1, "i", "A", 3, nil, nil, "", "BTREE", "", "", "YES", nil},
+			{"mytable", 0, "mytable_s", 1, "s", "A", 3, nil, nil, "", "BTREE", "", "", "YES", nil},
+			{"mytable", 1, "mytable_i_s", 1, "i", "A", 3, nil, nil, "", "BTREE", "", "", "YES", nil},
+			{"mytable", 1, "mytable_i_s", 2, "s", "A", 3, nil, nil, "", "BTREE", "", "", "YES", nil},
 		},
 	},
 	{
@@ -3883,6 +3943,72 @@ var InfoSchemaQueries = []QueryTest{
 				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"},
 		},
 	},
+	{
+		Query: `SELECT column_name, ordinal_position, column_type, column_key, extra, character_maximum_length, numeric_precision
+		FROM information_schema.columns WHERE table_schema = 'mydb' AND table_name IN ('mytable', 'auto_increment_tbl')
+		ORDER BY table_name, ordinal_position`,
+		Expected: []sql.Row{
+			{"pk", uint64(1), "bigint", "PRI", "auto_increment", nil, uint64(19)},
+			{"c0", uint64(2), "bigint", "", "", nil, uint64(19)},
+			{"i", uint64(1), "bigint", "PRI", "", nil, uint64(19)},
+			{"s", uint64(2), "varchar(20)", "UNI", "", uint64(20), nil},
+		},
+	},
+	{
+		Query: `SELECT index_name, non_unique, seq_in_index, column_name, index_type
+		FROM information_schema.statistics WHERE table_schema = 'mydb' AND table_name = 'mytable'
+		ORDER BY index_name, seq_in_index`,
+		Expected: []sql.Row{
+			{"PRIMARY", int64(0), int64(1), "i", "BTREE"},
+			{"mytable_i_s", int64(1), int64(1), "i", "BTREE"},
+			{"mytable_i_s", int64(1), int64(2), "s", "BTREE"},
+			{"mytable_s", int64(0), int64(1), "s", "BTREE"},
+		},
+	},
+	{
+		Query: `SELECT constraint_name, table_name, constraint_type, enforced FROM information_schema.table_constraints
+		WHERE table_schema = 'mydb' AND table_name IN ('mytable', 'fk_tbl') ORDER BY table_name, constraint_name`,
+		Expected: []sql.Row{
+			{"PRIMARY", "fk_tbl", "PRIMARY KEY", "YES"},
+			{"fk1", "fk_tbl", "FOREIGN KEY", "YES"},
+			{"PRIMARY", "mytable", "PRIMARY KEY", "YES"},
+			{"mytable_s", "mytable", "UNIQUE", "YES"},
+		},
+	},
+	{
+		Query: `SELECT constraint_name, table_name, column_name, ordinal_position, position_in_unique_constraint,
+		referenced_table_name, referenced_column_name FROM information_schema.key_column_usage
+		WHERE table_schema = 'mydb' AND table_name IN ('mytable', 'fk_tbl') ORDER BY table_name, constraint_name, ordinal_position`,
+		Expected: []sql.Row{
+			{"PRIMARY", "fk_tbl", "pk", uint32(1), nil, nil, nil},
+			{"fk1", "fk_tbl", "a", uint32(1), uint32(1), "mytable", "i"},
+			{"fk1", "fk_tbl", "b", uint32(2), uint32(2), "mytable", "s"},
+			{"PRIMARY", "mytable", "i", uint32(1), nil, nil, nil},
+			{"mytable_s", "mytable", "s", uint32(1), nil, nil, nil},
+		},
+	},
+	{
+		Query: `SELECT constraint_name, unique_constraint_name, match_option, update_rule, delete_rule, table_name, referenced_table_name
+		FROM information_schema.referential_constraints WHERE constraint_schema = 'mydb'`,
+		Expected: []sql.Row{
+			{"fk1", nil, "NONE", "NO ACTION", "CASCADE", "fk_tbl", "mytable"},
+		},
+	},
+	{
+		Query:    `SELECT * FROM information_schema.check_constraints`,
+		Expected: []sql.Row{},
+	},
+	{
+		Query:    `SELECT * FROM information_schema.parameters`,
+		Expected: []sql.Row{},
+	},
+	{
+		Query: `SELECT table_name, partition_name, partition_method, partition_comment FROM information_schema.partitions
+		WHERE table_schema = 'mydb' AND table_name = 'mytable'`,
+		Expected: []sql.Row{
+			{"mytable", nil, nil, ""},
+		},
+	},
 	{
 
 		Query: "SELECT table_name, `auto_increment` FROM information_schema.tables " +
@@ -3916,6 +4042,29 @@ var InfoSchemaScripts = []ScriptTest{
 			},
 		},
 	},
+	{
+		// mysqldump queries the tablespaces with an empty sql_mode, since EXTRA isn't grouped
+		Name: "mysqldump tablespaces",
+		SetUpScript: []string{
+			"set sql_mode = ''",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: `
+		SELECT
+			LOGFILE_GROUP_NAME, FILE_NAME, TOTAL_EXTENTS, INITIAL_SIZE, ENGINE, EXTRA
+		FROM INFORMATION_SCHEMA.FILES
+		WHERE FILE_TYPE = 'UNDO LOG'
+			AND FILE_NAME IS NOT NULL
+			AND LOGFILE_GROUP_NAME IS NOT NULL
+		GROUP BY LOGFILE_GROUP_NAME, FILE_NAME, ENGINE, TOTAL_EXTENTS, INITIAL_SIZE
+		ORDER BY LOGFILE_GROUP_NAME
+		`,
+				Expected: nil,
+			},
+		},
+	},
+
 }
 
 var ExplodeQueries = []QueryTest{
@@ -18,6 +18,8 @@ import (
 	"gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+	"github.com/dolthub/go-mysql-server/sql/plan"
 )
 
 type ScriptTest struct {
@@ -318,4 +320,1442 @@ var ScriptTests = []ScriptTest{
 			},
 		},
 	},
+	{
+		Name: "dump tables",
+		SetUpScript: []string{
+			"create table dumped (pk int primary key, s varchar(20), f float)",
+			"insert into dumped values (1, 'first', 1.5), (2, 'it''s', null)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "dump tables dumped",
+				Expected: []sql.Row{
+					{"USE `mydb`"},
+					{"DROP TABLE IF EXISTS `dumped`"},
+					{"CREATE TABLE `dumped` (\n  `pk` int NOT NULL,\n  `s` varchar(20),\n  `f` float,\n  PRIMARY KEY (`pk`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"},
+					{"INSERT INTO `dumped` (`pk`,`s`,`f`) VALUES (1,'first',1.5),(2,'it\\'s',NULL)"},
+				},
+			},
+		},
+	},
+	{
+		Name: "unique secondary index",
+		SetUpScript: []string{
+			"create table uniq (pk int primary key, v int)",
+			"create unique index v on uniq (v)",
+			"insert into uniq values (1, 10), (2, null), (3, null), (4, 5)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "insert into uniq values (5, 10)",
+				ExpectedErr: sql.ErrUniqueKeyViolation,
+			},
+			{
+				Query:       "update uniq set v = 10 where pk = 2",
+				ExpectedErr: sql.ErrUniqueKeyViolation,
+			},
+			{
+				Query:    "update uniq set v = 20 where pk = 1",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:    "select pk from uniq where v > 1",
+				Expected: []sql.Row{{4}, {1}},
+			},
+		},
+	},
+	{
+		Name: "ordered secondary index scans",
+		SetUpScript: []string{
+			"create table ord (pk int primary key, a int, b varchar(10))",
+			"create index ab on ord (a, b)",
+			"insert into ord values (1, 3, 'x'), (2, null, 'y'), (3, 1, 'z'), (4, 2, 'x'), (5, 1, 'y'), (6, 3, 'w')",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select a, b from ord order by a, b",
+				Expected: []sql.Row{{nil, "y"}, {1, "y"}, {1, "z"}, {2, "x"}, {3, "w"}, {3, "x"}},
+			},
+			{
+				Query:    "select pk from ord order by a desc, b desc",
+				Expected: []sql.Row{{1}, {6}, {4}, {3}, {5}, {2}},
+			},
+			{
+				Query:    "select pk from ord where a > 1 order by a desc, b",
+				Expected: []sql.Row{{6}, {1}, {4}},
+			},
+			{
+				Query:    "select a, count(*) from ord group by a order by 1",
+				Expected: []sql.Row{{nil, 1}, {1, 2}, {2, 1}, {3, 2}},
+			},
+			{
+				Query:    "select b, a, count(*) from ord group by a, b",
+				Expected: []sql.Row{{"y", nil, 1}, {"y", 1, 1}, {"z", 1, 1}, {"x", 2, 1}, {"w", 3, 1}, {"x", 3, 1}},
+			},
+		},
+	},
+	{
+		Name: "descending index key parts",
+		SetUpScript: []string{
+			"create table dsc (pk int, a int, b int, primary key (pk desc))",
+			"create index a_b on dsc (a, b desc)",
+			"insert into dsc values (1, 1, 2), (2, 2, null), (3, 1, 3), (4, null, 1), (5, 2, 1)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "show create table dsc",
+				Expected: []sql.Row{{"dsc", "CREATE TABLE `dsc` (\n" +
+					"  `pk` int NOT NULL,\n" +
+					"  `a` int,\n" +
+					"  `b` int,\n" +
+					"  PRIMARY KEY (`pk` DESC),\n" +
+					"  KEY `a_b` (`a`,`b` DESC)\n" +
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+			},
+			{
+				Query:    "select index_name, column_name, collation from information_schema.statistics where table_name = 'dsc' order by 1, 2",
+				Expected: []sql.Row{{"PRIMARY", "pk", "D"}, {"a_b", "a", "A"}, {"a_b", "b", "D"}},
+			},
+			{
+				Query:    "select pk from dsc order by a, b desc",
+				Expected: []sql.Row{{4}, {3}, {1}, {5}, {2}},
+			},
+			{
+				Query:    "select pk from dsc order by a desc, b",
+				Expected: []sql.Row{{2}, {5}, {1}, {3}, {4}},
+			},
+			{
+				Query:    "select pk from dsc where a = 1 and b < 3 order by a, b desc",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select pk from dsc where a = 2 and b > 0 order by a, b desc",
+				Expected: []sql.Row{{5}},
+			},
+		},
+	},
+	{
+		Name: "functional key parts",
+		SetUpScript: []string{
+			"create table fn (pk int primary key, a int, b varchar(10))",
+			"insert into fn values (1, 1, 'x'), (2, 5, 'Y'), (3, 3, 'z')",
+			"create index a_plus on fn ((a + 1))",
+			"create index lower_b on fn ((lower(b)) desc, a)",
+			"insert into fn values (4, 4, 'y')",
+			"update fn set a = 10 where pk = 1",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "show create table fn",
+				Expected: []sql.Row{{"fn", "CREATE TABLE `fn` (\n" +
+					"  `pk` int NOT NULL,\n" +
+					"  `a` int,\n" +
+					"  `b` varchar(10),\n" +
+					"  PRIMARY KEY (`pk`)\n" +
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n" +
+					"CREATE INDEX `a_plus` ON `fn` ((`a` + 1));\n" +
+					"CREATE INDEX `lower_b` ON `fn` ((LOWER(`b`)) DESC,`a`)"}},
+			},
+			{
+				Query:    "select pk from fn where a + 1 = 11",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select pk from fn f where f.a + 1 > 4 order by pk",
+				Expected: []sql.Row{{1}, {2}, {4}},
+			},
+			{
+				Query:    "select pk from fn where lower(b) = 'y' and a = 4",
+				Expected: []sql.Row{{4}},
+			},
+			{
+				Query:    "alter table fn rename column a to c",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select pk from fn where c + 1 = 11",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:       "create index bad on fn ((nope + 1))",
+				ExpectedErr: sql.ErrColumnNotFound,
+			},
+		},
+	},
+	{
+		Name: "prefix key parts",
+		SetUpScript: []string{
+			"create table pre (pk int primary key, s varchar(20), b blob, index s3 (s(3)), index b2 (b(2)))",
+			"insert into pre values (1, 'abcdef', 'xyz'), (2, 'abc', 'xy'), (3, 'abd', 'x'), (4, 'ab', null), (5, 'ééééé', 'xyzw')",
+			"create unique index u on pre (s(4) desc)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "show create table pre",
+				Expected: []sql.Row{{"pre", "CREATE TABLE `pre` (\n" +
+					"  `pk` int NOT NULL,\n" +
+					"  `s` varchar(20),\n" +
+					"  `b` blob,\n" +
+					"  PRIMARY KEY (`pk`),\n" +
+					"  KEY `b2` (`b`(2)),\n" +
+					"  KEY `s3` (`s`(3)),\n" +
+					"  UNIQUE KEY `u` (`s`(4) DESC)\n" +
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+			},
+			{
+				Query:    "select index_name, column_name, sub_part from information_schema.statistics where table_name = 'pre' order by 1",
+				Expected: []sql.Row{{"PRIMARY", "pk", nil}, {"b2", "b", int64(2)}, {"s3", "s", int64(3)}, {"u", "s", int64(4)}},
+			},
+			{
+				Query:    "select pk from pre where s = 'abcdef'",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select pk from pre where s > 'abc' order by pk",
+				Expected: []sql.Row{{1}, {3}, {5}},
+			},
+			{
+				Query:    "select pk from pre where s >= 'abc' and s < 'abd' order by pk",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				Query:    "select pk from pre where s = 'ééééé'",
+				Expected: []sql.Row{{5}},
+			},
+			{
+				Query:    "select pk from pre where b > 'xy' order by pk",
+				Expected: []sql.Row{{1}, {5}},
+			},
+			{
+				Query:    "select pk from pre order by s",
+				Expected: []sql.Row{{4}, {2}, {1}, {3}, {5}},
+			},
+			{
+				Query:       "insert into pre values (6, 'abcdzz', null)",
+				ExpectedErr: sql.ErrUniqueKeyViolation,
+			},
+			{
+				Query:       "create index bad on pre (pk(3))",
+				ExpectedErr: plan.ErrCreateIndexInvalidPrefix,
+			},
+			{
+				Query:       "create index bad on pre (s(30))",
+				ExpectedErr: plan.ErrCreateIndexInvalidPrefix,
+			},
+		},
+	},
+	{
+		Name: "invisible indexes",
+		SetUpScript: []string{
+			"create table inv (pk int primary key, a int, b int)",
+			"insert into inv values (1, 10, 100), (2, 20, 200), (3, 30, 300)",
+			"create index a on inv (a) invisible",
+			"create index b on inv (b) comment 'bee' invisible",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "show create table inv",
+				Expected: []sql.Row{{"inv", "CREATE TABLE `inv` (\n" +
+					"  `pk` int NOT NULL,\n" +
+					"  `a` int,\n" +
+					"  `b` int,\n" +
+					"  PRIMARY KEY (`pk`),\n" +
+					"  KEY `a` (`a`) /*!80000 INVISIBLE */,\n" +
+					"  KEY `b` (`b`) COMMENT 'bee' /*!80000 INVISIBLE */\n" +
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+			},
+			{
+				Query:    "select index_name, is_visible from information_schema.statistics where table_name = 'inv' order by 1",
+				Expected: []sql.Row{{"PRIMARY", "YES"}, {"a", "NO"}, {"b", "NO"}},
+			},
+			{
+				Query: "explain select pk from inv where a = 20",
+				Expected: []sql.Row{
+					{"Project(inv.pk)"},
+					{" └─ Filter(inv.a = 20)"},
+					{"     └─ Table(inv)"},
+				},
+			},
+			{
+				Query:    "select pk from inv where a = 20",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "alter table inv alter index a visible",
+				Expected: []sql.Row{},
+			},
+			{
+				Query: "explain select pk from inv where a = 20",
+				Expected: []sql.Row{
+					{"Project(inv.pk)"},
+					{" └─ Indexed table access on index [inv.a]"},
+					{"     └─ Filter(inv.a = 20)"},
+					{"         └─ Table(inv)"},
+				},
+			},
+			{
+				Query:    "set optimizer_switch = 'use_invisible_indexes=on'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query: "explain select pk from inv where b = 300",
+				Expected: []sql.Row{
+					{"Project(inv.pk)"},
+					{" └─ Indexed table access on index [inv.b]"},
+					{"     └─ Filter(inv.b = 300)"},
+					{"         └─ Table(inv)"},
+				},
+			},
+			{
+				Query:    "select pk from inv where b = 300",
+				Expected: []sql.Row{{3}},
+			},
+			{
+				Query:    "set optimizer_switch = default",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "select index_name, is_visible from information_schema.statistics where table_name = 'inv' order by 1",
+				Expected: []sql.Row{{"PRIMARY", "YES"}, {"a", "YES"}, {"b", "NO"}},
+			},
+			{
+				Query:       "alter table inv alter index primary invisible",
+				ExpectedErr: plan.ErrInvisiblePrimaryKey,
+			},
+			{
+				Query:       "alter table inv alter index nope invisible",
+				ExpectedErr: sql.ErrIndexNotFound,
+			},
+		},
+	},
+	{
+		Name: "check constraints",
+		SetUpScript: []string{
+			"create table chk (pk int primary key, a int, b varchar(10))",
+			"insert into chk values (1, 10, 'x'), (2, 20, 'y'), (3, null, 'z')",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "alter table chk add constraint a_small check (a < 15)",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:    "alter table chk add constraint a_small check (a < 15) not enforced",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "alter table chk add constraint a_positive check (a > 0)",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "alter table chk add check (length(b) = 1)",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:       "alter table chk add constraint a_positive check (a > 1)",
+				ExpectedErr: sql.ErrCheckConstraintDuplicateName,
+			},
+			{
+				Query: "show create table chk",
+				Expected: []sql.Row{{"chk", "CREATE TABLE `chk` (\n" +
+					"  `pk` int NOT NULL,\n" +
+					"  `a` int,\n" +
+					"  `b` varchar(10),\n" +
+					"  PRIMARY KEY (`pk`),\n" +
+					"  CONSTRAINT `a_small` CHECK (a < 15) /*!80016 NOT ENFORCED */,\n" +
+					"  CONSTRAINT `a_positive` CHECK (a > 0),\n" +
+					"  CONSTRAINT `chk_chk_1` CHECK (length(b) = 1)\n" +
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+			},
+			{
+				Query:    "select constraint_name, check_clause from information_schema.check_constraints order by 1",
+				Expected: []sql.Row{{"a_positive", "(a > 0)"}, {"a_small", "(a < 15)"}, {"chk_chk_1", "(length(b) = 1)"}},
+			},
+			{
+				Query:    "select constraint_name, enforced from information_schema.table_constraints where table_name = 'chk' and constraint_type = 'CHECK' order by 1",
+				Expected: []sql.Row{{"a_positive", "YES"}, {"a_small", "NO"}, {"chk_chk_1", "YES"}},
+			},
+			{
+				Query:       "insert into chk values (4, -1, 'w')",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:       "insert into chk values (4, 1, 'ww')",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:    "insert into chk values (4, 40, 'w'), (5, null, null)",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query:       "update chk set a = 0 where pk = 1",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:       "insert into chk values (1, 10, 'x') on duplicate key update a = -10",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:    "alter table chk drop check a_positive",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "update chk set a = 0 where pk = 1",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:    "alter table chk drop constraint chk_chk_1",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:       "alter table chk drop check chk_chk_1",
+				ExpectedErr: sql.ErrCheckConstraintNotFound,
+			},
+			{
+				Query:       "alter table chk drop constraint chk_chk_1",
+				ExpectedErr: sql.ErrConstraintNotFound,
+			},
+			{
+				Query:    "select constraint_name from information_schema.check_constraints",
+				Expected: []sql.Row{{"a_small"}},
+			},
+		},
+	},
+	{
+		Name: "on update current_timestamp",
+		SetUpScript: []string{
+			"create table upd (pk int primary key, v int, ts datetime default '2000-01-01' on update current_timestamp, ts6 timestamp null on update current_timestamp(6))",
+			"insert into upd (pk, v) values (1, 1), (2, 2), (3, 3)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "update upd set v = 1",
+				Expected: []sql.Row{{newUpdateResult(3, 2)}},
+			},
+			{
+				Query:    "select pk, year(ts) = 2000, ts6 is null from upd order by pk",
+				Expected: []sql.Row{{1, true, true}, {2, false, false}, {3, false, false}},
+			},
+			{
+				Query:    "update upd set v = 2, ts = '2001-01-01' where pk = 1",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:    "insert into upd (pk, v) values (3, 3) on duplicate key update v = 4, ts6 = null",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query:    "select pk, v, year(ts) = 2001, ts6 is null from upd order by pk",
+				Expected: []sql.Row{{1, 2, true, false}, {2, 1, false, false}, {3, 4, false, true}},
+			},
+			{
+				Query: "show create table upd",
+				Expected: []sql.Row{{"upd", "CREATE TABLE `upd` (\n" +
+					"  `pk` int NOT NULL,\n" +
+					"  `v` int,\n" +
+					"  `ts` datetime DEFAULT '2000-01-01' ON UPDATE CURRENT_TIMESTAMP(),\n" +
+					"  `ts6` timestamp ON UPDATE CURRENT_TIMESTAMP(6),\n" +
+					"  PRIMARY KEY (`pk`)\n" +
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+			},
+			{
+				Query:       "create table upd2 (pk int primary key, v int on update current_timestamp)",
+				ExpectedErr: sql.ErrInvalidOnUpdate,
+			},
+			{
+				Query:       "create table upd2 (pk int primary key, v datetime on update utc_timestamp)",
+				ExpectedErr: sql.ErrInvalidOnUpdate,
+			},
+		},
+	},
+	{
+		Name: "column collations",
+		SetUpScript: []string{
+			"create table coll (pk int primary key, ci varchar(10) collate utf8mb4_general_ci, cs varchar(10) character set utf8mb4 collate utf8mb4_bin)",
+			"insert into coll values (1, 'abc', 'abc'), (2, 'ABC', 'ABC'), (3, 'Abd', 'Abd')",
+			"alter table coll add column ci2 varchar(10) character set latin1 collate latin1_general_ci",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select pk from coll where ci = 'ABC' order by pk",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				Query:    "select pk from coll where cs = 'ABC' order by pk",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "select pk from coll where concat(ci, 'X') = 'abcx' order by pk",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				Query:    "select pk from coll where ci < 'abd' order by pk",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				Query: "select column_name, character_set_name, collation_name from information_schema.columns where table_name = 'coll' and data_type = 'varchar' order by ordinal_position",
+				Expected: []sql.Row{
+					{"ci", "utf8mb4", "utf8mb4_general_ci"},
+					{"cs", "utf8mb4", "utf8mb4_bin"},
+					{"ci2", "latin1", "latin1_general_ci"},
+				},
+			},
+		},
+	},
+	{
+		Name: "invisible columns",
+		SetUpScript: []string{
+			"create table invcol (pk int primary key, v int, audit varchar(20) default 'created' invisible)",
+			"insert into invcol values (1, 10)",
+			"insert into invcol (pk, v, audit) values (2, 20, 'manual')",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select * from invcol order by pk",
+				Expected: []sql.Row{{1, 10}, {2, 20}},
+			},
+			{
+				Query:    "select pk, audit from invcol order by pk",
+				Expected: []sql.Row{{1, "created"}, {2, "manual"}},
+			},
+			{
+				Query:    "select a.*, b.audit from invcol a join invcol b on a.pk = b.pk order by a.pk",
+				Expected: []sql.Row{{1, 10, "created"}, {2, 20, "manual"}},
+			},
+			{
+				Query: "show create table invcol",
+				Expected: []sql.Row{{"invcol", "CREATE TABLE `invcol` (\n" +
+					"  `pk` int NOT NULL,\n" +
+					"  `v` int,\n" +
+					"  `audit` varchar(20) DEFAULT 'created' /*!80023 INVISIBLE */,\n" +
+					"  PRIMARY KEY (`pk`)\n" +
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+			},
+			{
+				Query:    "select column_name, extra from information_schema.columns where table_name = 'invcol' order by ordinal_position",
+				Expected: []sql.Row{{"pk", ""}, {"v", ""}, {"audit", "INVISIBLE"}},
+			},
+			{
+				Query:    "alter table invcol add column note varchar(10) invisible",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "alter table invcol alter column audit set visible",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select * from invcol order by pk",
+				Expected: []sql.Row{{1, 10, "created"}, {2, 20, "manual"}},
+			},
+			{
+				Query:    "alter table invcol modify column note varchar(10)",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select * from invcol order by pk",
+				Expected: []sql.Row{{1, 10, "created", nil}, {2, 20, "manual", nil}},
+			},
+			{
+				Query:       "create table invcol2 (pk int primary key invisible)",
+				ExpectedErr: sql.ErrTableMustHaveVisibleColumn,
+			},
+		},
+	},
+	{
+		Name: "table options",
+		SetUpScript: []string{
+			"create table opts (pk int primary key auto_increment) engine=MyISAM auto_increment=10 default charset=utf8mb4 row_format=compressed comment='it''s options' key_block_size=8",
+			"create table opts_like like opts",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "show create table opts",
+				Expected: []sql.Row{{"opts", "CREATE TABLE `opts` (\n" +
+					"  `pk` int NOT NULL AUTO_INCREMENT,\n" +
+					"  PRIMARY KEY (`pk`)\n" +
+					") ENGINE=MyISAM AUTO_INCREMENT=10 DEFAULT CHARSET=utf8mb4 ROW_FORMAT=compressed COMMENT='it''s options' KEY_BLOCK_SIZE=8"}},
+			},
+			{
+				Query: "select table_name, engine, row_format, create_options, table_comment from information_schema.tables where table_name like 'opts%' order by 1",
+				Expected: []sql.Row{
+					{"opts", "MyISAM", "Compressed", "row_format=compressed key_block_size=8", "it's options"},
+					{"opts_like", "MyISAM", "Compressed", "row_format=compressed key_block_size=8", "it's options"},
+				},
+			},
+			{
+				Query:    "insert into opts values (null)",
+				Expected: []sql.Row{{sql.OkResult{RowsAffected: 1, InsertID: 10}}},
+			},
+			{
+				Query:    "select pk from opts",
+				Expected: []sql.Row{{10}},
+			},
+		},
+	},
+	{
+		Name: "show create table round trip",
+		SetUpScript: []string{
+			"create table `odd``name` (pk int primary key, e enum('Small','it''s') not null default 'Small' comment 'it''s an enum', " +
+				"l varchar(10) character set latin1 collate latin1_general_ci, n varchar(10) default 'none')",
+			"alter table `odd``name` add constraint `chk``pk` check (pk > 0)",
+			"create unique index `u``l` using hash on `odd``name` (l) comment 'unique ''l'''",
+			"create index idx_n on `odd``name` (n)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "show create table `odd``name`",
+				Expected: []sql.Row{{"odd`name", "CREATE TABLE `odd``name` (\n" +
+					"  `pk` int NOT NULL,\n" +
+					"  `e` enum('Small','it''s') NOT NULL DEFAULT 'Small' COMMENT 'it''s an enum',\n" +
+					"  `l` varchar(10) CHARACTER SET latin1 COLLATE latin1_general_ci,\n" +
+					"  `n` varchar(10) DEFAULT 'none',\n" +
+					"  PRIMARY KEY (`pk`),\n" +
+					"  KEY `idx_n` (`n`),\n" +
+					"  UNIQUE KEY `u``l` (`l`) USING HASH COMMENT 'unique ''l''',\n" +
+					"  CONSTRAINT `chk``pk` CHECK (pk > 0)\n" +
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+			},
+		},
+	},
+	{
+		Name: "rename tables atomically",
+		SetUpScript: []string{
+			"create table a (pk int primary key)",
+			"create table b (pk int primary key)",
+			"create table child (pk int primary key, a_pk int)",
+			"alter table child add constraint fk_a foreign key (a_pk) references a (pk)",
+			"create table log (msg varchar(20))",
+			"create trigger a_insert before insert on `a` for each row insert into log values ('into a')",
+			"insert into a values (1)",
+			"insert into b values (2)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "rename table a to tmp, b to a, tmp to b",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select pk from a",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "select pk from b",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query: "show create table child",
+				Expected: []sql.Row{{"child", "CREATE TABLE `child` (\n" +
+					"  `pk` int NOT NULL,\n" +
+					"  `a_pk` int,\n" +
+					"  PRIMARY KEY (`pk`),\n" +
+					"  CONSTRAINT `fk_a` FOREIGN KEY (`a_pk`) REFERENCES `b` (`pk`)\n" +
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+			},
+			{
+				Query:    "select trigger_name, event_object_table from information_schema.triggers",
+				Expected: []sql.Row{{"a_insert", "b"}},
+			},
+			{
+				Query:       "rename table a to c, missing to d",
+				ExpectedErr: sql.ErrTableNotFound,
+			},
+			{
+				Query:       "rename table a to c, b to c",
+				ExpectedErr: sql.ErrTableAlreadyExists,
+			},
+			{
+				Query:    "show tables",
+				Expected: []sql.Row{{"a"}, {"b"}, {"child"}, {"log"}, {"myview"}},
+			},
+		},
+	},
+	{
+		Name: "create index on a renamed table",
+		SetUpScript: []string{
+			"create table r (pk int primary key, v int, index v_idx (v))",
+			"insert into r values (1, 10), (2, 20)",
+			"rename table r to s",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "create index pk_v on s (pk, v)",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "create index v2 on s ((v * 2))",
+				Expected: []sql.Row{},
+			},
+			{
+				Query: "show create table s",
+				Expected: []sql.Row{{"s", "CREATE TABLE `s` (\n" +
+					"  `pk` int NOT NULL,\n" +
+					"  `v` int,\n" +
+					"  PRIMARY KEY (`pk`),\n" +
+					"  KEY `pk_v` (`pk`,`v`),\n" +
+					"  KEY `v_idx` (`v`)\n" +
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n" +
+					"CREATE INDEX `v2` ON `s` ((`v` * 2))"}},
+			},
+			{
+				Query:    "select pk from s where v = 20",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "select pk from s where v * 2 = 20",
+				Expected: []sql.Row{{1}},
+			},
+		},
+	},
+	{
+		Name: "sql_mode",
+		SetUpScript: []string{
+			"create table modes (pk int primary key, ti tinyint, s varchar(3), dt date, dc decimal(4,2))",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "insert into modes (pk, ti) values (1, 300)",
+				ExpectedErr: sql.ErrOutOfRange,
+			},
+			{
+				Query:       "insert into modes (pk, s) values (1, 'abcdef')",
+				ExpectedErr: sql.ErrLengthBeyondLimit,
+			},
+			{
+				Query:    "insert into modes (pk, dt) values (1, '0000-00-00')",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "set sql_mode = 'TRADITIONAL'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:       "insert into modes (pk, dt) values (2, '0000-00-00')",
+				ExpectedErr: sql.ErrInvalidZeroDate,
+			},
+			{
+				Query:       "insert into modes (pk, ti) values (2, 1 div 0)",
+				ExpectedErr: sql.ErrDivisionByZero,
+			},
+			{
+				Query:       "update modes set ti = 5 % 0",
+				ExpectedErr: sql.ErrDivisionByZero,
+			},
+			{
+				Query:    "select 1 div 0",
+				Expected: []sql.Row{{sql.Null}},
+			},
+			{
+				Query:    "show warnings limit 1",
+				Expected: []sql.Row{{"Warning", 1365, "Division by 0"}},
+			},
+			{
+				Query:    "set sql_mode = ''",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "insert into modes values (3, 300, 'abcdef', '2020-01-01', 1000)",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query: "show warnings limit 3",
+				Expected: []sql.Row{
+					{"Warning", 1264, "Out of range value for column 'dc'"},
+					{"Warning", 1265, "Data truncated for column 's'"},
+					{"Warning", 1264, "Out of range value for column 'ti'"},
+				},
+			},
+			{
+				Query:    "insert into modes (pk, ti) values (4, 'abc')",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "show warnings limit 1",
+				Expected: []sql.Row{{"Warning", 1366, "Incorrect tinyint value: 'abc' for column 'ti'"}},
+			},
+			{
+				Query:    "update modes set ti = -1000 where pk = 4",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:    "select pk, ti, s, dc from modes where pk > 1 order by pk",
+				Expected: []sql.Row{{3, 127, "abc", "99.99"}, {4, -128, nil, nil}},
+			},
+			{
+				Query:    "set sql_mode = 'PIPES_AS_CONCAT'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "select 'a' || 'b' || s, 1 or 0 from modes where pk = 3",
+				Expected: []sql.Row{{"ababc", true}},
+			},
+			{
+				Query:    "set sql_mode = default",
+				Expected: []sql.Row{{}},
+			},
+		},
+	},
+	{
+		Name: "only full group by",
+		SetUpScript: []string{
+			"create table grouped (pk int primary key, u int not null, n int, v varchar(10), unique key u (u), unique key n (n))",
+			"insert into grouped values (1, 10, null, 'a'), (2, 20, null, 'b'), (3, 30, 200, 'a')",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "select n, v from grouped group by n",
+				ExpectedErr: analyzer.ErrValidationGroupBy,
+			},
+			{
+				Query:       "select v, count(*) from grouped",
+				ExpectedErr: analyzer.ErrValidationAggregationWithoutGroupBy,
+			},
+			{
+				Query:       "select g.v from grouped g join grouped h on g.pk = h.pk group by h.pk",
+				ExpectedErr: analyzer.ErrValidationGroupBy,
+			},
+			{
+				Query:    "select pk, v from grouped group by pk order by pk",
+				Expected: []sql.Row{{1, "a"}, {2, "b"}, {3, "a"}},
+			},
+			{
+				Query:    "select u, v, count(*) from grouped group by u order by u",
+				Expected: []sql.Row{{10, "a", 1}, {20, "b", 1}, {30, "a", 1}},
+			},
+			{
+				Query:    "select pk as id, v from grouped group by id order by id",
+				Expected: []sql.Row{{1, "a"}, {2, "b"}, {3, "a"}},
+			},
+			{
+				Query:    "select n + 1, count(*) from grouped group by n + 1 order by 1",
+				Expected: []sql.Row{{nil, 2}, {201, 1}},
+			},
+			{
+				Query:    "select v, (select count(*) from grouped h where h.v = grouped.v) from grouped group by v order by v",
+				Expected: []sql.Row{{"a", 2}, {"b", 1}},
+			},
+			{
+				Query:    "select v, count(*) from grouped where v = 'a'",
+				Expected: []sql.Row{{"a", 2}},
+			},
+			{
+				Query:    "select char_length(v), count(*) from grouped group by 1 having char_length(v) > 0",
+				Expected: []sql.Row{{1, 3}},
+			},
+			{
+				Query:       "select n, count(*) from grouped group by n having v = 'a'",
+				ExpectedErr: analyzer.ErrValidationGroupBy,
+			},
+			{
+				Query:    "set sql_mode = ''",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "select count(*) from (select n, v from grouped group by n) g",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "set sql_mode = default",
+				Expected: []sql.Row{{}},
+			},
+		},
+	},
+	{
+		Name: "group by subquery alias without only full group by",
+		SetUpScript: []string{
+			"create table one_pk (pk int primary key)",
+			"insert into one_pk values (0), (1), (2), (3)",
+			"set sql_mode = ''",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: `SELECT pk, (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) AS x FROM one_pk opk GROUP BY x ORDER BY x`,
+				Expected: []sql.Row{
+					{0, nil},
+					{1, 0},
+					{2, 1},
+					{3, 2},
+				},
+			},
+			{
+				Query: `SELECT pk, (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) AS x
+						FROM one_pk opk WHERE (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) > 0
+						GROUP BY x ORDER BY x`,
+				Expected: []sql.Row{
+					{2, 1},
+					{3, 2},
+				},
+			},
+			{
+				Query: `SELECT pk, (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) AS x
+						FROM one_pk opk WHERE (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) > 0
+						GROUP BY (SELECT max(pk) FROM one_pk WHERE pk < opk.pk) ORDER BY x`,
+				Expected: []sql.Row{
+					{2, 1},
+					{3, 2},
+				},
+			},
+		},
+	},
+	{
+		Name: "sql_mode parsing",
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "set sql_mode = 'ANSI_QUOTES'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    `create table "quoted" ("a b" int primary key, s varchar(20))`,
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    `insert into "quoted" values (1, 'x'), (2, 'it''s "y"')`,
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query:    `select "a b", s from quoted order by "a b"`,
+				Expected: []sql.Row{{1, "x"}, {2, `it's "y"`}},
+			},
+			{
+				Query:    "set sql_mode = 'NO_BACKSLASH_ESCAPES'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    `select 'a\nb', length('\\'), "\"`,
+				Expected: []sql.Row{{`a\nb`, 2, `\`}},
+			},
+			{
+				Query:    "set sql_mode = 'REAL_AS_FLOAT'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "create table reals (pk int primary key, r real)",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select data_type from information_schema.columns where table_name = 'reals' and column_name = 'r'",
+				Expected: []sql.Row{{"float"}},
+			},
+			{
+				Query:    "set sql_mode = 'ANSI'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    `select "a b" || ':' || s from quoted order by 1`,
+				Expected: []sql.Row{{"1:x"}, {`2:it's "y"`}},
+			},
+			{
+				Query:    "set sql_mode = default",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    `select "a b", 'a\tb' from quoted where "a b" = 1`,
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    `select "a b", 'a\tb' from quoted where "a b" = "a b"`,
+				Expected: []sql.Row{{"a b", "a\tb"}, {"a b", "a\tb"}},
+			},
+		},
+	},
+	{
+		Name: "zero dates",
+		SetUpScript: []string{
+			"create table zd (pk int primary key, d date, dt datetime, ts timestamp)",
+			`insert into zd values (1, '0000-00-00', '0000-00-00 00:00:00', '0000-00-00 00:00:00'),
+				(2, '2020-01-02', '2020-01-02 03:04:05', '2020-01-02 03:04:05'),
+				(3, 0, '0000-00-00 00:00:00.000000', 0)`,
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select pk from zd where d = '0000-00-00' order by pk",
+				Expected: []sql.Row{{1}, {3}},
+			},
+			{
+				Query:    "select pk from zd where d = '2020-01-02'",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "select pk from zd where dt < '2000-01-01' and ts = '0000-00-00 00:00:00' order by pk",
+				Expected: []sql.Row{{1}, {3}},
+			},
+			{
+				Query:    "select cast(d as char), concat(dt, ''), date(dt), date_format(d, '%Y/%m/%d %H:%i'), date_format(d, '%W') from zd where pk = 1",
+				Expected: []sql.Row{{"0000-00-00 00:00:00", "0000-00-00 00:00:00", "0000-00-00", "0000/00/00 00:00", nil}},
+			},
+			{
+				Query:    "select year(d), month(d), day(d), dayofweek(d), unix_timestamp(ts) from zd where pk = 1",
+				Expected: []sql.Row{{0, 0, 0, nil, float64(0)}},
+			},
+			{
+				Query:    "select date_add(d, interval 1 day), date_sub(dt, interval 1 day), dayname(d), monthname(d), weekofyear(dt) from zd where pk = 1",
+				Expected: []sql.Row{{nil, nil, nil, nil, nil}},
+			},
+			{
+				Query:    "select pk from zd where dt = 0 and d = 0 order by pk",
+				Expected: []sql.Row{{1}, {3}},
+			},
+			{
+				Query:    "select pk from zd where d = 20200102 and dt = 20200102030405",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "insert into zd (pk, d, dt) values (6, '2020-00-15', '2020-02-00 10:11:12')",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select date(d), concat(dt, ''), year(d), month(d), day(dt), dayname(d), date_add(dt, interval 1 day), date_format(d, '%Y %c %e') from zd where pk = 6",
+				Expected: []sql.Row{{"2020-00-15", "2020-02-00 10:11:12", 2020, 0, 0, nil, nil, "2020 0 15"}},
+			},
+			{
+				Query:    "select pk from zd where d > '2020-00-01' and d < '2020-00-31' and dt = 20200200101112",
+				Expected: []sql.Row{{6}},
+			},
+			{
+				Query:    "set sql_mode = 'NO_ZERO_DATE'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "insert into zd (pk, d) values (4, '0000-00-00')",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "show warnings limit 1",
+				Expected: []sql.Row{{"Warning", 1292, "Incorrect date value: '0000-00-00' for column 'd'"}},
+			},
+			{
+				Query:    "set sql_mode = 'STRICT_ALL_TABLES,NO_ZERO_DATE'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:       "insert into zd (pk, d) values (5, '0000-00-00')",
+				ExpectedErr: sql.ErrInvalidZeroDate,
+			},
+			{
+				Query:    "set sql_mode = 'NO_ZERO_IN_DATE'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "insert into zd (pk, d) values (7, '2020-00-15')",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "show warnings limit 1",
+				Expected: []sql.Row{{"Warning", 1292, "Incorrect date value: '2020-00-15' for column 'd'"}},
+			},
+			{
+				Query:    "select date(d) from zd where pk = 7",
+				Expected: []sql.Row{{"0000-00-00"}},
+			},
+			{
+				Query:    "set sql_mode = 'STRICT_ALL_TABLES,NO_ZERO_IN_DATE'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:       "insert into zd (pk, d) values (8, '2020-00-15')",
+				ExpectedErr: sql.ErrInvalidZeroDate,
+			},
+			{
+				Query:    "set sql_mode = default",
+				Expected: []sql.Row{{}},
+			},
+		},
+	},
+	{
+		Name: "warnings",
+		SetUpScript: []string{
+			"create table warn (pk int primary key, c varchar(3) not null)",
+			"alter table warn add constraint pk_small check (pk < 100)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "insert ignore into warn values (1, 'abcdef'), (1, 'x'), (2, null), (200, 'y')",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query: "show warnings",
+				Expected: []sql.Row{
+					{"Warning", 3819, "Check constraint 'pk_small' is violated."},
+					{"Warning", 1048, "Column 'c' cannot be null"},
+					{"Warning", 1062, "duplicate unique key for PRIMARY"},
+					{"Warning", 1265, "Data truncated for column 'c'"},
+				},
+			},
+			{
+				Query:    "show count(*) warnings",
+				Expected: []sql.Row{{int64(4)}},
+			},
+			{
+				Query:    "show warnings limit 1",
+				Expected: []sql.Row{{"Warning", 3819, "Check constraint 'pk_small' is violated."}},
+			},
+			{
+				Query:    "select * from warn order by pk",
+				Expected: []sql.Row{{1, "abc"}, {2, ""}},
+			},
+			{
+				Query:    "show warnings",
+				Expected: nil,
+			},
+			{
+				Query:    "drop table if exists nope",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "show warnings",
+				Expected: []sql.Row{{"Note", 1051, "Unknown table 'mydb.nope'"}},
+			},
+			{
+				Query:    "create table if not exists warn (pk int primary key)",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "show warnings",
+				Expected: []sql.Row{{"Note", 1050, "Table 'warn' already exists"}},
+			},
+			{
+				Query:    "set sql_notes = 0",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "drop table if exists nope",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "show count(*) warnings",
+				Expected: []sql.Row{{int64(0)}},
+			},
+			{
+				Query:    "set sql_notes = 1",
+				Expected: []sql.Row{{}},
+			},
+		},
+	},
+	{
+		Name: "diagnostics",
+		SetUpScript: []string{
+			"create table diag (pk int primary key)",
+			"insert into diag values (1)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "insert into diag values (1)",
+				ExpectedErr: sql.ErrUniqueKeyViolation,
+			},
+			{
+				Query:    "show errors",
+				Expected: []sql.Row{{"Error", 1105, "duplicate unique key for PRIMARY"}},
+			},
+			{
+				Query:    "show count(*) errors",
+				Expected: []sql.Row{{int64(1)}},
+			},
+			{
+				Query:    "get diagnostics @n = number",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "get diagnostics condition 1 @state = returned_sqlstate, @msg = message_text, @errno = mysql_errno",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select @n, @state, @msg, @errno",
+				Expected: []sql.Row{{int64(1), "HY000", "duplicate unique key for PRIMARY", int64(1105)}},
+			},
+			{
+				Query:    "show count(*) errors",
+				Expected: []sql.Row{{int64(0)}},
+			},
+			{
+				Query:    "drop table if exists nope",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "set @cond = 1",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "show errors",
+				Expected: nil,
+			},
+			{
+				Query:       "get diagnostics condition 2 @msg = message_text",
+				ExpectedErr: sql.ErrInvalidConditionNumber,
+			},
+			{
+				Query:       "get stacked diagnostics @n = number",
+				ExpectedErr: sql.ErrStackedDiagnosticsWithoutHandler,
+			},
+		},
+	},
+	{
+		Name: "row_count and found_rows",
+		SetUpScript: []string{
+			"create table counted (pk int primary key, v int)",
+			"insert into counted values (1, 1), (2, 1), (3, 2), (4, 2), (5, 3)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "update counted set v = 2 where v = 1",
+				Expected: []sql.Row{{newUpdateResult(2, 2)}},
+			},
+			{
+				Query:    "select row_count()",
+				Expected: []sql.Row{{int64(2)}},
+			},
+			{
+				Query:    "select row_count()",
+				Expected: []sql.Row{{int64(-1)}},
+			},
+			{
+				Query:    "delete from counted where v = 3",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select row_count()",
+				Expected: []sql.Row{{int64(1)}},
+			},
+			{
+				Query:    "select * from counted where v = 2 order by pk limit 2",
+				Expected: []sql.Row{{1, 2}, {2, 2}},
+			},
+			{
+				Query:    "select found_rows()",
+				Expected: []sql.Row{{int64(2)}},
+			},
+			{
+				Query:    "select sql_calc_found_rows * from counted where v = 2 order by pk limit 1, 2",
+				Expected: []sql.Row{{2, 2}, {3, 2}},
+			},
+			{
+				Query:    "select found_rows()",
+				Expected: []sql.Row{{int64(4)}},
+			},
+			{
+				Query:    "select found_rows()",
+				Expected: []sql.Row{{int64(1)}},
+			},
+			{
+				Query:    "select sql_calc_found_rows pk from counted limit 10, 1",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select found_rows()",
+				Expected: []sql.Row{{int64(4)}},
+			},
+		},
+	},
+	{
+		Name: "last_insert_id",
+		SetUpScript: []string{
+			"create table ids (pk int primary key auto_increment, v int)",
+			"insert into ids (v) values (1)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select last_insert_id()",
+				Expected: []sql.Row{{uint64(1)}},
+			},
+			{
+				Query:    "insert into ids (v) values (2), (3), (4)",
+				Expected: []sql.Row{{sql.OkResult{RowsAffected: 3, InsertID: 2}}},
+			},
+			{
+				Query:    "select last_insert_id()",
+				Expected: []sql.Row{{uint64(2)}},
+			},
+			{
+				Query:    "insert into ids values (10, 10)",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select last_insert_id()",
+				Expected: []sql.Row{{uint64(2)}},
+			},
+			{
+				Query:    "select last_insert_id(42)",
+				Expected: []sql.Row{{uint64(42)}},
+			},
+			{
+				Query:    "select last_insert_id()",
+				Expected: []sql.Row{{uint64(42)}},
+			},
+			{
+				Query:    "insert into ids (v) values (last_insert_id())",
+				Expected: []sql.Row{{sql.OkResult{RowsAffected: 1, InsertID: 11}}},
+			},
+			{
+				Query:    "select pk, v from ids where pk = last_insert_id()",
+				Expected: []sql.Row{{11, 42}},
+			},
+		},
+	},
+	{
+		Name: "delete and update of index ranges",
+		SetUpScript: []string{
+			"create table ranges (pk int primary key, v int)",
+			"alter table ranges add check (v < 100)",
+			"insert into ranges values (1, 1), (2, 2), (3, 3), (4, 4), (5, 5), (6, 6), (7, 7), (8, 8)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "delete from ranges where pk between 2 and 3",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query:    "delete from ranges where pk > 6 and v = 7",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query: "update ranges set v = 5 where pk between 4 and 5",
+				Expected: []sql.Row{{sql.OkResult{
+					RowsAffected: 1,
+					Info:         plan.UpdateInfo{Matched: 2, Updated: 1},
+				}}},
+			},
+			{
+				Query:       "update ranges set v = v * 100 where pk between 4 and 6",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:    "select * from ranges",
+				Expected: []sql.Row{{1, 1}, {4, 5}, {5, 5}, {6, 6}, {8, 8}},
+			},
+		},
+	},
+	{
+		Name: "delete and update with order by and limit",
+		SetUpScript: []string{
+			"create table purge (pk int primary key, c int)",
+			"create table purged (id int primary key auto_increment, pk int)",
+			"create trigger log_purge before delete on purge for each row insert into purged (pk) values (old.pk)",
+			"insert into purge values (1, 5), (2, 4), (3, 3), (4, 2), (5, 1)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "delete from purge where pk < 5 order by c limit 2",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query:    "select pk from purged order by id",
+				Expected: []sql.Row{{4}, {3}},
+			},
+			{
+				Query: "update purge set c = 0 order by c desc limit 2",
+				Expected: []sql.Row{{sql.OkResult{
+					RowsAffected: 2,
+					Info:         plan.UpdateInfo{Matched: 2, Updated: 2},
+				}}},
+			},
+			{
+				Query:    "select * from purge order by pk",
+				Expected: []sql.Row{{1, 0}, {2, 0}, {5, 1}},
+			},
+		},
+	},
+	{
+		Name: "joined deletes of tables with triggers or foreign keys",
+		SetUpScript: []string{
+			"create table parent (pk int primary key)",
+			"create table child (pk int primary key, parent_pk int)",
+			"create table orphan (pk int primary key, parent_pk int)",
+			"create table restricted (pk int primary key, parent_pk int)",
+			"create table audited (pk int primary key)",
+			"create table audit (pk int, event varchar(10))",
+			"alter table child add constraint fk_parent foreign key (parent_pk) references parent (pk) on delete cascade",
+			"alter table orphan add constraint fk_orphan foreign key (parent_pk) references parent (pk) on delete set null",
+			"alter table restricted add constraint fk_restricted foreign key (parent_pk) references parent (pk)",
+			"create trigger audit_before before delete on audited for each row insert into audit values (old.pk, 'before')",
+			"create trigger audit_after after delete on audited for each row insert into audit values (old.pk, 'after')",
+			"insert into parent values (1), (2), (3), (4)",
+			"insert into child values (1, 1), (2, 1), (3, 2), (4, 3)",
+			"insert into orphan values (1, 1), (2, 2)",
+			"insert into restricted values (1, 4)",
+			"insert into audited values (1), (2), (3)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "delete from parent using parent join audited on parent.pk = audited.pk where parent.pk < 3",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query:    "select * from child order by pk",
+				Expected: []sql.Row{{4, 3}},
+			},
+			{
+				Query:    "select * from orphan order by pk",
+				Expected: []sql.Row{{1, nil}, {2, nil}},
+			},
+			{
+				Query:       "delete parent from parent join restricted on parent.pk = restricted.parent_pk",
+				ExpectedErr: sql.ErrForeignKeyParentViolation,
+			},
+			{
+				Query:    "delete parent, child from parent join child on parent.pk = child.parent_pk",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select * from parent order by pk",
+				Expected: []sql.Row{{4}},
+			},
+			{
+				Query:    "select * from child order by pk",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "delete audited from audited join orphan on audited.pk = orphan.pk",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query:    "select * from audit",
+				Expected: []sql.Row{{1, "before"}, {1, "after"}, {2, "before"}, {2, "after"}},
+			},
+			{
+				Query:    "delete audited, orphan from audited join orphan on audited.pk > orphan.pk",
+				Expected: []sql.Row{{sql.NewOkResult(3)}},
+			},
+			{
+				Query:    "select * from audit where pk = 3",
+				Expected: []sql.Row{{3, "before"}, {3, "after"}},
+			},
+			{
+				Query:    "select * from orphan",
+				Expected: []sql.Row{},
+			},
+		},
+	},
+	{
+		Name: "insert into ... select copies of tables",
+		SetUpScript: []string{
+			"create table src (pk int primary key, v int)",
+			"create table dst (pk int primary key, v int)",
+			"create table narrow (pk int primary key, v tinyint)",
+			"insert into src values (1, 1), (2, 2), (3, 300)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "insert into dst select * from src where pk > 1",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query:    "insert into dst select * from src where pk = 1",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:       "insert into dst select * from src",
+				ExpectedErr: sql.ErrUniqueKeyViolation,
+			},
+			{
+				Query:    "select * from dst order by pk",
+				Expected: []sql.Row{{1, 1}, {2, 2}, {3, 300}},
+			},
+			{
+				Query:       "insert into narrow select * from src",
+				ExpectedErr: sql.ErrOutOfRange,
+			},
+			{
+				Query:    "insert into narrow select * from src where v < 100",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+		},
+	},
 }
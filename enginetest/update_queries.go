@@ -90,6 +90,12 @@ var UpdateTests = []WriteQueryTest{
 		SelectQuery:         "SELECT * FROM mytable;",
 		ExpectedSelect:      []sql.Row{{int64(1), "first row"}, {int64(2), "updated"}, {int64(3), "updated"}},
 	},
+	{
+		WriteQuery:          "UPDATE mytable SET s = 'updated' WHERE i < 3 ORDER BY s DESC LIMIT 1;",
+		ExpectedWriteResult: []sql.Row{{newUpdateResult(1, 1)}},
+		SelectQuery:         "SELECT * FROM mytable;",
+		ExpectedSelect:      []sql.Row{{int64(1), "first row"}, {int64(2), "updated"}, {int64(3), "third row"}},
+	},
 	{
 		WriteQuery:          "UPDATE mytable SET s = 'updated' ORDER BY i LIMIT 1 OFFSET 1;",
 		ExpectedWriteResult: []sql.Row{{newUpdateResult(1, 1)}},
@@ -55,9 +55,9 @@ var VariableQueries = []ScriptTest{
 	{
 		Name: "set system variable ON / OFF",
 		SetUpScript: []string{
-			"set @@autocommit = ON, sql_mode = OFF",
+			"set @@autocommit = ON, unique_checks = OFF",
 		},
-		Query: "SELECT @@autocommit, @@session.sql_mode",
+		Query: "SELECT @@autocommit, @@session.unique_checks",
 		Expected: []sql.Row{
 			{1, 0},
 		},
@@ -65,9 +65,9 @@ var VariableQueries = []ScriptTest{
 	{
 		Name: "set system variable true / false quoted",
 		SetUpScript: []string{
-			`set @@autocommit = "true", sql_mode = "false"`,
+			`set @@autocommit = "true", unique_checks = "false"`,
 		},
-		Query: "SELECT @@autocommit, @@session.sql_mode",
+		Query: "SELECT @@autocommit, @@session.unique_checks",
 		Expected: []sql.Row{
 			{1, 0},
 		},
@@ -75,9 +75,9 @@ var VariableQueries = []ScriptTest{
 	{
 		Name: "set system variable true / false",
 		SetUpScript: []string{
-			`set @@autocommit = true, sql_mode = false`,
+			`set @@autocommit = true, unique_checks = false`,
 		},
-		Query: "SELECT @@autocommit, @@session.sql_mode",
+		Query: "SELECT @@autocommit, @@session.unique_checks",
 		Expected: []sql.Row{
 			{1, 0},
 		},
@@ -145,16 +145,40 @@ var VariableQueries = []ScriptTest{
 			{"some_mode"},
 		},
 	},
-	// TODO: for compatibility, we allow unknown system variables to be set as well. For full MySQL emulation, we need to
-	//  list every system variable MySQL supports and reject all others.
 	{
-		Name: "set unknown system variable",
+		Name: "set global system variable",
 		SetUpScript: []string{
-			`set dne = "hello"`,
+			`set global wait_timeout = 100`,
+			`set @global_timeout = @@global.wait_timeout`,
+			`set @@global.wait_timeout = default`,
 		},
-		Query: "SELECT @@dne",
+		Query: "SELECT @global_timeout, @@global.wait_timeout, @@wait_timeout, @@session.wait_timeout",
 		Expected: []sql.Row{
-			{"hello"},
+			{100, 28800, 28800, 28800},
+		},
+	},
+	{
+		Name: "global only system variable",
+		SetUpScript: []string{
+			`set global max_connections = 10`,
+			`set @max_connections = @@max_connections`,
+			`set global max_connections = default`,
+		},
+		Query: "SELECT @max_connections, @@max_connections",
+		Expected: []sql.Row{
+			{10, 151},
+		},
+	},
+	{
+		Name: "set session tracking system variables",
+		SetUpScript: []string{
+			`set session_track_schema = OFF, session_track_state_change = ON`,
+			`set session_track_transaction_info = 'characteristics'`,
+			`set session_track_system_variables = '*'`,
+		},
+		Query: "SELECT @@session_track_schema, @@session_track_state_change, @@session_track_transaction_info, @@session_track_system_variables",
+		Expected: []sql.Row{
+			{0, 1, "CHARACTERISTICS", "*"},
 		},
 	},
 	// User variables
@@ -209,17 +233,163 @@ var VariableQueries = []ScriptTest{
 			{1234, 1234},
 		},
 	},
+	{
+		Name: "set user var with :=",
+		SetUpScript: []string{
+			`set @a := 1, @b := @a + 1`,
+			`set @c = @d := 'hello'`,
+		},
+		Query: "SELECT @a, @b, @c, @d",
+		// @a has no value before the statement, so @a + 1 is a float.
+		Expected: []sql.Row{
+			{1, float64(2), "hello", "hello"},
+		},
+	},
+	{
+		Name: "user var assignments in a query",
+		SetUpScript: []string{
+			`create table t (i bigint primary key, s text)`,
+			`insert into t values (1, 'first row'), (2, 'second row'), (3, 'third row')`,
+			`set @total = 0`,
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "SELECT i, @total := @total + i FROM t ORDER BY i",
+				Expected: []sql.Row{{1, 1}, {2, 3}, {3, 6}},
+			},
+			{
+				Query:    "SELECT @total, @last := 'done', @last",
+				Expected: []sql.Row{{6, "done", "done"}},
+			},
+			{
+				Query:    "SELECT CASE WHEN @x := 3 THEN 'a' ELSE 'b' END, @x",
+				Expected: []sql.Row{{"a", 3}},
+			},
+		},
+	},
+	{
+		Name: "prepared statements",
+		SetUpScript: []string{
+			`create table t (i bigint primary key, s text)`,
+			`insert into t values (1, 'first row'), (2, 'second row'), (3, 'third row')`,
+			`set @min = 1, @s = 'second row'`,
+			`prepare stmt from 'SELECT i, ? FROM t WHERE i > ? ORDER BY i'`,
+			`set @query = 'SELECT s FROM t WHERE i = ?'`,
+			`prepare stmt2 from @query`,
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "EXECUTE stmt USING @s, @min",
+				Expected: []sql.Row{{2, "second row"}, {3, "second row"}},
+			},
+			{
+				Query:       "EXECUTE stmt USING @min",
+				ExpectedErr: sql.ErrWrongArgumentsToExecute,
+			},
+			{
+				Query:    "EXECUTE stmt2 USING @min",
+				Expected: []sql.Row{{"first row"}},
+			},
+			{
+				Query:    "DEALLOCATE PREPARE stmt",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:       "EXECUTE stmt USING @s, @min",
+				ExpectedErr: sql.ErrUnknownPreparedStatement,
+			},
+		},
+	},
+	{
+		Name: "max_execution_time",
+		SetUpScript: []string{
+			"set @@max_execution_time = 50",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "SELECT @@max_execution_time",
+				Expected: []sql.Row{{int64(50)}},
+			},
+			{
+				Query:       "SELECT SLEEP(1)",
+				ExpectedErr: sql.ErrQueryTimeout,
+			},
+			{
+				Query:    "SELECT /*+ MAX_EXECUTION_TIME(2000) */ SLEEP(0.1)",
+				Expected: []sql.Row{{int(0)}},
+			},
+		},
+	},
+	{
+		Name: "max_execution_time hint",
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "SELECT /*+ MAX_EXECUTION_TIME(50) */ SLEEP(1)",
+				ExpectedErr: sql.ErrQueryTimeout,
+			},
+			{
+				Query:    "SELECT /*+ MAX_EXECUTION_TIME(0) */ SLEEP(0.1)",
+				Expected: []sql.Row{{int(0)}},
+			},
+		},
+	},
 }
 
 var VariableErrorTests = []QueryErrorTest{
-	// TODO: for compatibility, we allow unknown system variables to be set as well. For full MySQL emulation, we need to
-	//  list every system variable MySQL supports and reject all others.
-	// {
-	// 	Query:       "set @@does_not_exist = 100",
-	// 	ExpectedErr: sql.ErrUnknownSystemVariable,
-	// },
+	{
+		Query:       "set @@does_not_exist = 100",
+		ExpectedErr: sql.ErrUnknownSystemVariable,
+	},
+	{
+		Query:       "set does_not_exist = 100",
+		ExpectedErr: sql.ErrUnknownSystemVariable,
+	},
+	{
+		Query:       "select @@does_not_exist",
+		ExpectedErr: sql.ErrUnknownSystemVariable,
+	},
+	{
+		Query:       "set @@version = '1.0'",
+		ExpectedErr: sql.ErrSystemVariableReadOnly,
+	},
+	{
+		Query:       "set max_connections = 10",
+		ExpectedErr: sql.ErrSystemVariableGlobalOnly,
+	},
+	{
+		Query:       "select @@session.max_connections",
+		ExpectedErr: sql.ErrSystemVariableGlobalOnly,
+	},
+	{
+		Query:       "set @@auto_increment_increment = 0",
+		ExpectedErr: sql.ErrInvalidSystemVariableValue,
+	},
+	{
+		Query:       "set autocommit = 2",
+		ExpectedErr: sql.ErrInvalidSystemVariableValue,
+	},
+	{
+		Query:       "set session_track_transaction_info = 'all'",
+		ExpectedErr: sql.ErrInvalidSystemVariableValue,
+	},
 	{
 		Query:       "set @myvar = bareword",
 		ExpectedErr: sql.ErrColumnNotFound,
 	},
+	{
+		Query:       "deallocate prepare nonexistent",
+		ExpectedErr: sql.ErrUnknownPreparedStatement,
+	},
+	{
+		Query:       "set persist wait_timeout = 60",
+		ExpectedErr: sql.ErrPersistNotSupported,
+	},
+	{
+		Query:       "reset persist",
+		ExpectedErr: sql.ErrPersistNotSupported,
+	},
+	{
+		Query:       "select @@persist.wait_timeout",
+		ExpectedErr: sql.ErrUnknownSystemVariable,
+	},
 }
@@ -0,0 +1,153 @@
+package federated
+
+import (
+	gosql "database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/parse"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// Database is a database whose tables are proxies for the tables of a database in a remote MySQL server. Queries on
+// its tables are translated into queries on the remote server, pushing down filters, projections and limits where
+// possible, so that only the rows and columns needed are transferred.
+//
+// The connection to the remote server is provided by the integrator, so any database/sql driver speaking the MySQL
+// dialect can be used, e.g. github.com/go-sql-driver/mysql.
+type Database struct {
+	name   string
+	remote string
+	conn   *gosql.DB
+}
+
+var _ sql.Database = (*Database)(nil)
+var _ sql.TableCreator = (*Database)(nil)
+var _ sql.TableDropper = (*Database)(nil)
+
+// NewDatabase creates a new database with the given name, proxying the tables of the database named remote in the
+// server of the connection given.
+func NewDatabase(name string, conn *gosql.DB, remote string) *Database {
+	return &Database{name: name, remote: remote, conn: conn}
+}
+
+// Name implements the sql.Database interface.
+func (d *Database) Name() string {
+	return d.name
+}
+
+// GetTableInsensitive implements the sql.Database interface. The schema of the table is read from the remote server
+// every time the table is requested.
+func (d *Database) GetTableInsensitive(ctx *sql.Context, tblName string) (sql.Table, bool, error) {
+	names, err := d.GetTableNames(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	name, ok := sql.GetTableNameInsensitive(tblName, names)
+	if !ok {
+		return nil, false, nil
+	}
+
+	var ignored, createStmt string
+	err = d.conn.QueryRowContext(ctx, fmt.Sprintf("SHOW CREATE TABLE %s", d.qualify(name))).Scan(&ignored, &createStmt)
+	if err != nil {
+		return nil, false, err
+	}
+
+	schema, err := parseSchema(ctx, createStmt)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to read schema of remote table %s: %s", name, err)
+	}
+
+	return newTable(d, name, schema), true, nil
+}
+
+// GetTableNames implements the sql.Database interface.
+func (d *Database) GetTableNames(ctx *sql.Context) ([]string, error) {
+	rows, err := d.conn.QueryContext(ctx, fmt.Sprintf("SHOW TABLES FROM %s", quoteIdentifier(d.remote)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// CreateTable implements the sql.TableCreator interface. The table is created in the remote database.
+func (d *Database) CreateTable(ctx *sql.Context, name string, schema sql.Schema) error {
+	var defs = make([]string, 0, len(schema)+1)
+	var pks []string
+	for _, col := range schema {
+		def := quoteIdentifier(col.Name) + " " + col.Type.String()
+		if !col.Nullable {
+			def += " NOT NULL"
+		}
+		if col.AutoIncrement {
+			def += " AUTO_INCREMENT"
+		}
+		if col.Default != nil {
+			def += " DEFAULT " + col.Default.String()
+		}
+		defs = append(defs, def)
+
+		if col.PrimaryKey {
+			pks = append(pks, quoteIdentifier(col.Name))
+		}
+	}
+
+	if len(pks) > 0 {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(pks, ", ")))
+	}
+
+	_, err := d.conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", d.qualify(name), strings.Join(defs, ", ")))
+	return err
+}
+
+// DropTable implements the sql.TableDropper interface. The table is dropped from the remote database.
+func (d *Database) DropTable(ctx *sql.Context, name string) error {
+	_, err := d.conn.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s", d.qualify(name)))
+	return err
+}
+
+// qualify returns the quoted name of the remote table with the name given.
+func (d *Database) qualify(table string) string {
+	return quoteIdentifier(d.remote) + "." + quoteIdentifier(table)
+}
+
+// parseSchema returns the schema of the table defined by the CREATE TABLE statement given. Column defaults are
+// dropped, since they are only used for inserts, which leave them to the remote server.
+func parseSchema(ctx *sql.Context, createStmt string) (sql.Schema, error) {
+	node, err := parse.Parse(ctx, createStmt)
+	if err != nil {
+		return nil, err
+	}
+
+	ct, ok := node.(*plan.CreateTable)
+	if !ok {
+		return nil, fmt.Errorf("expecting CREATE TABLE statement, got %T", node)
+	}
+
+	var schema = make(sql.Schema, len(ct.Schema()))
+	for i, col := range ct.Schema() {
+		c := *col
+		c.Default = nil
+		schema[i] = &c
+	}
+
+	return schema, nil
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
@@ -0,0 +1,151 @@
+package federated
+
+import (
+	gosql "database/sql"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// remoteServer starts a server playing the role of the remote MySQL server, with a single table, and returns a
+// connection to it.
+func remoteServer(t *testing.T) *gosql.DB {
+	db := memory.NewDatabase("remote")
+	table := memory.NewTable("people", sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "people", PrimaryKey: true},
+		{Name: "name", Type: sql.Text, Source: "people", Nullable: true},
+		{Name: "age", Type: sql.Int32, Source: "people", Nullable: true},
+	})
+	db.AddTable("people", table)
+
+	ctx := sql.NewEmptyContext()
+	for _, row := range []sql.Row{
+		{int64(1), "john", int32(30)},
+		{int64(2), "jane", int32(25)},
+		{int64(3), "o'neil", nil},
+	} {
+		require.NoError(t, table.Insert(ctx, row))
+	}
+
+	e := sqle.NewDefault()
+	e.AddDatabase(db)
+
+	s, err := server.NewDefaultServer(server.Config{
+		Protocol: "tcp",
+		Address:  "localhost:0",
+		Auth:     new(auth.None),
+	}, e)
+	require.NoError(t, err)
+	go s.Start()
+	t.Cleanup(func() { s.Close() })
+
+	conn, err := gosql.Open("mysql", "root:@tcp("+s.Listener.Addr().String()+")/remote")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func query(t *testing.T, e *sqle.Engine, q string) []sql.Row {
+	ctx := sql.NewEmptyContext().WithCurrentDB("fed")
+	_, iter, err := e.Query(ctx, q)
+	require.NoError(t, err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(t, err)
+	return rows
+}
+
+func TestDatabase(t *testing.T) {
+	require := require.New(t)
+	db := NewDatabase("fed", remoteServer(t), "remote")
+	ctx := sql.NewEmptyContext()
+
+	names, err := db.GetTableNames(ctx)
+	require.NoError(err)
+	require.Equal([]string{"people"}, names)
+
+	table, ok, err := db.GetTableInsensitive(ctx, "PEOPLE")
+	require.NoError(err)
+	require.True(ok)
+	require.Equal("people", table.Name())
+	require.Equal([]string{"id", "name", "age"}, columnNames(table.Schema()))
+
+	_, ok, err = db.GetTableInsensitive(ctx, "nope")
+	require.NoError(err)
+	require.False(ok)
+}
+
+func TestTablePushdown(t *testing.T) {
+	require := require.New(t)
+	db := NewDatabase("fed", remoteServer(t), "remote")
+	ctx := sql.NewEmptyContext()
+
+	tbl, _, err := db.GetTableInsensitive(ctx, "people")
+	require.NoError(err)
+	table := tbl.(*Table)
+
+	filters := []sql.Expression{
+		expression.NewGreaterThan(
+			expression.NewGetFieldWithTable(2, sql.Int32, "people", "age", true),
+			expression.NewLiteral(int32(20), sql.Int32),
+		),
+		expression.NewEquals(
+			expression.NewGetFieldWithTable(1, sql.Text, "people", "name", true),
+			expression.NewGetFieldWithTable(0, sql.Text, "other", "name", true),
+		),
+	}
+	require.Equal(filters[:1], table.HandledFilters(filters))
+
+	pushed := table.WithFilters(filters[:1]).(*Table).
+		WithProjection([]string{"name"}).(*Table).
+		WithLimit(1).(*Table)
+	require.Equal("SELECT `name` FROM `remote`.`people` WHERE (`age` > 20) LIMIT 1", pushed.query())
+
+	rows, err := sql.RowIterToRows(mustRowIter(t, pushed))
+	require.NoError(err)
+	require.Equal([]sql.Row{{"john"}}, rows)
+}
+
+func TestQueries(t *testing.T) {
+	require := require.New(t)
+	e := sqle.NewDefault()
+	e.AddDatabase(NewDatabase("fed", remoteServer(t), "remote"))
+
+	require.Equal([]sql.Row{{"jane"}, {"john"}}, query(t, e, "SELECT name FROM people WHERE age IS NOT NULL ORDER BY name"))
+	require.Equal([]sql.Row{{int64(3), "o'neil"}}, query(t, e, "SELECT id, name FROM people WHERE name LIKE 'o''%'"))
+	require.Len(query(t, e, "SELECT * FROM people LIMIT 2"), 2)
+
+	query(t, e, "INSERT INTO people VALUES (4, 'new', 40)")
+	query(t, e, "UPDATE people SET age = 41 WHERE id = 4")
+	require.Equal([]sql.Row{{"new", int32(41)}}, query(t, e, "SELECT name, age FROM people WHERE id = 4"))
+
+	query(t, e, "DELETE FROM people WHERE id = 4")
+	require.Empty(query(t, e, "SELECT name, age FROM people WHERE id = 4"))
+}
+
+func columnNames(schema sql.Schema) []string {
+	var names []string
+	for _, col := range schema {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+func mustRowIter(t *testing.T, table sql.Table) sql.RowIter {
+	ctx := sql.NewEmptyContext()
+	partitions, err := table.Partitions(ctx)
+	require.NoError(t, err)
+	p, err := partitions.Next()
+	require.NoError(t, err)
+	iter, err := table.PartitionRows(ctx, p)
+	require.NoError(t, err)
+	return iter
+}
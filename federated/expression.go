@@ -0,0 +1,121 @@
+package federated
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/shopspring/decimal"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// toSQL returns the SQL text of the expression given for the remote server, or false if the expression can't be
+// translated. Only columns of the table given, literals and a handful of comparison and logical operators are
+// supported, which covers most filters worth pushing down.
+func toSQL(table string, e sql.Expression) (string, bool) {
+	switch e := e.(type) {
+	case *expression.GetField:
+		if !strings.EqualFold(e.Table(), table) {
+			return "", false
+		}
+		return quoteIdentifier(e.Name()), true
+	case *expression.Literal:
+		return literalSQL(e.Value())
+	case *expression.Equals:
+		return binarySQL(table, "=", e.Left(), e.Right())
+	case *expression.GreaterThan:
+		return binarySQL(table, ">", e.Left(), e.Right())
+	case *expression.GreaterThanOrEqual:
+		return binarySQL(table, ">=", e.Left(), e.Right())
+	case *expression.LessThan:
+		return binarySQL(table, "<", e.Left(), e.Right())
+	case *expression.LessThanOrEqual:
+		return binarySQL(table, "<=", e.Left(), e.Right())
+	case *expression.Like:
+		return binarySQL(table, "LIKE", e.Left, e.Right)
+	case *expression.InTuple:
+		return binarySQL(table, "IN", e.Left(), e.Right())
+	case *expression.And:
+		return binarySQL(table, "AND", e.Left, e.Right)
+	case *expression.Or:
+		return binarySQL(table, "OR", e.Left, e.Right)
+	case *expression.Not:
+		child, ok := toSQL(table, e.Child)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("(NOT %s)", child), true
+	case *expression.IsNull:
+		child, ok := toSQL(table, e.Child)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("(%s IS NULL)", child), true
+	case expression.Tuple:
+		var elems = make([]string, len(e))
+		for i, elem := range e {
+			s, ok := toSQL(table, elem)
+			if !ok {
+				return "", false
+			}
+			elems[i] = s
+		}
+		return fmt.Sprintf("(%s)", strings.Join(elems, ", ")), true
+	default:
+		return "", false
+	}
+}
+
+func binarySQL(table, op string, left, right sql.Expression) (string, bool) {
+	l, ok := toSQL(table, left)
+	if !ok {
+		return "", false
+	}
+
+	r, ok := toSQL(table, right)
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf("(%s %s %s)", l, op, r), true
+}
+
+// literalSQL returns the SQL text of the value given, or false if its type isn't supported.
+func literalSQL(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "NULL", true
+	case bool:
+		if v {
+			return "TRUE", true
+		}
+		return "FALSE", true
+	case int8, int16, int32, int64, int, uint8, uint16, uint32, uint64, uint:
+		return fmt.Sprintf("%d", v), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case decimal.Decimal:
+		return v.String(), true
+	case string:
+		return quoteString([]byte(v)), true
+	case []byte:
+		return quoteString(v), true
+	case time.Time:
+		return quoteString([]byte(sql.FormatTime(v, sql.TimestampDatetimeLayout))), true
+	default:
+		return "", false
+	}
+}
+
+func quoteString(s []byte) string {
+	var buf bytes.Buffer
+	sqltypes.MakeTrusted(sqltypes.VarBinary, s).EncodeSQL(&buf)
+	return buf.String()
+}
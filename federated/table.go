@@ -0,0 +1,341 @@
+package federated
+
+import (
+	gosql "database/sql"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Table is a proxy for a table in a remote MySQL server. It has a single partition, which is read with one query on
+// the remote server.
+type Table struct {
+	db     *Database
+	name   string
+	schema sql.Schema
+
+	// Pushed down operations, applied by the remote server.
+	projection []string
+	filters    []sql.Expression
+	where      []string
+	limit      int64
+	hasLimit   bool
+}
+
+var _ sql.Table = (*Table)(nil)
+var _ sql.FilteredTable = (*Table)(nil)
+var _ sql.ProjectedTable = (*Table)(nil)
+var _ sql.LimitedTable = (*Table)(nil)
+var _ sql.InsertableTable = (*Table)(nil)
+var _ sql.UpdatableTable = (*Table)(nil)
+var _ sql.DeletableTable = (*Table)(nil)
+
+func newTable(db *Database, name string, schema sql.Schema) *Table {
+	for _, col := range schema {
+		col.Source = name
+	}
+	return &Table{db: db, name: name, schema: schema}
+}
+
+// Name implements the sql.Table interface.
+func (t *Table) Name() string {
+	return t.name
+}
+
+func (t *Table) String() string {
+	return t.name
+}
+
+// Schema implements the sql.Table interface.
+func (t *Table) Schema() sql.Schema {
+	return t.schema
+}
+
+// Partitions implements the sql.Table interface.
+func (t *Table) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &partitionIter{}, nil
+}
+
+// PartitionRows implements the sql.Table interface.
+func (t *Table) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	rows, err := t.db.conn.QueryContext(ctx, t.query())
+	if err != nil {
+		return nil, err
+	}
+
+	return &tableIter{rows: rows, schema: t.schema}, nil
+}
+
+// query returns the query that reads the rows of the table from the remote server.
+func (t *Table) query() string {
+	var columns = make([]string, len(t.schema))
+	for i, col := range t.schema {
+		columns[i] = quoteIdentifier(col.Name)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), t.db.qualify(t.name))
+	if len(t.where) > 0 {
+		query += " WHERE " + strings.Join(t.where, " AND ")
+	}
+	if t.hasLimit {
+		query += fmt.Sprintf(" LIMIT %d", t.limit)
+	}
+
+	return query
+}
+
+// HandledFilters implements the sql.FilteredTable interface. Filters that can be translated to SQL are handled by
+// the remote server.
+func (t *Table) HandledFilters(filters []sql.Expression) []sql.Expression {
+	var handled []sql.Expression
+	for _, f := range filters {
+		if _, ok := toSQL(t.name, f); ok {
+			handled = append(handled, f)
+		}
+	}
+	return handled
+}
+
+// WithFilters implements the sql.FilteredTable interface.
+func (t *Table) WithFilters(filters []sql.Expression) sql.Table {
+	if len(filters) == 0 {
+		return t
+	}
+
+	nt := *t
+	nt.filters = append(t.filters[:len(t.filters):len(t.filters)], filters...)
+	for _, f := range filters {
+		where, ok := toSQL(t.name, f)
+		if !ok {
+			// The analyzer only gives back the filters returned by HandledFilters.
+			panic(fmt.Sprintf("unable to push down filter %s", f))
+		}
+		nt.where = append(nt.where[:len(nt.where):len(nt.where)], where)
+	}
+
+	return &nt
+}
+
+// Filters implements the sql.FilteredTable interface.
+func (t *Table) Filters() []sql.Expression {
+	return t.filters
+}
+
+// WithProjection implements the sql.ProjectedTable interface. Only the columns projected are requested to the remote
+// server.
+func (t *Table) WithProjection(colNames []string) sql.Table {
+	if len(colNames) == 0 {
+		return t
+	}
+
+	nt := *t
+	nt.schema = make(sql.Schema, len(colNames))
+	for i, name := range colNames {
+		idx := t.schema.IndexOf(name, t.name)
+		if idx < 0 {
+			panic(fmt.Sprintf("could not find column %s", name))
+		}
+		nt.schema[i] = t.schema[idx]
+	}
+	nt.projection = colNames
+
+	return &nt
+}
+
+// Projection implements the sql.ProjectedTable interface.
+func (t *Table) Projection() []string {
+	return t.projection
+}
+
+// WithLimit implements the sql.LimitedTable interface.
+func (t *Table) WithLimit(limit int64) sql.Table {
+	nt := *t
+	nt.limit = limit
+	nt.hasLimit = true
+	return &nt
+}
+
+// Limit implements the sql.LimitedTable interface.
+func (t *Table) Limit() (int64, bool) {
+	return t.limit, t.hasLimit
+}
+
+// Inserter implements the sql.InsertableTable interface. Rows are inserted in the remote table one statement at a
+// time.
+func (t *Table) Inserter(*sql.Context) sql.RowInserter {
+	return &rowEditor{t}
+}
+
+// Updater implements the sql.UpdatableTable interface. Each row is updated with a statement matching all the values
+// of the old row.
+func (t *Table) Updater(*sql.Context) sql.RowUpdater {
+	return &rowEditor{t}
+}
+
+// Deleter implements the sql.DeletableTable interface. Each row is deleted with a statement matching all of its
+// values.
+func (t *Table) Deleter(*sql.Context) sql.RowDeleter {
+	return &rowEditor{t}
+}
+
+type partition struct{}
+
+func (partition) Key() []byte { return nil }
+
+type partitionIter struct {
+	done bool
+}
+
+func (p *partitionIter) Next() (sql.Partition, error) {
+	if p.done {
+		return nil, io.EOF
+	}
+
+	p.done = true
+	return partition{}, nil
+}
+
+func (p *partitionIter) Close() error { return nil }
+
+type tableIter struct {
+	rows   *gosql.Rows
+	schema sql.Schema
+}
+
+func (i *tableIter) Next() (sql.Row, error) {
+	if !i.rows.Next() {
+		if err := i.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	var values = make([]interface{}, len(i.schema))
+	var dest = make([]interface{}, len(i.schema))
+	for j := range values {
+		dest[j] = &values[j]
+	}
+
+	if err := i.rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	var row = make(sql.Row, len(values))
+	for j, v := range values {
+		// Drivers return most values as text, which the column type converts to the right value.
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+
+		var err error
+		row[j], err = i.schema[j].Type.Convert(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return row, nil
+}
+
+func (i *tableIter) Close() error {
+	return i.rows.Close()
+}
+
+// rowEditor inserts, updates and deletes rows of a remote table.
+type rowEditor struct {
+	t *Table
+}
+
+// Insert implements the sql.RowInserter interface.
+func (e *rowEditor) Insert(ctx *sql.Context, row sql.Row) error {
+	var columns = make([]string, len(e.t.schema))
+	var values = make([]string, len(e.t.schema))
+	for i, col := range e.t.schema {
+		columns[i] = quoteIdentifier(col.Name)
+
+		var ok bool
+		values[i], ok = literalSQL(row[i])
+		if !ok {
+			return fmt.Errorf("unable to insert value of type %T in remote table %s", row[i], e.t.name)
+		}
+	}
+
+	return e.exec(ctx, fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s)",
+		e.t.db.qualify(e.t.name),
+		strings.Join(columns, ", "),
+		strings.Join(values, ", "),
+	))
+}
+
+// Update implements the sql.RowUpdater interface.
+func (e *rowEditor) Update(ctx *sql.Context, old, new sql.Row) error {
+	where, err := e.matchRow(old)
+	if err != nil {
+		return err
+	}
+
+	var set = make([]string, len(e.t.schema))
+	for i, col := range e.t.schema {
+		value, ok := literalSQL(new[i])
+		if !ok {
+			return fmt.Errorf("unable to update value of type %T in remote table %s", new[i], e.t.name)
+		}
+		set[i] = fmt.Sprintf("%s = %s", quoteIdentifier(col.Name), value)
+	}
+
+	return e.exec(ctx, fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s LIMIT 1",
+		e.t.db.qualify(e.t.name),
+		strings.Join(set, ", "),
+		where,
+	))
+}
+
+// Delete implements the sql.RowDeleter interface.
+func (e *rowEditor) Delete(ctx *sql.Context, row sql.Row) error {
+	where, err := e.matchRow(row)
+	if err != nil {
+		return err
+	}
+
+	res, err := e.t.db.conn.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s LIMIT 1", e.t.db.qualify(e.t.name), where))
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrDeleteRowNotFound.New()
+	}
+
+	return nil
+}
+
+// Close implements the sql.Closer interface. Statements are executed as they come, so there's nothing left to do.
+func (e *rowEditor) Close(*sql.Context) error {
+	return nil
+}
+
+// matchRow returns a WHERE condition matching the row given by all of its values.
+func (e *rowEditor) matchRow(row sql.Row) (string, error) {
+	var conds = make([]string, len(e.t.schema))
+	for i, col := range e.t.schema {
+		value, ok := literalSQL(row[i])
+		if !ok {
+			return "", fmt.Errorf("unable to match value of type %T in remote table %s", row[i], e.t.name)
+		}
+		if row[i] == nil {
+			conds[i] = fmt.Sprintf("%s IS NULL", quoteIdentifier(col.Name))
+		} else {
+			conds[i] = fmt.Sprintf("%s = %s", quoteIdentifier(col.Name), value)
+		}
+	}
+	return strings.Join(conds, " AND "), nil
+}
+
+func (e *rowEditor) exec(ctx *sql.Context, stmt string) error {
+	_, err := e.t.db.conn.ExecContext(ctx, stmt)
+	return err
+}
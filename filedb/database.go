@@ -0,0 +1,151 @@
+package filedb
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Database is a read-only database exposing the data files in a directory as tables. A file with a supported
+// extension is exposed as a table named after the file, without its extension. A sub-directory containing only files
+// of the same format is exposed as a single table named after the directory, with one partition per file, so that
+// its files can be read in parallel. The directory is listed again every time a table is requested, so files added or
+// removed are picked up by the next query.
+type Database struct {
+	name string
+	dir  string
+}
+
+var _ sql.Database = (*Database)(nil)
+
+// NewDatabase creates a new database with the given name exposing the files in the directory given.
+func NewDatabase(name, dir string) *Database {
+	return &Database{name: name, dir: dir}
+}
+
+// Name implements the sql.Database interface.
+func (d *Database) Name() string {
+	return d.name
+}
+
+// GetTableInsensitive implements the sql.Database interface.
+func (d *Database) GetTableInsensitive(ctx *sql.Context, tblName string) (sql.Table, bool, error) {
+	sources, err := d.sources()
+	if err != nil {
+		return nil, false, err
+	}
+
+	name, ok := sql.GetTableNameInsensitive(tblName, sortedNames(sources))
+	if !ok {
+		return nil, false, nil
+	}
+
+	src := sources[name]
+	table, err := newTable(name, src.format, src.files)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return table, true, nil
+}
+
+// GetTableNames implements the sql.Database interface.
+func (d *Database) GetTableNames(ctx *sql.Context) ([]string, error) {
+	sources, err := d.sources()
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedNames(sources), nil
+}
+
+// source is the set of files backing a table.
+type source struct {
+	format format
+	files  []string
+}
+
+// sources returns the tables of the database, keyed by name. Files and directories that can't be exposed as tables
+// are ignored.
+func (d *Database) sources() (map[string]source, error) {
+	entries, err := ioutil.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources = make(map[string]source)
+	for _, entry := range entries {
+		path := filepath.Join(d.dir, entry.Name())
+		if entry.IsDir() {
+			src, ok, err := directorySource(path)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				sources[entry.Name()] = src
+			}
+			continue
+		}
+
+		name, f, ok := fileFormat(entry.Name())
+		if !ok {
+			continue
+		}
+
+		// A directory takes precedence over a file with the same name.
+		if _, ok := sources[name]; !ok {
+			sources[name] = source{f, []string{path}}
+		}
+	}
+
+	return sources, nil
+}
+
+// directorySource returns the source for a directory, which is only valid if all the files in it have the same
+// format. Nested directories are ignored.
+func directorySource(dir string) (source, bool, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return source{}, false, err
+	}
+
+	var src source
+	for _, entry := range entries {
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+
+		_, f, ok := fileFormat(entry.Name())
+		if !ok || (src.format != nil && src.format != f) {
+			return source{}, false, nil
+		}
+
+		src.format = f
+		src.files = append(src.files, filepath.Join(dir, entry.Name()))
+	}
+
+	return src, len(src.files) > 0, nil
+}
+
+// fileFormat returns the table name and format of the file with the name given, if its extension is supported.
+func fileFormat(fileName string) (string, format, bool) {
+	ext := filepath.Ext(fileName)
+	f, ok := formats[strings.ToLower(ext)]
+	if !ok {
+		return "", nil, false
+	}
+
+	return strings.TrimSuffix(fileName, ext), f, true
+}
+
+func sortedNames(sources map[string]source) []string {
+	var names = make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
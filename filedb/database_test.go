@@ -0,0 +1,158 @@
+package filedb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "filedb")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	}
+
+	return dir
+}
+
+func tableRows(t *testing.T, table sql.Table) []sql.Row {
+	ctx := sql.NewEmptyContext()
+	rows, err := sql.NodeToRows(ctx, plan.NewResolvedTable(table))
+	require.NoError(t, err)
+	return rows
+}
+
+func TestDatabase_GetTableNames(t *testing.T) {
+	require := require.New(t)
+	dir := writeFiles(t, map[string]string{
+		"people.csv":           "id,name\n1,a\n",
+		"events.jsonl":         "{\"id\": 1}\n",
+		"readme.txt":           "not a table",
+		"logs/2020-01.ndjson":  "{\"id\": 1}\n",
+		"logs/2020-02.ndjson":  "{\"id\": 2}\n",
+		"mixed/a.csv":          "id\n1\n",
+		"mixed/b.jsonl":        "{\"id\": 1}\n",
+		"nested/inner/a.csv":   "id\n1\n",
+		"nested/inner/b.jsonl": "{\"id\": 1}\n",
+	})
+
+	db := NewDatabase("files", dir)
+	require.Equal("files", db.Name())
+
+	names, err := db.GetTableNames(sql.NewEmptyContext())
+	require.NoError(err)
+	require.Equal([]string{"events", "logs", "people"}, names)
+
+	table, ok, err := db.GetTableInsensitive(sql.NewEmptyContext(), "PEOPLE")
+	require.NoError(err)
+	require.True(ok)
+	require.Equal("people", table.Name())
+
+	_, ok, err = db.GetTableInsensitive(sql.NewEmptyContext(), "readme")
+	require.NoError(err)
+	require.False(ok)
+}
+
+func TestTable_CSV(t *testing.T) {
+	require := require.New(t)
+	dir := writeFiles(t, map[string]string{
+		"people.csv": "id,name,score,zip\n1,john,1.5,01234\n2,\"doe, jane\",2,\n3,,,12345\n",
+	})
+
+	table, ok, err := NewDatabase("files", dir).GetTableInsensitive(sql.NewEmptyContext(), "people")
+	require.NoError(err)
+	require.True(ok)
+
+	require.Equal(sql.Schema{
+		{Name: "id", Type: sql.Int64, Nullable: true, Source: "people"},
+		{Name: "name", Type: sql.LongText, Nullable: true, Source: "people"},
+		{Name: "score", Type: sql.Float64, Nullable: true, Source: "people"},
+		{Name: "zip", Type: sql.Int64, Nullable: true, Source: "people"},
+	}, table.Schema())
+
+	require.Equal([]sql.Row{
+		{int64(1), "john", float64(1.5), int64(1234)},
+		{int64(2), "doe, jane", float64(2), nil},
+		{int64(3), nil, nil, int64(12345)},
+	}, tableRows(t, table))
+
+	projected := table.(sql.ProjectedTable).WithProjection([]string{"name", "id"})
+	require.Equal([]string{"name", "id"}, projected.(sql.ProjectedTable).Projection())
+	require.Equal([]sql.Row{
+		{"john", int64(1)},
+		{"doe, jane", int64(2)},
+		{nil, int64(3)},
+	}, tableRows(t, projected))
+}
+
+func TestTable_JSONLines(t *testing.T) {
+	require := require.New(t)
+	dir := writeFiles(t, map[string]string{
+		"events/a.jsonl": "{\"id\": 1, \"ok\": true, \"tags\": [\"x\"]}\n\n{\"id\": 2, \"ok\": false, \"user\": \"1\"}\n",
+		"events/b.jsonl": "{\"id\": 3.5, \"ok\": null, \"extra\": 1}\n",
+	})
+
+	table, ok, err := NewDatabase("files", dir).GetTableInsensitive(sql.NewEmptyContext(), "events")
+	require.NoError(err)
+	require.True(ok)
+
+	require.Equal(sql.Schema{
+		{Name: "id", Type: sql.Int64, Nullable: true, Source: "events"},
+		{Name: "ok", Type: sql.Boolean, Nullable: true, Source: "events"},
+		{Name: "tags", Type: sql.JSON, Nullable: true, Source: "events"},
+		{Name: "user", Type: sql.LongText, Nullable: true, Source: "events"},
+	}, table.Schema())
+
+	iter, err := table.Partitions(sql.NewEmptyContext())
+	require.NoError(err)
+	var partitions int
+	for {
+		if _, err := iter.Next(); err != nil {
+			break
+		}
+		partitions++
+	}
+	require.Equal(2, partitions)
+
+	// The second file doesn't match the schema inferred from the first one.
+	_, err = sql.NodeToRows(sql.NewEmptyContext(), plan.NewResolvedTable(table))
+	require.Error(err)
+
+	projected := table.(sql.ProjectedTable).WithProjection([]string{"ok", "user"})
+	require.Equal([]sql.Row{
+		{int8(1), nil},
+		{int8(0), "1"},
+		{nil, nil},
+	}, tableRows(t, projected))
+}
+
+func TestQueryFiles(t *testing.T) {
+	require := require.New(t)
+	dir := writeFiles(t, map[string]string{
+		"people.csv":     "id,name\n1,john\n2,jane\n",
+		"visits/a.jsonl": "{\"person\": 1, \"page\": \"home\"}\n{\"person\": 2, \"page\": \"home\"}\n",
+		"visits/b.jsonl": "{\"person\": 1, \"page\": \"about\"}\n",
+	})
+
+	e := sqle.NewDefault()
+	e.AddDatabase(NewDatabase("files", dir))
+	ctx := sql.NewEmptyContext().WithCurrentDB("files")
+
+	_, iter, err := e.Query(ctx, "SELECT p.name, COUNT(*) FROM people p JOIN visits v ON p.id = v.person GROUP BY p.name ORDER BY p.name")
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{{"jane", int64(1)}, {"john", int64(2)}}, rows)
+}
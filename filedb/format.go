@@ -0,0 +1,165 @@
+package filedb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// format is a file format that can be exposed as a table.
+type format interface {
+	// newReader returns a reader for the records in the data given.
+	newReader(r io.Reader) (recordReader, error)
+	// untyped returns whether values of the format are all read as strings, in which case their types are inferred
+	// from their contents.
+	untyped() bool
+}
+
+// formats are the supported file formats, by file extension.
+var formats = map[string]format{
+	".csv":    csvFormat{},
+	".jsonl":  jsonLinesFormat{},
+	".ndjson": jsonLinesFormat{},
+}
+
+// record is a single record of a data file, with the names of its fields in the order they appear in the file.
+type record struct {
+	names  []string
+	values []interface{}
+}
+
+// value returns the value of the field with the name given, or nil if the record doesn't have it.
+func (r record) value(name string) interface{} {
+	for i, n := range r.names {
+		if n == name {
+			return r.values[i]
+		}
+	}
+	return nil
+}
+
+// recordReader reads the records of a data file.
+type recordReader interface {
+	// Next returns the next record, or io.EOF once there are no more.
+	Next() (record, error)
+}
+
+// csvFormat is the format of CSV files with a header row naming their columns. Empty fields are read as NULL.
+type csvFormat struct{}
+
+func (csvFormat) untyped() bool { return true }
+
+func (csvFormat) newReader(r io.Reader) (recordReader, error) {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return &csvReader{r: cr}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &csvReader{r: cr, header: header}, nil
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+func (r *csvReader) Next() (record, error) {
+	if r.header == nil {
+		return record{}, io.EOF
+	}
+
+	fields, err := r.r.Read()
+	if err != nil {
+		return record{}, err
+	}
+
+	var values = make([]interface{}, len(r.header))
+	for i := range r.header {
+		if i < len(fields) && fields[i] != "" {
+			values[i] = fields[i]
+		}
+	}
+
+	return record{r.header, values}, nil
+}
+
+// jsonLinesFormat is the format of files with a JSON object per line. Columns are the keys of the objects, in the
+// order they first appear. Blank lines are skipped.
+type jsonLinesFormat struct{}
+
+func (jsonLinesFormat) untyped() bool { return false }
+
+func (jsonLinesFormat) newReader(r io.Reader) (recordReader, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 64*1024*1024)
+	return &jsonLinesReader{s: s}, nil
+}
+
+type jsonLinesReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func (r *jsonLinesReader) Next() (record, error) {
+	for r.s.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		rec, err := decodeObject(line)
+		if err != nil {
+			return record{}, fmt.Errorf("line %d: %s", r.line, err)
+		}
+		return rec, nil
+	}
+
+	if err := r.s.Err(); err != nil {
+		return record{}, err
+	}
+
+	return record{}, io.EOF
+}
+
+// decodeObject decodes the JSON object given, keeping the order of its keys. Numbers are decoded as json.Number so
+// that integers don't lose precision.
+func decodeObject(data []byte) (record, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return record{}, err
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return record{}, fmt.Errorf("expecting a JSON object")
+	}
+
+	var rec record
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return record{}, err
+		}
+
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return record{}, err
+		}
+
+		rec.names = append(rec.names, tok.(string))
+		rec.values = append(rec.values, v)
+	}
+
+	return rec, nil
+}
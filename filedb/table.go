@@ -0,0 +1,312 @@
+package filedb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// inferenceSampleSize is the number of records of the first file of a table used to infer its schema.
+const inferenceSampleSize = 100
+
+var errColumnNotFound = errors.NewKind("could not find column %s")
+
+// Table is a read-only table backed by one or more data files of the same format, with one partition per file. Its
+// schema is inferred from the first records of its first file. All columns are nullable.
+type Table struct {
+	name   string
+	format format
+	files  []string
+	schema sql.Schema
+	// columns are the names of the columns read from the files, which is a subset of the inferred columns when a
+	// projection is set.
+	columns    []string
+	projection []string
+}
+
+var _ sql.Table = (*Table)(nil)
+var _ sql.ProjectedTable = (*Table)(nil)
+
+func newTable(name string, f format, files []string) (*Table, error) {
+	schema, err := inferSchema(name, f, files[0])
+	if err != nil {
+		return nil, fmt.Errorf("unable to infer schema of table %s: %s", name, err)
+	}
+
+	var columns = make([]string, len(schema))
+	for i, col := range schema {
+		columns[i] = col.Name
+	}
+
+	return &Table{
+		name:    name,
+		format:  f,
+		files:   files,
+		schema:  schema,
+		columns: columns,
+	}, nil
+}
+
+// Name implements the sql.Table interface.
+func (t *Table) Name() string {
+	return t.name
+}
+
+func (t *Table) String() string {
+	return t.name
+}
+
+// Schema implements the sql.Table interface.
+func (t *Table) Schema() sql.Schema {
+	return t.schema
+}
+
+// Partitions implements the sql.Table interface.
+func (t *Table) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &partitionIter{files: t.files}, nil
+}
+
+// PartitionRows implements the sql.Table interface.
+func (t *Table) PartitionRows(ctx *sql.Context, partition sql.Partition) (sql.RowIter, error) {
+	f, err := os.Open(string(partition.Key()))
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := t.format.newReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &tableIter{file: f, r: r, columns: t.columns, schema: t.schema}, nil
+}
+
+// WithProjection implements the sql.ProjectedTable interface. Only the columns projected are converted when reading
+// rows.
+func (t *Table) WithProjection(colNames []string) sql.Table {
+	if len(colNames) == 0 {
+		return t
+	}
+
+	nt := *t
+	nt.schema = make(sql.Schema, len(colNames))
+	nt.columns = make([]string, len(colNames))
+	for i, name := range colNames {
+		idx := t.schema.IndexOf(name, t.name)
+		if idx < 0 {
+			panic(errColumnNotFound.New(name))
+		}
+		nt.schema[i] = t.schema[idx]
+		nt.columns[i] = t.columns[idx]
+	}
+	nt.projection = colNames
+
+	return &nt
+}
+
+// Projection implements the sql.ProjectedTable interface.
+func (t *Table) Projection() []string {
+	return t.projection
+}
+
+type partition struct {
+	key []byte
+}
+
+func (p *partition) Key() []byte { return p.key }
+
+type partitionIter struct {
+	files []string
+	pos   int
+}
+
+func (p *partitionIter) Next() (sql.Partition, error) {
+	if p.pos >= len(p.files) {
+		return nil, io.EOF
+	}
+
+	file := p.files[p.pos]
+	p.pos++
+	return &partition{[]byte(file)}, nil
+}
+
+func (p *partitionIter) Close() error { return nil }
+
+type tableIter struct {
+	file    *os.File
+	r       recordReader
+	columns []string
+	schema  sql.Schema
+}
+
+func (i *tableIter) Next() (sql.Row, error) {
+	rec, err := i.r.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	var row = make(sql.Row, len(i.columns))
+	for j, name := range i.columns {
+		row[j], err = convertValue(i.schema[j].Type, rec.value(name))
+		if err != nil {
+			return nil, fmt.Errorf("%s: column %s: %s", i.file.Name(), name, err)
+		}
+	}
+
+	return row, nil
+}
+
+func (i *tableIter) Close() error {
+	return i.file.Close()
+}
+
+// valueKind is the kind of a value read from a file, used to infer column types. A column holding ints and floats is
+// inferred as float, and one holding any other mix of kinds as text.
+type valueKind int
+
+const (
+	nullKind valueKind = iota
+	boolKind
+	intKind
+	floatKind
+	jsonKind
+	textKind
+)
+
+func (k valueKind) widen(other valueKind) valueKind {
+	switch {
+	case k == other || other == nullKind:
+		return k
+	case k == nullKind:
+		return other
+	case (k == intKind && other == floatKind) || (k == floatKind && other == intKind):
+		return floatKind
+	default:
+		return textKind
+	}
+}
+
+func (k valueKind) sqlType() sql.Type {
+	switch k {
+	case boolKind:
+		return sql.Boolean
+	case intKind:
+		return sql.Int64
+	case floatKind:
+		return sql.Float64
+	case jsonKind:
+		return sql.JSON
+	default:
+		return sql.LongText
+	}
+}
+
+func kindOf(v interface{}, untyped bool) valueKind {
+	switch v := v.(type) {
+	case nil:
+		return nullKind
+	case bool:
+		return boolKind
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return intKind
+		}
+		return floatKind
+	case string:
+		if !untyped {
+			return textKind
+		}
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return intKind
+		}
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return floatKind
+		}
+		return textKind
+	default:
+		return jsonKind
+	}
+}
+
+// inferSchema returns the schema of the file given from its first records. Columns are in the order they first
+// appear.
+func inferSchema(table string, f format, file string) (sql.Schema, error) {
+	fd, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	r, err := f.newReader(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	var kinds = make(map[string]valueKind)
+	for i := 0; i < inferenceSampleSize; i++ {
+		rec, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for j, name := range rec.names {
+			kind, ok := kinds[name]
+			if !ok {
+				names = append(names, name)
+			}
+			kinds[name] = kind.widen(kindOf(rec.values[j], f.untyped()))
+		}
+	}
+
+	var schema = make(sql.Schema, len(names))
+	for i, name := range names {
+		schema[i] = &sql.Column{
+			Name:     name,
+			Type:     kinds[name].sqlType(),
+			Nullable: true,
+			Source:   table,
+		}
+	}
+
+	return schema, nil
+}
+
+// convertValue converts a value read from a file to the type of its column.
+func convertValue(typ sql.Type, v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case json.Number:
+		v = val.String()
+	case bool:
+		if typ != sql.Boolean {
+			v = strconv.FormatBool(val)
+		}
+	case map[string]interface{}, []interface{}:
+		if typ != sql.JSON {
+			b, err := json.Marshal(val)
+			if err != nil {
+				return nil, err
+			}
+			v = string(b)
+		}
+	}
+
+	// Integers are parsed here since sql.Int64 would read numbers with leading zeros as octal.
+	if s, ok := v.(string); ok && typ == sql.Int64 {
+		return strconv.ParseInt(s, 10, 64)
+	}
+
+	return typ.Convert(v)
+}
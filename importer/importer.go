@@ -0,0 +1,311 @@
+// Package importer loads SQL dumps, such as the ones mysqldump and
+// Engine.Dump write, into an engine faster than running their statements one
+// by one: consecutive INSERT statements into the same table are merged into
+// batches, the foreign key and unique checks of the session are disabled
+// while the dump is loaded, and the tables implementing sql.BulkLoadTable
+// defer maintaining their indexes and checking their constraints until all
+// their rows have been inserted.
+package importer
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// DefaultBatchSize is the size in bytes past which INSERT statements stop
+// being merged when Options.BatchSize is zero.
+const DefaultBatchSize = 4 << 20
+
+// checkVariables are the session variables disabled while importing, like
+// mysqldump does at the start of its dumps.
+var checkVariables = []string{"foreign_key_checks", "unique_checks"}
+
+// insertRegex matches the INSERT statements that can be merged, with the
+// part before the rows of their VALUES clause, the database and the name of
+// their table, and their rows.
+var insertRegex = regexp.MustCompile("(?is)^(insert\\s+into\\s+(?:(`[^`]+`|\\w+)\\.)?(`[^`]+`|\\w+)(?:\\s*\\([^)]*\\))?\\s*values)\\s*(\\(.*\\))$")
+
+// createDatabaseRegex matches the CREATE DATABASE IF NOT EXISTS statements,
+// with the name of their database.
+var createDatabaseRegex = regexp.MustCompile("(?is)^create\\s+(?:database|schema)\\s+if\\s+not\\s+exists\\s+(`[^`]+`|\\w+)$")
+
+// Options configures an import.
+type Options struct {
+	// BatchSize is the size in bytes past which consecutive INSERT
+	// statements into the same table stop being merged into one.
+	// DefaultBatchSize is used if it's zero, and negative sizes disable
+	// merging.
+	BatchSize int
+	// Progress, if set, is called with the progress of the import after
+	// every statement run.
+	Progress func(Progress)
+}
+
+// Progress is the progress of an import.
+type Progress struct {
+	// Bytes is the number of bytes of the dump read.
+	Bytes uint64
+	// Statements is the number of statements run, counting every batch of
+	// INSERT statements merged as one.
+	Statements uint64
+	// Rows is the number of rows the statements run changed.
+	Rows uint64
+	// Elapsed is the time since the import started.
+	Elapsed time.Duration
+}
+
+// ImportFile imports the dump in the file at the path given, as Import does.
+func ImportFile(ctx *sql.Context, e *sqle.Engine, path string, opts Options) (Progress, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Progress{}, err
+	}
+	defer f.Close()
+
+	return Import(ctx, e, f, opts)
+}
+
+// Import runs the statements of the dump read from r in the engine given, in
+// the session of the context given, and returns the progress it made, which
+// is the complete import unless it fails.
+func Import(ctx *sql.Context, e *sqle.Engine, r io.Reader, opts Options) (p Progress, err error) {
+	if opts.BatchSize == 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	i := &importer{
+		ctx:     ctx,
+		engine:  e,
+		scanner: newScanner(r),
+		opts:    opts,
+		start:   time.Now(),
+		loading: make(map[string]bool),
+	}
+
+	restore, err := i.disableChecks()
+	if err != nil {
+		return i.progress, err
+	}
+	defer func() {
+		if rerr := restore(); err == nil {
+			err = rerr
+		}
+	}()
+
+	if err = i.run(); err != nil {
+		// The loads are finished to leave the tables usable, but their
+		// errors are less relevant than the one of the import.
+		_ = i.finishLoads()
+	}
+	return i.progress, err
+}
+
+// importer runs the statements of a dump.
+type importer struct {
+	ctx      *sql.Context
+	engine   *sqle.Engine
+	scanner  *scanner
+	opts     Options
+	start    time.Time
+	progress Progress
+
+	// prefix and batch are the INSERT statement whose rows are being batched
+	// and its rows, if batch isn't empty.
+	prefix string
+	batch  strings.Builder
+	// loads are the tables bulk loading their rows, in the order their loads
+	// started, and loading has their qualified lowercase names.
+	loads   []sql.BulkLoadTable
+	loading map[string]bool
+}
+
+func (i *importer) run() error {
+	for {
+		stmt, err := i.scanner.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if m := insertRegex.FindStringSubmatch(stmt); m != nil && i.opts.BatchSize > 0 &&
+			!strings.Contains(strings.ToLower(m[4]), "on duplicate key update") {
+			if err := i.insert(m[1], m[2], m[3], m[4]); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// The engine can't create databases, but the statements creating the
+		// ones that exist already do nothing.
+		if m := createDatabaseRegex.FindStringSubmatch(stmt); m != nil && i.engine.Catalog.HasDB(strings.Trim(m[1], "`")) {
+			continue
+		}
+
+		if err := i.flush(); err != nil {
+			return err
+		}
+		// The statements that aren't inserts may drop or change the tables
+		// loaded, so that their loads must be finished first.
+		if err := i.finishLoads(); err != nil {
+			return err
+		}
+		if err := i.exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	if err := i.flush(); err != nil {
+		return err
+	}
+	return i.finishLoads()
+}
+
+// insert adds the rows of an INSERT statement to the batch, running the
+// batch first if the statement inserts into another table or doesn't fit.
+func (i *importer) insert(prefix, db, table, rows string) error {
+	if i.batch.Len() > 0 && prefix == i.prefix && i.batch.Len()+len(rows)+1 <= i.opts.BatchSize {
+		i.batch.WriteByte(',')
+		i.batch.WriteString(rows)
+		return nil
+	}
+
+	if err := i.flush(); err != nil {
+		return err
+	}
+	if err := i.startLoad(db, table); err != nil {
+		return err
+	}
+
+	i.prefix = prefix
+	i.batch.WriteString(rows)
+	return nil
+}
+
+// flush runs the INSERT statement of the rows batched, if there are any.
+func (i *importer) flush() error {
+	if i.batch.Len() == 0 {
+		return nil
+	}
+
+	stmt := i.prefix + " " + i.batch.String()
+	i.batch.Reset()
+	return i.exec(stmt)
+}
+
+// startLoad starts the bulk load of a table, named as in an INSERT
+// statement, if it's a sql.BulkLoadTable and isn't loading already.
+func (i *importer) startLoad(db, name string) error {
+	db, name = strings.Trim(db, "`"), strings.Trim(name, "`")
+	if db == "" {
+		db = i.ctx.GetCurrentDatabase()
+	}
+
+	key := strings.ToLower(db + "." + name)
+	if i.loading[key] {
+		return nil
+	}
+
+	t, err := i.engine.Catalog.Table(i.ctx, db, name)
+	if err != nil {
+		// The INSERT statement fails with the error.
+		return nil
+	}
+
+	bt, ok := t.(sql.BulkLoadTable)
+	if !ok {
+		return nil
+	}
+
+	if err := bt.StartBulkLoad(i.ctx); err != nil {
+		return err
+	}
+	i.loads = append(i.loads, bt)
+	i.loading[key] = true
+	return nil
+}
+
+// finishLoads finishes the bulk loads of the tables loading.
+func (i *importer) finishLoads() error {
+	loads := i.loads
+	i.loads = nil
+	i.loading = make(map[string]bool)
+
+	var err error
+	for _, t := range loads {
+		if lerr := t.FinishBulkLoad(i.ctx); lerr != nil && err == nil {
+			err = lerr
+		}
+	}
+	return err
+}
+
+// exec runs a statement, reading all its rows.
+func (i *importer) exec(stmt string) error {
+	_, iter, err := i.engine.Query(i.ctx, stmt)
+	if err != nil {
+		return err
+	}
+
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			iter.Close()
+			return err
+		}
+
+		if len(row) == 1 {
+			if ok, isOk := row[0].(sql.OkResult); isOk {
+				i.progress.Rows += ok.RowsAffected
+			}
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return err
+	}
+
+	i.progress.Bytes = i.scanner.read
+	i.progress.Statements++
+	i.progress.Elapsed = time.Since(i.start)
+	if i.opts.Progress != nil {
+		i.opts.Progress(i.progress)
+	}
+	return nil
+}
+
+// disableChecks disables the checks of the session while importing, and
+// returns the function restoring them.
+func (i *importer) disableChecks() (func() error, error) {
+	values := make(map[string]interface{}, len(checkVariables))
+	for _, name := range checkVariables {
+		typ, v := i.ctx.Get(name)
+		if typ == nil || typ == sql.Null {
+			continue
+		}
+
+		if err := i.ctx.Set(i.ctx, name, typ, int8(0)); err != nil {
+			return nil, err
+		}
+		values[name] = v
+	}
+
+	return func() error {
+		for name, v := range values {
+			typ, _ := i.ctx.Get(name)
+			if err := i.ctx.Set(i.ctx, name, typ, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,167 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/importer"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+)
+
+func newEngine(dbs ...sql.Database) *sqle.Engine {
+	catalog := sql.NewCatalog()
+	for _, db := range dbs {
+		catalog.AddDatabase(db)
+	}
+	return sqle.New(catalog, analyzer.NewBuilder(catalog).Build(), nil)
+}
+
+func query(t *testing.T, e *sqle.Engine, ctx *sql.Context, q string) []sql.Row {
+	_, iter, err := e.Query(ctx, q)
+	require.NoError(t, err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(t, err)
+	return rows
+}
+
+func TestImport(t *testing.T) {
+	require := require.New(t)
+	e := newEngine(memory.NewDatabase("mydb"))
+	ctx := sql.NewEmptyContext()
+	ctx.SetCurrentDatabase("mydb")
+
+	dump := "CREATE TABLE t (a int PRIMARY KEY, b varchar(10));\n" +
+		"INSERT INTO t VALUES (1, 'one'), (2, 'two');\n" +
+		"INSERT INTO t VALUES (3, 'three');\n" +
+		"INSERT INTO t (a) VALUES (4);\n" +
+		"INSERT INTO t VALUES (5, 'five') ON DUPLICATE KEY UPDATE b = 'dup';\n" +
+		"INSERT INTO t VALUES (6, 'six');\n"
+
+	var updates []importer.Progress
+	p, err := importer.Import(ctx, e, strings.NewReader(dump), importer.Options{
+		Progress: func(p importer.Progress) {
+			updates = append(updates, p)
+		},
+	})
+	require.NoError(err)
+
+	require.Equal(uint64(len(dump)), p.Bytes)
+	require.Equal(uint64(5), p.Statements)
+	require.Equal(uint64(6), p.Rows)
+	require.Len(updates, 5)
+	require.Equal(p, updates[4])
+
+	require.Equal([]sql.Row{
+		{int32(1), "one"},
+		{int32(2), "two"},
+		{int32(3), "three"},
+		{int32(4), nil},
+		{int32(5), "five"},
+		{int32(6), "six"},
+	}, query(t, e, ctx, "SELECT * FROM t ORDER BY a"))
+
+	require.Equal([]sql.Row{{int8(1), int8(1)}}, query(t, e, ctx, "SELECT @@foreign_key_checks, @@unique_checks"))
+}
+
+func TestImportBatchSize(t *testing.T) {
+	require := require.New(t)
+	e := newEngine(memory.NewDatabase("mydb"))
+	ctx := sql.NewEmptyContext()
+	ctx.SetCurrentDatabase("mydb")
+
+	dump := "CREATE TABLE t (a int PRIMARY KEY);\n" +
+		"INSERT INTO t VALUES (1);\nINSERT INTO t VALUES (2);\nINSERT INTO t VALUES (3);\n"
+
+	p, err := importer.Import(ctx, e, strings.NewReader(dump), importer.Options{BatchSize: 8})
+	require.NoError(err)
+	require.Equal(uint64(3), p.Statements)
+
+	_, err = importer.Import(ctx, e, strings.NewReader("INSERT INTO t VALUES (4);\nINSERT INTO t VALUES (1);\n"), importer.Options{BatchSize: -1})
+	require.Error(err)
+	require.Equal([]sql.Row{{int64(4)}}, query(t, e, ctx, "SELECT COUNT(*) FROM t"))
+}
+
+func TestImportDump(t *testing.T) {
+	require := require.New(t)
+	src := newEngine(memory.NewDatabase("mydb"))
+	ctx := sql.NewEmptyContext()
+	ctx.SetCurrentDatabase("mydb")
+
+	query(t, src, ctx, "CREATE TABLE t (a int PRIMARY KEY, b text, c double)")
+	query(t, src, ctx, "INSERT INTO t VALUES (1, 'it''s', 1.5), (2, 'back\\\\slash;', NULL), (3, NULL, -2)")
+	query(t, src, ctx, "CREATE TABLE u (a int PRIMARY KEY)")
+	query(t, src, ctx, "CREATE TRIGGER trig BEFORE INSERT ON u FOR EACH ROW SET new.a = new.a * 2")
+	query(t, src, ctx, "CREATE TABLE v (a int PRIMARY KEY, b int)")
+	query(t, src, ctx, "CREATE INDEX idx_fn ON v ((b * 2))")
+	query(t, src, ctx, "INSERT INTO v VALUES (1, 2), (2, 3)")
+
+	var dump strings.Builder
+	require.NoError(src.Dump(ctx, &dump))
+	require.Contains(dump.String(), "CREATE INDEX `idx_fn` ON `v` ((`b` * 2));\n")
+
+	dst := newEngine(memory.NewDatabase("mydb"))
+	dstCtx := sql.NewEmptyContext()
+	_, err := importer.Import(dstCtx, dst, strings.NewReader(dump.String()), importer.Options{})
+	require.NoError(err)
+
+	var restored strings.Builder
+	require.NoError(dst.Dump(dstCtx, &restored))
+	require.Equal(dump.String(), restored.String())
+
+	query(t, dst, dstCtx, "INSERT INTO u VALUES (2)")
+	require.Equal([]sql.Row{{int32(4)}}, query(t, dst, dstCtx, "SELECT * FROM u"))
+	require.Equal([]sql.Row{{int32(2)}}, query(t, dst, dstCtx, "SELECT a FROM v WHERE b * 2 = 6"))
+}
+
+// bulkLoadDatabase is a database whose tables are bulkLoadTables.
+type bulkLoadDatabase struct {
+	*memory.Database
+	loads []string
+}
+
+func (d *bulkLoadDatabase) GetTableInsensitive(ctx *sql.Context, name string) (sql.Table, bool, error) {
+	t, ok, err := d.Database.GetTableInsensitive(ctx, name)
+	if !ok || err != nil {
+		return t, ok, err
+	}
+	return &bulkLoadTable{t.(*memory.Table), d}, true, nil
+}
+
+// bulkLoadTable is a table recording its bulk loads in its database.
+type bulkLoadTable struct {
+	*memory.Table
+	db *bulkLoadDatabase
+}
+
+var _ sql.BulkLoadTable = (*bulkLoadTable)(nil)
+
+func (t *bulkLoadTable) StartBulkLoad(ctx *sql.Context) error {
+	t.db.loads = append(t.db.loads, "start "+t.Name())
+	return nil
+}
+
+func (t *bulkLoadTable) FinishBulkLoad(ctx *sql.Context) error {
+	t.db.loads = append(t.db.loads, "finish "+t.Name())
+	return nil
+}
+
+func TestImportBulkLoad(t *testing.T) {
+	require := require.New(t)
+	db := &bulkLoadDatabase{Database: memory.NewDatabase("mydb")}
+	e := newEngine(db)
+	ctx := sql.NewEmptyContext()
+	ctx.SetCurrentDatabase("mydb")
+
+	dump := "CREATE TABLE t (a int PRIMARY KEY);\nCREATE TABLE u (a int PRIMARY KEY);\n" +
+		"INSERT INTO t VALUES (1);\nINSERT INTO `mydb`.`u` VALUES (1);\nINSERT INTO t VALUES (2);\n" +
+		"SELECT 1;\nINSERT INTO t VALUES (3);\n"
+
+	_, err := importer.Import(ctx, e, strings.NewReader(dump), importer.Options{})
+	require.NoError(err)
+	require.Equal([]string{"start t", "start u", "finish t", "finish u", "start t", "finish t"}, db.loads)
+}
@@ -0,0 +1,201 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// defaultDelimiter is the delimiter ending statements until a DELIMITER
+// command changes it.
+const defaultDelimiter = ";"
+
+// scanner reads the statements of a dump, like the mysql client does: the
+// statements end with the delimiter, which the DELIMITER command changes,
+// and comments are skipped, except the executable /*! ... */ ones.
+type scanner struct {
+	r         *bufio.Reader
+	delimiter string
+	buf       bytes.Buffer
+	// read is the number of bytes read.
+	read uint64
+}
+
+func newScanner(r io.Reader) *scanner {
+	return &scanner{r: bufio.NewReaderSize(r, 1<<16), delimiter: defaultDelimiter}
+}
+
+func (s *scanner) readByte() (byte, error) {
+	c, err := s.r.ReadByte()
+	if err == nil {
+		s.read++
+	}
+	return c, err
+}
+
+// next returns the next statement, without its delimiter, or io.EOF once
+// there are no more.
+func (s *scanner) next() (string, error) {
+	s.buf.Reset()
+	for {
+		c, err := s.readByte()
+		if err == io.EOF {
+			if stmt := strings.TrimSpace(s.buf.String()); stmt != "" {
+				return stmt, nil
+			}
+			return "", io.EOF
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case (c == 'd' || c == 'D') && isSpace(s.buf.Bytes()):
+			ok, err := s.maybeDelimiter()
+			if err != nil {
+				return "", err
+			}
+			if ok {
+				s.buf.Reset()
+				continue
+			}
+		case c == '#':
+			if err := s.skipLine(); err != nil {
+				return "", err
+			}
+			continue
+		case c == '-':
+			if next, _ := s.r.Peek(2); len(next) > 0 && next[0] == '-' && (len(next) == 1 || isSpace(next[1:])) {
+				if err := s.skipLine(); err != nil {
+					return "", err
+				}
+				continue
+			}
+		case c == '/':
+			if next, _ := s.r.Peek(2); len(next) > 0 && next[0] == '*' {
+				if err := s.comment(len(next) > 1 && next[1] == '!'); err != nil {
+					return "", err
+				}
+				continue
+			}
+		case c == '\'' || c == '"' || c == '`':
+			if err := s.quoted(c); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		s.buf.WriteByte(c)
+		if bytes.HasSuffix(s.buf.Bytes(), []byte(s.delimiter)) {
+			stmt := strings.TrimSpace(string(s.buf.Bytes()[:s.buf.Len()-len(s.delimiter)]))
+			if stmt != "" {
+				return stmt, nil
+			}
+			s.buf.Reset()
+		}
+	}
+}
+
+// maybeDelimiter reads a DELIMITER command once its first letter has been
+// read, changing the delimiter, and returns whether it was one. If it wasn't,
+// nothing else is read.
+func (s *scanner) maybeDelimiter() (bool, error) {
+	const rest = "elimiter"
+	next, _ := s.r.Peek(len(rest) + 1)
+	if len(next) <= len(rest) || !strings.EqualFold(string(next[:len(rest)]), rest) || !isSpace(next[len(rest):]) {
+		return false, nil
+	}
+
+	line, err := s.r.ReadString('\n')
+	s.read += uint64(len(line))
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	if d := strings.TrimSpace(line[len(rest):]); d != "" {
+		s.delimiter = d
+	}
+	return true, nil
+}
+
+// skipLine skips the rest of a line comment.
+func (s *scanner) skipLine() error {
+	line, err := s.r.ReadString('\n')
+	s.read += uint64(len(line))
+	if err == io.EOF {
+		return nil
+	}
+	if err == nil {
+		s.buf.WriteByte('\n')
+	}
+	return err
+}
+
+// comment reads a comment once its slash has been read, keeping it in the
+// statement if it's executable.
+func (s *scanner) comment(executable bool) error {
+	// The star opening the comment can't close it too.
+	if _, err := s.readByte(); err != nil {
+		return err
+	}
+	if executable {
+		s.buf.WriteString("/*")
+	}
+
+	var prev byte
+	for {
+		c, err := s.readByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if executable {
+			s.buf.WriteByte(c)
+		}
+		if prev == '*' && c == '/' {
+			if !executable {
+				s.buf.WriteByte(' ')
+			}
+			return nil
+		}
+		prev = c
+	}
+}
+
+// quoted reads a quoted string or identifier once its opening quote has been
+// read. Backslashes escape the characters following them in strings.
+func (s *scanner) quoted(quote byte) error {
+	s.buf.WriteByte(quote)
+	for {
+		c, err := s.readByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		s.buf.WriteByte(c)
+		switch {
+		case c == quote:
+			return nil
+		case c == '\\' && quote != '`':
+			c, err := s.readByte()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			s.buf.WriteByte(c)
+		}
+	}
+}
+
+func isSpace(b []byte) bool {
+	return len(bytes.TrimSpace(b)) == 0
+}
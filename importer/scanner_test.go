@@ -0,0 +1,45 @@
+package importer
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanner(t *testing.T) {
+	dump := "-- MySQL dump\n" +
+		"/*!40101 SET NAMES utf8 */;\n" +
+		"# comment; with a semicolon\n" +
+		"INSERT INTO `t;1` VALUES ('a;b', \"c\\\";d\", 'it''s;'); /* skipped; */ SELECT 1;\n" +
+		"SELECT 2 -- trailing\n" +
+		"  - 1;\n" +
+		"DELIMITER ;;\n" +
+		"CREATE TRIGGER trig BEFORE INSERT ON t FOR EACH ROW BEGIN SET new.a = 1; END ;;\n" +
+		"delimiter ;\n" +
+		"SELECT 3--1;\n" +
+		"SELECT 4"
+
+	s := newScanner(strings.NewReader(dump))
+	var stmts []string
+	for {
+		stmt, err := s.next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		stmts = append(stmts, stmt)
+	}
+
+	require.Equal(t, []string{
+		"/*!40101 SET NAMES utf8 */",
+		"INSERT INTO `t;1` VALUES ('a;b', \"c\\\";d\", 'it''s;')",
+		"SELECT 1",
+		"SELECT 2 \n  - 1",
+		"CREATE TRIGGER trig BEFORE INSERT ON t FOR EACH ROW BEGIN SET new.a = 1; END",
+		"SELECT 3--1",
+		"SELECT 4",
+	}, stmts)
+	require.Equal(t, uint64(len(dump)), s.read)
+}
@@ -0,0 +1,177 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// BER tags used by the LDAP messages implemented.
+const (
+	tagBoolean     = 0x01
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x30
+	tagSet         = 0x31
+
+	tagBindRequest       = 0x60
+	tagBindResponse      = 0x61
+	tagUnbindRequest     = 0x42
+	tagSearchRequest     = 0x63
+	tagSearchResultEntry = 0x64
+	tagSearchResultDone  = 0x65
+
+	tagSimpleAuth    = 0x80
+	tagPresentFilter = 0x87
+
+	// constructed is set in the tag of elements made of other elements.
+	constructed = 0x20
+)
+
+// maxElementLength is the maximum length of the elements read, which
+// prevents a misbehaving server from exhausting the memory.
+const maxElementLength = 16 << 20
+
+var errMalformed = errors.New("ldap: malformed message")
+
+// element is a BER encoded type-length-value.
+type element struct {
+	tag      byte
+	value    []byte
+	children []element
+}
+
+func encode(tag byte, content ...[]byte) []byte {
+	var length int
+	for _, c := range content {
+		length += len(c)
+	}
+
+	var buf = []byte{tag}
+	switch {
+	case length < 0x80:
+		buf = append(buf, byte(length))
+	default:
+		var lb []byte
+		for l := length; l > 0; l >>= 8 {
+			lb = append([]byte{byte(l)}, lb...)
+		}
+		buf = append(buf, 0x80|byte(len(lb)))
+		buf = append(buf, lb...)
+	}
+
+	for _, c := range content {
+		buf = append(buf, c...)
+	}
+
+	return buf
+}
+
+func encodeInt(tag byte, n int) []byte {
+	var b []byte
+	for {
+		b = append([]byte{byte(n)}, b...)
+		n >>= 8
+		if n == 0 && b[0]&0x80 == 0 {
+			break
+		}
+	}
+	return encode(tag, b)
+}
+
+func encodeString(tag byte, s string) []byte {
+	return encode(tag, []byte(s))
+}
+
+func encodeBool(b bool) []byte {
+	if b {
+		return encode(tagBoolean, []byte{0xff})
+	}
+	return encode(tagBoolean, []byte{0})
+}
+
+// readElement reads a whole element, decoding the children of constructed ones.
+func readElement(r *bufio.Reader) (element, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return element{}, err
+	}
+
+	length, err := readLength(r)
+	if err != nil {
+		return element{}, err
+	}
+
+	var value = make([]byte, length)
+	if _, err := io.ReadFull(r, value); err != nil {
+		return element{}, err
+	}
+
+	return decode(tag, value)
+}
+
+func readLength(r io.ByteReader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if b&0x80 == 0 {
+		return int(b), nil
+	}
+
+	n := int(b & 0x7f)
+	if n == 0 || n > 4 {
+		return 0, errMalformed
+	}
+
+	var length int
+	for i := 0; i < n; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+
+	if length > maxElementLength {
+		return 0, errMalformed
+	}
+
+	return length, nil
+}
+
+func decode(tag byte, value []byte) (element, error) {
+	e := element{tag: tag, value: value}
+	if tag&constructed == 0 {
+		return e, nil
+	}
+
+	r := bufio.NewReader(bytes.NewReader(value))
+	for {
+		child, err := readElement(r)
+		if err == io.EOF {
+			return e, nil
+		} else if err != nil {
+			return element{}, errMalformed
+		}
+		e.children = append(e.children, child)
+	}
+}
+
+func (e element) int() int {
+	var n int
+	for i, b := range e.value {
+		if i == 0 && b&0x80 != 0 {
+			n = -1
+		}
+		n = n<<8 | int(b)
+	}
+	return n
+}
+
+func (e element) string() string {
+	return string(e.value)
+}
@@ -0,0 +1,315 @@
+// Package ldap implements the small subset of the LDAP protocol needed to
+// authenticate users with a simple bind and read their group memberships.
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+var (
+	// ErrInvalidCredentials is returned when the directory rejects the
+	// credentials of a user.
+	ErrInvalidCredentials = errors.NewKind("ldap: invalid credentials for %s")
+	// ErrResult is returned when the directory answers a request with an
+	// error.
+	ErrResult = errors.NewKind("ldap: %s failed with result code %d: %s")
+	// ErrUnexpectedResponse is returned when the directory answers with an
+	// unexpected message.
+	ErrUnexpectedResponse = errors.NewKind("ldap: unexpected response to %s")
+)
+
+// resultInvalidCredentials is the result code of a bind with a wrong
+// password.
+const resultInvalidCredentials = 49
+
+// Config of a connection pool to a directory.
+type Config struct {
+	// Address of the directory server, in host:port form.
+	Address string
+	// TLS, if not nil, is used to connect to the server with LDAPS.
+	TLS *tls.Config
+	// UserDN is the template of the DN users bind as, with a %s replaced by
+	// the escaped user name, e.g. uid=%s,ou=people,dc=example,dc=com, or
+	// %s@example.com for Active Directory.
+	UserDN string
+	// GroupAttribute is the attribute of the user entry holding the DNs of
+	// its groups. Defaults to memberOf.
+	GroupAttribute string
+	// PoolSize is the maximum number of idle connections kept open.
+	// Defaults to 4.
+	PoolSize int
+	// Timeout of each operation, including dialing. Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+// Pool authenticates users against a directory server, reusing connections
+// between authentications.
+type Pool struct {
+	cfg  Config
+	mu   sync.Mutex
+	idle []*conn
+}
+
+// NewPool creates a pool of connections to the directory configured.
+func NewPool(cfg Config) *Pool {
+	if cfg.GroupAttribute == "" {
+		cfg.GroupAttribute = "memberOf"
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 4
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Pool{cfg: cfg}
+}
+
+// Authenticate binds as the user given and returns the DNs of its groups.
+// Empty passwords are always rejected, since the server would take them as
+// an unauthenticated bind.
+func (p *Pool) Authenticate(user, password string) ([]string, error) {
+	if password == "" {
+		return nil, ErrInvalidCredentials.New(user)
+	}
+
+	dn := strings.Replace(p.cfg.UserDN, "%s", EscapeDN(user), -1)
+	for {
+		c, reused, err := p.get()
+		if err != nil {
+			return nil, err
+		}
+
+		groups, err := c.authenticate(dn, password, p.cfg.GroupAttribute, p.cfg.Timeout)
+		if err == nil || ErrInvalidCredentials.Is(err) {
+			p.put(c)
+			return groups, err
+		}
+
+		// The state of the connection is unknown, so it's not reused.
+		c.close()
+
+		// Idle connections may have been closed by the server in the
+		// meantime, so the operation is retried with another one.
+		if !reused || ErrResult.Is(err) || ErrUnexpectedResponse.Is(err) {
+			return nil, err
+		}
+	}
+}
+
+// Close closes the idle connections of the pool.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, c := range p.idle {
+		c.close()
+	}
+	p.idle = nil
+}
+
+// get returns an idle connection, or a new one if there's none. The boolean
+// returned is true if the connection was idle.
+func (p *Pool) get() (*conn, bool, error) {
+	p.mu.Lock()
+	if n := len(p.idle); n > 0 {
+		c := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return c, true, nil
+	}
+	p.mu.Unlock()
+
+	dialer := &net.Dialer{Timeout: p.cfg.Timeout}
+	var nc net.Conn
+	var err error
+	if p.cfg.TLS != nil {
+		nc, err = tls.DialWithDialer(dialer, "tcp", p.cfg.Address, p.cfg.TLS)
+	} else {
+		nc, err = dialer.Dial("tcp", p.cfg.Address)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &conn{nc: nc, r: bufio.NewReader(nc)}, false, nil
+}
+
+func (p *Pool) put(c *conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.idle) >= p.cfg.PoolSize {
+		c.close()
+		return
+	}
+	p.idle = append(p.idle, c)
+}
+
+type conn struct {
+	nc     net.Conn
+	r      *bufio.Reader
+	nextID int
+}
+
+func (c *conn) authenticate(dn, password, groupAttribute string, timeout time.Duration) ([]string, error) {
+	if err := c.nc.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	if err := c.bind(dn, password); err != nil {
+		return nil, err
+	}
+
+	return c.readAttribute(dn, groupAttribute)
+}
+
+func (c *conn) bind(dn, password string) error {
+	id, err := c.send(encode(tagBindRequest,
+		encodeInt(tagInteger, 3),
+		encodeString(tagOctetString, dn),
+		encodeString(tagSimpleAuth, password),
+	))
+	if err != nil {
+		return err
+	}
+
+	op, err := c.receive(id)
+	if err != nil {
+		return err
+	}
+
+	if op.tag != tagBindResponse {
+		return ErrUnexpectedResponse.New("bind")
+	}
+
+	code, msg, err := result(op)
+	if err != nil {
+		return err
+	}
+
+	switch code {
+	case 0:
+		return nil
+	case resultInvalidCredentials:
+		return ErrInvalidCredentials.New(dn)
+	default:
+		return ErrResult.New("bind", code, msg)
+	}
+}
+
+// readAttribute returns the values of an attribute of the entry given.
+func (c *conn) readAttribute(dn, attribute string) ([]string, error) {
+	id, err := c.send(encode(tagSearchRequest,
+		encodeString(tagOctetString, dn),
+		encodeInt(tagEnumerated, 0), // scope: baseObject
+		encodeInt(tagEnumerated, 0), // derefAliases: never
+		encodeInt(tagInteger, 1),    // sizeLimit
+		encodeInt(tagInteger, 0),    // timeLimit
+		encodeBool(false),           // typesOnly
+		encodeString(tagPresentFilter, "objectClass"),
+		encode(tagSequence, encodeString(tagOctetString, attribute)),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	for {
+		op, err := c.receive(id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.tag {
+		case tagSearchResultEntry:
+			if len(op.children) < 2 {
+				return nil, errMalformed
+			}
+
+			for _, attr := range op.children[1].children {
+				if len(attr.children) < 2 || !strings.EqualFold(attr.children[0].string(), attribute) {
+					continue
+				}
+
+				for _, v := range attr.children[1].children {
+					values = append(values, v.string())
+				}
+			}
+		case tagSearchResultDone:
+			code, msg, err := result(op)
+			if err != nil {
+				return nil, err
+			}
+
+			if code != 0 {
+				return nil, ErrResult.New("search", code, msg)
+			}
+
+			return values, nil
+		default:
+			// Search result references are ignored.
+		}
+	}
+}
+
+func (c *conn) send(op []byte) (int, error) {
+	c.nextID++
+	_, err := c.nc.Write(encode(tagSequence, encodeInt(tagInteger, c.nextID), op))
+	return c.nextID, err
+}
+
+// receive reads the next message, which must have the ID given, and returns
+// its protocol operation.
+func (c *conn) receive(id int) (element, error) {
+	msg, err := readElement(c.r)
+	if err != nil {
+		return element{}, err
+	}
+
+	if msg.tag != tagSequence || len(msg.children) < 2 || msg.children[0].int() != id {
+		return element{}, errMalformed
+	}
+
+	return msg.children[1], nil
+}
+
+func (c *conn) close() {
+	_ = c.nc.SetDeadline(time.Now().Add(time.Second))
+	_, _ = c.send(encode(tagUnbindRequest))
+	_ = c.nc.Close()
+}
+
+// result returns the result code and diagnostic message of a response.
+func result(op element) (int, string, error) {
+	if len(op.children) < 3 {
+		return 0, "", errMalformed
+	}
+	return op.children[0].int(), op.children[2].string(), nil
+}
+
+// EscapeDN escapes a value to be used in a distinguished name, as defined in
+// RFC 4514.
+func EscapeDN(value string) string {
+	var b strings.Builder
+	for i, r := range value {
+		switch {
+		case strings.ContainsRune(`,+"\<>;=`, r),
+			i == 0 && (r == ' ' || r == '#'),
+			i == len(value)-1 && r == ' ':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r == 0:
+			b.WriteString(`\00`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
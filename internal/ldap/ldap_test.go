@@ -0,0 +1,216 @@
+package ldap
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDirectory is a directory server accepting the users given, with the
+// groups given.
+type fakeDirectory struct {
+	listener  net.Listener
+	passwords map[string]string
+	groups    map[string][]string
+
+	mu    sync.Mutex
+	conns int
+	binds []string
+}
+
+func newFakeDirectory(t *testing.T) *fakeDirectory {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	d := &fakeDirectory{
+		listener: l,
+		passwords: map[string]string{
+			"uid=bob,ou=people,dc=example,dc=com":   "secret",
+			`uid=a\,b,ou=people,dc=example,dc=com`:  "comma",
+			"uid=alice,ou=people,dc=example,dc=com": "wonderland",
+		},
+		groups: map[string][]string{
+			"uid=bob,ou=people,dc=example,dc=com": {
+				"cn=dba,ou=groups,dc=example,dc=com",
+				"cn=dev,ou=groups,dc=example,dc=com",
+			},
+		},
+	}
+
+	go d.serve()
+	t.Cleanup(func() { l.Close() })
+
+	return d
+}
+
+func (d *fakeDirectory) serve() {
+	for {
+		c, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		d.mu.Lock()
+		d.conns++
+		d.mu.Unlock()
+
+		go d.handle(c)
+	}
+}
+
+func (d *fakeDirectory) handle(c net.Conn) {
+	defer c.Close()
+
+	r := bufio.NewReader(c)
+	for {
+		msg, err := readElement(r)
+		if err != nil {
+			return
+		}
+
+		id := msg.children[0].int()
+		op := msg.children[1]
+		respond := func(ops ...[]byte) {
+			for _, op := range ops {
+				_, _ = c.Write(encode(tagSequence, encodeInt(tagInteger, id), op))
+			}
+		}
+
+		switch op.tag {
+		case tagBindRequest:
+			dn, password := op.children[1].string(), op.children[2].string()
+
+			d.mu.Lock()
+			d.binds = append(d.binds, dn)
+			d.mu.Unlock()
+
+			code := 0
+			if p, ok := d.passwords[dn]; !ok || p != password {
+				code = resultInvalidCredentials
+			}
+			respond(response(tagBindResponse, code))
+		case tagSearchRequest:
+			dn := op.children[0].string()
+			attribute := op.children[7].children[0].string()
+
+			var values [][]byte
+			for _, g := range d.groups[dn] {
+				values = append(values, encodeString(tagOctetString, g))
+			}
+
+			respond(
+				encode(tagSearchResultEntry,
+					encodeString(tagOctetString, dn),
+					encode(tagSequence, encode(tagSequence,
+						encodeString(tagOctetString, attribute),
+						encode(tagSet, values...),
+					)),
+				),
+				response(tagSearchResultDone, 0),
+			)
+		case tagUnbindRequest:
+			return
+		}
+	}
+}
+
+func response(tag byte, code int) []byte {
+	return encode(tag,
+		encodeInt(tagEnumerated, code),
+		encodeString(tagOctetString, ""),
+		encodeString(tagOctetString, ""),
+	)
+}
+
+func TestAuthenticate(t *testing.T) {
+	require := require.New(t)
+
+	d := newFakeDirectory(t)
+	p := NewPool(Config{
+		Address: d.listener.Addr().String(),
+		UserDN:  "uid=%s,ou=people,dc=example,dc=com",
+	})
+	defer p.Close()
+
+	groups, err := p.Authenticate("bob", "secret")
+	require.NoError(err)
+	require.Equal([]string{
+		"cn=dba,ou=groups,dc=example,dc=com",
+		"cn=dev,ou=groups,dc=example,dc=com",
+	}, groups)
+
+	groups, err = p.Authenticate("alice", "wonderland")
+	require.NoError(err)
+	require.Empty(groups)
+
+	_, err = p.Authenticate("a,b", "comma")
+	require.NoError(err)
+
+	_, err = p.Authenticate("bob", "wrong")
+	require.True(ErrInvalidCredentials.Is(err))
+
+	_, err = p.Authenticate("bob", "")
+	require.True(ErrInvalidCredentials.Is(err))
+
+	// Empty passwords never reach the server.
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	require.Len(d.binds, 4)
+	require.Equal(1, d.conns)
+}
+
+func TestAuthenticateStaleConnection(t *testing.T) {
+	require := require.New(t)
+
+	d := newFakeDirectory(t)
+	p := NewPool(Config{
+		Address: d.listener.Addr().String(),
+		UserDN:  "uid=%s,ou=people,dc=example,dc=com",
+	})
+	defer p.Close()
+
+	_, err := p.Authenticate("bob", "secret")
+	require.NoError(err)
+
+	// Break the idle connection, as if the server had closed it.
+	p.idle[0].nc.Close()
+
+	_, err = p.Authenticate("bob", "secret")
+	require.NoError(err)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	require.Equal(2, d.conns)
+}
+
+func TestAuthenticateUnreachable(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+
+	p := NewPool(Config{Address: addr, UserDN: "uid=%s"})
+	_, err = p.Authenticate("bob", "secret")
+	require.Error(t, err)
+	require.False(t, ErrInvalidCredentials.Is(err))
+}
+
+func TestEscapeDN(t *testing.T) {
+	testCases := []struct {
+		in, out string
+	}{
+		{"bob", "bob"},
+		{"a,b", `a\,b`},
+		{`a+b"c\d<e>f;g=h`, `a\+b\"c\\d\<e\>f\;g\=h`},
+		{" bob ", `\ bob\ `},
+		{"#bob#", `\#bob#`},
+		{"a\x00b", `a\00b`},
+	}
+
+	for _, tt := range testCases {
+		require.Equal(t, tt.out, EscapeDN(tt.in), tt.in)
+	}
+}
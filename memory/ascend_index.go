@@ -72,3 +72,14 @@ func (l *AscendIndexLookup) EvalExpression() sql.Expression {
 func (l *AscendIndexLookup) Intersection(lookups ...sql.IndexLookup) (sql.IndexLookup, error) {
 	return intersection(l.Index, l, lookups...), nil
 }
+
+// treeRows implements the treeLookup interface.
+func (l *AscendIndexLookup) treeRows(partition string) ([]sql.Row, bool, error) {
+	tree := indexTreeOf(l.Index)
+	if tree == nil {
+		return nil, false, nil
+	}
+
+	rows, err := tree.rangeRows(partition, l.Gte, l.Lt, false, l.EvalExpression())
+	return rows, true, err
+}
@@ -0,0 +1,257 @@
+package memory
+
+import (
+	"sort"
+)
+
+// btreeDegree is the minimum number of children of the inner nodes of a btree, other than its root.
+const btreeDegree = 32
+
+const (
+	btreeMaxItems = 2*btreeDegree - 1
+	btreeMinItems = btreeDegree - 1
+)
+
+// btree is a B-tree of index entries, ordered by its less function.
+type btree struct {
+	root *btreeNode
+	less func(a, b *indexEntry) bool
+	len  int
+}
+
+type btreeNode struct {
+	items    []*indexEntry
+	children []*btreeNode
+}
+
+func newBtree(less func(a, b *indexEntry) bool) *btree {
+	return &btree{less: less}
+}
+
+// insert adds an entry to the tree, which must not be equal to any other entry in it.
+func (t *btree) insert(e *indexEntry) {
+	t.len++
+	if t.root == nil {
+		t.root = &btreeNode{items: []*indexEntry{e}}
+		return
+	}
+
+	if len(t.root.items) >= btreeMaxItems {
+		item, next := t.root.split(btreeMaxItems / 2)
+		t.root = &btreeNode{
+			items:    []*indexEntry{item},
+			children: []*btreeNode{t.root, next},
+		}
+	}
+	t.root.insert(e, t.less)
+}
+
+// remove removes the entry equal to the one given from the tree, and returns whether there was one.
+func (t *btree) remove(e *indexEntry) bool {
+	if t.root == nil {
+		return false
+	}
+
+	removed := t.root.remove(e, t.less)
+	if len(t.root.items) == 0 {
+		if len(t.root.children) > 0 {
+			t.root = t.root.children[0]
+		} else {
+			t.root = nil
+		}
+	}
+	if removed {
+		t.len--
+	}
+	return removed
+}
+
+// ascend calls f with the entries of the tree not less than from, or all of them if from is nil, in ascending order
+// until it returns false.
+func (t *btree) ascend(from *indexEntry, f func(*indexEntry) bool) {
+	if t.root != nil {
+		t.root.ascend(from, t.less, f)
+	}
+}
+
+// descend calls f with the entries of the tree not greater than to, or all of them if to is nil, in descending order
+// until it returns false.
+func (t *btree) descend(to *indexEntry, f func(*indexEntry) bool) {
+	if t.root != nil {
+		t.root.descend(to, t.less, f)
+	}
+}
+
+// find returns the position of the first item of the node not less than the entry given, and whether it's equal to
+// it.
+func (n *btreeNode) find(e *indexEntry, less func(a, b *indexEntry) bool) (int, bool) {
+	i := sort.Search(len(n.items), func(i int) bool {
+		return !less(n.items[i], e)
+	})
+	return i, i < len(n.items) && !less(e, n.items[i])
+}
+
+// split splits the node at the item given, which is returned with the new node holding the items and children after
+// it.
+func (n *btreeNode) split(i int) (*indexEntry, *btreeNode) {
+	item := n.items[i]
+	next := &btreeNode{items: append([]*indexEntry(nil), n.items[i+1:]...)}
+	n.items = n.items[:i:i]
+	if len(n.children) > 0 {
+		next.children = append([]*btreeNode(nil), n.children[i+1:]...)
+		n.children = n.children[: i+1 : i+1]
+	}
+	return item, next
+}
+
+func (n *btreeNode) insert(e *indexEntry, less func(a, b *indexEntry) bool) {
+	i, _ := n.find(e, less)
+	if len(n.children) == 0 {
+		n.items = insertItem(n.items, i, e)
+		return
+	}
+
+	if len(n.children[i].items) >= btreeMaxItems {
+		item, next := n.children[i].split(btreeMaxItems / 2)
+		n.items = insertItem(n.items, i, item)
+		n.children = insertChild(n.children, i+1, next)
+		if less(item, e) {
+			i++
+		}
+	}
+	n.children[i].insert(e, less)
+}
+
+func (n *btreeNode) remove(e *indexEntry, less func(a, b *indexEntry) bool) bool {
+	i, found := n.find(e, less)
+	if len(n.children) == 0 {
+		if found {
+			n.items = removeItem(n.items, i)
+		}
+		return found
+	}
+
+	// The child removed from must have more than the minimum number of items, so that it still has enough after.
+	if len(n.children[i].items) <= btreeMinItems {
+		n.growChild(i)
+		return n.remove(e, less)
+	}
+
+	if found {
+		n.items[i] = n.children[i].removeMax()
+		return true
+	}
+	return n.children[i].remove(e, less)
+}
+
+// removeMax removes the largest item of the node and its children and returns it.
+func (n *btreeNode) removeMax() *indexEntry {
+	if len(n.children) == 0 {
+		item := n.items[len(n.items)-1]
+		n.items = removeItem(n.items, len(n.items)-1)
+		return item
+	}
+
+	i := len(n.items)
+	if len(n.children[i].items) <= btreeMinItems {
+		n.growChild(i)
+		return n.removeMax()
+	}
+	return n.children[i].removeMax()
+}
+
+// growChild adds an item to the child given, taking it from one of its siblings or merging it with one.
+func (n *btreeNode) growChild(i int) {
+	switch {
+	case i > 0 && len(n.children[i-1].items) > btreeMinItems:
+		child, left := n.children[i], n.children[i-1]
+		child.items = insertItem(child.items, 0, n.items[i-1])
+		n.items[i-1] = left.items[len(left.items)-1]
+		left.items = removeItem(left.items, len(left.items)-1)
+		if len(left.children) > 0 {
+			child.children = insertChild(child.children, 0, left.children[len(left.children)-1])
+			left.children = removeChild(left.children, len(left.children)-1)
+		}
+	case i < len(n.items) && len(n.children[i+1].items) > btreeMinItems:
+		child, right := n.children[i], n.children[i+1]
+		child.items = append(child.items, n.items[i])
+		n.items[i] = right.items[0]
+		right.items = removeItem(right.items, 0)
+		if len(right.children) > 0 {
+			child.children = append(child.children, right.children[0])
+			right.children = removeChild(right.children, 0)
+		}
+	default:
+		if i >= len(n.items) {
+			i--
+		}
+		child, next := n.children[i], n.children[i+1]
+		child.items = append(child.items, n.items[i])
+		child.items = append(child.items, next.items...)
+		child.children = append(child.children, next.children...)
+		n.items = removeItem(n.items, i)
+		n.children = removeChild(n.children, i+1)
+	}
+}
+
+func (n *btreeNode) ascend(from *indexEntry, less func(a, b *indexEntry) bool, f func(*indexEntry) bool) bool {
+	i := 0
+	if from != nil {
+		i, _ = n.find(from, less)
+	}
+
+	for ; i <= len(n.items); i++ {
+		if len(n.children) > 0 && !n.children[i].ascend(from, less, f) {
+			return false
+		}
+		if i < len(n.items) && !f(n.items[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *btreeNode) descend(to *indexEntry, less func(a, b *indexEntry) bool, f func(*indexEntry) bool) bool {
+	i := len(n.items)
+	if to != nil {
+		i = sort.Search(len(n.items), func(i int) bool {
+			return less(to, n.items[i])
+		})
+	}
+
+	for ; i >= 0; i-- {
+		if len(n.children) > 0 && !n.children[i].descend(to, less, f) {
+			return false
+		}
+		if i > 0 && !f(n.items[i-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func insertItem(items []*indexEntry, i int, e *indexEntry) []*indexEntry {
+	items = append(items, nil)
+	copy(items[i+1:], items[i:])
+	items[i] = e
+	return items
+}
+
+func removeItem(items []*indexEntry, i int) []*indexEntry {
+	copy(items[i:], items[i+1:])
+	items[len(items)-1] = nil
+	return items[:len(items)-1]
+}
+
+func insertChild(children []*btreeNode, i int, c *btreeNode) []*btreeNode {
+	children = append(children, nil)
+	copy(children[i+1:], children[i:])
+	children[i] = c
+	return children
+}
+
+func removeChild(children []*btreeNode, i int) []*btreeNode {
+	copy(children[i:], children[i+1:])
+	children[len(children)-1] = nil
+	return children[:len(children)-1]
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBtree(t *testing.T) {
+	require := require.New(t)
+	less := func(a, b *indexEntry) bool { return a.seq < b.seq }
+	tree := newBtree(less)
+
+	r := rand.New(rand.NewSource(1))
+	present := make(map[uint64]bool)
+	for i := 0; i < 20000; i++ {
+		seq := uint64(r.Intn(5000))
+		if present[seq] {
+			require.True(tree.remove(&indexEntry{seq: seq}))
+			delete(present, seq)
+		} else {
+			tree.insert(&indexEntry{seq: seq})
+			present[seq] = true
+		}
+	}
+	require.False(tree.remove(&indexEntry{seq: 5000}))
+
+	var expected []uint64
+	for seq := range present {
+		expected = append(expected, seq)
+	}
+	sort.Slice(expected, func(i, j int) bool { return expected[i] < expected[j] })
+	require.Equal(len(expected), tree.len)
+
+	var ascended []uint64
+	tree.ascend(nil, func(e *indexEntry) bool {
+		ascended = append(ascended, e.seq)
+		return true
+	})
+	require.Equal(expected, ascended)
+
+	pivot := expected[len(expected)/2]
+	var from []uint64
+	tree.ascend(&indexEntry{seq: pivot}, func(e *indexEntry) bool {
+		from = append(from, e.seq)
+		return len(from) < 3
+	})
+	require.Equal(expected[len(expected)/2:len(expected)/2+3], from)
+
+	var descended []uint64
+	tree.descend(&indexEntry{seq: pivot}, func(e *indexEntry) bool {
+		descended = append(descended, e.seq)
+		return true
+	})
+	require.Len(descended, len(expected)/2+1)
+	for i, seq := range descended {
+		require.Equal(expected[len(expected)/2-i], seq)
+	}
+
+	for _, seq := range expected {
+		require.True(tree.remove(&indexEntry{seq: seq}))
+	}
+	require.Equal(0, tree.len)
+	require.Nil(tree.root)
+}
@@ -11,33 +11,75 @@ type Database struct {
 	name              string
 	tables            map[string]sql.Table
 	triggers          []sql.TriggerDefinition
+	functions         sql.FunctionRegistry
 	primaryKeyIndexes bool
+	partitions        int
+	collation         sql.Collation
+	transactions      *transactions
 }
 
 var _ sql.Database = (*Database)(nil)
 var _ sql.TableCreator = (*Database)(nil)
+var _ sql.TableOptionsCreator = (*Database)(nil)
 var _ sql.TableDropper = (*Database)(nil)
 var _ sql.TableRenamer = (*Database)(nil)
+var _ sql.MultiTableRenamer = (*Database)(nil)
 var _ sql.TriggerDatabase = (*Database)(nil)
+var _ sql.FunctionDatabase = (*Database)(nil)
+var _ sql.CollatedDatabase = (*Database)(nil)
 
 // NewDatabase creates a new database with the given name.
 func NewDatabase(name string) *Database {
 	return &Database{
-		name:   name,
-		tables: map[string]sql.Table{},
+		name:         name,
+		tables:       map[string]sql.Table{},
+		transactions: newTransactions(),
 	}
 }
 
+// DatabaseCreator creates the databases of CREATE DATABASE statements as new memory databases.
+type DatabaseCreator struct{}
+
+var _ sql.DatabaseCreator = DatabaseCreator{}
+
+// CreateDatabase implements the sql.DatabaseCreator interface.
+func (DatabaseCreator) CreateDatabase(ctx *sql.Context, name string, collation sql.Collation) (sql.Database, error) {
+	db := NewDatabase(name)
+	db.collation = collation
+	return db, nil
+}
+
 // EnablePrimaryKeyIndexes causes every table created in this database to use an index on its primary keys
 func (d *Database) EnablePrimaryKeyIndexes() {
 	d.primaryKeyIndexes = true
 }
 
+// SetPartitions causes every table created in this database to have the given number of partitions, among which their
+// rows are hashed on their primary key. Tables have a single partition by default.
+func (d *Database) SetPartitions(n int) {
+	d.partitions = n
+}
+
 // Name returns the database name.
 func (d *Database) Name() string {
 	return d.name
 }
 
+// Collation implements the sql.CollatedDatabase interface. Databases have the default collation of the server unless
+// it's changed.
+func (d *Database) Collation() sql.Collation {
+	if d.collation == "" {
+		return sql.Collation_Default
+	}
+	return d.collation
+}
+
+// SetCollation implements the sql.CollatedDatabase interface.
+func (d *Database) SetCollation(ctx *sql.Context, collation sql.Collation) error {
+	d.collation = collation
+	return nil
+}
+
 // Tables returns all tables in the database.
 func (d *Database) Tables() map[string]sql.Table {
 	return d.tables
@@ -45,6 +87,9 @@ func (d *Database) Tables() map[string]sql.Table {
 
 func (d *Database) GetTableInsensitive(ctx *sql.Context, tblName string) (sql.Table, bool, error) {
 	tbl, ok := sql.GetTableInsensitive(tblName, d.tables)
+	if ok {
+		tbl = transactionTable(ctx, tbl)
+	}
 	return tbl, ok, nil
 }
 
@@ -57,6 +102,20 @@ func (d *Database) GetTableNames(ctx *sql.Context) ([]string, error) {
 	return tblNames, nil
 }
 
+// AddFunction registers functions that are only visible while this database is the current database.
+func (d *Database) AddFunction(fn ...sql.Function) error {
+	if d.functions == nil {
+		d.functions = sql.NewFunctionRegistry()
+	}
+	return d.functions.Register(fn...)
+}
+
+// Function implements sql.FunctionDatabase
+func (d *Database) Function(ctx *sql.Context, name string) (sql.Function, bool, error) {
+	fn, ok := d.functions[name]
+	return fn, ok, nil
+}
+
 // HistoryDatabase is a test-only VersionedDatabase implementation. It only supports exact lookups, not AS OF queries
 // between two revisions. It's constructed just like its non-versioned sibling, but it can receive updates to particular
 // tables via the AddTableAsOf method. Consecutive calls to AddTableAsOf with the same table must install new versions
@@ -105,31 +164,50 @@ func (db *HistoryDatabase) AddTableAsOf(name string, t sql.Table, asOf interface
 	}
 
 	db.Revisions[strings.ToLower(name)][asOf] = t
-	db.tables[name] = t
+	db.AddTable(name, t)
 }
 
-// AddTable adds a new table to the database.
+// AddTable adds a new table to the database. The memory tables are read by the transactions of the database from then
+// on.
 func (d *Database) AddTable(name string, t sql.Table) {
+	if t, ok := t.(*Table); ok && t.transactions == nil {
+		t.transactions = d.transactions
+	}
 	d.tables[name] = t
 }
 
 // CreateTable creates a table with the given name and schema
 func (d *Database) CreateTable(ctx *sql.Context, name string, schema sql.Schema) error {
+	return d.CreateTableWithOptions(ctx, name, schema, nil)
+}
+
+// CreateTableWithOptions creates a table with the given name, schema and options. The tables keep their options to
+// report them, but don't act on them.
+func (d *Database) CreateTableWithOptions(ctx *sql.Context, name string, schema sql.Schema, options sql.TableOptions) error {
+	if err := commitTransaction(ctx); err != nil {
+		return err
+	}
+
 	_, ok := d.tables[name]
 	if ok {
 		return sql.ErrTableAlreadyExists.New(name)
 	}
 
-	table := NewTable(name, schema)
+	table := NewPartitionedTable(name, schema, d.partitions)
 	if d.primaryKeyIndexes {
 		table.EnablePrimaryKeyIndexes()
 	}
-	d.tables[name] = table
+	table.options = options
+	d.AddTable(name, table)
 	return nil
 }
 
 // DropTable drops the table with the given name
 func (d *Database) DropTable(ctx *sql.Context, name string) error {
+	if err := commitTransaction(ctx); err != nil {
+		return err
+	}
+
 	_, ok := d.tables[name]
 	if !ok {
 		return sql.ErrTableNotFound.New(name)
@@ -140,6 +218,10 @@ func (d *Database) DropTable(ctx *sql.Context, name string) error {
 }
 
 func (d *Database) RenameTable(ctx *sql.Context, oldName, newName string) error {
+	if err := commitTransaction(ctx); err != nil {
+		return err
+	}
+
 	tbl, ok := d.tables[oldName]
 	if !ok {
 		// Should be impossible (engine already checks this condition)
@@ -151,13 +233,52 @@ func (d *Database) RenameTable(ctx *sql.Context, oldName, newName string) error
 		return sql.ErrTableAlreadyExists.New(newName)
 	}
 
-	tbl.(*Table).name = newName
+	tbl.(*Table).rename(newName)
 	d.tables[newName] = tbl
 	delete(d.tables, oldName)
 
 	return nil
 }
 
+// RenameTables implements sql.MultiTableRenamer. The tables are renamed on a copy of the tables of the database, which
+// are replaced by it once all the renames are done.
+func (d *Database) RenameTables(ctx *sql.Context, oldNames, newNames []string) error {
+	if err := commitTransaction(ctx); err != nil {
+		return err
+	}
+
+	tables := make(map[string]sql.Table, len(d.tables))
+	for name, tbl := range d.tables {
+		tables[name] = tbl
+	}
+
+	for i, oldName := range oldNames {
+		tbl, ok := tables[oldName]
+		if !ok {
+			return sql.ErrTableNotFound.New(oldName)
+		}
+		if _, ok := tables[newNames[i]]; ok {
+			return sql.ErrTableAlreadyExists.New(newNames[i])
+		}
+		tables[newNames[i]] = tbl
+		delete(tables, oldName)
+	}
+
+	for _, name := range newNames {
+		if tbl, ok := tables[name]; ok {
+			tbl.(*Table).rename(name)
+		}
+	}
+	for name := range d.tables {
+		delete(d.tables, name)
+	}
+	for name, tbl := range tables {
+		d.tables[name] = tbl
+	}
+
+	return nil
+}
+
 func (d *Database) GetTriggers(ctx *sql.Context) ([]sql.TriggerDefinition, error) {
 	var triggers []sql.TriggerDefinition
 	for _, def := range d.triggers {
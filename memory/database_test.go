@@ -32,3 +32,17 @@ func TestDatabase_AddTable(t *testing.T) {
 	err = db.CreateTable(sql.NewEmptyContext(), "test_table", nil)
 	require.Error(err)
 }
+
+func TestDatabase_SetPartitions(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	db := NewDatabase("test")
+	db.SetPartitions(4)
+
+	require.NoError(db.CreateTable(ctx, "test_table", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "test_table", PrimaryKey: true},
+	}))
+	count, err := db.Tables()["test_table"].(sql.PartitionCounter).PartitionCount(ctx)
+	require.NoError(err)
+	require.Equal(int64(4), count)
+}
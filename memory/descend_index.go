@@ -74,3 +74,14 @@ func (l *DescendIndexLookup) Union(lookups ...sql.IndexLookup) (sql.IndexLookup,
 func (l *DescendIndexLookup) Intersection(lookups ...sql.IndexLookup) (sql.IndexLookup, error) {
 	return intersection(l.Index, l, lookups...), nil
 }
+
+// treeRows implements the treeLookup interface.
+func (l *DescendIndexLookup) treeRows(partition string) ([]sql.Row, bool, error) {
+	tree := indexTreeOf(l.Index)
+	if tree == nil {
+		return nil, false, nil
+	}
+
+	rows, err := tree.rangeRows(partition, l.Gt, l.Lte, true, l.EvalExpression())
+	return rows, true, err
+}
@@ -0,0 +1,406 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// indexEntry is an entry of an index tree: the values of the index expressions for a row, and the row. Entries with
+// equal keys are ordered by seq, the order they were added in. Entries with a bound are only used to look up the
+// others, and are ordered before (-1) or after (1) all the entries whose keys begin with their key.
+type indexEntry struct {
+	key   []interface{}
+	row   sql.Row
+	seq   uint64
+	bound int
+}
+
+// treeLookup is a lookup that the tree of its index, if it has one, finds the rows of.
+type treeLookup interface {
+	// treeRows returns the rows of the partition given that the lookup matches, in the order of the index, and
+	// whether its index has a tree to find them with.
+	treeRows(partition string) ([]sql.Row, bool, error)
+}
+
+// indexTree keeps the rows of a table ordered by the values of the expressions of one of its indexes, in a B-tree for
+// every partition.
+type indexTree struct {
+	index *MergeableIndex
+	parts map[string]*btree
+	seq   uint64
+}
+
+func newIndexTree(index *MergeableIndex) *indexTree {
+	return &indexTree{index: index, parts: make(map[string]*btree)}
+}
+
+// indexTreeOf returns the tree of the index given, or nil if it has none.
+func indexTreeOf(index ExpressionsIndex) *indexTree {
+	switch index := index.(type) {
+	case *MergeableIndex:
+		return index.tree
+	case *UnmergeableIndex:
+		return index.tree
+	default:
+		return nil
+	}
+}
+
+func (t *indexTree) less(a, b *indexEntry) bool {
+	return t.compare(a, b) < 0
+}
+
+func (t *indexTree) compare(a, b *indexEntry) int {
+	for i := 0; i < len(a.key) && i < len(b.key); i++ {
+		if c := compareIndexValues(t.index.Exprs[i].Type(), a.key[i], b.key[i]); c != 0 {
+			if t.descending(i) {
+				return -c
+			}
+			return c
+		}
+	}
+
+	switch {
+	case a.bound != b.bound:
+		return a.bound - b.bound
+	case a.seq < b.seq:
+		return -1
+	case a.seq > b.seq:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// descending returns whether the index orders the values of its i-th expression in descending order.
+func (t *indexTree) descending(i int) bool {
+	return i < len(t.index.Desc) && t.index.Desc[i]
+}
+
+// compareIndexValues compares two values of an index expression of the type given, ordering NULL first.
+func compareIndexValues(typ sql.Type, a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	c, err := typ.Compare(a, b)
+	if err != nil {
+		// The values in the tree are all of the type of the expression, so this only orders the keys of lookups that
+		// can't be converted to it consistently.
+		as, bs := fmt.Sprint(a), fmt.Sprint(b)
+		switch {
+		case as < bs:
+			return -1
+		case as > bs:
+			return 1
+		}
+		return 0
+	}
+	return c
+}
+
+// prefixLength returns the length of the prefix of the values of the i-th index expression that the index keeps, or
+// 0 if it keeps all of them.
+func (t *indexTree) prefixLength(i int) int64 {
+	if i < len(t.index.Prefixes) {
+		return t.index.Prefixes[i]
+	}
+	return 0
+}
+
+// prefix returns the prefix of the value of the i-th index expression that the index keeps: its first characters, or
+// bytes for binary strings, if the index has a prefix length for the expression.
+func (t *indexTree) prefix(i int, v interface{}) interface{} {
+	length := t.prefixLength(i)
+	s, ok := v.(string)
+	if length == 0 || !ok {
+		return v
+	}
+
+	if sql.IsBlob(t.index.Exprs[i].Type()) {
+		if int64(len(s)) > length {
+			return s[:length]
+		}
+		return s
+	}
+
+	var chars int64
+	for j := range s {
+		if chars == length {
+			return s[:j]
+		}
+		chars++
+	}
+	return s
+}
+
+// key returns the values of the index expressions for a row.
+func (t *indexTree) key(row sql.Row) ([]interface{}, error) {
+	key := make([]interface{}, len(t.index.Exprs))
+	for i, e := range t.index.Exprs {
+		v, err := e.Eval(sql.NewEmptyContext(), row)
+		if err != nil {
+			return nil, err
+		}
+		key[i] = t.prefix(i, v)
+	}
+	return key, nil
+}
+
+// lookupKey converts the values of a lookup to the types of the first index expressions, and to the prefixes of them
+// the index keeps, returning false if any can't be converted.
+func (t *indexTree) lookupKey(values []interface{}) ([]interface{}, bool) {
+	if len(values) > len(t.index.Exprs) {
+		return nil, false
+	}
+
+	key := make([]interface{}, len(values))
+	for i, v := range values {
+		typ := t.index.Exprs[i].Type()
+		// Strings are compared with the numbers they're compared to as numbers, not as the strings they convert to.
+		if _, ok := v.(string); sql.IsText(typ) && v != nil && !ok {
+			return nil, false
+		}
+
+		converted, err := typ.Convert(v)
+		if err != nil {
+			return nil, false
+		}
+		key[i] = t.prefix(i, converted)
+	}
+	return key, true
+}
+
+// insert adds a row of a partition to the tree.
+func (t *indexTree) insert(partition string, row sql.Row) error {
+	key, err := t.key(row)
+	if err != nil {
+		return err
+	}
+
+	tree, ok := t.parts[partition]
+	if !ok {
+		tree = newBtree(t.less)
+		t.parts[partition] = tree
+	}
+
+	t.seq++
+	tree.insert(&indexEntry{key: key, row: row, seq: t.seq})
+	return nil
+}
+
+// remove removes a row of a partition from the tree.
+func (t *indexTree) remove(partition string, row sql.Row) error {
+	key, err := t.key(row)
+	if err != nil {
+		return err
+	}
+
+	tree, ok := t.parts[partition]
+	if !ok {
+		return nil
+	}
+
+	// The entry of the row itself is removed rather than the one of another row with the same values.
+	var found *indexEntry
+	tree.ascend(&indexEntry{key: key, bound: -1}, func(e *indexEntry) bool {
+		if t.compare(e, &indexEntry{key: key, bound: 1}) > 0 {
+			return false
+		}
+		if sameRow(e.row, row) {
+			found = e
+			return false
+		}
+		if found == nil && reflect.DeepEqual(e.row, row) {
+			found = e
+		}
+		return true
+	})
+
+	if found != nil {
+		tree.remove(found)
+	}
+	return nil
+}
+
+// duplicate returns whether a row other than except has the key of the row given, in any partition. Keys with NULL
+// values are never duplicated, like in MySQL.
+func (t *indexTree) duplicate(row, except sql.Row) (bool, error) {
+	key, err := t.key(row)
+	if err != nil {
+		return false, err
+	}
+
+	for _, v := range key {
+		if v == nil {
+			return false, nil
+		}
+	}
+
+	var duplicate bool
+	for _, tree := range t.parts {
+		tree.ascend(&indexEntry{key: key, bound: -1}, func(e *indexEntry) bool {
+			if t.compare(e, &indexEntry{key: key, bound: 1}) > 0 {
+				return false
+			}
+			duplicate = except == nil || !reflect.DeepEqual(e.row, except)
+			return !duplicate
+		})
+		if duplicate {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// rows returns the rows of a partition whose keys are between the ones of from and to, which are nil if unbounded,
+// in the order of the index or its reverse, and for which filter is true.
+func (t *indexTree) rows(partition string, from, to *indexEntry, desc bool, filter sql.Expression) ([]sql.Row, error) {
+	tree, ok := t.parts[partition]
+	if !ok {
+		return nil, nil
+	}
+
+	var rows []sql.Row
+	var err error
+	f := func(e *indexEntry) bool {
+		if (desc && from != nil && t.less(e, from)) || (!desc && to != nil && t.less(to, e)) {
+			return false
+		}
+
+		var ok bool
+		ok, err = sql.EvaluateCondition(sql.NewEmptyContext(), filter, e.row)
+		if err != nil {
+			return false
+		}
+		if ok {
+			rows = append(rows, e.row)
+		}
+		return true
+	}
+
+	if desc {
+		tree.descend(to, f)
+	} else {
+		tree.ascend(from, f)
+	}
+	return rows, err
+}
+
+// firstPartition returns the first of the partitions given with rows, or an empty string if none has any.
+func (t *indexTree) firstPartition(partitions [][]byte) string {
+	for _, partition := range partitions {
+		tree, ok := t.parts[string(partition)]
+		if !ok {
+			continue
+		}
+
+		var empty = true
+		tree.ascend(nil, func(*indexEntry) bool {
+			empty = false
+			return false
+		})
+		if !empty {
+			return string(partition)
+		}
+	}
+	return ""
+}
+
+// rangeRows returns the rows of a partition for a lookup on a range of the first index expression, with the bounds
+// given, which are empty if unbounded, in the order of its values or their reverse if desc.
+func (t *indexTree) rangeRows(partition string, lower, upper []interface{}, desc bool, filter sql.Expression) ([]sql.Row, error) {
+	if t.descending(0) {
+		// The lowest values of a descending expression are last in the tree.
+		lower, upper, desc = upper, lower, !desc
+	}
+
+	var from, to *indexEntry
+	if len(lower) > 0 {
+		if key, ok := t.lookupKey(lower[:1]); ok {
+			from = &indexEntry{key: key, bound: -1}
+		}
+	}
+	if len(upper) > 0 {
+		if key, ok := t.lookupKey(upper[:1]); ok {
+			to = &indexEntry{key: key, bound: 1}
+		}
+	}
+	return t.rows(partition, from, to, desc, filter)
+}
+
+// rangeBounds returns the entries bounding the keys within the ranges given of the index expressions: the ones
+// beginning with the values of the longest prefix of them ranging over a single value, and then with the values within
+// the range of the next one.
+func (t *indexTree) rangeBounds(ranges []sql.IndexRange) (from, to *indexEntry) {
+	var prefix []interface{}
+	for i, r := range ranges {
+		if i >= len(t.index.Exprs) {
+			break
+		}
+		if !isPointRange(t.index.Exprs[i].Type(), r) {
+			if t.descending(i) {
+				return t.boundEntry(prefix, r.Upper, -1), t.boundEntry(prefix, r.Lower, 1)
+			}
+			return t.boundEntry(prefix, r.Lower, -1), t.boundEntry(prefix, r.Upper, 1)
+		}
+		prefix = append(prefix, r.Lower.Value)
+	}
+	return t.boundEntry(prefix, nil, -1), t.boundEntry(prefix, nil, 1)
+}
+
+// boundEntry returns the entry ordered before (side -1) or after (1) the keys beginning with the prefix given and the
+// value of the bound, if any, or nil if they don't bound the keys. Exclusive bounds are on the other side of the keys
+// beginning with their value, unless the index only keeps a prefix of it.
+func (t *indexTree) boundEntry(prefix []interface{}, bound *sql.IndexBound, side int) *indexEntry {
+	values := prefix
+	if bound != nil {
+		values = append(append([]interface{}{}, prefix...), bound.Value)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	key, ok := t.lookupKey(values)
+	if !ok {
+		if bound == nil {
+			return nil
+		}
+		return t.boundEntry(prefix, nil, side)
+	}
+
+	if bound != nil && !bound.Inclusive && t.prefixLength(len(values)-1) == 0 {
+		side = -side
+	}
+	return &indexEntry{key: key, bound: side}
+}
+
+// build adds all the rows of a table to the tree, failing if the index is unique and two of them have the same key.
+func (t *indexTree) build(partitions map[string][]sql.Row) error {
+	t.parts = make(map[string]*btree)
+	for partition, rows := range partitions {
+		for _, row := range rows {
+			if t.index.Unique {
+				duplicate, err := t.duplicate(row, nil)
+				if err != nil {
+					return err
+				}
+				if duplicate {
+					return sql.ErrUniqueKeyViolation.New(t.index.ID())
+				}
+			}
+
+			if err := t.insert(partition, row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -17,12 +17,28 @@ type MergeableIndex struct {
 	Name       string
 	Unique     bool
 	CommentStr string
+	// Desc is whether the index orders the values of each of Exprs in descending order, all ascending if empty.
+	Desc []bool
+	// Prefixes is the length of the prefix of the values of each of Exprs that the index keeps, 0 to keep all of them.
+	Prefixes []int64
+	// Invisible is whether the index is invisible to the analyzer.
+	Invisible bool
+	// Type is the type of the index it was created as, such as HASH or FULLTEXT, or BTREE if empty. It's only reported,
+	// as all the indexes of the tables are kept the same way.
+	Type string
+	// tree keeps the rows of the table ordered by the index, if the table maintains it.
+	tree *indexTree
 }
 
 var _ sql.Index = (*MergeableIndex)(nil)
 var _ sql.AscendIndex = (*MergeableIndex)(nil)
 var _ sql.DescendIndex = (*MergeableIndex)(nil)
 var _ sql.NegateIndex = (*MergeableIndex)(nil)
+var _ sql.RangeIndex = (*MergeableIndex)(nil)
+var _ sql.OrderedIndex = (*MergeableIndex)(nil)
+var _ sql.DirectionalIndex = (*MergeableIndex)(nil)
+var _ sql.PrefixIndex = (*MergeableIndex)(nil)
+var _ sql.InvisibleIndex = (*MergeableIndex)(nil)
 
 func (i *MergeableIndex) Database() string                    { return i.DB }
 func (i *MergeableIndex) Driver() string                      { return i.DriverName }
@@ -37,6 +53,37 @@ func (i *MergeableIndex) Expressions() []string {
 	return exprs
 }
 
+func (i *MergeableIndex) Descending() []bool {
+	desc := make([]bool, len(i.Exprs))
+	copy(desc, i.Desc)
+	return desc
+}
+
+func (i *MergeableIndex) PrefixLengths() []int64 {
+	prefixes := make([]int64, len(i.Exprs))
+	copy(prefixes, i.Prefixes)
+	return prefixes
+}
+
+func (i *MergeableIndex) IsVisible() bool {
+	return !i.Invisible
+}
+
+// indexColumns returns the key parts the index was created with.
+func (i *MergeableIndex) indexColumns() []sql.IndexColumn {
+	desc, prefixes := i.Descending(), i.PrefixLengths()
+	columns := make([]sql.IndexColumn, len(i.Exprs))
+	for j, e := range i.Exprs {
+		columns[j] = sql.IndexColumn{Length: prefixes[j], Descending: desc[j]}
+		if gf, ok := e.(*expression.GetField); ok {
+			columns[j].Name = gf.Name()
+		} else {
+			columns[j].Expression = e
+		}
+	}
+	return columns
+}
+
 func (i *MergeableIndex) IsUnique() bool {
 	return i.Unique
 }
@@ -49,6 +96,9 @@ func (i *MergeableIndex) IndexType() string {
 	if len(i.DriverName) > 0 {
 		return i.DriverName
 	}
+	if len(i.Type) > 0 {
+		return i.Type
+	}
 	return "BTREE" // fake but so are you
 }
 
@@ -76,6 +126,17 @@ func (i *MergeableIndex) DescendRange(lessOrEqual, greaterThan []interface{}) (s
 	return &DescendIndexLookup{Gt: greaterThan, Lte: lessOrEqual, Index: i}, nil
 }
 
+func (i *MergeableIndex) Range(ranges ...sql.IndexRange) (sql.IndexLookup, error) {
+	return &RangeIndexLookup{Ranges: ranges, Index: i}, nil
+}
+
+func (i *MergeableIndex) Ordered(lookup sql.IndexLookup, descending bool) (sql.IndexLookup, error) {
+	if i.tree == nil {
+		return nil, nil
+	}
+	return &OrderedIndexLookup{Lookup: lookup, Descending: descending, Index: i}, nil
+}
+
 func (i *MergeableIndex) Not(keys ...interface{}) (sql.IndexLookup, error) {
 	lookup, err := i.Get(keys...)
 	if err != nil {
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// OrderedIndexLookup is a lookup of the rows of another lookup of its index, or of all the rows of the table if it's
+// nil, in the order of the index expressions or its reverse. All of them are returned for the first partition of the
+// table with rows, so that reading its partitions one after another reads them in order.
+type OrderedIndexLookup struct {
+	Lookup     sql.IndexLookup
+	Descending bool
+	Index      ExpressionsIndex
+}
+
+var _ memoryIndexLookup = (*OrderedIndexLookup)(nil)
+
+func (l *OrderedIndexLookup) String() string {
+	order := "ASC"
+	if l.Descending {
+		order = "DESC"
+	}
+	if l.Lookup == nil {
+		return order
+	}
+	return l.Lookup.String() + " " + order
+}
+
+func (l *OrderedIndexLookup) Values(p sql.Partition) (sql.IndexValueIter, error) {
+	return &indexValIter{
+		tbl:             l.Index.MemTable(),
+		partition:       p,
+		matchExpression: l.EvalExpression(),
+	}, nil
+}
+
+func (l *OrderedIndexLookup) EvalExpression() sql.Expression {
+	switch lookup := l.Lookup.(type) {
+	case memoryIndexLookup:
+		return lookup.EvalExpression()
+	case *UnmergeableIndexLookup:
+		return lookup.evalExpression()
+	default:
+		return expression.NewLiteral(true, sql.Boolean)
+	}
+}
+
+// treeRows implements the treeLookup interface.
+func (l *OrderedIndexLookup) treeRows(partition string) ([]sql.Row, bool, error) {
+	tree := indexTreeOf(l.Index)
+	if tree == nil {
+		return nil, false, nil
+	}
+
+	keys := l.Index.MemTable().keys
+	if partition != tree.firstPartition(keys) {
+		return nil, true, nil
+	}
+
+	var entries []*indexEntry
+	for _, key := range keys {
+		rows, err := l.partitionRows(tree, string(key))
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, row := range rows {
+			k, err := tree.key(row)
+			if err != nil {
+				return nil, false, err
+			}
+			entries = append(entries, &indexEntry{key: k, row: row})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if l.Descending {
+			return tree.less(entries[j], entries[i])
+		}
+		return tree.less(entries[i], entries[j])
+	})
+
+	rows := make([]sql.Row, len(entries))
+	for i, e := range entries {
+		rows[i] = e.row
+	}
+	return rows, true, nil
+}
+
+// partitionRows returns the rows of a partition that the lookup of the ordered one matches.
+func (l *OrderedIndexLookup) partitionRows(tree *indexTree, partition string) ([]sql.Row, error) {
+	if lookup, ok := l.Lookup.(treeLookup); ok {
+		rows, ok, err := lookup.treeRows(partition)
+		if err != nil || ok {
+			return rows, err
+		}
+	}
+	return tree.rows(partition, nil, nil, false, l.EvalExpression())
+}
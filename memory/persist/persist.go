@@ -0,0 +1,190 @@
+// Package persist makes the databases of the memory package survive restarts
+// of the process, saving snapshots of them to a directory and loading them
+// back when the process starts again.
+//
+// The snapshots are SQL dumps written by Engine.Dump, one file per database
+// named after it with the .sql extension, which are loaded with the importer
+// package. Since memory tables can't be locked, a snapshot taken while a
+// database is being written may have the changes of a statement in some of
+// its tables but not in others, and the auto increment counters of the tables
+// restored continue from their largest values.
+package persist
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/importer"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// fileExt is the extension of the files the snapshots of the databases are
+// saved in.
+const fileExt = ".sql"
+
+// Options configures a Store.
+type Options struct {
+	// Interval is the time between the snapshots the store saves in the
+	// background. If it's zero, snapshots are only saved by Save and Close.
+	Interval time.Duration
+	// NewContext returns the context snapshots are saved in the background
+	// with. sql.NewEmptyContext is used if it's nil, so that the views of the
+	// databases aren't saved unless it returns contexts with the view
+	// registry they're in.
+	NewContext func() *sql.Context
+}
+
+// Store saves snapshots of the memory databases of an engine to a directory.
+type Store struct {
+	dir    string
+	engine *sqle.Engine
+	opts   Options
+
+	// mu serializes the snapshots.
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Open loads the snapshots saved in the directory given, creating it if it
+// doesn't exist, and returns the store saving the memory databases of the
+// engine to it. The databases of the snapshots are added to the engine as
+// memory databases unless it has them already, and the views they have are
+// created in the view registry of the context given.
+func Open(ctx *sql.Context, e *sqle.Engine, dir string, opts Options) (*Store, error) {
+	if opts.NewContext == nil {
+		opts.NewContext = func() *sql.Context { return sql.NewEmptyContext() }
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	if err := load(ctx, e, dir); err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		dir:    dir,
+		engine: e,
+		opts:   opts,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	if opts.Interval > 0 {
+		go s.run()
+	} else {
+		close(s.done)
+	}
+
+	return s, nil
+}
+
+// load imports the snapshots saved in a directory into the engine.
+func load(ctx *sql.Context, e *sqle.Engine, dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+fileExt))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	current := ctx.GetCurrentDatabase()
+	defer ctx.SetCurrentDatabase(current)
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), fileExt)
+		if !e.Catalog.HasDB(name) {
+			e.Catalog.AddDatabase(memory.NewDatabase(name))
+		}
+
+		if _, err := importer.ImportFile(ctx, e, path, importer.Options{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Save(s.opts.NewContext()); err != nil {
+				logrus.Errorf("unable to save snapshot of memory databases: %s", err)
+			}
+		}
+	}
+}
+
+// Save saves a snapshot of every memory database of the engine, replacing
+// the ones saved before. Every file is written aside and then renamed, so
+// that a failed snapshot leaves the previous one of its database intact.
+func (s *Store) Save(ctx *sql.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, db := range s.engine.Catalog.AllDatabases() {
+		if _, ok := db.(*memory.Database); !ok {
+			continue
+		}
+
+		if err := s.save(ctx, db.Name()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// save saves the snapshot of a database.
+func (s *Store) save(ctx *sql.Context, name string) (err error) {
+	f, err := ioutil.TempFile(s.dir, name+fileExt+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	if err = s.engine.Dump(ctx, f, plan.DumpTarget{Database: name}); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), filepath.Join(s.dir, name+fileExt))
+}
+
+// Close stops saving snapshots in the background and saves a last one with
+// the context given.
+func (s *Store) Close(ctx *sql.Context) error {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+	return s.Save(ctx)
+}
@@ -0,0 +1,110 @@
+package persist_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/memory/persist"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+)
+
+func newEngine(databases ...sql.Database) *sqle.Engine {
+	catalog := sql.NewCatalog()
+	for _, db := range databases {
+		catalog.AddDatabase(db)
+	}
+	return sqle.New(catalog, analyzer.NewBuilder(catalog).Build(), nil)
+}
+
+func newContext() *sql.Context {
+	return sql.NewContext(context.Background(), sql.WithViewRegistry(sql.NewViewRegistry()))
+}
+
+func query(t *testing.T, e *sqle.Engine, ctx *sql.Context, q string) []sql.Row {
+	_, iter, err := e.Query(ctx, q)
+	require.NoError(t, err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(t, err)
+	return rows
+}
+
+func TestStore(t *testing.T) {
+	require := require.New(t)
+	dir, err := ioutil.TempDir("", "persist")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	e := newEngine(memory.NewDatabase("mydb"), memory.NewDatabase("other"))
+	ctx := newContext()
+	ctx.SetCurrentDatabase("mydb")
+
+	s, err := persist.Open(ctx, e, dir, persist.Options{})
+	require.NoError(err)
+
+	query(t, e, ctx, "CREATE TABLE t (i int primary key auto_increment, s varchar(10), KEY s (s))")
+	query(t, e, ctx, "INSERT INTO t (s) VALUES ('a'), ('b'), (NULL)")
+	query(t, e, ctx, "CREATE VIEW v AS SELECT s FROM t WHERE i > 1")
+	ctx.SetCurrentDatabase("other")
+	query(t, e, ctx, "CREATE TABLE u (i int)")
+	ctx.SetCurrentDatabase("mydb")
+	query(t, e, ctx, "INSERT INTO other.u VALUES (1)")
+	require.NoError(s.Close(ctx))
+
+	for _, name := range []string{"mydb.sql", "other.sql"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		require.NoError(err)
+	}
+
+	// The databases that don't exist are created as memory databases.
+	e = newEngine(memory.NewDatabase("mydb"))
+	ctx = newContext()
+	ctx.SetCurrentDatabase("mydb")
+	s, err = persist.Open(ctx, e, dir, persist.Options{})
+	require.NoError(err)
+	defer s.Close(ctx)
+
+	require.Equal("mydb", ctx.GetCurrentDatabase())
+	require.Equal([]sql.Row{{int32(1), "a"}, {int32(2), "b"}, {int32(3), nil}}, query(t, e, ctx, "SELECT * FROM t ORDER BY i"))
+	require.Equal([]sql.Row{{"b"}, {nil}}, query(t, e, ctx, "SELECT * FROM v ORDER BY 1 DESC"))
+	require.Equal([]sql.Row{{int32(1)}}, query(t, e, ctx, "SELECT * FROM other.u"))
+
+	query(t, e, ctx, "INSERT INTO t (s) VALUES ('c')")
+	require.Equal([]sql.Row{{int32(4)}}, query(t, e, ctx, "SELECT i FROM t WHERE s = 'c'"))
+}
+
+func TestStoreInterval(t *testing.T) {
+	require := require.New(t)
+	dir, err := ioutil.TempDir("", "persist")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	e := newEngine(memory.NewDatabase("mydb"))
+	ctx := newContext()
+	ctx.SetCurrentDatabase("mydb")
+	query(t, e, ctx, "CREATE TABLE t (i int)")
+	query(t, e, ctx, "INSERT INTO t VALUES (1)")
+
+	s, err := persist.Open(ctx, e, dir, persist.Options{Interval: 10 * time.Millisecond})
+	require.NoError(err)
+	defer s.Close(ctx)
+
+	require.Eventually(func() bool {
+		_, err := os.Stat(filepath.Join(dir, "mydb.sql"))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	e = newEngine()
+	ctx = newContext()
+	_, err = persist.Open(ctx, e, dir, persist.Options{})
+	require.NoError(err)
+	require.Equal([]sql.Row{{int32(1)}}, query(t, e, ctx, "SELECT * FROM mydb.t"))
+}
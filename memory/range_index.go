@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// RangeIndexLookup is a lookup of the rows whose value of every expression of its index is within a range.
+type RangeIndexLookup struct {
+	id     string
+	Ranges []sql.IndexRange
+	Index  ExpressionsIndex
+}
+
+var _ memoryIndexLookup = (*RangeIndexLookup)(nil)
+var _ sql.MergeableIndexLookup = (*RangeIndexLookup)(nil)
+
+func (l *RangeIndexLookup) ID() string     { return l.id }
+func (l *RangeIndexLookup) String() string { return l.id }
+
+func (l *RangeIndexLookup) Values(p sql.Partition) (sql.IndexValueIter, error) {
+	return &indexValIter{
+		tbl:             l.Index.MemTable(),
+		partition:       p,
+		matchExpression: l.EvalExpression(),
+	}, nil
+}
+
+func (l *RangeIndexLookup) EvalExpression() sql.Expression {
+	var columnExprs []sql.Expression
+	for i, indexExpr := range l.Index.ColumnExpressions() {
+		if i >= len(l.Ranges) {
+			break
+		}
+
+		r := l.Ranges[i]
+		if isPointRange(indexExpr.Type(), r) && r.Lower.Value == nil {
+			columnExprs = append(columnExprs, expression.NewIsNull(indexExpr))
+			continue
+		}
+
+		if r.Lower != nil {
+			lower, typ := getType(r.Lower.Value)
+			if r.Lower.Inclusive {
+				columnExprs = append(columnExprs, expression.NewGreaterThanOrEqual(indexExpr, expression.NewLiteral(lower, typ)))
+			} else {
+				columnExprs = append(columnExprs, expression.NewGreaterThan(indexExpr, expression.NewLiteral(lower, typ)))
+			}
+		}
+		if r.Upper != nil {
+			upper, typ := getType(r.Upper.Value)
+			if r.Upper.Inclusive {
+				columnExprs = append(columnExprs, expression.NewLessThanOrEqual(indexExpr, expression.NewLiteral(upper, typ)))
+			} else {
+				columnExprs = append(columnExprs, expression.NewLessThan(indexExpr, expression.NewLiteral(upper, typ)))
+			}
+		}
+	}
+
+	if len(columnExprs) == 0 {
+		return expression.NewLiteral(true, sql.Boolean)
+	}
+	return and(columnExprs...)
+}
+
+func (l *RangeIndexLookup) Indexes() []string {
+	return []string{l.id}
+}
+
+func (l *RangeIndexLookup) IsMergeable(lookup sql.IndexLookup) bool {
+	_, ok := lookup.(MergeableLookup)
+	return ok
+}
+
+func (l *RangeIndexLookup) Union(lookups ...sql.IndexLookup) (sql.IndexLookup, error) {
+	return union(l.Index, l, lookups...), nil
+}
+
+func (l *RangeIndexLookup) Intersection(lookups ...sql.IndexLookup) (sql.IndexLookup, error) {
+	return intersection(l.Index, l, lookups...), nil
+}
+
+// treeRows implements the treeLookup interface.
+func (l *RangeIndexLookup) treeRows(partition string) ([]sql.Row, bool, error) {
+	tree := indexTreeOf(l.Index)
+	if tree == nil {
+		return nil, false, nil
+	}
+
+	from, to := tree.rangeBounds(l.Ranges)
+	rows, err := tree.rows(partition, from, to, false, l.EvalExpression())
+	return rows, true, err
+}
+
+// isPointRange returns whether the range given of values of the type given has a single value.
+func isPointRange(typ sql.Type, r sql.IndexRange) bool {
+	return r.Lower != nil && r.Upper != nil && r.Lower.Inclusive && r.Upper.Inclusive &&
+		compareIndexValues(typ, r.Lower.Value, r.Upper.Value) == 0
+}
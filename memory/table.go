@@ -5,9 +5,12 @@ import (
 	"encoding/gob"
 	"fmt"
 	"io"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	errors "gopkg.in/src-d/go-errors.v1"
 
@@ -23,6 +26,8 @@ type Table struct {
 	columns          []int
 	indexes          map[string]sql.Index
 	foreignKeys      []sql.ForeignKeyConstraint
+	checks           []sql.CheckDefinition
+	options          sql.TableOptions
 	pkIndexesEnabled bool
 
 	// Data storage
@@ -38,20 +43,47 @@ type Table struct {
 	// AUTO_INCREMENT bookkeeping
 	autoIncVal interface{}
 	autoColIdx int
+
+	// Concurrency control, shared by the copies of the table with other lookups, filters or projections. The id
+	// orders the locks of tables, given when first needed, seq is the sequence number of the last change to the rows,
+	// history are the rows the table had before it that open transactions see, and transactions are the ones of the
+	// database of the table, none if it has no database.
+	mu           *sync.RWMutex
+	id           uint64
+	seq          uint64
+	history      []*tableVersion
+	transactions *transactions
+
+	// Transaction bookkeeping, for the copies of tables that transactions read and write, which share the rows of the
+	// table until they're first changed
+	base   *Table
+	tx     *transaction
+	shared bool
 }
 
 var _ sql.Table = (*Table)(nil)
 var _ sql.InsertableTable = (*Table)(nil)
 var _ sql.UpdatableTable = (*Table)(nil)
 var _ sql.DeletableTable = (*Table)(nil)
+var _ sql.RangeDeletableTable = (*Table)(nil)
+var _ sql.RangeUpdatableTable = (*Table)(nil)
+var _ sql.CopyableTable = (*Table)(nil)
 var _ sql.ReplaceableTable = (*Table)(nil)
+var _ sql.UpsertableTable = (*Table)(nil)
 var _ sql.DriverIndexableTable = (*Table)(nil)
 var _ sql.AlterableTable = (*Table)(nil)
 var _ sql.IndexAlterableTable = (*Table)(nil)
+var _ sql.IndexVisibilityAlterableTable = (*Table)(nil)
 var _ sql.IndexedTable = (*Table)(nil)
 var _ sql.ForeignKeyAlterableTable = (*Table)(nil)
+var _ sql.CrossDatabaseForeignKeyTable = (*Table)(nil)
 var _ sql.ForeignKeyTable = (*Table)(nil)
+var _ sql.CheckAlterableTable = (*Table)(nil)
+var _ sql.CheckTable = (*Table)(nil)
 var _ sql.AutoIncrementTable = (*Table)(nil)
+var _ sql.StatisticsTable = (*Table)(nil)
+var _ sql.TableOptionsTable = (*Table)(nil)
+var _ sql.BulkRowInserter = (*tableEditor)(nil)
 
 // PushdownTable is an extension to Table that implements sql.FilteredTable and sql.ProjectedTable. This is mostly just
 // for demonstration and testing purposes -- these new interfaces do not significantly speed up query execution.
@@ -76,7 +108,8 @@ func NewPushdownTable(name string, schema sql.Schema) *PushdownTable {
 	return NewPartitionedPushdownTable(name, schema, 0)
 }
 
-// NewPartitionedTable creates a new Table with the given name, schema and number of partitions.
+// NewPartitionedTable creates a new Table with the given name, schema and number of partitions. The rows of tables with
+// a primary key go to the partition it hashes to, and the ones of the others to every partition in turn.
 func NewPartitionedTable(name string, schema sql.Schema, numPartitions int) *Table {
 	var keys [][]byte
 	var partitions = map[string][]sql.Row{}
@@ -108,6 +141,7 @@ func NewPartitionedTable(name string, schema sql.Schema, numPartitions int) *Tab
 		keys:       keys,
 		autoIncVal: autoIncVal,
 		autoColIdx: autoIncIdx,
+		mu:         &sync.RWMutex{},
 	}
 }
 
@@ -132,6 +166,7 @@ func NewPartitionedPushdownTable(name string, schema sql.Schema, numPartitions i
 			schema:     schema,
 			partitions: partitions,
 			keys:       keys,
+			mu:         &sync.RWMutex{},
 		},
 	}
 }
@@ -148,98 +183,158 @@ func (t *Table) Schema() sql.Schema {
 
 // Partitions implements the sql.Table interface.
 func (t *Table) Partitions(ctx *sql.Context) (sql.PartitionIter, error) {
-	var keys [][]byte
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var partitions []*partition
 	for _, k := range t.keys {
 		if rows, ok := t.partitions[string(k)]; ok && len(rows) > 0 {
-			keys = append(keys, k)
+			partitions = append(partitions, &partition{key: k, rows: rows})
 		}
 	}
-	return &partitionIter{keys: keys}, nil
+	return &partitionIter{partitions: partitions}, nil
 }
 
 // PartitionCount implements the sql.PartitionCounter interface.
 func (t *Table) PartitionCount(ctx *sql.Context) (int64, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
 	return int64(len(t.partitions)), nil
 }
 
 // PartitionRows implements the sql.PartitionRows interface.
 func (t *Table) PartitionRows(ctx *sql.Context, partition sql.Partition) (sql.RowIter, error) {
-	rows, ok := t.partitions[string(partition.Key())]
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rows, ok := t.partitionRows(partition)
 	if !ok {
 		return nil, fmt.Errorf(
 			"partition not found: %q", partition.Key(),
 		)
 	}
 
+	if l, ok := t.lookup.(treeLookup); ok {
+		rows, ok, err := l.treeRows(string(partition.Key()))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &tableIter{rows: rows}, nil
+		}
+	}
+
 	var values sql.IndexValueIter
 	if t.lookup != nil {
 		var err error
-		values, err = t.lookup.(sql.DriverIndexLookup).Values(partition)
+		values, err = t.lookupValues(partition)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// The slice could be altered by other operations taking place during iteration (such as deletion or insertion), so
-	// make a copy of the values as they exist when execution begins.
-	rowsCopy := make([]sql.Row, len(rows))
-	copy(rowsCopy, rows)
-
+	// The slices of rows are never changed in place, so the ones read aren't changed by other operations taking place
+	// during iteration (such as deletion or insertion).
 	return &tableIter{
-		rows:        rowsCopy,
+		rows:        rows,
 		indexValues: values,
 	}, nil
 }
 
 func (t *PushdownTable) PartitionRows(ctx *sql.Context, partition sql.Partition) (sql.RowIter, error) {
-	rows, ok := t.partitions[string(partition.Key())]
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rows, ok := t.partitionRows(partition)
 	if !ok {
 		return nil, fmt.Errorf(
 			"partition not found: %q", partition.Key(),
 		)
 	}
 
+	if l, ok := t.lookup.(treeLookup); ok {
+		rows, ok, err := l.treeRows(string(partition.Key()))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &tableIter{
+				rows:    rows,
+				columns: t.columns,
+				filters: t.filters,
+			}, nil
+		}
+	}
+
 	var values sql.IndexValueIter
 	if t.lookup != nil {
 		var err error
-		values, err = t.lookup.(sql.DriverIndexLookup).Values(partition)
+		values, err = t.Table.lookupValues(partition)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// The slice could be altered by other operations taking place during iteration (such as deletion or insertion), so
-	// make a copy of the values as they exist when execution begins.
-	rowsCopy := make([]sql.Row, len(rows))
-	copy(rowsCopy, rows)
-
+	// The slices of rows are never changed in place, so the ones read aren't changed by other operations taking place
+	// during iteration (such as deletion or insertion).
 	return &tableIter{
-		rows:        rowsCopy,
+		rows:        rows,
 		columns:     t.columns,
 		filters:     t.filters,
 		indexValues: values,
 	}, nil
 }
 
+// partitionRows returns the rows of a partition that the scans of the table read: the ones it had when it was listed,
+// or the ones it has if the table has a lookup, whose values are positions in them.
+func (t *Table) partitionRows(p sql.Partition) ([]sql.Row, bool) {
+	if p, ok := p.(*partition); ok && p.rows != nil && t.lookup == nil {
+		return p.rows, true
+	}
+
+	rows, ok := t.partitions[string(p.Key())]
+	return rows, ok
+}
+
+// lookupValues returns the positions of the rows of a partition that the lookup of the table matches, which are
+// found while the table is locked so that they're the ones of the rows the table has then.
+func (t *Table) lookupValues(partition sql.Partition) (sql.IndexValueIter, error) {
+	values, err := t.lookup.(sql.DriverIndexLookup).Values(partition)
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok := values.(*indexValIter); ok {
+		if err := v.initValues(); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// partition is a partition of a table, with the rows it had when the partitions were listed, which the scans of the
+// table read so that they don't see the rows inserted while they run, by INSERT INTO ... SELECT from the same table
+// for instance. The slices of rows of tables are never changed in place for this reason.
 type partition struct {
-	key []byte
+	key  []byte
+	rows []sql.Row
 }
 
 func (p *partition) Key() []byte { return p.key }
 
 type partitionIter struct {
-	keys [][]byte
-	pos  int
+	partitions []*partition
+	pos        int
 }
 
 func (p *partitionIter) Next() (sql.Partition, error) {
-	if p.pos >= len(p.keys) {
+	if p.pos >= len(p.partitions) {
 		return nil, io.EOF
 	}
 
-	key := p.keys[p.pos]
+	partition := p.partitions[p.pos]
 	p.pos++
-	return &partition{key}, nil
+	return partition, nil
 }
 
 func (p *partitionIter) Close() error { return nil }
@@ -357,6 +452,7 @@ var _ sql.RowReplacer = (*tableEditor)(nil)
 var _ sql.RowUpdater = (*tableEditor)(nil)
 var _ sql.RowInserter = (*tableEditor)(nil)
 var _ sql.RowDeleter = (*tableEditor)(nil)
+var _ sql.RowUpserter = (*tableEditor)(nil)
 
 func (t tableEditor) Close(*sql.Context) error {
 	// TODO: it would be nice to apply all pending updates here at once, rather than directly in the Insert / Update
@@ -376,6 +472,10 @@ func (t *Table) Replacer(*sql.Context) sql.RowReplacer {
 	return &tableEditor{t}
 }
 
+func (t *Table) Upserter(*sql.Context) sql.RowUpserter {
+	return &tableEditor{t}
+}
+
 func (t *Table) Deleter(*sql.Context) sql.RowDeleter {
 	return &tableEditor{t}
 }
@@ -395,6 +495,43 @@ func (t *Table) Insert(ctx *sql.Context, row sql.Row) error {
 
 // Insert a new row into the table.
 func (t *tableEditor) Insert(ctx *sql.Context, row sql.Row) error {
+	t.table.mu.Lock()
+	defer t.table.mu.Unlock()
+
+	row = row.Copy()
+	if err := t.insert(row); err != nil {
+		return err
+	}
+	t.table.record(tableOp{new: row})
+	return nil
+}
+
+// StatementBegin implements the sql.BulkRowInserter interface.
+func (t *tableEditor) StatementBegin(*sql.Context) {}
+
+// InsertBatch implements the sql.BulkRowInserter interface. The table is locked once for all the rows.
+func (t *tableEditor) InsertBatch(ctx *sql.Context, rows []sql.Row) error {
+	t.table.mu.Lock()
+	defer t.table.mu.Unlock()
+
+	for _, row := range rows {
+		row = row.Copy()
+		if err := t.insert(row); err != nil {
+			return err
+		}
+		t.table.record(tableOp{new: row})
+	}
+	return nil
+}
+
+// StatementComplete implements the sql.BulkRowInserter interface.
+func (t *tableEditor) StatementComplete(*sql.Context) error {
+	return nil
+}
+
+// insert inserts the row given, which the table keeps rather than a copy of it.
+func (t *tableEditor) insert(row sql.Row) error {
+	t.table.own()
 	if err := checkRow(t.table.schema, row); err != nil {
 		return err
 	}
@@ -402,27 +539,40 @@ func (t *tableEditor) Insert(ctx *sql.Context, row sql.Row) error {
 	if err := t.checkUniquenessConstraints(row); err != nil {
 		return err
 	}
+	if err := t.table.checkUniqueIndexes(row, nil); err != nil {
+		return err
+	}
 
-	key := string(t.table.keys[t.table.insert])
-	t.table.insert++
-	if t.table.insert == len(t.table.keys) {
-		t.table.insert = 0
+	key, err := t.partitionKey(row)
+	if err != nil {
+		return err
 	}
 
+	t.table.preserve()
 	t.table.partitions[key] = append(t.table.partitions[key], row)
+	for _, tree := range t.table.indexTrees() {
+		if err := tree.insert(key, row); err != nil {
+			return err
+		}
+	}
 
 	idx := t.table.autoColIdx
 	if idx >= 0 {
-		// autoIncVal = max(autoIncVal, insertVal)
+		counter := t.table.autoIncTable()
+		if counter != t.table {
+			counter.mu.Lock()
+			defer counter.mu.Unlock()
+		}
+
+		// autoIncVal = max(autoIncVal, insertVal + 1)
 		autoCol := t.table.schema[idx]
-		cmp, err := autoCol.Type.Compare(row[idx], t.table.autoIncVal)
+		cmp, err := autoCol.Type.Compare(row[idx], counter.autoIncVal)
 		if err != nil {
 			return err
 		}
-		if cmp > 0 {
-			t.table.autoIncVal = row[idx]
+		if cmp >= 0 {
+			counter.autoIncVal = increment(row[idx])
 		}
-		t.table.autoIncVal = increment(t.table.autoIncVal)
 	}
 
 	return nil
@@ -460,93 +610,420 @@ func increment(v interface{}) interface{} {
 
 // Delete the given row from the table.
 func (t *tableEditor) Delete(ctx *sql.Context, row sql.Row) error {
-	if err := checkRow(t.table.schema, row); err != nil {
+	t.table.mu.Lock()
+	defer t.table.mu.Unlock()
+
+	deleted, err := t.delete(row)
+	if err != nil {
 		return err
 	}
+	t.table.record(tableOp{old: deleted})
+	return nil
+}
 
-	matches := false
-	for partitionIndex, partition := range t.table.partitions {
-		for partitionRowIndex, partitionRow := range partition {
-			matches = true
+// delete deletes the row given, or the one with its primary key, and returns the row deleted.
+func (t *tableEditor) delete(row sql.Row) (sql.Row, error) {
+	t.table.own()
+	if err := checkRow(t.table.schema, row); err != nil {
+		return nil, err
+	}
 
-			// For DELETE queries, we will have previously selected the row in order to delete it. For REPLACE, we will just
-			// have the row to be replaced, so we need to consider primary key information.
-			pkColIdxes := t.pkColumnIndexes()
-			if len(pkColIdxes) > 0 {
-				if columnsMatch(pkColIdxes, partitionRow, row) {
-					t.table.partitions[partitionIndex] = append(partition[:partitionRowIndex], partition[partitionRowIndex+1:]...)
-					break
-				}
+	t.table.preserve()
+
+	// For DELETE queries, we will have previously selected the row in order to delete it. For REPLACE, we will just
+	// have the row to be replaced, so we need to consider primary key information.
+	key, i, ok := t.table.position(row)
+	if !ok {
+		key, i, ok = t.find(row, t.pkColumnIndexes())
+	}
+	if !ok {
+		return nil, sql.ErrDeleteRowNotFound.New()
+	}
+
+	partition := t.table.partitions[key]
+	deleted := partition[i]
+	t.table.partitions[key] = removeRow(partition, i)
+
+	for _, tree := range t.table.indexTrees() {
+		if err := tree.remove(key, deleted); err != nil {
+			return nil, err
+		}
+	}
+
+	return deleted, nil
+}
+
+// find returns the partition and the position in it of a row with the primary key columns given of the row given, or
+// with all its values, and whether the table has one.
+func (t *tableEditor) find(row sql.Row, pkColIdxes []int) (string, int, bool) {
+	for key, partition := range t.table.partitions {
+		for i, partitionRow := range partition {
+			if len(pkColIdxes) > 0 && columnsMatch(pkColIdxes, partitionRow, row) {
+				return key, i, true
 			}
 
-			// If we had no primary key match (or have no primary key), check each row for a total match
+			matches := true
 			for rIndex, val := range row {
-				if val != partitionRow[rIndex] {
+				if !valuesEqual(val, partitionRow[rIndex]) {
 					matches = false
 					break
 				}
 			}
-
 			if matches {
-				t.table.partitions[partitionIndex] = append(partition[:partitionRowIndex], partition[partitionRowIndex+1:]...)
-				break
+				return key, i, true
 			}
 		}
-		if matches {
-			break
-		}
 	}
+	return "", 0, false
+}
 
-	if !matches {
-		return sql.ErrDeleteRowNotFound.New()
-	}
+func (t *tableEditor) Update(ctx *sql.Context, oldRow sql.Row, newRow sql.Row) error {
+	t.table.mu.Lock()
+	defer t.table.mu.Unlock()
 
+	newRow = newRow.Copy()
+	updated, err := t.update(oldRow, newRow)
+	if err != nil {
+		return err
+	}
+	if updated != nil {
+		t.table.record(tableOp{old: updated, new: newRow})
+	}
 	return nil
 }
 
-func (t *tableEditor) Update(ctx *sql.Context, oldRow sql.Row, newRow sql.Row) error {
+// update replaces the row given with the new one, which the table keeps rather than a copy of it, and returns the row
+// replaced, nil if the table didn't have it.
+func (t *tableEditor) update(oldRow sql.Row, newRow sql.Row) (sql.Row, error) {
+	t.table.own()
 	if err := checkRow(t.table.schema, oldRow); err != nil {
-		return err
+		return nil, err
 	}
 	if err := checkRow(t.table.schema, newRow); err != nil {
-		return err
+		return nil, err
 	}
 
 	if t.pkColsDiffer(oldRow, newRow) {
 		if err := t.checkUniquenessConstraints(newRow); err != nil {
-			return err
+			return nil, err
 		}
 	}
+	if err := t.table.checkUniqueIndexes(newRow, oldRow); err != nil {
+		return nil, err
+	}
 
-	matches := false
-	for partitionIndex, partition := range t.table.partitions {
-		for partitionRowIndex, partitionRow := range partition {
-			matches = true
-			for rIndex, val := range oldRow {
-				if val != partitionRow[rIndex] {
-					matches = false
-					break
-				}
+	t.table.preserve()
+
+	partitionIndex, partitionRowIndex, ok := t.table.position(oldRow)
+	if !ok {
+		partitionIndex, partitionRowIndex, ok = t.find(oldRow, nil)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	partition := t.table.partitions[partitionIndex]
+	partitionRow := partition[partitionRowIndex]
+
+	// The rows whose primary key changed move to the partition it hashes to.
+	key := partitionIndex
+	if t.pkColsDiffer(oldRow, newRow) {
+		var err error
+		if key, err = t.partitionKey(newRow); err != nil {
+			return nil, err
+		}
+	}
+
+	if key == partitionIndex {
+		rows := append([]sql.Row(nil), partition...)
+		rows[partitionRowIndex] = newRow
+		t.table.partitions[partitionIndex] = rows
+	} else {
+		t.table.partitions[partitionIndex] = removeRow(partition, partitionRowIndex)
+		t.table.partitions[key] = append(t.table.partitions[key], newRow)
+	}
+
+	for _, tree := range t.table.indexTrees() {
+		if err := tree.remove(partitionIndex, partitionRow); err != nil {
+			return nil, err
+		}
+		if err := tree.insert(key, newRow); err != nil {
+			return nil, err
+		}
+	}
+
+	return partitionRow, nil
+}
+
+// Replace implements the sql.RowUpserter interface. The row deleted is restored if the new one can't be inserted.
+func (t *tableEditor) Replace(ctx *sql.Context, row sql.Row) (bool, error) {
+	t.table.mu.Lock()
+	defer t.table.mu.Unlock()
+
+	deleted, err := t.delete(row)
+	if err != nil && !sql.ErrDeleteRowNotFound.Is(err) {
+		return false, err
+	}
+
+	row = row.Copy()
+	if err := t.insert(row); err != nil {
+		if deleted != nil {
+			_ = t.insert(deleted)
+		}
+		return false, err
+	}
+
+	if deleted != nil {
+		t.table.record(tableOp{old: deleted})
+	}
+	t.table.record(tableOp{new: row})
+	return deleted != nil, nil
+}
+
+// Upsert implements the sql.RowUpserter interface. The table isn't locked while the update function computes the new
+// row, as its expressions may read it.
+func (t *tableEditor) Upsert(ctx *sql.Context, row sql.Row, update func(sql.Row) (sql.Row, error)) (sql.Row, sql.Row, error) {
+	oldRow, err := t.insertOrFind(row)
+	if err != nil || oldRow == nil {
+		return nil, row, err
+	}
+
+	newRow, err := update(oldRow)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := t.Update(ctx, oldRow, newRow); err != nil {
+		return nil, nil, err
+	}
+	return oldRow, newRow, nil
+}
+
+// insertOrFind inserts the row given, unless the table has a row with its primary key, which it returns instead.
+func (t *tableEditor) insertOrFind(row sql.Row) (sql.Row, error) {
+	t.table.mu.Lock()
+	defer t.table.mu.Unlock()
+
+	row = row.Copy()
+	err := t.insert(row)
+	if err == nil {
+		t.table.record(tableOp{new: row})
+		return nil, nil
+	}
+
+	pkColIdxes := t.pkColumnIndexes()
+	if !sql.ErrUniqueKeyViolation.Is(err) || len(pkColIdxes) == 0 {
+		return nil, err
+	}
+	for _, partition := range t.table.partitions {
+		for _, partitionRow := range partition {
+			if columnsMatch(pkColIdxes, partitionRow, row) {
+				return partitionRow, nil
 			}
-			if matches {
-				t.table.partitions[partitionIndex][partitionRowIndex] = newRow
+		}
+	}
+
+	// The duplicate key is of a unique index
+	return nil, err
+}
+
+// DeleteRange implements the sql.RangeDeletableTable interface.
+func (t *Table) DeleteRange(ctx *sql.Context, filter sql.Expression) (int, error) {
+	return t.deleteRange(ctx, []sql.Expression{filter})
+}
+
+// DeleteRange implements the sql.RangeDeletableTable interface. The rows deleted also satisfy the filters of the table.
+func (t *PushdownTable) DeleteRange(ctx *sql.Context, filter sql.Expression) (int, error) {
+	return t.Table.deleteRange(ctx, append([]sql.Expression{filter}, t.filters...))
+}
+
+func (t *Table) deleteRange(ctx *sql.Context, filters []sql.Expression) (int, error) {
+	rows, err := t.rangeRows(ctx, filters)
+	if err != nil {
+		return 0, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	editor := &tableEditor{t}
+	for _, row := range rows {
+		deleted, err := editor.delete(row)
+		if err != nil {
+			return 0, err
+		}
+		t.record(tableOp{old: deleted})
+	}
+	return len(rows), nil
+}
+
+// UpdateRange implements the sql.RangeUpdatableTable interface.
+func (t *Table) UpdateRange(ctx *sql.Context, filter sql.Expression, update func(sql.Row) (sql.Row, error)) (int, int, error) {
+	return t.updateRange(ctx, []sql.Expression{filter}, update)
+}
+
+// UpdateRange implements the sql.RangeUpdatableTable interface. The rows updated also satisfy the filters of the table.
+func (t *PushdownTable) UpdateRange(ctx *sql.Context, filter sql.Expression, update func(sql.Row) (sql.Row, error)) (int, int, error) {
+	return t.Table.updateRange(ctx, append([]sql.Expression{filter}, t.filters...), update)
+}
+
+func (t *Table) updateRange(ctx *sql.Context, filters []sql.Expression, update func(sql.Row) (sql.Row, error)) (int, int, error) {
+	rows, err := t.rangeRows(ctx, filters)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// The new rows are computed before any is changed, like the ones of updates reading the rows one at a time.
+	newRows := make([]sql.Row, len(rows))
+	for i, row := range rows {
+		if newRows[i], err = update(row); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	editor := &tableEditor{t}
+	var updated int
+	for i, row := range rows {
+		if equals, err := row.Equals(newRows[i], t.schema); err != nil {
+			return 0, 0, err
+		} else if equals {
+			continue
+		}
+
+		newRow := newRows[i].Copy()
+		replaced, err := editor.update(row, newRow)
+		if err != nil {
+			return 0, 0, err
+		}
+		if replaced != nil {
+			t.record(tableOp{old: replaced, new: newRow})
+			updated++
+		}
+	}
+	return len(rows), updated, nil
+}
+
+// CopyRows implements the sql.CopyableTable interface. It copies the rows of the other tables of the engine.
+func (t *Table) CopyRows(ctx *sql.Context, source sql.Table, filter sql.Expression) (int, bool, error) {
+	var rows []sql.Row
+	var err error
+	switch source := source.(type) {
+	case *Table:
+		rows, err = source.rangeRows(ctx, []sql.Expression{filter})
+	case *PushdownTable:
+		if len(source.projection) > 0 {
+			return 0, false, nil
+		}
+		rows, err = source.Table.rangeRows(ctx, append([]sql.Expression{filter}, source.filters...))
+	default:
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	editor := &tableEditor{t}
+	for i, row := range rows {
+		row = row.Copy()
+		if err := editor.insert(row); err != nil {
+			return i, true, err
+		}
+		t.record(tableOp{new: row})
+	}
+	return len(rows), true, nil
+}
+
+// rangeRows returns the rows of the table, or of its lookup if it has one, that satisfy all the filters given.
+func (t *Table) rangeRows(ctx *sql.Context, filters []sql.Expression) ([]sql.Row, error) {
+	partitions, err := t.Partitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sql.RowIterToRows(sql.NewTableRowIter(ctx, t, partitions))
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []sql.Row
+	for _, row := range rows {
+		ok := true
+		for _, filter := range filters {
+			if filter == nil {
+				continue
+			}
+			if ok, err = sql.EvaluateCondition(ctx, filter, row); err != nil {
+				return nil, err
+			} else if !ok {
 				break
 			}
 		}
-		if matches {
-			break
+		if ok {
+			matched = append(matched, row)
 		}
 	}
-
-	return nil
+	return matched, nil
 }
 
-// SetAutoIncrementValue sets a new AUTO_INCREMENT value
+// SetAutoIncrementValue sets a new AUTO_INCREMENT value. Like in MySQL, it can't be set to a value already in use, so
+// values not greater than the ones in the table set it to the greatest of them plus one.
 func (t *tableEditor) SetAutoIncrementValue(ctx *sql.Context, val interface{}) error {
-	t.table.autoIncVal = val
+	t.table.mu.Lock()
+	defer t.table.mu.Unlock()
+
+	idx := t.table.autoColIdx
+	if idx < 0 {
+		t.table.setAutoIncVal(val)
+		return nil
+	}
+
+	autoCol := t.table.schema[idx]
+	val, err := autoCol.Type.Convert(val)
+	if err != nil {
+		return err
+	}
+
+	for _, partition := range t.table.partitions {
+		for _, row := range partition {
+			cmp, err := autoCol.Type.Compare(row[idx], val)
+			if err != nil {
+				return err
+			}
+			if cmp >= 0 {
+				val = increment(row[idx])
+			}
+		}
+	}
+
+	t.table.setAutoIncVal(val)
 	return nil
 }
 
+// autoIncTable returns the table keeping the AUTO_INCREMENT counter of the table: the base of the copies that
+// transactions read and write, as the counter isn't transactional, like in MySQL, so that concurrent transactions
+// don't insert the same values.
+func (t *Table) autoIncTable() *Table {
+	if t.base != nil {
+		return t.base
+	}
+	return t
+}
+
+// setAutoIncVal sets the AUTO_INCREMENT counter of the table, which must be locked.
+func (t *Table) setAutoIncVal(val interface{}) {
+	counter := t.autoIncTable()
+	if counter != t {
+		counter.mu.Lock()
+		defer counter.mu.Unlock()
+	}
+	counter.autoIncVal = val
+}
+
 func (t *tableEditor) checkUniquenessConstraints(row sql.Row) error {
 	pkColIdxes := t.pkColumnIndexes()
 
@@ -554,7 +1031,7 @@ func (t *tableEditor) checkUniquenessConstraints(row sql.Row) error {
 		for _, partition := range t.table.partitions {
 			for _, partitionRow := range partition {
 				if columnsMatch(pkColIdxes, partitionRow, row) {
-					return sql.ErrUniqueKeyViolation.New(pkColIdxes)
+					return sql.ErrUniqueKeyViolation.New("PRIMARY")
 				}
 			}
 		}
@@ -563,6 +1040,24 @@ func (t *tableEditor) checkUniquenessConstraints(row sql.Row) error {
 	return nil
 }
 
+// checkUniqueIndexes returns an error if a row other than except has the key of the row given in a unique index.
+func (t *Table) checkUniqueIndexes(row, except sql.Row) error {
+	for _, tree := range t.indexTrees() {
+		if !tree.index.Unique {
+			continue
+		}
+
+		duplicate, err := tree.duplicate(row, except)
+		if err != nil {
+			return err
+		}
+		if duplicate {
+			return sql.ErrUniqueKeyViolation.New(tree.index.ID())
+		}
+	}
+	return nil
+}
+
 func (t *tableEditor) pkColumnIndexes() []int {
 	var pkColIdxes []int
 	for _, column := range t.table.schema {
@@ -574,6 +1069,35 @@ func (t *tableEditor) pkColumnIndexes() []int {
 	return pkColIdxes
 }
 
+// removeRow returns a copy of the rows given without the one at the position given.
+func removeRow(rows []sql.Row, i int) []sql.Row {
+	removed := make([]sql.Row, 0, len(rows)-1)
+	removed = append(removed, rows[:i]...)
+	return append(removed, rows[i+1:]...)
+}
+
+// partitionKey returns the key of the partition a new row goes to: the one its primary key hashes to, if the table has
+// one, or the next one in turn otherwise.
+func (t *tableEditor) partitionKey(row sql.Row) (string, error) {
+	pkColIdxes := t.pkColumnIndexes()
+	if len(pkColIdxes) == 0 || len(t.table.keys) == 1 {
+		key := string(t.table.keys[t.table.insert])
+		t.table.insert = (t.table.insert + 1) % len(t.table.keys)
+		return key, nil
+	}
+
+	pk := make(sql.Row, len(pkColIdxes))
+	for i, idx := range pkColIdxes {
+		pk[i] = row[idx]
+	}
+
+	hash, err := sql.HashOf(pk)
+	if err != nil {
+		return "", err
+	}
+	return string(t.table.keys[hash%uint64(len(t.table.keys))]), nil
+}
+
 func (t *tableEditor) pkColsDiffer(row, row2 sql.Row) bool {
 	pkColIdxes := t.pkColumnIndexes()
 	return !columnsMatch(pkColIdxes, row, row2)
@@ -582,21 +1106,55 @@ func (t *tableEditor) pkColsDiffer(row, row2 sql.Row) bool {
 // Returns whether the values for the columns given match in the two rows provided
 func columnsMatch(colIndexes []int, row sql.Row, row2 sql.Row) bool {
 	for _, i := range colIndexes {
-		if row[i] != row2[i] {
+		if !valuesEqual(row[i], row2[i]) {
 			return false
 		}
 	}
 	return true
 }
 
+// valuesEqual returns whether two values of rows are equal. The values of uncomparable types, such as the []byte of
+// BLOB and JSON columns or the documents JSON columns are given, which == panics on, are compared deeply.
+func valuesEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case []byte:
+		b, ok := b.([]byte)
+		return ok && bytes.Equal(a, b)
+	default:
+		if !reflect.TypeOf(a).Comparable() {
+			return reflect.DeepEqual(a, b)
+		}
+		return a == b
+	}
+}
+
 // GetAutoIncrementValue gets the last AUTO_INCREMENT value
 func (t *Table) GetAutoIncrementValue(*sql.Context) (interface{}, error) {
-	return t.autoIncVal, nil
+	counter := t.autoIncTable()
+	counter.mu.RLock()
+	defer counter.mu.RUnlock()
+	return counter.autoIncVal, nil
 }
 
 func (t *Table) AddColumn(ctx *sql.Context, column *sql.Column, order *sql.ColumnOrder) error {
+	if t.base != nil {
+		if err := commitTransaction(ctx); err != nil {
+			return err
+		}
+		return t.base.AddColumn(ctx, column, order)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.changeSchema()
+
 	newColIdx := t.addColumnToSchema(ctx, column, order)
-	return t.insertValueInRows(ctx, newColIdx, column.Default)
+	if err := t.insertValueInRows(ctx, newColIdx, column.Default); err != nil {
+		return err
+	}
+	return t.reindex("", "")
 }
 
 // addColumnToSchema adds the given column to the schema and returns the new index
@@ -659,6 +1217,17 @@ func (t *Table) insertValueInRows(ctx *sql.Context, idx int, colDefault *sql.Col
 }
 
 func (t *Table) DropColumn(ctx *sql.Context, columnName string) error {
+	if t.base != nil {
+		if err := commitTransaction(ctx); err != nil {
+			return err
+		}
+		return t.base.DropColumn(ctx, columnName)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.changeSchema()
+
 	droppedCol := t.dropColumnFromSchema(ctx, columnName)
 	for k, p := range t.partitions {
 		newP := make([]sql.Row, len(p))
@@ -670,7 +1239,7 @@ func (t *Table) DropColumn(ctx *sql.Context, columnName string) error {
 		}
 		t.partitions[k] = newP
 	}
-	return nil
+	return t.reindex("", "")
 }
 
 // dropColumnFromSchema drops the given column name from the schema and returns its old index.
@@ -691,6 +1260,17 @@ func (t *Table) dropColumnFromSchema(ctx *sql.Context, columnName string) int {
 }
 
 func (t *Table) ModifyColumn(ctx *sql.Context, columnName string, column *sql.Column, order *sql.ColumnOrder) error {
+	if t.base != nil {
+		if err := commitTransaction(ctx); err != nil {
+			return err
+		}
+		return t.base.ModifyColumn(ctx, columnName, column, order)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.changeSchema()
+
 	oldIdx := -1
 	newIdx := 0
 	for i, col := range t.schema {
@@ -739,6 +1319,89 @@ func (t *Table) ModifyColumn(ctx *sql.Context, columnName string, column *sql.Co
 
 	_ = t.dropColumnFromSchema(ctx, columnName)
 	t.addColumnToSchema(ctx, column, order)
+	return t.reindex(columnName, column.Name)
+}
+
+// indexTrees returns the trees of the indexes of the table, ordered by the names of the indexes.
+func (t *Table) indexTrees() []*indexTree {
+	if len(t.indexes) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(t.indexes))
+	for name := range t.indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var trees []*indexTree
+	for _, name := range names {
+		if index, ok := t.indexes[name].(ExpressionsIndex); ok {
+			if tree := indexTreeOf(index); tree != nil {
+				trees = append(trees, tree)
+			}
+		}
+	}
+	return trees
+}
+
+// reindex points the expressions of the indexes of the table to the columns they index after its schema changed,
+// renaming the column from, if given, to the name to, and rebuilds their trees. The expressions of columns no longer
+// in the table are removed from the indexes, and the indexes left with none are dropped.
+func (t *Table) reindex(from, to string) error {
+	for name, index := range t.indexes {
+		idx, ok := index.(*UnmergeableIndex)
+		if !ok || idx.tree == nil {
+			continue
+		}
+
+		var exprs []sql.Expression
+		var desc []bool
+		var prefixes []int64
+		oldDesc, oldPrefixes := idx.Descending(), idx.PrefixLengths()
+		for i, e := range idx.Exprs {
+			var missing bool
+			e, err := expression.TransformUp(e, func(e sql.Expression) (sql.Expression, error) {
+				gf, ok := e.(*expression.GetField)
+				if !ok {
+					return e, nil
+				}
+
+				column := gf.Name()
+				if from != "" && strings.EqualFold(column, from) {
+					column = to
+				}
+
+				i, field := t.getField(column)
+				if field == nil {
+					missing = true
+					return e, nil
+				}
+				return expression.NewGetFieldWithTable(i, field.Type, t.name, field.Name, field.Nullable), nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if !missing {
+				exprs = append(exprs, e)
+				desc = append(desc, oldDesc[i])
+				prefixes = append(prefixes, oldPrefixes[i])
+			}
+		}
+
+		if len(exprs) == 0 {
+			delete(t.indexes, name)
+			continue
+		}
+
+		idx.Exprs = exprs
+		idx.Desc = desc
+		idx.Prefixes = prefixes
+		if err := idx.tree.build(t.partitions); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -961,6 +1624,9 @@ func (t *Table) EnablePrimaryKeyIndexes() {
 
 // GetIndexes implements sql.IndexedTable
 func (t *Table) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
 	indexes := make([]sql.Index, 0)
 
 	if t.pkIndexesEnabled {
@@ -973,9 +1639,11 @@ func (t *Table) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
 
 		if len(pkCols) > 0 {
 			exprs := make([]sql.Expression, len(pkCols))
+			desc := make([]bool, len(pkCols))
 			for i, column := range pkCols {
 				idx, field := t.getField(column.Name)
 				exprs[i] = expression.NewGetFieldWithTable(idx, field.Type, t.name, field.Name, field.Nullable)
+				desc[i] = column.PrimaryKeyDescending
 			}
 			indexes = append(indexes, &MergeableIndex{
 				DB:         "",
@@ -985,6 +1653,7 @@ func (t *Table) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
 				Exprs:      exprs,
 				Name:       "PRIMARY",
 				Unique:     true,
+				Desc:       desc,
 			})
 		}
 	}
@@ -1002,13 +1671,107 @@ func (t *Table) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
 	return append(indexes, nonPrimaryIndexes...), nil
 }
 
+// Statistics implements sql.StatisticsTable. The statistics of memory tables are computed from all of their rows.
+func (t *Table) Statistics(ctx *sql.Context) (*sql.TableStatistics, error) {
+	indexes, err := t.GetIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var rows []sql.Row
+	for _, key := range t.keys {
+		rows = append(rows, t.partitions[string(key)]...)
+	}
+
+	stats := &sql.TableStatistics{
+		RowCount:         uint64(len(rows)),
+		IndexCardinality: make(map[string]uint64, len(indexes)),
+	}
+
+	var size uint64
+	for _, row := range rows {
+		for _, v := range row {
+			size += valueSize(v)
+		}
+	}
+	if len(rows) > 0 {
+		stats.AvgRowSize = size / uint64(len(rows))
+	}
+
+	for _, index := range indexes {
+		idx, ok := index.(interface{ ColumnExpressions() []sql.Expression })
+		if !ok {
+			continue
+		}
+
+		distinct := make(map[uint64]struct{})
+		for _, row := range rows {
+			key := make(sql.Row, len(idx.ColumnExpressions()))
+			for i, e := range idx.ColumnExpressions() {
+				if key[i], err = e.Eval(ctx, row); err != nil {
+					return nil, err
+				}
+			}
+			hash, err := sql.HashOf(key)
+			if err != nil {
+				return nil, err
+			}
+			distinct[hash] = struct{}{}
+		}
+		stats.IndexCardinality[index.ID()] = uint64(len(distinct))
+	}
+
+	return stats, nil
+}
+
+// valueSize returns the size of a value of a row, in bytes.
+func valueSize(v interface{}) uint64 {
+	switch v := v.(type) {
+	case nil:
+		return 0
+	case bool, int8, uint8:
+		return 1
+	case int16, uint16:
+		return 2
+	case int32, uint32, float32:
+		return 4
+	case int, uint, int64, uint64, float64, time.Time:
+		return 8
+	case string:
+		return uint64(len(v))
+	case []byte:
+		return uint64(len(v))
+	default:
+		return uint64(len(fmt.Sprint(v)))
+	}
+}
+
 // GetForeignKeys implements sql.ForeignKeyTable
 func (t *Table) GetForeignKeys(_ *sql.Context) ([]sql.ForeignKeyConstraint, error) {
 	return t.foreignKeys, nil
 }
 
-// CreateForeignKey implements sql.ForeignKeyAlterableTable. Foreign keys are not enforced on update / delete.
-func (t *Table) CreateForeignKey(_ *sql.Context, fkName string, columns []string, referencedTable string, referencedColumns []string, onUpdate, onDelete sql.ForeignKeyReferenceOption) error {
+// CreateForeignKey implements sql.ForeignKeyAlterableTable. The engine checks the foreign keys and applies their
+// referential actions, unless foreign_key_checks is off; the rows already in the table are not checked.
+func (t *Table) CreateForeignKey(ctx *sql.Context, fkName string, columns []string, referencedTable string, referencedColumns []string, onUpdate, onDelete sql.ForeignKeyReferenceOption) error {
+	return t.CreateCrossDatabaseForeignKey(ctx, fkName, columns, "", referencedTable, referencedColumns, onUpdate, onDelete)
+}
+
+// CreateCrossDatabaseForeignKey implements sql.CrossDatabaseForeignKeyTable.
+func (t *Table) CreateCrossDatabaseForeignKey(ctx *sql.Context, fkName string, columns []string, referencedDatabase, referencedTable string, referencedColumns []string, onUpdate, onDelete sql.ForeignKeyReferenceOption) error {
+	if t.base != nil {
+		if err := commitTransaction(ctx); err != nil {
+			return err
+		}
+		return t.base.CreateCrossDatabaseForeignKey(ctx, fkName, columns, referencedDatabase, referencedTable, referencedColumns, onUpdate, onDelete)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	for _, key := range t.foreignKeys {
 		if key.Name == fkName {
 			return fmt.Errorf("Constraint %s already exists", fkName)
@@ -1016,12 +1779,13 @@ func (t *Table) CreateForeignKey(_ *sql.Context, fkName string, columns []string
 	}
 
 	t.foreignKeys = append(t.foreignKeys, sql.ForeignKeyConstraint{
-		Name:              fkName,
-		Columns:           columns,
-		ReferencedTable:   referencedTable,
-		ReferencedColumns: referencedColumns,
-		OnUpdate:          onUpdate,
-		OnDelete:          onDelete,
+		Name:               fkName,
+		Columns:            columns,
+		ReferencedDatabase: referencedDatabase,
+		ReferencedTable:    referencedTable,
+		ReferencedColumns:  referencedColumns,
+		OnUpdate:           onUpdate,
+		OnDelete:           onDelete,
 	})
 
 	return nil
@@ -1029,6 +1793,16 @@ func (t *Table) CreateForeignKey(_ *sql.Context, fkName string, columns []string
 
 // DropForeignKey implements sql.ForeignKeyAlterableTable.
 func (t *Table) DropForeignKey(ctx *sql.Context, fkName string) error {
+	if t.base != nil {
+		if err := commitTransaction(ctx); err != nil {
+			return err
+		}
+		return t.base.DropForeignKey(ctx, fkName)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	for i, key := range t.foreignKeys {
 		if key.Name == fkName {
 			t.foreignKeys = append(t.foreignKeys[:i], t.foreignKeys[i+1:]...)
@@ -1038,6 +1812,59 @@ func (t *Table) DropForeignKey(ctx *sql.Context, fkName string) error {
 	return nil
 }
 
+// TableOptions implements sql.TableOptionsTable
+func (t *Table) TableOptions() sql.TableOptions {
+	return t.options
+}
+
+// GetChecks implements sql.CheckTable
+func (t *Table) GetChecks(_ *sql.Context) ([]sql.CheckDefinition, error) {
+	return t.checks, nil
+}
+
+// CreateCheck implements sql.CheckAlterableTable.
+func (t *Table) CreateCheck(ctx *sql.Context, check *sql.CheckDefinition) error {
+	if t.base != nil {
+		if err := commitTransaction(ctx); err != nil {
+			return err
+		}
+		return t.base.CreateCheck(ctx, check)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, c := range t.checks {
+		if strings.EqualFold(c.Name, check.Name) {
+			return sql.ErrCheckConstraintDuplicateName.New(check.Name)
+		}
+	}
+
+	t.checks = append(t.checks, *check)
+	return nil
+}
+
+// DropCheck implements sql.CheckAlterableTable.
+func (t *Table) DropCheck(ctx *sql.Context, checkName string) error {
+	if t.base != nil {
+		if err := commitTransaction(ctx); err != nil {
+			return err
+		}
+		return t.base.DropCheck(ctx, checkName)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, c := range t.checks {
+		if strings.EqualFold(c.Name, checkName) {
+			t.checks = append(t.checks[:i:i], t.checks[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrCheckConstraintNotFound.New(checkName)
+}
+
 func (t *Table) createIndex(name string, columns []sql.IndexColumn, constraint sql.IndexConstraint, comment string) (sql.Index, error) {
 	if t.indexes[name] != nil {
 		// TODO: extract a standard error type for this
@@ -1045,12 +1872,20 @@ func (t *Table) createIndex(name string, columns []sql.IndexColumn, constraint s
 	}
 
 	exprs := make([]sql.Expression, len(columns))
+	desc := make([]bool, len(columns))
+	prefixes := make([]int64, len(columns))
 	for i, column := range columns {
-		idx, field := t.getField(column.Name)
-		exprs[i] = expression.NewGetFieldWithTable(idx, field.Type, t.name, field.Name, field.Nullable)
+		if column.Expression != nil {
+			exprs[i] = column.Expression
+		} else {
+			idx, field := t.getField(column.Name)
+			exprs[i] = expression.NewGetFieldWithTable(idx, field.Type, t.name, field.Name, field.Nullable)
+		}
+		desc[i] = column.Descending
+		prefixes[i] = column.Length
 	}
 
-	return &UnmergeableIndex{
+	index := &UnmergeableIndex{
 		MergeableIndex{
 			DB:         "",
 			DriverName: "",
@@ -1060,8 +1895,16 @@ func (t *Table) createIndex(name string, columns []sql.IndexColumn, constraint s
 			Name:       name,
 			Unique:     constraint == sql.IndexConstraint_Unique,
 			CommentStr: comment,
+			Desc:       desc,
+			Prefixes:   prefixes,
 		},
-	}, nil
+	}
+
+	index.tree = newIndexTree(&index.MergeableIndex)
+	if err := index.tree.build(t.partitions); err != nil {
+		return nil, err
+	}
+	return index, nil
 }
 
 // getField returns the index and column index with the name given, if it exists, or -1, nil otherwise.
@@ -1082,6 +1925,16 @@ func (t *Table) getField(col string) (int, *sql.Column) {
 
 // CreateIndex implements sql.IndexAlterableTable
 func (t *Table) CreateIndex(ctx *sql.Context, indexName string, using sql.IndexUsing, constraint sql.IndexConstraint, columns []sql.IndexColumn, comment string) error {
+	if t.base != nil {
+		if err := commitTransaction(ctx); err != nil {
+			return err
+		}
+		return t.base.CreateIndex(ctx, indexName, using, constraint, columns, comment)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	if t.indexes == nil {
 		t.indexes = make(map[string]sql.Index)
 	}
@@ -1091,12 +1944,31 @@ func (t *Table) CreateIndex(ctx *sql.Context, indexName string, using sql.IndexU
 		return err
 	}
 
+	switch {
+	case constraint == sql.IndexConstraint_Fulltext:
+		index.(*UnmergeableIndex).Type = "FULLTEXT"
+	case constraint == sql.IndexConstraint_Spatial:
+		index.(*UnmergeableIndex).Type = "SPATIAL"
+	case using == sql.IndexUsing_Hash:
+		index.(*UnmergeableIndex).Type = "HASH"
+	}
+
 	t.indexes[indexName] = index
 	return nil
 }
 
 // DropIndex implements sql.IndexAlterableTable
 func (t *Table) DropIndex(ctx *sql.Context, indexName string) error {
+	if t.base != nil {
+		if err := commitTransaction(ctx); err != nil {
+			return err
+		}
+		return t.base.DropIndex(ctx, indexName)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	for name := range t.indexes {
 		if name == indexName {
 			delete(t.indexes, name)
@@ -1107,6 +1979,16 @@ func (t *Table) DropIndex(ctx *sql.Context, indexName string) error {
 
 // RenameIndex implements sql.IndexAlterableTable
 func (t *Table) RenameIndex(ctx *sql.Context, fromIndexName string, toIndexName string) error {
+	if t.base != nil {
+		if err := commitTransaction(ctx); err != nil {
+			return err
+		}
+		return t.base.RenameIndex(ctx, fromIndexName, toIndexName)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	for name, index := range t.indexes {
 		if name == fromIndexName {
 			delete(t.indexes, name)
@@ -1116,6 +1998,61 @@ func (t *Table) RenameIndex(ctx *sql.Context, fromIndexName string, toIndexName
 	return nil
 }
 
+// rename renames the table, along with the source of its columns and the table of the columns of its indexes.
+func (t *Table) rename(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	oldName := t.name
+	t.name = name
+
+	schema := make(sql.Schema, len(t.schema))
+	for i, col := range t.schema {
+		c := *col
+		c.Source = name
+		schema[i] = &c
+	}
+	t.schema = schema
+
+	for _, index := range t.indexes {
+		idx, ok := index.(*UnmergeableIndex)
+		if !ok {
+			continue
+		}
+
+		exprs := make([]sql.Expression, len(idx.Exprs))
+		for i, expr := range idx.Exprs {
+			exprs[i], _ = expression.TransformUp(expr, func(e sql.Expression) (sql.Expression, error) {
+				if gf, ok := e.(*expression.GetField); ok && gf.Table() == oldName {
+					return gf.WithTable(name), nil
+				}
+				return e, nil
+			})
+		}
+		idx.TableName, idx.Exprs = name, exprs
+	}
+}
+
+// SetIndexVisibility implements sql.IndexVisibilityAlterableTable
+func (t *Table) SetIndexVisibility(ctx *sql.Context, indexName string, visible bool) error {
+	if t.base != nil {
+		if err := commitTransaction(ctx); err != nil {
+			return err
+		}
+		return t.base.SetIndexVisibility(ctx, indexName, visible)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	index, ok := t.indexes[indexName].(*UnmergeableIndex)
+	if !ok {
+		return sql.ErrIndexNotFound.New(indexName)
+	}
+	index.Invisible = !visible
+	return nil
+}
+
 // WithIndexLookup implements the sql.IndexAddressableTable interface.
 func (t *Table) WithIndexLookup(lookup sql.IndexLookup) sql.Table {
 	if lookup == nil {
@@ -3,6 +3,7 @@ package memory
 import (
 	"fmt"
 	"io"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -404,3 +405,477 @@ func TestTableIndexKeyValueIter(t *testing.T) {
 		})
 	}
 }
+
+func TestSecondaryIndexes(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t", Nullable: true},
+		{Name: "u", Type: sql.Int64, Source: "t", Nullable: true},
+	}, 1)
+	// lookupRows returns the rows of the table that a lookup matches.
+	lookupRows := func(lookup sql.IndexLookup, err error) []sql.Row {
+		require.NoError(err)
+		return testFlatRows(t, table.WithIndexLookup(lookup))
+	}
+
+	for i := int64(0); i < 200; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(i, fmt.Sprintf("s%d", i%10), 1000-i)))
+	}
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(200), nil, nil)))
+
+	require.NoError(table.CreateIndex(ctx, "s", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "s"}}, ""))
+	require.NoError(table.CreateIndex(ctx, "u", sql.IndexUsing_BTree, sql.IndexConstraint_Unique, []sql.IndexColumn{{Name: "u"}}, ""))
+	indexes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+	s, u := indexes[0].(*UnmergeableIndex), indexes[1].(*UnmergeableIndex)
+
+	rows := lookupRows(s.Get("s3"))
+	require.Len(rows, 20)
+	for i, row := range rows {
+		require.Equal(int64(i*10+3), row[0])
+	}
+	require.Equal([]sql.Row{{int64(200), nil, nil}}, lookupRows(s.Get(nil)))
+
+	// The rows of ranges are ordered by the index.
+	require.Equal([]sql.Row{
+		{int64(199), "s9", int64(801)},
+		{int64(198), "s8", int64(802)},
+		{int64(197), "s7", int64(803)},
+	}, lookupRows(u.AscendLessThan(int64(804))))
+	require.Equal([]sql.Row{
+		{int64(0), "s0", int64(1000)},
+		{int64(1), "s1", int64(999)},
+	}, lookupRows(u.DescendGreater(int64(998))))
+	require.Len(lookupRows(u.AscendRange([]interface{}{int64(900)}, []interface{}{int64(950)})), 50)
+
+	// Unique indexes reject duplicate keys, except NULL ones.
+	require.True(sql.ErrUniqueKeyViolation.Is(table.Insert(ctx, sql.NewRow(int64(300), "x", int64(900)))))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(301), "x", nil)))
+	err = table.CreateIndex(ctx, "s2", sql.IndexUsing_BTree, sql.IndexConstraint_Unique, []sql.IndexColumn{{Name: "s"}}, "")
+	require.True(sql.ErrUniqueKeyViolation.Is(err))
+
+	updater := table.Updater(ctx)
+	require.True(sql.ErrUniqueKeyViolation.Is(updater.Update(ctx, sql.NewRow(int64(1), "s1", int64(999)), sql.NewRow(int64(1), "s1", int64(998)))))
+	require.NoError(updater.Update(ctx, sql.NewRow(int64(1), "s1", int64(999)), sql.NewRow(int64(1), "s1", int64(2000))))
+	require.NoError(updater.Close(ctx))
+	require.Equal([]sql.Row{{int64(1), "s1", int64(2000)}}, lookupRows(u.Get(int64(2000))))
+	require.Empty(lookupRows(u.Get(int64(999))))
+
+	deleter := table.Deleter(ctx)
+	require.NoError(deleter.Delete(ctx, sql.NewRow(int64(1), "s1", int64(2000))))
+	require.NoError(deleter.Close(ctx))
+	require.Empty(lookupRows(u.Get(int64(2000))))
+	require.Len(lookupRows(s.Get("s1")), 19)
+
+	// The indexes follow their columns when the schema changes.
+	require.NoError(table.AddColumn(ctx, &sql.Column{Name: "first", Type: sql.Int64, Source: "t", Nullable: true}, &sql.ColumnOrder{First: true}))
+	require.Equal([]sql.Row{{nil, int64(2), "s2", int64(998)}}, lookupRows(u.Get(int64(998))))
+	require.NoError(table.DropColumn(ctx, "s"))
+	indexes, err = table.GetIndexes(ctx)
+	require.NoError(err)
+	require.Len(indexes, 1)
+	require.Equal([]sql.Row{{nil, int64(2), int64(998)}}, lookupRows(u.Get(int64(998))))
+}
+
+func TestPartitionedTableHashesPrimaryKey(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	schema := sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t"},
+	}
+	// partitionsOf returns the key of the partition of every row of a table, by primary key.
+	partitionsOf := func(table *Table) map[int64]string {
+		keys := make(map[int64]string)
+		for key, rows := range table.partitions {
+			for _, row := range rows {
+				keys[row[0].(int64)] = key
+			}
+		}
+		return keys
+	}
+
+	// The rows go to the same partitions whatever the order they're inserted in.
+	table, other := NewPartitionedTable("t", schema, 4), NewPartitionedTable("t", schema, 4)
+	for i := int64(0); i < 100; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(i, "a")))
+		require.NoError(other.Insert(ctx, sql.NewRow(99-i, "a")))
+	}
+	keys := partitionsOf(table)
+	require.Equal(keys, partitionsOf(other))
+	for key, rows := range table.partitions {
+		require.NotEmpty(rows, "partition %s", key)
+	}
+
+	// The rows whose primary key changes move to the partition it hashes to.
+	updater := table.Updater(ctx)
+	for i := int64(0); i < 10; i++ {
+		require.NoError(updater.Update(ctx, sql.NewRow(i, "a"), sql.NewRow(i+100, "b")))
+		require.NoError(other.Insert(ctx, sql.NewRow(i+100, "b")))
+		require.NoError(other.Deleter(ctx).Delete(ctx, sql.NewRow(i, "a")))
+	}
+	require.Equal(partitionsOf(other), partitionsOf(table))
+
+	// The scans of a table don't see the rows inserted after its partitions were listed.
+	partitions, err := table.Partitions(ctx)
+	require.NoError(err)
+	for i := int64(200); i < 300; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(i, "c")))
+	}
+	var n int
+	for {
+		p, err := partitions.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(err)
+		iter, err := table.PartitionRows(ctx, p)
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		n += len(rows)
+	}
+	require.Equal(100, n)
+}
+
+func TestTableStatistics(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t", Nullable: true},
+	}, 3)
+	table.EnablePrimaryKeyIndexes()
+
+	stats, err := table.Statistics(ctx)
+	require.NoError(err)
+	require.Equal(&sql.TableStatistics{IndexCardinality: map[string]uint64{"PRIMARY": 0}}, stats)
+
+	for i := int64(0); i < 10; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(i, fmt.Sprintf("s%d", i%4))))
+	}
+	require.NoError(table.CreateIndex(ctx, "s", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "s"}}, ""))
+
+	stats, err = table.Statistics(ctx)
+	require.NoError(err)
+	require.Equal(uint64(10), stats.RowCount)
+	require.Equal(uint64(8+2), stats.AvgRowSize)
+	require.Equal(uint64(100), stats.DataLength())
+	require.Equal(map[string]uint64{"PRIMARY": 10, "s": 4}, stats.IndexCardinality)
+}
+
+func TestRangeIndexLookup(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t"},
+		{Name: "b", Type: sql.Int64, Source: "t"},
+	}, 1)
+	for a := int64(0); a < 10; a++ {
+		for b := int64(0); b < 10; b++ {
+			require.NoError(table.Insert(ctx, sql.NewRow(a, b)))
+		}
+	}
+	require.NoError(table.CreateIndex(ctx, "ab", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "a"}, {Name: "b"}}, ""))
+	indexes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+	index := indexes[0].(sql.RangeIndex)
+
+	// lookupRows returns the rows of the table that a range lookup matches.
+	lookupRows := func(ranges ...sql.IndexRange) []sql.Row {
+		lookup, err := index.Range(ranges...)
+		require.NoError(err)
+		return testFlatRows(t, table.WithIndexLookup(lookup))
+	}
+	bound := func(v int64, inclusive bool) *sql.IndexBound {
+		return &sql.IndexBound{Value: v, Inclusive: inclusive}
+	}
+
+	require.Equal([]sql.Row{{int64(3), int64(3)}, {int64(3), int64(4)}, {int64(3), int64(5)}}, lookupRows(
+		sql.IndexRange{Lower: bound(3, true), Upper: bound(3, true)},
+		sql.IndexRange{Lower: bound(2, false), Upper: bound(5, true)},
+	))
+	require.Equal([]sql.Row{{int64(8), int64(0)}, {int64(9), int64(0)}}, lookupRows(
+		sql.IndexRange{Lower: bound(7, false)},
+		sql.IndexRange{Upper: bound(1, false)},
+	))
+	require.Len(lookupRows(sql.IndexRange{Upper: bound(2, false)}), 20)
+	require.Empty(lookupRows(sql.IndexRange{Lower: bound(5, true), Upper: bound(5, false)}))
+}
+
+func TestRangeDeleteAndUpdate(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "b", Type: sql.Int64, Source: "t"},
+	}, 2)
+	for a := int64(0); a < 10; a++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(a, a%3)))
+	}
+	require.NoError(table.CreateIndex(ctx, "a", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "a"}}, ""))
+	indexes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+	index := indexes[0].(sql.RangeIndex)
+
+	// rangeTable returns the table with a lookup of the rows whose a is between the values given.
+	rangeTable := func(lower, upper int64) *Table {
+		lookup, err := index.Range(sql.IndexRange{
+			Lower: &sql.IndexBound{Value: lower, Inclusive: true},
+			Upper: &sql.IndexBound{Value: upper, Inclusive: true},
+		})
+		require.NoError(err)
+		return table.WithIndexLookup(lookup).(*Table)
+	}
+	isZero := expression.NewEquals(expression.NewGetField(1, sql.Int64, "b", false), expression.NewLiteral(int64(0), sql.Int64))
+
+	deleted, err := rangeTable(2, 4).DeleteRange(ctx, nil)
+	require.NoError(err)
+	require.Equal(3, deleted)
+
+	deleted, err = rangeTable(5, 9).DeleteRange(ctx, isZero)
+	require.NoError(err)
+	require.Equal(2, deleted)
+
+	matched, updated, err := rangeTable(0, 8).UpdateRange(ctx, nil, func(row sql.Row) (sql.Row, error) {
+		return sql.NewRow(row[0], int64(1)), nil
+	})
+	require.NoError(err)
+	require.Equal(5, matched)
+	require.Equal(3, updated)
+
+	rows := testFlatRows(t, table)
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0].(int64) < rows[j][0].(int64) })
+	require.Equal([]sql.Row{
+		{int64(0), int64(1)}, {int64(1), int64(1)}, {int64(5), int64(1)}, {int64(7), int64(1)}, {int64(8), int64(1)},
+	}, rows)
+}
+
+func TestCopyRows(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	source := NewPushdownTable("source", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "source", PrimaryKey: true},
+		{Name: "b", Type: sql.Int64, Source: "source"},
+	})
+	for a := int64(0); a < 5; a++ {
+		require.NoError(source.Insert(ctx, sql.NewRow(a, a%2)))
+	}
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "b", Type: sql.Int64, Source: "t"},
+	}, 2)
+
+	isOne := expression.NewEquals(expression.NewGetField(1, sql.Int64, "b", false), expression.NewLiteral(int64(1), sql.Int64))
+	copied, ok, err := table.CopyRows(ctx, source, isOne)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(2, copied)
+
+	// The filters of the source apply to the rows copied
+	isEven := expression.NewEquals(expression.NewGetField(1, sql.Int64, "b", false), expression.NewLiteral(int64(0), sql.Int64))
+	lessThanFour := expression.NewLessThan(expression.NewGetField(0, sql.Int64, "a", false), expression.NewLiteral(int64(4), sql.Int64))
+	copied, ok, err = table.CopyRows(ctx, source.WithFilters([]sql.Expression{lessThanFour}), isEven)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(2, copied)
+
+	rows := testFlatRows(t, table)
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0].(int64) < rows[j][0].(int64) })
+	require.Equal([]sql.Row{{int64(0), int64(0)}, {int64(1), int64(1)}, {int64(2), int64(0)}, {int64(3), int64(1)}}, rows)
+
+	_, _, err = table.CopyRows(ctx, source, nil)
+	require.True(sql.ErrUniqueKeyViolation.Is(err))
+
+	// Projected sources aren't copied
+	_, ok, err = table.CopyRows(ctx, source.WithProjection([]string{"a"}), nil)
+	require.NoError(err)
+	require.False(ok)
+}
+
+func TestUpsert(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "b", Type: sql.Int64, Source: "t"},
+	}, 2)
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(1), int64(1))))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(2), int64(2))))
+	require.NoError(table.CreateIndex(ctx, "b", sql.IndexUsing_BTree, sql.IndexConstraint_Unique, []sql.IndexColumn{{Name: "b"}}, ""))
+	upserter := table.Upserter(ctx)
+
+	replaced, err := upserter.Replace(ctx, sql.NewRow(int64(1), int64(10)))
+	require.NoError(err)
+	require.True(replaced)
+	replaced, err = upserter.Replace(ctx, sql.NewRow(int64(3), int64(30)))
+	require.NoError(err)
+	require.False(replaced)
+
+	// The row deleted is restored if the new one has a duplicate key
+	_, err = upserter.Replace(ctx, sql.NewRow(int64(1), int64(2)))
+	require.True(sql.ErrUniqueKeyViolation.Is(err))
+
+	increment := func(row sql.Row) (sql.Row, error) {
+		return sql.NewRow(row[0], row[1].(int64)+1), nil
+	}
+	old, updated, err := upserter.Upsert(ctx, sql.NewRow(int64(2), int64(0)), increment)
+	require.NoError(err)
+	require.Equal(sql.NewRow(int64(2), int64(2)), old)
+	require.Equal(sql.NewRow(int64(2), int64(3)), updated)
+
+	// The new row of an update has a duplicate key of the unique index
+	_, _, err = upserter.Upsert(ctx, sql.NewRow(int64(2), int64(0)), func(row sql.Row) (sql.Row, error) {
+		return sql.NewRow(row[0], int64(10)), nil
+	})
+	require.True(sql.ErrUniqueKeyViolation.Is(err))
+
+	old, inserted, err := upserter.Upsert(ctx, sql.NewRow(int64(4), int64(4)), increment)
+	require.NoError(err)
+	require.Nil(old)
+	require.Equal(sql.NewRow(int64(4), int64(4)), inserted)
+
+	// Only the rows with the same primary key are updated
+	_, _, err = upserter.Upsert(ctx, sql.NewRow(int64(5), int64(4)), increment)
+	require.True(sql.ErrUniqueKeyViolation.Is(err))
+	require.NoError(upserter.Close(ctx))
+
+	rows := testFlatRows(t, table)
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0].(int64) < rows[j][0].(int64) })
+	require.Equal([]sql.Row{{int64(1), int64(10)}, {int64(2), int64(3)}, {int64(3), int64(30)}, {int64(4), int64(4)}}, rows)
+}
+
+func TestUncomparableValues(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	table := NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t"},
+		{Name: "b", Type: sql.JSON, Source: "t", Nullable: true},
+	})
+
+	// JSON documents are given as maps and slices as well as their encoding
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(1), map[string]interface{}{"a": []interface{}{float64(1)}})))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(2), []byte(`{"b":2}`))))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(3), nil)))
+
+	editor := table.Updater(ctx)
+	require.NoError(editor.Update(ctx,
+		sql.NewRow(int64(1), map[string]interface{}{"a": []interface{}{float64(1)}}),
+		sql.NewRow(int64(1), []interface{}{"c"})))
+	// Rows that differ are left alone
+	require.NoError(editor.Update(ctx, sql.NewRow(int64(2), []byte(`{"b":3}`)), sql.NewRow(int64(2), nil)))
+	require.NoError(editor.Close(ctx))
+
+	deleter := table.Deleter(ctx)
+	require.NoError(deleter.Delete(ctx, sql.NewRow(int64(2), []byte(`{"b":2}`))))
+	require.NoError(deleter.Delete(ctx, sql.NewRow(int64(3), nil)))
+	err := deleter.Delete(ctx, sql.NewRow(int64(1), map[string]interface{}{"a": []interface{}{float64(1)}}))
+	require.True(sql.ErrDeleteRowNotFound.Is(err))
+	require.NoError(deleter.Close(ctx))
+
+	require.Equal([]sql.Row{{int64(1), []interface{}{"c"}}}, testFlatRows(t, table))
+}
+
+func TestOrderedIndexLookup(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t", Nullable: true},
+		{Name: "b", Type: sql.Int64, Source: "t"},
+	}, 3)
+	for _, row := range []sql.Row{{int64(2), int64(0)}, {nil, int64(1)}, {int64(1), int64(2)}, {int64(3), int64(3)}, {int64(4), int64(4)}} {
+		require.NoError(table.Insert(ctx, row))
+	}
+	require.NoError(table.CreateIndex(ctx, "a", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "a"}}, ""))
+	indexes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+	index := indexes[0].(sql.OrderedIndex)
+
+	// lookupRows returns the rows of the table that an ordered lookup of the one given returns.
+	lookupRows := func(lookup sql.IndexLookup, descending bool) []sql.Row {
+		ordered, err := index.Ordered(lookup, descending)
+		require.NoError(err)
+		return testFlatRows(t, table.WithIndexLookup(ordered))
+	}
+
+	require.Equal([]sql.Row{
+		{nil, int64(1)}, {int64(1), int64(2)}, {int64(2), int64(0)}, {int64(3), int64(3)}, {int64(4), int64(4)},
+	}, lookupRows(nil, false))
+	require.Equal([]sql.Row{
+		{int64(4), int64(4)}, {int64(3), int64(3)}, {int64(2), int64(0)}, {int64(1), int64(2)}, {nil, int64(1)},
+	}, lookupRows(nil, true))
+
+	lookup, err := index.(sql.RangeIndex).Range(sql.IndexRange{Lower: &sql.IndexBound{Value: int64(1), Inclusive: false}})
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(4), int64(4)}, {int64(3), int64(3)}, {int64(2), int64(0)}}, lookupRows(lookup, true))
+
+	lookup, err = index.Get(int64(1))
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1), int64(2)}}, lookupRows(lookup, false))
+}
+
+func TestDescendingIndex(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t"},
+		{Name: "b", Type: sql.Int64, Source: "t", Nullable: true},
+	}, 2)
+	for _, row := range []sql.Row{{int64(1), int64(1)}, {int64(1), nil}, {int64(2), int64(3)}, {int64(1), int64(2)}, {int64(3), int64(0)}} {
+		require.NoError(table.Insert(ctx, row))
+	}
+	require.NoError(table.CreateIndex(ctx, "ab", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "a"}, {Name: "b", Descending: true}}, ""))
+	indexes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+	index := indexes[0].(*UnmergeableIndex)
+	require.Equal([]bool{false, true}, sql.IndexDirections(index))
+
+	ordered, err := index.Ordered(nil, false)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{int64(1), int64(2)}, {int64(1), int64(1)}, {int64(1), nil}, {int64(2), int64(3)}, {int64(3), int64(0)},
+	}, testFlatRows(t, table.WithIndexLookup(ordered)))
+
+	lookup, err := index.Range(
+		sql.IndexRange{Lower: &sql.IndexBound{Value: int64(1), Inclusive: true}, Upper: &sql.IndexBound{Value: int64(1), Inclusive: true}},
+		sql.IndexRange{Lower: &sql.IndexBound{Value: int64(1), Inclusive: false}},
+	)
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1), int64(2)}}, testFlatRows(t, table.WithIndexLookup(lookup)))
+	require.NoError(table.CreateIndex(ctx, "b", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "b", Descending: true}}, ""))
+	indexes, err = table.GetIndexes(ctx)
+	require.NoError(err)
+	lookup, err = indexes[1].(sql.AscendIndex).AscendGreaterOrEqual(int64(2))
+	require.NoError(err)
+	require.ElementsMatch([]sql.Row{{int64(1), int64(2)}, {int64(2), int64(3)}}, testFlatRows(t, table.WithIndexLookup(lookup)))
+}
+
+func TestPrefixIndex(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t"},
+		{Name: "s", Type: sql.Text, Source: "t", Nullable: true},
+	}, 2)
+	for _, row := range []sql.Row{{int64(1), "abcd"}, {int64(2), "abc"}, {int64(3), "abd"}, {int64(4), "ab"}, {int64(5), nil}} {
+		require.NoError(table.Insert(ctx, row))
+	}
+	require.NoError(table.CreateIndex(ctx, "s", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "s", Length: 3}}, ""))
+	indexes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+	index := indexes[0].(*UnmergeableIndex)
+	require.Equal([]int64{3}, sql.IndexPrefixLengths(index))
+
+	lookup, err := index.Get("abcd")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1), "abcd"}}, testFlatRows(t, table.WithIndexLookup(lookup)))
+
+	lookup, err = index.Range(sql.IndexRange{Lower: &sql.IndexBound{Value: "abc", Inclusive: false}})
+	require.NoError(err)
+	require.ElementsMatch([]sql.Row{{int64(1), "abcd"}, {int64(3), "abd"}}, testFlatRows(t, table.WithIndexLookup(lookup)))
+
+	require.Error(table.CreateIndex(ctx, "u", sql.IndexUsing_BTree, sql.IndexConstraint_Unique, []sql.IndexColumn{{Name: "s", Length: 3}}, ""))
+	require.NoError(table.CreateIndex(ctx, "u", sql.IndexUsing_BTree, sql.IndexConstraint_Unique, []sql.IndexColumn{{Name: "s", Length: 4}}, ""))
+}
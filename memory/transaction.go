@@ -0,0 +1,525 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ErrWriteConflict is returned when a transaction commits a change to a row that another transaction changed and
+// committed first. The transaction is rolled back.
+var ErrWriteConflict = errors.NewKind("write conflict on table %s: %s, try restarting the transaction")
+
+// ErrRollbackFailed is returned when the changes a transaction committed to some tables can't be undone after its
+// commit failed on another.
+var ErrRollbackFailed = errors.NewKind("unable to roll back the changes committed to table %s: %s, after: %s")
+
+// Session is a session whose transactions have snapshot isolation of the memory tables. The transactions started with
+// BEGIN or START TRANSACTION see the rows the tables of a database had when they first read one of them, like the
+// consistent reads of MySQL, whatever the other sessions change while they're open, and their own changes are only
+// seen by the other sessions once they commit. Commits fail with
+// ErrWriteConflict if another transaction committed a change to a row the transaction changed first, so that the first
+// committer wins. The statements run outside of transactions change the tables as they run, whatever the autocommit
+// of the session, and the statements changing schemas commit the open transaction before they run, like in MySQL. The
+// AUTO_INCREMENT counters of the tables aren't transactional, so that concurrent inserts get different values.
+type Session struct {
+	sql.Session
+	mu sync.Mutex
+	tx *transaction
+}
+
+var _ sql.TransactionHookSession = (*Session)(nil)
+
+// NewSession returns a Session with the state of the session given.
+func NewSession(s sql.Session) *Session {
+	return &Session{Session: s}
+}
+
+// StartTransaction implements the sql.ExplicitTransactionSession interface.
+func (s *Session) StartTransaction(*sql.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.commit(); err != nil {
+		return err
+	}
+	s.tx = newTransaction()
+	return nil
+}
+
+// InTransaction implements the sql.ExplicitTransactionSession interface.
+func (s *Session) InTransaction() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tx != nil
+}
+
+// CommitTransaction implements the sql.Session interface.
+func (s *Session) CommitTransaction(*sql.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commit()
+}
+
+// Rollback implements the sql.TransactionSession interface.
+func (s *Session) Rollback(*sql.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tx != nil {
+		s.tx.end()
+		s.tx.runHooks(false)
+		s.tx = nil
+	}
+	return nil
+}
+
+// OnTransactionEnd implements the sql.TransactionHookSession interface.
+func (s *Session) OnTransactionEnd(f func(committed bool)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tx == nil {
+		f(true)
+		return
+	}
+	s.tx.hooks = append(s.tx.hooks, f)
+}
+
+// commit commits the open transaction, if any. It's ended even if it fails.
+func (s *Session) commit() error {
+	if s.tx == nil {
+		return nil
+	}
+
+	tx := s.tx
+	s.tx = nil
+	defer tx.end()
+
+	err := tx.commit()
+	tx.runHooks(err == nil)
+	return err
+}
+
+// transaction returns the open transaction of the session, or nil if there's none.
+func (s *Session) transaction() *transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tx
+}
+
+// commitTransaction commits the open transaction of the session of the context, if it's a Session, as the statements
+// changing schemas do before they run.
+func commitTransaction(ctx *sql.Context) error {
+	if ctx == nil {
+		return nil
+	}
+
+	if s, ok := ctx.Session.(*Session); ok {
+		return s.CommitTransaction(ctx)
+	}
+	return nil
+}
+
+// transactionTable returns the copy of a table that the open transaction of the session of the context reads and
+// writes, or the table itself if there's none.
+func transactionTable(ctx *sql.Context, t sql.Table) sql.Table {
+	if ctx == nil {
+		return t
+	}
+
+	s, ok := ctx.Session.(*Session)
+	base, isTable := t.(*Table)
+	if !ok || !isTable {
+		return t
+	}
+
+	if tx := s.transaction(); tx != nil && base.transactions != nil {
+		return tx.table(base)
+	}
+	return t
+}
+
+// transactions are the open transactions that read the tables of a database, and seq is the sequence number of the
+// last change to one of them. The transactions see the changes with sequence numbers up to their start, the sequence
+// number when they first read one of the tables.
+type transactions struct {
+	mu   sync.Mutex
+	seq  uint64
+	open map[*transaction]uint64
+}
+
+func newTransactions() *transactions {
+	return &transactions{open: make(map[*transaction]uint64)}
+}
+
+// tableIDs is the last identifier given to a table, which orders the locks taken on commit.
+var tableIDs uint64
+
+// nextChange returns the sequence number of a change to a table, and the starts of the open transactions.
+func (ts *transactions) nextChange() (uint64, []uint64) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.seq++
+	starts := make([]uint64, 0, len(ts.open))
+	for _, start := range ts.open {
+		starts = append(starts, start)
+	}
+	return ts.seq, starts
+}
+
+// begin returns the start of a transaction, which starts when it's first given.
+func (ts *transactions) begin(tx *transaction) uint64 {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	start, ok := ts.open[tx]
+	if !ok {
+		start = ts.seq
+		ts.open[tx] = start
+	}
+	return start
+}
+
+// end ends a transaction, releasing the versions of the tables kept for it.
+func (ts *transactions) end(tx *transaction) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	delete(ts.open, tx)
+}
+
+// tableOp is a change a transaction made to a table: the insertion of a row, if old is nil, the deletion of one, if
+// new is nil, or the update of one.
+type tableOp struct {
+	old, new sql.Row
+}
+
+func (op tableOp) inverse() tableOp {
+	return tableOp{old: op.new, new: op.old}
+}
+
+// tableVersion are the rows a table had before the change with the sequence number following seq, kept for the open
+// transactions that started before it.
+type tableVersion struct {
+	seq        uint64
+	partitions map[string][]sql.Row
+}
+
+type transaction struct {
+	mu sync.Mutex
+	// tables are the copies of the tables the transaction read, and ops are the changes it made to them, in the order
+	// of the tables in written. started are the transactions of the databases of the tables it read.
+	tables  map[*Table]*Table
+	ops     map[*Table][]tableOp
+	written []*Table
+	started map[*transactions]bool
+	// hooks run once the transaction ends, with whether it committed.
+	hooks []func(committed bool)
+}
+
+func newTransaction() *transaction {
+	return &transaction{
+		tables:  make(map[*Table]*Table),
+		ops:     make(map[*Table][]tableOp),
+		started: make(map[*transactions]bool),
+	}
+}
+
+// end ends the transaction, releasing the versions of the tables kept for it.
+func (tx *transaction) end() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	for ts := range tx.started {
+		ts.end(tx)
+	}
+}
+
+// runHooks runs the functions added to run once the transaction ends.
+func (tx *transaction) runHooks(committed bool) {
+	for _, f := range tx.hooks {
+		f(committed)
+	}
+	tx.hooks = nil
+}
+
+// table returns the copy of a table the transaction reads and writes, taking it when it's first read.
+func (tx *transaction) table(base *Table) *Table {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if t, ok := tx.tables[base]; ok {
+		return t
+	}
+
+	tx.started[base.transactions] = true
+	t := base.snapshot(tx, base.transactions.begin(tx))
+	tx.tables[base] = t
+	return t
+}
+
+// record records a change the transaction made to its copy of a table.
+func (tx *transaction) record(base *Table, op tableOp) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if _, ok := tx.ops[base]; !ok {
+		tx.written = append(tx.written, base)
+	}
+	tx.ops[base] = append(tx.ops[base], op)
+}
+
+// commit applies the changes of the transaction to the tables, all of them or none, while they're locked.
+func (tx *transaction) commit() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	locked := append([]*Table(nil), tx.written...)
+	sort.Slice(locked, func(i, j int) bool {
+		return locked[i].lockID() < locked[j].lockID()
+	})
+	for _, t := range locked {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+	}
+
+	type appliedOp struct {
+		table *Table
+		op    tableOp
+	}
+
+	var applied []appliedOp
+	for _, t := range tx.written {
+		for _, op := range tx.ops[t] {
+			if err := t.apply(op); err != nil {
+				for i := len(applied) - 1; i >= 0; i-- {
+					if rbErr := applied[i].table.apply(applied[i].op.inverse()); rbErr != nil {
+						return ErrRollbackFailed.New(applied[i].table.name, rbErr, ErrWriteConflict.New(t.name, err))
+					}
+				}
+				return ErrWriteConflict.New(t.name, err)
+			}
+			applied = append(applied, appliedOp{t, op})
+		}
+	}
+
+	return nil
+}
+
+// apply applies a change a transaction made to its copy of the table, which must be locked, failing if the row it
+// changed isn't in the table anymore or the row it inserted has a duplicate key. The rows changed are told apart by
+// their identity rather than their values, so that the rows changed and changed back by other transactions since, and
+// the other copies of duplicate rows, aren't mistaken for them.
+func (t *Table) apply(op tableOp) error {
+	editor := &tableEditor{t}
+	if op.old != nil && !t.hasRow(op.old) {
+		return fmt.Errorf("row %s was changed", sql.FormatRow(op.old))
+	}
+
+	var err error
+	switch {
+	case op.old == nil:
+		err = editor.insert(op.new)
+	case op.new == nil:
+		_, err = editor.delete(op.old)
+	default:
+		_, err = editor.update(op.old, op.new)
+	}
+	return err
+}
+
+// lockID returns the identifier ordering the lock of the table among the ones of other tables, giving it one first
+// if it has none.
+func (t *Table) lockID() uint64 {
+	if id := atomic.LoadUint64(&t.id); id != 0 {
+		return id
+	}
+	atomic.CompareAndSwapUint64(&t.id, 0, atomic.AddUint64(&tableIDs, 1))
+	return atomic.LoadUint64(&t.id)
+}
+
+// snapshot returns a copy of the table with the rows it had at the start given of the transaction given, for the
+// transaction to read and write. The copy shares the rows with the table, or the version of it kept for the
+// transaction, which are never changed in place, until the transaction first changes it, and has no index trees until
+// then, its index lookups reading all the rows. The AUTO_INCREMENT counter isn't copied, as it isn't transactional.
+func (t *Table) snapshot(tx *transaction, start uint64) *Table {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	partitions := t.partitions
+	if t.seq > start {
+		for i := len(t.history) - 1; i >= 0; i-- {
+			if v := t.history[i]; v.seq <= start {
+				partitions = v.partitions
+				break
+			}
+		}
+	}
+
+	c := &Table{
+		name:             t.name,
+		schema:           t.schema,
+		columns:          t.columns,
+		foreignKeys:      append([]sql.ForeignKeyConstraint(nil), t.foreignKeys...),
+		checks:           append([]sql.CheckDefinition(nil), t.checks...),
+		options:          t.options,
+		pkIndexesEnabled: t.pkIndexesEnabled,
+		partitions:       partitions,
+		shared:           true,
+		keys:             t.keys,
+		insert:           t.insert,
+		autoColIdx:       t.autoColIdx,
+		mu:               &sync.RWMutex{},
+		base:             t,
+		tx:               tx,
+	}
+
+	for name, index := range t.indexes {
+		idx, ok := index.(*UnmergeableIndex)
+		if !ok {
+			continue
+		}
+
+		ci := *idx
+		ci.Tbl, ci.tree = c, nil
+		if c.indexes == nil {
+			c.indexes = make(map[string]sql.Index)
+		}
+		c.indexes[name] = &ci
+	}
+
+	return c
+}
+
+// own gives a copy of a table that a transaction read its own rows and index trees, before the transaction first
+// changes it. The copies of it with lookups, filters or projections change the rows of the one the transaction keeps,
+// so that the transaction sees the changes made through any of them.
+func (t *Table) own() {
+	if !t.shared {
+		return
+	}
+
+	if c := t.tx.table(t.base); c != t {
+		c.own()
+		t.partitions, t.indexes, t.shared = c.partitions, c.indexes, false
+		return
+	}
+
+	t.partitions = copyPartitions(t.partitions)
+	t.shared = false
+	for name, index := range t.indexes {
+		idx, ok := index.(*UnmergeableIndex)
+		if !ok {
+			continue
+		}
+
+		ci := *idx
+		ci.tree = newIndexTree(&ci.MergeableIndex)
+		if err := ci.tree.build(t.partitions); err != nil {
+			// The rows of a version had unique keys when the index was built.
+			continue
+		}
+		t.indexes[name] = &ci
+	}
+}
+
+// preserve keeps the rows of the table, which must be locked, for the open transactions that see them, before a
+// change to them, and gives the change its sequence number. The table changes a copy of its rows then, rather than
+// the ones kept, and the ones no open transaction sees are dropped.
+func (t *Table) preserve() {
+	if t.base != nil || t.transactions == nil {
+		return
+	}
+
+	seq, starts := t.transactions.nextChange()
+
+	history := t.history
+	for _, start := range starts {
+		if start >= t.seq {
+			if len(history) == 0 || history[len(history)-1].seq != t.seq {
+				history = append(history, &tableVersion{
+					seq:        t.seq,
+					partitions: t.partitions,
+				})
+				t.partitions = copyPartitions(t.partitions)
+			}
+			break
+		}
+	}
+
+	var kept []*tableVersion
+	for i, v := range history {
+		next := seq
+		if i+1 < len(history) {
+			next = history[i+1].seq
+		}
+		for _, start := range starts {
+			if start >= v.seq && start < next {
+				kept = append(kept, v)
+				break
+			}
+		}
+	}
+
+	t.history = kept
+	t.seq = seq
+}
+
+// changeSchema drops the copies of the rows of the table, which must be locked, before a change of its schema, so
+// that the open transactions that read it after see it as it is. The change is made to a copy of the rows, as the
+// transactions that read it before share them.
+func (t *Table) changeSchema() {
+	if t.base != nil || t.transactions == nil {
+		return
+	}
+
+	t.seq, _ = t.transactions.nextChange()
+	t.history = nil
+	t.partitions = copyPartitions(t.partitions)
+}
+
+// record records a change to a copy of a table that a transaction made.
+func (t *Table) record(op tableOp) {
+	if t.tx != nil {
+		t.tx.record(t.base, op)
+	}
+}
+
+// hasRow returns whether the table has the row given, itself rather than a row with the same values.
+func (t *Table) hasRow(row sql.Row) bool {
+	_, _, ok := t.position(row)
+	return ok
+}
+
+// position returns the partition and the position in it of the row given, itself rather than a row with the same
+// values, and whether the table has it.
+func (t *Table) position(row sql.Row) (string, int, bool) {
+	for key, partition := range t.partitions {
+		for i, r := range partition {
+			if sameRow(r, row) {
+				return key, i, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// sameRow returns whether two rows are the same row of a table. The tables keep their own copy of the rows inserted,
+// so that every row of a table is a different slice, that the changes of the rows replace.
+func sameRow(a, b sql.Row) bool {
+	return len(a) == len(b) && len(a) > 0 && &a[0] == &b[0]
+}
+
+func copyPartitions(partitions map[string][]sql.Row) map[string][]sql.Row {
+	c := make(map[string][]sql.Row, len(partitions))
+	for key, rows := range partitions {
+		c[key] = append([]sql.Row(nil), rows...)
+	}
+	return c
+}
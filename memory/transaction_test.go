@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestTransactions(t *testing.T) {
+	require := require.New(t)
+
+	db := NewDatabase("mydb")
+	newSession := func() *sql.Context {
+		return sql.NewContext(context.Background(), sql.WithSession(NewSession(sql.NewBaseSession())))
+	}
+	ctx1, ctx2 := newSession(), newSession()
+	require.NoError(db.CreateTable(ctx1, "t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t"},
+	}))
+
+	// rows returns the rows of the table the session of the context given sees, ordered by their primary key.
+	rows := func(ctx *sql.Context) []sql.Row {
+		table, ok, err := db.GetTableInsensitive(ctx, "t")
+		require.NoError(err)
+		require.True(ok)
+		rows := testFlatRows(t, table)
+		sort.Slice(rows, func(i, j int) bool {
+			return rows[i][0].(int64) < rows[j][0].(int64)
+		})
+		return rows
+	}
+	editor := func(ctx *sql.Context) *tableEditor {
+		table, _, err := db.GetTableInsensitive(ctx, "t")
+		require.NoError(err)
+		return table.(*Table).Updater(ctx).(*tableEditor)
+	}
+
+	require.NoError(editor(ctx1).Insert(ctx1, sql.NewRow(int64(1), "a")))
+
+	// The transaction sees the rows the table had when it first read it, even if they changed after.
+	s1 := ctx1.Session.(*Session)
+	require.NoError(s1.StartTransaction(ctx1))
+	require.True(s1.InTransaction())
+	require.Equal([]sql.Row{{int64(1), "a"}}, rows(ctx1))
+	require.NoError(editor(ctx2).Insert(ctx2, sql.NewRow(int64(2), "b")))
+	require.Equal([]sql.Row{{int64(1), "a"}}, rows(ctx1))
+
+	require.NoError(editor(ctx1).Insert(ctx1, sql.NewRow(int64(3), "c")))
+	require.NoError(editor(ctx1).Update(ctx1, sql.NewRow(int64(1), "a"), sql.NewRow(int64(1), "x")))
+	require.Equal([]sql.Row{{int64(1), "x"}, {int64(3), "c"}}, rows(ctx1))
+	require.Equal([]sql.Row{{int64(1), "a"}, {int64(2), "b"}}, rows(ctx2))
+
+	require.NoError(s1.CommitTransaction(ctx1))
+	require.False(s1.InTransaction())
+	expected := []sql.Row{{int64(1), "x"}, {int64(2), "b"}, {int64(3), "c"}}
+	require.Equal(expected, rows(ctx1))
+	require.Equal(expected, rows(ctx2))
+
+	// The changes rolled back are never seen.
+	require.NoError(s1.StartTransaction(ctx1))
+	require.NoError(editor(ctx1).Delete(ctx1, sql.NewRow(int64(2), "b")))
+	require.NoError(s1.Rollback(ctx1))
+	require.Equal(expected, rows(ctx1))
+
+	// The first transaction committing a change to a row wins, and the other one is rolled back whole.
+	s2 := ctx2.Session.(*Session)
+	require.NoError(s1.StartTransaction(ctx1))
+	require.NoError(s2.StartTransaction(ctx2))
+	require.NoError(editor(ctx2).Insert(ctx2, sql.NewRow(int64(4), "d")))
+	require.NoError(editor(ctx2).Update(ctx2, sql.NewRow(int64(3), "c"), sql.NewRow(int64(3), "z")))
+	require.NoError(editor(ctx1).Delete(ctx1, sql.NewRow(int64(3), "c")))
+	require.NoError(s1.CommitTransaction(ctx1))
+
+	err := s2.CommitTransaction(ctx2)
+	require.True(ErrWriteConflict.Is(err), "%v", err)
+	require.False(s2.InTransaction())
+	expected = []sql.Row{{int64(1), "x"}, {int64(2), "b"}}
+	require.Equal(expected, rows(ctx1))
+	require.Equal(expected, rows(ctx2))
+
+	// Changing the schema commits the transaction open first.
+	require.NoError(s1.StartTransaction(ctx1))
+	require.NoError(editor(ctx1).Insert(ctx1, sql.NewRow(int64(5), "e")))
+	table, _, err := db.GetTableInsensitive(ctx1, "t")
+	require.NoError(err)
+	require.NoError(table.(*Table).CreateIndex(ctx1, "s", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "s"}}, ""))
+	require.False(s1.InTransaction())
+	require.Equal([]sql.Row{{int64(1), "x"}, {int64(2), "b"}, {int64(5), "e"}}, rows(ctx2))
+
+	indexes, err := db.tables["t"].(*Table).GetIndexes(ctx2)
+	require.NoError(err)
+	require.Len(indexes, 1)
+}
+
+func TestTransactionConflicts(t *testing.T) {
+	require := require.New(t)
+
+	db := NewDatabase("mydb")
+	newSession := func() *sql.Context {
+		return sql.NewContext(context.Background(), sql.WithSession(NewSession(sql.NewBaseSession())))
+	}
+	ctx1, ctx2 := newSession(), newSession()
+	require.NoError(db.CreateTable(ctx1, "t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t"},
+	}))
+	require.NoError(db.CreateTable(ctx1, "u", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "u"},
+	}))
+
+	rows := func(ctx *sql.Context, name string) []sql.Row {
+		table, ok, err := db.GetTableInsensitive(ctx, name)
+		require.NoError(err)
+		require.True(ok)
+		return testFlatRows(t, table)
+	}
+	editor := func(ctx *sql.Context, name string) *tableEditor {
+		table, _, err := db.GetTableInsensitive(ctx, name)
+		require.NoError(err)
+		return table.(*Table).Updater(ctx).(*tableEditor)
+	}
+
+	require.NoError(editor(ctx1, "t").Insert(ctx1, sql.NewRow(int64(1), "a")))
+	require.NoError(editor(ctx1, "u").Insert(ctx1, sql.NewRow(int64(1))))
+	require.NoError(editor(ctx1, "u").Insert(ctx1, sql.NewRow(int64(1))))
+
+	// Two transactions deleting the same one of duplicate rows conflict, and the changes the one committing last made
+	// to the other tables are rolled back.
+	s1, s2 := ctx1.Session.(*Session), ctx2.Session.(*Session)
+	require.NoError(s1.StartTransaction(ctx1))
+	require.NoError(s2.StartTransaction(ctx2))
+	require.NoError(editor(ctx1, "t").Update(ctx1, sql.NewRow(int64(1), "a"), sql.NewRow(int64(1), "x")))
+	require.NoError(editor(ctx1, "u").Delete(ctx1, sql.NewRow(int64(1))))
+	require.NoError(editor(ctx2, "u").Delete(ctx2, sql.NewRow(int64(1))))
+	require.NoError(s2.CommitTransaction(ctx2))
+
+	err := s1.CommitTransaction(ctx1)
+	require.True(ErrWriteConflict.Is(err), "%v", err)
+	require.Equal([]sql.Row{{int64(1), "a"}}, rows(ctx1, "t"))
+	require.Equal([]sql.Row{{int64(1)}}, rows(ctx1, "u"))
+
+	// A row changed and changed back since the transaction read it is still a different row.
+	require.NoError(s1.StartTransaction(ctx1))
+	require.Equal([]sql.Row{{int64(1), "a"}}, rows(ctx1, "t"))
+	require.NoError(editor(ctx2, "t").Update(ctx2, sql.NewRow(int64(1), "a"), sql.NewRow(int64(1), "b")))
+	require.NoError(editor(ctx2, "t").Update(ctx2, sql.NewRow(int64(1), "b"), sql.NewRow(int64(1), "a")))
+	require.NoError(editor(ctx1, "t").Update(ctx1, sql.NewRow(int64(1), "a"), sql.NewRow(int64(1), "y")))
+
+	err = s1.CommitTransaction(ctx1)
+	require.True(ErrWriteConflict.Is(err), "%v", err)
+	require.Equal([]sql.Row{{int64(1), "a"}}, rows(ctx1, "t"))
+}
+
+func TestTransactionsConcurrency(t *testing.T) {
+	require := require.New(t)
+
+	db := NewDatabase("mydb")
+	ctx := sql.NewContext(context.Background(), sql.WithSession(NewSession(sql.NewBaseSession())))
+	require.NoError(db.CreateTable(ctx, "t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t"},
+	}))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			ctx := sql.NewContext(context.Background(), sql.WithSession(NewSession(sql.NewBaseSession())))
+			s := ctx.Session.(*Session)
+			for i := 0; i < 50; i++ {
+				if err := s.StartTransaction(ctx); err != nil {
+					errs <- err
+					return
+				}
+				table, _, err := db.GetTableInsensitive(ctx, "t")
+				if err != nil {
+					errs <- err
+					return
+				}
+				if err := table.(*Table).Insert(ctx, sql.NewRow(int64(w*1000+i), fmt.Sprint(i))); err != nil {
+					errs <- err
+					return
+				}
+				if err := s.CommitTransaction(ctx); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+
+	// The readers outside of transactions see the rows of every transaction committed.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		last := 0
+		for i := 0; i < 100; i++ {
+			table, _, err := db.GetTableInsensitive(ctx, "t")
+			if err != nil {
+				errs <- err
+				return
+			}
+			n := len(testFlatRows(t, table))
+			if n < last {
+				errs <- fmt.Errorf("read %d rows after %d", n, last)
+				return
+			}
+			last = n
+		}
+	}()
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(err)
+	}
+
+	table, _, err := db.GetTableInsensitive(ctx, "t")
+	require.NoError(err)
+	require.Len(testFlatRows(t, table), 200)
+}
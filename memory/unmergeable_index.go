@@ -148,6 +148,14 @@ func (u *indexValIter) Close() error {
 }
 
 func (u *UnmergeableIndexLookup) Values(p sql.Partition) (sql.IndexValueIter, error) {
+	return &indexValIter{
+		tbl:             u.idx.Tbl,
+		partition:       p,
+		matchExpression: u.evalExpression(),
+	}, nil
+}
+
+func (u *UnmergeableIndexLookup) evalExpression() sql.Expression {
 	var exprs []sql.Expression
 	for exprI, expr := range u.idx.Exprs {
 		lit, typ := getType(u.key[exprI])
@@ -157,12 +165,22 @@ func (u *UnmergeableIndexLookup) Values(p sql.Partition) (sql.IndexValueIter, er
 			exprs = append(exprs, expression.NewEquals(expr, expression.NewLiteral(lit, typ)))
 		}
 	}
+	return and(exprs...)
+}
 
-	return &indexValIter{
-		tbl:             u.idx.Tbl,
-		partition:       p,
-		matchExpression: and(exprs...),
-	}, nil
+// treeRows implements the treeLookup interface.
+func (u *UnmergeableIndexLookup) treeRows(partition string) ([]sql.Row, bool, error) {
+	if u.idx.tree == nil {
+		return nil, false, nil
+	}
+
+	key, ok := u.idx.tree.lookupKey(u.key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	rows, err := u.idx.tree.rows(partition, &indexEntry{key: key, bound: -1}, &indexEntry{key: key, bound: 1}, false, u.evalExpression())
+	return rows, true, err
 }
 
 func (u *UnmergeableIndexLookup) Indexes() []string {
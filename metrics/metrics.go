@@ -0,0 +1,44 @@
+// Package metrics exposes the metrics of the engine and the server. The
+// engine, the analyzer and the server record them in package level go-kit
+// metrics, which discard them by default; Install replaces all of them with
+// metrics created by a Provider, such as the Prometheus one of this package.
+package metrics
+
+import (
+	kitmetrics "github.com/go-kit/kit/metrics"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/server"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+)
+
+// Provider creates metrics. The label names given are the ones the metrics
+// are exported with; label values passed to With for other names, such as
+// the text of queries, may be ignored.
+type Provider interface {
+	// NewCounter creates a counter.
+	NewCounter(name, help string, labels ...string) kitmetrics.Counter
+	// NewGauge creates a gauge.
+	NewGauge(name, help string, labels ...string) kitmetrics.Gauge
+	// NewHistogram creates a histogram.
+	NewHistogram(name, help string, labels ...string) kitmetrics.Histogram
+}
+
+// Install replaces the metrics of the engine, the analyzer and the server
+// with ones created by the provider given. It must be called before creating
+// engines and servers.
+func Install(p Provider) {
+	server.ConnectionCounter = p.NewCounter("connections_total", "Number of connections accepted.")
+	server.ConnectionsGauge = p.NewGauge("connections", "Number of open connections.")
+
+	sqle.CommandCounter = p.NewCounter("commands_total", "Number of queries received, by command.", "command")
+	sqle.QueryCounter = p.NewCounter("queries_total", "Number of queries run successfully.")
+	sqle.QueryErrorCounter = p.NewCounter("query_errors_total", "Number of queries that failed.")
+	sqle.QueryHistogram = p.NewHistogram("query_duration_seconds", "Time it takes to run queries and read their rows.")
+	sqle.RowsWrittenCounter = p.NewCounter("rows_written_total", "Number of rows affected by queries.")
+
+	analyzer.RowsReadCounter = p.NewCounter("rows_read_total", "Number of rows read from tables.")
+	analyzer.ParallelQueryCounter = p.NewCounter("parallel_queries_total", "Number of queries run in parallel.", "parallelism")
+	analyzer.AnalyzeHistogram = p.NewHistogram("analyzer_duration_seconds", "Time it takes to analyze queries.")
+	analyzer.RuleHistogram = p.NewHistogram("analyzer_rule_duration_seconds", "Time it takes to apply analyzer rules.", "rule")
+}
@@ -0,0 +1,298 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	kitmetrics "github.com/go-kit/kit/metrics"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of the
+// histograms of a Prometheus provider, the same as the ones of the Prometheus
+// client libraries.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Prometheus is a Provider of metrics exported in the Prometheus text format.
+// It's an http.Handler to be mounted where Prometheus scrapes metrics from,
+// usually /metrics.
+type Prometheus struct {
+	namespace string
+	// Buckets are the upper bounds of the buckets of the histograms created
+	// after setting them. DefaultBuckets are used if it's empty.
+	Buckets []float64
+
+	mu      sync.Mutex
+	metrics map[string]*metric
+}
+
+var _ Provider = (*Prometheus)(nil)
+var _ http.Handler = (*Prometheus)(nil)
+
+// NewPrometheus creates a Prometheus provider whose metric names are
+// prefixed with the namespace given, if any.
+func NewPrometheus(namespace string) *Prometheus {
+	return &Prometheus{namespace: namespace, metrics: make(map[string]*metric)}
+}
+
+// NewCounter implements the Provider interface.
+func (p *Prometheus) NewCounter(name, help string, labels ...string) kitmetrics.Counter {
+	return &counter{m: p.register(name, help, "counter", labels, nil)}
+}
+
+// NewGauge implements the Provider interface.
+func (p *Prometheus) NewGauge(name, help string, labels ...string) kitmetrics.Gauge {
+	return &gauge{m: p.register(name, help, "gauge", labels, nil)}
+}
+
+// NewHistogram implements the Provider interface.
+func (p *Prometheus) NewHistogram(name, help string, labels ...string) kitmetrics.Histogram {
+	buckets := p.Buckets
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	return &histogram{m: p.register(name, help, "histogram", labels, buckets)}
+}
+
+func (p *Prometheus) register(name, help, kind string, labels []string, buckets []float64) *metric {
+	if p.namespace != "" {
+		name = p.namespace + "_" + name
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	m := &metric{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	p.metrics[name] = m
+	return m
+}
+
+// ServeHTTP implements the http.Handler interface, writing all the metrics.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = p.Write(w)
+}
+
+// Write writes all the metrics in the Prometheus text format, sorted by name.
+func (p *Prometheus) Write(w io.Writer) error {
+	p.mu.Lock()
+	metrics := make([]*metric, 0, len(p.metrics))
+	for _, m := range p.metrics {
+		metrics = append(metrics, m)
+	}
+	p.mu.Unlock()
+
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].name < metrics[j].name
+	})
+
+	var b strings.Builder
+	for _, m := range metrics {
+		m.write(&b)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// metric holds the series of a metric, one for each combination of values of
+// its labels.
+type metric struct {
+	name, help, kind string
+	labels           []string
+	buckets          []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	// value is the value of counters and gauges.
+	value float64
+	// counts are the number of observations of histograms by bucket, and sum
+	// and count the sum and number of all of them.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// with returns the values of the labels of the metric after applying the
+// label name and value pairs given to the ones given. Pairs for labels the
+// metric doesn't have are ignored.
+func (m *metric) with(values []string, pairs []string) []string {
+	result := make([]string, len(m.labels))
+	copy(result, values)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		for j, l := range m.labels {
+			if l == pairs[i] {
+				result[j] = pairs[i+1]
+			}
+		}
+	}
+	return result
+}
+
+// get returns the series with the label values given. The metric must be
+// locked.
+func (m *metric) get(values []string) *series {
+	if len(values) == 0 {
+		values = make([]string, len(m.labels))
+	}
+
+	key := strings.Join(values, "\xff")
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{labelValues: values}
+		if m.buckets != nil {
+			s.counts = make([]uint64, len(m.buckets))
+		}
+		m.series[key] = s
+	}
+	return s
+}
+
+func (m *metric) write(b *strings.Builder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n", m.name, helpEscaper.Replace(m.help))
+	fmt.Fprintf(b, "# TYPE %s %s\n", m.name, m.kind)
+
+	keys := make([]string, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := m.series[k]
+		if m.kind != "histogram" {
+			fmt.Fprintf(b, "%s%s %s\n", m.name, m.formatLabels(s.labelValues, ""), formatFloat(s.value))
+			continue
+		}
+
+		var cumulative uint64
+		for i, upper := range m.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(b, "%s_bucket%s %d\n", m.name, m.formatLabels(s.labelValues, formatFloat(upper)), cumulative)
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", m.name, m.formatLabels(s.labelValues, "+Inf"), s.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", m.name, m.formatLabels(s.labelValues, ""), formatFloat(s.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", m.name, m.formatLabels(s.labelValues, ""), s.count)
+	}
+}
+
+// formatLabels formats the labels of a series, along with the le label of
+// histogram buckets if given.
+func (m *metric) formatLabels(values []string, le string) string {
+	var pairs []string
+	for i, l := range m.labels {
+		pairs = append(pairs, l+`="`+labelEscaper.Replace(values[i])+`"`)
+	}
+	if le != "" {
+		pairs = append(pairs, `le="`+le+`"`)
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+type counter struct {
+	m      *metric
+	values []string
+}
+
+// With implements the metrics.Counter interface.
+func (c *counter) With(labelValues ...string) kitmetrics.Counter {
+	return &counter{m: c.m, values: c.m.with(c.values, labelValues)}
+}
+
+// Add implements the metrics.Counter interface.
+func (c *counter) Add(delta float64) {
+	c.m.mu.Lock()
+	c.m.get(c.values).value += delta
+	c.m.mu.Unlock()
+}
+
+type gauge struct {
+	m      *metric
+	values []string
+}
+
+// With implements the metrics.Gauge interface.
+func (g *gauge) With(labelValues ...string) kitmetrics.Gauge {
+	return &gauge{m: g.m, values: g.m.with(g.values, labelValues)}
+}
+
+// Set implements the metrics.Gauge interface.
+func (g *gauge) Set(value float64) {
+	g.m.mu.Lock()
+	g.m.get(g.values).value = value
+	g.m.mu.Unlock()
+}
+
+// Add implements the metrics.Gauge interface.
+func (g *gauge) Add(delta float64) {
+	g.m.mu.Lock()
+	g.m.get(g.values).value += delta
+	g.m.mu.Unlock()
+}
+
+type histogram struct {
+	m      *metric
+	values []string
+}
+
+// With implements the metrics.Histogram interface.
+func (h *histogram) With(labelValues ...string) kitmetrics.Histogram {
+	return &histogram{m: h.m, values: h.m.with(h.values, labelValues)}
+}
+
+// Observe implements the metrics.Histogram interface.
+func (h *histogram) Observe(value float64) {
+	h.m.mu.Lock()
+	defer h.m.mu.Unlock()
+
+	s := h.m.get(h.values)
+	s.sum += value
+	s.count++
+	for i, upper := range h.m.buckets {
+		if value <= upper {
+			s.counts[i]++
+			break
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/metrics"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+)
+
+func TestPrometheus(t *testing.T) {
+	require := require.New(t)
+
+	p := metrics.NewPrometheus("test")
+	p.Buckets = []float64{0.1, 1}
+
+	c := p.NewCounter("things_total", "Number of things.", "kind")
+	c.With("kind", "a").Add(1)
+	c.With("kind", "a", "ignored", "x").Add(2)
+	c.With("kind", "quote\"d\n").Add(1)
+
+	g := p.NewGauge("open", "Open things.")
+	g.Add(3)
+	g.Add(-1)
+
+	h := p.NewHistogram("duration_seconds", "Time of things.", "kind")
+	h.With("kind", "a").Observe(0.05)
+	h.With("kind", "a").Observe(0.5)
+	h.With("kind", "a").Observe(5)
+
+	var buf bytes.Buffer
+	require.NoError(p.Write(&buf))
+	require.Equal(`# HELP test_duration_seconds Time of things.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{kind="a",le="0.1"} 1
+test_duration_seconds_bucket{kind="a",le="1"} 2
+test_duration_seconds_bucket{kind="a",le="+Inf"} 3
+test_duration_seconds_sum{kind="a"} 5.55
+test_duration_seconds_count{kind="a"} 3
+# HELP test_open Open things.
+# TYPE test_open gauge
+test_open 2
+# HELP test_things_total Number of things.
+# TYPE test_things_total counter
+test_things_total{kind="a"} 3
+test_things_total{kind="quote\"d\n"} 1
+`, buf.String())
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(200, rec.Code)
+	require.Equal(buf.String(), rec.Body.String())
+}
+
+func TestInstall(t *testing.T) {
+	require := require.New(t)
+
+	p := metrics.NewPrometheus("gms")
+	metrics.Install(p)
+
+	db := memory.NewDatabase("mydb")
+	db.AddTable("a", memory.NewTable("a", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "a"},
+	}))
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	e := sqle.New(catalog, analyzer.NewBuilder(catalog).Build(), nil)
+
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession()))
+	ctx.SetCurrentDatabase("mydb")
+	for _, q := range []string{
+		"INSERT INTO a VALUES (1), (2)",
+		"SELECT * FROM a",
+		"  select * from a where i = 1",
+		"SELECT * FROM nope",
+	} {
+		_, iter, err := e.Query(ctx, q)
+		if err == nil {
+			_, err = sql.RowIterToRows(iter)
+			require.NoError(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(p.Write(&buf))
+	out := buf.String()
+
+	for _, line := range []string{
+		`gms_commands_total{command="insert"} 1`,
+		`gms_commands_total{command="select"} 3`,
+		`gms_queries_total 3`,
+		`gms_query_errors_total 1`,
+		`gms_query_duration_seconds_count 3`,
+		`gms_rows_written_total 2`,
+		`gms_rows_read_total 4`,
+		`gms_analyzer_duration_seconds_count 4`,
+	} {
+		require.Contains(out, line+"\n")
+	}
+	require.True(strings.Contains(out, `gms_analyzer_rule_duration_seconds_count{rule="resolve_tables"}`))
+}
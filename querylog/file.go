@@ -0,0 +1,115 @@
+package querylog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// timeFormat is the format of the timestamps of the logs, as MySQL writes
+// them with log_timestamps=UTC.
+const timeFormat = "2006-01-02T15:04:05.000000Z"
+
+// generalEntry is an entry of the general query log.
+type generalEntry struct {
+	time     time.Time
+	client   sql.Client
+	id       uint32
+	command  string
+	argument string
+}
+
+// slowEntry is an entry of the slow query log.
+type slowEntry struct {
+	start     time.Time
+	client    sql.Client
+	id        uint32
+	db        string
+	query     string
+	queryTime time.Duration
+	rowsSent  int64
+}
+
+// userHost returns the account of a client in the form of the user_host
+// column of the log tables.
+func userHost(client sql.Client) string {
+	priv := client.User
+	if client.ProxyUser != "" {
+		priv = client.ProxyUser
+	}
+	return fmt.Sprintf("%s[%s] @  [%s]", priv, client.User, clientHost(client.Address))
+}
+
+// logFile is a log file with the header MySQL writes when opening it.
+type logFile struct {
+	mu     sync.Mutex
+	w      io.WriteCloser
+	lastDB string
+}
+
+func openLogFile(path string) (*logFile, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	header := fmt.Sprintf(
+		"%s, Version: go-mysql-server. started with:\n"+
+			"Tcp port: 0  Unix socket: (null)\n"+
+			"Time                 Id Command    Argument\n",
+		filepath.Base(os.Args[0]),
+	)
+	if _, err := io.WriteString(f, header); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return &logFile{w: f}, nil
+}
+
+func (f *logFile) writeGeneral(e generalEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, err := fmt.Fprintf(f.w, "%s\t%5d %s\t%s\n",
+		e.time.UTC().Format(timeFormat), e.id, e.command, e.argument)
+	return err
+}
+
+func (f *logFile) writeSlow(e slowEntry) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Time: %s\n", e.start.Add(e.queryTime).UTC().Format(timeFormat))
+	fmt.Fprintf(&b, "# User@Host: %s  Id: %5d\n", userHost(e.client), e.id)
+	fmt.Fprintf(&b, "# Query_time: %.6f  Lock_time: %.6f Rows_sent: %d  Rows_examined: %d\n",
+		e.queryTime.Seconds(), 0.0, e.rowsSent, 0)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if e.db != "" && e.db != f.lastDB {
+		fmt.Fprintf(&b, "use %s;\n", e.db)
+		f.lastDB = e.db
+	}
+	fmt.Fprintf(&b, "SET timestamp=%d;\n", e.start.Unix())
+	b.WriteString(e.query)
+	if !strings.HasSuffix(strings.TrimSpace(e.query), ";") {
+		b.WriteString(";")
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(f.w, b.String())
+	return err
+}
+
+// Close closes the file.
+func (f *logFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.w.Close()
+}
@@ -0,0 +1,289 @@
+// Package querylog implements the general query log and the slow query log
+// of MySQL, in the formats MySQL writes them, so that existing log analysis
+// tools can read them. Logs are written to files, to the general_log and
+// slow_log tables of a mysql database, or both.
+package querylog
+
+import (
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+const (
+	// GeneralLogSessionVar enables the general query log for a session.
+	GeneralLogSessionVar = "general_log"
+	// SlowQueryLogSessionVar enables the slow query log for a session.
+	SlowQueryLogSessionVar = "slow_query_log"
+	// LongQueryTimeSessionVar is the number of seconds after which the
+	// queries of a session are slow.
+	LongQueryTimeSessionVar = "long_query_time"
+)
+
+// defaultLongQueryTime is the default value of @@long_query_time.
+const defaultLongQueryTime = 10 * time.Second
+
+// Output is where a Logger writes its logs.
+type Output int
+
+const (
+	// OutputFile writes logs to files.
+	OutputFile Output = 1 << iota
+	// OutputTable writes logs to the tables of the database returned by
+	// Logger.Database.
+	OutputTable
+)
+
+// Config configures a Logger.
+type Config struct {
+	// GeneralLog enables the general query log for all sessions. Sessions
+	// can also enable it for themselves by setting @@general_log.
+	GeneralLog bool
+	// GeneralLogFile is the path of the general query log file.
+	GeneralLogFile string
+	// SlowQueryLog enables the slow query log for all sessions. Sessions can
+	// also enable it for themselves by setting @@slow_query_log. Queries are
+	// slow once they take longer than @@long_query_time seconds.
+	SlowQueryLog bool
+	// SlowQueryLogFile is the path of the slow query log file.
+	SlowQueryLogFile string
+	// Output is where logs are written. Zero means OutputFile.
+	Output Output
+	// MaxTableRows is the number of rows each log table keeps, dropping the
+	// oldest ones. Zero keeps them all.
+	MaxTableRows int
+	// ServerID is the server_id of the rows of the log tables.
+	ServerID uint32
+}
+
+// Logger writes the general query log and the slow query log. A nil Logger
+// discards all entries.
+type Logger struct {
+	config        Config
+	general, slow *logFile
+	db            *Database
+}
+
+// NewLogger creates a Logger, opening or creating the log files configured.
+func NewLogger(config Config) (*Logger, error) {
+	if config.Output == 0 {
+		config.Output = OutputFile
+	}
+
+	l := &Logger{config: config}
+	if config.Output&OutputFile != 0 {
+		var err error
+		if config.GeneralLogFile != "" {
+			if l.general, err = openLogFile(config.GeneralLogFile); err != nil {
+				return nil, err
+			}
+		}
+
+		if config.SlowQueryLogFile != "" {
+			if l.slow, err = openLogFile(config.SlowQueryLogFile); err != nil {
+				_ = l.Close()
+				return nil, err
+			}
+		}
+	}
+
+	if config.Output&OutputTable != 0 {
+		l.db = newDatabase(config.MaxTableRows)
+	}
+
+	return l, nil
+}
+
+// Database returns the mysql database holding the general_log and slow_log
+// tables, or nil if the logger doesn't write to tables. It must be added to
+// the catalog for the tables to be queried.
+func (l *Logger) Database() *Database {
+	if l == nil || l.db == nil {
+		return nil
+	}
+	return l.db
+}
+
+// Close closes the log files.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	var err error
+	for _, f := range []*logFile{l.general, l.slow} {
+		if f != nil {
+			if cerr := f.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+	}
+	return err
+}
+
+// Connect writes the connection of a client with the session of the context
+// given to the general query log. The error is the reason the connection was
+// rejected, if it was.
+func (l *Logger) Connect(ctx *sql.Context, db string, err error) {
+	if !l.generalEnabled(ctx) {
+		return
+	}
+
+	client := ctx.Client()
+	argument := client.User + "@" + clientHost(client.Address) + " on " + db + " using TCP/IP"
+	if err != nil {
+		argument = err.Error()
+	}
+	l.writeGeneral(ctx, time.Now(), "Connect", argument)
+}
+
+// Quit writes the disconnection of the client of the session of the context
+// given to the general query log.
+func (l *Logger) Quit(ctx *sql.Context) {
+	if !l.generalEnabled(ctx) {
+		return
+	}
+	l.writeGeneral(ctx, time.Now(), "Quit", "")
+}
+
+// Query writes a query to the general query log as it's received, before it
+// runs.
+func (l *Logger) Query(ctx *sql.Context, query string) {
+	if !l.generalEnabled(ctx) {
+		return
+	}
+	l.writeGeneral(ctx, time.Now(), "Query", query)
+}
+
+// TrackQuery returns an iterator of the rows of a query, which started at the
+// time given, that writes it to the slow query log once closed if it took
+// longer than @@long_query_time.
+func (l *Logger) TrackQuery(ctx *sql.Context, query string, start time.Time, iter sql.RowIter) sql.RowIter {
+	if !l.slowEnabled(ctx) {
+		return iter
+	}
+
+	return &trackedIter{
+		log: l,
+		ctx: ctx,
+		entry: slowEntry{
+			start:  start,
+			client: ctx.Client(),
+			id:     ctx.ID(),
+			db:     ctx.GetCurrentDatabase(),
+			query:  query,
+		},
+		iter: iter,
+	}
+}
+
+func (l *Logger) writeGeneral(ctx *sql.Context, t time.Time, command, argument string) {
+	e := generalEntry{
+		time:     t,
+		client:   ctx.Client(),
+		id:       ctx.ID(),
+		command:  command,
+		argument: argument,
+	}
+
+	if l.general != nil {
+		if err := l.general.writeGeneral(e); err != nil {
+			logrus.WithField("system", "querylog").Errorf("unable to write to the general query log: %s", err)
+		}
+	}
+
+	if l.db != nil {
+		l.db.general.add(e.row(l.config.ServerID))
+	}
+}
+
+func (l *Logger) writeSlow(e slowEntry) {
+	if l.slow != nil {
+		if err := l.slow.writeSlow(e); err != nil {
+			logrus.WithField("system", "querylog").Errorf("unable to write to the slow query log: %s", err)
+		}
+	}
+
+	if l.db != nil {
+		l.db.slow.add(e.row(l.config.ServerID))
+	}
+}
+
+func (l *Logger) generalEnabled(ctx *sql.Context) bool {
+	return l != nil && (l.config.GeneralLog || sessionBool(ctx, GeneralLogSessionVar))
+}
+
+func (l *Logger) slowEnabled(ctx *sql.Context) bool {
+	return l != nil && (l.config.SlowQueryLog || sessionBool(ctx, SlowQueryLogSessionVar))
+}
+
+// sessionBool returns the value of a boolean session variable, which may
+// have been set to ON or OFF as well as to a number.
+func sessionBool(ctx *sql.Context, name string) bool {
+	_, v := ctx.Get(name)
+	if s, ok := v.(string); ok {
+		switch strings.ToLower(s) {
+		case "on", "true":
+			return true
+		case "off", "false":
+			return false
+		}
+	}
+
+	b, err := sql.ConvertToBool(v)
+	return err == nil && b
+}
+
+// longQueryTime returns the duration after which the queries of the session
+// of the context given are slow.
+func longQueryTime(ctx *sql.Context) time.Duration {
+	_, v := ctx.Get(LongQueryTimeSessionVar)
+	if v == nil {
+		return defaultLongQueryTime
+	}
+
+	seconds, err := sql.Float64.Convert(v)
+	if err != nil {
+		return defaultLongQueryTime
+	}
+	return time.Duration(seconds.(float64) * float64(time.Second))
+}
+
+// clientHost returns the host of a client address, without its port.
+func clientHost(address string) string {
+	if i := strings.LastIndex(address, ":"); i >= 0 {
+		return address[:i]
+	}
+	return address
+}
+
+type trackedIter struct {
+	log   *Logger
+	ctx   *sql.Context
+	entry slowEntry
+	iter  sql.RowIter
+	done  bool
+}
+
+func (i *trackedIter) Next() (sql.Row, error) {
+	row, err := i.iter.Next()
+	if err == nil {
+		i.entry.rowsSent++
+	}
+	return row, err
+}
+
+func (i *trackedIter) Close() error {
+	err := i.iter.Close()
+	if !i.done {
+		i.done = true
+		i.entry.queryTime = time.Since(i.entry.start)
+		if i.entry.queryTime > longQueryTime(i.ctx) {
+			i.log.writeSlow(i.entry)
+		}
+	}
+	return err
+}
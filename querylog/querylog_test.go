@@ -0,0 +1,174 @@
+package querylog_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/querylog"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+)
+
+func logEngine(t *testing.T, l *querylog.Logger) *sqle.Engine {
+	db := memory.NewDatabase("mydb")
+	table := memory.NewTable("a", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "a"},
+	})
+	db.AddTable("a", table)
+
+	ctx := sql.NewEmptyContext()
+	require.NoError(t, table.Insert(ctx, sql.NewRow(int64(1))))
+	require.NoError(t, table.Insert(ctx, sql.NewRow(int64(2))))
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	if ldb := l.Database(); ldb != nil {
+		catalog.AddDatabase(ldb)
+	}
+
+	a := analyzer.NewBuilder(catalog).Build()
+	return sqle.New(catalog, a, &sqle.Config{QueryLog: l})
+}
+
+func newContext() *sql.Context {
+	session := sql.NewSessionWithClient("localhost:3306", sql.Client{User: "root", Address: "127.0.0.1:1234"}, 1)
+	ctx := sql.NewContext(context.Background(), sql.WithSession(session))
+	ctx.SetCurrentDatabase("mydb")
+	return ctx
+}
+
+func query(t *testing.T, e *sqle.Engine, ctx *sql.Context, q string) []sql.Row {
+	_, iter, err := e.Query(ctx, q)
+	require.NoError(t, err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(t, err)
+	return rows
+}
+
+func TestGeneralLogFile(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "querylog")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "general.log")
+	l, err := querylog.NewLogger(querylog.Config{GeneralLog: true, GeneralLogFile: path})
+	require.NoError(err)
+
+	e := logEngine(t, l)
+	ctx := newContext()
+	l.Connect(ctx, "mydb", nil)
+	query(t, e, ctx, "SELECT * FROM a")
+	l.Quit(ctx)
+	require.NoError(l.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(lines, 6)
+	require.Equal("Time                 Id Command    Argument", lines[2])
+
+	entry := regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}Z\t    1 (.*)$`)
+	var entries []string
+	for _, line := range lines[3:] {
+		m := entry.FindStringSubmatch(line)
+		require.NotNil(m, line)
+		entries = append(entries, m[1])
+	}
+
+	require.Equal([]string{
+		"Connect\troot@127.0.0.1 on mydb using TCP/IP",
+		"Query\tSELECT * FROM a",
+		"Quit\t",
+	}, entries)
+}
+
+func TestSlowLogFile(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "querylog")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "slow.log")
+	l, err := querylog.NewLogger(querylog.Config{SlowQueryLog: true, SlowQueryLogFile: path})
+	require.NoError(err)
+
+	e := logEngine(t, l)
+	ctx := newContext()
+	query(t, e, ctx, "SELECT * FROM a")
+	query(t, e, ctx, "SET @@long_query_time = 0")
+	query(t, e, ctx, "SELECT * FROM a")
+	query(t, e, ctx, "SELECT * FROM a WHERE i = 1;")
+	require.NoError(l.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(err)
+
+	entry := regexp.MustCompile(`# Time: \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}Z
+# User@Host: root\[root\] @  \[127\.0\.0\.1\]  Id:     1
+# Query_time: \d+\.\d{6}  Lock_time: 0\.000000 Rows_sent: (\d+)  Rows_examined: 0
+(use mydb;
+)?SET timestamp=\d+;
+(.*)
+`)
+	matches := entry.FindAllStringSubmatch(string(data), -1)
+	require.Len(matches, 3)
+
+	// The threshold is checked once statements finish, as MySQL does, so the
+	// statement changing it is slow already.
+	require.Equal("use mydb;\n", matches[0][2])
+	require.Equal("SET @@long_query_time = 0;", matches[0][3])
+
+	require.Equal("2", matches[1][1])
+	require.Equal("", matches[1][2])
+	require.Equal("SELECT * FROM a;", matches[1][3])
+
+	require.Equal("1", matches[2][1])
+	require.Equal("", matches[2][2])
+	require.Equal("SELECT * FROM a WHERE i = 1;", matches[2][3])
+}
+
+func TestLogTables(t *testing.T) {
+	require := require.New(t)
+
+	l, err := querylog.NewLogger(querylog.Config{Output: querylog.OutputTable, MaxTableRows: 2})
+	require.NoError(err)
+
+	e := logEngine(t, l)
+	ctx := newContext()
+
+	// Sessions enable the logs for themselves.
+	query(t, e, ctx, "SELECT * FROM a")
+	require.Empty(query(t, e, ctx, "SELECT * FROM mysql.general_log"))
+	query(t, e, ctx, "SET @@general_log = ON, @@slow_query_log = 1, @@long_query_time = 0")
+	query(t, e, ctx, "SELECT * FROM a")
+
+	rows := query(t, e, ctx, "SELECT user_host, thread_id, command_type, argument FROM mysql.general_log")
+	require.Equal([]sql.Row{
+		{"root[root] @  [127.0.0.1]", uint64(1), "Query", "SELECT * FROM a"},
+		{"root[root] @  [127.0.0.1]", uint64(1), "Query", "SELECT user_host, thread_id, command_type, argument FROM mysql.general_log"},
+	}, rows)
+
+	// Only the last two rows are kept.
+	rows = query(t, e, ctx, "SELECT rows_sent, db, sql_text, thread_id FROM mysql.slow_log")
+	require.Equal([]sql.Row{
+		{int32(2), "mydb", "SELECT * FROM a", uint64(1)},
+		{int32(2), "mydb", "SELECT user_host, thread_id, command_type, argument FROM mysql.general_log", uint64(1)},
+	}, rows)
+}
+
+func TestNilLogger(t *testing.T) {
+	e := logEngine(t, nil)
+	query(t, e, newContext(), "SELECT * FROM a")
+}
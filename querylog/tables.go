@@ -0,0 +1,194 @@
+package querylog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+const (
+	// DatabaseName is the name of the database holding the log tables.
+	DatabaseName = "mysql"
+	// GeneralLogTableName is the name of the general query log table.
+	GeneralLogTableName = "general_log"
+	// SlowLogTableName is the name of the slow query log table.
+	SlowLogTableName = "slow_log"
+)
+
+var generalLogSchema = sql.Schema{
+	{Name: "event_time", Type: sql.Timestamp, Source: GeneralLogTableName},
+	{Name: "user_host", Type: sql.MediumText, Source: GeneralLogTableName},
+	{Name: "thread_id", Type: sql.Uint64, Source: GeneralLogTableName},
+	{Name: "server_id", Type: sql.Uint32, Source: GeneralLogTableName},
+	{Name: "command_type", Type: sql.MustCreateStringWithDefaults(sqltypes.VarChar, 64), Source: GeneralLogTableName},
+	{Name: "argument", Type: sql.MediumBlob, Source: GeneralLogTableName},
+}
+
+var slowLogSchema = sql.Schema{
+	{Name: "start_time", Type: sql.Timestamp, Source: SlowLogTableName},
+	{Name: "user_host", Type: sql.MediumText, Source: SlowLogTableName},
+	{Name: "query_time", Type: sql.Time, Source: SlowLogTableName},
+	{Name: "lock_time", Type: sql.Time, Source: SlowLogTableName},
+	{Name: "rows_sent", Type: sql.Int32, Source: SlowLogTableName},
+	{Name: "rows_examined", Type: sql.Int32, Source: SlowLogTableName},
+	{Name: "db", Type: sql.MustCreateStringWithDefaults(sqltypes.VarChar, 512), Source: SlowLogTableName},
+	{Name: "last_insert_id", Type: sql.Int32, Source: SlowLogTableName},
+	{Name: "insert_id", Type: sql.Int32, Source: SlowLogTableName},
+	{Name: "server_id", Type: sql.Uint32, Source: SlowLogTableName},
+	{Name: "sql_text", Type: sql.MediumBlob, Source: SlowLogTableName},
+	{Name: "thread_id", Type: sql.Uint64, Source: SlowLogTableName},
+}
+
+func (e generalEntry) row(serverID uint32) sql.Row {
+	return sql.NewRow(
+		e.time,
+		userHost(e.client),
+		uint64(e.id),
+		serverID,
+		e.command,
+		e.argument,
+	)
+}
+
+func (e slowEntry) row(serverID uint32) sql.Row {
+	return sql.NewRow(
+		e.start,
+		userHost(e.client),
+		formatTime(e.queryTime),
+		formatTime(0),
+		int32(e.rowsSent),
+		int32(0),
+		e.db,
+		int32(0),
+		int32(0),
+		serverID,
+		e.query,
+		uint64(e.id),
+	)
+}
+
+// formatTime formats a duration as a TIME(6) value.
+func formatTime(d time.Duration) string {
+	micros := d.Microseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%06d",
+		micros/3600000000, micros/60000000%60, micros/1000000%60, micros%1000000)
+}
+
+// Database is the mysql database holding the general_log and slow_log tables
+// a Logger writes to.
+type Database struct {
+	general, slow *logTable
+}
+
+var _ sql.Database = (*Database)(nil)
+
+func newDatabase(maxRows int) *Database {
+	return &Database{
+		general: &logTable{name: GeneralLogTableName, schema: generalLogSchema, maxRows: maxRows},
+		slow:    &logTable{name: SlowLogTableName, schema: slowLogSchema, maxRows: maxRows},
+	}
+}
+
+// Name implements the sql.Database interface.
+func (d *Database) Name() string {
+	return DatabaseName
+}
+
+// GetTableInsensitive implements the sql.Database interface.
+func (d *Database) GetTableInsensitive(ctx *sql.Context, tblName string) (sql.Table, bool, error) {
+	switch strings.ToLower(tblName) {
+	case GeneralLogTableName:
+		return d.general, true, nil
+	case SlowLogTableName:
+		return d.slow, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// GetTableNames implements the sql.Database interface.
+func (d *Database) GetTableNames(ctx *sql.Context) ([]string, error) {
+	return []string{GeneralLogTableName, SlowLogTableName}, nil
+}
+
+// logTable is a log table, holding the rows of its log in memory.
+type logTable struct {
+	name    string
+	schema  sql.Schema
+	maxRows int
+
+	mu   sync.RWMutex
+	rows []sql.Row
+}
+
+var _ sql.Table = (*logTable)(nil)
+
+func (t *logTable) add(row sql.Row) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rows = append(t.rows, row)
+	if t.maxRows > 0 && len(t.rows) > t.maxRows {
+		t.rows = append(t.rows[:0:0], t.rows[len(t.rows)-t.maxRows:]...)
+	}
+}
+
+// Name implements the sql.Table interface.
+func (t *logTable) Name() string {
+	return t.name
+}
+
+// String implements the sql.Table interface.
+func (t *logTable) String() string {
+	return t.name
+}
+
+// Schema implements the sql.Table interface.
+func (t *logTable) Schema() sql.Schema {
+	return t.schema
+}
+
+// Partitions implements the sql.Table interface.
+func (t *logTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &partitionIter{}, nil
+}
+
+// PartitionRows implements the sql.Table interface.
+func (t *logTable) PartitionRows(*sql.Context, sql.Partition) (sql.RowIter, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rows := make([]sql.Row, len(t.rows))
+	copy(rows, t.rows)
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// partition is the single partition of a log table.
+type partition struct{}
+
+// Key implements the sql.Partition interface.
+func (partition) Key() []byte { return []byte("log") }
+
+type partitionIter struct {
+	done bool
+}
+
+// Next implements the sql.PartitionIter interface.
+func (i *partitionIter) Next() (sql.Partition, error) {
+	if i.done {
+		return nil, io.EOF
+	}
+	i.done = true
+	return partition{}, nil
+}
+
+// Close implements the sql.PartitionIter interface.
+func (i *partitionIter) Close() error {
+	return nil
+}
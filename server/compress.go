@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+)
+
+const (
+	// capabilityClientCompress is CLIENT_COMPRESS, which the MySQL protocol
+	// implementation doesn't define since it doesn't support it.
+	capabilityClientCompress = 1 << 5
+	// maxCompressedPayload is the longest payload of a compressed packet.
+	maxCompressedPayload = 1<<24 - 1
+	// minCompressLength is the length of the shortest payloads compressed,
+	// as in MySQL. Shorter ones are sent uncompressed.
+	minCompressLength = 50
+)
+
+// readCompressedPacket reads a packet of the compressed protocol, returning
+// its payload, uncompressed, and its sequence number.
+func readCompressedPacket(r io.Reader) ([]byte, uint8, error) {
+	var header [7]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, 0, err
+	}
+
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	seq := header[3]
+	uncompressed := int(header[4]) | int(header[5])<<8 | int(header[6])<<16
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, 0, err
+	}
+
+	// Payloads with an uncompressed length of zero weren't compressed.
+	if uncompressed == 0 {
+		return payload, seq, nil
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer zr.Close()
+
+	data := make([]byte, uncompressed)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return nil, 0, err
+	}
+	return data, seq, nil
+}
+
+// writeCompressedPackets writes the data given in packets of the compressed
+// protocol, starting with the sequence number given, and returns the one of
+// the next packet.
+func writeCompressedPackets(w io.Writer, data []byte, seq uint8) (uint8, error) {
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > maxCompressedPayload {
+			chunk = chunk[:maxCompressedPayload]
+		}
+		data = data[len(chunk):]
+
+		payload, uncompressed := chunk, 0
+		if len(chunk) >= minCompressLength {
+			var buf bytes.Buffer
+			zw := zlib.NewWriter(&buf)
+			if _, err := zw.Write(chunk); err != nil {
+				return seq, err
+			}
+			if err := zw.Close(); err != nil {
+				return seq, err
+			}
+
+			if buf.Len() < len(chunk) {
+				payload, uncompressed = buf.Bytes(), len(chunk)
+			}
+		}
+
+		packet := make([]byte, 7, 7+len(payload))
+		packet[0], packet[1], packet[2] = byte(len(payload)), byte(len(payload)>>8), byte(len(payload)>>16)
+		packet[3] = seq
+		packet[4], packet[5], packet[6] = byte(uncompressed), byte(uncompressed>>8), byte(uncompressed>>16)
+		packet = append(packet, payload...)
+
+		if _, err := w.Write(packet); err != nil {
+			return seq, err
+		}
+		seq++
+	}
+	return seq, nil
+}
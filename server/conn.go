@@ -0,0 +1,571 @@
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/dolthub/vitess/go/mysql"
+
+	"github.com/dolthub/go-mysql-server/auth"
+)
+
+// serverConn is a net.Conn of a connection accepted by a Server, which
+// implements the parts of the MySQL protocol the protocol implementation used
+// by the server doesn't. It reads the handshake response of the client, the
+// first packet it sends, to keep the connection attributes in it, and if
+// compress is set, announces the compressed protocol and compresses the
+// packets after the handshake for the clients asking for it. After the
+// handshake, it reads the commands of the client to run the cursors of its
+// prepared statements.
+//
+// If tlsConfig is set, it announces TLS and switches the connection to TLS
+// for the clients asking for it itself, instead of the protocol
+// implementation, so that their packets can still be read once encrypted.
+// The protocol implementation goes on with the handshake as if they hadn't
+// asked for it, so the sequence numbers of the rest of the handshake are
+// shifted by one, and it's unaware of the connections using TLS, which is
+// why serverConn refuses the authentication methods sending passwords in
+// clear text over the others unless allowClearText is set.
+type serverConn struct {
+	net.Conn
+	// socket is the connection accepted, which Conn wraps if the server has
+	// connection middleware.
+	socket         net.Conn
+	compress       bool
+	tlsConfig      *tls.Config
+	allowClearText bool
+	// remote is the address of the client, if it's connected through a Unix
+	// domain socket.
+	remote *auth.PeerAddr
+	// proxied is set if the connection comes from a proxy, which starts it
+	// with a PROXY protocol header. Once the header is read, client is the
+	// address of the client it proxies, if it sent one, or proxyErr the
+	// error reading it.
+	proxied   bool
+	proxyOnce sync.Once
+	client    net.Addr
+	proxyErr  error
+
+	mu sync.Mutex
+	// in holds the bytes of the packets of the handshake read not read yet,
+	// and responded is set once the handshake response, the flags and
+	// connection attributes of which are kept, has been read.
+	in        []byte
+	responded bool
+	flags     uint32
+	attrs     map[string]string
+	// secure is the TLS connection of the client once it has switched to
+	// TLS, and shift the number the sequence numbers of the packets of the
+	// handshake are shifted by then.
+	secure *tls.Conn
+	shift  uint8
+	// out holds the bytes written of the packet of the handshake being
+	// written, until handshaken is set, once the server has written the
+	// result of the authentication.
+	out        []byte
+	greeted    bool
+	handshaken bool
+	// compressed is set once the handshake ends for clients asking for
+	// compression. Then, seq is the sequence number of the next compressed
+	// packet written, and pending holds the payload of the last compressed
+	// packet read not read yet.
+	compressed bool
+	seq        uint8
+	pending    []byte
+	// commands holds the bytes of the commands read after the handshake not
+	// read yet, and continued is set if the last packet read is followed by
+	// the rest of its payload.
+	commands  []byte
+	continued bool
+	// cursors are the cursors of the prepared statements of the connection,
+	// by statement ID, and request is the command opening or fetching from
+	// one being run.
+	cursors map[uint32]*cursor
+	request *cursorRequest
+	// handler and conn are the handler of the connection and its
+	// connection, once it has been created, which run the commands of the
+	// replication protocol.
+	handler *Handler
+	conn    *mysql.Conn
+}
+
+func newServerConn(conn net.Conn, compress bool) *serverConn {
+	return &serverConn{Conn: conn, socket: conn, compress: compress}
+}
+
+// RemoteAddr implements the net.Conn interface. The address of the clients
+// connected through a Unix domain socket is an auth.PeerAddr, and the one of
+// the clients connected through a proxy is the one in its PROXY protocol
+// header.
+func (c *serverConn) RemoteAddr() net.Addr {
+	if c.remote != nil {
+		return c.remote
+	}
+	if c.readProxyHeader() == nil && c.client != nil {
+		return c.client
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// readProxyHeader reads the PROXY protocol header of the connection the first
+// time it's called, if it comes from a proxy.
+func (c *serverConn) readProxyHeader() error {
+	if !c.proxied {
+		return nil
+	}
+
+	c.proxyOnce.Do(func() {
+		c.client, c.proxyErr = readProxyHeader(c.Conn)
+	})
+	return c.proxyErr
+}
+
+// Read implements the net.Conn interface.
+func (c *serverConn) Read(b []byte) (int, error) {
+	if err := c.readProxyHeader(); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	handshaken := c.handshaken
+	c.mu.Unlock()
+
+	if handshaken {
+		return c.readCommands(b)
+	}
+	return c.readHandshake(b)
+}
+
+// readHandshake reads the packets of the handshake of the client, switching
+// the connection to TLS if it asks for it.
+func (c *serverConn) readHandshake(b []byte) (int, error) {
+	for len(c.in) == 0 {
+		packet, err := c.readPacket()
+		if err != nil {
+			return 0, err
+		}
+
+		c.mu.Lock()
+		responded := c.responded
+		c.mu.Unlock()
+
+		if !responded {
+			response := packet[4:]
+			if c.tlsConfig != nil && c.secure == nil && isSSLRequest(response) {
+				if err := c.startTLS(); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			c.respond(response)
+		}
+
+		packet[3] -= c.shift
+		c.in = packet
+	}
+
+	n := copy(b, c.in)
+	c.in = c.in[n:]
+	return n, nil
+}
+
+// isSSLRequest returns whether a handshake response is a request to switch
+// to TLS, which only has the fields before the user name.
+func isSSLRequest(response []byte) bool {
+	return len(response) == 32 && binary.LittleEndian.Uint32(response)&mysql.CapabilityClientSSL != 0
+}
+
+// startTLS switches the connection to TLS, after the client asked for it.
+func (c *serverConn) startTLS() error {
+	conn := tls.Server(c.Conn, c.tlsConfig)
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.secure, c.shift = conn, 1
+	return nil
+}
+
+// respond keeps the flags and connection attributes of the handshake
+// response of the client.
+func (c *serverConn) respond(response []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(response) >= 4 {
+		c.flags = binary.LittleEndian.Uint32(response)
+	}
+	c.attrs = parseConnectAttrs(response)
+	c.responded = true
+}
+
+// transport returns the connection the packets of the client are read from
+// and written to, which is the TLS connection once it has switched to TLS.
+func (c *serverConn) transport() net.Conn {
+	if c.secure != nil {
+		return c.secure
+	}
+	return c.Conn
+}
+
+// TLSConnectionState returns the state of the TLS connection of the client,
+// and whether it switched to TLS.
+func (c *serverConn) TLSConnectionState() (tls.ConnectionState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.secure == nil {
+		return tls.ConnectionState{}, false
+	}
+	return c.secure.ConnectionState(), true
+}
+
+// readCommands reads the packets of the commands of the client, which the
+// cursors of the connection and the replication commands are run for.
+func (c *serverConn) readCommands(b []byte) (int, error) {
+	for len(c.commands) == 0 {
+		packet, err := c.readPacket()
+		if err != nil {
+			return 0, err
+		}
+
+		if ok, err := c.runReplicationCommand(packet); ok {
+			if err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		c.commands, err = c.runCursorCommand(packet)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(b, c.commands)
+	c.commands = c.commands[n:]
+	return n, nil
+}
+
+// readPacket reads a packet, with its header, uncompressing it if needed.
+func (c *serverConn) readPacket() ([]byte, error) {
+	r := io.Reader(c.transport())
+	if c.compressed {
+		r = readerFunc(c.readCompressed)
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	packet := make([]byte, 4+length)
+	copy(packet, header)
+	if _, err := io.ReadFull(r, packet[4:]); err != nil {
+		return nil, err
+	}
+	return packet, nil
+}
+
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(b []byte) (int, error) {
+	return f(b)
+}
+
+func (c *serverConn) readCompressed(b []byte) (int, error) {
+	for len(c.pending) == 0 {
+		payload, seq, err := readCompressedPacket(c.transport())
+		if err != nil {
+			return 0, err
+		}
+
+		c.mu.Lock()
+		c.seq = seq + 1
+		c.mu.Unlock()
+		c.pending = payload
+	}
+
+	n := copy(b, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// Write implements the net.Conn interface.
+func (c *serverConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case !c.handshaken:
+		return len(b), c.writeHandshake(b)
+	case c.request != nil && c.request.running:
+		return len(b), c.writeCursorResponse(b)
+	default:
+		if err := c.write(b); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+}
+
+// write writes the data given, compressing it if needed.
+func (c *serverConn) write(data []byte) error {
+	if !c.compressed {
+		_, err := c.transport().Write(data)
+		return err
+	}
+
+	seq, err := writeCompressedPackets(c.transport(), data, c.seq)
+	c.seq = seq
+	return err
+}
+
+// writeHandshake writes the packets of the handshake in the data given once
+// they're complete, announcing the compressed protocol in the first one if
+// compress is set and TLS if tlsConfig is, and starts compressing once the
+// handshake ends, if the client asked for it.
+func (c *serverConn) writeHandshake(data []byte) error {
+	c.out = append(c.out, data...)
+	for len(c.out) >= 4 {
+		length := int(c.out[0]) | int(c.out[1])<<8 | int(c.out[2])<<16
+		if len(c.out) < 4+length {
+			return nil
+		}
+
+		packet := c.out[:4+length]
+		c.out = c.out[4+length:]
+
+		if !c.greeted {
+			var flags uint16
+			if c.compress {
+				flags |= capabilityClientCompress
+			}
+			if c.tlsConfig != nil {
+				flags |= mysql.CapabilityClientSSL
+			}
+			announceCapabilities(packet[4:], flags)
+			c.greeted = true
+		} else if length > 0 && (packet[4] == mysql.OKPacket || packet[4] == mysql.ErrPacket) {
+			c.handshaken = true
+			c.compressed = c.compress && packet[4] == mysql.OKPacket && c.flags&capabilityClientCompress != 0
+		} else if c.secure == nil && !c.allowClearText && isClearTextAuthSwitch(packet[4:]) {
+			sqlErr := mysql.NewSQLError(mysql.CRServerHandshakeErr, mysql.SSUnknownSQLState, "Cannot use clear text authentication over non-SSL connections.")
+			if _, err := c.Conn.Write(errorPacket(packet[3], sqlErr)); err != nil {
+				return err
+			}
+			return sqlErr
+		}
+
+		packet[3] += c.shift
+		if _, err := c.transport().Write(packet); err != nil {
+			return err
+		}
+
+		if c.handshaken {
+			rest := c.out
+			c.out = nil
+			if len(rest) > 0 {
+				return c.write(rest)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// announceCapabilities sets the lower capability flags given in the
+// handshake packet of the server given.
+func announceCapabilities(greeting []byte, flags uint16) {
+	// Protocol version, server version, connection ID, first part of the
+	// salt and filler.
+	pos := bytes.IndexByte(greeting[1:], 0)
+	if pos < 0 {
+		return
+	}
+	pos += 2 + 4 + 8 + 1
+
+	// Lower part of the capability flags.
+	if pos+2 <= len(greeting) {
+		binary.LittleEndian.PutUint16(greeting[pos:], binary.LittleEndian.Uint16(greeting[pos:])|flags)
+	}
+}
+
+// isClearTextAuthSwitch returns whether a packet of the handshake asks the
+// client to switch to an authentication method other than
+// mysql_native_password, all of which the server supports sending the
+// password in clear text.
+func isClearTextAuthSwitch(payload []byte) bool {
+	if len(payload) == 0 || payload[0] != mysql.AuthSwitchRequestPacket {
+		return false
+	}
+	method := payload[1:]
+	if i := bytes.IndexByte(method, 0); i >= 0 {
+		method = method[:i]
+	}
+	return string(method) != mysql.MysqlNativePassword
+}
+
+// Attributes returns the connection attributes the client sent, if they
+// were read.
+func (c *serverConn) Attributes() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.attrs
+}
+
+// ConnectAttrs returns the connection attributes the client of a connection
+// accepted by a Server sent, or nil if it sent none or they couldn't be
+// read. Session builders use it to fill in the
+// sql.Client of sessions.
+func ConnectAttrs(c *mysql.Conn) map[string]string {
+	sc, ok := c.ClientData.(*serverConn)
+	if !ok {
+		return nil
+	}
+	return sc.Attributes()
+}
+
+// NetConn returns the connection of a client as returned by the connection
+// middleware of the Server accepting it, or nil if it wasn't accepted by a
+// Server. Session builders use it to read what the middleware knows of the
+// connection, as its tags.
+func NetConn(c *mysql.Conn) net.Conn {
+	sc, ok := c.ClientData.(*serverConn)
+	if !ok {
+		return nil
+	}
+	return sc.Conn
+}
+
+// parseConnectAttrs returns the connection attributes of a handshake
+// response packet, or nil if it has none or it's a request to switch to
+// TLS.
+func parseConnectAttrs(data []byte) map[string]string {
+	if len(data) < 32 {
+		return nil
+	}
+
+	flags := binary.LittleEndian.Uint32(data)
+	if flags&mysql.CapabilityClientConnAttr == 0 {
+		return nil
+	}
+
+	// Client flags, max packet size, character set and filler.
+	pos := 32
+
+	// User name.
+	pos, ok := skipNullString(data, pos)
+	if !ok {
+		return nil
+	}
+
+	// Authentication response.
+	switch {
+	case flags&mysql.CapabilityClientPluginAuthLenencClientData != 0:
+		var n uint64
+		n, pos, ok = readLenEncInt(data, pos)
+		if !ok || n > uint64(len(data)-pos) {
+			return nil
+		}
+		pos += int(n)
+	case flags&mysql.CapabilityClientSecureConnection != 0:
+		if pos >= len(data) {
+			return nil
+		}
+		pos += 1 + int(data[pos])
+	default:
+		pos, ok = skipNullString(data, pos)
+	}
+	if !ok || pos > len(data) {
+		return nil
+	}
+
+	if flags&mysql.CapabilityClientConnectWithDB != 0 {
+		if pos, ok = skipNullString(data, pos); !ok {
+			return nil
+		}
+	}
+
+	if flags&mysql.CapabilityClientPluginAuth != 0 {
+		if pos, ok = skipNullString(data, pos); !ok {
+			return nil
+		}
+	}
+
+	length, pos, ok := readLenEncInt(data, pos)
+	if !ok || length > uint64(len(data)-pos) {
+		return nil
+	}
+
+	data = data[:pos+int(length)]
+	attrs := make(map[string]string)
+	for pos < len(data) {
+		var key, value string
+		if key, pos, ok = readLenEncString(data, pos); !ok {
+			return nil
+		}
+		if value, pos, ok = readLenEncString(data, pos); !ok {
+			return nil
+		}
+		attrs[key] = value
+	}
+	return attrs
+}
+
+func skipNullString(data []byte, pos int) (int, bool) {
+	for i := pos; i < len(data); i++ {
+		if data[i] == 0 {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+func readLenEncInt(data []byte, pos int) (uint64, int, bool) {
+	if pos >= len(data) {
+		return 0, 0, false
+	}
+
+	var size int
+	switch data[pos] {
+	case 0xfc:
+		size = 2
+	case 0xfd:
+		size = 3
+	case 0xfe:
+		size = 8
+	case 0xfb, 0xff:
+		return 0, 0, false
+	default:
+		return uint64(data[pos]), pos + 1, true
+	}
+
+	if pos+1+size > len(data) {
+		return 0, 0, false
+	}
+
+	var n uint64
+	for i := 0; i < size; i++ {
+		n |= uint64(data[pos+1+i]) << (8 * i)
+	}
+	return n, pos + 1 + size, true
+}
+
+func readLenEncString(data []byte, pos int) (string, int, bool) {
+	// Lengths are compared before converting them to int, which the
+	// lengths of up to 2^64-1 the client can send would overflow.
+	n, pos, ok := readLenEncInt(data, pos)
+	if !ok || n > uint64(len(data)-pos) {
+		return "", 0, false
+	}
+	end := pos + int(n)
+	return string(data[pos:end]), end, true
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+// handshakeResponse builds a handshake response packet, with the header,
+// sending the connection attributes given as name, value pairs.
+func handshakeResponse(flags uint32, attrs ...string) []byte {
+	data := make([]byte, 32)
+	binary.LittleEndian.PutUint32(data, flags)
+	data = append(data, "root\x00"...)
+
+	if flags&mysql.CapabilityClientPluginAuthLenencClientData != 0 {
+		data = append(data, 3, 'a', 'b', 'c')
+	} else {
+		data = append(data, 0)
+	}
+	if flags&mysql.CapabilityClientConnectWithDB != 0 {
+		data = append(data, "mydb\x00"...)
+	}
+	if flags&mysql.CapabilityClientPluginAuth != 0 {
+		data = append(data, "mysql_native_password\x00"...)
+	}
+
+	var attrData []byte
+	for _, s := range attrs {
+		attrData = append(attrData, byte(len(s)))
+		attrData = append(attrData, s...)
+	}
+	if flags&mysql.CapabilityClientConnAttr != 0 {
+		data = append(data, byte(len(attrData)))
+		data = append(data, attrData...)
+	}
+
+	header := []byte{byte(len(data)), byte(len(data) >> 8), byte(len(data) >> 16), 1}
+	return append(header, data...)
+}
+
+func TestParseConnectAttrs(t *testing.T) {
+	const flags = mysql.CapabilityClientProtocol41 | mysql.CapabilityClientSecureConnection |
+		mysql.CapabilityClientPluginAuth | mysql.CapabilityClientConnAttr
+
+	testCases := []struct {
+		name     string
+		packet   []byte
+		expected map[string]string
+	}{
+		{
+			"attributes",
+			handshakeResponse(flags, "_client_name", "libmysql", "program_name", "mysql"),
+			map[string]string{"_client_name": "libmysql", "program_name": "mysql"},
+		},
+		{
+			"attributes with database and length encoded auth response",
+			handshakeResponse(flags|mysql.CapabilityClientConnectWithDB|mysql.CapabilityClientPluginAuthLenencClientData, "_os", "linux"),
+			map[string]string{"_os": "linux"},
+		},
+		{
+			"no attributes",
+			handshakeResponse(flags),
+			map[string]string{},
+		},
+		{
+			"attributes not supported",
+			handshakeResponse(flags &^ mysql.CapabilityClientConnAttr),
+			nil,
+		},
+		{
+			"ssl request",
+			handshakeResponse(flags | mysql.CapabilityClientSSL)[:4+32],
+			nil,
+		},
+		{
+			"truncated",
+			handshakeResponse(flags, "_client_name", "libmysql")[:50],
+			nil,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, parseConnectAttrs(tt.packet[4:]))
+		})
+	}
+
+	// Lengths that overflow int once converted are rejected.
+	huge := []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	prefix := handshakeResponse(flags)[4:]
+	prefix = prefix[:len(prefix)-1]
+
+	response := make([]byte, 32)
+	binary.LittleEndian.PutUint32(response, flags|mysql.CapabilityClientPluginAuthLenencClientData)
+	response = append(response, "root\x00"...)
+	response = append(response, huge...)
+	response = append(response, "abc\x00mysql_native_password\x00\x00"...)
+	require.Nil(t, parseConnectAttrs(response))
+
+	require.Nil(t, parseConnectAttrs(append(append(prefix, huge...), 1, 'a', 1, 'b')))
+
+	attrs := append([]byte{byte(len(huge) + 1)}, huge...)
+	attrs = append(attrs, 'a')
+	require.Nil(t, parseConnectAttrs(append(prefix, attrs...)))
+}
+
+func TestServerConnAttributes(t *testing.T) {
+	require := require.New(t)
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	conn := newServerConn(server, false)
+	defer conn.Close()
+
+	packet := handshakeResponse(
+		mysql.CapabilityClientProtocol41|mysql.CapabilityClientSecureConnection|mysql.CapabilityClientConnAttr,
+		"_client_name", "libmysql",
+	)
+	go func() {
+		// The packet is sent in parts, and followed by the next ones.
+		client.Write(packet[:3])
+		client.Write(packet[3:20])
+		client.Write(packet[20:])
+		client.Write([]byte{1, 0, 0, 0, 1})
+	}()
+
+	buf := make([]byte, len(packet)+5)
+	_, err := io.ReadFull(conn, buf)
+	require.NoError(err)
+	require.Equal(packet, buf[:len(packet)])
+
+	c := &mysql.Conn{ClientData: conn}
+	require.Equal(map[string]string{"_client_name": "libmysql"}, ConnectAttrs(c))
+	require.Nil(ConnectAttrs(&mysql.Conn{}))
+}
+
+func TestServerConnAttributesTLS(t *testing.T) {
+	require := require.New(t)
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	conn := newServerConn(server, false)
+	conn.tlsConfig = &tls.Config{Certificates: []tls.Certificate{newTestCA(t).issue(t, "localhost")}}
+	defer conn.Close()
+
+	const flags = mysql.CapabilityClientProtocol41 | mysql.CapabilityClientSecureConnection |
+		mysql.CapabilityClientConnAttr | mysql.CapabilityClientSSL
+	packet := handshakeResponse(flags, "_client_name", "libmysql")
+	errs := make(chan error, 1)
+	go func() {
+		sslRequest := handshakeResponse(flags)[:4+32]
+		sslRequest[0] = 32
+		if _, err := client.Write(sslRequest); err != nil {
+			errs <- err
+			return
+		}
+
+		// The handshake response follows the request to switch to TLS.
+		secure := tls.Client(client, &tls.Config{InsecureSkipVerify: true})
+		response := append([]byte(nil), packet...)
+		response[3] = 2
+		_, err := secure.Write(response)
+		errs <- err
+	}()
+
+	// The request to switch to TLS is hidden, and the handshake response
+	// read as if it followed the greeting.
+	buf := make([]byte, len(packet))
+	_, err := io.ReadFull(conn, buf)
+	require.NoError(err)
+	require.NoError(<-errs)
+	require.Equal(packet, buf)
+
+	_, secure := conn.TLSConnectionState()
+	require.True(secure)
+	require.Equal(map[string]string{"_client_name": "libmysql"}, conn.Attributes())
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/dolthub/vitess/go/mysql"
 	"github.com/opentracing/opentracing-go"
 
+	"github.com/dolthub/go-mysql-server/auth"
 	"github.com/dolthub/go-mysql-server/sql"
 )
 
@@ -19,8 +20,12 @@ type DoneFunc func()
 
 // DefaultSessionBuilder is a SessionBuilder that returns a base session.
 func DefaultSessionBuilder(ctx context.Context, c *mysql.Conn, addr string) (sql.Session, *sql.IndexRegistry, *sql.ViewRegistry, error) {
-	client := c.RemoteAddr().String()
-	return sql.NewSession(addr, client, c.User, c.ConnectionID), sql.NewIndexRegistry(), sql.NewViewRegistry(), nil
+	client := sql.Client{Address: c.RemoteAddr().String(), User: c.User, ConnectAttrs: ConnectAttrs(c)}
+	if p, ok := c.UserData.(auth.ProxyUserData); ok {
+		client.User, client.ProxyUser = p.User, p.Proxy
+	}
+
+	return sql.NewSessionWithClient(addr, client, c.ConnectionID), sql.NewIndexRegistry(), sql.NewViewRegistry(), nil
 }
 
 // SessionManager is in charge of creating new sessions for the given
@@ -31,6 +36,7 @@ type SessionManager struct {
 	tracer    opentracing.Tracer
 	hasDBFunc func(name string) bool
 	memory    *sql.MemoryManager
+	sysVars   *sql.SystemVariableRegistry
 	mu        *sync.Mutex
 	builder   SessionBuilder
 	sessions  map[uint32]sql.Session
@@ -74,11 +80,25 @@ func (s *SessionManager) NewSession(ctx context.Context, conn *mysql.Conn) error
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.sessions[conn.ConnectionID], s.idxRegs[conn.ConnectionID], s.viewRegs[conn.ConnectionID], err = s.builder(ctx, conn, s.addr)
+	s.sessions[conn.ConnectionID], s.idxRegs[conn.ConnectionID], s.viewRegs[conn.ConnectionID], err = s.build(ctx, conn)
 
 	return err
 }
 
+// build creates a session for the given connection with the builder. Its
+// system variables start with their global values on the server.
+func (s *SessionManager) build(ctx context.Context, conn *mysql.Conn) (sql.Session, *sql.IndexRegistry, *sql.ViewRegistry, error) {
+	sess, ir, vr, err := s.builder(ctx, conn, s.addr)
+	if err != nil || s.sysVars == nil {
+		return sess, ir, vr, err
+	}
+
+	if err := s.sysVars.InitSession(ctx, sess); err != nil {
+		return nil, nil, nil, err
+	}
+	return sess, ir, vr, nil
+}
+
 func (s *SessionManager) SetDB(conn *mysql.Conn, db string) error {
 	sess, _, _, err := s.getOrCreateSession(context.Background(), conn)
 
@@ -112,7 +132,7 @@ func (s *SessionManager) getOrCreateSession(ctx context.Context, conn *mysql.Con
 	vr := s.viewRegs[conn.ConnectionID]
 	if !ok {
 		var err error
-		sess, ir, vr, err = s.builder(ctx, conn, s.addr)
+		sess, ir, vr, err = s.build(ctx, conn)
 
 		if err != nil {
 			return nil, nil, nil, err
@@ -136,17 +156,26 @@ func (s *SessionManager) NewContextWithQuery(conn *mysql.Conn, query string) (*s
 		return nil, err
 	}
 
-	context := sql.NewContext(
-		ctx,
+	// The spans of the query are children of its root span, which may in turn
+	// be the child of a span of the client.
+	span := startQuerySpan(s.tracer, query)
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	opts := []sql.ContextOption{
 		sql.WithSession(sess),
 		sql.WithTracer(s.tracer),
 		sql.WithPid(s.nextPid()),
 		sql.WithQuery(query),
 		sql.WithMemoryManager(s.memory),
-		sql.WithRootSpan(s.tracer.StartSpan("query")),
+		sql.WithRootSpan(span),
 		sql.WithIndexRegistry(ir),
 		sql.WithViewRegistry(vr),
-	)
+	}
+	if s.sysVars != nil {
+		opts = append(opts, sql.WithSystemVariables(s.sysVars))
+	}
+
+	context := sql.NewContext(ctx, opts...)
 
 	return context, nil
 }
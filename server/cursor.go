@@ -0,0 +1,422 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/dolthub/vitess/go/vt/proto/query"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+const (
+	// cursorTypeMask are the flags of COM_STMT_EXECUTE asking for a cursor.
+	// All the cursors are read only.
+	cursorTypeMask = 0x07
+	// serverStatusCursorExists is SERVER_STATUS_CURSOR_EXISTS.
+	serverStatusCursorExists = 0x0040
+	// serverStatusLastRowSent is SERVER_STATUS_LAST_ROW_SENT.
+	serverStatusLastRowSent = 0x0080
+	// erStmtHasNoOpenCursor is the MySQL error code returned to clients
+	// fetching from statements without an open cursor.
+	erStmtHasNoOpenCursor = 1421
+	// maxPacketPayload is the length of the payload of the packets followed
+	// by the rest of it.
+	maxPacketPayload = 1<<24 - 1
+)
+
+// cursor is an open cursor of a prepared statement, whose rows are read as
+// the client fetches them.
+type cursor struct {
+	// execute is the COM_STMT_EXECUTE packet which opened the cursor, with
+	// its cursor flags cleared, which is run again for each fetch.
+	execute []byte
+	schema  sql.Schema
+	rows    sql.RowIter
+	cancel  context.CancelFunc
+}
+
+func (c *cursor) close() {
+	if err := c.rows.Close(); err != nil {
+		logrus.Errorf("unable to close cursor: %s", err)
+	}
+	c.cancel()
+}
+
+// cursorRequest is a command opening or fetching from the cursor of a
+// prepared statement, which is run as a COM_STMT_EXECUTE without a cursor.
+// The response to it is rewritten as the response of the command.
+type cursorRequest struct {
+	stmtID uint32
+	// execute is the packet run for the command.
+	execute []byte
+	// fetch is set for COM_STMT_FETCH, which fetches the given number of
+	// rows. last is set once the last row of the cursor has been read.
+	fetch bool
+	rows  uint32
+	last  bool
+	// running is set while the command is being run. It's cleared if no
+	// cursor is opened, once the response has been written.
+	running bool
+
+	// The state of the response being written: the packets received and not
+	// written, the number of columns, or -1 until it's read, and of column
+	// definitions read, whether the EOF after them has been read, whether
+	// the last packet is followed by the rest of its payload, and the
+	// sequence number of the next packet.
+	out       []byte
+	columns   int
+	defs      int
+	fieldsEOF bool
+	continued bool
+	seq       uint8
+}
+
+// runCursorCommand runs the command in the packet given for the cursors of
+// the connection, returning the packet that must be run instead, if any.
+func (c *serverConn) runCursorCommand(packet []byte) ([]byte, error) {
+	payload := packet[4:]
+	continued := c.continued
+	c.continued = len(payload) == maxPacketPayload
+	if continued || len(payload) == 0 {
+		return packet, nil
+	}
+
+	c.mu.Lock()
+	c.request = nil
+	c.mu.Unlock()
+
+	switch payload[0] {
+	case mysql.ComStmtExecute:
+		if len(payload) < 6 {
+			break
+		}
+
+		stmtID := binary.LittleEndian.Uint32(payload[1:])
+		c.closeCursor(stmtID)
+		if payload[5]&cursorTypeMask == 0 {
+			break
+		}
+
+		payload[5] = 0
+		c.mu.Lock()
+		c.request = &cursorRequest{stmtID: stmtID, execute: packet, running: true, columns: -1, seq: 1}
+		c.mu.Unlock()
+	case mysql.ComStmtFetch:
+		if len(payload) < 9 {
+			break
+		}
+
+		stmtID := binary.LittleEndian.Uint32(payload[1:])
+		rows := binary.LittleEndian.Uint32(payload[5:])
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		cur, ok := c.cursors[stmtID]
+		if !ok {
+			err := mysql.NewSQLError(erStmtHasNoOpenCursor, mysql.SSUnknownSQLState, "The statement (%d) has no open cursor.", stmtID)
+			return nil, c.write(errorPacket(1, err))
+		}
+
+		c.request = &cursorRequest{
+			stmtID:  stmtID,
+			execute: cur.execute,
+			fetch:   true,
+			rows:    rows,
+			running: true,
+			columns: -1,
+			seq:     1,
+		}
+		return cur.execute, nil
+	case mysql.ComStmtClose, mysql.ComStmtReset:
+		if len(payload) >= 5 {
+			c.closeCursor(binary.LittleEndian.Uint32(payload[1:]))
+		}
+	case mysql.ComResetConnection:
+		c.closeCursors()
+	}
+	return packet, nil
+}
+
+// cursorRequest returns the command opening or fetching from the cursor of
+// the prepared statement given being run, if any.
+func (c *serverConn) cursorRequest(stmtID uint32) *cursorRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.request == nil || !c.request.running || c.request.stmtID != stmtID {
+		return nil
+	}
+	return c.request
+}
+
+// openCursor opens the cursor of the statement of the request given, which
+// must be opening it, or writes the response of the statement as usual if
+// cur is nil.
+func (c *serverConn) openCursor(req *cursorRequest, cur *cursor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cur == nil {
+		req.running = false
+		return
+	}
+
+	if c.cursors == nil {
+		c.cursors = make(map[uint32]*cursor)
+	}
+	cur.execute = req.execute
+	c.cursors[req.stmtID] = cur
+}
+
+// cursor returns the open cursor of the prepared statement given.
+func (c *serverConn) cursor(stmtID uint32) (*cursor, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cur, ok := c.cursors[stmtID]
+	return cur, ok
+}
+
+// closeCursor closes the cursor of the prepared statement given, if it's
+// open.
+func (c *serverConn) closeCursor(stmtID uint32) {
+	c.mu.Lock()
+	cur, ok := c.cursors[stmtID]
+	delete(c.cursors, stmtID)
+	c.mu.Unlock()
+
+	if ok {
+		cur.close()
+	}
+}
+
+// closeCursors closes all the open cursors of the connection.
+func (c *serverConn) closeCursors() {
+	c.mu.Lock()
+	cursors := c.cursors
+	c.cursors = nil
+	c.mu.Unlock()
+
+	for _, cur := range cursors {
+		cur.close()
+	}
+}
+
+// writeCursorResponse writes the packets of the response to the command
+// of the request being run once they're complete. The result set of
+// statements opening a cursor has no rows, and the one of fetches only has
+// the rows, followed by the status of the cursor.
+func (c *serverConn) writeCursorResponse(data []byte) error {
+	req := c.request
+	req.out = append(req.out, data...)
+	deprecateEOF := c.flags&mysql.CapabilityClientDeprecateEOF != 0
+
+	for len(req.out) >= 4 {
+		length := int(req.out[0]) | int(req.out[1])<<8 | int(req.out[2])<<16
+		if len(req.out) < 4+length {
+			return nil
+		}
+
+		packet := req.out[:4+length]
+		req.out = req.out[4+length:]
+
+		write, done := req.response(packet, deprecateEOF)
+		if write {
+			packet[3] = req.seq
+			req.seq++
+			if err := c.write(packet); err != nil {
+				return err
+			}
+		}
+
+		if done {
+			rest := req.out
+			c.request = nil
+			if len(rest) > 0 {
+				return c.write(rest)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// response returns whether the packet given of the response to the request
+// must be written, setting the status of the cursor in it if it's needed,
+// and whether it's the last packet of the response.
+func (r *cursorRequest) response(packet []byte, deprecateEOF bool) (write, done bool) {
+	payload := packet[4:]
+	continued := r.continued
+	r.continued = len(payload) == maxPacketPayload
+
+	switch {
+	case continued:
+		return true, false
+	case r.columns < 0:
+		if len(payload) == 0 || payload[0] == mysql.OKPacket || payload[0] == mysql.ErrPacket {
+			return true, true
+		}
+		n, _, ok := readLenEncInt(payload, 0)
+		if !ok {
+			return true, true
+		}
+		r.columns = int(n)
+		return !r.fetch, false
+	case r.defs < r.columns:
+		r.defs++
+		return !r.fetch, false
+	case !deprecateEOF && !r.fieldsEOF:
+		r.fieldsEOF = true
+		if r.fetch {
+			return false, false
+		}
+		setStatusFlags(payload, false, serverStatusCursorExists)
+		return true, false
+	case len(payload) > 0 && payload[0] == mysql.ErrPacket:
+		return true, true
+	case len(payload) > 0 && payload[0] == mysql.EOFPacket:
+		if r.fetch {
+			flags := uint16(serverStatusCursorExists)
+			if r.last {
+				flags = serverStatusLastRowSent
+			}
+			setStatusFlags(payload, deprecateEOF, flags)
+			return true, true
+		}
+
+		// Without CLIENT_DEPRECATE_EOF, the EOF after the column definitions
+		// ends the response.
+		if deprecateEOF {
+			setStatusFlags(payload, true, serverStatusCursorExists)
+			return true, true
+		}
+		return false, true
+	default:
+		return true, false
+	}
+}
+
+// setStatusFlags sets the status flags given in an EOF packet, or an OK
+// packet with an EOF header if ok is set.
+func setStatusFlags(payload []byte, ok bool, flags uint16) {
+	pos := 3
+	if ok {
+		var valid bool
+		// Affected rows and last insert ID.
+		if _, pos, valid = readLenEncInt(payload, 1); !valid {
+			return
+		}
+		if _, pos, valid = readLenEncInt(payload, pos); !valid {
+			return
+		}
+	}
+
+	if pos+2 <= len(payload) {
+		status := binary.LittleEndian.Uint16(payload[pos:])
+		binary.LittleEndian.PutUint16(payload[pos:], status|flags)
+	}
+}
+
+// errorPacket returns the error packet of the error given, with the
+// sequence number given.
+func errorPacket(seq uint8, err *mysql.SQLError) []byte {
+	message := err.Message
+	payload := make([]byte, 0, 9+len(message))
+	payload = append(payload, mysql.ErrPacket, byte(err.Num), byte(err.Num>>8), '#')
+	payload = append(payload, err.State...)
+	payload = append(payload, message...)
+
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), seq}
+	return append(header, payload...)
+}
+
+// executeCursor runs the statement of a request to open a cursor or fetch
+// from it.
+func (h *Handler) executeCursor(
+	c *mysql.Conn,
+	sc *serverConn,
+	req *cursorRequest,
+	prepare *mysql.PrepareData,
+	bindVars map[string]*query.BindVariable,
+	callback func(*sqltypes.Result) error,
+) error {
+	if req.fetch {
+		return h.fetchCursor(sc, req, callback)
+	}
+
+	// Only the statements returning rows have cursors.
+	if len(prepare.ColumnNames) == 0 {
+		sc.openCursor(req, nil)
+		return h.doQuery(c, prepare.PrepareStmt, bindVars, callback)
+	}
+
+	ctx, err := h.sm.NewContextWithQuery(c, prepare.PrepareStmt)
+	if err != nil {
+		return err
+	}
+
+	// The cursor is canceled once it's closed.
+	newCtx, cancel := context.WithCancel(ctx)
+	ctx = ctx.WithContext(newCtx)
+
+	start := time.Now()
+	var bindings map[string]sql.Expression
+	if len(bindVars) > 0 {
+		bindings, err = bindingsToExprs(bindVars)
+		if err != nil {
+			cancel()
+			return err
+		}
+	}
+
+	schema, rows, err := h.e.QueryWithBindings(ctx, prepare.PrepareStmt, bindings)
+	if q, ok := h.e.Auth.(*auth.Audit); ok {
+		q.Query(ctx, time.Since(start), err)
+	}
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	sc.openCursor(req, &cursor{schema: schema, rows: rows, cancel: cancel})
+	return callback(&sqltypes.Result{Fields: schemaToFields(schema)})
+}
+
+// fetchCursor reads the rows of a fetch from the cursor of a request.
+func (h *Handler) fetchCursor(sc *serverConn, req *cursorRequest, callback func(*sqltypes.Result) error) error {
+	cur, ok := sc.cursor(req.stmtID)
+	if !ok {
+		return mysql.NewSQLError(erStmtHasNoOpenCursor, mysql.SSUnknownSQLState, "The statement (%d) has no open cursor.", req.stmtID)
+	}
+
+	r := &sqltypes.Result{Fields: schemaToFields(cur.schema)}
+	for i := uint32(0); i < req.rows; i++ {
+		row, err := cur.rows.Next()
+		if err == io.EOF {
+			sc.closeCursor(req.stmtID)
+			sc.mu.Lock()
+			req.last = true
+			sc.mu.Unlock()
+			break
+		}
+		if err != nil {
+			sc.closeCursor(req.stmtID)
+			return err
+		}
+
+		outputRow, err := rowToSQL(cur.schema, row)
+		if err != nil {
+			sc.closeCursor(req.stmtID)
+			return err
+		}
+		r.Rows = append(r.Rows, outputRow)
+		r.RowsAffected++
+	}
+	return callback(r)
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"io"
 	"net"
 	"regexp"
@@ -14,6 +15,7 @@ import (
 	"github.com/dolthub/vitess/go/sqltypes"
 	"github.com/dolthub/vitess/go/vt/proto/query"
 	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/go-kit/kit/metrics/discard"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/src-d/go-errors.v1"
 
@@ -36,8 +38,45 @@ var ErrConnectionWasClosed = errors.NewKind("connection was closed")
 
 var ErrUnsupportedOperation = errors.NewKind("unsupported operation")
 
+// erSecureTransportRequired is the MySQL error code returned to clients not
+// using TLS when the server requires it.
+const erSecureTransportRequired = 3159
+
+// erQueryTimeout is the MySQL error code returned to clients when a query
+// runs for longer than its maximum execution time.
+const erQueryTimeout = 3024
+
+// erNetWriteInterrupted is the MySQL error code of the queries whose client
+// doesn't read their results for @@net_write_timeout seconds, whose connection
+// is aborted, and ssNetError its SQL state.
+const (
+	erNetWriteInterrupted = 1161
+	ssNetError            = "08S01"
+)
+
+// SQL states of the errors returned to clients whose connection is rejected
+// by the connection limits.
+const (
+	ssConnectionRejected = "08004"
+	ssAccessViolation    = "42000"
+)
+
+var (
+	// ConnectionCounter describes a metric that accumulates number of connections monotonically.
+	ConnectionCounter = discard.NewCounter()
+
+	// ConnectionsGauge describes the number of open connections.
+	ConnectionsGauge = discard.NewGauge()
+)
+
 // TODO parametrize
 const rowsBatch = 100
+
+// rowsBatchSize bounds the size in bytes of the values of a batch of rows, so
+// that the rows buffered until the client reads them don't grow with the size
+// of the rows of a query. A batch is sent once it has rowsBatch rows or the
+// size of its values reaches rowsBatchSize.
+const rowsBatchSize = 64 * 1024
 const tcpCheckerSleepTime = 1
 
 type conntainer struct {
@@ -53,15 +92,37 @@ type Handler struct {
 	c           map[uint32]conntainer
 	readTimeout time.Duration
 	lc          []*net.Conn
+	// connected holds the connections that have been accepted.
+	connected map[uint32]bool
+	// accounts holds the account of each connection admitted by the
+	// connection limits, and userConns the number of connections of each
+	// account.
+	accounts  map[uint32]sql.Account
+	userConns map[sql.Account]int
+	// shuttingDown is set once the server starts shutting down, after which
+	// new queries are rejected.
+	shuttingDown bool
+
+	// auth and requireSecureTransport are used to check new connections once
+	// the user has authenticated.
+	auth                   auth.Auth
+	requireSecureTransport bool
 }
 
 // NewHandler creates a new Handler given a SQLe engine.
 func NewHandler(e *sqle.Engine, sm *SessionManager, rt time.Duration) *Handler {
+	// The sessions start with the global values of the system variables
+	// of the engine.
+	sm.sysVars = e.Catalog.SystemVariables
+
 	return &Handler{
 		e:           e,
 		sm:          sm,
 		c:           make(map[uint32]conntainer),
 		readTimeout: rt,
+		connected:   make(map[uint32]bool),
+		accounts:    make(map[uint32]sql.Account),
+		userConns:   make(map[sql.Account]int),
 	}
 }
 
@@ -86,18 +147,181 @@ func (h *Handler) NewConnection(c *mysql.Conn) {
 			logrus.Debug("Could not find TCP socket connection after Accept(), " +
 				"connection checker won't run")
 		}
+		if sc, ok := netConn.(*serverConn); ok {
+			c.ClientData = sc
+			netConn = sc.socket
+			sc.mu.Lock()
+			sc.handler, sc.conn = h, c
+			sc.mu.Unlock()
+		}
 		h.c[c.ConnectionID] = conntainer{c, netConn}
 	}
 
 	h.mu.Unlock()
 
+	ConnectionCounter.Add(1)
+	ConnectionsGauge.Add(1)
+
+	status := h.e.Catalog.GlobalStatus
+	status.Add(sql.StatusConnections, 1)
+	status.SetMax(sql.StatusMaxUsedConnections, status.Add(sql.StatusThreadsConnected, 1))
+
 	logrus.Infof("NewConnection: client %v", c.ConnectionID)
 }
 
+// ComInitDB implements the mysql.Handler interface. It is also called right
+// after a client authenticates, before the connection is accepted, which is
+// when the connection requirements of the user are checked.
 func (h *Handler) ComInitDB(c *mysql.Conn, schemaName string) error {
+	err := h.checkConnection(c)
+	if err == nil {
+		err = h.limitConnection(c)
+	}
+	h.logConnect(c, schemaName, err)
+	if err != nil {
+		return err
+	}
+
 	return h.sm.SetDB(c, schemaName)
 }
 
+// logConnect logs the connection of a client to the audit log and the query
+// log of the engine the first time it's checked, and marks it as connected if
+// it was accepted, which the statistics of the sys database count and the
+// catalog lists the client of.
+func (h *Handler) logConnect(c *mysql.Conn, db string, err error) {
+	h.mu.Lock()
+	_, ok := h.c[c.ConnectionID]
+	first := ok && !h.connected[c.ConnectionID]
+	if first && err == nil {
+		h.connected[c.ConnectionID] = true
+	}
+	h.mu.Unlock()
+
+	if !first {
+		return
+	}
+
+	ctx, ctxErr := h.sm.NewContextWithQuery(c, "")
+	if ctxErr != nil {
+		logrus.Errorf("unable to audit connection %d: %s", c.ConnectionID, ctxErr)
+		return
+	}
+
+	if err == nil {
+		h.e.Catalog.AddClient(c.ConnectionID, ctx.Client())
+	}
+
+	h.e.Audit.Connect(ctx, err)
+	h.e.QueryLog.Connect(ctx, db, err)
+	if err == nil {
+		h.e.Sys.Connect(ctx)
+	}
+}
+
+// checkConnection checks that the connection given satisfies the transport
+// requirements of the server and the user.
+func (h *Handler) checkConnection(c *mysql.Conn) error {
+	state, secure := tlsConnectionState(c)
+	if h.requireSecureTransport && !secure {
+		return mysql.NewSQLError(erSecureTransportRequired, mysql.SSUnknownSQLState, "Connections using insecure transport are prohibited while --require_secure_transport=ON.")
+	}
+
+	checker, ok := h.auth.(auth.ConnectionChecker)
+	if !ok {
+		return nil
+	}
+
+	return checker.CheckConnection(auth.Connection{
+		User:             c.User,
+		Address:          c.RemoteAddr().String(),
+		Secure:           secure,
+		PeerCertificates: state.PeerCertificates,
+	})
+}
+
+// tlsConnectionState returns the state of the TLS connection of the client
+// of a connection, and whether it's using TLS. Clients of the connections
+// accepted by a Server switch to TLS below the connection.
+func tlsConnectionState(c *mysql.Conn) (tls.ConnectionState, bool) {
+	if sc, ok := c.ClientData.(*serverConn); ok {
+		return sc.TLSConnectionState()
+	}
+
+	if c.Capabilities&mysql.CapabilityClientSSL == 0 {
+		return tls.ConnectionState{}, false
+	}
+	return tls.ConnectionState{PeerCertificates: c.GetTLSClientCerts()}, true
+}
+
+// limitConnection admits the connection given unless there are as many
+// connections as @@max_connections, or as many connections of its account as
+// its own limit or @@max_user_connections. Connections are only checked once.
+func (h *Handler) limitConnection(c *mysql.Conn) error {
+	account := sql.Account{Name: c.User, Host: "%"}
+	var limit int
+	if limiter, ok := h.auth.(auth.ConnectionLimiter); ok {
+		_, secure := tlsConnectionState(c)
+		var err error
+		account, limit, err = limiter.ConnectionLimit(auth.Connection{
+			User:    c.User,
+			Address: c.RemoteAddr().String(),
+			Secure:  secure,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	maxConnections, _ := h.e.Catalog.SystemVariables.Global("max_connections")
+	maxUserConnections, _ := h.e.Catalog.SystemVariables.Global("max_user_connections")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.accounts[c.ConnectionID]; ok {
+		return nil
+	}
+
+	if max, ok := maxConnections.(int64); ok && int64(len(h.accounts)) >= max {
+		h.e.Catalog.GlobalStatus.Add(sql.StatusConnectionErrorsMaxConnections, 1)
+		return mysql.NewSQLError(mysql.ERConCount, ssConnectionRejected, "Too many connections")
+	}
+
+	conns := h.userConns[account]
+	if limit > 0 && conns >= limit {
+		return mysql.NewSQLError(mysql.ERUserLimitReached, ssAccessViolation,
+			"User '%s' has exceeded the 'max_user_connections' resource (current value: %d)", account.Name, limit)
+	}
+	if max, ok := maxUserConnections.(int64); ok && limit == 0 && max > 0 && int64(conns) >= max {
+		return mysql.NewSQLError(mysql.ERTooManyUserConnections, ssAccessViolation,
+			"User %s already has more than 'max_user_connections' active connections", account.Name)
+	}
+
+	h.accounts[c.ConnectionID] = account
+	h.userConns[account] = conns + 1
+	return nil
+}
+
+// releaseConnection stops counting a closed connection in the connection
+// limits.
+func (h *Handler) releaseConnection(c *mysql.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	account, ok := h.accounts[c.ConnectionID]
+	if !ok {
+		return
+	}
+
+	delete(h.accounts, c.ConnectionID)
+	if h.userConns[account] <= 1 {
+		delete(h.userConns, account)
+	} else {
+		h.userConns[account]--
+	}
+}
+
 func (h *Handler) ComPrepare(c *mysql.Conn, query string) ([]*query.Field, error) {
 	ctx, err := h.sm.NewContextWithQuery(c, query)
 	if err != nil {
@@ -105,44 +329,205 @@ func (h *Handler) ComPrepare(c *mysql.Conn, query string) ([]*query.Field, error
 	}
 	schema, err := h.e.AnalyzeQuery(ctx, query)
 	if err != nil {
+		// Some statements, such as SET, need the values of their
+		// parameters to be analyzed. Their columns are sent on execution.
+		if sql.ErrUnboundPreparedStatementVariable.Is(err) {
+			return nil, nil
+		}
 		return nil, err
 	}
+
+	// Statements that don't return rows have no columns.
+	if schema.Equals(sql.OkResultSchema) {
+		return nil, nil
+	}
 	return schemaToFields(schema), nil
 }
 
 func (h *Handler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
-	return h.doQuery(c, prepare.PrepareStmt, prepare.BindVars, callback)
+	bindVars, err := preparedBindVars(prepare)
+	if err != nil {
+		return err
+	}
+
+	if sc, ok := c.ClientData.(*serverConn); ok {
+		if req := sc.cursorRequest(prepare.StatementID); req != nil {
+			return sqlError(h.executeCursor(c, sc, req, prepare, bindVars, callback))
+		}
+	}
+	return sqlError(h.doQuery(c, prepare.PrepareStmt, bindVars, callback))
 }
 
+// ComResetConnection resets the session of a connection to the state of a
+// new one, as clients do to reuse their connections, and releases the table
+// and named locks it holds. The prepared statements of the binary protocol
+// are released by the caller.
 func (h *Handler) ComResetConnection(c *mysql.Conn) {
-	// TODO: handle reset logic
+	ctx, err := h.sm.NewContextWithQuery(c, "")
+	if err != nil {
+		logrus.Errorf("unable to reset connection %d: %s", c.ConnectionID, err)
+		return
+	}
+
+	if err := h.e.Catalog.UnlockTables(ctx, c.ConnectionID); err != nil {
+		logrus.Errorf("unable to unlock tables on connection reset: %s", err)
+	}
+
+	if h.e.LS != nil {
+		if _, err := h.e.LS.ReleaseAll(ctx); err != nil {
+			logrus.Errorf("unable to release locks on connection reset: %s", err)
+		}
+	}
+
+	if s, ok := ctx.Session.(sql.ResettableSession); ok {
+		s.Reset()
+		if err := ctx.SystemVariables().InitSession(ctx, s); err != nil {
+			logrus.Errorf("unable to reset the system variables of connection %d: %s", c.ConnectionID, err)
+		}
+	}
+
+	logrus.Infof("ComResetConnection: client %v", c.ConnectionID)
 }
 
 // ConnectionClosed reports that a connection has been closed.
 func (h *Handler) ConnectionClosed(c *mysql.Conn) {
+	if sc, ok := c.ClientData.(*serverConn); ok {
+		sc.closeCursors()
+	}
+
+	h.releaseConnection(c)
+
 	ctx, _ := h.sm.NewContextWithQuery(c, "")
+	if ctx != nil {
+		if err := rollback(ctx); err != nil {
+			logrus.Errorf("unable to roll back the transaction of client %v: %s", c.ConnectionID, err)
+		}
+	}
 	h.sm.CloseConn(c)
 
 	h.mu.Lock()
+	connected := h.connected[c.ConnectionID]
 	delete(h.c, c.ConnectionID)
+	delete(h.connected, c.ConnectionID)
 	h.mu.Unlock()
 
+	h.e.Catalog.RemoveClient(c.ConnectionID)
+
+	if connected && ctx != nil {
+		h.e.Audit.Disconnect(ctx)
+		h.e.QueryLog.Quit(ctx)
+		h.e.Sys.Disconnect(ctx)
+	}
+
 	// If connection was closed, kill only its associated queries.
 	h.e.Catalog.ProcessList.KillOnlyQueries(c.ConnectionID)
 	if err := h.e.Catalog.UnlockTables(ctx, c.ConnectionID); err != nil {
 		logrus.Errorf("unable to unlock tables on session close: %s", err)
 	}
 
+	ConnectionsGauge.Add(-1)
+	h.e.Catalog.GlobalStatus.Add(sql.StatusThreadsConnected, -1)
+
 	logrus.Infof("ConnectionClosed: client %v", c.ConnectionID)
 }
 
+// drainInterval is how often a server shutting down checks whether the
+// queries running have finished.
+const drainInterval = 10 * time.Millisecond
+
+// shutdown makes the handler reject new queries.
+func (h *Handler) shutdown() {
+	h.mu.Lock()
+	h.shuttingDown = true
+	h.mu.Unlock()
+}
+
+// drain waits until there are no queries running, or the context given is
+// done, returning its error then.
+func (h *Handler) drain(ctx context.Context) error {
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	for h.runningQueries() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// runningQueries returns the number of queries running in the engine.
+func (h *Handler) runningQueries() int {
+	var n int
+	for _, p := range h.e.Catalog.ProcessList.Processes() {
+		if p.Type == sql.QueryProcess {
+			n++
+		}
+	}
+	return n
+}
+
+// closeConnections kills the queries of all the connections, rolls back the
+// transactions of their sessions and closes them. The rest of their state is
+// released by ConnectionClosed, once the server notices they're closed.
+func (h *Handler) closeConnections() {
+	h.mu.Lock()
+	conns := make([]conntainer, 0, len(h.c))
+	for _, c := range h.c {
+		conns = append(conns, c)
+	}
+	h.mu.Unlock()
+
+	for _, nc := range conns {
+		c := nc.MysqlConn
+		h.e.Catalog.ProcessList.Kill(c.ConnectionID)
+
+		if s, ok := h.sm.session(c).(sql.TransactionSession); ok {
+			ctx := sql.NewContext(context.Background(), sql.WithSession(s))
+			if err := s.Rollback(ctx); err != nil {
+				logrus.Errorf("unable to roll back the transaction of client %v: %s", c.ConnectionID, err)
+			}
+		}
+
+		shutdownConn(nc.NetConn)
+		c.Close()
+	}
+}
+
+// shutdownConn shuts down both directions of the socket of a connection, which
+// ends the command being read and the results being written. The connection
+// checker duplicates the sockets it checks, which puts them in blocking mode
+// and keeps them open once closed, so closing them is not enough.
+func shutdownConn(c net.Conn) {
+	if sc, ok := c.(interface {
+		CloseRead() error
+		CloseWrite() error
+	}); ok {
+		_ = sc.CloseRead()
+		_ = sc.CloseWrite()
+	} else if c != nil {
+		_ = c.Close()
+	}
+}
+
 // ComQuery executes a SQL query on the SQLe engine.
 func (h *Handler) ComQuery(
 	c *mysql.Conn,
 	query string,
 	callback func(*sqltypes.Result) error,
 ) error {
-	return h.doQuery(c, query, nil, callback)
+	return sqlError(h.doQuery(c, query, nil, callback))
+}
+
+// sqlError returns the MySQL error of the errors of the engine which have
+// their own error code.
+func sqlError(err error) error {
+	if sql.ErrQueryTimeout.Is(err) {
+		return mysql.NewSQLError(erQueryTimeout, mysql.SSUnknownSQLState, "%s", err.Error())
+	}
+	return err
 }
 
 func bindingsToExprs(bindings map[string]*query.BindVariable) (map[string]sql.Expression, error) {
@@ -260,6 +645,13 @@ func (h *Handler) doQuery(
 ) error {
 	logrus.Tracef("received query %s", query)
 
+	h.mu.Lock()
+	shuttingDown := h.shuttingDown
+	h.mu.Unlock()
+	if shuttingDown {
+		return mysql.NewSQLError(mysql.ERServerShutdown, mysql.SSServerShutdown, "Server shutdown in progress")
+	}
+
 	ctx, err := h.sm.NewContextWithQuery(c, query)
 
 	if err != nil {
@@ -294,7 +686,8 @@ func (h *Handler) doQuery(
 	if len(bindings) == 0 {
 		schema, rows, err = h.e.Query(ctx, query)
 	} else {
-		sqlBindings, err := bindingsToExprs(bindings)
+		var sqlBindings map[string]sql.Expression
+		sqlBindings, err = bindingsToExprs(bindings)
 		if err != nil {
 			return err
 		}
@@ -318,6 +711,7 @@ func (h *Handler) doQuery(
 	}
 
 	var r *sqltypes.Result
+	var size int
 	var proccesedAtLeastOneBatch bool
 
 	// Reads rows from the row reading goroutine
@@ -364,13 +758,13 @@ rowLoop:
 			r = &sqltypes.Result{Fields: schemaToFields(schema)}
 		}
 
-		if r.RowsAffected == rowsBatch {
-			if err := callback(r); err != nil {
+		if r.RowsAffected == rowsBatch || size >= rowsBatchSize {
+			if err := h.writeResult(ctx, nc, callback, r); err != nil {
 				close(quit)
 				return err
 			}
 
-			r = nil
+			r, size = nil, 0
 			proccesedAtLeastOneBatch = true
 			continue
 		}
@@ -404,6 +798,9 @@ rowLoop:
 			logrus.Tracef("returning result row %s", outputRow)
 			r.Rows = append(r.Rows, outputRow)
 			r.RowsAffected++
+			for _, v := range outputRow {
+				size += v.Len()
+			}
 		case <-timer.C:
 			if h.readTimeout != 0 {
 				// Cancel and return so Vitess can call the CloseConnection callback
@@ -421,7 +818,7 @@ rowLoop:
 		return err
 	}
 
-	autoCommit := isSessionAutocommit(ctx)
+	autoCommit := isSessionAutocommit(ctx) && !inTransaction(ctx)
 
 	_, statementIsCommit := parsedQuery.(*sqlparser.Commit)
 	if statementIsCommit || (autoCommit && statementNeedsCommit(parsedQuery, parseErr)) {
@@ -438,7 +835,40 @@ rowLoop:
 		return nil
 	}
 
-	return callback(r)
+	return h.writeResult(ctx, nc, callback, r)
+}
+
+// writeResult sends a result to the client with the callback given, which
+// blocks while the client doesn't read the results sent before, so that rows
+// are read as fast as the client reads them. If the client doesn't read them
+// for @@net_write_timeout seconds, its connection is aborted, since it could
+// keep the query running forever otherwise.
+func (h *Handler) writeResult(ctx *sql.Context, nc conntainer, callback func(*sqltypes.Result) error, r *sqltypes.Result) error {
+	timeout := netWriteTimeout(ctx)
+	timer := time.AfterFunc(timeout, func() {
+		logrus.Warnf("aborting connection %d, whose client didn't read the results of its query for %s",
+			nc.MysqlConn.ConnectionID, timeout)
+		shutdownConn(nc.NetConn)
+	})
+
+	err := callback(r)
+	if !timer.Stop() {
+		h.e.Catalog.GlobalStatus.Add(sql.StatusAbortedClients, 1)
+		return mysql.NewSQLError(erNetWriteInterrupted, ssNetError, "Got timeout writing communication packets")
+	}
+	return err
+}
+
+// netWriteTimeout returns the time the client of the session given has to
+// read each batch of results, @@net_write_timeout.
+func netWriteTimeout(ctx *sql.Context) time.Duration {
+	timeout := int64(60)
+	if _, v := ctx.Get("net_write_timeout"); v != nil {
+		if seconds, ok := v.(int64); ok {
+			timeout = seconds
+		}
+	}
+	return time.Duration(timeout) * time.Second
 }
 
 // Periodically polls the connection socket to determine if it is has been closed by the client, sending an error on
@@ -496,7 +926,7 @@ func isSessionAutocommit(ctx *sql.Context) bool {
 		switch typ {
 		case sql.Int64:
 			autoCommit = autoCommitSessionVar.(int64) == int64(1)
-		case sql.Boolean:
+		case sql.Boolean, sql.Int8:
 			autoCommit, _ = sql.ConvertToBool(autoCommitSessionVar)
 		default:
 		}
@@ -504,6 +934,21 @@ func isSessionAutocommit(ctx *sql.Context) bool {
 	return autoCommit
 }
 
+// rollback rolls back the transaction started in the session of the context, if any.
+func rollback(ctx *sql.Context) error {
+	if s, ok := ctx.Session.(sql.ExplicitTransactionSession); ok && s.InTransaction() {
+		return s.Rollback(ctx)
+	}
+	return nil
+}
+
+// inTransaction returns whether the session of the context has a transaction started explicitly open, which the
+// statements run in don't commit even if autocommit is enabled.
+func inTransaction(ctx *sql.Context) bool {
+	s, ok := ctx.Session.(sql.ExplicitTransactionSession)
+	return ok && s.InTransaction()
+}
+
 func statementNeedsCommit(parsedQuery sqlparser.Statement, parseErr error) bool {
 	if parseErr == nil {
 		switch parsedQuery.(type) {
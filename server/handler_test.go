@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"math"
 	"net"
 	"testing"
 	"time"
@@ -14,6 +15,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/audit"
+	"github.com/dolthub/go-mysql-server/auth"
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/expression"
 )
@@ -103,6 +106,17 @@ func TestHandlerOutput(t *testing.T) {
 				lastRowsAffected: uint64(30),
 			},
 		},
+		{
+			name:    "with rows filling the batch size",
+			handler: handler,
+			conn:    dummyConn,
+			query:   "SELECT REPEAT('a', 10000) FROM test limit 20",
+			expected: expectedValues{
+				callsToCallback:  3,
+				lenLastBatch:     6,
+				lastRowsAffected: uint64(6),
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -191,6 +205,185 @@ func TestHandlerKill(t *testing.T) {
 	assertNoConnProcesses(t, e, conn1.ConnectionID)
 }
 
+func TestHandlerConnectionStatus(t *testing.T) {
+	require := require.New(t)
+	e := setupMemDB(require)
+
+	handler := NewHandler(
+		e,
+		NewSessionManager(
+			testSessionBuilder,
+			opentracing.NoopTracer{},
+			func(db string) bool { return db == "test" },
+			sql.NewMemoryManager(nil),
+			"foo",
+		),
+		0,
+	)
+
+	conn1, conn2 := newConn(1), newConn(2)
+	handler.NewConnection(conn1)
+	handler.NewConnection(conn2)
+	handler.ConnectionClosed(conn1)
+
+	status := e.Catalog.GlobalStatus
+	require.Equal(int64(2), status.Get(sql.StatusConnections))
+	require.Equal(int64(1), status.Get(sql.StatusThreadsConnected))
+	require.Equal(int64(2), status.Get(sql.StatusMaxUsedConnections))
+}
+
+func TestHandlerConnectionLimits(t *testing.T) {
+	require := require.New(t)
+	e := setupMemDB(require)
+
+	handler := NewHandler(
+		e,
+		NewSessionManager(
+			testSessionBuilder,
+			opentracing.NoopTracer{},
+			func(db string) bool { return db == "test" },
+			sql.NewMemoryManager(nil),
+			"foo",
+		),
+		0,
+	)
+	handler.auth = auth.NewNativeStore(auth.NewMemoryUserStore(
+		auth.User{Name: "bob", Host: "%", MaxUserConnections: 1},
+		auth.User{Name: "alice", Host: "%"},
+	))
+
+	require.NoError(e.Catalog.SystemVariables.SetGlobal("max_connections", int64(4)))
+	require.NoError(e.Catalog.SystemVariables.SetGlobal("max_user_connections", int64(2)))
+
+	conns := make(map[uint32]*mysql.Conn)
+	connect := func(id uint32, user string) error {
+		conn := newConn(id)
+		conn.User = user
+		conns[id] = conn
+		handler.NewConnection(conn)
+		return handler.ComInitDB(conn, "test")
+	}
+
+	requireErrorCode := func(code int, err error) {
+		t.Helper()
+		require.Error(err)
+		sqlErr, ok := err.(*mysql.SQLError)
+		require.True(ok, "unexpected error: %s", err)
+		require.Equal(code, sqlErr.Number())
+	}
+
+	// The limit of the account is used instead of @@max_user_connections.
+	require.NoError(connect(1, "bob"))
+	requireErrorCode(mysql.ERUserLimitReached, connect(2, "bob"))
+
+	require.NoError(connect(3, "alice"))
+	require.NoError(connect(4, "alice"))
+	requireErrorCode(mysql.ERTooManyUserConnections, connect(5, "alice"))
+
+	require.NoError(e.Catalog.SystemVariables.SetGlobal("max_user_connections", int64(0)))
+	require.NoError(connect(6, "alice"))
+	requireErrorCode(mysql.ERConCount, connect(7, "alice"))
+	require.Equal(int64(1), e.Catalog.GlobalStatus.Get(sql.StatusConnectionErrorsMaxConnections))
+
+	// Connections are only checked once.
+	require.NoError(handler.ComInitDB(conns[4], "test"))
+
+	for _, id := range []uint32{1, 2, 5, 6, 7} {
+		handler.ConnectionClosed(conns[id])
+	}
+	require.NoError(connect(8, "bob"))
+}
+
+func TestHandlerResetConnection(t *testing.T) {
+	require := require.New(t)
+	e := setupMemDB(require)
+
+	handler := NewHandler(
+		e,
+		NewSessionManager(
+			testSessionBuilder,
+			opentracing.NoopTracer{},
+			func(db string) bool { return db == "test" },
+			sql.NewMemoryManager(nil),
+			"foo",
+		),
+		0,
+	)
+
+	conn := newConn(1)
+	handler.NewConnection(conn)
+	require.NoError(handler.ComInitDB(conn, "test"))
+
+	run := func(q string) *sqltypes.Result {
+		var result *sqltypes.Result
+		err := handler.ComQuery(conn, q, func(r *sqltypes.Result) error {
+			result = r
+			return nil
+		})
+		require.NoError(err)
+		return result
+	}
+
+	run("SET @a = 1, sql_select_limit = 10")
+	run("PREPARE stmt FROM 'SELECT 1'")
+	require.Equal(sqltypes.NewInt8(1), run("SELECT GET_LOCK('lock', 0)").Rows[0][0])
+
+	handler.ComResetConnection(conn)
+
+	result := run("SELECT @a, @@sql_select_limit, IS_USED_LOCK('lock'), DATABASE()")
+	require.Equal([]sqltypes.Value{
+		sqltypes.NULL,
+		sqltypes.NewInt32(math.MaxInt32),
+		sqltypes.NULL,
+		sqltypes.MakeTrusted(sqltypes.Text, []byte("test")),
+	}, result.Rows[0])
+
+	err := handler.ComQuery(conn, "EXECUTE stmt", func(*sqltypes.Result) error { return nil })
+	require.True(sql.ErrUnknownPreparedStatement.Is(err))
+}
+
+func TestHandlerSessionGlobalVariables(t *testing.T) {
+	require := require.New(t)
+	e := setupMemDB(require)
+
+	handler := NewHandler(
+		e,
+		NewSessionManager(
+			testSessionBuilder,
+			opentracing.NoopTracer{},
+			func(db string) bool { return db == "test" },
+			sql.NewMemoryManager(nil),
+			"foo",
+		),
+		0,
+	)
+
+	run := func(conn *mysql.Conn, q string) *sqltypes.Result {
+		var result *sqltypes.Result
+		err := handler.ComQuery(conn, q, func(r *sqltypes.Result) error {
+			result = r
+			return nil
+		})
+		require.NoError(err)
+		return result
+	}
+
+	conn1, conn2 := newConn(1), newConn(2)
+	handler.NewConnection(conn1)
+	require.NoError(handler.ComInitDB(conn1, "test"))
+	run(conn1, "SET GLOBAL sql_select_limit = 10")
+
+	// New sessions start with the global values of the engine, and the
+	// ones reset go back to them.
+	handler.NewConnection(conn2)
+	require.NoError(handler.ComInitDB(conn2, "test"))
+	require.Equal(sqltypes.NewInt32(10), run(conn2, "SELECT @@sql_select_limit").Rows[0][0])
+	require.Equal(sqltypes.NewInt32(math.MaxInt32), run(conn1, "SELECT @@sql_select_limit").Rows[0][0])
+
+	handler.ComResetConnection(conn1)
+	require.Equal(sqltypes.NewInt32(10), run(conn1, "SELECT @@sql_select_limit").Rows[0][0])
+}
+
 func assertNoConnProcesses(t *testing.T, e *sqle.Engine, conn uint32) {
 	t.Helper()
 
@@ -268,6 +461,33 @@ func TestHandlerTimeout(t *testing.T) {
 	require.NoError(err)
 }
 
+func TestHandlerMaxExecutionTime(t *testing.T) {
+	require := require.New(t)
+	e := setupMemDB(require)
+
+	handler := NewHandler(
+		e, NewSessionManager(testSessionBuilder,
+			opentracing.NoopTracer{},
+			func(db string) bool { return db == "test" },
+			sql.NewMemoryManager(nil),
+			"foo"),
+		0)
+
+	conn := newConn(1)
+	handler.NewConnection(conn)
+	handler.ComInitDB(conn, "test")
+
+	noop := func(res *sqltypes.Result) error { return nil }
+
+	err := handler.ComQuery(conn, "SELECT /*+ MAX_EXECUTION_TIME(50) */ SLEEP(1)", noop)
+	require.Error(err)
+	sqlErr, ok := err.(*mysql.SQLError)
+	require.True(ok)
+	require.Equal(erQueryTimeout, sqlErr.Number())
+
+	require.NoError(handler.ComQuery(conn, "SELECT SLEEP(0.1)", noop))
+}
+
 func TestOkClosedConnection(t *testing.T) {
 	require := require.New(t)
 	e := setupMemDB(require)
@@ -361,6 +581,36 @@ func TestBindingsToExprs(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			"BadMonthDate",
+			map[string]*query.BindVariable{
+				"v1": &query.BindVariable{Type: query.Type_DATE, Value: []byte("2020-13-45")},
+			},
+			nil,
+			true,
+		},
+		{
+			"ZeroDates",
+			map[string]*query.BindVariable{
+				"date":      &query.BindVariable{Type: query.Type_DATE, Value: []byte("0000-00-00")},
+				"datetime":  &query.BindVariable{Type: query.Type_DATETIME, Value: []byte("0000-00-00 00:00:00")},
+				"timestamp": &query.BindVariable{Type: query.Type_TIMESTAMP, Value: []byte("0000-00-00 00:00:00.000000")},
+			},
+			map[string]sql.Expression{
+				"date":      expression.NewLiteral(sql.Date.Zero(), sql.Date),
+				"datetime":  expression.NewLiteral(sql.Datetime.Zero(), sql.Datetime),
+				"timestamp": expression.NewLiteral(sql.Timestamp.Zero(), sql.Timestamp),
+			},
+			false,
+		},
+		{
+			"FirstDateOfYearZero",
+			map[string]*query.BindVariable{
+				"v1": &query.BindVariable{Type: query.Type_DATETIME, Value: []byte("0000-01-01 00:00:00")},
+			},
+			nil,
+			true,
+		},
 		{
 			"BadYear",
 			map[string]*query.BindVariable{
@@ -425,3 +675,48 @@ func TestBindingsToExprs(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerAudit(t *testing.T) {
+	require := require.New(t)
+	e := setupMemDB(require)
+
+	var events []audit.Event
+	e.Audit = audit.NewLog(nil, audit.SinkFunc(func(e audit.Event) error {
+		events = append(events, e)
+		return nil
+	}))
+
+	h := NewHandler(
+		e,
+		NewSessionManager(
+			testSessionBuilder,
+			opentracing.NoopTracer{},
+			func(db string) bool { return db == "test" },
+			sql.NewMemoryManager(nil),
+			"foo",
+		),
+		0,
+	)
+
+	c := newConn(1)
+	c.User = "root"
+	h.NewConnection(c)
+	require.NoError(h.ComInitDB(c, "test"))
+	// Later COM_INIT_DB commands are not new connections.
+	require.NoError(h.ComInitDB(c, "test"))
+	require.NoError(h.ComQuery(c, "SELECT * FROM test", func(*sqltypes.Result) error {
+		return nil
+	}))
+	h.ConnectionClosed(c)
+
+	require.Len(events, 3)
+	require.Equal(audit.Connect, events[0].Type)
+	require.Equal(audit.Query, events[1].Type)
+	require.Equal([]string{"test.test"}, events[1].Tables)
+	require.Equal(audit.Disconnect, events[2].Type)
+	for i, ev := range events {
+		require.Equal(uint64(i+1), ev.Seq)
+		require.Equal(uint32(1), ev.ConnectionID)
+		require.Equal("root", ev.User)
+	}
+}
@@ -95,6 +95,10 @@ type mockConn struct {
 
 func (c *mockConn) Close() error { return nil }
 
+func (c *mockConn) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 34567}
+}
+
 func newConn(id uint32) *mysql.Conn {
 	conn := &mysql.Conn{
 		ConnectionID: id,
@@ -1,29 +1,112 @@
 package server
 
 import (
+	"crypto/tls"
 	"net"
+
+	"github.com/sirupsen/logrus"
 )
 
+// ConnMiddleware is called on each connection a server accepts, before the
+// MySQL protocol is spoken on it, and returns the connection the server
+// handles, which may wrap the one given, or an error to reject it, which
+// closes the connection. It's called in the goroutine accepting connections,
+// so connections can be throttled by blocking in it. The remote address of
+// the connections of proxies sending a PROXY protocol header is the one of
+// the proxy, since the header is read later.
+type ConnMiddleware func(conn net.Conn) (net.Conn, error)
+
 type Listener struct {
 	net.Listener
 	h *Handler
+	// compress is whether the compressed protocol is announced to clients.
+	compress bool
+	// tlsConfig is the TLS configuration the clients asking for TLS switch
+	// to, if the server supports TLS, and allowClearText allows the others
+	// to send their password in clear text.
+	tlsConfig      *tls.Config
+	allowClearText bool
+	// proxies are the networks of the proxies whose connections start with a
+	// PROXY protocol header.
+	proxies proxyNetworks
+	// middleware is called in order on the connections accepted.
+	middleware []ConnMiddleware
 }
 
 // NewListener creates a new Listener.
 func NewListener(protocol, address string, handler *Handler) (*Listener, error) {
-	l, err := net.Listen(protocol, address)
+	l, err := listen(protocol, address)
 	if err != nil {
 		return nil, err
 	}
-	return &Listener{l, handler}, nil
+	return &Listener{Listener: l, h: handler}, nil
+}
+
+// newListener creates the listener of a server, accepting the connections of
+// its listener or its address, its socket, or both.
+func newListener(cfg Config, handler *Handler) (*Listener, error) {
+	l := cfg.Listener
+	if l == nil && (cfg.Address != "" || cfg.Socket == "") {
+		var err error
+		if l, err = listen(cfg.Protocol, cfg.Address); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Socket != "" {
+		socket, err := listenSocket(cfg.Socket)
+		if err != nil {
+			if cfg.Listener == nil && l != nil {
+				l.Close()
+			}
+			return nil, err
+		}
+
+		if l == nil {
+			l = socket
+		} else {
+			l = newMultiListener(l, socket)
+		}
+	}
+
+	return &Listener{Listener: l, h: handler, middleware: cfg.ConnMiddleware}, nil
 }
 
 func (l *Listener) Accept() (net.Conn, error) {
-	conn, err := l.Listener.Accept()
-	if err != nil {
-		return nil, err
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		wrapped, err := l.wrap(conn)
+		if err != nil {
+			logrus.Infof("rejecting connection from %s: %s", conn.RemoteAddr(), err)
+			conn.Close()
+			continue
+		}
+
+		sc := newServerConn(wrapped, l.compress)
+		sc.socket = conn
+		sc.tlsConfig, sc.allowClearText = l.tlsConfig, l.allowClearText
+		if uc, ok := conn.(*net.UnixConn); ok {
+			sc.remote = peerAddr(uc)
+		}
+		sc.proxied = l.proxies.trusts(conn.RemoteAddr())
+
+		conn = sc
+		l.h.AddNetConnection(&conn)
+		return conn, nil
 	}
+}
 
-	l.h.AddNetConnection(&conn)
-	return conn, err
+// wrap calls the middleware of the listener on a connection accepted.
+func (l *Listener) wrap(conn net.Conn) (net.Conn, error) {
+	for _, m := range l.middleware {
+		var err error
+		if conn, err = m(conn); err != nil {
+			return nil, err
+		}
+	}
+	return conn, nil
 }
@@ -0,0 +1,233 @@
+package mysqlx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// Types of the objects listed by list_objects.
+const (
+	objectCollection = "COLLECTION"
+	objectTable      = "TABLE"
+	objectView       = "VIEW"
+)
+
+// listObjectsSchema is the schema of the result of list_objects.
+var listObjectsSchema = sql.Schema{
+	{Name: "name", Type: sql.LongText},
+	{Name: "type", Type: sql.LongText},
+}
+
+// adminCommand runs a command of the mysqlx namespace, whose arguments are
+// given either as an object or by position.
+func (c *conn) adminCommand(name string, args []*anyValue) error {
+	switch name {
+	case "ping":
+		return c.sendExecuteOk(nil)
+	case "list_objects":
+		a, err := adminArgs(args, "schema", "pattern")
+		if err != nil {
+			return err
+		}
+		return c.listObjects(a["schema"], a["pattern"])
+	case "create_collection", "ensure_collection":
+		a, err := adminArgs(args, "schema", "name")
+		if err != nil {
+			return err
+		}
+
+		table, err := collectionName(a)
+		if err != nil {
+			return err
+		}
+
+		ifNotExists := ""
+		if name == "ensure_collection" {
+			ifNotExists = "IF NOT EXISTS "
+		}
+		return c.executeSQL(fmt.Sprintf(
+			"CREATE TABLE %s%s (doc JSON, _id VARCHAR(32) NOT NULL, PRIMARY KEY (_id))",
+			ifNotExists, table,
+		), nil)
+	case "drop_collection":
+		a, err := adminArgs(args, "schema", "name")
+		if err != nil {
+			return err
+		}
+
+		table, err := collectionName(a)
+		if err != nil {
+			return err
+		}
+		return c.executeSQL("DROP TABLE "+table, nil)
+	default:
+		return newError(erXInvalidAdminCommand, "Invalid mysqlx command %s", name)
+	}
+}
+
+// listObjects sends the collections, tables and views of a schema, the
+// current one by default, whose names match the LIKE pattern given, if any.
+func (c *conn) listObjects(schema, pattern string) error {
+	ctx := c.newContext("")
+	if schema == "" {
+		schema = ctx.GetCurrentDatabase()
+	}
+
+	db, err := c.s.e.Catalog.Database(schema)
+	if err != nil {
+		return err
+	}
+
+	names, err := db.GetTableNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	var rows []sql.Row
+	for _, name := range names {
+		t, ok, err := db.GetTableInsensitive(ctx, name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		typ := objectTable
+		if isCollection(t.Schema()) {
+			typ = objectCollection
+		}
+		rows = append(rows, sql.NewRow(name, typ))
+	}
+
+	if ctx.ViewRegistry != nil {
+		for _, v := range ctx.ViewRegistry.ViewsInDatabase(db.Name()) {
+			rows = append(rows, sql.NewRow(v.Name(), objectView))
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0].(string) < rows[j][0].(string) })
+
+	if err := c.sendMetaData(listObjectsSchema); err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		if pattern != "" {
+			like := expression.NewLike(
+				expression.NewLiteral(r[0], sql.LongText),
+				expression.NewLiteral(pattern, sql.LongText),
+			)
+			match, err := like.Eval(ctx, nil)
+			if err != nil {
+				return err
+			}
+			if match != true {
+				continue
+			}
+		}
+
+		msg, err := encodeRow(listObjectsSchema, r)
+		if err != nil {
+			return err
+		}
+		if err := c.send(serverResultsetRow, msg); err != nil {
+			return err
+		}
+	}
+
+	if err := c.send(serverResultsetFetchDone, &message{}); err != nil {
+		return err
+	}
+	return c.sendExecuteOk(nil)
+}
+
+// isCollection returns whether the schema of a table is the one of a
+// collection, with a doc column and an _id column.
+func isCollection(schema sql.Schema) bool {
+	if len(schema) != 2 {
+		return false
+	}
+
+	var doc, id bool
+	for _, c := range schema {
+		switch strings.ToLower(c.Name) {
+		case "doc":
+			doc = c.Type == sql.JSON
+		case "_id":
+			id = c.PrimaryKey
+		}
+	}
+	return doc && id
+}
+
+// collectionName returns the name of the table of the collection given in
+// the arguments of a command.
+func collectionName(args map[string]string) (string, error) {
+	name := args["name"]
+	if name == "" {
+		return "", newError(erXCmdArgumentValue, "Invalid value for argument 'name'")
+	}
+	schema := args["schema"]
+	return tableName(&collection{Name: proto.String(name), Schema: &schema})
+}
+
+// adminArgs returns the arguments of a command, which are given either as
+// an object whose fields are the names of the arguments, or as scalars in
+// the order of the names given. All of them are strings.
+func adminArgs(args []*anyValue, names ...string) (map[string]string, error) {
+	known := make(map[string]bool, len(names))
+	for _, n := range names {
+		known[n] = true
+	}
+
+	result := make(map[string]string, len(names))
+	if len(args) == 1 && args[0].GetType() == anyObject {
+		for _, f := range args[0].Obj.GetFld() {
+			if !known[f.GetKey()] {
+				return nil, newError(erXCmdArgumentValue, "Invalid argument %s", f.GetKey())
+			}
+
+			v, err := adminArg(f.GetKey(), f.Value)
+			if err != nil {
+				return nil, err
+			}
+			result[f.GetKey()] = v
+		}
+		return result, nil
+	}
+
+	if len(args) > len(names) {
+		return nil, newError(erXCmdArgumentValue, "Too many arguments")
+	}
+	for i, a := range args {
+		v, err := adminArg(names[i], a)
+		if err != nil {
+			return nil, err
+		}
+		result[names[i]] = v
+	}
+	return result, nil
+}
+
+func adminArg(name string, a *anyValue) (string, error) {
+	if a.GetType() != anyScalar || a.Scalar == nil {
+		return "", newError(erXCmdArgumentValue, "Invalid type of argument %s, expected a string", name)
+	}
+
+	switch a.Scalar.GetType() {
+	case scalarString:
+		return string(a.Scalar.VString.GetValue()), nil
+	case scalarOctets:
+		return string(a.Scalar.VOctets.GetValue()), nil
+	case scalarNull:
+		return "", nil
+	default:
+		return "", newError(erXCmdArgumentValue, "Invalid type of argument %s, expected a string", name)
+	}
+}
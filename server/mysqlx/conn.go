@@ -0,0 +1,659 @@
+package mysqlx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/golang/protobuf/proto"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Authentication mechanisms.
+const (
+	mechanismMySQL41 = "MYSQL41"
+	mechanismPlain   = "PLAIN"
+)
+
+// Error codes of the MySQL errors the connections return.
+const (
+	erConCount             = 1040
+	erNotSupportedAuthMode = 1251
+	erXExpectFieldExists   = 5168
+)
+
+// firstPid is the first ID of the processes of the queries of the X
+// Protocol, so that they don't collide with the ones of the classic protocol.
+const firstPid = 1 << 63
+
+// pids counts the processes of the queries of all the endpoints.
+var pids uint64 = firstPid
+
+// expectFields are the fields of the client messages the server supports,
+// as the message type and the number of the field, for the field_exists
+// condition of the expectation blocks.
+var expectFields = map[string]bool{
+	"6.1": true, // Mysqlx.Session.Reset.keep_open
+}
+
+// expectation is an expectation block opened by a client.
+type expectation struct {
+	// noError is whether the messages of the block fail once one fails.
+	noError bool
+	// failed is whether a message of the block failed, if noError is set.
+	failed bool
+}
+
+// conn is a connection of a client.
+type conn struct {
+	s  *Server
+	nc net.Conn
+	id uint32
+	r  *bufio.Reader
+	w  *bufio.Writer
+
+	// attrs are the connection attributes the client sets as the
+	// session_connect_attrs capability.
+	attrs map[string]string
+	// mechanism and salt of the MYSQL41 authentication in progress, if any.
+	mechanism string
+	salt      []byte
+
+	// session of the client once it authenticates, and whether it was
+	// logged as connected.
+	session   sql.Session
+	idxReg    *sql.IndexRegistry
+	viewReg   *sql.ViewRegistry
+	connected bool
+
+	expectations []expectation
+	closing      bool
+}
+
+func newConn(s *Server, nc net.Conn, id uint32) *conn {
+	return &conn{
+		s:  s,
+		nc: nc,
+		id: id,
+		r:  bufio.NewReader(nc),
+		w:  bufio.NewWriter(nc),
+	}
+}
+
+// serve handles the messages of the client until the connection is closed.
+func (c *conn) serve() {
+	status := c.s.e.Catalog.GlobalStatus
+	status.Add(sql.StatusConnections, 1)
+	status.SetMax(sql.StatusMaxUsedConnections, status.Add(sql.StatusThreadsConnected, 1))
+	logrus.Infof("mysqlx: new connection: client %v", c.id)
+
+	defer c.close()
+	for !c.closing {
+		if c.s.cfg.ConnReadTimeout > 0 {
+			_ = c.nc.SetReadDeadline(time.Now().Add(c.s.cfg.ConnReadTimeout))
+		}
+
+		typ, payload, err := readMessage(c.r)
+		if err != nil {
+			if xerr, ok := err.(*Error); ok {
+				_ = c.sendError(xerr)
+				_ = c.flush()
+			} else if err != io.EOF {
+				logrus.Debugf("mysqlx: unable to read message from client %v: %s", c.id, err)
+			}
+			return
+		}
+
+		if err := c.handle(typ, payload); err != nil {
+			xerr := toError(err)
+			c.failExpectation()
+			if err := c.sendError(xerr); err != nil || xerr.Fatal {
+				_ = c.flush()
+				return
+			}
+		}
+
+		if err := c.flush(); err != nil {
+			logrus.Debugf("mysqlx: unable to write to client %v: %s", c.id, err)
+			return
+		}
+	}
+}
+
+// close ends the session of the client, if any, and closes the connection.
+func (c *conn) close() {
+	c.endSession()
+	c.nc.Close()
+	c.s.removeConn(c)
+
+	c.s.e.Catalog.GlobalStatus.Add(sql.StatusThreadsConnected, -1)
+	logrus.Infof("mysqlx: connection closed: client %v", c.id)
+}
+
+// handle handles a message of the client.
+func (c *conn) handle(typ byte, payload []byte) error {
+	switch typ {
+	case clientConCapabilitiesGet:
+		return c.send(serverConnCapabilities, c.capabilities())
+	case clientConCapabilitiesSet:
+		return c.setCapabilities(payload)
+	case clientConClose:
+		c.closing = true
+		return c.send(serverOk, &okMessage{Msg: proto.String("bye!")})
+	case clientSessAuthenticateStart:
+		return c.authenticateStart(payload)
+	case clientSessAuthenticateCont:
+		return c.authenticateContinue(payload)
+	}
+
+	if c.session == nil {
+		return errUnexpectedMessage()
+	}
+
+	switch typ {
+	case clientExpectOpen:
+		return c.expectOpen(payload)
+	case clientExpectClose:
+		return c.expectClose()
+	}
+
+	if n := len(c.expectations); n > 0 && c.expectations[n-1].failed {
+		return newError(erXExpectFailed, "Expectation failed: no_error")
+	}
+
+	switch typ {
+	case clientSessReset:
+		return c.resetSession(payload)
+	case clientSessClose:
+		c.endSession()
+		return c.send(serverOk, &okMessage{Msg: proto.String("bye!")})
+	case clientSQLStmtExecute:
+		return c.stmtExecute(payload)
+	case clientCrudFind:
+		return c.find(payload)
+	case clientCrudInsert:
+		return c.insert(payload)
+	case clientCrudUpdate:
+		return c.update(payload)
+	case clientCrudDelete:
+		return c.delete(payload)
+	default:
+		return errUnexpectedMessage()
+	}
+}
+
+// capabilities returns the capabilities of the server. TLS and compression
+// are not supported.
+func (c *conn) capabilities() *capabilities {
+	mechanisms := []*anyValue{newScalarAny(newStringScalar(mechanismMySQL41))}
+	if c.s.cfg.AllowClearTextWithoutTLS {
+		mechanisms = append(mechanisms, newScalarAny(newStringScalar(mechanismPlain)))
+	}
+
+	return &capabilities{Capabilities: []*capability{
+		{Name: proto.String("authentication.mechanisms"), Value: &anyValue{Type: proto.Int32(anyArray), Array: &array{Value: mechanisms}}},
+		{Name: proto.String("doc.formats"), Value: newScalarAny(newStringScalar("text"))},
+		{Name: proto.String("node_type"), Value: newScalarAny(newStringScalar("mysql"))},
+		{Name: proto.String("client.pwd_expire_ok"), Value: newScalarAny(&scalar{Type: proto.Int32(scalarBool), VBool: proto.Bool(false)})},
+	}}
+}
+
+// setCapabilities sets the capabilities of the client, which are all
+// checked before any is set.
+func (c *conn) setCapabilities(payload []byte) error {
+	var m capabilitiesSet
+	if err := unmarshal(payload, &m); err != nil {
+		return err
+	}
+
+	var attrs map[string]string
+	for _, cap := range m.Capabilities.Capabilities {
+		switch name := cap.GetName(); name {
+		case "client.pwd_expire_ok", "client.interactive":
+		case "session_connect_attrs":
+			if c.session != nil {
+				return newError(erXCapabilitiesPrepare, "Capability prepare failed for '%s'", name)
+			}
+
+			v, err := anyJSON(cap.Value)
+			if err != nil {
+				return err
+			}
+
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return newError(erXCapabilitiesPrepare, "Capability prepare failed for '%s'", name)
+			}
+
+			attrs = make(map[string]string, len(obj))
+			for k, v := range obj {
+				attrs[k] = fmt.Sprint(v)
+			}
+		case "tls", "compression":
+			return newError(erXCapabilitiesPrepare, "Capability prepare failed for '%s'", name)
+		default:
+			return newError(erXCapabilityNotFound, "Capability '%s' doesn't exist", name)
+		}
+	}
+
+	if attrs != nil {
+		c.attrs = attrs
+	}
+	return c.send(serverOk, &okMessage{})
+}
+
+// authenticateStart starts the authentication of the client. With MYSQL41,
+// the client is sent a salt to scramble its password with. With PLAIN, the
+// client sends its password right away.
+func (c *conn) authenticateStart(payload []byte) error {
+	if c.session != nil {
+		return errUnexpectedMessage()
+	}
+
+	var m authenticateStart
+	if err := unmarshal(payload, &m); err != nil {
+		return err
+	}
+
+	switch name := m.GetMechName(); {
+	case name == mechanismMySQL41:
+		salt, err := mysql.NewSalt()
+		if err != nil {
+			return err
+		}
+
+		c.mechanism, c.salt = name, salt
+		return c.send(serverSessAuthenticateContinue, &authenticateContinue{AuthData: salt})
+	case name == mechanismPlain && c.s.cfg.AllowClearTextWithoutTLS:
+		schema, user, password, ok := splitAuthData(m.AuthData)
+		if !ok {
+			return errAccessDenied()
+		}
+
+		salt, err := mysql.NewSalt()
+		if err != nil {
+			return err
+		}
+		return c.authenticate(schema, user, salt, mysql.ScramblePassword(salt, password))
+	default:
+		return newError(erNotSupportedAuthMode, "Invalid authentication method %s", name)
+	}
+}
+
+// authenticateContinue completes the MYSQL41 authentication of the client,
+// which replies with the schema, the user and the hex of its scrambled
+// password, preceded by an asterisk if not empty.
+func (c *conn) authenticateContinue(payload []byte) error {
+	if c.session != nil || c.mechanism != mechanismMySQL41 {
+		return errUnexpectedMessage()
+	}
+
+	var m authenticateContinue
+	if err := unmarshal(payload, &m); err != nil {
+		return err
+	}
+
+	salt := c.salt
+	c.mechanism, c.salt = "", nil
+
+	schema, user, reply, ok := splitAuthData(m.AuthData)
+	if !ok {
+		return errAccessDenied()
+	}
+
+	var scramble []byte
+	if len(reply) > 0 {
+		var err error
+		if scramble, err = hex.DecodeString(string(bytes.TrimPrefix(reply, []byte("*")))); err != nil {
+			return errAccessDenied()
+		}
+	}
+
+	return c.authenticate(schema, user, salt, scramble)
+}
+
+// authenticate checks the password of the user, scrambled with the salt
+// given, and creates its session, using the schema given, if any.
+func (c *conn) authenticate(schema, user string, salt, scramble []byte) error {
+	as := c.s.cfg.Auth.Mysql()
+	if method, err := as.AuthMethod(user); err != nil || method != mysql.MysqlNativePassword {
+		return errAccessDenied()
+	}
+
+	getter, err := as.ValidateHash(salt, user, scramble, c.nc.RemoteAddr())
+	if err != nil {
+		return err
+	}
+
+	client := sql.Client{Address: c.nc.RemoteAddr().String(), User: user, ConnectAttrs: c.attrs}
+	if p, ok := getter.(auth.ProxyUserData); ok {
+		client.User, client.ProxyUser = p.User, p.Proxy
+	}
+
+	c.session = sql.NewSessionWithClient(c.s.cfg.Address, client, c.id)
+	c.idxReg, c.viewReg = sql.NewIndexRegistry(), sql.NewViewRegistry()
+
+	err = c.checkConnection(user)
+	c.logConnect(schema, err)
+	if err != nil {
+		c.endSession()
+		return err
+	}
+
+	if schema != "" {
+		if !c.s.e.Catalog.HasDB(schema) {
+			c.endSession()
+			return &Error{Code: mysql.ERBadDb, SQLState: "42000", Message: fmt.Sprintf("Unknown database '%s'", schema)}
+		}
+		c.session.SetCurrentDatabase(schema)
+	}
+
+	if err := c.sendStateChange(stateClientIDAssigned, newUintScalar(uint64(c.id))); err != nil {
+		return err
+	}
+	return c.send(serverSessAuthenticateOk, &authenticateOk{})
+}
+
+// checkConnection checks that the connection satisfies the transport
+// requirements of the server and the user, as the classic protocol does.
+func (c *conn) checkConnection(user string) error {
+	if c.s.cfg.RequireSecureTransport {
+		return newError(erSecureTransportRequire, "Connections using insecure transport are prohibited while --require_secure_transport=ON.")
+	}
+
+	checker, ok := c.s.cfg.Auth.(auth.ConnectionChecker)
+	if !ok {
+		return nil
+	}
+
+	return checker.CheckConnection(auth.Connection{
+		User:    user,
+		Address: c.nc.RemoteAddr().String(),
+	})
+}
+
+// logConnect logs the connection of the client to the audit log and the
+// query log of the engine, and marks it as connected if it was accepted,
+// which the statistics of the sys database count and the catalog lists the
+// client of.
+func (c *conn) logConnect(schema string, err error) {
+	ctx := c.newContext("")
+	if err == nil {
+		c.connected = true
+		c.s.e.Catalog.AddClient(c.id, ctx.Client())
+	}
+
+	c.s.e.Audit.Connect(ctx, err)
+	c.s.e.QueryLog.Connect(ctx, schema, err)
+	if err == nil {
+		c.s.e.Sys.Connect(ctx)
+	}
+}
+
+// endSession ends the session of the client, if any, which must
+// authenticate again to start another one.
+func (c *conn) endSession() {
+	if c.session == nil {
+		return
+	}
+
+	ctx := c.newContext("")
+	c.s.e.Catalog.RemoveClient(c.id)
+	if c.connected {
+		c.s.e.Audit.Disconnect(ctx)
+		c.s.e.QueryLog.Quit(ctx)
+		c.s.e.Sys.Disconnect(ctx)
+	}
+
+	c.s.e.Catalog.ProcessList.KillOnlyQueries(c.id)
+	if err := c.s.e.Catalog.UnlockTables(ctx, c.id); err != nil {
+		logrus.Errorf("mysqlx: unable to unlock tables on session close: %s", err)
+	}
+
+	c.session, c.idxReg, c.viewReg, c.connected = nil, nil, nil, false
+	c.expectations = nil
+}
+
+// resetSession resets the session of the client. Unless keep_open is set,
+// the session is ended, and the client must authenticate again.
+func (c *conn) resetSession(payload []byte) error {
+	var m sessionReset
+	if err := unmarshal(payload, &m); err != nil {
+		return err
+	}
+
+	if !m.GetKeepOpen() {
+		c.endSession()
+		return c.send(serverOk, &okMessage{})
+	}
+
+	ctx := c.newContext("")
+	if err := c.s.e.Catalog.UnlockTables(ctx, c.id); err != nil {
+		logrus.Errorf("mysqlx: unable to unlock tables on session reset: %s", err)
+	}
+
+	if c.s.e.LS != nil {
+		if _, err := c.s.e.LS.ReleaseAll(ctx); err != nil {
+			logrus.Errorf("mysqlx: unable to release locks on session reset: %s", err)
+		}
+	}
+
+	if s, ok := c.session.(sql.ResettableSession); ok {
+		s.Reset()
+	}
+	return c.send(serverOk, &okMessage{})
+}
+
+// expectOpen opens an expectation block. Its conditions are copied from the
+// enclosing block, unless the client asks for an empty one.
+func (c *conn) expectOpen(payload []byte) error {
+	var m expectOpen
+	if err := unmarshal(payload, &m); err != nil {
+		return err
+	}
+
+	var e expectation
+	if n := len(c.expectations); n > 0 {
+		parent := c.expectations[n-1]
+		if m.GetOp() == expectCtxCopyPrev {
+			e.noError = parent.noError
+		}
+
+		// Blocks opened in failed ones fail too.
+		if parent.failed {
+			c.expectations = append(c.expectations, expectation{noError: true, failed: true})
+			return newError(erXExpectFailed, "Expectation failed: no_error")
+		}
+	}
+
+	for _, cond := range m.Cond {
+		switch cond.GetConditionKey() {
+		case expectNoError:
+			e.noError = cond.GetOp() == expectOpSet
+		case expectFieldExist:
+			field := string(cond.ConditionValue)
+			if cond.GetOp() == expectOpSet && !expectFields[field] {
+				c.expectations = append(c.expectations, expectation{noError: true, failed: true})
+				return newError(erXExpectFieldExists, "Expectation failed: field_exists = '%s'", field)
+			}
+		default:
+			c.expectations = append(c.expectations, expectation{noError: true, failed: true})
+			return newError(erXExpectBadCondition, "Unknown condition key %d", cond.GetConditionKey())
+		}
+	}
+
+	c.expectations = append(c.expectations, e)
+	return c.send(serverOk, &okMessage{})
+}
+
+// expectClose closes the innermost expectation block, failing if a message
+// of it failed.
+func (c *conn) expectClose() error {
+	n := len(c.expectations)
+	if n == 0 {
+		return newError(erXExpectNotOpen, "Expect block currently not open")
+	}
+
+	e := c.expectations[n-1]
+	c.expectations = c.expectations[:n-1]
+	if e.failed {
+		return newError(erXExpectFailed, "Expectation failed: no_error")
+	}
+	return c.send(serverOk, &okMessage{})
+}
+
+// failExpectation marks the innermost expectation block as failed, if its
+// messages must not fail.
+func (c *conn) failExpectation() {
+	if n := len(c.expectations); n > 0 && c.expectations[n-1].noError {
+		c.expectations[n-1].failed = true
+	}
+}
+
+// newContext creates a context for a query of the session of the client.
+func (c *conn) newContext(query string) *sql.Context {
+	tracer := c.s.cfg.Tracer
+	span := tracer.StartSpan("query", ext.SpanKindRPCServer)
+	if query != "" {
+		ext.DBType.Set(span, "sql")
+		ext.DBStatement.Set(span, query)
+	}
+
+	return sql.NewContext(
+		opentracing.ContextWithSpan(context.Background(), span),
+		sql.WithSession(c.session),
+		sql.WithTracer(tracer),
+		sql.WithPid(atomic.AddUint64(&pids, 1)),
+		sql.WithQuery(query),
+		sql.WithMemoryManager(c.s.e.Catalog.MemoryManager),
+		sql.WithRootSpan(span),
+		sql.WithIndexRegistry(c.idxReg),
+		sql.WithViewRegistry(c.viewReg),
+	)
+}
+
+func (c *conn) send(typ byte, msg proto.Message) error {
+	return writeMessage(c.w, typ, msg)
+}
+
+func (c *conn) sendError(e *Error) error {
+	return c.send(serverError, errorMsg(e))
+}
+
+// sendNotice sends a notice local to the current message.
+func (c *conn) sendNotice(typ uint32, msg proto.Message) error {
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.send(serverNotice, &frame{Type: proto.Uint32(typ), Scope: proto.Int32(scopeLocal), Payload: payload})
+}
+
+// sendStateChange sends a SessionStateChanged notice.
+func (c *conn) sendStateChange(param int32, values ...*scalar) error {
+	return c.sendNotice(noticeSessionStateChanged, &sessionStateChanged{Param: proto.Int32(param), Value: values})
+}
+
+func (c *conn) flush() error {
+	if c.s.cfg.ConnWriteTimeout > 0 {
+		_ = c.nc.SetWriteDeadline(time.Now().Add(c.s.cfg.ConnWriteTimeout))
+	}
+	return c.w.Flush()
+}
+
+// errorMsg returns the message of an error.
+func errorMsg(e *Error) *errorMessage {
+	severity := int32(severityError)
+	if e.Fatal {
+		severity = severityFatal
+	}
+
+	return &errorMessage{
+		Severity: proto.Int32(severity),
+		Code:     proto.Uint32(e.Code),
+		Msg:      proto.String(e.Message),
+		SQLState: proto.String(e.SQLState),
+	}
+}
+
+// unmarshal decodes the payload of a message of the client.
+func unmarshal(payload []byte, msg proto.Message) error {
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return newError(erXBadMessage, "Invalid message: %s", err)
+	}
+	return nil
+}
+
+// splitAuthData splits the authentication data of the clients, which is
+// made of the schema, the user and the password, separated by zero bytes.
+func splitAuthData(data []byte) (string, string, []byte, bool) {
+	parts := bytes.SplitN(data, []byte{0}, 3)
+	if len(parts) != 3 {
+		return "", "", nil, false
+	}
+	return string(parts[0]), string(parts[1]), parts[2], true
+}
+
+func errUnexpectedMessage() *Error {
+	return &Error{Code: mysql.ERUnknownComError, SQLState: mysql.SSUnknownComError, Message: "Unexpected message received"}
+}
+
+func errAccessDenied() *Error {
+	return &Error{Code: mysql.ERAccessDeniedError, SQLState: mysql.SSAccessDeniedError, Message: "Invalid user or password"}
+}
+
+// Getters of the optional fields, as the ones generated.
+
+func (m *capability) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *authenticateStart) GetMechName() string {
+	if m != nil && m.MechName != nil {
+		return *m.MechName
+	}
+	return ""
+}
+
+func (m *sessionReset) GetKeepOpen() bool {
+	if m != nil && m.KeepOpen != nil {
+		return *m.KeepOpen
+	}
+	return false
+}
+
+func (m *expectOpen) GetOp() int32 {
+	if m != nil && m.Op != nil {
+		return *m.Op
+	}
+	return expectCtxCopyPrev
+}
+
+func (m *expectCondition) GetConditionKey() uint32 {
+	if m != nil && m.ConditionKey != nil {
+		return *m.ConditionKey
+	}
+	return 0
+}
+
+func (m *expectCondition) GetOp() int32 {
+	if m != nil && m.Op != nil {
+		return *m.Op
+	}
+	return expectOpSet
+}
@@ -0,0 +1,752 @@
+package mysqlx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Data models of the CRUD messages.
+const (
+	dataModelDocument = 1
+	dataModelTable    = 2
+)
+
+// Directions of the orders.
+const (
+	orderAsc  = 1
+	orderDesc = 2
+)
+
+// Types of the update operations.
+const (
+	updateSet         = 1
+	updateItemRemove  = 2
+	updateItemSet     = 3
+	updateItemReplace = 4
+	updateItemMerge   = 5
+	updateArrayInsert = 6
+	updateArrayAppend = 7
+	updateMergePatch  = 8
+)
+
+// erXBadMemberToUpdate is returned for the updates of the IDs of documents.
+const erXBadMemberToUpdate = 5053
+
+// collection is Mysqlx.Crud.Collection.
+type collection struct {
+	Name   *string `protobuf:"bytes,1,req,name=name"`
+	Schema *string `protobuf:"bytes,2,opt,name=schema"`
+}
+
+func (m *collection) Reset()         { *m = collection{} }
+func (m *collection) String() string { return proto.CompactTextString(m) }
+func (*collection) ProtoMessage()    {}
+
+// projection is Mysqlx.Crud.Projection.
+type projection struct {
+	Source *expr   `protobuf:"bytes,1,req,name=source"`
+	Alias  *string `protobuf:"bytes,2,opt,name=alias"`
+}
+
+func (m *projection) Reset()         { *m = projection{} }
+func (m *projection) String() string { return proto.CompactTextString(m) }
+func (*projection) ProtoMessage()    {}
+
+// column is Mysqlx.Crud.Column.
+type column struct {
+	Name         *string             `protobuf:"bytes,1,opt,name=name"`
+	Alias        *string             `protobuf:"bytes,2,opt,name=alias"`
+	DocumentPath []*documentPathItem `protobuf:"bytes,3,rep,name=document_path"`
+}
+
+func (m *column) Reset()         { *m = column{} }
+func (m *column) String() string { return proto.CompactTextString(m) }
+func (*column) ProtoMessage()    {}
+
+// order is Mysqlx.Crud.Order.
+type order struct {
+	Expr      *expr  `protobuf:"bytes,1,req,name=expr"`
+	Direction *int32 `protobuf:"varint,2,opt,name=direction"`
+}
+
+func (m *order) Reset()         { *m = order{} }
+func (m *order) String() string { return proto.CompactTextString(m) }
+func (*order) ProtoMessage()    {}
+
+// limit is Mysqlx.Crud.Limit.
+type limit struct {
+	RowCount *uint64 `protobuf:"varint,1,req,name=row_count"`
+	Offset   *uint64 `protobuf:"varint,2,opt,name=offset"`
+}
+
+func (m *limit) Reset()         { *m = limit{} }
+func (m *limit) String() string { return proto.CompactTextString(m) }
+func (*limit) ProtoMessage()    {}
+
+// limitExpr is Mysqlx.Crud.LimitExpr.
+type limitExpr struct {
+	RowCount *expr `protobuf:"bytes,1,req,name=row_count"`
+	Offset   *expr `protobuf:"bytes,2,opt,name=offset"`
+}
+
+func (m *limitExpr) Reset()         { *m = limitExpr{} }
+func (m *limitExpr) String() string { return proto.CompactTextString(m) }
+func (*limitExpr) ProtoMessage()    {}
+
+// updateOperation is Mysqlx.Crud.UpdateOperation.
+type updateOperation struct {
+	Source    *columnIdentifier `protobuf:"bytes,1,req,name=source"`
+	Operation *int32            `protobuf:"varint,2,req,name=operation"`
+	Value     *expr             `protobuf:"bytes,3,opt,name=value"`
+}
+
+func (m *updateOperation) Reset()         { *m = updateOperation{} }
+func (m *updateOperation) String() string { return proto.CompactTextString(m) }
+func (*updateOperation) ProtoMessage()    {}
+
+// find is Mysqlx.Crud.Find.
+type find struct {
+	Collection       *collection   `protobuf:"bytes,2,req,name=collection"`
+	DataModel        *int32        `protobuf:"varint,3,opt,name=data_model"`
+	Projection       []*projection `protobuf:"bytes,4,rep,name=projection"`
+	Criteria         *expr         `protobuf:"bytes,5,opt,name=criteria"`
+	Limit            *limit        `protobuf:"bytes,6,opt,name=limit"`
+	Order            []*order      `protobuf:"bytes,7,rep,name=order"`
+	Grouping         []*expr       `protobuf:"bytes,8,rep,name=grouping"`
+	GroupingCriteria *expr         `protobuf:"bytes,9,opt,name=grouping_criteria"`
+	Args             []*scalar     `protobuf:"bytes,11,rep,name=args"`
+	Locking          *int32        `protobuf:"varint,12,opt,name=locking"`
+	LockingOptions   *int32        `protobuf:"varint,13,opt,name=locking_options"`
+	LimitExpr        *limitExpr    `protobuf:"bytes,14,opt,name=limit_expr"`
+}
+
+func (m *find) Reset()         { *m = find{} }
+func (m *find) String() string { return proto.CompactTextString(m) }
+func (*find) ProtoMessage()    {}
+
+// typedRow is Mysqlx.Crud.Insert.TypedRow.
+type typedRow struct {
+	Field []*expr `protobuf:"bytes,1,rep,name=field"`
+}
+
+func (m *typedRow) Reset()         { *m = typedRow{} }
+func (m *typedRow) String() string { return proto.CompactTextString(m) }
+func (*typedRow) ProtoMessage()    {}
+
+// insert is Mysqlx.Crud.Insert.
+type insert struct {
+	Collection *collection `protobuf:"bytes,1,req,name=collection"`
+	DataModel  *int32      `protobuf:"varint,2,opt,name=data_model"`
+	Projection []*column   `protobuf:"bytes,3,rep,name=projection"`
+	Row        []*typedRow `protobuf:"bytes,4,rep,name=row"`
+	Args       []*scalar   `protobuf:"bytes,5,rep,name=args"`
+	Upsert     *bool       `protobuf:"varint,6,opt,name=upsert"`
+}
+
+func (m *insert) Reset()         { *m = insert{} }
+func (m *insert) String() string { return proto.CompactTextString(m) }
+func (*insert) ProtoMessage()    {}
+
+// update is Mysqlx.Crud.Update.
+type update struct {
+	Collection *collection        `protobuf:"bytes,2,req,name=collection"`
+	DataModel  *int32             `protobuf:"varint,3,opt,name=data_model"`
+	Criteria   *expr              `protobuf:"bytes,4,opt,name=criteria"`
+	Limit      *limit             `protobuf:"bytes,5,opt,name=limit"`
+	Order      []*order           `protobuf:"bytes,6,rep,name=order"`
+	Operation  []*updateOperation `protobuf:"bytes,7,rep,name=operation"`
+	Args       []*scalar          `protobuf:"bytes,8,rep,name=args"`
+	LimitExpr  *limitExpr         `protobuf:"bytes,9,opt,name=limit_expr"`
+}
+
+func (m *update) Reset()         { *m = update{} }
+func (m *update) String() string { return proto.CompactTextString(m) }
+func (*update) ProtoMessage()    {}
+
+// deleteMessage is Mysqlx.Crud.Delete.
+type deleteMessage struct {
+	Collection *collection `protobuf:"bytes,1,req,name=collection"`
+	DataModel  *int32      `protobuf:"varint,2,opt,name=data_model"`
+	Criteria   *expr       `protobuf:"bytes,3,opt,name=criteria"`
+	Limit      *limit      `protobuf:"bytes,4,opt,name=limit"`
+	Order      []*order    `protobuf:"bytes,5,rep,name=order"`
+	Args       []*scalar   `protobuf:"bytes,6,rep,name=args"`
+	LimitExpr  *limitExpr  `protobuf:"bytes,7,opt,name=limit_expr"`
+}
+
+func (m *deleteMessage) Reset()         { *m = deleteMessage{} }
+func (m *deleteMessage) String() string { return proto.CompactTextString(m) }
+func (*deleteMessage) ProtoMessage()    {}
+
+// docSchema is the schema of the results of the queries of collections.
+var docSchema = sql.Schema{{Name: "doc", Type: sql.JSON, Nullable: true}}
+
+// find sends the rows of a table or the documents of a collection. The
+// projections of the documents are computed from the documents found.
+func (c *conn) find(payload []byte) error {
+	var m find
+	if err := unmarshal(payload, &m); err != nil {
+		return err
+	}
+
+	if m.Locking != nil {
+		return errUnsupported("locking the rows found is not supported")
+	}
+
+	b := &exprBuilder{document: dataModel(m.DataModel) == dataModelDocument, args: m.Args}
+	table, err := tableName(m.Collection)
+	if err != nil {
+		return err
+	}
+
+	clauses, err := b.clauses(m.Criteria, m.Order, m.Limit, m.LimitExpr, true)
+	if err != nil {
+		return err
+	}
+
+	if !b.document {
+		fields := "*"
+		if len(m.Projection) > 0 {
+			list := make([]string, len(m.Projection))
+			for i, p := range m.Projection {
+				if list[i], err = b.sql(p.Source); err != nil {
+					return err
+				}
+				if p.Alias != nil {
+					list[i] += " AS " + quoteIdentifier(*p.Alias)
+				}
+			}
+			fields = strings.Join(list, ", ")
+		}
+
+		var grouping string
+		if len(m.Grouping) > 0 {
+			list, err := b.list(m.Grouping)
+			if err != nil {
+				return err
+			}
+			grouping = " GROUP BY " + strings.Join(list, ", ")
+		}
+		if m.GroupingCriteria != nil {
+			having, err := b.sql(m.GroupingCriteria)
+			if err != nil {
+				return err
+			}
+			grouping += " HAVING " + having
+		}
+
+		// The grouping goes between the criteria and the order.
+		where, rest := clauses, ""
+		if i := strings.Index(clauses, " ORDER BY "); i >= 0 {
+			where, rest = clauses[:i], clauses[i:]
+		} else if i := strings.Index(clauses, " LIMIT "); i >= 0 {
+			where, rest = clauses[:i], clauses[i:]
+		}
+		return c.executeSQL(fmt.Sprintf("SELECT %s FROM %s%s%s%s", fields, table, where, grouping, rest), nil)
+	}
+
+	if len(m.Grouping) > 0 || m.GroupingCriteria != nil {
+		return errUnsupported("grouping documents is not supported")
+	}
+
+	aliases := make([]string, len(m.Projection))
+	for i, p := range m.Projection {
+		if aliases[i], err = projectionAlias(p); err != nil {
+			return err
+		}
+	}
+
+	_, err = c.query(fmt.Sprintf("SELECT doc FROM %s%s", table, clauses), nil, c.sendMetaData, func(r sql.Row) error {
+		doc := r[0]
+		if len(m.Projection) > 0 {
+			if doc, err = b.project(doc, m.Projection, aliases); err != nil {
+				return err
+			}
+		}
+
+		msg, err := encodeRow(docSchema, sql.NewRow(doc))
+		if err != nil {
+			return err
+		}
+		return c.send(serverResultsetRow, msg)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := c.send(serverResultsetFetchDone, &message{}); err != nil {
+		return err
+	}
+	return c.sendExecuteOk(nil)
+}
+
+// projectionAlias returns the name of the member of a projection of
+// documents, which is the last member of the path projected by default.
+func projectionAlias(p *projection) (string, error) {
+	if p.Alias != nil {
+		return *p.Alias, nil
+	}
+
+	if p.Source.GetType() == exprIdent && p.Source.Identifier != nil {
+		path := p.Source.Identifier.DocumentPath
+		if n := len(path); n > 0 && path[n-1].GetType() == pathMember {
+			return path[n-1].GetValue(), nil
+		}
+		if n := len(path); n == 0 && p.Source.Identifier.GetName() != "" {
+			return p.Source.Identifier.GetName(), nil
+		}
+	}
+
+	return "", newError(erXBadProjection, "Invalid projection target name")
+}
+
+// project returns the projection of a document.
+func (b *exprBuilder) project(v interface{}, projections []*projection, aliases []string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	doc, err := jsonValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{}, len(projections))
+	for i, p := range projections {
+		if result[aliases[i]], err = b.json(p.Source, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(result)
+}
+
+// insert inserts rows in a table or documents in a collection. Documents
+// are given IDs if they have none.
+func (c *conn) insert(payload []byte) error {
+	var m insert
+	if err := unmarshal(payload, &m); err != nil {
+		return err
+	}
+
+	b := &exprBuilder{document: dataModel(m.DataModel) == dataModelDocument, args: m.Args}
+	table, err := tableName(m.Collection)
+	if err != nil {
+		return err
+	}
+
+	if len(m.Row) == 0 {
+		return newError(erXBadInsertData, "Missing row data for Insert")
+	}
+
+	if !b.document {
+		if m.Upsert != nil && *m.Upsert {
+			return newError(erXBadInsertData, "Unable update on duplicate key for TABLE data model")
+		}
+
+		var columns string
+		if len(m.Projection) > 0 {
+			names := make([]string, len(m.Projection))
+			for i, col := range m.Projection {
+				if len(col.DocumentPath) > 0 || col.Name == nil {
+					return newError(erXBadInsertData, "Invalid column of Insert")
+				}
+				names[i] = quoteIdentifier(*col.Name)
+			}
+			columns = " (" + strings.Join(names, ", ") + ")"
+		}
+
+		rows := make([]string, len(m.Row))
+		for i, r := range m.Row {
+			values, err := b.list(r.Field)
+			if err != nil {
+				return err
+			}
+			rows[i] = "(" + strings.Join(values, ", ") + ")"
+		}
+
+		return c.executeSQL(fmt.Sprintf("INSERT INTO %s%s VALUES %s", table, columns, strings.Join(rows, ", ")), nil)
+	}
+
+	if len(m.Projection) > 0 {
+		return newError(erXBadInsertData, "Invalid projection for document operation")
+	}
+
+	var generated []*scalar
+	rows := make([]string, len(m.Row))
+	for i, r := range m.Row {
+		if len(r.Field) != 1 {
+			return newError(erXBadInsertData, "Wrong number of fields in row: %d", len(r.Field))
+		}
+
+		v, err := b.json(r.Field[0], nil)
+		if err != nil {
+			return err
+		}
+
+		doc, ok := v.(map[string]interface{})
+		if !ok {
+			return newError(erXBadInsertData, "Invalid data for insert: documents must be objects")
+		}
+
+		id, ok, err := documentID(doc)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			id = c.s.ids.next()
+			doc["_id"] = id
+			generated = append(generated, &scalar{Type: proto.Int32(scalarOctets), VOctets: &octets{Value: []byte(id)}})
+		}
+
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		rows[i] = fmt.Sprintf("(%s, %s)", quoteString(string(data)), quoteString(id))
+	}
+
+	verb := "INSERT"
+	if m.Upsert != nil && *m.Upsert {
+		verb = "REPLACE"
+	}
+
+	ok, err := c.query(fmt.Sprintf("%s INTO %s (doc, _id) VALUES %s", verb, table, strings.Join(rows, ", ")), nil, ignoreSchema, ignoreRow)
+	if err != nil {
+		return err
+	}
+
+	if len(generated) > 0 {
+		if err := c.sendStateChange(stateGeneratedDocumentIDs, generated...); err != nil {
+			return err
+		}
+	}
+	return c.sendExecuteOk(ok)
+}
+
+// update updates the rows of a table or the documents of a collection. The
+// documents are updated by the server, which reads them and writes them
+// back once their operations are applied.
+func (c *conn) update(payload []byte) error {
+	var m update
+	if err := unmarshal(payload, &m); err != nil {
+		return err
+	}
+
+	b := &exprBuilder{document: dataModel(m.DataModel) == dataModelDocument, args: m.Args}
+	table, err := tableName(m.Collection)
+	if err != nil {
+		return err
+	}
+
+	if len(m.Operation) == 0 {
+		return newError(erXBadUpdateData, "Invalid update expression list")
+	}
+
+	clauses, err := b.clauses(m.Criteria, m.Order, m.Limit, m.LimitExpr, false)
+	if err != nil {
+		return err
+	}
+
+	if !b.document {
+		sets := make([]string, len(m.Operation))
+		for i, op := range m.Operation {
+			if op.GetOperation() != updateSet || op.Source.GetName() == "" || len(op.Source.DocumentPath) > 0 {
+				return newError(erXBadUpdateData, "Invalid type of update operation for table")
+			}
+
+			value, err := b.sql(op.Value)
+			if err != nil {
+				return err
+			}
+			sets[i] = quoteIdentifier(op.Source.GetName()) + " = " + value
+		}
+
+		return c.executeSQL(fmt.Sprintf("UPDATE %s SET %s%s", table, strings.Join(sets, ", "), clauses), nil)
+	}
+
+	for _, op := range m.Operation {
+		if err := checkDocumentUpdate(op); err != nil {
+			return err
+		}
+	}
+
+	type document struct {
+		id  interface{}
+		doc interface{}
+	}
+
+	var docs []document
+	_, err = c.query(fmt.Sprintf("SELECT _id, doc FROM %s%s", table, clauses), nil, ignoreSchema, func(r sql.Row) error {
+		docs = append(docs, document{r[0], r[1]})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var affected uint64
+	for _, d := range docs {
+		v, err := jsonValue(d.doc)
+		if err != nil {
+			return err
+		}
+
+		doc, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		before, _ := json.Marshal(doc)
+
+		for _, op := range m.Operation {
+			if err := b.applyUpdate(doc, op); err != nil {
+				return err
+			}
+		}
+
+		after, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(before, after) {
+			continue
+		}
+
+		res, err := c.query(fmt.Sprintf("UPDATE %s SET doc = %s WHERE _id = %s", table, quoteString(string(after)), quoteString(fmt.Sprint(d.id))), nil, ignoreSchema, ignoreRow)
+		if err != nil {
+			return err
+		}
+		if res != nil {
+			affected += res.RowsAffected
+		}
+	}
+
+	return c.sendExecuteOk(&sql.OkResult{RowsAffected: affected})
+}
+
+// checkDocumentUpdate checks an update operation of documents, which can't
+// change their IDs.
+func checkDocumentUpdate(op *updateOperation) error {
+	path := op.Source.DocumentPath
+	switch op.GetOperation() {
+	case updateItemSet, updateItemReplace, updateItemRemove, updateArrayInsert, updateArrayAppend:
+		if len(path) == 0 {
+			return newError(erXBadUpdateData, "Invalid document path for update operation")
+		}
+		if path[0].GetType() == pathMember && path[0].GetValue() == "_id" {
+			return newError(erXBadMemberToUpdate, "Forbidden update operation on '$._id' member")
+		}
+		if op.GetOperation() == updateArrayInsert && path[len(path)-1].GetType() != pathArrayIndex {
+			return newError(erXBadUpdateData, "Invalid document path for ARRAY_INSERT operation")
+		}
+		return checkPath(path)
+	case updateMergePatch:
+		if len(path) > 0 {
+			return newError(erXBadUpdateData, "Invalid document path for MERGE_PATCH operation")
+		}
+		return nil
+	case updateItemMerge:
+		return errUnsupported("the ITEM_MERGE update operation is not supported, use MERGE_PATCH instead")
+	default:
+		return newError(erXBadUpdateData, "Invalid type of update operation for document")
+	}
+}
+
+// applyUpdate applies an update operation to a document, as the JSON
+// functions of MySQL do.
+func (b *exprBuilder) applyUpdate(doc map[string]interface{}, op *updateOperation) error {
+	path := op.Source.DocumentPath
+	if op.GetOperation() == updateItemRemove {
+		return removePath(doc, path)
+	}
+
+	value, err := b.json(op.Value, doc)
+	if err != nil {
+		return err
+	}
+
+	switch op.GetOperation() {
+	case updateItemSet:
+		return setPath(doc, path, value, setAlways)
+	case updateItemReplace:
+		return setPath(doc, path, value, setReplace)
+	case updateArrayInsert:
+		return setPath(doc, path, value, setInsert)
+	case updateArrayAppend:
+		current, ok, err := getPath(doc, path)
+		if err != nil || !ok {
+			return err
+		}
+
+		arr, ok := current.([]interface{})
+		if !ok {
+			arr = []interface{}{current}
+		}
+		return setPath(doc, path, append(arr, value), setReplace)
+	case updateMergePatch:
+		id := doc["_id"]
+		mergePatch(doc, value)
+		if doc["_id"] != id {
+			return newError(erXBadMemberToUpdate, "Forbidden update operation on '$._id' member")
+		}
+	}
+	return nil
+}
+
+// delete deletes rows of a table or documents of a collection.
+func (c *conn) delete(payload []byte) error {
+	var m deleteMessage
+	if err := unmarshal(payload, &m); err != nil {
+		return err
+	}
+
+	b := &exprBuilder{document: dataModel(m.DataModel) == dataModelDocument, args: m.Args}
+	table, err := tableName(m.Collection)
+	if err != nil {
+		return err
+	}
+
+	clauses, err := b.clauses(m.Criteria, m.Order, m.Limit, m.LimitExpr, false)
+	if err != nil {
+		return err
+	}
+	return c.executeSQL(fmt.Sprintf("DELETE FROM %s%s", table, clauses), nil)
+}
+
+// clauses returns the WHERE, ORDER BY and LIMIT clauses of the query of a
+// CRUD message. Only the ones of Find can have offsets.
+func (b *exprBuilder) clauses(criteria *expr, orders []*order, l *limit, le *limitExpr, offset bool) (string, error) {
+	var sb strings.Builder
+	if criteria != nil {
+		where, err := b.sql(criteria)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(" WHERE " + where)
+	}
+
+	for i, o := range orders {
+		e, err := b.sql(o.Expr)
+		if err != nil {
+			return "", err
+		}
+
+		if i == 0 {
+			sb.WriteString(" ORDER BY ")
+		} else {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(e)
+		if o.Direction != nil && *o.Direction == orderDesc {
+			sb.WriteString(" DESC")
+		}
+	}
+
+	var count, skip uint64
+	switch {
+	case l != nil:
+		count, skip = l.GetRowCount(), l.GetOffset()
+	case le != nil:
+		var err error
+		if count, err = b.uint(le.RowCount); err != nil {
+			return "", err
+		}
+		if le.Offset != nil {
+			if skip, err = b.uint(le.Offset); err != nil {
+				return "", err
+			}
+		}
+	default:
+		return sb.String(), nil
+	}
+
+	if skip > 0 && !offset {
+		return "", newError(erXBadUpdateData, "Invalid parameter: non-zero offset value not allowed for this operation")
+	}
+
+	sb.WriteString(" LIMIT " + strconv.FormatUint(count, 10))
+	if skip > 0 {
+		sb.WriteString(" OFFSET " + strconv.FormatUint(skip, 10))
+	}
+	return sb.String(), nil
+}
+
+// uint evaluates an expression giving an unsigned integer, as the ones of
+// the limits.
+func (b *exprBuilder) uint(e *expr) (uint64, error) {
+	v, err := b.json(e, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if n, ok := v.(json.Number); ok {
+		if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+			return u, nil
+		}
+	}
+	return 0, errBadValue("invalid limit %v", v)
+}
+
+// tableName returns the name of the table of a collection, qualified with
+// its schema, if any.
+func tableName(col *collection) (string, error) {
+	if col == nil || col.Name == nil || *col.Name == "" {
+		return "", newError(erXInvalidCollection, "Invalid name of table/collection")
+	}
+
+	name := quoteIdentifier(*col.Name)
+	if col.Schema != nil && *col.Schema != "" {
+		name = quoteIdentifier(*col.Schema) + "." + name
+	}
+	return name, nil
+}
+
+// dataModel returns the data model of a CRUD message, which are documents by
+// default.
+func dataModel(m *int32) int32 {
+	if m == nil {
+		return dataModelDocument
+	}
+	return *m
+}
+
+// jsonValue returns a value of a JSON column as a document.
+func jsonValue(v interface{}) (interface{}, error) {
+	value, err := sql.JSON.SQL(v)
+	if err != nil {
+		return nil, err
+	}
+	return parseJSON(value.ToBytes())
+}
+
+func ignoreSchema(sql.Schema) error { return nil }
+
+func ignoreRow(sql.Row) error { return nil }
+
+func (m *limit) GetRowCount() uint64 {
+	if m != nil && m.RowCount != nil {
+		return *m.RowCount
+	}
+	return 0
+}
+
+func (m *limit) GetOffset() uint64 {
+	if m != nil && m.Offset != nil {
+		return *m.Offset
+	}
+	return 0
+}
+
+func (m *updateOperation) GetOperation() int32 {
+	if m != nil && m.Operation != nil {
+		return *m.Operation
+	}
+	return 0
+}
@@ -0,0 +1,376 @@
+package mysqlx
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// Types of the scalars.
+const (
+	scalarSint   = 1
+	scalarUint   = 2
+	scalarNull   = 3
+	scalarOctets = 4
+	scalarDouble = 5
+	scalarFloat  = 6
+	scalarBool   = 7
+	scalarString = 8
+)
+
+// Types of the values of Mysqlx.Datatypes.Any.
+const (
+	anyScalar = 1
+	anyObject = 2
+	anyArray  = 3
+)
+
+// scalar is Mysqlx.Datatypes.Scalar.
+type scalar struct {
+	Type         *int32       `protobuf:"varint,1,req,name=type"`
+	VSignedInt   *int64       `protobuf:"zigzag64,2,opt,name=v_signed_int"`
+	VUnsignedInt *uint64      `protobuf:"varint,3,opt,name=v_unsigned_int"`
+	VOctets      *octets      `protobuf:"bytes,5,opt,name=v_octets"`
+	VDouble      *float64     `protobuf:"fixed64,6,opt,name=v_double"`
+	VFloat       *float32     `protobuf:"fixed32,7,opt,name=v_float"`
+	VBool        *bool        `protobuf:"varint,8,opt,name=v_bool"`
+	VString      *stringValue `protobuf:"bytes,9,opt,name=v_string"`
+}
+
+func (m *scalar) Reset()         { *m = scalar{} }
+func (m *scalar) String() string { return proto.CompactTextString(m) }
+func (*scalar) ProtoMessage()    {}
+
+// octets is Mysqlx.Datatypes.Scalar.Octets.
+type octets struct {
+	Value       []byte  `protobuf:"bytes,1,req,name=value"`
+	ContentType *uint32 `protobuf:"varint,2,opt,name=content_type"`
+}
+
+func (m *octets) Reset()         { *m = octets{} }
+func (m *octets) String() string { return proto.CompactTextString(m) }
+func (*octets) ProtoMessage()    {}
+
+// stringValue is Mysqlx.Datatypes.Scalar.String.
+type stringValue struct {
+	Value     []byte  `protobuf:"bytes,1,req,name=value"`
+	Collation *uint64 `protobuf:"varint,2,opt,name=collation"`
+}
+
+func (m *stringValue) Reset()         { *m = stringValue{} }
+func (m *stringValue) String() string { return proto.CompactTextString(m) }
+func (*stringValue) ProtoMessage()    {}
+
+// objectField is Mysqlx.Datatypes.Object.ObjectField.
+type objectField struct {
+	Key   *string   `protobuf:"bytes,1,req,name=key"`
+	Value *anyValue `protobuf:"bytes,2,req,name=value"`
+}
+
+func (m *objectField) Reset()         { *m = objectField{} }
+func (m *objectField) String() string { return proto.CompactTextString(m) }
+func (*objectField) ProtoMessage()    {}
+
+// object is Mysqlx.Datatypes.Object.
+type object struct {
+	Fld []*objectField `protobuf:"bytes,1,rep,name=fld"`
+}
+
+func (m *object) Reset()         { *m = object{} }
+func (m *object) String() string { return proto.CompactTextString(m) }
+func (*object) ProtoMessage()    {}
+
+// array is Mysqlx.Datatypes.Array.
+type array struct {
+	Value []*anyValue `protobuf:"bytes,1,rep,name=value"`
+}
+
+func (m *array) Reset()         { *m = array{} }
+func (m *array) String() string { return proto.CompactTextString(m) }
+func (*array) ProtoMessage()    {}
+
+// anyValue is Mysqlx.Datatypes.Any.
+type anyValue struct {
+	Type   *int32  `protobuf:"varint,1,req,name=type"`
+	Scalar *scalar `protobuf:"bytes,2,opt,name=scalar"`
+	Obj    *object `protobuf:"bytes,3,opt,name=obj"`
+	Array  *array  `protobuf:"bytes,4,opt,name=array"`
+}
+
+func (m *anyValue) Reset()         { *m = anyValue{} }
+func (m *anyValue) String() string { return proto.CompactTextString(m) }
+func (*anyValue) ProtoMessage()    {}
+
+// newUintScalar returns an unsigned integer scalar.
+func newUintScalar(v uint64) *scalar {
+	return &scalar{Type: proto.Int32(scalarUint), VUnsignedInt: proto.Uint64(v)}
+}
+
+// newStringScalar returns a string scalar.
+func newStringScalar(v string) *scalar {
+	return &scalar{Type: proto.Int32(scalarString), VString: &stringValue{Value: []byte(v)}}
+}
+
+// newScalarAny returns an Any holding the scalar given.
+func newScalarAny(s *scalar) *anyValue {
+	return &anyValue{Type: proto.Int32(anyScalar), Scalar: s}
+}
+
+// scalarValue returns the value of a scalar and the SQL type of the value,
+// to bind it to a placeholder of a statement.
+func scalarValue(s *scalar) (interface{}, sql.Type, error) {
+	if s == nil {
+		return nil, nil, errBadValue("missing scalar")
+	}
+
+	switch s.GetType() {
+	case scalarSint:
+		return s.GetVSignedInt(), sql.Int64, nil
+	case scalarUint:
+		return s.GetVUnsignedInt(), sql.Uint64, nil
+	case scalarNull:
+		return nil, sql.Null, nil
+	case scalarOctets:
+		return string(s.VOctets.GetValue()), sql.LongText, nil
+	case scalarDouble:
+		return s.GetVDouble(), sql.Float64, nil
+	case scalarFloat:
+		return s.GetVFloat(), sql.Float32, nil
+	case scalarBool:
+		return s.GetVBool(), sql.Boolean, nil
+	case scalarString:
+		return string(s.VString.GetValue()), sql.LongText, nil
+	default:
+		return nil, nil, errBadValue("unknown scalar type %d", s.GetType())
+	}
+}
+
+// scalarLiteral returns the scalar given as a literal expression.
+func scalarLiteral(s *scalar) (sql.Expression, error) {
+	v, typ, err := scalarValue(s)
+	if err != nil {
+		return nil, err
+	}
+	return expression.NewLiteral(v, typ), nil
+}
+
+// scalarSQL returns the scalar given as a SQL literal.
+func scalarSQL(s *scalar) (string, error) {
+	v, _, err := scalarValue(s)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	default:
+		return quoteString(v.(string)), nil
+	}
+}
+
+// scalarJSON returns the scalar given as a value of a JSON document. Octets
+// with the JSON content type are parsed as JSON.
+func scalarJSON(s *scalar) (interface{}, error) {
+	if s.GetType() == scalarOctets && s.VOctets.GetContentType() == contentJSON {
+		return parseJSON(s.VOctets.GetValue())
+	}
+
+	v, _, err := scalarValue(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10)), nil
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10)), nil
+	case float32:
+		return float64(v), nil
+	default:
+		return v, nil
+	}
+}
+
+// anyJSON returns the Any given as a value of a JSON document.
+func anyJSON(a *anyValue) (interface{}, error) {
+	switch a.GetType() {
+	case anyScalar:
+		return scalarJSON(a.Scalar)
+	case anyObject:
+		obj := make(map[string]interface{}, len(a.Obj.GetFld()))
+		for _, f := range a.Obj.GetFld() {
+			v, err := anyJSON(f.Value)
+			if err != nil {
+				return nil, err
+			}
+			obj[f.GetKey()] = v
+		}
+		return obj, nil
+	case anyArray:
+		arr := make([]interface{}, len(a.Array.GetValue()))
+		for i, elem := range a.Array.GetValue() {
+			v, err := anyJSON(elem)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = v
+		}
+		return arr, nil
+	default:
+		return nil, errBadValue("unknown value type %d", a.GetType())
+	}
+}
+
+// parseJSON parses a JSON document, keeping its numbers as they are.
+func parseJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errBadValue("invalid JSON document: %s", err)
+	}
+	return doc, nil
+}
+
+// quoteString returns a string as a SQL string literal.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\'':
+			b.WriteString(`\'`)
+		case '\\':
+			b.WriteString(`\\`)
+		case 0:
+			b.WriteString(`\0`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// quoteIdentifier returns a name as a quoted SQL identifier.
+func quoteIdentifier(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+// Getters of the optional fields, as the ones generated.
+
+func (m *scalar) GetType() int32 {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return 0
+}
+
+func (m *scalar) GetVSignedInt() int64 {
+	if m != nil && m.VSignedInt != nil {
+		return *m.VSignedInt
+	}
+	return 0
+}
+
+func (m *scalar) GetVUnsignedInt() uint64 {
+	if m != nil && m.VUnsignedInt != nil {
+		return *m.VUnsignedInt
+	}
+	return 0
+}
+
+func (m *scalar) GetVDouble() float64 {
+	if m != nil && m.VDouble != nil {
+		return *m.VDouble
+	}
+	return 0
+}
+
+func (m *scalar) GetVFloat() float32 {
+	if m != nil && m.VFloat != nil {
+		return *m.VFloat
+	}
+	return 0
+}
+
+func (m *scalar) GetVBool() bool {
+	if m != nil && m.VBool != nil {
+		return *m.VBool
+	}
+	return false
+}
+
+func (m *octets) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *octets) GetContentType() uint32 {
+	if m != nil && m.ContentType != nil {
+		return *m.ContentType
+	}
+	return 0
+}
+
+func (m *stringValue) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *objectField) GetKey() string {
+	if m != nil && m.Key != nil {
+		return *m.Key
+	}
+	return ""
+}
+
+func (m *object) GetFld() []*objectField {
+	if m != nil {
+		return m.Fld
+	}
+	return nil
+}
+
+func (m *array) GetValue() []*anyValue {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *anyValue) GetType() int32 {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return 0
+}
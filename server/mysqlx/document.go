@@ -0,0 +1,236 @@
+package mysqlx
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// memberName matches the names of the members of documents that can be used
+// in the paths of the queries, which are the ones the JSON functions of the
+// engine support.
+var memberName = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// pathString returns a document path as a JSON path, such as $.a.b[1].
+func pathString(path []*documentPathItem) (string, error) {
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, item := range path {
+		switch item.GetType() {
+		case pathMember:
+			if !memberName.MatchString(item.GetValue()) {
+				return "", errUnsupported("unsupported name of document member %q", item.GetValue())
+			}
+			b.WriteString("." + item.GetValue())
+		case pathMemberAsterisk:
+			b.WriteString(".*")
+		case pathArrayIndex:
+			b.WriteString("[" + strconv.FormatUint(uint64(item.GetIndex()), 10) + "]")
+		case pathArrayIndexAsterisk:
+			b.WriteString("[*]")
+		case pathDoubleAsterisk:
+			return "", errUnsupported("the ** wildcard is not supported in document paths")
+		default:
+			return "", errBadValue("invalid document path item type %d", item.GetType())
+		}
+	}
+	return b.String(), nil
+}
+
+// isIDPath returns whether the path given is the one of the IDs of the
+// documents, which are also in the _id column of collections.
+func isIDPath(path []*documentPathItem) bool {
+	return len(path) == 1 && path[0].GetType() == pathMember && path[0].GetValue() == "_id"
+}
+
+// checkPath checks that a path has no wildcards, as the ones of the members
+// updated and projected.
+func checkPath(path []*documentPathItem) error {
+	for _, item := range path {
+		switch item.GetType() {
+		case pathMember, pathArrayIndex:
+		case pathMemberAsterisk, pathArrayIndexAsterisk, pathDoubleAsterisk:
+			return newError(erXBadUpdateData, "Invalid document path: wildcards are not allowed")
+		default:
+			return errBadValue("invalid document path item type %d", item.GetType())
+		}
+	}
+	return nil
+}
+
+// getPath returns the value in a path of a document, and whether there is
+// one.
+func getPath(doc interface{}, path []*documentPathItem) (interface{}, bool, error) {
+	if err := checkPath(path); err != nil {
+		return nil, false, err
+	}
+
+	v := doc
+	for _, item := range path {
+		switch item.GetType() {
+		case pathMember:
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, false, nil
+			}
+			if v, ok = obj[item.GetValue()]; !ok {
+				return nil, false, nil
+			}
+		case pathArrayIndex:
+			arr, ok := v.([]interface{})
+			if !ok || int(item.GetIndex()) >= len(arr) {
+				return nil, false, nil
+			}
+			v = arr[item.GetIndex()]
+		}
+	}
+	return v, true, nil
+}
+
+// Modes of setPath.
+const (
+	// setAlways sets the value in the path, replacing the one there if any.
+	setAlways = iota
+	// setReplace only replaces the value there is in the path.
+	setReplace
+	// setInsert inserts the value in the position of an array of the path.
+	setInsert
+)
+
+// setPath sets a value in a path of a document, which must not be empty. As
+// the JSON functions of MySQL, members are only added to existing objects,
+// and elements are only added at the end of existing arrays.
+func setPath(doc interface{}, path []*documentPathItem, value interface{}, mode int) error {
+	if err := checkPath(path); err != nil {
+		return err
+	}
+
+	parent, ok, _ := getPath(doc, path[:len(path)-1])
+	if !ok {
+		return nil
+	}
+
+	last := path[len(path)-1]
+	switch last.GetType() {
+	case pathMember:
+		obj, ok := parent.(map[string]interface{})
+		if !ok || mode == setInsert {
+			return nil
+		}
+		if _, exists := obj[last.GetValue()]; exists || mode == setAlways {
+			obj[last.GetValue()] = value
+		}
+	case pathArrayIndex:
+		arr, ok := parent.([]interface{})
+		if !ok {
+			return nil
+		}
+
+		i := int(last.GetIndex())
+		switch {
+		case mode == setInsert:
+			if i > len(arr) {
+				i = len(arr)
+			}
+			arr = append(arr[:i], append([]interface{}{value}, arr[i:]...)...)
+		case i < len(arr):
+			arr[i] = value
+		case mode == setAlways:
+			arr = append(arr, value)
+		}
+
+		// Documents are objects, so arrays always have a parent.
+		return setPath(doc, path[:len(path)-1], arr, setReplace)
+	}
+	return nil
+}
+
+// removePath removes the value in a path of a document, which must not be
+// empty.
+func removePath(doc interface{}, path []*documentPathItem) error {
+	if err := checkPath(path); err != nil {
+		return err
+	}
+
+	parent, ok, _ := getPath(doc, path[:len(path)-1])
+	if !ok {
+		return nil
+	}
+
+	last := path[len(path)-1]
+	switch last.GetType() {
+	case pathMember:
+		if obj, ok := parent.(map[string]interface{}); ok {
+			delete(obj, last.GetValue())
+		}
+	case pathArrayIndex:
+		arr, ok := parent.([]interface{})
+		if !ok || int(last.GetIndex()) >= len(arr) {
+			return nil
+		}
+
+		i := int(last.GetIndex())
+		return setPath(doc, path[:len(path)-1], append(arr[:i], arr[i+1:]...), setReplace)
+	}
+	return nil
+}
+
+// mergePatch applies a JSON merge patch to a value, as described in RFC 7396.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+// documentID returns the ID of a document as it's kept in the _id column,
+// and whether it has one.
+func documentID(doc map[string]interface{}) (string, bool, error) {
+	id, ok := doc["_id"]
+	if !ok {
+		return "", false, nil
+	}
+
+	switch id := id.(type) {
+	case string:
+		return id, true, nil
+	case json.Number:
+		return id.String(), true, nil
+	default:
+		return "", false, newError(erXBadInsertData, "Document ID must be a string or a number")
+	}
+}
+
+// documentIDs generates the IDs of the documents inserted without one, as
+// MySQL does: a prefix, the time the server started and a serial number, in
+// hexadecimal.
+type documentIDs struct {
+	start  int64
+	serial uint64
+}
+
+func newDocumentIDs() *documentIDs {
+	return &documentIDs{start: time.Now().Unix()}
+}
+
+func (g *documentIDs) next() string {
+	return fmt.Sprintf("%04x%08x%016x", 0, uint32(g.start), atomic.AddUint64(&g.serial, 1))
+}
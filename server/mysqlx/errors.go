@@ -0,0 +1,68 @@
+package mysqlx
+
+import (
+	"fmt"
+
+	"github.com/dolthub/vitess/go/mysql"
+)
+
+// Error codes of the X Plugin of MySQL returned to clients.
+const (
+	erXBadMessage            = 5000
+	erXCapabilitiesPrepare   = 5001
+	erXCapabilityNotFound    = 5002
+	erXBadInsertData         = 5014
+	erXCmdArgumentValue      = 5017
+	erXBadUpdateData         = 5050
+	erXBadProjection         = 5114
+	erXExprBadOperator       = 5150
+	erXExprBadNumArgs        = 5151
+	erXExprBadValue          = 5154
+	erXInvalidCollection     = 5156
+	erXInvalidAdminCommand   = 5157
+	erXExpectNotOpen         = 5158
+	erXExpectFailed          = 5159
+	erXExpectBadCondition    = 5160
+	erXInvalidNamespace      = 5162
+	erSecureTransportRequire = 3159
+
+	// ssNotSupported is the SQL state of ER_NOT_SUPPORTED_YET.
+	ssNotSupported = "42000"
+)
+
+// Error is an error returned to a client in a Mysqlx.Error message.
+type Error struct {
+	Code     uint32
+	SQLState string
+	Message  string
+	// Fatal errors close the connection once they are sent.
+	Fatal bool
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code %d, state %s)", e.Message, e.Code, e.SQLState)
+}
+
+func newError(code uint32, format string, args ...interface{}) *Error {
+	return &Error{Code: code, SQLState: mysql.SSUnknownSQLState, Message: fmt.Sprintf(format, args...)}
+}
+
+func errBadValue(format string, args ...interface{}) *Error {
+	return newError(erXExprBadValue, format, args...)
+}
+
+func errUnsupported(format string, args ...interface{}) *Error {
+	return &Error{Code: mysql.ERNotSupportedYet, SQLState: ssNotSupported, Message: fmt.Sprintf(format, args...)}
+}
+
+// toError converts the error given to the one returned to the client, with
+// the code and state of the MySQL errors it carries, if any.
+func toError(err error) *Error {
+	if xerr, ok := err.(*Error); ok {
+		return xerr
+	}
+
+	serr := mysql.NewSQLErrorFromError(err).(*mysql.SQLError)
+	return &Error{Code: uint32(serr.Num), SQLState: serr.State, Message: serr.Message}
+}
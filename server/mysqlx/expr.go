@@ -0,0 +1,507 @@
+package mysqlx
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Types of the expressions.
+const (
+	exprIdent       = 1
+	exprLiteral     = 2
+	exprVariable    = 3
+	exprFuncCall    = 4
+	exprOperator    = 5
+	exprPlaceholder = 6
+	exprObject      = 7
+	exprArray       = 8
+)
+
+// Types of the items of document paths.
+const (
+	pathMember             = 1
+	pathMemberAsterisk     = 2
+	pathArrayIndex         = 3
+	pathArrayIndexAsterisk = 4
+	pathDoubleAsterisk     = 5
+)
+
+// expr is Mysqlx.Expr.Expr.
+type expr struct {
+	Type         *int32            `protobuf:"varint,1,req,name=type"`
+	Identifier   *columnIdentifier `protobuf:"bytes,2,opt,name=identifier"`
+	Variable     *string           `protobuf:"bytes,3,opt,name=variable"`
+	Literal      *scalar           `protobuf:"bytes,4,opt,name=literal"`
+	FunctionCall *functionCall     `protobuf:"bytes,5,opt,name=function_call"`
+	Operator     *operator         `protobuf:"bytes,6,opt,name=operator"`
+	Position     *uint32           `protobuf:"varint,7,opt,name=position"`
+	Object       *exprObjectValue  `protobuf:"bytes,8,opt,name=object"`
+	Array        *exprArrayValue   `protobuf:"bytes,9,opt,name=array"`
+}
+
+func (m *expr) Reset()         { *m = expr{} }
+func (m *expr) String() string { return proto.CompactTextString(m) }
+func (*expr) ProtoMessage()    {}
+
+// identifier is Mysqlx.Expr.Identifier.
+type identifier struct {
+	Name       *string `protobuf:"bytes,1,req,name=name"`
+	SchemaName *string `protobuf:"bytes,2,opt,name=schema_name"`
+}
+
+func (m *identifier) Reset()         { *m = identifier{} }
+func (m *identifier) String() string { return proto.CompactTextString(m) }
+func (*identifier) ProtoMessage()    {}
+
+// documentPathItem is Mysqlx.Expr.DocumentPathItem.
+type documentPathItem struct {
+	Type  *int32  `protobuf:"varint,1,req,name=type"`
+	Value *string `protobuf:"bytes,2,opt,name=value"`
+	Index *uint32 `protobuf:"varint,3,opt,name=index"`
+}
+
+func (m *documentPathItem) Reset()         { *m = documentPathItem{} }
+func (m *documentPathItem) String() string { return proto.CompactTextString(m) }
+func (*documentPathItem) ProtoMessage()    {}
+
+// columnIdentifier is Mysqlx.Expr.ColumnIdentifier.
+type columnIdentifier struct {
+	DocumentPath []*documentPathItem `protobuf:"bytes,1,rep,name=document_path"`
+	Name         *string             `protobuf:"bytes,2,opt,name=name"`
+	TableName    *string             `protobuf:"bytes,3,opt,name=table_name"`
+	SchemaName   *string             `protobuf:"bytes,4,opt,name=schema_name"`
+}
+
+func (m *columnIdentifier) Reset()         { *m = columnIdentifier{} }
+func (m *columnIdentifier) String() string { return proto.CompactTextString(m) }
+func (*columnIdentifier) ProtoMessage()    {}
+
+// functionCall is Mysqlx.Expr.FunctionCall.
+type functionCall struct {
+	Name  *identifier `protobuf:"bytes,1,req,name=name"`
+	Param []*expr     `protobuf:"bytes,2,rep,name=param"`
+}
+
+func (m *functionCall) Reset()         { *m = functionCall{} }
+func (m *functionCall) String() string { return proto.CompactTextString(m) }
+func (*functionCall) ProtoMessage()    {}
+
+// operator is Mysqlx.Expr.Operator.
+type operator struct {
+	Name  *string `protobuf:"bytes,1,req,name=name"`
+	Param []*expr `protobuf:"bytes,2,rep,name=param"`
+}
+
+func (m *operator) Reset()         { *m = operator{} }
+func (m *operator) String() string { return proto.CompactTextString(m) }
+func (*operator) ProtoMessage()    {}
+
+// exprObjectField is Mysqlx.Expr.Object.ObjectField.
+type exprObjectField struct {
+	Key   *string `protobuf:"bytes,1,req,name=key"`
+	Value *expr   `protobuf:"bytes,2,req,name=value"`
+}
+
+func (m *exprObjectField) Reset()         { *m = exprObjectField{} }
+func (m *exprObjectField) String() string { return proto.CompactTextString(m) }
+func (*exprObjectField) ProtoMessage()    {}
+
+// exprObjectValue is Mysqlx.Expr.Object.
+type exprObjectValue struct {
+	Fld []*exprObjectField `protobuf:"bytes,1,rep,name=fld"`
+}
+
+func (m *exprObjectValue) Reset()         { *m = exprObjectValue{} }
+func (m *exprObjectValue) String() string { return proto.CompactTextString(m) }
+func (*exprObjectValue) ProtoMessage()    {}
+
+// exprArrayValue is Mysqlx.Expr.Array.
+type exprArrayValue struct {
+	Value []*expr `protobuf:"bytes,1,rep,name=value"`
+}
+
+func (m *exprArrayValue) Reset()         { *m = exprArrayValue{} }
+func (m *exprArrayValue) String() string { return proto.CompactTextString(m) }
+func (*exprArrayValue) ProtoMessage()    {}
+
+// binaryOperators are the SQL operators of the binary operators of the
+// expressions.
+var binaryOperators = map[string]string{
+	"==": "=", "!=": "!=", "<": "<", "<=": "<=", ">": ">", ">=": ">=",
+	"&&": "AND", "||": "OR", "xor": "XOR",
+	"+": "+", "-": "-", "*": "*", "/": "/", "div": "DIV", "%": "%",
+	"&": "&", "|": "|", "^": "^", "<<": "<<", ">>": ">>",
+	"is": "IS", "is_not": "IS NOT",
+	"like": "LIKE", "not_like": "NOT LIKE",
+	"regexp": "REGEXP", "not_regexp": "NOT REGEXP",
+}
+
+// unaryOperators are the SQL operators of the unary operators of the
+// expressions.
+var unaryOperators = map[string]string{
+	"!": "NOT ", "not": "NOT ", "sign_minus": "-", "sign_plus": "+", "~": "~",
+}
+
+// castTypes are the types the cast operator converts to.
+var castTypes = map[string]bool{
+	"BINARY": true, "CHAR": true, "DATE": true, "DATETIME": true, "DECIMAL": true,
+	"SIGNED": true, "SIGNED INTEGER": true, "TIME": true, "UNSIGNED": true, "UNSIGNED INTEGER": true,
+}
+
+// functionName matches the names of the functions the expressions can call.
+var functionName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// exprBuilder translates the expressions of CRUD messages to SQL, and
+// evaluates the ones of the values of documents.
+type exprBuilder struct {
+	// document is whether the expressions are the ones of a collection,
+	// whose identifiers are paths of its documents.
+	document bool
+	// args are the values of the placeholders.
+	args []*scalar
+}
+
+// sql returns the expression given in SQL.
+func (b *exprBuilder) sql(e *expr) (string, error) {
+	if e == nil {
+		return "", errBadValue("missing expression")
+	}
+
+	switch e.GetType() {
+	case exprIdent:
+		return b.identSQL(e.Identifier)
+	case exprLiteral:
+		return scalarSQL(e.Literal)
+	case exprPlaceholder:
+		arg, err := b.arg(e.GetPosition())
+		if err != nil {
+			return "", err
+		}
+		return scalarSQL(arg)
+	case exprFuncCall:
+		return b.funcSQL(e.FunctionCall)
+	case exprOperator:
+		return b.operatorSQL(e.Operator)
+	case exprObject, exprArray:
+		v, err := b.json(e, nil)
+		if err != nil {
+			return "", err
+		}
+
+		doc, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return quoteString(string(doc)), nil
+	case exprVariable:
+		return "", errUnsupported("variables are not supported in expressions")
+	default:
+		return "", errBadValue("invalid expression type %d", e.GetType())
+	}
+}
+
+// arg returns the value of the placeholder in the position given.
+func (b *exprBuilder) arg(pos uint32) (*scalar, error) {
+	if int(pos) >= len(b.args) {
+		return nil, newError(erXExprBadNumArgs, "Invalid value of placeholder %d", pos)
+	}
+	return b.args[pos], nil
+}
+
+// identSQL returns a column or a path of the documents of a collection in
+// SQL. The values of the paths are unquoted, to be compared as the values of
+// the columns.
+func (b *exprBuilder) identSQL(id *columnIdentifier) (string, error) {
+	if id == nil {
+		return "", errBadValue("missing identifier")
+	}
+
+	path := id.DocumentPath
+	if b.document && len(path) == 0 && id.GetName() != "" {
+		path = []*documentPathItem{{Type: proto.Int32(pathMember), Value: id.Name}}
+	}
+
+	var column string
+	switch {
+	case b.document:
+		column = "doc"
+		if isIDPath(path) {
+			return "_id", nil
+		}
+	case id.GetName() == "":
+		return "", errBadValue("missing column name")
+	default:
+		column = quoteIdentifier(id.GetName())
+		if id.GetTableName() != "" {
+			column = quoteIdentifier(id.GetTableName()) + "." + column
+			if id.GetSchemaName() != "" {
+				column = quoteIdentifier(id.GetSchemaName()) + "." + column
+			}
+		}
+	}
+
+	if len(path) == 0 {
+		return column, nil
+	}
+
+	p, err := pathString(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, %s))", column, quoteString(p)), nil
+}
+
+func (b *exprBuilder) funcSQL(f *functionCall) (string, error) {
+	if f == nil || f.Name == nil {
+		return "", errBadValue("missing function")
+	}
+
+	name := f.Name.GetName()
+	if !functionName.MatchString(name) {
+		return "", errBadValue("invalid function name %s", name)
+	}
+	if f.Name.GetSchemaName() != "" {
+		return "", errUnsupported("stored functions are not supported")
+	}
+
+	params, err := b.list(f.Param)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(params, ", ")), nil
+}
+
+func (b *exprBuilder) operatorSQL(op *operator) (string, error) {
+	if op == nil {
+		return "", errBadValue("missing operator")
+	}
+
+	name := op.GetName()
+	params, err := b.list(op.Param)
+	if err != nil {
+		return "", err
+	}
+
+	if sqlOp, ok := binaryOperators[name]; ok {
+		if len(params) != 2 {
+			return "", errNumArgs(name, 2, len(params))
+		}
+		return fmt.Sprintf("(%s %s %s)", params[0], sqlOp, params[1]), nil
+	}
+
+	if sqlOp, ok := unaryOperators[name]; ok {
+		if len(params) != 1 {
+			return "", errNumArgs(name, 1, len(params))
+		}
+		return fmt.Sprintf("(%s%s)", sqlOp, params[0]), nil
+	}
+
+	switch name {
+	case "in", "not_in":
+		if len(params) < 2 {
+			return "", errNumArgs(name, 2, len(params))
+		}
+
+		sqlOp := "IN"
+		if name == "not_in" {
+			sqlOp = "NOT IN"
+		}
+		return fmt.Sprintf("(%s %s (%s))", params[0], sqlOp, strings.Join(params[1:], ", ")), nil
+	case "between", "not_between":
+		if len(params) != 3 {
+			return "", errNumArgs(name, 3, len(params))
+		}
+
+		sqlOp := "BETWEEN"
+		if name == "not_between" {
+			sqlOp = "NOT BETWEEN"
+		}
+		return fmt.Sprintf("(%s %s %s AND %s)", params[0], sqlOp, params[1], params[2]), nil
+	case "cast":
+		if len(params) != 2 {
+			return "", errNumArgs(name, 2, len(params))
+		}
+
+		typ := strings.ToUpper(strings.Trim(params[1], "'"))
+		if !castTypes[typ] {
+			return "", errBadValue("invalid type of cast %s", params[1])
+		}
+		return fmt.Sprintf("CAST(%s AS %s)", params[0], typ), nil
+	default:
+		return "", newError(erXExprBadOperator, "Invalid operator %s", name)
+	}
+}
+
+func (b *exprBuilder) list(exprs []*expr) ([]string, error) {
+	result := make([]string, len(exprs))
+	for i, e := range exprs {
+		var err error
+		if result[i], err = b.sql(e); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// json evaluates an expression giving a value of a document, such as the
+// documents inserted, the values of the updates and the ones of the
+// projections. Only literals, placeholders, objects, arrays and the paths of
+// the document given, if any, are evaluated.
+func (b *exprBuilder) json(e *expr, doc interface{}) (interface{}, error) {
+	if e == nil {
+		return nil, errBadValue("missing expression")
+	}
+
+	switch e.GetType() {
+	case exprLiteral:
+		return scalarJSON(e.Literal)
+	case exprPlaceholder:
+		arg, err := b.arg(e.GetPosition())
+		if err != nil {
+			return nil, err
+		}
+		return scalarJSON(arg)
+	case exprObject:
+		obj := make(map[string]interface{}, len(e.Object.GetFld()))
+		for _, f := range e.Object.GetFld() {
+			v, err := b.json(f.Value, doc)
+			if err != nil {
+				return nil, err
+			}
+			obj[f.GetKey()] = v
+		}
+		return obj, nil
+	case exprArray:
+		arr := make([]interface{}, len(e.Array.GetValue()))
+		for i, elem := range e.Array.GetValue() {
+			v, err := b.json(elem, doc)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = v
+		}
+		return arr, nil
+	case exprIdent:
+		if doc == nil || e.Identifier == nil || (e.Identifier.GetName() != "" && len(e.Identifier.DocumentPath) > 0) {
+			break
+		}
+
+		path := e.Identifier.DocumentPath
+		if len(path) == 0 {
+			path = []*documentPathItem{{Type: proto.Int32(pathMember), Value: e.Identifier.Name}}
+		}
+
+		v, _, err := getPath(doc, path)
+		return v, err
+	}
+
+	return nil, errUnsupported("only literals, placeholders, objects, arrays and document paths are supported as values of documents")
+}
+
+func errNumArgs(name string, expected, got int) *Error {
+	return newError(erXExprBadNumArgs, "Invalid number of arguments for operator %s, expected %d but got %d", name, expected, got)
+}
+
+// Getters of the optional fields, as the ones generated.
+
+func (m *expr) GetType() int32 {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return 0
+}
+
+func (m *expr) GetPosition() uint32 {
+	if m != nil && m.Position != nil {
+		return *m.Position
+	}
+	return 0
+}
+
+func (m *identifier) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *identifier) GetSchemaName() string {
+	if m != nil && m.SchemaName != nil {
+		return *m.SchemaName
+	}
+	return ""
+}
+
+func (m *columnIdentifier) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *columnIdentifier) GetTableName() string {
+	if m != nil && m.TableName != nil {
+		return *m.TableName
+	}
+	return ""
+}
+
+func (m *columnIdentifier) GetSchemaName() string {
+	if m != nil && m.SchemaName != nil {
+		return *m.SchemaName
+	}
+	return ""
+}
+
+func (m *documentPathItem) GetType() int32 {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return 0
+}
+
+func (m *documentPathItem) GetValue() string {
+	if m != nil && m.Value != nil {
+		return *m.Value
+	}
+	return ""
+}
+
+func (m *documentPathItem) GetIndex() uint32 {
+	if m != nil && m.Index != nil {
+		return *m.Index
+	}
+	return 0
+}
+
+func (m *operator) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *exprObjectValue) GetFld() []*exprObjectField {
+	if m != nil {
+		return m.Fld
+	}
+	return nil
+}
+
+func (m *exprObjectField) GetKey() string {
+	if m != nil && m.Key != nil {
+		return *m.Key
+	}
+	return ""
+}
+
+func (m *exprArrayValue) GetValue() []*expr {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package mysqlx
+
+import "github.com/golang/protobuf/proto"
+
+// The messages of the X Protocol are the ones of the mysqlx*.proto files of
+// MySQL, written by hand instead of generated from them, with only the
+// fields the server uses.
+
+// Types of the messages sent by clients, as in Mysqlx.ClientMessages.
+const (
+	clientConCapabilitiesGet    = 1
+	clientConCapabilitiesSet    = 2
+	clientConClose              = 3
+	clientSessAuthenticateStart = 4
+	clientSessAuthenticateCont  = 5
+	clientSessReset             = 6
+	clientSessClose             = 7
+	clientSQLStmtExecute        = 12
+	clientCrudFind              = 17
+	clientCrudInsert            = 18
+	clientCrudUpdate            = 19
+	clientCrudDelete            = 20
+	clientExpectOpen            = 24
+	clientExpectClose           = 25
+)
+
+// Types of the messages sent by the server, as in Mysqlx.ServerMessages.
+const (
+	serverOk                       = 0
+	serverError                    = 1
+	serverConnCapabilities         = 2
+	serverSessAuthenticateContinue = 3
+	serverSessAuthenticateOk       = 4
+	serverNotice                   = 11
+	serverResultsetColumnMetaData  = 12
+	serverResultsetRow             = 13
+	serverResultsetFetchDone       = 14
+	serverSQLStmtExecuteOk         = 17
+)
+
+// message is a message with no fields, such as Mysqlx.Connection.Close.
+type message struct{}
+
+func (m *message) Reset()         { *m = message{} }
+func (m *message) String() string { return proto.CompactTextString(m) }
+func (*message) ProtoMessage()    {}
+
+// okMessage is Mysqlx.Ok.
+type okMessage struct {
+	Msg *string `protobuf:"bytes,1,opt,name=msg"`
+}
+
+func (m *okMessage) Reset()         { *m = okMessage{} }
+func (m *okMessage) String() string { return proto.CompactTextString(m) }
+func (*okMessage) ProtoMessage()    {}
+
+// Severities of the errors.
+const (
+	severityError = 0
+	severityFatal = 1
+)
+
+// errorMessage is Mysqlx.Error.
+type errorMessage struct {
+	Severity *int32  `protobuf:"varint,1,opt,name=severity"`
+	Code     *uint32 `protobuf:"varint,2,req,name=code"`
+	Msg      *string `protobuf:"bytes,3,req,name=msg"`
+	SQLState *string `protobuf:"bytes,4,req,name=sql_state"`
+}
+
+func (m *errorMessage) Reset()         { *m = errorMessage{} }
+func (m *errorMessage) String() string { return proto.CompactTextString(m) }
+func (*errorMessage) ProtoMessage()    {}
+
+// capability is Mysqlx.Connection.Capability.
+type capability struct {
+	Name  *string   `protobuf:"bytes,1,req,name=name"`
+	Value *anyValue `protobuf:"bytes,2,req,name=value"`
+}
+
+func (m *capability) Reset()         { *m = capability{} }
+func (m *capability) String() string { return proto.CompactTextString(m) }
+func (*capability) ProtoMessage()    {}
+
+// capabilities is Mysqlx.Connection.Capabilities.
+type capabilities struct {
+	Capabilities []*capability `protobuf:"bytes,1,rep,name=capabilities"`
+}
+
+func (m *capabilities) Reset()         { *m = capabilities{} }
+func (m *capabilities) String() string { return proto.CompactTextString(m) }
+func (*capabilities) ProtoMessage()    {}
+
+// capabilitiesSet is Mysqlx.Connection.CapabilitiesSet.
+type capabilitiesSet struct {
+	Capabilities *capabilities `protobuf:"bytes,1,req,name=capabilities"`
+}
+
+func (m *capabilitiesSet) Reset()         { *m = capabilitiesSet{} }
+func (m *capabilitiesSet) String() string { return proto.CompactTextString(m) }
+func (*capabilitiesSet) ProtoMessage()    {}
+
+// authenticateStart is Mysqlx.Session.AuthenticateStart.
+type authenticateStart struct {
+	MechName        *string `protobuf:"bytes,1,req,name=mech_name"`
+	AuthData        []byte  `protobuf:"bytes,2,opt,name=auth_data"`
+	InitialResponse []byte  `protobuf:"bytes,3,opt,name=initial_response"`
+}
+
+func (m *authenticateStart) Reset()         { *m = authenticateStart{} }
+func (m *authenticateStart) String() string { return proto.CompactTextString(m) }
+func (*authenticateStart) ProtoMessage()    {}
+
+// authenticateContinue is Mysqlx.Session.AuthenticateContinue.
+type authenticateContinue struct {
+	AuthData []byte `protobuf:"bytes,1,req,name=auth_data"`
+}
+
+func (m *authenticateContinue) Reset()         { *m = authenticateContinue{} }
+func (m *authenticateContinue) String() string { return proto.CompactTextString(m) }
+func (*authenticateContinue) ProtoMessage()    {}
+
+// authenticateOk is Mysqlx.Session.AuthenticateOk.
+type authenticateOk struct {
+	AuthData []byte `protobuf:"bytes,1,opt,name=auth_data"`
+}
+
+func (m *authenticateOk) Reset()         { *m = authenticateOk{} }
+func (m *authenticateOk) String() string { return proto.CompactTextString(m) }
+func (*authenticateOk) ProtoMessage()    {}
+
+// sessionReset is Mysqlx.Session.Reset.
+type sessionReset struct {
+	KeepOpen *bool `protobuf:"varint,1,opt,name=keep_open"`
+}
+
+func (m *sessionReset) Reset()         { *m = sessionReset{} }
+func (m *sessionReset) String() string { return proto.CompactTextString(m) }
+func (*sessionReset) ProtoMessage()    {}
+
+// stmtExecute is Mysqlx.Sql.StmtExecute.
+type stmtExecute struct {
+	Stmt            []byte      `protobuf:"bytes,1,req,name=stmt"`
+	Args            []*anyValue `protobuf:"bytes,2,rep,name=args"`
+	Namespace       *string     `protobuf:"bytes,3,opt,name=namespace"`
+	CompactMetadata *bool       `protobuf:"varint,4,opt,name=compact_metadata"`
+}
+
+func (m *stmtExecute) Reset()         { *m = stmtExecute{} }
+func (m *stmtExecute) String() string { return proto.CompactTextString(m) }
+func (*stmtExecute) ProtoMessage()    {}
+
+// Types of the columns of the result sets.
+const (
+	fieldSint     = 1
+	fieldUint     = 2
+	fieldDouble   = 5
+	fieldFloat    = 6
+	fieldBytes    = 7
+	fieldTime     = 10
+	fieldDatetime = 12
+	fieldEnum     = 16
+	fieldBit      = 17
+	fieldDecimal  = 18
+)
+
+// Content types of the BYTES columns.
+const (
+	contentGeometry = 1
+	contentJSON     = 2
+)
+
+// columnMetaData is Mysqlx.Resultset.ColumnMetaData.
+type columnMetaData struct {
+	Type             *int32  `protobuf:"varint,1,req,name=type"`
+	Name             []byte  `protobuf:"bytes,2,opt,name=name"`
+	OriginalName     []byte  `protobuf:"bytes,3,opt,name=original_name"`
+	Table            []byte  `protobuf:"bytes,4,opt,name=table"`
+	OriginalTable    []byte  `protobuf:"bytes,5,opt,name=original_table"`
+	Schema           []byte  `protobuf:"bytes,6,opt,name=schema"`
+	Catalog          []byte  `protobuf:"bytes,7,opt,name=catalog"`
+	Collation        *uint64 `protobuf:"varint,8,opt,name=collation"`
+	FractionalDigits *uint32 `protobuf:"varint,9,opt,name=fractional_digits"`
+	Length           *uint32 `protobuf:"varint,10,opt,name=length"`
+	Flags            *uint32 `protobuf:"varint,11,opt,name=flags"`
+	ContentType      *uint32 `protobuf:"varint,12,opt,name=content_type"`
+}
+
+func (m *columnMetaData) Reset()         { *m = columnMetaData{} }
+func (m *columnMetaData) String() string { return proto.CompactTextString(m) }
+func (*columnMetaData) ProtoMessage()    {}
+
+// row is Mysqlx.Resultset.Row.
+type row struct {
+	Field [][]byte `protobuf:"bytes,1,rep,name=field"`
+}
+
+func (m *row) Reset()         { *m = row{} }
+func (m *row) String() string { return proto.CompactTextString(m) }
+func (*row) ProtoMessage()    {}
+
+// Types and scopes of the notices.
+const (
+	noticeWarning             = 1
+	noticeSessionStateChanged = 3
+
+	scopeGlobal = 1
+	scopeLocal  = 2
+)
+
+// Parameters of the SessionStateChanged notices.
+const (
+	stateCurrentSchema        = 1
+	stateGeneratedInsertID    = 3
+	stateRowsAffected         = 4
+	stateProducedMessage      = 10
+	stateClientIDAssigned     = 11
+	stateGeneratedDocumentIDs = 12
+)
+
+// Levels of the warnings.
+const (
+	warningNote    = 1
+	warningWarning = 2
+	warningError   = 3
+)
+
+// frame is Mysqlx.Notice.Frame.
+type frame struct {
+	Type    *uint32 `protobuf:"varint,1,req,name=type"`
+	Scope   *int32  `protobuf:"varint,2,opt,name=scope"`
+	Payload []byte  `protobuf:"bytes,3,opt,name=payload"`
+}
+
+func (m *frame) Reset()         { *m = frame{} }
+func (m *frame) String() string { return proto.CompactTextString(m) }
+func (*frame) ProtoMessage()    {}
+
+// warning is Mysqlx.Notice.Warning.
+type warning struct {
+	Level *int32  `protobuf:"varint,1,opt,name=level"`
+	Code  *uint32 `protobuf:"varint,2,req,name=code"`
+	Msg   *string `protobuf:"bytes,3,req,name=msg"`
+}
+
+func (m *warning) Reset()         { *m = warning{} }
+func (m *warning) String() string { return proto.CompactTextString(m) }
+func (*warning) ProtoMessage()    {}
+
+// sessionStateChanged is Mysqlx.Notice.SessionStateChanged.
+type sessionStateChanged struct {
+	Param *int32    `protobuf:"varint,1,req,name=param"`
+	Value []*scalar `protobuf:"bytes,2,rep,name=value"`
+}
+
+func (m *sessionStateChanged) Reset()         { *m = sessionStateChanged{} }
+func (m *sessionStateChanged) String() string { return proto.CompactTextString(m) }
+func (*sessionStateChanged) ProtoMessage()    {}
+
+// Keys and operations of the conditions of the expectation blocks.
+const (
+	expectNoError    = 1
+	expectFieldExist = 2
+
+	expectOpSet   = 0
+	expectOpUnset = 1
+	expectOpClear = 2
+
+	expectCtxCopyPrev = 0
+	expectCtxEmpty    = 1
+)
+
+// expectCondition is Mysqlx.Expect.Open.Condition.
+type expectCondition struct {
+	ConditionKey   *uint32 `protobuf:"varint,1,req,name=condition_key"`
+	ConditionValue []byte  `protobuf:"bytes,2,opt,name=condition_value"`
+	Op             *int32  `protobuf:"varint,3,opt,name=op"`
+}
+
+func (m *expectCondition) Reset()         { *m = expectCondition{} }
+func (m *expectCondition) String() string { return proto.CompactTextString(m) }
+func (*expectCondition) ProtoMessage()    {}
+
+// expectOpen is Mysqlx.Expect.Open.
+type expectOpen struct {
+	Op   *int32             `protobuf:"varint,1,opt,name=op"`
+	Cond []*expectCondition `protobuf:"bytes,2,rep,name=cond"`
+}
+
+func (m *expectOpen) Reset()         { *m = expectOpen{} }
+func (m *expectOpen) String() string { return proto.CompactTextString(m) }
+func (*expectOpen) ProtoMessage()    {}
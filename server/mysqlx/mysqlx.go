@@ -0,0 +1,185 @@
+// Package mysqlx implements an endpoint of the X Protocol of MySQL for SQLe
+// engines, so that clients using the X DevAPI, such as MySQL Shell and the
+// MySQL connectors, can connect to them to run SQL statements and to use
+// collections of JSON documents.
+//
+// Collections are tables with a doc JSON column holding the documents and
+// an _id column holding their IDs, which is the primary key, as in MySQL.
+// Documents are filtered and sorted in the queries the CRUD messages are
+// translated to, while their projections and updates are computed by the
+// server, since the engine lacks the JSON functions MySQL uses for them.
+package mysqlx
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/opentracing/opentracing-go"
+	"github.com/sirupsen/logrus"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/auth"
+)
+
+// FirstConnectionID is the ID of the first connection of an X Protocol
+// endpoint. The IDs of its connections are counted from it, so that they
+// don't collide with the ones of the connections of the classic protocol.
+const FirstConnectionID = 1 << 31
+
+// Config of an X Protocol endpoint.
+type Config struct {
+	// Protocol for the connection.
+	Protocol string
+	// Address of the endpoint, usually on port 33060.
+	Address string
+	// Auth of the server. Clients authenticate with the MYSQL41 mechanism,
+	// or with the PLAIN one if AllowClearTextWithoutTLS is set, so only the
+	// users of authentication methods using mysql_native_password can log
+	// in.
+	Auth auth.Auth
+	// Tracer to use in the endpoint. By default, a noop tracer will be used
+	// if no tracer is provided.
+	Tracer opentracing.Tracer
+	// ConnReadTimeout is the read timeout of the connections.
+	ConnReadTimeout time.Duration
+	// ConnWriteTimeout is the write timeout of the connections.
+	ConnWriteTimeout time.Duration
+	// MaxConnections is the maximum number of simultaneous connections of
+	// the endpoint, if not zero.
+	MaxConnections uint64
+	// AllowClearTextWithoutTLS allows clients to authenticate with the PLAIN
+	// mechanism, sending their password in clear text, since the endpoint
+	// does not support TLS.
+	AllowClearTextWithoutTLS bool
+	// RequireSecureTransport rejects all the clients, which can't use TLS.
+	RequireSecureTransport bool
+}
+
+// Server is an X Protocol endpoint for a SQLe engine.
+type Server struct {
+	cfg      Config
+	e        *sqle.Engine
+	listener net.Listener
+
+	mu     sync.Mutex
+	conns  map[uint32]*conn
+	nextID uint32
+	closed bool
+
+	ids *documentIDs
+}
+
+// NewServer creates an X Protocol endpoint for the engine given, listening on
+// the address of the configuration.
+func NewServer(cfg Config, e *sqle.Engine) (*Server, error) {
+	if cfg.Tracer == nil {
+		cfg.Tracer = opentracing.NoopTracer{}
+	}
+
+	l, err := net.Listen(cfg.Protocol, cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		cfg:      cfg,
+		e:        e,
+		listener: l,
+		conns:    make(map[uint32]*conn),
+		nextID:   FirstConnectionID,
+		ids:      newDocumentIDs(),
+	}, nil
+}
+
+// Addr returns the address the endpoint listens on.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Start accepts connections on the endpoint until it's closed.
+func (s *Server) Start() error {
+	for {
+		nc, err := s.listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				logrus.Warnf("mysqlx: unable to accept connection: %s", err)
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		c, xerr := s.newConn(nc)
+		if xerr != nil {
+			logrus.Warnf("mysqlx: rejecting connection from %s: %s", nc.RemoteAddr(), xerr.Message)
+			_ = writeMessage(nc, serverError, errorMsg(xerr))
+			nc.Close()
+			continue
+		}
+
+		go c.serve()
+	}
+}
+
+// StopAccepting stops accepting connections on the endpoint, leaving the ones
+// established open.
+func (s *Server) StopAccepting() error {
+	s.mu.Lock()
+	closed := s.closed
+	s.closed = true
+	s.mu.Unlock()
+
+	if closed {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+// Close stops accepting connections on the endpoint and closes the ones
+// established.
+func (s *Server) Close() error {
+	err := s.StopAccepting()
+
+	s.mu.Lock()
+	conns := make([]*conn, 0, len(s.conns))
+	for _, c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range conns {
+		c.nc.Close()
+	}
+	return err
+}
+
+// newConn registers a new connection, unless there are too many.
+func (s *Server) newConn(nc net.Conn) (*conn, *Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cfg.MaxConnections > 0 && uint64(len(s.conns)) >= s.cfg.MaxConnections {
+		return nil, &Error{Code: erConCount, SQLState: mysql.SSUnknownSQLState, Message: "Too many connections", Fatal: true}
+	}
+
+	c := newConn(s, nc, s.nextID)
+	s.conns[c.id] = c
+	s.nextID++
+	return c, nil
+}
+
+// removeConn unregisters a connection once it's closed.
+func (s *Server) removeConn(c *conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, c.id)
+}
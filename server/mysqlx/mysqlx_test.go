@@ -0,0 +1,374 @@
+package mysqlx
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+)
+
+type testClient struct {
+	t       *testing.T
+	nc      net.Conn
+	r       *bufio.Reader
+	notices []*frame
+}
+
+func newTestServer(t *testing.T) *Server {
+	catalog := sql.NewCatalog()
+	db := memory.NewDatabase("mydb")
+	catalog.AddDatabase(db)
+
+	table := memory.NewTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.LongText, Source: "t"},
+	})
+	for i, s := range []string{"a", "b", "c"} {
+		require.NoError(t, table.Insert(sql.NewEmptyContext(), sql.NewRow(int64(i+1), s)))
+	}
+	db.AddTable("t", table)
+
+	a := auth.NewNativeSingle("user", "pass", auth.AllPermissions)
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: a})
+
+	s, err := NewServer(Config{Protocol: "tcp", Address: "localhost:0", Auth: a}, e)
+	require.NoError(t, err)
+	go s.Start()
+	return s
+}
+
+func dial(t *testing.T, s *Server) *testClient {
+	nc, err := net.Dial("tcp", s.Addr().String())
+	require.NoError(t, err)
+	return &testClient{t: t, nc: nc, r: bufio.NewReader(nc)}
+}
+
+func (c *testClient) send(typ byte, msg proto.Message) {
+	require.NoError(c.t, writeMessage(c.nc, typ, msg))
+}
+
+// read reads the next message, keeping the notices apart.
+func (c *testClient) read() (byte, []byte) {
+	for {
+		typ, payload, err := readMessage(c.r)
+		require.NoError(c.t, err)
+		if typ != serverNotice {
+			return typ, payload
+		}
+
+		var f frame
+		require.NoError(c.t, proto.Unmarshal(payload, &f))
+		c.notices = append(c.notices, &f)
+	}
+}
+
+func (c *testClient) expect(typ byte, msg proto.Message) {
+	got, payload := c.read()
+	if got == serverError && typ != serverError {
+		var e errorMessage
+		require.NoError(c.t, proto.Unmarshal(payload, &e))
+		c.t.Fatalf("unexpected error: %s", e.String())
+	}
+	require.Equal(c.t, typ, got)
+	if msg != nil {
+		require.NoError(c.t, proto.Unmarshal(payload, msg))
+	}
+}
+
+func (c *testClient) expectError(code uint32) {
+	var e errorMessage
+	c.expect(serverError, &e)
+	require.Equal(c.t, code, *e.Code)
+}
+
+func (c *testClient) login(user, password string) {
+	c.send(clientSessAuthenticateStart, &authenticateStart{MechName: proto.String(mechanismMySQL41)})
+	var cont authenticateContinue
+	c.expect(serverSessAuthenticateContinue, &cont)
+
+	reply := "*" + strings.ToUpper(hex.EncodeToString(mysql.ScramblePassword(cont.AuthData, []byte(password))))
+	c.send(clientSessAuthenticateCont, &authenticateContinue{AuthData: []byte("mydb\x00" + user + "\x00" + reply)})
+}
+
+// stmt runs a statement, returning the rows of its result, with their fields
+// as strings, and the rows it affected.
+func (c *testClient) stmt(namespace, stmt string, args ...*anyValue) ([][]string, uint64) {
+	c.send(clientSQLStmtExecute, &stmtExecute{Namespace: proto.String(namespace), Stmt: []byte(stmt), Args: args})
+	return c.result()
+}
+
+func (c *testClient) result() ([][]string, uint64) {
+	c.notices = nil
+
+	var columns []*columnMetaData
+	var rows [][]string
+	for {
+		typ, payload := c.read()
+		switch typ {
+		case serverResultsetColumnMetaData:
+			var md columnMetaData
+			require.NoError(c.t, proto.Unmarshal(payload, &md))
+			columns = append(columns, &md)
+		case serverResultsetRow:
+			var r row
+			require.NoError(c.t, proto.Unmarshal(payload, &r))
+			rows = append(rows, decodeRow(c.t, columns, r.Field))
+		case serverResultsetFetchDone:
+		case serverSQLStmtExecuteOk:
+			var affected uint64
+			for _, n := range c.notices {
+				var s sessionStateChanged
+				require.NoError(c.t, proto.Unmarshal(n.Payload, &s))
+				if *s.Param == stateRowsAffected {
+					affected = s.Value[0].GetVUnsignedInt()
+				}
+			}
+			return rows, affected
+		default:
+			var e errorMessage
+			require.NoError(c.t, proto.Unmarshal(payload, &e))
+			c.t.Fatalf("unexpected message %d: %s", typ, e.String())
+		}
+	}
+}
+
+func decodeRow(t *testing.T, columns []*columnMetaData, fields [][]byte) []string {
+	result := make([]string, len(fields))
+	for i, f := range fields {
+		switch *columns[i].Type {
+		case fieldSint:
+			n, _ := binary.Varint(f)
+			result[i] = strconv.FormatInt(n, 10)
+		case fieldBytes:
+			require.NotEmpty(t, f)
+			result[i] = string(f[:len(f)-1])
+		default:
+			t.Fatalf("unexpected column type %d", *columns[i].Type)
+		}
+	}
+	return result
+}
+
+func TestAuthentication(t *testing.T) {
+	require := require.New(t)
+	s := newTestServer(t)
+	defer s.Close()
+
+	c := dial(t, s)
+	defer c.nc.Close()
+
+	c.send(clientConCapabilitiesGet, &message{})
+	var caps capabilities
+	c.expect(serverConnCapabilities, &caps)
+	var names []string
+	for _, cap := range caps.Capabilities {
+		names = append(names, cap.GetName())
+	}
+	require.Contains(names, "authentication.mechanisms")
+
+	// Statements need a session.
+	c.send(clientSQLStmtExecute, &stmtExecute{Stmt: []byte("SELECT 1")})
+	c.expectError(mysql.ERUnknownComError)
+
+	c.login("user", "wrong")
+	c.expectError(mysql.ERAccessDeniedError)
+
+	c.login("user", "pass")
+	c.expect(serverSessAuthenticateOk, nil)
+	require.Len(c.notices, 1)
+
+	var id sessionStateChanged
+	require.NoError(proto.Unmarshal(c.notices[0].Payload, &id))
+	require.Equal(int32(stateClientIDAssigned), *id.Param)
+	require.Equal(uint64(FirstConnectionID), id.Value[0].GetVUnsignedInt())
+}
+
+func TestStmtExecute(t *testing.T) {
+	require := require.New(t)
+	s := newTestServer(t)
+	defer s.Close()
+
+	c := dial(t, s)
+	defer c.nc.Close()
+	c.login("user", "pass")
+	c.expect(serverSessAuthenticateOk, nil)
+
+	rows, _ := c.stmt("sql", "SELECT i, s FROM t WHERE i > ? ORDER BY i", newScalarAny(newUintScalar(1)))
+	require.Equal([][]string{{"2", "b"}, {"3", "c"}}, rows)
+
+	_, affected := c.stmt("sql", "INSERT INTO t VALUES (4, 'd'), (5, 'e')")
+	require.Equal(uint64(2), affected)
+
+	c.send(clientSQLStmtExecute, &stmtExecute{Stmt: []byte("SELECT * FROM nope")})
+	c.expectError(1105)
+
+	// The connection is still usable after an error.
+	rows, _ = c.stmt("sql", "SELECT COUNT(*) FROM t")
+	require.Equal([][]string{{"5"}}, rows)
+
+	c.send(clientSQLStmtExecute, &stmtExecute{Namespace: proto.String("nope"), Stmt: []byte("ping")})
+	c.expectError(erXInvalidNamespace)
+}
+
+func TestCollections(t *testing.T) {
+	require := require.New(t)
+	s := newTestServer(t)
+	defer s.Close()
+
+	c := dial(t, s)
+	defer c.nc.Close()
+	c.login("user", "pass")
+	c.expect(serverSessAuthenticateOk, nil)
+
+	nameArg := &anyValue{Type: proto.Int32(anyObject), Obj: &object{Fld: []*objectField{
+		{Key: proto.String("schema"), Value: newScalarAny(newStringScalar("mydb"))},
+		{Key: proto.String("name"), Value: newScalarAny(newStringScalar("people"))},
+	}}}
+	c.stmt("mysqlx", "create_collection", nameArg)
+	c.stmt("mysqlx", "ensure_collection", nameArg)
+
+	rows, _ := c.stmt("mysqlx", "list_objects", newScalarAny(newStringScalar("mydb")))
+	require.Equal([][]string{{"people", "COLLECTION"}, {"t", "TABLE"}}, rows)
+
+	people := &collection{Name: proto.String("people"), Schema: proto.String("mydb")}
+	doc := func(fields ...interface{}) *typedRow {
+		obj := &exprObjectValue{}
+		for i := 0; i < len(fields); i += 2 {
+			var v *scalar
+			switch f := fields[i+1].(type) {
+			case string:
+				v = newStringScalar(f)
+			case int:
+				v = newUintScalar(uint64(f))
+			}
+			obj.Fld = append(obj.Fld, &exprObjectField{
+				Key:   proto.String(fields[i].(string)),
+				Value: &expr{Type: proto.Int32(exprLiteral), Literal: v},
+			})
+		}
+		return &typedRow{Field: []*expr{{Type: proto.Int32(exprObject), Object: obj}}}
+	}
+
+	c.send(clientCrudInsert, &insert{Collection: people, Row: []*typedRow{
+		doc("_id", "1", "name", "alice", "age", 30),
+		doc("name", "bob", "age", 25),
+	}})
+	_, affected := c.result()
+	require.Equal(uint64(2), affected)
+
+	var ids []string
+	for _, n := range c.notices {
+		var s sessionStateChanged
+		require.NoError(proto.Unmarshal(n.Payload, &s))
+		if *s.Param == stateGeneratedDocumentIDs {
+			for _, v := range s.Value {
+				ids = append(ids, string(v.VOctets.GetValue()))
+			}
+		}
+	}
+	require.Len(ids, 1)
+	require.Len(ids[0], 28)
+
+	member := func(name string) *expr {
+		return &expr{Type: proto.Int32(exprIdent), Identifier: &columnIdentifier{
+			DocumentPath: []*documentPathItem{{Type: proto.Int32(pathMember), Value: proto.String(name)}},
+		}}
+	}
+	nameIs := &expr{Type: proto.Int32(exprOperator), Operator: &operator{
+		Name:  proto.String("=="),
+		Param: []*expr{member("name"), {Type: proto.Int32(exprPlaceholder), Position: proto.Uint32(0)}},
+	}}
+
+	c.send(clientCrudFind, &find{
+		Collection: people,
+		Projection: []*projection{{Source: member("name")}, {Source: member("age"), Alias: proto.String("years")}},
+		Order:      []*order{{Expr: member("name")}},
+	})
+	rows, _ = c.result()
+	require.Equal([][]string{{`{"name":"alice","years":30}`}, {`{"name":"bob","years":25}`}}, rows)
+
+	c.send(clientCrudUpdate, &update{
+		Collection: people,
+		Criteria:   nameIs,
+		Args:       []*scalar{newStringScalar("bob")},
+		Operation: []*updateOperation{{
+			Source:    &columnIdentifier{DocumentPath: member("age").Identifier.DocumentPath},
+			Operation: proto.Int32(updateItemSet),
+			Value:     &expr{Type: proto.Int32(exprLiteral), Literal: newUintScalar(26)},
+		}},
+	})
+	_, affected = c.result()
+	require.Equal(uint64(1), affected)
+
+	c.send(clientCrudUpdate, &update{
+		Collection: people,
+		Operation: []*updateOperation{{
+			Source:    &columnIdentifier{DocumentPath: member("_id").Identifier.DocumentPath},
+			Operation: proto.Int32(updateItemSet),
+			Value:     &expr{Type: proto.Int32(exprLiteral), Literal: newStringScalar("2")},
+		}},
+	})
+	c.expectError(erXBadMemberToUpdate)
+
+	c.send(clientCrudFind, &find{Collection: people, Criteria: nameIs, Args: []*scalar{newStringScalar("bob")}})
+	rows, _ = c.result()
+	require.Equal([][]string{{`{"_id":"` + ids[0] + `","age":26,"name":"bob"}`}}, rows)
+
+	olderThan := &expr{Type: proto.Int32(exprOperator), Operator: &operator{
+		Name:  proto.String(">"),
+		Param: []*expr{member("age"), {Type: proto.Int32(exprLiteral), Literal: newUintScalar(26)}},
+	}}
+	c.send(clientCrudFind, &find{Collection: people, Criteria: olderThan, Projection: []*projection{{Source: member("name")}}})
+	rows, _ = c.result()
+	require.Equal([][]string{{`{"name":"alice"}`}}, rows)
+
+	c.send(clientCrudDelete, &deleteMessage{Collection: people, Criteria: nameIs, Args: []*scalar{newStringScalar("alice")}})
+	_, affected = c.result()
+	require.Equal(uint64(1), affected)
+
+	rows, _ = c.stmt("sql", "SELECT _id FROM people")
+	require.Equal([][]string{{ids[0]}}, rows)
+
+	c.stmt("mysqlx", "drop_collection", nameArg)
+	rows, _ = c.stmt("mysqlx", "list_objects", newScalarAny(newStringScalar("mydb")))
+	require.Equal([][]string{{"t", "TABLE"}}, rows)
+}
+
+func TestExpectations(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	c := dial(t, s)
+	defer c.nc.Close()
+	c.login("user", "pass")
+	c.expect(serverSessAuthenticateOk, nil)
+
+	c.send(clientExpectOpen, &expectOpen{Cond: []*expectCondition{{ConditionKey: proto.Uint32(expectNoError)}}})
+	c.expect(serverOk, nil)
+
+	c.send(clientSQLStmtExecute, &stmtExecute{Stmt: []byte("SELECT * FROM nope")})
+	c.expectError(1105)
+
+	// The messages following a failed one fail until the block is closed.
+	c.send(clientSQLStmtExecute, &stmtExecute{Stmt: []byte("SELECT 1")})
+	c.expectError(erXExpectFailed)
+
+	c.send(clientExpectClose, &message{})
+	c.expectError(erXExpectFailed)
+
+	rows, _ := c.stmt("sql", "SELECT 1")
+	require.Equal(t, [][]string{{"1"}}, rows)
+}
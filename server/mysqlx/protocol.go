@@ -0,0 +1,49 @@
+package mysqlx
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// maxMessageLength is the length of the longest message clients can send,
+// which is the default of mysqlx_max_allowed_packet in MySQL.
+const maxMessageLength = 64 << 20
+
+// readMessage reads a message of the X Protocol, returning its type and its
+// payload. Messages are framed with their length, little endian in four
+// bytes, counting the byte of the type following it.
+func readMessage(r io.Reader) (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	length := binary.LittleEndian.Uint32(header[:4])
+	if length == 0 || length > maxMessageLength {
+		return 0, nil, &Error{Code: erXBadMessage, SQLState: "HY000", Message: "Invalid message length", Fatal: true}
+	}
+
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[4], payload, nil
+}
+
+// writeMessage writes a message of the X Protocol of the type given.
+func writeMessage(w io.Writer, typ byte, msg proto.Message) error {
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, 5, 5+len(payload))
+	binary.LittleEndian.PutUint32(buf, uint32(len(payload)+1))
+	buf[4] = typ
+	buf = append(buf, payload...)
+
+	_, err = w.Write(buf)
+	return err
+}
@@ -0,0 +1,250 @@
+package mysqlx
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// fieldType returns the type of the columns of the type given in the result
+// sets, and their content type, if any.
+func fieldType(t sql.Type) (int32, uint32) {
+	switch typ := t.Type(); {
+	case sqltypes.IsSigned(typ):
+		return fieldSint, 0
+	case sqltypes.IsUnsigned(typ):
+		return fieldUint, 0
+	case typ == sqltypes.Float32:
+		return fieldFloat, 0
+	case typ == sqltypes.Float64:
+		return fieldDouble, 0
+	case typ == sqltypes.Decimal:
+		return fieldDecimal, 0
+	case typ == sqltypes.Date, typ == sqltypes.Datetime, typ == sqltypes.Timestamp:
+		return fieldDatetime, 0
+	case typ == sqltypes.Time:
+		return fieldTime, 0
+	case typ == sqltypes.Enum:
+		return fieldEnum, 0
+	case typ == sqltypes.Bit:
+		return fieldBit, 0
+	case typ == sqltypes.TypeJSON:
+		return fieldBytes, contentJSON
+	case typ == sqltypes.Geometry:
+		return fieldBytes, contentGeometry
+	default:
+		return fieldBytes, 0
+	}
+}
+
+// columnsMetaData returns the metadata of the columns of a result set.
+func columnsMetaData(schema sql.Schema) []*columnMetaData {
+	columns := make([]*columnMetaData, len(schema))
+	for i, c := range schema {
+		typ, content := fieldType(c.Type)
+		md := &columnMetaData{
+			Type:          proto.Int32(typ),
+			Name:          []byte(c.Name),
+			OriginalName:  []byte(c.Name),
+			Table:         []byte(c.Source),
+			OriginalTable: []byte(c.Source),
+			Catalog:       []byte("def"),
+		}
+
+		switch {
+		case typ == fieldBytes || typ == fieldEnum:
+			var collation uint64 = mysql.CharacterSetUtf8
+			if sql.IsBlob(c.Type) {
+				collation = mysql.CharacterSetBinary
+			}
+			md.Collation = proto.Uint64(collation)
+		case typ == fieldDecimal:
+			if dt, ok := c.Type.(sql.DecimalType); ok {
+				md.FractionalDigits = proto.Uint32(uint32(dt.Scale()))
+			}
+		}
+
+		if content != 0 {
+			md.ContentType = proto.Uint32(content)
+		}
+		columns[i] = md
+	}
+	return columns
+}
+
+// encodeRow encodes a row of a result set.
+func encodeRow(schema sql.Schema, r sql.Row) (*row, error) {
+	fields := make([][]byte, len(r))
+	for i, v := range r {
+		var err error
+		if fields[i], err = encodeValue(schema[i].Type, v); err != nil {
+			return nil, err
+		}
+	}
+	return &row{Field: fields}, nil
+}
+
+// encodeValue encodes a value of a column of a result set. NULL values are
+// empty, and the others are encoded from their text representation.
+func encodeValue(t sql.Type, v interface{}) ([]byte, error) {
+	if v == nil {
+		return []byte{}, nil
+	}
+
+	value, err := t.SQL(v)
+	if err != nil {
+		return nil, err
+	}
+	if value.IsNull() {
+		return []byte{}, nil
+	}
+
+	typ, _ := fieldType(t)
+	s := value.ToString()
+	switch typ {
+	case fieldSint:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return appendVarint(nil, n), nil
+	case fieldUint, fieldBit:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return appendUvarint(nil, n), nil
+	case fieldDouble:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 8)
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(f))
+		return buf, nil
+	case fieldFloat:
+		f, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 4)
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(float32(f)))
+		return buf, nil
+	case fieldDecimal:
+		return encodeDecimal(s), nil
+	case fieldDatetime:
+		return encodeDatetime(s)
+	case fieldTime:
+		return encodeTime(s)
+	default:
+		return append(value.ToBytes(), 0), nil
+	}
+}
+
+// encodeDecimal encodes a decimal as its scale followed by its digits in
+// BCD, ending with the sign nibble.
+func encodeDecimal(s string) []byte {
+	sign := byte(0xc)
+	if strings.HasPrefix(s, "-") {
+		sign, s = 0xd, s[1:]
+	}
+
+	var scale int
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		scale = len(s) - i - 1
+		s = s[:i] + s[i+1:]
+	}
+
+	digits := make([]byte, 0, len(s)+1)
+	for _, c := range []byte(s) {
+		digits = append(digits, c-'0')
+	}
+	digits = append(digits, sign)
+	if len(digits)%2 != 0 {
+		digits = append(digits, 0)
+	}
+
+	buf := []byte{byte(scale)}
+	for i := 0; i < len(digits); i += 2 {
+		buf = append(buf, digits[i]<<4|digits[i+1])
+	}
+	return buf
+}
+
+// encodeDatetime encodes a date or a datetime, as in 2006-01-02 15:04:05.999999,
+// as the varints of its parts. The time is only encoded if there is one.
+func encodeDatetime(s string) ([]byte, error) {
+	date, clock := s, ""
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		date, clock = s[:i], s[i+1:]
+	}
+
+	buf, err := appendParts(nil, strings.Split(date, "-"))
+	if err != nil || clock == "" {
+		return buf, err
+	}
+	return appendClock(buf, clock)
+}
+
+// encodeTime encodes a time, as in -838:59:59.000000, as its sign followed by
+// the varints of its parts.
+func encodeTime(s string) ([]byte, error) {
+	buf := []byte{0}
+	if strings.HasPrefix(s, "-") {
+		buf[0], s = 1, s[1:]
+	}
+	return appendClock(buf, s)
+}
+
+// appendClock appends the hours, minutes, seconds and microseconds of a time
+// of the day, omitting the microseconds if zero.
+func appendClock(buf []byte, clock string) ([]byte, error) {
+	var micros string
+	if i := strings.IndexByte(clock, '.'); i >= 0 {
+		clock, micros = clock[:i], clock[i+1:]
+	}
+
+	buf, err := appendParts(buf, strings.Split(clock, ":"))
+	if err != nil || micros == "" {
+		return buf, err
+	}
+
+	for len(micros) < 6 {
+		micros += "0"
+	}
+	us, err := strconv.ParseUint(micros[:6], 10, 64)
+	if err != nil || us == 0 {
+		return buf, err
+	}
+	return appendUvarint(buf, us), nil
+}
+
+func appendParts(buf []byte, parts []string) ([]byte, error) {
+	for _, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendUvarint(buf, n)
+	}
+	return buf, nil
+}
+
+// appendVarint appends a signed integer as a zigzag encoded varint.
+func appendVarint(buf []byte, n int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutVarint(b[:], n)]...)
+}
+
+// appendUvarint appends an unsigned integer as a varint.
+func appendUvarint(buf []byte, n uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutUvarint(b[:], n)]...)
+}
@@ -0,0 +1,269 @@
+package mysqlx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/golang/protobuf/proto"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// stmtExecute runs a statement of the namespace given: a SQL statement, whose
+// arguments are bound to its placeholders, or a command of the mysqlx
+// namespace.
+func (c *conn) stmtExecute(payload []byte) error {
+	var m stmtExecute
+	if err := unmarshal(payload, &m); err != nil {
+		return err
+	}
+
+	switch ns := m.GetNamespace(); ns {
+	case "sql":
+		bindings, err := argBindings(m.Args)
+		if err != nil {
+			return err
+		}
+		return c.executeSQL(string(m.Stmt), bindings)
+	case "mysqlx", "xplugin":
+		return c.adminCommand(string(m.Stmt), m.Args)
+	default:
+		return newError(erXInvalidNamespace, "Unknown namespace %s", ns)
+	}
+}
+
+// argBindings returns the arguments of a SQL statement as the bindings of
+// its placeholders.
+func argBindings(args []*anyValue) (map[string]sql.Expression, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	bindings := make(map[string]sql.Expression, len(args))
+	for i, arg := range args {
+		if arg.GetType() != anyScalar {
+			return nil, errBadValue("invalid argument %d: only scalars can be bound to placeholders", i)
+		}
+
+		lit, err := scalarLiteral(arg.Scalar)
+		if err != nil {
+			return nil, err
+		}
+		bindings[fmt.Sprintf("v%d", i+1)] = lit
+	}
+	return bindings, nil
+}
+
+// executeSQL runs a SQL statement, sending its result to the client.
+func (c *conn) executeSQL(query string, bindings map[string]sql.Expression) error {
+	var schema sql.Schema
+	ok, err := c.query(query, bindings, func(s sql.Schema) error {
+		schema = s
+		return c.sendMetaData(s)
+	}, func(r sql.Row) error {
+		msg, err := encodeRow(schema, r)
+		if err != nil {
+			return err
+		}
+		return c.send(serverResultsetRow, msg)
+	})
+	if err != nil {
+		return err
+	}
+
+	if schema != nil {
+		if err := c.send(serverResultsetFetchDone, &message{}); err != nil {
+			return err
+		}
+	}
+	return c.sendExecuteOk(ok)
+}
+
+// query runs a query, calling onSchema with the schema of its result before
+// its first row, if it returns rows, and onRow with each of them. The result
+// of the statements not returning rows is returned instead.
+func (c *conn) query(
+	query string,
+	bindings map[string]sql.Expression,
+	onSchema func(sql.Schema) error,
+	onRow func(sql.Row) error,
+) (ok *sql.OkResult, err error) {
+	logrus.Tracef("mysqlx: received query %s", query)
+
+	ctx := c.newContext(query)
+	if !c.s.e.Async(ctx, query) {
+		newCtx, cancel := context.WithCancel(ctx)
+		ctx = ctx.WithContext(newCtx)
+		defer cancel()
+	}
+
+	start := time.Now()
+	parsedQuery, parseErr := sqlparser.Parse(query)
+	schema, rows, err := c.s.e.QueryWithBindings(ctx, query, bindings)
+	defer func() {
+		if q, ok := c.s.e.Auth.(*auth.Audit); ok {
+			q.Query(ctx, time.Since(start), err)
+		}
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.readRows(schema, rows, onSchema, onRow, &ok); err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	if err = rows.Close(); err != nil {
+		return nil, err
+	}
+
+	_, statementIsCommit := parsedQuery.(*sqlparser.Commit)
+	autoCommit := isSessionAutocommit(ctx) && !inTransaction(ctx)
+	if statementIsCommit || (autoCommit && statementNeedsCommit(parsedQuery, parseErr)) {
+		if err = ctx.Session.CommitTransaction(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return ok, nil
+}
+
+// readRows reads the rows of a result, setting ok if it's the result of a
+// statement not returning rows.
+func (c *conn) readRows(
+	schema sql.Schema,
+	rows sql.RowIter,
+	onSchema func(sql.Schema) error,
+	onRow func(sql.Row) error,
+	ok **sql.OkResult,
+) error {
+	var started bool
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(row) == 1 {
+			if r, isOk := row[0].(sql.OkResult); isOk {
+				*ok = &r
+				return nil
+			}
+		}
+
+		if !started {
+			started = true
+			if err := onSchema(schema); err != nil {
+				return err
+			}
+		}
+
+		if err := onRow(row); err != nil {
+			return err
+		}
+	}
+
+	if !started && len(schema) > 0 {
+		return onSchema(schema)
+	}
+	return nil
+}
+
+// sendMetaData sends the metadata of the columns of a result set.
+func (c *conn) sendMetaData(schema sql.Schema) error {
+	for _, md := range columnsMetaData(schema) {
+		if err := c.send(serverResultsetColumnMetaData, md); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendExecuteOk ends the result of a statement, sending the warnings of the
+// session and, if the statement didn't return rows, the rows it affected.
+func (c *conn) sendExecuteOk(ok *sql.OkResult) error {
+	warnings := c.session.Warnings()
+	for i := len(warnings) - 1; i >= 0; i-- {
+		w := warnings[i]
+
+		level := int32(warningWarning)
+		switch w.Level {
+		case "Note":
+			level = warningNote
+		case "Error":
+			level = warningError
+		}
+
+		if err := c.sendNotice(noticeWarning, &warning{Level: proto.Int32(level), Code: proto.Uint32(uint32(w.Code)), Msg: proto.String(w.Message)}); err != nil {
+			return err
+		}
+	}
+
+	if ok != nil {
+		if err := c.sendStateChange(stateRowsAffected, newUintScalar(ok.RowsAffected)); err != nil {
+			return err
+		}
+
+		if ok.InsertID > 0 {
+			if err := c.sendStateChange(stateGeneratedInsertID, newUintScalar(ok.InsertID)); err != nil {
+				return err
+			}
+		}
+
+		if ok.Info != nil {
+			if err := c.sendStateChange(stateProducedMessage, newStringScalar(ok.Info.String())); err != nil {
+				return err
+			}
+		}
+	}
+
+	return c.send(serverSQLStmtExecuteOk, &message{})
+}
+
+func isSessionAutocommit(ctx *sql.Context) bool {
+	typ, autoCommitSessionVar := ctx.Get(sql.AutoCommitSessionVar)
+	autoCommit := false
+	if autoCommitSessionVar != nil {
+		switch typ {
+		case sql.Int64:
+			autoCommit = autoCommitSessionVar.(int64) == int64(1)
+		case sql.Boolean, sql.Int8:
+			autoCommit, _ = sql.ConvertToBool(autoCommitSessionVar)
+		default:
+		}
+	}
+	return autoCommit
+}
+
+// inTransaction returns whether the session of the context has a transaction started explicitly open, which the
+// statements run in don't commit even if autocommit is enabled.
+func inTransaction(ctx *sql.Context) bool {
+	s, ok := ctx.Session.(sql.ExplicitTransactionSession)
+	return ok && s.InTransaction()
+}
+
+func statementNeedsCommit(parsedQuery sqlparser.Statement, parseErr error) bool {
+	if parseErr == nil {
+		switch parsedQuery.(type) {
+		case *sqlparser.DDL, *sqlparser.Commit, *sqlparser.Update, *sqlparser.Insert, *sqlparser.Delete:
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *stmtExecute) GetNamespace() string {
+	if m != nil && m.Namespace != nil {
+		return *m.Namespace
+	}
+	return "sql"
+}
@@ -0,0 +1,33 @@
+// +build linux
+
+package server
+
+import (
+	"net"
+	"syscall"
+
+	"github.com/dolthub/go-mysql-server/auth"
+)
+
+// peerCredentials returns the credentials of the process of the client of a
+// Unix domain socket, with SO_PEERCRED.
+func peerCredentials(c *net.UnixConn) (*auth.PeerCredentials, error) {
+	raw, err := c.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var cred *syscall.Ucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if credErr != nil {
+		return nil, credErr
+	}
+
+	return &auth.PeerCredentials{PID: cred.Pid, UID: cred.Uid, GID: cred.Gid}, nil
+}
@@ -0,0 +1,15 @@
+// +build !linux
+
+package server
+
+import (
+	"net"
+
+	"github.com/dolthub/go-mysql-server/auth"
+)
+
+// peerCredentials is not implemented on this platform, so the credentials of
+// the clients of Unix domain sockets are unknown.
+func peerCredentials(*net.UnixConn) (*auth.PeerCredentials, error) {
+	return nil, ErrUnsupportedOperation.New()
+}
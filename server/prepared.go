@@ -0,0 +1,129 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/dolthub/vitess/go/vt/proto/query"
+)
+
+// preparedBindVars returns the parameters of a prepared statement to execute.
+// Vitess decodes the signed integers shorter than 64 bits sent by clients
+// in the binary protocol without extending their sign, and the dates and
+// times without padding their fields, so that they're fixed here. The
+// parameters sent as long data are not decoded by vitess and are left as
+// they are.
+func preparedBindVars(prepare *mysql.PrepareData) (map[string]*query.BindVariable, error) {
+	if len(prepare.BindVars) == 0 {
+		return prepare.BindVars, nil
+	}
+
+	bindVars := make(map[string]*query.BindVariable, len(prepare.BindVars))
+	for k, v := range prepare.BindVars {
+		bindVars[k] = v
+	}
+
+	for i, typ := range prepare.ParamsType {
+		key := fmt.Sprintf("v%d", i+1)
+		bv, ok := bindVars[key]
+		if !ok || bv == nil {
+			continue
+		}
+
+		var err error
+		switch query.Type(typ) {
+		case sqltypes.Int8, sqltypes.Int16, sqltypes.Int24, sqltypes.Int32:
+			if bv.Type == sqltypes.Int64 {
+				bindVars[key], err = signExtend(query.Type(typ), bv)
+			}
+		case sqltypes.Date, sqltypes.Datetime, sqltypes.Timestamp:
+			if bv.Type == sqltypes.VarChar {
+				bindVars[key], err = formatDatetime(query.Type(typ), string(bv.Value))
+			}
+		case sqltypes.Time:
+			if bv.Type == sqltypes.VarChar {
+				bindVars[key], err = formatTime(string(bv.Value))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bindVars, nil
+}
+
+// signExtend returns the integer of the type given, read as unsigned.
+func signExtend(typ query.Type, bv *query.BindVariable) (*query.BindVariable, error) {
+	n, err := strconv.ParseInt(string(bv.Value), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typ {
+	case sqltypes.Int8:
+		n = int64(int8(n))
+	case sqltypes.Int16:
+		n = int64(int16(n))
+	default:
+		n = int64(int32(n))
+	}
+	return sqltypes.Int64BindVariable(n), nil
+}
+
+// formatDatetime formats a date or datetime, whose fields are separated by
+// any of "- :.", as a date of the given type.
+func formatDatetime(typ query.Type, s string) (*query.BindVariable, error) {
+	fields, err := timeFields(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var v [7]int
+	copy(v[:], fields)
+
+	var val string
+	if typ == sqltypes.Date {
+		val = fmt.Sprintf("%04d-%02d-%02d", v[0], v[1], v[2])
+	} else {
+		val = fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d.%06d", v[0], v[1], v[2], v[3], v[4], v[5], v[6])
+	}
+	return &query.BindVariable{Type: typ, Value: []byte(val)}, nil
+}
+
+// formatTime formats a time, whose fields are separated by any of ":.".
+func formatTime(s string) (*query.BindVariable, error) {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	fields, err := timeFields(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var v [4]int
+	copy(v[:], fields)
+
+	val := fmt.Sprintf("%s%02d:%02d:%02d.%06d", sign, v[0], v[1], v[2], v[3])
+	return &query.BindVariable{Type: sqltypes.Time, Value: []byte(val)}, nil
+}
+
+func timeFields(s string) ([]int, error) {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return strings.ContainsRune("- :.", r)
+	})
+
+	fields := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = n
+	}
+	return fields, nil
+}
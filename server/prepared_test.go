@@ -0,0 +1,63 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/dolthub/vitess/go/vt/proto/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreparedBindVars(t *testing.T) {
+	require := require.New(t)
+
+	prepare := &mysql.PrepareData{
+		ParamsCount: 11,
+		ParamsType: []int32{
+			int32(sqltypes.Int8),
+			int32(sqltypes.Int16),
+			int32(sqltypes.Int32),
+			int32(sqltypes.Uint8),
+			int32(sqltypes.Datetime),
+			int32(sqltypes.Timestamp),
+			int32(sqltypes.Date),
+			int32(sqltypes.Datetime),
+			int32(sqltypes.Time),
+			int32(sqltypes.Time),
+			int32(sqltypes.Int8),
+		},
+		BindVars: map[string]*query.BindVariable{
+			"v1":  sqltypes.Int64BindVariable(255),
+			"v2":  sqltypes.Int64BindVariable(65534),
+			"v3":  sqltypes.Int64BindVariable(4294967295),
+			"v4":  sqltypes.Uint64BindVariable(255),
+			"v5":  sqltypes.StringBindVariable("2020-1-2 3:4:5.5000"),
+			"v6":  sqltypes.StringBindVariable(" "),
+			"v7":  sqltypes.StringBindVariable("2020-12-31"),
+			"v8":  sqltypes.BytesBindVariable([]byte("2020-01-02 03:04:05")),
+			"v9":  sqltypes.StringBindVariable("-25:3:4.12"),
+			"v10": sqltypes.StringBindVariable("00:00:00"),
+			"v11": sqltypes.NullBindVariable,
+		},
+	}
+
+	bindVars, err := preparedBindVars(prepare)
+	require.NoError(err)
+	require.Equal(map[string]*query.BindVariable{
+		"v1":  sqltypes.Int64BindVariable(-1),
+		"v2":  sqltypes.Int64BindVariable(-2),
+		"v3":  sqltypes.Int64BindVariable(-1),
+		"v4":  sqltypes.Uint64BindVariable(255),
+		"v5":  {Type: sqltypes.Datetime, Value: []byte("2020-01-02 03:04:05.005000")},
+		"v6":  {Type: sqltypes.Timestamp, Value: []byte("0000-00-00 00:00:00.000000")},
+		"v7":  {Type: sqltypes.Date, Value: []byte("2020-12-31")},
+		"v8":  sqltypes.BytesBindVariable([]byte("2020-01-02 03:04:05")),
+		"v9":  {Type: sqltypes.Time, Value: []byte("-25:03:04.000012")},
+		"v10": {Type: sqltypes.Time, Value: []byte("00:00:00.000000")},
+		"v11": sqltypes.NullBindVariable,
+	}, bindVars)
+
+	// The parameters of the statement are left as they are.
+	require.Equal(sqltypes.Int64BindVariable(255), prepare.BindVars["v1"])
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// ErrInvalidProxyHeader is returned when a connection from a trusted proxy
+// doesn't start with a valid PROXY protocol header.
+var ErrInvalidProxyHeader = errors.NewKind("invalid PROXY protocol header: %s")
+
+// ErrInvalidProxyNetwork is returned when a network of the proxies of a
+// server is not an IP address nor in CIDR notation.
+var ErrInvalidProxyNetwork = errors.NewKind("invalid PROXY protocol network: %s")
+
+// proxyV2Signature starts the headers of the version 2 of the PROXY protocol.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+const (
+	// proxyV1MaxLength is the maximum length of a version 1 header, with
+	// its CRLF.
+	proxyV1MaxLength = 107
+
+	proxyV2Local = 0x20
+	proxyV2Proxy = 0x21
+
+	proxyV2TCP4 = 0x11
+	proxyV2TCP6 = 0x21
+)
+
+// proxyNetworks are the networks of the proxies allowed to send a PROXY
+// protocol header.
+type proxyNetworks []*net.IPNet
+
+// parseProxyNetworks parses networks in CIDR notation or IP addresses. The
+// network * matches every address.
+func parseProxyNetworks(networks []string) (proxyNetworks, error) {
+	var nets proxyNetworks
+	for _, n := range networks {
+		n = strings.TrimSpace(n)
+		if n == "*" {
+			nets = append(nets,
+				&net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)},
+				&net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)})
+			continue
+		}
+
+		if !strings.Contains(n, "/") {
+			ip := net.ParseIP(n)
+			if ip == nil {
+				return nil, ErrInvalidProxyNetwork.New(n)
+			}
+
+			bits := 128
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(n)
+		if err != nil {
+			return nil, ErrInvalidProxyNetwork.New(n)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// trusts returns whether the connections from the address given come from a
+// proxy.
+func (n proxyNetworks) trusts(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	for _, ipNet := range n {
+		if ipNet.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// readProxyHeader reads the PROXY protocol header, of version 1 or 2, the
+// connection of a proxy starts with, and returns the address of the client
+// it proxies. It returns nil if the proxy sends no address, as for the
+// health checks of proxies, which are not proxied.
+func readProxyHeader(r io.Reader) (net.Addr, error) {
+	// The shortest header is "PROXY UNKNOWN\r\n", so the first bytes tell
+	// the version.
+	start := make([]byte, len(proxyV2Signature))
+	if _, err := io.ReadFull(r, start); err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(start, proxyV2Signature) {
+		return readProxyV2Header(r)
+	}
+	if bytes.HasPrefix(start, []byte("PROXY ")) {
+		return readProxyV1Header(r, start)
+	}
+	return nil, ErrInvalidProxyHeader.New("missing signature")
+}
+
+// readProxyV1Header reads the rest of a header of the version 1 of the
+// PROXY protocol, which is read byte by byte to not read past its end.
+func readProxyV1Header(r io.Reader, start []byte) (net.Addr, error) {
+	line := start
+	b := make([]byte, 1)
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) == proxyV1MaxLength {
+			return nil, ErrInvalidProxyHeader.New("header too long")
+		}
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		line = append(line, b[0])
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	switch {
+	case len(fields) >= 2 && fields[1] == "UNKNOWN":
+		return nil, nil
+	case len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6"):
+		return nil, ErrInvalidProxyHeader.New(strconv.Quote(string(line)))
+	}
+
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if ip == nil || err != nil || (fields[1] == "TCP4") != (ip.To4() != nil) {
+		return nil, ErrInvalidProxyHeader.New(strconv.Quote(string(line)))
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyV2Header reads the rest of a header of the version 2 of the
+// PROXY protocol, after its signature. The TLV vectors after the addresses
+// are skipped.
+func readProxyV2Header(r io.Reader) (net.Addr, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	command, family := header[0], header[1]
+	data := make([]byte, binary.BigEndian.Uint16(header[2:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	switch command {
+	case proxyV2Local:
+		return nil, nil
+	case proxyV2Proxy:
+	default:
+		return nil, ErrInvalidProxyHeader.New("unknown version or command")
+	}
+
+	var size int
+	switch family {
+	case proxyV2TCP4:
+		size = net.IPv4len
+	case proxyV2TCP6:
+		size = net.IPv6len
+	default:
+		// Other transports have no address a client can be matched by.
+		return nil, nil
+	}
+
+	if len(data) < 2*size+4 {
+		return nil, ErrInvalidProxyHeader.New("addresses too short")
+	}
+
+	ip := make(net.IP, size)
+	copy(ip, data[:size])
+	port := binary.BigEndian.Uint16(data[2*size:])
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func proxyV2Header(command, family byte, addrs ...byte) []byte {
+	header := append([]byte{}, proxyV2Signature...)
+	header = append(header, command, family, byte(len(addrs)>>8), byte(len(addrs)))
+	return append(header, addrs...)
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header []byte
+		addr   string
+		err    bool
+	}{
+		{"v1 TCP4", []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 3306\r\n"), "192.0.2.1:56324", false},
+		{"v1 TCP6", []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 3306\r\n"), "[2001:db8::1]:56324", false},
+		{"v1 UNKNOWN", []byte("PROXY UNKNOWN\r\n"), "", false},
+		{"v1 mismatched family", []byte("PROXY TCP4 2001:db8::1 2001:db8::2 56324 3306\r\n"), "", true},
+		{"v1 invalid port", []byte("PROXY TCP4 192.0.2.1 198.51.100.1 65536 3306\r\n"), "", true},
+		{"v1 missing fields", []byte("PROXY TCP4 192.0.2.1\r\n"), "", true},
+		{"v1 too long", append([]byte("PROXY TCP4 "), bytes.Repeat([]byte("1"), 120)...), "", true},
+		{
+			"v2 TCP4",
+			proxyV2Header(proxyV2Proxy, proxyV2TCP4, 192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x0c, 0xea),
+			"192.0.2.1:56324",
+			false,
+		},
+		{
+			"v2 TCP4 with TLVs",
+			proxyV2Header(proxyV2Proxy, proxyV2TCP4, 192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x0c, 0xea, 0x04, 0x00, 0x00),
+			"192.0.2.1:56324",
+			false,
+		},
+		{"v2 LOCAL", proxyV2Header(proxyV2Local, 0), "", false},
+		{"v2 addresses too short", proxyV2Header(proxyV2Proxy, proxyV2TCP4, 192, 0, 2, 1), "", true},
+		{"v2 unknown command", proxyV2Header(0x22, proxyV2TCP4), "", true},
+		{"no header", []byte("\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"), "", true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			// The bytes after the header are left to read.
+			r := bytes.NewReader(append(tt.header, "rest"...))
+			addr, err := readProxyHeader(r)
+			if tt.err {
+				require.Error(err)
+				return
+			}
+
+			require.NoError(err)
+			if tt.addr == "" {
+				require.Nil(addr)
+			} else {
+				require.Equal(tt.addr, addr.String())
+			}
+			require.Equal(4, r.Len())
+		})
+	}
+}
+
+func TestParseProxyNetworks(t *testing.T) {
+	require := require.New(t)
+
+	nets, err := parseProxyNetworks([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"})
+	require.NoError(err)
+
+	addr := func(ip string) net.Addr {
+		return &net.TCPAddr{IP: net.ParseIP(ip), Port: 3306}
+	}
+	require.True(nets.trusts(addr("10.1.2.3")))
+	require.True(nets.trusts(addr("192.0.2.1")))
+	require.False(nets.trusts(addr("192.0.2.2")))
+	require.True(nets.trusts(addr("2001:db8::1")))
+	require.False(nets.trusts(&net.UnixAddr{Name: "mysql.sock", Net: "unix"}))
+
+	nets, err = parseProxyNetworks([]string{"*"})
+	require.NoError(err)
+	require.True(nets.trusts(addr("192.0.2.2")))
+	require.True(nets.trusts(addr("2001:db8::1")))
+
+	_, err = parseProxyNetworks([]string{"10.0.0.0/33"})
+	require.True(ErrInvalidProxyNetwork.Is(err))
+	_, err = parseProxyNetworks([]string{"localhost"})
+	require.True(ErrInvalidProxyNetwork.Is(err))
+}
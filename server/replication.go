@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/binlog"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+const (
+	// comRegisterSlave is COM_REGISTER_SLAVE, which replicas send before
+	// asking for the binary log.
+	comRegisterSlave = 0x15
+	// binlogDumpNonBlock is BINLOG_DUMP_NON_BLOCK, the flag of the dumps
+	// that end once the client has all the events.
+	binlogDumpNonBlock = 0x01
+
+	// erSpecificAccessDenied is the MySQL error code returned to clients
+	// lacking a global privilege.
+	erSpecificAccessDenied = 1227
+	// erMasterFatalReadingBinlog is the MySQL error code returned to
+	// replicas whose dump of the binary log fails.
+	erMasterFatalReadingBinlog = 1236
+	// erMalformedPacket is the MySQL error code returned to clients sending
+	// commands that can't be parsed.
+	erMalformedPacket = 1835
+)
+
+// heartbeatVariables are the user variables replicas set to the period of the
+// heartbeats they want while they wait for events, in nanoseconds.
+var heartbeatVariables = []string{"source_heartbeat_period", "master_heartbeat_period"}
+
+// runReplicationCommand runs the commands of the replication protocol the
+// handler doesn't get, and returns whether the packet given was one of them.
+// The client is disconnected once a dump of the binary log ends, unless it
+// asked for the dump not to block.
+func (c *serverConn) runReplicationCommand(packet []byte) (bool, error) {
+	payload := packet[4:]
+	if c.continued || len(payload) == 0 {
+		return false, nil
+	}
+
+	switch payload[0] {
+	case comRegisterSlave, mysql.ComBinlogDump, mysql.ComBinlogDumpGTID:
+	default:
+		return false, nil
+	}
+
+	c.mu.Lock()
+	h, conn := c.handler, c.conn
+	c.mu.Unlock()
+	if h == nil || conn == nil {
+		return false, nil
+	}
+
+	ctx, err := h.sm.NewContextWithQuery(conn, "")
+	if err != nil {
+		return true, err
+	}
+
+	if err := h.checkReplicationPrivilege(ctx); err != nil {
+		sqlErr := mysql.NewSQLError(erSpecificAccessDenied, ssAccessViolation, "%s", err.Error())
+		return true, c.writeLocked(errorPacket(1, sqlErr))
+	}
+
+	if payload[0] == comRegisterSlave {
+		// The replica is registered for nothing but SHOW REPLICAS, which
+		// isn't supported.
+		return true, c.writeLocked([]byte{7, 0, 0, 1, mysql.OKPacket, 0, 0, 2, 0, 0, 0})
+	}
+
+	log := h.e.Binlog
+	req, ok := parseDumpRequest(payload)
+	switch {
+	case log == nil:
+		sqlErr := mysql.NewSQLError(erMasterFatalReadingBinlog, mysql.SSUnknownSQLState, "Binary log is not open")
+		return true, c.writeLocked(errorPacket(1, sqlErr))
+	case !ok:
+		sqlErr := mysql.NewSQLError(erMalformedPacket, mysql.SSUnknownSQLState, "Malformed communication packet.")
+		return true, c.writeLocked(errorPacket(1, sqlErr))
+	}
+	req.Heartbeat = heartbeatPeriod(ctx)
+
+	dumpCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if !req.NonBlock {
+		// Replicas don't send anything while they read the log, so reading
+		// only returns once they disconnect.
+		go func() {
+			var b [1]byte
+			_, _ = c.transport().Read(b[:])
+			cancel()
+		}()
+	}
+
+	seq := uint8(1)
+	err = log.Dump(dumpCtx, req, func(event []byte) error {
+		return c.writePackets(&seq, append([]byte{mysql.OKPacket}, event...))
+	})
+
+	switch {
+	case err == nil:
+		eof := []byte{mysql.EOFPacket, 0, 0, 0, 0}
+		err = c.writePackets(&seq, eof)
+	case dumpCtx.Err() != nil:
+		return true, io.EOF
+	default:
+		logrus.WithError(err).Warn("binary log dump failed")
+		sqlErr := mysql.NewSQLError(erMasterFatalReadingBinlog, mysql.SSUnknownSQLState, "%s", err.Error())
+		err = c.writeLocked(errorPacket(seq, sqlErr))
+	}
+
+	if err == nil && !req.NonBlock {
+		err = io.EOF
+	}
+	return true, err
+}
+
+// checkReplicationPrivilege returns an error if the user of the session
+// given can't read the binary log.
+func (h *Handler) checkReplicationPrivilege(ctx *sql.Context) error {
+	pm, err := h.e.Catalog.PrivilegeManager()
+	if sql.ErrPrivilegesNotSupported.Is(err) {
+		return h.e.Auth.Allowed(ctx, auth.ReadPerm)
+	} else if err != nil {
+		return err
+	}
+
+	account, err := h.e.Catalog.CurrentAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	privileges, err := sql.ActivePrivileges(ctx, pm, account)
+	if err != nil {
+		return err
+	}
+	if !privileges.Has("", "", sql.PrivilegeReplicationSlave) {
+		return sql.ErrSpecificAccessDenied.New("REPLICATION SLAVE")
+	}
+	return nil
+}
+
+// parseDumpRequest parses the payload of a COM_BINLOG_DUMP or
+// COM_BINLOG_DUMP_GTID command.
+func parseDumpRequest(payload []byte) (binlog.DumpRequest, bool) {
+	var req binlog.DumpRequest
+	if payload[0] == mysql.ComBinlogDump {
+		if len(payload) < 11 {
+			return req, false
+		}
+
+		req.Position = uint64(binary.LittleEndian.Uint32(payload[1:]))
+		req.NonBlock = binary.LittleEndian.Uint16(payload[5:])&binlogDumpNonBlock != 0
+		req.File = string(payload[11:])
+		return req, true
+	}
+
+	if len(payload) < 11 {
+		return req, false
+	}
+	req.NonBlock = binary.LittleEndian.Uint16(payload[1:])&binlogDumpNonBlock != 0
+	n := int(binary.LittleEndian.Uint32(payload[7:]))
+	data := payload[11:]
+	if len(data) < n+8 {
+		return req, false
+	}
+	req.File = string(data[:n])
+	req.Position = binary.LittleEndian.Uint64(data[n:])
+	data = data[n+8:]
+
+	// The GTIDs follow the position if the replica sends them, with their
+	// size first.
+	gtids := mysql.Mysql56GTIDSet{}
+	if len(data) >= 4 {
+		size := int(binary.LittleEndian.Uint32(data))
+		if len(data) < 4+size {
+			return req, false
+		}
+
+		if size > 0 {
+			var err error
+			if gtids, err = mysql.NewMysql56GTIDSetFromSIDBlock(data[4 : 4+size]); err != nil {
+				return req, false
+			}
+		}
+	}
+	req.GTIDs = gtids
+	return req, true
+}
+
+// heartbeatPeriod returns the period of the heartbeats the replica of the
+// session given wants.
+func heartbeatPeriod(ctx *sql.Context) time.Duration {
+	for _, name := range heartbeatVariables {
+		_, v := ctx.Get(name)
+		if v == nil {
+			continue
+		}
+
+		ns, err := sql.Int64.Convert(v)
+		if err == nil && ns.(int64) > 0 {
+			return time.Duration(ns.(int64))
+		}
+	}
+	return 0
+}
+
+// writeLocked writes the data given while holding the lock of the
+// connection.
+func (c *serverConn) writeLocked(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.write(data)
+}
+
+// writePackets writes a payload in as many packets as needed, starting with
+// the sequence number given, which is left as the next one.
+func (c *serverConn) writePackets(seq *uint8, payload []byte) error {
+	var data []byte
+	for {
+		n := len(payload)
+		if n > maxPacketPayload {
+			n = maxPacketPayload
+		}
+
+		data = append(data, byte(n), byte(n>>8), byte(n>>16), *seq)
+		data = append(data, payload[:n]...)
+		*seq++
+		payload = payload[n:]
+		if n < maxPacketPayload {
+			break
+		}
+	}
+	return c.writeLocked(data)
+}
@@ -1,31 +1,47 @@
 package server
 
 import (
+	"context"
+	"net"
 	"time"
 
 	"github.com/dolthub/vitess/go/mysql"
 	"github.com/opentracing/opentracing-go"
+	"github.com/sirupsen/logrus"
 
 	sqle "github.com/dolthub/go-mysql-server"
 	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/server/mysqlx"
 )
 
 // Server is a MySQL server for SQLe engines.
 type Server struct {
 	Listener *mysql.Listener
-	h        *Handler
+	// X is the X Protocol endpoint of the server, if it has one.
+	X   *mysqlx.Server
+	h   *Handler
+	tls *tlsLoader
 }
 
 // Config for the mysql server.
 type Config struct {
 	// Protocol for the connection.
 	Protocol string
-	// Address of the server.
+	// Address of the server. It's the path of a socket for the unix
+	// protocol.
 	Address string
+	// Socket is the path of a Unix domain socket the server listens on, in
+	// addition to the address, unless it's empty. The socket file left by a
+	// server that didn't stop cleanly is replaced, and the file is removed
+	// once the server is closed. The remote address of the clients connected
+	// through a socket is an auth.PeerAddr with their credentials.
+	Socket string
 	// Auth of the server.
 	Auth auth.Auth
 	// Tracer to use in the server. By default, a noop tracer will be used if
-	// no tracer is provided.
+	// no tracer is provided. The root span of each query is the child of the
+	// span whose context the tracer extracts from the tags of the comments of
+	// the query, in the TextMap format, if any.
 	Tracer opentracing.Tracer
 	// Version string to advertise in running server
 	Version string
@@ -35,6 +51,34 @@ type Config struct {
 	ConnWriteTimeout time.Duration
 	// MaxConnections is the maximum number of simultaneous connections that the server will allow.
 	MaxConnections uint64
+	// TLS configures TLS connections. If nil, the server does not support TLS.
+	TLS *TLSConfig
+	// AllowClearTextWithoutTLS allows clients to send their password in clear
+	// text over connections not using TLS, as required by auth.DirectoryAuth when
+	// the server has no TLS configuration.
+	AllowClearTextWithoutTLS bool
+	// XProtocolAddress is the address of the X Protocol endpoint of the
+	// server, usually on port 33060. If empty, the server has no X Protocol
+	// endpoint. Its sessions are not created by the session builder of the
+	// server.
+	XProtocolAddress string
+	// ProxyProtocolNetworks are the networks, in CIDR notation or as IP
+	// addresses, of the load balancers the server is behind, or * for all
+	// of them. The TCP connections from these networks must start with a
+	// PROXY protocol header, of version 1 or 2, whose client address is the
+	// one the clients are authenticated and listed by. The connections of the
+	// health checks of load balancers, whose headers have no address, keep
+	// theirs.
+	ProxyProtocolNetworks []string
+	// Listener, if set, is the listener the server accepts connections on,
+	// instead of listening on the address. The X Protocol endpoint still
+	// listens on its own address. The server closes the listener once it's
+	// closed.
+	Listener net.Listener
+	// ConnMiddleware are called in order on the connections the server
+	// accepts, before they're handled, to reject them or wrap them, as for
+	// IP allowlists, throttling or tagging connections.
+	ConnMiddleware []ConnMiddleware
 }
 
 // NewDefaultServer creates a Server with the default session builder.
@@ -64,6 +108,10 @@ func NewServer(cfg Config, e *sqle.Engine, sb SessionBuilder) (*Server, error) {
 		cfg.MaxConnections = 0
 	}
 
+	if cfg.Listener != nil && cfg.Address == "" {
+		cfg.Address = cfg.Listener.Addr().String()
+	}
+
 	handler := NewHandler(e,
 		NewSessionManager(
 			sb,
@@ -72,11 +120,34 @@ func NewServer(cfg Config, e *sqle.Engine, sb SessionBuilder) (*Server, error) {
 			e.Catalog.MemoryManager,
 			cfg.Address),
 		cfg.ConnReadTimeout)
+	handler.auth = cfg.Auth
+
+	var tl *tlsLoader
+	if cfg.TLS != nil {
+		var err error
+		tl, err = newTLSLoader(*cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		handler.requireSecureTransport = cfg.TLS.RequireSecureTransport
+	}
+
+	proxies, err := parseProxyNetworks(cfg.ProxyProtocolNetworks)
+	if err != nil {
+		return nil, err
+	}
+
 	a := cfg.Auth.Mysql()
-	l, err := NewListener(cfg.Protocol, cfg.Address, handler)
+	l, err := newListener(cfg, handler)
 	if err != nil {
 		return nil, err
 	}
+	l.compress = true
+	l.proxies = proxies
+	l.allowClearText = cfg.AllowClearTextWithoutTLS
+	if tl != nil {
+		l.tlsConfig = tl.config()
+	}
 
 	listenerCfg := mysql.ListenerConfig{
 		Listener:           l,
@@ -96,17 +167,88 @@ func NewServer(cfg Config, e *sqle.Engine, sb SessionBuilder) (*Server, error) {
 		vtListnr.ServerVersion = cfg.Version
 	}
 
-	return &Server{Listener: vtListnr, h: handler}, nil
+	// Connections switch to TLS below the MySQL protocol implementation,
+	// which sees them all as insecure, so the listener refuses clear text
+	// passwords over insecure connections and the handler requires secure
+	// transport instead.
+	vtListnr.AllowClearTextWithoutTLS = true
+
+	var x *mysqlx.Server
+	if cfg.XProtocolAddress != "" {
+		x, err = mysqlx.NewServer(mysqlx.Config{
+			Protocol:                 cfg.Protocol,
+			Address:                  cfg.XProtocolAddress,
+			Auth:                     cfg.Auth,
+			Tracer:                   tracer,
+			ConnReadTimeout:          cfg.ConnReadTimeout,
+			ConnWriteTimeout:         cfg.ConnWriteTimeout,
+			MaxConnections:           cfg.MaxConnections,
+			AllowClearTextWithoutTLS: cfg.AllowClearTextWithoutTLS,
+			RequireSecureTransport:   cfg.TLS != nil && cfg.TLS.RequireSecureTransport,
+		}, e)
+		if err != nil {
+			vtListnr.Close()
+			return nil, err
+		}
+	}
+
+	return &Server{Listener: vtListnr, X: x, h: handler, tls: tl}, nil
+}
+
+// ReloadTLS reads again the certificates of the TLS configuration, which are
+// used for new connections from then on. Established connections are not
+// affected. If the certificates can't be loaded, the previous ones are kept.
+func (s *Server) ReloadTLS() error {
+	if s.tls == nil {
+		return ErrInvalidTLSConfig.New("the server has no TLS configuration")
+	}
+	return s.tls.load()
 }
 
 // Start starts accepting connections on the server.
 func (s *Server) Start() error {
+	if s.X != nil {
+		go func() {
+			if err := s.X.Start(); err != nil {
+				logrus.Errorf("mysqlx: unable to accept connections: %s", err)
+			}
+		}()
+	}
+
 	s.Listener.Accept()
 	return nil
 }
 
+// Shutdown stops the server gracefully. It stops accepting connections and
+// rejects new queries right away, then waits for the queries running to
+// finish until the context given is done, returning its error if some
+// didn't. Finally, the queries still running are killed, the transactions of
+// the sessions implementing sql.TransactionSession are rolled back, and the
+// connections are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.h.shutdown()
+	s.Listener.Shutdown()
+	if s.X != nil {
+		if err := s.X.StopAccepting(); err != nil {
+			logrus.Errorf("mysqlx: unable to stop accepting connections: %s", err)
+		}
+	}
+
+	err := s.h.drain(ctx)
+	s.h.closeConnections()
+	if s.X != nil {
+		if xerr := s.X.Close(); xerr != nil && err == nil {
+			err = xerr
+		}
+	}
+	return err
+}
+
 // Close closes the server connection.
 func (s *Server) Close() error {
 	s.Listener.Close()
+	if s.X != nil {
+		return s.X.Close()
+	}
 	return nil
 }
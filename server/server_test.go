@@ -0,0 +1,877 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	gosql "database/sql"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	gosqldriver "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/binlog"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+)
+
+func TestServerMultiStatements(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: new(auth.None)})
+
+	s, err := NewDefaultServer(Config{Protocol: "tcp", Address: "localhost:0", Auth: new(auth.None)}, e)
+	require.NoError(err)
+	go s.Start()
+	defer s.Close()
+
+	db, err := gosql.Open("mysql", "root:@tcp("+s.Listener.Addr().String()+")/mydb?multiStatements=true")
+	require.NoError(err)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE TABLE t (i INT PRIMARY KEY); INSERT INTO t VALUES (1), (2); INSERT INTO t VALUES (3)")
+	require.NoError(err)
+
+	// Each statement returns its own result set.
+	rows, err := db.Query("SELECT i FROM t ORDER BY i; SELECT 'a'; SELECT COUNT(*) FROM t;")
+	require.NoError(err)
+	defer rows.Close()
+
+	var results [][]string
+	for {
+		var result []string
+		for rows.Next() {
+			var v string
+			require.NoError(rows.Scan(&v))
+			result = append(result, v)
+		}
+		results = append(results, result)
+
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+	require.NoError(rows.Err())
+	require.Equal([][]string{{"1", "2", "3"}, {"a"}, {"3"}}, results)
+}
+
+func TestServerPreparedStatements(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: new(auth.None)})
+
+	s, err := NewDefaultServer(Config{Protocol: "tcp", Address: "localhost:0", Auth: new(auth.None)}, e)
+	require.NoError(err)
+	go s.Start()
+	defer s.Close()
+
+	// The driver uses the binary protocol for the queries with parameters.
+	db, err := gosql.Open("mysql", "root:@tcp("+s.Listener.Addr().String()+")/mydb?parseTime=true")
+	require.NoError(err)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE TABLE t (i TINYINT PRIMARY KEY, d DATETIME(6), t TIME, s TEXT)")
+	require.NoError(err)
+
+	d := time.Date(2020, 1, 2, 3, 4, 5, 5000, time.UTC)
+	for i := -2; i <= 2; i++ {
+		res, err := db.Exec("INSERT INTO t VALUES (?, ?, ?, ?)", int8(i), d.AddDate(0, 0, i), "-01:02:03", nil)
+		require.NoError(err)
+		n, err := res.RowsAffected()
+		require.NoError(err)
+		require.Equal(int64(1), n)
+	}
+
+	var i int8
+	var dt time.Time
+	require.NoError(db.QueryRow("SELECT i, d FROM t WHERE d = ?", d.AddDate(0, 0, -1)).Scan(&i, &dt))
+	require.Equal(int8(-1), i)
+	require.True(d.AddDate(0, 0, -1).Equal(dt))
+
+	var tm string
+	var str gosql.NullString
+	require.NoError(db.QueryRow("SELECT t, s FROM t WHERE i = ? AND t = ?", int8(-2), "-01:02:03").Scan(&tm, &str))
+	require.Equal("-01:02:03", tm)
+	require.False(str.Valid)
+
+	query := func(q string, args ...interface{}) []int8 {
+		rows, err := db.Query(q, args...)
+		require.NoError(err)
+		defer rows.Close()
+
+		var result []int8
+		for rows.Next() {
+			require.NoError(rows.Scan(&i))
+			result = append(result, i)
+		}
+		require.NoError(rows.Err())
+		return result
+	}
+
+	require.Equal([]int8{-1, 0}, query("SELECT i FROM t ORDER BY i LIMIT ? OFFSET ?", 2, 1))
+	require.Equal([]int8{1}, query("SELECT i FROM t WHERE i = (SELECT MAX(i) FROM t WHERE i < ?)", 2))
+
+	_, err = db.Query("SELECT i FROM t LIMIT ?", -1)
+	require.Error(err)
+
+	_, err = db.Exec("UPDATE t SET s = ? WHERE i > ?", "a", 0)
+	require.NoError(err)
+	require.Equal([]int8{1, 2}, query("SELECT i FROM t WHERE s = ? ORDER BY i", "a"))
+
+	_, err = db.Exec("SET @x = ?", 5)
+	require.NoError(err)
+
+	// The statements prepared explicitly are executed as many times as
+	// needed, with new values for their parameters each time.
+	stmt, err := db.Prepare("SELECT i FROM t WHERE i >= ? ORDER BY i")
+	require.NoError(err)
+	defer stmt.Close()
+
+	for _, n := range []int8{2, -1} {
+		rows, err := stmt.Query(n)
+		require.NoError(err)
+
+		var result []int8
+		for rows.Next() {
+			require.NoError(rows.Scan(&i))
+			result = append(result, i)
+		}
+		require.NoError(rows.Err())
+		require.NoError(rows.Close())
+		require.Equal(int(2-n+1), len(result))
+	}
+}
+
+// testTLSConfig returns the TLS configuration of a server using a
+// self-signed certificate for localhost, written to a temporary directory.
+func testTLSConfig(t *testing.T) *TLSConfig {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCertificate(t, certFile, keyFile, 1)
+	return &TLSConfig{CertFile: certFile, KeyFile: keyFile}
+}
+
+// switchToTLS asks the server to switch the connection given to TLS, right
+// after its greeting, and returns the TLS connection. The handshake response
+// follows with sequence number 2.
+func switchToTLS(t *testing.T, conn net.Conn, flags uint32) net.Conn {
+	request := make([]byte, 32)
+	binary.LittleEndian.PutUint32(request, flags|mysql.CapabilityClientSSL)
+	_, err := conn.Write(append([]byte{32, 0, 0, 1}, request...))
+	require.NoError(t, err)
+	return tls.Client(conn, &tls.Config{InsecureSkipVerify: true})
+}
+
+func TestServerCompression(t *testing.T) {
+	for _, secure := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tls %t", secure), func(t *testing.T) {
+			testServerCompression(t, secure)
+		})
+	}
+}
+
+func testServerCompression(t *testing.T, secure bool) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: new(auth.None)})
+
+	cfg := Config{Protocol: "tcp", Address: "localhost:0", Auth: new(auth.None)}
+	if secure {
+		cfg.TLS = testTLSConfig(t)
+	}
+	s, err := NewDefaultServer(cfg, e)
+	require.NoError(err)
+	go s.Start()
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.Listener.Addr().String())
+	require.NoError(err)
+	defer conn.Close()
+
+	readPacket := func(r io.Reader) []byte {
+		var header [4]byte
+		_, err := io.ReadFull(r, header[:])
+		require.NoError(err)
+		data := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+		_, err = io.ReadFull(r, data)
+		require.NoError(err)
+		return data
+	}
+
+	// The server announces compression, and TLS if it supports it.
+	greeting := readPacket(conn)
+	pos := bytes.IndexByte(greeting[1:], 0) + 2 + 4 + 8 + 1
+	capabilities := binary.LittleEndian.Uint16(greeting[pos:])
+	require.NotZero(capabilities & capabilityClientCompress)
+	require.Equal(secure, capabilities&mysql.CapabilityClientSSL != 0)
+
+	flags := uint32(mysql.CapabilityClientProtocol41 | mysql.CapabilityClientSecureConnection |
+		mysql.CapabilityClientPluginAuth | capabilityClientCompress)
+	seq := byte(1)
+	if secure {
+		conn = switchToTLS(t, conn, flags)
+		seq++
+	}
+	response := make([]byte, 32)
+	binary.LittleEndian.PutUint32(response, flags)
+	response = append(response, "root\x00\x00mysql_native_password\x00"...)
+	_, err = conn.Write(append([]byte{byte(len(response)), 0, 0, seq}, response...))
+	require.NoError(err)
+
+	// The handshake ends uncompressed.
+	require.Equal(byte(mysql.OKPacket), readPacket(conn)[0])
+
+	query := append([]byte{mysql.ComQuery}, "SELECT REPEAT('a', 1000)"...)
+	_, err = writeCompressedPackets(conn, append([]byte{byte(len(query)), 0, 0, 0}, query...), 0)
+	require.NoError(err)
+
+	// The result is compressed, starting with the next sequence number.
+	var raw bytes.Buffer
+	payload, seq, err := readCompressedPacket(io.TeeReader(conn, &raw))
+	require.NoError(err)
+	require.Equal(uint8(1), seq)
+	header := raw.Bytes()
+	require.NotZero(int(header[4]) | int(header[5])<<8 | int(header[6])<<16)
+	require.Less(raw.Len(), 1000)
+
+	result := payload
+	for !bytes.Contains(result, []byte(strings.Repeat("a", 1000))) {
+		payload, _, err = readCompressedPacket(conn)
+		require.NoError(err)
+		result = append(result, payload...)
+	}
+}
+
+func TestServerCursors(t *testing.T) {
+	for _, secure := range []bool{false, true} {
+		for _, deprecateEOF := range []bool{false, true} {
+			t.Run(fmt.Sprintf("tls %t deprecate EOF %t", secure, deprecateEOF), func(t *testing.T) {
+				testServerCursors(t, secure, deprecateEOF)
+			})
+		}
+	}
+}
+
+func testServerCursors(t *testing.T, secure, deprecateEOF bool) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("mydb")
+	table := memory.NewTable("t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true}})
+	for i := int64(1); i <= 5; i++ {
+		require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(i)))
+	}
+	db.AddTable("t", table)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: new(auth.None)})
+
+	cfg := Config{Protocol: "tcp", Address: "localhost:0", Auth: new(auth.None)}
+	if secure {
+		cfg.TLS = testTLSConfig(t)
+	}
+	s, err := NewDefaultServer(cfg, e)
+	require.NoError(err)
+	go s.Start()
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.Listener.Addr().String())
+	require.NoError(err)
+	defer conn.Close()
+
+	readPacket := func() ([]byte, uint8) {
+		var header [4]byte
+		_, err := io.ReadFull(conn, header[:])
+		require.NoError(err)
+		data := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+		_, err = io.ReadFull(conn, data)
+		require.NoError(err)
+		return data, header[3]
+	}
+	writePacket := func(seq uint8, data ...byte) {
+		_, err := conn.Write(append([]byte{byte(len(data)), byte(len(data) >> 8), 0, seq}, data...))
+		require.NoError(err)
+	}
+	// readEOF reads the packet ending column definitions or rows, returning
+	// its status flags, which follow the warnings in EOF packets and the
+	// affected rows and last insert ID, both zero, in OK packets.
+	readEOF := func() uint16 {
+		data, _ := readPacket()
+		require.Equal(byte(mysql.EOFPacket), data[0])
+		return binary.LittleEndian.Uint16(data[3:])
+	}
+	readRow := func(seq uint8) int64 {
+		data, s := readPacket()
+		require.Equal(seq, s)
+		require.Equal(byte(0), data[0])
+		return int64(binary.LittleEndian.Uint64(data[2:]))
+	}
+	fetch := func(stmtID uint32, rows uint32) {
+		data := []byte{mysql.ComStmtFetch, 0, 0, 0, 0, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(data[1:], stmtID)
+		binary.LittleEndian.PutUint32(data[5:], rows)
+		writePacket(0, data...)
+	}
+	requireNoOpenCursor := func() {
+		data, seq := readPacket()
+		require.Equal(uint8(1), seq)
+		require.Equal(byte(mysql.ErrPacket), data[0])
+		require.Equal(uint16(erStmtHasNoOpenCursor), binary.LittleEndian.Uint16(data[1:]))
+	}
+
+	readPacket()
+	flags := uint32(mysql.CapabilityClientProtocol41 | mysql.CapabilityClientSecureConnection |
+		mysql.CapabilityClientPluginAuth)
+	if deprecateEOF {
+		flags |= mysql.CapabilityClientDeprecateEOF
+	}
+	seq := uint8(1)
+	if secure {
+		conn = switchToTLS(t, conn, flags)
+		seq++
+	}
+	response := make([]byte, 32)
+	binary.LittleEndian.PutUint32(response, flags)
+	writePacket(seq, append(response, "root\x00\x00mysql_native_password\x00"...)...)
+	data, okSeq := readPacket()
+	require.Equal(byte(mysql.OKPacket), data[0])
+	require.Equal(seq+1, okSeq)
+
+	writePacket(0, append([]byte{mysql.ComPrepare}, "SELECT i FROM mydb.t WHERE i > ? ORDER BY i"...)...)
+	data, _ = readPacket()
+	require.Equal(byte(mysql.OKPacket), data[0])
+	stmtID := binary.LittleEndian.Uint32(data[1:])
+	// A parameter and a column.
+	for i := 0; i < 2; i++ {
+		readPacket()
+		if !deprecateEOF {
+			readEOF()
+		}
+	}
+
+	execute := func() {
+		// A read only cursor, one iteration, the NULL bitmap and the type
+		// and value of the parameter, a LONGLONG.
+		data := []byte{mysql.ComStmtExecute, 0, 0, 0, 0, 1, 1, 0, 0, 0, 0, 1, 0x08, 0}
+		binary.LittleEndian.PutUint32(data[1:], stmtID)
+		data = append(data, 2, 0, 0, 0, 0, 0, 0, 0)
+		writePacket(0, data...)
+
+		// The column count and definition, and the status of the cursor.
+		data, _ = readPacket()
+		require.Equal(byte(1), data[0])
+		readPacket()
+		require.NotZero(readEOF() & serverStatusCursorExists)
+	}
+	execute()
+
+	fetch(stmtID, 2)
+	require.Equal(int64(3), readRow(1))
+	require.Equal(int64(4), readRow(2))
+	status := readEOF()
+	require.NotZero(status & serverStatusCursorExists)
+	require.Zero(status & serverStatusLastRowSent)
+
+	// Other statements can be run while the cursor is open.
+	writePacket(0, append([]byte{mysql.ComQuery}, "SELECT 1"...)...)
+	readPacket()
+	readPacket()
+	if !deprecateEOF {
+		readEOF()
+	}
+	data, _ = readPacket()
+	require.Equal([]byte{1, '1'}, data)
+	require.Zero(readEOF() & serverStatusCursorExists)
+
+	fetch(stmtID, 10)
+	require.Equal(int64(5), readRow(1))
+	status = readEOF()
+	require.NotZero(status & serverStatusLastRowSent)
+
+	// The cursor is closed once all its rows have been read, and when its
+	// statement is closed.
+	fetch(stmtID, 1)
+	requireNoOpenCursor()
+
+	execute()
+	closeStmt := []byte{mysql.ComStmtClose, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(closeStmt[1:], stmtID)
+	writePacket(0, closeStmt...)
+	fetch(stmtID, 1)
+	requireNoOpenCursor()
+}
+
+// peerAuth is an Auth keeping the remote addresses of the clients that log
+// in.
+type peerAuth struct {
+	auth.None
+	addrs chan net.Addr
+}
+
+func (a *peerAuth) Mysql() mysql.AuthServer {
+	return &peerAuthServer{addrs: a.addrs}
+}
+
+type peerAuthServer struct {
+	mysql.AuthServerNone
+	addrs chan net.Addr
+}
+
+func (s *peerAuthServer) ValidateHash(salt []byte, user string, authResponse []byte, remoteAddr net.Addr) (mysql.Getter, error) {
+	s.addrs <- remoteAddr
+	return s.AuthServerNone.ValidateHash(salt, user, authResponse, remoteAddr)
+}
+
+// clearTextAuth is an Auth asking the clients for their password in clear
+// text, and letting them all in.
+type clearTextAuth struct {
+	auth.None
+}
+
+func (clearTextAuth) Mysql() mysql.AuthServer {
+	return new(clearTextAuthServer)
+}
+
+type clearTextAuthServer struct {
+	mysql.AuthServerNone
+}
+
+func (s *clearTextAuthServer) AuthMethod(user string) (string, error) {
+	return mysql.MysqlClearPassword, nil
+}
+
+func (s *clearTextAuthServer) Negotiate(c *mysql.Conn, user string, remoteAddr net.Addr) (mysql.Getter, error) {
+	if _, err := mysql.AuthServerReadPacketString(c); err != nil {
+		return nil, err
+	}
+	return new(mysql.NoneGetter), nil
+}
+
+func TestServerSocket(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "socket")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "mysql.sock")
+
+	// A socket file left by a server that didn't stop is replaced.
+	stale, err := net.Listen("unix", socket)
+	require.NoError(err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(stale.Close())
+
+	catalog := sql.NewCatalog()
+	a := &peerAuth{addrs: make(chan net.Addr, 2)}
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: a})
+
+	s, err := NewDefaultServer(Config{Protocol: "tcp", Address: "localhost:0", Socket: socket, Auth: a}, e)
+	require.NoError(err)
+	go s.Start()
+
+	// The socket of a running server is not.
+	_, err = NewDefaultServer(Config{Protocol: "unix", Address: socket, Auth: a}, e)
+	require.True(ErrSocketInUse.Is(err))
+
+	for _, dsn := range []string{"tcp(" + s.Listener.Addr().String() + ")", "unix(" + socket + ")"} {
+		db, err := gosql.Open("mysql", "root:@"+dsn+"/")
+		require.NoError(err)
+
+		var one int
+		require.NoError(db.QueryRow("SELECT 1").Scan(&one))
+		require.NoError(db.Close())
+	}
+
+	_, ok := auth.PeerCredentialsOf(<-a.addrs)
+	require.False(ok)
+
+	addr := <-a.addrs
+	require.Equal("unix", addr.Network())
+	if runtime.GOOS == "linux" {
+		cred, ok := auth.PeerCredentialsOf(addr)
+		require.True(ok)
+		require.Equal(uint32(os.Getuid()), cred.UID)
+		require.Equal(int32(os.Getpid()), cred.PID)
+	}
+
+	require.NoError(s.Close())
+	_, err = os.Stat(socket)
+	require.True(os.IsNotExist(err))
+}
+
+// transactionSession is a session counting the times its transaction is
+// rolled back.
+type transactionSession struct {
+	sql.Session
+	rollbacks *int32
+}
+
+func (s *transactionSession) Rollback(*sql.Context) error {
+	atomic.AddInt32(s.rollbacks, 1)
+	return nil
+}
+
+func TestServerShutdown(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: new(auth.None)})
+
+	var rollbacks int32
+	sb := func(ctx context.Context, c *mysql.Conn, addr string) (sql.Session, *sql.IndexRegistry, *sql.ViewRegistry, error) {
+		s, ir, vr, err := DefaultSessionBuilder(ctx, c, addr)
+		return &transactionSession{Session: s, rollbacks: &rollbacks}, ir, vr, err
+	}
+
+	s, err := NewServer(Config{Protocol: "tcp", Address: "localhost:0", Auth: new(auth.None)}, e, sb)
+	require.NoError(err)
+	go s.Start()
+	defer s.Close()
+
+	addr := s.Listener.Addr().String()
+	var dbs []*gosql.DB
+	for i := 0; i < 3; i++ {
+		db, err := gosql.Open("mysql", "root:@tcp("+addr+")/mydb")
+		require.NoError(err)
+		defer db.Close()
+		require.NoError(db.Ping())
+		dbs = append(dbs, db)
+	}
+
+	sleep := func(db *gosql.DB, seconds float64) chan error {
+		done := make(chan error, 1)
+		go func() {
+			var n int
+			done <- db.QueryRow("SELECT SLEEP(?)", seconds).Scan(&n)
+		}()
+		return done
+	}
+
+	short, long := sleep(dbs[0], 0.3), sleep(dbs[1], 10)
+	for s.h.runningQueries() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- s.Shutdown(ctx)
+	}()
+
+	// New connections and queries are rejected once shutting down, but the
+	// queries running finish until the deadline.
+	time.Sleep(100 * time.Millisecond)
+	_, err = net.DialTimeout("tcp", addr, 100*time.Millisecond)
+	require.Error(err)
+
+	_, err = dbs[2].Exec("SELECT 1")
+	require.Error(err)
+	require.Contains(err.Error(), "Server shutdown in progress")
+
+	require.NoError(<-short)
+	require.Equal(context.DeadlineExceeded, <-shutdown)
+	require.Error(<-long)
+
+	require.Equal(int32(3), atomic.LoadInt32(&rollbacks))
+}
+
+func TestServerProxyProtocol(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	a := &peerAuth{addrs: make(chan net.Addr, 2)}
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: a})
+
+	_, err := NewDefaultServer(Config{Protocol: "tcp", Address: "localhost:0", Auth: a, ProxyProtocolNetworks: []string{"proxy"}}, e)
+	require.True(ErrInvalidProxyNetwork.Is(err))
+
+	s, err := NewDefaultServer(Config{Protocol: "tcp", Address: "127.0.0.1:0", Auth: a, ProxyProtocolNetworks: []string{"127.0.0.0/8"}}, e)
+	require.NoError(err)
+	go s.Start()
+	defer s.Close()
+
+	// The connections of the proxy start with the header, and the clients
+	// are known by the address in it.
+	var header string
+	gosqldriver.RegisterDial("proxy", func(addr string) (net.Conn, error) {
+		c, err := net.Dial("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := c.Write([]byte(header)); err != nil {
+			c.Close()
+			return nil, err
+		}
+		return c, nil
+	})
+
+	dsn := "root:@proxy(" + s.Listener.Addr().String() + ")/mydb"
+	query := func() (string, error) {
+		db, err := gosql.Open("mysql", dsn)
+		require.NoError(err)
+		defer db.Close()
+
+		var host string
+		var ignored interface{}
+		err = db.QueryRow("SHOW PROCESSLIST").Scan(&ignored, &ignored, &host, &ignored, &ignored, &ignored, &ignored, &ignored)
+		return host, err
+	}
+
+	header = "PROXY TCP4 192.0.2.1 127.0.0.1 56324 3306\r\n"
+	host, err := query()
+	require.NoError(err)
+	require.Equal("192.0.2.1:56324", host)
+	require.Equal("192.0.2.1:56324", (<-a.addrs).String())
+
+	// Health checks keep the address of the proxy.
+	header = "PROXY UNKNOWN\r\n"
+	host, err = query()
+	require.NoError(err)
+	require.True(strings.HasPrefix(host, "127.0.0.1:"))
+	require.True(strings.HasPrefix((<-a.addrs).String(), "127.0.0.1:"))
+
+	// The connections of the proxy without a header are rejected.
+	header = ""
+	_, err = query()
+	require.Error(err)
+}
+
+func TestServerNetWriteTimeout(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	db := memory.NewDatabase("mydb")
+	catalog.AddDatabase(db)
+	table := memory.NewTable("t", sql.Schema{{Name: "s", Type: sql.LongText, Source: "t"}})
+	value := strings.Repeat("a", 10000)
+	for i := 0; i < 2000; i++ {
+		require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(value)))
+	}
+	db.AddTable("t", table)
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: new(auth.None)})
+
+	s, err := NewDefaultServer(Config{Protocol: "tcp", Address: "localhost:0", Auth: new(auth.None)}, e)
+	require.NoError(err)
+	go s.Start()
+	defer s.Close()
+
+	sqlDB, err := gosql.Open("mysql", "root:@tcp("+s.Listener.Addr().String()+")/mydb")
+	require.NoError(err)
+	defer sqlDB.Close()
+
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	require.NoError(err)
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "SET @@net_write_timeout = 1")
+	require.NoError(err)
+
+	// The client doesn't read the rows of the query, which are far more
+	// than the buffers of the sockets hold, so the server stops reading them
+	// and aborts the connection.
+	rows, err := conn.QueryContext(ctx, "SELECT s FROM t")
+	require.NoError(err)
+
+	status := e.Catalog.GlobalStatus
+	for i := 0; status.Get(sql.StatusAbortedClients) == 0; i++ {
+		require.True(i < 500, "the connection was not aborted")
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for rows.Next() {
+	}
+	require.Error(rows.Err())
+	require.Equal(int64(1), status.Get(sql.StatusAbortedClients))
+
+	// The rows of the clients reading them are sent.
+	rows, err = sqlDB.Query("SELECT s FROM t")
+	require.NoError(err)
+	var n int
+	for rows.Next() {
+		n++
+	}
+	require.NoError(rows.Err())
+	require.Equal(2000, n)
+}
+
+// taggedConn is a connection tagged by a connection middleware.
+type taggedConn struct {
+	net.Conn
+	tag string
+}
+
+func TestServerListenerMiddleware(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: new(auth.None)})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+
+	var accepted, rejecting int32 = 0, 1
+	tags := make(chan string, 2)
+	sb := func(ctx context.Context, c *mysql.Conn, addr string) (sql.Session, *sql.IndexRegistry, *sql.ViewRegistry, error) {
+		if tc, ok := NetConn(c).(*taggedConn); ok {
+			tags <- tc.tag
+		}
+		return DefaultSessionBuilder(ctx, c, addr)
+	}
+
+	s, err := NewServer(Config{
+		Listener: l,
+		Auth:     new(auth.None),
+		ConnMiddleware: []ConnMiddleware{
+			func(conn net.Conn) (net.Conn, error) {
+				if atomic.LoadInt32(&rejecting) == 1 {
+					return nil, fmt.Errorf("connection %s not allowed", conn.RemoteAddr())
+				}
+				return conn, nil
+			},
+			func(conn net.Conn) (net.Conn, error) {
+				return &taggedConn{Conn: conn, tag: fmt.Sprintf("conn-%d", atomic.AddInt32(&accepted, 1))}, nil
+			},
+		},
+	}, e, sb)
+	require.NoError(err)
+	go s.Start()
+	defer s.Close()
+	require.Equal(l.Addr(), s.Listener.Addr())
+
+	dsn := "root:@tcp(" + l.Addr().String() + ")/mydb"
+
+	// The connections are rejected until they're allowed.
+	db, err := gosql.Open("mysql", dsn)
+	require.NoError(err)
+	require.Error(db.Ping())
+	require.NoError(db.Close())
+	atomic.StoreInt32(&rejecting, 0)
+
+	db, err = gosql.Open("mysql", dsn)
+	require.NoError(err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	var one int
+	require.NoError(db.QueryRow("SELECT 1").Scan(&one))
+	require.Equal("conn-1", <-tags)
+
+	// The connection checker is given the socket, not the connection
+	// wrapping it.
+	s.h.mu.Lock()
+	for _, c := range s.h.c {
+		require.IsType(&net.TCPConn{}, c.NetConn)
+	}
+	s.h.mu.Unlock()
+
+	// The listener is closed with the server.
+	require.NoError(s.Close())
+	_, err = net.DialTimeout("tcp", l.Addr().String(), 100*time.Millisecond)
+	require.Error(err)
+}
+
+func TestServerBinlogDump(t *testing.T) {
+	for _, secure := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tls %t", secure), func(t *testing.T) {
+			testServerBinlogDump(t, secure)
+		})
+	}
+}
+
+func testServerBinlogDump(t *testing.T, secure bool) {
+	require := require.New(t)
+
+	log, err := binlog.NewLog(binlog.Config{ServerID: 7, ServerUUID: "3e11fa47-71ca-11e1-9e33-c80aa9429562"})
+	require.NoError(err)
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: new(auth.None), Binlog: log})
+
+	cfg := Config{Protocol: "tcp", Address: "localhost:0", Auth: new(auth.None)}
+	if secure {
+		cfg.TLS = testTLSConfig(t)
+	}
+	s, err := NewDefaultServer(cfg, e)
+	require.NoError(err)
+	go s.Start()
+	defer s.Close()
+
+	db, err := gosql.Open("mysql", "root:@tcp("+s.Listener.Addr().String()+")/mydb")
+	require.NoError(err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE t (i INT PRIMARY KEY)")
+	require.NoError(err)
+
+	addr := s.Listener.Addr().(*net.TCPAddr)
+	params := &mysql.ConnParams{Host: addr.IP.String(), Port: addr.Port, Uname: "root"}
+	if secure {
+		params.EnableSSL()
+		params.SslCa, params.ServerName = cfg.TLS.CertFile, "localhost"
+	}
+
+	// A dump that doesn't block ends once the replica has all the events.
+	conn, err := mysql.Connect(context.Background(), params)
+	require.NoError(err)
+	defer conn.Close()
+	require.NoError(conn.WriteComBinlogDump(2, "", 4, binlogDumpNonBlock))
+
+	var events []mysql.BinlogEvent
+	for {
+		event, err := conn.ReadBinlogEvent()
+		if err != nil {
+			require.Contains(err.Error(), io.EOF.Error())
+			break
+		}
+		events = append(events, event)
+	}
+	require.Len(events, 5)
+	require.True(events[0].IsRotate())
+	require.True(events[1].IsFormatDescription())
+	require.True(events[3].IsGTID())
+	require.True(events[4].IsQuery())
+
+	// A dump that blocks sends the events as they are written.
+	conn, err = mysql.Connect(context.Background(), params)
+	require.NoError(err)
+	defer conn.Close()
+	require.NoError(conn.WriteComBinlogDump(2, "binlog.000001", 4, 0))
+	for i := 0; i < 5; i++ {
+		_, err := conn.ReadBinlogEvent()
+		require.NoError(err)
+	}
+
+	_, err = db.Exec("INSERT INTO t VALUES (1)")
+	require.NoError(err)
+	event, err := conn.ReadBinlogEvent()
+	require.NoError(err)
+	require.True(event.IsGTID())
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"net"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/auth"
+)
+
+// ErrSocketInUse is returned when the socket file of a server is used by a
+// running server.
+var ErrSocketInUse = errors.NewKind("socket %s is in use by another server")
+
+// ErrNotSocket is returned when the path of the socket of a server is a file
+// that is not a socket.
+var ErrNotSocket = errors.NewKind("%s exists and is not a socket")
+
+var errListenerClosed = errors.NewKind("listener closed")
+
+// listen listens on the address given, which is the path of a socket for the
+// unix protocol.
+func listen(protocol, address string) (net.Listener, error) {
+	if protocol == "unix" {
+		return listenSocket(address)
+	}
+	return net.Listen(protocol, address)
+}
+
+// listenSocket listens on a Unix domain socket. The socket file left by a
+// server that didn't stop cleanly is replaced, but not the one of a running
+// server. The socket file is removed once the listener is closed.
+func listenSocket(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, ErrNotSocket.New(path)
+		}
+
+		if c, err := net.Dial("unix", path); err == nil {
+			c.Close()
+			return nil, ErrSocketInUse.New(path)
+		}
+
+		logrus.Infof("removing stale socket file %s", path)
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clients of all the users of the system can connect, as with the socket
+	// of MySQL, since they are authenticated by the server.
+	if err := os.Chmod(path, 0777); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// peerAddr returns the remote address of a client connected through a Unix
+// domain socket, with its credentials, if they can be read.
+func peerAddr(c *net.UnixConn) *auth.PeerAddr {
+	cred, err := peerCredentials(c)
+	if err != nil {
+		logrus.Debugf("unable to read the credentials of the client of socket %s: %s", c.LocalAddr(), err)
+	}
+	return &auth.PeerAddr{Addr: c.RemoteAddr(), Credentials: cred}
+}
+
+// multiListener accepts the connections of several listeners. Its address is
+// the one of the first listener.
+type multiListener struct {
+	listeners []net.Listener
+	accepted  chan acceptResult
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type acceptResult struct {
+	conn net.Conn
+	err  error
+}
+
+func newMultiListener(listeners ...net.Listener) *multiListener {
+	l := &multiListener{
+		listeners: listeners,
+		accepted:  make(chan acceptResult),
+		closed:    make(chan struct{}),
+	}
+
+	for _, ln := range listeners {
+		go l.accept(ln)
+	}
+	return l
+}
+
+func (l *multiListener) accept(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		select {
+		case l.accepted <- acceptResult{conn, err}:
+		case <-l.closed:
+			if conn != nil {
+				conn.Close()
+			}
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Accept implements the net.Listener interface.
+func (l *multiListener) Accept() (net.Conn, error) {
+	select {
+	case r := <-l.accepted:
+		return r.conn, r.err
+	case <-l.closed:
+		return nil, errListenerClosed.New()
+	}
+}
+
+// Close implements the net.Listener interface.
+func (l *multiListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.closed)
+		for _, ln := range l.listeners {
+			if e := ln.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	})
+	return err
+}
+
+// Addr implements the net.Listener interface.
+func (l *multiListener) Addr() net.Addr {
+	return l.listeners[0].Addr()
+}
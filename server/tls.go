@@ -0,0 +1,101 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"sync"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// ErrInvalidTLSConfig is returned when the TLS configuration of the server can't be loaded.
+var ErrInvalidTLSConfig = errors.NewKind("invalid TLS configuration: %s")
+
+// TLSConfig configures TLS connections to the server. When set, the server advertises SSL support in the handshake
+// and clients can upgrade their connections to TLS.
+type TLSConfig struct {
+	// CertFile is the path of the PEM encoded certificate of the server.
+	CertFile string
+	// KeyFile is the path of the PEM encoded private key of the server.
+	KeyFile string
+	// CAFile is the path of a PEM bundle with the certificate authorities
+	// client certificates are verified against. If empty, client
+	// certificates are not requested.
+	CAFile string
+	// MinVersion is the minimum TLS version accepted, e.g. tls.VersionTLS12.
+	// Defaults to TLS 1.2.
+	MinVersion uint16
+	// RequireSecureTransport rejects clients not using TLS.
+	RequireSecureTransport bool
+}
+
+// tlsLoader loads the certificates of a TLSConfig and provides them to new connections, so that they can be
+// reloaded without restarting the listener.
+type tlsLoader struct {
+	cfg TLSConfig
+
+	mu      sync.RWMutex
+	current *tls.Config
+}
+
+func newTLSLoader(cfg TLSConfig) (*tlsLoader, error) {
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	l := &tlsLoader{cfg: cfg}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// load reads the certificates from disk, replacing the ones given to new connections. If there's any error, the
+// certificates in use are kept.
+func (l *tlsLoader) load() error {
+	cert, err := tls.LoadX509KeyPair(l.cfg.CertFile, l.cfg.KeyFile)
+	if err != nil {
+		return ErrInvalidTLSConfig.New(err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   l.cfg.MinVersion,
+	}
+
+	if l.cfg.CAFile != "" {
+		pem, err := ioutil.ReadFile(l.cfg.CAFile)
+		if err != nil {
+			return ErrInvalidTLSConfig.New(err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return ErrInvalidTLSConfig.New("no certificates found in " + l.cfg.CAFile)
+		}
+
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	l.mu.Lock()
+	l.current = config
+	l.mu.Unlock()
+
+	return nil
+}
+
+// config returns the TLS configuration given to the listener, which hands out the last certificates loaded to
+// each new connection.
+func (l *tlsLoader) config() *tls.Config {
+	return &tls.Config{
+		MinVersion: l.cfg.MinVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			l.mu.RLock()
+			defer l.mu.RUnlock()
+			return l.current, nil
+		},
+	}
+}
@@ -0,0 +1,314 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	gosql "database/sql"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+)
+
+// writeCertificate writes a self-signed certificate for localhost with the serial number given, and its key, to the
+// files given.
+func writeCertificate(t *testing.T, certFile, keyFile string, serial int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+}
+
+// testCA is a certificate authority issuing certificates for tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert, key}
+}
+
+// issue returns a client certificate with the common name given signed by the CA.
+func (ca *testCA) issue(t *testing.T, commonName string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func (ca *testCA) write(t *testing.T, file string) {
+	require.NoError(t, ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0600))
+}
+
+func tlsTestServer(t *testing.T, a auth.Auth, tlsConfig *TLSConfig) *Server {
+	catalog := sql.NewCatalog()
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: a})
+
+	s, err := NewDefaultServer(Config{
+		Protocol: "tcp",
+		Address:  "localhost:0",
+		Auth:     a,
+		TLS:      tlsConfig,
+	}, e)
+	require.NoError(t, err)
+
+	go s.Start()
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+// connect connects to the server as the user given, using TLS if tlsName is not empty, and returns the serial number
+// of the certificate presented by the server. The client certificates given are presented to the server.
+func connect(s *Server, user, tlsName string, clientCerts ...tls.Certificate) (*big.Int, error) {
+	var serial *big.Int
+	if tlsName != "" {
+		err := mysql.RegisterTLSConfig(tlsName, &tls.Config{
+			Certificates:       clientCerts,
+			InsecureSkipVerify: true,
+			VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+				cert, err := x509.ParseCertificate(rawCerts[0])
+				if err != nil {
+					return err
+				}
+				serial = cert.SerialNumber
+				return nil
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer mysql.DeregisterTLSConfig(tlsName)
+	}
+
+	dsn := user + ":@tcp(" + s.Listener.Addr().String() + ")/"
+	if tlsName != "" {
+		dsn += "?tls=" + tlsName
+	}
+
+	db, err := gosql.Open("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return serial, db.Ping()
+}
+
+func TestServerTLS(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCertificate(t, certFile, keyFile, 1)
+
+	s := tlsTestServer(t, new(auth.None), &TLSConfig{CertFile: certFile, KeyFile: keyFile})
+
+	_, err = connect(s, "root", "")
+	require.NoError(err)
+
+	serial, err := connect(s, "root", "tls-test")
+	require.NoError(err)
+	require.Equal(int64(1), serial.Int64())
+
+	// New connections get the new certificate once reloaded.
+	writeCertificate(t, certFile, keyFile, 2)
+	serial, err = connect(s, "root", "tls-test")
+	require.NoError(err)
+	require.Equal(int64(1), serial.Int64())
+
+	require.NoError(s.ReloadTLS())
+	serial, err = connect(s, "root", "tls-test")
+	require.NoError(err)
+	require.Equal(int64(2), serial.Int64())
+
+	// Invalid certificates are not loaded.
+	require.NoError(ioutil.WriteFile(certFile, []byte("nope"), 0600))
+	require.True(ErrInvalidTLSConfig.Is(s.ReloadTLS()))
+	serial, err = connect(s, "root", "tls-test")
+	require.NoError(err)
+	require.Equal(int64(2), serial.Int64())
+}
+
+func TestServerRequireSecureTransport(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCertificate(t, certFile, keyFile, 1)
+
+	s := tlsTestServer(t, new(auth.None), &TLSConfig{CertFile: certFile, KeyFile: keyFile, RequireSecureTransport: true})
+
+	_, err = connect(s, "root", "")
+	require.Error(err)
+
+	_, err = connect(s, "root", "tls-test")
+	require.NoError(err)
+}
+
+func TestServerClearTextWithoutTLS(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCertificate(t, certFile, keyFile, 1)
+
+	s := tlsTestServer(t, new(clearTextAuth), &TLSConfig{CertFile: certFile, KeyFile: keyFile})
+
+	ping := func(params string) error {
+		db, err := gosql.Open("mysql", "root:pass@tcp("+s.Listener.Addr().String()+")/?allowCleartextPasswords=true"+params)
+		require.NoError(err)
+		defer db.Close()
+		return db.Ping()
+	}
+
+	// Passwords are only sent in clear text over TLS.
+	err = ping("")
+	require.Error(err)
+	require.Contains(err.Error(), "Cannot use clear text authentication over non-SSL connections")
+
+	require.NoError(ping("&tls=skip-verify"))
+}
+
+func TestServerRequireSSLPerUser(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCertificate(t, certFile, keyFile, 1)
+
+	a := auth.NewNativeStore(auth.NewMemoryUserStore(
+		auth.User{Name: "plain", Host: "%", Permissions: auth.AllPermissions},
+		auth.User{Name: "secure", Host: "%", Permissions: auth.AllPermissions, Require: sql.TLSRequirement{SSL: true}},
+	))
+	s := tlsTestServer(t, a, &TLSConfig{CertFile: certFile, KeyFile: keyFile})
+
+	_, err = connect(s, "plain", "")
+	require.NoError(err)
+
+	_, err = connect(s, "secure", "")
+	require.Error(err)
+	require.Contains(err.Error(), "Access denied")
+
+	_, err = connect(s, "secure", "tls-test")
+	require.NoError(err)
+}
+
+func TestServerX509(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile, caFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), filepath.Join(dir, "ca.pem")
+	writeCertificate(t, certFile, keyFile, 1)
+	ca := newTestCA(t)
+	ca.write(t, caFile)
+
+	a, err := auth.NewX509(auth.X509User{Name: "bob", Subject: "/CN=bob", Permissions: auth.AllPermissions})
+	require.NoError(err)
+	s := tlsTestServer(t, a, &TLSConfig{CertFile: certFile, KeyFile: keyFile, CAFile: caFile})
+
+	_, err = connect(s, "bob", "")
+	require.Error(err)
+
+	_, err = connect(s, "bob", "tls-test")
+	require.Error(err)
+
+	_, err = connect(s, "bob", "tls-test", ca.issue(t, "alice"))
+	require.Error(err)
+
+	_, err = connect(s, "bob", "tls-test", newTestCA(t).issue(t, "bob"))
+	require.Error(err)
+
+	_, err = connect(s, "bob", "tls-test", ca.issue(t, "bob"))
+	require.NoError(err)
+}
+
+func TestInvalidTLSConfig(t *testing.T) {
+	catalog := sql.NewCatalog()
+	e := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	_, err := NewDefaultServer(Config{
+		Protocol: "tcp",
+		Address:  "localhost:0",
+		Auth:     new(auth.None),
+		TLS:      &TLSConfig{CertFile: "missing.pem", KeyFile: "missing.pem"},
+	}, e)
+	require.True(t, ErrInvalidTLSConfig.Is(err))
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+var (
+	// sqlCommentRegex matches the comments of a query.
+	sqlCommentRegex = regexp.MustCompile(`(?s)/\*(.*?)\*/`)
+	// sqlCommentTagRegex matches the key='value' tags of a comment, as
+	// written by sqlcommenter, with URL encoded keys and values.
+	sqlCommentTagRegex = regexp.MustCompile(`([^\s=,]+)\s*=\s*'((?:[^'\\]|\\.)*)'`)
+)
+
+// queryTraceCarrier returns the tags of the comments of a query, such as the
+// traceparent and tracestate tags OpenTelemetry instrumentations add to the
+// queries they send, following sqlcommenter, to propagate their traces.
+func queryTraceCarrier(query string) opentracing.TextMapCarrier {
+	if !strings.Contains(query, "/*") {
+		return nil
+	}
+
+	var carrier opentracing.TextMapCarrier
+	for _, comment := range sqlCommentRegex.FindAllStringSubmatch(query, -1) {
+		for _, tag := range sqlCommentTagRegex.FindAllStringSubmatch(comment[1], -1) {
+			key, err := url.QueryUnescape(tag[1])
+			if err != nil {
+				continue
+			}
+
+			value, err := url.QueryUnescape(strings.ReplaceAll(tag[2], `\'`, `'`))
+			if err != nil {
+				continue
+			}
+
+			if carrier == nil {
+				carrier = make(opentracing.TextMapCarrier)
+			}
+			carrier[key] = value
+		}
+	}
+
+	return carrier
+}
+
+// startQuerySpan starts the root span of a query. If the query carries the
+// context of a span of the client in its comments, and the tracer can
+// extract it, the span is its child.
+func startQuerySpan(tracer opentracing.Tracer, query string) opentracing.Span {
+	opts := []opentracing.StartSpanOption{ext.SpanKindRPCServer}
+	if carrier := queryTraceCarrier(query); carrier != nil {
+		if parent, err := tracer.Extract(opentracing.TextMap, carrier); err == nil {
+			opts = append(opts, opentracing.ChildOf(parent))
+		}
+	}
+
+	span := tracer.StartSpan("query", opts...)
+	if query != "" {
+		ext.DBType.Set(span, "sql")
+		ext.DBStatement.Set(span, query)
+	}
+	return span
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/tracing"
+)
+
+func TestQueryTraceCarrier(t *testing.T) {
+	require := require.New(t)
+
+	require.Nil(queryTraceCarrier("SELECT 1"))
+	require.Nil(queryTraceCarrier("SELECT /* no tags */ 1"))
+
+	require.Equal(opentracing.TextMapCarrier{
+		"traceparent": "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01",
+		"tracestate":  "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7",
+		"route":       "/it's",
+	}, queryTraceCarrier("SELECT * FROM t "+
+		"/*route='%2Fit\\'s',traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01',"+
+		"tracestate='congo%3Dt61rcWkgMzE%2Crojo%3D00f067aa0ba902b7'*/"))
+}
+
+func TestHandlerTraceContext(t *testing.T) {
+	require := require.New(t)
+	e := setupMemDB(require)
+
+	tracer := mocktracer.New()
+	handler := NewHandler(
+		e,
+		NewSessionManager(
+			testSessionBuilder,
+			tracer,
+			func(db string) bool { return db == "test" },
+			sql.NewMemoryManager(nil),
+			"foo",
+		),
+		0,
+	)
+
+	c := newConn(1)
+	handler.NewConnection(c)
+	require.NoError(handler.ComInitDB(c, "test"))
+
+	query := "SELECT * FROM test /*mockpfx-ids-traceid='42',mockpfx-ids-spanid='7',mockpfx-ids-sampled='true'*/"
+	require.NoError(handler.ComQuery(c, query, func(*sqltypes.Result) error {
+		return nil
+	}))
+
+	spans := tracer.FinishedSpans()
+	require.NotEmpty(spans)
+
+	ids := make(map[int]*mocktracer.MockSpan)
+	var root *mocktracer.MockSpan
+	var rules int
+	for _, s := range spans {
+		ids[s.SpanContext.SpanID] = s
+		require.Equal(42, s.SpanContext.TraceID, s.OperationName)
+		if s.ParentID == 7 {
+			root = s
+		}
+		if s.OperationName == "rule" {
+			rules++
+		}
+	}
+
+	require.NotNil(root)
+	require.Equal("query", root.OperationName)
+	require.Equal(query, root.Tag("db.statement"))
+	require.NotZero(rules)
+
+	// All the spans descend from the root span.
+	for _, s := range spans {
+		for s != root {
+			parent, ok := ids[s.ParentID]
+			require.True(ok, "parent of span %s not found", s.OperationName)
+			s = parent
+		}
+	}
+}
+
+// otelTracer is a tracing.Tracer that records the spans it starts.
+type otelTracer struct {
+	mu    sync.Mutex
+	spans []*otelSpan
+}
+
+func (t *otelTracer) Start(_ context.Context, name string, parent tracing.SpanContext, _ time.Time) tracing.Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := &otelSpan{name: name, parent: parent, context: parent}
+	s.context.Remote = false
+	s.context.SpanID = [8]byte{byte(len(t.spans) + 1)}
+	t.spans = append(t.spans, s)
+	return s
+}
+
+type otelSpan struct {
+	name            string
+	parent, context tracing.SpanContext
+}
+
+func (s *otelSpan) SpanContext() tracing.SpanContext        { return s.context }
+func (s *otelSpan) SetName(name string)                     { s.name = name }
+func (s *otelSpan) SetAttribute(string, interface{})        {}
+func (s *otelSpan) AddEvent(string, map[string]interface{}) {}
+func (s *otelSpan) RecordError(error)                       {}
+func (s *otelSpan) End(time.Time)                           {}
+
+func TestHandlerOpenTelemetryTraceContext(t *testing.T) {
+	require := require.New(t)
+	e := setupMemDB(require)
+
+	tracer := new(otelTracer)
+	handler := NewHandler(
+		e,
+		NewSessionManager(
+			testSessionBuilder,
+			tracing.NewTracer(tracer),
+			func(db string) bool { return db == "test" },
+			sql.NewMemoryManager(nil),
+			"foo",
+		),
+		0,
+	)
+
+	c := newConn(1)
+	handler.NewConnection(c)
+	require.NoError(handler.ComInitDB(c, "test"))
+
+	traceparent := "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01"
+	query := "SELECT * FROM test /*traceparent='" + traceparent + "'*/"
+	started := len(tracer.spans)
+	require.NoError(handler.ComQuery(c, query, func(*sqltypes.Result) error {
+		return nil
+	}))
+
+	client, err := tracing.ParseTraceparent(traceparent)
+	require.NoError(err)
+
+	// All the spans of the query are in the trace of the client.
+	spans := tracer.spans[started:]
+	ids := make(map[[8]byte]*otelSpan)
+	var root *otelSpan
+	var rules int
+	for _, s := range spans {
+		require.Equal(client.TraceID, s.context.TraceID, s.name)
+		ids[s.context.SpanID] = s
+		if s.parent.Remote {
+			root = s
+		}
+		if s.name == "rule" {
+			rules++
+		}
+	}
+
+	require.NotNil(root)
+	require.Equal("query", root.name)
+	require.Equal(client.SpanID, root.parent.SpanID)
+	require.NotZero(rules)
+
+	// All the spans descend from the root span.
+	for _, s := range spans {
+		for s != root {
+			parent, ok := ids[s.parent.SpanID]
+			require.True(ok, "parent of span %s not found", s.name)
+			s = parent
+		}
+	}
+}
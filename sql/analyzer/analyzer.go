@@ -5,7 +5,9 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"time"
 
+	"github.com/go-kit/kit/metrics/discard"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pmezard/go-difflib/difflib"
 	"github.com/sirupsen/logrus"
@@ -27,21 +29,93 @@ var ErrInAnalysis = errors.NewKind("error in analysis: %s")
 // ErrInvalidNodeType is thrown when the analyzer can't handle a particular kind of node type
 var ErrInvalidNodeType = errors.NewKind("%s: invalid node of type: %T")
 
+// ErrRuleAlreadyExists is returned when adding a rule with the same name as an existing one
+var ErrRuleAlreadyExists = errors.NewKind("analyzer rule %q already exists")
+
+// ErrRuleNotFound is returned when referencing a rule that does not exist
+var ErrRuleNotFound = errors.NewKind("analyzer rule %q not found")
+
+var (
+	// AnalyzeHistogram describes the time it takes to analyze queries, not counting their subqueries separately.
+	AnalyzeHistogram = discard.NewHistogram()
+
+	// RuleHistogram describes the time it takes to apply each rule, labeled by rule.
+	RuleHistogram = discard.NewHistogram()
+)
+
+// RulePhase identifies one of the batches of rules run by the analyzer. Phases are run in the order they are declared.
+type RulePhase int
+
+const (
+	// PreAnalyzePhase contains custom rules run before any of the default rules.
+	PreAnalyzePhase RulePhase = iota
+	// OnceBeforePhase contains rules run once before the resolution rules, such as resolving tables and views.
+	OnceBeforePhase
+	// ResolutionPhase contains rules that resolve columns, functions and so on, run repeatedly until the plan stops
+	// changing.
+	ResolutionPhase
+	// OptimizationPhase contains rules run once after the plan is resolved, such as join planning and pushdown.
+	OptimizationPhase
+	// PostOptimizationPhase contains custom rules run after the optimization rules, repeatedly until the plan stops
+	// changing.
+	PostOptimizationPhase
+	// PreValidationPhase contains custom rules run once before the validation rules.
+	PreValidationPhase
+	// ValidationPhase contains the rules that validate the final plan.
+	ValidationPhase
+	// PostValidationPhase contains custom rules run once after the validation rules.
+	PostValidationPhase
+	// AfterAllPhase contains rules run once after every other rule, such as process tracking.
+	AfterAllPhase
+
+	numRulePhases
+)
+
+var rulePhaseBatches = [numRulePhases]struct {
+	desc       string
+	iterations int
+}{
+	PreAnalyzePhase:       {"pre-analyzer", maxAnalysisIterations},
+	OnceBeforePhase:       {"once-before", 1},
+	ResolutionPhase:       {"default-rules", maxAnalysisIterations},
+	OptimizationPhase:     {"once-after", 1},
+	PostOptimizationPhase: {"post-analyzer", maxAnalysisIterations},
+	PreValidationPhase:    {"pre-validation", 1},
+	ValidationPhase:       {"validation", 1},
+	PostValidationPhase:   {"post-validation", 1},
+	AfterAllPhase:         {"after-all", 1},
+}
+
+// String returns the description of the batch run for this phase.
+func (p RulePhase) String() string {
+	if p < 0 || p >= numRulePhases {
+		return fmt.Sprintf("RulePhase(%d)", int(p))
+	}
+	return rulePhaseBatches[p].desc
+}
+
 // Builder provides an easy way to generate Analyzer with custom rules and options.
+// Rules are identified by name, which must be unique within a phase. Rules can be added to any phase, positioned relative to an existing
+// rule, replaced or removed. Any error in these operations is recorded, leaving the rules unchanged, and is reported by Err
+// and BuildE.
 type Builder struct {
-	preAnalyzeRules     []Rule
-	postAnalyzeRules    []Rule
-	preValidationRules  []Rule
-	postValidationRules []Rule
-	catalog             *sql.Catalog
-	debug               bool
-	parallelism         int
+	phases      [numRulePhases][]Rule
+	catalog     *sql.Catalog
+	debug       bool
+	parallelism int
+	err         error
 }
 
 // NewBuilder creates a new Builder from a specific catalog.
 // This builder allow us add custom Rules and modify some internal properties.
 func NewBuilder(c *sql.Catalog) *Builder {
-	return &Builder{catalog: c}
+	ab := &Builder{catalog: c}
+	ab.phases[OnceBeforePhase] = append([]Rule(nil), OnceBeforeDefault...)
+	ab.phases[ResolutionPhase] = append([]Rule(nil), DefaultRules...)
+	ab.phases[OptimizationPhase] = append([]Rule(nil), OnceAfterDefault...)
+	ab.phases[ValidationPhase] = append([]Rule(nil), DefaultValidationRules...)
+	ab.phases[AfterAllPhase] = append([]Rule(nil), OnceAfterAll...)
+	return ab
 }
 
 // WithDebug activates debug on the Analyzer.
@@ -59,85 +133,184 @@ func (ab *Builder) WithParallelism(parallelism int) *Builder {
 
 // AddPreAnalyzeRule adds a new rule to the analyze before the standard analyzer rules.
 func (ab *Builder) AddPreAnalyzeRule(name string, fn RuleFunc) *Builder {
-	ab.preAnalyzeRules = append(ab.preAnalyzeRules, Rule{name, fn})
-
-	return ab
+	return ab.appendRule(PreAnalyzePhase, name, fn)
 }
 
 // AddPostAnalyzeRule adds a new rule to the analyzer after standard analyzer rules.
 func (ab *Builder) AddPostAnalyzeRule(name string, fn RuleFunc) *Builder {
-	ab.postAnalyzeRules = append(ab.postAnalyzeRules, Rule{name, fn})
-
-	return ab
+	return ab.appendRule(PostOptimizationPhase, name, fn)
 }
 
 // AddPreValidationRule adds a new rule to the analyzer before standard validation rules.
 func (ab *Builder) AddPreValidationRule(name string, fn RuleFunc) *Builder {
-	ab.preValidationRules = append(ab.preValidationRules, Rule{name, fn})
-
-	return ab
+	return ab.appendRule(PreValidationPhase, name, fn)
 }
 
 // AddPostValidationRule adds a new rule to the analyzer after standard validation rules.
 func (ab *Builder) AddPostValidationRule(name string, fn RuleFunc) *Builder {
-	ab.postValidationRules = append(ab.postValidationRules, Rule{name, fn})
+	return ab.appendRule(PostValidationPhase, name, fn)
+}
 
+// AddRule appends a new rule to the end of the given phase.
+func (ab *Builder) AddRule(phase RulePhase, name string, fn RuleFunc) *Builder {
+	if !ab.checkNewRule(phase, name) {
+		return ab
+	}
+
+	return ab.appendRule(phase, name, fn)
+}
+
+// appendRule appends a new rule to the end of the given phase, even if it already has one with the same name, as the
+// AddPre* and AddPost* methods always did.
+func (ab *Builder) appendRule(phase RulePhase, name string, fn RuleFunc) *Builder {
+	ab.phases[phase] = append(ab.phases[phase], Rule{name, fn})
+	return ab
+}
+
+// AddRuleBefore adds a new rule immediately before the existing rule named, in the same phase. If the existing rule
+// runs in more than one phase, the new rule is added before each occurrence.
+func (ab *Builder) AddRuleBefore(existing string, name string, fn RuleFunc) *Builder {
+	return ab.insertRule(existing, Rule{name, fn}, 0)
+}
+
+// AddRuleAfter adds a new rule immediately after the existing rule named, in the same phase. If the existing rule runs
+// in more than one phase, the new rule is added after each occurrence.
+func (ab *Builder) AddRuleAfter(existing string, name string, fn RuleFunc) *Builder {
+	return ab.insertRule(existing, Rule{name, fn}, 1)
+}
+
+// ReplaceRule replaces the implementation of the existing rule named, keeping its name and position.
+func (ab *Builder) ReplaceRule(name string, fn RuleFunc) *Builder {
+	found := false
+	for p := range ab.phases {
+		for i := range ab.phases[p] {
+			if ab.phases[p][i].Name == name {
+				ab.phases[p][i].Apply = fn
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		ab.setErr(ErrRuleNotFound.New(name))
+	}
 	return ab
 }
 
+// RemoveRule removes the rule named from every phase it runs in.
+func (ab *Builder) RemoveRule(name string) *Builder {
+	found := false
+	for p := range ab.phases {
+		rules := ab.phases[p][:0:0]
+		for _, r := range ab.phases[p] {
+			if r.Name == name {
+				found = true
+				continue
+			}
+			rules = append(rules, r)
+		}
+		ab.phases[p] = rules
+	}
+
+	if !found {
+		ab.setErr(ErrRuleNotFound.New(name))
+	}
+	return ab
+}
+
+// Rules returns the names of the rules that will be run in the phase given, in order.
+func (ab *Builder) Rules(phase RulePhase) []string {
+	names := make([]string, len(ab.phases[phase]))
+	for i, r := range ab.phases[phase] {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// Err returns the first error encountered while configuring the builder, if any.
+func (ab *Builder) Err() error {
+	return ab.err
+}
+
+func (ab *Builder) insertRule(existing string, rule Rule, offset int) *Builder {
+	// The phases are only changed once the rule is known to be insertable in all of them.
+	var phases []int
+	for p := range ab.phases {
+		if !ab.hasRule(RulePhase(p), existing) {
+			continue
+		}
+		if !ab.checkNewRule(RulePhase(p), rule.Name) {
+			return ab
+		}
+		phases = append(phases, p)
+	}
+
+	if len(phases) == 0 {
+		ab.setErr(ErrRuleNotFound.New(existing))
+		return ab
+	}
+
+	for _, p := range phases {
+		var rules []Rule
+		for _, r := range ab.phases[p] {
+			if r.Name == existing {
+				if offset == 0 {
+					rules = append(rules, rule, r)
+				} else {
+					rules = append(rules, r, rule)
+				}
+				continue
+			}
+			rules = append(rules, r)
+		}
+		ab.phases[p] = rules
+	}
+	return ab
+}
+
+func (ab *Builder) checkNewRule(phase RulePhase, name string) bool {
+	if ab.hasRule(phase, name) {
+		ab.setErr(ErrRuleAlreadyExists.New(name))
+		return false
+	}
+	return true
+}
+
+func (ab *Builder) hasRule(phase RulePhase, name string) bool {
+	for _, r := range ab.phases[phase] {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (ab *Builder) setErr(err error) {
+	if ab.err == nil {
+		ab.err = err
+	}
+}
+
 func init() {
 	logrus.SetFormatter(simpleLogFormatter{})
 }
 
-// Build creates a new Analyzer using all previous data setted to the Builder
+// Build creates a new Analyzer using all previous data setted to the Builder. The operations that failed while
+// configuring the builder are left out, see Err and BuildE.
 func (ab *Builder) Build() *Analyzer {
 	_, debug := os.LookupEnv(debugAnalyzerKey)
-	var batches = []*Batch{
-		&Batch{
-			Desc:       "pre-analyzer",
-			Iterations: maxAnalysisIterations,
-			Rules:      ab.preAnalyzeRules,
-		},
-		&Batch{
-			Desc:       "once-before",
-			Iterations: 1,
-			Rules:      OnceBeforeDefault,
-		},
-		&Batch{
-			Desc:       "default-rules",
-			Iterations: maxAnalysisIterations,
-			Rules:      DefaultRules,
-		},
-		&Batch{
-			Desc:       "once-after",
-			Iterations: 1,
-			Rules:      OnceAfterDefault,
-		},
-		&Batch{
-			Desc:       "post-analyzer",
-			Iterations: maxAnalysisIterations,
-			Rules:      ab.postAnalyzeRules,
-		},
-		&Batch{
-			Desc:       "pre-validation",
-			Iterations: 1,
-			Rules:      ab.preValidationRules,
-		},
-		&Batch{
-			Desc:       "validation",
-			Iterations: 1,
-			Rules:      DefaultValidationRules,
-		},
-		&Batch{
-			Desc:       "post-validation",
-			Iterations: 1,
-			Rules:      ab.postValidationRules,
-		},
-		&Batch{
-			Desc:       "after-all",
-			Iterations: 1,
-			Rules:      OnceAfterAll,
-		},
+	batches := make([]*Batch, numRulePhases)
+	for p, b := range rulePhaseBatches {
+		batches[p] = &Batch{
+			Desc:       b.desc,
+			Iterations: b.iterations,
+			Rules:      ab.phases[p],
+		}
+	}
+
+	fks := &foreignKeyCache{}
+	if ab.catalog != nil {
+		ab.catalog.AddSchemaListener(fks)
 	}
 
 	return &Analyzer{
@@ -146,9 +319,18 @@ func (ab *Builder) Build() *Analyzer {
 		Batches:      batches,
 		Catalog:      ab.catalog,
 		Parallelism:  ab.parallelism,
+		foreignKeys:  fks,
 	}
 }
 
+// BuildE creates a new Analyzer like Build, or returns the first error encountered while configuring the builder.
+func (ab *Builder) BuildE() (*Analyzer, error) {
+	if ab.err != nil {
+		return nil, ab.err
+	}
+	return ab.Build(), nil
+}
+
 // Analyzer analyzes nodes of the execution plan and applies rules and validations
 // to them.
 type Analyzer struct {
@@ -163,6 +345,8 @@ type Analyzer struct {
 	Batches []*Batch
 	// Catalog of databases and registered functions.
 	Catalog *sql.Catalog
+	// foreignKeys caches the foreign keys declared by the tables of the databases of the catalog.
+	foreignKeys *foreignKeyCache
 }
 
 // NewDefault creates a default Analyzer instance with all default Rules and configuration.
@@ -266,6 +450,12 @@ func (a *Analyzer) Analyze(ctx *sql.Context, n sql.Node, scope *Scope) (sql.Node
 		"plan": n.String(),
 	})
 
+	if scope == nil {
+		defer func(start time.Time) {
+			AnalyzeHistogram.With("duration", "seconds").Observe(time.Since(start).Seconds())
+		}(time.Now())
+	}
+
 	var err error
 	a.Log("starting analysis of node of type: %T", n)
 	for _, batch := range a.Batches {
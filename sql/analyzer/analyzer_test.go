@@ -38,7 +38,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	analyzed, err := a.Analyze(ctx, notAnalyzed, nil)
 	require.NoError(err)
 	require.Equal(
-		plan.NewResolvedTable(table),
+		plan.NewResolvedTable(table).WithDatabase("mydb"),
 		analyzed,
 	)
 
@@ -47,10 +47,10 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	require.Error(err)
 	require.NotNil(analyzed)
 
-	analyzed, err = a.Analyze(ctx, plan.NewResolvedTable(table), nil)
+	analyzed, err = a.Analyze(ctx, plan.NewResolvedTable(table).WithDatabase("mydb"), nil)
 	require.NoError(err)
 	require.Equal(
-		plan.NewResolvedTable(table),
+		plan.NewResolvedTable(table).WithDatabase("mydb"),
 		analyzed,
 	)
 
@@ -68,7 +68,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	analyzed, err = a.Analyze(ctx, notAnalyzed, nil)
 	var expected sql.Node = plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i]", plan.NewResolvedTable(
 		table.WithProjection([]string{"i"}),
-	))
+	).WithDatabase("mydb"))
 	require.NoError(err)
 	assertNodesEqualWithDiff(t, expected, analyzed)
 
@@ -77,7 +77,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	)
 	analyzed, err = a.Analyze(ctx, notAnalyzed, nil)
 	expected = plan.NewDescribe(
-		plan.NewResolvedTable(table),
+		plan.NewResolvedTable(table).WithDatabase("mydb"),
 	)
 	require.NoError(err)
 	assertNodesEqualWithDiff(t, expected, analyzed)
@@ -89,7 +89,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	analyzed, err = a.Analyze(ctx, notAnalyzed, nil)
 	require.NoError(err)
 
-	expected = plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i t]", plan.NewResolvedTable(table.WithProjection([]string{"i", "t"})))
+	expected = plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i t]", plan.NewResolvedTable(table.WithProjection([]string{"i", "t"})).WithDatabase("mydb"))
 	assertNodesEqualWithDiff(t, expected, analyzed)
 
 	notAnalyzed = plan.NewProject(
@@ -102,7 +102,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	analyzed, err = a.Analyze(ctx, notAnalyzed, nil)
 	require.NoError(err)
 
-	expected = plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i t]", plan.NewResolvedTable(table.WithProjection([]string{"i", "t"})))
+	expected = plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i t]", plan.NewResolvedTable(table.WithProjection([]string{"i", "t"})).WithDatabase("mydb"))
 	assertNodesEqualWithDiff(t, expected, analyzed)
 
 	notAnalyzed = plan.NewProject(
@@ -117,7 +117,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 			expression.NewAlias("foo", expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", false)),
 		},
 		plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i]",
-			plan.NewResolvedTable(table.WithProjection([]string{"i"}))),
+			plan.NewResolvedTable(table.WithProjection([]string{"i"})).WithDatabase("mydb")),
 	)
 	require.NoError(err)
 	assertNodesEqualWithDiff(t, expected, analyzed)
@@ -142,7 +142,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 						expression.NewLiteral(int32(1), sql.Int32),
 					),
 				}).(*memory.PushdownTable).WithProjection([]string{"i"}),
-			),
+			).WithDatabase("mydb"),
 		),
 	)
 	require.NoError(err)
@@ -160,8 +160,8 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	)
 	analyzed, err = a.Analyze(ctx, notAnalyzed, nil)
 	expected = plan.NewCrossJoin(
-		plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i]", plan.NewResolvedTable(table.WithProjection([]string{"i"}))),
-		plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i2]", plan.NewResolvedTable(table2.WithProjection([]string{"i2"}))),
+		plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i]", plan.NewResolvedTable(table.WithProjection([]string{"i"})).WithDatabase("mydb")),
+		plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i2]", plan.NewResolvedTable(table2.WithProjection([]string{"i2"})).WithDatabase("mydb")),
 	)
 	require.NoError(err)
 	assertNodesEqualWithDiff(t, expected, analyzed)
@@ -178,7 +178,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	expected = plan.NewLimit(
 		int64(1),
 		plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i]",
-			plan.NewResolvedTable(table.WithProjection([]string{"i"}))),
+			plan.NewResolvedTable(table.WithProjection([]string{"i"})).WithDatabase("mydb")),
 	)
 	require.NoError(err)
 	assertNodesEqualWithDiff(t, expected, analyzed)
@@ -262,6 +262,114 @@ func TestAddPostValidationRule(t *testing.T) {
 	require.Equal(countRules(a.Batches), defRulesCount+1)
 }
 
+func TestAddRuleBeforeAndAfter(t *testing.T) {
+	require := require.New(t)
+
+	b := NewBuilder(nil).
+		AddRuleBefore("prune_columns", "before_prune", pushdownFilters).
+		AddRuleAfter("prune_columns", "after_prune", pushdownFilters)
+	require.NoError(b.Err())
+
+	rules := b.Rules(OptimizationPhase)
+	var idx int
+	for i, r := range rules {
+		if r == "prune_columns" {
+			idx = i
+		}
+	}
+	require.Equal("before_prune", rules[idx-1])
+	require.Equal("after_prune", rules[idx+1])
+
+	a := b.Build()
+	require.Equal(countRules(NewDefault(nil).Batches)+2, countRules(a.Batches))
+}
+
+func TestAddRuleToPhase(t *testing.T) {
+	require := require.New(t)
+
+	b := NewBuilder(nil).AddRule(ResolutionPhase, "foo", pushdownFilters)
+	require.NoError(b.Err())
+
+	rules := b.Rules(ResolutionPhase)
+	require.Equal("foo", rules[len(rules)-1])
+	require.Equal("default-rules", ResolutionPhase.String())
+}
+
+func TestReplaceRule(t *testing.T) {
+	require := require.New(t)
+
+	var called bool
+	fn := func(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+		called = true
+		return n, nil
+	}
+
+	a := NewBuilder(nil).ReplaceRule("erase_projection", fn).Build()
+	require.Equal(countRules(NewDefault(nil).Batches), countRules(a.Batches))
+
+	for _, b := range a.Batches {
+		for _, r := range b.Rules {
+			if r.Name == "erase_projection" {
+				_, err := r.Apply(sql.NewEmptyContext(), a, plan.NewResolvedTable(memory.NewTable("foo", nil)), nil)
+				require.NoError(err)
+			}
+		}
+	}
+	require.True(called)
+}
+
+func TestRemoveRule(t *testing.T) {
+	require := require.New(t)
+
+	defRulesCount := countRules(NewDefault(nil).Batches)
+
+	a := NewBuilder(nil).RemoveRule("erase_projection").Build()
+	require.Equal(defRulesCount-1, countRules(a.Batches))
+
+	// rules that run in more than one phase are removed from all of them
+	a = NewBuilder(nil).RemoveRule("resolve_subquery_exprs").Build()
+	require.Equal(defRulesCount-2, countRules(a.Batches))
+}
+
+func TestLegacyRulesAllowDuplicates(t *testing.T) {
+	require := require.New(t)
+
+	b := NewBuilder(nil).
+		AddPostAnalyzeRule("foo", pushdownFilters).
+		AddPostAnalyzeRule("foo", pushdownFilters)
+	require.NoError(b.Err())
+
+	a, err := b.BuildE()
+	require.NoError(err)
+	require.Equal(countRules(NewDefault(nil).Batches)+2, countRules(a.Batches))
+}
+
+func TestRuleBuilderErrors(t *testing.T) {
+	require := require.New(t)
+
+	b := NewBuilder(nil).AddRule(OptimizationPhase, "erase_projection", pushdownFilters)
+	require.True(ErrRuleAlreadyExists.Is(b.Err()))
+	_, err := b.BuildE()
+	require.True(ErrRuleAlreadyExists.Is(err))
+	require.Equal(countRules(NewDefault(nil).Batches), countRules(b.Build().Batches))
+
+	// the rule isn't added to any phase if it can't be added to all of them
+	b = NewBuilder(nil).
+		AddRule(OptimizationPhase, "foo", pushdownFilters).
+		AddRuleAfter("resolve_subquery_exprs", "foo", pushdownFilters)
+	require.True(ErrRuleAlreadyExists.Is(b.Err()))
+	require.NotContains(b.Rules(ResolutionPhase), "foo")
+
+	b = NewBuilder(nil).AddRuleBefore("missing", "foo", pushdownFilters)
+	require.True(ErrRuleNotFound.Is(b.Err()))
+
+	b = NewBuilder(nil).ReplaceRule("missing", pushdownFilters)
+	require.True(ErrRuleNotFound.Is(b.Err()))
+
+	b = NewBuilder(nil).RemoveRule("missing")
+	require.True(ErrRuleNotFound.Is(b.Err()))
+}
+
 func countRules(batches []*Batch) int {
 	var count int
 	for _, b := range batches {
@@ -330,14 +438,14 @@ func TestMixInnerAndNaturalJoins(t *testing.T) {
 		},
 		plan.NewInnerJoin(
 			plan.NewInnerJoin(
-				plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i f t]", plan.NewResolvedTable(table.WithProjection([]string{"i", "f", "t"}))),
-				plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [f2 i2 t2]", plan.NewResolvedTable(table2.WithProjection([]string{"f2", "i2", "t2"}))),
+				plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [i f t]", plan.NewResolvedTable(table.WithProjection([]string{"i", "f", "t"})).WithDatabase("mydb")),
+				plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [f2 i2 t2]", plan.NewResolvedTable(table2.WithProjection([]string{"f2", "i2", "t2"})).WithDatabase("mydb")),
 				expression.NewEquals(
 					expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", false),
 					expression.NewGetFieldWithTable(4, sql.Int32, "mytable2", "i2", false),
 				),
 			),
-			plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [t3 i f2]", plan.NewResolvedTable(table3.WithProjection([]string{"t3", "i", "f2"}))),
+			plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [t3 i f2]", plan.NewResolvedTable(table3.WithProjection([]string{"t3", "i", "f2"})).WithDatabase("mydb")),
 			expression.NewAnd(
 				expression.NewEquals(
 					expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", false),
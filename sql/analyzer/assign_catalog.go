@@ -26,6 +26,10 @@ func assignCatalog(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 			nc.Catalog = a.Catalog
 			nc.CurrentDatabase = ctx.GetCurrentDatabase()
 			return &nc, nil
+		case *plan.CreateDatabase:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
 		case *plan.ShowDatabases:
 			nc := *node
 			nc.Catalog = a.Catalog
@@ -59,6 +63,118 @@ func assignCatalog(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 			nc := *node
 			nc.Catalog = a.Catalog
 			return &nc, nil
+		case *plan.CreateUser:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.AlterUser:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.DropUser:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.SetPassword:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.Grant:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.Revoke:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.CreateRole:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.DropRole:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.GrantRole:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.RevokeRole:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.SetRole:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.SetDefaultRole:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.ShowStatus:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.Dump:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.ShowBinaryLogs:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.ShowBinlogEvents:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.ShowMasterStatus:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.ChangeReplicationSource:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.StartReplica:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.StopReplica:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.ShowReplicaStatus:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.Set:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.ResetPersist:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.GrantProxy:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.RevokeProxy:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.ShowGrants:
+			nc := *node
+			nc.Catalog = a.Catalog
+			if nc.For == nil {
+				// The account is resolved so that the schema names it.
+				account, err := a.Catalog.CurrentAccount(ctx)
+				if err != nil {
+					return nil, err
+				}
+				nc.For = &account
+			}
+			return &nc, nil
 		default:
 			return n, nil
 		}
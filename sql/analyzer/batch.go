@@ -3,6 +3,9 @@ package analyzer
 import (
 	"reflect"
 	"strconv"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
 
 	"github.com/dolthub/go-mysql-server/sql"
 )
@@ -75,7 +78,11 @@ func (b *Batch) evalOnce(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope
 		var err error
 		a.Log("Evaluating rule %s", rule.Name)
 		a.PushDebugContext(rule.Name)
+		start := time.Now()
+		span, ctx := ctx.Span("rule", opentracing.Tag{Key: "rule", Value: rule.Name})
 		next, err := rule.Apply(ctx, a, prev, scope)
+		span.Finish()
+		RuleHistogram.With("rule", rule.Name, "duration", "seconds").Observe(time.Since(start).Seconds())
 		if next != nil {
 			a.LogDiff(prev, next)
 			prev = next
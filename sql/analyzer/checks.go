@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/parse"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// applyChecks gives the InsertInto and Update nodes of the tables declaring CHECK constraints the constraints, with
+// their conditions resolved against the table, so that they check the rows they insert or update satisfy them.
+func applyChecks(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, _ := ctx.Span("apply_checks")
+	defer span.Finish()
+
+	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
+		switch n := n.(type) {
+		case *plan.InsertInto:
+			checks, err := loadChecks(ctx, a, n.Left())
+			if err != nil || len(checks) == 0 {
+				return n, err
+			}
+			return n.WithChecks(checks), nil
+		case *plan.Update:
+			checks, err := loadChecks(ctx, a, n.Child)
+			if err != nil || len(checks) == 0 {
+				return n, err
+			}
+			return n.WithChecks(checks), nil
+		default:
+			return n, nil
+		}
+	})
+}
+
+// loadChecks returns the CHECK constraints of the table changed by the node given, with their conditions parsed and
+// resolved against the table.
+func loadChecks(ctx *sql.Context, a *Analyzer, node sql.Node) ([]*sql.CheckConstraint, error) {
+	table := getResolvedTable(node)
+	if table == nil {
+		return nil, nil
+	}
+
+	checkTable := plan.GetCheckTable(table.Table)
+	if checkTable == nil {
+		return nil, nil
+	}
+
+	definitions, err := checkTable.GetChecks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]*sql.CheckConstraint, len(definitions))
+	for i, definition := range definitions {
+		expr, err := parse.ParseExpression(ctx, definition.CheckExpression)
+		if err != nil {
+			return nil, err
+		}
+
+		expr, err = resolveCheckExpression(ctx, a, table, expr)
+		if err != nil {
+			return nil, err
+		}
+
+		checks[i] = &sql.CheckConstraint{
+			Name:     definition.Name,
+			Expr:     expr,
+			Enforced: definition.Enforced,
+		}
+	}
+	return checks, nil
+}
+
+// resolveCheckExpression resolves the columns and functions of the condition of a CHECK constraint of the table given,
+// whose columns are the only ones it can refer to.
+func resolveCheckExpression(ctx *sql.Context, a *Analyzer, table *plan.ResolvedTable, expr sql.Expression) (sql.Expression, error) {
+	schema := table.Schema()
+	expr, err := expression.TransformUp(expr, func(e sql.Expression) (sql.Expression, error) {
+		col, ok := e.(*expression.UnresolvedColumn)
+		if !ok {
+			return e, nil
+		}
+
+		idx := schema.IndexOf(col.Name(), table.Name())
+		if idx < 0 {
+			return nil, sql.ErrTableColumnNotFound.New(col.Name())
+		}
+		return expression.NewGetFieldWithTable(idx, schema[idx].Type, table.Name(), schema[idx].Name, schema[idx].Nullable), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return expression.TransformUp(expr, resolveFunctionsInExpr(ctx, a))
+}
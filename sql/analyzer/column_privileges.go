@@ -0,0 +1,359 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// restrictedTable is a table the account has some privileges on only for some of its columns.
+type restrictedTable struct {
+	db, table  string
+	privileges sql.Privilege
+	schema     sql.Schema
+}
+
+func addRestrictedTable(tables []*restrictedTable, db, table string, privileges sql.Privilege) []*restrictedTable {
+	for _, t := range tables {
+		if strings.EqualFold(t.db, db) && strings.EqualFold(t.table, table) {
+			t.privileges |= privileges
+			return tables
+		}
+	}
+	return append(tables, &restrictedTable{db: db, table: table, privileges: privileges})
+}
+
+// hasColumn returns whether the table has a column with the name given.
+func (t *restrictedTable) hasColumn(name string) bool {
+	for _, col := range t.schema {
+		if strings.EqualFold(col.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// tableSource is a table, or a subquery, a node reads rows from, as named in the query.
+type tableSource struct {
+	alias string
+	// table is nil unless the source is a restricted table.
+	table *restrictedTable
+}
+
+// columnChecker checks the privileges of the columns of restricted tables used by a query.
+type columnChecker struct {
+	currentDB  string
+	account    sql.Account
+	privileges sql.PrivilegeSet
+	tables     []*restrictedTable
+	// read are the sources, in the whole query, whose columns need to be
+	// readable.
+	read []tableSource
+}
+
+// checkColumnPrivileges checks that the account has the privileges needed on each column of the restricted tables
+// given that the query uses. Columns the account can't read are left out of star expressions.
+func checkColumnPrivileges(
+	ctx *sql.Context,
+	a *Analyzer,
+	n sql.Node,
+	account sql.Account,
+	privileges sql.PrivilegeSet,
+	tables []*restrictedTable,
+) (sql.Node, error) {
+	for _, t := range tables {
+		table, err := a.Catalog.Table(ctx, t.db, t.table)
+		if err != nil {
+			// Privileges can only be granted on the columns of tables.
+			return nil, sql.ErrTableAccessDenied.New(t.privileges.Names()[0], account, t.table)
+		}
+		t.schema = table.Schema()
+	}
+
+	c := &columnChecker{
+		currentDB:  ctx.GetCurrentDatabase(),
+		account:    account,
+		privileges: privileges,
+		tables:     tables,
+	}
+
+	n, err := c.node(n)
+	if err != nil {
+		return nil, err
+	}
+
+	c.read = c.sources(n)
+	if err := c.checkReads(n); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// lookup returns the restricted table a table node refers to, if any.
+func (c *columnChecker) lookup(n sql.Node) *restrictedTable {
+	t, ok := n.(*plan.UnresolvedTable)
+	if !ok {
+		return nil
+	}
+
+	db := t.Database
+	if db == "" {
+		db = c.currentDB
+	}
+
+	for _, rt := range c.tables {
+		if strings.EqualFold(rt.db, db) && strings.EqualFold(rt.table, t.Name()) {
+			return rt
+		}
+	}
+
+	return nil
+}
+
+// sources returns the tables and subqueries a node reads rows from, including the ones of subquery expressions.
+func (c *columnChecker) sources(n sql.Node) []tableSource {
+	var sources []tableSource
+	plan.Inspect(n, func(n sql.Node) bool {
+		if e, ok := n.(sql.Expressioner); ok {
+			for _, e := range e.Expressions() {
+				sql.Inspect(e, func(e sql.Expression) bool {
+					if s, ok := e.(*plan.Subquery); ok {
+						sources = append(sources, c.sources(s.Query)...)
+					}
+					return true
+				})
+			}
+		}
+
+		switch n := n.(type) {
+		case *plan.TableAlias:
+			sources = append(sources, tableSource{n.Name(), c.lookup(n.Child)})
+			return false
+		case *plan.UnresolvedTable:
+			sources = append(sources, tableSource{n.Name(), c.lookup(n)})
+		case *plan.SubqueryAlias:
+			sources = append(sources, tableSource{alias: n.Name()})
+		}
+		return true
+	})
+	return sources
+}
+
+// fromSources returns the sources of the rows of a node, without going into subqueries.
+func (c *columnChecker) fromSources(n sql.Node) []tableSource {
+	var sources []tableSource
+	plan.Inspect(n, func(n sql.Node) bool {
+		switch n := n.(type) {
+		case *plan.TableAlias:
+			sources = append(sources, tableSource{n.Name(), c.lookup(n.Child)})
+			return false
+		case *plan.UnresolvedTable:
+			sources = append(sources, tableSource{n.Name(), c.lookup(n)})
+		case *plan.SubqueryAlias:
+			sources = append(sources, tableSource{alias: n.Name()})
+			return false
+		}
+		return true
+	})
+	return sources
+}
+
+// node checks the columns written by the node and replaces the star expressions selecting columns of restricted
+// tables with the columns the account can read.
+func (c *columnChecker) node(n sql.Node) (sql.Node, error) {
+	n, err := plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
+		switch n := n.(type) {
+		case *plan.Project:
+			exprs, changed := c.expandStars(n.Projections, c.fromSources(n.Child))
+			if !changed {
+				return n, nil
+			}
+			return plan.NewProject(exprs, n.Child), nil
+		case *plan.GroupBy:
+			exprs, changed := c.expandStars(n.SelectedExprs, c.fromSources(n.Child))
+			if !changed {
+				return n, nil
+			}
+			return plan.NewGroupBy(exprs, n.GroupByExprs, n.Child), nil
+		case *plan.InsertInto:
+			return n, c.checkInsert(n)
+		case *plan.UpdateSource:
+			return n, c.checkUpdates(n.UpdateExprs, c.fromSources(n.Child))
+		default:
+			return n, nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.TransformExpressionsUp(n, func(e sql.Expression) (sql.Expression, error) {
+		s, ok := e.(*plan.Subquery)
+		if !ok {
+			return e, nil
+		}
+
+		q, err := c.node(s.Query)
+		if err != nil {
+			return nil, err
+		}
+		return s.WithQuery(q), nil
+	})
+}
+
+// expandStars replaces the star expressions that select columns of tables restricted for reading with the columns
+// that can be read.
+func (c *columnChecker) expandStars(exprs []sql.Expression, sources []tableSource) ([]sql.Expression, bool) {
+	var result []sql.Expression
+	var changed bool
+	for _, e := range exprs {
+		star, ok := e.(*expression.Star)
+		if !ok {
+			result = append(result, e)
+			continue
+		}
+
+		var expanded []sql.Expression
+		var restricted bool
+		for _, s := range sources {
+			if star.Table != "" && !strings.EqualFold(star.Table, s.alias) {
+				continue
+			}
+
+			if s.table == nil || s.table.privileges&sql.PrivilegeSelect == 0 {
+				expanded = append(expanded, expression.NewQualifiedStar(s.alias))
+				continue
+			}
+
+			restricted = true
+			for _, col := range s.table.schema {
+				if c.privileges.HasColumn(s.table.db, s.table.table, col.Name, sql.PrivilegeSelect) {
+					expanded = append(expanded, expression.NewUnresolvedQualifiedColumn(s.alias, col.Name))
+				}
+			}
+		}
+
+		if !restricted {
+			result = append(result, e)
+			continue
+		}
+
+		changed = true
+		result = append(result, expanded...)
+	}
+
+	return result, changed
+}
+
+func (c *columnChecker) checkInsert(n *plan.InsertInto) error {
+	t := c.lookup(n.Left())
+	if t == nil {
+		return nil
+	}
+
+	if t.privileges&sql.PrivilegeInsert != 0 {
+		columns := n.ColumnNames
+		if len(columns) == 0 {
+			for _, col := range t.schema {
+				if !col.Invisible {
+					columns = append(columns, col.Name)
+				}
+			}
+		}
+
+		for _, col := range columns {
+			if !c.privileges.HasColumn(t.db, t.table, col, sql.PrivilegeInsert) {
+				return sql.ErrColumnAccessDenied.New("INSERT", c.account, col, t.table)
+			}
+		}
+	}
+
+	return c.checkUpdates(n.OnDupExprs, []tableSource{{n.Left().(*plan.UnresolvedTable).Name(), t}})
+}
+
+// checkUpdates checks the columns assigned by the SET expressions given.
+func (c *columnChecker) checkUpdates(exprs []sql.Expression, sources []tableSource) error {
+	for _, e := range exprs {
+		set, ok := e.(*expression.SetField)
+		if !ok {
+			continue
+		}
+
+		col, ok := set.Left.(*expression.UnresolvedColumn)
+		if !ok {
+			continue
+		}
+
+		for _, s := range sources {
+			if s.table == nil || s.table.privileges&sql.PrivilegeUpdate == 0 {
+				continue
+			}
+
+			if col.Table() != "" && !strings.EqualFold(col.Table(), s.alias) || !s.table.hasColumn(col.Name()) {
+				continue
+			}
+
+			if !c.privileges.HasColumn(s.table.db, s.table.table, col.Name(), sql.PrivilegeUpdate) {
+				return sql.ErrColumnAccessDenied.New("UPDATE", c.account, col.Name(), s.table.table)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkReads checks that the columns of tables restricted for reading used by the query can be read. Unqualified
+// columns are checked against every restricted table having a column with the same name.
+func (c *columnChecker) checkReads(n sql.Node) error {
+	var err error
+	plan.Inspect(n, func(n sql.Node) bool {
+		if e, ok := n.(sql.Expressioner); ok && err == nil {
+			for _, e := range e.Expressions() {
+				if err = c.checkExpressionReads(e); err != nil {
+					break
+				}
+			}
+		}
+		return err == nil
+	})
+	return err
+}
+
+func (c *columnChecker) checkExpressionReads(e sql.Expression) error {
+	var err error
+	sql.Inspect(e, func(e sql.Expression) bool {
+		switch e := e.(type) {
+		case *expression.SetField:
+			// The column assigned is not read.
+			err = c.checkExpressionReads(e.Right)
+			return false
+		case *plan.Subquery:
+			err = c.checkReads(e.Query)
+		case *expression.UnresolvedColumn:
+			err = c.checkRead(e)
+		}
+		return err == nil
+	})
+	return err
+}
+
+func (c *columnChecker) checkRead(col *expression.UnresolvedColumn) error {
+	for _, s := range c.read {
+		if s.table == nil || s.table.privileges&sql.PrivilegeSelect == 0 {
+			continue
+		}
+
+		if col.Table() != "" && !strings.EqualFold(col.Table(), s.alias) || !s.table.hasColumn(col.Name()) {
+			continue
+		}
+
+		if !c.privileges.HasColumn(s.table.db, s.table.table, col.Name(), sql.PrivilegeSelect) {
+			return sql.ErrColumnAccessDenied.New("SELECT", c.account, col.Name(), s.table.table)
+		}
+	}
+
+	return nil
+}
@@ -58,6 +58,11 @@ func expandStarsForExpressions(a *Analyzer, exprs []sql.Expression, schema sql.S
 		if s, ok := e.(*expression.Star); ok {
 			var exprs []sql.Expression
 			for i, col := range schema {
+				// Invisible columns can only be selected by their names
+				if col.Invisible {
+					continue
+				}
+
 				lowerSource := strings.ToLower(col.Source)
 				lowerTable := strings.ToLower(s.Table)
 				if s.Table == "" || lowerTable == lowerSource ||
@@ -0,0 +1,384 @@
+package analyzer
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// foreignKeys are the foreign keys declared by the tables of the databases of the catalog, by the table declaring them,
+// and the tables declaring the foreign keys referencing each table, which can be in another database.
+type foreignKeys struct {
+	declared map[foreignKeyTable][]sql.ForeignKeyConstraint
+	children map[foreignKeyTable][]foreignKeyTable
+}
+
+// foreignKeyTable is a table declaring or referenced by foreign keys, by the lowercased names of its database and its
+// own.
+type foreignKeyTable struct {
+	db, name string
+}
+
+func newForeignKeyTable(db, name string) foreignKeyTable {
+	return foreignKeyTable{db: strings.ToLower(db), name: strings.ToLower(name)}
+}
+
+// applyForeignKeys gives the InsertInto, DeleteFrom and Update nodes of the tables declaring or referenced by foreign
+// keys the plan.ForeignKeyEditor that checks the rows they insert or update reference existing rows, and applies the
+// referential actions of the foreign keys referencing them to the rows of the tables that declare them.
+func applyForeignKeys(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, _ := ctx.Span("apply_foreign_keys")
+	defer span.Finish()
+
+	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
+		switch n := n.(type) {
+		case *plan.InsertInto:
+			editor, err := getForeignKeyEditor(ctx, a, n.Left(), false)
+			if err != nil || editor == nil {
+				return n, err
+			}
+			return n.WithForeignKeys(editor), nil
+		case *plan.DeleteFrom:
+			if len(n.Targets) > 0 {
+				editors, err := getJoinedDeleteForeignKeyEditors(ctx, a, n)
+				if err != nil || editors == nil {
+					return n, err
+				}
+				return n.WithTargetForeignKeys(editors), nil
+			}
+			editor, err := getForeignKeyEditor(ctx, a, n.Child, true)
+			if err != nil || editor == nil {
+				return n, err
+			}
+			return n.WithForeignKeys(editor), nil
+		case *plan.Update:
+			editor, err := getForeignKeyEditor(ctx, a, n.Child, true)
+			if err != nil || editor == nil {
+				return n, err
+			}
+			return n.WithForeignKeys(editor), nil
+		default:
+			return n, nil
+		}
+	})
+}
+
+// getJoinedDeleteForeignKeyEditors returns the editors of the tables deleted from by the deletion given, which joins
+// several, in the order of its targets, or nil if none of them declares or is referenced by foreign keys. The editors
+// share the editors of the tables their foreign keys reach, so that the rows deleted from several of them through the
+// referential actions of others are deleted once.
+func getJoinedDeleteForeignKeyEditors(ctx *sql.Context, a *Analyzer, n *plan.DeleteFrom) ([]*plan.ForeignKeyEditor, error) {
+	tables, err := n.TargetTables()
+	if err != nil {
+		return nil, err
+	}
+
+	fks, err := a.foreignKeys.load(ctx, a.Catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	editors := make([]*plan.ForeignKeyEditor, len(tables))
+	shared := make(map[foreignKeyTable]*plan.ForeignKeyEditor)
+	found := false
+	for i, node := range tables {
+		table := getResolvedTable(node)
+		if table == nil {
+			continue
+		}
+		db := table.Database
+		if db == "" {
+			db = ctx.GetCurrentDatabase()
+		}
+
+		key := newForeignKeyTable(db, table.Name())
+		if len(fks.declared[key]) == 0 && len(fks.children[key]) == 0 {
+			continue
+		}
+		editors[i], err = newForeignKeyEditor(ctx, a.Catalog, key, fks, shared)
+		if err != nil {
+			return nil, err
+		}
+		found = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+	return editors, nil
+}
+
+// getForeignKeyEditor returns the editor of the table changed by the node given, or nil if it neither declares nor is
+// referenced by any foreign key. The foreign keys referencing the table are only loaded if the references are asked
+// for, since they can be declared by the tables of any database, and inserted rows only need the foreign keys the
+// table declares to be checked.
+func getForeignKeyEditor(ctx *sql.Context, a *Analyzer, node sql.Node, references bool) (*plan.ForeignKeyEditor, error) {
+	table := getResolvedTable(node)
+	if table == nil {
+		return nil, nil
+	}
+
+	db := table.Database
+	if db == "" {
+		db = ctx.GetCurrentDatabase()
+	}
+
+	var fks *foreignKeys
+	var err error
+	if references {
+		fks, err = a.foreignKeys.load(ctx, a.Catalog)
+	} else {
+		fks = newForeignKeys()
+		err = fks.add(ctx, db, table.Table)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := newForeignKeyTable(db, table.Name())
+	if len(fks.declared[key]) == 0 && len(fks.children[key]) == 0 {
+		return nil, nil
+	}
+
+	return newForeignKeyEditor(ctx, a.Catalog, key, fks, make(map[foreignKeyTable]*plan.ForeignKeyEditor))
+}
+
+// loadForeignKeys returns the foreign keys declared by the tables of all the databases of the catalog given, as tables
+// can reference the tables of other databases.
+func loadForeignKeys(ctx *sql.Context, catalog *sql.Catalog) (*foreignKeys, error) {
+	fks := newForeignKeys()
+	for _, db := range catalog.AllDatabases() {
+		names, err := db.GetTableNames(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			table, ok, err := db.GetTableInsensitive(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			if err := fks.add(ctx, db.Name(), table); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return fks, nil
+}
+
+// foreignKeyCache caches the foreign keys declared by the tables of all the databases of a catalog, so that the
+// statements deleting and updating rows don't read the foreign keys of every table to find the ones referencing theirs.
+// As a schema listener of the catalog, it's cleared by the DDL statements changing tables, and it's reloaded once the
+// names of the databases of the catalog change.
+type foreignKeyCache struct {
+	mu        sync.Mutex
+	fks       *foreignKeys
+	databases []string
+	// generation counts the changes of tables, so that the foreign keys loaded while a table changed aren't cached.
+	generation uint64
+}
+
+// SchemaChanged implements the sql.SchemaListener interface.
+func (c *foreignKeyCache) SchemaChanged(ctx *sql.Context, change sql.SchemaChange) {
+	switch change.Type {
+	case sql.TableCreated, sql.TableAltered, sql.TableRenamed, sql.TableDropped:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.fks = nil
+		c.generation++
+	}
+}
+
+// load returns the foreign keys declared by the tables of all the databases of the catalog given, which the callers
+// mustn't change. A nil cache loads them every time.
+func (c *foreignKeyCache) load(ctx *sql.Context, catalog *sql.Catalog) (*foreignKeys, error) {
+	if c == nil {
+		return loadForeignKeys(ctx, catalog)
+	}
+
+	databases := catalog.DatabaseNames()
+	c.mu.Lock()
+	if c.fks != nil && stringSlicesEqual(c.databases, databases) {
+		defer c.mu.Unlock()
+		return c.fks, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	fks, err := loadForeignKeys(ctx, catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.fks = fks
+		c.databases = databases
+	}
+	return fks, nil
+}
+
+// stringSlicesEqual returns whether the slices given have the same strings in the same order.
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func newForeignKeys() *foreignKeys {
+	return &foreignKeys{
+		declared: make(map[foreignKeyTable][]sql.ForeignKeyConstraint),
+		children: make(map[foreignKeyTable][]foreignKeyTable),
+	}
+}
+
+// add adds the foreign keys declared by the table of the database given.
+func (fks *foreignKeys) add(ctx *sql.Context, db string, table sql.Table) error {
+	fkTable, ok := getForeignKeyTable(table)
+	if !ok {
+		return nil
+	}
+
+	declared, err := fkTable.GetForeignKeys(ctx)
+	if err != nil {
+		return err
+	}
+	child := newForeignKeyTable(db, table.Name())
+	for _, fk := range declared {
+		parentDB := fk.ReferencedDatabase
+		if parentDB == "" {
+			parentDB = db
+		}
+		parent := newForeignKeyTable(parentDB, fk.ReferencedTable)
+		fks.declared[child] = append(fks.declared[child], fk)
+		fks.children[parent] = append(fks.children[parent], child)
+	}
+	return nil
+}
+
+func getForeignKeyTable(t sql.Table) (sql.ForeignKeyTable, bool) {
+	switch t := t.(type) {
+	case sql.ForeignKeyTable:
+		return t, true
+	case sql.TableWrapper:
+		return getForeignKeyTable(t.Underlying())
+	default:
+		return nil, false
+	}
+}
+
+// newForeignKeyEditor returns the editor of the table given, with the editors of the tables it references and of the
+// tables declaring the foreign keys referencing it, recursively, or nil if the table or its database doesn't exist.
+// The editors already created are given by table, so that the tables referencing each other share them. The
+// references of each editor are created along with it, for the foreign keys its table declares.
+func newForeignKeyEditor(
+	ctx *sql.Context,
+	catalog *sql.Catalog,
+	key foreignKeyTable,
+	fks *foreignKeys,
+	editors map[foreignKeyTable]*plan.ForeignKeyEditor,
+) (*plan.ForeignKeyEditor, error) {
+	if editor, ok := editors[key]; ok {
+		return editor, nil
+	}
+
+	db, err := catalog.Database(key.db)
+	if sql.ErrDatabaseNotFound.Is(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	table, ok, err := db.GetTableInsensitive(ctx, key.name)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	editor := &plan.ForeignKeyEditor{Table: table}
+	editors[key] = editor
+
+	for _, fk := range fks.declared[key] {
+		parentDB := fk.ReferencedDatabase
+		if parentDB == "" {
+			parentDB = key.db
+		}
+		parent, err := newForeignKeyEditor(ctx, catalog, newForeignKeyTable(parentDB, fk.ReferencedTable), fks, editors)
+		if err != nil {
+			return nil, err
+		}
+
+		var parentIndex sql.Index
+		if parent != nil {
+			parentIndex, err = getForeignKeyIndex(ctx, parent.Table, fk.ReferencedColumns)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		childIndex, err := getForeignKeyIndex(ctx, table, fk.Columns)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := plan.NewForeignKeyReference(fk, parent, editor, parentIndex, childIndex); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, child := range fks.children[key] {
+		if _, err := newForeignKeyEditor(ctx, catalog, child, fks, editors); err != nil {
+			return nil, err
+		}
+	}
+
+	return editor, nil
+}
+
+// getForeignKeyIndex returns an index of the table given that can look up its rows by the values of the columns
+// given, as the first expressions of the index, or nil if it has none.
+func getForeignKeyIndex(ctx *sql.Context, table sql.Table, columns []string) (sql.Index, error) {
+	indexed, ok := table.(sql.IndexedTable)
+	if !ok {
+		return nil, nil
+	}
+
+	indexes, err := indexed.GetIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, index := range indexes {
+		exprs := index.Expressions()
+		if len(exprs) < len(columns) {
+			continue
+		}
+		if _, ok := index.(sql.RangeIndex); !ok && len(exprs) > len(columns) {
+			continue
+		}
+
+		matches := true
+		for i, col := range columns {
+			if !strings.EqualFold(exprs[i], table.Name()+"."+col) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return index, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -26,6 +26,8 @@ type indexAnalyzer struct {
 	indexesByTable map[string][]sql.Index
 	indexRegistry  *sql.IndexRegistry
 	registryIdxes  []sql.Index
+	// useInvisibleIndexes is whether the invisible indexes of the tables can be used to look up their rows.
+	useInvisibleIndexes bool
 }
 
 // getIndexesForNode returns an analyzer for indexes available in the node given. These might come from either the
@@ -66,13 +68,36 @@ func getIndexesForNode(ctx *sql.Context, a *Analyzer, n sql.Node) (*indexAnalyze
 	}
 
 	return &indexAnalyzer{
-		indexesByTable: indexes,
-		indexRegistry:  idxRegistry,
+		indexesByTable:      indexes,
+		indexRegistry:       idxRegistry,
+		useInvisibleIndexes: useInvisibleIndexes(ctx),
 	}, nil
 }
 
-// IndexesByTable returns all indexes on the table named. The table must be present in the node used to create the
-// analyzer.
+// useInvisibleIndexes returns whether the use_invisible_indexes flag of optimizer_switch is on, which lets the analyzer
+// use invisible indexes.
+func useInvisibleIndexes(ctx *sql.Context) bool {
+	return sql.OptimizerSwitch(ctx, "use_invisible_indexes")
+}
+
+// visibleIndexes returns the indexes given that the analyzer can use: the visible ones, or all of them if the
+// use_invisible_indexes flag of optimizer_switch is on.
+func visibleIndexes(ctx *sql.Context, indexes []sql.Index) []sql.Index {
+	if useInvisibleIndexes(ctx) {
+		return indexes
+	}
+
+	var visible []sql.Index
+	for _, idx := range indexes {
+		if sql.IsIndexVisible(idx) {
+			visible = append(visible, idx)
+		}
+	}
+	return visible
+}
+
+// IndexesByTable returns all indexes on the table named, including the invisible ones. The table must be present in the
+// node used to create the analyzer.
 func (r *indexAnalyzer) IndexesByTable(ctx *sql.Context, db, table string) []sql.Index {
 	indexes := r.indexesByTable[table]
 
@@ -102,6 +127,9 @@ func (r *indexAnalyzer) IndexByExpression(ctx *sql.Context, db string, expr ...s
 
 	for _, idxes := range r.indexesByTable {
 		for _, idx := range idxes {
+			if !r.useInvisibleIndexes && !sql.IsIndexVisible(idx) {
+				continue
+			}
 			if exprListsEqual(idx.Expressions(), exprStrs) {
 				return idx
 			}
@@ -126,6 +154,9 @@ func (r *indexAnalyzer) ExpressionsWithIndexes(db string, exprs ...sql.Expressio
 	for _, idxes := range r.indexesByTable {
 	Indexes:
 		for _, idx := range idxes {
+			if !r.useInvisibleIndexes && !sql.IsIndexVisible(idx) {
+				continue
+			}
 			if ln := len(idx.Expressions()); ln <= len(exprs) && ln > 1 {
 				var used = make(map[int]bool)
 				var matched []sql.Expression
@@ -0,0 +1,245 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// convertOrderingsToIndexedAccess replaces the sorts of the rows of a table by a prefix of the expressions of one of
+// its indexes, in the directions of the index or all reversed, with lookups on the index that return the rows in that
+// order, and makes the group-bys of the rows of a table by such a prefix aggregate the groups as the rows are read.
+// This applies to tables that implement sql.IndexAddressableTable and indexes that implement sql.OrderedIndex. A table
+// with an index lookup for its filters only gets an ordered lookup if the lookup is on a single index, which is then
+// used for the order.
+func convertOrderingsToIndexedAccess(ctx *sql.Context, a *Analyzer, n sql.Node, indexes indexLookupsByTable) (sql.Node, error) {
+	return plan.TransformUp(n, func(node sql.Node) (sql.Node, error) {
+		switch node := node.(type) {
+		case *plan.Sort:
+			if len(node.SortFields) == 0 {
+				return node, nil
+			}
+
+			columns := make([]sql.Expression, len(node.SortFields))
+			descending := make([]bool, len(node.SortFields))
+			for i, f := range node.SortFields {
+				// Indexes order NULL values lowest, which is first in ascending order and last in descending order, as
+				// sorts put them by default.
+				if f.NullOrdering != plan.NullsFirst {
+					return node, nil
+				}
+				columns[i] = f.Column
+				descending[i] = f.Order == plan.Descending
+			}
+
+			child, ok, err := withOrderedIndexLookup(ctx, a, node.Child, columns, descending, indexes)
+			if err != nil || !ok {
+				return node, err
+			}
+
+			a.Log("sort on %s replaced by an ordered index lookup", node.Child)
+			return child, nil
+		case *plan.GroupBy:
+			if node.Ordered || len(node.GroupByExprs) == 0 {
+				return node, nil
+			}
+
+			child, ok, err := withOrderedIndexLookup(ctx, a, node.Child, node.GroupByExprs, nil, indexes)
+			if err != nil || !ok {
+				return node, err
+			}
+
+			a.Log("group by on %s made to aggregate the rows of an ordered index lookup", node.Child)
+			ng := *node
+			ng.Child = child
+			ng.Ordered = true
+			return &ng, nil
+		default:
+			return node, nil
+		}
+	})
+}
+
+// withOrderedIndexLookup returns the node given, made of projections and filters of the rows of a table, with the
+// table given an index lookup that returns its rows in the order of the columns given, each descending or not as
+// given, or whether it can't be. The columns can be in any order in the index and in any direction if descending is
+// nil, as for grouping.
+func withOrderedIndexLookup(
+	ctx *sql.Context,
+	a *Analyzer,
+	node sql.Node,
+	columns []sql.Expression,
+	descending []bool,
+	indexes indexLookupsByTable,
+) (sql.Node, bool, error) {
+	tableNode, projections := orderedTableNode(node)
+	if tableNode == nil {
+		return node, false, nil
+	}
+
+	table, ok := getTable(tableNode).(sql.IndexAddressableTable)
+	if !ok {
+		return node, false, nil
+	}
+
+	var names []string
+	for _, column := range columns {
+		gf, ok := column.(*expression.GetField)
+		if !ok || !strings.EqualFold(gf.Table(), tableNode.Name()) || !projectsField(projections, gf) {
+			return node, false, nil
+		}
+		names = append(names, strings.ToLower(table.Name()+"."+gf.Name()))
+	}
+
+	var index sql.OrderedIndex
+	var lookup sql.IndexLookup
+	var reverse bool
+	if il, ok := indexes[tableNode.Name()]; ok {
+		if len(il.indexes) != 1 {
+			return node, false, nil
+		}
+		if idx, ok := il.indexes[0].(sql.OrderedIndex); ok {
+			if r, ok := indexScanOrder(idx, names, descending); ok {
+				index, lookup, reverse = idx, il.lookup, r
+			}
+		}
+	} else if it, ok := table.(sql.IndexedTable); ok {
+		idxes, err := it.GetIndexes(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, idx := range visibleIndexes(ctx, idxes) {
+			if idx, ok := idx.(sql.OrderedIndex); ok {
+				if r, ok := indexScanOrder(idx, names, descending); ok {
+					index, reverse = idx, r
+					break
+				}
+			}
+		}
+	}
+	if index == nil {
+		return node, false, nil
+	}
+
+	ordered, err := index.Ordered(lookup, reverse)
+	if err != nil || ordered == nil {
+		return node, false, err
+	}
+
+	order := "ascending"
+	if reverse {
+		order = "descending"
+	}
+	newTableNode, err := withTable(plan.NewDecoratedNode(
+		plan.DecorationTypeIndexedAccess,
+		fmt.Sprintf("Indexed table access on index %s in %s order", formatIndexDecoratorString(index)[0], order),
+		tableNode), table.WithIndexLookup(ordered))
+	if err != nil {
+		return nil, false, err
+	}
+
+	a.Log("table %q transformed with ordered lookup of index %s", tableNode.Name(), index.ID())
+	newNode, err := replaceTableNode(node, tableNode, newTableNode)
+	return newNode, err == nil, err
+}
+
+// orderedTableNode returns the table whose rows the node given returns, in the same order, along with the
+// projections in between, or nil if the node isn't made of just projections and filters of a table.
+func orderedTableNode(node sql.Node) (NameableNode, []*plan.Project) {
+	var projections []*plan.Project
+	for {
+		switch n := node.(type) {
+		case *plan.Project:
+			projections = append(projections, n)
+			node = n.Child
+		case *plan.Filter:
+			node = n.Child
+		case *plan.TableAlias:
+			if _, ok := n.Child.(*plan.ResolvedTable); !ok {
+				return nil, nil
+			}
+			return n, projections
+		case *plan.ResolvedTable:
+			return n, projections
+		default:
+			return nil, nil
+		}
+	}
+}
+
+// projectsField returns whether all the projections given return the field given of their child as is.
+func projectsField(projections []*plan.Project, field *expression.GetField) bool {
+	for _, p := range projections {
+		found := false
+		for _, e := range p.Projections {
+			if gf, ok := e.(*expression.GetField); ok &&
+				strings.EqualFold(gf.Table(), field.Table()) && strings.EqualFold(gf.Name(), field.Name()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// indexScanOrder returns whether the rows must be read in the reverse order of the index given for them to be in the
+// order of the expressions given, lowercase, each descending or not as given, or whether the index can't order them
+// because its expressions don't begin with them, it only keeps prefixes of their values or its directions don't match.
+// The expressions can be in any order and in any direction if descending is nil.
+func indexScanOrder(index sql.Index, exprs []string, descending []bool) (reverse bool, ok bool) {
+	indexExprs := index.Expressions()
+	if len(exprs) > len(indexExprs) {
+		return false, false
+	}
+
+	prefixLengths := sql.IndexPrefixLengths(index)
+	prefix := make([]string, len(exprs))
+	for i := range exprs {
+		if prefixLengths[i] != 0 {
+			return false, false
+		}
+		prefix[i] = strings.ToLower(indexExprs[i])
+	}
+
+	if descending == nil {
+		for _, e := range exprs {
+			if !stringContains(prefix, e) {
+				return false, false
+			}
+		}
+		return false, true
+	}
+
+	directions := sql.IndexDirections(index)
+	for i, e := range exprs {
+		if prefix[i] != e {
+			return false, false
+		}
+		r := descending[i] != directions[i]
+		if i > 0 && r != reverse {
+			return false, false
+		}
+		reverse = r
+	}
+	return reverse, true
+}
+
+// replaceTableNode returns the node given, made of nodes with a single child down to the table node given, with the
+// table node replaced.
+func replaceTableNode(node sql.Node, tableNode, replacement sql.Node) (sql.Node, error) {
+	if node == tableNode {
+		return replacement, nil
+	}
+
+	child, err := replaceTableNode(node.Children()[0], tableNode, replacement)
+	if err != nil {
+		return nil, err
+	}
+	return node.WithChildren(child)
+}
@@ -626,11 +626,20 @@ func getMultiColumnIndexes(
 	result := make(indexLookupsByTable)
 	columnExprs := columnExprsByTable(exprs)
 	for table, exps := range columnExprs {
+		index, lookup, err := getMultiColumnRangeIndex(ctx, ia, exps, exprAliases, tableAliases)
+		if err != nil {
+			return nil, err
+		}
+		if lookup != nil {
+			result[table] = &indexLookup{lookup, []sql.Index{index}}
+			continue
+		}
+
 		exprsByOp := groupExpressionsByOperator(exps)
 		for _, exps := range exprsByOp {
 			cols := make([]sql.Expression, len(exps))
 			for i, e := range exps {
-				cols[i] = e.col
+				cols[i] = e.colExpr
 			}
 
 			exprList := ia.ExpressionsWithIndexes(ctx.GetCurrentDatabase(), cols...)
@@ -674,6 +683,161 @@ func getMultiColumnIndexes(
 	return result, nil
 }
 
+// getMultiColumnRangeIndex returns the widest multi-column index whose every column is compared to a value by the
+// expressions given, and a lookup of the range of values of each column the comparisons allow, if the index is a
+// sql.RangeIndex. The comparisons that are all equalities are left to the other lookups.
+func getMultiColumnRangeIndex(
+	ctx *sql.Context,
+	ia *indexAnalyzer,
+	exprs []joinColExpr,
+	exprAliases ExprAliases,
+	tableAliases TableAliases,
+) (sql.Index, sql.IndexLookup, error) {
+	var cols []sql.Expression
+	seen := make(map[string]bool)
+	for _, e := range exprs {
+		if !seen[e.colExpr.String()] {
+			seen[e.colExpr.String()] = true
+			cols = append(cols, e.colExpr)
+		}
+	}
+
+	var selected []sql.Expression
+	for _, l := range ia.ExpressionsWithIndexes(ctx.GetCurrentDatabase(), cols...) {
+		if len(l) > len(selected) {
+			selected = l
+		}
+	}
+	if len(selected) == 0 {
+		return nil, nil, nil
+	}
+
+	index, ok := ia.IndexByExpression(ctx, ctx.GetCurrentDatabase(), normalizeExpressions(exprAliases, tableAliases, selected...)...).(sql.RangeIndex)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	allEquals := true
+	ranges := make([]sql.IndexRange, len(index.Expressions()))
+	for i, indexExpr := range index.Expressions() {
+		comparisons := 0
+		for _, e := range exprs {
+			if e.colExpr.String() != indexExpr {
+				continue
+			}
+
+			r, ok, err := columnRange(e)
+			if err != nil || !ok {
+				return nil, nil, err
+			}
+			if ranges[i], ok = intersectRanges(e.colExpr.Type(), ranges[i], r); !ok {
+				return nil, nil, nil
+			}
+
+			if _, ok := e.comparison.(*expression.Equals); !ok {
+				allEquals = false
+			}
+			comparisons++
+		}
+
+		if comparisons != 1 {
+			allEquals = false
+		}
+	}
+
+	if allEquals {
+		return nil, nil, nil
+	}
+
+	lookup, err := index.Range(ranges...)
+	if err != nil || lookup == nil {
+		return nil, nil, err
+	}
+	return index, lookup, nil
+}
+
+// columnRange returns the range of values of the compared expression of the expression given for which its comparison
+// is true, and whether it's a comparison with a value.
+func columnRange(e joinColExpr) (sql.IndexRange, bool, error) {
+	if between, ok := e.comparison.(*expression.Between); ok {
+		lower, err := between.Lower.Eval(sql.NewEmptyContext(), nil)
+		if err != nil {
+			return sql.IndexRange{}, false, err
+		}
+		upper, err := between.Upper.Eval(sql.NewEmptyContext(), nil)
+		if err != nil {
+			return sql.IndexRange{}, false, err
+		}
+
+		return sql.IndexRange{
+			Lower: &sql.IndexBound{Value: lower, Inclusive: true},
+			Upper: &sql.IndexBound{Value: upper, Inclusive: true},
+		}, true, nil
+	}
+
+	value, err := e.comparand.Eval(sql.NewEmptyContext(), nil)
+	if err != nil {
+		return sql.IndexRange{}, false, err
+	}
+
+	switch e.comparison.(type) {
+	case *expression.Equals:
+		bound := &sql.IndexBound{Value: value, Inclusive: true}
+		return sql.IndexRange{Lower: bound, Upper: bound}, true, nil
+	case *expression.GreaterThan:
+		return sql.IndexRange{Lower: &sql.IndexBound{Value: value}}, true, nil
+	case *expression.GreaterThanOrEqual:
+		return sql.IndexRange{Lower: &sql.IndexBound{Value: value, Inclusive: true}}, true, nil
+	case *expression.LessThan:
+		return sql.IndexRange{Upper: &sql.IndexBound{Value: value}}, true, nil
+	case *expression.LessThanOrEqual:
+		return sql.IndexRange{Upper: &sql.IndexBound{Value: value, Inclusive: true}}, true, nil
+	default:
+		return sql.IndexRange{}, false, nil
+	}
+}
+
+// intersectRanges returns the range of the values of the type given in both ranges given, and false if their bounds
+// can't be compared.
+func intersectRanges(typ sql.Type, a, b sql.IndexRange) (sql.IndexRange, bool) {
+	lower, ok := tighterBound(typ, a.Lower, b.Lower, 1)
+	if !ok {
+		return sql.IndexRange{}, false
+	}
+	upper, ok := tighterBound(typ, a.Upper, b.Upper, -1)
+	if !ok {
+		return sql.IndexRange{}, false
+	}
+	return sql.IndexRange{Lower: lower, Upper: upper}, true
+}
+
+// tighterBound returns the bound of the two given that limits a range the most, which is the greatest of two lower
+// bounds (sign 1) or the least of two upper bounds (sign -1), and false if they can't be compared.
+func tighterBound(typ sql.Type, a, b *sql.IndexBound, sign int) (*sql.IndexBound, bool) {
+	if a == nil {
+		return b, true
+	}
+	if b == nil {
+		return a, true
+	}
+
+	c, err := typ.Compare(a.Value, b.Value)
+	if err != nil {
+		return nil, false
+	}
+
+	switch {
+	case c*sign > 0:
+		return a, true
+	case c*sign < 0:
+		return b, true
+	case !a.Inclusive:
+		return a, true
+	default:
+		return b, true
+	}
+}
+
 func getMultiColumnIndexForExpressions(
 	ctx *sql.Context,
 	a *Analyzer,
@@ -688,7 +852,7 @@ func getMultiColumnIndexForExpressions(
 	if index != nil {
 		var first sql.Expression
 		for _, e := range exprs {
-			if e.col == selected[0] {
+			if e.colExpr == selected[0] {
 				first = e.comparison
 				break
 			}
@@ -808,7 +972,7 @@ func extractComparands(colExprs []*joinColExpr) []sql.Expression {
 
 func findColumn(cols []joinColExpr, column string) *joinColExpr {
 	for _, col := range cols {
-		if col.col.String() == column {
+		if col.colExpr.String() == column {
 			return &col
 		}
 	}
@@ -838,6 +1002,7 @@ func extractColumnExpr(e sql.Expression) (string, *joinColExpr) {
 			// TODO: handle this better
 			colExpr = &joinColExpr{
 				col:        colExpr.col,
+				colExpr:    colExpr.colExpr,
 				comparand:  colExpr.comparand,
 				comparison: expression.NewNot(colExpr.comparison),
 			}
@@ -882,7 +1047,7 @@ func extractColumnExpr(e sql.Expression) (string, *joinColExpr) {
 		}
 
 		// TODO: handle this better
-		return col.Table(), &joinColExpr{col: col, comparison: e}
+		return col.Table(), &joinColExpr{col: col, colExpr: e.Val, comparison: e}
 	default:
 		return "", nil
 	}
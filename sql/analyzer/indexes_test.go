@@ -1066,6 +1066,13 @@ func TestGetMultiColumnIndexes(t *testing.T) {
 				col(2, "t4", "bar"),
 			},
 		},
+		{
+			TableName: "t5",
+			Exprs: []sql.Expression{
+				col(0, "t5", "foo"),
+				col(1, "t5", "bar"),
+			},
+		},
 	}
 
 	for _, idx := range indexes {
@@ -1112,6 +1119,19 @@ func TestGetMultiColumnIndexes(t *testing.T) {
 			lit(1),
 			lit(6),
 		),
+		eq(
+			col(0, "t5", "foo"),
+			lit(1),
+		),
+		expression.NewBetween(
+			col(1, "t5", "bar"),
+			lit(2),
+			lit(5),
+		),
+		lt(
+			col(1, "t5", "bar"),
+			lit(4),
+		),
 	}
 
 	ctx := sql.NewContext(context.Background(), sql.WithIndexRegistry(idxReg))
@@ -1137,23 +1157,25 @@ func TestGetMultiColumnIndexes(t *testing.T) {
 			[]sql.Index{indexes[1]},
 		},
 		"t4": &indexLookup{
-			&memory.MergedIndexLookup{
-				Unions: []sql.IndexLookup{
-					&memory.AscendIndexLookup{
-						Gte:   []interface{}{int64(1), int64(2)},
-						Lt:    []interface{}{int64(6), int64(5)},
-						Index: indexes[4],
-					},
-					&memory.DescendIndexLookup{
-						Gt:    []interface{}{int64(1), int64(2)},
-						Lte:   []interface{}{int64(6), int64(5)},
-						Index: indexes[4],
-					},
+			&memory.RangeIndexLookup{
+				Ranges: []sql.IndexRange{
+					{Lower: &sql.IndexBound{Value: int64(1), Inclusive: true}, Upper: &sql.IndexBound{Value: int64(6), Inclusive: true}},
+					{Lower: &sql.IndexBound{Value: int64(2), Inclusive: true}, Upper: &sql.IndexBound{Value: int64(5), Inclusive: true}},
 				},
 				Index: indexes[4],
 			},
 			[]sql.Index{indexes[4]},
 		},
+		"t5": &indexLookup{
+			&memory.RangeIndexLookup{
+				Ranges: []sql.IndexRange{
+					{Lower: &sql.IndexBound{Value: int64(1), Inclusive: true}, Upper: &sql.IndexBound{Value: int64(1), Inclusive: true}},
+					{Lower: &sql.IndexBound{Value: int64(2), Inclusive: true}, Upper: &sql.IndexBound{Value: int64(4)}},
+				},
+				Index: indexes[5],
+			},
+			[]sql.Index{indexes[5]},
+		},
 	}
 
 	require.Equal(expected, result)
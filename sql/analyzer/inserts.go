@@ -31,10 +31,22 @@ func resolveInsertRows(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope)
 		return nil, err
 	}
 
+	// The rows are checked against the row policies of the table once they match its schema.
+	check, ok := insert.Right().(*plan.RowPolicyCheck)
+	if ok {
+		n, err = insert.WithChildren(insert.Left(), check.Child)
+		if err != nil {
+			return nil, err
+		}
+		insert = n.(*plan.InsertInto)
+	}
+
+	_, upsertable := insertable.(sql.UpsertableTable)
+
 	if insert.IsReplace {
 		var ok bool
 		_, ok = insertable.(sql.ReplaceableTable)
-		if !ok {
+		if !ok && !upsertable {
 			return nil, plan.ErrReplaceIntoNotSupported.New()
 		}
 	}
@@ -42,19 +54,20 @@ func resolveInsertRows(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope)
 	if len(insert.OnDupExprs) > 0 {
 		var ok bool
 		_, ok = insertable.(sql.UpdatableTable)
-		if !ok {
+		if !ok && !upsertable {
 			return nil, plan.ErrOnDuplicateKeyUpdateNotSupported.New()
 		}
 	}
 
 	dstSchema := insertable.Schema()
 
-	// If no columns are given, use the full schema
+	// If no columns are given, use the visible columns of the schema
 	columnNames := insert.ColumnNames
 	if len(columnNames) == 0 {
-		columnNames = make([]string, len(dstSchema))
-		for i, f := range dstSchema {
-			columnNames[i] = f.Name
+		for _, f := range dstSchema {
+			if !f.Invisible {
+				columnNames = append(columnNames, f.Name)
+			}
 		}
 	} else {
 		err = validateColumns(columnNames, dstSchema)
@@ -68,11 +81,19 @@ func resolveInsertRows(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope)
 		return nil, err
 	}
 
-	project, err := wrapRowSource(ctx, insert, insertable, columnNames)
+	var project sql.Node
+	project, err = wrapRowSource(ctx, insert, insertable, columnNames)
 	if err != nil {
 		return nil, err
 	}
 
+	if check != nil {
+		project, err = check.WithChildren(project)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return insert.WithChildren(insert.Left(), project)
 }
 
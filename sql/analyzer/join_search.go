@@ -25,7 +25,7 @@ import (
 )
 
 // orderTables returns an access order for the tables provided, attempting to minimize total query cost
-func orderTables(tables []NameableNode, tablesByName map[string]NameableNode, joinIndexes joinIndexesByTable) []string {
+func orderTables(ctx *sql.Context, tables []NameableNode, tablesByName map[string]NameableNode, joinIndexes joinIndexesByTable) []string {
 	tableNames := make([]string, len(tablesByName))
 	indexes := make([]int, len(tablesByName))
 	for i, table := range tables {
@@ -33,12 +33,14 @@ func orderTables(tables []NameableNode, tablesByName map[string]NameableNode, jo
 		indexes[i] = i
 	}
 
+	stats := tableStatistics(ctx, tablesByName)
+
 	// generate all permutations of table order
 	accessOrders := permutations(indexes)
 	lowestCost := int64(math.MaxInt64)
 	lowestCostIdx := 0
 	for i, accessOrder := range accessOrders {
-		cost := estimateTableOrderCost(tableNames, tablesByName, accessOrder, joinIndexes, lowestCost)
+		cost := estimateTableOrderCost(tableNames, tablesByName, stats, accessOrder, joinIndexes, lowestCost)
 		if cost < lowestCost {
 			lowestCost = cost
 			lowestCostIdx = i
@@ -76,10 +78,12 @@ func buildJoinTree(
 }
 
 // Estimates the cost of the table ordering given. Lower numbers are better. Bails out and returns cost so far if cost
-// exceeds lowest found so far. We could do this better if we had table and key statistics.
+// exceeds lowest found so far. The tables without statistics are estimated to have 1000 rows, and the index lookups
+// without them to return one.
 func estimateTableOrderCost(
 	tables []string,
 	tableNodes map[string]NameableNode,
+	stats map[string]*sql.TableStatistics,
 	accessOrder []int,
 	joinIndexes joinIndexesByTable,
 	lowestCost int64,
@@ -109,18 +113,64 @@ func estimateTableOrderCost(
 			}
 		}
 
-		if i == 0 || indexes.getUsableIndex(availableSchemaForKeys) == nil {
-			// TODO: estimate number of rows in table
-			cost *= 1000
+		rows := int64(1000)
+		if s := stats[table]; s != nil {
+			rows = int64(s.RowCount)
+			if rows < 1 {
+				rows = 1
+			}
+		}
+
+		usableIndex := indexes.getUsableIndex(availableSchemaForKeys)
+		if i == 0 || usableIndex == nil {
+			if cost > math.MaxInt64/rows {
+				return math.MaxInt64
+			}
+			cost *= rows
 		} else {
-			// TODO: estimate number of rows from index lookup based on cardinality
-			cost += 1
+			cost += lookupRows(stats[table], usableIndex.index, rows)
 		}
 	}
 
 	return cost
 }
 
+// tableStatistics returns the statistics of the tables given that have them, by their names.
+func tableStatistics(ctx *sql.Context, tables map[string]NameableNode) map[string]*sql.TableStatistics {
+	stats := make(map[string]*sql.TableStatistics)
+	for name, node := range tables {
+		table := getTable(node)
+		if table == nil {
+			continue
+		}
+
+		// The statistics are only estimates, so the tables failing to get theirs are planned without them.
+		if s, err := sql.GetTableStatistics(ctx, table); err == nil && s != nil {
+			stats[name] = s
+		}
+	}
+	return stats
+}
+
+// lookupRows returns the estimated number of rows an index lookup on a table with the rows given returns, which is
+// the number of rows per distinct value of the index if its cardinality is known.
+func lookupRows(stats *sql.TableStatistics, index sql.Index, rows int64) int64 {
+	if stats == nil {
+		return 1
+	}
+
+	cardinality, ok := stats.IndexCardinality[index.ID()]
+	if !ok || cardinality == 0 {
+		return 1
+	}
+
+	n := rows / int64(cardinality)
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
 // colsIncludeTable returns whether the columns given contain the table given
 func colsIncludeTable(cols []*expression.GetField, table string) bool {
 	for _, col := range cols {
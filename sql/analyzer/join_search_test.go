@@ -19,8 +19,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/dolthub/go-mysql-server/memory"
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
 )
 
 func TestBuildJoinTree(t *testing.T) {
@@ -578,3 +582,37 @@ func jt(name string) *joinSearchNode {
 		table: name,
 	}
 }
+
+func TestOrderTablesWithStatistics(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	// newTable returns a table with the number of rows given and an index on its column x, whose rows have the number
+	// of distinct values of x given.
+	newTable := func(name string, rows, distinct int) (*plan.ResolvedTable, sql.Index) {
+		table := memory.NewTable(name, sql.Schema{
+			{Name: "i", Type: sql.Int64, Source: name, PrimaryKey: true},
+			{Name: "x", Type: sql.Int64, Source: name},
+		})
+		for i := 0; i < rows; i++ {
+			require.NoError(table.Insert(ctx, sql.NewRow(int64(i), int64(i%distinct))))
+		}
+		require.NoError(table.CreateIndex(ctx, name+"_x", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "x"}}, ""))
+		indexes, err := table.GetIndexes(ctx)
+		require.NoError(err)
+		return plan.NewResolvedTable(table), indexes[0]
+	}
+
+	a, aIndex := newTable("a", 100, 50)
+	b, bIndex := newTable("b", 2, 2)
+	aX := expression.NewGetFieldWithTable(1, sql.Int64, "a", "x", false)
+	bX := expression.NewGetFieldWithTable(1, sql.Int64, "b", "x", false)
+	joinIndexes := joinIndexesByTable{
+		"a": {{table: "a", index: aIndex, cols: []*expression.GetField{aX}, comparandCols: []*expression.GetField{bX}}},
+		"b": {{table: "b", index: bIndex, cols: []*expression.GetField{bX}, comparandCols: []*expression.GetField{aX}}},
+	}
+
+	// Scanning the small table and looking up the two rows per value of the big one is the cheapest.
+	tables := []NameableNode{a, b}
+	require.Equal([]string{"b", "a"}, orderTables(ctx, tables, byLowerCaseName(tables), joinIndexes))
+}
@@ -216,89 +216,6 @@ func expressionSources(expr sql.Expression) []string {
 	return result
 }
 
-// evalFilter simplifies the expressions in Filter nodes where possible. This involves removing redundant parts of AND
-// and OR expressions, as well as replacing evaluable expressions with their literal result. Filters that can
-// statically be determined to be true or false are replaced with the child node or an empty result, respectively.
-func evalFilter(ctx *sql.Context, a *Analyzer, node sql.Node, scope *Scope) (sql.Node, error) {
-	if !node.Resolved() {
-		return node, nil
-	}
-
-	return plan.TransformUp(node, func(node sql.Node) (sql.Node, error) {
-		filter, ok := node.(*plan.Filter)
-		if !ok {
-			return node, nil
-		}
-
-		e, err := expression.TransformUp(filter.Expression, func(e sql.Expression) (sql.Expression, error) {
-			switch e := e.(type) {
-			case *expression.Or:
-				if isTrue(e.Left) {
-					return e.Left, nil
-				}
-
-				if isTrue(e.Right) {
-					return e.Right, nil
-				}
-
-				if isFalse(e.Left) {
-					return e.Right, nil
-				}
-
-				if isFalse(e.Right) {
-					return e.Left, nil
-				}
-
-				return e, nil
-			case *expression.And:
-				if isFalse(e.Left) {
-					return e.Left, nil
-				}
-
-				if isFalse(e.Right) {
-					return e.Right, nil
-				}
-
-				if isTrue(e.Left) {
-					return e.Right, nil
-				}
-
-				if isTrue(e.Right) {
-					return e.Left, nil
-				}
-
-				return e, nil
-			case *expression.Literal, expression.Tuple, *expression.Interval:
-				return e, nil
-			default:
-				if !isEvaluable(e) {
-					return e, nil
-				}
-
-				// All other expressions types can be evaluated once and turned into literals for the rest of query execution
-				val, err := e.Eval(ctx, nil)
-				if err != nil {
-					return e, nil
-				}
-				return expression.NewLiteral(val, e.Type()), nil
-			}
-		})
-		if err != nil {
-			return nil, err
-		}
-
-		if isFalse(e) {
-			return plan.EmptyTable, nil
-		}
-
-		if isTrue(e) {
-			return filter.Child, nil
-		}
-
-		return plan.NewFilter(e, filter.Child), nil
-	})
-}
-
 func isFalse(e sql.Expression) bool {
 	lit, ok := e.(*expression.Literal)
 	if ok && lit != nil && lit.Type() == sql.Boolean && lit.Value() != nil {
@@ -243,109 +243,6 @@ func TestMoveJoinConditionsToFilter(t *testing.T) {
 	assertNodesEqualWithDiff(t, expected, result)
 }
 
-func TestEvalFilter(t *testing.T) {
-	inner := memory.NewTable("foo", nil)
-	rule := getRule("eval_filter")
-
-	testCases := []struct {
-		filter   sql.Expression
-		expected sql.Node
-	}{
-		{
-			and(
-				eq(lit(5), lit(5)),
-				eq(col(0, "foo", "bar"), lit(5)),
-			),
-			plan.NewFilter(
-				eq(col(0, "foo", "bar"), lit(5)),
-				plan.NewResolvedTable(inner),
-			),
-		},
-		{
-			and(
-				eq(col(0, "foo", "bar"), lit(5)),
-				eq(lit(5), lit(5)),
-			),
-			plan.NewFilter(
-				eq(col(0, "foo", "bar"), lit(5)),
-				plan.NewResolvedTable(inner),
-			),
-		},
-		{
-			and(
-				eq(lit(5), lit(4)),
-				eq(col(0, "foo", "bar"), lit(5)),
-			),
-			plan.EmptyTable,
-		},
-		{
-			and(
-				eq(col(0, "foo", "bar"), lit(5)),
-				eq(lit(5), lit(4)),
-			),
-			plan.EmptyTable,
-		},
-		{
-			and(
-				eq(lit(4), lit(4)),
-				eq(lit(5), lit(5)),
-			),
-			plan.NewResolvedTable(inner),
-		},
-		{
-			or(
-				eq(lit(5), lit(4)),
-				eq(col(0, "foo", "bar"), lit(5)),
-			),
-			plan.NewFilter(
-				eq(col(0, "foo", "bar"), lit(5)),
-				plan.NewResolvedTable(inner),
-			),
-		},
-		{
-			or(
-				eq(col(0, "foo", "bar"), lit(5)),
-				eq(lit(5), lit(4)),
-			),
-			plan.NewFilter(
-				eq(col(0, "foo", "bar"), lit(5)),
-				plan.NewResolvedTable(inner),
-			),
-		},
-		{
-			or(
-				eq(lit(5), lit(5)),
-				eq(col(0, "foo", "bar"), lit(5)),
-			),
-			plan.NewResolvedTable(inner),
-		},
-		{
-			or(
-				eq(col(0, "foo", "bar"), lit(5)),
-				eq(lit(5), lit(5)),
-			),
-			plan.NewResolvedTable(inner),
-		},
-		{
-			or(
-				eq(lit(5), lit(4)),
-				eq(lit(5), lit(4)),
-			),
-			plan.EmptyTable,
-		},
-	}
-
-	for _, tt := range testCases {
-		t.Run(tt.filter.String(), func(t *testing.T) {
-			require := require.New(t)
-			node := plan.NewFilter(tt.filter, plan.NewResolvedTable(inner))
-			result, err := rule.Apply(sql.NewEmptyContext(), NewDefault(nil), node, nil)
-			require.NoError(err)
-			require.Equal(tt.expected, result)
-		})
-	}
-}
-
 func TestRemoveUnnecessaryConverts(t *testing.T) {
 	testCases := []struct {
 		name      string
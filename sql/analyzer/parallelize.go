@@ -31,7 +31,7 @@ func isDdlNode(node sql.Node) bool {
 	switch node.(type) {
 	case *plan.CreateTable, *plan.DropTable,
 		*plan.AddColumn, *plan.ModifyColumn, *plan.DropColumn,
-		*plan.RenameTable, *plan.RenameColumn,
+		*plan.RenameTable, *plan.RenameColumn, *plan.AlterColumnVisibility,
 		*plan.CreateIndex, *plan.AlterIndex, *plan.DropIndex,
 		*plan.CreateForeignKey, *plan.DropForeignKey,
 		*plan.CreateTrigger, *plan.DropTrigger,
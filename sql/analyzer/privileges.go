@@ -0,0 +1,475 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// privilegeCheck is a set of privileges needed on a table, on a database if the table is empty, or globally if the
+// database is empty too.
+type privilegeCheck struct {
+	db, table  string
+	privileges sql.Privilege
+	// columns is true if the privileges may be granted on the columns the
+	// query uses instead of on the table.
+	columns bool
+}
+
+// checkPrivileges checks that the account of the session has the privileges needed to execute the query, when the
+// user manager of the catalog manages privileges. It runs on the parsed query, so views are checked by their name,
+// and the tables they read need to be readable by the account as well. Trigger bodies are checked when the trigger
+// is created, since the account creating it is the definer they run as.
+func checkPrivileges(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	if a.Catalog == nil || scope != nil {
+		return n, nil
+	}
+
+	pm, err := a.Catalog.PrivilegeManager()
+	if sql.ErrPrivilegesNotSupported.Is(err) {
+		return n, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	span, _ := ctx.Span("check_privileges")
+	defer span.Finish()
+
+	account, err := pm.CurrentAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	privileges, err := sql.ActivePrivileges(ctx, pm, account)
+	if err != nil {
+		return nil, err
+	}
+
+	c := privilegeCollector{currentDB: ctx.GetCurrentDatabase(), account: account}
+	c.node(n, sql.PrivilegeSelect)
+
+	var restricted []*restrictedTable
+	for _, check := range c.checks {
+		if privileges.Has(check.db, check.table, check.privileges) {
+			continue
+		}
+
+		missing := check.privileges
+		for _, g := range privileges {
+			if g.Level.Contains(check.db, check.table) {
+				missing &^= g.Privileges
+			}
+		}
+
+		if check.columns && missing&^sql.ColumnPrivileges == 0 && privileges.HasAnyColumn(check.db, check.table, missing) {
+			restricted = addRestrictedTable(restricted, check.db, check.table, missing)
+			continue
+		}
+
+		switch {
+		case check.db == "":
+			return nil, sql.ErrSpecificAccessDenied.New(strings.Join(missing.Names(), ", "))
+		case check.table == "":
+			return nil, sql.ErrDatabaseAccessDenied.New(account, check.db)
+		default:
+			return nil, sql.ErrTableAccessDenied.New(missing.Names()[0], account, check.table)
+		}
+	}
+
+	if len(c.roles) > 0 && !privileges.Has("", "", sql.PrivilegeCreateUser) {
+		if err := checkRoleAdmin(ctx, pm, account, c.roles); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.proxied) > 0 && !privileges.Has("", "", sql.PrivilegeCreateUser) {
+		if err := checkProxyAdmin(ctx, pm, account, c.proxied); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(restricted) > 0 {
+		return checkColumnPrivileges(ctx, a, n, account, privileges, restricted)
+	}
+
+	return n, nil
+}
+
+// checkRoleAdmin checks that the account given, or one of its active roles, has been granted the roles given with
+// ADMIN OPTION.
+func checkRoleAdmin(ctx *sql.Context, pm sql.PrivilegeManager, account sql.Account, roles []sql.Account) error {
+	rm, ok := pm.(sql.RoleManager)
+	if !ok {
+		// The statement fails when executed.
+		return nil
+	}
+
+	grantees, err := sql.ActiveRoles(ctx, rm, account)
+	if err != nil {
+		return err
+	}
+	grantees = append(grantees, account)
+
+	var admin []sql.Account
+	for _, g := range grantees {
+		granted, err := rm.Roles(ctx, g)
+		if err != nil && !sql.ErrUserNotFound.Is(err) {
+			return err
+		}
+
+		for _, r := range granted {
+			if r.WithAdminOption {
+				admin = append(admin, r.Role)
+			}
+		}
+	}
+
+	for _, r := range roles {
+		var found bool
+		for _, a := range admin {
+			found = found || a.Equal(r)
+		}
+
+		if !found {
+			return sql.ErrSpecificAccessDenied.New("WITH ADMIN, CREATE USER")
+		}
+	}
+
+	return nil
+}
+
+// checkProxyAdmin checks that the account given has been granted PROXY with GRANT OPTION on the accounts given.
+func checkProxyAdmin(ctx *sql.Context, pm sql.PrivilegeManager, account sql.Account, proxied []sql.Account) error {
+	xm, ok := pm.(sql.ProxyManager)
+	if !ok {
+		// The statement fails when executed.
+		return nil
+	}
+
+	granted, err := xm.Proxies(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range proxied {
+		var found bool
+		for _, g := range granted {
+			found = found || g.WithGrantOption && g.Proxied.Equal(p)
+		}
+
+		if !found {
+			return sql.ErrSpecificAccessDenied.New("PROXY WITH GRANT OPTION, CREATE USER")
+		}
+	}
+
+	return nil
+}
+
+// privilegeCollector collects the privileges needed to execute a query.
+type privilegeCollector struct {
+	currentDB string
+	account   sql.Account
+	checks    []privilegeCheck
+	// inView is true while collecting the privileges of a view definition,
+	// which can't be changed to hide columns.
+	inView bool
+	// roles are the roles granted or revoked, which need ADMIN OPTION.
+	roles []sql.Account
+	// proxied are the accounts PROXY is granted or revoked on, which needs
+	// GRANT OPTION.
+	proxied []sql.Account
+}
+
+func (c *privilegeCollector) add(db, table string, privileges sql.Privilege) {
+	c.addCheck(db, table, privileges, false)
+}
+
+func (c *privilegeCollector) addCheck(db, table string, privileges sql.Privilege, columns bool) {
+	if db == "" {
+		db = c.currentDB
+	}
+
+	// Queries without a database fail to resolve anyway, and everyone can
+	// read the information schema.
+	if db == "" || strings.EqualFold(db, "information_schema") {
+		return
+	}
+
+	c.checks = append(c.checks, privilegeCheck{db, table, privileges, columns})
+}
+
+func (c *privilegeCollector) global(privileges sql.Privilege) {
+	c.checks = append(c.checks, privilegeCheck{privileges: privileges})
+}
+
+// level adds the privileges given on a privilege level.
+func (c *privilegeCollector) level(level sql.PrivilegeLevel, privileges sql.Privilege) {
+	if level.Database == "" {
+		c.global(privileges)
+	} else {
+		c.add(level.Database, level.Table, privileges)
+	}
+}
+
+// table adds the privileges given on a table node.
+func (c *privilegeCollector) table(n sql.Node, privileges sql.Privilege) {
+	if t, ok := n.(*plan.UnresolvedTable); ok {
+		c.add(t.Database, t.Name(), privileges)
+	}
+}
+
+// columns adds the privileges given on a table node, which may be granted on
+// the columns the query uses instead.
+func (c *privilegeCollector) columns(n sql.Node, privileges sql.Privilege) {
+	if t, ok := n.(*plan.UnresolvedTable); ok {
+		c.addCheck(t.Database, t.Name(), privileges, !c.inView)
+	}
+}
+
+func databaseName(db sql.Database) string {
+	if db == nil {
+		return ""
+	}
+	return db.Name()
+}
+
+// node adds the privileges needed to execute a node, where the tables read need the privileges given.
+func (c *privilegeCollector) node(n sql.Node, tablePrivileges sql.Privilege) {
+	plan.Inspect(n, func(n sql.Node) bool {
+		if e, ok := n.(sql.Expressioner); ok {
+			c.expressions(e.Expressions())
+		}
+
+		switch n := n.(type) {
+		case *plan.UnresolvedTable:
+			if n.Database != "" || !strings.EqualFold(n.Name(), "dual") {
+				c.columns(n, tablePrivileges)
+			}
+		case *plan.InsertInto:
+			privileges := sql.PrivilegeInsert
+			if n.IsReplace {
+				privileges |= sql.PrivilegeDelete
+			}
+			if len(n.OnDupExprs) > 0 {
+				privileges |= sql.PrivilegeUpdate
+			}
+			c.columns(n.Left(), privileges)
+			c.node(n.Right(), sql.PrivilegeSelect)
+			return false
+		case *plan.Update:
+			c.node(n.Child, sql.PrivilegeUpdate)
+			return false
+		case *plan.DeleteFrom:
+			if len(n.Targets) == 0 {
+				c.node(n.Child, sql.PrivilegeDelete)
+				return false
+			}
+			// The rows of the tables joined with the ones deleted from are only read
+			c.node(n.Child, sql.PrivilegeSelect)
+			if tables, err := n.TargetTables(); err == nil {
+				for _, table := range tables {
+					c.node(table, sql.PrivilegeDelete)
+				}
+			}
+			return false
+		case *plan.CreateDatabase:
+			c.add(n.Name, "", sql.PrivilegeCreate)
+		case *plan.AlterDatabase:
+			c.add(databaseName(n.Database()), "", sql.PrivilegeAlter)
+		case *plan.CreateTable:
+			c.add(databaseName(n.Database()), n.Name(), sql.PrivilegeCreate)
+			if n.Like() != nil {
+				c.node(n.Like(), sql.PrivilegeSelect)
+			}
+			return false
+		case *plan.DropTable:
+			for _, t := range n.TableNames() {
+				c.add(databaseName(n.Database()), t, sql.PrivilegeDrop)
+			}
+		case *plan.RenameTable:
+			for _, t := range n.OldNames() {
+				c.add(databaseName(n.Database()), t, sql.PrivilegeAlter|sql.PrivilegeDrop)
+			}
+			for _, t := range n.NewNames() {
+				c.add(databaseName(n.Database()), t, sql.PrivilegeCreate|sql.PrivilegeInsert)
+			}
+		case *plan.AddColumn:
+			c.add(databaseName(n.Database()), n.TableName(), sql.PrivilegeAlter)
+		case *plan.DropColumn:
+			c.add(databaseName(n.Database()), n.TableName(), sql.PrivilegeAlter)
+		case *plan.RenameColumn:
+			c.add(databaseName(n.Database()), n.TableName(), sql.PrivilegeAlter)
+		case *plan.ModifyColumn:
+			c.add(databaseName(n.Database()), n.TableName(), sql.PrivilegeAlter)
+		case *plan.AlterColumnVisibility:
+			c.add(databaseName(n.Database()), n.TableName(), sql.PrivilegeAlter)
+		case *plan.AlterAutoIncrement:
+			c.table(n.Child, sql.PrivilegeAlter)
+			return false
+		case *plan.AlterIndex:
+			c.table(n.Table, sql.PrivilegeAlter)
+			return false
+		case *plan.CreateIndex:
+			c.table(n.Table, sql.PrivilegeIndex)
+			return false
+		case *plan.DropIndex:
+			c.table(n.Table, sql.PrivilegeIndex)
+			return false
+		case *plan.CreateForeignKey:
+			c.table(n.Left(), sql.PrivilegeAlter)
+			c.table(n.Right(), sql.PrivilegeReferences)
+			return false
+		case *plan.DropForeignKey:
+			c.table(n.Child, sql.PrivilegeAlter)
+			return false
+		case *plan.CreateCheck:
+			c.table(n.Child, sql.PrivilegeAlter)
+			return false
+		case *plan.DropCheck:
+			c.table(n.Child, sql.PrivilegeAlter)
+			return false
+		case *plan.DropConstraint:
+			c.table(n.Child, sql.PrivilegeAlter)
+			return false
+		case *plan.CreateView:
+			privileges := sql.PrivilegeCreateView
+			if n.IsReplace {
+				privileges |= sql.PrivilegeDrop
+			}
+			c.add(databaseName(n.Database()), n.Name, privileges)
+			inView := c.inView
+			c.inView = true
+			c.node(n.Definition, sql.PrivilegeSelect)
+			c.inView = inView
+			return false
+		case *plan.SingleDropView:
+			c.add(databaseName(n.Database()), n.ViewName(), sql.PrivilegeDrop)
+		case *plan.CreateTrigger:
+			c.table(n.Table, sql.PrivilegeTrigger)
+			c.node(n.Body, sql.PrivilegeSelect)
+			return false
+		case *plan.DropTrigger:
+			c.add(databaseName(n.Database()), "", sql.PrivilegeTrigger)
+		case *plan.LockTables:
+			for _, l := range n.Locks {
+				if t, ok := l.Table.(*plan.UnresolvedTable); ok {
+					c.add(t.Database, "", sql.PrivilegeLockTables)
+					c.add(t.Database, t.Name(), sql.PrivilegeSelect)
+				}
+			}
+		case *plan.CreateUser, *plan.AlterUser, *plan.DropUser, *plan.CreateRole, *plan.DropRole:
+			c.global(sql.PrivilegeCreateUser)
+		case *plan.GrantRole:
+			c.roles = append(c.roles, n.Roles...)
+		case *plan.RevokeRole:
+			c.roles = append(c.roles, n.Roles...)
+		case *plan.GrantProxy:
+			c.proxied = append(c.proxied, n.Proxied)
+		case *plan.RevokeProxy:
+			c.proxied = append(c.proxied, n.Proxied)
+		case *plan.SetDefaultRole:
+			for _, a := range n.Accounts {
+				if !a.Equal(c.account) {
+					c.global(sql.PrivilegeCreateUser)
+					break
+				}
+			}
+		case *plan.SetPassword:
+			if n.For != nil {
+				c.global(sql.PrivilegeCreateUser)
+			}
+		case *plan.ShowBinaryLogs, *plan.ShowMasterStatus, *plan.ShowReplicaStatus:
+			c.global(sql.PrivilegeReplicationClient)
+		case *plan.ChangeReplicationSource, *plan.StartReplica, *plan.StopReplica:
+			c.global(sql.PrivilegeSuper)
+		case *plan.Set:
+			if setsGlobalVariables(n) {
+				c.global(sql.PrivilegeSuper)
+			}
+		case *plan.ResetPersist:
+			c.global(sql.PrivilegeSuper)
+		case *plan.ShowBinlogEvents:
+			c.global(sql.PrivilegeReplicationSlave)
+		case *plan.Dump:
+			if len(n.Targets) == 0 {
+				c.global(sql.PrivilegeSelect | sql.PrivilegeLockTables)
+			}
+			for _, t := range n.Targets {
+				if len(t.Tables) == 0 {
+					c.add(t.Database, "", sql.PrivilegeSelect|sql.PrivilegeLockTables)
+					continue
+				}
+				c.add(t.Database, "", sql.PrivilegeLockTables)
+				for _, name := range t.Tables {
+					c.add(t.Database, name, sql.PrivilegeSelect)
+				}
+			}
+		case *plan.ShowGrants:
+			if n.For != nil && !n.For.Equal(c.account) {
+				c.global(sql.PrivilegeCreateUser)
+			}
+		case *plan.Grant:
+			// Illegal grants are reported when executed.
+			if privileges, err := n.Granted(); err == nil {
+				c.level(n.Level, privileges|columnPrivileges(n.Columns)|sql.PrivilegeGrantOption)
+			}
+		case *plan.Revoke:
+			if n.All {
+				c.global(sql.PrivilegeCreateUser)
+			} else if privileges, err := n.Revoked(); err == nil {
+				c.level(n.Level, privileges|columnPrivileges(n.Columns)|sql.PrivilegeGrantOption)
+			}
+		}
+
+		return true
+	})
+}
+
+// setsGlobalVariables returns whether a SET statement sets or persists the global value of a system variable, which
+// changes the variables of every session and so needs the SUPER privilege.
+func setsGlobalVariables(n *plan.Set) bool {
+	for _, e := range n.Exprs {
+		sf, ok := e.(*expression.SetField)
+		if !ok {
+			continue
+		}
+
+		switch left := sf.Left.(type) {
+		case *expression.SystemVar:
+			if left.Global || left.Persist != expression.NoPersist {
+				return true
+			}
+		case column:
+			if !isSystemVariable(left) {
+				continue
+			}
+			if global, _ := systemVariableScope(left); global || systemVariablePersist(left) != expression.NoPersist {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expressions adds the privileges needed by the subqueries of the expressions given.
+func (c *privilegeCollector) expressions(exprs []sql.Expression) {
+	for _, e := range exprs {
+		sql.Inspect(e, func(e sql.Expression) bool {
+			if s, ok := e.(*plan.Subquery); ok {
+				c.node(s.Query, sql.PrivilegeSelect)
+			}
+			return true
+		})
+	}
+}
+
+// columnPrivileges returns the union of the privileges granted on columns.
+func columnPrivileges(columns []sql.ColumnGrant) sql.Privilege {
+	var privileges sql.Privilege
+	for _, c := range columns {
+		privileges |= c.Privileges
+	}
+	return privileges
+}
@@ -1,10 +1,17 @@
 package analyzer
 
 import (
+	"github.com/go-kit/kit/metrics/discard"
+
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/plan"
 )
 
+var (
+	// RowsReadCounter describes a metric that accumulates number of rows read from tables monotonically.
+	RowsReadCounter = discard.NewCounter()
+)
+
 // trackProcess will wrap the query in a process node and add progress items
 // to the already existing process.
 func trackProcess(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
@@ -19,6 +26,7 @@ func trackProcess(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.
 	processList := a.Catalog.ProcessList
 
 	var seen = make(map[string]struct{})
+	indexed := indexedTables(n)
 	n, err := plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
 		switch n := n.(type) {
 		case *plan.ResolvedTable:
@@ -49,12 +57,24 @@ func trackProcess(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.
 				processList.RemovePartitionProgress(ctx.Pid(), name, partitionName)
 			}
 
+			// Tables read through an index count as key lookups followed by
+			// the next entries of the index, the rest as full scans.
+			readStart, readNext := sql.StatusHandlerReadFirst, sql.StatusHandlerReadRndNext
+			if indexed[name] {
+				readStart, readNext = sql.StatusHandlerReadKey, sql.StatusHandlerReadNext
+			}
+
 			onPartitionStart := func(partitionName string) {
 				processList.AddPartitionProgress(ctx.Pid(), name, partitionName, -1)
+				a.Catalog.IncrementStatus(ctx, readStart, 1)
 			}
 
 			onRowNext := func(partitionName string) {
 				processList.UpdatePartitionProgress(ctx.Pid(), name, partitionName, 1)
+				processList.AddRowsRead(ctx.Pid(), name, 1)
+				RowsReadCounter.Add(1)
+				a.Catalog.IncrementStatus(ctx, readNext, 1)
+				a.Catalog.GlobalStatus.Add(sql.StatusInnodbRowsRead, 1)
 			}
 
 			var t sql.Table
@@ -65,7 +85,7 @@ func trackProcess(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.
 				t = plan.NewProcessTable(table, onPartitionDone, onPartitionStart, onRowNext)
 			}
 
-			return plan.NewResolvedTable(t), nil
+			return n.WithTable(t), nil
 		default:
 			return n, nil
 		}
@@ -106,3 +126,24 @@ func trackProcess(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.
 		}
 	}), nil
 }
+
+// indexedTables returns the names of the tables of a node that are read
+// through an index lookup.
+func indexedTables(n sql.Node) map[string]bool {
+	tables := make(map[string]bool)
+	plan.Inspect(n, func(n sql.Node) bool {
+		d, ok := n.(*plan.DecoratedNode)
+		if !ok || d.DecorationType != plan.DecorationTypeIndexedAccess {
+			return true
+		}
+
+		plan.Inspect(d.Child, func(n sql.Node) bool {
+			if t, ok := n.(*plan.ResolvedTable); ok {
+				tables[t.Table.Name()] = true
+			}
+			return true
+		})
+		return false
+	})
+	return tables
+}
@@ -11,7 +11,8 @@ import (
 
 // pushdownFilters attempts to push conditions in filters down to individual tables. Tables that implement
 // sql.FilteredTable will get such conditions applied to them. For conditions that have an index, tables that implement
-// sql.IndexAddressableTable will get an appropriate index lookup applied.
+// sql.IndexAddressableTable will get an appropriate index lookup applied, which returns the rows in order if they're
+// sorted or grouped by the index expressions.
 func pushdownFilters(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
 	span, ctx := ctx.Span("pushdown_filters")
 	defer span.Finish()
@@ -31,6 +32,11 @@ func pushdownFilters(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (s
 		return nil, err
 	}
 
+	n, err = convertOrderingsToIndexedAccess(ctx, a, n, indexes)
+	if err != nil {
+		return nil, err
+	}
+
 	n, err = convertFiltersToIndexedAccess(a, n, scope, indexes)
 	if err != nil {
 		return nil, err
@@ -469,3 +475,58 @@ func removePushedDownPredicates(a *Analyzer, node *plan.Filter, filters *filterS
 
 	return plan.NewFilter(expression.JoinAnd(unhandled...), node.Child), nil
 }
+
+// pushdownLimits gives the row count of Limit nodes to the tables below them that implement sql.LimitedTable, as long
+// as no node between them can discard, add or reorder rows. The Limit node is kept in place, since tables may return
+// more rows than the limit. The limits of queries calculating the rows found read all the rows, so they're not pushed.
+func pushdownLimits(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	if !n.Resolved() {
+		return n, nil
+	}
+
+	span, _ := ctx.Span("pushdown_limits")
+	defer span.Finish()
+
+	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
+		limit, ok := n.(*plan.Limit)
+		if !ok || limit.BindVar != "" || limit.CalcFoundRows {
+			return n, nil
+		}
+
+		child, ok, err := withTableLimit(limit.Child, limit.Limit)
+		if err != nil || !ok {
+			return n, err
+		}
+
+		a.Log("pushed down limit of %d rows to table", limit.Limit)
+		return limit.WithChildren(child)
+	})
+}
+
+// withTableLimit sets the limit given on the table at the bottom of the node given, if there's one that supports it
+// and only nodes that return one row per row of their child are found before it.
+func withTableLimit(n sql.Node, limit int64) (sql.Node, bool, error) {
+	switch n := n.(type) {
+	case *plan.ResolvedTable:
+		lt, ok := n.Table.(sql.LimitedTable)
+		if !ok {
+			return n, false, nil
+		}
+
+		if l, ok := lt.Limit(); ok && l <= limit {
+			return n, false, nil
+		}
+
+		return n.WithTable(lt.WithLimit(limit)), true, nil
+	case *plan.Project, *plan.TableAlias, *plan.DecoratedNode:
+		child, ok, err := withTableLimit(n.Children()[0], limit)
+		if err != nil || !ok {
+			return n, false, err
+		}
+
+		n2, err := n.WithChildren(child)
+		return n2, err == nil, err
+	default:
+		return n, false, nil
+	}
+}
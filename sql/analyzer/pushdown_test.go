@@ -1383,9 +1383,201 @@ func TestPushdownIndex(t *testing.T) {
 	runTestCases(t, sql.NewEmptyContext(), tests, a, getRule("pushdown_filters"))
 }
 
+func TestPushdownOrderingToIndex(t *testing.T) {
+	require := require.New(t)
+
+	table := memory.NewTable("mytable", sql.Schema{
+		{Name: "i", Type: sql.Int32, Source: "mytable"},
+		{Name: "f", Type: sql.Float64, Source: "mytable"},
+	})
+	err := table.CreateIndex(sql.NewEmptyContext(), "i_f", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{
+		{Name: "i"},
+		{Name: "f"},
+	}, "")
+	require.NoError(err)
+	err = table.CreateIndex(sql.NewEmptyContext(), "f", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{
+		{Name: "f"},
+	}, "")
+	require.NoError(err)
+	err = table.CreateIndex(sql.NewEmptyContext(), "i_f_desc", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{
+		{Name: "i"},
+		{Name: "f", Descending: true},
+	}, "")
+	require.NoError(err)
+
+	idxes, err := table.GetIndexes(sql.NewEmptyContext())
+	require.NoError(err)
+	idxF := idxes[0].(sql.OrderedIndex)
+	idx := idxes[1].(sql.OrderedIndex)
+	idxDesc := idxes[2].(sql.OrderedIndex)
+
+	db := memory.NewDatabase("")
+	db.AddTable("mytable", table)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	a := NewDefault(catalog)
+
+	i := expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", false)
+	f := expression.NewGetFieldWithTable(1, sql.Float64, "mytable", "f", false)
+	gt := expression.NewGreaterThan(f, expression.NewLiteral(1.0, sql.Float64))
+
+	tests := []analyzerFnTestCase{
+		{
+			name: "sort on index expressions",
+			node: plan.NewProject(
+				[]sql.Expression{f},
+				plan.NewSort(
+					[]plan.SortField{{Column: i, Order: plan.Descending}, {Column: f, Order: plan.Descending}},
+					plan.NewResolvedTable(table),
+				),
+			),
+			expected: plan.NewProject(
+				[]sql.Expression{f},
+				plan.NewDecoratedNode(plan.DecorationTypeIndexedAccess, "Indexed table access on index [mytable.i,mytable.f] in descending order",
+					plan.NewResolvedTable(table.WithIndexLookup(mustIndexLookup(idx.Ordered(nil, true)))),
+				),
+			),
+		},
+		{
+			name: "sort on index of filter",
+			node: plan.NewSort(
+				[]plan.SortField{{Column: f, Order: plan.Ascending}},
+				plan.NewProject(
+					[]sql.Expression{f},
+					plan.NewFilter(gt, plan.NewResolvedTable(table)),
+				),
+			),
+			expected: plan.NewProject(
+				[]sql.Expression{f},
+				plan.NewDecoratedNode(plan.DecorationTypeIndexedAccess, "Indexed table access on index [mytable.f] in ascending order",
+					plan.NewFilter(gt, plan.NewResolvedTable(table.WithIndexLookup(
+						mustIndexLookup(idxF.Ordered(mustIndexLookup(idxF.(sql.DescendIndex).DescendGreater(1.0)), false)),
+					))),
+				),
+			),
+		},
+		{
+			name: "sort in the directions of an index",
+			node: plan.NewSort(
+				[]plan.SortField{{Column: i, Order: plan.Ascending}, {Column: f, Order: plan.Descending}},
+				plan.NewResolvedTable(table),
+			),
+			expected: plan.NewDecoratedNode(plan.DecorationTypeIndexedAccess, "Indexed table access on index [mytable.i,mytable.f] in ascending order",
+				plan.NewResolvedTable(table.WithIndexLookup(mustIndexLookup(idxDesc.Ordered(nil, false)))),
+			),
+		},
+		{
+			name: "sort in the reverse directions of an index",
+			node: plan.NewSort(
+				[]plan.SortField{{Column: i, Order: plan.Descending}, {Column: f, Order: plan.Ascending}},
+				plan.NewResolvedTable(table),
+			),
+			expected: plan.NewDecoratedNode(plan.DecorationTypeIndexedAccess, "Indexed table access on index [mytable.i,mytable.f] in descending order",
+				plan.NewResolvedTable(table.WithIndexLookup(mustIndexLookup(idxDesc.Ordered(nil, true)))),
+			),
+		},
+		{
+			name: "sort on expressions not prefixing an index",
+			node: plan.NewSort(
+				[]plan.SortField{{Column: f, Order: plan.Ascending}, {Column: i, Order: plan.Ascending}},
+				plan.NewResolvedTable(table),
+			),
+		},
+		{
+			name: "group by on index expressions",
+			node: plan.NewGroupBy(
+				[]sql.Expression{f, i},
+				[]sql.Expression{f, i},
+				plan.NewResolvedTable(table),
+			),
+			expected: &plan.GroupBy{
+				UnaryNode: plan.UnaryNode{Child: plan.NewDecoratedNode(plan.DecorationTypeIndexedAccess, "Indexed table access on index [mytable.i,mytable.f] in ascending order",
+					plan.NewResolvedTable(table.WithIndexLookup(mustIndexLookup(idx.Ordered(nil, false)))),
+				)},
+				SelectedExprs: []sql.Expression{f, i},
+				GroupByExprs:  []sql.Expression{f, i},
+				Ordered:       true,
+			},
+		},
+	}
+
+	runTestCases(t, sql.NewEmptyContext(), tests, a, getRule("pushdown_filters"))
+}
+
 func mustIndexLookup(lookup sql.IndexLookup, err error) sql.IndexLookup {
 	if err != nil {
 		panic(err)
 	}
 	return lookup
 }
+
+type limitedTable struct {
+	*memory.Table
+	limit int64
+}
+
+func (t *limitedTable) WithLimit(limit int64) sql.Table {
+	return &limitedTable{t.Table, limit}
+}
+
+func (t *limitedTable) Limit() (int64, bool) {
+	return t.limit, t.limit > 0
+}
+
+func TestPushdownLimits(t *testing.T) {
+	table := &limitedTable{Table: memory.NewTable("mytable", sql.Schema{
+		{Name: "i", Type: sql.Int32, Source: "mytable"},
+	})}
+	i := expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", false)
+
+	rule := getRule("pushdown_limits")
+	a := NewDefault(sql.NewCatalog())
+
+	testCases := []struct {
+		name     string
+		node     sql.Node
+		expected sql.Node
+	}{
+		{
+			name: "limit over projected table",
+			node: plan.NewLimit(5, plan.NewProject(
+				[]sql.Expression{i},
+				plan.NewTableAlias("t", plan.NewResolvedTable(table)),
+			)),
+			expected: plan.NewLimit(5, plan.NewProject(
+				[]sql.Expression{i},
+				plan.NewTableAlias("t", plan.NewResolvedTable(table.WithLimit(5))),
+			)),
+		},
+		{
+			name:     "lower limit already pushed down",
+			node:     plan.NewLimit(5, plan.NewResolvedTable(table.WithLimit(2))),
+			expected: plan.NewLimit(5, plan.NewResolvedTable(table.WithLimit(2))),
+		},
+		{
+			name: "filter between limit and table",
+			node: plan.NewLimit(5, plan.NewFilter(
+				expression.NewEquals(i, expression.NewLiteral(int32(1), sql.Int32)),
+				plan.NewResolvedTable(table),
+			)),
+			expected: plan.NewLimit(5, plan.NewFilter(
+				expression.NewEquals(i, expression.NewLiteral(int32(1), sql.Int32)),
+				plan.NewResolvedTable(table),
+			)),
+		},
+		{
+			name:     "table without limit support",
+			node:     plan.NewLimit(5, plan.NewResolvedTable(table.Table)),
+			expected: plan.NewLimit(5, plan.NewResolvedTable(table.Table)),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := rule.Apply(sql.NewEmptyContext(), a, tt.node, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
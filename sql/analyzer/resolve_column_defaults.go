@@ -185,6 +185,7 @@ var validColumnDefaultFuncs = map[string]struct{}{
 	"linestring":                         {},
 	"ln":                                 {},
 	"load_file":                          {},
+	"localtime":                          {},
 	"localtimestamp":                     {},
 	"locate":                             {},
 	"log":                                {},
@@ -463,7 +464,7 @@ func resolveColumnDefaults(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Sco
 							err = sql.ErrInvalidColumnDefaultFunction.New(funcName, col.Name)
 							return false
 						}
-						if (funcName == "now" || funcName == "current_timestamp") &&
+						if (funcName == "now" || funcName == "current_timestamp" || funcName == "localtime" || funcName == "localtimestamp") &&
 							newDefault.IsLiteral() &&
 							(!sql.IsTime(col.Type) || sql.Date == col.Type) {
 							err = sql.ErrColumnDefaultDatetimeOnlyFunc.New()
@@ -473,6 +474,15 @@ func resolveColumnDefaults(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Sco
 					case *plan.Subquery:
 						err = sql.ErrColumnDefaultSubquery.New(col.Name)
 						return false
+					case *expression.UserVar, *expression.SystemVar:
+						err = sql.ErrColumnDefaultVariable.New(col.Name)
+						return false
+					case *expression.GetField:
+						if ref, ok := columns[strings.ToLower(expr.Name())]; ok && ref.AutoIncrement {
+							err = sql.ErrColumnDefaultAutoIncrement.New(col.Name, ref.Name)
+							return false
+						}
+						return true
 					default:
 						return true
 					}
@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/dolthub/vitess/go/vt/sqlparser"
-	"gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/go-mysql-server/internal/similartext"
 	"github.com/dolthub/go-mysql-server/sql"
@@ -405,12 +404,15 @@ func getColumnsInNodes(nodes []sql.Node, names availableNames, nestingLevel int)
 	}
 }
 
-var errGlobalVariablesNotSupported = errors.NewKind("can't resolve global variable, %s was requested")
-
 const (
 	sessionTable  = "@@" + sqlparser.SessionStr
+	globalTable   = "@@" + sqlparser.GlobalStr
 	sessionPrefix = sqlparser.SessionStr + "."
 	globalPrefix  = sqlparser.GlobalStr + "."
+	// The parser turns SET PERSIST var and SET PERSIST_ONLY var into
+	// @@persist.var and @@persist_only.var.
+	persistPrefix     = "persist."
+	persistOnlyPrefix = "persist_only."
 )
 
 // resolveColumns replaces UnresolvedColumn expressions with GetField expressions for the appropriate numbered field in
@@ -547,21 +549,66 @@ func indexColumns(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) map[t
 }
 
 func resolveSystemVariable(ctx *sql.Context, a *Analyzer, col column) (sql.Expression, error) {
-	if col.Table() != "" && strings.ToLower(col.Table()) != sessionTable {
-		return nil, errGlobalVariablesNotSupported.New(col)
+	table := strings.ToLower(col.Table())
+	if table != "" && table != sessionTable && table != globalTable {
+		return nil, sql.ErrUnknownSystemVariable.New(strings.TrimLeft(col.String(), "@"))
+	}
+
+	if systemVariablePersist(col) != expression.NoPersist {
+		return nil, sql.ErrUnknownSystemVariable.New(strings.TrimLeft(col.Name(), "@"))
 	}
 
 	name := trimVarName(col.Name())
-	typ, _ := ctx.Get(name)
+	v, ok := ctx.SystemVariables().Lookup(name)
+	if !ok {
+		return nil, sql.ErrUnknownSystemVariable.New(name)
+	}
 
-	a.Log("resolved column %s to system variable (type %s)", col, typ)
-	return expression.NewSystemVar(name, typ), nil
+	global, session := systemVariableScope(col)
+	switch {
+	case global && !v.HasGlobal():
+		return nil, sql.ErrSystemVariableSessionOnly.New(name)
+	case session && !v.HasSession():
+		return nil, sql.ErrSystemVariableGlobalOnly.New(name)
+	}
+
+	a.Log("resolved column %s to system variable (type %s)", col, v.Type)
+	if global {
+		return expression.NewGlobalSystemVar(name, v.Type), nil
+	}
+	return expression.NewSystemVar(name, v.Type), nil
+}
+
+// systemVariableScope returns whether a system variable column refers
+// explicitly to the global or to the session value of the variable, as in
+// @@global.var or @@session.var.
+func systemVariableScope(col column) (global, session bool) {
+	name := strings.TrimLeft(strings.ToLower(col.Name()), "@")
+	table := strings.ToLower(col.Table())
+	global = table == globalTable || strings.HasPrefix(name, globalPrefix)
+	session = table == sessionTable || strings.HasPrefix(name, sessionPrefix)
+	return global, session
+}
+
+// systemVariablePersist returns how setting a system variable column
+// persists it, as in @@persist.var or @@persist_only.var.
+func systemVariablePersist(col column) expression.PersistMode {
+	name := strings.TrimLeft(strings.ToLower(col.Name()), "@")
+	switch {
+	case strings.HasPrefix(name, persistPrefix):
+		return expression.Persist
+	case strings.HasPrefix(name, persistOnlyPrefix):
+		return expression.PersistOnly
+	default:
+		return expression.NoPersist
+	}
 }
 
 func trimVarName(name string) string {
 	name = strings.ToLower(name)
 	name = strings.TrimLeft(name, "@")
 	name = strings.TrimPrefix(strings.TrimPrefix(name, globalPrefix), sessionPrefix)
+	name = strings.TrimPrefix(strings.TrimPrefix(name, persistPrefix), persistOnlyPrefix)
 	return name
 }
 
@@ -571,8 +618,11 @@ func resolveUserVariable(ctx *sql.Context, a *Analyzer, col column) (sql.Express
 
 	name := strings.TrimLeft(colStr, "@")
 
-	a.Log("resolved column to user var %s", name)
-	return expression.NewUserVar(name), nil
+	// The expression has the type of the value the variable has before the
+	// query, even if the query sets it.
+	typ, _ := ctx.Get(name)
+	a.Log("resolved column to user var %s (type %s)", name, typ)
+	return expression.NewUserVarWithType(name, typ), nil
 }
 
 func resolveColumnExpression(ctx *sql.Context, a *Analyzer, e column, columns map[tableCol]indexedCol) (sql.Expression, error) {
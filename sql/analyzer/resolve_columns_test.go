@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/go-mysql-server/memory"
 	"github.com/dolthub/go-mysql-server/sql"
@@ -602,16 +603,15 @@ func TestResolveColumnsSession(t *testing.T) {
 	require := require.New(t)
 
 	ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession()))
-	err := ctx.Set(ctx, "foo_bar", sql.Int64, int64(42))
-	require.NoError(err)
-	err = ctx.Set(ctx, "autocommit", sql.Boolean, true)
+	err := ctx.Set(ctx, "autocommit", sql.Int8, int8(1))
 	require.NoError(err)
 
 	node := plan.NewProject(
 		[]sql.Expression{
-			uc("@@foo_bar"),
-			uc("@@bar_baz"),
+			uc("@@wait_timeout"),
 			uc("@@autocommit"),
+			uc("@@global.autocommit"),
+			uc("@@max_connections"),
 			uc("@myvar"),
 		},
 		plan.NewResolvedTable(dualTable),
@@ -622,15 +622,29 @@ func TestResolveColumnsSession(t *testing.T) {
 
 	expected := plan.NewProject(
 		[]sql.Expression{
-			expression.NewSystemVar("foo_bar", sql.Int64),
-			expression.NewSystemVar("bar_baz", sql.Null),
-			expression.NewSystemVar("autocommit", sql.Boolean),
+			expression.NewSystemVar("wait_timeout", sql.Int64),
+			expression.NewSystemVar("autocommit", sql.Int8),
+			expression.NewGlobalSystemVar("autocommit", sql.Int8),
+			expression.NewSystemVar("max_connections", sql.Int64),
 			expression.NewUserVar("myvar"),
 		},
 		plan.NewResolvedTable(dualTable),
 	)
 
 	require.Equal(expected, result)
+
+	for _, tt := range []struct {
+		name string
+		kind *errors.Kind
+	}{
+		{"@@bar_baz", sql.ErrUnknownSystemVariable},
+		{"@@session.max_connections", sql.ErrSystemVariableGlobalOnly},
+	} {
+		node := plan.NewProject([]sql.Expression{uc(tt.name)}, plan.NewResolvedTable(dualTable))
+		_, err = resolveColumns(ctx, NewDefault(nil), node, nil)
+		require.Error(err)
+		require.True(tt.kind.Is(err), "%s: %s", tt.name, err)
+	}
 }
 
 func TestPushdownGroupByAliases(t *testing.T) {
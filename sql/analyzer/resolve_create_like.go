@@ -30,12 +30,14 @@ func resolveCreateLike(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope)
 				constraint = sql.IndexConstraint_Unique
 			}
 			columns := make([]sql.IndexColumn, len(index.Expressions()))
+			descending, prefixLengths := sql.IndexDirections(index), sql.IndexPrefixLengths(index)
 			for i, col := range index.Expressions() {
 				//TODO: find a better way to get only the column name if the table is present
 				col = strings.TrimPrefix(col, indexableTable.Name()+".")
 				columns[i] = sql.IndexColumn{
-					Name:   col,
-					Length: 0,
+					Name:       col,
+					Length:     prefixLengths[i],
+					Descending: descending[i],
 				}
 			}
 			idxDefs = append(idxDefs, &plan.IndexDefinition{
@@ -44,6 +46,7 @@ func resolveCreateLike(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope)
 				Constraint: constraint,
 				Columns:    columns,
 				Comment:    index.Comment(),
+				Invisible:  !sql.IsIndexVisible(index),
 			})
 		}
 	}
@@ -54,5 +57,12 @@ func resolveCreateLike(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope)
 		tempCol.Source = planCreate.Name()
 		newSch[i] = &tempCol
 	}
-	return plan.NewCreateTable(planCreate.Database(), planCreate.Name(), newSch, planCreate.IfNotExists(), idxDefs, nil), nil
+	// The new table has the options of the other one, but not its next auto increment value
+	var options sql.TableOptions
+	for _, option := range plan.GetTableOptions(likeTable) {
+		if option.Name != sql.TableOption_AutoIncrement {
+			options = append(options, option)
+		}
+	}
+	return plan.NewCreateTable(planCreate.Database(), planCreate.Name(), newSch, planCreate.IfNotExists(), idxDefs, nil).WithTableOptions(options), nil
 }
@@ -1,12 +1,20 @@
 package analyzer
 
 import (
+	"gopkg.in/src-d/go-errors.v1"
+
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/expression/function/aggregation"
 	"github.com/dolthub/go-mysql-server/sql/plan"
 )
 
-// resolveFunctions replaces UnresolvedFunction nodes with equivalent functions from the Catalog.
+// ErrDistinctNonAggregate is returned when DISTINCT is used in a call to a function that is not an aggregation.
+var ErrDistinctNonAggregate = errors.NewKind("DISTINCT is only valid for aggregate functions, %s is not one")
+
+// resolveFunctions replaces UnresolvedFunction nodes with equivalent functions from the Catalog. Since the parser can
+// only identify built-in aggregations, a Project node found to contain aggregations once its functions are resolved
+// is converted to a GroupBy with no grouping expressions.
 func resolveFunctions(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
 	span, _ := ctx.Span("resolve_functions")
 	defer span.Finish()
@@ -16,11 +24,25 @@ func resolveFunctions(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (
 			return n, nil
 		}
 
-		return plan.TransformExpressionsUp(n, resolveFunctionsInExpr(a))
+		n, err := plan.TransformExpressionsUp(n, resolveFunctionsInExpr(ctx, a))
+		if err != nil {
+			return nil, err
+		}
+
+		if p, ok := n.(*plan.Project); ok {
+			for _, e := range p.Projections {
+				if containsAggregation(e) {
+					a.Log("project with aggregations converted to group by")
+					return plan.NewGroupBy(p.Projections, nil, p.Child), nil
+				}
+			}
+		}
+
+		return n, nil
 	})
 }
 
-func resolveFunctionsInExpr(a *Analyzer) sql.TransformExprFunc {
+func resolveFunctionsInExpr(ctx *sql.Context, a *Analyzer) sql.TransformExprFunc {
 	return func(e sql.Expression) (sql.Expression, error) {
 		if e.Resolved() {
 			return e, nil
@@ -32,7 +54,7 @@ func resolveFunctionsInExpr(a *Analyzer) sql.TransformExprFunc {
 		}
 
 		n := uf.Name()
-		f, err := a.Catalog.Function(n)
+		f, err := a.Catalog.ResolveFunction(ctx, n)
 		if err != nil {
 			return nil, err
 		}
@@ -42,6 +64,14 @@ func resolveFunctionsInExpr(a *Analyzer) sql.TransformExprFunc {
 			return nil, err
 		}
 
+		if uf.Distinct {
+			agg, ok := rf.(sql.Aggregation)
+			if !ok {
+				return nil, ErrDistinctNonAggregate.New(n)
+			}
+			rf = aggregation.NewDistinct(agg)
+		}
+
 		a.Log("resolved function %q", n)
 		return rf, nil
 	}
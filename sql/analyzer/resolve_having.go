@@ -245,13 +245,15 @@ func pushColumnsUp(node sql.Node, columns []int) (sql.Node, []int, error) {
 		if err != nil {
 			return nil, nil, err
 		}
-		return plan.NewLimit(node.Limit, child), columns, nil
+		n, err := node.WithChildren(child)
+		return n, columns, err
 	case *plan.Offset:
 		child, columns, err := pushColumnsUp(node.Child, columns)
 		if err != nil {
 			return nil, nil, err
 		}
-		return plan.NewOffset(node.Offset, child), columns, nil
+		n, err := node.WithChildren(child)
+		return n, columns, err
 	case *plan.Distinct:
 		child, columns, err := pushColumnsUp(node.Child, columns)
 		if err != nil {
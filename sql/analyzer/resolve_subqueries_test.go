@@ -73,14 +73,14 @@ func TestResolveSubqueries(t *testing.T) {
 						plan.NewSubqueryAlias(
 							"t1", "",
 							plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [a]",
-								plan.NewResolvedTable(foo.WithProjection([]string{"a"}))),
+								plan.NewResolvedTable(foo.WithProjection([]string{"a"})).WithDatabase("mydb")),
 						),
 						plan.NewSubqueryAlias(
 							"t2", "",
 							plan.NewSubqueryAlias(
 								"t2alias", "",
 								plan.NewDecoratedNode(plan.DecorationTypeProjectedAccess, "Projected table access on [b]",
-									plan.NewResolvedTable(bar.WithProjection([]string{"b"}))),
+									plan.NewResolvedTable(bar.WithProjection([]string{"b"})).WithDatabase("mydb")),
 							),
 						),
 					),
@@ -136,7 +136,7 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 						),
 						""),
 				},
-				plan.NewResolvedTable(table),
+				plan.NewResolvedTable(table).WithDatabase("mydb"),
 			),
 			expected: plan.NewProject(
 				[]sql.Expression{
@@ -151,12 +151,12 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 									gf(1, "mytable", "x"),
 									gf(2, "mytable2", "i"),
 								),
-								plan.NewResolvedTable(table2),
+								plan.NewResolvedTable(table2).WithDatabase("mydb"),
 							),
 						),
 						""),
 				},
-				plan.NewResolvedTable(table),
+				plan.NewResolvedTable(table).WithDatabase("mydb"),
 			),
 		},
 		{
@@ -179,7 +179,7 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 						),
 						""),
 				},
-				plan.NewResolvedTable(table),
+				plan.NewResolvedTable(table).WithDatabase("mydb"),
 			),
 			expected: plan.NewProject(
 				[]sql.Expression{
@@ -194,12 +194,12 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 									gf(1, "mytable", "x"),
 									gf(0, "mytable", "i"),
 								),
-								plan.NewResolvedTable(table2),
+								plan.NewResolvedTable(table2).WithDatabase("mydb"),
 							),
 						),
 						""),
 				},
-				plan.NewResolvedTable(table),
+				plan.NewResolvedTable(table).WithDatabase("mydb"),
 			),
 		},
 		{
@@ -222,7 +222,7 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 						),
 						""),
 				},
-				plan.NewResolvedTable(table),
+				plan.NewResolvedTable(table).WithDatabase("mydb"),
 			),
 			err: sql.ErrTableNotFound,
 		},
@@ -246,7 +246,7 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 						),
 						""),
 				},
-				plan.NewResolvedTable(table),
+				plan.NewResolvedTable(table).WithDatabase("mydb"),
 			),
 			err: sql.ErrTableNotFound,
 		},
@@ -270,7 +270,7 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 						),
 						""),
 				},
-				plan.NewResolvedTable(table),
+				plan.NewResolvedTable(table).WithDatabase("mydb"),
 			),
 			expected: plan.NewProject(
 				[]sql.Expression{
@@ -285,12 +285,12 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 									gf(1, "mytable", "x"),
 									gf(0, "mytable", "i"),
 								),
-								plan.NewResolvedTable(table2),
+								plan.NewResolvedTable(table2).WithDatabase("mydb"),
 							),
 						),
 						""),
 				},
-				plan.NewResolvedTable(table),
+				plan.NewResolvedTable(table).WithDatabase("mydb"),
 			),
 		},
 		{
@@ -308,12 +308,12 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 									gf(1, "mytable", "x"),
 									gf(2, "mytable2", "i"),
 								),
-								plan.NewResolvedTable(table2),
+								plan.NewResolvedTable(table2).WithDatabase("mydb"),
 							),
 						),
 						""),
 				},
-				plan.NewResolvedTable(table),
+				plan.NewResolvedTable(table).WithDatabase("mydb"),
 			),
 			expected: plan.NewProject(
 				[]sql.Expression{
@@ -328,12 +328,12 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 									gf(1, "mytable", "x"),
 									gf(2, "mytable2", "i"),
 								),
-								plan.NewResolvedTable(table2),
+								plan.NewResolvedTable(table2).WithDatabase("mydb"),
 							),
 						),
 						""),
 				},
-				plan.NewResolvedTable(table),
+				plan.NewResolvedTable(table).WithDatabase("mydb"),
 			),
 		},
 		{
@@ -369,7 +369,7 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 						),
 						""),
 				},
-				plan.NewResolvedTable(table),
+				plan.NewResolvedTable(table).WithDatabase("mydb"),
 			),
 			expected: plan.NewProject(
 				[]sql.Expression{
@@ -392,17 +392,17 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 													gf(1, "mytable", "x"),
 													gf(4, "mytable2", "i"),
 												),
-												plan.NewResolvedTable(table2),
+												plan.NewResolvedTable(table2).WithDatabase("mydb"),
 											),
 										),
 										""),
 								),
-								plan.NewResolvedTable(table2),
+								plan.NewResolvedTable(table2).WithDatabase("mydb"),
 							),
 						),
 						""),
 				},
-				plan.NewResolvedTable(table),
+				plan.NewResolvedTable(table).WithDatabase("mydb"),
 			),
 		},
 	}
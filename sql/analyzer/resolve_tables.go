@@ -1,6 +1,8 @@
 package analyzer
 
 import (
+	"gopkg.in/src-d/go-errors.v1"
+
 	"github.com/dolthub/go-mysql-server/memory"
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/expression"
@@ -9,6 +11,10 @@ import (
 
 const dualTableName = "dual"
 
+// ErrInvalidTableFunctionArgument is returned when an argument of a table function can't be resolved, e.g. because it
+// references a column.
+var ErrInvalidTableFunctionArgument = errors.NewKind("invalid argument for table function %s: %s")
+
 var dualTable = func() sql.Table {
 	t := memory.NewTable(dualTableName, sql.Schema{
 		{Name: "dummy", Source: dualTableName, Type: sql.LongText, Nullable: false},
@@ -26,6 +32,10 @@ func resolveTables(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 			return n, nil
 		}
 
+		if tf, ok := n.(*plan.UnresolvedTableFunction); ok {
+			return resolveTableFunction(ctx, a, tf)
+		}
+
 		t, ok := n.(*plan.UnresolvedTable)
 		if !ok {
 			return n, nil
@@ -41,7 +51,7 @@ func resolveTables(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 			// This is necessary to use functions in AS OF expressions. Because function resolution happens after table
 			// resolution, we resolve any functions in the AsOf here in order to evaluate them immediately. A better solution
 			// might be to defer evaluating the expression until later in the analysis, but that requires bigger changes.
-			asOfExpr, err := expression.TransformUp(t.AsOf, resolveFunctionsInExpr(a))
+			asOfExpr, err := expression.TransformUp(t.AsOf, resolveFunctionsInExpr(ctx, a))
 			if err != nil {
 				return nil, err
 			}
@@ -61,7 +71,7 @@ func resolveTables(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 			}
 
 			a.Log("table resolved: %q as of %s", rt.Name(), asOf)
-			return plan.NewResolvedTable(rt), nil
+			return plan.NewResolvedTable(rt).WithDatabase(db), nil
 		}
 
 		rt, err := a.Catalog.Table(ctx, db, name)
@@ -70,10 +80,41 @@ func resolveTables(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 		}
 
 		a.Log("table resolved: %s", t.Name())
-		return plan.NewResolvedTable(rt), nil
+		return plan.NewResolvedTable(rt).WithDatabase(db), nil
 	})
 }
 
+// resolveTableFunction replaces a call to a table function with the node returned by the function for its arguments.
+// As with AS OF expressions, functions used in the arguments are resolved here, since function resolution happens
+// after table resolution.
+func resolveTableFunction(ctx *sql.Context, a *Analyzer, tf *plan.UnresolvedTableFunction) (sql.Node, error) {
+	fn, err := a.Catalog.TableFunction(tf.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	var args = make([]sql.Expression, len(tf.Arguments))
+	for i, arg := range tf.Arguments {
+		arg, err = expression.TransformUp(arg, resolveFunctionsInExpr(ctx, a))
+		if err != nil {
+			return nil, err
+		}
+
+		if !arg.Resolved() {
+			return nil, ErrInvalidTableFunctionArgument.New(tf.Name(), arg)
+		}
+		args[i] = arg
+	}
+
+	n, err := fn.NewInstance(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Log("table function resolved: %s", tf.Name())
+	return n, nil
+}
+
 func handleTableLookupFailure(err error, tableName string, dbName string, a *Analyzer, t *plan.UnresolvedTable) (sql.Node, error) {
 	if sql.ErrDatabaseNotFound.Is(err) {
 		if tableName == dualTableName {
@@ -28,12 +28,12 @@ func TestResolveTables(t *testing.T) {
 	var notAnalyzed sql.Node = plan.NewUnresolvedTable("mytable", "")
 	analyzed, err := f.Apply(ctx, a, notAnalyzed, nil)
 	require.NoError(err)
-	require.Equal(plan.NewResolvedTable(table), analyzed)
+	require.Equal(plan.NewResolvedTable(table).WithDatabase("mydb"), analyzed)
 
 	notAnalyzed = plan.NewUnresolvedTable("MyTable", "")
 	analyzed, err = f.Apply(ctx, a, notAnalyzed, nil)
 	require.NoError(err)
-	require.Equal(plan.NewResolvedTable(table), analyzed)
+	require.Equal(plan.NewResolvedTable(table).WithDatabase("mydb"), analyzed)
 
 	notAnalyzed = plan.NewUnresolvedTable("nonexistant", "")
 	analyzed, err = f.Apply(ctx, a, notAnalyzed, nil)
@@ -57,7 +57,7 @@ func TestResolveTables(t *testing.T) {
 	notAnalyzed = plan.NewUnresolvedTableAsOf("myTable", "", expression.NewLiteral("2019-01-01", sql.LongText))
 	analyzed, err = f.Apply(ctx, a, notAnalyzed, nil)
 	require.NoError(err)
-	require.Equal(plan.NewResolvedTable(table), analyzed)
+	require.Equal(plan.NewResolvedTable(table).WithDatabase("mydb"), analyzed)
 
 	notAnalyzed = plan.NewUnresolvedTableAsOf("myTable", "", expression.NewLiteral("2019-01-02", sql.LongText))
 	analyzed, err = f.Apply(ctx, a, notAnalyzed, nil)
@@ -121,7 +121,7 @@ func TestResolveTablesNested(t *testing.T) {
 	require.NoError(err)
 	expected := plan.NewProject(
 		[]sql.Expression{expression.NewGetField(0, sql.Int32, "i", true)},
-		plan.NewResolvedTable(table),
+		plan.NewResolvedTable(table).WithDatabase("mydb"),
 	)
 	require.Equal(expected, analyzed)
 
@@ -133,7 +133,69 @@ func TestResolveTablesNested(t *testing.T) {
 	require.NoError(err)
 	expected = plan.NewProject(
 		[]sql.Expression{expression.NewGetField(0, sql.Int32, "i", true)},
-		plan.NewResolvedTable(table2),
+		plan.NewResolvedTable(table2).WithDatabase("my_other_db"),
 	)
 	require.Equal(expected, analyzed)
 }
+
+type sequenceTableFunction struct{}
+
+func (sequenceTableFunction) Name() string { return "sequence" }
+
+func (sequenceTableFunction) NewInstance(ctx *sql.Context, args []sql.Expression) (sql.Node, error) {
+	if len(args) != 1 {
+		return nil, sql.ErrInvalidArgumentNumber.New("sequence", 1, len(args))
+	}
+
+	n, err := args[0].Eval(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err = sql.Int64.Convert(n)
+	if err != nil {
+		return nil, err
+	}
+
+	table := memory.NewTable("sequence", sql.Schema{{Name: "n", Type: sql.Int64, Source: "sequence"}})
+	for i := int64(0); i < n.(int64); i++ {
+		if err := table.Insert(ctx, sql.NewRow(i)); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan.NewResolvedTable(table), nil
+}
+
+func TestResolveTableFunction(t *testing.T) {
+	require := require.New(t)
+	f := getRule("resolve_tables")
+
+	catalog := sql.NewCatalog()
+	require.NoError(catalog.RegisterTableFunction(sequenceTableFunction{}))
+	require.True(sql.ErrTableFunctionAlreadyRegistered.Is(catalog.RegisterTableFunction(sequenceTableFunction{})))
+
+	a := NewBuilder(catalog).AddPostAnalyzeRule(f.Name, f.Apply).Build()
+	ctx := sql.NewEmptyContext()
+
+	node := plan.NewProject(
+		[]sql.Expression{expression.NewUnresolvedColumn("n")},
+		plan.NewTableAlias("s", plan.NewUnresolvedTableFunction("SEQUENCE", expression.NewLiteral(int64(3), sql.Int64))),
+	)
+
+	analyzed, err := f.Apply(ctx, a, node, nil)
+	require.NoError(err)
+
+	table, ok := analyzed.(*plan.Project).Child.(*plan.TableAlias).Child.(*plan.ResolvedTable)
+	require.True(ok)
+
+	rows, err := sql.NodeToRows(ctx, table)
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(0)}, {int64(1)}, {int64(2)}}, rows)
+
+	_, err = f.Apply(ctx, a, plan.NewUnresolvedTableFunction("nope"), nil)
+	require.True(sql.ErrTableFunctionNotFound.Is(err))
+
+	_, err = f.Apply(ctx, a, plan.NewUnresolvedTableFunction("sequence", expression.NewUnresolvedColumn("i")), nil)
+	require.True(ErrInvalidTableFunctionArgument.Is(err))
+}
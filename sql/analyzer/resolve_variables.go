@@ -40,8 +40,16 @@ func resolveSetVariables(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope
 			return e, nil
 		}
 
+		var global bool
+		persist := expression.NoPersist
+		if uc, ok := sf.Left.(*expression.UnresolvedColumn); ok && isSystemVariable(uc) {
+			global, _ = systemVariableScope(uc)
+			persist = systemVariablePersist(uc)
+			global = global || persist != expression.NoPersist
+		}
+
 		varName := trimVarName(sf.Left.String())
-		setVal, err := getSetVal(ctx, varName, sf.Right)
+		setVal, err := getSetVal(ctx, varName, global, sf.Right)
 		if err != nil {
 			return nil, err
 		}
@@ -59,13 +67,9 @@ func resolveSetVariables(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope
 		// set @sql_mode = "abc"
 		if uc, ok := sf.Left.(*expression.UnresolvedColumn); ok {
 			if isSystemVariable(uc) {
-				// TODO: clean up distinction between system and user vars in this interface
-				typ, _ := ctx.Session.Get(varName)
-				if typ == sql.Null {
-					// TODO: since we don't support all system variables supported by MySQL yet, for compatibility reasons we
-					//  will just accept them all here. But we should reject unknown ones.
-					// return nil, sql.ErrUnknownSystemVariable.New(varName)
-					typ = sf.Right.Type()
+				v, ok := ctx.SystemVariables().Lookup(varName)
+				if !ok {
+					return nil, sql.ErrUnknownSystemVariable.New(varName)
 				}
 
 				// Special case: for system variables, MySQL allows naked strings (without quotes), which get interpreted as
@@ -76,7 +80,13 @@ func resolveSetVariables(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope
 					}
 				}
 
-				return sf.WithChildren(expression.NewSystemVar(varName, typ), setVal)
+				if persist != expression.NoPersist {
+					return sf.WithChildren(expression.NewPersistedSystemVar(varName, v.Type, persist), setVal)
+				}
+				if global {
+					return sf.WithChildren(expression.NewGlobalSystemVar(varName, v.Type), setVal)
+				}
+				return sf.WithChildren(expression.NewSystemVar(varName, v.Type), setVal)
 			}
 
 			if isUserVariable(uc) {
@@ -104,7 +114,7 @@ func resolveBarewordSetVariables(ctx *sql.Context, a *Analyzer, n sql.Node, scop
 		}
 
 		varName := trimVarName(sf.Left.String())
-		setVal, err := getSetVal(ctx, varName, sf.Right)
+		setVal, err := getSetVal(ctx, varName, false, sf.Right)
 		if err != nil {
 			return nil, err
 		}
@@ -113,12 +123,9 @@ func resolveBarewordSetVariables(ctx *sql.Context, a *Analyzer, n sql.Node, scop
 		// So treat it as a naked system variable and see if it exists
 		if uc, ok := sf.Left.(*deferredColumn); ok {
 			varName := trimVarName(uc.String())
-			typ, _ := ctx.Session.Get(varName)
-			if typ == sql.Null {
-				// TODO: since we don't support all system variables supported by MySQL yet, for compatibility reasons we
-				//  will just accept them all here. But we should reject unknown ones.
-				// return nil, sql.ErrUnknownSystemVariable.New(varName)
-				typ = sf.Right.Type()
+			v, ok := ctx.SystemVariables().Lookup(varName)
+			if !ok {
+				return nil, sql.ErrUnknownSystemVariable.New(varName)
 			}
 
 			// Special case: for system variables, MySQL allows naked strings (without quotes), which get interpreted as
@@ -129,22 +136,27 @@ func resolveBarewordSetVariables(ctx *sql.Context, a *Analyzer, n sql.Node, scop
 				}
 			}
 
-			return sf.WithChildren(expression.NewSystemVar(varName, typ), setVal)
+			return sf.WithChildren(expression.NewSystemVar(varName, v.Type), setVal)
 		}
 
 		return sf, nil
 	})
 }
 
-// getSetVal evaluates the right hand side of a SetField expression and returns an evaluated value as appropriate
-func getSetVal(ctx *sql.Context, varName string, e sql.Expression) (sql.Expression, error) {
+// getSetVal evaluates the right hand side of a SetField expression and returns an evaluated value as appropriate. The
+// default value of a variable is its global value for the session, and its compiled default globally.
+func getSetVal(ctx *sql.Context, varName string, global bool, e sql.Expression) (sql.Expression, error) {
 	if _, ok := e.(*expression.DefaultColumn); ok {
-		valtyp, ok := sql.DefaultSessionConfig()[varName]
+		v, ok := ctx.SystemVariables().Lookup(varName)
 		if !ok {
 			return nil, sql.ErrUnknownSystemVariable.New(varName)
 		}
-		value, typ := valtyp.Value, valtyp.Typ
-		return expression.NewLiteral(value, typ), nil
+
+		value := v.Default
+		if current, ok := ctx.SystemVariables().Global(varName); ok && !global {
+			value = current
+		}
+		return expression.NewLiteral(value, v.Type), nil
 	}
 
 	if !e.Resolved() {
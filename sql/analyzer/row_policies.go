@@ -0,0 +1,217 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// applyRowPolicies filters the tables the query reads rows from with the row filters of the row policies of the
+// catalog, and checks that the rows it inserts or updates satisfy them. It runs before tables are resolved, so that
+// each table is filtered once, even though subqueries are analyzed several times.
+func applyRowPolicies(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	if a.Catalog == nil {
+		return n, nil
+	}
+
+	policies := a.Catalog.RowPolicies()
+	if len(policies) == 0 {
+		return n, nil
+	}
+
+	span, _ := ctx.Span("apply_row_policies")
+	defer span.Finish()
+
+	n, err := plan.TransformUpWithParent(n, func(n sql.Node, parent sql.Node, childNum int) (sql.Node, error) {
+		if !readsRows(parent, childNum) {
+			return n, nil
+		}
+
+		var table *plan.UnresolvedTable
+		switch n := n.(type) {
+		case *plan.UnresolvedTable:
+			table = n
+		case *plan.TableAlias:
+			t, ok := n.Child.(*plan.UnresolvedTable)
+			if !ok {
+				return n, nil
+			}
+			table = t
+		default:
+			return n, nil
+		}
+
+		filter, err := rowFilter(ctx, policies, tableDatabase(ctx, table), table.Name())
+		if err != nil || filter == nil {
+			return n, err
+		}
+
+		if alias, ok := n.(*plan.TableAlias); ok {
+			filter, err = qualifyRowFilter(filter, table.Name(), alias.Name())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		a.Log("applying row filter %s to table %q", filter, table.Name())
+		return plan.NewFilter(filter, n), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
+		switch n := n.(type) {
+		case *plan.InsertInto:
+			table, ok := n.Left().(*plan.UnresolvedTable)
+			if !ok {
+				return n, nil
+			}
+
+			check, err := rowPolicyCheck(ctx, a, policies, table, n.Right(), false)
+			if err != nil || check == nil {
+				return n, err
+			}
+
+			// Both would change rows with the same key as the ones inserted, even if the row filters hide them.
+			if n.IsReplace {
+				return nil, sql.ErrRowPolicyNotSupported.New("REPLACE", table.Name())
+			}
+			if len(n.OnDupExprs) > 0 {
+				return nil, sql.ErrRowPolicyNotSupported.New("INSERT ... ON DUPLICATE KEY UPDATE", table.Name())
+			}
+
+			return n.WithChildren(n.Left(), check)
+		case *plan.Update:
+			var table *plan.UnresolvedTable
+			plan.Inspect(n.Child, func(n sql.Node) bool {
+				if t, ok := n.(*plan.UnresolvedTable); ok && table == nil {
+					table = t
+				}
+				return table == nil
+			})
+			if table == nil {
+				return n, nil
+			}
+
+			check, err := rowPolicyCheck(ctx, a, policies, table, n.Child, true)
+			if err != nil || check == nil {
+				return n, err
+			}
+
+			return n.WithChildren(check)
+		default:
+			return n, nil
+		}
+	})
+}
+
+// readsRows returns whether the child of the node given is a source of the rows it reads, as opposed to a table
+// whose definition it uses, like the ones of DDL statements and the table an insert writes to. A table with no
+// parent is the definition of a subquery or a view.
+func readsRows(parent sql.Node, childNum int) bool {
+	switch parent.(type) {
+	case nil:
+		return true
+	case *plan.InsertInto:
+		return childNum == 1
+	case *plan.Project, *plan.GroupBy, *plan.Filter, *plan.Having, *plan.Sort, *plan.Limit, *plan.Offset,
+		*plan.Distinct, *plan.OrderedDistinct, *plan.InnerJoin, *plan.LeftJoin, *plan.RightJoin, *plan.CrossJoin,
+		*plan.NaturalJoin, *plan.Union, *plan.UpdateSource, *plan.DeleteFrom:
+		return true
+	default:
+		return false
+	}
+}
+
+func tableDatabase(ctx *sql.Context, t *plan.UnresolvedTable) string {
+	if t.Database != "" {
+		return t.Database
+	}
+	return ctx.GetCurrentDatabase()
+}
+
+// rowFilter returns the conjunction of the row filters of the policies given for a table, or nil if it has none.
+func rowFilter(ctx *sql.Context, policies []sql.RowPolicy, db, table string) (sql.Expression, error) {
+	var filters []sql.Expression
+	for _, p := range policies {
+		f, err := p.RowFilter(ctx, db, table)
+		if err != nil {
+			return nil, err
+		}
+
+		if f != nil {
+			filters = append(filters, f)
+		}
+	}
+
+	return expression.JoinAnd(filters...), nil
+}
+
+// qualifyRowFilter replaces the table qualifying the columns of a row filter with the alias of the table.
+func qualifyRowFilter(filter sql.Expression, table, alias string) (sql.Expression, error) {
+	return expression.TransformUp(filter, func(e sql.Expression) (sql.Expression, error) {
+		col, ok := e.(*expression.UnresolvedColumn)
+		if !ok || !strings.EqualFold(col.Table(), table) {
+			return e, nil
+		}
+		return expression.NewUnresolvedQualifiedColumn(alias, col.Name()), nil
+	})
+}
+
+// rowPolicyCheck returns a RowPolicyCheck of the rows written to a table by the node given, or nil if the table has
+// no row filters. Updates write the new rows after the old ones.
+func rowPolicyCheck(
+	ctx *sql.Context,
+	a *Analyzer,
+	policies []sql.RowPolicy,
+	t *plan.UnresolvedTable,
+	n sql.Node,
+	update bool,
+) (*plan.RowPolicyCheck, error) {
+	db := tableDatabase(ctx, t)
+	filter, err := rowFilter(ctx, policies, db, t.Name())
+	if err != nil || filter == nil {
+		return nil, err
+	}
+
+	table, err := a.Catalog.Table(ctx, db, t.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	schema := table.Schema()
+	var offset int
+	if update {
+		offset = len(schema)
+	}
+
+	// The predicate is resolved here because the rows checked are not the rows of a table node the analyzer could
+	// resolve its columns against.
+	predicate, err := expression.TransformUp(filter, func(e sql.Expression) (sql.Expression, error) {
+		switch e := e.(type) {
+		case *expression.UnresolvedColumn:
+			if e.Table() != "" && !strings.EqualFold(e.Table(), table.Name()) {
+				return nil, sql.ErrTableNotFound.New(e.Table())
+			}
+
+			for i, col := range schema {
+				if strings.EqualFold(col.Name, e.Name()) {
+					return expression.NewGetFieldWithTable(offset+i, col.Type, table.Name(), col.Name, col.Nullable), nil
+				}
+			}
+			return nil, sql.ErrTableColumnNotFound.New(table.Name(), e.Name())
+		case *expression.UnresolvedFunction:
+			return resolveFunctionsInExpr(ctx, a)(e)
+		default:
+			return e, nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewRowPolicyCheck(table.Name(), predicate, n), nil
+}
@@ -7,7 +7,9 @@ import (
 // OnceBeforeDefault contains the rules to be applied just once before the
 // DefaultRules.
 var OnceBeforeDefault = []Rule{
+	{"check_privileges", checkPrivileges},
 	{"resolve_views", resolveViews},
+	{"apply_row_policies", applyRowPolicies},
 	{"resolve_tables", resolveTables},
 	{"resolve_set_variables", resolveSetVariables},
 	{"resolve_create_like", resolveCreateLike},
@@ -34,7 +36,7 @@ var DefaultRules = []Rule{
 	{"reorder_projection", reorderProjection},
 	{"resolve_subquery_exprs", resolveSubqueryExpressions},
 	{"move_join_conds_to_filter", moveJoinConditionsToFilter},
-	{"eval_filter", evalFilter},
+	{"simplify_expressions", simplifyExpressions},
 	{"optimize_distinct", optimizeDistinct},
 }
 
@@ -52,12 +54,15 @@ var OnceAfterDefault = []Rule{
 	{"pushdown_filters", pushdownFilters},
 	{"subquery_indexes", applyIndexesFromOuterScope},
 	{"pushdown_projections", pushdownProjections},
+	{"pushdown_limits", pushdownLimits},
 	{"erase_projection", eraseProjection},
 	// One final pass at analyzing subqueries to handle rewriting field indexes after changes to outer scope by
 	// previous rules.
 	{"resolve_subquery_exprs", resolveSubqueryExpressions},
 	{"cache_subquery_results", cacheSubqueryResults},
 	{"resolve_insert_rows", resolveInsertRows},
+	{"apply_foreign_keys", applyForeignKeys},
+	{"apply_checks", applyChecks},
 	{"apply_triggers", applyTriggers},
 	{"apply_row_update_accumulators", applyUpdateAccumulators},
 }
@@ -67,7 +72,6 @@ var OnceAfterDefault = []Rule{
 var OnceAfterAll = []Rule{
 	{"track_process", trackProcess},
 	{"parallelize", parallelize},
-	{"clear_warnings", clearWarnings},
 }
 
 var (
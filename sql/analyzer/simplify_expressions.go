@@ -0,0 +1,206 @@
+package analyzer
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// simplifyExpressions simplifies the predicates of Filter, Having and join nodes where possible. Constant
+// sub-expressions are folded into literals, redundant parts of AND and OR expressions are removed, nested NOTs are
+// collapsed, and IN expressions with a single element are converted to equality. Filters that can statically be
+// determined to be true or false are replaced with the child node or an empty result, respectively.
+func simplifyExpressions(ctx *sql.Context, a *Analyzer, node sql.Node, scope *Scope) (sql.Node, error) {
+	if !node.Resolved() {
+		return node, nil
+	}
+
+	span, _ := ctx.Span("simplify_expressions")
+	defer span.Finish()
+
+	return plan.TransformUp(node, func(node sql.Node) (sql.Node, error) {
+		if !node.Resolved() {
+			return node, nil
+		}
+
+		switch node := node.(type) {
+		case *plan.Filter:
+			e, err := simplifyPredicate(ctx, node.Expression)
+			if err != nil {
+				return nil, err
+			}
+
+			if isFalse(e) {
+				return plan.EmptyTable, nil
+			}
+
+			if isTrue(e) {
+				return node.Child, nil
+			}
+
+			return plan.NewFilter(e, node.Child), nil
+		case *plan.Having:
+			e, err := simplifyPredicate(ctx, node.Cond)
+			if err != nil {
+				return nil, err
+			}
+
+			if isTrue(e) {
+				return node.Child, nil
+			}
+
+			return plan.NewHaving(e, node.Child), nil
+		case *plan.InnerJoin:
+			e, err := simplifyPredicate(ctx, node.Cond)
+			if err != nil {
+				return nil, err
+			}
+
+			if isTrue(e) {
+				a.Log("join condition always true, converting to cross join")
+				return plan.NewCrossJoin(node.Left(), node.Right()), nil
+			}
+
+			return plan.NewInnerJoin(node.Left(), node.Right(), e), nil
+		case *plan.LeftJoin:
+			e, err := simplifyPredicate(ctx, node.Cond)
+			if err != nil {
+				return nil, err
+			}
+
+			return plan.NewLeftJoin(node.Left(), node.Right(), e), nil
+		case *plan.RightJoin:
+			e, err := simplifyPredicate(ctx, node.Cond)
+			if err != nil {
+				return nil, err
+			}
+
+			return plan.NewRightJoin(node.Left(), node.Right(), e), nil
+		default:
+			return node, nil
+		}
+	})
+}
+
+// simplifyPredicate simplifies an expression used as a boolean predicate. Since only the truthiness of the result
+// matters in this position, logical operators can be rewritten more aggressively than elsewhere: AND / OR with a
+// constant operand are reduced, and NOT(NOT(x)) becomes x. Operands of other expressions are only constant-folded.
+func simplifyPredicate(ctx *sql.Context, e sql.Expression) (sql.Expression, error) {
+	switch e := e.(type) {
+	case *expression.And:
+		left, err := simplifyPredicate(ctx, e.Left)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := simplifyPredicate(ctx, e.Right)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case isFalse(left):
+			return left, nil
+		case isFalse(right):
+			return right, nil
+		case isTrue(left):
+			return right, nil
+		case isTrue(right):
+			return left, nil
+		}
+
+		return expression.NewAnd(left, right), nil
+	case *expression.Or:
+		left, err := simplifyPredicate(ctx, e.Left)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := simplifyPredicate(ctx, e.Right)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case isTrue(left):
+			return left, nil
+		case isTrue(right):
+			return right, nil
+		case isFalse(left):
+			return right, nil
+		case isFalse(right):
+			return left, nil
+		}
+
+		return expression.NewOr(left, right), nil
+	case *expression.Not:
+		if inner, ok := e.Child.(*expression.Not); ok {
+			return simplifyPredicate(ctx, inner.Child)
+		}
+
+		child, err := simplifyPredicate(ctx, e.Child)
+		if err != nil {
+			return nil, err
+		}
+
+		return foldConstants(ctx, expression.NewNot(child))
+	default:
+		return foldConstants(ctx, e)
+	}
+}
+
+// foldConstants replaces every sub-expression that can be evaluated without a row with its literal result, and
+// rewrites IN expressions with a single-element tuple as equality comparisons.
+func foldConstants(ctx *sql.Context, e sql.Expression) (sql.Expression, error) {
+	return expression.TransformUp(e, func(e sql.Expression) (sql.Expression, error) {
+		switch e := e.(type) {
+		case *expression.Literal, expression.Tuple, *expression.Interval:
+			return e, nil
+		case *expression.InTuple:
+			tuple, ok := e.Right().(expression.Tuple)
+			if ok && len(tuple) == 1 && sql.NumColumns(e.Left().Type()) == 1 && sql.NumColumns(tuple[0].Type()) == 1 {
+				return foldConstant(ctx, expression.NewEquals(e.Left(), tuple[0])), nil
+			}
+		}
+
+		return foldConstant(ctx, e), nil
+	})
+}
+
+// foldConstant evaluates the expression given and returns it as a literal if it's safe to do so. Otherwise, or if
+// the evaluation fails, the expression is returned unchanged so that any error surfaces at execution time.
+func foldConstant(ctx *sql.Context, e sql.Expression) sql.Expression {
+	if !isFoldable(e) {
+		return e
+	}
+
+	val, err := e.Eval(ctx, nil)
+	if err != nil {
+		return e
+	}
+
+	return expression.NewLiteral(val, e.Type())
+}
+
+// isFoldable returns whether the expression given always evaluates to the same value for the duration of a query,
+// without needing a row to do so.
+func isFoldable(e sql.Expression) bool {
+	if !e.Resolved() || !isEvaluable(e) {
+		return false
+	}
+
+	foldable := true
+	sql.Inspect(e, func(e sql.Expression) bool {
+		switch e := e.(type) {
+		case sql.Aggregation, *expression.UserVar, *expression.BindVar:
+			foldable = false
+		case sql.NonDeterministicExpression:
+			if e.IsNonDeterministic() {
+				foldable = false
+			}
+		}
+		return foldable
+	})
+
+	return foldable
+}
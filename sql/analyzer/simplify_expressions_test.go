@@ -0,0 +1,244 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/expression/function"
+	"github.com/dolthub/go-mysql-server/sql/expression/function/aggregation"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestSimplifyExpressionsFilter(t *testing.T) {
+	inner := memory.NewTable("foo", nil)
+	rule := getRule("simplify_expressions")
+
+	testCases := []struct {
+		filter   sql.Expression
+		expected sql.Node
+	}{
+		// The first cases are those of the eval_filter rule this rule replaces.
+		{
+			and(
+				eq(lit(5), lit(5)),
+				eq(col(0, "foo", "bar"), lit(5)),
+			),
+			plan.NewFilter(
+				eq(col(0, "foo", "bar"), lit(5)),
+				plan.NewResolvedTable(inner),
+			),
+		},
+		{
+			and(
+				eq(col(0, "foo", "bar"), lit(5)),
+				eq(lit(5), lit(5)),
+			),
+			plan.NewFilter(
+				eq(col(0, "foo", "bar"), lit(5)),
+				plan.NewResolvedTable(inner),
+			),
+		},
+		{
+			and(
+				eq(lit(5), lit(4)),
+				eq(col(0, "foo", "bar"), lit(5)),
+			),
+			plan.EmptyTable,
+		},
+		{
+			and(
+				eq(col(0, "foo", "bar"), lit(5)),
+				eq(lit(5), lit(4)),
+			),
+			plan.EmptyTable,
+		},
+		{
+			and(
+				eq(lit(4), lit(4)),
+				eq(lit(5), lit(5)),
+			),
+			plan.NewResolvedTable(inner),
+		},
+		{
+			or(
+				eq(lit(5), lit(4)),
+				eq(col(0, "foo", "bar"), lit(5)),
+			),
+			plan.NewFilter(
+				eq(col(0, "foo", "bar"), lit(5)),
+				plan.NewResolvedTable(inner),
+			),
+		},
+		{
+			or(
+				eq(col(0, "foo", "bar"), lit(5)),
+				eq(lit(5), lit(4)),
+			),
+			plan.NewFilter(
+				eq(col(0, "foo", "bar"), lit(5)),
+				plan.NewResolvedTable(inner),
+			),
+		},
+		{
+			or(
+				eq(lit(5), lit(5)),
+				eq(col(0, "foo", "bar"), lit(5)),
+			),
+			plan.NewResolvedTable(inner),
+		},
+		{
+			or(
+				eq(col(0, "foo", "bar"), lit(5)),
+				eq(lit(5), lit(5)),
+			),
+			plan.NewResolvedTable(inner),
+		},
+		{
+			or(
+				eq(lit(5), lit(4)),
+				eq(lit(5), lit(4)),
+			),
+			plan.EmptyTable,
+		},
+		{
+			and(
+				eq(col(0, "foo", "bar"), lit(5)),
+				expression.NewLiteral(true, sql.Boolean),
+			),
+			plan.NewFilter(
+				eq(col(0, "foo", "bar"), lit(5)),
+				plan.NewResolvedTable(inner),
+			),
+		},
+		{
+			not(not(eq(col(0, "foo", "bar"), lit(5)))),
+			plan.NewFilter(
+				eq(col(0, "foo", "bar"), lit(5)),
+				plan.NewResolvedTable(inner),
+			),
+		},
+		{
+			not(not(not(eq(col(0, "foo", "bar"), lit(5))))),
+			plan.NewFilter(
+				not(eq(col(0, "foo", "bar"), lit(5))),
+				plan.NewResolvedTable(inner),
+			),
+		},
+		{
+			not(eq(lit(5), lit(4))),
+			plan.NewResolvedTable(inner),
+		},
+		{
+			in(col(0, "foo", "bar"), tuple(lit(5))),
+			plan.NewFilter(
+				eq(col(0, "foo", "bar"), lit(5)),
+				plan.NewResolvedTable(inner),
+			),
+		},
+		{
+			in(col(0, "foo", "bar"), tuple(lit(5), lit(6))),
+			plan.NewFilter(
+				in(col(0, "foo", "bar"), tuple(lit(5), lit(6))),
+				plan.NewResolvedTable(inner),
+			),
+		},
+		{
+			eq(col(0, "foo", "bar"), expression.NewArithmetic(lit(2), lit(3), "+")),
+			plan.NewFilter(
+				eq(col(0, "foo", "bar"), expression.NewLiteral(int64(5), sql.Int64)),
+				plan.NewResolvedTable(inner),
+			),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.filter.String(), func(t *testing.T) {
+			require := require.New(t)
+			node := plan.NewFilter(tt.filter, plan.NewResolvedTable(inner))
+			result, err := rule.Apply(sql.NewEmptyContext(), NewDefault(nil), node, nil)
+			require.NoError(err)
+			require.Equal(tt.expected, result)
+		})
+	}
+}
+
+func TestSimplifyExpressionsJoin(t *testing.T) {
+	require := require.New(t)
+	rule := getRule("simplify_expressions")
+
+	t1 := plan.NewResolvedTable(memory.NewTable("t1", nil))
+	t2 := plan.NewResolvedTable(memory.NewTable("t2", nil))
+
+	node := plan.NewInnerJoin(t1, t2, eq(lit(1), lit(1)))
+	result, err := rule.Apply(sql.NewEmptyContext(), NewDefault(nil), node, nil)
+	require.NoError(err)
+	require.Equal(plan.NewCrossJoin(t1, t2), result)
+
+	node = plan.NewInnerJoin(t1, t2, and(eq(col(0, "t1", "a"), col(1, "t2", "b")), eq(lit(1), lit(1))))
+	result, err = rule.Apply(sql.NewEmptyContext(), NewDefault(nil), node, nil)
+	require.NoError(err)
+	require.Equal(plan.NewInnerJoin(t1, t2, eq(col(0, "t1", "a"), col(1, "t2", "b"))), result)
+}
+
+func TestSimplifyExpressionsNonFoldable(t *testing.T) {
+	rule := getRule("simplify_expressions")
+	inner := plan.NewResolvedTable(memory.NewTable("foo", nil))
+
+	rand, err := function.NewRand()
+	require.NoError(t, err)
+	seededRand, err := function.NewRand(lit(1))
+	require.NoError(t, err)
+	seededValue, err := seededRand.Eval(sql.NewEmptyContext(), nil)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		filter   sql.Expression
+		expected sql.Expression
+	}{
+		{
+			"aggregation",
+			eq(col(0, "foo", "bar"), aggregation.NewCount(lit(1))),
+			eq(col(0, "foo", "bar"), aggregation.NewCount(lit(1))),
+		},
+		{
+			"user variable",
+			eq(col(0, "foo", "bar"), expression.NewUserVar("x")),
+			eq(col(0, "foo", "bar"), expression.NewUserVar("x")),
+		},
+		{
+			"bind variable",
+			eq(col(0, "foo", "bar"), expression.NewBindVar("v1")),
+			eq(col(0, "foo", "bar"), expression.NewBindVar("v1")),
+		},
+		{
+			"non deterministic function",
+			eq(col(0, "foo", "bar"), rand),
+			eq(col(0, "foo", "bar"), rand),
+		},
+		{
+			"non deterministic operand",
+			eq(col(0, "foo", "bar"), expression.NewArithmetic(rand, lit(1), "+")),
+			eq(col(0, "foo", "bar"), expression.NewArithmetic(rand, lit(1), "+")),
+		},
+		{
+			"deterministic function",
+			eq(col(0, "foo", "bar"), seededRand),
+			eq(col(0, "foo", "bar"), expression.NewLiteral(seededValue, sql.Float64)),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			node := plan.NewFilter(tt.filter, inner)
+			result, err := rule.Apply(sql.NewEmptyContext(), NewDefault(nil), node, nil)
+			require.NoError(err)
+			require.Equal(plan.NewFilter(tt.expected, inner), result)
+		})
+	}
+}
@@ -210,13 +210,13 @@ func withTable(node sql.Node, table sql.Table) (sql.Node, error) {
 				return nil, ErrInAnalysis.New("attempted to set more than one table in withTable()")
 			}
 			foundTable = true
-			return plan.NewResolvedTable(table), nil
+			return n.WithTable(table), nil
 		case *plan.IndexedTableAccess:
 			if foundTable {
 				return nil, ErrInAnalysis.New("attempted to set more than one table in withTable()")
 			}
 			foundTable = true
-			return n.WithChildren(plan.NewResolvedTable(table))
+			return n.WithChildren(n.ResolvedTable.WithTable(table))
 		default:
 			return n, nil
 		}
@@ -119,6 +119,7 @@ func applyTriggers(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 
 	var affectedTables []string
 	var triggerEvent plan.TriggerEvent
+	var joinedDelete *plan.DeleteFrom
 	plan.Inspect(n, func(n sql.Node) bool {
 		switch n := n.(type) {
 		case *plan.InsertInto:
@@ -128,12 +129,20 @@ func applyTriggers(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 			affectedTables = append(affectedTables, getTableName(n))
 			triggerEvent = plan.UpdateTrigger
 		case *plan.DeleteFrom:
+			if len(n.Targets) > 0 {
+				joinedDelete = n
+				return true
+			}
 			affectedTables = append(affectedTables, getTableName(n))
 			triggerEvent = plan.DeleteTrigger
 		}
 		return true
 	})
 
+	if joinedDelete != nil {
+		return applyJoinedDeleteTriggers(ctx, a, n, scope, joinedDelete)
+	}
+
 	if len(affectedTables) == 0 {
 		return n, nil
 	}
@@ -193,6 +202,89 @@ func applyTriggers(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 	return n, nil
 }
 
+// applyJoinedDeleteTriggers gives the deletion given, which joins several tables, the DELETE triggers of the tables it
+// deletes from, which it executes for each of their rows it deletes, and returns the node given with it.
+func applyJoinedDeleteTriggers(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope, deleteFrom *plan.DeleteFrom) (sql.Node, error) {
+	tables, err := deleteFrom.TargetTables()
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := a.Catalog.Database(ctx.GetCurrentDatabase())
+	if err != nil {
+		return nil, err
+	}
+	tdb, ok := database.(sql.TriggerDatabase)
+	if !ok {
+		return n, nil
+	}
+
+	triggers, err := tdb.GetTriggers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsedTriggers []*plan.CreateTrigger
+	for _, trigger := range triggers {
+		parsedTrigger, err := parse.Parse(ctx, trigger.CreateStatement)
+		if err != nil {
+			return nil, err
+		}
+		ct, ok := parsedTrigger.(*plan.CreateTrigger)
+		if !ok {
+			return nil, sql.ErrTriggerCreateStatementInvalid.New(trigger.CreateStatement)
+		}
+		if triggerEventsMatch(plan.DeleteTrigger, ct.TriggerEvent) {
+			parsedTriggers = append(parsedTriggers, ct)
+		}
+	}
+
+	targetTriggers := make([][]*plan.TriggerExecutor, len(tables))
+	found := false
+	for i, table := range tables {
+		rt := getResolvedTable(table)
+		if rt == nil {
+			continue
+		}
+
+		var affectedTriggers []*plan.CreateTrigger
+		for _, ct := range parsedTriggers {
+			if strings.EqualFold(rt.Name(), getTableName(ct.Table)) {
+				affectedTriggers = append(affectedTriggers, ct)
+			}
+		}
+
+		// The triggers are executed in order for each row, so the AFTER triggers aren't reversed
+		beforeTriggers, afterTriggers := OrderTriggers(affectedTriggers)
+		for _, trigger := range append(beforeTriggers, afterTriggers...) {
+			if err := validateNoCircularUpdates(trigger, table, scope); err != nil {
+				return nil, err
+			}
+
+			triggerLogic, err := getTriggerLogic(ctx, a, table, scope, trigger)
+			if err != nil {
+				return nil, err
+			}
+			targetTriggers[i] = append(targetTriggers[i], plan.NewTriggerExecutor(table, triggerLogic, plan.DeleteTrigger, plan.TriggerTime(trigger.TriggerTime), sql.TriggerDefinition{
+				Name:            trigger.TriggerName,
+				CreateStatement: trigger.CreateTriggerString,
+			}))
+			found = true
+		}
+	}
+
+	if !found {
+		return n, nil
+	}
+
+	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
+		if n, ok := n.(*plan.DeleteFrom); ok && len(n.Targets) > 0 {
+			return n.WithTargetTriggers(targetTriggers), nil
+		}
+		return n, nil
+	})
+}
+
 // applyTrigger applies the trigger given to the node given, returning the resulting node
 func applyTrigger(ctx *sql.Context, a *Analyzer, originalNode, n sql.Node, scope *Scope, trigger *plan.CreateTrigger) (sql.Node, error) {
 	triggerLogic, err := getTriggerLogic(ctx, a, originalNode, scope, trigger)
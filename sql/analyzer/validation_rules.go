@@ -33,9 +33,14 @@ var (
 	// ErrValidationOrderBy is returned when the order by contains aggregation
 	// expressions.
 	ErrValidationOrderBy = errors.NewKind("OrderBy does not support aggregation expressions")
-	// ErrValidationGroupBy is returned when the aggregation expression does not
-	// appear in the grouping columns.
-	ErrValidationGroupBy = errors.NewKind("GroupBy aggregate expression '%v' doesn't appear in the grouping columns")
+	// ErrValidationGroupBy is returned when a selected expression or a HAVING
+	// condition uses a column that isn't aggregated nor functionally dependent
+	// on the grouping columns while sql_mode has ONLY_FULL_GROUP_BY.
+	ErrValidationGroupBy = errors.NewKind("Expression #%d of %s is not in GROUP BY clause and contains nonaggregated column '%s' which is not functionally dependent on columns in GROUP BY clause; this is incompatible with sql_mode=only_full_group_by")
+	// ErrValidationAggregationWithoutGroupBy is returned when a query with
+	// aggregations and no GROUP BY selects a column outside of them while
+	// sql_mode has ONLY_FULL_GROUP_BY.
+	ErrValidationAggregationWithoutGroupBy = errors.NewKind("In aggregated query without GROUP BY, expression #%d of SELECT list contains nonaggregated column '%s'; this is incompatible with sql_mode=only_full_group_by")
 	// ErrValidationSchemaSource is returned when there is any column source
 	// that does not match the table name.
 	ErrValidationSchemaSource = errors.NewKind("one or more schema sources are empty")
@@ -158,45 +163,243 @@ func validateGroupBy(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (s
 	span, _ := ctx.Span("validate_group_by")
 	defer span.Finish()
 
-	switch n := n.(type) {
-	case *plan.GroupBy:
-		// Allow the parser use the GroupBy node to eval the aggregation functions
-		// for sql statements that don't make use of the GROUP BY expression.
-		if len(n.GroupByExprs) == 0 {
-			return n, nil
+	if !sql.SqlModeEnabled(ctx, sql.SqlMode_OnlyFullGroupBy) {
+		return n, nil
+	}
+
+	// HAVING selects the columns it uses that aren't selected in the grouping below it, which the projection above it
+	// removes, so they're validated as part of its condition instead.
+	havings := make(map[*plan.GroupBy]*plan.Having)
+	selected := make(map[*plan.GroupBy]int)
+	var err error
+	plan.Inspect(n, func(node sql.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch node := node.(type) {
+		case *plan.Project:
+			if having, ok := node.Child.(*plan.Having); ok {
+				if groupBy, e := findGroupBy(having); e == nil {
+					selected[groupBy] = len(node.Projections)
+				}
+			}
+		case *plan.Having:
+			if groupBy, e := findGroupBy(node); e == nil {
+				havings[groupBy] = node
+			}
+		case *plan.GroupBy:
+			err = validateGroupedColumns(ctx, node, havings[node], selected[node])
 		}
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
 
-		var validAggs []string
-		for _, expr := range n.GroupByExprs {
-			validAggs = append(validAggs, expr.String())
+// validateGroupedColumns returns an error if any of the selected expressions of the node given, or the condition of
+// the HAVING given over it, uses a column outside of an aggregation which isn't functionally dependent on its grouping
+// expressions, and so can have different values in a group. A column is functionally dependent on the grouping
+// expressions if it's one of them, if the WHERE clause makes it equal to a constant, or if its table has a primary key
+// or a unique key of non nullable columns that are all functionally dependent on them. Only the first number of
+// selected expressions given are validated if it isn't zero, the others being the columns HAVING selects.
+func validateGroupedColumns(ctx *sql.Context, n *plan.GroupBy, having *plan.Having, selected int) error {
+	tableKeys, err := getGroupedTableKeys(ctx, n.Child)
+	if err != nil {
+		return err
+	}
+
+	// Grouping by an alias of the selected expressions projects it below the node
+	aliases := make(map[string]sql.Expression)
+	if project, ok := n.Child.(*plan.Project); ok {
+		for _, expr := range project.Projections {
+			if alias, ok := expr.(*expression.Alias); ok {
+				aliases[strings.ToLower(alias.Name())] = alias.Child
+			}
 		}
+	}
 
-		// TODO: validate columns inside aggregations
-		// and allow any kind of expression that make use of the grouping
-		// columns.
-		for _, expr := range n.SelectedExprs {
-			if _, ok := expr.(sql.Aggregation); !ok {
-				if !isValidAgg(validAggs, expr) {
-					return nil, ErrValidationGroupBy.New(expr.String())
+	grouped := make(map[string]bool)
+	groupedColumns := getConstantColumns(n.Child)
+	for _, expr := range n.GroupByExprs {
+		if field, ok := expr.(*expression.GetField); ok && field.Table() == "" && aliases[strings.ToLower(field.Name())] != nil {
+			expr = aliases[strings.ToLower(field.Name())]
+		}
+		grouped[strings.ToLower(expr.String())] = true
+		if field, ok := expr.(*expression.GetField); ok {
+			groupedColumns[strings.ToLower(field.Table()+"."+field.Name())] = true
+		}
+	}
+
+	dependentTables := make(map[string]bool)
+	for name, keys := range tableKeys {
+		for _, key := range keys {
+			dependent := true
+			for _, column := range key {
+				dependent = dependent && groupedColumns[name+"."+column]
+			}
+			if dependent {
+				dependentTables[name] = true
+			}
+		}
+	}
+
+	ungroupedColumn := func(expr sql.Expression) *expression.GetField {
+		var column *expression.GetField
+		sql.Inspect(expr, func(e sql.Expression) bool {
+			if e == nil || column != nil {
+				return false
+			}
+			switch e := e.(type) {
+			case sql.Aggregation:
+				return false
+			case *expression.Alias:
+				return true
+			case *expression.GetField:
+				// Columns of the outer scope of a subquery are the same for all of its rows
+				table := strings.ToLower(e.Table())
+				if _, ok := tableKeys[table]; ok && !groupedColumns[table+"."+strings.ToLower(e.Name())] && !dependentTables[table] {
+					column = e
 				}
+				return false
 			}
+			return !grouped[strings.ToLower(e.String())]
+		})
+		return column
+	}
+
+	exprs := n.SelectedExprs
+	if selected > 0 && selected < len(exprs) {
+		exprs = exprs[:selected]
+	}
+	for i, expr := range exprs {
+		column := ungroupedColumn(expr)
+		if column == nil {
+			continue
 		}
 
-		return n, nil
+		name := column.Table() + "." + column.Name()
+		if len(n.GroupByExprs) == 0 {
+			return ErrValidationAggregationWithoutGroupBy.New(i+1, name)
+		}
+		return ErrValidationGroupBy.New(i+1, "SELECT list", name)
 	}
 
-	return n, nil
+	if having != nil {
+		for i, expr := range splitConjunction(having.Cond) {
+			if column := ungroupedColumn(expr); column != nil {
+				return ErrValidationGroupBy.New(i+1, "HAVING clause", column.Table()+"."+column.Name())
+			}
+		}
+	}
+
+	return nil
 }
 
-func isValidAgg(validAggs []string, expr sql.Expression) bool {
-	switch expr := expr.(type) {
-	case sql.Aggregation:
+// getConstantColumns returns the lowercase columns, qualified by their tables, that the filters of the node given
+// make equal to a literal, which have the same value in all of its rows.
+func getConstantColumns(n sql.Node) map[string]bool {
+	columns := make(map[string]bool)
+	plan.Inspect(n, func(node sql.Node) bool {
+		filter, ok := node.(*plan.Filter)
+		if !ok {
+			return true
+		}
+		for _, expr := range splitConjunction(filter.Expression) {
+			eq, ok := expr.(*expression.Equals)
+			if !ok {
+				continue
+			}
+			field, ok := eq.Left().(*expression.GetField)
+			other := eq.Right()
+			if !ok {
+				field, ok = eq.Right().(*expression.GetField)
+				other = eq.Left()
+			}
+			if _, isLiteral := other.(*expression.Literal); ok && isLiteral {
+				columns[strings.ToLower(field.Table()+"."+field.Name())] = true
+			}
+		}
 		return true
-	case *expression.Alias:
-		return isValidAgg(validAggs, expr.Child)
-	default:
-		return stringContains(validAggs, expr.String())
+	})
+	return columns
+}
+
+// getGroupedTableKeys returns the primary and unique keys of the tables of the node given, which determine the values
+// of all of their columns, by the lowercase names of the tables, or their aliases if they have one. Subqueries have no
+// keys.
+func getGroupedTableKeys(ctx *sql.Context, n sql.Node) (map[string][][]string, error) {
+	tableKeys := make(map[string][][]string)
+	var err error
+	plan.Inspect(n, func(node sql.Node) bool {
+		if err != nil {
+			return false
+		}
+
+		switch node := node.(type) {
+		case *plan.TableAlias, *plan.ResolvedTable, *plan.IndexedTableAccess:
+			var keys [][]string
+			if rt := getResolvedTable(node); rt != nil {
+				keys, err = getUniqueKeys(ctx, rt.Table)
+			}
+			tableKeys[strings.ToLower(node.(sql.Nameable).Name())] = keys
+			return false
+		case *plan.SubqueryAlias:
+			tableKeys[strings.ToLower(node.Name())] = nil
+			return false
+		}
+		return true
+	})
+
+	return tableKeys, err
+}
+
+// getUniqueKeys returns the lowercase columns of the primary key of the table given, and of each of its unique indexes
+// on columns that can't be null.
+func getUniqueKeys(ctx *sql.Context, table sql.Table) ([][]string, error) {
+	var keys [][]string
+
+	var primaryKey []string
+	for _, col := range table.Schema() {
+		if col.PrimaryKey {
+			primaryKey = append(primaryKey, strings.ToLower(col.Name))
+		}
+	}
+	if len(primaryKey) > 0 {
+		keys = append(keys, primaryKey)
+	}
+
+	indexed, ok := table.(sql.IndexedTable)
+	if !ok {
+		return keys, nil
 	}
+	indexes, err := indexed.GetIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, index := range indexes {
+		if !index.IsUnique() {
+			continue
+		}
+
+		var key []string
+		for _, expr := range index.Expressions() {
+			col := plan.GetColumnFromIndexExpr(expr, table)
+			if col == nil || col.Nullable {
+				key = nil
+				break
+			}
+			key = append(key, strings.ToLower(col.Name))
+		}
+		if len(key) > 0 {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
 }
 
 func validateSchemaSource(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
@@ -92,43 +92,65 @@ func TestValidateGroupByErr(t *testing.T) {
 
 	vr := getValidationRule(validateGroupByRule)
 
-	_, err := vr.Apply(sql.NewEmptyContext(), nil, dummyNode{true}, nil)
+	ctx := sql.NewEmptyContext()
+	require.NoError(ctx.Set(ctx, "sql_mode", sql.LongText, "ONLY_FULL_GROUP_BY"))
+
+	_, err := vr.Apply(ctx, nil, dummyNode{true}, nil)
 	require.NoError(err)
-	_, err = vr.Apply(sql.NewEmptyContext(), nil, dummyNode{false}, nil)
+	_, err = vr.Apply(ctx, nil, dummyNode{false}, nil)
 	require.NoError(err)
 
 	childSchema := sql.Schema{
-		{Name: "col1", Type: sql.Text},
-		{Name: "col2", Type: sql.Int64},
+		{Name: "col1", Type: sql.Text, Source: "test"},
+		{Name: "col2", Type: sql.Int64, Source: "test"},
 	}
-
 	child := memory.NewTable("test", childSchema)
 
-	rows := []sql.Row{
-		sql.NewRow("col1_1", int64(1111)),
-		sql.NewRow("col1_1", int64(2222)),
-		sql.NewRow("col1_2", int64(4444)),
-		sql.NewRow("col1_1", int64(1111)),
-		sql.NewRow("col1_2", int64(4444)),
-	}
-
-	for _, r := range rows {
-		require.NoError(child.Insert(sql.NewEmptyContext(), r))
-	}
-
 	p := plan.NewGroupBy(
 		[]sql.Expression{
-			expression.NewGetField(0, sql.Text, "col1", true),
-			expression.NewGetField(1, sql.Int64, "col2", true),
+			expression.NewGetFieldWithTable(0, sql.Text, "test", "col1", true),
+			expression.NewGetFieldWithTable(1, sql.Int64, "test", "col2", true),
 		},
 		[]sql.Expression{
-			expression.NewGetField(0, sql.Text, "col1", true),
+			expression.NewGetFieldWithTable(0, sql.Text, "test", "col1", true),
 		},
 		plan.NewResolvedTable(child),
 	)
 
+	_, err = vr.Apply(ctx, nil, p, nil)
+	require.True(ErrValidationGroupBy.Is(err))
+	require.Equal("Expression #2 of SELECT list is not in GROUP BY clause and contains nonaggregated column "+
+		"'test.col2' which is not functionally dependent on columns in GROUP BY clause; this is incompatible with "+
+		"sql_mode=only_full_group_by", err.Error())
+
+	// The grouping columns are allowed without ONLY_FULL_GROUP_BY, which the default sql_mode has
 	_, err = vr.Apply(sql.NewEmptyContext(), nil, p, nil)
-	require.Error(err)
+	require.True(ErrValidationGroupBy.Is(err))
+	emptyModeCtx := sql.NewEmptyContext()
+	require.NoError(emptyModeCtx.Set(emptyModeCtx, "sql_mode", sql.LongText, ""))
+	_, err = vr.Apply(emptyModeCtx, nil, p, nil)
+	require.NoError(err)
+
+	// The columns of a table grouped by its primary key are functionally dependent on it
+	keyedChild := memory.NewTable("test", sql.Schema{
+		{Name: "col1", Type: sql.Text, Source: "test", PrimaryKey: true},
+		{Name: "col2", Type: sql.Int64, Source: "test"},
+	})
+	keyed, err := p.WithChildren(plan.NewResolvedTable(keyedChild))
+	require.NoError(err)
+	_, err = vr.Apply(ctx, nil, keyed, nil)
+	require.NoError(err)
+
+	p = plan.NewGroupBy(
+		[]sql.Expression{
+			expression.NewGetFieldWithTable(0, sql.Text, "test", "col1", true),
+			aggregation.NewCount(expression.NewGetFieldWithTable(1, sql.Int64, "test", "col2", true)),
+		},
+		nil,
+		plan.NewResolvedTable(child),
+	)
+	_, err = vr.Apply(ctx, nil, p, nil)
+	require.True(ErrValidationAggregationWithoutGroupBy.Is(err))
 }
 
 func TestValidateSchemaSource(t *testing.T) {
@@ -0,0 +1,49 @@
+package sql
+
+import (
+	errors "gopkg.in/src-d/go-errors.v1"
+)
+
+var (
+	// ErrNoBinaryLog is returned when a statement needs the binary log and the engine doesn't write one.
+	ErrNoBinaryLog = errors.NewKind("You are not using binary logging")
+
+	// ErrBinaryLogNotFound is returned when a binary log file doesn't exist.
+	ErrBinaryLogNotFound = errors.NewKind("Error when executing command SHOW BINLOG EVENTS: Could not find target log")
+)
+
+// BinaryLogFile is a file of the binary log.
+type BinaryLogFile struct {
+	// Name of the file, such as binlog.000001.
+	Name string
+	// Size of the file in bytes.
+	Size uint64
+}
+
+// BinaryLogEvent is an event of the binary log, as SHOW BINLOG EVENTS shows it.
+type BinaryLogEvent struct {
+	// LogName is the name of the file the event is in.
+	LogName string
+	// Pos is the position the event starts at in its file.
+	Pos uint64
+	// Type is the name of the type of the event, such as Write_rows.
+	Type string
+	// ServerID is the server_id of the server the event comes from.
+	ServerID uint32
+	// EndPos is the position the next event starts at.
+	EndPos uint64
+	// Info describes the event, such as the query of a Query event.
+	Info string
+}
+
+// BinaryLog is the binary log of the changes made to the databases, which replicas read to apply them too.
+type BinaryLog interface {
+	// Files returns the files of the log, from the oldest.
+	Files() []BinaryLogFile
+	// Events returns the events of a file from the position given, or of the first file if the name is empty. It
+	// returns ErrBinaryLogNotFound if the file doesn't exist.
+	Events(name string, pos uint64) ([]BinaryLogEvent, error)
+	// Position returns the file and the position the next event will be written at, and the set of the GTIDs of the
+	// transactions written.
+	Position() (file string, pos uint64, executedGTIDs string)
+}
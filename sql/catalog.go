@@ -16,6 +16,12 @@ var ErrDatabaseNotFound = errors.NewKind("database not found: %s")
 // ErrNoDatabaseSelected is thrown when a database is not selected and the query requires one
 var ErrNoDatabaseSelected = errors.NewKind("no database selected")
 
+// ErrDatabaseExists is thrown when CREATE DATABASE is given the name of an existing database
+var ErrDatabaseExists = errors.NewKind("can't create database %s; database exists")
+
+// ErrDatabaseCreationNotSupported is thrown by CREATE DATABASE when the catalog has no DatabaseCreator
+var ErrDatabaseCreationNotSupported = errors.NewKind("creating databases is not supported")
+
 // ErrAsOfNotSupported is thrown when an AS OF query is run on a database that can't support it
 var ErrAsOfNotSupported = errors.NewKind("AS OF not supported for database %s")
 
@@ -28,10 +34,27 @@ type Catalog struct {
 	FunctionRegistry
 	*ProcessList
 	*MemoryManager
-
-	mu    sync.RWMutex
-	dbs   Databases
-	locks sessionLocks
+	// GlobalStatus holds the global values of the status variables the
+	// engine and the server count.
+	GlobalStatus *StatusVariables
+	// SystemVariables holds the system variables of the engine and their
+	// global values.
+	SystemVariables *SystemVariableRegistry
+
+	mu              sync.RWMutex
+	dbs             Databases
+	dbProvider      DatabaseProvider
+	dbCreator       DatabaseCreator
+	locks           sessionLocks
+	tableFunctions  TableFunctionRegistry
+	userManager     UserManager
+	persister       VariablePersister
+	binaryLog       BinaryLog
+	replica         Replica
+	rowPolicies     []RowPolicy
+	schemaListeners []SchemaListener
+	status          []StatusProvider
+	clients         map[uint32]Client
 }
 
 type tableLocks map[string]struct{}
@@ -46,17 +69,294 @@ func NewCatalog() *Catalog {
 		FunctionRegistry: NewFunctionRegistry(),
 		MemoryManager:    NewMemoryManager(ProcessMemory),
 		ProcessList:      NewProcessList(),
+		GlobalStatus:     NewStatusVariables(),
+		SystemVariables:  NewSystemVariables(),
 		locks:            make(sessionLocks),
+		tableFunctions:   NewTableFunctionRegistry(),
+		clients:          make(map[uint32]Client),
+	}
+}
+
+// SetUserManager sets the UserManager used to execute account management statements.
+func (c *Catalog) SetUserManager(um UserManager) {
+	c.mu.Lock()
+	c.userManager = um
+	c.mu.Unlock()
+}
+
+// UserManager returns the UserManager used to execute account management statements, or an error if there is none.
+func (c *Catalog) UserManager() (UserManager, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.userManager == nil {
+		return nil, ErrUserManagementNotSupported.New()
 	}
+	return c.userManager, nil
 }
 
-// AllDatabases returns all databases in the catalog.
+// SetVariablePersister sets the VariablePersister used to persist system variables.
+func (c *Catalog) SetVariablePersister(p VariablePersister) {
+	c.mu.Lock()
+	c.persister = p
+	c.mu.Unlock()
+}
+
+// VariablePersister returns the VariablePersister used to persist system variables, or an error if there is none.
+func (c *Catalog) VariablePersister() (VariablePersister, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.persister == nil {
+		return nil, ErrPersistNotSupported.New()
+	}
+	return c.persister, nil
+}
+
+// SetBinaryLog sets the BinaryLog the SHOW statements of the binary log show.
+func (c *Catalog) SetBinaryLog(l BinaryLog) {
+	c.mu.Lock()
+	c.binaryLog = l
+	c.mu.Unlock()
+}
+
+// BinaryLog returns the BinaryLog of the engine, or an error if it doesn't write one.
+func (c *Catalog) BinaryLog() (BinaryLog, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.binaryLog == nil {
+		return nil, ErrNoBinaryLog.New()
+	}
+	return c.binaryLog, nil
+}
+
+// SetReplica sets the Replica the replication statements control.
+func (c *Catalog) SetReplica(r Replica) {
+	c.mu.Lock()
+	c.replica = r
+	c.mu.Unlock()
+}
+
+// Replica returns the Replica of the engine, or an error if it can't replicate.
+func (c *Catalog) Replica() (Replica, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.replica == nil {
+		return nil, ErrNoReplica.New()
+	}
+	return c.replica, nil
+}
+
+// CurrentAccount returns the account the session of the context given authenticated as. When the catalog doesn't
+// manage users, clients log in as any user from any host, so it's the user of the client on the % host.
+func (c *Catalog) CurrentAccount(ctx *Context) (Account, error) {
+	um, err := c.UserManager()
+	if err != nil {
+		return Account{Name: ctx.Client().User, Host: "%"}, nil
+	}
+	return um.CurrentAccount(ctx)
+}
+
+// PrivilegeManager returns the UserManager of the catalog if it also manages privileges, or an error if it doesn't.
+func (c *Catalog) PrivilegeManager() (PrivilegeManager, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pm, ok := c.userManager.(PrivilegeManager)
+	if !ok {
+		return nil, ErrPrivilegesNotSupported.New()
+	}
+	return pm, nil
+}
+
+// RoleManager returns the UserManager of the catalog if it also manages roles, or an error if it doesn't.
+func (c *Catalog) RoleManager() (RoleManager, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	rm, ok := c.userManager.(RoleManager)
+	if !ok {
+		return nil, ErrRolesNotSupported.New()
+	}
+	return rm, nil
+}
+
+// ProxyManager returns the UserManager of the catalog if it also manages proxy users, or an error if it doesn't.
+func (c *Catalog) ProxyManager() (ProxyManager, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pm, ok := c.userManager.(ProxyManager)
+	if !ok {
+		return nil, ErrProxiesNotSupported.New()
+	}
+	return pm, nil
+}
+
+// PasswordManager returns the UserManager of the catalog if it also enforces the expiration of passwords, or an error
+// if it doesn't.
+func (c *Catalog) PasswordManager() (PasswordManager, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pm, ok := c.userManager.(PasswordManager)
+	if !ok {
+		return nil, ErrPasswordExpirationNotSupported.New()
+	}
+	return pm, nil
+}
+
+// ResourceManager returns the UserManager of the catalog if it also limits the resources of accounts, or an error if
+// it doesn't.
+func (c *Catalog) ResourceManager() (ResourceManager, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	rm, ok := c.userManager.(ResourceManager)
+	if !ok {
+		return nil, ErrResourceLimitsNotSupported.New()
+	}
+	return rm, nil
+}
+
+// AddRowPolicy adds a RowPolicy restricting the rows of tables each session can access. When several policies filter
+// the same table, its rows must satisfy all of them.
+func (c *Catalog) AddRowPolicy(p RowPolicy) {
+	c.mu.Lock()
+	c.rowPolicies = append(c.rowPolicies, p)
+	c.mu.Unlock()
+}
+
+// RowPolicies returns the RowPolicies added to the catalog.
+func (c *Catalog) RowPolicies() []RowPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result = make([]RowPolicy, len(c.rowPolicies))
+	copy(result, c.rowPolicies)
+	return result
+}
+
+// AddSchemaListener adds a SchemaListener notified of the changes that DDL statements make to databases, tables and
+// indexes.
+func (c *Catalog) AddSchemaListener(l SchemaListener) {
+	c.mu.Lock()
+	c.schemaListeners = append(c.schemaListeners, l)
+	c.mu.Unlock()
+}
+
+// SchemaListeners returns the SchemaListeners added to the catalog.
+func (c *Catalog) SchemaListeners() []SchemaListener {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result = make([]SchemaListener, len(c.schemaListeners))
+	copy(result, c.schemaListeners)
+	return result
+}
+
+// AddStatusProvider adds a StatusProvider of status variables shown by SHOW STATUS.
+func (c *Catalog) AddStatusProvider(p StatusProvider) {
+	c.mu.Lock()
+	c.status = append(c.status, p)
+	c.mu.Unlock()
+}
+
+// Status returns the current global values of the status variables of the catalog and of all its StatusProviders by
+// name.
+func (c *Catalog) Status() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result = c.GlobalStatus.Status()
+	for _, p := range c.status {
+		for k, v := range p.Status() {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// StatusOfSession returns the current values of the status variables in the session of the context given by name,
+// which are the global ones but for the variables counted for each session.
+func (c *Catalog) StatusOfSession(ctx *Context) map[string]interface{} {
+	result := c.Status()
+	session := SessionStatus(ctx)
+	for _, name := range SessionStatusVariables {
+		result[name] = session.Get(name)
+	}
+	return result
+}
+
+// IncrementStatus adds delta to a status variable, both to its global value and to its value in the session of the
+// context given, if it counts them.
+func (c *Catalog) IncrementStatus(ctx *Context, name string, delta int64) {
+	c.GlobalStatus.Add(name, delta)
+	SessionStatus(ctx).Add(name, delta)
+}
+
+// AddClient records the client of a connection once it has connected, until RemoveClient is called for it.
+func (c *Catalog) AddClient(id uint32, client Client) {
+	c.mu.Lock()
+	c.clients[id] = client
+	c.mu.Unlock()
+}
+
+// RemoveClient forgets the client of a connection once it has disconnected.
+func (c *Catalog) RemoveClient(id uint32) {
+	c.mu.Lock()
+	delete(c.clients, id)
+	c.mu.Unlock()
+}
+
+// Clients returns the clients connected, by connection ID.
+func (c *Catalog) Clients() map[uint32]Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result = make(map[uint32]Client, len(c.clients))
+	for id, client := range c.clients {
+		result[id] = client
+	}
+	return result
+}
+
+// AllDatabases returns all databases in the catalog, loading all the databases of its DatabaseProvider.
 func (c *Catalog) AllDatabases() Databases {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	var result = make(Databases, len(c.dbs))
 	copy(result, c.dbs)
+	if c.dbProvider != nil {
+		for _, db := range c.dbProvider.AllDatabases() {
+			if _, err := c.dbs.Database(db.Name()); err != nil {
+				result = append(result, db)
+			}
+		}
+	}
+	return result
+}
+
+// DatabaseNames returns the names of all databases in the catalog, without loading the databases of its
+// DatabaseProvider.
+func (c *Catalog) DatabaseNames() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result = make([]string, len(c.dbs))
+	for i, db := range c.dbs {
+		result[i] = db.Name()
+	}
+	if c.dbProvider != nil {
+		for _, name := range c.dbProvider.DatabaseNames() {
+			if _, err := c.dbs.Database(name); err != nil {
+				result = append(result, name)
+			}
+		}
+	}
 	return result
 }
 
@@ -67,10 +367,46 @@ func (c *Catalog) AddDatabase(db Database) {
 	c.mu.Unlock()
 }
 
+// SetDatabaseProvider sets the DatabaseProvider that provides the databases that weren't added to the catalog. The
+// databases added take precedence over the ones of the provider with the same name.
+func (c *Catalog) SetDatabaseProvider(provider DatabaseProvider) {
+	c.mu.Lock()
+	c.dbProvider = provider
+	c.mu.Unlock()
+}
+
+// SetDatabaseCreator sets the DatabaseCreator that creates the databases of CREATE DATABASE statements.
+func (c *Catalog) SetDatabaseCreator(creator DatabaseCreator) {
+	c.mu.Lock()
+	c.dbCreator = creator
+	c.mu.Unlock()
+}
+
+// CreateDatabase creates a database with the DatabaseCreator of the catalog and adds it, unless there's already one
+// with the name given.
+func (c *Catalog) CreateDatabase(ctx *Context, name string, collation Collation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.database(name); err == nil {
+		return ErrDatabaseExists.New(name)
+	}
+	if c.dbCreator == nil {
+		return ErrDatabaseCreationNotSupported.New()
+	}
+
+	db, err := c.dbCreator.CreateDatabase(ctx, name, collation)
+	if err != nil {
+		return err
+	}
+	c.dbs.Add(db)
+	return nil
+}
+
 func (c *Catalog) HasDB(db string) bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	_, err := c.dbs.Database(db)
+	_, err := c.database(db)
 
 	return err == nil
 }
@@ -79,14 +415,28 @@ func (c *Catalog) HasDB(db string) bool {
 func (c *Catalog) Database(db string) (Database, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.dbs.Database(db)
+	return c.database(db)
+}
+
+// database returns the database added to the catalog with the given name, or the one of its DatabaseProvider.
+func (c *Catalog) database(name string) (Database, error) {
+	db, err := c.dbs.Database(name)
+	if ErrDatabaseNotFound.Is(err) && c.dbProvider != nil {
+		return c.dbProvider.Database(name)
+	}
+	return db, err
 }
 
 // Table returns the table in the given database with the given name.
 func (c *Catalog) Table(ctx *Context, db, table string) (Table, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.dbs.Table(ctx, db, table)
+
+	database, err := c.database(db)
+	if err != nil {
+		return nil, err
+	}
+	return databaseTable(ctx, database, table)
 }
 
 // TableAsOf returns the table in the given database with the given name, as it existed at the time given. The database
@@ -94,7 +444,53 @@ func (c *Catalog) Table(ctx *Context, db, table string) (Table, error) {
 func (c *Catalog) TableAsOf(ctx *Context, db, table string, time interface{}) (Table, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.dbs.TableAsOf(ctx, db, table, time)
+
+	database, err := c.database(db)
+	if err != nil {
+		return nil, err
+	}
+	return databaseTableAsOf(ctx, database, table, time)
+}
+
+// ResolveFunction returns the function with the name given, as visible to the session of the context given. Functions
+// registered in the session take precedence over functions provided by the current database, which in turn take
+// precedence over functions registered in the catalog.
+func (c *Catalog) ResolveFunction(ctx *Context, name string) (Function, error) {
+	if fs, ok := ctx.Session.(FunctionSession); ok {
+		if fn, ok := fs.Function(name); ok {
+			return fn, nil
+		}
+	}
+
+	if dbName := ctx.GetCurrentDatabase(); dbName != "" {
+		if db, err := c.Database(dbName); err == nil {
+			if fdb, ok := db.(FunctionDatabase); ok {
+				fn, ok, err := fdb.Function(ctx, name)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					return fn, nil
+				}
+			}
+		}
+	}
+
+	return c.FunctionRegistry.Function(name)
+}
+
+// RegisterTableFunction registers table functions, which can then be used in the FROM clause of queries.
+func (c *Catalog) RegisterTableFunction(fn ...TableFunction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tableFunctions.Register(fn...)
+}
+
+// TableFunction returns the table function with the name given.
+func (c *Catalog) TableFunction(name string) (TableFunction, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tableFunctions.Function(name)
 }
 
 // Databases is a collection of Database.
@@ -129,7 +525,11 @@ func (d Databases) Table(ctx *Context, dbName string, tableName string) (Table,
 	if err != nil {
 		return nil, err
 	}
+	return databaseTable(ctx, db, tableName)
+}
 
+// databaseTable returns the Table of the database given with the given name if it exists.
+func databaseTable(ctx *Context, db Database, tableName string) (Table, error) {
 	tbl, ok, err := db.GetTableInsensitive(ctx, tableName)
 
 	if err != nil {
@@ -158,7 +558,11 @@ func (d Databases) TableAsOf(ctx *Context, dbName string, tableName string, asOf
 	if err != nil {
 		return nil, err
 	}
+	return databaseTableAsOf(ctx, db, tableName, asOf)
+}
 
+// databaseTableAsOf returns the table of the database given with the name given at the time given, if it existed.
+func databaseTableAsOf(ctx *Context, db Database, tableName string, asOf interface{}) (Table, error) {
 	versionedDb, ok := db.(VersionedDatabase)
 	if !ok {
 		return nil, ErrAsOfNotSupported.New(tableName)
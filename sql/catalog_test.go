@@ -2,6 +2,7 @@ package sql_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -47,6 +48,66 @@ func TestCatalogDatabase(t *testing.T) {
 	require.Equal(mydb, db)
 }
 
+// tenantProvider provides a database for each tenant, created the first time it's used.
+type tenantProvider struct {
+	tenants []string
+	loaded  map[string]sql.Database
+}
+
+func (p *tenantProvider) Database(name string) (sql.Database, error) {
+	for _, tenant := range p.tenants {
+		if strings.EqualFold(tenant, name) {
+			db, ok := p.loaded[tenant]
+			if !ok {
+				db = memory.NewDatabase(tenant)
+				p.loaded[tenant] = db
+			}
+			return db, nil
+		}
+	}
+	return nil, sql.ErrDatabaseNotFound.New(name)
+}
+
+func (p *tenantProvider) DatabaseNames() []string {
+	return p.tenants
+}
+
+func (p *tenantProvider) AllDatabases() []sql.Database {
+	dbs := make([]sql.Database, len(p.tenants))
+	for i, tenant := range p.tenants {
+		dbs[i], _ = p.Database(tenant)
+	}
+	return dbs
+}
+
+func TestCatalogDatabaseProvider(t *testing.T) {
+	require := require.New(t)
+
+	provider := &tenantProvider{tenants: []string{"tenant1", "tenant2", "foo"}, loaded: make(map[string]sql.Database)}
+	foo := memory.NewDatabase("foo")
+	c := sql.NewCatalog()
+	c.AddDatabase(foo)
+	c.SetDatabaseProvider(provider)
+
+	db, err := c.Database("TENANT2")
+	require.NoError(err)
+	require.Equal("tenant2", db.Name())
+	require.Len(provider.loaded, 1)
+	require.True(c.HasDB("tenant1"))
+	require.False(c.HasDB("tenant3"))
+
+	db, err = c.Database("foo")
+	require.NoError(err)
+	require.Equal(foo, db)
+
+	_, err = c.Table(sql.NewEmptyContext(), "tenant1", "bar")
+	require.EqualError(err, "table not found: bar")
+
+	require.Equal([]string{"foo", "tenant1", "tenant2"}, c.DatabaseNames())
+	require.Len(provider.loaded, 2)
+	require.Equal(sql.Databases{foo, provider.loaded["tenant1"], provider.loaded["tenant2"]}, c.AllDatabases())
+}
+
 func TestCatalogTable(t *testing.T) {
 	require := require.New(t)
 
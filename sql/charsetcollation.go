@@ -2,6 +2,7 @@ package sql
 
 import (
 	"fmt"
+	"strings"
 
 	"gopkg.in/src-d/go-errors.v1"
 )
@@ -1341,3 +1342,8 @@ func (c Collation) PadSpace() string {
 	}
 	return s.PadSpace
 }
+
+// IsCaseInsensitive returns whether the collation compares strings without regard to letter case.
+func (c Collation) IsCaseInsensitive() bool {
+	return strings.HasSuffix(string(c), "_ci")
+}
@@ -13,6 +13,9 @@ type Column struct {
 	Type Type
 	// Default contains the default value of the column or nil if it was not explicitly defined. A nil instance is valid, thus calls do not error.
 	Default *ColumnDefaultValue
+	// OnUpdate contains the value the column is set to when an update changes its row without assigning it, or nil if
+	// it has none. It's always the current time, as set by ON UPDATE CURRENT_TIMESTAMP.
+	OnUpdate *ColumnDefaultValue
 	// AutoIncrement is true if the column auto-increments.
 	AutoIncrement bool
 	// Nullable is true if the column can contain NULL values, or false
@@ -22,10 +25,15 @@ type Column struct {
 	Source string
 	// PrimaryKey is true if the column is part of the primary key for its table.
 	PrimaryKey bool
+	// PrimaryKeyDescending is true if the primary key orders the values of the column in descending order.
+	PrimaryKeyDescending bool
 	// Comment contains the string comment for this column.
 	Comment string
 	// Extra contains any additional information to put in the `extra` column under `information_schema.columns`.
 	Extra string
+	// Invisible is true if the column is left out of SELECT * and of inserts that don't name their columns, so it
+	// can only be used by its name.
+	Invisible bool
 }
 
 // Check ensures the value is correct for this column.
@@ -44,5 +52,6 @@ func (c *Column) Equals(c2 *Column) bool {
 		c.Source == c2.Source &&
 		c.Nullable == c2.Nullable &&
 		reflect.DeepEqual(c.Default, c2.Default) &&
+		reflect.DeepEqual(c.OnUpdate, c2.OnUpdate) &&
 		reflect.DeepEqual(c.Type, c2.Type)
 }
@@ -91,7 +91,6 @@ func (e *ColumnDefaultValue) Resolved() bool {
 
 // String implements sql.Expression
 func (e *ColumnDefaultValue) String() string {
-	//TODO: currently (2+2)/2 will, when output as a string, give (2 + 2 / 2), which is clearly wrong
 	if e == nil {
 		return ""
 	}
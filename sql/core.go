@@ -177,12 +177,15 @@ type Table interface {
 
 // ForeignKeyConstraint declares a constraint between the columns of two tables.
 type ForeignKeyConstraint struct {
-	Name              string
-	Columns           []string
-	ReferencedTable   string
-	ReferencedColumns []string
-	OnUpdate          ForeignKeyReferenceOption
-	OnDelete          ForeignKeyReferenceOption
+	Name    string
+	Columns []string
+	// ReferencedDatabase is the database of the referenced table, or empty if it's the one of the table declaring the
+	// foreign key.
+	ReferencedDatabase string
+	ReferencedTable    string
+	ReferencedColumns  []string
+	OnUpdate           ForeignKeyReferenceOption
+	OnDelete           ForeignKeyReferenceOption
 }
 
 // TableWrapper is a node that wraps the real table. This is needed because
@@ -198,6 +201,42 @@ type PartitionCounter interface {
 	PartitionCount(*Context) (int64, error)
 }
 
+// TableStatistics are the estimated statistics of the data of a table.
+type TableStatistics struct {
+	// RowCount is the number of rows of the table.
+	RowCount uint64
+	// AvgRowSize is the average size of the rows of the table, in bytes.
+	AvgRowSize uint64
+	// IndexCardinality is the number of distinct values of each index of the table, by the ID of the index. The
+	// cardinality of the indexes missing is unknown.
+	IndexCardinality map[string]uint64
+}
+
+// DataLength returns the size of the data of the table, in bytes.
+func (s *TableStatistics) DataLength() uint64 {
+	return s.RowCount * s.AvgRowSize
+}
+
+// StatisticsTable is a table that can estimate the statistics of its data, which the analyzer uses to order the
+// tables of joins, and SHOW TABLE STATUS, SHOW INDEXES and the information_schema tables report.
+type StatisticsTable interface {
+	Table
+	// Statistics returns the estimated statistics of the table.
+	Statistics(*Context) (*TableStatistics, error)
+}
+
+// GetTableStatistics returns the statistics of the table given, or nil if it isn't a StatisticsTable.
+func GetTableStatistics(ctx *Context, table Table) (*TableStatistics, error) {
+	switch t := table.(type) {
+	case StatisticsTable:
+		return t.Statistics(ctx)
+	case TableWrapper:
+		return GetTableStatistics(ctx, t.Underlying())
+	default:
+		return nil, nil
+	}
+}
+
 // FilteredTable is a table that can produce a specific RowIter
 // that's more optimized given the filters.
 type FilteredTable interface {
@@ -215,6 +254,15 @@ type ProjectedTable interface {
 	Projection() []string
 }
 
+// LimitedTable is a table that can stop producing rows once it's known that no more than a number of them will be
+// used, e.g. because of a LIMIT clause. Implementations may apply the limit to each partition separately, since the
+// engine still applies the limit to the rows returned.
+type LimitedTable interface {
+	Table
+	WithLimit(limit int64) Table
+	Limit() (int64, bool)
+}
+
 // IndexUsing is the desired storage type.
 type IndexUsing byte
 
@@ -239,6 +287,11 @@ type IndexColumn struct {
 	Name string
 	// Length represents the index prefix length. If zero, then no length was specified.
 	Length int64
+	// Descending is whether the index orders the values of the column in descending order.
+	Descending bool
+	// Expression is the indexed expression of a functional key part, such as (a + b) in CREATE INDEX i ON t ((a + b)),
+	// in which case Name is empty. Its columns are resolved against the schema of the table.
+	Expression Expression
 }
 
 // IndexedTable represents a table that has one or more native indexes on its columns, and can use those indexes to
@@ -272,6 +325,15 @@ type IndexAlterableTable interface {
 	RenameIndex(ctx *Context, fromIndexName string, toIndexName string) error
 }
 
+// IndexVisibilityAlterableTable is a table whose indexes can be made invisible to the analyzer, with CREATE INDEX ...
+// INVISIBLE or ALTER TABLE ... ALTER INDEX.
+type IndexVisibilityAlterableTable interface {
+	IndexAlterableTable
+	// SetIndexVisibility makes an existing index of this table visible or invisible.
+	// Returns an error if the index does not exist.
+	SetIndexVisibility(ctx *Context, indexName string, visible bool) error
+}
+
 // ForeignKeyReferenceOption is the behavior for this foreign key with the relevant action is performed on the foreign
 // table.
 type ForeignKeyReferenceOption string
@@ -303,6 +365,49 @@ type ForeignKeyAlterableTable interface {
 	DropForeignKey(ctx *Context, fkName string) error
 }
 
+// CrossDatabaseForeignKeyTable should be implemented by tables that can declare foreign keys referencing the tables of
+// other databases. Such foreign keys can't be created in the tables that don't implement it.
+type CrossDatabaseForeignKeyTable interface {
+	ForeignKeyAlterableTable
+	// CreateCrossDatabaseForeignKey creates a foreign key referencing the table of the database given, which is not
+	// the one of this table. Returns an error if the foreign key name already exists.
+	CreateCrossDatabaseForeignKey(ctx *Context, fkName string, columns []string, referencedDatabase, referencedTable string,
+		referencedColumns []string, onUpdate, onDelete ForeignKeyReferenceOption) error
+}
+
+// CheckDefinition is a CHECK constraint as the tables that declare it store it, with the SQL text of its condition,
+// which the engine parses and resolves against the table to check its rows.
+type CheckDefinition struct {
+	Name            string
+	CheckExpression string
+	Enforced        bool
+}
+
+// CheckConstraint is a CHECK constraint with its condition resolved against the table that declares it. The rows of the
+// table can't make the condition false while the constraint is enforced.
+type CheckConstraint struct {
+	Name     string
+	Expr     Expression
+	Enforced bool
+}
+
+// CheckTable is a table that can declare its CHECK constraints.
+type CheckTable interface {
+	Table
+	// GetChecks returns the CHECK constraints on this table.
+	GetChecks(ctx *Context) ([]CheckDefinition, error)
+}
+
+// CheckAlterableTable represents a table that supports the creation and removal of CHECK constraints. The engine checks
+// the rows already in the table satisfy a constraint before it's created.
+type CheckAlterableTable interface {
+	Table
+	// CreateCheck creates a CHECK constraint for this table. Returns an error if the constraint name already exists.
+	CreateCheck(ctx *Context, check *CheckDefinition) error
+	// DropCheck removes a CHECK constraint from this table. Returns an error if the constraint doesn't exist.
+	DropCheck(ctx *Context, checkName string) error
+}
+
 // InsertableTable is a table that can process insertion of new rows.
 type InsertableTable interface {
 	Table
@@ -321,6 +426,34 @@ type RowInserter interface {
 	Closer
 }
 
+// InsertBatchSize is the number of rows given at a time to BulkRowInserter.InsertBatch.
+const InsertBatchSize = 1000
+
+// BulkRowInserter is a RowInserter that inserts rows in batches, letting the table amortize the overhead of its writes.
+// When the inserter of a table implements it, the INSERT statements without ON DUPLICATE KEY UPDATE call
+// StatementBegin before their first row, InsertBatch with their rows, InsertBatchSize at most at a time, instead of
+// Insert, and StatementComplete once they end, before Close. The other statements use it as a RowInserter.
+type BulkRowInserter interface {
+	RowInserter
+	// StatementBegin is called before the first batch of rows of a statement.
+	StatementBegin(ctx *Context)
+	// InsertBatch inserts the rows given, returning an error if it cannot insert any of them.
+	InsertBatch(ctx *Context, rows []Row) error
+	// StatementComplete is called once the statement ended, after its last batch of rows or the error that ended it.
+	StatementComplete(ctx *Context) error
+}
+
+// CopyableTable is a table that can insert the rows of another table itself, rather than having them read and inserted
+// one at a time, when both are kept by the same backend. INSERT ... SELECT statements inserting the rows of a table,
+// or of its index lookup, as they are, copy them this way.
+type CopyableTable interface {
+	InsertableTable
+	// CopyRows inserts the rows of the source table given that satisfy the filter given, which is nil to copy all of
+	// them, and returns the number of rows inserted. The source has the same column types as the table. It returns
+	// false if the table can't copy the rows of the source, which are then inserted one at a time.
+	CopyRows(ctx *Context, source Table, filter Expression) (int, bool, error)
+}
+
 // DeleteableTable is a table that can process the deletion of rows
 type DeletableTable interface {
 	Table
@@ -339,6 +472,15 @@ type RowDeleter interface {
 	Closer
 }
 
+// RangeDeletableTable is a table that can delete the rows of a range itself, rather than having them read and deleted
+// one at a time. The range is the one of the index lookup of the table, if it has one.
+type RangeDeletableTable interface {
+	DeletableTable
+	// DeleteRange deletes the rows of the table that satisfy the filter given, which is nil to delete all of them, and
+	// returns the number of rows deleted.
+	DeleteRange(ctx *Context, filter Expression) (int, error)
+}
+
 // AutoIncrementTable is a table that supports AUTO_INCREMENT.
 // Getter and Setter methods access the table's AUTO_INCREMENT
 // sequence. These methods should only be used for tables with
@@ -390,6 +532,29 @@ type ReplaceableTable interface {
 	Replacer(ctx *Context) RowReplacer
 }
 
+// UpsertableTable is a table that can replace or update the rows with the same keys as the ones inserted in a single
+// operation. REPLACE and INSERT ... ON DUPLICATE KEY UPDATE statements use its RowUpserter instead of deleting or
+// reading and updating those rows first.
+type UpsertableTable interface {
+	InsertableTable
+	// Upserter returns a RowUpserter for this table. The RowUpserter will have Replace or Upsert called once for each
+	// row, followed by a call to Close() when all rows have been processed.
+	Upserter(ctx *Context) RowUpserter
+}
+
+// RowUpserter is a cursor that can insert rows in place of the existing ones with the same primary key.
+type RowUpserter interface {
+	// Replace inserts the row given, deleting the row with the same primary key first if there's one, and returns
+	// whether it did.
+	Replace(ctx *Context, row Row) (bool, error)
+	// Upsert inserts the row given, or, if there's a row with the same primary key, updates it to the row the function
+	// given returns for it instead. It returns the row updated, or nil if the row was inserted, and the row stored. A
+	// duplicate key of a unique index other than the primary key is an ErrUniqueKeyViolation.
+	Upsert(ctx *Context, row Row, update func(Row) (Row, error)) (old Row, new Row, err error)
+	// Close finalizes the upsert operation, persisting the result.
+	Closer
+}
+
 // UpdateableTable is a table that can process updates of existing rows via update statements.
 type UpdatableTable interface {
 	Table
@@ -406,6 +571,15 @@ type RowUpdater interface {
 	Closer
 }
 
+// RangeUpdatableTable is a table that can update the rows of a range itself, rather than having them read and updated
+// one at a time. The range is the one of the index lookup of the table, if it has one.
+type RangeUpdatableTable interface {
+	UpdatableTable
+	// UpdateRange replaces the rows of the table that satisfy the filter given, which is nil to update all of them, with
+	// the rows the update function returns for them, and returns the number of rows matched and the number changed.
+	UpdateRange(ctx *Context, filter Expression, update func(Row) (Row, error)) (matched int, updated int, err error)
+}
+
 // Database represents the database.
 type Database interface {
 	Nameable
@@ -435,6 +609,59 @@ type VersionedDatabase interface {
 	GetTableNamesAsOf(ctx *Context, asOf interface{}) ([]string, error)
 }
 
+// FunctionDatabase is a Database that provides its own functions. These functions are only visible to queries run
+// while the database is the session's current database, and take precedence over functions of the same name
+// registered in the Catalog. This allows integrators to expose functions to some databases without exposing them to all.
+type FunctionDatabase interface {
+	Database
+
+	// Function returns the function with the name given, or false if the database provides no such function. Names
+	// are given in lower case.
+	Function(ctx *Context, name string) (Function, bool, error)
+}
+
+// CollatedDatabase is a Database with a default character set and collation, which the tables created in it take
+// unless they're given their own.
+type CollatedDatabase interface {
+	Database
+
+	// Collation returns the default collation of the database, which also gives its default character set.
+	Collation() Collation
+
+	// SetCollation changes the default collation of the database. The tables created before keep their own.
+	SetCollation(ctx *Context, collation Collation) error
+}
+
+// GetDatabaseCollation returns the default collation of the database given, which is the default collation of the
+// server unless it's a CollatedDatabase.
+func GetDatabaseCollation(db Database) Collation {
+	if collated, ok := db.(CollatedDatabase); ok && collated.Collation() != "" {
+		return collated.Collation()
+	}
+	return Collation_Default
+}
+
+// DatabaseCreator creates the databases of CREATE DATABASE statements. Integrators set it on the Catalog to support
+// them.
+type DatabaseCreator interface {
+	// CreateDatabase returns a new empty database with the name and default collation given, which the engine adds to
+	// the catalog.
+	CreateDatabase(ctx *Context, name string, collation Collation) (Database, error)
+}
+
+// DatabaseProvider provides the databases of the Catalog that weren't added to it, resolving them by name as queries
+// use them rather than all of them up front. Integrators with many databases set it on the Catalog so that queries
+// only load the databases they use.
+type DatabaseProvider interface {
+	// Database returns the database with the name given, case-insensitively, or ErrDatabaseNotFound if there's none.
+	Database(name string) (Database, error)
+	// DatabaseNames returns the names of all the databases, without loading them.
+	DatabaseNames() []string
+	// AllDatabases returns all the databases, for the statements that list them, such as SHOW TABLE STATUS and the
+	// queries of the information_schema database.
+	AllDatabases() []Database
+}
+
 // TriggerDefinition defines a trigger. Integrators are not expected to parse or understand the trigger definitions,
 // but must store and return them when asked.
 type TriggerDefinition struct {
@@ -540,6 +767,24 @@ type TableCreator interface {
 	CreateTable(ctx *Context, name string, schema Schema) error
 }
 
+// TableOptionsCreator should be implemented by databases that can act on the options given to CREATE TABLE, such as
+// ENGINE, ROW_FORMAT, COMMENT or options of their own. Databases that don't implement it create their tables with
+// CreateTable, and the options are dropped.
+type TableOptionsCreator interface {
+	TableCreator
+	// CreateTableWithOptions creates the table with the given name, schema and options. If a table with that name
+	// already exists, must return sql.ErrTableAlreadyExists.
+	CreateTableWithOptions(ctx *Context, name string, schema Schema, options TableOptions) error
+}
+
+// TableOptionsTable is a table that reports the options it was created with, so that they can be shown by
+// SHOW CREATE TABLE and information_schema.TABLES.
+type TableOptionsTable interface {
+	Table
+	// TableOptions returns the options the table was created with.
+	TableOptions() TableOptions
+}
+
 // ViewCreator should be implemented by databases that want to know when a view
 // has been created.
 type ViewCreator interface {
@@ -566,6 +811,17 @@ type TableRenamer interface {
 	RenameTable(ctx *Context, oldName, newName string) error
 }
 
+// MultiTableRenamer should be implemented by databases that can rename several tables in a single operation, as
+// RENAME TABLE a TO b, c TO d does. Databases that don't implement it have their tables renamed one at a time with
+// RenameTable, and the renames done are undone if one fails.
+type MultiTableRenamer interface {
+	TableRenamer
+	// RenameTables renames each table of oldNames to the name at the same position of newNames, in order, so that a
+	// table can take the name another one gave up before it. Either all the tables are renamed or none are. If a table
+	// would take the name of a table that exists at that point, must return sql.ErrTableAlreadyExists.
+	RenameTables(ctx *Context, oldNames, newNames []string) error
+}
+
 // ColumnOrder is used in ALTER TABLE statements to change the order of inserted / modified columns.
 type ColumnOrder struct {
 	First       bool   // True if this column should come first
@@ -584,6 +840,24 @@ type AlterableTable interface {
 	ModifyColumn(ctx *Context, columnName string, column *Column, order *ColumnOrder) error
 }
 
+// RewritableTable is a table whose schema can be changed by rewriting all of its rows with the new schema, which the
+// engine does to add, drop and modify the columns of the tables that don't implement AlterableTable.
+type RewritableTable interface {
+	Table
+	// Rewriter returns a TableRewriter writing the rows of the table with the new schema given, in which oldColumn was
+	// replaced with newColumn: oldColumn is nil if newColumn is added, and newColumn is nil if oldColumn is dropped.
+	Rewriter(ctx *Context, newSchema Schema, oldColumn, newColumn *Column) (TableRewriter, error)
+}
+
+// TableRewriter writes the rows of a table rewritten with a new schema. The engine inserts every row of the table,
+// converted to the new schema, and then calls Close, which replaces the schema and rows of the table with the ones
+// written atomically, or Discard if it failed, which leaves the table as it was.
+type TableRewriter interface {
+	RowInserter
+	// Discard discards the rows written.
+	Discard(ctx *Context) error
+}
+
 // Lockable should be implemented by tables that can be locked and unlocked.
 type Lockable interface {
 	Nameable
@@ -600,6 +874,20 @@ type Lockable interface {
 	Unlock(ctx *Context, id uint32) error
 }
 
+// BulkLoadTable is a table that can defer maintaining its indexes and checking
+// its constraints while many rows are loaded into it, such as by the import
+// of a dump, to do it once for all of them when the load is done.
+type BulkLoadTable interface {
+	Table
+	// StartBulkLoad starts deferring the index maintenance and the
+	// constraint checks of the rows inserted.
+	StartBulkLoad(ctx *Context) error
+	// FinishBulkLoad updates the indexes with the rows inserted since
+	// StartBulkLoad and checks their constraints, returning an error if any
+	// row violates them.
+	FinishBulkLoad(ctx *Context) error
+}
+
 // EvaluateCondition evaluates a condition, which is an expression whose value
 // will be coerced to boolean.
 func EvaluateCondition(ctx *Context, cond Expression, row Row) (bool, error) {
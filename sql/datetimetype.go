@@ -1,11 +1,15 @@
 package sql
 
 import (
+	"fmt"
 	"math"
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/dolthub/vitess/go/sqltypes"
 	"github.com/dolthub/vitess/go/vt/proto/query"
+	"github.com/spf13/cast"
 	"gopkg.in/src-d/go-errors.v1"
 )
 
@@ -17,9 +21,6 @@ const (
 	// TimestampDatetimeLayout is the formatting string with the layout of the timestamp
 	// using the format of Go "time" package.
 	TimestampDatetimeLayout = "2006-01-02 15:04:05.999999"
-
-	zeroDateStr              = "0000-00-00"
-	zeroTimestampDatetimeStr = "0000-00-00 00:00:00"
 )
 
 var (
@@ -54,8 +55,17 @@ var (
 		"20060102",
 	}
 
-	// zeroTime is 0000-01-01 00:00:00 UTC which is the closest Go can get to 0000-00-00 00:00:00
-	zeroTime = time.Unix(-62167219200, 0).UTC()
+	// zeroInDateRegex matches the strings of dates whose month or day may be zero, with or without a time.
+	zeroInDateRegex = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})(?:[ T](\d{2}):(\d{2}):(\d{2})(\.\d{0,9})?)?$`)
+
+	// zeroTime stands for the zero date 0000-00-00 00:00:00, which time.Time can't hold. The dates with a zero month or
+	// day, like 2020-00-15, which it can't hold either, are the days after it in the order of their year, month and day,
+	// with their times. They're all before the year 0 that dates can be parsed in, so that they aren't mistaken for
+	// dates like 0000-01-01, and sort before all the dates.
+	zeroTime = time.Date(-12000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// zeroInDateEnd is the time after the ones standing for the dates with a zero month or day.
+	zeroInDateEnd = zeroInDate(10000, 0, 0, 0)
 
 	// Date is a date with day, month and year.
 	Date = MustCreateDatetimeType(sqltypes.Date)
@@ -145,9 +155,15 @@ func (t datetimeType) Convert(v interface{}) (interface{}, error) {
 		return nil, err
 	}
 
-	if res.Equal(zeroTime) {
+	if IsZeroTime(res) {
 		return zeroTime, nil
 	}
+	if HasZeroInDate(res) {
+		if t.baseType == sqltypes.Timestamp {
+			return nil, ErrConvertingToTimeOutOfRange.New(FormatTime(res, TimestampDatetimeLayout), t.String())
+		}
+		return res, nil
+	}
 
 	switch t.baseType {
 	case sqltypes.Date:
@@ -172,8 +188,9 @@ func (t datetimeType) ConvertWithoutRangeCheck(v interface{}) (time.Time, error)
 
 	switch value := v.(type) {
 	case string:
-		if value == zeroDateStr || value == zeroTimestampDatetimeStr {
-			return zeroTime, nil
+		if date, ok := parseZeroInDate(value); ok {
+			res = date
+			break
 		}
 		parsed := false
 		for _, fmt := range TimestampDatetimeLayouts {
@@ -184,12 +201,18 @@ func (t datetimeType) ConvertWithoutRangeCheck(v interface{}) (time.Time, error)
 			}
 		}
 		if !parsed {
-			return zeroTime, ErrConvertingToTime.New(v)
+			return time.Time{}, ErrConvertingToTime.New(v)
 		}
 	case time.Time:
 		res = value.UTC()
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		// The number 0 is the zero date, which is the only number dates are converted from
+		if f, err := cast.ToFloat64E(value); err != nil || f != 0 {
+			return time.Time{}, ErrConvertToSQL.New(t)
+		}
+		return zeroTime, nil
 	default:
-		return zeroTime, ErrConvertToSQL.New(t)
+		return time.Time{}, ErrConvertToSQL.New(t)
 	}
 
 	if t.baseType == sqltypes.Date {
@@ -198,6 +221,72 @@ func (t datetimeType) ConvertWithoutRangeCheck(v interface{}) (time.Time, error)
 	return res, nil
 }
 
+// parseZeroInDate returns the time standing for the date with a zero month or day the string given is, with or
+// without a time, like '2020-00-15' or '0000-00-00 00:00:00.000000', and whether it's one. Only the dates written with
+// separators are, as strings of digits like '00000000' aren't dates.
+func parseZeroInDate(s string) (time.Time, bool) {
+	match := zeroInDateRegex.FindStringSubmatch(s)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	var parts [6]int
+	for i := range parts {
+		parts[i], _ = strconv.Atoi(match[i+1])
+	}
+	year, month, day, hour, minute, second := parts[0], parts[1], parts[2], parts[3], parts[4], parts[5]
+	if (month != 0 && day != 0) || month > 12 || day > 31 || hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, false
+	}
+
+	var nanos int
+	if fraction := match[7]; len(fraction) > 1 {
+		nanos, _ = strconv.Atoi((fraction[1:] + "000000000")[:9])
+	}
+	clock := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second +
+		time.Duration(nanos)
+	return zeroInDate(year, month, day, clock), true
+}
+
+// zeroInDate returns the time standing for the date with a zero month or day given, with the time of day given.
+func zeroInDate(year, month, day int, clock time.Duration) time.Time {
+	return zeroTime.AddDate(0, 0, (year*13+month)*32+day).Add(clock)
+}
+
+// IsZeroTime returns whether the time given is the zero date 0000-00-00 00:00:00, which dates can hold unless sql_mode
+// has NO_ZERO_DATE. Its year, month and day are zero, unlike the ones of the time.Time standing for it.
+func IsZeroTime(t time.Time) bool {
+	return t.Equal(zeroTime)
+}
+
+// HasZeroInDate returns whether the time given stands for the zero date, with any time, or for a date with a zero
+// month or day, like 2020-00-15, which dates can hold unless sql_mode has NO_ZERO_IN_DATE. They aren't days of the
+// calendar, so the functions of days like DAYNAME or DATE_ADD are NULL for them.
+func HasZeroInDate(t time.Time) bool {
+	return !t.Before(zeroTime) && t.Before(zeroInDateEnd)
+}
+
+// DateParts returns the year, month and day of the date the time given stands for, which are the ones of the time
+// unless it has a zero month or day.
+func DateParts(t time.Time) (year, month, day int) {
+	if !HasZeroInDate(t) {
+		return t.Year(), int(t.Month()), t.Day()
+	}
+	days := int((t.Unix() - zeroTime.Unix()) / (24 * 60 * 60))
+	return days / (13 * 32), days / 32 % 13, days % 32
+}
+
+// FormatTime returns the time given formatted with the layout given, DateLayout or one starting with it like
+// TimestampDatetimeLayout, writing the zero date and the dates with a zero month or day like MySQL does.
+func FormatTime(t time.Time, layout string) string {
+	if !HasZeroInDate(t) {
+		return t.Format(layout)
+	}
+	year, month, day := DateParts(t)
+	clock := time.Date(2000, time.January, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return fmt.Sprintf("%04d-%02d-%02d", year, month, day) + clock.Format(layout)[len(DateLayout):]
+}
+
 func (t datetimeType) MustConvert(v interface{}) interface{} {
 	value, err := t.Convert(v)
 	if err != nil {
@@ -228,37 +317,19 @@ func (t datetimeType) SQL(v interface{}) (sqltypes.Value, error) {
 
 	switch t.baseType {
 	case sqltypes.Date:
-		if vt.Equal(zeroTime) {
-			return sqltypes.MakeTrusted(
-				sqltypes.Date,
-				[]byte(vt.Format(zeroDateStr)),
-			), nil
-		}
 		return sqltypes.MakeTrusted(
 			sqltypes.Date,
-			[]byte(vt.Format(DateLayout)),
+			[]byte(FormatTime(vt, DateLayout)),
 		), nil
 	case sqltypes.Datetime:
-		if vt.Equal(zeroTime) {
-			return sqltypes.MakeTrusted(
-				sqltypes.Datetime,
-				[]byte(vt.Format(zeroTimestampDatetimeStr)),
-			), nil
-		}
 		return sqltypes.MakeTrusted(
 			sqltypes.Datetime,
-			[]byte(vt.Format(TimestampDatetimeLayout)),
+			[]byte(FormatTime(vt, TimestampDatetimeLayout)),
 		), nil
 	case sqltypes.Timestamp:
-		if vt.Equal(zeroTime) {
-			return sqltypes.MakeTrusted(
-				sqltypes.Timestamp,
-				[]byte(vt.Format(zeroTimestampDatetimeStr)),
-			), nil
-		}
 		return sqltypes.MakeTrusted(
 			sqltypes.Timestamp,
-			[]byte(vt.Format(TimestampDatetimeLayout)),
+			[]byte(FormatTime(vt, TimestampDatetimeLayout)),
 		), nil
 	default:
 		panic(ErrInvalidBaseType.New(t.baseType.String(), "datetime"))
@@ -304,9 +375,10 @@ func (t datetimeType) MinimumTime() time.Time {
 }
 
 // ValidateTime receives a time and returns either that time or nil if it's
-// not a valid time.
+// not a valid time, outside of the years 0 to 9999.
 func ValidateTime(t time.Time) interface{} {
-	if t.After(time.Date(9999, time.December, 31, 23, 59, 59, 999999999, time.UTC)) {
+	if t.Before(time.Date(0, time.January, 1, 0, 0, 0, 0, time.UTC)) ||
+		t.After(time.Date(9999, time.December, 31, 23, 59, 59, 999999999, time.UTC)) {
 		return nil
 	}
 	return t
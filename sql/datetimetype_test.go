@@ -170,50 +170,66 @@ func TestDatetimeConvert(t *testing.T) {
 		{Date, "", nil, true},
 		{Date, "500-01-01", nil, true},
 		{Date, "10000-01-01", nil, true},
-		{Date, int(0), nil, true},
-		{Date, int8(0), nil, true},
-		{Date, int16(0), nil, true},
-		{Date, int32(0), nil, true},
-		{Date, int64(0), nil, true},
-		{Date, uint(0), nil, true},
-		{Date, uint8(0), nil, true},
-		{Date, uint16(0), nil, true},
-		{Date, uint32(0), nil, true},
-		{Date, uint64(0), nil, true},
-		{Date, float32(0), nil, true},
-		{Date, float64(0), nil, true},
+		{Date, int(0), zeroTime, false},
+		{Date, int8(0), zeroTime, false},
+		{Date, int16(0), zeroTime, false},
+		{Date, int32(0), zeroTime, false},
+		{Date, int64(0), zeroTime, false},
+		{Date, uint(0), zeroTime, false},
+		{Date, uint8(0), zeroTime, false},
+		{Date, uint16(0), zeroTime, false},
+		{Date, uint32(0), zeroTime, false},
+		{Date, uint64(0), zeroTime, false},
+		{Date, float32(0), zeroTime, false},
+		{Date, float64(0), zeroTime, false},
+		{Date, int(1), nil, true},
 		{Date, []byte{0}, nil, true},
+		{Date, "0000-00-00", zeroTime, false},
+		{Date, "00000000", nil, true},
+		{Date, "0000-01-01", nil, true},
+		{Date, time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), nil, true},
 
 		{Datetime, time.Date(500, 1, 1, 1, 1, 1, 1, time.UTC), nil, true},
 		{Datetime, time.Date(10000, 1, 1, 1, 1, 1, 1, time.UTC), nil, true},
-		{Datetime, int(0), nil, true},
-		{Datetime, int8(0), nil, true},
-		{Datetime, int16(0), nil, true},
-		{Datetime, int32(0), nil, true},
-		{Datetime, int64(0), nil, true},
-		{Datetime, uint(0), nil, true},
-		{Datetime, uint8(0), nil, true},
-		{Datetime, uint16(0), nil, true},
-		{Datetime, uint32(0), nil, true},
-		{Datetime, uint64(0), nil, true},
-		{Datetime, float32(0), nil, true},
-		{Datetime, float64(0), nil, true},
+		{Datetime, int(0), zeroTime, false},
+		{Datetime, int8(0), zeroTime, false},
+		{Datetime, int16(0), zeroTime, false},
+		{Datetime, int32(0), zeroTime, false},
+		{Datetime, int64(0), zeroTime, false},
+		{Datetime, uint(0), zeroTime, false},
+		{Datetime, uint8(0), zeroTime, false},
+		{Datetime, uint16(0), zeroTime, false},
+		{Datetime, uint32(0), zeroTime, false},
+		{Datetime, uint64(0), zeroTime, false},
+		{Datetime, float32(0), zeroTime, false},
+		{Datetime, float64(0), zeroTime, false},
+		{Datetime, int(1), nil, true},
 		{Datetime, []byte{0}, nil, true},
+		{Datetime, "0000-00-00 00:00:00", zeroTime, false},
+		{Datetime, "0000-00-00T00:00:00.000000", zeroTime, false},
+		{Datetime, "0000-00-00 00:00:01", zeroInDate(0, 0, 0, time.Second), false},
+		{Datetime, "2020-00-15", zeroInDate(2020, 0, 15, 0), false},
+		{Datetime, "2020-02-00 10:11:12.5", zeroInDate(2020, 2, 0, 10*time.Hour+11*time.Minute+12500*time.Millisecond), false},
+		{Datetime, "2020-13-00", nil, true},
+		{Datetime, "2020-00-32", nil, true},
+		{Date, "2020-00-00", zeroInDate(2020, 0, 0, 0), false},
+		{Timestamp, "2020-00-15", nil, true},
 
 		{Timestamp, time.Date(1960, 1, 1, 1, 1, 1, 1, time.UTC), nil, true},
 		{Timestamp, time.Date(2040, 1, 1, 1, 1, 1, 1, time.UTC), nil, true},
-		{Timestamp, int(0), nil, true},
-		{Timestamp, int8(0), nil, true},
-		{Timestamp, int16(0), nil, true},
-		{Timestamp, int32(0), nil, true},
-		{Timestamp, int64(0), nil, true},
-		{Timestamp, uint(0), nil, true},
-		{Timestamp, uint8(0), nil, true},
-		{Timestamp, uint16(0), nil, true},
-		{Timestamp, uint32(0), nil, true},
-		{Timestamp, uint64(0), nil, true},
-		{Timestamp, float32(0), nil, true},
-		{Timestamp, float64(0), nil, true},
+		{Timestamp, int(0), zeroTime, false},
+		{Timestamp, int8(0), zeroTime, false},
+		{Timestamp, int16(0), zeroTime, false},
+		{Timestamp, int32(0), zeroTime, false},
+		{Timestamp, int64(0), zeroTime, false},
+		{Timestamp, uint(0), zeroTime, false},
+		{Timestamp, uint8(0), zeroTime, false},
+		{Timestamp, uint16(0), zeroTime, false},
+		{Timestamp, uint32(0), zeroTime, false},
+		{Timestamp, uint64(0), zeroTime, false},
+		{Timestamp, float32(0), zeroTime, false},
+		{Timestamp, float64(0), zeroTime, false},
+		{Timestamp, int(1), nil, true},
 		{Timestamp, []byte{0}, nil, true},
 	}
 
@@ -247,3 +263,29 @@ func TestDatetimeString(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatTime(t *testing.T) {
+	tests := []struct {
+		val         time.Time
+		layout      string
+		expectedStr string
+	}{
+		{time.Date(2020, 1, 15, 10, 11, 12, 0, time.UTC), DateLayout, "2020-01-15"},
+		{time.Date(2020, 1, 15, 10, 11, 12, 0, time.UTC), TimestampDatetimeLayout, "2020-01-15 10:11:12"},
+		{zeroTime, DateLayout, "0000-00-00"},
+		{zeroTime, TimestampDatetimeLayout, "0000-00-00 00:00:00"},
+		{zeroInDate(2020, 0, 15, 0), DateLayout, "2020-00-15"},
+		{zeroInDate(9999, 12, 0, 10*time.Hour+500*time.Millisecond), TimestampDatetimeLayout, "9999-12-00 10:00:00.5"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expectedStr, func(t *testing.T) {
+			assert.Equal(t, test.expectedStr, FormatTime(test.val, test.layout))
+		})
+	}
+
+	year, month, day := DateParts(zeroInDate(2020, 2, 0, time.Hour))
+	assert.Equal(t, []int{2020, 2, 0}, []int{year, month, day})
+	assert.True(t, HasZeroInDate(zeroTime))
+	assert.False(t, HasZeroInDate(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
@@ -222,7 +222,7 @@ func (t enumType) SQL(v interface{}) (sqltypes.Value, error) {
 
 // String implements Type interface.
 func (t enumType) String() string {
-	s := fmt.Sprintf("ENUM('%v')", strings.Join(t.indexToVal, `','`))
+	s := fmt.Sprintf("ENUM('%v')", strings.Join(quoteValues(t.indexToVal), `','`))
 	if t.CharacterSet() != Collation_Default.CharacterSet() {
 		s += " CHARACTER SET " + t.CharacterSet().String()
 	}
@@ -232,6 +232,15 @@ func (t enumType) String() string {
 	return s
 }
 
+// quoteValues returns the values of an ENUM or SET type given with their single quotes doubled, to be put in quotes.
+func quoteValues(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strings.ReplaceAll(v, "'", "''")
+	}
+	return quoted
+}
+
 // Type implements Type interface.
 func (t enumType) Type() query.Type {
 	return sqltypes.Enum
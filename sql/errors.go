@@ -82,6 +82,19 @@ var (
 	// ErrColumnDefaultSubquery is returned when a default value contains a subquery.
 	ErrColumnDefaultSubquery = errors.NewKind("default value on column `%s` may not contain subqueries")
 
+	// ErrColumnDefaultAutoIncrement is returned when a default value references an auto_increment column.
+	ErrColumnDefaultAutoIncrement = errors.NewKind("default value on column `%s` may not refer to auto_increment column `%s`")
+
+	// ErrColumnDefaultVariable is returned when a default value references a user or system variable.
+	ErrColumnDefaultVariable = errors.NewKind("default value on column `%s` may not refer to variables")
+
+	// ErrInvalidOnUpdate is returned when a column declares an ON UPDATE value other than the current time, or isn't a
+	// datetime or timestamp column.
+	ErrInvalidOnUpdate = errors.NewKind("invalid ON UPDATE clause for `%s` column")
+
+	// ErrTableMustHaveVisibleColumn is returned when all the columns of a table would be invisible.
+	ErrTableMustHaveVisibleColumn = errors.NewKind("a table must have at least one visible column")
+
 	// ErrInvalidDefaultValueOrder is returned when a default value references a column that comes after it and contains a default expression.
 	ErrInvalidDefaultValueOrder = errors.NewKind(`default value of column "%s" cannot refer to a column defined after it if those columns have an expression default value`)
 
@@ -106,9 +119,53 @@ var (
 	// ErrTriggerCannotBeDropped is returned when dropping a trigger would cause another trigger to reference a non-existent trigger.
 	ErrTriggerCannotBeDropped = errors.NewKind(`trigger "%s" cannot be dropped as it is referenced by trigger "%s"`)
 
+	// ErrForeignKeyParentViolation is returned when a row is deleted or updated while rows of another table reference it
+	// through a foreign key that restricts it
+	ErrForeignKeyParentViolation = errors.NewKind("cannot delete or update a parent row: a foreign key constraint fails (`%s`, CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s` (%s))")
+
+	// ErrForeignKeyChildViolation is returned when a row is inserted or updated with values of the columns of a foreign
+	// key that no row of the table it references has
+	ErrForeignKeyChildViolation = errors.NewKind("cannot add or update a child row: a foreign key constraint fails (`%s`, CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s` (%s))")
+
+	// ErrCheckConstraintViolated is returned when a row makes the condition of an enforced CHECK constraint false
+	ErrCheckConstraintViolated = errors.NewKind("Check constraint '%s' is violated.")
+
+	// ErrCheckConstraintDuplicateName is returned when a CHECK constraint is created with the name of another one
+	ErrCheckConstraintDuplicateName = errors.NewKind("Duplicate check constraint name '%s'.")
+
+	// ErrCheckConstraintNotFound is returned when a CHECK constraint that a table doesn't have is dropped
+	ErrCheckConstraintNotFound = errors.NewKind("Check constraint '%s' is not found in the table.")
+
+	// ErrConstraintNotFound is returned when a constraint that a table doesn't have is dropped
+	ErrConstraintNotFound = errors.NewKind("Constraint '%s' does not exist.")
+
+	// ErrForeignKeyDepthLimit is returned when the referential actions of foreign keys cascade through too many tables
+	ErrForeignKeyDepthLimit = errors.NewKind("Foreign key cascade delete/update exceeds max depth of %d.")
+
 	// ErrUnknownSystemVariable is returned when a query references a system variable that doesn't exist
 	ErrUnknownSystemVariable = errors.NewKind(`Unknown system variable '%s'`)
 
+	// ErrSystemVariableReadOnly is returned when a query sets a system variable that can't be set
+	ErrSystemVariableReadOnly = errors.NewKind(`Variable '%s' is a read only variable`)
+
+	// ErrSystemVariableSessionOnly is returned when a query sets or reads the global value of a system variable that only
+	// has a value in each session
+	ErrSystemVariableSessionOnly = errors.NewKind(`Variable '%s' is a SESSION variable and can't be used with SET GLOBAL`)
+
+	// ErrSystemVariableGlobalOnly is returned when a query sets or reads the session value of a system variable that
+	// only has a global value
+	ErrSystemVariableGlobalOnly = errors.NewKind(`Variable '%s' is a GLOBAL variable and should be set with SET GLOBAL`)
+
+	// ErrInvalidSystemVariableValue is returned when a query sets a system variable to a value it can't have
+	ErrInvalidSystemVariableValue = errors.NewKind(`Variable '%s' can't be set to the value of '%v'`)
+
+	// ErrPersistNotSupported is returned when a query persists system variables and the engine has no
+	// VariablePersister to store them with
+	ErrPersistNotSupported = errors.NewKind(`persisting system variables is not supported`)
+
+	// ErrVariableNotPersisted is returned by RESET PERSIST when the variable to remove isn't persisted
+	ErrVariableNotPersisted = errors.NewKind(`Variable %s does not exist in persisted config file`)
+
 	// ErrInvalidUseOfOldNew is returned when a trigger attempts to make use of OLD or NEW references when they don't exist
 	ErrInvalidUseOfOldNew = errors.NewKind("There is no %s row in on %s trigger")
 
@@ -118,6 +175,37 @@ var (
 	// ErrInvalidUpdateInAfterTrigger is returned when a trigger attempts to assign to a new row in an AFTER trigger
 	ErrInvalidUpdateInAfterTrigger = errors.NewKind("Updating of new row is not allowed in after trigger")
 
+	// ErrUnknownPreparedStatement is returned when a query executes or deallocates a statement that wasn't prepared
+	ErrUnknownPreparedStatement = errors.NewKind(`Unknown prepared statement handler (%s) given to %s`)
+
+	// ErrPreparedStatementsNotSupported is returned when a query prepares a statement in a session that can't hold
+	// prepared statements
+	ErrPreparedStatementsNotSupported = errors.NewKind(`prepared statements are not supported by the session`)
+
+	// ErrWrongArgumentsToExecute is returned when a query executes a prepared statement with a number of variables
+	// other than the number of its parameters
+	ErrWrongArgumentsToExecute = errors.NewKind(`Incorrect arguments to EXECUTE`)
+
+	// ErrWrongArgumentsToLimit is returned when the value bound to the LIMIT or OFFSET of a prepared statement isn't a
+	// non-negative integer
+	ErrWrongArgumentsToLimit = errors.NewKind(`Incorrect arguments to %s`)
+
+	// ErrQueryTimeout is returned when a query runs for longer than its maximum execution time
+	ErrQueryTimeout = errors.NewKind(`Query execution was interrupted, maximum statement execution time exceeded`)
+
 	// ErrUnboundPreparedStatementVariable is returned when a query is executed without a binding for one its variables.
 	ErrUnboundPreparedStatementVariable = errors.NewKind(`unbound variable "%s" in query`)
+
+	// ErrDivisionByZero is returned when INSERT or UPDATE divides by zero in strict mode with ERROR_FOR_DIVISION_BY_ZERO
+	ErrDivisionByZero = errors.NewKind(`Division by 0`)
+
+	// ErrInvalidZeroDate is returned when INSERT or UPDATE stores a zero date in strict mode with NO_ZERO_DATE, or a
+	// date with a zero month or day with NO_ZERO_IN_DATE
+	ErrInvalidZeroDate = errors.NewKind(`Incorrect date value: '%v' for column '%s'`)
+
+	// ErrInvalidConditionNumber is returned when GET DIAGNOSTICS gets information about a condition that doesn't exist
+	ErrInvalidConditionNumber = errors.NewKind(`Invalid condition number`)
+
+	// ErrStackedDiagnosticsWithoutHandler is returned when GET STACKED DIAGNOSTICS is run outside of a condition handler
+	ErrStackedDiagnosticsWithoutHandler = errors.NewKind(`GET STACKED DIAGNOSTICS when handler not active`)
 )
@@ -92,7 +92,16 @@ func NewMod(left, right sql.Expression) *Arithmetic {
 }
 
 func (a *Arithmetic) String() string {
-	return fmt.Sprintf("%s %s %s", a.Left, a.Op, a.Right)
+	return fmt.Sprintf("%s %s %s", arithmeticOperand(a.Left), a.Op, arithmeticOperand(a.Right))
+}
+
+// arithmeticOperand returns the string of an operand of an arithmetic expression, parenthesized if it's another
+// arithmetic expression so that the string keeps the order of evaluation.
+func arithmeticOperand(e sql.Expression) string {
+	if _, ok := e.(*Arithmetic); ok {
+		return fmt.Sprintf("(%s)", e)
+	}
+	return e.String()
 }
 
 func (a *Arithmetic) DebugString() string {
@@ -171,6 +180,13 @@ func (a *Arithmetic) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		return nil, err
 	}
 
+	switch strings.ToLower(a.Op) {
+	case sqlparser.DivStr, sqlparser.IntDivStr, sqlparser.ModStr:
+		if isZero(rval) {
+			sql.WarnDivisionByZero(ctx)
+		}
+	}
+
 	switch strings.ToLower(a.Op) {
 	case sqlparser.PlusStr:
 		return plus(lval, rval)
@@ -486,12 +502,18 @@ func mod(lval, rval interface{}) (interface{}, error) {
 	case uint64:
 		switch r := rval.(type) {
 		case uint64:
+			if r == 0 {
+				return sql.Null, nil
+			}
 			return l % r, nil
 		}
 
 	case int64:
 		switch r := rval.(type) {
 		case int64:
+			if r == 0 {
+				return sql.Null, nil
+			}
 			return l % r, nil
 		}
 	}
@@ -499,6 +521,20 @@ func mod(lval, rval interface{}) (interface{}, error) {
 	return nil, errUnableToCast.New(lval, rval)
 }
 
+// isZero returns whether the value given, converted for an arithmetic operation, is zero.
+func isZero(v interface{}) bool {
+	switch v := v.(type) {
+	case uint64:
+		return v == 0
+	case int64:
+		return v == 0
+	case float64:
+		return v == 0
+	default:
+		return false
+	}
+}
+
 // UnaryMinus is an unary minus operator.
 type UnaryMinus struct {
 	UnaryExpression
@@ -22,7 +22,11 @@ type AutoIncrement struct {
 	autoIncVal *Literal
 	autoTbl    sql.AutoIncrementTable
 	autoCol    *sql.Column
+	// increment and offset are the values of auto_increment_increment and auto_increment_offset.
+	increment, offset int64
 	sync.Once
+	// first is the first value generated, or nil if none was.
+	first interface{}
 }
 
 // NewAutoIncrement creates a new AutoIncrement expression.
@@ -48,12 +52,16 @@ func NewAutoIncrement(ctx *sql.Context, table sql.Table, given sql.Expression) (
 		return nil, ErrNoAutoIncrementCols.New(table.Name())
 	}
 
+	increment, offset := sql.AutoIncrementSequence(ctx)
 	return &AutoIncrement{
 		UnaryExpression{Child: given},
 		&Literal{last, autoCol.Type},
 		autoTbl,
 		autoCol,
+		increment,
+		offset,
 		sync.Once{},
+		nil,
 	}, nil
 }
 
@@ -82,32 +90,63 @@ func (i *AutoIncrement) Eval(ctx *sql.Context, row sql.Row) (interface{}, error)
 	}
 
 	if given != nil && cmp != 0 {
-		// check if the given value is greater than autoIncVal
+		// explicit values are used as given, and generated values follow the greatest of them
 		cmp, err := i.Type().Compare(given, i.autoIncVal.value)
 		if err != nil {
 			return nil, err
 		}
-		if cmp <= 0 {
-			// if it's less, return it and don't increment
-			return given, nil
+		if cmp >= 0 {
+			nextVal, err := NewIncrement(NewLiteral(given, i.Type())).Eval(ctx, row)
+			if err != nil {
+				return nil, err
+			}
+			i.autoIncVal = NewLiteral(nextVal, i.Type())
 		}
-		i.autoIncVal = NewLiteral(given, i.Type())
+		return given, nil
 	}
 
-	val, err := i.autoIncVal.Eval(ctx, row)
+	val, err := i.nextValue()
 	if err != nil {
 		return nil, err
 	}
 
-	nextVal, err := NewIncrement(i.autoIncVal).Eval(ctx, row)
+	nextVal, err := NewIncrement(NewLiteral(val, i.Type())).Eval(ctx, row)
 	if err != nil {
 		return nil, err
 	}
 	i.autoIncVal = NewLiteral(nextVal, i.Type())
+	i.Do(func() { i.first = val })
 
 	return val, nil
 }
 
+// FirstGenerated returns the first value generated for the rows inserted, which is the one LAST_INSERT_ID() returns
+// after the statement, or nil if all of them had a value given.
+func (i *AutoIncrement) FirstGenerated() interface{} {
+	return i.first
+}
+
+// nextValue returns the smallest value of the sequence defined by auto_increment_increment and auto_increment_offset
+// that isn't less than the AUTO_INCREMENT value of the table.
+func (i *AutoIncrement) nextValue() (interface{}, error) {
+	if i.increment <= 1 && i.offset <= 1 {
+		return i.autoIncVal.value, nil
+	}
+
+	v, err := sql.Int64.Convert(i.autoIncVal.value)
+	if err != nil {
+		return nil, err
+	}
+
+	n := v.(int64)
+	if n <= i.offset {
+		n = i.offset
+	} else {
+		n = i.offset + (n-i.offset+i.increment-1)/i.increment*i.increment
+	}
+	return i.Type().Convert(n)
+}
+
 func (i *AutoIncrement) String() string {
 	return fmt.Sprintf("AutoIncrement(%s)", i.Child.String())
 }
@@ -122,7 +161,10 @@ func (i *AutoIncrement) WithChildren(children ...sql.Expression) (sql.Expression
 		i.autoIncVal,
 		i.autoTbl,
 		i.autoCol,
+		i.increment,
+		i.offset,
 		sync.Once{},
+		nil,
 	}, nil
 }
 
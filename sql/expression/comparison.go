@@ -3,7 +3,10 @@ package expression
 import (
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/shopspring/decimal"
 	errors "gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/go-mysql-server/internal/regex"
@@ -77,6 +80,15 @@ func (c *comparison) castLeftAndRight(left, right interface{}) (interface{}, int
 	if sql.IsTuple(leftType) && sql.IsTuple(rightType) {
 		return left, right, c.Left().Type(), nil
 	}
+	// Dates are compared with numbers as numbers, like 20200102 for the date 2020-01-02 and 0 for the zero date
+	if (sql.IsTime(leftType) && sql.IsNumber(rightType)) || (sql.IsNumber(leftType) && sql.IsTime(rightType)) {
+		l, r, err := convertLeftAndRight(dateNumber(left, leftType), dateNumber(right, rightType), ConvertToDecimal)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		return l, r, sql.MustCreateDecimalType(sql.DecimalTypeMaxPrecision, 10), nil
+	}
 	if sql.IsNumber(leftType) || sql.IsNumber(rightType) {
 		if sql.IsDecimal(leftType) || sql.IsDecimal(rightType) {
 			//TODO: We need to set to the actual DECIMAL type
@@ -118,14 +130,57 @@ func (c *comparison) castLeftAndRight(left, right interface{}) (interface{}, int
 		return l, r, sql.Uint64, nil
 	}
 
+	// Dates are compared with strings as dates, which strings like '2020-01-02' and '0000-00-00' are written as
+	if sql.IsTime(leftType) || sql.IsTime(rightType) {
+		l, r, err := convertLeftAndRight(left, right, ConvertToDatetime)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if l != nil && r != nil {
+			return l, r, sql.Datetime, nil
+		}
+	}
+
 	left, right, err := convertLeftAndRight(left, right, ConvertToChar)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
+	if collation := comparisonCollation(leftType, rightType); collation != sql.Collation_Default {
+		return left, right, sql.CreateLongText(collation), nil
+	}
 	return left, right, sql.LongText, nil
 }
 
+// comparisonCollation returns the collation strings of the types given are compared with, which is the one of the
+// first string type whose collation isn't the default one.
+func comparisonCollation(types ...sql.Type) sql.Collation {
+	for _, t := range types {
+		if st, ok := t.(sql.StringType); ok && st.Collation() != sql.Collation_Default {
+			return st.Collation()
+		}
+	}
+	return sql.Collation_Default
+}
+
+// dateNumber returns the date of the type given as the number MySQL compares it with numbers as, YYYYMMDD for dates and
+// YYYYMMDDhhmmss.ffffff for datetimes and timestamps, or the value given if it isn't a date.
+func dateNumber(v interface{}, typ sql.Type) interface{} {
+	t, ok := v.(time.Time)
+	if !ok {
+		return v
+	}
+
+	year, month, day := sql.DateParts(t)
+	date := decimal.New(int64(year*10000+month*100+day), 0)
+	if typ.Type() == sqltypes.Date {
+		return date
+	}
+	clock := decimal.New(int64(t.Hour()*10000+t.Minute()*100+t.Second()), 0).
+		Add(decimal.New(int64(t.Nanosecond()/1000), -6))
+	return date.Mul(decimal.New(1, 6)).Add(clock)
+}
+
 func convertLeftAndRight(left, right interface{}, convertTo string) (interface{}, interface{}, error) {
 	l, err := convertValue(left, convertTo)
 	if err != nil {
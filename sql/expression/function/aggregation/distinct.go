@@ -0,0 +1,123 @@
+package aggregation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/hashstructure"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Distinct wraps an aggregation so that only rows with distinct argument values are fed to it, as in
+// SUM(DISTINCT x). It works with any aggregation, built-in or user-defined.
+type Distinct struct {
+	agg sql.Aggregation
+}
+
+var _ sql.FunctionExpression = (*Distinct)(nil)
+var _ sql.Aggregation = (*Distinct)(nil)
+
+// NewDistinct returns a new Distinct aggregation wrapping the one given.
+func NewDistinct(agg sql.Aggregation) *Distinct {
+	return &Distinct{agg}
+}
+
+// FunctionName implements sql.FunctionExpression
+func (d *Distinct) FunctionName() string {
+	if fn, ok := d.agg.(sql.FunctionExpression); ok {
+		return fn.FunctionName()
+	}
+	return ""
+}
+
+// Resolved implements the Expression interface.
+func (d *Distinct) Resolved() bool {
+	return d.agg.Resolved()
+}
+
+// Type implements the Expression interface.
+func (d *Distinct) Type() sql.Type {
+	return d.agg.Type()
+}
+
+// IsNullable implements the Expression interface.
+func (d *Distinct) IsNullable() bool {
+	return d.agg.IsNullable()
+}
+
+// Children implements the Expression interface.
+func (d *Distinct) Children() []sql.Expression {
+	return d.agg.Children()
+}
+
+func (d *Distinct) String() string {
+	var args = make([]string, len(d.agg.Children()))
+	for i, arg := range d.agg.Children() {
+		args[i] = arg.String()
+	}
+	return fmt.Sprintf("%s(DISTINCT %s)", strings.ToUpper(d.FunctionName()), strings.Join(args, ", "))
+}
+
+// WithChildren implements the Expression interface.
+func (d *Distinct) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	agg, err := d.agg.WithChildren(children...)
+	if err != nil {
+		return nil, err
+	}
+	return NewDistinct(agg.(sql.Aggregation)), nil
+}
+
+// NewBuffer implements the Aggregation interface. The buffer holds the rows seen so far, keyed by the hash of their
+// argument values, and the buffer of the wrapped aggregation.
+func (d *Distinct) NewBuffer() sql.Row {
+	return sql.NewRow(make(map[uint64]sql.Row), d.agg.NewBuffer())
+}
+
+// Update implements the Aggregation interface.
+func (d *Distinct) Update(ctx *sql.Context, buffer, row sql.Row) error {
+	seen := buffer[0].(map[uint64]sql.Row)
+
+	var values = make([]interface{}, len(d.agg.Children()))
+	for i, arg := range d.agg.Children() {
+		v, err := arg.Eval(ctx, row)
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+
+	hash, err := hashstructure.Hash(values, nil)
+	if err != nil {
+		return fmt.Errorf("distinct aggregation unable to hash value: %s", err)
+	}
+
+	if _, ok := seen[hash]; ok {
+		return nil
+	}
+
+	seen[hash] = row
+	return d.agg.Update(ctx, buffer[1].(sql.Row), row)
+}
+
+// Merge implements the Aggregation interface. Rows of the partial buffer not seen in this one are replayed against
+// the wrapped aggregation, since partial results can't be merged without counting duplicates twice.
+func (d *Distinct) Merge(ctx *sql.Context, buffer, partial sql.Row) error {
+	seen := buffer[0].(map[uint64]sql.Row)
+	for hash, row := range partial[0].(map[uint64]sql.Row) {
+		if _, ok := seen[hash]; ok {
+			continue
+		}
+
+		seen[hash] = row
+		if err := d.agg.Update(ctx, buffer[1].(sql.Row), row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Eval implements the Aggregation interface.
+func (d *Distinct) Eval(ctx *sql.Context, buffer sql.Row) (interface{}, error) {
+	return d.agg.Eval(ctx, buffer[1].(sql.Row))
+}
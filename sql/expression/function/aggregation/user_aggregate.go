@@ -0,0 +1,149 @@
+package aggregation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Aggregator holds the state of a user-defined aggregate function for a single group of rows. A new Aggregator is
+// created for every group, updated with the argument values of each row in the group, and finalized once all of them
+// have been seen.
+type Aggregator interface {
+	// Update adds the values of the function arguments for one row to the state of the aggregation.
+	Update(ctx *sql.Context, args []interface{}) error
+	// Merge merges the state of another Aggregator of the same function into this one. It's used to combine partial
+	// aggregations computed in parallel.
+	Merge(ctx *sql.Context, other Aggregator) error
+	// Finalize returns the result of the aggregation.
+	Finalize(ctx *sql.Context) (interface{}, error)
+}
+
+// AggregateFunction defines an aggregate function implemented in Go. Its Function method returns a sql.Function that
+// can be registered in the catalog, a database or a session like any other function, and used anywhere the built-in
+// aggregations can be used, including with DISTINCT.
+type AggregateFunction struct {
+	// Name of the function.
+	Name string
+	// ReturnType is the type of the result of the function.
+	ReturnType sql.Type
+	// NumArgs is the number of arguments the function takes. If negative, any number of arguments is accepted.
+	NumArgs int
+	// NewAggregator returns a new Aggregator in its initial state.
+	NewAggregator func() Aggregator
+}
+
+// Function returns the sql.Function used to register this aggregate function.
+func (f AggregateFunction) Function() sql.Function {
+	// Expressions share a pointer to the definition, since its func field would otherwise make them compare unequal
+	// when the analyzer checks whether a node changed.
+	fn := &f
+	return sql.FunctionN{
+		Name: f.Name,
+		Fn: func(args ...sql.Expression) (sql.Expression, error) {
+			if f.NumArgs >= 0 && len(args) != f.NumArgs {
+				return nil, sql.ErrInvalidArgumentNumber.New(f.Name, f.NumArgs, len(args))
+			}
+			return NewUserAggregate(fn, args...), nil
+		},
+	}
+}
+
+// UserAggregate is the expression for a call to an AggregateFunction.
+type UserAggregate struct {
+	fn   *AggregateFunction
+	args []sql.Expression
+}
+
+var _ sql.FunctionExpression = (*UserAggregate)(nil)
+var _ sql.Aggregation = (*UserAggregate)(nil)
+
+// NewUserAggregate returns a new UserAggregate calling the function given with the arguments given.
+func NewUserAggregate(fn *AggregateFunction, args ...sql.Expression) *UserAggregate {
+	return &UserAggregate{fn, args}
+}
+
+// FunctionName implements sql.FunctionExpression
+func (u *UserAggregate) FunctionName() string {
+	return u.fn.Name
+}
+
+// Resolved implements the Expression interface.
+func (u *UserAggregate) Resolved() bool {
+	for _, arg := range u.args {
+		if !arg.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// Type implements the Expression interface.
+func (u *UserAggregate) Type() sql.Type {
+	return u.fn.ReturnType
+}
+
+// IsNullable implements the Expression interface.
+func (u *UserAggregate) IsNullable() bool {
+	return true
+}
+
+// Children implements the Expression interface.
+func (u *UserAggregate) Children() []sql.Expression {
+	return u.args
+}
+
+func (u *UserAggregate) String() string {
+	var args = make([]string, len(u.args))
+	for i, arg := range u.args {
+		args[i] = arg.String()
+	}
+	return fmt.Sprintf("%s(%s)", strings.ToUpper(u.fn.Name), strings.Join(args, ", "))
+}
+
+// WithChildren implements the Expression interface.
+func (u *UserAggregate) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) != len(u.args) {
+		return nil, sql.ErrInvalidChildrenNumber.New(u, len(children), len(u.args))
+	}
+	return NewUserAggregate(u.fn, children...), nil
+}
+
+// NewBuffer implements the Aggregation interface.
+func (u *UserAggregate) NewBuffer() sql.Row {
+	return sql.NewRow(u.fn.NewAggregator())
+}
+
+// Update implements the Aggregation interface.
+func (u *UserAggregate) Update(ctx *sql.Context, buffer, row sql.Row) error {
+	var args = make([]interface{}, len(u.args))
+	for i, arg := range u.args {
+		v, err := arg.Eval(ctx, row)
+		if err != nil {
+			return err
+		}
+		args[i] = v
+	}
+
+	return buffer[0].(Aggregator).Update(ctx, args)
+}
+
+// Merge implements the Aggregation interface.
+func (u *UserAggregate) Merge(ctx *sql.Context, buffer, partial sql.Row) error {
+	return buffer[0].(Aggregator).Merge(ctx, partial[0].(Aggregator))
+}
+
+// Eval implements the Aggregation interface.
+func (u *UserAggregate) Eval(ctx *sql.Context, buffer sql.Row) (interface{}, error) {
+	v, err := buffer[0].(Aggregator).Finalize(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if v == nil {
+		return nil, nil
+	}
+
+	return u.fn.ReturnType.Convert(v)
+}
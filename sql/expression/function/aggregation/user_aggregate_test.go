@@ -0,0 +1,109 @@
+package aggregation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+type productAggregator struct {
+	product int64
+	seen    bool
+}
+
+func (p *productAggregator) Update(ctx *sql.Context, args []interface{}) error {
+	if args[0] == nil {
+		return nil
+	}
+
+	v, err := sql.Int64.Convert(args[0])
+	if err != nil {
+		return err
+	}
+
+	if !p.seen {
+		p.product, p.seen = 1, true
+	}
+	p.product *= v.(int64)
+	return nil
+}
+
+func (p *productAggregator) Merge(ctx *sql.Context, other Aggregator) error {
+	o := other.(*productAggregator)
+	if o.seen {
+		return p.Update(ctx, []interface{}{o.product})
+	}
+	return nil
+}
+
+func (p *productAggregator) Finalize(ctx *sql.Context) (interface{}, error) {
+	if !p.seen {
+		return nil, nil
+	}
+	return p.product, nil
+}
+
+var productFunc = &AggregateFunction{
+	Name:          "product",
+	ReturnType:    sql.Int64,
+	NumArgs:       1,
+	NewAggregator: func() Aggregator { return new(productAggregator) },
+}
+
+func TestUserAggregate(t *testing.T) {
+	require := require.New(t)
+
+	e, err := productFunc.Function().Call(expression.NewGetField(0, sql.Int64, "i", true))
+	require.NoError(err)
+	agg := e.(sql.Aggregation)
+	require.Equal("PRODUCT(i)", agg.String())
+	require.Equal(sql.Int64, agg.Type())
+
+	require.Equal(int64(24), aggregate(t, agg, sql.Row{int64(2)}, sql.Row{nil}, sql.Row{int64(3)}, sql.Row{int64(4)}))
+	require.Nil(aggregate(t, agg))
+
+	_, err = productFunc.Function().Call()
+	require.True(sql.ErrInvalidArgumentNumber.Is(err))
+}
+
+func TestUserAggregateMerge(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	agg := NewUserAggregate(productFunc, expression.NewGetField(0, sql.Int64, "i", true))
+
+	buf := agg.NewBuffer()
+	require.NoError(agg.Update(ctx, buf, sql.Row{int64(2)}))
+	partial := agg.NewBuffer()
+	require.NoError(agg.Update(ctx, partial, sql.Row{int64(5)}))
+	require.NoError(agg.Merge(ctx, buf, partial))
+
+	v, err := agg.Eval(ctx, buf)
+	require.NoError(err)
+	require.Equal(int64(10), v)
+}
+
+func TestDistinct(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	sum := NewDistinct(NewSum(expression.NewGetField(0, sql.Int64, "i", true)))
+	require.Equal("SUM(DISTINCT i)", sum.String())
+	require.Equal(float64(6), aggregate(t, sum, sql.Row{int64(1)}, sql.Row{int64(2)}, sql.Row{int64(2)}, sql.Row{int64(3)}, sql.Row{nil}))
+
+	product := NewDistinct(NewUserAggregate(productFunc, expression.NewGetField(0, sql.Int64, "i", true)))
+	buf := product.NewBuffer()
+	require.NoError(product.Update(ctx, buf, sql.Row{int64(2)}))
+	require.NoError(product.Update(ctx, buf, sql.Row{int64(3)}))
+	partial := product.NewBuffer()
+	require.NoError(product.Update(ctx, partial, sql.Row{int64(3)}))
+	require.NoError(product.Update(ctx, partial, sql.Row{int64(5)}))
+	require.NoError(product.Merge(ctx, buf, partial))
+
+	v, err := product.Eval(ctx, buf)
+	require.NoError(err)
+	require.Equal(int64(30), v)
+}
@@ -51,7 +51,7 @@ func (c *Concat) FunctionName() string {
 }
 
 // Type implements the Expression interface.
-func (f *Concat) Type() sql.Type { return sql.LongText }
+func (f *Concat) Type() sql.Type { return stringResultType(f.args...) }
 
 // IsNullable implements the Expression interface.
 func (f *Concat) IsNullable() bool {
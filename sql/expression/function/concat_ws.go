@@ -48,7 +48,7 @@ func (f *ConcatWithSeparator) FunctionName() string {
 }
 
 // Type implements the Expression interface.
-func (f *ConcatWithSeparator) Type() sql.Type { return sql.LongText }
+func (f *ConcatWithSeparator) Type() sql.Type { return stringResultType(f.args...) }
 
 // IsNullable implements the Expression interface.
 func (f *ConcatWithSeparator) IsNullable() bool {
@@ -73,6 +73,9 @@ func (d *DateAdd) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	if sql.HasZeroInDate(date.(time.Time)) {
+		return nil, nil
+	}
 
 	delta, err := d.Interval.EvalDelta(ctx, row)
 	if err != nil {
@@ -155,6 +158,9 @@ func (d *DateSub) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	if sql.HasZeroInDate(date.(time.Time)) {
+		return nil, nil
+	}
 
 	delta, err := d.Interval.EvalDelta(ctx, row)
 	if err != nil {
@@ -355,6 +361,9 @@ func (ut *UnixTimestamp) Eval(ctx *sql.Context, row sql.Row) (interface{}, error
 }
 
 func toUnixTimestamp(t time.Time) (interface{}, error) {
+	if sql.HasZeroInDate(t) {
+		return sql.Float64.Convert(0)
+	}
 	return sql.Float64.Convert(float64(t.Unix()) + float64(t.Nanosecond())/float64(1000000000))
 }
 
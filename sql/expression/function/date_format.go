@@ -3,6 +3,7 @@ package function
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/lestrrat-go/strftime"
@@ -294,9 +295,49 @@ func (f *DateFormat) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		return nil, ErrInvalidArgument.New("DATE_FORMAT", "format must be a string")
 	}
 
+	if sql.HasZeroInDate(t) {
+		return formatZeroInDate(formatStr, t)
+	}
+
 	return formatDate(formatStr, t)
 }
 
+// formatZeroInDate returns the zero date or the date with a zero month or day given, like 2020-00-15, formatted with
+// the format given, or nil if the format has names or weeks, which those dates don't have.
+func formatZeroInDate(format string, t time.Time) (interface{}, error) {
+	year, month, day := sql.DateParts(t)
+	var sb strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			sb.WriteByte(format[i])
+			continue
+		}
+
+		i++
+		switch format[i] {
+		case 'Y':
+			sb.WriteString(fmt.Sprintf("%04d", year))
+		case 'y':
+			sb.WriteString(fmt.Sprintf("%02d", year%100))
+		case 'm':
+			sb.WriteString(fmt.Sprintf("%02d", month))
+		case 'd':
+			sb.WriteString(fmt.Sprintf("%02d", day))
+		case 'c':
+			sb.WriteString(strconv.Itoa(month))
+		case 'e':
+			sb.WriteString(strconv.Itoa(day))
+		case 'a', 'b', 'D', 'j', 'M', 'U', 'u', 'V', 'v', 'W', 'w', 'X', 'x':
+			return nil, nil
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(format[i])
+		}
+	}
+
+	return formatDate(sb.String(), t)
+}
+
 // Type implements the Expression interface.
 func (f *DateFormat) Type() sql.Type {
 	return sql.Text
@@ -0,0 +1,111 @@
+package function
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// WaitForExecutedGTIDSet is the WAIT_FOR_EXECUTED_GTID_SET function, which waits until the transactions of a GTID
+// set were all executed by the server, or for a number of seconds at most. It returns 0 once they were, and 1 if
+// the timeout expired before.
+type WaitForExecutedGTIDSet struct {
+	expression.BinaryExpression
+}
+
+var _ sql.FunctionExpression = (*WaitForExecutedGTIDSet)(nil)
+
+// NewWaitForExecutedGTIDSet returns a new WaitForExecutedGTIDSet expression, whose timeout is optional.
+func NewWaitForExecutedGTIDSet(args ...sql.Expression) (sql.Expression, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, sql.ErrInvalidArgumentNumber.New("WAIT_FOR_EXECUTED_GTID_SET", "1 or 2", len(args))
+	}
+
+	var timeout sql.Expression
+	if len(args) == 2 {
+		timeout = args[1]
+	}
+	return &WaitForExecutedGTIDSet{expression.BinaryExpression{Left: args[0], Right: timeout}}, nil
+}
+
+// FunctionName implements sql.FunctionExpression
+func (w *WaitForExecutedGTIDSet) FunctionName() string {
+	return "wait_for_executed_gtid_set"
+}
+
+// Children implements the Expression interface.
+func (w *WaitForExecutedGTIDSet) Children() []sql.Expression {
+	if w.Right == nil {
+		return []sql.Expression{w.Left}
+	}
+	return w.BinaryExpression.Children()
+}
+
+// Eval implements the Expression interface.
+func (w *WaitForExecutedGTIDSet) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	val, err := w.Left.Eval(ctx, row)
+	if err != nil || val == nil {
+		return nil, err
+	}
+
+	val, err = sql.LongText.Convert(val)
+	if err != nil {
+		return nil, err
+	}
+	set, err := sql.ParseGTIDSet(val.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	var timeout time.Duration
+	if w.Right != nil {
+		val, err := w.Right.Eval(ctx, row)
+		if err != nil || val == nil {
+			return nil, err
+		}
+
+		val, err = sql.Float64.Convert(val)
+		if err != nil {
+			return nil, err
+		}
+		seconds := val.(float64)
+		if seconds < 0 {
+			return nil, ErrInvalidArgument.New("WAIT_FOR_EXECUTED_GTID_SET", "timeout must not be negative")
+		}
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+
+	executed, err := sql.WaitForExecutedGTIDs(ctx, set, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if executed {
+		return int64(0), nil
+	}
+	return int64(1), nil
+}
+
+// IsNullable implements the Expression interface.
+func (w *WaitForExecutedGTIDSet) IsNullable() bool {
+	return w.Left.IsNullable() || w.Right != nil && w.Right.IsNullable()
+}
+
+// Type implements the Expression interface.
+func (w *WaitForExecutedGTIDSet) Type() sql.Type {
+	return sql.Int64
+}
+
+// String implements the fmt.Stringer interface.
+func (w *WaitForExecutedGTIDSet) String() string {
+	if w.Right == nil {
+		return fmt.Sprintf("WAIT_FOR_EXECUTED_GTID_SET(%s)", w.Left)
+	}
+	return fmt.Sprintf("WAIT_FOR_EXECUTED_GTID_SET(%s, %s)", w.Left, w.Right)
+}
+
+// WithChildren implements the Expression interface.
+func (w *WaitForExecutedGTIDSet) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	return NewWaitForExecutedGTIDSet(children...)
+}
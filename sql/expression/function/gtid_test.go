@@ -0,0 +1,83 @@
+package function
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+func TestWaitForExecutedGTIDSet(t *testing.T) {
+	const uuid = "8a94f357-aab4-11df-86ab-c80aa9429562"
+	sid, err := mysql.ParseSID(uuid)
+	require.NoError(t, err)
+	sql.AddExecutedGTID(mysql.Mysql56GTID{Server: sid, Sequence: 1})
+
+	f, err := NewWaitForExecutedGTIDSet(
+		expression.NewGetField(0, sql.LongText, "set", true),
+		expression.NewGetField(1, sql.Float64, "timeout", true),
+	)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		row      sql.Row
+		expected interface{}
+		err      bool
+	}{
+		{"null set", sql.NewRow(nil, 1.0), nil, false},
+		{"null timeout", sql.NewRow(uuid+":1", nil), nil, false},
+		{"executed", sql.NewRow(uuid+":1", 1.0), int64(0), false},
+		{"empty set", sql.NewRow("", 1.0), int64(0), false},
+		{"timeout", sql.NewRow(uuid+":1-2", 0.05), int64(1), false},
+		{"malformed set", sql.NewRow("foo", 1.0), nil, true},
+		{"negative timeout", sql.NewRow(uuid+":1", -1.0), nil, true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			v, err := f.Eval(sql.NewEmptyContext(), tt.row)
+			if tt.err {
+				require.Error(err)
+			} else {
+				require.NoError(err)
+				require.Equal(tt.expected, v)
+			}
+		})
+	}
+
+	t.Run("executed while waiting", func(t *testing.T) {
+		require := require.New(t)
+		f, err := NewWaitForExecutedGTIDSet(expression.NewLiteral(uuid+":1-3", sql.LongText))
+		require.NoError(err)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			sql.AddExecutedGTID(mysql.Mysql56GTID{Server: sid, Sequence: 2})
+			time.Sleep(10 * time.Millisecond)
+			sql.AddExecutedGTID(mysql.Mysql56GTID{Server: sid, Sequence: 3})
+		}()
+		v, err := f.Eval(sql.NewEmptyContext(), nil)
+		require.NoError(err)
+		require.Equal(int64(0), v)
+	})
+
+	t.Run("killed", func(t *testing.T) {
+		require := require.New(t)
+		f, err := NewWaitForExecutedGTIDSet(expression.NewLiteral(uuid+":10", sql.LongText))
+		require.NoError(err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = f.Eval(sql.NewContext(ctx), nil)
+		require.Equal(context.DeadlineExceeded, err)
+	})
+
+	_, err = NewWaitForExecutedGTIDSet()
+	require.True(t, sql.ErrInvalidArgumentNumber.Is(err))
+}
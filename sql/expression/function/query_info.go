@@ -0,0 +1,157 @@
+// Copyright 2020 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// RowCount returns the number of rows changed, deleted or inserted by the last statement of the session.
+type RowCount struct {
+	NoArgFunc
+}
+
+var _ sql.FunctionExpression = RowCount{}
+
+// NewRowCount creates a new RowCount expression.
+func NewRowCount() sql.Expression {
+	return RowCount{
+		NoArgFunc: NoArgFunc{"row_count", sql.Int64},
+	}
+}
+
+// Eval implements sql.Expression
+func (r RowCount) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	return sql.LastQueryInfo(ctx, sql.RowCount), nil
+}
+
+// WithChildren implements sql.Expression
+func (r RowCount) WithChildren(expressions ...sql.Expression) (sql.Expression, error) {
+	return NoArgFuncWithChildren(r, expressions)
+}
+
+// FoundRows returns the number of rows returned by the last SELECT of the session, or the number of rows it would have
+// returned without its LIMIT if it had the SQL_CALC_FOUND_ROWS modifier.
+type FoundRows struct {
+	NoArgFunc
+}
+
+var _ sql.FunctionExpression = FoundRows{}
+
+// NewFoundRows creates a new FoundRows expression.
+func NewFoundRows() sql.Expression {
+	return FoundRows{
+		NoArgFunc: NoArgFunc{"found_rows", sql.Int64},
+	}
+}
+
+// Eval implements sql.Expression
+func (f FoundRows) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	return sql.LastQueryInfo(ctx, sql.FoundRows), nil
+}
+
+// WithChildren implements sql.Expression
+func (f FoundRows) WithChildren(expressions ...sql.Expression) (sql.Expression, error) {
+	return NoArgFuncWithChildren(f, expressions)
+}
+
+// LastInsertID returns the first value generated for an AUTO_INCREMENT column by the last INSERT of the session
+// generating one. With an argument, it returns its value, which LAST_INSERT_ID() returns from then on.
+type LastInsertID struct {
+	Child sql.Expression
+}
+
+var _ sql.FunctionExpression = (*LastInsertID)(nil)
+var _ sql.NonDeterministicExpression = (*LastInsertID)(nil)
+
+// NewLastInsertID creates a new LastInsertID expression.
+func NewLastInsertID(exprs ...sql.Expression) (sql.Expression, error) {
+	if len(exprs) > 1 {
+		return nil, sql.ErrInvalidArgumentNumber.New("last_insert_id", "0 or 1", len(exprs))
+	}
+	if len(exprs) > 0 {
+		return &LastInsertID{Child: exprs[0]}, nil
+	}
+	return &LastInsertID{}, nil
+}
+
+// FunctionName implements sql.FunctionExpression
+func (l *LastInsertID) FunctionName() string {
+	return "last_insert_id"
+}
+
+// Type implements sql.Expression.
+func (l *LastInsertID) Type() sql.Type {
+	return sql.Uint64
+}
+
+// IsNonDeterministic implements sql.NonDeterministicExpression. Setting the value is never folded into a constant.
+func (l *LastInsertID) IsNonDeterministic() bool {
+	return l.Child != nil
+}
+
+// IsNullable implements sql.Expression
+func (l *LastInsertID) IsNullable() bool {
+	return l.Child != nil && l.Child.IsNullable()
+}
+
+// Resolved implements sql.Expression
+func (l *LastInsertID) Resolved() bool {
+	return l.Child == nil || l.Child.Resolved()
+}
+
+func (l *LastInsertID) String() string {
+	if l.Child != nil {
+		return fmt.Sprintf("LAST_INSERT_ID(%s)", l.Child)
+	}
+	return "LAST_INSERT_ID()"
+}
+
+// WithChildren implements sql.Expression.
+func (l *LastInsertID) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) > 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(l, len(children), 1)
+	}
+	return NewLastInsertID(children...)
+}
+
+// Children implements sql.Expression
+func (l *LastInsertID) Children() []sql.Expression {
+	if l.Child == nil {
+		return nil
+	}
+	return []sql.Expression{l.Child}
+}
+
+// Eval implements sql.Expression.
+func (l *LastInsertID) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	if l.Child == nil {
+		return uint64(sql.LastQueryInfo(ctx, sql.LastInsertID)), nil
+	}
+
+	v, err := l.Child.Eval(ctx, row)
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	id, err := sql.Uint64.Convert(v)
+	if err != nil {
+		return nil, err
+	}
+	sql.SetLastQueryInfo(ctx, sql.LastInsertID, int64(id.(uint64)))
+	return id, nil
+}
@@ -0,0 +1,64 @@
+package function
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+func TestRowCountAndFoundRows(t *testing.T) {
+	require := require.New(t)
+
+	session := sql.NewSession("", "", "", 1)
+	ctx := sql.NewContext(context.Background(), sql.WithSession(session))
+
+	result, err := NewRowCount().Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(int64(0), result)
+
+	sql.SetLastQueryInfo(ctx, sql.RowCount, -1)
+	sql.SetLastQueryInfo(ctx, sql.FoundRows, 5)
+
+	result, err = NewRowCount().Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(int64(-1), result)
+
+	result, err = NewFoundRows().Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(int64(5), result)
+}
+
+func TestLastInsertID(t *testing.T) {
+	require := require.New(t)
+
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewSession("", "", "", 1)))
+	other := sql.NewContext(context.Background(), sql.WithSession(sql.NewSession("", "", "", 2)))
+	sql.SetLastQueryInfo(ctx, sql.LastInsertID, 3)
+
+	get, err := NewLastInsertID()
+	require.NoError(err)
+	result, err := get.Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(uint64(3), result)
+
+	set, err := NewLastInsertID(expression.NewLiteral(int8(7), sql.Int8))
+	require.NoError(err)
+	result, err = set.Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(uint64(7), result)
+
+	result, err = get.Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(uint64(7), result)
+
+	result, err = get.Eval(other, nil)
+	require.NoError(err)
+	require.Equal(uint64(0), result)
+
+	_, err = NewLastInsertID(expression.NewLiteral(1, sql.Int8), expression.NewLiteral(2, sql.Int8))
+	require.True(sql.ErrInvalidArgumentNumber.Is(err))
+}
@@ -34,8 +34,7 @@ var Defaults = []sql.Function{
 	sql.NewFunction0("curdate", NewCurrDate),
 	sql.NewFunction0("current_date", NewCurrentDate),
 	sql.NewFunction0("current_time", NewCurrentTime),
-	sql.NewFunction0("current_timestamp", NewCurrTimestamp),
-	sql.NewFunction0("current_user", NewCurrentUser),
+	sql.FunctionN{Name: "current_timestamp", Fn: NewNowSynonym("current_timestamp")},
 	sql.NewFunction0("curtime", NewCurrTime),
 	sql.Function1{Name: "date", Fn: NewDate},
 	sql.FunctionN{Name: "date_add", Fn: NewDateAdd},
@@ -51,6 +50,7 @@ var Defaults = []sql.Function{
 	sql.Function1{Name: "explode", Fn: NewExplode},
 	sql.Function1{Name: "first", Fn: func(e sql.Expression) sql.Expression { return aggregation.NewFirst(e) }},
 	sql.Function1{Name: "floor", Fn: NewFloor},
+	sql.NewFunction0("found_rows", NewFoundRows),
 	sql.Function1{Name: "from_base64", Fn: NewFromBase64},
 	sql.FunctionN{Name: "greatest", Fn: NewGreatest},
 	sql.Function1{Name: "hex", Fn: NewHex},
@@ -62,11 +62,14 @@ var Defaults = []sql.Function{
 	sql.FunctionN{Name: "json_extract", Fn: NewJSONExtract},
 	sql.Function1{Name: "json_unquote", Fn: NewJSONUnquote},
 	sql.Function1{Name: "last", Fn: func(e sql.Expression) sql.Expression { return aggregation.NewLast(e) }},
+	sql.FunctionN{Name: "last_insert_id", Fn: NewLastInsertID},
 	sql.Function1{Name: "lcase", Fn: NewLower},
 	sql.FunctionN{Name: "least", Fn: NewLeast},
 	sql.Function2{Name: "left", Fn: NewLeft},
 	sql.Function1{Name: "length", Fn: NewLength},
 	sql.Function1{Name: "ln", Fn: NewLogBaseFunc(float64(math.E))},
+	sql.FunctionN{Name: "localtime", Fn: NewNowSynonym("localtime")},
+	sql.FunctionN{Name: "localtimestamp", Fn: NewNowSynonym("localtimestamp")},
 	sql.FunctionN{Name: "log", Fn: NewLog},
 	sql.Function1{Name: "log10", Fn: NewLogBaseFunc(float64(10))},
 	sql.Function1{Name: "log2", Fn: NewLogBaseFunc(float64(2))},
@@ -91,6 +94,7 @@ var Defaults = []sql.Function{
 	sql.Function3{Name: "replace", Fn: NewReplace},
 	sql.Function1{Name: "reverse", Fn: NewReverse},
 	sql.FunctionN{Name: "round", Fn: NewRound},
+	sql.NewFunction0("row_count", NewRowCount),
 	sql.FunctionN{Name: "rpad", Fn: NewPadFunc(rPadType)},
 	sql.Function1{Name: "rtrim", Fn: NewTrimFunc(rTrimType)},
 	sql.Function1{Name: "second", Fn: NewSecond},
@@ -116,6 +120,8 @@ var Defaults = []sql.Function{
 	sql.Function2{Name: "timediff", Fn: NewTimeDiff},
 	sql.Function1{Name: "upper", Fn: NewUpper},
 	sql.NewFunction0("user", NewUser),
+	sql.NewFunction0("uuid", NewUUID),
+	sql.FunctionN{Name: "wait_for_executed_gtid_set", Fn: NewWaitForExecutedGTIDSet},
 	sql.FunctionN{Name: "week", Fn: NewWeek},
 	sql.Function1{Name: "weekday", Fn: NewWeekday},
 	sql.Function1{Name: "weekofyear", Fn: NewWeekOfYear},
@@ -95,7 +95,7 @@ func (r *Repeat) String() string {
 
 // Type implements the Expression interface.
 func (r *Repeat) Type() sql.Type {
-	return sql.LongText
+	return stringResultType(r.Left)
 }
 
 // WithChildren implements the Expression interface.
@@ -177,7 +177,7 @@ func (r *Replace) String() string {
 
 // Type implements the Expression interface.
 func (r *Replace) Type() sql.Type {
-	return sql.LongText
+	return stringResultType(r.str)
 }
 
 // WithChildren implements the Expression interface.
@@ -73,7 +73,7 @@ func (p *Pad) IsNullable() bool {
 }
 
 // Type implements the Expression interface.
-func (p *Pad) Type() sql.Type { return sql.LongText }
+func (p *Pad) Type() sql.Type { return stringResultType(p.str) }
 
 func (p *Pad) String() string {
 	if p.padType == lPadType {
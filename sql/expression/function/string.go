@@ -367,3 +367,14 @@ func (h *Bitlength) WithChildren(children ...sql.Expression) (sql.Expression, er
 	}
 	return NewBitlength(children[0]), nil
 }
+
+// stringResultType returns the type of the strings a function returns from the ones given as arguments, which keep
+// the collation of the first argument whose collation isn't the default one.
+func stringResultType(args ...sql.Expression) sql.Type {
+	for _, arg := range args {
+		if st, ok := arg.Type().(sql.StringType); ok && st.Collation() != sql.Collation_Default {
+			return sql.CreateLongText(st.Collation())
+		}
+	}
+	return sql.LongText
+}
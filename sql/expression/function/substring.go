@@ -147,7 +147,7 @@ func (s *Substring) Resolved() bool {
 }
 
 // Type implements the Expression interface.
-func (*Substring) Type() sql.Type { return sql.LongText }
+func (s *Substring) Type() sql.Type { return stringResultType(s.str) }
 
 // WithChildren implements the Expression interface.
 func (*Substring) WithChildren(children ...sql.Expression) (sql.Expression, error) {
@@ -357,7 +357,7 @@ func (l Left) Resolved() bool {
 }
 
 // Type implements the Expression interface.
-func (Left) Type() sql.Type { return sql.LongText }
+func (l Left) Type() sql.Type { return stringResultType(l.str) }
 
 // WithChildren implements the Expression interface.
 func (l Left) WithChildren(children ...sql.Expression) (sql.Expression, error) {
@@ -14,7 +14,13 @@
 
 package function
 
-import "github.com/dolthub/go-mysql-server/sql"
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
 
 type ConnectionID struct {
 	NoArgFunc
@@ -42,12 +48,28 @@ func (c ConnectionID) WithChildren(expressions ...sql.Expression) (sql.Expressio
 	return NoArgFuncWithChildren(c, expressions)
 }
 
+// User returns the user the client logged in as and the host it connected from, in the user@host form. For clients
+// acting as another user, it's the proxy user they logged in as.
 type User struct {
 	NoArgFunc
 }
 
 func userFuncLogic(ctx *sql.Context, _ sql.Row) (interface{}, error) {
-	return ctx.Client().User, nil
+	client := ctx.Client()
+	user := client.User
+	if client.ProxyUser != "" {
+		user = client.ProxyUser
+	}
+
+	host := client.Address
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" {
+		host = "localhost"
+	}
+
+	return user + "@" + host, nil
 }
 
 var _ sql.FunctionExpression = User{}
@@ -58,12 +80,6 @@ func NewUser() sql.Expression {
 	}
 }
 
-func NewCurrentUser() sql.Expression {
-	return User{
-		NoArgFunc: NoArgFunc{"current_user", sql.LongText},
-	}
-}
-
 // Eval implements sql.Expression
 func (c User) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 	return userFuncLogic(ctx, row)
@@ -73,3 +89,91 @@ func (c User) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 func (c User) WithChildren(expressions ...sql.Expression) (sql.Expression, error) {
 	return NoArgFuncWithChildren(c, expressions)
 }
+
+// CurrentUser returns the account whose privileges the session has, in the user@host form, where host is the host of
+// the account rather than the one the client connected from. For clients logged in as a proxy user, it's the proxied
+// account.
+type CurrentUser struct {
+	NoArgFunc
+	catalog *sql.Catalog
+}
+
+var _ sql.FunctionExpression = CurrentUser{}
+
+// NewCurrentUser returns a function creating CurrentUser expressions that
+// look the account up in the catalog given.
+func NewCurrentUser(c *sql.Catalog) func() sql.Expression {
+	return func() sql.Expression {
+		return CurrentUser{
+			NoArgFunc: NoArgFunc{"current_user", sql.LongText},
+			catalog:   c,
+		}
+	}
+}
+
+// Eval implements sql.Expression
+func (c CurrentUser) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	account, err := c.catalog.CurrentAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return account.Name + "@" + account.Host, nil
+}
+
+// WithChildren implements sql.Expression
+func (c CurrentUser) WithChildren(expressions ...sql.Expression) (sql.Expression, error) {
+	return NoArgFuncWithChildren(c, expressions)
+}
+
+// CurrentRole returns the roles active in the session, such as `r1`@`%`,`r2`@`%`, or NONE if there are none.
+type CurrentRole struct {
+	NoArgFunc
+	catalog *sql.Catalog
+}
+
+var _ sql.FunctionExpression = CurrentRole{}
+
+// NewCurrentRole returns a function creating CurrentRole expressions that
+// look the roles up in the catalog given.
+func NewCurrentRole(c *sql.Catalog) func() sql.Expression {
+	return func() sql.Expression {
+		return CurrentRole{
+			NoArgFunc: NoArgFunc{"current_role", sql.LongText},
+			catalog:   c,
+		}
+	}
+}
+
+// Eval implements sql.Expression
+func (c CurrentRole) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	rm, err := c.catalog.RoleManager()
+	if err != nil {
+		return "NONE", nil
+	}
+
+	account, err := rm.CurrentAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := sql.ActiveRoles(ctx, rm, account)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(roles) == 0 {
+		return "NONE", nil
+	}
+
+	names := make([]string, len(roles))
+	for i, r := range roles {
+		names[i] = "`" + r.Name + "`@`" + r.Host + "`"
+	}
+	sort.Strings(names)
+	return strings.Join(names, ","), nil
+}
+
+// WithChildren implements sql.Expression
+func (c CurrentRole) WithChildren(expressions ...sql.Expression) (sql.Expression, error) {
+	return NoArgFuncWithChildren(c, expressions)
+}
@@ -672,19 +672,33 @@ func calcDaynr(yyyy, mm, dd int32) int32 {
 }
 
 var (
-	year      = datePartFunc((time.Time).Year)
-	month     = datePartFunc(func(t time.Time) int { return int(t.Month()) })
-	day       = datePartFunc((time.Time).Day)
-	weekday   = datePartFunc(func(t time.Time) int { return (int(t.Weekday()) + 6) % 7 })
+	year      = datePartFunc(func(t time.Time) int { year, _, _ := sql.DateParts(t); return year })
+	month     = datePartFunc(func(t time.Time) int { _, month, _ := sql.DateParts(t); return month })
+	day       = datePartFunc(func(t time.Time) int { _, _, day := sql.DateParts(t); return day })
+	weekday   = calendarPartFunc(func(t time.Time) int { return (int(t.Weekday()) + 6) % 7 })
 	hour      = datePartFunc((time.Time).Hour)
 	minute    = datePartFunc((time.Time).Minute)
 	second    = datePartFunc((time.Time).Second)
-	dayOfWeek = datePartFunc(func(t time.Time) int { return int(t.Weekday()) + 1 })
-	dayOfYear = datePartFunc((time.Time).YearDay)
+	dayOfWeek = calendarPartFunc(func(t time.Time) int { return int(t.Weekday()) + 1 })
+	dayOfYear = calendarPartFunc((time.Time).YearDay)
 )
 
+// calendarPartFunc is like datePartFunc, for parts of dates the zero date 0000-00-00 and the dates with a zero month or
+// day like 2020-00-15 don't have, like their day of the week, which are NULL for them.
+func calendarPartFunc(fn func(time.Time) int) func(interface{}) interface{} {
+	part := datePartFunc(fn)
+	return func(v interface{}) interface{} {
+		if t, ok := v.(time.Time); ok && sql.HasZeroInDate(t) {
+			return nil
+		}
+		return part(v)
+	}
+}
+
 // Now is a function that returns the current time.
 type Now struct {
+	// name is the name it's called by, which is now unless it's one of its synonyms.
+	name      string
 	precision *int
 }
 
@@ -692,9 +706,21 @@ var _ sql.FunctionExpression = (*Now)(nil)
 
 // NewNow returns a new Now node.
 func NewNow(args ...sql.Expression) (sql.Expression, error) {
+	return newNow("now", args...)
+}
+
+// NewNowSynonym returns the constructor of a synonym of NOW() with the name given, such as CURRENT_TIMESTAMP or
+// LOCALTIME.
+func NewNowSynonym(name string) func(args ...sql.Expression) (sql.Expression, error) {
+	return func(args ...sql.Expression) (sql.Expression, error) {
+		return newNow(name, args...)
+	}
+}
+
+func newNow(name string, args ...sql.Expression) (sql.Expression, error) {
 	var precision *int
 	if len(args) > 1 {
-		return nil, sql.ErrInvalidArgumentNumber.New("TIMESTAMP", 1, len(args))
+		return nil, sql.ErrInvalidArgumentNumber.New(strings.ToUpper(name), 1, len(args))
 	} else if len(args) == 1 {
 		argType := args[0].Type().Promote()
 		if argType != sql.Int64 && argType != sql.Uint64 {
@@ -712,12 +738,12 @@ func NewNow(args ...sql.Expression) (sql.Expression, error) {
 
 		n := int(precisionArg.(int32))
 		if n < 0 || n > 6 {
-			return nil, sql.ErrOutOfRange.New("precision", "now")
+			return nil, sql.ErrOutOfRange.New("precision", name)
 		}
 		precision = &n
 	}
 
-	return &Now{precision}, nil
+	return &Now{name, precision}, nil
 }
 
 func subSecondPrecision(t time.Time, precision int) string {
@@ -750,7 +776,7 @@ func fractionOfSecString(t time.Time) string {
 
 // FunctionName implements sql.FunctionExpression
 func (n *Now) FunctionName() string {
-	return "now"
+	return n.name
 }
 
 // Type implements the sql.Expression interface.
@@ -760,10 +786,10 @@ func (n *Now) Type() sql.Type {
 
 func (n *Now) String() string {
 	if n.precision == nil {
-		return "NOW()"
+		return strings.ToUpper(n.name) + "()"
 	}
 
-	return fmt.Sprintf("NOW(%d)", *n.precision)
+	return fmt.Sprintf("%s(%d)", strings.ToUpper(n.name), *n.precision)
 }
 
 // IsNullable implements the sql.Expression interface.
@@ -795,7 +821,7 @@ func (n *Now) Eval(ctx *sql.Context, _ sql.Row) (interface{}, error) {
 
 // WithChildren implements the Expression interface.
 func (n *Now) WithChildren(children ...sql.Expression) (sql.Expression, error) {
-	return NewNow(children...)
+	return newNow(n.name, children...)
 }
 
 // UTCTimestamp is a function that returns the current time.
@@ -902,7 +928,7 @@ func (d *Date) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 			return nil
 		}
 
-		return v.(time.Time).Format("2006-01-02")
+		return sql.FormatTime(v.(time.Time), sql.DateLayout)
 	})
 }
 
@@ -973,7 +999,10 @@ func (d *DayName) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		return nil, err
 	}
 
-	t := val.(time.Time)
+	t, ok := val.(time.Time)
+	if !ok || sql.HasZeroInDate(t) {
+		return nil, nil
+	}
 	return t.Weekday().String(), nil
 }
 
@@ -1029,7 +1058,10 @@ func (d *MonthName) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		return nil, err
 	}
 
-	t := val.(time.Time)
+	t, ok := val.(time.Time)
+	if !ok || sql.HasZeroInDate(t) {
+		return nil, nil
+	}
 	return t.Month().String(), nil
 }
 
@@ -1085,7 +1117,10 @@ func (m *WeekOfYear) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		return nil, err
 	}
 
-	t := val.(time.Time)
+	t, ok := val.(time.Time)
+	if !ok || sql.HasZeroInDate(t) {
+		return nil, nil
+	}
 	_, wk := t.ISOWeek()
 	return wk, nil
 }
@@ -53,7 +53,7 @@ func (t *Trim) FunctionName() string {
 }
 
 // Type implements the Expression interface.
-func (t *Trim) Type() sql.Type { return sql.LongText }
+func (t *Trim) Type() sql.Type { return stringResultType(t.Child) }
 
 func (t *Trim) String() string {
 	switch t.trimType {
@@ -19,18 +19,18 @@ func TestUser(t *testing.T) {
 
 	user, err := fn().Eval(ctx, nil)
 	require.NoError(t, err)
-	assert.Equal(t, "root", user)
+	assert.Equal(t, "root@client", user)
 
 	session = sql.NewSession("server", "client", "someguy", 0)
 	ctx = sql.NewContext(context.TODO(), sql.WithSession(session))
 
 	user, err = fn().Eval(ctx, nil)
 	require.NoError(t, err)
-	assert.Equal(t, "someguy", user)
+	assert.Equal(t, "someguy@client", user)
 
 	ctx = sql.NewEmptyContext()
 
 	user, err = fn().Eval(ctx, nil)
 	require.NoError(t, err)
-	assert.Equal(t, "", user)
+	assert.Equal(t, "@localhost", user)
 }
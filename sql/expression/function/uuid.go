@@ -0,0 +1,88 @@
+// Copyright 2020 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// UUID returns a version 1 UUID, made of the time it's called at, a clock sequence and a node ID. Like MySQL does
+// for servers without a usable MAC address, the node ID is random, and picked once per process.
+type UUID struct {
+	NoArgFunc
+}
+
+var _ sql.FunctionExpression = UUID{}
+
+// uuidEpoch is the start of the Gregorian calendar, from which version 1 UUIDs count time in 100 nanosecond intervals.
+var uuidEpoch = time.Date(1582, time.October, 15, 0, 0, 0, 0, time.UTC)
+
+var uuidState struct {
+	sync.Mutex
+	node     [6]byte
+	clockSeq uint16
+	last     uint64
+}
+
+func init() {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	copy(uuidState.node[:], b[:6])
+	// random node IDs have the multicast bit set, so they can't clash with MAC addresses
+	uuidState.node[0] |= 0x01
+	uuidState.clockSeq = binary.BigEndian.Uint16(b[6:]) & 0x3fff
+}
+
+func NewUUID() sql.Expression {
+	return UUID{
+		NoArgFunc: NoArgFunc{"uuid", sql.LongText},
+	}
+}
+
+func uuidFuncLogic(_ *sql.Context, _ sql.Row) (interface{}, error) {
+	uuidState.Lock()
+	ts := uint64(time.Since(uuidEpoch) / 100)
+	if ts <= uuidState.last {
+		// keep the UUIDs generated within the same interval unique
+		ts = uuidState.last + 1
+	}
+	uuidState.last = ts
+	clockSeq, node := uuidState.clockSeq, uuidState.node
+	uuidState.Unlock()
+
+	var b [16]byte
+	binary.BigEndian.PutUint32(b[0:4], uint32(ts))
+	binary.BigEndian.PutUint16(b[4:6], uint16(ts>>32))
+	binary.BigEndian.PutUint16(b[6:8], uint16(ts>>48)&0x0fff|0x1000)
+	binary.BigEndian.PutUint16(b[8:10], clockSeq|0x8000)
+	copy(b[10:], node[:])
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// Eval implements sql.Expression
+func (u UUID) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	return uuidFuncLogic(ctx, row)
+}
+
+// WithChildren implements sql.Expression
+func (u UUID) WithChildren(expressions ...sql.Expression) (sql.Expression, error) {
+	return NoArgFuncWithChildren(u, expressions)
+}
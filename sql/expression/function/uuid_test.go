@@ -0,0 +1,28 @@
+package function
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestUUID(t *testing.T) {
+	require := require.New(t)
+
+	uuidFunc := sql.NewFunction0("uuid", NewUUID)
+	uuidRegex := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-1[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		result, err := uuidFunc.Fn().Eval(sql.NewEmptyContext(), nil)
+		require.NoError(err)
+
+		uuid := result.(string)
+		require.Regexp(uuidRegex, uuid)
+		require.False(seen[uuid], "duplicate UUID %s", uuid)
+		seen[uuid] = true
+	}
+}
@@ -48,11 +48,9 @@ func (s *SetField) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	if val != nil {
-		val, err = getField.fieldType.Convert(val)
-		if err != nil {
-			return nil, err
-		}
+	val, err = sql.ConvertToColumn(ctx, getField.fieldType, getField.name, val)
+	if err != nil {
+		return nil, err
 	}
 	updatedRow := row.Copy()
 	updatedRow[getField.fieldIndex] = val
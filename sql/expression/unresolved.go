@@ -79,6 +79,8 @@ type UnresolvedFunction struct {
 	name string
 	// IsAggregate or not.
 	IsAggregate bool
+	// Distinct is whether the function was called with DISTINCT, which is only valid for aggregations.
+	Distinct bool
 	// Children of the expression.
 	Arguments []sql.Expression
 }
@@ -89,7 +91,7 @@ func NewUnresolvedFunction(
 	agg bool,
 	arguments ...sql.Expression,
 ) *UnresolvedFunction {
-	return &UnresolvedFunction{name, agg, arguments}
+	return &UnresolvedFunction{name: name, IsAggregate: agg, Arguments: arguments}
 }
 
 // Children implements the Expression interface.
@@ -120,6 +122,9 @@ func (uf *UnresolvedFunction) String() string {
 	for i, e := range uf.Arguments {
 		exprs[i] = e.String()
 	}
+	if uf.Distinct {
+		return fmt.Sprintf("%s(DISTINCT %s)", uf.name, strings.Join(exprs, ", "))
+	}
 	return fmt.Sprintf("%s(%s)", uf.name, strings.Join(exprs, ", "))
 }
 
@@ -133,5 +138,7 @@ func (uf *UnresolvedFunction) WithChildren(children ...sql.Expression) (sql.Expr
 	if len(children) != len(uf.Arguments) {
 		return nil, sql.ErrInvalidChildrenNumber.New(uf, len(children), len(uf.Arguments))
 	}
-	return NewUnresolvedFunction(uf.name, uf.IsAggregate, children...), nil
+	nf := NewUnresolvedFunction(uf.name, uf.IsAggregate, children...)
+	nf.Distinct = uf.Distinct
+	return nf, nil
 }
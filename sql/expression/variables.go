@@ -17,6 +17,8 @@ package expression
 import (
 	"fmt"
 
+	"github.com/dolthub/vitess/go/sqltypes"
+
 	"github.com/dolthub/go-mysql-server/sql"
 )
 
@@ -25,11 +27,44 @@ import (
 type SystemVar struct {
 	Name string
 	typ  sql.Type
+	// Global is whether the expression refers to the global value of the
+	// variable instead of the one in the session.
+	Global bool
+	// Persist is whether setting the variable persists its global value,
+	// along with setting it or instead of it.
+	Persist PersistMode
 }
 
-// NewSystemVar creates a new SystemVar expression.
+// PersistMode is how a SET statement persists the global value of a system
+// variable.
+type PersistMode byte
+
+const (
+	// NoPersist sets the value of the variable without persisting it.
+	NoPersist PersistMode = iota
+	// Persist sets the global value of the variable and persists it, as SET
+	// PERSIST does.
+	Persist
+	// PersistOnly persists the global value of the variable without setting
+	// it, as SET PERSIST_ONLY does.
+	PersistOnly
+)
+
+// NewSystemVar creates a new SystemVar expression for the value of a variable in the session, or its global value if it
+// has no value in each session.
 func NewSystemVar(name string, typ sql.Type) *SystemVar {
-	return &SystemVar{name, typ}
+	return &SystemVar{Name: name, typ: typ}
+}
+
+// NewGlobalSystemVar creates a new SystemVar expression for the global value of a variable.
+func NewGlobalSystemVar(name string, typ sql.Type) *SystemVar {
+	return &SystemVar{Name: name, typ: typ, Global: true}
+}
+
+// NewPersistedSystemVar creates a new SystemVar expression for the global value of a variable, to be persisted when
+// it's set.
+func NewPersistedSystemVar(name string, typ sql.Type, persist PersistMode) *SystemVar {
+	return &SystemVar{Name: name, typ: typ, Global: true, Persist: persist}
 }
 
 // Children implements the sql.Expression interface.
@@ -37,7 +72,15 @@ func (v *SystemVar) Children() []sql.Expression { return nil }
 
 // Eval implements the sql.Expression interface.
 func (v *SystemVar) Eval(ctx *sql.Context, _ sql.Row) (interface{}, error) {
-	_, val := ctx.Get(v.Name)
+	// Session values share their names with user variables, so only the
+	// variables that have them are looked up in the session.
+	if sv, ok := ctx.SystemVariables().Lookup(v.Name); !v.Global && (!ok || sv.HasSession()) {
+		if typ, val := ctx.Get(v.Name); typ != sql.Null {
+			return val, nil
+		}
+	}
+
+	val, _ := ctx.SystemVariables().Global(v.Name)
 	return val, nil
 }
 
@@ -51,10 +94,21 @@ func (v *SystemVar) IsNullable() bool { return false }
 func (v *SystemVar) Resolved() bool { return true }
 
 // String implements the sql.Expression interface.
-func (v *SystemVar) String() string { return "@@" + v.Name }
+func (v *SystemVar) String() string {
+	switch v.Persist {
+	case Persist:
+		return "@@persist." + v.Name
+	case PersistOnly:
+		return "@@persist_only." + v.Name
+	}
+	if v.Global {
+		return "@@global." + v.Name
+	}
+	return "@@" + v.Name
+}
 
 func (v *SystemVar) DebugString() string {
-	return fmt.Sprintf("@@%s (%s)", v.Name, v.typ)
+	return fmt.Sprintf("%s (%s)", v, v.typ)
 }
 
 // WithChildren implements the Expression interface.
@@ -69,11 +123,19 @@ func (v *SystemVar) WithChildren(children ...sql.Expression) (sql.Expression, er
 // side of a SET statement for a user var.
 type UserVar struct {
 	Name string
+	// typ is the type of the value the variable had when the expression was resolved, used as the type of the
+	// expression for the rest of the query.
+	typ sql.Type
 }
 
 // NewUserVar creates a new UserVar expression.
 func NewUserVar(name string) *UserVar {
-	return &UserVar{name}
+	return &UserVar{Name: name, typ: sql.Null}
+}
+
+// NewUserVarWithType creates a new UserVar expression for a variable whose value is of the type given.
+func NewUserVarWithType(name string, typ sql.Type) *UserVar {
+	return &UserVar{Name: name, typ: typ}
 }
 
 // Children implements the sql.Expression interface.
@@ -85,9 +147,55 @@ func (v *UserVar) Eval(ctx *sql.Context, _ sql.Row) (interface{}, error) {
 	return val, nil
 }
 
+// Set sets the variable to a value of the type given, and returns the value it's set to. User variables only have
+// values of a few types: the value is converted to a 64 bit integer, a float, a decimal, a string with the collation
+// it has, a binary string, or NULL. Values of other types, such as dates, are set as their string representation.
+func (v *UserVar) Set(ctx *sql.Context, typ sql.Type, value interface{}) (interface{}, error) {
+	var err error
+	varType := userVarType(typ)
+	switch _, isString := typ.(sql.StringType); {
+	case value == nil:
+		varType = sql.Null
+	case varType == sql.LongText && !isString:
+		var str sqltypes.Value
+		if str, err = typ.SQL(value); err != nil {
+			return nil, err
+		}
+		value = str.ToString()
+	default:
+		if value, err = varType.Convert(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return value, ctx.Set(ctx, v.Name, varType, value)
+}
+
+// userVarType returns the type of the values of user variables set to values of the type given.
+func userVarType(typ sql.Type) sql.Type {
+	st, isString := typ.(sql.StringType)
+	switch {
+	case typ == sql.Null:
+		return sql.Null
+	case sql.IsUnsigned(typ):
+		return sql.Uint64
+	case sql.IsInteger(typ):
+		return sql.Int64
+	case sql.IsFloat(typ):
+		return sql.Float64
+	case sql.IsDecimal(typ):
+		return typ
+	case isString && sql.IsBlob(typ):
+		return sql.LongBlob
+	case isString:
+		return sql.CreateLongText(st.Collation())
+	default:
+		return sql.LongText
+	}
+}
+
 // Type implements the sql.Expression interface.
-// TODO: type checking based on type of user var
-func (v *UserVar) Type() sql.Type { return sql.Boolean }
+func (v *UserVar) Type() sql.Type { return v.typ }
 
 // IsNullable implements the sql.Expression interface.
 func (v *UserVar) IsNullable() bool { return true }
@@ -109,3 +217,55 @@ func (v *UserVar) WithChildren(children ...sql.Expression) (sql.Expression, erro
 	}
 	return v, nil
 }
+
+// UserVarAssignment is an expression that sets a user variable to the value of another one and returns it, as in
+// @var := expr. It's evaluated each time its value is needed, so in a query reading rows it's set once per row, in the
+// order the rows are read.
+type UserVarAssignment struct {
+	UnaryExpression
+	Var *UserVar
+}
+
+var _ sql.NonDeterministicExpression = (*UserVarAssignment)(nil)
+
+// NewUserVarAssignment creates a new UserVarAssignment expression.
+func NewUserVarAssignment(v *UserVar, expr sql.Expression) *UserVarAssignment {
+	return &UserVarAssignment{UnaryExpression{Child: expr}, v}
+}
+
+// Eval implements the sql.Expression interface.
+func (a *UserVarAssignment) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	value, err := a.Child.Eval(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Var.Set(ctx, a.Child.Type(), value)
+}
+
+// Type implements the sql.Expression interface.
+func (a *UserVarAssignment) Type() sql.Type {
+	return userVarType(a.Child.Type())
+}
+
+// IsNonDeterministic implements the sql.NonDeterministicExpression interface. Assignments are never folded into the
+// value they set, since they must set the variable when they're evaluated.
+func (a *UserVarAssignment) IsNonDeterministic() bool { return true }
+
+// String implements the sql.Expression interface.
+func (a *UserVarAssignment) String() string {
+	return fmt.Sprintf("%s := %s", a.Var, a.Child)
+}
+
+// DebugString implements the sql.DebugStringer interface.
+func (a *UserVarAssignment) DebugString() string {
+	return fmt.Sprintf("%s := %s", a.Var, sql.DebugString(a.Child))
+}
+
+// WithChildren implements the sql.Expression interface.
+func (a *UserVarAssignment) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(a, len(children), 1)
+	}
+	return NewUserVarAssignment(a.Var, children[0]), nil
+}
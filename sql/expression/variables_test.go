@@ -0,0 +1,75 @@
+package expression
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestUserVarSet(t *testing.T) {
+	decimalType := sql.MustCreateDecimalType(10, 2)
+	binaryType := sql.MustCreateBinary(sqltypes.VarBinary, 10)
+
+	testCases := []struct {
+		name          string
+		typ           sql.Type
+		value         interface{}
+		expectedType  sql.Type
+		expectedValue interface{}
+	}{
+		{"null", sql.Int64, nil, sql.Null, nil},
+		{"signed integer", sql.Int8, int8(3), sql.Int64, int64(3)},
+		{"unsigned integer", sql.Uint16, uint16(3), sql.Uint64, uint64(3)},
+		{"float", sql.Float32, float32(1.5), sql.Float64, float64(1.5)},
+		{"decimal", decimalType, decimal.NewFromFloat(1.25), decimalType, "1.25"},
+		{"string", sql.MustCreateStringWithDefaults(sqltypes.VarChar, 10), "abc", sql.LongText, "abc"},
+		{
+			"string with collation",
+			sql.MustCreateString(sqltypes.VarChar, 10, sql.Collation_utf8mb4_bin),
+			"abc",
+			sql.CreateLongText(sql.Collation_utf8mb4_bin),
+			"abc",
+		},
+		{"binary string", binaryType, []byte("abc"), sql.LongBlob, "abc"},
+		{"date", sql.Date, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), sql.LongText, "2020-01-02"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession()))
+
+			value, err := NewUserVar("myvar").Set(ctx, tt.typ, tt.value)
+			require.NoError(err)
+			require.Equal(tt.expectedValue, value)
+
+			typ, value := ctx.Get("myvar")
+			require.Equal(tt.expectedType, typ)
+			require.Equal(tt.expectedValue, value)
+		})
+	}
+}
+
+func TestUserVarAssignment(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession()))
+
+	a := NewUserVarAssignment(NewUserVar("myvar"), NewGetField(0, sql.Int32, "i", false))
+	require.Equal(sql.Int64, a.Type())
+	require.Equal("@myvar := i", a.String())
+
+	for _, i := range []int32{1, 2} {
+		value, err := a.Eval(ctx, sql.NewRow(i))
+		require.NoError(err)
+		require.Equal(int64(i), value)
+
+		_, value = ctx.Get("myvar")
+		require.Equal(int64(i), value)
+	}
+}
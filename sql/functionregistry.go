@@ -1,6 +1,8 @@
 package sql
 
 import (
+	"strings"
+
 	"gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/go-mysql-server/internal/similartext"
@@ -234,3 +236,55 @@ func (r FunctionRegistry) Function(name string) (Function, error) {
 	similar := similartext.FindFromMap(r, name)
 	return nil, ErrFunctionNotFound.New(name + similar)
 }
+
+// ErrTableFunctionAlreadyRegistered is thrown when a table function is already registered
+var ErrTableFunctionAlreadyRegistered = errors.NewKind("table function '%s' is already registered")
+
+// ErrTableFunctionNotFound is thrown when a table function is not found
+var ErrTableFunctionNotFound = errors.NewKind("table function: '%s' not found")
+
+// TableFunction is a function that returns a table, and can be used in place of a table in the FROM clause of a
+// query, e.g. SELECT * FROM my_func(1, 2).
+type TableFunction interface {
+	// Name returns the name of the function.
+	Name() string
+	// NewInstance returns a node that produces the rows of the function for the arguments given. Arguments are
+	// resolved, but they are not guaranteed to be constant and may need to be evaluated against the row given to the
+	// RowIter of the node returned.
+	NewInstance(ctx *Context, args []Expression) (Node, error)
+}
+
+// TableFunctionRegistry is used to register table functions.
+type TableFunctionRegistry map[string]TableFunction
+
+// NewTableFunctionRegistry creates a new TableFunctionRegistry.
+func NewTableFunctionRegistry() TableFunctionRegistry {
+	return make(TableFunctionRegistry)
+}
+
+// Register registers table functions. If a table function with the same name is already registered,
+// ErrTableFunctionAlreadyRegistered is returned.
+func (r TableFunctionRegistry) Register(fn ...TableFunction) error {
+	for _, f := range fn {
+		name := strings.ToLower(f.Name())
+		if _, ok := r[name]; ok {
+			return ErrTableFunctionAlreadyRegistered.New(f.Name())
+		}
+		r[name] = f
+	}
+	return nil
+}
+
+// Function returns the table function with the given name.
+func (r TableFunctionRegistry) Function(name string) (TableFunction, error) {
+	if fn, ok := r[strings.ToLower(name)]; ok {
+		return fn, nil
+	}
+
+	if len(r) == 0 {
+		return nil, ErrTableFunctionNotFound.New(name)
+	}
+
+	similar := similartext.FindFromMap(r, name)
+	return nil, ErrTableFunctionNotFound.New(name + similar)
+}
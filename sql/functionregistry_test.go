@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/dolthub/go-mysql-server/memory"
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/expression"
 )
@@ -44,3 +45,55 @@ func TestFunctionRegistryMissingFunction(t *testing.T) {
 	require.Error(err)
 	require.Nil(f)
 }
+
+func TestResolveScopedFunctions(t *testing.T) {
+	require := require.New(t)
+
+	newFunc := func(name string, value string) sql.Function {
+		return sql.Function0{
+			Name: name,
+			Fn:   func() sql.Expression { return expression.NewLiteral(value, sql.LongText) },
+		}
+	}
+
+	c := sql.NewCatalog()
+	c.MustRegister(newFunc("f", "global"), newFunc("g", "global"))
+
+	db := memory.NewDatabase("tenant")
+	require.NoError(db.AddFunction(newFunc("f", "database"), newFunc("h", "database")))
+	c.AddDatabase(db)
+	c.AddDatabase(memory.NewDatabase("other"))
+
+	ctx := sql.NewEmptyContext()
+	require.NoError(ctx.Session.(sql.FunctionSession).RegisterFunction(newFunc("g", "session")))
+
+	resolve := func(name string) string {
+		fn, err := c.ResolveFunction(ctx, name)
+		require.NoError(err)
+		e, err := fn.Call()
+		require.NoError(err)
+		v, err := e.Eval(ctx, nil)
+		require.NoError(err)
+		return v.(string)
+	}
+
+	ctx.SetCurrentDatabase("tenant")
+	require.Equal("database", resolve("f"))
+	require.Equal("session", resolve("g"))
+	require.Equal("database", resolve("h"))
+
+	ctx.SetCurrentDatabase("other")
+	require.Equal("global", resolve("f"))
+	require.Equal("session", resolve("g"))
+	_, err := c.ResolveFunction(ctx, "h")
+	require.True(sql.ErrFunctionNotFound.Is(err))
+
+	other := sql.NewEmptyContext().WithCurrentDB("tenant")
+	fn, err := c.ResolveFunction(other, "g")
+	require.NoError(err)
+	e, err := fn.Call()
+	require.NoError(err)
+	v, err := e.Eval(other, nil)
+	require.NoError(err)
+	require.Equal("global", v)
+}
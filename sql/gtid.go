@@ -0,0 +1,96 @@
+package sql
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	errors "gopkg.in/src-d/go-errors.v1"
+)
+
+// ErrInvalidGTIDSet is returned when a GTID set given to a statement or a function can't be parsed.
+var ErrInvalidGTIDSet = errors.NewKind("Malformed GTID set specification '%s'.")
+
+// gtids are the GTIDs of the transactions executed by the binary logs and the replicas of the process, and of the
+// ones purged from the binary logs, which @@gtid_executed and @@gtid_purged show.
+var gtids = struct {
+	mu       sync.Mutex
+	executed mysql.Mysql56GTIDSet
+	purged   mysql.Mysql56GTIDSet
+	// changed is closed and replaced every time a GTID is executed, to wake up WaitForExecutedGTIDs.
+	changed chan struct{}
+}{
+	executed: mysql.Mysql56GTIDSet{},
+	purged:   mysql.Mysql56GTIDSet{},
+	changed:  make(chan struct{}),
+}
+
+// AddExecutedGTID adds the GTID of a transaction written to a binary log or applied by a replica to @@gtid_executed.
+func AddExecutedGTID(gtid mysql.GTID) {
+	gtids.mu.Lock()
+	defer gtids.mu.Unlock()
+
+	gtids.executed = gtids.executed.AddGTID(gtid).(mysql.Mysql56GTIDSet)
+	close(gtids.changed)
+	gtids.changed = make(chan struct{})
+}
+
+// AddPurgedGTID adds the GTID of a transaction purged from a binary log to @@gtid_purged.
+func AddPurgedGTID(gtid mysql.GTID) {
+	gtids.mu.Lock()
+	defer gtids.mu.Unlock()
+
+	gtids.purged = gtids.purged.AddGTID(gtid).(mysql.Mysql56GTIDSet)
+}
+
+// executedGTIDs returns the value of @@gtid_executed.
+func executedGTIDs() interface{} {
+	gtids.mu.Lock()
+	defer gtids.mu.Unlock()
+	return gtids.executed.String()
+}
+
+// purgedGTIDs returns the value of @@gtid_purged.
+func purgedGTIDs() interface{} {
+	gtids.mu.Lock()
+	defer gtids.mu.Unlock()
+	return gtids.purged.String()
+}
+
+// ParseGTIDSet parses a GTID set in the format of MySQL, such as 3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5:7. It
+// returns ErrInvalidGTIDSet if it's malformed.
+func ParseGTIDSet(s string) (mysql.Mysql56GTIDSet, error) {
+	p, err := mysql.ParsePosition("MySQL56", s)
+	if err != nil {
+		return nil, ErrInvalidGTIDSet.New(s)
+	}
+	return p.GTIDSet.(mysql.Mysql56GTIDSet), nil
+}
+
+// WaitForExecutedGTIDs waits until all the GTIDs of a set are in @@gtid_executed, and returns false if they aren't
+// after the timeout given, or if there's no timeout, until the context is done.
+func WaitForExecutedGTIDs(ctx *Context, set mysql.GTIDSet, timeout time.Duration) (bool, error) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+
+	for {
+		gtids.mu.Lock()
+		executed, changed := gtids.executed.Contains(set), gtids.changed
+		gtids.mu.Unlock()
+		if executed {
+			return true, nil
+		}
+
+		select {
+		case <-changed:
+		case <-expired:
+			return false, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGTIDVariables(t *testing.T) {
+	require := require.New(t)
+
+	sid, err := mysql.ParseSID("3e11fa47-71ca-11e1-9e33-c80aa9429562")
+	require.NoError(err)
+	for i := int64(1); i <= 3; i++ {
+		AddExecutedGTID(mysql.Mysql56GTID{Server: sid, Sequence: i})
+	}
+	AddExecutedGTID(mysql.Mysql56GTID{Server: sid, Sequence: 5})
+	AddPurgedGTID(mysql.Mysql56GTID{Server: sid, Sequence: 1})
+
+	// The GTIDs of the process are the same in all the registries.
+	r := NewSystemVariables()
+	executed, _ := r.Global("gtid_executed")
+	require.Equal("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-3:5", executed)
+	purged, _ := NewSystemVariables().Global("gtid_purged")
+	require.Equal("3e11fa47-71ca-11e1-9e33-c80aa9429562:1", purged)
+
+	require.True(ErrSystemVariableReadOnly.Is(r.SetGlobal("gtid_executed", "")))
+
+	set, err := ParseGTIDSet("3E11FA47-71CA-11E1-9E33-C80AA9429562:2-3,\n3e11fa47-71ca-11e1-9e33-c80aa9429562:5")
+	require.NoError(err)
+	require.True(gtids.executed.Contains(set))
+
+	_, err = ParseGTIDSet("3e11fa47:1")
+	require.True(ErrInvalidGTIDSet.Is(err))
+}
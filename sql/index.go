@@ -60,6 +60,90 @@ type NegateIndex interface {
 	Not(keys ...interface{}) (IndexLookup, error)
 }
 
+// RangeIndex is an index that supports retrieving the keys whose values for its expressions are each within a range,
+// which the analyzer uses to look up the rows matching comparisons on several of its columns at once, such as
+// a = 1 AND b BETWEEN 2 AND 5 on an index on (a, b).
+type RangeIndex interface {
+	Index
+	// Range returns an IndexLookup for keys whose value of every expression of the index is within the range given
+	// for it, in the order of Expressions.
+	Range(ranges ...IndexRange) (IndexLookup, error)
+}
+
+// IndexRange is a range of the values of an index expression. The bounds that are nil don't limit the range.
+type IndexRange struct {
+	Lower, Upper *IndexBound
+}
+
+// IndexBound is a bound of an IndexRange, which includes the value if it's inclusive.
+type IndexBound struct {
+	Value     interface{}
+	Inclusive bool
+}
+
+// DirectionalIndex is an index that orders the values of some of its expressions in descending order, as for the key
+// parts declared DESC in CREATE INDEX. The indexes that don't implement it order all of them in ascending order.
+type DirectionalIndex interface {
+	Index
+	// Descending returns whether the index orders the values of each of its expressions in descending order, in the
+	// order of Expressions.
+	Descending() []bool
+}
+
+// IndexDirections returns whether the index given orders the values of each of its expressions in descending order.
+func IndexDirections(index Index) []bool {
+	if di, ok := index.(DirectionalIndex); ok {
+		return di.Descending()
+	}
+	return make([]bool, len(index.Expressions()))
+}
+
+// PrefixIndex is an index that only keeps a prefix of the values of some of its expressions, as for the key parts with
+// a length in CREATE INDEX, such as name(10). Its lookups can return rows that don't match them, which are filtered
+// out by the predicates they were made for, and it can't return rows in the order of those expressions.
+type PrefixIndex interface {
+	Index
+	// PrefixLengths returns the length of the prefix of the values of each of its expressions that the index keeps,
+	// in characters, or bytes for binary strings, or 0 if it keeps all of them, in the order of Expressions.
+	PrefixLengths() []int64
+}
+
+// IndexPrefixLengths returns the length of the prefix of the values of each of its expressions that the index given
+// keeps, or 0 for those it keeps whole.
+func IndexPrefixLengths(index Index) []int64 {
+	if pi, ok := index.(PrefixIndex); ok {
+		return pi.PrefixLengths()
+	}
+	return make([]int64, len(index.Expressions()))
+}
+
+// InvisibleIndex is an index that can be invisible, as the ones created INVISIBLE, which the analyzer doesn't use unless
+// the use_invisible_indexes flag of optimizer_switch is on. The indexes that don't implement it are visible.
+type InvisibleIndex interface {
+	Index
+	// IsVisible returns whether the index is visible to the analyzer.
+	IsVisible() bool
+}
+
+// IsIndexVisible returns whether the index given is visible to the analyzer.
+func IsIndexVisible(index Index) bool {
+	if ii, ok := index.(InvisibleIndex); ok {
+		return ii.IsVisible()
+	}
+	return true
+}
+
+// OrderedIndex is an index whose lookups can return the rows of its table in the order of its expressions, which the
+// analyzer uses to avoid sorting the rows of a table by them, for ORDER BY and GROUP BY.
+type OrderedIndex interface {
+	Index
+	// Ordered returns an IndexLookup for the rows of the lookup given of this index, or for all the rows of the table if
+	// it's nil, that makes the table return them in the order of the expressions of the index, each ascending or
+	// descending as given by IndexDirections, with NULL values lowest, or in the reverse order if descending, when its
+	// partitions are read one after another. It returns nil if the rows can't be returned in order.
+	Ordered(lookup IndexLookup, descending bool) (IndexLookup, error)
+}
+
 // IndexLookup is the implementation-specific definition of an index lookup, created by calls to Index.Get(). The
 // IndexLookup must contain all necessary information to retrieve exactly the rows in the table specified by key(s)
 // specified in Index.Get(). Implementors are responsible for all semantics of correctly returning rows that match an
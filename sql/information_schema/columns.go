@@ -0,0 +1,174 @@
+package information_schema
+
+import (
+	"strings"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+
+	. "github.com/dolthub/go-mysql-server/sql"
+)
+
+// collatedType is a type with a character set and a collation, such as
+// strings, enums and sets.
+type collatedType interface {
+	CharacterSet() CharacterSet
+	Collation() Collation
+}
+
+// columnType returns the full type of a column as MySQL shows it, in
+// lowercase but for the values of enums and sets, and without its
+// character set and collation.
+func columnType(t Type) string {
+	s := t.String()
+	for _, clause := range []string{" CHARACTER SET ", " COLLATE "} {
+		if i := strings.Index(s, clause); i >= 0 {
+			s = s[:i]
+		}
+	}
+
+	switch t.Type() {
+	case sqltypes.Enum, sqltypes.Set:
+		if i := strings.Index(s, "("); i >= 0 {
+			return strings.ToLower(s[:i]) + s[i:]
+		}
+	}
+	return strings.ToLower(s)
+}
+
+// dataType returns the name of the type of a column, without its length,
+// precision or attributes, such as varchar or bigint.
+func dataType(t Type) string {
+	s := columnType(t)
+	if i := strings.IndexAny(s, "( "); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// characterLengths returns the maximum length of a string column in
+// characters and in bytes, or nil for other columns.
+func characterLengths(t Type) (interface{}, interface{}) {
+	st, ok := t.(StringType)
+	if !ok {
+		return nil, nil
+	}
+	return uint64(st.MaxCharacterLength()), uint64(st.MaxByteLength())
+}
+
+// numericPrecision returns the precision and the scale of a numeric column,
+// or nil for other columns. Floating point numbers have no scale.
+func numericPrecision(t Type) (interface{}, interface{}) {
+	switch t.Type() {
+	case sqltypes.Int8, sqltypes.Uint8:
+		return uint64(3), uint64(0)
+	case sqltypes.Int16, sqltypes.Uint16:
+		return uint64(5), uint64(0)
+	case sqltypes.Int24, sqltypes.Uint24:
+		return uint64(7), uint64(0)
+	case sqltypes.Int32, sqltypes.Uint32:
+		return uint64(10), uint64(0)
+	case sqltypes.Int64:
+		return uint64(19), uint64(0)
+	case sqltypes.Uint64:
+		return uint64(20), uint64(0)
+	case sqltypes.Float32:
+		return uint64(12), nil
+	case sqltypes.Float64:
+		return uint64(22), nil
+	case sqltypes.Decimal:
+		if dt, ok := t.(DecimalType); ok {
+			return uint64(dt.Precision()), uint64(dt.Scale())
+		}
+	case sqltypes.Bit:
+		if bt, ok := t.(BitType); ok {
+			return uint64(bt.NumberOfBits()), nil
+		}
+	}
+	return nil, nil
+}
+
+// datetimePrecision returns the fractional seconds precision of a temporal
+// column, or nil for other columns.
+func datetimePrecision(t Type) interface{} {
+	switch t.Type() {
+	case sqltypes.Datetime, sqltypes.Timestamp, sqltypes.Time:
+		return uint64(0)
+	default:
+		return nil
+	}
+}
+
+// columnDefault returns the default value of a column as MySQL shows it:
+// the value of literals, unquoted, CURRENT_TIMESTAMP for the current time,
+// or the text of expressions.
+func columnDefault(ctx *Context, c *Column) (interface{}, error) {
+	if c.Default == nil {
+		return nil, nil
+	}
+
+	if !c.Default.IsLiteral() {
+		return c.Default.Expression.String(), nil
+	}
+	if isCurrentTimestampDefault(c) {
+		return "CURRENT_TIMESTAMP", nil
+	}
+
+	v, err := c.Default.Eval(ctx, nil)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return LongText.Convert(v)
+}
+
+// columnKey returns whether a column is part of the primary key (PRI), the
+// column of a single column unique key (UNI) or the first column of another
+// index (MUL), in that order of precedence.
+func columnKey(keys []tableKey, column string) string {
+	var key string
+	for _, k := range keys {
+		if len(k.columns) == 0 {
+			continue
+		}
+
+		switch {
+		case k.name == primaryKeyName:
+			for _, c := range k.columns {
+				if strings.EqualFold(c, column) {
+					return "PRI"
+				}
+			}
+		case !strings.EqualFold(k.columns[0], column):
+		case k.unique && len(k.columns) == 1:
+			key = "UNI"
+		case key == "":
+			key = "MUL"
+		}
+	}
+	return key
+}
+
+// columnExtra returns the additional information about a column, such as
+// whether it's auto incremented, its default value is an expression or it's
+// invisible.
+func columnExtra(c *Column) string {
+	var extra string
+	switch {
+	case c.Extra != "":
+		extra = c.Extra
+	case c.AutoIncrement:
+		extra = "auto_increment"
+	case c.Default != nil && (!c.Default.IsLiteral() || isCurrentTimestampDefault(c)):
+		extra = "DEFAULT_GENERATED"
+	}
+	if c.Invisible {
+		extra = strings.TrimSpace(extra + " INVISIBLE")
+	}
+	return extra
+}
+
+// isCurrentTimestampDefault returns whether the default value of a column is
+// the current time, given as NOW() or CURRENT_TIMESTAMP without parentheses.
+func isCurrentTimestampDefault(c *Column) bool {
+	_, ok := c.Default.Expression.(FunctionExpression)
+	return ok && c.Default.IsLiteral()
+}
@@ -48,6 +48,12 @@ const (
 	ViewsTableName = "views"
 	// UserPrivilegesTableName is the name of the user_privileges table
 	UserPrivilegesTableName = "user_privileges"
+	// CheckConstraintsTableName is the name of the check_constraints table.
+	CheckConstraintsTableName = "check_constraints"
+	// PartitionsTableName is the name of the partitions table.
+	PartitionsTableName = "partitions"
+	// ParametersTableName is the name of the parameters table.
+	ParametersTableName = "parameters"
 )
 
 var _ Database = (*informationSchemaDatabase)(nil)
@@ -247,8 +253,8 @@ var keyColumnUsageSchema = Schema{
 	{Name: "table_schema", Type: LongText, Default: nil, Nullable: true, Source: KeyColumnUsageTableName},
 	{Name: "table_name", Type: LongText, Default: nil, Nullable: true, Source: KeyColumnUsageTableName},
 	{Name: "column_name", Type: LongText, Default: nil, Nullable: true, Source: KeyColumnUsageTableName},
-	{Name: "ordinal_position", Type: LongText, Default: nil, Nullable: false, Source: KeyColumnUsageTableName},
-	{Name: "position_in_unique_constraint", Type: LongText, Default: nil, Nullable: true, Source: KeyColumnUsageTableName},
+	{Name: "ordinal_position", Type: Uint32, Default: nil, Nullable: false, Source: KeyColumnUsageTableName},
+	{Name: "position_in_unique_constraint", Type: Uint32, Default: nil, Nullable: true, Source: KeyColumnUsageTableName},
 	{Name: "referenced_table_schema", Type: LongText, Default: nil, Nullable: true, Source: KeyColumnUsageTableName},
 	{Name: "referenced_table_name", Type: LongText, Default: nil, Nullable: true, Source: KeyColumnUsageTableName},
 	{Name: "referenced_column_name", Type: LongText, Default: nil, Nullable: true, Source: KeyColumnUsageTableName},
@@ -360,6 +366,77 @@ var userPrivilegesSchema = Schema{
 	{Name: "is_grantable", Type: LongText, Default: nil, Nullable: false, Source: UserPrivilegesTableName},
 }
 
+var checkConstraintsSchema = Schema{
+	{Name: "constraint_catalog", Type: LongText, Default: nil, Nullable: false, Source: CheckConstraintsTableName},
+	{Name: "constraint_schema", Type: LongText, Default: nil, Nullable: false, Source: CheckConstraintsTableName},
+	{Name: "constraint_name", Type: LongText, Default: nil, Nullable: false, Source: CheckConstraintsTableName},
+	{Name: "check_clause", Type: LongText, Default: nil, Nullable: false, Source: CheckConstraintsTableName},
+}
+
+var partitionsSchema = Schema{
+	{Name: "table_catalog", Type: LongText, Default: nil, Nullable: false, Source: PartitionsTableName},
+	{Name: "table_schema", Type: LongText, Default: nil, Nullable: false, Source: PartitionsTableName},
+	{Name: "table_name", Type: LongText, Default: nil, Nullable: false, Source: PartitionsTableName},
+	{Name: "partition_name", Type: LongText, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "subpartition_name", Type: LongText, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "partition_ordinal_position", Type: Uint32, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "subpartition_ordinal_position", Type: Uint32, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "partition_method", Type: LongText, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "subpartition_method", Type: LongText, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "partition_expression", Type: LongText, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "subpartition_expression", Type: LongText, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "partition_description", Type: LongText, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "table_rows", Type: Uint64, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "avg_row_length", Type: Uint64, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "data_length", Type: Uint64, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "max_data_length", Type: Uint64, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "index_length", Type: Uint64, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "data_free", Type: Uint64, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "create_time", Type: Timestamp, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "update_time", Type: Timestamp, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "check_time", Type: Timestamp, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "checksum", Type: Int64, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "partition_comment", Type: LongText, Default: nil, Nullable: false, Source: PartitionsTableName},
+	{Name: "nodegroup", Type: LongText, Default: nil, Nullable: true, Source: PartitionsTableName},
+	{Name: "tablespace_name", Type: LongText, Default: nil, Nullable: true, Source: PartitionsTableName},
+}
+
+var parametersSchema = Schema{
+	{Name: "specific_catalog", Type: LongText, Default: nil, Nullable: false, Source: ParametersTableName},
+	{Name: "specific_schema", Type: LongText, Default: nil, Nullable: false, Source: ParametersTableName},
+	{Name: "specific_name", Type: LongText, Default: nil, Nullable: false, Source: ParametersTableName},
+	{Name: "ordinal_position", Type: Uint64, Default: nil, Nullable: false, Source: ParametersTableName},
+	{Name: "parameter_mode", Type: LongText, Default: nil, Nullable: true, Source: ParametersTableName},
+	{Name: "parameter_name", Type: LongText, Default: nil, Nullable: true, Source: ParametersTableName},
+	{Name: "data_type", Type: LongText, Default: nil, Nullable: true, Source: ParametersTableName},
+	{Name: "character_maximum_length", Type: Int64, Default: nil, Nullable: true, Source: ParametersTableName},
+	{Name: "character_octet_length", Type: Int64, Default: nil, Nullable: true, Source: ParametersTableName},
+	{Name: "numeric_precision", Type: Uint32, Default: nil, Nullable: true, Source: ParametersTableName},
+	{Name: "numeric_scale", Type: Int64, Default: nil, Nullable: true, Source: ParametersTableName},
+	{Name: "datetime_precision", Type: Uint32, Default: nil, Nullable: true, Source: ParametersTableName},
+	{Name: "character_set_name", Type: LongText, Default: nil, Nullable: true, Source: ParametersTableName},
+	{Name: "collation_name", Type: LongText, Default: nil, Nullable: true, Source: ParametersTableName},
+	{Name: "dtd_identifier", Type: LongText, Default: nil, Nullable: false, Source: ParametersTableName},
+	{Name: "routine_type", Type: LongText, Default: nil, Nullable: false, Source: ParametersTableName},
+}
+
+// createOptions returns the options of a table that information_schema.TABLES doesn't report in columns of their
+// own, as MySQL does, or nil if there are none.
+func createOptions(options TableOptions) interface{} {
+	var createOptions []string
+	for _, option := range options {
+		switch option.Name {
+		case TableOption_Engine, TableOption_AutoIncrement, TableOption_CharacterSet, TableOption_Collate, TableOption_Comment:
+		default:
+			createOptions = append(createOptions, strings.ToLower(option.Name)+"="+option.Value)
+		}
+	}
+	if len(createOptions) == 0 {
+		return nil
+	}
+	return strings.Join(createOptions, " ")
+}
+
 func tablesRowIter(ctx *Context, cat *Catalog) (RowIter, error) {
 	var rows []Row
 	for _, db := range cat.AllDatabases() {
@@ -376,28 +453,54 @@ func tablesRowIter(ctx *Context, cat *Catalog) (RowIter, error) {
 		err := DBTableIter(ctx, db, func(t Table) (cont bool, err error) {
 
 			autoVal := getAutoIncrementValue(ctx, t)
+			var tableRows, avgRowLength, dataLength interface{}
+			stats, err := GetTableStatistics(ctx, t)
+			if err != nil {
+				return false, err
+			}
+			if stats != nil {
+				tableRows, avgRowLength, dataLength = stats.RowCount, stats.AvgRowSize, stats.DataLength()
+			}
+
+			options := plan.GetTableOptions(t)
+			tableEngine, ok := options.Get(TableOption_Engine)
+			if !ok {
+				tableEngine = engine
+			}
+			tableRowFormat, ok := options.Get(TableOption_RowFormat)
+			if ok {
+				tableRowFormat = strings.Title(strings.ToLower(tableRowFormat))
+			} else {
+				tableRowFormat = rowFormat
+			}
+			collation, ok := options.Get(TableOption_Collate)
+			if !ok {
+				collation = Collation_Default.String()
+			}
+			comment, _ := options.Get(TableOption_Comment)
+
 			rows = append(rows, Row{
-				"def",                      // table_catalog
-				db.Name(),                  // table_schema
-				t.Name(),                   // table_name
-				tableType,                  // table_type
-				engine,                     // engine
-				10,                         // version (protocol, always 10)
-				rowFormat,                  // row_format
-				nil,                        // table_rows
-				nil,                        // avg_row_length
-				nil,                        // data_length
-				nil,                        // max_data_length
-				nil,                        // max_data_length
-				nil,                        // data_free
-				autoVal,                    // auto_increment
-				y2k,                        // create_time
-				y2k,                        // update_time
-				nil,                        // check_time
-				Collation_Default.String(), // table_collation
-				nil,                        // checksum
-				nil,                        // create_options
-				"",                         // table_comment
+				"def",                  // table_catalog
+				db.Name(),              // table_schema
+				t.Name(),               // table_name
+				tableType,              // table_type
+				tableEngine,            // engine
+				10,                     // version (protocol, always 10)
+				tableRowFormat,         // row_format
+				tableRows,              // table_rows
+				avgRowLength,           // avg_row_length
+				dataLength,             // data_length
+				nil,                    // max_data_length
+				nil,                    // max_data_length
+				nil,                    // data_free
+				autoVal,                // auto_increment
+				y2k,                    // create_time
+				y2k,                    // update_time
+				nil,                    // check_time
+				collation,              // table_collation
+				nil,                    // checksum
+				createOptions(options), // create_options
+				comment,                // table_comment
 			})
 
 			return true, nil
@@ -441,6 +544,11 @@ func columnsRowIter(ctx *Context, cat *Catalog) (RowIter, error) {
 	var rows []Row
 	for _, db := range cat.AllDatabases() {
 		err := DBTableIter(ctx, db, func(t Table) (cont bool, err error) {
+			keys, err := tableKeys(ctx, t)
+			if err != nil {
+				return false, err
+			}
+
 			for i, c := range t.Schema() {
 				var (
 					nullable string
@@ -452,32 +560,40 @@ func columnsRowIter(ctx *Context, cat *Catalog) (RowIter, error) {
 				} else {
 					nullable = "NO"
 				}
-				if IsText(c.Type) {
-					charName = Collation_Default.CharacterSet().String()
-					collName = Collation_Default.String()
+				if cs, ok := c.Type.(collatedType); ok && cs.CharacterSet() != CharacterSet_binary {
+					charName = cs.CharacterSet().String()
+					collName = cs.Collation().String()
 				}
+
+				columnDefault, err := columnDefault(ctx, c)
+				if err != nil {
+					return false, err
+				}
+
+				charLength, octetLength := characterLengths(c.Type)
+				precision, scale := numericPrecision(c.Type)
 				rows = append(rows, Row{
-					"def",                            // table_catalog
-					db.Name(),                        // table_schema
-					t.Name(),                         // table_name
-					c.Name,                           // column_name
-					uint64(i),                        // ordinal_position
-					c.Default.String(),               // column_default
-					nullable,                         // is_nullable
-					strings.ToLower(c.Type.String()), // data_type
-					nil,                              // character_maximum_length
-					nil,                              // character_octet_length
-					nil,                              // numeric_precision
-					nil,                              // numeric_scale
-					nil,                              // datetime_precision
-					charName,                         // character_set_name
-					collName,                         // collation_name
-					strings.ToLower(c.Type.String()), // column_type
-					"",                               // column_key
-					c.Extra,                          // extra
-					"select",                         // privileges
-					c.Comment,                        // column_comment
-					"",                               // generation_expression
+					"def",                     // table_catalog
+					db.Name(),                 // table_schema
+					t.Name(),                  // table_name
+					c.Name,                    // column_name
+					uint64(i + 1),             // ordinal_position
+					columnDefault,             // column_default
+					nullable,                  // is_nullable
+					dataType(c.Type),          // data_type
+					charLength,                // character_maximum_length
+					octetLength,               // character_octet_length
+					precision,                 // numeric_precision
+					scale,                     // numeric_scale
+					datetimePrecision(c.Type), // datetime_precision
+					charName,                  // character_set_name
+					collName,                  // collation_name
+					columnType(c.Type),        // column_type
+					columnKey(keys, c.Name),   // column_key
+					columnExtra(c),            // extra
+					"select",                  // privileges
+					c.Comment,                 // column_comment
+					"",                        // generation_expression
 				})
 			}
 			return true, nil
@@ -495,11 +611,12 @@ func schemataRowIter(ctx *Context, c *Catalog) (RowIter, error) {
 
 	var rows []Row
 	for _, db := range dbs {
+		collation := GetDatabaseCollation(db)
 		rows = append(rows, Row{
 			"def",
 			db.Name(),
-			Collation_Default.CharacterSet().String(),
-			Collation_Default.String(),
+			collation.CharacterSet().String(),
+			collation.String(),
 			nil,
 		})
 	}
@@ -613,6 +730,49 @@ func triggersRowIter(ctx *Context, c *Catalog) (RowIter, error) {
 	return RowsToRowIter(rows...), nil
 }
 
+func partitionsRowIter(ctx *Context, cat *Catalog) (RowIter, error) {
+	var rows []Row
+	for _, db := range cat.AllDatabases() {
+		// Tables aren't partitioned, so each one has a single row without a
+		// partition name, as in MySQL.
+		err := DBTableIter(ctx, db, func(t Table) (cont bool, err error) {
+			rows = append(rows, Row{
+				"def",     // table_catalog
+				db.Name(), // table_schema
+				t.Name(),  // table_name
+				nil,       // partition_name
+				nil,       // subpartition_name
+				nil,       // partition_ordinal_position
+				nil,       // subpartition_ordinal_position
+				nil,       // partition_method
+				nil,       // subpartition_method
+				nil,       // partition_expression
+				nil,       // subpartition_expression
+				nil,       // partition_description
+				nil,       // table_rows
+				nil,       // avg_row_length
+				nil,       // data_length
+				nil,       // max_data_length
+				nil,       // index_length
+				nil,       // data_free
+				nil,       // create_time
+				nil,       // update_time
+				nil,       // check_time
+				nil,       // checksum
+				"",        // partition_comment
+				nil,       // nodegroup
+				nil,       // tablespace_name
+			})
+			return true, nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return RowsToRowIter(rows...), nil
+}
+
 func emptyRowIter(ctx *Context, c *Catalog) (RowIter, error) {
 	return RowsToRowIter(), nil
 }
@@ -660,25 +820,25 @@ func NewInformationSchemaDatabase(cat *Catalog) Database {
 				name:    StatisticsTableName,
 				schema:  statisticsSchema,
 				catalog: cat,
-				rowIter: emptyRowIter,
+				rowIter: statisticsRowIter,
 			},
 			TableConstraintsTableName: &informationSchemaTable{
 				name:    TableConstraintsTableName,
 				schema:  tableConstraintsSchema,
 				catalog: cat,
-				rowIter: emptyRowIter,
+				rowIter: tableConstraintsRowIter,
 			},
 			ReferentialConstraintsTableName: &informationSchemaTable{
 				name:    ReferentialConstraintsTableName,
 				schema:  referentialConstraintsSchema,
 				catalog: cat,
-				rowIter: emptyRowIter,
+				rowIter: referentialConstraintsRowIter,
 			},
 			KeyColumnUsageTableName: &informationSchemaTable{
 				name:    KeyColumnUsageTableName,
 				schema:  keyColumnUsageSchema,
 				catalog: cat,
-				rowIter: emptyRowIter,
+				rowIter: keyColumnUsageRowIter,
 			},
 			TriggersTableName: &informationSchemaTable{
 				name:    TriggersTableName,
@@ -710,6 +870,25 @@ func NewInformationSchemaDatabase(cat *Catalog) Database {
 				catalog: cat,
 				rowIter: emptyRowIter,
 			},
+			CheckConstraintsTableName: &informationSchemaTable{
+				name:    CheckConstraintsTableName,
+				schema:  checkConstraintsSchema,
+				catalog: cat,
+				rowIter: checkConstraintsRowIter,
+			},
+			// There are no stored routines, so this table is always empty.
+			ParametersTableName: &informationSchemaTable{
+				name:    ParametersTableName,
+				schema:  parametersSchema,
+				catalog: cat,
+				rowIter: emptyRowIter,
+			},
+			PartitionsTableName: &informationSchemaTable{
+				name:    PartitionsTableName,
+				schema:  partitionsSchema,
+				catalog: cat,
+				rowIter: partitionsRowIter,
+			},
 		},
 	}
 }
@@ -725,7 +904,7 @@ func viewRowIter(context *Context, catalog *Catalog) (RowIter, error) {
 				view.Name(),
 				view.TextDefinition(),
 				"NONE",
-				"YES",
+				isUpdatable(view.Definition()),
 				"",
 				"DEFINER",
 				Collation_Default.CharacterSet().String(),
@@ -736,6 +915,25 @@ func viewRowIter(context *Context, catalog *Catalog) (RowIter, error) {
 	return RowsToRowIter(rows...), nil
 }
 
+// isUpdatable returns whether rows can be inserted, updated or deleted
+// through a view, which they can't if it aggregates or combines rows.
+func isUpdatable(definition Node) string {
+	updatable := "YES"
+	if definition == nil {
+		return updatable
+	}
+
+	plan.Inspect(definition, func(n Node) bool {
+		switch n.(type) {
+		case *plan.GroupBy, *plan.Having, *plan.Distinct, *plan.OrderedDistinct, *plan.Union,
+			*plan.CrossJoin, *plan.InnerJoin, *plan.LeftJoin, *plan.RightJoin, *plan.NaturalJoin:
+			updatable = "NO"
+		}
+		return updatable == "YES"
+	})
+	return updatable
+}
+
 // Name implements the sql.Database interface.
 func (db *informationSchemaDatabase) Name() string { return db.name }
 
@@ -831,8 +1029,10 @@ func partitionKey(tableName string) []byte {
 func getAutoIncrementValue(ctx *Context, t Table) (val interface{}) {
 	for _, c := range t.Schema() {
 		if c.AutoIncrement {
-			val, _ = t.(AutoIncrementTable).GetAutoIncrementValue(ctx)
-			// ignore errors
+			if autoTbl, ok := t.(AutoIncrementTable); ok {
+				val, _ = autoTbl.GetAutoIncrementValue(ctx)
+				// ignore errors
+			}
 			break
 		}
 	}
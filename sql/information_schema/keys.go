@@ -0,0 +1,472 @@
+package information_schema
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// primaryKeyName is the name of the primary key of tables, as an index and
+// as a constraint.
+const primaryKeyName = "PRIMARY"
+
+// tableKey is an index of a table: its primary key, one of its unique keys
+// or a non unique index.
+type tableKey struct {
+	name      string
+	unique    bool
+	indexType string
+	comment   string
+	// columns are the names of the indexed columns, or empty for the
+	// expressions that aren't columns.
+	columns []string
+	// expressions are the indexed expressions.
+	expressions []string
+	// descending is whether the key orders the values of each expression in
+	// descending order.
+	descending []bool
+	// prefixLengths are the lengths of the prefixes of the values of each
+	// expression that the key keeps, or 0 if it keeps all of them.
+	prefixLengths []int64
+	// visible is whether the key is visible to the optimizer.
+	visible bool
+}
+
+// tableKeys returns the keys of a table, the primary key first.
+func tableKeys(ctx *Context, t Table) ([]tableKey, error) {
+	var keys []tableKey
+
+	var pk []string
+	var pkDesc []bool
+	for _, c := range t.Schema() {
+		if c.PrimaryKey {
+			pk = append(pk, c.Name)
+			pkDesc = append(pkDesc, c.PrimaryKeyDescending)
+		}
+	}
+	if len(pk) > 0 {
+		keys = append(keys, tableKey{
+			name:          primaryKeyName,
+			unique:        true,
+			indexType:     "BTREE",
+			columns:       pk,
+			expressions:   pk,
+			descending:    pkDesc,
+			prefixLengths: make([]int64, len(pk)),
+			visible:       true,
+		})
+	}
+
+	indexed, ok := t.(IndexedTable)
+	if !ok {
+		return keys, nil
+	}
+
+	indexes, err := indexed.GetIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, idx := range indexes {
+		if idx.ID() == primaryKeyName {
+			continue
+		}
+
+		key := tableKey{
+			name:          idx.ID(),
+			unique:        idx.IsUnique(),
+			indexType:     idx.IndexType(),
+			comment:       idx.Comment(),
+			expressions:   idx.Expressions(),
+			descending:    IndexDirections(idx),
+			prefixLengths: IndexPrefixLengths(idx),
+			visible:       IsIndexVisible(idx),
+		}
+		for _, expr := range idx.Expressions() {
+			var name string
+			if col := plan.GetColumnFromIndexExpr(expr, t); col != nil {
+				name = col.Name
+			}
+			key.columns = append(key.columns, name)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// foreignKeys returns the foreign keys of a table, if it can declare them.
+func foreignKeys(ctx *Context, t Table) ([]ForeignKeyConstraint, error) {
+	fkTable, ok := t.(ForeignKeyTable)
+	if !ok {
+		return nil, nil
+	}
+	return fkTable.GetForeignKeys(ctx)
+}
+
+// referencedSchema returns the name of the database of the table referenced by a foreign key declared by a table of
+// the database given.
+func referencedSchema(db Database, fk ForeignKeyConstraint) string {
+	if fk.ReferencedDatabase != "" {
+		return fk.ReferencedDatabase
+	}
+	return db.Name()
+}
+
+// checks returns the CHECK constraints of a table, which has none if it
+// isn't a CheckTable.
+func checks(ctx *Context, t Table) ([]CheckDefinition, error) {
+	checkTable := plan.GetCheckTable(t)
+	if checkTable == nil {
+		return nil, nil
+	}
+	return checkTable.GetChecks(ctx)
+}
+
+// uniqueConstraint returns the name of the unique key of a table whose
+// columns are the ones given, or nil if there is none.
+func uniqueConstraint(keys []tableKey, columns []string) interface{} {
+	for _, key := range keys {
+		if key.unique && equalColumns(key.columns, columns) {
+			return key.name
+		}
+	}
+	return nil
+}
+
+// schemaColumn returns the column of a schema with the name given, or nil.
+func schemaColumn(schema Schema, name string) *Column {
+	for _, c := range schema {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+func equalColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// referenceRule returns the rule of a foreign key for updates or deletes,
+// which is NO ACTION if none was given.
+func referenceRule(option ForeignKeyReferenceOption) string {
+	if option == "" || option == ForeignKeyReferenceOption_DefaultAction {
+		return string(ForeignKeyReferenceOption_NoAction)
+	}
+	return string(option)
+}
+
+func statisticsRowIter(ctx *Context, cat *Catalog) (RowIter, error) {
+	var rows []Row
+	for _, db := range cat.AllDatabases() {
+		err := DBTableIter(ctx, db, func(t Table) (cont bool, err error) {
+			keys, err := tableKeys(ctx, t)
+			if err != nil {
+				return false, err
+			}
+			stats, err := GetTableStatistics(ctx, t)
+			if err != nil {
+				return false, err
+			}
+
+			for _, key := range keys {
+				cardinality := int64(0)
+				if stats != nil {
+					cardinality = int64(stats.IndexCardinality[key.name])
+				}
+
+				nonUnique := int64(1)
+				if key.unique {
+					nonUnique = 0
+				}
+
+				visible := "YES"
+				if !key.visible {
+					visible = "NO"
+				}
+
+				for i, column := range key.columns {
+					var columnName, expression interface{}
+					nullable := ""
+					collation := "A"
+					if key.descending[i] {
+						collation = "D"
+					}
+					var subPart interface{}
+					if key.prefixLengths[i] != 0 {
+						subPart = key.prefixLengths[i]
+					}
+					if column == "" {
+						expression = key.expressions[i]
+					} else {
+						columnName = column
+						if c := schemaColumn(t.Schema(), column); c != nil && c.Nullable {
+							nullable = "YES"
+						}
+					}
+
+					rows = append(rows, Row{
+						"def",         // table_catalog
+						db.Name(),     // table_schema
+						t.Name(),      // table_name
+						nonUnique,     // non_unique
+						db.Name(),     // index_schema
+						key.name,      // index_name
+						int64(i + 1),  // seq_in_index
+						columnName,    // column_name
+						collation,     // collation
+						cardinality,   // cardinality
+						subPart,       // sub_part
+						nil,           // packed
+						nullable,      // nullable
+						key.indexType, // index_type
+						"",            // comment
+						key.comment,   // index_comment
+						visible,       // is_visible
+						expression,    // expression
+					})
+				}
+			}
+			return true, nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return RowsToRowIter(rows...), nil
+}
+
+func tableConstraintsRowIter(ctx *Context, cat *Catalog) (RowIter, error) {
+	var rows []Row
+	for _, db := range cat.AllDatabases() {
+		err := DBTableIter(ctx, db, func(t Table) (cont bool, err error) {
+			keys, err := tableKeys(ctx, t)
+			if err != nil {
+				return false, err
+			}
+
+			for _, key := range keys {
+				if !key.unique {
+					continue
+				}
+
+				constraintType := "UNIQUE"
+				if key.name == primaryKeyName {
+					constraintType = "PRIMARY KEY"
+				}
+				rows = append(rows, Row{
+					"def",          // constraint_catalog
+					db.Name(),      // constraint_schema
+					key.name,       // constraint_name
+					db.Name(),      // table_schema
+					t.Name(),       // table_name
+					constraintType, // constraint_type
+					"YES",          // enforced
+				})
+			}
+
+			fks, err := foreignKeys(ctx, t)
+			if err != nil {
+				return false, err
+			}
+
+			for _, fk := range fks {
+				rows = append(rows, Row{
+					"def",         // constraint_catalog
+					db.Name(),     // constraint_schema
+					fk.Name,       // constraint_name
+					db.Name(),     // table_schema
+					t.Name(),      // table_name
+					"FOREIGN KEY", // constraint_type
+					"YES",         // enforced
+				})
+			}
+
+			checks, err := checks(ctx, t)
+			if err != nil {
+				return false, err
+			}
+
+			for _, check := range checks {
+				enforced := "YES"
+				if !check.Enforced {
+					enforced = "NO"
+				}
+				rows = append(rows, Row{
+					"def",      // constraint_catalog
+					db.Name(),  // constraint_schema
+					check.Name, // constraint_name
+					db.Name(),  // table_schema
+					t.Name(),   // table_name
+					"CHECK",    // constraint_type
+					enforced,   // enforced
+				})
+			}
+			return true, nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return RowsToRowIter(rows...), nil
+}
+
+func checkConstraintsRowIter(ctx *Context, cat *Catalog) (RowIter, error) {
+	var rows []Row
+	for _, db := range cat.AllDatabases() {
+		err := DBTableIter(ctx, db, func(t Table) (cont bool, err error) {
+			checks, err := checks(ctx, t)
+			if err != nil {
+				return false, err
+			}
+
+			for _, check := range checks {
+				rows = append(rows, Row{
+					"def",      // constraint_catalog
+					db.Name(),  // constraint_schema
+					check.Name, // constraint_name
+					fmt.Sprintf("(%s)", check.CheckExpression), // check_clause
+				})
+			}
+			return true, nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return RowsToRowIter(rows...), nil
+}
+
+func keyColumnUsageRowIter(ctx *Context, cat *Catalog) (RowIter, error) {
+	var rows []Row
+	for _, db := range cat.AllDatabases() {
+		err := DBTableIter(ctx, db, func(t Table) (cont bool, err error) {
+			keys, err := tableKeys(ctx, t)
+			if err != nil {
+				return false, err
+			}
+
+			for _, key := range keys {
+				if !key.unique {
+					continue
+				}
+
+				for i, column := range key.columns {
+					if column == "" {
+						continue
+					}
+					rows = append(rows, Row{
+						"def",         // constraint_catalog
+						db.Name(),     // constraint_schema
+						key.name,      // constraint_name
+						"def",         // table_catalog
+						db.Name(),     // table_schema
+						t.Name(),      // table_name
+						column,        // column_name
+						uint32(i + 1), // ordinal_position
+						nil,           // position_in_unique_constraint
+						nil,           // referenced_table_schema
+						nil,           // referenced_table_name
+						nil,           // referenced_column_name
+					})
+				}
+			}
+
+			fks, err := foreignKeys(ctx, t)
+			if err != nil {
+				return false, err
+			}
+
+			for _, fk := range fks {
+				for i, column := range fk.Columns {
+					var referencedColumn interface{}
+					if i < len(fk.ReferencedColumns) {
+						referencedColumn = fk.ReferencedColumns[i]
+					}
+					rows = append(rows, Row{
+						"def",                    // constraint_catalog
+						db.Name(),                // constraint_schema
+						fk.Name,                  // constraint_name
+						"def",                    // table_catalog
+						db.Name(),                // table_schema
+						t.Name(),                 // table_name
+						column,                   // column_name
+						uint32(i + 1),            // ordinal_position
+						uint32(i + 1),            // position_in_unique_constraint
+						referencedSchema(db, fk), // referenced_table_schema
+						fk.ReferencedTable,       // referenced_table_name
+						referencedColumn,         // referenced_column_name
+					})
+				}
+			}
+			return true, nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return RowsToRowIter(rows...), nil
+}
+
+func referentialConstraintsRowIter(ctx *Context, cat *Catalog) (RowIter, error) {
+	var rows []Row
+	for _, db := range cat.AllDatabases() {
+		err := DBTableIter(ctx, db, func(t Table) (cont bool, err error) {
+			fks, err := foreignKeys(ctx, t)
+			if err != nil {
+				return false, err
+			}
+
+			for _, fk := range fks {
+				var uniqueName interface{}
+				refSchema := referencedSchema(db, fk)
+				referenced, err := cat.Table(ctx, refSchema, fk.ReferencedTable)
+				if err != nil && !ErrDatabaseNotFound.Is(err) && !ErrTableNotFound.Is(err) {
+					return false, err
+				}
+				if err == nil {
+					keys, err := tableKeys(ctx, referenced)
+					if err != nil {
+						return false, err
+					}
+					uniqueName = uniqueConstraint(keys, fk.ReferencedColumns)
+				}
+
+				rows = append(rows, Row{
+					"def",                      // constraint_catalog
+					db.Name(),                  // constraint_schema
+					fk.Name,                    // constraint_name
+					"def",                      // unique_constraint_catalog
+					refSchema,                  // unique_constraint_schema
+					uniqueName,                 // unique_constraint_name
+					"NONE",                     // match_option
+					referenceRule(fk.OnUpdate), // update_rule
+					referenceRule(fk.OnDelete), // delete_rule
+					t.Name(),                   // table_name
+					fk.ReferencedTable,         // referenced_table_name
+				})
+			}
+			return true, nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return RowsToRowIter(rows...), nil
+}
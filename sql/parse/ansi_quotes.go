@@ -0,0 +1,75 @@
+package parse
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// normalizeQuotes rewrites the quoted strings and identifiers of the query given as the SQL parser reads them by default,
+// if sql_mode has modes that change how they're read. With ANSI_QUOTES, double quotes delimit identifiers rather than
+// strings, and they're replaced by backticks. With NO_BACKSLASH_ESCAPES, backslashes in strings aren't escapes, and
+// they're escaped for the parser to keep them. The query must not have comments.
+func normalizeQuotes(ctx *sql.Context, query string) string {
+	ansiQuotes := sql.SqlModeEnabled(ctx, sql.SqlMode_AnsiQuotes)
+	noBackslashEscapes := sql.SqlModeEnabled(ctx, sql.SqlMode_NoBackslashEscapes)
+	if !ansiQuotes && !noBackslashEscapes {
+		return query
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(query); i++ {
+		quote := query[i]
+		if quote != '\'' && quote != '"' && quote != '`' {
+			sb.WriteByte(quote)
+			continue
+		}
+
+		identifier := quote == '`' || (quote == '"' && ansiQuotes)
+		end := closingQuoteInMode(query, i, identifier || noBackslashEscapes)
+		if end == i || query[end] != quote {
+			// The parser fails on the unclosed quote
+			sb.WriteString(query[i:])
+			break
+		}
+		quoted := query[i : end+1]
+
+		switch {
+		case quote == '"' && ansiQuotes:
+			name := strings.ReplaceAll(query[i+1:end], `""`, `"`)
+			sb.WriteString(quoteIdentifier(name))
+		case !identifier && noBackslashEscapes:
+			sb.WriteString(strings.ReplaceAll(quoted, `\`, `\\`))
+		default:
+			sb.WriteString(quoted)
+		}
+		i = end
+	}
+	return sb.String()
+}
+
+// closingQuoteInMode returns the index of the quote closing the string or identifier of the string given whose opening
+// quote is at the index given, where backslashes are escapes unless literalBackslashes is true. It returns the index
+// of the last character of the string given if it isn't closed.
+func closingQuoteInMode(s string, start int, literalBackslashes bool) int {
+	if !literalBackslashes {
+		return closingQuote(s, start)
+	}
+
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		if s[i] == quote {
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(s) - 1
+}
+
+// quoteIdentifier returns the identifier given quoted with backticks.
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
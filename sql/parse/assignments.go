@@ -0,0 +1,175 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// userVarAssignmentFunc is the function user variable assignments, as in
+// @var := expr, are rewritten to before parsing, since the parser doesn't
+// support the := operator. It's converted to a UserVarAssignment expression.
+const userVarAssignmentFunc = "gms_user_var_assignment"
+
+// assignmentEndWords are the keywords ending the expression assigned to a
+// user variable, which takes everything up to them since := has the lowest
+// precedence of all operators.
+var assignmentEndWords = map[string]bool{
+	"from": true, "where": true, "group": true, "having": true, "order": true, "limit": true, "into": true,
+	"union": true, "for": true, "as": true, "on": true, "using": true, "window": true, "lock": true,
+	"when": true, "then": true, "else": true, "end": true,
+}
+
+// fixUserVarAssignments rewrites the := assignments of a query so that the
+// parser can parse them. In the SET statements, the assignments of the
+// variables set are the same as using =, and the rest are rewritten to calls
+// to userVarAssignmentFunc with the name of the variable and the expression
+// assigned. Assignments are rewritten from the last one, so that the ones in
+// the expressions of others are rewritten first.
+func fixUserVarAssignments(s string, set bool) string {
+	for {
+		positions := unquotedIndexes(s, ":=")
+		if len(positions) == 0 {
+			return s
+		}
+		i := positions[len(positions)-1]
+
+		start, name := userVarBefore(s, i)
+		if start < 0 || isSetTarget(s, start, set) {
+			s = s[:i] + "=" + s[i+2:]
+			continue
+		}
+
+		end := assignmentEnd(s, i+2)
+		expr := strings.TrimRightFunc(s[i+2:end], unicode.IsSpace)
+		s = fmt.Sprintf("%s%s('%s', %s)%s", s[:start], userVarAssignmentFunc, name, strings.TrimSpace(expr), s[i+2+len(expr):])
+	}
+}
+
+// unquotedIndexes returns the positions of a string in a query, except for
+// the ones in quoted strings and identifiers.
+func unquotedIndexes(s, substr string) []int {
+	var positions []int
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0 && c == '\\' && quote != '`':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case strings.HasPrefix(s[i:], substr):
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+// userVarBefore returns the position and the name of the user variable
+// before the position given, ignoring spaces, or -1 if there is none.
+func userVarBefore(s string, pos int) (int, string) {
+	end := len(strings.TrimRightFunc(s[:pos], unicode.IsSpace))
+	start := end
+	for start > 0 && isUserVarRune(s[start-1]) {
+		start--
+	}
+
+	if start == end || start == 0 || s[start-1] != '@' || (start > 1 && s[start-2] == '@') {
+		return -1, ""
+	}
+	return start - 1, s[start:end]
+}
+
+func isUserVarRune(c byte) bool {
+	return c == '_' || c == '$' || c == '.' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// isSetTarget returns whether the variable in the position given is one of
+// the variables a SET statement sets, instead of one assigned in an
+// expression. Other than in SET queries, only the first variable of the SET
+// statements in them, such as in the ones of triggers, is considered.
+func isSetTarget(s string, pos int, set bool) bool {
+	before := strings.ToLower(strings.TrimRightFunc(s[:pos], unicode.IsSpace))
+	if set && strings.HasSuffix(before, ",") {
+		return strings.Count(before, "(") == strings.Count(before, ")")
+	}
+	return strings.HasSuffix(before, "set") && (len(before) == 3 || !isUserVarRune(before[len(before)-4]))
+}
+
+// assignmentEnd returns the position where the expression assigned to a
+// variable starting in the position given ends: at the end of the query, at
+// a comma or a parenthesis closing an enclosing one, or at a keyword ending
+// it other than the ones of a CASE in the expression.
+func assignmentEnd(s string, pos int) int {
+	var depth, cases int
+	var quote byte
+	for i := pos; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0 && c == '\\' && quote != '`':
+			i++
+			continue
+		case quote != 0 && c == quote:
+			quote = 0
+			continue
+		case quote != 0:
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			continue
+		case c == '(':
+			depth++
+			continue
+		case c == ')' && depth == 0, c == ',' && depth == 0:
+			return i
+		case c == ')':
+			depth--
+			continue
+		case depth > 0 || !isUserVarRune(c) || (i > pos && isUserVarRune(s[i-1])):
+			continue
+		}
+
+		word := i
+		for word < len(s) && isUserVarRune(s[word]) {
+			word++
+		}
+
+		// Variables can have the names of keywords.
+		if i > 0 && s[i-1] == '@' {
+			i = word - 1
+			continue
+		}
+
+		switch w := strings.ToLower(s[i:word]); {
+		case w == "case":
+			cases++
+		case w == "end" && cases > 0:
+			cases--
+		case assignmentEndWords[w] && cases == 0:
+			return i
+		}
+		i = word - 1
+	}
+	return len(s)
+}
+
+// convertUserVarAssignment converts the arguments of a call to
+// userVarAssignmentFunc to the assignment it was rewritten from.
+func convertUserVarAssignment(args []sql.Expression) (sql.Expression, error) {
+	if len(args) != 2 {
+		return nil, ErrUnsupportedSyntax.New(userVarAssignmentFunc)
+	}
+
+	name, ok := args[0].(*expression.Literal)
+	if !ok || !sql.IsText(name.Type()) {
+		return nil, ErrUnsupportedSyntax.New(userVarAssignmentFunc)
+	}
+
+	return expression.NewUserVarAssignment(expression.NewUserVar(name.Value().(string)), args[1]), nil
+}
@@ -0,0 +1,83 @@
+package parse
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// parseShowBinlogEvents parses SHOW BINLOG EVENTS [IN 'log_name'] [FROM pos] [LIMIT [offset,] row_count].
+func parseShowBinlogEvents(s string) (sql.Node, error) {
+	var in, from, offset, limit string
+	var hasIn, hasFrom, hasLimit, hasOffset bool
+
+	r := bufio.NewReader(strings.NewReader(s))
+	err := parseFuncs{
+		expect("show"),
+		skipSpaces,
+		expect("binlog"),
+		skipSpaces,
+		expect("events"),
+		skipSpaces,
+		maybe(&hasIn, "in"),
+		func(rd *bufio.Reader) error {
+			if !hasIn {
+				return nil
+			}
+			return parseFuncs{skipSpaces, readQuotedString(&in), skipSpaces}.exec(rd)
+		},
+		maybe(&hasFrom, "from"),
+		func(rd *bufio.Reader) error {
+			if !hasFrom {
+				return nil
+			}
+			return parseFuncs{skipSpaces, readDigits(&from), skipSpaces}.exec(rd)
+		},
+		maybe(&hasLimit, "limit"),
+		func(rd *bufio.Reader) error {
+			if !hasLimit {
+				return nil
+			}
+			err := parseFuncs{skipSpaces, readDigits(&limit), skipSpaces, maybe(&hasOffset, ",")}.exec(rd)
+			if err != nil || !hasOffset {
+				return err
+			}
+			offset = limit
+			return parseFuncs{skipSpaces, readDigits(&limit), skipSpaces}.exec(rd)
+		},
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	pos, err := parseBinlogNumber("FROM position", from, hasFrom, 0)
+	if err != nil {
+		return nil, err
+	}
+	off, err := parseBinlogNumber("LIMIT offset", offset, hasOffset, 0)
+	if err != nil {
+		return nil, err
+	}
+	count, err := parseBinlogNumber("LIMIT row count", limit, hasLimit, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewShowBinlogEvents(in, uint64(pos), off, count), nil
+}
+
+// parseBinlogNumber parses a number of SHOW BINLOG EVENTS, which is the default given if the clause it's in is
+// missing.
+func parseBinlogNumber(name, digits string, present bool, def int64) (int64, error) {
+	if !present {
+		return def, nil
+	}
+	if digits == "" {
+		return 0, errUnexpectedSyntax.New(name, "")
+	}
+	return strconv.ParseInt(digits, 10, 64)
+}
@@ -0,0 +1,104 @@
+package parse
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// parseAlterAddCheck parses ALTER TABLE table ADD [CONSTRAINT [name]] CHECK (expr) [[NOT] ENFORCED], which the SQL
+// parser doesn't support.
+func parseAlterAddCheck(ctx *sql.Context, s string) (sql.Node, error) {
+	r := bufio.NewReader(strings.NewReader(s))
+
+	var db, table, name, condition string
+	var constraint, check, not, enforced bool
+	err := parseFuncs{
+		expect("alter"),
+		skipSpaces,
+		expect("table"),
+		skipSpaces,
+		readQualifiedIndexIdent(&db, &table),
+		skipSpaces,
+		expect("add"),
+		skipSpaces,
+		maybeKeyword(&constraint, "constraint"),
+		skipSpaces,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// The name of the constraint is optional, and can't be CHECK unless quoted
+	if constraint {
+		err = parseFuncs{maybeKeyword(&check, "check"), skipSpaces}.exec(r)
+		if err == nil && !check {
+			err = parseFuncs{readIndexIdent(&name), skipSpaces}.exec(r)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !check {
+		if err := (parseFuncs{expect("check"), skipSpaces}).exec(r); err != nil {
+			return nil, err
+		}
+	}
+
+	err = parseFuncs{
+		readParenthesized(&condition),
+		skipSpaces,
+		maybeKeyword(&not, "not"),
+		skipSpaces,
+		maybeKeyword(&enforced, "enforced"),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+	if not && !enforced {
+		return nil, errUnexpectedSyntax.New("ENFORCED", "EOF")
+	}
+
+	condition = strings.TrimSpace(condition)
+	expr, err := parseExpr(ctx, condition)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewAlterAddCheck(plan.NewUnresolvedTable(table, db), &sql.CheckDefinition{
+		Name:            name,
+		CheckExpression: condition,
+		Enforced:        !not,
+	}, expr), nil
+}
+
+// parseAlterDropCheck parses ALTER TABLE table DROP CHECK name, which the SQL parser ignores.
+func parseAlterDropCheck(s string) (sql.Node, error) {
+	r := bufio.NewReader(strings.NewReader(s))
+
+	var db, table, name string
+	err := parseFuncs{
+		expect("alter"),
+		skipSpaces,
+		expect("table"),
+		skipSpaces,
+		readQualifiedIndexIdent(&db, &table),
+		skipSpaces,
+		expect("drop"),
+		skipSpaces,
+		expect("check"),
+		skipSpaces,
+		readIndexIdent(&name),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewAlterDropCheck(plan.NewUnresolvedTable(table, db), name), nil
+}
@@ -0,0 +1,224 @@
+package parse
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// definitionWord is a word of a table element of CREATE TABLE or of a clause of ALTER TABLE, which is either an
+// unquoted word, lowercased, or an identifier quoted with backticks.
+type definitionWord struct {
+	word       string
+	quoted     bool
+	start, end int
+}
+
+// is returns whether the word is the unquoted keyword given.
+func (w definitionWord) is(keyword string) bool {
+	return !w.quoted && w.word == keyword
+}
+
+// tableElementKeywords are the words starting the elements of CREATE TABLE that aren't columns.
+var tableElementKeywords = map[string]bool{
+	"check":      true,
+	"constraint": true,
+	"foreign":    true,
+	"fulltext":   true,
+	"index":      true,
+	"key":        true,
+	"primary":    true,
+	"spatial":    true,
+	"unique":     true,
+}
+
+// stripColumnVisibility removes the VISIBLE and INVISIBLE attributes, which the SQL parser doesn't support, from the
+// column definitions of the CREATE TABLE or ALTER TABLE statement given. It returns the statement without them, and
+// whether each column declaring one is invisible, by the lowercase name of the column.
+func stripColumnVisibility(s string) (string, map[string]bool) {
+	// The definitions of CREATE TABLE are in its first parentheses, and the clauses of ALTER TABLE aren't in any
+	create := strings.HasPrefix(strings.ToLower(s), "create")
+	level := 0
+	if create {
+		level = 1
+	}
+
+	stripped := []byte(s)
+	visibility := make(map[string]bool)
+	var words []definitionWord
+	endDefinition := func() {
+		name := definitionColumnName(words, create)
+		if name < 0 {
+			words = words[:0]
+			return
+		}
+		for _, w := range words[name+1:] {
+			if w.is("visible") || w.is("invisible") {
+				for i := w.start; i < w.end; i++ {
+					stripped[i] = ' '
+				}
+				visibility[strings.ToLower(words[name].word)] = w.word == "invisible"
+			}
+		}
+		words = words[:0]
+	}
+
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			end := closingQuote(s, i)
+			if c == '`' && depth == level {
+				words = append(words, definitionWord{
+					word:   strings.ReplaceAll(s[i+1:end], "``", "`"),
+					quoted: true,
+					start:  i,
+					end:    end + 1,
+				})
+			}
+			i = end
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == level {
+				endDefinition()
+			}
+			depth--
+			if create && depth == 0 {
+				return string(stripped), visibility
+			}
+		case c == ',' && depth == level:
+			endDefinition()
+		case isIdentRune(rune(c)) || c >= 0x80:
+			end := i
+			for end < len(s) && (isIdentRune(rune(s[end])) || s[end] >= 0x80) {
+				end++
+			}
+			if depth == level {
+				words = append(words, definitionWord{word: strings.ToLower(s[i:end]), start: i, end: end})
+			}
+			i = end - 1
+		}
+	}
+	endDefinition()
+
+	return string(stripped), visibility
+}
+
+// definitionColumnName returns the index of the name of the column defined by the words given, or -1 if they don't
+// define a column. The words of ALTER TABLE define one when they add, modify or change a column.
+func definitionColumnName(words []definitionWord, create bool) int {
+	if len(words) == 0 {
+		return -1
+	}
+	if create {
+		if !words[0].quoted && tableElementKeywords[words[0].word] {
+			return -1
+		}
+		return 0
+	}
+
+	for i, w := range words {
+		if !w.is("add") && !w.is("modify") && !w.is("change") {
+			continue
+		}
+
+		name := i + 1
+		column := name < len(words) && words[name].is("column")
+		if column {
+			name++
+		}
+		if w.is("change") {
+			// The new name of the column follows its current one
+			name++
+		}
+		if name >= len(words) || (!column && !words[name].quoted && tableElementKeywords[words[name].word]) {
+			return -1
+		}
+		return name
+	}
+	return -1
+}
+
+// closingQuote returns the position of the quote closing the one at the position given, or the end of the string
+// given if it's unterminated.
+func closingQuote(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote != '`':
+			i++
+		case s[i] == quote && i+1 < len(s) && s[i+1] == quote:
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return len(s) - 1
+}
+
+// applyColumnVisibility makes the columns of the CREATE TABLE or ALTER TABLE node given invisible or visible, as
+// declared by the attributes removed by stripColumnVisibility.
+func applyColumnVisibility(node sql.Node, visibility map[string]bool) (sql.Node, error) {
+	var columns []*sql.Column
+	switch n := node.(type) {
+	case *plan.CreateTable:
+		columns = n.Schema()
+	case *plan.AddColumn:
+		columns = []*sql.Column{n.Column()}
+	case *plan.ModifyColumn:
+		columns = []*sql.Column{n.Column()}
+	}
+
+	visible := false
+	for _, col := range columns {
+		if invisible, ok := visibility[strings.ToLower(col.Name)]; ok {
+			col.Invisible = invisible
+		}
+		visible = visible || !col.Invisible
+	}
+
+	if _, ok := node.(*plan.CreateTable); ok && !visible {
+		return nil, sql.ErrTableMustHaveVisibleColumn.New()
+	}
+	return node, nil
+}
+
+// parseAlterColumnVisibility parses ALTER TABLE table ALTER [COLUMN] name SET {VISIBLE | INVISIBLE}, which the SQL
+// parser doesn't support.
+func parseAlterColumnVisibility(s string) (sql.Node, error) {
+	r := bufio.NewReader(strings.NewReader(s))
+
+	var db, table, name, visibility string
+	var column bool
+	err := parseFuncs{
+		expect("alter"),
+		skipSpaces,
+		expect("table"),
+		skipSpaces,
+		readQualifiedIndexIdent(&db, &table),
+		skipSpaces,
+		expect("alter"),
+		skipSpaces,
+		maybeKeyword(&column, "column"),
+		skipSpaces,
+		readIndexIdent(&name),
+		skipSpaces,
+		expect("set"),
+		skipSpaces,
+		readIdent(&visibility),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if visibility != "visible" && visibility != "invisible" {
+		return nil, errUnexpectedSyntax.New("one of: VISIBLE, INVISIBLE", visibility)
+	}
+
+	return plan.NewAlterColumnVisibility(sql.UnresolvedDatabase(db), table, name, visibility == "invisible"), nil
+}
@@ -0,0 +1,157 @@
+package parse
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// parseCreateDatabase parses CREATE {DATABASE | SCHEMA} [IF NOT EXISTS] name [options], whose CHARACTER SET and
+// COLLATE options the SQL parser drops.
+func parseCreateDatabase(s string) (sql.Node, error) {
+	r := bufio.NewReader(strings.NewReader(s))
+
+	var name, charset, collation string
+	var ifNotExists bool
+	err := parseFuncs{
+		expect("create"),
+		skipSpaces,
+		oneOf("database", "schema"),
+		skipSpaces,
+		multiMaybe(&ifNotExists, "if", "not", "exists"),
+		readIndexIdent(&name),
+		skipSpaces,
+		readDatabaseOptions(&charset, &collation),
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	collated, err := sql.ParseCollation(&charset, &collation, false)
+	if err != nil {
+		return nil, err
+	}
+	return plan.NewCreateDatabase(name, ifNotExists, collated), nil
+}
+
+// parseAlterDatabase parses ALTER {DATABASE | SCHEMA} [name] options, which change the default character set and
+// collation of the database, or of the current one if it's not named.
+func parseAlterDatabase(s string) (sql.Node, error) {
+	r := bufio.NewReader(strings.NewReader(s))
+
+	var name, charset, collation string
+	err := parseFuncs{
+		expect("alter"),
+		skipSpaces,
+		oneOf("database", "schema"),
+		skipSpaces,
+		readAlterDatabaseName(&name),
+		skipSpaces,
+		readDatabaseOptions(&charset, &collation),
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if charset == "" && collation == "" {
+		return nil, errUnexpectedSyntax.New("CHARACTER SET or COLLATE", "EOF")
+	}
+	collated, err := sql.ParseCollation(&charset, &collation, false)
+	if err != nil {
+		return nil, err
+	}
+	return plan.NewAlterDatabase(sql.UnresolvedDatabase(name), collated), nil
+}
+
+// readAlterDatabaseName reads the name of the database of ALTER DATABASE, if the statement names one rather than
+// starting with its options.
+func readAlterDatabaseName(name *string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		b, err := rd.Peek(1)
+		if err != nil {
+			return err
+		}
+		if b[0] == '`' {
+			return readIndexIdent(name)(rd)
+		}
+
+		var ident string
+		if err := readIndexIdent(&ident)(rd); err != nil {
+			return err
+		}
+		switch strings.ToLower(ident) {
+		case "default", "character", "charset", "collate":
+			unreadString(rd, ident)
+		default:
+			*name = ident
+		}
+		return nil
+	}
+}
+
+// readDatabaseOptions reads the [DEFAULT] {CHARACTER SET | CHARSET} [=] name and [DEFAULT] COLLATE [=] name options of
+// CREATE and ALTER DATABASE, in any order.
+func readDatabaseOptions(charset, collation *string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		for {
+			var word string
+			if err := readIdent(&word)(rd); err != nil || word == "" {
+				return err
+			}
+			if word == "default" {
+				if err := (parseFuncs{skipSpaces, readIdent(&word)}).exec(rd); err != nil {
+					return err
+				}
+			}
+
+			var value *string
+			switch word {
+			case "character":
+				if err := (parseFuncs{skipSpaces, expect("set")}).exec(rd); err != nil {
+					return err
+				}
+				value = charset
+			case "charset":
+				value = charset
+			case "collate":
+				value = collation
+			default:
+				return errUnexpectedSyntax.New("CHARACTER SET or COLLATE", word)
+			}
+
+			var equals bool
+			err := parseFuncs{
+				skipSpaces,
+				maybe(&equals, "="),
+				skipSpaces,
+				readOptionName(value),
+				skipSpaces,
+			}.exec(rd)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readOptionName reads the name of a character set or collation, which may be quoted, in lower case.
+func readOptionName(name *string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		b, err := rd.Peek(1)
+		if err != nil {
+			return err
+		}
+
+		if b[0] == '\'' || b[0] == '"' {
+			err = readQuotedString(name)(rd)
+		} else {
+			err = readIndexIdent(name)(rd)
+		}
+		*name = strings.ToLower(*name)
+		return err
+	}
+}
@@ -0,0 +1,63 @@
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// getDiagnosticsStatementRegex matches GET DIAGNOSTICS, with the area, the condition number and the items.
+var getDiagnosticsStatementRegex = regexp.MustCompile(`(?is)^get\s+(?:(current|stacked)\s+)?diagnostics\s+(?:condition\s+(\S+)\s+)?(.*)$`)
+
+// diagnosticsItemRegex matches an item of GET DIAGNOSTICS, with its target user variable and its name.
+var diagnosticsItemRegex = regexp.MustCompile(`(?s)^\s*@([\w$.]+)\s*=\s*(\w+)\s*$`)
+
+// parseGetDiagnostics parses GET [CURRENT | STACKED] DIAGNOSTICS @var = item [, @var = item] ... and GET
+// DIAGNOSTICS CONDITION number @var = item [, @var = item] ..., whose condition number is an integer or a user
+// variable. Only the current diagnostics area exists outside of condition handlers, which aren't supported.
+func parseGetDiagnostics(ctx *sql.Context, s string) (sql.Node, error) {
+	match := getDiagnosticsStatementRegex.FindStringSubmatch(s)
+	if match == nil {
+		return nil, errUnexpectedSyntax.New("GET DIAGNOSTICS", s)
+	}
+	if strings.ToLower(match[1]) == "stacked" {
+		return nil, sql.ErrStackedDiagnosticsWithoutHandler.New()
+	}
+
+	validItems := plan.StatementDiagnosticsItems
+	var condition sql.Expression
+	if match[2] != "" {
+		var err error
+		if condition, err = parseExpr(ctx, match[2]); err != nil {
+			return nil, err
+		}
+		validItems = plan.ConditionDiagnosticsItems
+	}
+
+	var items []plan.DiagnosticsItem
+	for _, item := range strings.Split(match[3], ",") {
+		itemMatch := diagnosticsItemRegex.FindStringSubmatch(item)
+		if itemMatch == nil {
+			return nil, errUnexpectedSyntax.New("@var = item", strings.TrimSpace(item))
+		}
+
+		name := strings.ToUpper(itemMatch[2])
+		if !isDiagnosticsItem(validItems, name) {
+			return nil, errUnexpectedSyntax.New("one of: "+strings.Join(validItems, ", "), itemMatch[2])
+		}
+		items = append(items, plan.DiagnosticsItem{Target: strings.ToLower(itemMatch[1]), Name: name})
+	}
+
+	return plan.NewGetDiagnostics(ctx.Session.Warnings(), condition, items), nil
+}
+
+func isDiagnosticsItem(items []string, name string) bool {
+	for _, item := range items {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package parse
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// parseDump parses DUMP [ALL DATABASES | DATABASE[S] db [, db]... | TABLE[S] [db.]tbl [, [db.]tbl]...]. DUMP alone
+// dumps all the databases.
+func parseDump(s string) (sql.Node, error) {
+	r := bufio.NewReader(strings.NewReader(s))
+	if err := (parseFuncs{expect("dump"), skipSpaces}).exec(r); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.Peek(1); err == io.EOF {
+		return plan.NewDump(nil), nil
+	}
+
+	var kind string
+	if err := (parseFuncs{readIdent(&kind), skipSpaces}).exec(r); err != nil {
+		return nil, err
+	}
+
+	var names []qualifiedName
+	switch kind {
+	case "all":
+		err := parseFuncs{expect("databases"), skipSpaces, checkEOF}.exec(r)
+		if err != nil {
+			return nil, err
+		}
+		return plan.NewDump(nil), nil
+	case "database", "databases":
+		if err := (parseFuncs{readQualifiedIdentifierList(&names), checkEOF}).exec(r); err != nil {
+			return nil, err
+		}
+
+		targets := make([]plan.DumpTarget, len(names))
+		for i, n := range names {
+			if n.qualifier != "" {
+				return nil, errUnexpectedSyntax.New("database name", n.qualifier+"."+n.name)
+			}
+			targets[i] = plan.DumpTarget{Database: n.name}
+		}
+		return plan.NewDump(targets), nil
+	case "table", "tables":
+		if err := (parseFuncs{readQualifiedIdentifierList(&names), checkEOF}).exec(r); err != nil {
+			return nil, err
+		}
+
+		// The tables of the same database are dumped together, in the order the first of them was given.
+		var targets []plan.DumpTarget
+		byDatabase := make(map[string]int)
+		for _, n := range names {
+			i, ok := byDatabase[n.qualifier]
+			if !ok {
+				i = len(targets)
+				byDatabase[n.qualifier] = i
+				targets = append(targets, plan.DumpTarget{Database: n.qualifier})
+			}
+			targets[i].Tables = append(targets[i].Tables, n.name)
+		}
+		return plan.NewDump(targets), nil
+	default:
+		return nil, errUnexpectedSyntax.New("ALL DATABASES, DATABASES or TABLES", kind)
+	}
+}
@@ -0,0 +1,422 @@
+package parse
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// parseGrant parses GRANT statements, both granting privileges and roles. Statements granting roles have no ON
+// clause, and are parsed as such if they can't be parsed as granting privileges.
+func parseGrant(ctx *sql.Context, query string) (sql.Node, error) {
+	n, err := parseGrantPrivileges(ctx, query)
+	if err != nil {
+		if n, roleErr := parseGrantRole(query); roleErr == nil {
+			return n, nil
+		}
+		return nil, err
+	}
+	return n, nil
+}
+
+// parseRevoke parses REVOKE statements, both revoking privileges and roles, as parseGrant does.
+func parseRevoke(ctx *sql.Context, query string) (sql.Node, error) {
+	n, err := parseRevokePrivileges(ctx, query)
+	if err != nil {
+		if n, roleErr := parseRevokeRole(query); roleErr == nil {
+			return n, nil
+		}
+		return nil, err
+	}
+	return n, nil
+}
+
+func parseGrantPrivileges(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var privileges sql.Privilege
+	var columns []sql.ColumnGrant
+	var level sql.PrivilegeLevel
+	var accounts []sql.Account
+	var withGrantOption bool
+	err := parseFuncs{
+		expect("grant"),
+		skipSpaces,
+		readPrivileges(&privileges, &columns, "on"),
+		expect("on"),
+		skipSpaces,
+		readPrivilegeLevel(ctx, &level),
+		skipSpaces,
+		expect("to"),
+		readAccountList(&accounts),
+		skipSpaces,
+		multiMaybe(&withGrantOption, "with", "grant", "option"),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewGrant(privileges, columns, level, accounts, withGrantOption), nil
+}
+
+func parseRevokePrivileges(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var privileges sql.Privilege
+	var columns []sql.ColumnGrant
+	var accounts []sql.Account
+	err := parseFuncs{
+		expect("revoke"),
+		skipSpaces,
+		readPrivileges(&privileges, &columns, "on", "from"),
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// REVOKE ALL [PRIVILEGES], GRANT OPTION FROM accounts
+	var all bool
+	if err := maybe(&all, "from")(r); err != nil {
+		return nil, err
+	}
+
+	if all {
+		if privileges != sql.PrivilegeAll|sql.PrivilegeGrantOption || len(columns) > 0 {
+			return nil, errUnexpectedSyntax.New("ON", "FROM")
+		}
+
+		err := parseFuncs{
+			readAccountList(&accounts),
+			skipSpaces,
+			checkEOF,
+		}.exec(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return plan.NewRevokeAll(accounts), nil
+	}
+
+	var level sql.PrivilegeLevel
+	err = parseFuncs{
+		expect("on"),
+		skipSpaces,
+		readPrivilegeLevel(ctx, &level),
+		skipSpaces,
+		expect("from"),
+		readAccountList(&accounts),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewRevoke(privileges, columns, level, accounts), nil
+}
+
+// readPrivileges reads a comma separated list of privileges, such as SELECT, CREATE VIEW or ALL PRIVILEGES, up to
+// one of the keywords given, which is not consumed. Privileges followed by a list of columns, such as SELECT (a, b),
+// are added to the column grants.
+func readPrivileges(privileges *sql.Privilege, columns *[]sql.ColumnGrant, end ...string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		var words []string
+		var read bool
+		for {
+			if err := skipSpaces(rd); err != nil {
+				return err
+			}
+
+			var comma, paren bool
+			if err := maybe(&comma, ",")(rd); err != nil {
+				return err
+			}
+
+			if !comma {
+				if err := maybe(&paren, "(")(rd); err != nil {
+					return err
+				}
+			}
+
+			if paren {
+				p, err := parsePrivilegeWords(words)
+				if err != nil {
+					return err
+				}
+
+				if err := readColumnGrants(columns, p)(rd); err != nil {
+					return err
+				}
+
+				words, read = words[:0], true
+				continue
+			}
+
+			var word string
+			if !comma {
+				if err := readIdent(&word)(rd); err != nil {
+					return err
+				}
+			}
+
+			if comma || isOneOf(word, end) {
+				if len(words) > 0 {
+					p, err := parsePrivilegeWords(words)
+					if err != nil {
+						return err
+					}
+
+					*privileges |= p
+					words, read = words[:0], true
+				}
+
+				if !read {
+					return errUnexpectedSyntax.New("privilege", word)
+				}
+				read = false
+
+				if !comma {
+					unreadString(rd, word)
+					return nil
+				}
+				continue
+			}
+
+			if word == "" {
+				ru, _, err := rd.ReadRune()
+				if err == io.EOF {
+					return errUnexpectedSyntax.New(strings.ToUpper(strings.Join(end, " or ")), "EOF")
+				}
+				return errUnexpectedSyntax.New("privilege", string(ru))
+			}
+
+			if read {
+				return errUnexpectedSyntax.New("comma", word)
+			}
+
+			words = append(words, word)
+		}
+	}
+}
+
+func parsePrivilegeWords(words []string) (sql.Privilege, error) {
+	if len(words) == 0 {
+		return 0, errUnexpectedSyntax.New("privilege", "(")
+	}
+
+	p, ok := sql.ParsePrivilege(strings.Join(words, " "))
+	if !ok {
+		return 0, errUnexpectedSyntax.New("privilege", strings.Join(words, " "))
+	}
+
+	return p, nil
+}
+
+// readColumnGrants reads a comma separated list of columns ending with a closing parenthesis, adding the privilege
+// given to each of them.
+func readColumnGrants(columns *[]sql.ColumnGrant, privilege sql.Privilege) parseFunc {
+	return func(rd *bufio.Reader) error {
+		for {
+			var column string
+			err := parseFuncs{
+				skipSpaces,
+				readQuotableIdent(&column),
+				skipSpaces,
+			}.exec(rd)
+			if err != nil {
+				return err
+			}
+
+			if column == "" {
+				ru, _, _ := rd.ReadRune()
+				return errUnexpectedSyntax.New("column name", string(ru))
+			}
+
+			addColumnGrant(columns, column, privilege)
+
+			var more bool
+			if err := maybe(&more, ",")(rd); err != nil {
+				return err
+			}
+
+			if !more {
+				return expectRune(')')(rd)
+			}
+		}
+	}
+}
+
+func addColumnGrant(columns *[]sql.ColumnGrant, column string, privilege sql.Privilege) {
+	for i, c := range *columns {
+		if strings.EqualFold(c.Column, column) {
+			(*columns)[i].Privileges |= privilege
+			return
+		}
+	}
+
+	*columns = append(*columns, sql.ColumnGrant{Column: column, Privileges: privilege})
+}
+
+func isOneOf(word string, options []string) bool {
+	for _, o := range options {
+		if word == o {
+			return true
+		}
+	}
+	return false
+}
+
+// readPrivilegeLevel reads the level privileges are granted on: *.*, db.*, db.table, or * and table for the current
+// database.
+func readPrivilegeLevel(ctx *sql.Context, level *sql.PrivilegeLevel) parseFunc {
+	return func(rd *bufio.Reader) error {
+		// The optional TABLE keyword can't be told apart from a table named
+		// table, so it's only skipped when followed by a name.
+		var word string
+		if err := readIdent(&word)(rd); err != nil {
+			return err
+		}
+
+		if word == "table" {
+			if err := skipSpaces(rd); err != nil {
+				return err
+			}
+
+			if b, err := rd.Peek(1); err == nil && b[0] != '.' {
+				word = ""
+			}
+		}
+		unreadString(rd, word)
+
+		var first, second string
+		var qualified bool
+		err := parseFuncs{
+			readLevelPart(&first),
+			maybe(&qualified, "."),
+		}.exec(rd)
+		if err != nil {
+			return err
+		}
+
+		if qualified {
+			if err := readLevelPart(&second)(rd); err != nil {
+				return err
+			}
+
+			if first == "*" {
+				if second != "*" {
+					return errUnexpectedSyntax.New("*", second)
+				}
+				*level = sql.PrivilegeLevel{}
+				return nil
+			}
+
+			*level = sql.PrivilegeLevel{Database: first}
+			if second != "*" {
+				level.Table = second
+			}
+			return nil
+		}
+
+		db := ctx.GetCurrentDatabase()
+		if db == "" {
+			return sql.ErrNoDatabaseSelected.New()
+		}
+
+		*level = sql.PrivilegeLevel{Database: db}
+		if first != "*" {
+			level.Table = first
+		}
+		return nil
+	}
+}
+
+// readLevelPart reads either * or an identifier, which may be quoted.
+func readLevelPart(part *string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		var star bool
+		if err := maybe(&star, "*")(rd); err != nil {
+			return err
+		}
+
+		if star {
+			*part = "*"
+			return nil
+		}
+
+		if err := readQuotableIdent(part)(rd); err != nil {
+			return err
+		}
+
+		if *part == "" {
+			ru, _, _ := rd.ReadRune()
+			return errUnexpectedSyntax.New("database or table name", string(ru))
+		}
+
+		return nil
+	}
+}
+
+func parseShowGrants(query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var account *sql.Account
+	var using []sql.Account
+	err := parseFuncs{
+		expect("show"),
+		skipSpaces,
+		expect("grants"),
+		skipSpaces,
+		readGrantsFor(&account),
+		skipSpaces,
+		readGrantsUsing(&using),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewShowGrants(account, using), nil
+}
+
+// readGrantsFor reads the FOR clause of SHOW GRANTS, leaving the account nil
+// if there's none or it's CURRENT_USER.
+func readGrantsFor(account **sql.Account) parseFunc {
+	return func(rd *bufio.Reader) error {
+		var matched bool
+		if err := maybeKeyword(&matched, "for")(rd); err != nil || !matched {
+			return err
+		}
+
+		if err := (parseFuncs{skipSpaces, maybeKeyword(&matched, "current_user")}).exec(rd); err != nil {
+			return err
+		} else if matched {
+			return parseFuncs{skipSpaces, maybe(&matched, "()")}.exec(rd)
+		}
+
+		var a sql.Account
+		if err := readAccount(&a)(rd); err != nil {
+			return err
+		}
+		*account = &a
+		return nil
+	}
+}
+
+// readGrantsUsing reads the USING clause of SHOW GRANTS.
+func readGrantsUsing(roles *[]sql.Account) parseFunc {
+	return func(rd *bufio.Reader) error {
+		var matched bool
+		if err := maybeKeyword(&matched, "using")(rd); err != nil || !matched {
+			return err
+		}
+		return readAccountList(roles)(rd)
+	}
+}
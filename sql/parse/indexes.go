@@ -0,0 +1,385 @@
+package parse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+var keyPartColumnRegex = regexp.MustCompile("^(`(?:[^`]|``)+`|[a-zA-Z0-9_$]+)\\s*(?:\\(\\s*([0-9]+)\\s*\\))?$")
+
+// parseCreateIndex parses CREATE [UNIQUE] INDEX name [USING {BTREE | HASH}] ON table (key_part, ...)
+// [USING {BTREE | HASH}] [COMMENT 'string'] [VISIBLE | INVISIBLE], where each key part is either a column, with an
+// optional prefix length, or a functional key part: an expression in parentheses. Both may be followed by ASC or DESC.
+// The SQL parser doesn't support functional key parts or the visibility of indexes, so only the statements with them
+// are parsed here.
+func parseCreateIndex(ctx *sql.Context, s string) (sql.Node, error) {
+	r := bufio.NewReader(strings.NewReader(s))
+
+	var unique bool
+	var name, db, table string
+	var keyParts []string
+	using := sql.IndexUsing_BTree
+	err := parseFuncs{
+		expect("create"),
+		skipSpaces,
+		maybeKeyword(&unique, "unique"),
+		skipSpaces,
+		expect("index"),
+		skipSpaces,
+		readIndexIdent(&name),
+		skipSpaces,
+		readIndexUsing(&using),
+		skipSpaces,
+		expect("on"),
+		skipSpaces,
+		readQualifiedIndexIdent(&db, &table),
+		skipSpaces,
+		readKeyParts(&keyParts),
+		skipSpaces,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var comment string
+	var invisible bool
+	for done := false; !done; {
+		var word string
+		if err := readIdent(&word)(r); err != nil {
+			return nil, err
+		}
+
+		var steps parseFuncs
+		switch word {
+		case "":
+			steps, done = parseFuncs{checkEOF}, true
+		case "using":
+			steps = parseFuncs{skipSpaces, readIndexMethod(&using)}
+		case "comment":
+			var equals bool
+			steps = parseFuncs{skipSpaces, maybe(&equals, "="), skipSpaces, readQuotedString(&comment)}
+		case "visible", "invisible":
+			invisible = word == "invisible"
+		default:
+			return nil, errUnexpectedSyntax.New("one of: USING, COMMENT, VISIBLE, INVISIBLE", word)
+		}
+
+		if err := append(steps, skipSpaces).exec(r); err != nil {
+			return nil, err
+		}
+	}
+
+	columns := make([]sql.IndexColumn, len(keyParts))
+	for i, keyPart := range keyParts {
+		column, err := parseKeyPart(ctx, keyPart)
+		if err != nil {
+			return nil, err
+		}
+		columns[i] = column
+	}
+
+	constraint := sql.IndexConstraint_None
+	if unique {
+		constraint = sql.IndexConstraint_Unique
+	}
+
+	node := plan.NewAlterCreateIndex(plan.NewUnresolvedTable(table, db), name, using, constraint, columns, comment)
+	node.Invisible = invisible
+	return node, nil
+}
+
+// parseAlterIndexVisibility parses ALTER TABLE table ALTER INDEX name {VISIBLE | INVISIBLE}, which the SQL parser
+// doesn't support.
+func parseAlterIndexVisibility(s string) (sql.Node, error) {
+	r := bufio.NewReader(strings.NewReader(s))
+
+	var db, table, name, visibility string
+	err := parseFuncs{
+		expect("alter"),
+		skipSpaces,
+		expect("table"),
+		skipSpaces,
+		readQualifiedIndexIdent(&db, &table),
+		skipSpaces,
+		expect("alter"),
+		skipSpaces,
+		expect("index"),
+		skipSpaces,
+		readIndexIdent(&name),
+		skipSpaces,
+		readIdent(&visibility),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if visibility != "visible" && visibility != "invisible" {
+		return nil, errUnexpectedSyntax.New("one of: VISIBLE, INVISIBLE", visibility)
+	}
+
+	return plan.NewAlterIndexVisibility(plan.NewUnresolvedTable(table, db), name, visibility == "visible"), nil
+}
+
+// parseKeyPart parses a key part of CREATE INDEX: a column with an optional prefix length, or an expression in
+// parentheses, followed by an optional ASC or DESC.
+func parseKeyPart(ctx *sql.Context, keyPart string) (sql.IndexColumn, error) {
+	var column sql.IndexColumn
+
+	fields := strings.Fields(keyPart)
+	switch strings.ToLower(fields[len(fields)-1]) {
+	case "desc":
+		column.Descending = true
+		fallthrough
+	case "asc":
+		keyPart = strings.TrimSpace(keyPart[:strings.LastIndexAny(keyPart, " \t\r\n")])
+	}
+
+	if strings.HasPrefix(keyPart, "(") {
+		if closing := matchingParen(keyPart); closing != len(keyPart)-1 {
+			return sql.IndexColumn{}, errUnexpectedSyntax.New("key part", keyPart)
+		}
+
+		expr, err := parseExpr(ctx, keyPart[1:len(keyPart)-1])
+		if err != nil {
+			return sql.IndexColumn{}, err
+		}
+		if _, ok := expr.(*expression.UnresolvedColumn); ok {
+			return sql.IndexColumn{}, errInvalidIndexExpression.New(keyPart)
+		}
+
+		column.Expression = expr
+		return column, nil
+	}
+
+	match := keyPartColumnRegex.FindStringSubmatch(keyPart)
+	if match == nil {
+		return sql.IndexColumn{}, errUnexpectedSyntax.New("key part", keyPart)
+	}
+
+	if match[2] != "" {
+		length, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			return sql.IndexColumn{}, err
+		}
+		if length < 1 {
+			return sql.IndexColumn{}, ErrInvalidIndexPrefix.New(length)
+		}
+		column.Length = length
+	}
+
+	column.Name = match[1]
+	if strings.HasPrefix(column.Name, "`") {
+		column.Name = strings.ReplaceAll(column.Name[1:len(column.Name)-1], "``", "`")
+	}
+	return column, nil
+}
+
+// matchingParen returns the position of the parenthesis closing the one the string given begins with, or -1 if it
+// isn't closed.
+func matchingParen(s string) int {
+	closing := -1
+	walkUnquoted(s, func(i int, ru rune, depth int) bool {
+		if ru == ')' && depth == 0 {
+			closing = i
+			return false
+		}
+		return true
+	})
+	return closing
+}
+
+// walkUnquoted calls f with each rune of the string given that isn't in a quoted string or identifier, its position
+// and the depth of the parentheses it's in, until f returns false.
+func walkUnquoted(s string, f func(i int, ru rune, depth int) bool) {
+	var depth int
+	var quote rune
+	var escaped bool
+	for i, ru := range s {
+		switch {
+		case escaped:
+			escaped = false
+			continue
+		case quote != 0:
+			if ru == '\\' && quote != '`' {
+				escaped = true
+			} else if ru == quote {
+				quote = 0
+			}
+			continue
+		case ru == '\'' || ru == '"' || ru == '`':
+			quote = ru
+			continue
+		case ru == '(':
+			depth++
+		case ru == ')':
+			depth--
+		}
+
+		if !f(i, ru, depth) {
+			return
+		}
+	}
+}
+
+// readParenthesized reads a string in parentheses, which can contain other parentheses and quoted strings, without
+// the parentheses around it.
+func readParenthesized(str *string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		if err := expectRune('(')(rd); err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		buf.WriteRune('(')
+		for {
+			ru, _, err := rd.ReadRune()
+			if err == io.EOF {
+				return errUnexpectedSyntax.New(")", "EOF")
+			} else if err != nil {
+				return err
+			}
+
+			buf.WriteRune(ru)
+			if ru == ')' && matchingParen(buf.String()) == buf.Len()-1 {
+				break
+			}
+		}
+
+		*str = buf.String()[1 : buf.Len()-1]
+		return nil
+	}
+}
+
+// readKeyParts reads the parenthesized list of key parts of CREATE INDEX, as the strings of each of them.
+func readKeyParts(keyParts *[]string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		var list string
+		if err := readParenthesized(&list)(rd); err != nil {
+			return err
+		}
+
+		start := 0
+		walkUnquoted(list, func(i int, ru rune, depth int) bool {
+			if ru == ',' && depth == 0 {
+				*keyParts = append(*keyParts, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+			return true
+		})
+		*keyParts = append(*keyParts, strings.TrimSpace(list[start:]))
+
+		for _, keyPart := range *keyParts {
+			if keyPart == "" {
+				return errUnexpectedSyntax.New("key part", list)
+			}
+		}
+		return nil
+	}
+}
+
+// readIndexUsing reads an optional USING BTREE or USING HASH of CREATE INDEX.
+func readIndexUsing(using *sql.IndexUsing) parseFunc {
+	return func(rd *bufio.Reader) error {
+		var matched bool
+		if err := maybeKeyword(&matched, "using")(rd); err != nil || !matched {
+			return err
+		}
+
+		return parseFuncs{
+			skipSpaces,
+			readIndexMethod(using),
+		}.exec(rd)
+	}
+}
+
+// readIndexMethod reads the index method of USING in CREATE INDEX, BTREE or HASH.
+func readIndexMethod(using *sql.IndexUsing) parseFunc {
+	return func(rd *bufio.Reader) error {
+		var method string
+		if err := readIdent(&method)(rd); err != nil {
+			return err
+		}
+
+		switch method {
+		case "btree":
+			*using = sql.IndexUsing_BTree
+		case "hash":
+			*using = sql.IndexUsing_Hash
+		default:
+			return ErrUnsupportedFeature.New("USING " + method + " with functional key parts or index visibility")
+		}
+		return nil
+	}
+}
+
+// readQualifiedIndexIdent reads a table name, which may be qualified with the name of its database.
+func readQualifiedIndexIdent(db, table *string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		var qualified bool
+		err := parseFuncs{
+			readIndexIdent(table),
+			maybe(&qualified, "."),
+		}.exec(rd)
+		if err != nil || !qualified {
+			return err
+		}
+
+		*db = *table
+		return readIndexIdent(table)(rd)
+	}
+}
+
+// readIndexIdent reads an identifier, which may be quoted with backticks, keeping its case.
+func readIndexIdent(ident *string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		b, err := rd.Peek(1)
+		if err != nil {
+			return err
+		}
+
+		if b[0] == '`' {
+			return readQuotedString(ident)(rd)
+		}
+
+		var buf bytes.Buffer
+		for {
+			ru, _, err := rd.ReadRune()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+
+			if !isIdentRune(ru) {
+				if err := rd.UnreadRune(); err != nil {
+					return err
+				}
+				break
+			}
+
+			buf.WriteRune(ru)
+		}
+
+		if buf.Len() == 0 {
+			ru, _, _ := rd.ReadRune()
+			return errUnexpectedSyntax.New("identifier", string(ru))
+		}
+
+		*ident = buf.String()
+		return nil
+	}
+}
+
+func isIdentRune(ru rune) bool {
+	return ru >= 'a' && ru <= 'z' || ru >= 'A' && ru <= 'Z' || ru >= '0' && ru <= '9' || ru == '_' || ru == '$'
+}
@@ -44,15 +44,57 @@ var (
 	ErrInvalidAutoIncCols = errors.NewKind("there can be only one auto_increment column and it must be defined as a key")
 
 	ErrUnknownConstraintDefinition = errors.NewKind("unknown constraint definition: %s, %T")
+
+	// ErrIncorrectUsage is returned when clauses that can't be used together are, like ORDER BY in an UPDATE of
+	// several tables.
+	ErrIncorrectUsage = errors.NewKind("Incorrect usage of %s and %s")
 )
 
 var (
 	showVariablesRegex   = regexp.MustCompile(`^show\s+(.*)?variables\s*`)
-	showWarningsRegex    = regexp.MustCompile(`^show\s+warnings\s*`)
+	showStatusRegex      = regexp.MustCompile(`^show\s+((global|session)\s+)?status(\s|$)`)
+	showWarningsRegex    = regexp.MustCompile(`^show\s+(warnings|errors)\s*`)
+	showCountRegex       = regexp.MustCompile(`^show\s+count\s*\(\s*\*\s*\)\s+(warnings|errors)$`)
+	getDiagnosticsRegex  = regexp.MustCompile(`^get\s+((current|stacked)\s+)?diagnostics\s`)
 	fullProcessListRegex = regexp.MustCompile(`^show\s+(full\s+)?processlist$`)
 	unlockTablesRegex    = regexp.MustCompile(`^unlock\s+tables$`)
 	lockTablesRegex      = regexp.MustCompile(`^lock\s+tables\s`)
 	setRegex             = regexp.MustCompile(`^set\s+`)
+	createUserRegex      = regexp.MustCompile(`^create\s+user\s`)
+	dropUserRegex        = regexp.MustCompile(`^drop\s+user\s`)
+	alterUserRegex       = regexp.MustCompile(`^alter\s+user\s`)
+	setPasswordRegex     = regexp.MustCompile(`^set\s+password(\s|=)`)
+	grantProxyRegex      = regexp.MustCompile(`^grant\s+proxy\s+on\s`)
+	revokeProxyRegex     = regexp.MustCompile(`^revoke\s+proxy\s+on\s`)
+	grantRegex           = regexp.MustCompile(`^grant\s`)
+	revokeRegex          = regexp.MustCompile(`^revoke\s`)
+	createRoleRegex      = regexp.MustCompile(`^create\s+role\s`)
+	dropRoleRegex        = regexp.MustCompile(`^drop\s+role\s`)
+	setRoleRegex         = regexp.MustCompile(`^set\s+role\s`)
+	setDefaultRoleRegex  = regexp.MustCompile(`^set\s+default\s+role\s`)
+	showGrantsRegex      = regexp.MustCompile(`^show\s+grants(\s|$)`)
+	binaryLogsRegex      = regexp.MustCompile(`^show\s+(binary|master)\s+logs$`)
+	binlogEventsRegex    = regexp.MustCompile(`^show\s+binlog\s+events(\s|$)`)
+	masterStatusRegex    = regexp.MustCompile(`^show\s+master\s+status$`)
+	changeSourceRegex    = regexp.MustCompile(`^change\s+(replication\s+source|master)\s+to\s`)
+	startReplicaRegex    = regexp.MustCompile(`^start\s+(replica|slave)$`)
+	stopReplicaRegex     = regexp.MustCompile(`^stop\s+(replica|slave)$`)
+	replicaStatusRegex   = regexp.MustCompile(`^show\s+(replica|slave)\s+status$`)
+	resetPersistRegex    = regexp.MustCompile(`^reset\s+persist(\s|$)`)
+	dumpRegex            = regexp.MustCompile(`^dump(\s|$)`)
+	prepareRegex         = regexp.MustCompile(`^prepare\s`)
+	executeRegex         = regexp.MustCompile(`^execute\s`)
+	deallocateRegex      = regexp.MustCompile(`^(deallocate|drop)\s+prepare\s`)
+	createIndexExprRegex = regexp.MustCompile(`(?s)^create\s+(unique\s+)?index\s+[^(]+\((\s*\(|.*,\s*\()`)
+	createIndexVisRegex  = regexp.MustCompile(`(?s)^create\s+(unique\s+)?index\s.*\)[^)]*\s(in)?visible(\s|$)`)
+	alterIndexVisRegex   = regexp.MustCompile(`(?s)^alter\s+table\s.*\salter\s+index\s`)
+	alterColumnVisRegex  = regexp.MustCompile(`(?s)^alter\s+table\s.*\salter\s+(column\s+)?\S+\s+set\s+(in)?visible$`)
+	columnVisRegex       = regexp.MustCompile(`(?s)^(create\s+(temporary\s+)?table|alter\s+table)\s.*\b(in)?visible\b`)
+	alterAddCheckRegex   = regexp.MustCompile(`(?s)^alter\s+table\s+[^(]*\sadd\s+(constraint\s+([^(]*\s)?)?check\s*\(`)
+	alterDropCheckRegex  = regexp.MustCompile(`(?s)^alter\s+table\s+[^(]*\sdrop\s+check\s`)
+	createDatabaseRegex  = regexp.MustCompile(`^create\s+(database|schema)\s`)
+	alterDatabaseRegex   = regexp.MustCompile(`^alter\s+(database|schema)\s`)
+	calcFoundRowsRegex   = regexp.MustCompile(`(?i)^(select\s+((all|distinct|distinctrow|high_priority|straight_join|sql_small_result|sql_big_result|sql_buffer_result|sql_cache|sql_no_cache)\s+)*)sql_calc_found_rows\s`)
 )
 
 var describeSupportedFormats = []string{"tree"}
@@ -98,7 +140,7 @@ func Parse(ctx *sql.Context, query string) (sql.Node, error) {
 	span, ctx := ctx.Span("parse", opentracing.Tag{Key: "query", Value: query})
 	defer span.Finish()
 
-	s := strings.TrimSpace(removeComments(query))
+	s := normalizeQuotes(ctx, strings.TrimSpace(removeComments(query)))
 	if strings.HasSuffix(s, ";") {
 		s = s[:len(s)-1]
 	}
@@ -113,16 +155,104 @@ func Parse(ctx *sql.Context, query string) (sql.Node, error) {
 	switch true {
 	case showVariablesRegex.MatchString(lowerQuery):
 		return parseShowVariables(ctx, s)
+	case showStatusRegex.MatchString(lowerQuery):
+		return parseShowStatus(s)
 	case showWarningsRegex.MatchString(lowerQuery):
 		return parseShowWarnings(ctx, s)
+	case showCountRegex.MatchString(lowerQuery):
+		return parseShowCount(ctx, lowerQuery), nil
+	case getDiagnosticsRegex.MatchString(lowerQuery):
+		return parseGetDiagnostics(ctx, s)
 	case fullProcessListRegex.MatchString(lowerQuery):
 		return plan.NewShowProcessList(), nil
 	case unlockTablesRegex.MatchString(lowerQuery):
 		return plan.NewUnlockTables(), nil
 	case lockTablesRegex.MatchString(lowerQuery):
 		return parseLockTables(ctx, s)
+	case createUserRegex.MatchString(lowerQuery):
+		return parseCreateUser(ctx, s)
+	case alterUserRegex.MatchString(lowerQuery):
+		return parseAlterUser(ctx, s)
+	case dropUserRegex.MatchString(lowerQuery):
+		return parseDropUser(ctx, s)
+	case setPasswordRegex.MatchString(lowerQuery):
+		return parseSetPassword(ctx, s)
+	case grantProxyRegex.MatchString(lowerQuery):
+		return parseGrantProxy(s)
+	case revokeProxyRegex.MatchString(lowerQuery):
+		return parseRevokeProxy(s)
+	case grantRegex.MatchString(lowerQuery):
+		return parseGrant(ctx, s)
+	case revokeRegex.MatchString(lowerQuery):
+		return parseRevoke(ctx, s)
+	case createRoleRegex.MatchString(lowerQuery):
+		return parseCreateRole(ctx, s)
+	case dropRoleRegex.MatchString(lowerQuery):
+		return parseDropRole(ctx, s)
+	case setRoleRegex.MatchString(lowerQuery):
+		return parseSetRole(ctx, s)
+	case setDefaultRoleRegex.MatchString(lowerQuery):
+		return parseSetDefaultRole(ctx, s)
+	case showGrantsRegex.MatchString(lowerQuery):
+		return parseShowGrants(s)
+	case binaryLogsRegex.MatchString(lowerQuery):
+		return plan.NewShowBinaryLogs(), nil
+	case binlogEventsRegex.MatchString(lowerQuery):
+		return parseShowBinlogEvents(s)
+	case masterStatusRegex.MatchString(lowerQuery):
+		return plan.NewShowMasterStatus(), nil
+	case changeSourceRegex.MatchString(lowerQuery):
+		warnDeprecatedReplicaSyntax(ctx, lowerQuery, "CHANGE MASTER", "CHANGE REPLICATION SOURCE")
+		return parseChangeReplicationSource(s)
+	case startReplicaRegex.MatchString(lowerQuery):
+		warnDeprecatedReplicaSyntax(ctx, lowerQuery, "START SLAVE", "START REPLICA")
+		return plan.NewStartReplica(), nil
+	case stopReplicaRegex.MatchString(lowerQuery):
+		warnDeprecatedReplicaSyntax(ctx, lowerQuery, "STOP SLAVE", "STOP REPLICA")
+		return plan.NewStopReplica(), nil
+	case replicaStatusRegex.MatchString(lowerQuery):
+		warnDeprecatedReplicaSyntax(ctx, lowerQuery, "SHOW SLAVE STATUS", "SHOW REPLICA STATUS")
+		return plan.NewShowReplicaStatus(), nil
+	case prepareRegex.MatchString(lowerQuery):
+		return parsePrepare(ctx, s)
+	case executeRegex.MatchString(lowerQuery):
+		return parseExecute(ctx, s)
+	case deallocateRegex.MatchString(lowerQuery):
+		return parseDeallocate(s)
+	case createIndexExprRegex.MatchString(lowerQuery), createIndexVisRegex.MatchString(lowerQuery):
+		return parseCreateIndex(ctx, s)
+	case alterIndexVisRegex.MatchString(lowerQuery):
+		return parseAlterIndexVisibility(s)
+	case alterColumnVisRegex.MatchString(lowerQuery):
+		return parseAlterColumnVisibility(s)
+	case alterAddCheckRegex.MatchString(lowerQuery):
+		return parseAlterAddCheck(ctx, s)
+	case alterDropCheckRegex.MatchString(lowerQuery):
+		return parseAlterDropCheck(s)
+	case createDatabaseRegex.MatchString(lowerQuery):
+		return parseCreateDatabase(s)
+	case alterDatabaseRegex.MatchString(lowerQuery):
+		return parseAlterDatabase(s)
+	case resetPersistRegex.MatchString(lowerQuery):
+		return parseResetPersist(s)
+	case dumpRegex.MatchString(lowerQuery):
+		return parseDump(s)
 	case setRegex.MatchString(lowerQuery):
-		s = fixSetQuery(s)
+		s = fixSetQuery(fixUserVarAssignments(s, true))
+	default:
+		s = fixUserVarAssignments(s, false)
+	}
+
+	// The SQL parser doesn't support the visibility of columns, which is applied to the columns once it's parsed
+	var visibility map[string]bool
+	if columnVisRegex.MatchString(lowerQuery) {
+		s, visibility = stripColumnVisibility(s)
+	}
+
+	// The SQL parser doesn't support SQL_CALC_FOUND_ROWS either, which is set on the limit of the query once it's parsed
+	calcFoundRows := calcFoundRowsRegex.MatchString(s)
+	if calcFoundRows {
+		s = calcFoundRowsRegex.ReplaceAllString(s, "${1}")
 	}
 
 	stmt, err := sqlparser.Parse(s)
@@ -130,7 +260,20 @@ func Parse(ctx *sql.Context, query string) (sql.Node, error) {
 		return nil, err
 	}
 
-	return convert(ctx, stmt, s)
+	node, err := convert(withConcatPipes(ctx, s, stmt), stmt, s)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a limit, the rows found are the rows returned, which are counted like for any other query
+	if limit, ok := node.(*plan.Limit); ok && calcFoundRows {
+		limit.CalcFoundRows = true
+	}
+
+	if len(visibility) == 0 {
+		return node, nil
+	}
+	return applyColumnVisibility(node, visibility)
 }
 
 func convert(ctx *sql.Context, stmt sqlparser.Statement, query string) (sql.Node, error) {
@@ -575,9 +718,14 @@ func convertAlterTable(ctx *sql.Context, ddl *sqlparser.DDL) (sql.Node, error) {
 		switch strings.ToLower(ddl.ConstraintAction) {
 		case sqlparser.AddStr:
 			if fkConstraint, ok := parsedConstraint.(*sql.ForeignKeyConstraint); ok {
+				// The referenced table is in the database of the table, unless the foreign key names another
+				refDatabase := fkConstraint.ReferencedDatabase
+				if refDatabase == "" {
+					refDatabase = ddl.Table.Qualifier.String()
+				}
 				return plan.NewAlterAddForeignKey(
 					table,
-					plan.NewUnresolvedTable(fkConstraint.ReferencedTable, ddl.Table.Qualifier.String()),
+					plan.NewUnresolvedTable(fkConstraint.ReferencedTable, refDatabase),
 					fkConstraint), nil
 			} else {
 				return nil, ErrUnsupportedFeature.New(sqlparser.String(ddl))
@@ -587,11 +735,8 @@ func convertAlterTable(ctx *sql.Context, ddl *sqlparser.DDL) (sql.Node, error) {
 			case *sql.ForeignKeyConstraint:
 				return plan.NewAlterDropForeignKey(table, c), nil
 			case namedConstraint:
-				// For simple named constraint drops, fill in a partial foreign key constraint. This will need to be changed if
-				// we ever support other kinds of constraints than foreign keys (e.g. CHECK)
-				return plan.NewAlterDropForeignKey(table, &sql.ForeignKeyConstraint{
-					Name: c.name,
-				}), nil
+				// DROP CONSTRAINT drops either a CHECK constraint or a foreign key, which isn't known until the table is resolved
+				return plan.NewAlterDropConstraint(table, c.name), nil
 			default:
 				return nil, ErrUnsupportedFeature.New(sqlparser.String(ddl))
 			}
@@ -610,7 +755,7 @@ func convertAlterTable(ctx *sql.Context, ddl *sqlparser.DDL) (sql.Node, error) {
 		case sqlparser.RenameStr:
 			return plan.NewRenameColumn(sql.UnresolvedDatabase(""), ddl.Table.Name.String(), ddl.Column.String(), ddl.ToColumn.String()), nil
 		case sqlparser.ModifyStr, sqlparser.ChangeStr:
-			sch, err := TableSpecToSchema(nil, ddl.TableSpec)
+			sch, err := TableSpecToSchema(ctx, ddl.TableSpec)
 			if err != nil {
 				return nil, err
 			}
@@ -655,9 +800,11 @@ func convertAlterIndex(ctx *sql.Context, ddl *sqlparser.DDL) (sql.Node, error) {
 
 		columns := make([]sql.IndexColumn, len(ddl.IndexSpec.Columns))
 		for i, col := range ddl.IndexSpec.Columns {
+			var length int64
 			if col.Length != nil {
 				if col.Length.Type == sqlparser.IntVal {
-					length, err := strconv.ParseInt(string(col.Length.Val), 10, 64)
+					var err error
+					length, err = strconv.ParseInt(string(col.Length.Val), 10, 64)
 					if err != nil {
 						return nil, err
 					}
@@ -667,8 +814,9 @@ func convertAlterIndex(ctx *sql.Context, ddl *sqlparser.DDL) (sql.Node, error) {
 				}
 			}
 			columns[i] = sql.IndexColumn{
-				Name:   col.Column.String(),
-				Length: 0,
+				Name:       col.Column.String(),
+				Length:     length,
+				Descending: col.Order == sqlparser.DescScr,
 			}
 		}
 
@@ -766,7 +914,7 @@ func convertCreateTable(ctx *sql.Context, c *sqlparser.DDL) (sql.Node, error) {
 		), nil
 	}
 
-	schema, err := TableSpecToSchema(nil, c.TableSpec)
+	schema, err := TableSpecToSchema(ctx, c.TableSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -801,9 +949,11 @@ func convertCreateTable(ctx *sql.Context, c *sqlparser.DDL) (sql.Node, error) {
 
 		columns := make([]sql.IndexColumn, len(idxDef.Columns))
 		for i, col := range idxDef.Columns {
+			var length int64
 			if col.Length != nil {
 				if col.Length.Type == sqlparser.IntVal {
-					length, err := strconv.ParseInt(string(col.Length.Val), 10, 64)
+					var err error
+					length, err = strconv.ParseInt(string(col.Length.Val), 10, 64)
 					if err != nil {
 						return nil, err
 					}
@@ -813,8 +963,9 @@ func convertCreateTable(ctx *sql.Context, c *sqlparser.DDL) (sql.Node, error) {
 				}
 			}
 			columns[i] = sql.IndexColumn{
-				Name:   col.Column.String(),
-				Length: 0,
+				Name:       col.Column.String(),
+				Length:     length,
+				Descending: col.Order == sqlparser.DescScr,
 			}
 		}
 
@@ -848,8 +999,26 @@ func convertCreateTable(ctx *sql.Context, c *sqlparser.DDL) (sql.Node, error) {
 		}
 	}
 
-	return plan.NewCreateTable(
-		sql.UnresolvedDatabase(""), c.Table.Name.String(), schema, c.IfNotExists, idxDefs, fkDefs), nil
+	createTable := plan.NewCreateTable(
+		sql.UnresolvedDatabase(""), c.Table.Name.String(), schema, c.IfNotExists, idxDefs, fkDefs)
+	createTable = createTable.WithTableOptions(convertTableOptions(c.TableSpec.Options))
+	return createTable.WithDefaultCollatedColumns(defaultCollatedColumns(c.TableSpec, schema)), nil
+}
+
+// defaultCollatedColumns returns the indexes of the columns of the table given whose string types are declared without
+// a character set or collation, which take the default collation of the table.
+func defaultCollatedColumns(tableSpec *sqlparser.TableSpec, schema sql.Schema) []int {
+	var columns []int
+	for i, cd := range tableSpec.Columns {
+		if cd.Type.Charset != "" || cd.Type.Collate != "" {
+			continue
+		}
+		collated, ok := schema[i].Type.(interface{ Collation() sql.Collation })
+		if ok && collated.Collation() == sql.Collation_Default {
+			columns = append(columns, i)
+		}
+	}
+	return columns
 }
 
 type namedConstraint struct {
@@ -867,12 +1036,13 @@ func convertConstraintDefinition(ctx *sql.Context, cd *sqlparser.ConstraintDefin
 			refColumns[i] = col.String()
 		}
 		return &sql.ForeignKeyConstraint{
-			Name:              cd.Name,
-			Columns:           columns,
-			ReferencedTable:   fkConstraint.ReferencedTable.Name.String(),
-			ReferencedColumns: refColumns,
-			OnUpdate:          convertReferenceAction(fkConstraint.OnUpdate),
-			OnDelete:          convertReferenceAction(fkConstraint.OnDelete),
+			Name:               cd.Name,
+			Columns:            columns,
+			ReferencedDatabase: fkConstraint.ReferencedTable.Qualifier.String(),
+			ReferencedTable:    fkConstraint.ReferencedTable.Name.String(),
+			ReferencedColumns:  refColumns,
+			OnUpdate:           convertReferenceAction(fkConstraint.OnUpdate),
+			OnDelete:           convertReferenceAction(fkConstraint.OnDelete),
 		}, nil
 	} else if len(cd.Name) > 0 && cd.Details == nil {
 		return namedConstraint{cd.Name}, nil
@@ -929,10 +1099,6 @@ func convertInsert(ctx *sql.Context, i *sqlparser.Insert) (sql.Node, error) {
 		return nil, err
 	}
 
-	if len(i.Ignore) > 0 {
-		return nil, ErrUnsupportedSyntax.New(sqlparser.String(i))
-	}
-
 	isReplace := i.Action == sqlparser.ReplaceStr
 
 	src, err := insertRowsToNode(ctx, i.Rows)
@@ -940,13 +1106,15 @@ func convertInsert(ctx *sql.Context, i *sqlparser.Insert) (sql.Node, error) {
 		return nil, err
 	}
 
-	return plan.NewInsertInto(
+	insert := plan.NewInsertInto(
 		tableNameToUnresolvedTable(i.Table),
 		src,
 		isReplace,
 		columnsToStrings(i.Columns),
 		onDupExprs,
-	), nil
+	)
+	insert.Ignore = len(i.Ignore) > 0
+	return insert, nil
 }
 
 func convertDelete(ctx *sql.Context, d *sqlparser.Delete) (sql.Node, error) {
@@ -984,7 +1152,37 @@ func convertDelete(ctx *sql.Context, d *sqlparser.Delete) (sql.Node, error) {
 		}
 	}
 
-	return plan.NewDeleteFrom(node), nil
+	del := plan.NewDeleteFrom(node)
+	if targets := deleteTargets(d); len(targets) > 0 {
+		del = del.WithTargets(targets)
+	}
+	return del, nil
+}
+
+// deleteTargets returns the names of the tables that the rows deleted by the statement given are deleted from, which
+// are its targets, of the form DELETE t1, t2 FROM ... or DELETE FROM t1, t2 USING ..., unless it has a single one that
+// is the only table it reads.
+func deleteTargets(d *sqlparser.Delete) []string {
+	if len(d.Targets) == 0 {
+		return nil
+	}
+
+	if len(d.Targets) == 1 && len(d.TableExprs) == 1 {
+		if te, ok := d.TableExprs[0].(*sqlparser.AliasedTableExpr); ok {
+			if name, ok := te.Expr.(sqlparser.TableName); ok {
+				target := d.Targets[0].Name.String()
+				if strings.EqualFold(target, te.As.String()) || te.As.IsEmpty() && strings.EqualFold(target, name.Name.String()) {
+					return nil
+				}
+			}
+		}
+	}
+
+	targets := make([]string, len(d.Targets))
+	for i, t := range d.Targets {
+		targets[i] = t.Name.String()
+	}
+	return targets
 }
 
 func convertUpdate(ctx *sql.Context, d *sqlparser.Update) (sql.Node, error) {
@@ -998,6 +1196,16 @@ func convertUpdate(ctx *sql.Context, d *sqlparser.Update) (sql.Node, error) {
 		return nil, err
 	}
 
+	// Only the rows of single tables have an order in which they're updated
+	if len(d.TableExprs) > 1 || isJoin(d.TableExprs[0]) {
+		if len(d.OrderBy) != 0 {
+			return nil, ErrIncorrectUsage.New("UPDATE", "ORDER BY")
+		}
+		if d.Limit != nil {
+			return nil, ErrIncorrectUsage.New("UPDATE", "LIMIT")
+		}
+	}
+
 	if d.Where != nil {
 		node, err = whereToFilter(ctx, d.Where, node)
 		if err != nil {
@@ -1031,6 +1239,18 @@ func convertUpdate(ctx *sql.Context, d *sqlparser.Update) (sql.Node, error) {
 	return plan.NewUpdate(node, updateExprs), nil
 }
 
+// isJoin returns whether the table expression given is a join of several tables.
+func isJoin(te sqlparser.TableExpr) bool {
+	switch te := te.(type) {
+	case *sqlparser.JoinTableExpr:
+		return true
+	case *sqlparser.ParenTableExpr:
+		return len(te.Exprs) > 1 || isJoin(te.Exprs[0])
+	default:
+		return false
+	}
+}
+
 // TableSpecToSchema creates a sql.Schema from a parsed TableSpec
 func TableSpecToSchema(ctx *sql.Context, tableSpec *sqlparser.TableSpec) (sql.Schema, error) {
 	err := validateIndexes(tableSpec)
@@ -1103,10 +1323,14 @@ func columnDefinitionToColumn(ctx *sql.Context, cd *sqlparser.ColumnDefinition,
 	if err != nil {
 		return nil, err
 	}
+	if strings.ToLower(cd.Type.Type) == "real" && sql.SqlModeEnabled(ctx, sql.SqlMode_RealAsFloat) {
+		internalTyp = sql.Float32
+	}
 
 	// Primary key info can either be specified in the column's type info (for in-line declarations), or in a slice of
 	// indexes attached to the table def. We have to check both places to find if a column is part of the primary key
 	isPkey := cd.Type.KeyOpt == colKeyPrimary
+	var isPkeyDesc bool
 
 	if !isPkey {
 	OuterLoop:
@@ -1115,6 +1339,7 @@ func columnDefinitionToColumn(ctx *sql.Context, cd *sqlparser.ColumnDefinition,
 				for _, indexCol := range index.Columns {
 					if indexCol.Column.Equal(cd.Name) {
 						isPkey = true
+						isPkeyDesc = indexCol.Order == sqlparser.DescScr
 						break OuterLoop
 					}
 				}
@@ -1135,8 +1360,10 @@ func columnDefinitionToColumn(ctx *sql.Context, cd *sqlparser.ColumnDefinition,
 		}
 		// The literal and expression distinction seems to be decided by the presence of parentheses, even for defaults like NOW() vs (NOW())
 		_, isExpr := cd.Type.Default.(*sqlparser.ParenExpr)
-		// A literal will never have children, thus we can also check for that.
-		isExpr = isExpr || len(parsedExpr.Children()) != 0
+		// A literal will never have children, thus we can also check for that, unless it's the current time with a
+		// precision.
+		_, isCurTime := cd.Type.Default.(*sqlparser.CurTimeFuncExpr)
+		isExpr = isExpr || (!isCurTime && len(parsedExpr.Children()) != 0)
 		defaultVal, err = ExpressionToColumnDefaultValue(ctx, parsedExpr, !isExpr)
 		if err != nil {
 			return nil, err
@@ -1148,18 +1375,64 @@ func columnDefinitionToColumn(ctx *sql.Context, cd *sqlparser.ColumnDefinition,
 		extra = "auto_increment"
 	}
 
+	var onUpdate *sql.ColumnDefaultValue
+	if cd.Type.OnUpdate != nil {
+		onUpdate, err = convertOnUpdate(ctx, cd.Name.String(), internalTyp, !isPkey && !bool(cd.Type.NotNull), cd.Type.OnUpdate)
+		if err != nil {
+			return nil, err
+		}
+		extra = "on update " + onUpdate.String()
+	}
+
 	return &sql.Column{
-		Nullable:      !isPkey && !bool(cd.Type.NotNull),
-		Type:          internalTyp,
-		Name:          cd.Name.String(),
-		PrimaryKey:    isPkey,
-		Default:       defaultVal,
-		AutoIncrement: bool(cd.Type.Autoincrement),
-		Comment:       comment,
-		Extra:         extra,
+		Nullable:             !isPkey && !bool(cd.Type.NotNull),
+		Type:                 internalTyp,
+		Name:                 cd.Name.String(),
+		PrimaryKey:           isPkey,
+		Default:              defaultVal,
+		OnUpdate:             onUpdate,
+		AutoIncrement:        bool(cd.Type.Autoincrement),
+		Comment:              comment,
+		Extra:                extra,
+		PrimaryKeyDescending: isPkeyDesc,
 	}, nil
 }
 
+// convertOnUpdate returns the ON UPDATE value of a column with the name and type given, which can only be the current
+// time, given as CURRENT_TIMESTAMP or one of its synonyms, with an optional precision.
+func convertOnUpdate(ctx *sql.Context, column string, typ sql.Type, nullable bool, onUpdate sqlparser.Expr) (*sql.ColumnDefaultValue, error) {
+	if !sql.IsTime(typ) || typ == sql.Date {
+		return nil, sql.ErrInvalidOnUpdate.New(column)
+	}
+
+	var name string
+	var args []sql.Expression
+	switch f := onUpdate.(type) {
+	case *sqlparser.FuncExpr:
+		name = f.Name.Lowered()
+	case *sqlparser.CurTimeFuncExpr:
+		name = f.Name.Lowered()
+		fsp, err := exprToExpression(ctx, f.Fsp)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, fsp)
+	}
+
+	switch name {
+	case "current_timestamp", "localtime", "localtimestamp":
+	default:
+		return nil, sql.ErrInvalidOnUpdate.New(column)
+	}
+
+	// Its synonyms are shown as CURRENT_TIMESTAMP, like MySQL does
+	now, err := function.NewNowSynonym("current_timestamp")(args...)
+	if err != nil {
+		return nil, err
+	}
+	return sql.NewColumnDefaultValue(now, typ, true, nullable)
+}
+
 func columnsToStrings(cols sqlparser.Columns) []string {
 	res := make([]string, len(cols))
 	for i, c := range cols {
@@ -1353,6 +1626,10 @@ func limitToLimit(
 	limit sqlparser.Expr,
 	child sql.Node,
 ) (*plan.Limit, error) {
+	if name, ok := bindVarName(limit); ok {
+		return plan.NewBindLimit(name, child), nil
+	}
+
 	rowCount, err := getInt64Value(ctx, limit, "LIMIT with non-integer literal")
 	if err != nil {
 		return nil, err
@@ -1379,6 +1656,10 @@ func offsetToOffset(
 	offset sqlparser.Expr,
 	child sql.Node,
 ) (*plan.Offset, error) {
+	if name, ok := bindVarName(offset); ok {
+		return plan.NewBindOffset(name, child), nil
+	}
+
 	o, err := getInt64Value(ctx, offset, "OFFSET with non-integer literal")
 	if err != nil {
 		return nil, err
@@ -1391,6 +1672,16 @@ func offsetToOffset(
 	return plan.NewOffset(o, child), nil
 }
 
+// bindVarName returns the name of the parameter of a prepared statement the
+// expression given is, if it's one.
+func bindVarName(expr sqlparser.Expr) (string, bool) {
+	v, ok := expr.(*sqlparser.SQLVal)
+	if !ok || v.Type != sqlparser.ValArg {
+		return "", false
+	}
+	return strings.TrimPrefix(string(v.Val), ":"), true
+}
+
 // getInt64Literal returns an int64 *expression.Literal for the value given, or an unsupported error with the string
 // given if the expression doesn't represent an integer literal.
 func getInt64Literal(ctx *sql.Context, expr sqlparser.Expr, errStr string) (*expression.Literal, error) {
@@ -1618,11 +1909,11 @@ func exprToExpression(ctx *sql.Context, e sqlparser.Expr) (sql.Expression, error
 			return nil, err
 		}
 
-		if v.Distinct {
-			if v.Name.Lowered() != "count" {
-				return nil, ErrUnsupportedSyntax.New("DISTINCT on non-COUNT aggregations")
-			}
+		if v.Name.Lowered() == userVarAssignmentFunc {
+			return convertUserVarAssignment(exprs)
+		}
 
+		if v.Distinct && v.Name.Lowered() == "count" {
 			if len(exprs) != 1 {
 				return nil, ErrUnsupportedSyntax.New("more than one expression in COUNT")
 			}
@@ -1630,8 +1921,17 @@ func exprToExpression(ctx *sql.Context, e sqlparser.Expr) (sql.Expression, error
 			return aggregation.NewCountDistinct(exprs[0]), nil
 		}
 
-		return expression.NewUnresolvedFunction(v.Name.Lowered(),
-			isAggregateFunc(v), exprs...), nil
+		uf := expression.NewUnresolvedFunction(v.Name.Lowered(),
+			isAggregateFunc(v) || v.Distinct, exprs...)
+		uf.Distinct = v.Distinct
+		return uf, nil
+	case *sqlparser.CurTimeFuncExpr:
+		fsp, err := exprToExpression(ctx, v.Fsp)
+		if err != nil {
+			return nil, err
+		}
+
+		return expression.NewUnresolvedFunction(v.Name.Lowered(), false, fsp), nil
 	case *sqlparser.ParenExpr:
 		return exprToExpression(ctx, v.Expr)
 	case *sqlparser.AndExpr:
@@ -1657,6 +1957,9 @@ func exprToExpression(ctx *sql.Context, e sqlparser.Expr) (sql.Expression, error
 			return nil, err
 		}
 
+		if isConcatPipe(ctx, v) {
+			return expression.NewUnresolvedFunction("concat", false, lhs, rhs), nil
+		}
 		return expression.NewOr(lhs, rhs), nil
 	case *sqlparser.ConvertExpr:
 		expr, err := exprToExpression(ctx, v.Expr)
@@ -2214,9 +2517,13 @@ func parseShowTableStatus(ctx *sql.Context, query string) (sql.Node, error) {
 
 var fixSessionRegex = regexp.MustCompile(`(,\s*|(set|SET)\s+)(SESSION|session)\s+([a-zA-Z0-9_]+)\s*=`)
 var fixGlobalRegex = regexp.MustCompile(`(,\s*|(set|SET)\s+)(GLOBAL|global)\s+([a-zA-Z0-9_]+)\s*=`)
+var fixPersistRegex = regexp.MustCompile(`(,\s*|(set|SET)\s+)(PERSIST|persist)\s+([a-zA-Z0-9_]+)\s*=`)
+var fixPersistOnlyRegex = regexp.MustCompile(`(,\s*|(set|SET)\s+)(PERSIST_ONLY|persist_only)\s+([a-zA-Z0-9_]+)\s*=`)
 
 func fixSetQuery(s string) string {
 	s = fixSessionRegex.ReplaceAllString(s, `$1@@session.$4 =`)
 	s = fixGlobalRegex.ReplaceAllString(s, `$1@@global.$4 =`)
+	s = fixPersistRegex.ReplaceAllString(s, `$1@@persist.$4 =`)
+	s = fixPersistOnlyRegex.ReplaceAllString(s, `$1@@persist_only.$4 =`)
 	return s
 }
@@ -31,6 +31,24 @@ var showCollationProjection = plan.NewProject([]sql.Expression{
 )
 
 var fixtures = map[string]sql.Node{
+	"CREATE TABLE t1(a INTEGER) ENGINE=MyISAM DEFAULT CHARSET=latin1 row_format compressed, COMMENT='it''s a table' my_option='my value'": plan.NewCreateTable(
+		sql.UnresolvedDatabase(""),
+		"t1",
+		sql.Schema{{
+			Name:     "a",
+			Type:     sql.Int32,
+			Nullable: true,
+		}},
+		false,
+		nil,
+		nil,
+	).WithTableOptions(sql.TableOptions{
+		{Name: "ENGINE", Value: "MyISAM"},
+		{Name: "CHARACTER SET", Value: "latin1"},
+		{Name: "ROW_FORMAT", Value: "compressed"},
+		{Name: "COMMENT", Value: "it's a table", Quoted: true},
+		{Name: "MY_OPTION", Value: "my value", Quoted: true},
+	}),
 	`CREATE TABLE t1(a INTEGER, b TEXT, c DATE, d TIMESTAMP, e VARCHAR(20), f BLOB NOT NULL, g DATETIME, h CHAR(40))`: plan.NewCreateTable(
 		sql.UnresolvedDatabase(""),
 		"t1",
@@ -70,7 +88,7 @@ var fixtures = map[string]sql.Node{
 		false,
 		nil,
 		nil,
-	),
+	).WithDefaultCollatedColumns([]int{1, 4, 7}),
 	`CREATE TABLE t1(a INTEGER NOT NULL PRIMARY KEY, b TEXT)`: plan.NewCreateTable(
 		sql.UnresolvedDatabase(""),
 		"t1",
@@ -88,7 +106,7 @@ var fixtures = map[string]sql.Node{
 		false,
 		nil,
 		nil,
-	),
+	).WithDefaultCollatedColumns([]int{1}),
 	`CREATE TABLE t1(a INTEGER NOT NULL PRIMARY KEY COMMENT "hello", b TEXT COMMENT "goodbye")`: plan.NewCreateTable(
 		sql.UnresolvedDatabase(""),
 		"t1",
@@ -108,7 +126,7 @@ var fixtures = map[string]sql.Node{
 		false,
 		nil,
 		nil,
-	),
+	).WithDefaultCollatedColumns([]int{1}),
 	`CREATE TABLE t1(a INTEGER, b TEXT, PRIMARY KEY (a))`: plan.NewCreateTable(
 		sql.UnresolvedDatabase(""),
 		"t1",
@@ -126,7 +144,7 @@ var fixtures = map[string]sql.Node{
 		false,
 		nil,
 		nil,
-	),
+	).WithDefaultCollatedColumns([]int{1}),
 	`CREATE TABLE t1(a INTEGER, b TEXT, PRIMARY KEY (a, b))`: plan.NewCreateTable(
 		sql.UnresolvedDatabase(""),
 		"t1",
@@ -144,7 +162,26 @@ var fixtures = map[string]sql.Node{
 		false,
 		nil,
 		nil,
-	),
+	).WithDefaultCollatedColumns([]int{1}),
+	`CREATE TABLE t1(a INTEGER, b TEXT, PRIMARY KEY (a, b DESC))`: plan.NewCreateTable(
+		sql.UnresolvedDatabase(""),
+		"t1",
+		sql.Schema{{
+			Name:       "a",
+			Type:       sql.Int32,
+			Nullable:   false,
+			PrimaryKey: true,
+		}, {
+			Name:                 "b",
+			Type:                 sql.Text,
+			Nullable:             false,
+			PrimaryKey:           true,
+			PrimaryKeyDescending: true,
+		}},
+		false,
+		nil,
+		nil,
+	).WithDefaultCollatedColumns([]int{1}),
 	`CREATE TABLE IF NOT EXISTS t1(a INTEGER, b TEXT, PRIMARY KEY (a, b))`: plan.NewCreateTable(
 		sql.UnresolvedDatabase(""),
 		"t1",
@@ -162,7 +199,7 @@ var fixtures = map[string]sql.Node{
 		true,
 		nil,
 		nil,
-	),
+	).WithDefaultCollatedColumns([]int{1}),
 	`CREATE TABLE t1(a INTEGER PRIMARY KEY, b INTEGER, INDEX (b))`: plan.NewCreateTable(
 		sql.UnresolvedDatabase(""),
 		"t1",
@@ -182,7 +219,7 @@ var fixtures = map[string]sql.Node{
 			IndexName:  "",
 			Using:      sql.IndexUsing_Default,
 			Constraint: sql.IndexConstraint_None,
-			Columns:    []sql.IndexColumn{{"b", 0}},
+			Columns:    []sql.IndexColumn{{Name: "b"}},
 			Comment:    "",
 		}},
 		nil,
@@ -206,7 +243,7 @@ var fixtures = map[string]sql.Node{
 			IndexName:  "idx_name",
 			Using:      sql.IndexUsing_Default,
 			Constraint: sql.IndexConstraint_None,
-			Columns:    []sql.IndexColumn{{"b", 0}},
+			Columns:    []sql.IndexColumn{{Name: "b"}},
 			Comment:    "",
 		}},
 		nil,
@@ -230,7 +267,7 @@ var fixtures = map[string]sql.Node{
 			IndexName:  "idx_name",
 			Using:      sql.IndexUsing_Default,
 			Constraint: sql.IndexConstraint_None,
-			Columns:    []sql.IndexColumn{{"b", 0}},
+			Columns:    []sql.IndexColumn{{Name: "b"}},
 			Comment:    "hi",
 		}},
 		nil,
@@ -254,7 +291,7 @@ var fixtures = map[string]sql.Node{
 			IndexName:  "",
 			Using:      sql.IndexUsing_Default,
 			Constraint: sql.IndexConstraint_Unique,
-			Columns:    []sql.IndexColumn{{"b", 0}},
+			Columns:    []sql.IndexColumn{{Name: "b"}},
 			Comment:    "",
 		}},
 		nil,
@@ -278,7 +315,7 @@ var fixtures = map[string]sql.Node{
 			IndexName:  "",
 			Using:      sql.IndexUsing_Default,
 			Constraint: sql.IndexConstraint_Unique,
-			Columns:    []sql.IndexColumn{{"b", 0}},
+			Columns:    []sql.IndexColumn{{Name: "b"}},
 			Comment:    "",
 		}},
 		nil,
@@ -302,7 +339,7 @@ var fixtures = map[string]sql.Node{
 			IndexName:  "",
 			Using:      sql.IndexUsing_Default,
 			Constraint: sql.IndexConstraint_None,
-			Columns:    []sql.IndexColumn{{"b", 0}, {"a", 0}},
+			Columns:    []sql.IndexColumn{{Name: "b"}, {Name: "a"}},
 			Comment:    "",
 		}},
 		nil,
@@ -326,13 +363,13 @@ var fixtures = map[string]sql.Node{
 			IndexName:  "",
 			Using:      sql.IndexUsing_Default,
 			Constraint: sql.IndexConstraint_None,
-			Columns:    []sql.IndexColumn{{"b", 0}},
+			Columns:    []sql.IndexColumn{{Name: "b"}},
 			Comment:    "",
 		}, {
 			IndexName:  "",
 			Using:      sql.IndexUsing_Default,
 			Constraint: sql.IndexConstraint_None,
-			Columns:    []sql.IndexColumn{{"b", 0}, {"a", 0}},
+			Columns:    []sql.IndexColumn{{Name: "b"}, {Name: "a"}},
 			Comment:    "",
 		}},
 		nil,
@@ -553,6 +590,17 @@ var fixtures = map[string]sql.Node{
 			Nullable: false,
 		}, nil,
 	),
+	"ALTER TABLE foo ADD COLUMN `visible` INT NOT NULL INVISIBLE COMMENT 'visible'": plan.NewAddColumn(
+		sql.UnresolvedDatabase(""), "foo", &sql.Column{
+			Name:      "visible",
+			Type:      sql.Int32,
+			Nullable:  false,
+			Comment:   "visible",
+			Invisible: true,
+		}, nil,
+	),
+	`ALTER TABLE foo ALTER COLUMN bar SET INVISIBLE`: plan.NewAlterColumnVisibility(sql.UnresolvedDatabase(""), "foo", "bar", true),
+	`ALTER TABLE mydb.foo ALTER bar SET VISIBLE`:     plan.NewAlterColumnVisibility(sql.UnresolvedDatabase("mydb"), "foo", "bar", false),
 	`ALTER TABLE foo ADD COLUMN bar INT NOT NULL DEFAULT 42 COMMENT 'hello' AFTER baz`: plan.NewAddColumn(
 		sql.UnresolvedDatabase(""), "foo", &sql.Column{
 			Name:     "bar",
@@ -611,9 +659,82 @@ var fixtures = map[string]sql.Node{
 		"",
 		sql.IndexUsing_BTree,
 		sql.IndexConstraint_None,
-		[]sql.IndexColumn{{"v1", 0}},
+		[]sql.IndexColumn{{Name: "v1"}},
+		"",
+	),
+	`ALTER TABLE foo ADD INDEX (v1 DESC, v2 ASC)`: plan.NewAlterCreateIndex(
+		plan.NewUnresolvedTable("foo", ""),
+		"",
+		sql.IndexUsing_BTree,
+		sql.IndexConstraint_None,
+		[]sql.IndexColumn{{Name: "v1", Descending: true}, {Name: "v2"}},
+		"",
+	),
+	`ALTER TABLE foo ADD INDEX (v1(10), v2)`: plan.NewAlterCreateIndex(
+		plan.NewUnresolvedTable("foo", ""),
+		"",
+		sql.IndexUsing_BTree,
+		sql.IndexConstraint_None,
+		[]sql.IndexColumn{{Name: "v1", Length: 10}, {Name: "v2"}},
+		"",
+	),
+	"CREATE INDEX idx ON foo (`s`(10) DESC, (a + 1))": plan.NewAlterCreateIndex(
+		plan.NewUnresolvedTable("foo", ""),
+		"idx",
+		sql.IndexUsing_BTree,
+		sql.IndexConstraint_None,
+		[]sql.IndexColumn{
+			{Name: "s", Length: 10, Descending: true},
+			{Expression: expression.NewArithmetic(
+				expression.NewUnresolvedColumn("a"),
+				expression.NewLiteral(int8(1), sql.Int8),
+				"+",
+			)},
+		},
 		"",
 	),
+	"CREATE INDEX idx ON foo (a) INVISIBLE": &plan.AlterIndex{
+		Action:     plan.IndexAction_Create,
+		Table:      plan.NewUnresolvedTable("foo", ""),
+		IndexName:  "idx",
+		Using:      sql.IndexUsing_BTree,
+		Constraint: sql.IndexConstraint_None,
+		Columns:    []sql.IndexColumn{{Name: "a"}},
+		Invisible:  true,
+	},
+	"CREATE INDEX idx ON foo (a) COMMENT 'hi' VISIBLE": plan.NewAlterCreateIndex(
+		plan.NewUnresolvedTable("foo", ""),
+		"idx",
+		sql.IndexUsing_BTree,
+		sql.IndexConstraint_None,
+		[]sql.IndexColumn{{Name: "a"}},
+		"hi",
+	),
+	`ALTER TABLE foo ALTER INDEX idx INVISIBLE`:    plan.NewAlterIndexVisibility(plan.NewUnresolvedTable("foo", ""), "idx", false),
+	`ALTER TABLE mydb.foo ALTER INDEX idx VISIBLE`: plan.NewAlterIndexVisibility(plan.NewUnresolvedTable("foo", "mydb"), "idx", true),
+	"CREATE INDEX idx ON foo ((a + 1))": plan.NewAlterCreateIndex(
+		plan.NewUnresolvedTable("foo", ""),
+		"idx",
+		sql.IndexUsing_BTree,
+		sql.IndexConstraint_None,
+		[]sql.IndexColumn{{Expression: expression.NewArithmetic(
+			expression.NewUnresolvedColumn("a"),
+			expression.NewLiteral(int8(1), sql.Int8),
+			"+",
+		)}},
+		"",
+	),
+	"CREATE UNIQUE INDEX `Idx` ON mydb.foo (a, (lower(b)) DESC) USING HASH COMMENT 'hi'": plan.NewAlterCreateIndex(
+		plan.NewUnresolvedTable("foo", "mydb"),
+		"Idx",
+		sql.IndexUsing_Hash,
+		sql.IndexConstraint_Unique,
+		[]sql.IndexColumn{
+			{Name: "a"},
+			{Expression: expression.NewUnresolvedFunction("lower", false, expression.NewUnresolvedColumn("b")), Descending: true},
+		},
+		"hi",
+	),
 	`ALTER TABLE foo DROP COLUMN bar`: plan.NewDropColumn(
 		sql.UnresolvedDatabase(""), "foo", "bar",
 	),
@@ -659,6 +780,19 @@ var fixtures = map[string]sql.Node{
 			OnDelete:          sql.ForeignKeyReferenceOption_DefaultAction,
 		},
 	),
+	`ALTER TABLE db1.t1 ADD FOREIGN KEY (b_id) REFERENCES db0.t0(b)`: plan.NewAlterAddForeignKey(
+		plan.NewUnresolvedTable("t1", "db1"),
+		plan.NewUnresolvedTable("t0", "db0"),
+		&sql.ForeignKeyConstraint{
+			Name:               "",
+			Columns:            []string{"b_id"},
+			ReferencedDatabase: "db0",
+			ReferencedTable:    "t0",
+			ReferencedColumns:  []string{"b"},
+			OnUpdate:           sql.ForeignKeyReferenceOption_DefaultAction,
+			OnDelete:           sql.ForeignKeyReferenceOption_DefaultAction,
+		},
+	),
 	`ALTER TABLE t1 ADD FOREIGN KEY (b_id) REFERENCES t0(b) ON UPDATE CASCADE`: plan.NewAlterAddForeignKey(
 		plan.NewUnresolvedTable("t1", ""),
 		plan.NewUnresolvedTable("t0", ""),
@@ -730,12 +864,31 @@ var fixtures = map[string]sql.Node{
 			OnDelete:          sql.ForeignKeyReferenceOption_DefaultAction,
 		},
 	),
-	`ALTER TABLE t1 DROP CONSTRAINT fk_name`: plan.NewAlterDropForeignKey(
+	`ALTER TABLE t1 DROP CONSTRAINT fk_name`: plan.NewAlterDropConstraint(plan.NewUnresolvedTable("t1", ""), "fk_name"),
+	`ALTER TABLE t1 ADD CONSTRAINT chk CHECK (a > 0)`: plan.NewAlterAddCheck(
 		plan.NewUnresolvedTable("t1", ""),
-		&sql.ForeignKeyConstraint{
-			Name: "fk_name",
-		},
+		&sql.CheckDefinition{Name: "chk", CheckExpression: "a > 0", Enforced: true},
+		expression.NewGreaterThan(expression.NewUnresolvedColumn("a"), expression.NewLiteral(int8(0), sql.Int8)),
 	),
+	"ALTER TABLE mydb.t1 ADD CONSTRAINT `my chk` CHECK (a in (1, 2)) NOT ENFORCED": plan.NewAlterAddCheck(
+		plan.NewUnresolvedTable("t1", "mydb"),
+		&sql.CheckDefinition{Name: "my chk", CheckExpression: "a in (1, 2)", Enforced: false},
+		expression.NewInTuple(
+			expression.NewUnresolvedColumn("a"),
+			expression.NewTuple(expression.NewLiteral(int8(1), sql.Int8), expression.NewLiteral(int8(2), sql.Int8)),
+		),
+	),
+	`ALTER TABLE t1 ADD CHECK (a > 0) ENFORCED`: plan.NewAlterAddCheck(
+		plan.NewUnresolvedTable("t1", ""),
+		&sql.CheckDefinition{CheckExpression: "a > 0", Enforced: true},
+		expression.NewGreaterThan(expression.NewUnresolvedColumn("a"), expression.NewLiteral(int8(0), sql.Int8)),
+	),
+	`ALTER TABLE t1 ADD CONSTRAINT CHECK (a > 0)`: plan.NewAlterAddCheck(
+		plan.NewUnresolvedTable("t1", ""),
+		&sql.CheckDefinition{CheckExpression: "a > 0", Enforced: true},
+		expression.NewGreaterThan(expression.NewUnresolvedColumn("a"), expression.NewLiteral(int8(0), sql.Int8)),
+	),
+	`ALTER TABLE t1 DROP CHECK chk`: plan.NewAlterDropCheck(plan.NewUnresolvedTable("t1", ""), "chk"),
 	`DESCRIBE foo;`: plan.NewShowColumns(false,
 		plan.NewUnresolvedTable("foo", ""),
 	),
@@ -1190,6 +1343,15 @@ var fixtures = map[string]sql.Node{
 			plan.NewUnresolvedTable("foo", ""),
 		)),
 	),
+	`SELECT foo, bar FROM foo LIMIT :v1 OFFSET :v2;`: plan.NewBindLimit("v1",
+		plan.NewBindOffset("v2", plan.NewProject(
+			[]sql.Expression{
+				expression.NewUnresolvedColumn("foo"),
+				expression.NewUnresolvedColumn("bar"),
+			},
+			plan.NewUnresolvedTable("foo", ""),
+		)),
+	),
 	`SELECT * FROM foo WHERE (a = 1)`: plan.NewProject(
 		[]sql.Expression{
 			expression.NewStar(),
@@ -1363,6 +1525,37 @@ var fixtures = map[string]sql.Node{
 			plan.NewUnresolvedTable("foo", ""),
 		),
 	),
+	`DELETE FROM foo USING foo INNER JOIN bar ON a = b`: plan.NewDeleteFrom(
+		plan.NewInnerJoin(
+			plan.NewUnresolvedTable("foo", ""),
+			plan.NewUnresolvedTable("bar", ""),
+			expression.NewEquals(
+				expression.NewUnresolvedColumn("a"),
+				expression.NewUnresolvedColumn("b"),
+			),
+		),
+	).WithTargets([]string{"foo"}),
+	`DELETE f, bar FROM foo AS f, bar WHERE a = b`: plan.NewDeleteFrom(
+		plan.NewFilter(
+			expression.NewEquals(
+				expression.NewUnresolvedColumn("a"),
+				expression.NewUnresolvedColumn("b"),
+			),
+			plan.NewCrossJoin(
+				plan.NewTableAlias("f", plan.NewUnresolvedTable("foo", "")),
+				plan.NewUnresolvedTable("bar", ""),
+			),
+		),
+	).WithTargets([]string{"f", "bar"}),
+	`DELETE foo FROM foo WHERE a = 1`: plan.NewDeleteFrom(
+		plan.NewFilter(
+			expression.NewEquals(
+				expression.NewUnresolvedColumn("a"),
+				expression.NewLiteral(int8(1), sql.Int8),
+			),
+			plan.NewUnresolvedTable("foo", ""),
+		),
+	),
 	`SELECT * FROM foo INNER JOIN bar ON a = b`: plan.NewProject(
 		[]sql.Expression{expression.NewStar()},
 		plan.NewInnerJoin(
@@ -1609,7 +1802,7 @@ var fixtures = map[string]sql.Node{
 		sql.IndexUsing_BTree,
 		sql.IndexConstraint_None,
 		[]sql.IndexColumn{
-			{"bar", 0},
+			{Name: "bar"},
 		},
 		"",
 	),
@@ -1619,7 +1812,7 @@ var fixtures = map[string]sql.Node{
 		sql.IndexUsing_BTree,
 		sql.IndexConstraint_None,
 		[]sql.IndexColumn{
-			{"bar", 0},
+			{Name: "bar"},
 		},
 		"",
 	),
@@ -1705,7 +1898,45 @@ var fixtures = map[string]sql.Node{
 			expression.NewSetField(expression.NewUnresolvedColumn("@@sql_select_limit"), expression.NewDefaultColumn("")),
 		},
 	),
-	`/*!40101 SET NAMES utf8 */`: plan.Nothing,
+	`SET PERSIST wait_timeout = 60, PERSIST_ONLY max_connections = 10`: plan.NewSet(
+		[]sql.Expression{
+			expression.NewSetField(expression.NewUnresolvedColumn("@@persist.wait_timeout"), expression.NewLiteral(int8(60), sql.Int8)),
+			expression.NewSetField(expression.NewUnresolvedColumn("@@persist_only.max_connections"), expression.NewLiteral(int8(10), sql.Int8)),
+		},
+	),
+	`SELECT @a := i + 1 AS x, @b := 'foo' FROM foo`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewAlias("x", expression.NewUserVarAssignment(
+				expression.NewUserVar("a"),
+				expression.NewArithmetic(expression.NewUnresolvedColumn("i"), expression.NewLiteral(int8(1), sql.Int8), "+"),
+			)),
+			expression.NewUserVarAssignment(expression.NewUserVar("b"), expression.NewLiteral("foo", sql.LongText)),
+		},
+		plan.NewUnresolvedTable("foo", ""),
+	),
+	`SET @a := 1, @b = @c := 2`: plan.NewSet(
+		[]sql.Expression{
+			expression.NewSetField(expression.NewUnresolvedColumn("@a"), expression.NewLiteral(int8(1), sql.Int8)),
+			expression.NewSetField(
+				expression.NewUnresolvedColumn("@b"),
+				expression.NewUserVarAssignment(expression.NewUserVar("c"), expression.NewLiteral(int8(2), sql.Int8)),
+			),
+		},
+	),
+	`PREPARE stmt FROM 'SELECT * FROM foo WHERE a = ?'`: plan.NewPrepareQuery("stmt", "SELECT * FROM foo WHERE a = ?"),
+	`DEALLOCATE PREPARE stmt`:                           plan.NewDeallocateQuery("stmt"),
+	`DROP PREPARE stmt`:                                 plan.NewDeallocateQuery("stmt"),
+	`RESET PERSIST`:                                     plan.NewResetPersist("", false),
+	`RESET PERSIST wait_timeout`:                        plan.NewResetPersist("wait_timeout", false),
+	`RESET PERSIST IF EXISTS wait_timeout`:              plan.NewResetPersist("wait_timeout", true),
+	`DUMP`:                                              plan.NewDump(nil),
+	`DUMP ALL DATABASES`:                                plan.NewDump(nil),
+	`DUMP DATABASES mydb, otherdb`:                      plan.NewDump([]plan.DumpTarget{{Database: "mydb"}, {Database: "otherdb"}}),
+	`/*!40101 SET NAMES utf8 */`:                        plan.Nothing,
+	`dump table mytable, otherdb.t1, t2`: plan.NewDump([]plan.DumpTarget{
+		{Tables: []string{"mytable", "t2"}},
+		{Database: "otherdb", Tables: []string{"t1"}},
+	}),
 	`SELECT /*!40101 SET NAMES utf8 */ * FROM foo`: plan.NewProject(
 		[]sql.Expression{
 			expression.NewStar(),
@@ -1779,11 +2010,13 @@ var fixtures = map[string]sql.Node{
 		},
 		plan.NewUnresolvedTable("bar", "foo"),
 	),
-	`SHOW VARIABLES`:                           plan.NewShowVariables(sql.NewEmptyContext().GetAll(), ""),
-	`SHOW GLOBAL VARIABLES`:                    plan.NewShowVariables(sql.NewEmptyContext().GetAll(), ""),
-	`SHOW SESSION VARIABLES`:                   plan.NewShowVariables(sql.NewEmptyContext().GetAll(), ""),
-	`SHOW VARIABLES LIKE 'gtid_mode'`:          plan.NewShowVariables(sql.NewEmptyContext().GetAll(), "gtid_mode"),
-	`SHOW SESSION VARIABLES LIKE 'autocommit'`: plan.NewShowVariables(sql.NewEmptyContext().GetAll(), "autocommit"),
+	`SHOW VARIABLES`:                           plan.NewShowVariables("", false),
+	`SHOW GLOBAL VARIABLES`:                    plan.NewShowVariables("", true),
+	`SHOW SESSION VARIABLES`:                   plan.NewShowVariables("", false),
+	`SHOW VARIABLES LIKE 'gtid_mode'`:          plan.NewShowVariables("gtid_mode", false),
+	`SHOW SESSION VARIABLES LIKE 'autocommit'`: plan.NewShowVariables("autocommit", false),
+	`SHOW STATUS`:                              plan.NewShowStatus("", false),
+	`SHOW GLOBAL STATUS LIKE 'Locked%'`:        plan.NewShowStatus("locked%", true),
 	`UNLOCK TABLES`:                            plan.NewUnlockTables(),
 	`LOCK TABLES foo READ`: plan.NewLockTables([]*plan.TableLock{
 		{Table: plan.NewUnresolvedTable("foo", "")},
@@ -1806,6 +2039,144 @@ var fixtures = map[string]sql.Node{
 	`LOCK TABLES foo LOW_PRIORITY WRITE`: plan.NewLockTables([]*plan.TableLock{
 		{Table: plan.NewUnresolvedTable("foo", ""), Write: true},
 	}),
+	`CREATE USER bob`: plan.NewCreateUser([]plan.UserSpec{
+		{Account: sql.Account{Name: "bob", Host: "%"}},
+	}, false, sql.TLSRequirement{}, nil, nil),
+	"CREATE USER IF NOT EXISTS 'Bob'@'localhost' IDENTIFIED BY 'it''s', `alice`@`10.0.%`, carol@127.0.0.1 IDENTIFIED BY \"pw\"": plan.NewCreateUser([]plan.UserSpec{
+		{Account: sql.Account{Name: "Bob", Host: "localhost"}, Password: "it's"},
+		{Account: sql.Account{Name: "alice", Host: "10.0.%"}},
+		{Account: sql.Account{Name: "carol", Host: "127.0.0.1"}, Password: "pw"},
+	}, true, sql.TLSRequirement{}, nil, nil),
+	`CREATE USER bob IDENTIFIED BY 'pw', alice REQUIRE SSL`: plan.NewCreateUser([]plan.UserSpec{
+		{Account: sql.Account{Name: "bob", Host: "%"}, Password: "pw"},
+		{Account: sql.Account{Name: "alice", Host: "%"}},
+	}, false, sql.TLSRequirement{SSL: true}, nil, nil),
+	`CREATE USER bob REQUIRE X509`: plan.NewCreateUser([]plan.UserSpec{
+		{Account: sql.Account{Name: "bob", Host: "%"}},
+	}, false, sql.TLSRequirement{SSL: true, X509: true}, nil, nil),
+	`CREATE USER bob REQUIRE ISSUER '/CN=ca' AND SUBJECT '/O=acme/CN=bob'`: plan.NewCreateUser([]plan.UserSpec{
+		{Account: sql.Account{Name: "bob", Host: "%"}},
+	}, false, sql.TLSRequirement{SSL: true, X509: true, Subject: "/O=acme/CN=bob", Issuer: "/CN=ca"}, nil, nil),
+	`CREATE USER bob IDENTIFIED BY 'pw' PASSWORD EXPIRE`: plan.NewCreateUser([]plan.UserSpec{
+		{Account: sql.Account{Name: "bob", Host: "%"}, Password: "pw"},
+	}, false, sql.TLSRequirement{}, nil, &sql.PasswordExpire{Now: true}),
+	`CREATE USER bob REQUIRE SSL PASSWORD EXPIRE INTERVAL 90 DAY`: plan.NewCreateUser([]plan.UserSpec{
+		{Account: sql.Account{Name: "bob", Host: "%"}},
+	}, false, sql.TLSRequirement{SSL: true}, nil, &sql.PasswordExpire{Lifetime: sql.PasswordLifetimeInterval, Days: 90}),
+	`ALTER USER bob, alice PASSWORD EXPIRE`: plan.NewAlterUser([]sql.Account{
+		{Name: "bob", Host: "%"},
+		{Name: "alice", Host: "%"},
+	}, false, nil, &sql.PasswordExpire{Now: true}),
+	`ALTER USER IF EXISTS 'bob'@'localhost' PASSWORD EXPIRE NEVER`: plan.NewAlterUser([]sql.Account{
+		{Name: "bob", Host: "localhost"},
+	}, true, nil, &sql.PasswordExpire{Lifetime: sql.PasswordLifetimeNever}),
+	`ALTER USER bob PASSWORD EXPIRE DEFAULT`: plan.NewAlterUser([]sql.Account{
+		{Name: "bob", Host: "%"},
+	}, false, nil, &sql.PasswordExpire{}),
+	`CREATE USER bob REQUIRE SSL WITH MAX_USER_CONNECTIONS 10 PASSWORD EXPIRE NEVER`: plan.NewCreateUser([]plan.UserSpec{
+		{Account: sql.Account{Name: "bob", Host: "%"}},
+	}, false, sql.TLSRequirement{SSL: true}, &sql.ResourceLimits{MaxUserConnections: 10}, &sql.PasswordExpire{Lifetime: sql.PasswordLifetimeNever}),
+	`ALTER USER bob WITH MAX_USER_CONNECTIONS 2 MAX_USER_CONNECTIONS 3`: plan.NewAlterUser([]sql.Account{
+		{Name: "bob", Host: "%"},
+	}, false, &sql.ResourceLimits{MaxUserConnections: 3}, nil),
+	`ALTER USER bob WITH MAX_USER_CONNECTIONS 0 PASSWORD EXPIRE`: plan.NewAlterUser([]sql.Account{
+		{Name: "bob", Host: "%"},
+	}, false, &sql.ResourceLimits{}, &sql.PasswordExpire{Now: true}),
+	`DROP USER IF EXISTS bob, 'alice'@'%'`: plan.NewDropUser([]sql.Account{
+		{Name: "bob", Host: "%"},
+		{Name: "alice", Host: "%"},
+	}, true),
+	`SET PASSWORD = 'secret'`:                       plan.NewSetPassword(nil, "secret"),
+	`SET PASSWORD FOR 'bob'@'localhost' = 'secret'`: plan.NewSetPassword(&sql.Account{Name: "bob", Host: "localhost"}, "secret"),
+	`GRANT SELECT, INSERT ON mydb.* TO bob`: plan.NewGrant(sql.PrivilegeSelect|sql.PrivilegeInsert, nil, sql.PrivilegeLevel{Database: "mydb"}, []sql.Account{
+		{Name: "bob", Host: "%"},
+	}, false),
+	"GRANT ALL PRIVILEGES ON *.* TO 'bob'@'localhost', alice WITH GRANT OPTION": plan.NewGrant(sql.PrivilegeAll, nil, sql.PrivilegeLevel{}, []sql.Account{
+		{Name: "bob", Host: "localhost"},
+		{Name: "alice", Host: "%"},
+	}, true),
+	"GRANT CREATE VIEW, lock tables ON TABLE `my db`.`my table` TO bob": plan.NewGrant(sql.PrivilegeCreateView|sql.PrivilegeLockTables, nil, sql.PrivilegeLevel{Database: "my db", Table: "my table"}, []sql.Account{
+		{Name: "bob", Host: "%"},
+	}, false),
+	`REVOKE UPDATE, GRANT OPTION ON mydb.mytable FROM bob`: plan.NewRevoke(sql.PrivilegeUpdate|sql.PrivilegeGrantOption, nil, sql.PrivilegeLevel{Database: "mydb", Table: "mytable"}, []sql.Account{
+		{Name: "bob", Host: "%"},
+	}),
+	`REVOKE ALL PRIVILEGES, GRANT OPTION FROM bob, alice`: plan.NewRevokeAll([]sql.Account{
+		{Name: "bob", Host: "%"},
+		{Name: "alice", Host: "%"},
+	}),
+	"GRANT SELECT (a, `B`), INSERT (a), UPDATE ON mydb.mytable TO bob": plan.NewGrant(sql.PrivilegeUpdate, []sql.ColumnGrant{
+		{Column: "a", Privileges: sql.PrivilegeSelect | sql.PrivilegeInsert},
+		{Column: "b", Privileges: sql.PrivilegeSelect},
+	}, sql.PrivilegeLevel{Database: "mydb", Table: "mytable"}, []sql.Account{
+		{Name: "bob", Host: "%"},
+	}, false),
+	`REVOKE UPDATE(a) ON mydb.mytable FROM bob`: plan.NewRevoke(sql.PrivilegeUsage, []sql.ColumnGrant{
+		{Column: "a", Privileges: sql.PrivilegeUpdate},
+	}, sql.PrivilegeLevel{Database: "mydb", Table: "mytable"}, []sql.Account{
+		{Name: "bob", Host: "%"},
+	}),
+	`CREATE ROLE IF NOT EXISTS app_read, 'app_write'@'localhost'`: plan.NewCreateRole([]sql.Account{
+		{Name: "app_read", Host: "%"},
+		{Name: "app_write", Host: "localhost"},
+	}, true),
+	`DROP ROLE app_read`: plan.NewDropRole([]sql.Account{
+		{Name: "app_read", Host: "%"},
+	}, false),
+	`GRANT app_read, app_write TO bob WITH ADMIN OPTION`: plan.NewGrantRole([]sql.Account{
+		{Name: "app_read", Host: "%"},
+		{Name: "app_write", Host: "%"},
+	}, []sql.Account{
+		{Name: "bob", Host: "%"},
+	}, true),
+	`REVOKE app_read FROM bob, alice`: plan.NewRevokeRole([]sql.Account{
+		{Name: "app_read", Host: "%"},
+	}, []sql.Account{
+		{Name: "bob", Host: "%"},
+		{Name: "alice", Host: "%"},
+	}),
+	`SET ROLE DEFAULT`:                 plan.NewSetRole(plan.RoleDefault, nil),
+	`SET ROLE NONE`:                    plan.NewSetRole(plan.RoleNone, nil),
+	`SET ROLE ALL`:                     plan.NewSetRole(plan.RoleAll, nil),
+	`SET ROLE ALL EXCEPT app_read`:     plan.NewSetRole(plan.RoleAll, []sql.Account{{Name: "app_read", Host: "%"}}),
+	`SET ROLE allowed, 'none'@'%'`:     plan.NewSetRole(plan.RoleList, []sql.Account{{Name: "allowed", Host: "%"}, {Name: "none", Host: "%"}}),
+	`SET DEFAULT ROLE ALL TO bob`:      plan.NewSetDefaultRole(plan.RoleAll, nil, []sql.Account{{Name: "bob", Host: "%"}}),
+	`SET DEFAULT ROLE app_read TO bob`: plan.NewSetDefaultRole(plan.RoleList, []sql.Account{{Name: "app_read", Host: "%"}}, []sql.Account{{Name: "bob", Host: "%"}}),
+	"GRANT PROXY ON 'bob'@'localhost' TO middleware WITH GRANT OPTION": plan.NewGrantProxy(sql.Account{Name: "bob", Host: "localhost"}, []sql.Account{
+		{Name: "middleware", Host: "%"},
+	}, true),
+	`REVOKE PROXY ON bob FROM middleware, alice`: plan.NewRevokeProxy(sql.Account{Name: "bob", Host: "%"}, []sql.Account{
+		{Name: "middleware", Host: "%"},
+		{Name: "alice", Host: "%"},
+	}),
+	`SHOW GRANTS`:                       plan.NewShowGrants(nil, nil),
+	`SHOW GRANTS FOR CURRENT_USER()`:    plan.NewShowGrants(nil, nil),
+	`SHOW GRANTS FOR 'bob'@'localhost'`: plan.NewShowGrants(&sql.Account{Name: "bob", Host: "localhost"}, nil),
+	`SHOW GRANTS FOR bob USING app_read, r2`: plan.NewShowGrants(&sql.Account{Name: "bob", Host: "%"}, []sql.Account{
+		{Name: "app_read", Host: "%"},
+		{Name: "r2", Host: "%"},
+	}),
+	`SHOW BINARY LOGS`:   plan.NewShowBinaryLogs(),
+	`show master logs`:   plan.NewShowBinaryLogs(),
+	`SHOW BINLOG EVENTS`: plan.NewShowBinlogEvents("", 0, 0, -1),
+	`SHOW BINLOG EVENTS IN 'binlog.000002' FROM 154`:   plan.NewShowBinlogEvents("binlog.000002", 154, 0, -1),
+	`SHOW BINLOG EVENTS FROM 4 LIMIT 10`:               plan.NewShowBinlogEvents("", 4, 0, 10),
+	`SHOW BINLOG EVENTS IN "binlog.000001" LIMIT 2, 5`: plan.NewShowBinlogEvents("binlog.000001", 0, 2, 5),
+	`SHOW MASTER STATUS`:                               plan.NewShowMasterStatus(),
+	`CHANGE REPLICATION SOURCE TO SOURCE_HOST = 'h', SOURCE_PORT = 3307`: plan.NewChangeReplicationSource([]sql.ReplicaOption{
+		{Name: sql.ReplicaSourceHost, Value: "h"},
+		{Name: sql.ReplicaSourcePort, Value: uint64(3307)},
+	}),
+	`change master to master_user='repl',master_password = "pw", master_auto_position = 1`: plan.NewChangeReplicationSource([]sql.ReplicaOption{
+		{Name: sql.ReplicaSourceUser, Value: "repl"},
+		{Name: sql.ReplicaSourcePassword, Value: "pw"},
+		{Name: sql.ReplicaSourceAutoPosition, Value: uint64(1)},
+	}),
+	`START REPLICA`:       plan.NewStartReplica(),
+	`start slave`:         plan.NewStartReplica(),
+	`STOP REPLICA`:        plan.NewStopReplica(),
+	`SHOW REPLICA STATUS`: plan.NewShowReplicaStatus(),
+	`show slave status`:   plan.NewShowReplicaStatus(),
 	`LOCK TABLES foo WRITE, bar READ`: plan.NewLockTables([]*plan.TableLock{
 		{Table: plan.NewUnresolvedTable("foo", ""), Write: true},
 		{Table: plan.NewUnresolvedTable("bar", "")},
@@ -1819,17 +2190,42 @@ var fixtures = map[string]sql.Node{
 		{Table: plan.NewUnresolvedTable("bar", ""), Write: true},
 		{Table: plan.NewUnresolvedTable("baz", "")},
 	}),
-	`SHOW CREATE DATABASE foo`:                 plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), false),
-	`SHOW CREATE SCHEMA foo`:                   plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), false),
-	`SHOW CREATE DATABASE IF NOT EXISTS foo`:   plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), true),
-	`SHOW CREATE SCHEMA IF NOT EXISTS foo`:     plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), true),
-	`SHOW WARNINGS`:                            plan.NewOffset(0, plan.ShowWarnings(sql.NewEmptyContext().Warnings())),
-	`SHOW WARNINGS LIMIT 10`:                   plan.NewLimit(10, plan.NewOffset(0, plan.ShowWarnings(sql.NewEmptyContext().Warnings()))),
-	`SHOW WARNINGS LIMIT 5,10`:                 plan.NewLimit(10, plan.NewOffset(5, plan.ShowWarnings(sql.NewEmptyContext().Warnings()))),
-	"SHOW CREATE DATABASE `foo`":               plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), false),
-	"SHOW CREATE SCHEMA `foo`":                 plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), false),
-	"SHOW CREATE DATABASE IF NOT EXISTS `foo`": plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), true),
-	"SHOW CREATE SCHEMA IF NOT EXISTS `foo`":   plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), true),
+	`SHOW CREATE DATABASE foo`:               plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), false),
+	`SHOW CREATE SCHEMA foo`:                 plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), false),
+	`SHOW CREATE DATABASE IF NOT EXISTS foo`: plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), true),
+	`SHOW CREATE SCHEMA IF NOT EXISTS foo`:   plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), true),
+	`SHOW WARNINGS`:                          plan.NewOffset(0, plan.ShowWarnings(sql.NewEmptyContext().Warnings())),
+	`SHOW WARNINGS LIMIT 10`:                 plan.NewLimit(10, plan.NewOffset(0, plan.ShowWarnings(sql.NewEmptyContext().Warnings()))),
+	`SHOW WARNINGS LIMIT 5,10`:               plan.NewLimit(10, plan.NewOffset(5, plan.ShowWarnings(sql.NewEmptyContext().Warnings()))),
+	`SELECT SQL_CALC_FOUND_ROWS a FROM foo LIMIT 1, 2`: func() sql.Node {
+		limit := plan.NewLimit(2, plan.NewOffset(1, plan.NewProject(
+			[]sql.Expression{expression.NewUnresolvedColumn("a")},
+			plan.NewUnresolvedTable("foo", ""),
+		)))
+		limit.CalcFoundRows = true
+		return limit
+	}(),
+	`SHOW COUNT(*) WARNINGS`: plan.NewShowWarningCount(0),
+	`SHOW ERRORS`:            plan.NewOffset(0, plan.ShowWarnings(nil)),
+	`SHOW COUNT(*) ERRORS`:   plan.NewShowErrorCount(0),
+	`GET DIAGNOSTICS @n = NUMBER`: plan.NewGetDiagnostics(sql.NewEmptyContext().Warnings(), nil, []plan.DiagnosticsItem{
+		{Target: "n", Name: "NUMBER"},
+	}),
+	`GET CURRENT DIAGNOSTICS CONDITION 1 @s = RETURNED_SQLSTATE, @m = message_text`: plan.NewGetDiagnostics(
+		sql.NewEmptyContext().Warnings(),
+		expression.NewLiteral(int8(1), sql.Int8),
+		[]plan.DiagnosticsItem{{Target: "s", Name: "RETURNED_SQLSTATE"}, {Target: "m", Name: "MESSAGE_TEXT"}},
+	),
+	"SHOW CREATE DATABASE `foo`":                                            plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), false),
+	"SHOW CREATE SCHEMA `foo`":                                              plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), false),
+	"SHOW CREATE DATABASE IF NOT EXISTS `foo`":                              plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), true),
+	"SHOW CREATE SCHEMA IF NOT EXISTS `foo`":                                plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), true),
+	`CREATE DATABASE foo`:                                                   plan.NewCreateDatabase("foo", false, sql.Collation_Default),
+	`CREATE SCHEMA IF NOT EXISTS foo`:                                       plan.NewCreateDatabase("foo", true, sql.Collation_Default),
+	`CREATE DATABASE foo CHARACTER SET latin1`:                              plan.NewCreateDatabase("foo", false, sql.Collation_latin1_swedish_ci),
+	"CREATE DATABASE `foo` DEFAULT CHARSET = 'utf8mb4' COLLATE utf8mb4_bin": plan.NewCreateDatabase("foo", false, sql.Collation_utf8mb4_bin),
+	`ALTER DATABASE foo COLLATE = latin1_bin`:                               plan.NewAlterDatabase(sql.UnresolvedDatabase("foo"), sql.Collation_latin1_bin),
+	`ALTER SCHEMA DEFAULT CHARACTER SET latin1`:                             plan.NewAlterDatabase(sql.UnresolvedDatabase(""), sql.Collation_latin1_swedish_ci),
 	"SELECT CASE foo WHEN 1 THEN 'foo' WHEN 2 THEN 'bar' ELSE 'baz' END": plan.NewProject(
 		[]sql.Expression{expression.NewCase(
 			expression.NewUnresolvedColumn("foo"),
@@ -2089,6 +2485,17 @@ var fixtures = map[string]sql.Node{
 		[]sql.Expression{},
 		plan.NewUnresolvedTable("foo", ""),
 	),
+	`SELECT AVG(DISTINCT i) FROM foo`: plan.NewGroupBy(
+		[]sql.Expression{
+			func() sql.Expression {
+				uf := expression.NewUnresolvedFunction("avg", true, expression.NewUnresolvedColumn("i"))
+				uf.Distinct = true
+				return uf
+			}(),
+		},
+		[]sql.Expression{},
+		plan.NewUnresolvedTable("foo", ""),
+	),
 	`SELECT -128, 127, 255, -32768, 32767, 65535, -2147483648, 2147483647, 4294967295, -9223372036854775808, 9223372036854775807, 18446744073709551615`: plan.NewProject(
 		[]sql.Expression{
 			expression.NewLiteral(int8(math.MinInt8), sql.Int8),
@@ -2342,9 +2749,52 @@ func assertNodesEqualWithDiff(t *testing.T, expected, actual sql.Node) {
 }
 
 var fixturesErrors = map[string]*errors.Kind{
-	`SHOW METHEMONEY`:                                         ErrUnsupportedFeature,
-	`LOCK TABLES foo AS READ`:                                 errUnexpectedSyntax,
-	`LOCK TABLES foo LOW_PRIORITY READ`:                       errUnexpectedSyntax,
+	`SHOW METHEMONEY`: ErrUnsupportedFeature,
+	`UPDATE foo, bar SET foo.a = 1 ORDER BY foo.a`:               ErrIncorrectUsage,
+	`UPDATE foo JOIN bar ON foo.a = bar.a SET foo.a = 1 LIMIT 1`: ErrIncorrectUsage,
+	`GET DIAGNOSTICS @n = MESSAGE_TEXT`:                          errUnexpectedSyntax,
+	`GET DIAGNOSTICS CONDITION 1 n = MESSAGE_TEXT`:               errUnexpectedSyntax,
+	`GET STACKED DIAGNOSTICS @n = NUMBER`:                        sql.ErrStackedDiagnosticsWithoutHandler,
+	`LOCK TABLES foo AS READ`:                                    errUnexpectedSyntax,
+	`LOCK TABLES foo LOW_PRIORITY READ`:                          errUnexpectedSyntax,
+	`CREATE USER bob IDENTIFIED BY secret`:                       errUnexpectedSyntax,
+	`SET PASSWORD FOR bob 'secret'`:                              errUnexpectedSyntax,
+	`CREATE USER bob REQUIRE CIPHER`:                             errUnexpectedSyntax,
+	`CREATE USER b REQUIRE SUBJECT 'x' AND CIPHER 'y'`:           errUnexpectedSyntax,
+	`GRANT SELECT ON * TO bob`:                                   sql.ErrNoDatabaseSelected,
+	`GRANT SELECT, ON mydb.* TO bob`:                             errUnexpectedSyntax,
+	`GRANT FLY ON mydb.* TO bob`:                                 errUnexpectedSyntax,
+	`GRANT SELECT ON *.mytable TO bob`:                           errUnexpectedSyntax,
+	`REVOKE SELECT ON mydb.* TO bob`:                             errUnexpectedSyntax,
+	`GRANT SELECT (a) INSERT ON mydb.mytable TO bob`:             errUnexpectedSyntax,
+	`GRANT SELECT (a ON mydb.mytable TO bob`:                     errUnexpectedSyntax,
+	`GRANT app_read TO bob WITH GRANT OPTION`:                    errUnexpectedSyntax,
+	`SET DEFAULT ROLE DEFAULT TO bob`:                            errUnexpectedSyntax,
+	`SET ROLE NONE, app_read`:                                    errUnexpectedSyntax,
+	`GRANT PROXY ON bob, alice TO middleware`:                    errUnexpectedSyntax,
+	`ALTER USER bob PASSWORD EXPIRE INTERVAL 0 DAY`:              errUnexpectedSyntax,
+	`ALTER USER bob PASSWORD EXPIRE SOON`:                        errUnexpectedSyntax,
+	`ALTER USER bob WITH MAX_QUERIES_PER_HOUR 10`:                errUnexpectedSyntax,
+	`ALTER USER bob WITH MAX_USER_CONNECTIONS -1`:                errUnexpectedSyntax,
+	`ALTER USER bob`:                                             errUnexpectedSyntax,
+	`REVOKE PROXY ON bob TO middleware`:                          errUnexpectedSyntax,
+	`SHOW GRANTS USING app_read FOR bob`:                         errUnexpectedSyntax,
+	`SHOW BINLOG EVENTS FROM`:                                    errUnexpectedSyntax,
+	`SHOW BINLOG EVENTS LIMIT 1, `:                               errUnexpectedSyntax,
+	`CHANGE REPLICATION SOURCE TO SOURCE_DELAY = 1`:              sql.ErrUnknownReplicaOption,
+	`CHANGE REPLICATION SOURCE TO SOURCE_PORT = 'x'`:             sql.ErrInvalidReplicaOption,
+	`CHANGE REPLICATION SOURCE TO SOURCE_HOST = 1`:               errUnexpectedSyntax,
+	`EXECUTE stmt`:                                            sql.ErrUnknownPreparedStatement,
+	`PREPARE stmt FROM 'EXECUTE other'`:                       ErrUnsupportedFeature,
+	`PREPARE stmt FROM 1`:                                     errUnexpectedSyntax,
+	`EXECUTE stmt USING 1`:                                    errUnexpectedSyntax,
+	`RESET PERSIST IF EXISTS`:                                 errUnexpectedSyntax,
+	`RESET PERSIST wait_timeout, net_read_timeout`:            errUnexpectedSyntax,
+	`DUMP ALL TABLES FROM mydb`:                               errUnexpectedSyntax,
+	`DUMP DATABASE mydb.mytable`:                              errUnexpectedSyntax,
+	`DUMP VIEWS myview, otherview`:                            errUnexpectedSyntax,
+	`CREATE DATABASE foo ENGINE = InnoDB`:                     errUnexpectedSyntax,
+	`ALTER DATABASE foo`:                                      errUnexpectedSyntax,
 	`SELECT * FROM mytable LIMIT -100`:                        ErrUnsupportedSyntax,
 	`SELECT * FROM mytable LIMIT 100 OFFSET -1`:               ErrUnsupportedSyntax,
 	`SELECT INTERVAL 1 DAY - '2018-05-01'`:                    ErrUnsupportedSyntax,
@@ -2353,10 +2803,14 @@ var fixturesErrors = map[string]*errors.Kind{
 	`SELECT '2018-05-01' / INTERVAL 1 DAY`:                    ErrUnsupportedSyntax,
 	`SELECT INTERVAL 1 DAY + INTERVAL 1 DAY`:                  ErrUnsupportedSyntax,
 	`SELECT '2018-05-01' + (INTERVAL 1 DAY + INTERVAL 1 DAY)`: ErrUnsupportedSyntax,
-	`SELECT AVG(DISTINCT foo) FROM b`:                         ErrUnsupportedSyntax,
-	`CREATE VIEW myview AS SELECT AVG(DISTINCT foo) FROM b`:   ErrUnsupportedSyntax,
 	"DESCRIBE FORMAT=pretty SELECT * FROM foo":                errInvalidDescribeFormat,
 	`CREATE TABLE test (pk int, primary key(pk, noexist))`:    ErrUnknownIndexColumn,
+	`CREATE INDEX idx ON foo ((a))`:                           errInvalidIndexExpression,
+	`CREATE INDEX idx ON foo (a, (b + 1)`:                     errUnexpectedSyntax,
+	`CREATE INDEX idx ON foo ((a + 1)) USING RTREE`:           ErrUnsupportedFeature,
+	`ALTER TABLE foo ALTER INDEX idx HIDDEN`:                  errUnexpectedSyntax,
+	`ALTER TABLE t1 ADD CHECK (a > 0) NOT`:                    errUnexpectedSyntax,
+	`ALTER TABLE t1 DROP CHECK chk extra`:                     errUnexpectedSyntax,
 }
 
 func TestParseErrors(t *testing.T) {
@@ -2427,6 +2881,7 @@ func TestFixSetQuery(t *testing.T) {
 		{"set session foo = 1, session bar = 2", "set @@session.foo = 1, @@session.bar = 2"},
 		{"set global foo = 1, session bar = 2", "set @@global.foo = 1, @@session.bar = 2"},
 		{"set SESSION foo = 1, GLOBAL bar = 2", "set @@session.foo = 1, @@global.bar = 2"},
+		{"set persist foo = 1, PERSIST_ONLY bar = 2", "set @@persist.foo = 1, @@persist_only.bar = 2"},
 	}
 
 	for _, tt := range testCases {
@@ -2436,6 +2891,31 @@ func TestFixSetQuery(t *testing.T) {
 	}
 }
 
+func TestFixUserVarAssignments(t *testing.T) {
+	testCases := []struct {
+		in  string
+		set bool
+		out string
+	}{
+		{"set @a := 1, @b := 2", true, "set @a = 1, @b = 2"},
+		{"set session foo := 1", true, "set session foo = 1"},
+		{"set @a = @b := 1 + 2", true, "set @a = gms_user_var_assignment('b', 1 + 2)"},
+		{"set @a = f(1, @b := 2)", true, "set @a = f(1, gms_user_var_assignment('b', 2))"},
+		{"select @a := 1, ':=' from t", false, "select gms_user_var_assignment('a', 1), ':=' from t"},
+		{"select @a := @b := x or y as z", false, "select gms_user_var_assignment('a', gms_user_var_assignment('b', x or y)) as z"},
+		{"select (@a := 1) + 1", false, "select (gms_user_var_assignment('a', 1)) + 1"},
+		{"select case when x then @a := 1 end, @a := case when 1 then 2 else 3 end from t", false,
+			"select case when x then gms_user_var_assignment('a', 1) end, gms_user_var_assignment('a', case when 1 then 2 else 3 end) from t"},
+		{"select @a := @from where 1", false, "select gms_user_var_assignment('a', @from) where 1"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+			require.Equal(t, tt.out, fixUserVarAssignments(tt.in, tt.set))
+		})
+	}
+}
+
 func TestPrintTree(t *testing.T) {
 	require := require.New(t)
 	node, err := Parse(sql.NewEmptyContext(), `
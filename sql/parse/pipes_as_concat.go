@@ -0,0 +1,87 @@
+package parse
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// concatPipesKey is the key of the value of the context of a conversion holding the OR expressions of the statement
+// converted that were written with ||, which are concatenations while sql_mode has PIPES_AS_CONCAT.
+type concatPipesKey struct{}
+
+// withConcatPipes returns the context given with the OR expressions of the statement given that were written with ||
+// in the query given, if sql_mode has PIPES_AS_CONCAT. The SQL parser gives both || and OR as OR expressions, which are
+// told apart by their order in the query.
+func withConcatPipes(ctx *sql.Context, query string, stmt sqlparser.Statement) *sql.Context {
+	if !strings.Contains(query, "||") || !sql.SqlModeEnabled(ctx, sql.SqlMode_PipesAsConcat) {
+		return ctx
+	}
+
+	// The operators of nested OR expressions are in the query in the order of an in-order walk of them
+	var ors []*sqlparser.OrExpr
+	var visit sqlparser.Visit
+	visit = func(node sqlparser.SQLNode) (bool, error) {
+		or, ok := node.(*sqlparser.OrExpr)
+		if !ok {
+			return true, nil
+		}
+		_ = sqlparser.Walk(visit, or.Left)
+		ors = append(ors, or)
+		_ = sqlparser.Walk(visit, or.Right)
+		return false, nil
+	}
+	_ = sqlparser.Walk(visit, stmt)
+
+	operators := orOperators(query)
+	if len(operators) != len(ors) {
+		return ctx
+	}
+
+	pipes := make(map[*sqlparser.OrExpr]bool)
+	for i, or := range ors {
+		if operators[i] {
+			pipes[or] = true
+		}
+	}
+	return ctx.WithContext(context.WithValue(ctx.Context, concatPipesKey{}, pipes))
+}
+
+// isConcatPipe returns whether the OR expression given was written with || in a query converted with the context
+// given, and is a concatenation.
+func isConcatPipe(ctx *sql.Context, or *sqlparser.OrExpr) bool {
+	pipes, _ := ctx.Value(concatPipesKey{}).(map[*sqlparser.OrExpr]bool)
+	return pipes[or]
+}
+
+// orOperators returns whether each of the OR operators of the query given, in order, is written with || rather than
+// OR. The query must not have comments.
+func orOperators(query string) []bool {
+	var pipes []bool
+	var previous string
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = closingQuote(query, i)
+		case c == '|' && i+1 < len(query) && query[i+1] == '|':
+			pipes = append(pipes, true)
+			i++
+		case isIdentRune(rune(c)):
+			end := i
+			for end < len(query) && isIdentRune(rune(query[end])) {
+				end++
+			}
+			word := strings.ToLower(query[i:end])
+			// CREATE OR REPLACE isn't an operator
+			if word == "or" && previous != "create" {
+				pipes = append(pipes, false)
+			}
+			previous = word
+			i = end - 1
+		}
+	}
+	return pipes
+}
@@ -0,0 +1,149 @@
+package parse
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// parsePrepare parses PREPARE name FROM 'query' and PREPARE name FROM @var.
+// The query is parsed so that its syntax errors are returned when it's
+// prepared.
+func parsePrepare(ctx *sql.Context, s string) (sql.Node, error) {
+	var name, from string
+	r := bufio.NewReader(strings.NewReader(s))
+	err := parseFuncs{
+		expect("prepare"),
+		skipSpaces,
+		readQuotableIdent(&name),
+		skipSpaces,
+		expect("from"),
+		skipSpaces,
+		readRemaining(&from),
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var query string
+	if from = strings.TrimSpace(from); strings.HasPrefix(from, "@") && !strings.HasPrefix(from, "@@") {
+		// The query of a variable that isn't a string, such as NULL, fails to
+		// parse, as it does in MySQL.
+		_, value := ctx.Get(from[1:])
+		query = fmt.Sprint(value)
+		if value == nil {
+			query = "NULL"
+		}
+	} else {
+		e, err := parseExpr(ctx, from)
+		if err != nil {
+			return nil, err
+		}
+
+		l, ok := e.(*expression.Literal)
+		if !ok || !sql.IsText(l.Type()) {
+			return nil, errUnexpectedSyntax.New("string or user variable", from)
+		}
+		query = l.Value().(string)
+	}
+
+	lowerQuery := strings.ToLower(strings.TrimSpace(removeComments(query)))
+	if prepareRegex.MatchString(lowerQuery) || executeRegex.MatchString(lowerQuery) || deallocateRegex.MatchString(lowerQuery) {
+		return nil, ErrUnsupportedFeature.New("PREPARE of PREPARE, EXECUTE or DEALLOCATE PREPARE")
+	}
+
+	if _, err := Parse(ctx, query); err != nil {
+		return nil, err
+	}
+
+	return plan.NewPrepareQuery(name, query), nil
+}
+
+// parseExecute parses EXECUTE name [USING @var [, @var] ...], which is
+// parsed to the statement prepared with that name, with the values of the
+// variables as its parameters.
+func parseExecute(ctx *sql.Context, s string) (sql.Node, error) {
+	var name, using string
+	var hasUsing bool
+	r := bufio.NewReader(strings.NewReader(s))
+	err := parseFuncs{
+		expect("execute"),
+		skipSpaces,
+		readQuotableIdent(&name),
+		skipSpaces,
+		maybe(&hasUsing, "using"),
+		readRemaining(&using),
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var vars []string
+	if hasUsing {
+		for _, v := range strings.Split(using, ",") {
+			v = strings.TrimSpace(v)
+			if !strings.HasPrefix(v, "@") || strings.HasPrefix(v, "@@") || len(v) == 1 {
+				return nil, errUnexpectedSyntax.New("user variable", v)
+			}
+			vars = append(vars, v[1:])
+		}
+	} else if strings.TrimSpace(using) != "" {
+		return nil, errUnexpectedSyntax.New("USING", strings.TrimSpace(using))
+	}
+
+	session, ok := ctx.Session.(sql.PreparedStatementSession)
+	if !ok {
+		return nil, sql.ErrPreparedStatementsNotSupported.New()
+	}
+
+	query, ok := session.PreparedStatement(name)
+	if !ok {
+		return nil, sql.ErrUnknownPreparedStatement.New(name, "EXECUTE")
+	}
+
+	if len(unquotedIndexes(query, "?")) != len(vars) {
+		return nil, sql.ErrWrongArgumentsToExecute.New()
+	}
+
+	node, err := Parse(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vars) == 0 {
+		return node, nil
+	}
+
+	// The parameters have the values and the types of the variables when the
+	// statement is executed.
+	bindings := make(map[string]sql.Expression, len(vars))
+	for i, v := range vars {
+		typ, value := ctx.Get(v)
+		bindings[fmt.Sprintf("v%d", i+1)] = expression.NewLiteral(value, typ)
+	}
+	return plan.ApplyBindings(node, bindings)
+}
+
+// parseDeallocate parses DEALLOCATE PREPARE name and DROP PREPARE name.
+func parseDeallocate(s string) (sql.Node, error) {
+	var name string
+	r := bufio.NewReader(strings.NewReader(s))
+	err := parseFuncs{
+		oneOf("deallocate", "drop"),
+		skipSpaces,
+		expect("prepare"),
+		skipSpaces,
+		readQuotableIdent(&name),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewDeallocateQuery(name), nil
+}
@@ -0,0 +1,64 @@
+package parse
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func parseGrantProxy(query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var proxied sql.Account
+	var accounts []sql.Account
+	var withGrantOption bool
+	err := parseFuncs{
+		expect("grant"),
+		skipSpaces,
+		expect("proxy"),
+		skipSpaces,
+		expect("on"),
+		skipSpaces,
+		readAccount(&proxied),
+		skipSpaces,
+		expect("to"),
+		readAccountList(&accounts),
+		skipSpaces,
+		multiMaybe(&withGrantOption, "with", "grant", "option"),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewGrantProxy(proxied, accounts, withGrantOption), nil
+}
+
+func parseRevokeProxy(query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var proxied sql.Account
+	var accounts []sql.Account
+	err := parseFuncs{
+		expect("revoke"),
+		skipSpaces,
+		expect("proxy"),
+		skipSpaces,
+		expect("on"),
+		skipSpaces,
+		readAccount(&proxied),
+		skipSpaces,
+		expect("from"),
+		readAccountList(&accounts),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewRevokeProxy(proxied, accounts), nil
+}
@@ -0,0 +1,114 @@
+package parse
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// parseChangeReplicationSource parses CHANGE REPLICATION SOURCE TO option = value [, option = value] ..., and CHANGE
+// MASTER TO, whose options are named MASTER_ rather than SOURCE_.
+func parseChangeReplicationSource(s string) (sql.Node, error) {
+	var options []sql.ReplicaOption
+	r := bufio.NewReader(strings.NewReader(s))
+	err := parseFuncs{
+		expect("change"),
+		skipSpaces,
+		func(rd *bufio.Reader) error {
+			var word string
+			if err := readIdent(&word)(rd); err != nil {
+				return err
+			}
+
+			switch word {
+			case "master":
+				return nil
+			case "replication":
+				return parseFuncs{skipSpaces, expect("source")}.exec(rd)
+			default:
+				return errUnexpectedSyntax.New("replication source", word)
+			}
+		},
+		skipSpaces,
+		expect("to"),
+		skipSpaces,
+		readReplicaOptions(&options),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewChangeReplicationSource(options), nil
+}
+
+func readReplicaOptions(options *[]sql.ReplicaOption) parseFunc {
+	return func(rd *bufio.Reader) error {
+		for {
+			var name string
+			if err := readIdent(&name)(rd); err != nil {
+				return err
+			}
+
+			name = strings.ToUpper(name)
+			if strings.HasPrefix(name, "MASTER_") {
+				name = "SOURCE_" + strings.TrimPrefix(name, "MASTER_")
+			}
+
+			isString, ok := sql.ReplicaOptionIsString(name)
+			if !ok {
+				return sql.ErrUnknownReplicaOption.New(name)
+			}
+
+			if err := (parseFuncs{skipSpaces, expectRune('='), skipSpaces}).exec(rd); err != nil {
+				return err
+			}
+
+			option := sql.ReplicaOption{Name: name}
+			if isString {
+				var value string
+				if err := readQuotedString(&value)(rd); err != nil {
+					return err
+				}
+				option.Value = value
+			} else {
+				var digits string
+				if err := readDigits(&digits)(rd); err != nil {
+					return err
+				}
+
+				n, err := strconv.ParseUint(digits, 10, 64)
+				if err != nil {
+					return sql.ErrInvalidReplicaOption.New(name, digits)
+				}
+				option.Value = n
+			}
+			*options = append(*options, option)
+
+			var more bool
+			if err := (parseFuncs{skipSpaces, maybe(&more, ","), skipSpaces}).exec(rd); err != nil {
+				return err
+			}
+			if !more {
+				return nil
+			}
+		}
+	}
+}
+
+// deprecatedReplicaKeyword matches the MASTER and SLAVE keywords of the replication statements, which are deprecated
+// in favor of SOURCE and REPLICA.
+var deprecatedReplicaKeyword = regexp.MustCompile(`^\w+\s+(\w+\s+)?(master|slave)(\s|$)`)
+
+// warnDeprecatedReplicaSyntax adds the warning of MySQL about the deprecated statement given to the session, if the
+// lowercase query given is written with its deprecated MASTER or SLAVE keyword rather than the replacement given.
+func warnDeprecatedReplicaSyntax(ctx *sql.Context, lowerQuery, deprecated, replacement string) {
+	if deprecatedReplicaKeyword.MatchString(lowerQuery) {
+		ctx.Warn(1287, "'%s' is deprecated and will be removed in a future release. Please use %s instead", deprecated, replacement)
+	}
+}
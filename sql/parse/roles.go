@@ -0,0 +1,206 @@
+package parse
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func parseCreateRole(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var ifNotExists bool
+	var roles []sql.Account
+	err := parseFuncs{
+		expect("create"),
+		skipSpaces,
+		expect("role"),
+		skipSpaces,
+		multiMaybe(&ifNotExists, "if", "not", "exists"),
+		readAccountList(&roles),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewCreateRole(roles, ifNotExists), nil
+}
+
+func parseDropRole(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var ifExists bool
+	var roles []sql.Account
+	err := parseFuncs{
+		expect("drop"),
+		skipSpaces,
+		expect("role"),
+		skipSpaces,
+		multiMaybe(&ifExists, "if", "exists"),
+		readAccountList(&roles),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewDropRole(roles, ifExists), nil
+}
+
+func parseGrantRole(query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var roles, accounts []sql.Account
+	var withAdminOption bool
+	err := parseFuncs{
+		expect("grant"),
+		readAccountList(&roles),
+		skipSpaces,
+		expect("to"),
+		readAccountList(&accounts),
+		skipSpaces,
+		multiMaybe(&withAdminOption, "with", "admin", "option"),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewGrantRole(roles, accounts, withAdminOption), nil
+}
+
+func parseRevokeRole(query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var roles, accounts []sql.Account
+	err := parseFuncs{
+		expect("revoke"),
+		readAccountList(&roles),
+		skipSpaces,
+		expect("from"),
+		readAccountList(&accounts),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewRevokeRole(roles, accounts), nil
+}
+
+func parseSetRole(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var spec plan.RoleSpec
+	var roles []sql.Account
+	err := parseFuncs{
+		expect("set"),
+		skipSpaces,
+		expect("role"),
+		skipSpaces,
+		readRoleSpec(&spec, &roles, true),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewSetRole(spec, roles), nil
+}
+
+func parseSetDefaultRole(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var spec plan.RoleSpec
+	var roles, accounts []sql.Account
+	err := parseFuncs{
+		expect("set"),
+		skipSpaces,
+		expect("default"),
+		skipSpaces,
+		expect("role"),
+		skipSpaces,
+		readRoleSpec(&spec, &roles, false),
+		skipSpaces,
+		expect("to"),
+		readAccountList(&accounts),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewSetDefaultRole(spec, roles, accounts), nil
+}
+
+// readRoleSpec reads the roles selected by SET ROLE and SET DEFAULT ROLE: NONE, ALL [EXCEPT role, ...], a list of
+// roles, and DEFAULT if allowed.
+func readRoleSpec(spec *plan.RoleSpec, roles *[]sql.Account, allowDefault bool) parseFunc {
+	return func(rd *bufio.Reader) error {
+		var matched bool
+		if err := maybeKeyword(&matched, "none")(rd); err != nil || matched {
+			*spec = plan.RoleNone
+			return err
+		}
+
+		if err := maybeKeyword(&matched, "default")(rd); err != nil {
+			return err
+		} else if matched {
+			if !allowDefault {
+				return errUnexpectedSyntax.New("NONE, ALL or a role", "DEFAULT")
+			}
+			*spec = plan.RoleDefault
+			return nil
+		}
+
+		if err := maybeKeyword(&matched, "all")(rd); err != nil {
+			return err
+		}
+
+		if !matched {
+			*spec = plan.RoleList
+			return readAccountList(roles)(rd)
+		}
+
+		*spec = plan.RoleAll
+		err := parseFuncs{
+			skipSpaces,
+			maybeKeyword(&matched, "except"),
+		}.exec(rd)
+		if err != nil || !matched {
+			return err
+		}
+
+		return readAccountList(roles)(rd)
+	}
+}
+
+// maybeKeyword is like maybe, but only matches whole words, so that names
+// starting with the keyword are not mistaken for it.
+func maybeKeyword(matched *bool, keyword string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		*matched = false
+
+		data, _ := rd.Peek(len(keyword) + 1)
+		if len(data) < len(keyword) || strings.ToLower(string(data[:len(keyword)])) != keyword {
+			return nil
+		}
+
+		if len(data) > len(keyword) && (isAccountRune(rune(data[len(keyword)])) || data[len(keyword)] == '@') {
+			return nil
+		}
+
+		*matched = true
+		_, err := rd.Discard(len(keyword))
+		return err
+	}
+}
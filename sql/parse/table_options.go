@@ -0,0 +1,132 @@
+package parse
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// valuedTableOptions are the table options that can be given a value without an equals sign.
+var valuedTableOptions = map[string]bool{
+	"AUTO_INCREMENT":     true,
+	"AVG_ROW_LENGTH":     true,
+	"CHARACTER SET":      true,
+	"CHARSET":            true,
+	"CHECKSUM":           true,
+	"COLLATE":            true,
+	"COMMENT":            true,
+	"COMPRESSION":        true,
+	"CONNECTION":         true,
+	"DATA DIRECTORY":     true,
+	"DELAY_KEY_WRITE":    true,
+	"ENCRYPTION":         true,
+	"ENGINE":             true,
+	"INDEX DIRECTORY":    true,
+	"INSERT_METHOD":      true,
+	"KEY_BLOCK_SIZE":     true,
+	"MAX_ROWS":           true,
+	"MIN_ROWS":           true,
+	"PACK_KEYS":          true,
+	"PASSWORD":           true,
+	"ROW_FORMAT":         true,
+	"STATS_AUTO_RECALC":  true,
+	"STATS_PERSISTENT":   true,
+	"STATS_SAMPLE_PAGES": true,
+	"TABLESPACE":         true,
+}
+
+// tableOptionToken is a word, a string, an equals sign or a comma of the table options of CREATE TABLE.
+type tableOptionToken struct {
+	text   string
+	quoted bool
+}
+
+// convertTableOptions returns the table options of CREATE TABLE, as given by the SQL parser: the words and strings of
+// each option, with the strings in single quotes, with the options separated by spaces or commas.
+func convertTableOptions(options string) sql.TableOptions {
+	tokens := tokenizeTableOptions(options)
+
+	var converted sql.TableOptions
+	var name []string
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case !token.quoted && token.text == ",":
+			name = nil
+		case !token.quoted && token.text == "=":
+			if i+1 < len(tokens) && len(name) > 0 {
+				i++
+				converted = append(converted, newTableOption(name, tokens[i]))
+			}
+			name = nil
+		case token.quoted:
+			// A string can't name an option
+			name = nil
+		default:
+			name = append(name, strings.ToUpper(token.text))
+			next := i + 1
+			if valuedTableOptions[tableOptionName(name)] && next < len(tokens) &&
+				(tokens[next].quoted || (tokens[next].text != "=" && tokens[next].text != ",")) {
+				i++
+				converted = append(converted, newTableOption(name, tokens[i]))
+				name = nil
+			}
+		}
+	}
+	return converted
+}
+
+// tableOptionName returns the name of the option given by the words given, without the optional DEFAULT before the
+// character set and collation options.
+func tableOptionName(words []string) string {
+	name := strings.Join(words, " ")
+	name = strings.TrimPrefix(name, "DEFAULT ")
+	if name == "CHARSET" {
+		return sql.TableOption_CharacterSet
+	}
+	return name
+}
+
+func newTableOption(name []string, value tableOptionToken) sql.TableOption {
+	return sql.TableOption{
+		Name:   tableOptionName(name),
+		Value:  value.text,
+		Quoted: value.quoted,
+	}
+}
+
+// tokenizeTableOptions splits the table options given into words, strings, equals signs and commas. The SQL parser
+// gives strings in single quotes without escaping the quotes in them, so a string ends at the quote before a space, a
+// comma or the end of the options.
+func tokenizeTableOptions(options string) []tableOptionToken {
+	var tokens []tableOptionToken
+	for i := 0; i < len(options); i++ {
+		switch c := options[i]; {
+		case c == ' ':
+		case c == '=' || c == ',':
+			tokens = append(tokens, tableOptionToken{text: string(c)})
+		case c == '\'':
+			end := i + 1
+			for ; end < len(options); end++ {
+				if options[end] == '\'' && (end+1 == len(options) || options[end+1] == ' ' || options[end+1] == ',') {
+					break
+				}
+			}
+			if end >= len(options) {
+				end = len(options)
+				tokens = append(tokens, tableOptionToken{text: options[i+1:], quoted: true})
+			} else {
+				tokens = append(tokens, tableOptionToken{text: options[i+1 : end], quoted: true})
+			}
+			i = end
+		default:
+			end := i
+			for end < len(options) && options[end] != ' ' && options[end] != '=' && options[end] != ',' {
+				end++
+			}
+			tokens = append(tokens, tableOptionToken{text: options[i:end]})
+			i = end - 1
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,513 @@
+package parse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func parseCreateUser(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var ifNotExists bool
+	var users []plan.UserSpec
+	var require sql.TLSRequirement
+	var limits *sql.ResourceLimits
+	var hasExpire bool
+	var expire sql.PasswordExpire
+	err := parseFuncs{
+		expect("create"),
+		skipSpaces,
+		expect("user"),
+		skipSpaces,
+		multiMaybe(&ifNotExists, "if", "not", "exists"),
+		readUserSpecs(&users),
+		skipSpaces,
+		readTLSRequirement(&require),
+		skipSpaces,
+		readResourceLimits(&limits),
+		skipSpaces,
+		multiMaybe(&hasExpire, "password", "expire"),
+		func(rd *bufio.Reader) error {
+			if !hasExpire {
+				return nil
+			}
+			return readPasswordExpire(&expire)(rd)
+		},
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasExpire {
+		return plan.NewCreateUser(users, ifNotExists, require, limits, nil), nil
+	}
+	return plan.NewCreateUser(users, ifNotExists, require, limits, &expire), nil
+}
+
+func parseAlterUser(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var ifExists bool
+	var accounts []sql.Account
+	var limits *sql.ResourceLimits
+	var hasExpire bool
+	var expire sql.PasswordExpire
+	err := parseFuncs{
+		expect("alter"),
+		skipSpaces,
+		expect("user"),
+		skipSpaces,
+		multiMaybe(&ifExists, "if", "exists"),
+		readAccountList(&accounts),
+		skipSpaces,
+		readResourceLimits(&limits),
+		skipSpaces,
+		func(rd *bufio.Reader) error {
+			// The PASSWORD EXPIRE clause is only optional after a WITH clause.
+			if limits != nil {
+				return multiMaybe(&hasExpire, "password", "expire")(rd)
+			}
+
+			hasExpire = true
+			return parseFuncs{expect("password"), skipSpaces, expect("expire")}.exec(rd)
+		},
+		func(rd *bufio.Reader) error {
+			if !hasExpire {
+				return nil
+			}
+			return readPasswordExpire(&expire)(rd)
+		},
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasExpire {
+		return plan.NewAlterUser(accounts, ifExists, limits, nil), nil
+	}
+	return plan.NewAlterUser(accounts, ifExists, limits, &expire), nil
+}
+
+func parseDropUser(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var ifExists bool
+	var accounts []sql.Account
+	err := parseFuncs{
+		expect("drop"),
+		skipSpaces,
+		expect("user"),
+		skipSpaces,
+		multiMaybe(&ifExists, "if", "exists"),
+		readAccountList(&accounts),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewDropUser(accounts, ifExists), nil
+}
+
+func parseSetPassword(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var hasFor bool
+	var account sql.Account
+	var password string
+	err := parseFuncs{
+		expect("set"),
+		skipSpaces,
+		expect("password"),
+		skipSpaces,
+		maybe(&hasFor, "for"),
+		func(rd *bufio.Reader) error {
+			if !hasFor {
+				return nil
+			}
+			return parseFuncs{skipSpaces, readAccount(&account), skipSpaces}.exec(rd)
+		},
+		expectRune('='),
+		skipSpaces,
+		readQuotedString(&password),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasFor {
+		return plan.NewSetPassword(nil, password), nil
+	}
+	return plan.NewSetPassword(&account, password), nil
+}
+
+func readUserSpecs(users *[]plan.UserSpec) parseFunc {
+	return func(rd *bufio.Reader) error {
+		for {
+			var u plan.UserSpec
+			var identified bool
+			err := parseFuncs{
+				skipSpaces,
+				readAccount(&u.Account),
+				skipSpaces,
+				multiMaybe(&identified, "identified", "by"),
+			}.exec(rd)
+			if err != nil {
+				return err
+			}
+
+			if identified {
+				if err := readQuotedString(&u.Password)(rd); err != nil {
+					return err
+				}
+			}
+
+			*users = append(*users, u)
+
+			var more bool
+			if err := (parseFuncs{skipSpaces, maybe(&more, ",")}).exec(rd); err != nil {
+				return err
+			}
+
+			if !more {
+				return nil
+			}
+		}
+	}
+}
+
+// readTLSRequirement reads an optional REQUIRE clause.
+func readTLSRequirement(require *sql.TLSRequirement) parseFunc {
+	return func(rd *bufio.Reader) error {
+		var matched bool
+		if err := maybe(&matched, "require")(rd); err != nil {
+			return err
+		}
+
+		if !matched {
+			return nil
+		}
+
+		var option string
+		err := parseFuncs{
+			skipSpaces,
+			readIdent(&option),
+		}.exec(rd)
+		if err != nil {
+			return err
+		}
+
+		switch option {
+		case "none":
+			return nil
+		case "ssl":
+			require.SSL = true
+			return nil
+		case "x509":
+			require.SSL, require.X509 = true, true
+			return nil
+		}
+
+		// SUBJECT 'subject' [AND] ISSUER 'issuer', in any order.
+		for {
+			if option != "subject" && option != "issuer" {
+				return errUnexpectedSyntax.New("one of: NONE, SSL, X509, SUBJECT, ISSUER", option)
+			}
+
+			var value string
+
+			if err := (parseFuncs{skipSpaces, readQuotedString(&value), skipSpaces}).exec(rd); err != nil {
+				return err
+			}
+
+			if option == "subject" {
+				require.Subject = value
+			} else {
+				require.Issuer = value
+			}
+			require.SSL, require.X509 = true, true
+
+			var and bool
+			if err := (parseFuncs{maybe(&and, "and"), skipSpaces}).exec(rd); err != nil {
+				return err
+			}
+
+			if _, err := rd.Peek(1); err == io.EOF {
+				return nil
+			}
+
+			if err := readIdent(&option)(rd); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readPasswordExpire reads the options of a PASSWORD EXPIRE clause, which expires the password right away if it has
+// none: [DEFAULT | NEVER | INTERVAL N DAY].
+func readPasswordExpire(expire *sql.PasswordExpire) parseFunc {
+	return func(rd *bufio.Reader) error {
+		if err := skipSpaces(rd); err != nil {
+			return err
+		}
+
+		if _, err := rd.Peek(1); err == io.EOF {
+			expire.Now = true
+			return nil
+		}
+
+		var option string
+		if err := readIdent(&option)(rd); err != nil {
+			return err
+		}
+
+		switch option {
+		case "default":
+			expire.Lifetime = sql.PasswordLifetimeDefault
+			return nil
+		case "never":
+			expire.Lifetime = sql.PasswordLifetimeNever
+			return nil
+		case "interval":
+		default:
+			return errUnexpectedSyntax.New("one of: DEFAULT, NEVER, INTERVAL", option)
+		}
+
+		var digits string
+		if err := (parseFuncs{skipSpaces, readDigits(&digits)}).exec(rd); err != nil {
+			return err
+		}
+
+		days, err := strconv.Atoi(digits)
+		if err != nil || days < 1 || days > 65535 {
+			return errUnexpectedSyntax.New("a number of days between 1 and 65535", digits)
+		}
+
+		expire.Lifetime, expire.Days = sql.PasswordLifetimeInterval, days
+		return parseFuncs{skipSpaces, expect("day")}.exec(rd)
+	}
+}
+
+// readResourceLimits reads an optional WITH clause. MAX_USER_CONNECTIONS is the only resource option supported, and
+// the last value given is the one used if it's repeated.
+func readResourceLimits(limits **sql.ResourceLimits) parseFunc {
+	return func(rd *bufio.Reader) error {
+		var matched bool
+		if err := maybe(&matched, "with")(rd); err != nil {
+			return err
+		}
+
+		if !matched {
+			return nil
+		}
+
+		if err := (parseFuncs{skipSpaces, expect("max_user_connections")}).exec(rd); err != nil {
+			return err
+		}
+
+		*limits = new(sql.ResourceLimits)
+		for {
+			var digits string
+			if err := (parseFuncs{skipSpaces, readDigits(&digits)}).exec(rd); err != nil {
+				return err
+			}
+
+			n, err := strconv.ParseUint(digits, 10, 32)
+			if err != nil {
+				return errUnexpectedSyntax.New("a number of connections", digits)
+			}
+			(*limits).MaxUserConnections = int(n)
+
+			var more bool
+			if err := (parseFuncs{skipSpaces, maybe(&more, "max_user_connections")}).exec(rd); err != nil {
+				return err
+			}
+
+			if !more {
+				return nil
+			}
+		}
+	}
+}
+
+// readDigits reads a sequence of decimal digits, which may be empty.
+func readDigits(digits *string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		var buf bytes.Buffer
+		for {
+			ru, _, err := rd.ReadRune()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+
+			if ru < '0' || ru > '9' {
+				if err := rd.UnreadRune(); err != nil {
+					return err
+				}
+				break
+			}
+
+			buf.WriteRune(ru)
+		}
+
+		*digits = buf.String()
+		return nil
+	}
+}
+
+func readAccountList(accounts *[]sql.Account) parseFunc {
+	return func(rd *bufio.Reader) error {
+		for {
+			var a sql.Account
+			if err := (parseFuncs{skipSpaces, readAccount(&a)}).exec(rd); err != nil {
+				return err
+			}
+
+			*accounts = append(*accounts, a)
+
+			var more bool
+			if err := (parseFuncs{skipSpaces, maybe(&more, ",")}).exec(rd); err != nil {
+				return err
+			}
+
+			if !more {
+				return nil
+			}
+		}
+	}
+}
+
+// readAccount reads an account in the form name[@host], where both the name and the host may be quoted with
+// backticks, single or double quotes. The host is % if not given.
+func readAccount(account *sql.Account) parseFunc {
+	return func(rd *bufio.Reader) error {
+		if err := readAccountPart(&account.Name)(rd); err != nil {
+			return err
+		}
+
+		var hasHost bool
+		if err := maybe(&hasHost, "@")(rd); err != nil {
+			return err
+		}
+
+		if !hasHost {
+			account.Host = "%"
+			return nil
+		}
+
+		return readAccountPart(&account.Host)(rd)
+	}
+}
+
+func readAccountPart(part *string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		b, err := rd.Peek(1)
+		if err != nil {
+			return err
+		}
+
+		switch b[0] {
+		case '`', '\'', '"':
+			return readQuotedString(part)(rd)
+		}
+
+		var buf bytes.Buffer
+		for {
+			ru, _, err := rd.ReadRune()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+
+			if !isAccountRune(ru) {
+				if err := rd.UnreadRune(); err != nil {
+					return err
+				}
+				break
+			}
+
+			buf.WriteRune(ru)
+		}
+
+		if buf.Len() == 0 {
+			ru, _, _ := rd.ReadRune()
+			return errUnexpectedSyntax.New("user name or host", string(ru))
+		}
+
+		*part = buf.String()
+		return nil
+	}
+}
+
+func isAccountRune(ru rune) bool {
+	switch {
+	case ru >= 'a' && ru <= 'z', ru >= 'A' && ru <= 'Z', ru >= '0' && ru <= '9':
+		return true
+	default:
+		return strings.ContainsRune("_$.%-:", ru)
+	}
+}
+
+// readQuotedString reads a string quoted with backticks, single or double quotes. The quote character can be escaped
+// by doubling it, and single and double quoted strings may also use backslash escapes.
+func readQuotedString(str *string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		quote, _, err := rd.ReadRune()
+		if err != nil {
+			return err
+		}
+
+		if quote != '`' && quote != '\'' && quote != '"' {
+			return errUnexpectedSyntax.New("quoted string", string(quote))
+		}
+
+		var buf bytes.Buffer
+		for {
+			ru, _, err := rd.ReadRune()
+			if err == io.EOF {
+				return errUnexpectedSyntax.New(string(quote), "EOF")
+			} else if err != nil {
+				return err
+			}
+
+			switch {
+			case ru == quote:
+				next, _, err := rd.ReadRune()
+				if err == io.EOF {
+					*str = buf.String()
+					return nil
+				} else if err != nil {
+					return err
+				}
+
+				if next != quote {
+					*str = buf.String()
+					return rd.UnreadRune()
+				}
+			case ru == '\\' && quote != '`':
+				ru, _, err = rd.ReadRune()
+				if err != nil {
+					return errUnexpectedSyntax.New(string(quote), "EOF")
+				}
+			}
+
+			buf.WriteRune(ru)
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 var (
 	errUnexpectedSyntax       = errors.NewKind("expecting %q but got %q instead")
 	errInvalidIndexExpression = errors.NewKind("invalid expression to index: %s")
+	errInvalidExpression      = errors.NewKind("invalid expression: %s")
 )
 
 type parseFunc func(*bufio.Reader) error
@@ -343,6 +344,11 @@ func readRemaining(val *string) parseFunc {
 	}
 }
 
+// ParseExpression parses the SQL text of an expression, as the condition of a CHECK constraint stored by a table.
+func ParseExpression(ctx *sql.Context, str string) (sql.Expression, error) {
+	return parseExpr(ctx, str)
+}
+
 func parseExpr(ctx *sql.Context, str string) (sql.Expression, error) {
 	stmt, err := sqlparser.Parse("SELECT " + str)
 	if err != nil {
@@ -351,16 +357,16 @@ func parseExpr(ctx *sql.Context, str string) (sql.Expression, error) {
 
 	selectStmt, ok := stmt.(*sqlparser.Select)
 	if !ok {
-		return nil, errInvalidIndexExpression.New(str)
+		return nil, errInvalidExpression.New(str)
 	}
 
 	if len(selectStmt.SelectExprs) != 1 {
-		return nil, errInvalidIndexExpression.New(str)
+		return nil, errInvalidExpression.New(str)
 	}
 
 	selectExpr, ok := selectStmt.SelectExprs[0].(*sqlparser.AliasedExpr)
 	if !ok {
-		return nil, errInvalidIndexExpression.New(str)
+		return nil, errInvalidExpression.New(str)
 	}
 
 	return exprToExpression(ctx, selectExpr.Expr)
@@ -10,6 +10,7 @@ import (
 
 func parseShowVariables(ctx *sql.Context, s string) (sql.Node, error) {
 	var pattern string
+	var global bool
 
 	r := bufio.NewReader(strings.NewReader(s))
 	for _, fn := range []parseFunc{
@@ -23,6 +24,7 @@ func parseShowVariables(ctx *sql.Context, s string) (sql.Node, error) {
 
 			switch s {
 			case "global", "session":
+				global = s == "global"
 				if err := skipSpaces(in); err != nil {
 					return err
 				}
@@ -54,5 +56,82 @@ func parseShowVariables(ctx *sql.Context, s string) (sql.Node, error) {
 		}
 	}
 
-	return plan.NewShowVariables(ctx.Session.GetAll(), pattern), nil
+	return plan.NewShowVariables(pattern, global), nil
+}
+
+func parseShowStatus(s string) (sql.Node, error) {
+	var pattern string
+	var global bool
+
+	r := bufio.NewReader(strings.NewReader(s))
+	for _, fn := range []parseFunc{
+		expect("show"),
+		skipSpaces,
+		func(in *bufio.Reader) error {
+			var s string
+			if err := readIdent(&s)(in); err != nil {
+				return err
+			}
+
+			switch s {
+			case "global", "session":
+				global = s == "global"
+				if err := skipSpaces(in); err != nil {
+					return err
+				}
+
+				return expect("status")(in)
+			case "status":
+				return nil
+			}
+			return errUnexpectedSyntax.New("show [global | session] status", s)
+		},
+		skipSpaces,
+		func(in *bufio.Reader) error {
+			if expect("like")(in) == nil {
+				if err := skipSpaces(in); err != nil {
+					return err
+				}
+
+				if err := readValue(&pattern)(in); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		skipSpaces,
+		checkEOF,
+	} {
+		if err := fn(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan.NewShowStatus(pattern, global), nil
+}
+
+func parseResetPersist(s string) (sql.Node, error) {
+	var name string
+	var ifExists bool
+
+	r := bufio.NewReader(strings.NewReader(s))
+	err := parseFuncs{
+		expect("reset"),
+		skipSpaces,
+		expect("persist"),
+		skipSpaces,
+		multiMaybe(&ifExists, "if", "exists"),
+		optional(readQuotableIdent(&name)),
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if ifExists && name == "" {
+		return nil, errUnexpectedSyntax.New("variable name", "EOF")
+	}
+
+	return plan.NewResetPersist(name, ifExists), nil
 }
@@ -16,8 +16,18 @@ import (
 
 var errInvalidIndex = errors.NewKind("invalid %s index %d (index must be non-negative)")
 
+// IsDiagnosticsStatement returns whether the query given is a statement reading the warnings of the last one, like
+// SHOW WARNINGS and GET DIAGNOSTICS, which unlike the other statements don't start with the warnings cleared.
+func IsDiagnosticsStatement(query string) bool {
+	s := strings.ToLower(strings.TrimSpace(removeComments(query)))
+	s = strings.TrimSpace(strings.TrimSuffix(s, ";"))
+	return showWarningsRegex.MatchString(s) || showCountRegex.MatchString(s) || getDiagnosticsRegex.MatchString(s)
+}
+
+// parseShowWarnings parses SHOW WARNINGS and SHOW ERRORS, which only shows the warnings of the error level.
 func parseShowWarnings(ctx *sql.Context, s string) (sql.Node, error) {
 	var (
+		kind   string
 		offstr string
 		cntstr string
 	)
@@ -26,7 +36,7 @@ func parseShowWarnings(ctx *sql.Context, s string) (sql.Node, error) {
 	for _, fn := range []parseFunc{
 		expect("show"),
 		skipSpaces,
-		expect("warnings"),
+		readIdent(&kind),
 		skipSpaces,
 		func(in *bufio.Reader) error {
 			if expect("limit")(in) == nil {
@@ -50,8 +60,13 @@ func parseShowWarnings(ctx *sql.Context, s string) (sql.Node, error) {
 		}
 	}
 
+	warnings := ctx.Session.Warnings()
+	if kind == "errors" {
+		warnings = errorWarnings(warnings)
+	}
+
 	var (
-		node   sql.Node = plan.ShowWarnings(ctx.Session.Warnings())
+		node   sql.Node = plan.ShowWarnings(warnings)
 		offset int
 		count  int
 		err    error
@@ -80,6 +95,25 @@ func parseShowWarnings(ctx *sql.Context, s string) (sql.Node, error) {
 	return node, nil
 }
 
+// parseShowCount parses SHOW COUNT(*) WARNINGS and SHOW COUNT(*) ERRORS, given in lowercase.
+func parseShowCount(ctx *sql.Context, s string) sql.Node {
+	if showCountRegex.FindStringSubmatch(s)[1] == "errors" {
+		return plan.NewShowErrorCount(len(errorWarnings(ctx.Session.Warnings())))
+	}
+	return plan.NewShowWarningCount(int(ctx.WarningCount()))
+}
+
+// errorWarnings returns the warnings given of the error level.
+func errorWarnings(warnings []*sql.Warning) []*sql.Warning {
+	var errs []*sql.Warning
+	for _, w := range warnings {
+		if w.Level == "Error" {
+			errs = append(errs, w)
+		}
+	}
+	return errs
+}
+
 func readValue(val *string) parseFunc {
 	return func(rd *bufio.Reader) error {
 		var buf bytes.Buffer
@@ -0,0 +1,69 @@
+package sql
+
+import (
+	"fmt"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+var (
+	// ErrPasswordPolicy is returned by UserManagers when a password doesn't satisfy their password policy, mirroring
+	// MySQL's ER_NOT_VALID_PASSWORD.
+	ErrPasswordPolicy = errors.NewKind("Your password does not satisfy the current policy requirements: %s")
+
+	// ErrMustChangePassword is returned for the statements run by a user whose password has expired, other than the
+	// ones changing it, mirroring MySQL's ER_MUST_CHANGE_PASSWORD.
+	ErrMustChangePassword = errors.NewKind("You must reset your password using SET PASSWORD before executing this statement.")
+
+	// ErrPasswordExpirationNotSupported is returned when the expiration of passwords is changed and the
+	// authentication method in use does not support it.
+	ErrPasswordExpirationNotSupported = errors.NewKind("the authentication method does not support password expiration")
+)
+
+// PasswordLifetime is how long the password of an account lasts before it expires.
+type PasswordLifetime int
+
+const (
+	// PasswordLifetimeDefault uses the default lifetime of the passwords of the authentication method.
+	PasswordLifetimeDefault PasswordLifetime = iota
+	// PasswordLifetimeNever keeps the password from expiring.
+	PasswordLifetimeNever
+	// PasswordLifetimeInterval makes the password expire a number of days after it's changed.
+	PasswordLifetimeInterval
+)
+
+// PasswordExpire holds the PASSWORD EXPIRE clause of CREATE USER and ALTER USER statements.
+type PasswordExpire struct {
+	// Now expires the password right away, as PASSWORD EXPIRE with no options does, so that the user must change it
+	// before running other statements. The lifetime is left as is.
+	Now bool
+	// Lifetime is how long the password lasts, unless Now is set.
+	Lifetime PasswordLifetime
+	// Days is the number of days the password lasts for PasswordLifetimeInterval.
+	Days int
+}
+
+// String returns the clause as written in a statement.
+func (e PasswordExpire) String() string {
+	switch {
+	case e.Now:
+		return "PASSWORD EXPIRE"
+	case e.Lifetime == PasswordLifetimeNever:
+		return "PASSWORD EXPIRE NEVER"
+	case e.Lifetime == PasswordLifetimeInterval:
+		return fmt.Sprintf("PASSWORD EXPIRE INTERVAL %d DAY", e.Days)
+	default:
+		return "PASSWORD EXPIRE DEFAULT"
+	}
+}
+
+// PasswordManager is implemented by UserManagers that enforce the expiration of passwords. Users whose password has
+// expired can't run any statement but SET PASSWORD until they change it.
+type PasswordManager interface {
+	UserManager
+	// SetPasswordExpire changes when the password of an existing account expires.
+	SetPasswordExpire(ctx *Context, account Account, expire PasswordExpire) error
+	// PasswordExpired returns whether the password of the account the session of the context given authenticated as
+	// has expired.
+	PasswordExpired(ctx *Context) (bool, error)
+}
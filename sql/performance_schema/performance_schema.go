@@ -0,0 +1,229 @@
+// Package performance_schema implements the tables of the performance_schema
+// database of MySQL the engine has the data of, so that the tools that query
+// them instead of using SHOW statements keep working.
+package performance_schema
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+const (
+	// DatabaseName is the name of the performance_schema database.
+	DatabaseName = "performance_schema"
+	// GlobalStatusTableName is the name of the global_status table.
+	GlobalStatusTableName = "global_status"
+	// SessionStatusTableName is the name of the session_status table.
+	SessionStatusTableName = "session_status"
+	// PersistedVariablesTableName is the name of the persisted_variables table.
+	PersistedVariablesTableName = "persisted_variables"
+	// SessionConnectAttrsTableName is the name of the session_connect_attrs
+	// table.
+	SessionConnectAttrsTableName = "session_connect_attrs"
+)
+
+// statusSchema is the schema of the status tables, and of the
+// persisted_variables table.
+func statusSchema(table string) sql.Schema {
+	return sql.Schema{
+		{Name: "VARIABLE_NAME", Type: sql.MustCreateStringWithDefaults(sqltypes.VarChar, 64), Source: table},
+		{Name: "VARIABLE_VALUE", Type: sql.MustCreateStringWithDefaults(sqltypes.VarChar, 1024), Source: table, Nullable: true},
+	}
+}
+
+// statusRows returns the rows of a status table, or of the
+// persisted_variables table, sorted by variable name.
+func statusRows(status map[string]interface{}) []sql.Row {
+	rows := make([]sql.Row, 0, len(status))
+	for name, v := range status {
+		var value interface{}
+		if v != nil {
+			value = fmt.Sprint(v)
+		}
+		rows = append(rows, sql.NewRow(name, value))
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i][0].(string) < rows[j][0].(string)
+	})
+	return rows
+}
+
+// connectAttrsSchema is the schema of the session_connect_attrs table.
+var connectAttrsSchema = sql.Schema{
+	{Name: "PROCESSLIST_ID", Type: sql.Uint64, Source: SessionConnectAttrsTableName},
+	{Name: "ATTR_NAME", Type: sql.MustCreateStringWithDefaults(sqltypes.VarChar, 32), Source: SessionConnectAttrsTableName},
+	{Name: "ATTR_VALUE", Type: sql.MustCreateStringWithDefaults(sqltypes.VarChar, 1024), Source: SessionConnectAttrsTableName, Nullable: true},
+	{Name: "ORDINAL_POSITION", Type: sql.Int32, Source: SessionConnectAttrsTableName, Nullable: true},
+}
+
+// connectAttrsRows returns the rows of the session_connect_attrs table, with
+// the attributes of each client in name order, which is the one their
+// ordinal positions are in.
+func connectAttrsRows(clients map[uint32]sql.Client) []sql.Row {
+	ids := make([]uint32, 0, len(clients))
+	for id := range clients {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var rows []sql.Row
+	for _, id := range ids {
+		attrs := clients[id].ConnectAttrs
+		names := make([]string, 0, len(attrs))
+		for name := range attrs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for i, name := range names {
+			rows = append(rows, sql.NewRow(uint64(id), name, attrs[name], int32(i)))
+		}
+	}
+	return rows
+}
+
+// Database is the performance_schema database.
+type Database struct {
+	tables map[string]*table
+}
+
+var _ sql.Database = (*Database)(nil)
+
+// NewPerformanceSchemaDatabase creates the performance_schema database showing
+// the data of the catalog given, to be added to it.
+func NewPerformanceSchemaDatabase(cat *sql.Catalog) *Database {
+	return &Database{tables: map[string]*table{
+		GlobalStatusTableName: {
+			name:   GlobalStatusTableName,
+			schema: statusSchema(GlobalStatusTableName),
+			rows: func(*sql.Context) ([]sql.Row, error) {
+				return statusRows(cat.Status()), nil
+			},
+		},
+		SessionStatusTableName: {
+			name:   SessionStatusTableName,
+			schema: statusSchema(SessionStatusTableName),
+			rows: func(ctx *sql.Context) ([]sql.Row, error) {
+				return statusRows(cat.StatusOfSession(ctx)), nil
+			},
+		},
+		PersistedVariablesTableName: {
+			name:   PersistedVariablesTableName,
+			schema: statusSchema(PersistedVariablesTableName),
+			rows: func(ctx *sql.Context) ([]sql.Row, error) {
+				// Without a persister no variables are persisted.
+				p, err := cat.VariablePersister()
+				if err != nil {
+					return nil, nil
+				}
+
+				persisted, err := p.Persisted(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return statusRows(persisted), nil
+			},
+		},
+		SessionConnectAttrsTableName: {
+			name:   SessionConnectAttrsTableName,
+			schema: connectAttrsSchema,
+			rows: func(*sql.Context) ([]sql.Row, error) {
+				return connectAttrsRows(cat.Clients()), nil
+			},
+		},
+	}}
+}
+
+// Name implements the sql.Database interface.
+func (d *Database) Name() string {
+	return DatabaseName
+}
+
+// GetTableInsensitive implements the sql.Database interface.
+func (d *Database) GetTableInsensitive(ctx *sql.Context, tblName string) (sql.Table, bool, error) {
+	t, ok := d.tables[strings.ToLower(tblName)]
+	if !ok {
+		return nil, false, nil
+	}
+	return t, true, nil
+}
+
+// GetTableNames implements the sql.Database interface.
+func (d *Database) GetTableNames(ctx *sql.Context) ([]string, error) {
+	names := make([]string, 0, len(d.tables))
+	for name := range d.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// table is a read only table of the performance_schema database, whose rows
+// are computed each time it's read.
+type table struct {
+	name   string
+	schema sql.Schema
+	rows   func(ctx *sql.Context) ([]sql.Row, error)
+}
+
+var _ sql.Table = (*table)(nil)
+
+// Name implements the sql.Table interface.
+func (t *table) Name() string {
+	return t.name
+}
+
+// String implements the sql.Table interface.
+func (t *table) String() string {
+	return t.name
+}
+
+// Schema implements the sql.Table interface.
+func (t *table) Schema() sql.Schema {
+	return t.schema
+}
+
+// Partitions implements the sql.Table interface.
+func (t *table) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &partitionIter{}, nil
+}
+
+// PartitionRows implements the sql.Table interface.
+func (t *table) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	rows, err := t.rows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// partition is the single partition of a table.
+type partition struct{}
+
+// Key implements the sql.Partition interface.
+func (partition) Key() []byte { return []byte(DatabaseName) }
+
+type partitionIter struct {
+	done bool
+}
+
+// Next implements the sql.PartitionIter interface.
+func (i *partitionIter) Next() (sql.Partition, error) {
+	if i.done {
+		return nil, io.EOF
+	}
+	i.done = true
+	return partition{}, nil
+}
+
+// Close implements the sql.PartitionIter interface.
+func (i *partitionIter) Close() error {
+	return nil
+}
@@ -0,0 +1,142 @@
+package performance_schema_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+	"github.com/dolthub/go-mysql-server/sql/performance_schema"
+)
+
+func newEngine() *sqle.Engine {
+	db := memory.NewDatabase("mydb")
+	db.AddTable("a", memory.NewTable("a", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "a"},
+	}))
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	catalog.AddDatabase(performance_schema.NewPerformanceSchemaDatabase(catalog))
+
+	return sqle.New(catalog, analyzer.NewBuilder(catalog).Build(), nil)
+}
+
+func newContext(id uint32) *sql.Context {
+	session := sql.NewSession("localhost:3306", "127.0.0.1:1234", "root", id)
+	ctx := sql.NewContext(context.Background(), sql.WithSession(session))
+	ctx.SetCurrentDatabase("mydb")
+	return ctx
+}
+
+func query(t *testing.T, e *sqle.Engine, ctx *sql.Context, q string) []sql.Row {
+	_, iter, err := e.Query(ctx, q)
+	require.NoError(t, err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(t, err)
+	return rows
+}
+
+func TestStatusTables(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine()
+	ctx, other := newContext(1), newContext(2)
+
+	query(t, e, ctx, "INSERT INTO a VALUES (1), (2), (3)")
+	query(t, e, ctx, "UPDATE a SET i = i + 1 WHERE i > 1")
+	query(t, e, ctx, "SELECT * FROM a")
+	query(t, e, other, "DELETE FROM a WHERE i = 1")
+
+	const q = `SELECT variable_name, variable_value FROM performance_schema.%s
+		WHERE variable_name IN ('Questions', 'Com_insert', 'Com_delete', 'Handler_write',
+			'Handler_update', 'Handler_delete', 'Select_scan', 'Innodb_rows_inserted')
+		ORDER BY 1`
+
+	require.Equal([]sql.Row{
+		{"Com_delete", "0"},
+		{"Com_insert", "1"},
+		{"Handler_delete", "0"},
+		{"Handler_update", "2"},
+		{"Handler_write", "3"},
+		{"Innodb_rows_inserted", "3"},
+		{"Questions", "4"},
+		// Reading the status table is a scan as well.
+		{"Select_scan", "2"},
+	}, query(t, e, ctx, fmt.Sprintf(q, "session_status")))
+
+	require.Equal([]sql.Row{
+		{"Com_delete", "1"},
+		{"Com_insert", "1"},
+		{"Handler_delete", "1"},
+		{"Handler_update", "2"},
+		{"Handler_write", "3"},
+		{"Innodb_rows_inserted", "3"},
+		{"Questions", "6"},
+		{"Select_scan", "3"},
+	}, query(t, e, ctx, fmt.Sprintf(q, "global_status")))
+
+	rows := query(t, e, other, "SHOW SESSION STATUS LIKE 'Handler_read_rnd_next'")
+	require.Equal([]sql.Row{{"Handler_read_rnd_next", "3"}}, rows)
+}
+
+func TestPersistedVariables(t *testing.T) {
+	require := require.New(t)
+
+	p := sql.NewMemoryVariablePersister(map[string]interface{}{
+		"wait_timeout":           "120",
+		"lower_case_table_names": int64(1),
+	})
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	catalog.AddDatabase(performance_schema.NewPerformanceSchemaDatabase(catalog))
+	e := sqle.New(catalog, analyzer.NewBuilder(catalog).Build(), &sqle.Config{Persister: p})
+	ctx := newContext(1)
+
+	// The variables persisted are set when the engine starts.
+	require.Equal([]sql.Row{{int64(120), int32(1)}},
+		query(t, e, ctx, "SELECT @@global.wait_timeout, @@lower_case_table_names"))
+
+	query(t, e, ctx, "SET PERSIST net_read_timeout = 45")
+	query(t, e, ctx, "SET PERSIST_ONLY net_write_timeout = 90")
+	query(t, e, ctx, "RESET PERSIST wait_timeout")
+
+	require.Equal([]sql.Row{
+		{"lower_case_table_names", "1"},
+		{"net_read_timeout", "45"},
+		{"net_write_timeout", "90"},
+	}, query(t, e, ctx, "SELECT * FROM performance_schema.persisted_variables"))
+
+	require.Equal([]sql.Row{{int64(45), int64(60)}},
+		query(t, e, ctx, "SELECT @@global.net_read_timeout, @@global.net_write_timeout"))
+}
+
+func TestSessionConnectAttrs(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine()
+	ctx := newContext(1)
+
+	e.Catalog.AddClient(1, sql.Client{User: "root", ConnectAttrs: map[string]string{
+		"_client_name":    "libmysql",
+		"_client_version": "8.0.22",
+	}})
+	e.Catalog.AddClient(2, sql.Client{User: "root"})
+	e.Catalog.AddClient(3, sql.Client{User: "app", ConnectAttrs: map[string]string{"program_name": "app"}})
+
+	require.Equal([]sql.Row{
+		{uint64(1), "_client_name", "libmysql", int32(0)},
+		{uint64(1), "_client_version", "8.0.22", int32(1)},
+		{uint64(3), "program_name", "app", int32(0)},
+	}, query(t, e, ctx, "SELECT * FROM performance_schema.session_connect_attrs"))
+
+	e.Catalog.RemoveClient(1)
+	require.Equal([]sql.Row{{uint64(3), "program_name"}},
+		query(t, e, ctx, "SELECT PROCESSLIST_ID, ATTR_NAME FROM performance_schema.session_connect_attrs"))
+}
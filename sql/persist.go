@@ -0,0 +1,76 @@
+package sql
+
+import (
+	"strings"
+	"sync"
+)
+
+// VariablePersister stores the global values of the system variables set with SET PERSIST and SET PERSIST_ONLY, so
+// that the engine sets them when it starts. It's supplied by the integrator, who decides where they are kept.
+type VariablePersister interface {
+	// Persist stores the value of a variable, replacing the one stored if there is one.
+	Persist(ctx *Context, name string, value interface{}) error
+	// Unpersist removes the value stored of a variable, returning whether there was one.
+	Unpersist(ctx *Context, name string) (bool, error)
+	// UnpersistAll removes the values stored of all the variables.
+	UnpersistAll(ctx *Context) error
+	// Persisted returns the values stored, by variable name.
+	Persisted(ctx *Context) (map[string]interface{}, error)
+}
+
+// MemoryVariablePersister is a VariablePersister keeping the values of the variables in memory, mostly meant for
+// tests and for integrators to keep them in their own storage.
+type MemoryVariablePersister struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+var _ VariablePersister = (*MemoryVariablePersister)(nil)
+
+// NewMemoryVariablePersister creates a MemoryVariablePersister with the values given, if any.
+func NewMemoryVariablePersister(values map[string]interface{}) *MemoryVariablePersister {
+	p := &MemoryVariablePersister{values: make(map[string]interface{}, len(values))}
+	for name, value := range values {
+		p.values[strings.ToLower(name)] = value
+	}
+	return p
+}
+
+// Persist implements the VariablePersister interface.
+func (p *MemoryVariablePersister) Persist(_ *Context, name string, value interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.values[strings.ToLower(name)] = value
+	return nil
+}
+
+// Unpersist implements the VariablePersister interface.
+func (p *MemoryVariablePersister) Unpersist(_ *Context, name string) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	name = strings.ToLower(name)
+	_, ok := p.values[name]
+	delete(p.values, name)
+	return ok, nil
+}
+
+// UnpersistAll implements the VariablePersister interface.
+func (p *MemoryVariablePersister) UnpersistAll(*Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.values = make(map[string]interface{})
+	return nil
+}
+
+// Persisted implements the VariablePersister interface.
+func (p *MemoryVariablePersister) Persisted(*Context) (map[string]interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	values := make(map[string]interface{}, len(p.values))
+	for name, value := range p.values {
+		values[name] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,309 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// ErrNoCheckConstraintSupport is returned when the table does not support CHECK constraint operations.
+var ErrNoCheckConstraintSupport = errors.NewKind("the table does not support check constraint operations: %s")
+
+// CreateCheck is a node for ALTER TABLE ADD CHECK, which creates a CHECK constraint once the rows of the table are
+// checked to satisfy it, unless it's not enforced.
+type CreateCheck struct {
+	UnaryNode
+	// Check is the constraint to create. Its name is generated from the name of the table if it's empty.
+	Check *sql.CheckDefinition
+	// Expr is the condition of the constraint, resolved against the table.
+	Expr sql.Expression
+}
+
+// DropCheck is a node for ALTER TABLE DROP CHECK.
+type DropCheck struct {
+	UnaryNode
+	Name string
+}
+
+// DropConstraint is a node for ALTER TABLE DROP CONSTRAINT, which drops the CHECK constraint or the foreign key of the
+// table with the name given.
+type DropConstraint struct {
+	UnaryNode
+	Name string
+}
+
+// NewAlterAddCheck returns a CreateCheck node for the constraint given, whose condition is the expression given.
+func NewAlterAddCheck(table sql.Node, check *sql.CheckDefinition, expr sql.Expression) *CreateCheck {
+	return &CreateCheck{
+		UnaryNode: UnaryNode{Child: table},
+		Check:     check,
+		Expr:      expr,
+	}
+}
+
+// NewAlterDropCheck returns a DropCheck node for the constraint with the name given.
+func NewAlterDropCheck(table sql.Node, name string) *DropCheck {
+	return &DropCheck{
+		UnaryNode: UnaryNode{Child: table},
+		Name:      name,
+	}
+}
+
+// NewAlterDropConstraint returns a DropConstraint node for the constraint with the name given.
+func NewAlterDropConstraint(table sql.Node, name string) *DropConstraint {
+	return &DropConstraint{
+		UnaryNode: UnaryNode{Child: table},
+		Name:      name,
+	}
+}
+
+func getCheckAlterable(node sql.Node) (sql.CheckAlterableTable, error) {
+	switch node := node.(type) {
+	case sql.CheckAlterableTable:
+		return node, nil
+	case *ResolvedTable:
+		return getCheckAlterableTable(node.Table)
+	default:
+		return nil, ErrNoCheckConstraintSupport.New(node.String())
+	}
+}
+
+func getCheckAlterableTable(t sql.Table) (sql.CheckAlterableTable, error) {
+	switch t := t.(type) {
+	case sql.CheckAlterableTable:
+		return t, nil
+	case sql.TableWrapper:
+		return getCheckAlterableTable(t.Underlying())
+	default:
+		return nil, ErrNoCheckConstraintSupport.New(t.Name())
+	}
+}
+
+// GetCheckTable returns the underlying CheckTable for the table given, or nil if it isn't a CheckTable.
+func GetCheckTable(t sql.Table) sql.CheckTable {
+	switch t := t.(type) {
+	case sql.CheckTable:
+		return t
+	case sql.TableWrapper:
+		return GetCheckTable(t.Underlying())
+	default:
+		return nil
+	}
+}
+
+// getChecks returns the CHECK constraints of the table given, which has none if it isn't a CheckTable.
+func getChecks(ctx *sql.Context, t sql.Table) ([]sql.CheckDefinition, error) {
+	checkTable := GetCheckTable(t)
+	if checkTable == nil {
+		return nil, nil
+	}
+	return checkTable.GetChecks(ctx)
+}
+
+// Execute creates the CHECK constraint.
+func (p *CreateCheck) Execute(ctx *sql.Context) error {
+	checkAlterable, err := getCheckAlterable(p.Child)
+	if err != nil {
+		return err
+	}
+
+	checks, err := getChecks(ctx, checkAlterable)
+	if err != nil {
+		return err
+	}
+
+	check := *p.Check
+	if check.Name == "" {
+		// MySQL names them after the table, numbering them from 1
+		for i := 1; check.Name == "" || hasCheck(checks, check.Name); i++ {
+			check.Name = fmt.Sprintf("%s_chk_%d", checkAlterable.Name(), i)
+		}
+	} else if hasCheck(checks, check.Name) {
+		return sql.ErrCheckConstraintDuplicateName.New(check.Name)
+	}
+
+	if check.Enforced {
+		if err := p.checkRows(ctx, check.Name); err != nil {
+			return err
+		}
+	}
+
+	return checkAlterable.CreateCheck(ctx, &check)
+}
+
+// checkRows returns an error if a row of the table doesn't satisfy the constraint with the name given.
+func (p *CreateCheck) checkRows(ctx *sql.Context, name string) error {
+	constraint := []*sql.CheckConstraint{{Name: name, Expr: p.Expr, Enforced: true}}
+	return forEachRow(ctx, p.Child, func(row sql.Row) error {
+		return checkRow(ctx, constraint, row)
+	})
+}
+
+func hasCheck(checks []sql.CheckDefinition, name string) bool {
+	for _, check := range checks {
+		if strings.EqualFold(check.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRow returns an error if the row given makes the condition of one of the enforced constraints given false.
+func checkRow(ctx *sql.Context, checks []*sql.CheckConstraint, row sql.Row) error {
+	for _, check := range checks {
+		if !check.Enforced {
+			continue
+		}
+
+		violated, err := sql.EvaluateCondition(ctx, expression.NewIsFalse(check.Expr), row)
+		if err != nil {
+			return err
+		}
+		if violated {
+			return sql.ErrCheckConstraintViolated.New(check.Name)
+		}
+	}
+	return nil
+}
+
+// Execute drops the CHECK constraint.
+func (p *DropCheck) Execute(ctx *sql.Context) error {
+	checkAlterable, err := getCheckAlterable(p.Child)
+	if err != nil {
+		return err
+	}
+	return checkAlterable.DropCheck(ctx, p.Name)
+}
+
+// Execute drops the CHECK constraint or the foreign key.
+func (p *DropConstraint) Execute(ctx *sql.Context) error {
+	rt, ok := p.Child.(*ResolvedTable)
+	if !ok {
+		return ErrNoCheckConstraintSupport.New(p.Child.String())
+	}
+	table := rt.Table
+
+	checks, err := getChecks(ctx, table)
+	if err != nil {
+		return err
+	}
+	if hasCheck(checks, p.Name) {
+		return NewAlterDropCheck(p.Child, p.Name).Execute(ctx)
+	}
+
+	if fkTable := getForeignKeyTable(table); fkTable != nil {
+		fks, err := fkTable.GetForeignKeys(ctx)
+		if err != nil {
+			return err
+		}
+		for _, fk := range fks {
+			if strings.EqualFold(fk.Name, p.Name) {
+				return NewAlterDropForeignKey(p.Child, &sql.ForeignKeyConstraint{Name: fk.Name}).Execute(ctx)
+			}
+		}
+	}
+
+	return sql.ErrConstraintNotFound.New(p.Name)
+}
+
+// RowIter implements the Node interface.
+func (p *CreateCheck) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	if err := p.Execute(ctx); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// RowIter implements the Node interface.
+func (p *DropCheck) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	if err := p.Execute(ctx); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// RowIter implements the Node interface.
+func (p *DropConstraint) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	if err := p.Execute(ctx); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the Node interface.
+func (p *CreateCheck) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(p, len(children), 1)
+	}
+	return NewAlterAddCheck(children[0], p.Check, p.Expr), nil
+}
+
+// WithChildren implements the Node interface.
+func (p *DropCheck) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(p, len(children), 1)
+	}
+	return NewAlterDropCheck(children[0], p.Name), nil
+}
+
+// WithChildren implements the Node interface.
+func (p *DropConstraint) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(p, len(children), 1)
+	}
+	return NewAlterDropConstraint(children[0], p.Name), nil
+}
+
+// Expressions implements the sql.Expressioner interface.
+func (p *CreateCheck) Expressions() []sql.Expression {
+	return []sql.Expression{p.Expr}
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (p *CreateCheck) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(p, len(exprs), 1)
+	}
+	return NewAlterAddCheck(p.Child, p.Check, exprs[0]), nil
+}
+
+// Resolved implements the Resolvable interface.
+func (p *CreateCheck) Resolved() bool {
+	return p.Child.Resolved() && p.Expr.Resolved()
+}
+
+func (p *CreateCheck) Schema() sql.Schema    { return nil }
+func (p *DropCheck) Schema() sql.Schema      { return nil }
+func (p *DropConstraint) Schema() sql.Schema { return nil }
+
+func (p CreateCheck) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("AddCheck(%s)", p.Check.Name)
+	children := []string{
+		fmt.Sprintf("Table(%s)", p.Child.String()),
+		fmt.Sprintf("Expression(%s)", p.Expr.String()),
+	}
+	if !p.Check.Enforced {
+		children = append(children, "NotEnforced")
+	}
+	_ = pr.WriteChildren(children...)
+	return pr.String()
+}
+
+func (p DropCheck) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("DropCheck(%s)", p.Name)
+	_ = pr.WriteChildren(fmt.Sprintf("Table(%s)", p.Child.String()))
+	return pr.String()
+}
+
+func (p DropConstraint) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("DropConstraint(%s)", p.Name)
+	_ = pr.WriteChildren(fmt.Sprintf("Table(%s)", p.Child.String()))
+	return pr.String()
+}
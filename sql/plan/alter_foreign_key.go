@@ -2,6 +2,7 @@ package plan
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"gopkg.in/src-d/go-errors.v1"
@@ -16,6 +17,9 @@ var (
 	ErrForeignKeyMissingColumns = errors.NewKind("cannot create a foreign key without columns")
 	// ErrAddForeignKeyDuplicateColumn is returned when an ALTER TABLE ADD FOREIGN KEY statement has the same column multiple times
 	ErrAddForeignKeyDuplicateColumn = errors.NewKind("cannot have duplicates of columns in a foreign key: `%v`")
+	// ErrCrossDatabaseForeignKeyNotSupported is returned when a foreign key references a table of another database
+	// than the one of a table that can't declare such foreign keys.
+	ErrCrossDatabaseForeignKeyNotSupported = errors.NewKind("table %s doesn't support foreign keys referencing tables of other databases")
 )
 
 type CreateForeignKey struct {
@@ -64,6 +68,59 @@ func getForeignKeyAlterableTable(t sql.Table) (sql.ForeignKeyAlterableTable, err
 	}
 }
 
+// createForeignKey creates the foreign key given in the table given, of the database with the name given. Foreign keys
+// that reference a table of another database are created with CreateCrossDatabaseForeignKey, and the ones naming
+// the database of the table like the others.
+func createForeignKey(ctx *sql.Context, table sql.ForeignKeyAlterableTable, db string, fk *sql.ForeignKeyConstraint) error {
+	fk, err := namedForeignKey(ctx, table, fk)
+	if err != nil {
+		return err
+	}
+
+	if fk.ReferencedDatabase == "" || strings.EqualFold(fk.ReferencedDatabase, db) {
+		return table.CreateForeignKey(ctx, fk.Name, fk.Columns, fk.ReferencedTable, fk.ReferencedColumns, fk.OnUpdate, fk.OnDelete)
+	}
+
+	crossDatabase, ok := table.(sql.CrossDatabaseForeignKeyTable)
+	if !ok {
+		return ErrCrossDatabaseForeignKeyNotSupported.New(table.Name())
+	}
+	return crossDatabase.CreateCrossDatabaseForeignKey(ctx, fk.Name, fk.Columns, fk.ReferencedDatabase, fk.ReferencedTable,
+		fk.ReferencedColumns, fk.OnUpdate, fk.OnDelete)
+}
+
+// namedForeignKey returns the foreign key given of the table given, or a copy of it named after the table if it has no
+// name, like MySQL names them: <table>_ibfk_<n>, numbering them from 1.
+func namedForeignKey(ctx *sql.Context, table sql.Table, fk *sql.ForeignKeyConstraint) (*sql.ForeignKeyConstraint, error) {
+	if fk.Name != "" {
+		return fk, nil
+	}
+
+	var fks []sql.ForeignKeyConstraint
+	if fkTable, ok := table.(sql.ForeignKeyTable); ok {
+		var err error
+		if fks, err = fkTable.GetForeignKeys(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	named := *fk
+	for i := 1; named.Name == "" || hasForeignKey(fks, named.Name); i++ {
+		named.Name = fmt.Sprintf("%s_ibfk_%d", table.Name(), i)
+	}
+	return &named, nil
+}
+
+// hasForeignKey returns whether one of the foreign keys given has the name given.
+func hasForeignKey(fks []sql.ForeignKeyConstraint, name string) bool {
+	for _, fk := range fks {
+		if strings.EqualFold(fk.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // Execute inserts the rows in the database.
 func (p *CreateForeignKey) Execute(ctx *sql.Context) error {
 	fkAlterable, err := getForeignKeyAlterable(p.BinaryNode.left)
@@ -99,7 +156,103 @@ func (p *CreateForeignKey) Execute(ctx *sql.Context) error {
 		}
 	}
 
-	return fkAlterable.CreateForeignKey(ctx, p.FkDef.Name, p.FkDef.Columns, p.FkDef.ReferencedTable, p.FkDef.ReferencedColumns, p.FkDef.OnUpdate, p.FkDef.OnDelete)
+	fk, err := namedForeignKey(ctx, fkAlterable, p.FkDef)
+	if err != nil {
+		return err
+	}
+
+	// The rows already in the table aren't checked while foreign keys aren't, as when dumps are loaded
+	if sql.ForeignKeyChecks(ctx) {
+		if err := p.checkRows(ctx, fkAlterable.Name(), fk); err != nil {
+			return err
+		}
+	}
+
+	var db string
+	if rt, ok := p.left.(*ResolvedTable); ok {
+		db = rt.Database
+	}
+	return createForeignKey(ctx, fkAlterable, db, fk)
+}
+
+// checkRows returns an error if a row of the table with the name given references a row that the referenced table
+// doesn't have, by the foreign key given.
+func (p *CreateForeignKey) checkRows(ctx *sql.Context, table string, fk *sql.ForeignKeyConstraint) error {
+	childSchema, parentSchema := p.left.Schema(), p.right.Schema()
+	childColumns, err := foreignKeyColumns(childSchema, fk.Columns)
+	if err != nil {
+		return err
+	}
+	parentColumns, err := foreignKeyColumns(parentSchema, fk.ReferencedColumns)
+	if err != nil {
+		return err
+	}
+	if len(childColumns) != len(parentColumns) {
+		return fmt.Errorf("foreign key %s has %d columns referencing %d columns",
+			fk.Name, len(childColumns), len(parentColumns))
+	}
+
+	keys := make(map[string]bool)
+	err = forEachRow(ctx, p.right, func(row sql.Row) error {
+		key, err := rowKey(row, parentColumns, parentSchema, parentColumns)
+		if key != nil {
+			keys[fmt.Sprint(key)] = true
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return forEachRow(ctx, p.left, func(row sql.Row) error {
+		key, err := rowKey(row, childColumns, parentSchema, parentColumns)
+		if err != nil || key == nil || keys[fmt.Sprint(key)] {
+			return err
+		}
+		return sql.ErrForeignKeyChildViolation.New(table, fk.Name, quoteColumns(fk.Columns),
+			fk.ReferencedTable, quoteColumns(fk.ReferencedColumns))
+	})
+}
+
+// foreignKeyColumns returns the positions of the columns given in the schema given.
+func foreignKeyColumns(schema sql.Schema, columns []string) ([]int, error) {
+	positions := make([]int, len(columns))
+	for i, col := range columns {
+		positions[i] = -1
+		for j, c := range schema {
+			if strings.EqualFold(c.Name, col) {
+				positions[i] = j
+				break
+			}
+		}
+		if positions[i] < 0 {
+			return nil, sql.ErrTableColumnNotFound.New(col)
+		}
+	}
+	return positions, nil
+}
+
+// forEachRow calls f with each row of the node given, until it returns an error.
+func forEachRow(ctx *sql.Context, node sql.Node, f func(sql.Row) error) error {
+	iter, err := node.RowIter(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			err = f(row)
+		}
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+	}
+	return iter.Close()
 }
 
 // Execute inserts the rows in the database.
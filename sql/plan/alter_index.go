@@ -18,6 +18,12 @@ var (
 	ErrCreateIndexNonExistentColumn = errors.NewKind("column `%v` does not exist in the table")
 	// ErrCreateIndexDuplicateColumn is returned when a CREATE INDEX statement has the same column multiple times
 	ErrCreateIndexDuplicateColumn = errors.NewKind("cannot have duplicates of columns in an index: `%v`")
+	// ErrCreateIndexInvalidPrefix is returned when a key part has a prefix length that the column it's on can't have
+	ErrCreateIndexInvalidPrefix = errors.NewKind("incorrect prefix key on column `%v`: the column isn't a string, or the prefix is longer than it")
+	// ErrInvisiblePrimaryKey is returned when trying to make the primary key invisible
+	ErrInvisiblePrimaryKey = errors.NewKind("a primary key index cannot be invisible")
+	// ErrIndexVisibilityNotSupported is returned when making an index of a table invisible isn't supported by the table
+	ErrIndexVisibilityNotSupported = errors.NewKind("the table doesn't support invisible indexes")
 )
 
 type IndexAction byte
@@ -26,10 +32,11 @@ const (
 	IndexAction_Create IndexAction = iota
 	IndexAction_Drop
 	IndexAction_Rename
+	IndexAction_Visibility
 )
 
 type AlterIndex struct {
-	// Action states whether it's a CREATE, DROP, or RENAME
+	// Action states whether it's a CREATE, DROP, RENAME, or a change of the visibility of the index
 	Action IndexAction
 	// Table is the table that is being referenced
 	Table sql.Node
@@ -41,10 +48,13 @@ type AlterIndex struct {
 	Using sql.IndexUsing
 	// Constraint specifies whether this is UNIQUE, FULLTEXT, SPATIAL, or none
 	Constraint sql.IndexConstraint
-	// Columns contains the column names (and possibly lengths) when creating an index
+	// Columns contains the column names (and possibly lengths), or the expressions of functional key parts, when
+	// creating an index
 	Columns []sql.IndexColumn
 	// Comment is the comment that was left at index creation, if any
 	Comment string
+	// Invisible states whether the index is created or altered to be invisible
+	Invisible bool
 }
 
 func NewAlterCreateIndex(table sql.Node, indexName string, using sql.IndexUsing, constraint sql.IndexConstraint, columns []sql.IndexColumn, comment string) *AlterIndex {
@@ -76,6 +86,15 @@ func NewAlterRenameIndex(table sql.Node, fromIndexName, toIndexName string) *Alt
 	}
 }
 
+func NewAlterIndexVisibility(table sql.Node, indexName string, visible bool) *AlterIndex {
+	return &AlterIndex{
+		Action:    IndexAction_Visibility,
+		Table:     table,
+		IndexName: indexName,
+		Invisible: !visible,
+	}
+}
+
 // Schema implements the Node interface.
 func (p *AlterIndex) Schema() sql.Schema {
 	return nil
@@ -122,6 +141,9 @@ func (p *AlterIndex) Execute(ctx *sql.Context) error {
 			seenCols[col.Name] = false
 		}
 		for _, indexCol := range p.Columns {
+			if indexCol.Expression != nil {
+				continue
+			}
 			if seen, ok := seenCols[indexCol.Name]; ok {
 				if !seen {
 					seenCols[indexCol.Name] = true
@@ -133,16 +155,81 @@ func (p *AlterIndex) Execute(ctx *sql.Context) error {
 			}
 		}
 
-		return indexable.CreateIndex(ctx, p.IndexName, p.Using, p.Constraint, p.Columns, p.Comment)
+		if err := validateIndexPrefixes(indexable.Schema(), p.Columns); err != nil {
+			return err
+		}
+
+		if !p.Invisible {
+			return indexable.CreateIndex(ctx, p.IndexName, p.Using, p.Constraint, p.Columns, p.Comment)
+		}
+
+		visibilityAlterable, err := getIndexVisibilityAlterable(indexable, p.IndexName)
+		if err != nil {
+			return err
+		}
+		if err := indexable.CreateIndex(ctx, p.IndexName, p.Using, p.Constraint, p.Columns, p.Comment); err != nil {
+			return err
+		}
+		return visibilityAlterable.SetIndexVisibility(ctx, p.IndexName, false)
 	case IndexAction_Drop:
 		return indexable.DropIndex(ctx, p.IndexName)
 	case IndexAction_Rename:
 		return indexable.RenameIndex(ctx, p.PreviousIndexName, p.IndexName)
+	case IndexAction_Visibility:
+		if !p.Invisible {
+			if visibilityAlterable, ok := indexable.(sql.IndexVisibilityAlterableTable); ok {
+				return visibilityAlterable.SetIndexVisibility(ctx, p.IndexName, true)
+			}
+			// The indexes of tables that don't support invisible indexes are all visible already.
+			return nil
+		}
+
+		visibilityAlterable, err := getIndexVisibilityAlterable(indexable, p.IndexName)
+		if err != nil {
+			return err
+		}
+		return visibilityAlterable.SetIndexVisibility(ctx, p.IndexName, false)
 	default:
 		return ErrIndexActionNotImplemented.New(p.Action)
 	}
 }
 
+// getIndexVisibilityAlterable returns the table given as a table that supports making the index named invisible, or an
+// error if it doesn't or the index is the primary key.
+func getIndexVisibilityAlterable(table sql.IndexAlterableTable, indexName string) (sql.IndexVisibilityAlterableTable, error) {
+	if strings.EqualFold(indexName, "PRIMARY") {
+		return nil, ErrInvisiblePrimaryKey.New()
+	}
+
+	visibilityAlterable, ok := table.(sql.IndexVisibilityAlterableTable)
+	if !ok {
+		return nil, ErrIndexVisibilityNotSupported.New()
+	}
+	return visibilityAlterable, nil
+}
+
+// validateIndexPrefixes returns an error if any of the key parts given has a prefix length and isn't on a string
+// column, or is longer than the values of a CHAR, VARCHAR, BINARY or VARBINARY column.
+func validateIndexPrefixes(schema sql.Schema, columns []sql.IndexColumn) error {
+	for _, indexCol := range columns {
+		if indexCol.Length == 0 {
+			continue
+		}
+
+		for _, col := range schema {
+			if !strings.EqualFold(col.Name, indexCol.Name) {
+				continue
+			}
+
+			st, ok := col.Type.(sql.StringType)
+			if !ok || (!sql.IsTextBlob(st) && indexCol.Length > st.MaxCharacterLength()) {
+				return ErrCreateIndexInvalidPrefix.New(indexCol.Name)
+			}
+		}
+	}
+	return nil
+}
+
 // RowIter implements the Node interface.
 func (p *AlterIndex) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 	err := p.Execute(ctx)
@@ -160,11 +247,15 @@ func (p *AlterIndex) WithChildren(children ...sql.Node) (sql.Node, error) {
 	}
 	switch p.Action {
 	case IndexAction_Create:
-		return NewAlterCreateIndex(children[0], p.IndexName, p.Using, p.Constraint, p.Columns, p.Comment), nil
+		np := NewAlterCreateIndex(children[0], p.IndexName, p.Using, p.Constraint, p.Columns, p.Comment)
+		np.Invisible = p.Invisible
+		return np, nil
 	case IndexAction_Drop:
 		return NewAlterDropIndex(children[0], p.IndexName), nil
 	case IndexAction_Rename:
 		return NewAlterRenameIndex(children[0], p.PreviousIndexName, p.IndexName), nil
+	case IndexAction_Visibility:
+		return NewAlterIndexVisibility(children[0], p.IndexName, !p.Invisible), nil
 	default:
 		return nil, ErrIndexActionNotImplemented.New(p.Action)
 	}
@@ -192,7 +283,9 @@ func (p AlterIndex) String() string {
 		}
 		cols := make([]string, len(p.Columns))
 		for i, col := range p.Columns {
-			if col.Length == 0 {
+			if col.Expression != nil {
+				cols[i] = fmt.Sprintf("(%s)", col.Expression)
+			} else if col.Length == 0 {
 				cols[i] = col.Name
 			} else {
 				cols[i] = fmt.Sprintf("%s(%v)", col.Name, col.Length)
@@ -200,6 +293,9 @@ func (p AlterIndex) String() string {
 		}
 		children = append(children, fmt.Sprintf("Columns(%s)", strings.Join(cols, ", ")))
 		children = append(children, fmt.Sprintf("Comment(%s)", p.Comment))
+		if p.Invisible {
+			children = append(children, "Invisible")
+		}
 		_ = pr.WriteChildren(children...)
 	case IndexAction_Drop:
 		_ = pr.WriteNode("DropIndex(%s)", p.IndexName)
@@ -211,6 +307,16 @@ func (p AlterIndex) String() string {
 			fmt.Sprintf("FromIndex(%s)", p.PreviousIndexName),
 			fmt.Sprintf("ToIndex(%s)", p.IndexName),
 		)
+	case IndexAction_Visibility:
+		visibility := "VISIBLE"
+		if p.Invisible {
+			visibility = "INVISIBLE"
+		}
+		_ = pr.WriteNode("AlterIndex(%s)", p.IndexName)
+		_ = pr.WriteChildren(
+			fmt.Sprintf("Table(%s)", p.Table.String()),
+			fmt.Sprintf("Visibility(%s)", visibility),
+		)
 	default:
 		_ = pr.WriteNode("Unknown_Index_Action(%v)", p.Action)
 	}
@@ -218,7 +324,45 @@ func (p AlterIndex) String() string {
 }
 
 func (p *AlterIndex) Resolved() bool {
-	return p.Table.Resolved()
+	if !p.Table.Resolved() {
+		return false
+	}
+
+	for _, e := range p.Expressions() {
+		if !e.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// Expressions implements the sql.Expressioner interface. It returns the expressions of the functional key parts of the
+// index being created.
+func (p *AlterIndex) Expressions() []sql.Expression {
+	var exprs []sql.Expression
+	for _, col := range p.Columns {
+		if col.Expression != nil {
+			exprs = append(exprs, col.Expression)
+		}
+	}
+	return exprs
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (p *AlterIndex) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != len(p.Expressions()) {
+		return nil, sql.ErrInvalidChildrenNumber.New(p, len(exprs), len(p.Expressions()))
+	}
+
+	np := *p
+	np.Columns = make([]sql.IndexColumn, len(p.Columns))
+	for i, col := range p.Columns {
+		if col.Expression != nil {
+			col.Expression, exprs = exprs[0], exprs[1:]
+		}
+		np.Columns[i] = col
+	}
+	return &np, nil
 }
 
 func (p *AlterIndex) Children() []sql.Node {
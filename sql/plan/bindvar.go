@@ -25,8 +25,10 @@ import (
 // returned and the |BindVar| expression is left in place. There is no check on
 // whether all entries in |bindings| are used at least once throughout the |n|.
 //
-// This applies binding substitutions across *SubqueryAlias nodes, but will
-// fail to apply bindings across other |sql.Opaque| nodes.
+// This applies binding substitutions across *SubqueryAlias nodes and
+// *Subquery expressions, but will fail to apply bindings across other
+// |sql.Opaque| nodes. The bindings of the LIMIT and OFFSET of *Limit and
+// *Offset nodes must be non-negative integers.
 func ApplyBindings(n sql.Node, bindings map[string]sql.Expression) (sql.Node, error) {
 	withSubqueries, err := TransformUp(n, func(n sql.Node) (sql.Node, error) {
 		switch n := n.(type) {
@@ -36,6 +38,20 @@ func ApplyBindings(n sql.Node, bindings map[string]sql.Expression) (sql.Node, er
 				return nil, err
 			}
 			return n.WithChildren(child)
+		case *Limit:
+			val, found, err := boundSize(n.BindVar, "LIMIT", bindings)
+			if err != nil || !found {
+				return n, err
+			}
+			limit := NewLimit(val, n.Child)
+			limit.CalcFoundRows = n.CalcFoundRows
+			return limit, nil
+		case *Offset:
+			val, found, err := boundSize(n.BindVar, "OFFSET", bindings)
+			if err != nil || !found {
+				return n, err
+			}
+			return NewOffset(val, n.Child), nil
 		default:
 			return n, nil
 		}
@@ -44,12 +60,47 @@ func ApplyBindings(n sql.Node, bindings map[string]sql.Expression) (sql.Node, er
 		return nil, err
 	}
 	return TransformExpressionsUp(withSubqueries, func(e sql.Expression) (sql.Expression, error) {
-		if bv, ok := e.(*expression.BindVar); ok {
-			val, found := bindings[bv.Name]
+		switch e := e.(type) {
+		case *expression.BindVar:
+			val, found := bindings[e.Name]
 			if found {
 				return val, nil
 			}
+		case *Subquery:
+			query, err := ApplyBindings(e.Query, bindings)
+			if err != nil {
+				return nil, err
+			}
+			return e.WithQuery(query), nil
 		}
 		return e, nil
 	})
 }
+
+// boundSize returns the value bound to the LIMIT or OFFSET given by the name
+// of its variable, if any.
+func boundSize(name, clause string, bindings map[string]sql.Expression) (int64, bool, error) {
+	if name == "" {
+		return 0, false, nil
+	}
+
+	e, found := bindings[name]
+	if !found {
+		return 0, false, nil
+	}
+
+	val, err := e.Eval(sql.NewEmptyContext(), nil)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if !sql.IsInteger(e.Type()) {
+		return 0, false, sql.ErrWrongArgumentsToLimit.New(clause)
+	}
+
+	size, err := sql.Int64.Convert(val)
+	if err != nil || size.(int64) < 0 {
+		return 0, false, sql.ErrWrongArgumentsToLimit.New(clause)
+	}
+	return size.(int64), true, nil
+}
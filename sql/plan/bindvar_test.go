@@ -192,6 +192,59 @@ func TestApplyBindings(t *testing.T) {
 				),
 			),
 		},
+		tc{
+			"SubqueryExpression",
+			NewFilter(
+				expression.NewEquals(
+					expression.NewUnresolvedColumn("foo"),
+					NewSubquery(
+						NewFilter(
+							expression.NewLessThan(
+								expression.NewUnresolvedColumn("bar"),
+								expression.NewBindVar("v1"),
+							),
+							NewUnresolvedTable("t2", ""),
+						),
+						"select bar from t2 where bar < :v1",
+					),
+				),
+				NewUnresolvedTable("t1", ""),
+			),
+			map[string]sql.Expression{
+				"v1": expression.NewLiteral(int64(3), sql.Int64),
+			},
+			NewFilter(
+				expression.NewEquals(
+					expression.NewUnresolvedColumn("foo"),
+					NewSubquery(
+						NewFilter(
+							expression.NewLessThan(
+								expression.NewUnresolvedColumn("bar"),
+								expression.NewLiteral(int64(3), sql.Int64),
+							),
+							NewUnresolvedTable("t2", ""),
+						),
+						"select bar from t2 where bar < :v1",
+					),
+				),
+				NewUnresolvedTable("t1", ""),
+			),
+		},
+		tc{
+			"LimitOffset",
+			NewLimit(
+				5,
+				NewOffset(0, NewBindLimit("v1", NewBindOffset("v2", NewUnresolvedTable("t1", "")))),
+			),
+			map[string]sql.Expression{
+				"v1": expression.NewLiteral(int8(10), sql.Int8),
+				"v2": expression.NewLiteral(uint64(2), sql.Uint64),
+			},
+			NewLimit(
+				5,
+				NewOffset(0, NewLimit(10, NewOffset(2, NewUnresolvedTable("t1", "")))),
+			),
+		},
 	}
 
 	for _, c := range cases {
@@ -203,3 +256,17 @@ func TestApplyBindings(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyBindingsWrongLimit(t *testing.T) {
+	for _, v := range []sql.Expression{
+		expression.NewLiteral(int64(-1), sql.Int64),
+		expression.NewLiteral("10", sql.LongText),
+		expression.NewLiteral(1.5, sql.Float64),
+	} {
+		_, err := ApplyBindings(
+			NewBindLimit("v1", NewUnresolvedTable("t1", "")),
+			map[string]sql.Expression{"v1": v},
+		)
+		assert.True(t, sql.ErrWrongArgumentsToLimit.Is(err), "%s", v)
+	}
+}
@@ -0,0 +1,109 @@
+package plan
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// copyRowsIter returns an iterator with the result of the insert given, executed by its table as a copy of the rows of
+// the table it selects, or nil if it can't be. That's the case of the inserts of the rows of a table, or of an index
+// lookup of it, with the same column types, which need no values computed, converted or checked.
+func copyRowsIter(ctx *sql.Context, n sql.Node, row sql.Row) (sql.RowIter, error) {
+	insert, ok := n.(*InsertInto)
+	if !ok || len(row) > 0 || insert.IsReplace || len(insert.OnDupExprs) > 0 || insert.Ignore {
+		return nil, nil
+	}
+	if insert.ForeignKeys != nil && sql.ForeignKeyChecks(ctx) {
+		return nil, nil
+	}
+	for _, check := range insert.Checks {
+		if check.Enforced {
+			return nil, nil
+		}
+	}
+
+	rt, ok := insert.Left().(*ResolvedTable)
+	if !ok {
+		return nil, nil
+	}
+	copyable, ok := unwrapTable(rt.Table).(sql.CopyableTable)
+	if !ok {
+		return nil, nil
+	}
+
+	source, filter := copySource(insert.Right(), copyable.Schema())
+	if source == nil {
+		return nil, nil
+	}
+
+	copied, ok, err := copyable.CopyRows(ctx, source, filter)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return sql.RowsToRowIter(sql.NewRow(sql.NewOkResult(copied))), nil
+}
+
+// copySource returns the table whose rows the source of an insert given returns as they are, with the filter of the
+// rows read, or nil if it returns other values, or values of other types or nullability than the ones of the schema of
+// the table inserted into given.
+func copySource(n sql.Node, schema sql.Schema) (sql.Table, sql.Expression) {
+	// The source of inserts is projected to the columns of the table inserted into
+	project, ok := n.(*Project)
+	if !ok || len(project.Projections) != len(schema) {
+		return nil, nil
+	}
+	for i, e := range project.Projections {
+		field, ok := e.(*expression.GetField)
+		if !ok || field.Index() != i {
+			return nil, nil
+		}
+	}
+
+	var filter sql.Expression
+	child := project.Child
+	for {
+		switch c := child.(type) {
+		case *DecoratedNode:
+			child = c.Child
+			continue
+		case *Exchange:
+			child = c.Child
+			continue
+		case *Filter:
+			if filter != nil {
+				return nil, nil
+			}
+			filter = c.Expression
+			child = c.Child
+			continue
+		}
+		break
+	}
+
+	rt, ok := child.(*ResolvedTable)
+	if !ok {
+		return nil, nil
+	}
+
+	sourceSchema := rt.Schema()
+	if len(sourceSchema) != len(schema) {
+		return nil, nil
+	}
+	for i, col := range schema {
+		if !sql.TypesEqual(col.Type, sourceSchema[i].Type) || !col.Nullable && sourceSchema[i].Nullable {
+			return nil, nil
+		}
+	}
+	return unwrapTable(rt.Table), filter
+}
+
+// unwrapTable returns the table wrapped by the one given, if it's a wrapper, or the table given otherwise.
+func unwrapTable(table sql.Table) sql.Table {
+	for {
+		wrapper, ok := table.(sql.TableWrapper)
+		if !ok {
+			return table
+		}
+		table = wrapper.Underlying()
+	}
+}
@@ -0,0 +1,128 @@
+package plan
+
+import (
+	"fmt"
+
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ErrAlterDatabaseNotSupported is returned by ALTER DATABASE for databases that have no default collation of their own.
+var ErrAlterDatabaseNotSupported = errors.NewKind("database %s doesn't support changing its character set or collation")
+
+// CreateDatabase creates a database with the DatabaseCreator of the catalog.
+type CreateDatabase struct {
+	Name        string
+	IfNotExists bool
+	// Collation is the default collation of the database, given by its CHARACTER SET and COLLATE options.
+	Collation sql.Collation
+	Catalog   *sql.Catalog
+}
+
+var _ sql.Node = (*CreateDatabase)(nil)
+
+// NewCreateDatabase creates a new CreateDatabase node.
+func NewCreateDatabase(name string, ifNotExists bool, collation sql.Collation) *CreateDatabase {
+	return &CreateDatabase{Name: name, IfNotExists: ifNotExists, Collation: collation}
+}
+
+// Children implements the sql.Node interface.
+func (*CreateDatabase) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*CreateDatabase) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*CreateDatabase) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (c *CreateDatabase) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	err := c.Catalog.CreateDatabase(ctx, c.Name, c.Collation)
+	if sql.ErrDatabaseExists.Is(err) && c.IfNotExists {
+		ctx.Note(1007, "Can't create database '%s'; database exists", c.Name)
+	} else if err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (c *CreateDatabase) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(c, len(children), 0)
+	}
+	return c, nil
+}
+
+// String implements the sql.Node interface.
+func (c *CreateDatabase) String() string {
+	var ifNotExists string
+	if c.IfNotExists {
+		ifNotExists = "IF NOT EXISTS "
+	}
+	return fmt.Sprintf("CREATE DATABASE %s%s COLLATE %s", ifNotExists, c.Name, c.Collation)
+}
+
+// AlterDatabase changes the default collation of a database.
+type AlterDatabase struct {
+	db        sql.Database
+	Collation sql.Collation
+}
+
+var _ sql.Node = (*AlterDatabase)(nil)
+var _ sql.Databaser = (*AlterDatabase)(nil)
+
+// NewAlterDatabase creates a new AlterDatabase node.
+func NewAlterDatabase(db sql.Database, collation sql.Collation) *AlterDatabase {
+	return &AlterDatabase{db: db, Collation: collation}
+}
+
+// Database implements the sql.Databaser interface.
+func (a *AlterDatabase) Database() sql.Database {
+	return a.db
+}
+
+// WithDatabase implements the sql.Databaser interface.
+func (a *AlterDatabase) WithDatabase(db sql.Database) (sql.Node, error) {
+	na := *a
+	na.db = db
+	return &na, nil
+}
+
+// Children implements the sql.Node interface.
+func (*AlterDatabase) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (a *AlterDatabase) Resolved() bool {
+	_, ok := a.db.(sql.UnresolvedDatabase)
+	return !ok
+}
+
+// Schema implements the sql.Node interface.
+func (*AlterDatabase) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (a *AlterDatabase) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	collated, ok := a.db.(sql.CollatedDatabase)
+	if !ok {
+		return nil, ErrAlterDatabaseNotSupported.New(a.db.Name())
+	}
+	if err := collated.SetCollation(ctx, a.Collation); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (a *AlterDatabase) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(a, len(children), 0)
+	}
+	return a, nil
+}
+
+// String implements the sql.Node interface.
+func (a *AlterDatabase) String() string {
+	return fmt.Sprintf("ALTER DATABASE %s COLLATE %s", a.db.Name(), a.Collation)
+}
@@ -59,6 +59,7 @@ type IndexDefinition struct {
 	Constraint sql.IndexConstraint
 	Columns    []sql.IndexColumn
 	Comment    string
+	Invisible  bool
 }
 
 // CreateTable is a node describing the creation of some table.
@@ -70,6 +71,9 @@ type CreateTable struct {
 	fkDefs      []*sql.ForeignKeyConstraint
 	idxDefs     []*IndexDefinition
 	like        sql.Node
+	options     sql.TableOptions
+	// defaultCollated are the indexes of the columns declared without a character set or collation.
+	defaultCollated []int
 }
 
 var _ sql.Databaser = (*CreateTable)(nil)
@@ -92,6 +96,26 @@ func NewCreateTable(db sql.Database, name string, schema sql.Schema, ifNotExists
 	}
 }
 
+// WithTableOptions returns a copy of the node that creates the table with the options given.
+func (c *CreateTable) WithTableOptions(options sql.TableOptions) *CreateTable {
+	nc := *c
+	nc.options = options
+	return &nc
+}
+
+// TableOptions returns the options the table is created with.
+func (c *CreateTable) TableOptions() sql.TableOptions {
+	return c.options
+}
+
+// WithDefaultCollatedColumns returns a copy of the node whose columns at the indexes given, which are declared without
+// a character set or collation, take the default collation of the table.
+func (c *CreateTable) WithDefaultCollatedColumns(columns []int) *CreateTable {
+	nc := *c
+	nc.defaultCollated = columns
+	return &nc
+}
+
 // NewCreateTableLike creates a new CreateTable node for CREATE TABLE LIKE statements
 func NewCreateTableLike(db sql.Database, name string, likeTable sql.Node, ifNotExists bool) *CreateTable {
 	return &CreateTable{
@@ -127,14 +151,29 @@ func (c *CreateTable) Resolved() bool {
 func (c *CreateTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 	creatable, ok := c.db.(sql.TableCreator)
 	if ok {
+		// The columns take the default collation of the table before they're validated and created
+		c, err := c.withDefaultCollation()
+		if err != nil {
+			return sql.RowsToRowIter(), err
+		}
 		if err := c.validateDefaultPosition(); err != nil {
 			return sql.RowsToRowIter(), err
 		}
+		for _, idxDef := range c.idxDefs {
+			if err := validateIndexPrefixes(c.schema, idxDef.Columns); err != nil {
+				return sql.RowsToRowIter(), err
+			}
+		}
 
-		err := creatable.CreateTable(ctx, c.name, c.schema)
+		err = c.createTable(ctx, creatable)
 		if err != nil && !(sql.ErrTableAlreadyExists.Is(err) && c.ifNotExists) {
 			return sql.RowsToRowIter(), err
 		}
+		if err != nil {
+			ctx.Note(1050, "Table '%s' already exists", c.name)
+		} else if err := c.setAutoIncrementOption(ctx); err != nil {
+			return sql.RowsToRowIter(), err
+		}
 		//TODO: in the event that foreign keys or indexes aren't supported, you'll be left with a created table and no foreign keys/indexes
 		//this also means that if a foreign key or index fails, you'll only have what was declared up to the failure
 		if len(c.idxDefs) > 0 || len(c.fkDefs) > 0 {
@@ -155,6 +194,16 @@ func (c *CreateTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 					if err != nil {
 						return sql.RowsToRowIter(), err
 					}
+					if idxDef.Invisible {
+						visibilityAlterable, err := getIndexVisibilityAlterable(idxAlterable, idxDef.IndexName)
+						if err != nil {
+							return sql.RowsToRowIter(), err
+						}
+						err = visibilityAlterable.SetIndexVisibility(ctx, idxDef.IndexName, false)
+						if err != nil {
+							return sql.RowsToRowIter(), err
+						}
+					}
 				}
 			}
 			if len(c.fkDefs) > 0 {
@@ -163,7 +212,7 @@ func (c *CreateTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 					return sql.RowsToRowIter(), ErrNoForeignKeySupport.New(c.name)
 				}
 				for _, fkDef := range c.fkDefs {
-					err = fkAlterable.CreateForeignKey(ctx, fkDef.Name, fkDef.Columns, fkDef.ReferencedTable, fkDef.ReferencedColumns, fkDef.OnUpdate, fkDef.OnDelete)
+					err = createForeignKey(ctx, fkAlterable, c.db.Name(), fkDef)
 					if err != nil {
 						return sql.RowsToRowIter(), err
 					}
@@ -176,6 +225,93 @@ func (c *CreateTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 	return nil, ErrCreateTableNotSupported.New(c.db.Name())
 }
 
+// withDefaultCollation returns a copy of the node whose columns declared without a character set or collation take the
+// default collation of the table. That's the one of its CHARACTER SET and COLLATE options, or the one of its database,
+// which the table is then created with.
+func (c *CreateTable) withDefaultCollation() (*CreateTable, error) {
+	nc := *c
+	charset, hasCharset := c.options.Get(sql.TableOption_CharacterSet)
+	collate, hasCollate := c.options.Get(sql.TableOption_Collate)
+
+	var collation sql.Collation
+	if hasCharset || hasCollate {
+		charset, collate = strings.ToLower(charset), strings.ToLower(collate)
+		var err error
+		if collation, err = sql.ParseCollation(&charset, &collate, false); err != nil {
+			return nil, err
+		}
+	} else {
+		collation = sql.GetDatabaseCollation(c.db)
+		if collation == sql.Collation_Default {
+			return c, nil
+		}
+		nc.options = append(c.options[:len(c.options):len(c.options)],
+			sql.TableOption{Name: sql.TableOption_CharacterSet, Value: collation.CharacterSet().String()},
+			sql.TableOption{Name: sql.TableOption_Collate, Value: collation.String()},
+		)
+	}
+
+	if len(c.defaultCollated) == 0 || collation == sql.Collation_Default {
+		return &nc, nil
+	}
+	nc.schema = make(sql.Schema, len(c.schema))
+	for i, col := range c.schema {
+		copied := *col
+		nc.schema[i] = &copied
+	}
+	for _, i := range c.defaultCollated {
+		typ, err := sql.CollatedType(nc.schema[i].Type, collation)
+		if err != nil {
+			return nil, err
+		}
+		nc.schema[i].Type = typ
+	}
+	return &nc, nil
+}
+
+// GetTableOptions returns the options the table given was created with, or nil if it doesn't report them.
+func GetTableOptions(t sql.Table) sql.TableOptions {
+	switch t := t.(type) {
+	case sql.TableOptionsTable:
+		return t.TableOptions()
+	case sql.TableWrapper:
+		return GetTableOptions(t.Underlying())
+	default:
+		return nil
+	}
+}
+
+// createTable creates the table with the database given, with its options if the database supports them.
+func (c *CreateTable) createTable(ctx *sql.Context, creatable sql.TableCreator) error {
+	if optionsCreator, ok := creatable.(sql.TableOptionsCreator); ok && len(c.options) > 0 {
+		return optionsCreator.CreateTableWithOptions(ctx, c.name, c.schema, c.options)
+	}
+	return creatable.CreateTable(ctx, c.name, c.schema)
+}
+
+// setAutoIncrementOption sets the next value of the auto increment column of the table created to the one given by
+// its AUTO_INCREMENT option, if it has one.
+func (c *CreateTable) setAutoIncrementOption(ctx *sql.Context) error {
+	value, ok := c.options.Get(sql.TableOption_AutoIncrement)
+	if !ok {
+		return nil
+	}
+
+	table, ok, err := c.db.GetTableInsensitive(ctx, c.name)
+	if err != nil || !ok {
+		return err
+	}
+	autoTbl := getAutoIncrementTable(table)
+	if autoTbl == nil {
+		return nil
+	}
+	setter := autoTbl.AutoIncrementSetter(ctx)
+	if err := setter.SetAutoIncrementValue(ctx, value); err != nil {
+		return err
+	}
+	return setter.Close(ctx)
+}
+
 // Children implements the Node interface.
 func (c *CreateTable) Children() []sql.Node {
 	if c.like != nil {
@@ -310,6 +446,7 @@ func (d *DropTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error)
 
 		if !ok {
 			if d.ifExists {
+				ctx.Note(1051, "Unknown table '%s.%s'", d.db.Name(), tableName)
 				continue
 			}
 
@@ -370,6 +507,16 @@ func NewRenameTable(db sql.Database, oldNames, newNames []string) *RenameTable {
 	}
 }
 
+// OldNames returns the names of the tables renamed.
+func (r *RenameTable) OldNames() []string {
+	return r.oldNames
+}
+
+// NewNames returns the new names of the tables renamed.
+func (r *RenameTable) NewNames() []string {
+	return r.newNames
+}
+
 func (r *RenameTable) WithDatabase(db sql.Database) (sql.Node, error) {
 	nr := *r
 	nr.db = db
@@ -380,32 +527,44 @@ func (r *RenameTable) String() string {
 	return fmt.Sprintf("Rename table %s to %s", r.oldNames, r.newNames)
 }
 
+// RowIter implements the Node interface. The tables are renamed in order, all of them or none: the renames are checked
+// against the tables and views of the database before any is done, and the database renames the tables in a single
+// operation if it's a sql.MultiTableRenamer. The foreign keys referencing the tables renamed and their triggers are
+// updated for their new names.
 func (r *RenameTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 	renamer, ok := r.db.(sql.TableRenamer)
 	if !ok {
 		return nil, ErrRenameTableNotSupported.New(r.db.Name())
 	}
 
-	var err error
-	for i, oldName := range r.oldNames {
-		var tbl sql.Table
-		var ok bool
-		tbl, ok, err = r.db.GetTableInsensitive(ctx, oldName)
-		if err != nil {
-			return nil, err
-		}
+	renames, renamed, err := r.planRenames(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		if !ok {
-			return nil, sql.ErrTableNotFound.New(oldName)
+	var tableRenames, viewRenames []tableRename
+	for _, rename := range renames {
+		if rename.view {
+			viewRenames = append(viewRenames, rename)
+		} else {
+			tableRenames = append(tableRenames, rename)
 		}
+	}
 
-		err = renamer.RenameTable(ctx, tbl.Name(), r.newNames[i])
-		if err != nil {
-			break
-		}
+	if err = renameTables(ctx, renamer, tableRenames); err != nil {
+		return nil, err
+	}
+	if err = renameViews(ctx, r.db, viewRenames); err != nil {
+		return nil, err
+	}
+	if err = updateForeignKeyReferences(ctx, r.db, renamed); err != nil {
+		return nil, err
+	}
+	if err = updateTriggerTables(ctx, r.db, renamed); err != nil {
+		return nil, err
 	}
 
-	return sql.RowsToRowIter(), err
+	return sql.RowsToRowIter(), nil
 }
 
 func (r *RenameTable) WithChildren(children ...sql.Node) (sql.Node, error) {
@@ -558,6 +717,10 @@ func NewDropColumn(db sql.Database, tableName string, column string) *DropColumn
 	}
 }
 
+func (d *DropColumn) TableName() string {
+	return d.tableName
+}
+
 func (d *DropColumn) WithDatabase(db sql.Database) (sql.Node, error) {
 	nd := *d
 	nd.db = db
@@ -633,6 +796,10 @@ func NewRenameColumn(db sql.Database, tableName string, columnName string, newCo
 	}
 }
 
+func (r *RenameColumn) TableName() string {
+	return r.tableName
+}
+
 func (r *RenameColumn) WithDatabase(db sql.Database) (sql.Node, error) {
 	nr := *r
 	nr.db = db
@@ -670,6 +837,85 @@ func (r *RenameColumn) WithChildren(children ...sql.Node) (sql.Node, error) {
 	return NillaryWithChildren(r, children...)
 }
 
+// AlterColumnVisibility is a node for ALTER TABLE ALTER COLUMN {VISIBLE | INVISIBLE}.
+type AlterColumnVisibility struct {
+	ddlNode
+	tableName  string
+	columnName string
+	invisible  bool
+}
+
+var _ sql.Node = (*AlterColumnVisibility)(nil)
+var _ sql.Databaser = (*AlterColumnVisibility)(nil)
+
+// NewAlterColumnVisibility returns an AlterColumnVisibility node making the column given visible or invisible.
+func NewAlterColumnVisibility(db sql.Database, tableName string, columnName string, invisible bool) *AlterColumnVisibility {
+	return &AlterColumnVisibility{
+		ddlNode:    ddlNode{db},
+		tableName:  tableName,
+		columnName: columnName,
+		invisible:  invisible,
+	}
+}
+
+func (a *AlterColumnVisibility) TableName() string {
+	return a.tableName
+}
+
+func (a *AlterColumnVisibility) WithDatabase(db sql.Database) (sql.Node, error) {
+	na := *a
+	na.db = db
+	return &na, nil
+}
+
+func (a *AlterColumnVisibility) String() string {
+	visibility := "visible"
+	if a.invisible {
+		visibility = "invisible"
+	}
+	return fmt.Sprintf("alter column %s set %s", a.columnName, visibility)
+}
+
+func (a *AlterColumnVisibility) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	alterable, err := getAlterableTable(a.db, ctx, a.tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	tbl := alterable.(sql.Table)
+	tblSch := tbl.Schema()
+	idx := tblSch.IndexOf(a.columnName, tbl.Name())
+	if idx < 0 {
+		return nil, sql.ErrTableColumnNotFound.New(tbl.Name(), a.columnName)
+	}
+
+	nc := *tblSch[idx]
+	nc.Invisible = a.invisible
+	if err := validateVisibleColumns(tblSch, &nc); err != nil {
+		return nil, err
+	}
+
+	return sql.RowsToRowIter(), alterable.ModifyColumn(ctx, tblSch[idx].Name, &nc, nil)
+}
+
+func (a *AlterColumnVisibility) WithChildren(children ...sql.Node) (sql.Node, error) {
+	return NillaryWithChildren(a, children...)
+}
+
+// validateVisibleColumns returns an error if the schema given would have no visible columns once the column given
+// replaced the one of the same name.
+func validateVisibleColumns(sch sql.Schema, column *sql.Column) error {
+	if !column.Invisible {
+		return nil
+	}
+	for _, col := range sch {
+		if !col.Invisible && !strings.EqualFold(col.Name, column.Name) {
+			return nil
+		}
+	}
+	return sql.ErrTableMustHaveVisibleColumn.New()
+}
+
 type ModifyColumn struct {
 	ddlNode
 	tableName  string
@@ -742,6 +988,9 @@ func (m *ModifyColumn) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, erro
 	if err := m.validateDefaultPosition(tblSch); err != nil {
 		return nil, err
 	}
+	if err := validateVisibleColumns(tblSch, &sql.Column{Name: m.columnName, Invisible: m.column.Invisible}); err != nil {
+		return nil, err
+	}
 	if err := updateDefaultsOnColumnRename(ctx, alterable, m.columnName, m.column.Name); err != nil {
 		return nil, err
 	}
@@ -825,7 +1074,8 @@ func (m *ModifyColumn) validateDefaultPosition(tblSch sql.Schema) error {
 	return nil
 }
 
-// Gets an AlterableTable with the name given from the database, or an error if it cannot.
+// Gets an AlterableTable with the name given from the database, or an error if it cannot. The tables that can only be
+// rewritten are altered by rewriting them.
 func getAlterableTable(db sql.Database, ctx *sql.Context, tableName string) (sql.AlterableTable, error) {
 	tbl, ok, err := db.GetTableInsensitive(ctx, tableName)
 	if err != nil {
@@ -836,12 +1086,14 @@ func getAlterableTable(db sql.Database, ctx *sql.Context, tableName string) (sql
 		return nil, sql.ErrTableNotFound.New(tableName)
 	}
 
-	alterable, ok := tbl.(sql.AlterableTable)
-	if !ok {
+	switch tbl := tbl.(type) {
+	case sql.AlterableTable:
+		return tbl, nil
+	case sql.RewritableTable:
+		return &rewritingTable{tbl}, nil
+	default:
 		return nil, ErrAlterTableNotSupported.New(tableName, db.Name())
 	}
-
-	return alterable, nil
 }
 
 func inspectDefaultForInvalidColumns(col *sql.Column, columnsAfterThis map[string]*sql.Column) error {
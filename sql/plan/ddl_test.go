@@ -1,6 +1,7 @@
 package plan
 
 import (
+	"context"
 	"io"
 	"testing"
 
@@ -85,6 +86,43 @@ func TestDropTable(t *testing.T) {
 	require.False(ok)
 }
 
+func TestRenameTable(t *testing.T) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("test")
+
+	s := sql.Schema{
+		{Name: "c1", Type: sql.Text},
+	}
+
+	require.NoError(createTable(t, db, "a", s, false))
+	require.NoError(createTable(t, db, "b", s, false))
+	a, b := db.Tables()["a"], db.Tables()["b"]
+
+	viewReg := sql.NewViewRegistry()
+	require.NoError(viewReg.Register(db.Name(), sql.NewView("v", nil, "select 1")))
+	ctx := sql.NewContext(context.Background(), sql.WithViewRegistry(viewReg))
+
+	_, err := NewRenameTable(db, []string{"a", "b", "tmp"}, []string{"tmp", "a", "b"}).RowIter(ctx, nil)
+	require.NoError(err)
+	require.Equal(b, db.Tables()["a"])
+	require.Equal(a, db.Tables()["b"])
+	require.Len(db.Tables(), 2)
+
+	_, err = NewRenameTable(db, []string{"a", "b"}, []string{"c", "v"}).RowIter(ctx, nil)
+	require.True(sql.ErrTableAlreadyExists.Is(err))
+	_, err = NewRenameTable(db, []string{"a", "missing"}, []string{"c", "d"}).RowIter(ctx, nil)
+	require.True(sql.ErrTableNotFound.Is(err))
+	require.Equal(b, db.Tables()["a"])
+	require.Equal(a, db.Tables()["b"])
+
+	_, err = NewRenameTable(db, []string{"v", "a"}, []string{"w", "v"}).RowIter(ctx, nil)
+	require.NoError(err)
+	require.Equal(b, db.Tables()["v"])
+	require.True(viewReg.Exists(db.Name(), "w"))
+	require.False(viewReg.Exists(db.Name(), "v"))
+}
+
 func createTable(t *testing.T, db sql.Database, name string, schema sql.Schema, ifNotExists bool) error {
 	c := NewCreateTable(db, name, schema, ifNotExists, nil, nil)
 
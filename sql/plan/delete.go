@@ -1,6 +1,9 @@
 package plan
 
 import (
+	"fmt"
+	"strings"
+
 	"gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/go-mysql-server/sql"
@@ -8,14 +11,90 @@ import (
 
 var ErrDeleteFromNotSupported = errors.NewKind("table doesn't support DELETE FROM")
 
+// ErrUnknownDeleteTarget is returned when a table deleted from isn't one of the tables joined by a deletion.
+var ErrUnknownDeleteTarget = errors.NewKind("Unknown table '%s' in MULTI DELETE")
+
 // DeleteFrom is a node describing a deletion from some table.
 type DeleteFrom struct {
 	UnaryNode
+	// ForeignKeys applies the referential actions of the foreign keys referencing the table to the rows deleted, unless
+	// foreign_key_checks is off, or is nil if there are none.
+	ForeignKeys *ForeignKeyEditor
+	// Targets are the names or aliases of the tables of the child that rows are deleted from, which joins them with
+	// others, or nil if the child reads a single table.
+	Targets []string
+	// TargetForeignKeys are the editors of the tables of the targets, in the order of the targets, which are nil for the
+	// tables that neither declare nor are referenced by foreign keys.
+	TargetForeignKeys []*ForeignKeyEditor
+	// TargetTriggers are the DELETE triggers of the tables of the targets, in the order of the targets, as the
+	// TriggerExecutor nodes wrapping the tables, which execute them for each row deleted in the order given.
+	TargetTriggers [][]*TriggerExecutor
 }
 
 // NewDeleteFrom creates a DeleteFrom node.
 func NewDeleteFrom(n sql.Node) *DeleteFrom {
-	return &DeleteFrom{UnaryNode{n}}
+	return &DeleteFrom{UnaryNode: UnaryNode{n}}
+}
+
+// WithForeignKeys returns a copy of this node that applies the referential actions of the foreign keys of the editor
+// given to the rows deleted.
+func (p *DeleteFrom) WithForeignKeys(editor *ForeignKeyEditor) *DeleteFrom {
+	np := *p
+	np.ForeignKeys = editor
+	return &np
+}
+
+// WithTargets returns a copy of this node that deletes the rows of the tables of its child with the names or aliases
+// given, which are joined with others.
+func (p *DeleteFrom) WithTargets(targets []string) *DeleteFrom {
+	np := *p
+	np.Targets = targets
+	return &np
+}
+
+// WithTargetForeignKeys returns a copy of this node that applies the referential actions of the foreign keys of the
+// editors given, one for each target, to the rows deleted.
+func (p *DeleteFrom) WithTargetForeignKeys(editors []*ForeignKeyEditor) *DeleteFrom {
+	np := *p
+	np.TargetForeignKeys = editors
+	return &np
+}
+
+// WithTargetTriggers returns a copy of this node that executes the triggers given, one list for each target, for the
+// rows deleted.
+func (p *DeleteFrom) WithTargetTriggers(triggers [][]*TriggerExecutor) *DeleteFrom {
+	np := *p
+	np.TargetTriggers = triggers
+	return &np
+}
+
+// TargetTables returns the nodes of the tables a deletion joining several deletes the rows of, which are table aliases
+// or tables, in the order of its targets.
+func (p *DeleteFrom) TargetTables() ([]sql.Node, error) {
+	tables := make([]sql.Node, len(p.Targets))
+	for i, target := range p.Targets {
+		Inspect(p.Child, func(n sql.Node) bool {
+			switch n := n.(type) {
+			case *TableAlias:
+				if strings.EqualFold(n.Name(), target) {
+					tables[i] = n
+				}
+				return false
+			case *ResolvedTable, *UnresolvedTable:
+				if strings.EqualFold(n.(sql.Nameable).Name(), target) {
+					tables[i] = n
+				}
+				return false
+			case *SubqueryAlias:
+				return false
+			}
+			return tables[i] == nil
+		})
+		if tables[i] == nil {
+			return nil, ErrUnknownDeleteTarget.New(target)
+		}
+	}
+	return tables, nil
 }
 
 func getDeletable(node sql.Node) (sql.DeletableTable, error) {
@@ -49,6 +128,10 @@ func getDeletableTable(t sql.Table) (sql.DeletableTable, error) {
 
 // RowIter implements the Node interface.
 func (p *DeleteFrom) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	if len(p.Targets) > 0 {
+		return p.joinedRowIter(ctx, row)
+	}
+
 	deletable, err := getDeletable(p.Child)
 	if err != nil {
 		return nil, err
@@ -61,15 +144,22 @@ func (p *DeleteFrom) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error)
 
 	deleter := deletable.Deleter(ctx)
 
-	return newDeleteIter(iter, deleter, deletable.Schema(), ctx), nil
+	d := newDeleteIter(iter, deleter, deletable.Schema(), ctx)
+	if p.ForeignKeys != nil && sql.ForeignKeyChecks(ctx) {
+		d.foreignKeys = p.ForeignKeys
+		d.cascade = newForeignKeyCascade(p.ForeignKeys, deleter, nil)
+	}
+	return d, nil
 }
 
 type deleteIter struct {
-	deleter   sql.RowDeleter
-	schema    sql.Schema
-	childIter sql.RowIter
-	ctx       *sql.Context
-	closed    bool
+	deleter     sql.RowDeleter
+	schema      sql.Schema
+	childIter   sql.RowIter
+	ctx         *sql.Context
+	foreignKeys *ForeignKeyEditor
+	cascade     *foreignKeyCascade
+	closed      bool
 }
 
 func (d *deleteIter) Next() (sql.Row, error) {
@@ -85,12 +175,20 @@ func (d *deleteIter) Next() (sql.Row, error) {
 		row = row[len(row)-len(d.schema):]
 	}
 
+	if d.cascade != nil {
+		return row, d.cascade.delete(d.ctx, d.foreignKeys, row, 0)
+	}
 	return row, d.deleter.Delete(d.ctx, row)
 }
 
 func (d *deleteIter) Close() error {
 	if !d.closed {
 		d.closed = true
+		if d.cascade != nil {
+			if err := d.cascade.Close(d.ctx); err != nil {
+				return err
+			}
+		}
 		if err := d.deleter.Close(d.ctx); err != nil {
 			return err
 		}
@@ -108,19 +206,213 @@ func (p *DeleteFrom) WithChildren(children ...sql.Node) (sql.Node, error) {
 	if len(children) != 1 {
 		return nil, sql.ErrInvalidChildrenNumber.New(p, len(children), 1)
 	}
-	return NewDeleteFrom(children[0]), nil
+	np := *p
+	np.Child = children[0]
+	return &np, nil
 }
 
 func (p DeleteFrom) String() string {
 	pr := sql.NewTreePrinter()
-	_ = pr.WriteNode("Delete")
+	_ = pr.WriteNode(p.nodeName())
 	_ = pr.WriteChildren(p.Child.String())
 	return pr.String()
 }
 
 func (p DeleteFrom) DebugString() string {
 	pr := sql.NewTreePrinter()
-	_ = pr.WriteNode("Delete")
+	_ = pr.WriteNode(p.nodeName())
 	_ = pr.WriteChildren(sql.DebugString(p.Child))
 	return pr.String()
 }
+
+func (p DeleteFrom) nodeName() string {
+	if len(p.Targets) == 0 {
+		return "Delete"
+	}
+	return fmt.Sprintf("Delete(%s)", strings.Join(p.Targets, ", "))
+}
+
+// joinedRowIter returns the iterator of a deletion of the rows of some of the tables its child joins.
+func (p *DeleteFrom) joinedRowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	tables, err := p.TargetTables()
+	if err != nil {
+		return nil, err
+	}
+
+	var cascade *foreignKeyCascade
+	if len(p.TargetForeignKeys) > 0 && sql.ForeignKeyChecks(ctx) {
+		cascade = newForeignKeyCascade(nil, nil, nil)
+	}
+
+	schema := p.Child.Schema()
+	targets := make([]*deleteTarget, len(tables))
+	for i, table := range tables {
+		deletable, err := getDeletable(table)
+		if err != nil {
+			return nil, err
+		}
+
+		offset := -1
+		for j, col := range schema {
+			if strings.EqualFold(col.Source, p.Targets[i]) {
+				offset = j
+				break
+			}
+		}
+		if offset < 0 {
+			return nil, ErrUnknownDeleteTarget.New(p.Targets[i])
+		}
+
+		target := &deleteTarget{
+			deleter: deletable.Deleter(ctx),
+			start:   offset,
+			end:     offset + len(table.Schema()),
+			deleted: make(map[uint64]struct{}),
+		}
+		if cascade != nil && p.TargetForeignKeys[i] != nil {
+			target.foreignKeys = p.TargetForeignKeys[i]
+			if _, ok := cascade.deleters[target.foreignKeys]; !ok {
+				cascade.deleters[target.foreignKeys] = target.deleter
+			}
+		}
+		if len(p.TargetTriggers) > 0 {
+			target.triggers = p.TargetTriggers[i]
+		}
+		targets[i] = target
+	}
+
+	iter, err := p.Child.RowIter(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	return &joinedDeleteIter{ctx: ctx, childIter: iter, schema: schema, targets: targets, cascade: cascade}, nil
+}
+
+// deleteTarget is a table a joined deletion deletes the rows of, which are the values between the start and end of
+// the rows joined.
+type deleteTarget struct {
+	deleter     sql.RowDeleter
+	start, end  int
+	deleted     map[uint64]struct{}
+	foreignKeys *ForeignKeyEditor
+	triggers    []*TriggerExecutor
+}
+
+// delete deletes the row given from the table of the target, with the cascade given if the table has foreign keys,
+// and executes the triggers of the table before and after. It returns whether the row was deleted, as the referential
+// actions of the rows of another target can delete it first.
+func (t *deleteTarget) delete(ctx *sql.Context, cascade *foreignKeyCascade, row sql.Row) (bool, error) {
+	if err := t.executeTriggers(ctx, BeforeTrigger, row); err != nil {
+		return false, err
+	}
+
+	if t.foreignKeys != nil {
+		err := cascade.delete(ctx, t.foreignKeys, row, 0)
+		if sql.ErrDeleteRowNotFound.Is(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	} else if err := t.deleter.Delete(ctx, row); err != nil {
+		return false, err
+	}
+
+	return true, t.executeTriggers(ctx, AfterTrigger, row)
+}
+
+func (t *deleteTarget) executeTriggers(ctx *sql.Context, triggerTime TriggerTime, row sql.Row) error {
+	for _, trigger := range t.triggers {
+		if trigger.TriggerTime != triggerTime {
+			continue
+		}
+		if _, _, err := executeTriggerLogic(ctx, trigger.Right(), row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinedDeleteIter deletes the rows of the tables a join reads that the rows joined have, once each, and returns the
+// rows deleted.
+type joinedDeleteIter struct {
+	ctx       *sql.Context
+	childIter sql.RowIter
+	schema    sql.Schema
+	targets   []*deleteTarget
+	cascade   *foreignKeyCascade
+	pending   []sql.Row
+	closed    bool
+}
+
+func (d *joinedDeleteIter) Next() (sql.Row, error) {
+	for len(d.pending) == 0 {
+		row, err := d.childIter.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		// Reduce the row to the length of the schema, as some values can come from an outer scope.
+		if len(d.schema) < len(row) {
+			row = row[len(row)-len(d.schema):]
+		}
+
+		for _, target := range d.targets {
+			deleted := row[target.start:target.end]
+			if isNullRow(deleted) {
+				// The missing rows of outer joins
+				continue
+			}
+
+			hash, err := sql.HashOf(deleted)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := target.deleted[hash]; ok {
+				continue
+			}
+			target.deleted[hash] = struct{}{}
+
+			ok, err := target.delete(d.ctx, d.cascade, deleted)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				d.pending = append(d.pending, deleted)
+			}
+		}
+	}
+
+	row := d.pending[0]
+	d.pending = d.pending[1:]
+	return row, nil
+}
+
+func (d *joinedDeleteIter) Close() error {
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+
+	if d.cascade != nil {
+		if err := d.cascade.Close(d.ctx); err != nil {
+			return err
+		}
+	}
+	for _, target := range d.targets {
+		if err := target.deleter.Close(d.ctx); err != nil {
+			return err
+		}
+	}
+	return d.childIter.Close()
+}
+
+// isNullRow returns whether all the values of the row given are NULL.
+func isNullRow(row sql.Row) bool {
+	for _, v := range row {
+		if v != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -55,6 +55,7 @@ func (d *DropTrigger) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 	triggerDb, ok := d.db.(sql.TriggerDatabase)
 	if !ok {
 		if d.IfExists {
+			ctx.Note(1360, "Trigger does not exist")
 			return sql.RowsToRowIter(), nil
 		} else {
 			return nil, sql.ErrTriggerDoesNotExist.New(d.TriggerName)
@@ -62,6 +63,7 @@ func (d *DropTrigger) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 	}
 	err := triggerDb.DropTrigger(ctx, d.TriggerName)
 	if d.IfExists && sql.ErrTriggerDoesNotExist.Is(err) {
+		ctx.Note(1360, "Trigger does not exist")
 		return sql.RowsToRowIter(), nil
 	} else if err != nil {
 		return nil, err
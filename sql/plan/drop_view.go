@@ -60,6 +60,11 @@ func (dv *SingleDropView) WithChildren(children ...sql.Node) (sql.Node, error) {
 	return dv, nil
 }
 
+// ViewName returns the name of the view dropped.
+func (dv *SingleDropView) ViewName() string {
+	return dv.viewName
+}
+
 // Database implements the Databaser interfacee. It returns the node's database.
 func (dv *SingleDropView) Database() sql.Database {
 	return dv.database
@@ -127,6 +132,9 @@ func (dvs *DropView) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error)
 		}
 
 		viewList[i] = sql.NewViewKey(drop.database.Name(), drop.viewName)
+		if dvs.ifExists && !ctx.ViewRegistry.Exists(drop.database.Name(), drop.viewName) {
+			ctx.Note(1051, "Unknown table '%s.%s'", drop.database.Name(), drop.viewName)
+		}
 	}
 
 	return sql.RowsToRowIter(), ctx.ViewRegistry.DeleteList(viewList, !dvs.ifExists)
@@ -0,0 +1,403 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// DumpInsertSize is the size in bytes past which the INSERT statements of a dump stop adding rows, like the
+// net_buffer_length of mysqldump.
+const DumpInsertSize = 1 << 20
+
+// dumpSkippedDatabases are the databases a dump of all the databases leaves out, which the server provides itself.
+var dumpSkippedDatabases = map[string]bool{
+	"information_schema": true,
+	"performance_schema": true,
+	"sys":                true,
+}
+
+// DumpTarget is a database to dump, with the tables of it to dump.
+type DumpTarget struct {
+	// Database is the name of the database, or empty for the current database.
+	Database string
+	// Tables are the names of the tables to dump. If there are none, the whole database is dumped: the statement
+	// creating it, and all its tables, views and triggers.
+	Tables []string
+}
+
+// Dump is a node that returns the statements recreating databases and tables with the rows they have, like the
+// output of mysqldump. The tables that implement sql.Lockable are locked for read while the dump runs, so that the
+// rows of all of them are from the same moment. Tables with row filters from the row policies of the catalog can't be
+// dumped, since their statements would recreate them with the rows visible to the session only.
+type Dump struct {
+	// Targets are the databases and tables to dump, or nil to dump all the databases.
+	Targets []DumpTarget
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*Dump)(nil)
+
+// NewDump returns a new Dump node of the targets given, or of all the databases if there are none.
+func NewDump(targets []DumpTarget) *Dump {
+	return &Dump{Targets: targets}
+}
+
+// Resolved implements the sql.Node interface.
+func (d *Dump) Resolved() bool {
+	return true
+}
+
+// WithChildren implements the sql.Node interface.
+func (d *Dump) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(d, len(children), 0)
+	}
+	return d, nil
+}
+
+// Children implements the sql.Node interface.
+func (d *Dump) Children() []sql.Node { return nil }
+
+// String implements the fmt.Stringer interface.
+func (d *Dump) String() string {
+	if len(d.Targets) == 0 {
+		return "DUMP ALL DATABASES"
+	}
+
+	var databases, tables []string
+	for _, t := range d.Targets {
+		if len(t.Tables) == 0 {
+			databases = append(databases, t.Database)
+			continue
+		}
+		for _, name := range t.Tables {
+			if t.Database != "" {
+				name = t.Database + "." + name
+			}
+			tables = append(tables, name)
+		}
+	}
+
+	if len(tables) == 0 {
+		return fmt.Sprintf("DUMP DATABASES %s", strings.Join(databases, ", "))
+	}
+	return fmt.Sprintf("DUMP TABLES %s", strings.Join(tables, ", "))
+}
+
+// Schema implements the sql.Node interface.
+func (d *Dump) Schema() sql.Schema {
+	return sql.Schema{
+		&sql.Column{Name: "Statement", Type: sql.LongText},
+	}
+}
+
+// RowIter implements the sql.Node interface.
+func (d *Dump) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	span, ctx := ctx.Span("plan.Dump")
+	defer span.Finish()
+
+	targets := d.Targets
+	if len(targets) == 0 {
+		for _, db := range d.Catalog.AllDatabases() {
+			if !dumpSkippedDatabases[strings.ToLower(db.Name())] {
+				targets = append(targets, DumpTarget{Database: db.Name()})
+			}
+		}
+	}
+
+	iter := &dumpIter{ctx: ctx, policies: d.Catalog.RowPolicies()}
+	for _, t := range targets {
+		name := t.Database
+		if name == "" {
+			name = ctx.GetCurrentDatabase()
+		}
+
+		db, err := d.Catalog.Database(name)
+		if err != nil {
+			iter.Close()
+			return nil, err
+		}
+
+		if err := iter.addDatabase(db, t.Tables); err != nil {
+			iter.Close()
+			return nil, err
+		}
+	}
+
+	return iter, nil
+}
+
+// dumpIter returns the statements of a dump, reading the rows of the tables dumped as the INSERT statements are
+// needed.
+type dumpIter struct {
+	ctx      *sql.Context
+	policies []sql.RowPolicy
+	// steps add the statements to return to pending, or start reading rows, in order.
+	steps   []func() error
+	pending []string
+	// table is the table whose rows are read, if rows isn't nil, and columns the list of its columns the INSERT
+	// statements name, so that the values of invisible columns are inserted as well.
+	table   sql.Table
+	columns string
+	rows    sql.RowIter
+	row     sql.Row
+	// locked are the tables locked for the dump.
+	locked []sql.Lockable
+}
+
+// addDatabase adds the steps dumping the tables given of a database, or all of it if there are none, and locks its
+// tables dumped.
+func (i *dumpIter) addDatabase(db sql.Database, names []string) error {
+	whole := len(names) == 0
+	if whole {
+		var err error
+		names, err = db.GetTableNames(i.ctx)
+		if err != nil {
+			return err
+		}
+		sort.Strings(names)
+	}
+
+	tables := make([]sql.Table, len(names))
+	for j, name := range names {
+		t, ok, err := db.GetTableInsensitive(i.ctx, name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return sql.ErrTableNotFound.New(name)
+		}
+		tables[j] = t
+
+		for _, p := range i.policies {
+			filter, err := p.RowFilter(i.ctx, db.Name(), t.Name())
+			if err != nil {
+				return err
+			}
+			if filter != nil {
+				return sql.ErrRowPolicyNotSupported.New("DUMP", t.Name())
+			}
+		}
+
+		if l, err := getLockableTable(t); err == nil {
+			if err := l.Lock(i.ctx, false); err != nil {
+				return err
+			}
+			i.locked = append(i.locked, l)
+		}
+	}
+
+	i.steps = append(i.steps, func() error {
+		if whole {
+			create := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", db.Name())
+			if collation := sql.GetDatabaseCollation(db); collation != sql.Collation_Default {
+				create += fmt.Sprintf(" DEFAULT CHARACTER SET %s COLLATE %s", collation.CharacterSet(), collation)
+			}
+			i.pending = append(i.pending, create)
+		}
+		i.pending = append(i.pending, fmt.Sprintf("USE `%s`", db.Name()))
+		return nil
+	})
+
+	for _, t := range tables {
+		t := t
+		i.steps = append(i.steps, func() error {
+			return i.startTable(t)
+		})
+	}
+
+	if whole {
+		i.steps = append(i.steps, func() error {
+			return i.addViewsAndTriggers(db)
+		})
+	}
+
+	return nil
+}
+
+// startTable adds the statements recreating a table and its indexes, and starts reading its rows.
+func (i *dumpIter) startTable(t sql.Table) error {
+	var indexes []sql.Index
+	if it, ok := t.(sql.IndexedTable); ok {
+		var err error
+		indexes, err = it.GetIndexes(i.ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	create, err := produceCreateTableStatement(i.ctx, t, indexes)
+	if err != nil {
+		return err
+	}
+
+	rows, err := NewResolvedTable(t).RowIter(i.ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	i.pending = append(i.pending, fmt.Sprintf("DROP TABLE IF EXISTS `%s`", t.Name()), create)
+	i.pending = append(i.pending, produceCreateIndexStatements(t, indexes)...)
+	names := make([]string, len(t.Schema()))
+	for j, col := range t.Schema() {
+		names[j] = col.Name
+	}
+
+	i.table, i.columns, i.rows = t, strings.Join(quoteIdentifiers(names), ","), rows
+	return nil
+}
+
+// addViewsAndTriggers adds the statements creating the views and triggers of a database.
+func (i *dumpIter) addViewsAndTriggers(db sql.Database) error {
+	if i.ctx.ViewRegistry != nil {
+		views := i.ctx.ViewsInDatabase(strings.ToLower(db.Name()))
+		sort.Slice(views, func(a, b int) bool {
+			return views[a].Name() < views[b].Name()
+		})
+		for _, v := range views {
+			i.pending = append(i.pending,
+				fmt.Sprintf("DROP VIEW IF EXISTS `%s`", v.Name()),
+				fmt.Sprintf("CREATE VIEW `%s` AS %s", v.Name(), v.TextDefinition()))
+		}
+	}
+
+	if tdb, ok := db.(sql.TriggerDatabase); ok {
+		triggers, err := tdb.GetTriggers(i.ctx)
+		if err != nil {
+			return err
+		}
+		for _, t := range triggers {
+			i.pending = append(i.pending, t.CreateStatement)
+		}
+	}
+
+	return nil
+}
+
+// insert returns the next INSERT statement of the rows of the table read, or an empty string once it has no more
+// rows.
+func (i *dumpIter) insert() (string, error) {
+	var b strings.Builder
+	for b.Len() < DumpInsertSize {
+		row := i.row
+		i.row = nil
+		if row == nil {
+			var err error
+			row, err = i.rows.Next()
+			if err == io.EOF {
+				err = i.rows.Close()
+				i.table, i.rows = nil, nil
+				if err != nil {
+					return "", err
+				}
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+		}
+
+		values, err := dumpValues(i.table.Schema(), row)
+		if err != nil {
+			return "", err
+		}
+
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "INSERT INTO `%s` (%s) VALUES %s", i.table.Name(), i.columns, values)
+		} else if b.Len()+len(values)+1 > DumpInsertSize {
+			i.row = row
+			break
+		} else {
+			b.WriteByte(',')
+			b.WriteString(values)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// dumpValues returns the row given as the values of an INSERT statement.
+func dumpValues(schema sql.Schema, row sql.Row) (string, error) {
+	var b strings.Builder
+	b.WriteByte('(')
+	for j, v := range row {
+		if j > 0 {
+			b.WriteByte(',')
+		}
+
+		if v == nil {
+			b.WriteString("NULL")
+			continue
+		}
+
+		value, err := schema[j].Type.SQL(v)
+		if err != nil {
+			return "", err
+		}
+
+		// BIT values are the text of their number, which EncodeSQL would write as the bits of the text.
+		if value.Type() == sqltypes.Bit {
+			b.Write(value.Raw())
+			continue
+		}
+		value.EncodeSQL(&b)
+	}
+	b.WriteByte(')')
+	return b.String(), nil
+}
+
+// Next implements the sql.RowIter interface.
+func (i *dumpIter) Next() (sql.Row, error) {
+	for {
+		if len(i.pending) > 0 {
+			stmt := i.pending[0]
+			i.pending = i.pending[1:]
+			return sql.NewRow(stmt), nil
+		}
+
+		if i.rows != nil {
+			stmt, err := i.insert()
+			if err != nil {
+				return nil, err
+			}
+			if stmt != "" {
+				return sql.NewRow(stmt), nil
+			}
+			continue
+		}
+
+		if len(i.steps) == 0 {
+			return nil, io.EOF
+		}
+
+		step := i.steps[0]
+		i.steps = i.steps[1:]
+		if err := step(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Close implements the sql.RowIter interface.
+func (i *dumpIter) Close() error {
+	var err error
+	if i.rows != nil {
+		err = i.rows.Close()
+		i.rows = nil
+	}
+
+	for _, l := range i.locked {
+		if e := l.Unlock(i.ctx, i.ctx.ID()); e != nil && err == nil {
+			err = e
+		}
+	}
+	i.locked = nil
+
+	return err
+}
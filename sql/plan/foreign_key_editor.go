@@ -0,0 +1,543 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// MaxForeignKeyCascadeDepth is the number of times the referential actions of foreign keys can cascade from the rows
+// deleted or updated by a statement, as in MySQL.
+const MaxForeignKeyCascadeDepth = 15
+
+// ForeignKeyEditor checks the foreign keys declared by a table and applies the referential actions of the foreign keys
+// referencing it to the rows of the tables that declare them, as the rows of the table are inserted, deleted or
+// updated by InsertInto, DeleteFrom and Update nodes. The editors of tables that reference each other, or themselves,
+// form cycles.
+type ForeignKeyEditor struct {
+	// Table is the table whose rows are inserted, deleted or updated.
+	Table sql.Table
+	// References are the foreign keys referencing the table.
+	References []*ForeignKeyReference
+	// ForeignKeys are the foreign keys declared by the table.
+	ForeignKeys []*ForeignKeyReference
+}
+
+// ForeignKeyReference is a foreign key declared by the table of a ForeignKeyEditor, referencing the table of another.
+type ForeignKeyReference struct {
+	ForeignKey sql.ForeignKeyConstraint
+	// Parent is the editor of the table that the foreign key references, or nil if it doesn't exist, as it can while
+	// foreign keys aren't checked.
+	Parent *ForeignKeyEditor
+	// Child is the editor of the table that declares the foreign key.
+	Child *ForeignKeyEditor
+	// ParentIndex is an index of the parent table whose first expressions are the referenced columns, which is used to
+	// look up the row referenced by a row, or nil to scan the parent table for it.
+	ParentIndex sql.Index
+	// ChildIndex is an index of the child table whose first expressions are the columns of the foreign key, which is
+	// used to look up the rows referencing a row, or nil to scan the child table for them.
+	ChildIndex sql.Index
+
+	parentColumns []int
+	childColumns  []int
+}
+
+// NewForeignKeyReference returns the ForeignKeyReference for the foreign key given, declared by the table of the child
+// editor given, referencing the table of the parent editor given, and adds it to both editors.
+func NewForeignKeyReference(fk sql.ForeignKeyConstraint, parent, child *ForeignKeyEditor, parentIndex, childIndex sql.Index) (*ForeignKeyReference, error) {
+	ref := &ForeignKeyReference{
+		ForeignKey:   fk,
+		Parent:       parent,
+		Child:        child,
+		ParentIndex:  parentIndex,
+		ChildIndex:   childIndex,
+		childColumns: make([]int, len(fk.Columns)),
+	}
+
+	childSchema := child.Table.Schema()
+	for i, col := range fk.Columns {
+		ref.childColumns[i] = childSchema.IndexOf(col, child.Table.Name())
+		if ref.childColumns[i] < 0 {
+			return nil, sql.ErrTableColumnNotFound.New(col)
+		}
+	}
+
+	if parent != nil {
+		parentSchema := parent.Table.Schema()
+		ref.parentColumns = make([]int, len(fk.ReferencedColumns))
+		for i, col := range fk.ReferencedColumns {
+			ref.parentColumns[i] = parentSchema.IndexOf(col, parent.Table.Name())
+			if ref.parentColumns[i] < 0 {
+				return nil, sql.ErrTableColumnNotFound.New(col)
+			}
+		}
+
+		if len(ref.parentColumns) != len(ref.childColumns) {
+			return nil, fmt.Errorf("foreign key %s has %d columns referencing %d columns",
+				fk.Name, len(ref.childColumns), len(ref.parentColumns))
+		}
+		parent.References = append(parent.References, ref)
+	}
+
+	child.ForeignKeys = append(child.ForeignKeys, ref)
+	return ref, nil
+}
+
+// rowKey returns the values of the columns given of the row given, converted to the types of the columns given of the
+// other schema given, or nil if any of them is NULL, as NULL values never reference or are referenced by any row.
+func rowKey(row sql.Row, columns []int, schema sql.Schema, schemaColumns []int) ([]interface{}, error) {
+	key := make([]interface{}, len(columns))
+	for i, col := range columns {
+		if row[col] == nil {
+			return nil, nil
+		}
+
+		var err error
+		key[i], err = schema[schemaColumns[i]].Type.Convert(row[col])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// keyChanged returns whether the update of the row given, of the table with the schema given, changes the values of
+// the columns given.
+func keyChanged(schema sql.Schema, columns []int, oldRow, newRow sql.Row) (bool, error) {
+	for _, col := range columns {
+		cmp, err := schema[col].Type.Compare(oldRow[col], newRow[col])
+		if err != nil {
+			return false, err
+		}
+		if cmp != 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasKey returns whether the columns given of the row given, of the table with the schema given, have the values of
+// the key given.
+func hasKey(schema sql.Schema, columns []int, row sql.Row, key []interface{}) (bool, error) {
+	for i, col := range columns {
+		if row[col] == nil {
+			return false, nil
+		}
+
+		cmp, err := schema[col].Type.Compare(row[col], key[i])
+		if err != nil {
+			return false, err
+		}
+		if cmp != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// rowsWithKey returns the rows of the table given whose columns given have the values of the key given, looking them
+// up with the index given if it's not nil.
+func rowsWithKey(ctx *sql.Context, table sql.Table, index sql.Index, columns []int, key []interface{}) (rows []sql.Row, returnErr error) {
+	if index != nil {
+		lookup, err := keyLookup(index, key)
+		if err != nil {
+			return nil, err
+		}
+		if lookup != nil {
+			table = table.(sql.IndexAddressableTable).WithIndexLookup(lookup)
+		}
+	}
+
+	partitions, err := table.Partitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := sql.NewTableRowIter(ctx, table, partitions)
+	defer func() {
+		if err := iter.Close(); returnErr == nil {
+			returnErr = err
+		}
+	}()
+
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Index lookups can return rows that don't have the key, as prefix indexes do, so every row is checked
+		if ok, err := hasKey(table.Schema(), columns, row, key); err != nil {
+			return nil, err
+		} else if ok {
+			rows = append(rows, row)
+		}
+	}
+}
+
+// keyLookup returns the lookup of the index given for the rows whose first expressions of the index have the values of
+// the key given, or nil if the index can't look them up.
+func keyLookup(index sql.Index, key []interface{}) (sql.IndexLookup, error) {
+	n := len(index.Expressions())
+	if n == len(key) {
+		return index.Get(key...)
+	}
+
+	rangeIndex, ok := index.(sql.RangeIndex)
+	if !ok {
+		return nil, nil
+	}
+
+	ranges := make([]sql.IndexRange, n)
+	for i, v := range key {
+		bound := &sql.IndexBound{Value: v, Inclusive: true}
+		ranges[i] = sql.IndexRange{Lower: bound, Upper: bound}
+	}
+	return rangeIndex.Range(ranges...)
+}
+
+// referencingRows returns the rows of the child table that reference the parent row given.
+func (r *ForeignKeyReference) referencingRows(ctx *sql.Context, parentRow sql.Row) ([]sql.Row, error) {
+	key, err := rowKey(parentRow, r.parentColumns, r.Child.Table.Schema(), r.childColumns)
+	if err != nil || key == nil {
+		return nil, err
+	}
+	return rowsWithKey(ctx, r.Child.Table, r.ChildIndex, r.childColumns, key)
+}
+
+// checkReferenced returns an error if the child row given references a row that the parent table doesn't have. The
+// rows referencing themselves, through a foreign key of a table referencing itself, are referenced.
+func (r *ForeignKeyReference) checkReferenced(ctx *sql.Context, childRow sql.Row) error {
+	if r.Parent == nil {
+		for _, col := range r.childColumns {
+			if childRow[col] == nil {
+				return nil
+			}
+		}
+		return r.childError()
+	}
+
+	parentSchema := r.Parent.Table.Schema()
+	key, err := rowKey(childRow, r.childColumns, parentSchema, r.parentColumns)
+	if err != nil || key == nil {
+		return err
+	}
+
+	if r.Parent == r.Child {
+		if ok, err := hasKey(parentSchema, r.parentColumns, childRow, key); err != nil || ok {
+			return err
+		}
+	}
+
+	rows, err := rowsWithKey(ctx, r.Parent.Table, r.ParentIndex, r.parentColumns, key)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return r.childError()
+	}
+	return nil
+}
+
+// checkForeignKeys returns an error if the row given references a row that the table referenced by one of the foreign
+// keys declared by the table doesn't have. Only the foreign keys whose columns the update of the old row given changes
+// are checked, so that the rows that reference no row, as they can once they were inserted while foreign keys weren't
+// checked, can still be updated. All of them are checked if the old row is nil.
+func (e *ForeignKeyEditor) checkForeignKeys(ctx *sql.Context, oldRow, row sql.Row) error {
+	for _, ref := range e.ForeignKeys {
+		if oldRow != nil {
+			changed, err := keyChanged(e.Table.Schema(), ref.childColumns, oldRow, row)
+			if err != nil {
+				return err
+			}
+			if !changed {
+				continue
+			}
+		}
+
+		if err := ref.checkReferenced(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cascaded returns the child row given with the columns of the foreign key set to the values of the referenced
+// columns of the updated parent row given.
+func (r *ForeignKeyReference) cascaded(row, parentRow sql.Row) (sql.Row, error) {
+	childSchema := r.Child.Table.Schema()
+	newRow := row.Copy()
+	for i, col := range r.childColumns {
+		v := parentRow[r.parentColumns[i]]
+		if v != nil {
+			var err error
+			if v, err = childSchema[col].Type.Convert(v); err != nil {
+				return nil, err
+			}
+		}
+		newRow[col] = v
+	}
+	return newRow, nil
+}
+
+// nullified returns the child row given with the columns of the foreign key set to NULL.
+func (r *ForeignKeyReference) nullified(row sql.Row) (sql.Row, error) {
+	childSchema := r.Child.Table.Schema()
+	newRow := row.Copy()
+	for _, col := range r.childColumns {
+		if !childSchema[col].Nullable {
+			return nil, ErrInsertIntoNonNullableProvidedNull.New(childSchema[col].Name)
+		}
+		newRow[col] = nil
+	}
+	return newRow, nil
+}
+
+// restrictError returns the error for a change of a parent row that the reference restricts.
+func (r *ForeignKeyReference) restrictError() error {
+	return sql.ErrForeignKeyParentViolation.New(r.Child.Table.Name(), r.ForeignKey.Name,
+		quoteColumns(r.ForeignKey.Columns), r.ForeignKey.ReferencedTable, quoteColumns(r.ForeignKey.ReferencedColumns))
+}
+
+// childError returns the error for a child row referencing a row that the parent table doesn't have.
+func (r *ForeignKeyReference) childError() error {
+	return sql.ErrForeignKeyChildViolation.New(r.Child.Table.Name(), r.ForeignKey.Name,
+		quoteColumns(r.ForeignKey.Columns), r.ForeignKey.ReferencedTable, quoteColumns(r.ForeignKey.ReferencedColumns))
+}
+
+func quoteColumns(cols []string) string {
+	quoted := make([]string, len(cols))
+	for i, col := range cols {
+		quoted[i] = "`" + col + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// foreignKeyAction returns the action for the referential action given, which restricts the change for every action
+// other than CASCADE and SET NULL, as in MySQL, where SET DEFAULT is rejected and NO ACTION is RESTRICT.
+func foreignKeyAction(action sql.ForeignKeyReferenceOption) sql.ForeignKeyReferenceOption {
+	switch action {
+	case sql.ForeignKeyReferenceOption_Cascade, sql.ForeignKeyReferenceOption_SetNull:
+		return action
+	default:
+		return sql.ForeignKeyReferenceOption_Restrict
+	}
+}
+
+// foreignKeyCascade deletes and updates the rows of a statement, and applies the referential actions of the foreign
+// keys referencing them, with the row editors of each table it changes, which it opens as needed.
+type foreignKeyCascade struct {
+	deleters map[*ForeignKeyEditor]sql.RowDeleter
+	updaters map[*ForeignKeyEditor]sql.RowUpdater
+	opened   []sql.Closer
+}
+
+// newForeignKeyCascade returns a foreignKeyCascade that changes the table of the editor given with the row deleter or
+// updater of the statement, which it doesn't close.
+func newForeignKeyCascade(editor *ForeignKeyEditor, deleter sql.RowDeleter, updater sql.RowUpdater) *foreignKeyCascade {
+	c := &foreignKeyCascade{
+		deleters: make(map[*ForeignKeyEditor]sql.RowDeleter),
+		updaters: make(map[*ForeignKeyEditor]sql.RowUpdater),
+	}
+	if deleter != nil {
+		c.deleters[editor] = deleter
+	}
+	if updater != nil {
+		c.updaters[editor] = updater
+	}
+	return c
+}
+
+func (c *foreignKeyCascade) deleter(ctx *sql.Context, editor *ForeignKeyEditor) (sql.RowDeleter, error) {
+	if deleter, ok := c.deleters[editor]; ok {
+		return deleter, nil
+	}
+
+	deletable, err := getDeletableTable(editor.Table)
+	if err != nil {
+		return nil, err
+	}
+	deleter := deletable.Deleter(ctx)
+	c.deleters[editor] = deleter
+	c.opened = append(c.opened, deleter)
+	return deleter, nil
+}
+
+func (c *foreignKeyCascade) updater(ctx *sql.Context, editor *ForeignKeyEditor) (sql.RowUpdater, error) {
+	if updater, ok := c.updaters[editor]; ok {
+		return updater, nil
+	}
+
+	updatable, err := getUpdatableTable(editor.Table)
+	if err != nil {
+		return nil, err
+	}
+	updater := updatable.Updater(ctx)
+	c.updaters[editor] = updater
+	c.opened = append(c.opened, updater)
+	return updater, nil
+}
+
+// delete deletes the row given from the table of the editor given, after checking that no foreign key restricts it,
+// and then deletes the rows referencing it, or sets their columns of the foreign key to NULL, as the foreign keys
+// declare. The rows referencing themselves don't restrict their deletion.
+func (c *foreignKeyCascade) delete(ctx *sql.Context, editor *ForeignKeyEditor, row sql.Row, depth int) error {
+	if depth > MaxForeignKeyCascadeDepth {
+		return sql.ErrForeignKeyDepthLimit.New(MaxForeignKeyCascadeDepth)
+	}
+
+	for _, ref := range editor.References {
+		if foreignKeyAction(ref.ForeignKey.OnDelete) != sql.ForeignKeyReferenceOption_Restrict {
+			continue
+		}
+
+		rows, err := ref.referencingRows(ctx, row)
+		if err != nil {
+			return err
+		}
+		for _, child := range rows {
+			if ref.Child == editor {
+				if equals, err := child.Equals(row, editor.Table.Schema()); err != nil {
+					return err
+				} else if equals {
+					continue
+				}
+			}
+			return ref.restrictError()
+		}
+	}
+
+	deleter, err := c.deleter(ctx, editor)
+	if err != nil {
+		return err
+	}
+	if err := deleter.Delete(ctx, row); err != nil {
+		return err
+	}
+
+	for _, ref := range editor.References {
+		action := foreignKeyAction(ref.ForeignKey.OnDelete)
+		if action == sql.ForeignKeyReferenceOption_Restrict {
+			continue
+		}
+
+		rows, err := ref.referencingRows(ctx, row)
+		if err != nil {
+			return err
+		}
+		for _, child := range rows {
+			if action == sql.ForeignKeyReferenceOption_Cascade {
+				// The row can be gone already, if a previous row cascaded to it through another foreign key
+				if err := c.delete(ctx, ref.Child, child, depth+1); err != nil && !sql.ErrDeleteRowNotFound.Is(err) {
+					return err
+				}
+				continue
+			}
+
+			newRow, err := ref.nullified(child)
+			if err != nil {
+				return err
+			}
+			if err := c.update(ctx, ref.Child, child, newRow, depth+1, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// update replaces the row given of the table of the editor given with the new one, after checking that no foreign key
+// restricts it, and then updates the rows referencing it, or sets their columns of the foreign key to NULL, as the
+// foreign keys declare. The editors of the tables that the cascade updated before are given, as, like in MySQL, the
+// foreign keys whose ON UPDATE action would update one of them again restrict the update instead.
+func (c *foreignKeyCascade) update(ctx *sql.Context, editor *ForeignKeyEditor, oldRow, newRow sql.Row, depth int, updated []*ForeignKeyEditor) error {
+	if depth > MaxForeignKeyCascadeDepth {
+		return sql.ErrForeignKeyDepthLimit.New(MaxForeignKeyCascadeDepth)
+	}
+
+	if err := editor.checkForeignKeys(ctx, oldRow, newRow); err != nil {
+		return err
+	}
+
+	updated = append(updated[:len(updated):len(updated)], editor)
+	actions := make([]sql.ForeignKeyReferenceOption, len(editor.References))
+	for i, ref := range editor.References {
+		changed, err := keyChanged(editor.Table.Schema(), ref.parentColumns, oldRow, newRow)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+
+		actions[i] = foreignKeyAction(ref.ForeignKey.OnUpdate)
+		for _, e := range updated {
+			if e == ref.Child {
+				actions[i] = sql.ForeignKeyReferenceOption_Restrict
+			}
+		}
+
+		if actions[i] == sql.ForeignKeyReferenceOption_Restrict {
+			rows, err := ref.referencingRows(ctx, oldRow)
+			if err != nil {
+				return err
+			}
+			if len(rows) > 0 {
+				return ref.restrictError()
+			}
+		}
+	}
+
+	updater, err := c.updater(ctx, editor)
+	if err != nil {
+		return err
+	}
+	if err := updater.Update(ctx, oldRow, newRow); err != nil {
+		return err
+	}
+
+	for i, ref := range editor.References {
+		if actions[i] != sql.ForeignKeyReferenceOption_Cascade && actions[i] != sql.ForeignKeyReferenceOption_SetNull {
+			continue
+		}
+
+		rows, err := ref.referencingRows(ctx, oldRow)
+		if err != nil {
+			return err
+		}
+		for _, child := range rows {
+			var newChild sql.Row
+			if actions[i] == sql.ForeignKeyReferenceOption_Cascade {
+				newChild, err = ref.cascaded(child, newRow)
+			} else {
+				newChild, err = ref.nullified(child)
+			}
+			if err != nil {
+				return err
+			}
+			if err := c.update(ctx, ref.Child, child, newChild, depth+1, updated); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Close closes the row editors that the cascade opened.
+func (c *foreignKeyCascade) Close(ctx *sql.Context) error {
+	var firstErr error
+	for _, closer := range c.opened {
+		if err := closer.Close(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	c.opened = nil
+	return firstErr
+}
@@ -0,0 +1,142 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cast"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// DiagnosticsItem is an assignment of GET DIAGNOSTICS of an item of information to a user variable.
+type DiagnosticsItem struct {
+	// Target is the name of the user variable.
+	Target string
+	// Name is the name of the item, in uppercase, like MESSAGE_TEXT.
+	Name string
+}
+
+// StatementDiagnosticsItems are the items of information about the last statement.
+var StatementDiagnosticsItems = []string{"NUMBER"}
+
+// ConditionDiagnosticsItems are the items of information about a condition of the last statement.
+var ConditionDiagnosticsItems = []string{
+	"CLASS_ORIGIN", "SUBCLASS_ORIGIN", "RETURNED_SQLSTATE", "MESSAGE_TEXT", "MYSQL_ERRNO", "CONSTRAINT_CATALOG",
+	"CONSTRAINT_SCHEMA", "CONSTRAINT_NAME", "CATALOG_NAME", "SCHEMA_NAME", "TABLE_NAME", "COLUMN_NAME", "CURSOR_NAME",
+}
+
+// GetDiagnostics is the GET DIAGNOSTICS statement, which sets user variables to information about the last statement
+// or about one of its conditions, which are its warnings, numbered like the rows of SHOW WARNINGS from 1.
+type GetDiagnostics struct {
+	// Condition is the number of the condition to get information about, or nil to get information about the
+	// statement.
+	Condition sql.Expression
+	Items     []DiagnosticsItem
+	warnings  []*sql.Warning
+}
+
+var _ sql.Node = (*GetDiagnostics)(nil)
+
+// NewGetDiagnostics creates a GetDiagnostics node getting information about the warnings given.
+func NewGetDiagnostics(warnings []*sql.Warning, condition sql.Expression, items []DiagnosticsItem) *GetDiagnostics {
+	return &GetDiagnostics{Condition: condition, Items: items, warnings: warnings}
+}
+
+// Resolved implements the sql.Node interface.
+func (g *GetDiagnostics) Resolved() bool {
+	return g.Condition == nil || g.Condition.Resolved()
+}
+
+// Children implements the sql.Node interface.
+func (*GetDiagnostics) Children() []sql.Node { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (g *GetDiagnostics) WithChildren(children ...sql.Node) (sql.Node, error) {
+	return NillaryWithChildren(g, children...)
+}
+
+// Schema implements the sql.Node interface.
+func (*GetDiagnostics) Schema() sql.Schema { return nil }
+
+// String implements the fmt.Stringer interface.
+func (g *GetDiagnostics) String() string {
+	items := make([]string, len(g.Items))
+	for i, item := range g.Items {
+		items[i] = fmt.Sprintf("@%s = %s", item.Target, item.Name)
+	}
+	if g.Condition != nil {
+		return fmt.Sprintf("GET DIAGNOSTICS CONDITION %s %s", g.Condition, strings.Join(items, ", "))
+	}
+	return fmt.Sprintf("GET DIAGNOSTICS %s", strings.Join(items, ", "))
+}
+
+// RowIter implements the sql.Node interface.
+func (g *GetDiagnostics) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	var warning *sql.Warning
+	if g.Condition != nil {
+		n, err := g.Condition.Eval(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		i, err := cast.ToIntE(n)
+		if err != nil || i < 1 || i > len(g.warnings) {
+			return nil, sql.ErrInvalidConditionNumber.New()
+		}
+		warning = g.warnings[i-1]
+	}
+
+	for _, item := range g.Items {
+		var typ sql.Type = sql.LongText
+		var value interface{}
+		if warning == nil {
+			typ, value = sql.Int64, int64(len(g.warnings))
+		} else {
+			typ, value = conditionItem(warning, item.Name)
+		}
+		if _, err := expression.NewUserVar(item.Target).Set(ctx, typ, value); err != nil {
+			return nil, err
+		}
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// conditionItem returns the type and the value of the item of information with the name given about the condition of
+// the warning given. The items about constraints, tables and cursors are always empty.
+func conditionItem(warning *sql.Warning, name string) (sql.Type, interface{}) {
+	state := warning.State()
+	switch name {
+	case "CLASS_ORIGIN":
+		return sql.LongText, sqlStateOrigin(isStandardSQLStateClass(state))
+	case "SUBCLASS_ORIGIN":
+		return sql.LongText, sqlStateOrigin(isStandardSQLStateClass(state) && strings.HasSuffix(state, "000"))
+	case "RETURNED_SQLSTATE":
+		return sql.LongText, state
+	case "MESSAGE_TEXT":
+		return sql.LongText, warning.Message
+	case "MYSQL_ERRNO":
+		return sql.Int64, int64(warning.Code)
+	default:
+		return sql.LongText, ""
+	}
+}
+
+// isStandardSQLStateClass returns whether the class of the SQLSTATE given, its first two characters, is defined by the
+// SQL standard rather than by the implementation.
+func isStandardSQLStateClass(state string) bool {
+	if len(state) < 2 || state[:2] == "HY" {
+		return false
+	}
+	c := state[0]
+	return c >= '0' && c <= '4' || c >= 'A' && c <= 'H'
+}
+
+// sqlStateOrigin returns the CLASS_ORIGIN or SUBCLASS_ORIGIN of a SQLSTATE, given whether it's defined by the SQL
+// standard.
+func sqlStateOrigin(standard bool) string {
+	if standard {
+		return "ISO 9075"
+	}
+	return "MySQL"
+}
@@ -0,0 +1,287 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Grant grants privileges at a level to one or more accounts.
+type Grant struct {
+	Privileges sql.Privilege
+	// Columns are the privileges granted on columns of the table of the
+	// level.
+	Columns         []sql.ColumnGrant
+	Level           sql.PrivilegeLevel
+	Accounts        []sql.Account
+	WithGrantOption bool
+	Catalog         *sql.Catalog
+}
+
+var _ sql.Node = (*Grant)(nil)
+
+// NewGrant creates a new Grant node.
+func NewGrant(privileges sql.Privilege, columns []sql.ColumnGrant, level sql.PrivilegeLevel, accounts []sql.Account, withGrantOption bool) *Grant {
+	return &Grant{
+		Privileges:      privileges,
+		Columns:         columns,
+		Level:           level,
+		Accounts:        accounts,
+		WithGrantOption: withGrantOption,
+	}
+}
+
+// Children implements the sql.Node interface.
+func (*Grant) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*Grant) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*Grant) Schema() sql.Schema { return nil }
+
+// Granted returns the privileges the statement grants, where ALL PRIVILEGES means all the privileges of the level.
+func (n *Grant) Granted() (sql.Privilege, error) {
+	privileges := levelPrivileges(n.Privileges, n.Level)
+	if n.WithGrantOption {
+		privileges |= sql.PrivilegeGrantOption
+	}
+
+	if privileges&^n.Level.Privileges() != 0 {
+		return 0, sql.ErrIllegalGrantForLevel.New()
+	}
+
+	if err := checkColumnGrants(n.Columns, n.Level); err != nil {
+		return 0, err
+	}
+
+	return privileges, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (n *Grant) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	pm, err := n.Catalog.PrivilegeManager()
+	if err != nil {
+		return nil, err
+	}
+
+	privileges, err := n.Granted()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range n.Accounts {
+		if privileges != sql.PrivilegeUsage || len(n.Columns) == 0 {
+			if err := pm.Grant(ctx, a, n.Level, privileges); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, c := range n.Columns {
+			if err := pm.Grant(ctx, a, columnLevel(n.Level, c.Column), c.Privileges); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *Grant) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *Grant) String() string {
+	var withGrantOption string
+	if n.WithGrantOption {
+		withGrantOption = " WITH GRANT OPTION"
+	}
+	return fmt.Sprintf("GRANT %s ON %s TO %s%s", privilegeList(n.Privileges, n.Columns), n.Level, joinAccounts(n.Accounts), withGrantOption)
+}
+
+// Revoke revokes privileges at a level from one or more accounts.
+type Revoke struct {
+	Privileges sql.Privilege
+	Columns    []sql.ColumnGrant
+	Level      sql.PrivilegeLevel
+	// All revokes all the privileges of the accounts at every level, as
+	// REVOKE ALL PRIVILEGES, GRANT OPTION does. Privileges and Level are
+	// ignored.
+	All      bool
+	Accounts []sql.Account
+	Catalog  *sql.Catalog
+}
+
+var _ sql.Node = (*Revoke)(nil)
+
+// NewRevoke creates a new Revoke node.
+func NewRevoke(privileges sql.Privilege, columns []sql.ColumnGrant, level sql.PrivilegeLevel, accounts []sql.Account) *Revoke {
+	return &Revoke{Privileges: privileges, Columns: columns, Level: level, Accounts: accounts}
+}
+
+// NewRevokeAll creates a new Revoke node revoking all the privileges of the accounts given.
+func NewRevokeAll(accounts []sql.Account) *Revoke {
+	return &Revoke{All: true, Accounts: accounts}
+}
+
+// Children implements the sql.Node interface.
+func (*Revoke) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*Revoke) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*Revoke) Schema() sql.Schema { return nil }
+
+// Revoked returns the privileges the statement revokes, where ALL PRIVILEGES means all the privileges of the level.
+func (n *Revoke) Revoked() (sql.Privilege, error) {
+	privileges := levelPrivileges(n.Privileges, n.Level)
+	if privileges&^n.Level.Privileges() != 0 {
+		return 0, sql.ErrIllegalGrantForLevel.New()
+	}
+
+	if err := checkColumnGrants(n.Columns, n.Level); err != nil {
+		return 0, err
+	}
+
+	return privileges, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (n *Revoke) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	pm, err := n.Catalog.PrivilegeManager()
+	if err != nil {
+		return nil, err
+	}
+
+	if n.All {
+		var failed []sql.Account
+		for _, a := range n.Accounts {
+			if err := revokeAll(ctx, pm, a); err != nil {
+				failed = append(failed, a)
+			}
+		}
+
+		if len(failed) > 0 {
+			return nil, sql.ErrUserOperationFailed.New("REVOKE ALL PRIVILEGES, GRANT OPTION", joinAccounts(failed))
+		}
+
+		return sql.RowsToRowIter(), nil
+	}
+
+	privileges, err := n.Revoked()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range n.Accounts {
+		if privileges != sql.PrivilegeUsage || len(n.Columns) == 0 {
+			if err := pm.Revoke(ctx, a, n.Level, privileges); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, c := range n.Columns {
+			if err := pm.Revoke(ctx, a, columnLevel(n.Level, c.Column), c.Privileges); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+func revokeAll(ctx *sql.Context, pm sql.PrivilegeManager, account sql.Account) error {
+	privileges, err := pm.Privileges(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range privileges {
+		if err := pm.Revoke(ctx, account, g.Level, g.Privileges); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *Revoke) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *Revoke) String() string {
+	if n.All {
+		return fmt.Sprintf("REVOKE ALL PRIVILEGES, GRANT OPTION FROM %s", joinAccounts(n.Accounts))
+	}
+
+	privileges := privilegeList(n.Privileges, n.Columns)
+	if n.Privileges == sql.PrivilegeGrantOption && len(n.Columns) == 0 {
+		privileges = "GRANT OPTION"
+	} else if n.Privileges&sql.PrivilegeGrantOption != 0 {
+		privileges += ", GRANT OPTION"
+	}
+	return fmt.Sprintf("REVOKE %s ON %s FROM %s", privileges, n.Level, joinAccounts(n.Accounts))
+}
+
+// levelPrivileges returns the privileges given, where ALL PRIVILEGES means
+// all the privileges that can be granted at the level given.
+func levelPrivileges(privileges sql.Privilege, level sql.PrivilegeLevel) sql.Privilege {
+	if privileges&sql.PrivilegeAll == sql.PrivilegeAll {
+		return privileges&sql.PrivilegeGrantOption | level.Privileges()&^sql.PrivilegeGrantOption
+	}
+	return privileges
+}
+
+// checkColumnGrants checks that the privileges granted on columns can be
+// granted on columns, and that the level given is a table.
+func checkColumnGrants(columns []sql.ColumnGrant, level sql.PrivilegeLevel) error {
+	for _, c := range columns {
+		if level.Table == "" || c.Privileges&^sql.ColumnPrivileges != 0 {
+			return sql.ErrIllegalGrantForLevel.New()
+		}
+	}
+	return nil
+}
+
+func columnLevel(level sql.PrivilegeLevel, column string) sql.PrivilegeLevel {
+	level.Column = column
+	return level
+}
+
+// privilegeList returns the privileges as written in GRANT and REVOKE
+// statements, such as SELECT, INSERT (a, b).
+func privilegeList(privileges sql.Privilege, columns []sql.ColumnGrant) string {
+	var list []string
+	if privileges&^sql.PrivilegeGrantOption != sql.PrivilegeUsage || len(columns) == 0 {
+		list = append(list, privileges.String())
+	}
+
+	for _, name := range sql.ColumnPrivileges.Names() {
+		p, _ := sql.ParsePrivilege(name)
+
+		var names []string
+		for _, c := range columns {
+			if c.Privileges&p != 0 {
+				names = append(names, c.Column)
+			}
+		}
+
+		if len(names) > 0 {
+			list = append(list, fmt.Sprintf("%s (%s)", name, strings.Join(names, ", ")))
+		}
+	}
+
+	return strings.Join(list, ", ")
+}
@@ -21,6 +21,9 @@ type GroupBy struct {
 	UnaryNode
 	SelectedExprs []sql.Expression
 	GroupByExprs  []sql.Expression
+	// Ordered is whether the child returns the rows of every group one after another, as when they're ordered by the
+	// grouping expressions, so that the groups can be aggregated one at a time as the rows are read.
+	Ordered bool
 }
 
 // NewGroupBy creates a new GroupBy node. Like Project, GroupBy is a top-level node, and contains all the fields that
@@ -85,6 +88,8 @@ func (g *GroupBy) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 	var iter sql.RowIter
 	if len(g.GroupByExprs) == 0 {
 		iter = newGroupByIter(ctx, g.SelectedExprs, i)
+	} else if g.Ordered {
+		iter = newGroupByOrderedIter(ctx, g.SelectedExprs, g.GroupByExprs, i)
 	} else {
 		iter = newGroupByGroupingIter(ctx, g.SelectedExprs, g.GroupByExprs, i)
 	}
@@ -98,7 +103,9 @@ func (g *GroupBy) WithChildren(children ...sql.Node) (sql.Node, error) {
 		return nil, sql.ErrInvalidChildrenNumber.New(g, len(children), 1)
 	}
 
-	return NewGroupBy(g.SelectedExprs, g.GroupByExprs, children[0]), nil
+	ng := *g
+	ng.Child = children[0]
+	return &ng, nil
 }
 
 // WithExpressions implements the Node interface.
@@ -119,7 +126,10 @@ func (g *GroupBy) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
 		grouping[i] = exprs[i+offset]
 	}
 
-	return NewGroupBy(agg, grouping, g.Child), nil
+	ng := *g
+	ng.SelectedExprs = agg
+	ng.GroupByExprs = grouping
+	return &ng, nil
 }
 
 func (g *GroupBy) String() string {
@@ -315,6 +325,86 @@ func (i *groupByGroupingIter) Close() error {
 	return i.child.Close()
 }
 
+// groupByOrderedIter aggregates the groups of rows of a child that returns the rows of every group one after another,
+// returning the result for each group as soon as the rows of the next one begin.
+type groupByOrderedIter struct {
+	selectedExprs []sql.Expression
+	groupByExprs  []sql.Expression
+	child         sql.RowIter
+	ctx           *sql.Context
+	buf           []sql.Row
+	key           uint64
+	done          bool
+}
+
+func newGroupByOrderedIter(
+	ctx *sql.Context,
+	selectedExprs, groupByExprs []sql.Expression,
+	child sql.RowIter,
+) *groupByOrderedIter {
+	return &groupByOrderedIter{
+		selectedExprs: selectedExprs,
+		groupByExprs:  groupByExprs,
+		child:         child,
+		ctx:           ctx,
+	}
+}
+
+func (i *groupByOrderedIter) Next() (sql.Row, error) {
+	if i.done {
+		return nil, io.EOF
+	}
+
+	for {
+		row, err := i.child.Next()
+		if err == io.EOF {
+			i.done = true
+			if i.buf == nil {
+				return nil, io.EOF
+			}
+			return evalBuffers(i.ctx, i.buf, i.selectedExprs)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := groupingKey(i.ctx, i.groupByExprs, row)
+		if err != nil {
+			return nil, err
+		}
+
+		var result sql.Row
+		if i.buf != nil && key != i.key {
+			result, err = evalBuffers(i.ctx, i.buf, i.selectedExprs)
+			if err != nil {
+				return nil, err
+			}
+			i.buf = nil
+		}
+
+		if i.buf == nil {
+			i.key = key
+			i.buf = make([]sql.Row, len(i.selectedExprs))
+			for j, a := range i.selectedExprs {
+				i.buf[j] = fillBuffer(a)
+			}
+		}
+
+		if err := updateBuffers(i.ctx, i.buf, i.selectedExprs, row); err != nil {
+			return nil, err
+		}
+
+		if result != nil {
+			return result, nil
+		}
+	}
+}
+
+func (i *groupByOrderedIter) Close() error {
+	i.buf = nil
+	return i.child.Close()
+}
+
 func groupingKey(
 	ctx *sql.Context,
 	exprs []sql.Expression,
@@ -97,6 +97,56 @@ func TestGroupByRowIter(t *testing.T) {
 	require.Equal(sql.NewRow("col1_2", int64(4444)), rows[1])
 }
 
+func TestGroupByOrderedRowIter(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	child := memory.NewTable("test", sql.Schema{
+		{Name: "col1", Type: sql.LongText},
+		{Name: "col2", Type: sql.Int64},
+	})
+
+	rows := []sql.Row{
+		sql.NewRow("col1_1", int64(1)),
+		sql.NewRow("col1_1", int64(2)),
+		sql.NewRow("col1_2", int64(3)),
+		sql.NewRow("col1_3", int64(4)),
+		sql.NewRow("col1_3", int64(5)),
+	}
+
+	for _, r := range rows {
+		require.NoError(child.Insert(sql.NewEmptyContext(), r))
+	}
+
+	p := NewGroupBy(
+		[]sql.Expression{
+			expression.NewGetField(0, sql.LongText, "col1", true),
+			aggregation.NewSum(expression.NewGetField(1, sql.Int64, "col2", true)),
+		},
+		[]sql.Expression{
+			expression.NewGetField(0, sql.LongText, "col1", true),
+		},
+		NewResolvedTable(child),
+	)
+	p.Ordered = true
+
+	rows, err := sql.NodeToRows(ctx, p)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		sql.NewRow("col1_1", float64(3)),
+		sql.NewRow("col1_2", float64(3)),
+		sql.NewRow("col1_3", float64(9)),
+	}, rows)
+
+	node, err := p.WithChildren(NewResolvedTable(memory.NewTable("test", child.Schema())))
+	require.NoError(err)
+	require.True(node.(*GroupBy).Ordered)
+
+	rows, err = sql.NodeToRows(ctx, node)
+	require.NoError(err)
+	require.Empty(rows)
+}
+
 func TestGroupByEvalEmptyBuffer(t *testing.T) {
 	require := require.New(t)
 	ctx := sql.NewEmptyContext()
@@ -29,6 +29,14 @@ type InsertInto struct {
 	ColumnNames []string
 	IsReplace   bool
 	OnDupExprs  []sql.Expression
+	// ForeignKeys checks the rows inserted reference existing rows, unless foreign_key_checks is off, or is nil if the
+	// table declares no foreign keys.
+	ForeignKeys *ForeignKeyEditor
+	// Checks are the CHECK constraints of the table, which the rows inserted must satisfy while they're enforced.
+	Checks []*sql.CheckConstraint
+	// Ignore is whether the statement is INSERT IGNORE, which skips the rows that would fail it with a warning, and
+	// stores the values their columns can't hold adjusted in any sql_mode.
+	Ignore bool
 }
 
 // NewInsertInto creates an InsertInto node.
@@ -41,6 +49,20 @@ func NewInsertInto(dst, src sql.Node, isReplace bool, cols []string, onDupExprs
 	}
 }
 
+// WithForeignKeys returns a copy of this node that checks the foreign keys of the editor given for the rows inserted.
+func (p *InsertInto) WithForeignKeys(editor *ForeignKeyEditor) *InsertInto {
+	np := *p
+	np.ForeignKeys = editor
+	return &np
+}
+
+// WithChecks returns a copy of this node that checks the rows inserted satisfy the CHECK constraints given.
+func (p *InsertInto) WithChecks(checks []*sql.CheckConstraint) *InsertInto {
+	np := *p
+	np.Checks = checks
+	return &np
+}
+
 // Schema implements the sql.Node interface.
 // Insert nodes return rows that are inserted. Replaces return a concatenation of the deleted row and the inserted row.
 // If no row was deleted, the value of those columns is nil.
@@ -56,11 +78,21 @@ type insertIter struct {
 	inserter    sql.RowInserter
 	replacer    sql.RowReplacer
 	updater     sql.RowUpdater
+	upserter    sql.RowUpserter
 	rowSource   sql.RowIter
 	ctx         *sql.Context
 	updateExprs []sql.Expression
 	tableNode   sql.Node
 	closed      bool
+	// foreignKeys checks the foreign keys of the rows inserted, or is nil if they aren't checked.
+	foreignKeys *ForeignKeyEditor
+	checks      []*sql.CheckConstraint
+	ignore      bool
+
+	// bulk is the inserter if it inserts rows in batches, and batch are the rows of the last batch left to return.
+	bulk       sql.BulkRowInserter
+	batch      []sql.Row
+	sourceDone bool
 }
 
 func GetInsertable(node sql.Node) (sql.InsertableTable, error) {
@@ -95,6 +127,9 @@ func newInsertIter(
 	values sql.Node,
 	isReplace bool,
 	onDupUpdateExpr []sql.Expression,
+	foreignKeys *ForeignKeyEditor,
+	checks []*sql.CheckConstraint,
+	ignore bool,
 	row sql.Row,
 ) (*insertIter, error) {
 	dstSchema := table.Schema()
@@ -108,8 +143,11 @@ func newInsertIter(
 
 	var replacer sql.RowReplacer
 	var updater sql.RowUpdater
+	var upserter sql.RowUpserter
 	// These type casts have already been asserted in the analyzer
-	if isReplace {
+	if upsertable, ok := insertable.(sql.UpsertableTable); ok && (isReplace || len(onDupUpdateExpr) > 0) {
+		upserter = upsertable.Upserter(ctx)
+	} else if isReplace {
 		replacer = insertable.(sql.ReplaceableTable).Replacer(ctx)
 	} else {
 		inserter = insertable.Inserter(ctx)
@@ -118,54 +156,62 @@ func newInsertIter(
 		}
 	}
 
+	// Some sources compute their rows when their iterators are created
+	warnings := ctx.WarningCount()
 	rowIter, err := values.RowIter(ctx, row)
 	if err != nil {
 		return nil, err
 	}
+	if err = sql.CheckDivisionByZero(ctx, warnings); err != nil && !ignore {
+		_ = rowIter.Close()
+		return nil, err
+	}
 
-	return &insertIter{
+	iter := &insertIter{
 		schema:      dstSchema,
 		tableNode:   table,
 		inserter:    inserter,
 		replacer:    replacer,
 		updater:     updater,
+		upserter:    upserter,
 		rowSource:   rowIter,
 		updateExprs: onDupUpdateExpr,
+		checks:      checks,
+		ignore:      ignore,
 		ctx:         ctx,
-	}, nil
-}
+	}
 
-func (i insertIter) Next() (returnRow sql.Row, returnErr error) {
-	row, err := i.rowSource.Next()
-	if err == io.EOF {
-		return nil, err
+	if foreignKeys != nil && len(foreignKeys.ForeignKeys) > 0 && sql.ForeignKeyChecks(ctx) {
+		iter.foreignKeys = foreignKeys
 	}
 
-	if err != nil {
-		_ = i.rowSource.Close()
-		return nil, err
+	// The rows of a batch aren't inserted until it's complete, so the ones referencing rows inserted before them in the
+	// same batch would fail the foreign key checks, and INSERT IGNORE skips the rows failing to be inserted one by one.
+	if bulk, ok := inserter.(sql.BulkRowInserter); ok && updater == nil && iter.foreignKeys == nil && !ignore {
+		iter.bulk = bulk
+		bulk.StatementBegin(ctx)
 	}
 
-	// Prune the row down to the size of the schema. It can be larger in the case of running with an outer scope, in which
-	// case the additional scope variables are prepended to the row.
-	if len(row) > len(i.schema) {
-		row = row[len(row)-len(i.schema):]
+	return iter, nil
+}
+
+func (i *insertIter) Next() (returnRow sql.Row, returnErr error) {
+	if i.bulk != nil {
+		return i.nextBulk()
+	}
+
+	row, err := i.nextPreparedRow()
+	if err == io.EOF {
+		return nil, err
 	}
 
-	err = validateNullability(i.schema, row)
 	if err != nil {
 		_ = i.rowSource.Close()
 		return nil, err
 	}
 
-	// Do any necessary type conversions to the target schema
-	for i, col := range i.schema {
-		if row[i] != nil {
-			row[i], err = col.Type.Convert(row[i])
-			if err != nil {
-				return nil, err
-			}
-		}
+	if i.upserter != nil {
+		return i.upsert(row)
 	}
 
 	if i.replacer != nil {
@@ -188,6 +234,9 @@ func (i insertIter) Next() (returnRow sql.Row, returnErr error) {
 		return toReturn, nil
 	} else {
 		if err := i.inserter.Insert(i.ctx, row); err != nil {
+			if i.ignore && len(i.updateExprs) == 0 && i.ignoreError(err) {
+				return i.Next()
+			}
 			if !sql.ErrUniqueKeyViolation.Is(err) || len(i.updateExprs) == 0 {
 				_ = i.rowSource.Close()
 				return nil, err
@@ -207,6 +256,12 @@ func (i insertIter) Next() (returnRow sql.Row, returnErr error) {
 				}
 			}
 
+			// The duplicate key is of a unique index, not of a primary key, and only primary keys are updated for now.
+			if pkExpression == nil {
+				_ = i.rowSource.Close()
+				return nil, err
+			}
+
 			filter := NewFilter(pkExpression, i.tableNode)
 			filterIter, err := filter.RowIter(i.ctx, row)
 			if err != nil {
@@ -228,7 +283,7 @@ func (i insertIter) Next() (returnRow sql.Row, returnErr error) {
 				return nil, err
 			}
 
-			newRow, err := applyUpdateExpressions(i.ctx, i.updateExprs, rowToUpdate)
+			newRow, err := i.updatedRow(rowToUpdate)
 			if err != nil {
 				return nil, err
 			}
@@ -246,9 +301,204 @@ func (i insertIter) Next() (returnRow sql.Row, returnErr error) {
 	return row, nil
 }
 
-func (i insertIter) Close() error {
+// upsert replaces the row with the same key as the one given, or updates it with the ON DUPLICATE KEY UPDATE
+// expressions, or inserts the row given if there's none, with the upserter of the table. It returns the same rows as
+// the replacer and the inserter and updater would.
+func (i *insertIter) upsert(row sql.Row) (sql.Row, error) {
+	if len(i.updateExprs) == 0 {
+		replaced, err := i.upserter.Replace(i.ctx, row)
+		if err != nil {
+			_ = i.rowSource.Close()
+			return nil, err
+		}
+		if !replaced {
+			return make(sql.Row, len(row)).Append(row), nil
+		}
+		return row.Append(row), nil
+	}
+
+	rowToUpdate, newRow, err := i.upserter.Upsert(i.ctx, row, i.updatedRow)
+	if err != nil {
+		_ = i.rowSource.Close()
+		return nil, err
+	}
+	if rowToUpdate == nil {
+		return newRow, nil
+	}
+	return rowToUpdate.Append(newRow), nil
+}
+
+// updatedRow returns the row given, which has the same key as a row inserted, updated with the ON DUPLICATE KEY
+// UPDATE expressions, after checking it satisfies the constraints of the table.
+func (i *insertIter) updatedRow(rowToUpdate sql.Row) (sql.Row, error) {
+	newRow, err := applyUpdateExpressions(i.ctx, i.updateExprs, rowToUpdate)
+	if err != nil {
+		return nil, err
+	}
+	newRow, err = applyOnUpdateExpressions(i.ctx, i.schema, i.updateExprs, rowToUpdate, newRow)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkRow(i.ctx, i.checks, newRow); err != nil {
+		return nil, err
+	}
+
+	if i.foreignKeys != nil {
+		if err := i.foreignKeys.checkForeignKeys(i.ctx, rowToUpdate, newRow); err != nil {
+			return nil, err
+		}
+	}
+	return newRow, nil
+}
+
+// nextSourceRow returns the next row to insert from the source, which is an error in strict mode if computing it
+// divided by zero.
+func (i *insertIter) nextSourceRow() (sql.Row, error) {
+	warnings := i.ctx.WarningCount()
+	row, err := i.rowSource.Next()
+	if err != nil {
+		return nil, err
+	}
+	if err = sql.CheckDivisionByZero(i.ctx, warnings); err != nil && !i.ignore {
+		return nil, err
+	}
+	return row, nil
+}
+
+// nextPreparedRow returns the next row of the source prepared to be inserted. With IGNORE, the rows failing to be
+// prepared are skipped with a warning.
+func (i *insertIter) nextPreparedRow() (sql.Row, error) {
+	for {
+		row, err := i.nextSourceRow()
+		if err != nil {
+			return nil, err
+		}
+
+		row, err = i.prepareRow(row)
+		if err != nil && i.ignore && i.ignoreError(err) {
+			continue
+		}
+		return row, err
+	}
+}
+
+// ignoreError adds a warning for the error given and returns true if it's one of a row that INSERT IGNORE skips.
+func (i *insertIter) ignoreError(err error) bool {
+	var code int
+	switch {
+	case sql.ErrUniqueKeyViolation.Is(err):
+		code = 1062
+	case sql.ErrCheckConstraintViolated.Is(err):
+		code = 3819
+	case sql.ErrForeignKeyChildViolation.Is(err):
+		code = 1452
+	default:
+		return false
+	}
+	i.ctx.Warn(code, "%s", err.Error())
+	return true
+}
+
+// prepareRow validates a row to insert and converts its values to the types of the columns of the table, as the
+// sql_mode of the session allows.
+func (i *insertIter) prepareRow(row sql.Row) (sql.Row, error) {
+	// Prune the row down to the size of the schema. It can be larger in the case of running with an outer scope, in which
+	// case the additional scope variables are prepended to the row.
+	if len(row) > len(i.schema) {
+		row = row[len(row)-len(i.schema):]
+	}
+
+	if i.ignore {
+		// NULL values of columns that aren't nullable are their zero values with a warning
+		for j, col := range i.schema {
+			if !col.Nullable && row[j] == nil {
+				i.ctx.Warn(1048, "Column '%s' cannot be null", col.Name)
+				row[j] = col.Type.Zero()
+			}
+		}
+	} else if err := validateNullability(i.schema, row); err != nil {
+		return nil, err
+	}
+
+	// Do any necessary type conversions to the target schema
+	ctx := i.ctx
+	convert := sql.ConvertToColumn
+	if i.ignore {
+		convert = sql.AdjustToColumn
+	}
+	for i, col := range i.schema {
+		if row[i] != nil {
+			var err error
+			row[i], err = convert(ctx, col.Type, col.Name, row[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := checkRow(i.ctx, i.checks, row); err != nil {
+		return nil, err
+	}
+
+	if i.foreignKeys != nil {
+		if err := i.foreignKeys.checkForeignKeys(i.ctx, nil, row); err != nil {
+			return nil, err
+		}
+	}
+	return row, nil
+}
+
+// nextBulk returns the next row inserted by the bulk inserter, inserting the next batch of rows first once the ones of
+// the last batch were all returned.
+func (i *insertIter) nextBulk() (sql.Row, error) {
+	if len(i.batch) == 0 {
+		if err := i.insertBatch(); err != nil {
+			return nil, err
+		}
+	}
+
+	row := i.batch[0]
+	i.batch = i.batch[1:]
+	return row, nil
+}
+
+// insertBatch inserts the next sql.InsertBatchSize rows of the source at most with the bulk inserter.
+func (i *insertIter) insertBatch() error {
+	var batch []sql.Row
+	for !i.sourceDone && len(batch) < sql.InsertBatchSize {
+		row, err := i.nextPreparedRow()
+		if err == io.EOF {
+			i.sourceDone = true
+			break
+		}
+		if err != nil {
+			_ = i.rowSource.Close()
+			return err
+		}
+		batch = append(batch, row)
+	}
+
+	if len(batch) == 0 {
+		return io.EOF
+	}
+
+	if err := i.bulk.InsertBatch(i.ctx, batch); err != nil {
+		_ = i.rowSource.Close()
+		return err
+	}
+	i.batch = batch
+	return nil
+}
+
+func (i *insertIter) Close() error {
 	if !i.closed {
 		i.closed = true
+		if i.bulk != nil {
+			if err := i.bulk.StatementComplete(i.ctx); err != nil {
+				return err
+			}
+		}
 		if i.inserter != nil {
 			if err := i.inserter.Close(i.ctx); err != nil {
 				return err
@@ -264,6 +514,11 @@ func (i insertIter) Close() error {
 				return err
 			}
 		}
+		if i.upserter != nil {
+			if err := i.upserter.Close(i.ctx); err != nil {
+				return err
+			}
+		}
 		if i.rowSource != nil {
 			if err := i.rowSource.Close(); err != nil {
 				return err
@@ -275,7 +530,7 @@ func (i insertIter) Close() error {
 
 // RowIter implements the Node interface.
 func (p *InsertInto) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
-	return newInsertIter(ctx, p.left, p.right, p.IsReplace, p.OnDupExprs, row)
+	return newInsertIter(ctx, p.left, p.right, p.IsReplace, p.OnDupExprs, p.ForeignKeys, p.Checks, p.Ignore, row)
 }
 
 // WithChildren implements the Node interface.
@@ -293,6 +548,8 @@ func (p InsertInto) String() string {
 	pr := sql.NewTreePrinter()
 	if p.IsReplace {
 		_ = pr.WriteNode("Replace(%s)", strings.Join(p.ColumnNames, ", "))
+	} else if p.Ignore {
+		_ = pr.WriteNode("Insert ignore(%s)", strings.Join(p.ColumnNames, ", "))
 	} else {
 		_ = pr.WriteNode("Insert(%s)", strings.Join(p.ColumnNames, ", "))
 	}
@@ -330,7 +587,9 @@ func (p *InsertInto) WithExpressions(newExprs ...sql.Expression) (sql.Node, erro
 		return nil, sql.ErrInvalidChildrenNumber.New(p, len(p.OnDupExprs), 1)
 	}
 
-	return NewInsertInto(p.left, p.right, p.IsReplace, p.ColumnNames, newExprs), nil
+	np := *p
+	np.OnDupExprs = newExprs
+	return &np, nil
 }
 
 // Resolved implements the Resolvable interface.
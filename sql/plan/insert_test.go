@@ -0,0 +1,79 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// bulkTable is a table whose inserter records the calls of the bulk insertions.
+type bulkTable struct {
+	*memory.Table
+	calls []string
+}
+
+func (t *bulkTable) Inserter(ctx *sql.Context) sql.RowInserter {
+	return &bulkInserter{t.Table.Inserter(ctx).(sql.BulkRowInserter), t}
+}
+
+type bulkInserter struct {
+	sql.BulkRowInserter
+	table *bulkTable
+}
+
+func (i *bulkInserter) Insert(ctx *sql.Context, row sql.Row) error {
+	i.table.calls = append(i.table.calls, "insert")
+	return i.BulkRowInserter.Insert(ctx, row)
+}
+
+func (i *bulkInserter) StatementBegin(ctx *sql.Context) {
+	i.table.calls = append(i.table.calls, "begin")
+	i.BulkRowInserter.StatementBegin(ctx)
+}
+
+func (i *bulkInserter) InsertBatch(ctx *sql.Context, rows []sql.Row) error {
+	i.table.calls = append(i.table.calls, "batch")
+	return i.BulkRowInserter.InsertBatch(ctx, rows)
+}
+
+func (i *bulkInserter) StatementComplete(ctx *sql.Context) error {
+	i.table.calls = append(i.table.calls, "complete")
+	return i.BulkRowInserter.StatementComplete(ctx)
+}
+
+func TestInsertIntoBulk(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	schema := sql.Schema{{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true}}
+	table := &bulkTable{Table: memory.NewTable("t", schema)}
+
+	values := make([][]sql.Expression, sql.InsertBatchSize+1)
+	for i := range values {
+		values[i] = []sql.Expression{expression.NewLiteral(int64(i), sql.Int64)}
+	}
+
+	insert := NewInsertInto(NewResolvedTable(table), NewValues(values), false, []string{"i"}, nil)
+	iter, err := insert.RowIter(ctx, nil)
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Len(rows, sql.InsertBatchSize+1)
+	require.Equal([]string{"begin", "batch", "batch", "complete"}, table.calls)
+
+	require.Len(collectRows(t, NewResolvedTable(table)), sql.InsertBatchSize+1)
+
+	// The duplicate keys are errors of the batch they're in.
+	table.calls = nil
+	insert = NewInsertInto(NewResolvedTable(table), NewValues([][]sql.Expression{{expression.NewLiteral(int64(0), sql.Int64)}}), false, []string{"i"}, nil)
+	iter, err = insert.RowIter(ctx, nil)
+	require.NoError(err)
+	_, err = sql.RowIterToRows(iter)
+	require.True(sql.ErrUniqueKeyViolation.Is(err), "%v", err)
+	require.NoError(iter.Close())
+	require.Equal([]string{"begin", "batch", "complete"}, table.calls)
+}
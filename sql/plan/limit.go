@@ -2,6 +2,7 @@ package plan
 
 import (
 	"io"
+	"strconv"
 
 	opentracing "github.com/opentracing/opentracing-go"
 
@@ -12,6 +13,13 @@ import (
 type Limit struct {
 	UnaryNode
 	Limit int64
+	// BindVar is the name of the parameter of a prepared statement whose
+	// value is the limit, until the statement is executed.
+	BindVar string
+	// CalcFoundRows is whether the limit is the one of a SELECT with
+	// SQL_CALC_FOUND_ROWS, which reads all the rows of its child to set the
+	// number of rows found without it.
+	CalcFoundRows bool
 }
 
 // NewLimit creates a new Limit node with the given size.
@@ -22,6 +30,15 @@ func NewLimit(size int64, child sql.Node) *Limit {
 	}
 }
 
+// NewBindLimit creates a new Limit node whose size is the value of the
+// parameter of a prepared statement given.
+func NewBindLimit(name string, child sql.Node) *Limit {
+	return &Limit{
+		UnaryNode: UnaryNode{Child: child},
+		BindVar:   name,
+	}
+}
+
 // Resolved implements the Resolvable interface.
 func (l *Limit) Resolved() bool {
 	return l.UnaryNode.Child.Resolved()
@@ -29,8 +46,16 @@ func (l *Limit) Resolved() bool {
 
 // RowIter implements the Node interface.
 func (l *Limit) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	if l.BindVar != "" {
+		return nil, sql.ErrUnboundPreparedStatementVariable.New(l.BindVar)
+	}
+
 	span, ctx := ctx.Span("plan.Limit", opentracing.Tag{Key: "limit", Value: l.Limit})
 
+	if l.CalcFoundRows {
+		return l.foundRowsIter(ctx, span, row)
+	}
+
 	li, err := l.Child.RowIter(ctx, row)
 	if err != nil {
 		span.Finish()
@@ -39,28 +64,53 @@ func (l *Limit) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 	return sql.NewSpanIter(span, &limitIter{l, 0, li}), nil
 }
 
+// foundRowsIter returns the iterator of a Limit calculating the rows found,
+// which skips the rows of the Offset below it itself, to count them too.
+func (l *Limit) foundRowsIter(ctx *sql.Context, span opentracing.Span, row sql.Row) (sql.RowIter, error) {
+	child, offset := l.Child, int64(0)
+	if o, ok := child.(*Offset); ok && o.BindVar == "" {
+		child, offset = o.Child, o.Offset
+	}
+
+	it, err := child.RowIter(ctx, row)
+	if err != nil {
+		span.Finish()
+		return nil, err
+	}
+	return sql.NewSpanIter(span, &foundRowsIter{ctx: ctx, limit: l.Limit, offset: offset, childIter: it}), nil
+}
+
 // WithChildren implements the Node interface.
 func (l *Limit) WithChildren(children ...sql.Node) (sql.Node, error) {
 	if len(children) != 1 {
 		return nil, sql.ErrInvalidChildrenNumber.New(l, len(children), 1)
 	}
-	return NewLimit(l.Limit, children[0]), nil
+	nl := *l
+	nl.UnaryNode = UnaryNode{Child: children[0]}
+	return &nl, nil
 }
 
 func (l Limit) String() string {
 	pr := sql.NewTreePrinter()
-	_ = pr.WriteNode("Limit(%d)", l.Limit)
+	_ = pr.WriteNode("Limit(%s)", l.size())
 	_ = pr.WriteChildren(l.Child.String())
 	return pr.String()
 }
 
 func (l Limit) DebugString() string {
 	pr := sql.NewTreePrinter()
-	_ = pr.WriteNode("Limit(%d)", l.Limit)
+	_ = pr.WriteNode("Limit(%s)", l.size())
 	_ = pr.WriteChildren(sql.DebugString(l.Child))
 	return pr.String()
 }
 
+func (l Limit) size() string {
+	if l.BindVar != "" {
+		return ":" + l.BindVar
+	}
+	return strconv.FormatInt(l.Limit, 10)
+}
+
 type limitIter struct {
 	l          *Limit
 	currentPos int64
@@ -84,3 +134,35 @@ func (li *limitIter) Next() (sql.Row, error) {
 func (li *limitIter) Close() error {
 	return li.childIter.Close()
 }
+
+// foundRowsIter returns the rows of its child after the first offset ones,
+// up to limit rows, and sets the number of rows found once it read all of
+// them.
+type foundRowsIter struct {
+	ctx       *sql.Context
+	limit     int64
+	offset    int64
+	found     int64
+	childIter sql.RowIter
+}
+
+func (i *foundRowsIter) Next() (sql.Row, error) {
+	for {
+		row, err := i.childIter.Next()
+		if err == io.EOF {
+			sql.SetLastQueryInfo(i.ctx, sql.FoundRows, i.found)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		i.found++
+		if i.found > i.offset && i.found-i.offset <= i.limit {
+			return row, nil
+		}
+	}
+}
+
+func (i *foundRowsIter) Close() error {
+	return i.childIter.Close()
+}
@@ -65,6 +65,22 @@ func TestLimitGreaterThanTotal(t *testing.T) {
 	testLimitOverflow(t, iterator, testingLimit, size)
 }
 
+func TestLimitCalcFoundRows(t *testing.T) {
+	require := require.New(t)
+	table, size := getTestingTable(t)
+
+	ctx := sql.NewEmptyContext()
+	limit := NewLimit(1, NewOffset(1, NewResolvedTable(table)))
+	limit.CalcFoundRows = true
+
+	iter, err := limit.RowIter(ctx, nil)
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{sql.NewRow("22a")}, rows)
+	require.Equal(int64(size), sql.LastQueryInfo(ctx, sql.FoundRows))
+}
+
 func testLimitOverflow(t *testing.T, iter sql.RowIter, limit int, dataSize int) {
 	require := require.New(t)
 	for i := 0; i < limit+1; i++ {
@@ -10,6 +10,9 @@ import (
 type Offset struct {
 	UnaryNode
 	Offset int64
+	// BindVar is the name of the parameter of a prepared statement whose
+	// value is the offset, until the statement is executed.
+	BindVar string
 }
 
 // NewOffset creates a new Offset node.
@@ -20,6 +23,15 @@ func NewOffset(n int64, child sql.Node) *Offset {
 	}
 }
 
+// NewBindOffset creates a new Offset node whose number of rows to skip is the
+// value of the parameter of a prepared statement given.
+func NewBindOffset(name string, child sql.Node) *Offset {
+	return &Offset{
+		UnaryNode: UnaryNode{Child: child},
+		BindVar:   name,
+	}
+}
+
 // Resolved implements the Resolvable interface.
 func (o *Offset) Resolved() bool {
 	return o.Child.Resolved()
@@ -27,6 +39,10 @@ func (o *Offset) Resolved() bool {
 
 // RowIter implements the Node interface.
 func (o *Offset) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	if o.BindVar != "" {
+		return nil, sql.ErrUnboundPreparedStatementVariable.New(o.BindVar)
+	}
+
 	span, ctx := ctx.Span("plan.Offset", opentracing.Tag{Key: "offset", Value: o.Offset})
 
 	it, err := o.Child.RowIter(ctx, row)
@@ -42,12 +58,18 @@ func (o *Offset) WithChildren(children ...sql.Node) (sql.Node, error) {
 	if len(children) != 1 {
 		return nil, sql.ErrInvalidChildrenNumber.New(o, len(children), 1)
 	}
-	return NewOffset(o.Offset, children[0]), nil
+	no := *o
+	no.UnaryNode = UnaryNode{Child: children[0]}
+	return &no, nil
 }
 
 func (o Offset) String() string {
 	pr := sql.NewTreePrinter()
-	_ = pr.WriteNode("Offset(%d)", o.Offset)
+	if o.BindVar != "" {
+		_ = pr.WriteNode("Offset(:%s)", o.BindVar)
+	} else {
+		_ = pr.WriteNode("Offset(%d)", o.Offset)
+	}
 	_ = pr.WriteChildren(o.Child.String())
 	return pr.String()
 }
@@ -0,0 +1,97 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// PrepareQuery prepares a statement with a name in the session, to be executed with EXECUTE. Executing it is parsing
+// the statement, with the values of the variables given as its parameters.
+type PrepareQuery struct {
+	Name  string
+	Query string
+}
+
+var _ sql.Node = (*PrepareQuery)(nil)
+
+// NewPrepareQuery creates a new PrepareQuery node.
+func NewPrepareQuery(name, query string) *PrepareQuery {
+	return &PrepareQuery{Name: name, Query: query}
+}
+
+// Children implements the sql.Node interface.
+func (*PrepareQuery) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*PrepareQuery) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*PrepareQuery) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *PrepareQuery) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	session, ok := ctx.Session.(sql.PreparedStatementSession)
+	if !ok {
+		return nil, sql.ErrPreparedStatementsNotSupported.New()
+	}
+
+	session.PrepareStatement(n.Name, n.Query)
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *PrepareQuery) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *PrepareQuery) String() string {
+	return fmt.Sprintf("PREPARE %s FROM '%s'", n.Name, n.Query)
+}
+
+// DeallocateQuery removes a statement prepared in the session.
+type DeallocateQuery struct {
+	Name string
+}
+
+var _ sql.Node = (*DeallocateQuery)(nil)
+
+// NewDeallocateQuery creates a new DeallocateQuery node.
+func NewDeallocateQuery(name string) *DeallocateQuery {
+	return &DeallocateQuery{Name: name}
+}
+
+// Children implements the sql.Node interface.
+func (*DeallocateQuery) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*DeallocateQuery) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*DeallocateQuery) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *DeallocateQuery) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	session, ok := ctx.Session.(sql.PreparedStatementSession)
+	if !ok || !session.DeallocateStatement(n.Name) {
+		return nil, sql.ErrUnknownPreparedStatement.New(n.Name, "DEALLOCATE PREPARE")
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *DeallocateQuery) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *DeallocateQuery) String() string {
+	return fmt.Sprintf("DEALLOCATE PREPARE %s", n.Name)
+}
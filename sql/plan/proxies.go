@@ -0,0 +1,116 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// GrantProxy grants PROXY on an account to one or more accounts.
+type GrantProxy struct {
+	Proxied         sql.Account
+	Accounts        []sql.Account
+	WithGrantOption bool
+	Catalog         *sql.Catalog
+}
+
+var _ sql.Node = (*GrantProxy)(nil)
+
+// NewGrantProxy creates a new GrantProxy node.
+func NewGrantProxy(proxied sql.Account, accounts []sql.Account, withGrantOption bool) *GrantProxy {
+	return &GrantProxy{Proxied: proxied, Accounts: accounts, WithGrantOption: withGrantOption}
+}
+
+// Children implements the sql.Node interface.
+func (*GrantProxy) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*GrantProxy) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*GrantProxy) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *GrantProxy) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	pm, err := n.Catalog.ProxyManager()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range n.Accounts {
+		if err := pm.GrantProxy(ctx, n.Proxied, a, n.WithGrantOption); err != nil {
+			return nil, err
+		}
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *GrantProxy) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *GrantProxy) String() string {
+	var withGrantOption string
+	if n.WithGrantOption {
+		withGrantOption = " WITH GRANT OPTION"
+	}
+	return fmt.Sprintf("GRANT PROXY ON %s TO %s%s", n.Proxied, joinAccounts(n.Accounts), withGrantOption)
+}
+
+// RevokeProxy revokes PROXY on an account from one or more accounts.
+type RevokeProxy struct {
+	Proxied  sql.Account
+	Accounts []sql.Account
+	Catalog  *sql.Catalog
+}
+
+var _ sql.Node = (*RevokeProxy)(nil)
+
+// NewRevokeProxy creates a new RevokeProxy node.
+func NewRevokeProxy(proxied sql.Account, accounts []sql.Account) *RevokeProxy {
+	return &RevokeProxy{Proxied: proxied, Accounts: accounts}
+}
+
+// Children implements the sql.Node interface.
+func (*RevokeProxy) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*RevokeProxy) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*RevokeProxy) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *RevokeProxy) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	pm, err := n.Catalog.ProxyManager()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range n.Accounts {
+		if err := pm.RevokeProxy(ctx, n.Proxied, a); err != nil {
+			return nil, err
+		}
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *RevokeProxy) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *RevokeProxy) String() string {
+	return fmt.Sprintf("REVOKE PROXY ON %s FROM %s", n.Proxied, joinAccounts(n.Accounts))
+}
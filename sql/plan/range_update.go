@@ -0,0 +1,105 @@
+package plan
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// rangeUpdateIter returns an iterator with the result of the DELETE or UPDATE given, executed by its table as a range
+// operation, or nil if it can't be. That's the case of the deletions and updates of the rows an index lookup of a table
+// that supports it reads, as long as no foreign key actions apply to them. The outer row given is empty unless the
+// statement is run by a trigger, whose row the expressions would need.
+func rangeUpdateIter(ctx *sql.Context, n sql.Node, row sql.Row) (sql.RowIter, error) {
+	if len(row) > 0 {
+		return nil, nil
+	}
+
+	switch n := n.(type) {
+	case *DeleteFrom:
+		if len(n.Targets) > 0 || n.ForeignKeys != nil && sql.ForeignKeyChecks(ctx) {
+			return nil, nil
+		}
+		table, filter := rangeTable(n.Child)
+		deletable, ok := table.(sql.RangeDeletableTable)
+		if !ok {
+			return nil, nil
+		}
+
+		deleted, err := deletable.DeleteRange(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowsToRowIter(sql.NewRow(sql.NewOkResult(deleted))), nil
+	case *Update:
+		if n.ForeignKeys != nil && sql.ForeignKeyChecks(ctx) {
+			return nil, nil
+		}
+		source, ok := n.Child.(*UpdateSource)
+		if !ok {
+			return nil, nil
+		}
+		table, filter := rangeTable(source.Child)
+		updatable, ok := table.(sql.RangeUpdatableTable)
+		if !ok {
+			return nil, nil
+		}
+
+		schema := updatable.Schema()
+		matched, updated, err := updatable.UpdateRange(ctx, filter, func(oldRow sql.Row) (sql.Row, error) {
+			warnings := ctx.WarningCount()
+			newRow, err := applyUpdateExpressions(ctx, source.UpdateExprs, oldRow)
+			if err != nil {
+				return nil, err
+			}
+			if err = sql.CheckDivisionByZero(ctx, warnings); err != nil {
+				return nil, err
+			}
+
+			newRow, err = applyOnUpdateExpressions(ctx, schema, source.UpdateExprs, oldRow, newRow)
+			if err != nil {
+				return nil, err
+			}
+
+			if equals, err := oldRow.Equals(newRow, schema); err != nil || equals {
+				return newRow, err
+			}
+			return newRow, checkRow(ctx, n.Checks, newRow)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		result := sql.OkResult{
+			RowsAffected: uint64(updated),
+			Info: UpdateInfo{
+				Matched: matched,
+				Updated: updated,
+			},
+		}
+		return sql.RowsToRowIter(sql.NewRow(result)), nil
+	default:
+		return nil, nil
+	}
+}
+
+// rangeTable returns the table of the node given if it reads the rows of an index lookup of it, with the filter of the
+// rows read, or nil if it doesn't.
+func rangeTable(n sql.Node) (sql.Table, sql.Expression) {
+	decorated, ok := n.(*DecoratedNode)
+	if !ok || decorated.DecorationType != DecorationTypeIndexedAccess {
+		return nil, nil
+	}
+
+	var filter sql.Expression
+	child := decorated.Child
+	if f, ok := child.(*Filter); ok {
+		filter = f.Expression
+		child = f.Child
+	}
+
+	rt, ok := child.(*ResolvedTable)
+	if !ok {
+		return nil, nil
+	}
+
+	return unwrapTable(rt.Table), filter
+}
@@ -0,0 +1,222 @@
+package plan
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// tableRename is a single rename of RENAME TABLE, of a table or a view.
+type tableRename struct {
+	oldName, newName string
+	view             bool
+}
+
+// renamedEntity is a table or a view of the database while the renames of RENAME TABLE are planned.
+type renamedEntity struct {
+	name         string
+	originalName string
+	view         bool
+}
+
+// planRenames checks the renames of the node against the tables and views of its database, and returns them with the
+// names the tables and views have in the database. It also returns the new names of the tables renamed, by the
+// lowercase names they had before, which the names they were given in between don't matter for: a table swapped with
+// another through a temporary name is renamed to the name of the other.
+func (r *RenameTable) planRenames(ctx *sql.Context) ([]tableRename, map[string]string, error) {
+	names, err := r.db.GetTableNames(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entities := make(map[string]renamedEntity)
+	for _, name := range names {
+		entities[strings.ToLower(name)] = renamedEntity{name: name, originalName: name}
+	}
+	if ctx.ViewRegistry != nil {
+		for _, view := range ctx.ViewRegistry.ViewsInDatabase(r.db.Name()) {
+			entities[strings.ToLower(view.Name())] = renamedEntity{name: view.Name(), originalName: view.Name(), view: true}
+		}
+	}
+
+	renames := make([]tableRename, len(r.oldNames))
+	for i, oldName := range r.oldNames {
+		entity, ok := entities[strings.ToLower(oldName)]
+		if !ok {
+			return nil, nil, sql.ErrTableNotFound.New(oldName)
+		}
+
+		newName := r.newNames[i]
+		if existing, ok := entities[strings.ToLower(newName)]; ok && existing != entity {
+			return nil, nil, sql.ErrTableAlreadyExists.New(newName)
+		}
+
+		renames[i] = tableRename{oldName: entity.name, newName: newName, view: entity.view}
+		delete(entities, strings.ToLower(oldName))
+		entity.name = newName
+		entities[strings.ToLower(newName)] = entity
+	}
+
+	renamed := make(map[string]string)
+	for _, entity := range entities {
+		if !entity.view && entity.name != entity.originalName {
+			renamed[strings.ToLower(entity.originalName)] = entity.name
+		}
+	}
+
+	return renames, renamed, nil
+}
+
+// renameTables renames the tables given with the renamer given, in a single operation if it's a
+// sql.MultiTableRenamer. Otherwise, the tables are renamed one at a time, and the renames done are undone if one fails.
+func renameTables(ctx *sql.Context, renamer sql.TableRenamer, renames []tableRename) error {
+	if len(renames) == 0 {
+		return nil
+	}
+
+	if multiRenamer, ok := renamer.(sql.MultiTableRenamer); ok {
+		oldNames, newNames := make([]string, len(renames)), make([]string, len(renames))
+		for i, rename := range renames {
+			oldNames[i], newNames[i] = rename.oldName, rename.newName
+		}
+		return multiRenamer.RenameTables(ctx, oldNames, newNames)
+	}
+
+	for i, rename := range renames {
+		err := renamer.RenameTable(ctx, rename.oldName, rename.newName)
+		if err != nil {
+			for j := i - 1; j >= 0; j-- {
+				// The error of the failed rename is more useful than any of undoing the others
+				_ = renamer.RenameTable(ctx, renames[j].newName, renames[j].oldName)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// renameViews renames the views given in the view registry, and in the database given if it stores its views.
+func renameViews(ctx *sql.Context, db sql.Database, renames []tableRename) error {
+	for _, rename := range renames {
+		view, err := ctx.ViewRegistry.View(db.Name(), rename.oldName)
+		if err != nil {
+			return err
+		}
+		if err = ctx.ViewRegistry.Delete(db.Name(), rename.oldName); err != nil {
+			return err
+		}
+
+		if dropper, ok := db.(sql.ViewDropper); ok {
+			if err = dropper.DropView(ctx, rename.oldName); err != nil && !sql.ErrNonExistingView.Is(err) {
+				return err
+			}
+		}
+		if creator, ok := db.(sql.ViewCreator); ok {
+			if err = creator.CreateView(ctx, rename.newName, view.TextDefinition()); err != nil {
+				return err
+			}
+		}
+
+		err = ctx.ViewRegistry.Register(db.Name(), sql.NewView(rename.newName, view.Definition(), view.TextDefinition()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateForeignKeyReferences points the foreign keys of the tables of the database given that reference the tables
+// renamed to their new names, given by their lowercase old names. The foreign keys referencing the tables of other
+// databases are left as they are.
+func updateForeignKeyReferences(ctx *sql.Context, db sql.Database, renamed map[string]string) error {
+	if len(renamed) == 0 {
+		return nil
+	}
+
+	return sql.DBTableIter(ctx, db, func(table sql.Table) (bool, error) {
+		fkTable, ok := table.(sql.ForeignKeyTable)
+		if !ok {
+			return true, nil
+		}
+		fkAlterable, ok := table.(sql.ForeignKeyAlterableTable)
+		if !ok {
+			return true, nil
+		}
+
+		fks, err := fkTable.GetForeignKeys(ctx)
+		if err != nil {
+			return false, err
+		}
+		for _, fk := range fks {
+			if fk.ReferencedDatabase != "" && !strings.EqualFold(fk.ReferencedDatabase, db.Name()) {
+				continue
+			}
+			newName, ok := renamed[strings.ToLower(fk.ReferencedTable)]
+			if !ok {
+				continue
+			}
+			if err = fkAlterable.DropForeignKey(ctx, fk.Name); err != nil {
+				return false, err
+			}
+			err = fkAlterable.CreateForeignKey(ctx, fk.Name, fk.Columns, newName, fk.ReferencedColumns, fk.OnUpdate, fk.OnDelete)
+			if err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// triggerIdentifier matches an identifier, quoted or not.
+const triggerIdentifier = "(?:`(?:[^`]|``)*`|[\\w$]+)"
+
+// triggerTableRegex matches the start of a CREATE TRIGGER statement up to the table of the trigger, and the table,
+// optionally qualified by its database.
+var triggerTableRegex = regexp.MustCompile(`(?is)^(.*?\b(?:before|after)\s+(?:insert|update|delete)\s+on\s+)((?:` +
+	triggerIdentifier + `\s*\.\s*)?(` + triggerIdentifier + `))`)
+
+// updateTriggerTables moves the triggers of the database given on the tables renamed to their new names, given by
+// their lowercase old names. The triggers are created again in the order they had, which the order they're run in
+// depends on.
+func updateTriggerTables(ctx *sql.Context, db sql.Database, renamed map[string]string) error {
+	triggerDb, ok := db.(sql.TriggerDatabase)
+	if !ok || len(renamed) == 0 {
+		return nil
+	}
+
+	triggers, err := triggerDb.GetTriggers(ctx)
+	if err != nil {
+		return err
+	}
+
+	var moved []sql.TriggerDefinition
+	for _, trigger := range triggers {
+		match := triggerTableRegex.FindStringSubmatchIndex(trigger.CreateStatement)
+		if match == nil {
+			continue
+		}
+
+		table := trigger.CreateStatement[match[6]:match[7]]
+		if strings.HasPrefix(table, "`") {
+			table = strings.ReplaceAll(table[1:len(table)-1], "``", "`")
+		}
+		newName, ok := renamed[strings.ToLower(table)]
+		if !ok {
+			continue
+		}
+
+		if err = triggerDb.DropTrigger(ctx, trigger.Name); err != nil {
+			return err
+		}
+		trigger.CreateStatement = trigger.CreateStatement[:match[4]] + quoteIdentifier(newName) + trigger.CreateStatement[match[5]:]
+		moved = append(moved, trigger)
+	}
+
+	for _, trigger := range moved {
+		if err = triggerDb.CreateTrigger(ctx, trigger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
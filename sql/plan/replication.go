@@ -0,0 +1,287 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ChangeReplicationSource is a node that sets the options of the source of the replica of the catalog.
+type ChangeReplicationSource struct {
+	Options []sql.ReplicaOption
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*ChangeReplicationSource)(nil)
+
+// NewChangeReplicationSource returns a new ChangeReplicationSource node.
+func NewChangeReplicationSource(options []sql.ReplicaOption) *ChangeReplicationSource {
+	return &ChangeReplicationSource{Options: options}
+}
+
+// Children implements the sql.Node interface.
+func (*ChangeReplicationSource) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*ChangeReplicationSource) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*ChangeReplicationSource) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *ChangeReplicationSource) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	r, err := replica(n.Catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.ChangeSource(ctx, n.Options); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *ChangeReplicationSource) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface. The password is never shown.
+func (n *ChangeReplicationSource) String() string {
+	options := make([]string, len(n.Options))
+	for i, o := range n.Options {
+		switch v := o.Value.(type) {
+		case string:
+			if o.Name == sql.ReplicaSourcePassword {
+				v = "<secret>"
+			}
+			options[i] = fmt.Sprintf("%s = '%s'", o.Name, v)
+		default:
+			options[i] = fmt.Sprintf("%s = %v", o.Name, v)
+		}
+	}
+	return fmt.Sprintf("CHANGE REPLICATION SOURCE TO %s", strings.Join(options, ", "))
+}
+
+// StartReplica is a node that starts the replica of the catalog.
+type StartReplica struct {
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*StartReplica)(nil)
+
+// NewStartReplica returns a new StartReplica node.
+func NewStartReplica() *StartReplica {
+	return &StartReplica{}
+}
+
+// Children implements the sql.Node interface.
+func (*StartReplica) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*StartReplica) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*StartReplica) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *StartReplica) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	r, err := replica(n.Catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.Start(ctx); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *StartReplica) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (*StartReplica) String() string {
+	return "START REPLICA"
+}
+
+// StopReplica is a node that stops the replica of the catalog.
+type StopReplica struct {
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*StopReplica)(nil)
+
+// NewStopReplica returns a new StopReplica node.
+func NewStopReplica() *StopReplica {
+	return &StopReplica{}
+}
+
+// Children implements the sql.Node interface.
+func (*StopReplica) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*StopReplica) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*StopReplica) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *StopReplica) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	r, err := replica(n.Catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.Stop(ctx); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *StopReplica) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (*StopReplica) String() string {
+	return "STOP REPLICA"
+}
+
+// ShowReplicaStatus is a node that shows the state of the replica of the catalog.
+type ShowReplicaStatus struct {
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*ShowReplicaStatus)(nil)
+
+// NewShowReplicaStatus returns a new ShowReplicaStatus node.
+func NewShowReplicaStatus() *ShowReplicaStatus {
+	return &ShowReplicaStatus{}
+}
+
+// Children implements the sql.Node interface.
+func (*ShowReplicaStatus) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*ShowReplicaStatus) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface. It has the columns of SHOW REPLICA STATUS in MySQL 8.0 that apply to
+// a replica without relay log nor filters.
+func (*ShowReplicaStatus) Schema() sql.Schema {
+	return sql.Schema{
+		&sql.Column{Name: "Replica_IO_State", Type: sql.LongText},
+		&sql.Column{Name: "Source_Host", Type: sql.LongText},
+		&sql.Column{Name: "Source_User", Type: sql.LongText},
+		&sql.Column{Name: "Source_Port", Type: sql.Uint16},
+		&sql.Column{Name: "Connect_Retry", Type: sql.Uint32},
+		&sql.Column{Name: "Source_Log_File", Type: sql.LongText},
+		&sql.Column{Name: "Read_Source_Log_Pos", Type: sql.Uint64},
+		&sql.Column{Name: "Relay_Source_Log_File", Type: sql.LongText},
+		&sql.Column{Name: "Replica_IO_Running", Type: sql.LongText},
+		&sql.Column{Name: "Replica_SQL_Running", Type: sql.LongText},
+		&sql.Column{Name: "Last_Errno", Type: sql.Uint32},
+		&sql.Column{Name: "Last_Error", Type: sql.LongText},
+		&sql.Column{Name: "Exec_Source_Log_Pos", Type: sql.Uint64},
+		&sql.Column{Name: "Last_IO_Errno", Type: sql.Uint32},
+		&sql.Column{Name: "Last_IO_Error", Type: sql.LongText},
+		&sql.Column{Name: "Last_SQL_Errno", Type: sql.Uint32},
+		&sql.Column{Name: "Last_SQL_Error", Type: sql.LongText},
+		&sql.Column{Name: "Source_Server_Id", Type: sql.Uint32},
+		&sql.Column{Name: "Source_UUID", Type: sql.LongText},
+		&sql.Column{Name: "Retrieved_Gtid_Set", Type: sql.LongText},
+		&sql.Column{Name: "Executed_Gtid_Set", Type: sql.LongText},
+		&sql.Column{Name: "Auto_Position", Type: sql.Int8},
+	}
+}
+
+// RowIter implements the sql.Node interface. Like MySQL, it shows no rows when the server isn't a replica.
+func (n *ShowReplicaStatus) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	r, err := replica(n.Catalog)
+	if sql.ErrNoReplica.Is(err) {
+		return sql.RowsToRowIter(), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	s, ok := r.Status()
+	if !ok {
+		return sql.RowsToRowIter(), nil
+	}
+
+	state := ""
+	switch s.IORunning {
+	case "Yes":
+		state = "Waiting for source to send event"
+	case "Connecting":
+		state = "Connecting to source"
+	}
+
+	lastErrno, lastError := s.LastSQLErrno, s.LastSQLError
+	if lastErrno == 0 {
+		lastErrno, lastError = s.LastIOErrno, s.LastIOError
+	}
+
+	var autoPosition int8
+	if s.AutoPosition {
+		autoPosition = 1
+	}
+
+	return sql.RowsToRowIter(sql.NewRow(
+		state,
+		s.SourceHost,
+		s.SourceUser,
+		s.SourcePort,
+		s.ConnectRetry,
+		s.SourceLogFile,
+		s.ReadSourceLogPos,
+		s.ExecSourceLogFile,
+		s.IORunning,
+		s.SQLRunning,
+		lastErrno,
+		lastError,
+		s.ExecSourceLogPos,
+		s.LastIOErrno,
+		s.LastIOError,
+		s.LastSQLErrno,
+		s.LastSQLError,
+		s.SourceServerID,
+		s.SourceUUID,
+		s.RetrievedGTIDSet,
+		s.ExecutedGTIDSet,
+		autoPosition,
+	)), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *ShowReplicaStatus) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (*ShowReplicaStatus) String() string {
+	return "SHOW REPLICA STATUS"
+}
+
+func replica(c *sql.Catalog) (sql.Replica, error) {
+	if c == nil {
+		return nil, sql.ErrNoReplica.New()
+	}
+	return c.Replica()
+}
@@ -0,0 +1,76 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ResetPersist removes the persisted value of a system variable, or of all of them if it has no name.
+type ResetPersist struct {
+	Name     string
+	IfExists bool
+	Catalog  *sql.Catalog
+}
+
+var _ sql.Node = (*ResetPersist)(nil)
+
+// NewResetPersist creates a new ResetPersist node.
+func NewResetPersist(name string, ifExists bool) *ResetPersist {
+	return &ResetPersist{Name: name, IfExists: ifExists}
+}
+
+// Children implements the sql.Node interface.
+func (*ResetPersist) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*ResetPersist) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*ResetPersist) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *ResetPersist) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	p, err := n.Catalog.VariablePersister()
+	if err != nil {
+		return nil, err
+	}
+
+	if n.Name == "" {
+		return sql.RowsToRowIter(), p.UnpersistAll(ctx)
+	}
+
+	ok, err := p.Unpersist(ctx, n.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok && n.IfExists {
+		ctx.Warn(3615, "Variable %s does not exist in persisted config file", n.Name)
+	} else if !ok {
+		return nil, sql.ErrVariableNotPersisted.New(n.Name)
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *ResetPersist) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *ResetPersist) String() string {
+	if n.Name == "" {
+		return "RESET PERSIST"
+	}
+
+	var ifExists string
+	if n.IfExists {
+		ifExists = "IF EXISTS "
+	}
+	return fmt.Sprintf("RESET PERSIST %s%s", ifExists, n.Name)
+}
@@ -9,13 +9,30 @@ import (
 // ResolvedTable represents a resolved SQL Table.
 type ResolvedTable struct {
 	sql.Table
+	// Database is the name of the database of the table, or empty if the table isn't in one, as the tables of
+	// information_schema, or isn't from the catalog.
+	Database string
 }
 
 var _ sql.Node = (*ResolvedTable)(nil)
 
 // NewResolvedTable creates a new instance of ResolvedTable.
 func NewResolvedTable(table sql.Table) *ResolvedTable {
-	return &ResolvedTable{table}
+	return &ResolvedTable{Table: table}
+}
+
+// WithDatabase returns a copy of this node for the table in the database with the name given.
+func (t *ResolvedTable) WithDatabase(db string) *ResolvedTable {
+	nt := *t
+	nt.Database = db
+	return &nt
+}
+
+// WithTable returns a copy of this node for the table given, which replaces the one of this node in its database.
+func (t *ResolvedTable) WithTable(table sql.Table) *ResolvedTable {
+	nt := *t
+	nt.Table = table
+	return &nt
 }
 
 // Resolved implements the Resolvable interface.
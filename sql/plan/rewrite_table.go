@@ -0,0 +1,177 @@
+package plan
+
+import (
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// rewritingTable is a sql.RewritableTable altered by rewriting its rows with the new schema.
+type rewritingTable struct {
+	sql.RewritableTable
+}
+
+var _ sql.AlterableTable = (*rewritingTable)(nil)
+
+// AddColumn implements the sql.AlterableTable interface.
+func (t *rewritingTable) AddColumn(ctx *sql.Context, column *sql.Column, order *sql.ColumnOrder) error {
+	schema, sources := t.columns(-1)
+	if order == nil {
+		order = &sql.ColumnOrder{}
+		if len(schema) > 0 {
+			order.AfterColumn = schema[len(schema)-1].Name
+		}
+	}
+
+	schema, sources = insertColumn(schema, sources, column, -1, order)
+	return t.rewrite(ctx, schema, sources, nil, column)
+}
+
+// DropColumn implements the sql.AlterableTable interface.
+func (t *rewritingTable) DropColumn(ctx *sql.Context, columnName string) error {
+	idx := t.Schema().IndexOf(columnName, t.Name())
+	if idx < 0 {
+		return sql.ErrTableColumnNotFound.New(t.Name(), columnName)
+	}
+
+	schema, sources := t.columns(idx)
+	return t.rewrite(ctx, schema, sources, t.Schema()[idx], nil)
+}
+
+// ModifyColumn implements the sql.AlterableTable interface.
+func (t *rewritingTable) ModifyColumn(ctx *sql.Context, columnName string, column *sql.Column, order *sql.ColumnOrder) error {
+	idx := t.Schema().IndexOf(columnName, t.Name())
+	if idx < 0 {
+		return sql.ErrTableColumnNotFound.New(t.Name(), columnName)
+	}
+
+	schema, sources := t.columns(idx)
+	if order == nil {
+		order = &sql.ColumnOrder{First: idx == 0}
+		if idx > 0 {
+			order.AfterColumn = t.Schema()[idx-1].Name
+		}
+	}
+
+	schema, sources = insertColumn(schema, sources, column, idx, order)
+	return t.rewrite(ctx, schema, sources, t.Schema()[idx], column)
+}
+
+// columns returns copies of the columns of the table but the one at the position given, and their positions.
+func (t *rewritingTable) columns(except int) (sql.Schema, []int) {
+	var schema sql.Schema
+	var sources []int
+	for i, col := range t.Schema() {
+		if i != except {
+			nc := *col
+			schema = append(schema, &nc)
+			sources = append(sources, i)
+		}
+	}
+	return schema, sources
+}
+
+// insertColumn inserts a column in a schema in the position given, with the position of its values in the rows of the
+// table, or -1 if it's new, returning the new schema and the positions of its columns.
+func insertColumn(schema sql.Schema, sources []int, column *sql.Column, source int, order *sql.ColumnOrder) (sql.Schema, []int) {
+	i := 0
+	if !order.First {
+		for i < len(schema) && schema[i].Name != order.AfterColumn {
+			i++
+		}
+		i++
+		if i > len(schema) {
+			i = len(schema)
+		}
+	}
+
+	nc := *column
+	newSchema := append(append(append(sql.Schema{}, schema[:i]...), &nc), schema[i:]...)
+	newSources := append(append(append([]int{}, sources[:i]...), source), sources[i:]...)
+	return newSchema, newSources
+}
+
+// rewrite rewrites the rows of the table with the new schema given, whose columns have the values of the columns of
+// the table at the positions given, or their default values if they're new.
+func (t *rewritingTable) rewrite(ctx *sql.Context, schema sql.Schema, sources []int, oldColumn, newColumn *sql.Column) error {
+	for _, col := range schema {
+		col.Source = t.Name()
+		if col.Default == nil {
+			continue
+		}
+
+		// The defaults referencing other columns point to their new positions.
+		def, err := expression.TransformUp(col.Default, func(e sql.Expression) (sql.Expression, error) {
+			if gf, ok := e.(*expression.GetField); ok {
+				if idx := schema.IndexOf(gf.Name(), t.Name()); idx >= 0 {
+					return gf.WithIndex(idx), nil
+				}
+			}
+			return e, nil
+		})
+		if err != nil {
+			return err
+		}
+		col.Default = def.(*sql.ColumnDefaultValue)
+	}
+
+	rewriter, err := t.Rewriter(ctx, schema, oldColumn, newColumn)
+	if err != nil {
+		return err
+	}
+
+	if err := t.copyRows(ctx, rewriter, schema, sources); err != nil {
+		_ = rewriter.Discard(ctx)
+		return err
+	}
+	return rewriter.Close(ctx)
+}
+
+// copyRows inserts the rows of the table converted to the new schema in the rewriter.
+func (t *rewritingTable) copyRows(ctx *sql.Context, rewriter sql.TableRewriter, schema sql.Schema, sources []int) (err error) {
+	iter, err := NewResolvedTable(t.RewritableTable).RowIter(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := iter.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		newRow := make(sql.Row, len(schema))
+		for i, source := range sources {
+			if source < 0 || row[source] == nil {
+				continue
+			}
+			if newRow[i], err = schema[i].Type.Convert(row[source]); err != nil {
+				return err
+			}
+		}
+
+		for i, source := range sources {
+			if source < 0 && schema[i].Default != nil {
+				if newRow[i], err = schema[i].Default.Eval(ctx, newRow); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := validateNullability(schema, newRow); err != nil {
+			return err
+		}
+		if err := rewriter.Insert(ctx, newRow); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// rewritableTable is a table that can only be altered by rewriting it, replacing the memory table it reads.
+type rewritableTable struct {
+	table    *memory.Table
+	rewrites []string
+}
+
+var _ sql.RewritableTable = (*rewritableTable)(nil)
+
+func (t *rewritableTable) Name() string {
+	return t.table.Name()
+}
+
+func (t *rewritableTable) Schema() sql.Schema {
+	return t.table.Schema()
+}
+
+func (t *rewritableTable) Partitions(ctx *sql.Context) (sql.PartitionIter, error) {
+	return t.table.Partitions(ctx)
+}
+
+func (t *rewritableTable) PartitionRows(ctx *sql.Context, p sql.Partition) (sql.RowIter, error) {
+	return t.table.PartitionRows(ctx, p)
+}
+
+func (t *rewritableTable) String() string {
+	return t.table.String()
+}
+
+func (t *rewritableTable) Rewriter(ctx *sql.Context, schema sql.Schema, oldColumn, newColumn *sql.Column) (sql.TableRewriter, error) {
+	var old, new string
+	if oldColumn != nil {
+		old = oldColumn.Name
+	}
+	if newColumn != nil {
+		new = newColumn.Name
+	}
+	t.rewrites = append(t.rewrites, old+"->"+new)
+	return &tableRewriter{t, memory.NewTable(t.Name(), schema)}, nil
+}
+
+type tableRewriter struct {
+	table *rewritableTable
+	new   *memory.Table
+}
+
+func (r *tableRewriter) Insert(ctx *sql.Context, row sql.Row) error {
+	return r.new.Insert(ctx, row)
+}
+
+func (r *tableRewriter) Close(*sql.Context) error {
+	r.table.table = r.new
+	return nil
+}
+
+func (r *tableRewriter) Discard(*sql.Context) error {
+	return nil
+}
+
+func TestRewriteTable(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := &rewritableTable{table: memory.NewTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t", Nullable: true},
+	})}
+	require.NoError(table.table.Insert(ctx, sql.NewRow(int64(1), "1")))
+	require.NoError(table.table.Insert(ctx, sql.NewRow(int64(2), nil)))
+
+	db := memory.NewDatabase("db")
+	db.AddTable("t", table)
+
+	run := func(node sql.Node) error {
+		iter, err := node.RowIter(ctx, nil)
+		if err != nil {
+			return err
+		}
+		_, err = sql.RowIterToRows(iter)
+		return err
+	}
+
+	def, err := sql.NewColumnDefaultValue(expression.NewLiteral(int8(5), sql.Int8), sql.Int8, true, false)
+	require.NoError(err)
+	require.NoError(run(NewAddColumn(db, "t", &sql.Column{Name: "n", Type: sql.Int8, Default: def}, &sql.ColumnOrder{First: true})))
+	require.Equal([]string{"n", "i", "s"}, columnNames(table.Schema()))
+	require.Equal([]sql.Row{{int8(5), int64(1), "1"}, {int8(5), int64(2), nil}}, collectRows(t, NewResolvedTable(table)))
+
+	require.NoError(run(NewModifyColumn(db, "t", "s", &sql.Column{Name: "s", Type: sql.Int32, Nullable: true}, nil)))
+	require.Equal([]string{"n", "i", "s"}, columnNames(table.Schema()))
+	require.Equal([]sql.Row{{int8(5), int64(1), int32(1)}, {int8(5), int64(2), nil}}, collectRows(t, NewResolvedTable(table)))
+
+	require.NoError(run(NewRenameColumn(db, "t", "s", "x")))
+	require.NoError(run(NewDropColumn(db, "t", "n")))
+	require.Equal([]string{"i", "x"}, columnNames(table.Schema()))
+	require.Equal([]sql.Row{{int64(1), int32(1)}, {int64(2), nil}}, collectRows(t, NewResolvedTable(table)))
+	require.Equal([]string{"->n", "s->s", "s->x", "n->"}, table.rewrites)
+
+	// The table is left as it was if a row can't be rewritten.
+	err = run(NewModifyColumn(db, "t", "x", &sql.Column{Name: "x", Type: sql.Int32}, nil))
+	require.True(ErrInsertIntoNonNullableProvidedNull.Is(err), "%v", err)
+	require.Equal([]sql.Row{{int64(1), int32(1)}, {int64(2), nil}}, collectRows(t, NewResolvedTable(table)))
+}
+
+func columnNames(schema sql.Schema) []string {
+	var names []string
+	for _, col := range schema {
+		names = append(names, col.Name)
+	}
+	return names
+}
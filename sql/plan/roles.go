@@ -0,0 +1,444 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// CreateRole creates one or more roles.
+type CreateRole struct {
+	Roles       []sql.Account
+	IfNotExists bool
+	Catalog     *sql.Catalog
+}
+
+var _ sql.Node = (*CreateRole)(nil)
+
+// NewCreateRole creates a new CreateRole node.
+func NewCreateRole(roles []sql.Account, ifNotExists bool) *CreateRole {
+	return &CreateRole{Roles: roles, IfNotExists: ifNotExists}
+}
+
+// Children implements the sql.Node interface.
+func (*CreateRole) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*CreateRole) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*CreateRole) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *CreateRole) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	rm, err := n.Catalog.RoleManager()
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []sql.Account
+	for _, r := range n.Roles {
+		err := rm.CreateRole(ctx, r)
+		if sql.ErrUserAlreadyExists.Is(err) && n.IfNotExists {
+			ctx.Note(3163, "Authorization ID %s already exists.", r)
+		} else if err != nil {
+			failed = append(failed, r)
+		}
+	}
+
+	if len(failed) > 0 {
+		return nil, sql.ErrUserOperationFailed.New("CREATE ROLE", joinAccounts(failed))
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *CreateRole) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *CreateRole) String() string {
+	var ifNotExists string
+	if n.IfNotExists {
+		ifNotExists = "IF NOT EXISTS "
+	}
+	return fmt.Sprintf("CREATE ROLE %s%s", ifNotExists, joinAccounts(n.Roles))
+}
+
+// DropRole removes one or more roles.
+type DropRole struct {
+	Roles    []sql.Account
+	IfExists bool
+	Catalog  *sql.Catalog
+}
+
+var _ sql.Node = (*DropRole)(nil)
+
+// NewDropRole creates a new DropRole node.
+func NewDropRole(roles []sql.Account, ifExists bool) *DropRole {
+	return &DropRole{Roles: roles, IfExists: ifExists}
+}
+
+// Children implements the sql.Node interface.
+func (*DropRole) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*DropRole) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*DropRole) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *DropRole) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	rm, err := n.Catalog.RoleManager()
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []sql.Account
+	for _, r := range n.Roles {
+		err := rm.DropRole(ctx, r)
+		if sql.ErrUserNotFound.Is(err) && n.IfExists {
+			ctx.Note(3162, "Authorization ID %s does not exist.", r)
+		} else if err != nil {
+			failed = append(failed, r)
+		}
+	}
+
+	if len(failed) > 0 {
+		return nil, sql.ErrUserOperationFailed.New("DROP ROLE", joinAccounts(failed))
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *DropRole) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *DropRole) String() string {
+	var ifExists string
+	if n.IfExists {
+		ifExists = "IF EXISTS "
+	}
+	return fmt.Sprintf("DROP ROLE %s%s", ifExists, joinAccounts(n.Roles))
+}
+
+// GrantRole grants one or more roles to one or more accounts.
+type GrantRole struct {
+	Roles           []sql.Account
+	Accounts        []sql.Account
+	WithAdminOption bool
+	Catalog         *sql.Catalog
+}
+
+var _ sql.Node = (*GrantRole)(nil)
+
+// NewGrantRole creates a new GrantRole node.
+func NewGrantRole(roles, accounts []sql.Account, withAdminOption bool) *GrantRole {
+	return &GrantRole{Roles: roles, Accounts: accounts, WithAdminOption: withAdminOption}
+}
+
+// Children implements the sql.Node interface.
+func (*GrantRole) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*GrantRole) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*GrantRole) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *GrantRole) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	rm, err := n.Catalog.RoleManager()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range n.Accounts {
+		for _, r := range n.Roles {
+			if err := rm.GrantRole(ctx, r, a, n.WithAdminOption); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *GrantRole) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *GrantRole) String() string {
+	var withAdminOption string
+	if n.WithAdminOption {
+		withAdminOption = " WITH ADMIN OPTION"
+	}
+	return fmt.Sprintf("GRANT %s TO %s%s", joinAccounts(n.Roles), joinAccounts(n.Accounts), withAdminOption)
+}
+
+// RevokeRole revokes one or more roles from one or more accounts.
+type RevokeRole struct {
+	Roles    []sql.Account
+	Accounts []sql.Account
+	Catalog  *sql.Catalog
+}
+
+var _ sql.Node = (*RevokeRole)(nil)
+
+// NewRevokeRole creates a new RevokeRole node.
+func NewRevokeRole(roles, accounts []sql.Account) *RevokeRole {
+	return &RevokeRole{Roles: roles, Accounts: accounts}
+}
+
+// Children implements the sql.Node interface.
+func (*RevokeRole) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*RevokeRole) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*RevokeRole) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *RevokeRole) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	rm, err := n.Catalog.RoleManager()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range n.Accounts {
+		for _, r := range n.Roles {
+			if err := rm.RevokeRole(ctx, r, a); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *RevokeRole) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *RevokeRole) String() string {
+	return fmt.Sprintf("REVOKE %s FROM %s", joinAccounts(n.Roles), joinAccounts(n.Accounts))
+}
+
+// RoleSpec selects roles in SET ROLE and SET DEFAULT ROLE statements.
+type RoleSpec byte
+
+const (
+	// RoleList selects the roles given.
+	RoleList RoleSpec = iota
+	// RoleNone selects no roles.
+	RoleNone
+	// RoleAll selects all the roles granted to the account, except the ones given.
+	RoleAll
+	// RoleDefault selects the default roles of the account. It's only valid in SET ROLE.
+	RoleDefault
+)
+
+// selectRoles returns the roles selected from the ones granted to an account.
+func selectRoles(ctx *sql.Context, rm sql.RoleManager, account sql.Account, spec RoleSpec, roles []sql.Account) ([]sql.Account, error) {
+	granted, err := rm.Roles(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	isGranted := func(role sql.Account) bool {
+		for _, g := range granted {
+			if g.Role.Equal(role) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch spec {
+	case RoleNone:
+		return nil, nil
+	case RoleDefault:
+		return rm.DefaultRoles(ctx, account)
+	case RoleAll:
+		var selected []sql.Account
+		for _, g := range granted {
+			var excepted bool
+			for _, r := range roles {
+				excepted = excepted || r.Equal(g.Role)
+			}
+
+			if !excepted {
+				selected = append(selected, g.Role)
+			}
+		}
+		return selected, nil
+	default:
+		for _, r := range roles {
+			if !isGranted(r) {
+				return nil, sql.ErrRoleNotGranted.New(r, account)
+			}
+		}
+		return roles, nil
+	}
+}
+
+func roleSpecString(spec RoleSpec, roles []sql.Account) string {
+	switch spec {
+	case RoleNone:
+		return "NONE"
+	case RoleDefault:
+		return "DEFAULT"
+	case RoleAll:
+		if len(roles) > 0 {
+			return "ALL EXCEPT " + joinAccounts(roles)
+		}
+		return "ALL"
+	default:
+		return joinAccounts(roles)
+	}
+}
+
+// SetRole changes the roles active in the current session.
+type SetRole struct {
+	Spec RoleSpec
+	// Roles are the roles activated by RoleList, or the ones excepted by
+	// RoleAll.
+	Roles   []sql.Account
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*SetRole)(nil)
+
+// NewSetRole creates a new SetRole node.
+func NewSetRole(spec RoleSpec, roles []sql.Account) *SetRole {
+	return &SetRole{Spec: spec, Roles: roles}
+}
+
+// Children implements the sql.Node interface.
+func (*SetRole) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*SetRole) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*SetRole) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *SetRole) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	rm, err := n.Catalog.RoleManager()
+	if err != nil {
+		return nil, err
+	}
+
+	session, ok := ctx.Session.(sql.RoleSession)
+	if !ok {
+		return nil, sql.ErrRolesNotSupported.New()
+	}
+
+	account, err := rm.CurrentAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := selectRoles(ctx, rm, account, n.Spec, n.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	session.SetActiveRoles(roles)
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *SetRole) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *SetRole) String() string {
+	return "SET ROLE " + roleSpecString(n.Spec, n.Roles)
+}
+
+// SetDefaultRole sets the roles activated when one or more accounts log in.
+type SetDefaultRole struct {
+	Spec     RoleSpec
+	Roles    []sql.Account
+	Accounts []sql.Account
+	Catalog  *sql.Catalog
+}
+
+var _ sql.Node = (*SetDefaultRole)(nil)
+
+// NewSetDefaultRole creates a new SetDefaultRole node.
+func NewSetDefaultRole(spec RoleSpec, roles, accounts []sql.Account) *SetDefaultRole {
+	return &SetDefaultRole{Spec: spec, Roles: roles, Accounts: accounts}
+}
+
+// Children implements the sql.Node interface.
+func (*SetDefaultRole) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*SetDefaultRole) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*SetDefaultRole) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *SetDefaultRole) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	rm, err := n.Catalog.RoleManager()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range n.Accounts {
+		roles, err := selectRoles(ctx, rm, a, n.Spec, n.Roles)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := rm.SetDefaultRoles(ctx, a, roles); err != nil {
+			return nil, err
+		}
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *SetDefaultRole) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *SetDefaultRole) String() string {
+	return fmt.Sprintf("SET DEFAULT ROLE %s TO %s", roleSpecString(n.Spec, n.Roles), joinAccounts(n.Accounts))
+}
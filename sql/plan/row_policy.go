@@ -0,0 +1,105 @@
+package plan
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// RowPolicyCheck checks that the rows written to a table satisfy its row filters, returning
+// sql.ErrRowPolicyViolation for the first one that doesn't. Its child is the source of the rows of an insert, or of
+// the old and new rows of an update, and the predicate is evaluated on the last values of each row.
+type RowPolicyCheck struct {
+	UnaryNode
+	Table     string
+	Predicate sql.Expression
+}
+
+var _ sql.Node = (*RowPolicyCheck)(nil)
+
+// NewRowPolicyCheck returns a new RowPolicyCheck of the rows of the node given, with a predicate already resolved
+// against the schema of the table they are written to.
+func NewRowPolicyCheck(table string, predicate sql.Expression, child sql.Node) *RowPolicyCheck {
+	return &RowPolicyCheck{
+		UnaryNode: UnaryNode{Child: child},
+		Table:     table,
+		Predicate: predicate,
+	}
+}
+
+// Resolved implements the Resolvable interface.
+func (c *RowPolicyCheck) Resolved() bool {
+	return c.Child.Resolved() && c.Predicate.Resolved()
+}
+
+// RowIter implements the Node interface.
+func (c *RowPolicyCheck) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	iter, err := c.Child.RowIter(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rowPolicyCheckIter{
+		ctx:       ctx,
+		table:     c.Table,
+		predicate: c.Predicate,
+		width:     len(c.Child.Schema()),
+		childIter: iter,
+	}, nil
+}
+
+// WithChildren implements the Node interface.
+func (c *RowPolicyCheck) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(c, len(children), 1)
+	}
+
+	return NewRowPolicyCheck(c.Table, c.Predicate, children[0]), nil
+}
+
+func (c *RowPolicyCheck) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("RowPolicyCheck(%s)", c.Predicate)
+	_ = pr.WriteChildren(c.Child.String())
+	return pr.String()
+}
+
+func (c *RowPolicyCheck) DebugString() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("RowPolicyCheck(%s)", sql.DebugString(c.Predicate))
+	_ = pr.WriteChildren(sql.DebugString(c.Child))
+	return pr.String()
+}
+
+type rowPolicyCheckIter struct {
+	ctx       *sql.Context
+	table     string
+	predicate sql.Expression
+	width     int
+	childIter sql.RowIter
+}
+
+func (i *rowPolicyCheckIter) Next() (sql.Row, error) {
+	row, err := i.childIter.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows can have the values of an outer scope prepended, as in trigger bodies.
+	checked := row
+	if len(checked) > i.width {
+		checked = checked[len(checked)-i.width:]
+	}
+
+	ok, err := sql.EvaluateCondition(i.ctx, i.predicate, checked)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, sql.ErrRowPolicyViolation.New(i.table)
+	}
+
+	return row, nil
+}
+
+func (i *rowPolicyCheckIter) Close() error {
+	return i.childIter.Close()
+}
@@ -20,6 +20,7 @@ import (
 	"sync"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
 )
 
 type RowUpdateType int
@@ -188,9 +189,12 @@ func (u *deleteRowHandler) okResult() sql.OkResult {
 }
 
 type accumulatorIter struct {
+	ctx              *sql.Context
 	iter             sql.RowIter
 	once             sync.Once
 	updateRowHandler accumulatorRowHandler
+	// autoIncrement generates the values of the AUTO_INCREMENT column of the rows inserted, if they have one.
+	autoIncrement *expression.AutoIncrement
 }
 
 func (a *accumulatorIter) Next() (sql.Row, error) {
@@ -206,7 +210,7 @@ func (a *accumulatorIter) Next() (sql.Row, error) {
 	for {
 		row, err := a.iter.Next()
 		if err == io.EOF {
-			return sql.NewRow(a.updateRowHandler.okResult()), nil
+			return sql.NewRow(a.okResult()), nil
 		}
 
 		if err != nil {
@@ -220,11 +224,42 @@ func (a *accumulatorIter) Next() (sql.Row, error) {
 	}
 }
 
+// okResult returns the result of the rows updated, with the first value generated for the AUTO_INCREMENT column of
+// the rows inserted as its insert ID, which is kept as the last one of the session.
+func (a *accumulatorIter) okResult() sql.OkResult {
+	result := a.updateRowHandler.okResult()
+	if a.autoIncrement == nil || a.autoIncrement.FirstGenerated() == nil {
+		return result
+	}
+
+	id, err := sql.Uint64.Convert(a.autoIncrement.FirstGenerated())
+	if err != nil {
+		return result
+	}
+	result.InsertID = id.(uint64)
+	sql.SetLastQueryInfo(a.ctx, sql.LastInsertID, int64(result.InsertID))
+	return result
+}
+
 func (a *accumulatorIter) Close() error {
 	return a.iter.Close()
 }
 
 func (r RowUpdateAccumulator) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	// Deletions and updates of ranges, and copies of the rows of tables, are done by the tables that support it, with no
+	// rows to accumulate
+	var pushedDown sql.RowIter
+	var err error
+	switch r.RowUpdateType {
+	case UpdateTypeDelete, UpdateTypeUpdate:
+		pushedDown, err = rangeUpdateIter(ctx, r.Child, row)
+	case UpdateTypeInsert:
+		pushedDown, err = copyRowsIter(ctx, r.Child, row)
+	}
+	if pushedDown != nil || err != nil {
+		return pushedDown, err
+	}
+
 	rowIter, err := r.Child.RowIter(ctx, row)
 	if err != nil {
 		return nil, err
@@ -249,8 +284,27 @@ func (r RowUpdateAccumulator) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIte
 		panic(fmt.Sprintf("Unrecognized RowUpdateType %d", r.RowUpdateType))
 	}
 
-	return &accumulatorIter{
+	iter := &accumulatorIter{
+		ctx:              ctx,
 		iter:             rowIter,
 		updateRowHandler: rowHandler,
-	}, nil
+	}
+	// Deletions and updates have no insert ID, even if their triggers insert rows
+	if r.RowUpdateType != UpdateTypeDelete && r.RowUpdateType != UpdateTypeUpdate {
+		iter.autoIncrement = findAutoIncrement(r.Child)
+	}
+	return iter, nil
+}
+
+// findAutoIncrement returns the expression generating the values of the AUTO_INCREMENT column of the rows inserted by
+// the node given, or nil if there's none.
+func findAutoIncrement(n sql.Node) *expression.AutoIncrement {
+	var autoIncrement *expression.AutoIncrement
+	InspectExpressions(n, func(e sql.Expression) bool {
+		if ai, ok := e.(*expression.AutoIncrement); ok {
+			autoIncrement = ai
+		}
+		return autoIncrement == nil
+	})
+	return autoIncrement
 }
@@ -0,0 +1,185 @@
+package plan
+
+import (
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// NotifySchemaChanges returns the node to run for a statement instead of its analyzed node, so that the schema
+// listeners of the catalog are notified of the changes the statement makes to databases, tables and indexes once it
+// has succeeded. The changes are worked out before the statement runs, so the tables that CREATE TABLE IF NOT
+// EXISTS and DROP TABLE IF EXISTS skip aren't notified.
+func NotifySchemaChanges(ctx *sql.Context, catalog *sql.Catalog, n sql.Node) sql.Node {
+	listeners := catalog.SchemaListeners()
+	if len(listeners) == 0 {
+		return n
+	}
+
+	changes, err := schemaChanges(ctx, catalog, n)
+	if err != nil || len(changes) == 0 {
+		// The statement fails with the same error once it runs.
+		return n
+	}
+
+	return &schemaChangesNode{UnaryNode: UnaryNode{Child: n}, listeners: listeners, changes: changes}
+}
+
+// schemaChanges returns the changes that a statement is about to make to databases, tables and indexes.
+func schemaChanges(ctx *sql.Context, catalog *sql.Catalog, n sql.Node) ([]sql.SchemaChange, error) {
+	if qp, ok := n.(*QueryProcess); ok {
+		n = qp.Child
+	}
+
+	switch n := n.(type) {
+	case *CreateDatabase:
+		if catalog.HasDB(n.Name) {
+			return nil, nil
+		}
+		return []sql.SchemaChange{{Type: sql.DatabaseCreated, Database: n.Name}}, nil
+	case *AlterDatabase:
+		return []sql.SchemaChange{{Type: sql.DatabaseAltered, Database: n.db.Name()}}, nil
+	case *CreateTable:
+		_, ok, err := n.db.GetTableInsensitive(ctx, n.name)
+		if err != nil || ok {
+			return nil, err
+		}
+		return []sql.SchemaChange{{Type: sql.TableCreated, Database: n.db.Name(), Table: n.name}}, nil
+	case *DropTable:
+		var changes []sql.SchemaChange
+		for _, name := range n.names {
+			table, ok, err := n.db.GetTableInsensitive(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				changes = append(changes, sql.SchemaChange{Type: sql.TableDropped, Database: n.db.Name(), Table: table.Name()})
+			}
+		}
+		return changes, nil
+	case *RenameTable:
+		changes := make([]sql.SchemaChange, len(n.oldNames))
+		for i := range n.oldNames {
+			changes[i] = sql.SchemaChange{Type: sql.TableRenamed, Database: n.db.Name(), Table: n.oldNames[i], NewName: n.newNames[i]}
+		}
+		return changes, nil
+	case *AddColumn:
+		return tableAltered(n.db.Name(), n.tableName), nil
+	case *DropColumn:
+		return tableAltered(n.db.Name(), n.tableName), nil
+	case *RenameColumn:
+		return tableAltered(n.db.Name(), n.tableName), nil
+	case *ModifyColumn:
+		return tableAltered(n.db.Name(), n.tableName), nil
+	case *AlterColumnVisibility:
+		return tableAltered(n.db.Name(), n.tableName), nil
+	case *CreateForeignKey:
+		db, table := changedTable(ctx, n.left)
+		return tableAltered(db, table), nil
+	case *AlterAutoIncrement, *DropForeignKey, *CreateCheck, *DropCheck, *DropConstraint:
+		db, table := changedTable(ctx, n)
+		return tableAltered(db, table), nil
+	case *CreateIndex:
+		_, table := changedTable(ctx, n.Table)
+		return []sql.SchemaChange{{Type: sql.IndexCreated, Database: n.CurrentDatabase, Table: table, Index: n.Name}}, nil
+	case *DropIndex:
+		_, table := changedTable(ctx, n.Table)
+		return []sql.SchemaChange{{Type: sql.IndexDropped, Database: n.CurrentDatabase, Table: table, Index: n.Name}}, nil
+	case *AlterIndex:
+		db, table := changedTable(ctx, n.Table)
+		change := sql.SchemaChange{Database: db, Table: table, Index: n.IndexName}
+		switch n.Action {
+		case IndexAction_Create:
+			change.Type = sql.IndexCreated
+		case IndexAction_Drop:
+			change.Type = sql.IndexDropped
+		case IndexAction_Rename:
+			change.Type = sql.IndexAltered
+			change.Index = n.PreviousIndexName
+			change.NewName = n.IndexName
+		default:
+			change.Type = sql.IndexAltered
+		}
+		return []sql.SchemaChange{change}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// tableAltered returns the change of a table altered by ALTER TABLE.
+func tableAltered(db, table string) []sql.SchemaChange {
+	if table == "" {
+		return nil
+	}
+	return []sql.SchemaChange{{Type: sql.TableAltered, Database: db, Table: table}}
+}
+
+// changedTable returns the database and the name of the first table of a node, with the current database as the
+// database of tables that don't have one.
+func changedTable(ctx *sql.Context, n sql.Node) (db, table string) {
+	Inspect(n, func(n sql.Node) bool {
+		if rt, ok := n.(*ResolvedTable); ok && table == "" {
+			db, table = rt.Database, rt.Name()
+		}
+		return table == ""
+	})
+	if db == "" {
+		db = ctx.GetCurrentDatabase()
+	}
+	return db, table
+}
+
+// schemaChangesNode notifies the schema listeners of the changes of a statement once it has succeeded.
+type schemaChangesNode struct {
+	UnaryNode
+	listeners []sql.SchemaListener
+	changes   []sql.SchemaChange
+}
+
+func (n *schemaChangesNode) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	iter, err := n.Child.RowIter(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	return &schemaChangesIter{ctx: ctx, iter: iter, node: n}, nil
+}
+
+func (n *schemaChangesNode) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 1)
+	}
+	nn := *n
+	nn.Child = children[0]
+	return &nn, nil
+}
+
+func (n *schemaChangesNode) String() string {
+	return n.Child.String()
+}
+
+type schemaChangesIter struct {
+	ctx    *sql.Context
+	iter   sql.RowIter
+	node   *schemaChangesNode
+	failed bool
+}
+
+func (i *schemaChangesIter) Next() (sql.Row, error) {
+	row, err := i.iter.Next()
+	if err != nil && err != io.EOF {
+		i.failed = true
+	}
+	return row, err
+}
+
+func (i *schemaChangesIter) Close() error {
+	err := i.iter.Close()
+	if err == nil && !i.failed {
+		for _, change := range i.node.changes {
+			for _, l := range i.node.listeners {
+				l.SchemaChanged(i.ctx, change)
+			}
+		}
+	}
+	return err
+}
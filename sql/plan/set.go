@@ -11,6 +11,9 @@ import (
 // Set represents a set statement. This can be variables, but in some instances can also refer to row values.
 type Set struct {
 	Exprs []sql.Expression
+	// Catalog has the VariablePersister persisting the variables set with
+	// SET PERSIST and SET PERSIST_ONLY.
+	Catalog *sql.Catalog
 }
 
 // NewSet creates a new Set node.
@@ -46,7 +49,9 @@ func (s *Set) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
 		return nil, sql.ErrInvalidChildrenNumber.New(s, len(exprs), len(s.Exprs))
 	}
 
-	return NewSet(exprs), nil
+	ns := *s
+	ns.Exprs = exprs
+	return &ns, nil
 }
 
 // Expressions implements the sql.Expressioner interface.
@@ -68,7 +73,7 @@ func (s *Set) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 
 		switch left := setField.Left.(type) {
 		case *expression.SystemVar:
-			_, err := setSystemVar(ctx, left, setField.Right, row)
+			_, err := setSystemVar(ctx, s.Catalog, left, setField.Right, row)
 			if err != nil {
 				return nil, err
 			}
@@ -98,50 +103,69 @@ func (s *Set) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 }
 
 func setUserVar(ctx *sql.Context, userVar *expression.UserVar, right sql.Expression, row sql.Row) (interface{}, error) {
-	var (
-		value interface{}
-		err   error
-	)
+	value, err := right.Eval(ctx, row)
+	if err != nil {
+		return nil, err
+	}
 
-	var varName = userVar.Name
+	return userVar.Set(ctx, right.Type(), value)
+}
 
-	value, err = right.Eval(ctx, row)
+func setSystemVar(ctx *sql.Context, c *sql.Catalog, sysVar *expression.SystemVar, right sql.Expression, row sql.Row) (interface{}, error) {
+	value, err := right.Eval(ctx, row)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: differentiate between system and user vars here
-	err = ctx.Set(ctx, varName, right.Type(), value)
+	switch sysVar.Persist {
+	case expression.Persist, expression.PersistOnly:
+		return value, persistSystemVar(ctx, c, sysVar, value)
+	}
+
+	// The global value of a variable is the one sessions start with, so it
+	// doesn't change its value in the current session.
+	if sysVar.Global {
+		return value, ctx.SystemVariables().SetGlobal(sysVar.Name, value)
+	}
+
+	v, value, err := ctx.SystemVariables().Convert(sysVar.Name, false, value)
 	if err != nil {
 		return nil, err
 	}
 
-	return value, nil
+	return value, ctx.Set(ctx, v.Name, v.Type, value)
 }
 
-func setSystemVar(ctx *sql.Context, sysVar *expression.SystemVar, right sql.Expression, row sql.Row) (interface{}, error) {
-	var (
-		value interface{}
-		typ   sql.Type
-		err   error
-	)
-
-	var varName = sysVar.Name
+// persistSystemVar persists the global value of a variable, also setting it
+// unless it's only persisted.
+func persistSystemVar(ctx *sql.Context, c *sql.Catalog, sysVar *expression.SystemVar, value interface{}) error {
+	if c == nil {
+		return sql.ErrPersistNotSupported.New()
+	}
 
-	// TODO: value checking for system variables. Each one has specific lists of acceptable values.
-	value, err = right.Eval(ctx, row)
+	p, err := c.VariablePersister()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	typ = sysVar.Type()
 
-	// TODO: differentiate between system and user vars here
-	err = ctx.Set(ctx, varName, typ, value)
+	var v sql.SystemVariable
+	if sysVar.Persist == expression.PersistOnly {
+		v, value, err = ctx.SystemVariables().ConvertPersisted(sysVar.Name, value)
+	} else {
+		v, value, err = ctx.SystemVariables().Convert(sysVar.Name, true, value)
+	}
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	if err := p.Persist(ctx, v.Name, value); err != nil {
+		return err
 	}
 
-	return value, nil
+	if sysVar.Persist == expression.PersistOnly {
+		return nil
+	}
+	return ctx.SystemVariables().SetGlobal(v.Name, value)
 }
 
 // Schema implements the sql.Node interface.
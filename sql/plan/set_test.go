@@ -17,19 +17,116 @@ func TestSet(t *testing.T) {
 
 	s := NewSet(
 		[]sql.Expression{
-			expression.NewSetField(expression.NewSystemVar("foo", sql.LongText), expression.NewLiteral("bar", sql.LongText)),
-			expression.NewSetField(expression.NewSystemVar("baz", sql.Int64), expression.NewLiteral(int64(1), sql.Int64)),
+			expression.NewSetField(expression.NewSystemVar("time_zone", sql.LongText), expression.NewLiteral("+00:00", sql.LongText)),
+			expression.NewSetField(expression.NewSystemVar("wait_timeout", sql.Int64), expression.NewLiteral(int8(1), sql.Int8)),
 		},
 	)
 
 	_, err := s.RowIter(ctx, nil)
 	require.NoError(err)
 
-	typ, v := ctx.Get("foo")
+	typ, v := ctx.Get("time_zone")
 	require.Equal(sql.LongText, typ)
-	require.Equal("bar", v)
+	require.Equal("+00:00", v)
 
-	typ, v = ctx.Get("baz")
+	typ, v = ctx.Get("wait_timeout")
 	require.Equal(sql.Int64, typ)
 	require.Equal(int64(1), v)
 }
+
+func TestSetGlobal(t *testing.T) {
+	require := require.New(t)
+
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession()))
+
+	s := NewSet([]sql.Expression{
+		expression.NewSetField(expression.NewGlobalSystemVar("wait_timeout", sql.Int64), expression.NewLiteral(int64(60), sql.Int64)),
+	})
+	_, err := s.RowIter(ctx, nil)
+	require.NoError(err)
+
+	// The session setting the global value keeps its own, and new sessions
+	// start with the global one.
+	_, v := ctx.Get("wait_timeout")
+	require.Equal(int64(28800), v)
+	sess := sql.NewBaseSession()
+	require.NoError(ctx.SystemVariables().InitSession(ctx, sess))
+	_, v = sess.Get("wait_timeout")
+	require.Equal(int64(60), v)
+
+	// The global values are the ones of the registry of the context.
+	v, _ = sql.NewEmptyContext().SystemVariables().Global("wait_timeout")
+	require.Equal(int64(28800), v)
+
+	for _, set := range []sql.Expression{
+		expression.NewSetField(expression.NewSystemVar("version", sql.LongText), expression.NewLiteral("1", sql.LongText)),
+		expression.NewSetField(expression.NewSystemVar("max_connections", sql.Int64), expression.NewLiteral(int64(1), sql.Int64)),
+		expression.NewSetField(expression.NewSystemVar("wait_timeout", sql.Int64), expression.NewLiteral(int64(0), sql.Int64)),
+		expression.NewSetField(expression.NewSystemVar("transaction_isolation", sql.LongText), expression.NewLiteral("foo", sql.LongText)),
+	} {
+		_, err := NewSet([]sql.Expression{set}).RowIter(ctx, nil)
+		require.Error(err, set.String())
+	}
+}
+
+func TestSetPersist(t *testing.T) {
+	require := require.New(t)
+
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession()))
+
+	set := NewSet([]sql.Expression{
+		expression.NewSetField(
+			expression.NewPersistedSystemVar("wait_timeout", sql.Int64, expression.Persist),
+			expression.NewLiteral(int64(60), sql.Int64),
+		),
+		expression.NewSetField(
+			expression.NewPersistedSystemVar("lower_case_table_names", sql.Int32, expression.PersistOnly),
+			expression.NewLiteral(int64(1), sql.Int64),
+		),
+	})
+
+	_, err := set.RowIter(ctx, nil)
+	require.True(sql.ErrPersistNotSupported.Is(err))
+
+	p := sql.NewMemoryVariablePersister(nil)
+	set.Catalog = sql.NewCatalog()
+	set.Catalog.SetVariablePersister(p)
+
+	_, err = set.RowIter(ctx, nil)
+	require.NoError(err)
+
+	persisted, err := p.Persisted(ctx)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"wait_timeout":           int64(60),
+		"lower_case_table_names": int32(1),
+	}, persisted)
+
+	// SET PERSIST sets the global value too, and SET PERSIST_ONLY doesn't.
+	v, _ := ctx.SystemVariables().Global("wait_timeout")
+	require.Equal(int64(60), v)
+	v, _ = ctx.SystemVariables().Global("lower_case_table_names")
+	require.Equal(int32(0), v)
+
+	reset := NewResetPersist("wait_timeout", false)
+	reset.Catalog = set.Catalog
+	_, err = reset.RowIter(ctx, nil)
+	require.NoError(err)
+	_, err = reset.RowIter(ctx, nil)
+	require.True(sql.ErrVariableNotPersisted.Is(err))
+
+	reset = NewResetPersist("wait_timeout", true)
+	reset.Catalog = set.Catalog
+	_, err = reset.RowIter(ctx, nil)
+	require.NoError(err)
+	require.Len(ctx.Warnings(), 1)
+
+	reset = NewResetPersist("", false)
+	reset.Catalog = set.Catalog
+	_, err = reset.RowIter(ctx, nil)
+	require.NoError(err)
+
+	persisted, err = p.Persisted(ctx)
+	require.NoError(err)
+	require.Empty(persisted)
+}
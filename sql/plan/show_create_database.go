@@ -51,10 +51,11 @@ func (s *ShowCreateDatabase) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter
 	buf.WriteRune('`')
 	buf.WriteString(name)
 	buf.WriteRune('`')
+	collation := sql.GetDatabaseCollation(s.db)
 	buf.WriteString(fmt.Sprintf(
 		" /*!40100 DEFAULT CHARACTER SET %s COLLATE %s */",
-		sql.Collation_Default.CharacterSet().String(),
-		sql.Collation_Default.String(),
+		collation.CharacterSet().String(),
+		collation.String(),
 	))
 
 	return sql.RowsToRowIter(
@@ -3,11 +3,13 @@ package plan
 import (
 	"fmt"
 	"io"
+	"regexp"
 	"strings"
 
 	"gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
 )
 
 var ErrNotView = errors.NewKind("'%' is not VIEW")
@@ -119,10 +121,14 @@ func (i *showCreateTablesIter) Next() (sql.Row, error) {
 
 		tableName = table.Name()
 		var err error
-		composedCreateTableStatement, err = i.produceCreateTableStatement(table.Table)
+		composedCreateTableStatement, err = produceCreateTableStatement(i.ctx, table.Table, i.indexes)
 		if err != nil {
 			return nil, err
 		}
+		// The indexes with functional key parts follow as CREATE INDEX statements, which can create them.
+		for _, stmt := range produceCreateIndexStatements(table.Table, i.indexes) {
+			composedCreateTableStatement += ";\n" + stmt
+		}
 	case *SubqueryAlias:
 		tableName = table.Name()
 		composedCreateTableStatement = produceCreateViewStatement(table)
@@ -141,15 +147,16 @@ type NameAndSchema interface {
 	Schema() sql.Schema
 }
 
-func (i *showCreateTablesIter) produceCreateTableStatement(table sql.Table) (string, error) {
+// produceCreateTableStatement returns the CREATE TABLE statement of a table with the indexes given, but the ones with
+// functional key parts, which produceCreateIndexStatements returns the statements of.
+func produceCreateTableStatement(ctx *sql.Context, table sql.Table, indexes []sql.Index) (string, error) {
 	schema := table.Schema()
 	colStmts := make([]string, len(schema))
 	var primaryKeyCols []string
 
 	// Statement creation parts for each column
-	// TODO: rather than lower-casing here, we should do it in the String() method of types
 	for i, col := range schema {
-		stmt := fmt.Sprintf("  `%s` %s", col.Name, strings.ToLower(col.Type.String()))
+		stmt := fmt.Sprintf("  %s %s", quoteIdentifier(col.Name), columnTypeString(col.Type))
 
 		if !col.Nullable {
 			stmt = fmt.Sprintf("%s NOT NULL", stmt)
@@ -160,15 +167,27 @@ func (i *showCreateTablesIter) produceCreateTableStatement(table sql.Table) (str
 		}
 
 		if col.Default != nil {
-			stmt = fmt.Sprintf("%s DEFAULT %s", stmt, col.Default.String())
+			stmt = fmt.Sprintf("%s DEFAULT %s", stmt, columnDefaultString(col.Default))
+		}
+
+		if col.OnUpdate != nil {
+			stmt = fmt.Sprintf("%s ON UPDATE %s", stmt, col.OnUpdate.String())
 		}
 
 		if col.Comment != "" {
-			stmt = fmt.Sprintf("%s COMMENT '%s'", stmt, col.Comment)
+			stmt = fmt.Sprintf("%s COMMENT %s", stmt, quoteString(col.Comment))
+		}
+
+		if col.Invisible {
+			stmt += " /*!80023 INVISIBLE */"
 		}
 
 		if col.PrimaryKey {
-			primaryKeyCols = append(primaryKeyCols, col.Name)
+			primaryKeyCol := quoteIdentifier(col.Name)
+			if col.PrimaryKeyDescending {
+				primaryKeyCol += " DESC"
+			}
+			primaryKeyCols = append(primaryKeyCols, primaryKeyCol)
 		}
 
 		colStmts[i] = stmt
@@ -177,32 +196,35 @@ func (i *showCreateTablesIter) produceCreateTableStatement(table sql.Table) (str
 	// TODO: the order of the primary key columns might not match their order in the schema. The current interface can't
 	//  represent this. We will need a new sql.Table extension to support this cleanly.
 	if len(primaryKeyCols) > 0 {
-		primaryKey := fmt.Sprintf("  PRIMARY KEY (%s)", strings.Join(quoteIdentifiers(primaryKeyCols), ","))
+		primaryKey := fmt.Sprintf("  PRIMARY KEY (%s)", strings.Join(primaryKeyCols, ","))
 		colStmts = append(colStmts, primaryKey)
 	}
 
-	for _, index := range i.indexes {
+	for _, index := range indexes {
 		// The primary key may or may not be declared as an index by the table. Don't print it twice if it's here.
-		if isPrimaryKeyIndex(index, table) {
+		if isPrimaryKeyIndex(index, table) || isFunctionalIndex(index, table) {
 			continue
 		}
 
-		var indexCols []string
-		for _, expr := range index.Expressions() {
-			col := GetColumnFromIndexExpr(expr, table)
-			if col != nil {
-				indexCols = append(indexCols, fmt.Sprintf("`%s`", col.Name))
-			}
-		}
+		indexCols := indexKeyParts(index, table)
 
-		unique := ""
-		if index.IsUnique() {
-			unique = "UNIQUE "
+		kind, using := "", ""
+		switch indexType := strings.ToUpper(index.IndexType()); {
+		case indexType == "FULLTEXT" || indexType == "SPATIAL":
+			kind = indexType + " "
+		case index.IsUnique():
+			kind = "UNIQUE "
+		}
+		if strings.EqualFold(index.IndexType(), "HASH") {
+			using = " USING HASH"
 		}
 
-		key := fmt.Sprintf("  %sKEY `%s` (%s)", unique, index.ID(), strings.Join(indexCols, ","))
+		key := fmt.Sprintf("  %sKEY %s (%s)%s", kind, quoteIdentifier(index.ID()), strings.Join(indexCols, ","), using)
 		if index.Comment() != "" {
-			key = fmt.Sprintf("%s COMMENT '%s'", key, index.Comment())
+			key = fmt.Sprintf("%s COMMENT %s", key, quoteString(index.Comment()))
+		}
+		if !sql.IsIndexVisible(index) {
+			key += " /*!80000 INVISIBLE */"
 		}
 
 		colStmts = append(colStmts, key)
@@ -210,7 +232,7 @@ func (i *showCreateTablesIter) produceCreateTableStatement(table sql.Table) (str
 
 	fkt := getForeignKeyTable(table)
 	if fkt != nil {
-		fks, err := fkt.GetForeignKeys(i.ctx)
+		fks, err := fkt.GetForeignKeys(ctx)
 		if err != nil {
 			return "", err
 		}
@@ -225,17 +247,177 @@ func (i *showCreateTablesIter) produceCreateTableStatement(table sql.Table) (str
 			if len(fk.OnUpdate) > 0 && fk.OnUpdate != sql.ForeignKeyReferenceOption_DefaultAction {
 				onUpdate = " ON UPDATE " + string(fk.OnUpdate)
 			}
-			colStmts = append(colStmts, fmt.Sprintf("  CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s` (%s)%s%s", fk.Name, keyCols, fk.ReferencedTable, refCols, onDelete, onUpdate))
+			refTable := quoteIdentifier(fk.ReferencedTable)
+			if fk.ReferencedDatabase != "" {
+				refTable = quoteIdentifier(fk.ReferencedDatabase) + "." + refTable
+			}
+			colStmts = append(colStmts, fmt.Sprintf("  CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)%s%s", quoteIdentifier(fk.Name), keyCols, refTable, refCols, onDelete, onUpdate))
+		}
+	}
+
+	checks, err := getChecks(ctx, table)
+	if err != nil {
+		return "", err
+	}
+	for _, check := range checks {
+		stmt := fmt.Sprintf("  CONSTRAINT %s CHECK (%s)", quoteIdentifier(check.Name), check.CheckExpression)
+		if !check.Enforced {
+			stmt += " /*!80016 NOT ENFORCED */"
 		}
+		colStmts = append(colStmts, stmt)
+	}
+
+	autoIncrement, err := autoIncrementOption(ctx, table)
+	if err != nil {
+		return "", err
+	}
+
+	options := GetTableOptions(table)
+	engine, ok := options.Get(sql.TableOption_Engine)
+	if !ok {
+		engine = "InnoDB"
+	}
+	charset, ok := options.Get(sql.TableOption_CharacterSet)
+	if !ok {
+		charset = "utf8mb4"
 	}
 
 	return fmt.Sprintf(
-		"CREATE TABLE `%s` (\n%s\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
-		table.Name(),
+		"CREATE TABLE %s (\n%s\n) ENGINE=%s%s DEFAULT CHARSET=%s%s",
+		quoteIdentifier(table.Name()),
 		strings.Join(colStmts, ",\n"),
+		engine,
+		autoIncrement,
+		charset,
+		otherTableOptions(options),
 	), nil
 }
 
+// produceCreateIndexStatements returns the CREATE INDEX statements of the indexes given of a table that have functional
+// key parts, which CREATE TABLE can't create.
+func produceCreateIndexStatements(table sql.Table, indexes []sql.Index) []string {
+	var stmts []string
+	for _, index := range indexes {
+		if !isFunctionalIndex(index, table) {
+			continue
+		}
+
+		unique, using := "", ""
+		if index.IsUnique() {
+			unique = "UNIQUE "
+		}
+		if strings.EqualFold(index.IndexType(), "HASH") {
+			using = " USING HASH"
+		}
+
+		stmt := fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)%s", unique, quoteIdentifier(index.ID()), quoteIdentifier(table.Name()), strings.Join(indexKeyParts(index, table), ","), using)
+		if index.Comment() != "" {
+			stmt = fmt.Sprintf("%s COMMENT %s", stmt, quoteString(index.Comment()))
+		}
+		if !sql.IsIndexVisible(index) {
+			stmt += " INVISIBLE"
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
+
+// isFunctionalIndex returns whether an index of a table has a key part that isn't a column.
+func isFunctionalIndex(index sql.Index, table sql.Table) bool {
+	for _, expr := range index.Expressions() {
+		if GetColumnFromIndexExpr(expr, table) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// indexKeyParts returns the key parts of an index of a table as they're given in CREATE TABLE and CREATE INDEX. The
+// columns in functional key parts aren't qualified with the table name, which those statements don't allow.
+func indexKeyParts(index sql.Index, table sql.Table) []string {
+	var keyParts []string
+	descending, prefixLengths := sql.IndexDirections(index), sql.IndexPrefixLengths(index)
+	for i, expr := range index.Expressions() {
+		var keyPart string
+		if col := GetColumnFromIndexExpr(expr, table); col != nil {
+			keyPart = quoteIdentifier(col.Name)
+			if prefixLengths[i] != 0 {
+				keyPart += fmt.Sprintf("(%d)", prefixLengths[i])
+			}
+		} else {
+			keyPart = fmt.Sprintf("(%s)", unqualifyColumns(expr, table))
+		}
+		if descending[i] {
+			keyPart += " DESC"
+		}
+		keyParts = append(keyParts, keyPart)
+	}
+	return keyParts
+}
+
+// unqualifyColumns returns an expression of an index of a table with its columns quoted instead of qualified with the
+// table name.
+func unqualifyColumns(expr string, table sql.Table) string {
+	for _, col := range table.Schema() {
+		qualified := regexp.MustCompile(`\b` + regexp.QuoteMeta(col.Source+"."+col.Name) + `\b`)
+		expr = qualified.ReplaceAllLiteralString(expr, quoteIdentifier(col.Name))
+	}
+	return expr
+}
+
+// otherTableOptions returns the options given other than the engine, the next auto increment value and the character
+// set, as they're given in CREATE TABLE, after a space if there are any.
+func otherTableOptions(options sql.TableOptions) string {
+	var other string
+	for _, option := range options {
+		switch option.Name {
+		case sql.TableOption_Engine, sql.TableOption_AutoIncrement, sql.TableOption_CharacterSet:
+		default:
+			other += " " + option.String()
+		}
+	}
+	return other
+}
+
+// autoIncrementOption returns the AUTO_INCREMENT table option of the CREATE TABLE statement of the table given, which
+// like in MySQL is only shown once the AUTO_INCREMENT value of the table has moved past its first value.
+func autoIncrementOption(ctx *sql.Context, table sql.Table) (string, error) {
+	autoTbl := getAutoIncrementTable(table)
+	if autoTbl == nil {
+		return "", nil
+	}
+
+	for _, col := range table.Schema() {
+		if !col.AutoIncrement {
+			continue
+		}
+
+		val, err := autoTbl.GetAutoIncrementValue(ctx)
+		if err != nil || val == nil {
+			return "", err
+		}
+		cmp, err := col.Type.Compare(val, sql.NumericUnaryValue(col.Type))
+		if err != nil || cmp <= 0 {
+			return "", err
+		}
+		return fmt.Sprintf(" AUTO_INCREMENT=%v", val), nil
+	}
+	return "", nil
+}
+
+// getAutoIncrementTable returns the underlying AutoIncrementTable for the table given, or nil if it isn't an
+// AutoIncrementTable
+func getAutoIncrementTable(t sql.Table) sql.AutoIncrementTable {
+	switch t := t.(type) {
+	case sql.AutoIncrementTable:
+		return t
+	case sql.TableWrapper:
+		return getAutoIncrementTable(t.Underlying())
+	default:
+		return nil
+	}
+}
+
 // getForeignKeyTable returns the underlying ForeignKeyTable for the table given, or nil if it isn't a ForeignKeyTable
 func getForeignKeyTable(t sql.Table) sql.ForeignKeyTable {
 	switch t := t.(type) {
@@ -251,11 +433,60 @@ func getForeignKeyTable(t sql.Table) sql.ForeignKeyTable {
 func quoteIdentifiers(ids []string) []string {
 	quoted := make([]string, len(ids))
 	for i, id := range ids {
-		quoted[i] = fmt.Sprintf("`%s`", id)
+		quoted[i] = quoteIdentifier(id)
 	}
 	return quoted
 }
 
+// quoteIdentifier returns the identifier given in backticks, with the backticks in it doubled.
+func quoteIdentifier(id string) string {
+	return "`" + strings.ReplaceAll(id, "`", "``") + "`"
+}
+
+// quoteString returns the string given in single quotes, with its quotes and backslashes escaped.
+func quoteString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// columnTypeString returns the type given as MySQL shows it in column definitions: lowercase, except for the values
+// of ENUM and SET types and the CHARACTER SET and COLLATE clauses.
+func columnTypeString(typ sql.Type) string {
+	s := typ.String()
+
+	// The character set and collation clauses follow the parameters of the type, which may contain either in a value
+	start := strings.LastIndex(s, ")") + 1
+	end := len(s)
+	for _, clause := range []string{" CHARACTER SET ", " COLLATE "} {
+		if i := strings.Index(s[start:], clause); i >= 0 && start+i < end {
+			end = start + i
+		}
+	}
+
+	lowered := []byte(s[:end])
+	quoted := false
+	for i, c := range lowered {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case !quoted && c >= 'A' && c <= 'Z':
+			lowered[i] = c + 'a' - 'A'
+		}
+	}
+	return string(lowered) + s[end:]
+}
+
+// columnDefaultString returns the default value given as MySQL shows it in column definitions, with strings in
+// single quotes.
+func columnDefaultString(def *sql.ColumnDefaultValue) string {
+	if lit, ok := def.Expression.(*expression.Literal); ok && def.IsLiteral() {
+		if s, ok := lit.Value().(string); ok {
+			return quoteString(s)
+		}
+	}
+	return def.String()
+}
+
 // isPrimaryKeyIndex returns whether the index given matches the table's primary key columns. Order is not considered.
 func isPrimaryKeyIndex(index sql.Index, table sql.Table) bool {
 	var pks []*sql.Column
@@ -84,13 +84,13 @@ func (s *ShowCreateTrigger) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter,
 			_, characterSetClient := ctx.Get("character_set_client")
 			_, collationConnection := ctx.Get("collation_connection")
 			return sql.RowsToRowIter(sql.Row{
-				trigger.Name,                   // Trigger
-				"",                             // sql_mode
-				trigger.CreateStatement,        // SQL Original Statement
-				characterSetClient,             // character_set_client //TODO: allow these to be retrieved from integrators
-				collationConnection,            // collation_connection //TODO: allow these to be retrieved from integrators
-				sql.Collation_Default.String(), // Database Collation //TODO: add support for databases to set collation
-				time.Unix(0, 0).UTC(),          // Created
+				trigger.Name,                            // Trigger
+				"",                                      // sql_mode
+				trigger.CreateStatement,                 // SQL Original Statement
+				characterSetClient,                      // character_set_client //TODO: allow these to be retrieved from integrators
+				collationConnection,                     // collation_connection //TODO: allow these to be retrieved from integrators
+				sql.GetDatabaseCollation(s.db).String(), // Database Collation
+				time.Unix(0, 0).UTC(),                   // Created
 			}), nil
 		}
 	}
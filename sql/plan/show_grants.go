@@ -0,0 +1,236 @@
+package plan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ShowGrants shows the privileges, roles and proxy users granted to an account as the GRANT statements that would
+// grant them.
+type ShowGrants struct {
+	// For is the account whose grants are shown. It's the current account if
+	// nil, which the analyzer resolves before executing the statement.
+	For *sql.Account
+	// Using are roles granted to the account whose privileges are shown as
+	// well, as if they were active.
+	Using   []sql.Account
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*ShowGrants)(nil)
+
+// NewShowGrants creates a new ShowGrants node.
+func NewShowGrants(account *sql.Account, using []sql.Account) *ShowGrants {
+	return &ShowGrants{For: account, Using: using}
+}
+
+// Children implements the sql.Node interface.
+func (*ShowGrants) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*ShowGrants) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (n *ShowGrants) Schema() sql.Schema {
+	name := "Grants for CURRENT_USER"
+	if n.For != nil {
+		name = fmt.Sprintf("Grants for %s@%s", n.For.Name, n.For.Host)
+	}
+	return sql.Schema{{Name: name, Type: sql.MustCreateStringWithDefaults(sqltypes.VarChar, 1024)}}
+}
+
+// RowIter implements the sql.Node interface.
+func (n *ShowGrants) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	account := n.For
+	if account == nil {
+		a, err := n.Catalog.CurrentAccount(ctx)
+		if err != nil {
+			return nil, err
+		}
+		account = &a
+	}
+
+	pm, err := n.Catalog.PrivilegeManager()
+	if err != nil {
+		// Without privileges, every account can do anything.
+		return sql.RowsToRowIter(sql.NewRow(fmt.Sprintf("GRANT ALL PRIVILEGES ON *.* TO %s WITH GRANT OPTION",
+			grantee(*account)))), nil
+	}
+
+	grants, err := accountGrants(ctx, pm, *account, n.Using)
+	if sql.ErrUserNotFound.Is(err) {
+		return nil, sql.ErrNonexistingGrant.New(account.Name, account.Host)
+	} else if err != nil {
+		return nil, err
+	}
+
+	rows := make([]sql.Row, len(grants))
+	for i, g := range grants {
+		rows[i] = sql.NewRow(g)
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// accountGrants returns the GRANT statements of an account: its privileges
+// from the global level down to the columns, then the proxy users and the
+// roles it was granted.
+func accountGrants(ctx *sql.Context, pm sql.PrivilegeManager, account sql.Account, using []sql.Account) ([]string, error) {
+	privileges, err := pm.Privileges(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	var roles []sql.RoleGrant
+	if rm, ok := pm.(sql.RoleManager); ok {
+		if roles, err = rm.Roles(ctx, account); err != nil {
+			return nil, err
+		}
+
+		for _, r := range using {
+			if !grantedRole(roles, r) {
+				return nil, sql.ErrRoleNotGranted.New(r, account)
+			}
+		}
+
+		if len(using) > 0 {
+			if privileges, err = sql.RolePrivileges(ctx, rm, account, using); err != nil {
+				return nil, err
+			}
+		}
+	} else if len(using) > 0 {
+		return nil, sql.ErrRolesNotSupported.New()
+	}
+
+	to := grantee(account)
+	grants := []string{levelGrant(sql.PrivilegeLevel{}, privileges.Level(sql.PrivilegeLevel{}), nil, to)}
+
+	var levels []sql.PrivilegeLevel
+	// Column privileges are shown along with the ones of their table, by the
+	// lowercase name of the table level.
+	columns := make(map[string][]sql.ColumnGrant)
+	for _, g := range privileges {
+		switch {
+		case g.Level.Database == "":
+			continue
+		case g.Level.Column != "":
+			table := columnLevel(g.Level, "")
+			key := strings.ToLower(table.String())
+			if _, ok := columns[key]; !ok && privileges.Level(table) == sql.PrivilegeUsage {
+				levels = append(levels, table)
+			}
+			columns[key] = append(columns[key], sql.ColumnGrant{
+				Column:     "`" + strings.Replace(g.Level.Column, "`", "``", -1) + "`",
+				Privileges: g.Privileges,
+			})
+		default:
+			levels = append(levels, g.Level)
+		}
+	}
+
+	// Database levels go before table levels, and each kind by name.
+	sort.Slice(levels, func(i, j int) bool {
+		if (levels[i].Table == "") != (levels[j].Table == "") {
+			return levels[i].Table == ""
+		}
+		return levels[i].String() < levels[j].String()
+	})
+
+	for _, l := range levels {
+		grants = append(grants, levelGrant(l, privileges.Level(l), columns[strings.ToLower(l.String())], to))
+	}
+
+	if xm, ok := pm.(sql.ProxyManager); ok {
+		proxies, err := xm.Proxies(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range proxies {
+			var withGrantOption string
+			if p.WithGrantOption {
+				withGrantOption = " WITH GRANT OPTION"
+			}
+			grants = append(grants, fmt.Sprintf("GRANT PROXY ON %s TO %s%s", grantee(p.Proxied), to, withGrantOption))
+		}
+	}
+
+	for _, admin := range []bool{false, true} {
+		var granted []string
+		for _, r := range roles {
+			if r.WithAdminOption == admin {
+				granted = append(granted, grantee(r.Role))
+			}
+		}
+
+		if len(granted) == 0 {
+			continue
+		}
+
+		sort.Strings(granted)
+		grant := fmt.Sprintf("GRANT %s TO %s", strings.Join(granted, ","), to)
+		if admin {
+			grant += " WITH ADMIN OPTION"
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, nil
+}
+
+// levelGrant returns the GRANT statement of the privileges granted at a level
+// and on the columns of the level, if it's a table.
+func levelGrant(level sql.PrivilegeLevel, privileges sql.Privilege, columns []sql.ColumnGrant, to string) string {
+	var withGrantOption string
+	if privileges&sql.PrivilegeGrantOption != 0 {
+		withGrantOption = " WITH GRANT OPTION"
+	}
+
+	privileges &^= sql.PrivilegeGrantOption
+	if privileges != sql.PrivilegeUsage && privileges == level.Privileges()&^sql.PrivilegeGrantOption {
+		privileges = sql.PrivilegeAll
+	}
+
+	return fmt.Sprintf("GRANT %s ON %s TO %s%s", privilegeList(privileges, columns), level, to, withGrantOption)
+}
+
+func grantedRole(roles []sql.RoleGrant, role sql.Account) bool {
+	for _, r := range roles {
+		if r.Role.Equal(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// grantee returns an account as SHOW GRANTS writes it, such as `root`@`%`.
+func grantee(a sql.Account) string {
+	quote := func(s string) string {
+		return "`" + strings.Replace(s, "`", "``", -1) + "`"
+	}
+	return quote(a.Name) + "@" + quote(a.Host)
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *ShowGrants) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *ShowGrants) String() string {
+	s := "SHOW GRANTS"
+	if n.For != nil {
+		s += " FOR " + n.For.String()
+	}
+	if len(n.Using) > 0 {
+		s += " USING " + joinAccounts(n.Using)
+	}
+	return s
+}
@@ -67,8 +67,14 @@ func (n *ShowIndexes) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 		panic(fmt.Sprintf("unexpected type %T", n.Child))
 	}
 
+	stats, err := sql.GetTableStatistics(ctx, table.Table)
+	if err != nil {
+		return nil, err
+	}
+
 	return &showIndexesIter{
 		table: table,
+		stats: stats,
 		idxs:  newIndexesToShow(n.IndexesToShow),
 		ctx:   ctx,
 	}, nil
@@ -82,6 +88,7 @@ func newIndexesToShow(indexes []sql.Index) *indexesToShow {
 
 type showIndexesIter struct {
 	table *ResolvedTable
+	stats *sql.TableStatistics
 	idxs  *indexesToShow
 	ctx   *sql.Context
 }
@@ -109,26 +116,43 @@ func (i *showIndexesIter) Next() (sql.Row, error) {
 	}
 
 	visible := "YES"
-	if x, ok := show.index.(sql.DriverIndex); ok && len(x.Driver()) > 0 {
+	if !sql.IsIndexVisible(show.index) {
+		visible = "NO"
+	} else if x, ok := show.index.(sql.DriverIndex); ok && len(x.Driver()) > 0 {
 		if !i.ctx.CanUseIndex(x) {
 			visible = "NO"
 		}
 	}
 
+	cardinality := int64(0)
+	if i.stats != nil {
+		cardinality = int64(i.stats.IndexCardinality[show.index.ID()])
+	}
+
 	nonUnique := 0
 	if !show.index.IsUnique() {
 		nonUnique = 1
 	}
 
+	collation := "A"
+	if sql.IndexDirections(show.index)[show.exPosition] {
+		collation = "D"
+	}
+
+	var subPart interface{}
+	if length := sql.IndexPrefixLengths(show.index)[show.exPosition]; length != 0 {
+		subPart = length
+	}
+
 	return sql.NewRow(
 		show.index.Table(),     // "Table" string
 		nonUnique,              // "Non_unique" int32, Values [0, 1]
 		show.index.ID(),        // "Key_name" string
 		show.exPosition+1,      // "Seq_in_index" int32
 		columnName,             // "Column_name" string
-		nil,                    // "Collation" string, Values [A, D, NULL]
-		int64(0),               // "Cardinality" int64 (not calculated)
-		nil,                    // "Sub_part" int64
+		collation,              // "Collation" string, Values [A, D, NULL]
+		cardinality,            // "Cardinality" int64
+		subPart,                // "Sub_part" int64
 		nil,                    // "Packed" string
 		nullable,               // "Null" string, Values [YES, '']
 		show.index.IndexType(), // "Index_type" string
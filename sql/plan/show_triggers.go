@@ -81,17 +81,17 @@ func (s *ShowTriggers) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, erro
 		_, characterSetClient := ctx.Get("character_set_client")
 		_, collationConnection := ctx.Get("collation_connection")
 		rows = append(rows, sql.Row{
-			trigger.TriggerName,            // Trigger
-			triggerEvent,                   // Event
-			tableName,                      // Table
-			trigger.BodyString,             // Statement
-			triggerTime,                    // Timing
-			time.Unix(0, 0).UTC(),          // Created
-			"",                             // sql_mode
-			"",                             // Definer
-			characterSetClient,             // character_set_client //TODO: allow these to be retrieved from integrators
-			collationConnection,            // collation_connection //TODO: allow these to be retrieved from integrators
-			sql.Collation_Default.String(), // Database Collation
+			trigger.TriggerName,                     // Trigger
+			triggerEvent,                            // Event
+			tableName,                               // Table
+			trigger.BodyString,                      // Statement
+			triggerTime,                             // Timing
+			time.Unix(0, 0).UTC(),                   // Created
+			"",                                      // sql_mode
+			"",                                      // Definer
+			characterSetClient,                      // character_set_client //TODO: allow these to be retrieved from integrators
+			collationConnection,                     // collation_connection //TODO: allow these to be retrieved from integrators
+			sql.GetDatabaseCollation(s.db).String(), // Database Collation
 		})
 	}
 	return sql.RowsToRowIter(rows...), nil
@@ -0,0 +1,217 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ShowBinaryLogs is a node that shows the files of the binary log of the catalog.
+type ShowBinaryLogs struct {
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*ShowBinaryLogs)(nil)
+
+// NewShowBinaryLogs returns a new ShowBinaryLogs node.
+func NewShowBinaryLogs() *ShowBinaryLogs {
+	return &ShowBinaryLogs{}
+}
+
+// Resolved implements the sql.Node interface.
+func (s *ShowBinaryLogs) Resolved() bool {
+	return true
+}
+
+// WithChildren implements the sql.Node interface.
+func (s *ShowBinaryLogs) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(s, len(children), 0)
+	}
+	return s, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (s *ShowBinaryLogs) String() string {
+	return "SHOW BINARY LOGS"
+}
+
+// Schema implements the sql.Node interface.
+func (s *ShowBinaryLogs) Schema() sql.Schema {
+	return sql.Schema{
+		&sql.Column{Name: "Log_name", Type: sql.LongText},
+		&sql.Column{Name: "File_size", Type: sql.Uint64},
+		&sql.Column{Name: "Encrypted", Type: sql.LongText},
+	}
+}
+
+// Children implements the sql.Node interface.
+func (s *ShowBinaryLogs) Children() []sql.Node { return nil }
+
+// RowIter implements the sql.Node interface.
+func (s *ShowBinaryLogs) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	log, err := binaryLog(s.Catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []sql.Row
+	for _, f := range log.Files() {
+		rows = append(rows, sql.NewRow(f.Name, f.Size, "No"))
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// ShowBinlogEvents is a node that shows the events of a file of the binary log of the catalog, from a position, with
+// an offset and a limit to the number of events shown if Limit isn't negative.
+type ShowBinlogEvents struct {
+	In      string
+	From    uint64
+	Offset  int64
+	Limit   int64
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*ShowBinlogEvents)(nil)
+
+// NewShowBinlogEvents returns a new ShowBinlogEvents node, of the events of the first file if in is empty.
+func NewShowBinlogEvents(in string, from uint64, offset, limit int64) *ShowBinlogEvents {
+	return &ShowBinlogEvents{In: in, From: from, Offset: offset, Limit: limit}
+}
+
+// Resolved implements the sql.Node interface.
+func (s *ShowBinlogEvents) Resolved() bool {
+	return true
+}
+
+// WithChildren implements the sql.Node interface.
+func (s *ShowBinlogEvents) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(s, len(children), 0)
+	}
+	return s, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (s *ShowBinlogEvents) String() string {
+	var b strings.Builder
+	b.WriteString("SHOW BINLOG EVENTS")
+	if s.In != "" {
+		fmt.Fprintf(&b, " IN '%s'", s.In)
+	}
+	if s.From > 0 {
+		fmt.Fprintf(&b, " FROM %d", s.From)
+	}
+	if s.Limit >= 0 {
+		fmt.Fprintf(&b, " LIMIT %d, %d", s.Offset, s.Limit)
+	}
+	return b.String()
+}
+
+// Schema implements the sql.Node interface.
+func (s *ShowBinlogEvents) Schema() sql.Schema {
+	return sql.Schema{
+		&sql.Column{Name: "Log_name", Type: sql.LongText},
+		&sql.Column{Name: "Pos", Type: sql.Uint64},
+		&sql.Column{Name: "Event_type", Type: sql.LongText},
+		&sql.Column{Name: "Server_id", Type: sql.Uint32},
+		&sql.Column{Name: "End_log_pos", Type: sql.Uint64},
+		&sql.Column{Name: "Info", Type: sql.LongText},
+	}
+}
+
+// Children implements the sql.Node interface.
+func (s *ShowBinlogEvents) Children() []sql.Node { return nil }
+
+// RowIter implements the sql.Node interface.
+func (s *ShowBinlogEvents) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	log, err := binaryLog(s.Catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := log.Events(s.In, s.From)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Offset >= int64(len(events)) {
+		events = nil
+	} else {
+		events = events[s.Offset:]
+	}
+	if s.Limit >= 0 && s.Limit < int64(len(events)) {
+		events = events[:s.Limit]
+	}
+
+	rows := make([]sql.Row, len(events))
+	for i, e := range events {
+		rows[i] = sql.NewRow(e.LogName, e.Pos, e.Type, e.ServerID, e.EndPos, e.Info)
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// ShowMasterStatus is a node that shows the position of the binary log of the catalog.
+type ShowMasterStatus struct {
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*ShowMasterStatus)(nil)
+
+// NewShowMasterStatus returns a new ShowMasterStatus node.
+func NewShowMasterStatus() *ShowMasterStatus {
+	return &ShowMasterStatus{}
+}
+
+// Resolved implements the sql.Node interface.
+func (s *ShowMasterStatus) Resolved() bool {
+	return true
+}
+
+// WithChildren implements the sql.Node interface.
+func (s *ShowMasterStatus) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(s, len(children), 0)
+	}
+	return s, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (s *ShowMasterStatus) String() string {
+	return "SHOW MASTER STATUS"
+}
+
+// Schema implements the sql.Node interface.
+func (s *ShowMasterStatus) Schema() sql.Schema {
+	return sql.Schema{
+		&sql.Column{Name: "File", Type: sql.LongText},
+		&sql.Column{Name: "Position", Type: sql.Uint64},
+		&sql.Column{Name: "Binlog_Do_DB", Type: sql.LongText},
+		&sql.Column{Name: "Binlog_Ignore_DB", Type: sql.LongText},
+		&sql.Column{Name: "Executed_Gtid_Set", Type: sql.LongText},
+	}
+}
+
+// Children implements the sql.Node interface.
+func (s *ShowMasterStatus) Children() []sql.Node { return nil }
+
+// RowIter implements the sql.Node interface. Like MySQL, it shows no rows when there's no binary log.
+func (s *ShowMasterStatus) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	log, err := binaryLog(s.Catalog)
+	if sql.ErrNoBinaryLog.Is(err) {
+		return sql.RowsToRowIter(), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	file, pos, executed := log.Position()
+	return sql.RowsToRowIter(sql.NewRow(file, pos, "", "", executed)), nil
+}
+
+func binaryLog(c *sql.Catalog) (sql.BinaryLog, error) {
+	if c == nil {
+		return nil, sql.ErrNoBinaryLog.New()
+	}
+	return c.BinaryLog()
+}
@@ -95,6 +95,11 @@ func (s *ShowColumns) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 			defaultVal = col.Default.String()
 		}
 
+		extra := col.Extra
+		if col.Invisible {
+			extra = strings.TrimSpace(extra + " INVISIBLE")
+		}
+
 		// TODO: rather than lower-casing here, we should lower-case the String() method of types
 		if s.Full {
 			row = sql.Row{
@@ -104,7 +109,7 @@ func (s *ShowColumns) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 				null,
 				key,
 				defaultVal,
-				col.Extra,
+				extra,
 				"", // Privileges
 				col.Comment,
 			}
@@ -115,7 +120,7 @@ func (s *ShowColumns) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 				null,
 				key,
 				defaultVal,
-				col.Extra,
+				extra,
 			}
 		}
 
@@ -38,10 +38,10 @@ func (*ShowDatabases) Schema() sql.Schema {
 
 // RowIter implements the Node interface.
 func (p *ShowDatabases) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
-	dbs := p.Catalog.AllDatabases()
-	var rows = make([]sql.Row, 0, len(dbs))
-	for _, db := range dbs {
-		rows = append(rows, sql.Row{db.Name()})
+	names := p.Catalog.DatabaseNames()
+	var rows = make([]sql.Row, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, sql.Row{name})
 	}
 
 	sort.Slice(rows, func(i, j int) bool {
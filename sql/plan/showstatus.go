@@ -0,0 +1,108 @@
+package plan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// ShowStatus is a node that shows the status variables of the catalog. Unless global, the variables counted for each
+// session have the values of the current session.
+type ShowStatus struct {
+	pattern string
+	global  bool
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*ShowStatus)(nil)
+
+// NewShowStatus returns a new ShowStatus reference. If like is an empty string it will return all variables.
+func NewShowStatus(like string, global bool) *ShowStatus {
+	return &ShowStatus{pattern: like, global: global}
+}
+
+// Resolved implements sql.Node interface. The function always returns true.
+func (s *ShowStatus) Resolved() bool {
+	return true
+}
+
+// WithChildren implements the Node interface.
+func (s *ShowStatus) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(s, len(children), 0)
+	}
+
+	return s, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (s *ShowStatus) String() string {
+	var like string
+	if s.pattern != "" {
+		like = fmt.Sprintf(" LIKE '%s'", s.pattern)
+	}
+
+	var scope string
+	if s.global {
+		scope = " GLOBAL"
+	}
+	return fmt.Sprintf("SHOW%s STATUS%s", scope, like)
+}
+
+// Schema returns a new Schema reference for "SHOW STATUS" query.
+func (*ShowStatus) Schema() sql.Schema {
+	return sql.Schema{
+		&sql.Column{Name: "Variable_name", Type: sql.LongText, Nullable: false},
+		&sql.Column{Name: "Value", Type: sql.LongText, Nullable: true},
+	}
+}
+
+// Children implements sql.Node interface. The function always returns nil.
+func (*ShowStatus) Children() []sql.Node { return nil }
+
+// RowIter implements the sql.Node interface. Variables are sorted by name.
+func (s *ShowStatus) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	var like sql.Expression
+	if s.pattern != "" {
+		like = expression.NewLike(
+			expression.NewGetField(0, sql.LongText, "", false),
+			expression.NewGetField(1, sql.LongText, s.pattern, false),
+		)
+	}
+
+	var status = make(map[string]interface{})
+	if s.Catalog != nil && s.global {
+		status = s.Catalog.Status()
+	} else if s.Catalog != nil {
+		status = s.Catalog.StatusOfSession(ctx)
+	}
+
+	var rows []sql.Row
+	for k, v := range status {
+		if like != nil {
+			// Status variables are matched regardless of case, as in MySQL.
+			b, err := like.Eval(ctx, sql.NewRow(strings.ToLower(k), strings.ToLower(s.pattern)))
+			if err != nil {
+				return nil, err
+			}
+			if !b.(bool) {
+				continue
+			}
+		}
+
+		var value interface{}
+		if v != nil {
+			value = fmt.Sprint(v)
+		}
+		rows = append(rows, sql.NewRow(k, value))
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i][0].(string) < rows[j][0].(string)
+	})
+
+	return sql.RowsToRowIter(rows...), nil
+}
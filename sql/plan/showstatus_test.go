@@ -0,0 +1,62 @@
+package plan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+type testStatus map[string]interface{}
+
+func (s testStatus) Status() map[string]interface{} { return s }
+
+func TestShowStatus(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddStatusProvider(testStatus{"Locked_connects": uint64(2), "Uptime": 10})
+	catalog.AddStatusProvider(testStatus{"Aborted_connects": uint64(1), "Empty": nil})
+
+	ss := NewShowStatus("", true)
+	ss.Catalog = catalog
+
+	rows, err := sql.NodeToRows(sql.NewEmptyContext(), ss)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"Aborted_connects", "1"},
+		{"Empty", nil},
+		{"Locked_connects", "2"},
+		{"Uptime", "10"},
+	}, rows)
+
+	ss = NewShowStatus("%connects", true)
+	ss.Catalog = catalog
+
+	rows, err = sql.NodeToRows(sql.NewEmptyContext(), ss)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"Aborted_connects", "1"},
+		{"Locked_connects", "2"},
+	}, rows)
+
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession()))
+	catalog.IncrementStatus(ctx, sql.StatusQuestions, 2)
+	catalog.IncrementStatus(sql.NewEmptyContext(), sql.StatusQuestions, 1)
+
+	ss = NewShowStatus("questions", true)
+	ss.Catalog = catalog
+
+	rows, err = sql.NodeToRows(ctx, ss)
+	require.NoError(err)
+	require.Equal([]sql.Row{{"Questions", "3"}}, rows)
+
+	ss = NewShowStatus("questions", false)
+	ss.Catalog = catalog
+
+	rows, err = sql.NodeToRows(ctx, ss)
+	require.NoError(err)
+	require.Equal([]sql.Row{{"Questions", "2"}}, rows)
+}
@@ -51,19 +51,11 @@ func (s *ShowTableStatus) Schema() sql.Schema { return showTableStatusSchema }
 
 // RowIter implements the sql.Node interface.
 func (s *ShowTableStatus) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
-	var rows []sql.Row
-	var tables []string
-	var err error
+	var dbs []sql.Database
 	if len(s.Databases) > 0 {
 		for _, db := range s.Catalog.AllDatabases() {
-			if !stringContains(s.Databases, db.Name()) {
-				continue
-			}
-
-			tables, err = db.GetTableNames(ctx)
-
-			if err != nil {
-				return nil, err
+			if stringContains(s.Databases, db.Name()) {
+				dbs = append(dbs, db)
 			}
 		}
 	} else {
@@ -71,17 +63,32 @@ func (s *ShowTableStatus) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, e
 		if err != nil {
 			return nil, err
 		}
+		dbs = append(dbs, db)
+	}
 
-		tables, err = db.GetTableNames(ctx)
-
+	var rows []sql.Row
+	for _, db := range dbs {
+		tables, err := db.GetTableNames(ctx)
 		if err != nil {
 			return nil, err
 		}
-	}
 
-	sort.Strings(tables)
-	for _, t := range tables {
-		rows = append(rows, tableToStatusRow(t))
+		sort.Strings(tables)
+		for _, name := range tables {
+			table, ok, err := db.GetTableInsensitive(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, sql.ErrTableNotFound.New(name)
+			}
+
+			row, err := tableToStatusRow(ctx, name, table)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
 	}
 
 	return sql.RowsToRowIter(rows...), nil
@@ -109,18 +116,27 @@ func stringContains(slice []string, str string) bool {
 	return false
 }
 
-func tableToStatusRow(table string) sql.Row {
+func tableToStatusRow(ctx *sql.Context, name string, table sql.Table) (sql.Row, error) {
+	var rows, avgRowLength, dataLength int64
+	stats, err := sql.GetTableStatistics(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	if stats != nil {
+		rows, avgRowLength, dataLength = int64(stats.RowCount), int64(stats.AvgRowSize), int64(stats.DataLength())
+	}
+
 	return sql.NewRow(
-		table,    // Name
+		name,     // Name
 		"InnoDB", // Engine
 		// This column is unused. With the removal of .frm files in MySQL 8.0, this
 		// column now reports a hardcoded value of 10, which is the last .frm file
 		// version used in MySQL 5.7.
 		"10",                           // Version
 		"Fixed",                        // Row_format
-		int64(0),                       // Rows
-		int64(0),                       // Avg_row_length
-		int64(0),                       // Data_length
+		rows,                           // Rows
+		avgRowLength,                   // Avg_row_length
+		dataLength,                     // Data_length
 		int64(0),                       // Max_data_length
 		int64(0),                       // Index_length
 		int64(0),                       // Data_free
@@ -131,5 +147,5 @@ func tableToStatusRow(table string) sql.Row {
 		sql.Collation_Default.String(), // Collation
 		nil,                            // Create_options
 		nil,                            // Comments
-	)
+	), nil
 }
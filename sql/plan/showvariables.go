@@ -2,24 +2,25 @@ package plan
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/expression"
 )
 
-// ShowVariables is a node that shows the global and session variables
+// ShowVariables is a node that shows the system variables, with their values in the session unless global.
 type ShowVariables struct {
-	config  map[string]sql.TypedValue
 	pattern string
+	global  bool
 }
 
 // NewShowVariables returns a new ShowVariables reference.
-// config is a variables lookup table
 // like is a "like pattern". If like is an empty string it will return all variables.
-func NewShowVariables(config map[string]sql.TypedValue, like string) *ShowVariables {
+// global is whether to show the global values of the variables instead of their values in the session.
+func NewShowVariables(like string, global bool) *ShowVariables {
 	return &ShowVariables{
-		config:  config,
 		pattern: like,
+		global:  global,
 	}
 }
 
@@ -43,7 +44,12 @@ func (sv *ShowVariables) String() string {
 	if sv.pattern != "" {
 		like = fmt.Sprintf(" LIKE '%s'", sv.pattern)
 	}
-	return fmt.Sprintf("SHOW VARIABLES%s", like)
+
+	var scope string
+	if sv.global {
+		scope = " GLOBAL"
+	}
+	return fmt.Sprintf("SHOW%s VARIABLES%s", scope, like)
 }
 
 // Schema returns a new Schema reference for "SHOW VARIABLES" query.
@@ -58,7 +64,8 @@ func (*ShowVariables) Schema() sql.Schema {
 func (*ShowVariables) Children() []sql.Node { return nil }
 
 // RowIter implements the sql.Node interface.
-// The function returns an iterator for filtered variables (based on like pattern)
+// The function returns an iterator for filtered variables (based on like pattern), sorted by name. Variables without
+// a value in the session show their global value.
 func (sv *ShowVariables) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 	var (
 		rows []sql.Row
@@ -71,9 +78,10 @@ func (sv *ShowVariables) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, er
 		)
 	}
 
-	for k, v := range sv.config {
+	for _, v := range ctx.SystemVariables().All() {
 		if like != nil {
-			b, err := like.Eval(ctx, sql.NewRow(k, sv.pattern))
+			// Variables are matched regardless of case, as in MySQL.
+			b, err := like.Eval(ctx, sql.NewRow(v.Name, strings.ToLower(sv.pattern)))
 			if err != nil {
 				return nil, err
 			}
@@ -82,7 +90,17 @@ func (sv *ShowVariables) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, er
 			}
 		}
 
-		rows = append(rows, sql.NewRow(k, v.Value))
+		var value interface{}
+		if sv.global || !v.HasSession() {
+			var ok bool
+			if value, ok = ctx.SystemVariables().Global(v.Name); !ok {
+				continue
+			}
+		} else {
+			_, value = ctx.Get(v.Name)
+		}
+
+		rows = append(rows, sql.NewRow(v.Name, value))
 	}
 
 	return sql.RowsToRowIter(rows...), nil
@@ -1,7 +1,9 @@
 package plan
 
 import (
+	"context"
 	"io"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -14,56 +16,60 @@ func TestShowVariables(t *testing.T) {
 
 	ctx := sql.NewEmptyContext()
 	config := ctx.Session.GetAll()
-	sv := NewShowVariables(config, "")
+	sv := NewShowVariables("", false)
 	require.True(sv.Resolved())
 
 	it, err := sv.RowIter(ctx, nil)
 	require.NoError(err)
 
+	var names []string
 	for row, err := it.Next(); err == nil; row, err = it.Next() {
 		key := row[0].(string)
 		val := row[1]
 
 		t.Logf("key: %s\tval: %v\n", key, val)
 
-		require.Equal(config[key].Value, val)
-		delete(config, key)
+		if v, ok := config[key]; ok {
+			require.Equal(v.Value, val)
+			delete(config, key)
+		} else {
+			global, ok := ctx.SystemVariables().Global(key)
+			require.True(ok)
+			require.Equal(global, val)
+		}
+		names = append(names, key)
 	}
 	if err != io.EOF {
 		require.NoError(err)
 	}
 	require.NoError(it.Close())
 	require.Equal(0, len(config))
+	require.True(sort.StringsAreSorted(names))
 }
 
 func TestShowVariablesWithLike(t *testing.T) {
 	require := require.New(t)
 
-	vars := map[string]sql.TypedValue{
-		"int1": {Typ: sql.Int32, Value: 1},
-		"int2": {Typ: sql.Int32, Value: 2},
-		"txt":  {Typ: sql.LongText, Value: "abcdefghijklmnoprstuwxyz"},
-	}
+	session := sql.NewBaseSession()
+	ctx := sql.NewContext(context.Background(), sql.WithSession(session))
+	require.NoError(session.Set(ctx, "wait_timeout", sql.Int64, int64(10)))
 
-	sv := NewShowVariables(vars, "int%")
+	sv := NewShowVariables("%_TIMEOUT", false)
 	require.True(sv.Resolved())
 
-	it, err := sv.RowIter(sql.NewEmptyContext(), nil)
+	rows, err := sql.NodeToRows(ctx, sv)
 	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"innodb_lock_wait_timeout", int64(50)},
+		{"interactive_timeout", int64(28800)},
+		{"lock_wait_timeout", int64(31536000)},
+		{"net_read_timeout", int64(30)},
+		{"net_write_timeout", int64(60)},
+		{"wait_timeout", int64(10)},
+	}, rows)
 
-	for row, err := it.Next(); err == nil; row, err = it.Next() {
-		key := row[0].(string)
-		val := row[1]
-		require.Equal(vars[key].Value, val)
-		require.Equal(sql.Int32, vars[key].Typ)
-		delete(vars, key)
-	}
-	if err != io.EOF {
-		require.NoError(err)
-	}
-	require.NoError(it.Close())
-	require.Equal(1, len(vars))
-
-	_, ok := vars["txt"]
-	require.True(ok)
+	sv = NewShowVariables("wait_timeout", true)
+	rows, err = sql.NodeToRows(ctx, sv)
+	require.NoError(err)
+	require.Equal([]sql.Row{{"wait_timeout", int64(28800)}}, rows)
 }
@@ -48,3 +48,59 @@ func (sw ShowWarnings) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, erro
 
 	return sql.RowsToRowIter(rows...), nil
 }
+
+// ShowWarningCount is a node that shows the number of warnings of the session, for SHOW COUNT(*) WARNINGS, or the
+// number of them of the error level, for SHOW COUNT(*) ERRORS.
+type ShowWarningCount struct {
+	count  int
+	errors bool
+}
+
+var _ sql.Node = (*ShowWarningCount)(nil)
+
+// NewShowWarningCount creates a ShowWarningCount node showing the number of warnings given.
+func NewShowWarningCount(count int) *ShowWarningCount {
+	return &ShowWarningCount{count: count}
+}
+
+// NewShowErrorCount creates a ShowWarningCount node showing the number of errors given.
+func NewShowErrorCount(count int) *ShowWarningCount {
+	return &ShowWarningCount{count: count, errors: true}
+}
+
+// Resolved implements the sql.Node interface.
+func (*ShowWarningCount) Resolved() bool {
+	return true
+}
+
+// WithChildren implements the sql.Node interface.
+func (s *ShowWarningCount) WithChildren(children ...sql.Node) (sql.Node, error) {
+	return NillaryWithChildren(s, children...)
+}
+
+// String implements the fmt.Stringer interface.
+func (s *ShowWarningCount) String() string {
+	if s.errors {
+		return "SHOW COUNT(*) ERRORS"
+	}
+	return "SHOW COUNT(*) WARNINGS"
+}
+
+// Schema implements the sql.Node interface.
+func (s *ShowWarningCount) Schema() sql.Schema {
+	name := "@@session.warning_count"
+	if s.errors {
+		name = "@@session.error_count"
+	}
+	return sql.Schema{
+		&sql.Column{Name: name, Type: sql.Int64, Nullable: false},
+	}
+}
+
+// Children implements the sql.Node interface.
+func (*ShowWarningCount) Children() []sql.Node { return nil }
+
+// RowIter implements the sql.Node interface.
+func (s *ShowWarningCount) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	return sql.RowsToRowIter(sql.NewRow(int64(s.count))), nil
+}
@@ -0,0 +1,58 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// UnresolvedTableFunction is a call to a table function in the FROM clause of a query that has not been resolved yet.
+// It's replaced during analysis with the node returned by the sql.TableFunction of the same name.
+type UnresolvedTableFunction struct {
+	name      string
+	Arguments []sql.Expression
+}
+
+// NewUnresolvedTableFunction creates a new UnresolvedTableFunction.
+func NewUnresolvedTableFunction(name string, args ...sql.Expression) *UnresolvedTableFunction {
+	return &UnresolvedTableFunction{name, args}
+}
+
+// Name implements the Nameable interface.
+func (t *UnresolvedTableFunction) Name() string {
+	return t.name
+}
+
+// Resolved implements the Resolvable interface.
+func (*UnresolvedTableFunction) Resolved() bool {
+	return false
+}
+
+// Children implements the Node interface.
+func (*UnresolvedTableFunction) Children() []sql.Node { return nil }
+
+// Schema implements the Node interface.
+func (*UnresolvedTableFunction) Schema() sql.Schema { return nil }
+
+// RowIter implements the RowIter interface.
+func (*UnresolvedTableFunction) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	return nil, ErrUnresolvedTable.New()
+}
+
+// WithChildren implements the Node interface.
+func (t *UnresolvedTableFunction) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(t, len(children), 0)
+	}
+
+	return t, nil
+}
+
+func (t *UnresolvedTableFunction) String() string {
+	var args = make([]string, len(t.Arguments))
+	for i, arg := range t.Arguments {
+		args[i] = arg.String()
+	}
+	return fmt.Sprintf("UnresolvedTableFunction(%s(%s))", t.name, strings.Join(args, ", "))
+}
@@ -2,14 +2,19 @@ package plan
 
 import "github.com/dolthub/go-mysql-server/sql"
 
-// Begin starts a transaction. This is provided just for compatibility with SQL clients and is a no-op.
+// Begin starts a transaction in the sessions implementing sql.ExplicitTransactionSession, and is a no-op in the others.
 type Begin struct{}
 
 // NewBegin creates a new Begin node.
 func NewBegin() *Begin { return new(Begin) }
 
 // RowIter implements the sql.Node interface.
-func (*Begin) RowIter(*sql.Context, sql.Row) (sql.RowIter, error) {
+func (*Begin) RowIter(ctx *sql.Context, _ sql.Row) (sql.RowIter, error) {
+	if s, ok := ctx.Session.(sql.ExplicitTransactionSession); ok {
+		if err := s.StartTransaction(ctx); err != nil {
+			return nil, err
+		}
+	}
 	return sql.RowsToRowIter(), nil
 }
 
@@ -33,15 +38,20 @@ func (*Begin) Children() []sql.Node { return nil }
 // Schema implements the sql.Node interface.
 func (*Begin) Schema() sql.Schema { return nil }
 
-// Commit commits the changes performed in a transaction. This is provided just for compatibility with SQL clients and
-// is a no-op.
+// Commit commits the changes performed in the transaction started in the sessions implementing
+// sql.ExplicitTransactionSession. The server commits the transactions of the others after it.
 type Commit struct{}
 
 // NewCommit creates a new Commit node.
 func NewCommit() *Commit { return new(Commit) }
 
 // RowIter implements the sql.Node interface.
-func (*Commit) RowIter(*sql.Context, sql.Row) (sql.RowIter, error) {
+func (*Commit) RowIter(ctx *sql.Context, _ sql.Row) (sql.RowIter, error) {
+	if s, ok := ctx.Session.(sql.ExplicitTransactionSession); ok && s.InTransaction() {
+		if err := s.CommitTransaction(ctx); err != nil {
+			return nil, err
+		}
+	}
 	return sql.RowsToRowIter(), nil
 }
 
@@ -65,15 +75,20 @@ func (*Commit) Children() []sql.Node { return nil }
 // Schema implements the sql.Node interface.
 func (*Commit) Schema() sql.Schema { return nil }
 
-// Rollback undoes the changes performed in a transaction. This is provided just for compatibility with SQL clients and
-// is a no-op.
+// Rollback undoes the changes performed in the transaction of the sessions implementing sql.TransactionSession, and is
+// a no-op in the others.
 type Rollback struct{}
 
 // NewRollback creates a new Rollback node.
 func NewRollback() *Rollback { return new(Rollback) }
 
 // RowIter implements the sql.Node interface.
-func (*Rollback) RowIter(*sql.Context, sql.Row) (sql.RowIter, error) {
+func (*Rollback) RowIter(ctx *sql.Context, _ sql.Row) (sql.RowIter, error) {
+	if s, ok := ctx.Session.(sql.TransactionSession); ok {
+		if err := s.Rollback(ctx); err != nil {
+			return nil, err
+		}
+	}
 	return sql.RowsToRowIter(), nil
 }
 
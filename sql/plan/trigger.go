@@ -127,20 +127,37 @@ func (t *triggerIter) Next() (row sql.Row, returnErr error) {
 		return nil, err
 	}
 
-	// Wrap the execution logic with the current child row before executing it.
-	logic, err := TransformUpWithParent(t.executionLogic, prependRowInPlanForTriggerExecution(childRow))
+	logic, logicRow, err := executeTriggerLogic(t.ctx, t.executionLogic, childRow)
 	if err != nil {
 		return nil, err
 	}
 
+	// For some logic statements, we want to return the result of the logic operation as our row, e.g. a Set that alters
+	// the fields of the new row
+	if ok, returnRow := shouldUseLogicResult(logic, logicRow); ok {
+		return returnRow, nil
+	}
+
+	return childRow, nil
+}
+
+// executeTriggerLogic executes the trigger execution logic given for the row given, and returns the logic executed
+// and the last row it returned.
+func executeTriggerLogic(parentCtx *sql.Context, executionLogic sql.Node, row sql.Row) (logic sql.Node, logicRow sql.Row, returnErr error) {
+	// Wrap the execution logic with the current child row before executing it.
+	logic, err := TransformUpWithParent(executionLogic, prependRowInPlanForTriggerExecution(row))
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// We don't do anything interesting with this subcontext yet, but it's a good idea to cancel it independently of the
 	// parent context if something goes wrong in trigger execution.
-	ctx, cancelFunc := t.ctx.NewSubContext()
+	ctx, cancelFunc := parentCtx.NewSubContext()
 	defer cancelFunc()
 
-	logicIter, err := logic.RowIter(ctx, childRow)
+	logicIter, err := logic.RowIter(ctx, row)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer func() {
@@ -150,25 +167,18 @@ func (t *triggerIter) Next() (row sql.Row, returnErr error) {
 		}
 	}()
 
-	var logicRow sql.Row
 	for {
-		row, err := logicIter.Next()
+		next, err := logicIter.Next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		logicRow = row
+		logicRow = next
 	}
 
-	// For some logic statements, we want to return the result of the logic operation as our row, e.g. a Set that alters
-	// the fields of the new row
-	if ok, returnRow := shouldUseLogicResult(logic, logicRow); ok {
-		return returnRow, nil
-	}
-
-	return childRow, nil
+	return logic, logicRow, nil
 }
 
 func shouldUseLogicResult(logic sql.Node, row sql.Row) (bool, sql.Row) {
@@ -2,10 +2,12 @@ package plan
 
 import (
 	"fmt"
+	"strings"
 
 	"gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
 )
 
 var ErrUpdateNotSupported = errors.NewKind("table doesn't support UPDATE")
@@ -14,11 +16,31 @@ var ErrUpdateUnexpectedSetResult = errors.NewKind("attempted to set field but ex
 // Update is a node for updating rows on tables.
 type Update struct {
 	UnaryNode
+	// ForeignKeys checks the rows updated reference existing rows and applies the referential actions of the foreign
+	// keys referencing the table to them, unless foreign_key_checks is off, or is nil if there are none.
+	ForeignKeys *ForeignKeyEditor
+	// Checks are the CHECK constraints of the table, which the rows updated must satisfy while they're enforced.
+	Checks []*sql.CheckConstraint
 }
 
 // NewUpdate creates an Update node.
 func NewUpdate(n sql.Node, updateExprs []sql.Expression) *Update {
-	return &Update{UnaryNode{NewUpdateSource(n, updateExprs)}}
+	return &Update{UnaryNode: UnaryNode{NewUpdateSource(n, updateExprs)}}
+}
+
+// WithForeignKeys returns a copy of this node that checks the foreign keys of the editor given for the rows updated
+// and applies their referential actions to them.
+func (u *Update) WithForeignKeys(editor *ForeignKeyEditor) *Update {
+	nu := *u
+	nu.ForeignKeys = editor
+	return &nu
+}
+
+// WithChecks returns a copy of this node that checks the rows updated satisfy the CHECK constraints given.
+func (u *Update) WithChecks(checks []*sql.CheckConstraint) *Update {
+	nu := *u
+	nu.Checks = checks
+	return &nu
 }
 
 func getUpdatable(node sql.Node) (sql.UpdatableTable, error) {
@@ -61,23 +83,38 @@ func (ui UpdateInfo) String() string {
 }
 
 type updateIter struct {
-	childIter sql.RowIter
-	schema    sql.Schema
-	updater   sql.RowUpdater
-	ctx       *sql.Context
-	closed    bool
+	childIter   sql.RowIter
+	schema      sql.Schema
+	updater     sql.RowUpdater
+	ctx         *sql.Context
+	foreignKeys *ForeignKeyEditor
+	cascade     *foreignKeyCascade
+	checks      []*sql.CheckConstraint
+	closed      bool
 }
 
 func (u *updateIter) Next() (sql.Row, error) {
+	warnings := u.ctx.WarningCount()
 	oldAndNewRow, err := u.childIter.Next()
 	if err != nil {
 		return nil, err
 	}
+	if err = sql.CheckDivisionByZero(u.ctx, warnings); err != nil {
+		return nil, err
+	}
 
 	oldRow, newRow := oldAndNewRow[:len(oldAndNewRow)/2], oldAndNewRow[len(oldAndNewRow)/2:]
 	if equals, err := oldRow.Equals(newRow, u.schema); err == nil {
 		if !equals {
-			err = u.updater.Update(u.ctx, oldRow, newRow)
+			if err := checkRow(u.ctx, u.checks, newRow); err != nil {
+				return nil, err
+			}
+
+			if u.cascade != nil {
+				err = u.cascade.update(u.ctx, u.foreignKeys, oldRow, newRow, 0, nil)
+			} else {
+				err = u.updater.Update(u.ctx, oldRow, newRow)
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -107,9 +144,56 @@ func applyUpdateExpressions(ctx *sql.Context, updateExprs []sql.Expression, row
 	return prev, nil
 }
 
+// applyOnUpdateExpressions returns the new row of an update with the columns of the schema given that have an ON
+// UPDATE value set to it, unless the update expressions assign them or the row is unchanged, like MySQL does.
+func applyOnUpdateExpressions(ctx *sql.Context, schema sql.Schema, updateExprs []sql.Expression, oldRow, newRow sql.Row) (sql.Row, error) {
+	var onUpdate bool
+	for _, col := range schema {
+		if col.OnUpdate != nil {
+			onUpdate = true
+			break
+		}
+	}
+	if !onUpdate {
+		return newRow, nil
+	}
+
+	unchanged, err := oldRow.Equals(newRow, schema)
+	if err != nil || unchanged {
+		return newRow, err
+	}
+
+	assigned := make(map[string]bool)
+	for _, updateExpr := range updateExprs {
+		if setField, ok := updateExpr.(*expression.SetField); ok {
+			if field, ok := setField.Left.(*expression.GetField); ok {
+				assigned[strings.ToLower(field.Name())] = true
+			}
+		}
+	}
+
+	row := newRow.Copy()
+	for i, col := range schema {
+		if col.OnUpdate == nil || assigned[strings.ToLower(col.Name)] {
+			continue
+		}
+		val, err := col.OnUpdate.Eval(ctx, newRow)
+		if err != nil {
+			return nil, err
+		}
+		row[i] = val
+	}
+	return row, nil
+}
+
 func (u *updateIter) Close() error {
 	if !u.closed {
 		u.closed = true
+		if u.cascade != nil {
+			if err := u.cascade.Close(u.ctx); err != nil {
+				return err
+			}
+		}
 		if err := u.updater.Close(u.ctx); err != nil {
 			return err
 		}
@@ -140,7 +224,13 @@ func (u *Update) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 		return nil, err
 	}
 
-	return newUpdateIter(iter, updatable.Schema(), updater, ctx), nil
+	ui := newUpdateIter(iter, updatable.Schema(), updater, ctx)
+	ui.checks = u.Checks
+	if u.ForeignKeys != nil && sql.ForeignKeyChecks(ctx) {
+		ui.foreignKeys = u.ForeignKeys
+		ui.cascade = newForeignKeyCascade(u.ForeignKeys, nil, updater)
+	}
+	return ui, nil
 }
 
 // WithChildren implements the Node interface.
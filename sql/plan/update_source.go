@@ -115,6 +115,11 @@ func (u *updateSourceIter) Next() (sql.Row, error) {
 		newRow = newRow[len(newRow)-expectedSchemaLen:]
 	}
 
+	newRow, err = applyOnUpdateExpressions(u.ctx, u.tableSchema, u.updateExprs, oldRow, newRow)
+	if err != nil {
+		return nil, err
+	}
+
 	return oldRow.Append(newRow), nil
 }
 
@@ -0,0 +1,354 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// UserSpec is an account created by CREATE USER, along with its password.
+type UserSpec struct {
+	Account  sql.Account
+	Password string
+}
+
+// CreateUser creates one or more user accounts.
+type CreateUser struct {
+	Users       []UserSpec
+	IfNotExists bool
+	Require     sql.TLSRequirement
+	// Limits is nil unless the statement has a WITH clause.
+	Limits *sql.ResourceLimits
+	// PasswordExpire is nil unless the statement has a PASSWORD EXPIRE clause.
+	PasswordExpire *sql.PasswordExpire
+	Catalog        *sql.Catalog
+}
+
+var _ sql.Node = (*CreateUser)(nil)
+
+// NewCreateUser creates a new CreateUser node. The transport requirements, resource limits and password expiration
+// given apply to all the users.
+func NewCreateUser(users []UserSpec, ifNotExists bool, require sql.TLSRequirement, limits *sql.ResourceLimits, expire *sql.PasswordExpire) *CreateUser {
+	return &CreateUser{Users: users, IfNotExists: ifNotExists, Require: require, Limits: limits, PasswordExpire: expire}
+}
+
+// Children implements the sql.Node interface.
+func (*CreateUser) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*CreateUser) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*CreateUser) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface. As in MySQL, the accounts that can be created are created even if some
+// of them fail, which are reported in a single error. Passwords that are not strong enough are reported as they are.
+func (n *CreateUser) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	um, err := n.Catalog.UserManager()
+	if err != nil {
+		return nil, err
+	}
+
+	var rm sql.ResourceManager
+	if n.Limits != nil {
+		if rm, err = n.Catalog.ResourceManager(); err != nil {
+			return nil, err
+		}
+	}
+
+	var pm sql.PasswordManager
+	if n.PasswordExpire != nil {
+		if pm, err = n.Catalog.PasswordManager(); err != nil {
+			return nil, err
+		}
+	}
+
+	var failed []sql.Account
+	for _, u := range n.Users {
+		err := um.CreateUser(ctx, u.Account, u.Password, n.Require)
+		if err == nil && rm != nil {
+			err = rm.SetResourceLimits(ctx, u.Account, *n.Limits)
+		}
+		if err == nil && pm != nil {
+			err = pm.SetPasswordExpire(ctx, u.Account, *n.PasswordExpire)
+		}
+
+		if sql.ErrUserAlreadyExists.Is(err) && n.IfNotExists {
+			ctx.Note(3163, "Authorization ID %s already exists.", u.Account)
+		} else if sql.ErrPasswordPolicy.Is(err) {
+			return nil, err
+		} else if err != nil {
+			failed = append(failed, u.Account)
+		}
+	}
+
+	if len(failed) > 0 {
+		return nil, sql.ErrUserOperationFailed.New("CREATE USER", joinAccounts(failed))
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *CreateUser) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface. Passwords are never shown.
+func (n *CreateUser) String() string {
+	var accounts = make([]sql.Account, len(n.Users))
+	for i, u := range n.Users {
+		accounts[i] = u.Account
+	}
+
+	var ifNotExists string
+	if n.IfNotExists {
+		ifNotExists = "IF NOT EXISTS "
+	}
+
+	var require string
+	if r := n.Require.String(); r != "" {
+		require = " " + r
+	}
+
+	return fmt.Sprintf("CREATE USER %s%s%s%s", ifNotExists, joinAccounts(accounts), require, userOptions(n.Limits, n.PasswordExpire))
+}
+
+// AlterUser changes the resource limits and the password expiration of one or more user accounts.
+type AlterUser struct {
+	Accounts []sql.Account
+	IfExists bool
+	// Limits is nil unless the statement has a WITH clause.
+	Limits *sql.ResourceLimits
+	// PasswordExpire is nil unless the statement has a PASSWORD EXPIRE clause.
+	PasswordExpire *sql.PasswordExpire
+	Catalog        *sql.Catalog
+}
+
+var _ sql.Node = (*AlterUser)(nil)
+
+// NewAlterUser creates a new AlterUser node. At least one of the resource limits and the password expiration should
+// be given.
+func NewAlterUser(accounts []sql.Account, ifExists bool, limits *sql.ResourceLimits, expire *sql.PasswordExpire) *AlterUser {
+	return &AlterUser{Accounts: accounts, IfExists: ifExists, Limits: limits, PasswordExpire: expire}
+}
+
+// Children implements the sql.Node interface.
+func (*AlterUser) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*AlterUser) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*AlterUser) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *AlterUser) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	var rm sql.ResourceManager
+	var err error
+	if n.Limits != nil {
+		if rm, err = n.Catalog.ResourceManager(); err != nil {
+			return nil, err
+		}
+	}
+
+	var pm sql.PasswordManager
+	if n.PasswordExpire != nil {
+		if pm, err = n.Catalog.PasswordManager(); err != nil {
+			return nil, err
+		}
+	}
+
+	var failed []sql.Account
+	for _, a := range n.Accounts {
+		var err error
+		if rm != nil {
+			err = rm.SetResourceLimits(ctx, a, *n.Limits)
+		}
+		if err == nil && pm != nil {
+			err = pm.SetPasswordExpire(ctx, a, *n.PasswordExpire)
+		}
+
+		if sql.ErrUserNotFound.Is(err) && n.IfExists {
+			ctx.Note(3162, "Authorization ID %s does not exist.", a)
+		} else if err != nil {
+			failed = append(failed, a)
+		}
+	}
+
+	if len(failed) > 0 {
+		return nil, sql.ErrUserOperationFailed.New("ALTER USER", joinAccounts(failed))
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *AlterUser) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *AlterUser) String() string {
+	var ifExists string
+	if n.IfExists {
+		ifExists = "IF EXISTS "
+	}
+	return fmt.Sprintf("ALTER USER %s%s%s", ifExists, joinAccounts(n.Accounts), userOptions(n.Limits, n.PasswordExpire))
+}
+
+// userOptions returns the WITH and PASSWORD EXPIRE clauses of CREATE USER and ALTER USER statements, preceded by a
+// space, or an empty string if neither is given.
+func userOptions(limits *sql.ResourceLimits, expire *sql.PasswordExpire) string {
+	var options string
+	if limits != nil {
+		options += " " + limits.String()
+	}
+	if expire != nil {
+		options += " " + expire.String()
+	}
+	return options
+}
+
+// DropUser removes one or more user accounts.
+type DropUser struct {
+	Accounts []sql.Account
+	IfExists bool
+	Catalog  *sql.Catalog
+}
+
+var _ sql.Node = (*DropUser)(nil)
+
+// NewDropUser creates a new DropUser node.
+func NewDropUser(accounts []sql.Account, ifExists bool) *DropUser {
+	return &DropUser{Accounts: accounts, IfExists: ifExists}
+}
+
+// Children implements the sql.Node interface.
+func (*DropUser) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*DropUser) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*DropUser) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *DropUser) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	um, err := n.Catalog.UserManager()
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []sql.Account
+	for _, a := range n.Accounts {
+		err := um.DropUser(ctx, a)
+		if sql.ErrUserNotFound.Is(err) && n.IfExists {
+			ctx.Note(3162, "Authorization ID %s does not exist.", a)
+		} else if err != nil {
+			failed = append(failed, a)
+		}
+	}
+
+	if len(failed) > 0 {
+		return nil, sql.ErrUserOperationFailed.New("DROP USER", joinAccounts(failed))
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *DropUser) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface.
+func (n *DropUser) String() string {
+	var ifExists string
+	if n.IfExists {
+		ifExists = "IF EXISTS "
+	}
+	return fmt.Sprintf("DROP USER %s%s", ifExists, joinAccounts(n.Accounts))
+}
+
+// SetPassword changes the password of a user account. If no account is given, the password of the account of the
+// current session is changed.
+type SetPassword struct {
+	For      *sql.Account
+	Password string
+	Catalog  *sql.Catalog
+}
+
+var _ sql.Node = (*SetPassword)(nil)
+
+// NewSetPassword creates a new SetPassword node. The account may be nil to refer to the current user.
+func NewSetPassword(account *sql.Account, password string) *SetPassword {
+	return &SetPassword{For: account, Password: password}
+}
+
+// Children implements the sql.Node interface.
+func (*SetPassword) Children() []sql.Node { return nil }
+
+// Resolved implements the sql.Node interface.
+func (*SetPassword) Resolved() bool { return true }
+
+// Schema implements the sql.Node interface.
+func (*SetPassword) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (n *SetPassword) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	um, err := n.Catalog.UserManager()
+	if err != nil {
+		return nil, err
+	}
+
+	var account sql.Account
+	if n.For != nil {
+		account = *n.For
+	} else {
+		account, err = um.CurrentAccount(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := um.SetPassword(ctx, account, n.Password); err != nil {
+		return nil, err
+	}
+
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (n *SetPassword) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 0)
+	}
+	return n, nil
+}
+
+// String implements the sql.Node interface. The password is never shown.
+func (n *SetPassword) String() string {
+	if n.For == nil {
+		return "SET PASSWORD"
+	}
+	return fmt.Sprintf("SET PASSWORD FOR %s", n.For)
+}
+
+func joinAccounts(accounts []sql.Account) string {
+	var names = make([]string, len(accounts))
+	for i, a := range accounts {
+		names[i] = a.String()
+	}
+	return strings.Join(names, ",")
+}
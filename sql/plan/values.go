@@ -2,6 +2,7 @@ package plan
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/dolthub/go-mysql-server/sql"
@@ -58,23 +59,42 @@ func (p *Values) Resolved() bool {
 	return true
 }
 
-// RowIter implements the Node interface.
+// RowIter implements the Node interface. The tuples are evaluated as their rows are read, so that the rows of large
+// INSERT statements aren't all held in memory at once.
 func (p *Values) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
-	rows := make([]sql.Row, len(p.ExpressionTuples))
-	for i, et := range p.ExpressionTuples {
-		vals := make([]interface{}, len(et))
-		for j, e := range et {
-			var err error
-			vals[j], err = e.Eval(ctx, row)
-			if err != nil {
-				return nil, err
-			}
-		}
+	return &valuesIter{ctx: ctx, tuples: p.ExpressionTuples, row: row}, nil
+}
+
+// valuesIter returns the rows of the tuples of a Values node, evaluating the expressions of each one when its row is
+// read.
+type valuesIter struct {
+	ctx    *sql.Context
+	tuples [][]sql.Expression
+	row    sql.Row
+	pos    int
+}
 
-		rows[i] = sql.NewRow(vals...)
+func (i *valuesIter) Next() (sql.Row, error) {
+	if i.pos >= len(i.tuples) {
+		return nil, io.EOF
 	}
 
-	return sql.RowsToRowIter(rows...), nil
+	tuple := i.tuples[i.pos]
+	i.pos++
+
+	vals := make([]interface{}, len(tuple))
+	for j, e := range tuple {
+		var err error
+		vals[j], err = e.Eval(i.ctx, i.row)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sql.NewRow(vals...), nil
+}
+
+func (i *valuesIter) Close() error {
+	return nil
 }
 
 func (p *Values) String() string {
@@ -104,7 +124,12 @@ func (p *Values) DebugString() string {
 
 // Expressions implements the Expressioner interface.
 func (p *Values) Expressions() []sql.Expression {
-	var exprs []sql.Expression
+	var size int
+	for _, tuple := range p.ExpressionTuples {
+		size += len(tuple)
+	}
+
+	exprs := make([]sql.Expression, 0, size)
 	for _, tuple := range p.ExpressionTuples {
 		exprs = append(exprs, tuple...)
 	}
@@ -131,13 +156,12 @@ func (p *Values) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
 		return nil, sql.ErrInvalidChildrenNumber.New(p, len(exprs), expected)
 	}
 
+	// The tuples share the slice of expressions given rather than copying them, which matters for large INSERTs
 	var offset int
 	var tuples = make([][]sql.Expression, len(p.ExpressionTuples))
 	for i, t := range p.ExpressionTuples {
-		for range t {
-			tuples[i] = append(tuples[i], exprs[offset])
-			offset++
-		}
+		tuples[i] = exprs[offset : offset+len(t) : offset+len(t)]
+		offset += len(t)
 	}
 
 	return NewValues(tuples), nil
@@ -0,0 +1,62 @@
+package plan
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+func TestValuesEvaluatedPerRow(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	values := NewValues([][]sql.Expression{
+		{expression.NewUserVarAssignment(expression.NewUserVar("a"), expression.NewLiteral(int64(1), sql.Int64))},
+		{expression.NewUserVarAssignment(expression.NewUserVar("a"), expression.NewLiteral(int64(2), sql.Int64))},
+	})
+
+	// Each tuple is only evaluated once its row is read
+	iter, err := values.RowIter(ctx, nil)
+	require.NoError(err)
+	_, v := ctx.Get("a")
+	require.Nil(v)
+
+	row, err := iter.Next()
+	require.NoError(err)
+	require.Equal(sql.NewRow(int64(1)), row)
+	_, v = ctx.Get("a")
+	require.Equal(int64(1), v)
+
+	row, err = iter.Next()
+	require.NoError(err)
+	require.Equal(sql.NewRow(int64(2)), row)
+
+	_, err = iter.Next()
+	require.Equal(io.EOF, err)
+	require.NoError(iter.Close())
+}
+
+func TestValuesWithExpressions(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	values := NewValues([][]sql.Expression{
+		{expression.NewLiteral(int64(1), sql.Int64), expression.NewLiteral(int64(2), sql.Int64)},
+		{expression.NewLiteral(int64(3), sql.Int64), expression.NewLiteral(int64(4), sql.Int64)},
+	})
+
+	exprs := values.Expressions()
+	exprs[3] = expression.NewLiteral(int64(5), sql.Int64)
+	n, err := values.WithExpressions(exprs...)
+	require.NoError(err)
+
+	iter, err := n.RowIter(ctx, nil)
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1), int64(2)}, {int64(3), int64(5)}}, rows)
+}
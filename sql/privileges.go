@@ -0,0 +1,379 @@
+package sql
+
+import (
+	"strings"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+var (
+	// ErrPrivilegesNotSupported is returned when a GRANT or REVOKE statement is executed and the authentication
+	// method in use does not manage privileges.
+	ErrPrivilegesNotSupported = errors.NewKind("the authentication method does not support privileges")
+
+	// ErrIllegalGrantForLevel is returned when granting privileges that don't apply to the level given, mirroring
+	// MySQL's ER_ILLEGAL_GRANT_FOR_TABLE.
+	ErrIllegalGrantForLevel = errors.NewKind("Illegal GRANT/REVOKE command; please consult the manual to see which privileges can be used")
+
+	// ErrNonexistingGrant is returned when revoking privileges from an account that has no grants at the level
+	// given, mirroring MySQL's ER_NONEXISTING_GRANT.
+	ErrNonexistingGrant = errors.NewKind("There is no such grant defined for user '%s' on host '%s'")
+
+	// ErrTableAccessDenied is returned when a user lacks a privilege on a table, mirroring MySQL's
+	// ER_TABLEACCESS_DENIED_ERROR.
+	ErrTableAccessDenied = errors.NewKind("%s command denied to user %s for table '%s'")
+
+	// ErrDatabaseAccessDenied is returned when a user lacks a privilege on a database, mirroring MySQL's
+	// ER_DBACCESS_DENIED_ERROR.
+	ErrDatabaseAccessDenied = errors.NewKind("Access denied for user %s to database '%s'")
+
+	// ErrColumnAccessDenied is returned when a user lacks a privilege on a column, mirroring MySQL's
+	// ER_COLUMNACCESS_DENIED_ERROR.
+	ErrColumnAccessDenied = errors.NewKind("%s command denied to user %s for column '%s' in table '%s'")
+
+	// ErrSpecificAccessDenied is returned when a user lacks a global privilege, mirroring MySQL's
+	// ER_SPECIFIC_ACCESS_DENIED_ERROR.
+	ErrSpecificAccessDenied = errors.NewKind("Access denied; you need (at least one of) the %s privilege(s) for this operation")
+)
+
+// Privilege is a set of the privileges that can be granted to an account.
+type Privilege uint64
+
+const (
+	// PrivilegeSelect allows reading rows from tables and views.
+	PrivilegeSelect Privilege = 1 << iota
+	// PrivilegeInsert allows inserting rows into tables.
+	PrivilegeInsert
+	// PrivilegeUpdate allows updating rows of tables.
+	PrivilegeUpdate
+	// PrivilegeDelete allows deleting rows from tables.
+	PrivilegeDelete
+	// PrivilegeCreate allows creating databases and tables.
+	PrivilegeCreate
+	// PrivilegeDrop allows dropping databases, tables and views.
+	PrivilegeDrop
+	// PrivilegeReferences allows creating foreign keys referencing tables.
+	PrivilegeReferences
+	// PrivilegeIndex allows creating and dropping indexes.
+	PrivilegeIndex
+	// PrivilegeAlter allows altering the definition of tables.
+	PrivilegeAlter
+	// PrivilegeCreateView allows creating views.
+	PrivilegeCreateView
+	// PrivilegeShowView allows showing the definition of views.
+	PrivilegeShowView
+	// PrivilegeTrigger allows creating and dropping triggers.
+	PrivilegeTrigger
+	// PrivilegeLockTables allows locking tables the account can read.
+	PrivilegeLockTables
+	// PrivilegeProcess allows seeing the queries of other accounts.
+	PrivilegeProcess
+	// PrivilegeCreateUser allows managing accounts.
+	PrivilegeCreateUser
+	// PrivilegeSuper allows administrative operations, such as killing the queries of other accounts.
+	PrivilegeSuper
+	// PrivilegeGrantOption allows granting the privileges the account has at the same level to other accounts.
+	PrivilegeGrantOption
+	// PrivilegeReplicationSlave allows replicas to read the binary log of the server.
+	PrivilegeReplicationSlave
+	// PrivilegeReplicationClient allows showing the binary logs of the server and their positions.
+	PrivilegeReplicationClient
+)
+
+const (
+	// PrivilegeUsage is the empty set of privileges.
+	PrivilegeUsage Privilege = 0
+
+	// ColumnPrivileges are the privileges that can be granted on columns.
+	ColumnPrivileges = PrivilegeSelect | PrivilegeInsert | PrivilegeUpdate | PrivilegeReferences
+
+	// TablePrivileges are the privileges that can be granted on tables.
+	TablePrivileges = PrivilegeSelect | PrivilegeInsert | PrivilegeUpdate | PrivilegeDelete | PrivilegeCreate |
+		PrivilegeDrop | PrivilegeReferences | PrivilegeIndex | PrivilegeAlter | PrivilegeCreateView |
+		PrivilegeShowView | PrivilegeTrigger | PrivilegeGrantOption
+
+	// DatabasePrivileges are the privileges that can be granted on databases.
+	DatabasePrivileges = TablePrivileges | PrivilegeLockTables
+
+	// GlobalPrivileges are the privileges that can be granted globally.
+	GlobalPrivileges = DatabasePrivileges | PrivilegeProcess | PrivilegeCreateUser | PrivilegeSuper |
+		PrivilegeReplicationSlave | PrivilegeReplicationClient
+
+	// PrivilegeAll are all the privileges but GRANT OPTION, as given by ALL [PRIVILEGES].
+	PrivilegeAll = GlobalPrivileges &^ PrivilegeGrantOption
+)
+
+// privilegeNames are the names of each privilege, in the order MySQL lists them.
+var privilegeNames = []struct {
+	privilege Privilege
+	name      string
+}{
+	{PrivilegeSelect, "SELECT"},
+	{PrivilegeInsert, "INSERT"},
+	{PrivilegeUpdate, "UPDATE"},
+	{PrivilegeDelete, "DELETE"},
+	{PrivilegeCreate, "CREATE"},
+	{PrivilegeDrop, "DROP"},
+	{PrivilegeProcess, "PROCESS"},
+	{PrivilegeReferences, "REFERENCES"},
+	{PrivilegeIndex, "INDEX"},
+	{PrivilegeAlter, "ALTER"},
+	{PrivilegeSuper, "SUPER"},
+	{PrivilegeLockTables, "LOCK TABLES"},
+	{PrivilegeReplicationSlave, "REPLICATION SLAVE"},
+	{PrivilegeReplicationClient, "REPLICATION CLIENT"},
+	{PrivilegeCreateView, "CREATE VIEW"},
+	{PrivilegeShowView, "SHOW VIEW"},
+	{PrivilegeCreateUser, "CREATE USER"},
+	{PrivilegeTrigger, "TRIGGER"},
+	{PrivilegeGrantOption, "GRANT OPTION"},
+}
+
+// ParsePrivilege returns the privileges with the name given, such as SELECT, CREATE VIEW or ALL PRIVILEGES. Names are
+// case insensitive.
+func ParsePrivilege(name string) (Privilege, bool) {
+	name = strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	switch name {
+	case "ALL", "ALL PRIVILEGES":
+		return PrivilegeAll, true
+	case "USAGE":
+		return PrivilegeUsage, true
+	}
+
+	for _, p := range privilegeNames {
+		if p.name == name {
+			return p.privilege, true
+		}
+	}
+
+	return 0, false
+}
+
+// Names returns the names of the privileges in the set.
+func (p Privilege) Names() []string {
+	var names []string
+	for _, n := range privilegeNames {
+		if p&n.privilege != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return names
+}
+
+// String returns the privileges as written in a GRANT statement. GRANT OPTION is not included, since it's given by
+// the WITH GRANT OPTION clause.
+func (p Privilege) String() string {
+	p &^= PrivilegeGrantOption
+	switch {
+	case p == PrivilegeUsage:
+		return "USAGE"
+	case p == PrivilegeAll:
+		return "ALL PRIVILEGES"
+	default:
+		return strings.Join(p.Names(), ", ")
+	}
+}
+
+// PrivilegeLevel is the scope privileges are granted on. An empty database means all databases, an empty table means
+// all the tables of the database, and an empty column means all the columns of the table.
+type PrivilegeLevel struct {
+	Database string
+	Table    string
+	Column   string
+}
+
+// String returns the level as written in a GRANT statement, e.g. *.*, `db`.* or `db`.`table`. Column levels, which
+// GRANT gives in the list of privileges, are written as `db`.`table`.`column`.
+func (l PrivilegeLevel) String() string {
+	if l.Database == "" {
+		return "*.*"
+	}
+
+	if l.Table == "" {
+		return quoteIdentifier(l.Database) + ".*"
+	}
+
+	if l.Column == "" {
+		return quoteIdentifier(l.Database) + "." + quoteIdentifier(l.Table)
+	}
+
+	return quoteIdentifier(l.Database) + "." + quoteIdentifier(l.Table) + "." + quoteIdentifier(l.Column)
+}
+
+// Privileges returns the privileges that can be granted at the level.
+func (l PrivilegeLevel) Privileges() Privilege {
+	switch {
+	case l.Database == "":
+		return GlobalPrivileges
+	case l.Table == "":
+		return DatabasePrivileges
+	case l.Column == "":
+		return TablePrivileges
+	default:
+		return ColumnPrivileges
+	}
+}
+
+// Equal returns whether both levels are the same. Names are case insensitive.
+func (l PrivilegeLevel) Equal(other PrivilegeLevel) bool {
+	return strings.EqualFold(l.Database, other.Database) && strings.EqualFold(l.Table, other.Table) &&
+		strings.EqualFold(l.Column, other.Column)
+}
+
+// Contains returns whether privileges granted at the level apply to the database and table given. An empty table
+// means the database itself. Column levels don't contain any table.
+func (l PrivilegeLevel) Contains(db, table string) bool {
+	if l.Database == "" {
+		return true
+	}
+
+	if l.Column != "" || !strings.EqualFold(l.Database, db) {
+		return false
+	}
+
+	return l.Table == "" || strings.EqualFold(l.Table, table)
+}
+
+// ContainsColumn returns whether privileges granted at the level apply to the column given.
+func (l PrivilegeLevel) ContainsColumn(db, table, column string) bool {
+	if l.Column == "" {
+		return l.Contains(db, table)
+	}
+
+	return strings.EqualFold(l.Database, db) && strings.EqualFold(l.Table, table) && strings.EqualFold(l.Column, column)
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+// Grant is a set of privileges granted at a level.
+type Grant struct {
+	Level      PrivilegeLevel
+	Privileges Privilege
+}
+
+// PrivilegeSet holds the grants of an account, with at most one grant per level.
+type PrivilegeSet []Grant
+
+// Has returns whether the set holds all the privileges given on the database and table given. An empty table means
+// the database itself, and an empty database means the privileges must be global.
+func (s PrivilegeSet) Has(db, table string, privileges Privilege) bool {
+	var granted Privilege
+	for _, g := range s {
+		if db == "" && g.Level.Database != "" {
+			continue
+		}
+
+		if table == "" && g.Level.Table != "" {
+			continue
+		}
+
+		if g.Level.Contains(db, table) {
+			granted |= g.Privileges
+		}
+	}
+
+	return granted&privileges == privileges
+}
+
+// HasColumn returns whether the set holds all the privileges given on a column, either granted on the column or on
+// its table.
+func (s PrivilegeSet) HasColumn(db, table, column string, privileges Privilege) bool {
+	var granted Privilege
+	for _, g := range s {
+		if g.Level.ContainsColumn(db, table, column) {
+			granted |= g.Privileges
+		}
+	}
+
+	return granted&privileges == privileges
+}
+
+// HasAnyColumn returns whether each of the privileges given is held on at least one column of a table.
+func (s PrivilegeSet) HasAnyColumn(db, table string, privileges Privilege) bool {
+	var granted Privilege
+	for _, g := range s {
+		if g.Level.Column != "" && strings.EqualFold(g.Level.Database, db) && strings.EqualFold(g.Level.Table, table) ||
+			g.Level.Contains(db, table) {
+			granted |= g.Privileges
+		}
+	}
+
+	return granted&privileges == privileges
+}
+
+// ColumnGrant is a set of privileges granted on a column of a table, as given by GRANT SELECT (column) statements.
+type ColumnGrant struct {
+	Column     string
+	Privileges Privilege
+}
+
+// All returns the union of the privileges granted at any level.
+func (s PrivilegeSet) All() Privilege {
+	var all Privilege
+	for _, g := range s {
+		all |= g.Privileges
+	}
+	return all
+}
+
+// Level returns the privileges granted exactly at the level given.
+func (s PrivilegeSet) Level(level PrivilegeLevel) Privilege {
+	for _, g := range s {
+		if g.Level.Equal(level) {
+			return g.Privileges
+		}
+	}
+	return PrivilegeUsage
+}
+
+// Grant returns a copy of the set with the privileges given added at the level given.
+func (s PrivilegeSet) Grant(level PrivilegeLevel, privileges Privilege) PrivilegeSet {
+	var result = make(PrivilegeSet, 0, len(s)+1)
+	var found bool
+	for _, g := range s {
+		if g.Level.Equal(level) {
+			g.Privileges |= privileges
+			found = true
+		}
+		result = append(result, g)
+	}
+
+	if !found && privileges != PrivilegeUsage {
+		result = append(result, Grant{level, privileges})
+	}
+
+	return result
+}
+
+// Revoke returns a copy of the set without the privileges given at the level given. Grants left without privileges
+// are removed.
+func (s PrivilegeSet) Revoke(level PrivilegeLevel, privileges Privilege) PrivilegeSet {
+	var result = make(PrivilegeSet, 0, len(s))
+	for _, g := range s {
+		if g.Level.Equal(level) {
+			g.Privileges &^= privileges
+		}
+
+		if g.Privileges != PrivilegeUsage {
+			result = append(result, g)
+		}
+	}
+
+	return result
+}
+
+// PrivilegeManager is implemented by UserManagers that also keep the privileges of their accounts, making it possible
+// to manage them with GRANT and REVOKE statements. When the catalog has a PrivilegeManager, the analyzer checks the
+// privileges of the current account before executing queries.
+type PrivilegeManager interface {
+	UserManager
+	// Grant adds privileges at a level to an existing account.
+	Grant(ctx *Context, account Account, level PrivilegeLevel, privileges Privilege) error
+	// Revoke removes privileges at a level from an existing account. It must return ErrNonexistingGrant if the
+	// account has no privileges at that level.
+	Revoke(ctx *Context, account Account, level PrivilegeLevel, privileges Privilege) error
+	// Privileges returns the privileges of an existing account.
+	Privileges(ctx *Context, account Account) (PrivilegeSet, error)
+}
@@ -83,6 +83,7 @@ type Process struct {
 	Type       ProcessType
 	Query      string
 	Progress   map[string]TableProgress
+	RowsRead   map[string]int64
 	StartedAt  time.Time
 	Kill       context.CancelFunc
 }
@@ -191,6 +192,41 @@ func (pl *ProcessList) UpdatePartitionProgress(pid uint64, tableName, partitionN
 	tablePg.PartitionsProgress[partitionName] = partitionPg
 }
 
+// AddRowsRead adds the number of rows given to the rows read from the table
+// with the given name by the process with the given pid.
+func (pl *ProcessList) AddRowsRead(pid uint64, name string, delta int64) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	p, ok := pl.procs[pid]
+	if !ok {
+		return
+	}
+
+	if p.RowsRead == nil {
+		p.RowsRead = make(map[string]int64)
+	}
+	p.RowsRead[name] += delta
+}
+
+// RowsRead returns the number of rows read from each table by the process
+// with the given pid, or nil if it does not exist.
+func (pl *ProcessList) RowsRead(pid uint64) map[string]int64 {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+
+	p, ok := pl.procs[pid]
+	if !ok {
+		return nil
+	}
+
+	result := make(map[string]int64, len(p.RowsRead))
+	for name, n := range p.RowsRead {
+		result[name] = n
+	}
+	return result
+}
+
 // AddTableProgress adds a new item to track progress from to the process with
 // the given pid. If the pid does not exist, it will do nothing.
 func (pl *ProcessList) AddTableProgress(pid uint64, name string, total int64) {
@@ -0,0 +1,31 @@
+package sql
+
+import (
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// ErrProxiesNotSupported is returned when PROXY is granted or revoked and the authentication method in use does not
+// support proxy users.
+var ErrProxiesNotSupported = errors.NewKind("the authentication method does not support proxy users")
+
+// ProxyGrant is the PROXY privilege on an account granted to another account.
+type ProxyGrant struct {
+	// Proxied is the account the grantee can act as.
+	Proxied Account
+	// WithGrantOption allows the grantee to grant PROXY on the proxied account to other accounts.
+	WithGrantOption bool
+}
+
+// ProxyManager is implemented by PrivilegeManagers that let clients log in as proxy users: clients authenticate with
+// the credentials of an account and then act as another account the first one was granted PROXY on, with its
+// privileges. This lets middleware connect with a single credential on behalf of each of its end users.
+type ProxyManager interface {
+	PrivilegeManager
+	// GrantProxy grants PROXY on the proxied account to an existing account.
+	GrantProxy(ctx *Context, proxied, to Account, withGrantOption bool) error
+	// RevokeProxy revokes PROXY on the proxied account from an account. It must return ErrNonexistingGrant if it was
+	// not granted.
+	RevokeProxy(ctx *Context, proxied, from Account) error
+	// Proxies returns the PROXY grants of an existing account.
+	Proxies(ctx *Context, account Account) ([]ProxyGrant, error)
+}
@@ -0,0 +1,107 @@
+package sql
+
+import (
+	errors "gopkg.in/src-d/go-errors.v1"
+)
+
+var (
+	// ErrNoReplica is returned when a replication statement is run and the engine can't replicate.
+	ErrNoReplica = errors.NewKind("replication is not supported by this server")
+
+	// ErrReplicaNotConfigured is returned when the replica is started before its source is set.
+	ErrReplicaNotConfigured = errors.NewKind("The server is not configured as replica; fix in config file or with CHANGE REPLICATION SOURCE TO")
+
+	// ErrReplicaRunning is returned when the source of a running replica is changed.
+	ErrReplicaRunning = errors.NewKind("This operation cannot be performed with a running replica; run STOP REPLICA first")
+
+	// ErrUnknownReplicaOption is returned when CHANGE REPLICATION SOURCE sets an option that doesn't exist.
+	ErrUnknownReplicaOption = errors.NewKind("unknown replication source option: %s")
+
+	// ErrInvalidReplicaOption is returned when an option of CHANGE REPLICATION SOURCE has a value of the wrong type.
+	ErrInvalidReplicaOption = errors.NewKind("invalid value for replication source option %s: %v")
+)
+
+// The options of CHANGE REPLICATION SOURCE, by the names MySQL 8.0.23 gives them. The MASTER_ names of older versions
+// are the same options.
+const (
+	ReplicaSourceHost            = "SOURCE_HOST"
+	ReplicaSourcePort            = "SOURCE_PORT"
+	ReplicaSourceUser            = "SOURCE_USER"
+	ReplicaSourcePassword        = "SOURCE_PASSWORD"
+	ReplicaSourceLogFile         = "SOURCE_LOG_FILE"
+	ReplicaSourceLogPos          = "SOURCE_LOG_POS"
+	ReplicaSourceAutoPosition    = "SOURCE_AUTO_POSITION"
+	ReplicaSourceConnectRetry    = "SOURCE_CONNECT_RETRY"
+	ReplicaSourceHeartbeatPeriod = "SOURCE_HEARTBEAT_PERIOD"
+)
+
+// ReplicaOptionIsString returns whether the value of a replication source option is a string, or else an integer, and
+// whether the option exists.
+func ReplicaOptionIsString(name string) (isString bool, ok bool) {
+	switch name {
+	case ReplicaSourceHost, ReplicaSourceUser, ReplicaSourcePassword, ReplicaSourceLogFile:
+		return true, true
+	case ReplicaSourcePort, ReplicaSourceLogPos, ReplicaSourceAutoPosition, ReplicaSourceConnectRetry,
+		ReplicaSourceHeartbeatPeriod:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// ReplicaOption is an option of CHANGE REPLICATION SOURCE.
+type ReplicaOption struct {
+	// Name of the option, such as SOURCE_HOST.
+	Name string
+	// Value of the option, a string or an uint64 as ReplicaOptionIsString tells.
+	Value interface{}
+}
+
+// ReplicaStatus is the state of a replica, as SHOW REPLICA STATUS shows it.
+type ReplicaStatus struct {
+	// SourceHost, SourcePort and SourceUser are the source the replica connects to.
+	SourceHost string
+	SourcePort uint16
+	SourceUser string
+	// ConnectRetry is the number of seconds between the attempts to connect to the source.
+	ConnectRetry uint32
+	// SourceLogFile and ReadSourceLogPos are the position in the binary log of the source of the last event read.
+	SourceLogFile    string
+	ReadSourceLogPos uint64
+	// ExecSourceLogFile and ExecSourceLogPos are the position in the binary log of the source after the last
+	// transaction applied.
+	ExecSourceLogFile string
+	ExecSourceLogPos  uint64
+	// IORunning is whether the replica is connected to the source: Yes, No or Connecting.
+	IORunning string
+	// SQLRunning is whether the replica applies the events read: Yes or No.
+	SQLRunning string
+	// LastIOErrno and LastIOError are the last error reading from the source.
+	LastIOErrno uint32
+	LastIOError string
+	// LastSQLErrno and LastSQLError are the last error applying an event, which stops the replica.
+	LastSQLErrno uint32
+	LastSQLError string
+	// SourceServerID and SourceUUID identify the source.
+	SourceServerID uint32
+	SourceUUID     string
+	// RetrievedGTIDSet and ExecutedGTIDSet are the GTIDs of the transactions read from the source and applied.
+	RetrievedGTIDSet string
+	ExecutedGTIDSet  string
+	// AutoPosition is whether the replica asks for the transactions it lacks by GTID, rather than by position.
+	AutoPosition bool
+}
+
+// Replica applies the changes of the binary log of a source server to the databases of the engine.
+type Replica interface {
+	// ChangeSource sets the options of the source, as CHANGE REPLICATION SOURCE does. It returns ErrReplicaRunning if
+	// the replica is running.
+	ChangeSource(ctx *Context, options []ReplicaOption) error
+	// Start starts replicating from the source. It returns ErrReplicaNotConfigured if the source wasn't set, and does
+	// nothing if the replica is running.
+	Start(ctx *Context) error
+	// Stop stops replicating, once the transaction being applied is. It does nothing if the replica isn't running.
+	Stop(ctx *Context) error
+	// Status returns the state of the replica, and false if its source was never set.
+	Status() (ReplicaStatus, bool)
+}
@@ -0,0 +1,31 @@
+package sql
+
+import (
+	"fmt"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// ErrResourceLimitsNotSupported is returned when the resource limits of an account are changed and the
+// authentication method in use does not support them.
+var ErrResourceLimitsNotSupported = errors.NewKind("the authentication method does not support resource limits")
+
+// ResourceLimits holds the WITH clause of CREATE USER and ALTER USER statements, which limits the resources an
+// account can use.
+type ResourceLimits struct {
+	// MaxUserConnections is the maximum number of simultaneous connections of the account. Zero means the global
+	// max_user_connections system variable applies.
+	MaxUserConnections int
+}
+
+// String returns the clause as written in a statement.
+func (l ResourceLimits) String() string {
+	return fmt.Sprintf("WITH MAX_USER_CONNECTIONS %d", l.MaxUserConnections)
+}
+
+// ResourceManager is implemented by UserManagers that limit the resources of their accounts.
+type ResourceManager interface {
+	UserManager
+	// SetResourceLimits changes the resource limits of an existing account.
+	SetResourceLimits(ctx *Context, account Account, limits ResourceLimits) error
+}
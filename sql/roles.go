@@ -0,0 +1,163 @@
+package sql
+
+import (
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+var (
+	// ErrRolesNotSupported is returned when a role management statement is executed and the authentication method in
+	// use does not manage roles.
+	ErrRolesNotSupported = errors.NewKind("the authentication method does not support roles")
+
+	// ErrRoleNotGranted is returned when activating, revoking or making default a role that is not granted to the
+	// account, mirroring MySQL's ER_ROLE_NOT_GRANTED.
+	ErrRoleNotGranted = errors.NewKind("%s is not granted to %s")
+
+	// ErrRoleGrantLoop is returned when granting a role would make it be granted to itself, directly or through other
+	// roles, mirroring MySQL's ER_ROLE_GRANTED_TO_ITSELF.
+	ErrRoleGrantLoop = errors.NewKind("User account %s is directly or indirectly granted to the role %s. The GRANT would create a loop in the role grant graph.")
+)
+
+// RoleGrant is a role granted to an account.
+type RoleGrant struct {
+	Role Account
+	// WithAdminOption allows the account to grant the role to other accounts and to revoke it from them.
+	WithAdminOption bool
+}
+
+// RoleManager is implemented by PrivilegeManagers that also keep roles, which are accounts that can't log in and
+// whose privileges are given to the accounts they are granted to while they are active. As in MySQL, roles can be
+// granted to other roles, and the privileges of an active role include the ones of every role granted to it.
+type RoleManager interface {
+	PrivilegeManager
+	// CreateRole creates a new role. It must return ErrUserAlreadyExists if an account with the same name and host
+	// already exists.
+	CreateRole(ctx *Context, role Account) error
+	// DropRole removes a role, or any other account, and revokes it from the accounts it was granted to.
+	DropRole(ctx *Context, role Account) error
+	// GrantRole grants a role to an existing account. It must return ErrRoleGrantLoop if the account is the role
+	// itself or is granted to the role, directly or indirectly.
+	GrantRole(ctx *Context, role, to Account, withAdminOption bool) error
+	// RevokeRole revokes a role from an account. It must return ErrRoleNotGranted if it was not granted.
+	RevokeRole(ctx *Context, role, from Account) error
+	// Roles returns the roles granted directly to an existing account.
+	Roles(ctx *Context, account Account) ([]RoleGrant, error)
+	// SetDefaultRoles sets the roles activated when an account logs in, which must be granted to it.
+	SetDefaultRoles(ctx *Context, account Account, roles []Account) error
+	// DefaultRoles returns the roles activated when an existing account logs in.
+	DefaultRoles(ctx *Context, account Account) ([]Account, error)
+}
+
+// RoleSession is a Session that keeps the roles activated with SET ROLE.
+type RoleSession interface {
+	Session
+	// ActiveRoles returns the roles activated in the session, or false if SET ROLE was never executed, in which case
+	// the default roles of the account are active.
+	ActiveRoles() ([]Account, bool)
+	// SetActiveRoles sets the roles active in the session.
+	SetActiveRoles(roles []Account)
+}
+
+// ActiveRoles returns the roles active for the account of the session of the context given, which are its default
+// roles until changed with SET ROLE. Roles that are no longer granted to the account are left out.
+func ActiveRoles(ctx *Context, rm RoleManager, account Account) ([]Account, error) {
+	granted, err := rm.Roles(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, ok := activeSessionRoles(ctx)
+	if !ok {
+		roles, err = rm.DefaultRoles(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var active []Account
+	for _, r := range roles {
+		for _, g := range granted {
+			if g.Role.Equal(r) {
+				active = append(active, r)
+				break
+			}
+		}
+	}
+
+	return active, nil
+}
+
+func activeSessionRoles(ctx *Context) ([]Account, bool) {
+	if s, ok := ctx.Session.(RoleSession); ok {
+		return s.ActiveRoles()
+	}
+	return nil, false
+}
+
+// ActivePrivileges returns the privileges of an account in the session of the context given: its own privileges and
+// the ones of its active roles, including the roles granted to them.
+func ActivePrivileges(ctx *Context, pm PrivilegeManager, account Account) (PrivilegeSet, error) {
+	rm, ok := pm.(RoleManager)
+	if !ok {
+		return pm.Privileges(ctx, account)
+	}
+
+	roles, err := ActiveRoles(ctx, rm, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return RolePrivileges(ctx, rm, account, roles)
+}
+
+// RolePrivileges returns the privileges an account would have with the roles given active: its own privileges and
+// the ones of the roles, including the roles granted to them.
+func RolePrivileges(ctx *Context, rm RoleManager, account Account, roles []Account) (PrivilegeSet, error) {
+	privileges, err := rm.Privileges(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	var visited []Account
+	for len(roles) > 0 {
+		role := roles[0]
+		roles = roles[1:]
+
+		if containsAccount(visited, role) {
+			continue
+		}
+		visited = append(visited, role)
+
+		rolePrivileges, err := rm.Privileges(ctx, role)
+		if ErrUserNotFound.Is(err) {
+			// The role was dropped after the query started.
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, g := range rolePrivileges {
+			privileges = privileges.Grant(g.Level, g.Privileges)
+		}
+
+		granted, err := rm.Roles(ctx, role)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, g := range granted {
+			roles = append(roles, g.Role)
+		}
+	}
+
+	return privileges, nil
+}
+
+func containsAccount(accounts []Account, account Account) bool {
+	for _, a := range accounts {
+		if a.Equal(account) {
+			return true
+		}
+	}
+	return false
+}
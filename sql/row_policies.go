@@ -0,0 +1,32 @@
+package sql
+
+import (
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+var (
+	// ErrRowPolicyViolation is returned when a row inserted or updated doesn't satisfy the row filters of its table.
+	ErrRowPolicyViolation = errors.NewKind("new row violates the row policy for table %s")
+
+	// ErrRowPolicyNotSupported is returned for statements that could change rows hidden by the row filters of a
+	// table, such as REPLACE.
+	ErrRowPolicyNotSupported = errors.NewKind("%s is not supported on table %s, which has a row policy")
+)
+
+// RowPolicy restricts the rows of tables each session can access, which lets integrators isolate the data of
+// different tenants in the same tables. The rows of a table are filtered when it's read, so that only the rows
+// satisfying the filter can be selected, updated or deleted, and the rows inserted or updated must satisfy it too.
+type RowPolicy interface {
+	// RowFilter returns the predicate the rows of the table given must satisfy for the session of the context given,
+	// or nil if its rows are not restricted. Columns of the table are referenced with unresolved columns, optionally
+	// qualified with the name of the table, and functions can be left unresolved as well.
+	RowFilter(ctx *Context, db, table string) (Expression, error)
+}
+
+// RowPolicyFunc is a function implementing RowPolicy.
+type RowPolicyFunc func(ctx *Context, db, table string) (Expression, error)
+
+// RowFilter implements the RowPolicy interface.
+func (f RowPolicyFunc) RowFilter(ctx *Context, db, table string) (Expression, error) {
+	return f(ctx, db, table)
+}
@@ -0,0 +1,80 @@
+package sql
+
+// SchemaChangeType is the type of a change to the definition of a database, a table or an index.
+type SchemaChangeType byte
+
+const (
+	// DatabaseCreated is the change of a database created by CREATE DATABASE.
+	DatabaseCreated SchemaChangeType = iota + 1
+	// DatabaseAltered is the change of the default character set or collation of a database by ALTER DATABASE.
+	DatabaseAltered
+	// TableCreated is the change of a table created by CREATE TABLE.
+	TableCreated
+	// TableAltered is the change of the columns or constraints of a table by ALTER TABLE.
+	TableAltered
+	// TableRenamed is the change of the name of a table by RENAME TABLE or ALTER TABLE ... RENAME.
+	TableRenamed
+	// TableDropped is the change of a table dropped by DROP TABLE.
+	TableDropped
+	// IndexCreated is the change of an index created by CREATE INDEX or ALTER TABLE ... ADD INDEX.
+	IndexCreated
+	// IndexAltered is the change of the name or the visibility of an index by ALTER TABLE.
+	IndexAltered
+	// IndexDropped is the change of an index dropped by DROP INDEX or ALTER TABLE ... DROP INDEX.
+	IndexDropped
+)
+
+// String returns the name of the schema change type.
+func (t SchemaChangeType) String() string {
+	switch t {
+	case DatabaseCreated:
+		return "database created"
+	case DatabaseAltered:
+		return "database altered"
+	case TableCreated:
+		return "table created"
+	case TableAltered:
+		return "table altered"
+	case TableRenamed:
+		return "table renamed"
+	case TableDropped:
+		return "table dropped"
+	case IndexCreated:
+		return "index created"
+	case IndexAltered:
+		return "index altered"
+	case IndexDropped:
+		return "index dropped"
+	default:
+		return "unknown"
+	}
+}
+
+// SchemaChange is a change that a DDL statement made to the definition of a database, a table or an index.
+type SchemaChange struct {
+	Type SchemaChangeType
+	// Database is the database changed, or the one of the table or the index changed.
+	Database string
+	// Table is the table changed, or the one of the index changed. It's empty for the changes of databases.
+	Table string
+	// Index is the index changed, for the changes of indexes.
+	Index string
+	// NewName is the new name of the table or index renamed.
+	NewName string
+}
+
+// SchemaListener is notified of the changes that DDL statements make to databases, tables and indexes, which lets
+// integrators invalidate caches, update search indexes or replicate the changes. The changes of a statement are
+// notified once it has succeeded, in the session of the statement, before its result is returned.
+type SchemaListener interface {
+	// SchemaChanged is called with each change made by a statement, in the order they were made.
+	SchemaChanged(ctx *Context, change SchemaChange)
+}
+
+// SchemaListenerFunc is a function implementing SchemaListener.
+type SchemaListenerFunc func(ctx *Context, change SchemaChange)
+
+// SchemaChanged implements the SchemaListener interface.
+func (f SchemaListenerFunc) SchemaChanged(ctx *Context, change SchemaChange) {
+	f(ctx, change)
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"math"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -31,6 +31,12 @@ type Client struct {
 	User string
 	// Address of the client.
 	Address string
+	// ProxyUser is the user the client authenticated as when it logged in as
+	// a proxy user acting as User, and empty otherwise.
+	ProxyUser string
+	// ConnectAttrs are the connection attributes the client sent when it
+	// connected, such as the name and version of its program and library.
+	ConnectAttrs map[string]string
 }
 
 // Session holds the session data.
@@ -57,7 +63,8 @@ type Session interface {
 	Warn(warn *Warning)
 	// Warnings returns a copy of session warnings (from the most recent).
 	Warnings() []*Warning
-	// ClearWarnings cleans up session warnings.
+	// ClearWarnings cleans up session warnings. The engine clears them before each statement that doesn't only read
+	// them, like SHOW WARNINGS does.
 	ClearWarnings()
 	// WarningCount returns a number of session warnings
 	WarningCount() uint16
@@ -69,6 +76,104 @@ type Session interface {
 	IterLocks(cb func(name string) error) error
 }
 
+// FunctionSession is a Session that can hold functions registered for its lifetime only. Session functions take
+// precedence over functions provided by the current database and functions registered in the Catalog.
+type FunctionSession interface {
+	Session
+	// RegisterFunction registers functions visible only to this session. If a function with the same name is already
+	// registered in the session, ErrFunctionAlreadyRegistered is returned.
+	RegisterFunction(fn ...Function) error
+	// Function returns the session function with the name given, or false if there is none.
+	Function(name string) (Function, bool)
+}
+
+// PreparedStatementSession is a Session that holds the statements prepared with PREPARE until they're deallocated.
+type PreparedStatementSession interface {
+	Session
+	// PrepareStatement stores the query of a statement with the name given, replacing the one with that name if any.
+	PrepareStatement(name, query string)
+	// PreparedStatement returns the query of the statement with the name given, or false if there is none.
+	PreparedStatement(name string) (string, bool)
+	// DeallocateStatement removes the statement with the name given, returning whether there was one.
+	DeallocateStatement(name string) bool
+}
+
+// ResettableSession is a Session that can be reset to the state of a new one, keeping its client and current
+// database, as when clients reset their connection to reuse it.
+type ResettableSession interface {
+	Session
+	// Reset sets the session variables back to their defaults, and clears the user variables, the warnings, the
+	// roles activated, the prepared statements and the information about the last statements of the session.
+	Reset()
+}
+
+// TransactionSession is a Session of an integrator keeping its own transactions, whose open transaction the server
+// rolls back when it shuts down before the client ends it.
+type TransactionSession interface {
+	Session
+	// Rollback rolls back the open transaction of the session, if any.
+	Rollback(ctx *Context) error
+}
+
+// ExplicitTransactionSession is a TransactionSession whose transactions are started with BEGIN or START TRANSACTION,
+// and stay open until COMMIT or ROLLBACK even if autocommit is enabled. The server also rolls back the open
+// transaction of one when its client disconnects.
+type ExplicitTransactionSession interface {
+	TransactionSession
+	// StartTransaction starts a transaction, committing the one open first, if any.
+	StartTransaction(ctx *Context) error
+	// InTransaction returns whether the session has a transaction started with StartTransaction open.
+	InTransaction() bool
+}
+
+// TransactionHookSession is an ExplicitTransactionSession that runs functions once its open transaction ends, so that
+// the changes made in it, such as the ones delivered to feeds of changes, are only passed on if it commits.
+type TransactionHookSession interface {
+	ExplicitTransactionSession
+	// OnTransactionEnd adds a function that runs once the open transaction is committed or rolled back, with whether
+	// it committed, or runs it at once as committed if there's none. The function must not use the session.
+	OnTransactionEnd(f func(committed bool))
+}
+
+// Keys of the information about the last statements of a session kept by QueryInfoSession.
+const (
+	// RowCount is the number of rows changed, deleted or inserted by the last statement, which is -1 for statements
+	// returning rows and failing ones, and 0 for the other statements.
+	RowCount = "row_count"
+	// FoundRows is the number of rows returned by the last statement returning rows, or the number of rows it would
+	// have returned without its LIMIT for a SELECT with SQL_CALC_FOUND_ROWS.
+	FoundRows = "found_rows"
+	// LastInsertID is the first value generated for an AUTO_INCREMENT column by the last INSERT generating one, or the
+	// value given to the last LAST_INSERT_ID(expr) since.
+	LastInsertID = "last_insert_id"
+)
+
+// QueryInfoSession is a Session keeping information about its last statements, for ROW_COUNT(), FOUND_ROWS() and
+// LAST_INSERT_ID().
+type QueryInfoSession interface {
+	Session
+	// SetLastQueryInfo sets the information with the key given, such as RowCount.
+	SetLastQueryInfo(key string, value int64)
+	// LastQueryInfo returns the information with the key given, or zero if it was never set.
+	LastQueryInfo(key string) int64
+}
+
+// SetLastQueryInfo sets information about the last statement of the session of the context given, if it keeps it.
+func SetLastQueryInfo(ctx *Context, key string, value int64) {
+	if s, ok := ctx.Session.(QueryInfoSession); ok {
+		s.SetLastQueryInfo(key, value)
+	}
+}
+
+// LastQueryInfo returns information about the last statement of the session of the context given, or zero if it
+// doesn't keep it.
+func LastQueryInfo(ctx *Context, key string) int64 {
+	if s, ok := ctx.Session.(QueryInfoSession); ok {
+		return s.LastQueryInfo(key)
+	}
+	return 0
+}
+
 // BaseSession is the basic session type.
 type BaseSession struct {
 	id        uint32
@@ -78,9 +183,22 @@ type BaseSession struct {
 	mu        *sync.RWMutex
 	config    map[string]TypedValue
 	warnings  []*Warning
-	warncnt   uint16
 	locks     map[string]bool
-}
+	functions FunctionRegistry
+	// roles are the roles activated with SET ROLE, if rolesSet.
+	roles     []Account
+	rolesSet  bool
+	status    *StatusVariables
+	prepared  map[string]string
+	queryInfo map[string]int64
+}
+
+var _ FunctionSession = (*BaseSession)(nil)
+var _ RoleSession = (*BaseSession)(nil)
+var _ StatusSession = (*BaseSession)(nil)
+var _ PreparedStatementSession = (*BaseSession)(nil)
+var _ ResettableSession = (*BaseSession)(nil)
+var _ QueryInfoSession = (*BaseSession)(nil)
 
 // CommitTransaction commits the current transaction for the current database.
 func (s *BaseSession) CommitTransaction(*Context) error {
@@ -166,14 +284,8 @@ func (s *BaseSession) ClearWarnings() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	cnt := uint16(len(s.warnings))
-	if s.warncnt == cnt {
-		if s.warnings != nil {
-			s.warnings = s.warnings[:0]
-		}
-		s.warncnt = 0
-	} else {
-		s.warncnt = cnt
+	if s.warnings != nil {
+		s.warnings = s.warnings[:0]
 	}
 }
 
@@ -218,6 +330,118 @@ func (s *BaseSession) IterLocks(cb func(name string) error) error {
 	return nil
 }
 
+// RegisterFunction implements the FunctionSession interface.
+func (s *BaseSession) RegisterFunction(fn ...Function) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.functions == nil {
+		s.functions = NewFunctionRegistry()
+	}
+	return s.functions.Register(fn...)
+}
+
+// Function implements the FunctionSession interface.
+func (s *BaseSession) Function(name string) (Function, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fn, ok := s.functions[name]
+	return fn, ok
+}
+
+// ActiveRoles implements the RoleSession interface.
+func (s *BaseSession) ActiveRoles() ([]Account, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var roles = make([]Account, len(s.roles))
+	copy(roles, s.roles)
+	return roles, s.rolesSet
+}
+
+// StatusVariables implements the StatusSession interface.
+func (s *BaseSession) StatusVariables() *StatusVariables {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status == nil {
+		s.status = NewStatusVariables()
+	}
+	return s.status
+}
+
+// SetActiveRoles implements the RoleSession interface.
+func (s *BaseSession) SetActiveRoles(roles []Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.roles = append([]Account(nil), roles...)
+	s.rolesSet = true
+}
+
+// PrepareStatement implements the PreparedStatementSession interface.
+func (s *BaseSession) PrepareStatement(name, query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.prepared == nil {
+		s.prepared = make(map[string]string)
+	}
+	s.prepared[strings.ToLower(name)] = query
+}
+
+// PreparedStatement implements the PreparedStatementSession interface.
+func (s *BaseSession) PreparedStatement(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query, ok := s.prepared[strings.ToLower(name)]
+	return query, ok
+}
+
+// DeallocateStatement implements the PreparedStatementSession interface.
+func (s *BaseSession) DeallocateStatement(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = strings.ToLower(name)
+	_, ok := s.prepared[name]
+	delete(s.prepared, name)
+	return ok
+}
+
+// SetLastQueryInfo implements the QueryInfoSession interface.
+func (s *BaseSession) SetLastQueryInfo(key string, value int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.queryInfo == nil {
+		s.queryInfo = make(map[string]int64)
+	}
+	s.queryInfo[key] = value
+}
+
+// LastQueryInfo implements the QueryInfoSession interface.
+func (s *BaseSession) LastQueryInfo(key string) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.queryInfo[key]
+}
+
+// Reset implements the ResettableSession interface.
+func (s *BaseSession) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.config = DefaultSessionConfig()
+	s.warnings = nil
+	s.roles, s.rolesSet = nil, false
+	s.prepared = nil
+	s.queryInfo = nil
+}
+
 type (
 	// TypedValue is a value along with its type.
 	TypedValue struct {
@@ -230,37 +454,40 @@ type (
 		Level   string
 		Message string
 		Code    int
+		// SQLState is the SQLSTATE of the condition, or empty for the default of its level.
+		SQLState string
 	}
 )
 
-// DefaultSessionConfig returns default values for session variables
-// TODO: allow integrators to specify defaults for their system variables
+// State returns the SQLSTATE of the warning, which defaults to 01000 for warnings and notes, and to HY000 for errors.
+func (w *Warning) State() string {
+	switch {
+	case w.SQLState != "":
+		return w.SQLState
+	case w.Level == "Error":
+		return "HY000"
+	default:
+		return "01000"
+	}
+}
+
+// defaultSessionConfig holds the default values of the system variables of the engine with a value in each session.
+var defaultSessionConfig = NewSystemVariables().SessionDefaults()
+
+// DefaultSessionConfig returns the default values of the system variables of the engine with a value in each session,
+// which new sessions start with until they're given the global values of the SystemVariableRegistry of a server.
 func DefaultSessionConfig() map[string]TypedValue {
-	return map[string]TypedValue{
-		"auto_increment_increment": TypedValue{Int64, int64(1)},
-		"time_zone":                TypedValue{LongText, "SYSTEM"},
-		"system_time_zone":         TypedValue{LongText, time.Now().UTC().Location().String()},
-		"max_allowed_packet":       TypedValue{Int32, math.MaxInt32},
-		"sql_mode":                 TypedValue{LongText, ""},
-		"gtid_mode":                TypedValue{Int32, int32(0)},
-		"collation_database":       TypedValue{LongText, Collation_Default.String()},
-		"ndbinfo_version":          TypedValue{LongText, ""},
-		"sql_select_limit":         TypedValue{Int32, math.MaxInt32},
-		"transaction_isolation":    TypedValue{LongText, "READ UNCOMMITTED"},
-		"version":                  TypedValue{LongText, ""},
-		"version_comment":          TypedValue{LongText, ""},
-		"autocommit":               TypedValue{Int8, 0},
-		"character_set_client":     TypedValue{LongText, Collation_Default.CharacterSet().String()},
-		"character_set_connection": TypedValue{LongText, Collation_Default.CharacterSet().String()},
-		"character_set_results":    TypedValue{LongText, Collation_Default.CharacterSet().String()},
-		"collation_connection":     TypedValue{LongText, Collation_Default.String()},
+	config := make(map[string]TypedValue, len(defaultSessionConfig))
+	for name, v := range defaultSessionConfig {
+		config[name] = v
 	}
+	return config
 }
 
 // HasDefaultValue checks if session variable value is the default one.
 func HasDefaultValue(s Session, key string) (bool, interface{}) {
 	typ, val := s.Get(key)
-	if cfg, ok := DefaultSessionConfig()[key]; ok {
+	if cfg, ok := defaultSessionConfig[key]; ok {
 		return (cfg.Typ == typ && cfg.Value == val), val
 	}
 	return false, val
@@ -268,13 +495,15 @@ func HasDefaultValue(s Session, key string) (bool, interface{}) {
 
 // NewSession creates a new session with data.
 func NewSession(server, client, user string, id uint32) Session {
+	return NewSessionWithClient(server, Client{Address: client, User: user}, id)
+}
+
+// NewSessionWithClient creates a new session for the client given.
+func NewSessionWithClient(server string, client Client, id uint32) Session {
 	return &BaseSession{
-		id:   id,
-		addr: server,
-		client: Client{
-			Address: client,
-			User:    user,
-		},
+		id:     id,
+		addr:   server,
+		client: client,
 		config: DefaultSessionConfig(),
 		mu:     &sync.RWMutex{},
 		locks:  make(map[string]bool),
@@ -301,6 +530,7 @@ type Context struct {
 	queryTime time.Time
 	tracer    opentracing.Tracer
 	rootSpan  opentracing.Span
+	sysVars   *SystemVariableRegistry
 }
 
 // ContextOption is a function to configure the context.
@@ -353,6 +583,13 @@ func WithMemoryManager(m *MemoryManager) ContextOption {
 	}
 }
 
+// WithSystemVariables sets the registry of the system variables the context reads and sets.
+func WithSystemVariables(r *SystemVariableRegistry) ContextOption {
+	return func(ctx *Context) {
+		ctx.sysVars = r
+	}
+}
+
 // WithRootSpan sets the root span of the context.
 func WithRootSpan(s opentracing.Span) ContextOption {
 	return func(ctx *Context) {
@@ -379,13 +616,14 @@ func RunWithNowFunc(nowFunc func() time.Time, fn func() error) error {
 // NewContext creates a new query context. Options can be passed to configure
 // the context. If some aspect of the context is not configure, the default
 // value will be used.
-// By default, the context will have an empty base session, a noop tracer and
-// a memory manager using the process reporter.
+// By default, the context will have an empty base session, a noop tracer, a
+// memory manager using the process reporter and its own registry of system
+// variables with their default values.
 func NewContext(
 	ctx context.Context,
 	opts ...ContextOption,
 ) *Context {
-	c := &Context{ctx, NewBaseSession(), nil, nil, nil, 0, "", ctxNowFunc(), opentracing.NoopTracer{}, nil}
+	c := &Context{ctx, NewBaseSession(), nil, nil, nil, 0, "", ctxNowFunc(), opentracing.NoopTracer{}, nil, nil}
 	for _, opt := range opts {
 		opt(c)
 	}
@@ -401,6 +639,10 @@ func NewContext(
 	if c.Memory == nil {
 		c.Memory = NewMemoryManager(ProcessMemory)
 	}
+
+	if c.sysVars == nil {
+		c.sysVars = NewSystemVariables()
+	}
 	return c
 }
 
@@ -450,6 +692,7 @@ func (c *Context) Span(
 		queryTime:     c.queryTime,
 		tracer:        c.tracer,
 		rootSpan:      c.rootSpan,
+		sysVars:       c.sysVars,
 	}
 }
 
@@ -468,6 +711,7 @@ func (c *Context) NewSubContext() (*Context, context.CancelFunc) {
 		queryTime:     c.queryTime,
 		tracer:        c.tracer,
 		rootSpan:      c.rootSpan,
+		sysVars:       c.sysVars,
 	}, cancelFunc
 }
 
@@ -489,9 +733,15 @@ func (c *Context) WithContext(ctx context.Context) *Context {
 		queryTime:     c.queryTime,
 		tracer:        c.tracer,
 		rootSpan:      c.rootSpan,
+		sysVars:       c.sysVars,
 	}
 }
 
+// SystemVariables returns the registry of the system variables of the context.
+func (c *Context) SystemVariables() *SystemVariableRegistry {
+	return c.sysVars
+}
+
 // RootSpan returns the root span, if any.
 func (c *Context) RootSpan() opentracing.Span {
 	return c.rootSpan
@@ -515,6 +765,19 @@ func (c *Context) Warn(code int, msg string, args ...interface{}) {
 	})
 }
 
+// Note adds a note to the session, unless sql_notes is off. Notes are about conditions that aren't problems, like a
+// table of DROP TABLE IF EXISTS not existing.
+func (c *Context) Note(code int, msg string, args ...interface{}) {
+	if _, notes := c.Get("sql_notes"); notes == int8(0) {
+		return
+	}
+	c.Session.Warn(&Warning{
+		Level:   "Note",
+		Code:    code,
+		Message: fmt.Sprintf(msg, args...),
+	})
+}
+
 // NewSpanIter creates a RowIter executed in the given span.
 // Currently inactive, returns the iter returned unaltered.
 func NewSpanIter(span opentracing.Span, iter RowIter) RowIter {
@@ -0,0 +1,253 @@
+package sql
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cast"
+)
+
+// Modes of sql_mode that change the behavior of the engine.
+const (
+	SqlMode_AnsiQuotes             = "ANSI_QUOTES"
+	SqlMode_ErrorForDivisionByZero = "ERROR_FOR_DIVISION_BY_ZERO"
+	SqlMode_NoBackslashEscapes     = "NO_BACKSLASH_ESCAPES"
+	SqlMode_NoZeroDate             = "NO_ZERO_DATE"
+	SqlMode_NoZeroInDate           = "NO_ZERO_IN_DATE"
+	SqlMode_OnlyFullGroupBy        = "ONLY_FULL_GROUP_BY"
+	SqlMode_PipesAsConcat          = "PIPES_AS_CONCAT"
+	SqlMode_RealAsFloat            = "REAL_AS_FLOAT"
+	SqlMode_StrictAllTables        = "STRICT_ALL_TABLES"
+	SqlMode_StrictTransTables      = "STRICT_TRANS_TABLES"
+)
+
+// defaultSqlMode is the value of sql_mode until it's set, the default of MySQL 8 without the modes that reject zero
+// dates and divisions by zero.
+const defaultSqlMode = SqlMode_OnlyFullGroupBy + "," + SqlMode_StrictTransTables + ",NO_ENGINE_SUBSTITUTION"
+
+// sqlModeCombinations are the modes of sql_mode that stand for a list of others.
+var sqlModeCombinations = map[string][]string{
+	"ANSI": {SqlMode_RealAsFloat, SqlMode_PipesAsConcat, SqlMode_AnsiQuotes, "IGNORE_SPACE", SqlMode_OnlyFullGroupBy},
+	"TRADITIONAL": {SqlMode_StrictTransTables, SqlMode_StrictAllTables, SqlMode_NoZeroInDate, SqlMode_NoZeroDate,
+		SqlMode_ErrorForDivisionByZero, "NO_ENGINE_SUBSTITUTION"},
+}
+
+// Warning codes of MySQL for the values adjusted to fit their columns outside of strict mode.
+const (
+	warnDataTruncated      = 1265
+	warnDataOutOfRange     = 1264
+	warnDivisionByZero     = 1365
+	warnTruncatedWrongVal  = 1292
+	warnIncorrectValueType = 1366
+)
+
+// SqlModeEnabled returns whether the mode of sql_mode given is on in the session of the context given, by itself or
+// as part of a combination of modes like TRADITIONAL.
+func SqlModeEnabled(ctx *Context, mode string) bool {
+	_, value := ctx.Get("sql_mode")
+	s, ok := value.(string)
+	if !ok {
+		s = defaultSqlMode
+	}
+
+	for _, m := range strings.Split(strings.ToUpper(s), ",") {
+		m = strings.TrimSpace(m)
+		if m == mode {
+			return true
+		}
+		for _, combined := range sqlModeCombinations[m] {
+			if combined == mode {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// StrictMode returns whether the session of the context given is in strict SQL mode, where INSERT and UPDATE fail on
+// values their columns can't hold, rather than storing the closest value the columns can hold with a warning.
+func StrictMode(ctx *Context) bool {
+	return SqlModeEnabled(ctx, SqlMode_StrictTransTables) || SqlModeEnabled(ctx, SqlMode_StrictAllTables)
+}
+
+// WarnDivisionByZero adds the warning of a division by zero to the session of the context given if its sql_mode has
+// ERROR_FOR_DIVISION_BY_ZERO. INSERT and UPDATE fail on the warning in strict mode.
+func WarnDivisionByZero(ctx *Context) {
+	if SqlModeEnabled(ctx, SqlMode_ErrorForDivisionByZero) {
+		ctx.Warn(warnDivisionByZero, "Division by 0")
+	}
+}
+
+// CheckDivisionByZero returns ErrDivisionByZero if the session of the context given is in strict mode and any of its
+// warnings added after the number of warnings given is a division by zero, which INSERT and UPDATE fail on then.
+func CheckDivisionByZero(ctx *Context, warnings uint16) error {
+	count := ctx.WarningCount()
+	if count <= warnings || !StrictMode(ctx) {
+		return nil
+	}
+	// Warnings returns the most recent warnings first
+	for _, warning := range ctx.Warnings()[:count-warnings] {
+		if warning.Code == warnDivisionByZero {
+			return ErrDivisionByZero.New()
+		}
+	}
+	return nil
+}
+
+// ConvertToColumn converts the value given to the type of the column given, for INSERT and UPDATE to store it in the
+// column. In strict mode, the values the type can't hold are errors. Otherwise, they're replaced by the closest value
+// the type can hold, or by its zero value, with a warning. Zero dates are errors in strict mode if sql_mode has
+// NO_ZERO_DATE, and they're stored with a warning otherwise. Dates with a zero month or day, like 2020-00-15, are
+// errors in strict mode if sql_mode has NO_ZERO_IN_DATE, and they're replaced by the zero date with a warning
+// otherwise.
+func ConvertToColumn(ctx *Context, typ Type, column string, value interface{}) (interface{}, error) {
+	return convertToColumn(ctx, typ, column, value, StrictMode(ctx))
+}
+
+// AdjustToColumn converts the value given to the type of the column given like ConvertToColumn does outside of strict
+// mode, for INSERT IGNORE, which stores the values their columns can't hold adjusted with a warning in any mode.
+func AdjustToColumn(ctx *Context, typ Type, column string, value interface{}) (interface{}, error) {
+	return convertToColumn(ctx, typ, column, value, false)
+}
+
+func convertToColumn(ctx *Context, typ Type, column string, value interface{}, strict bool) (interface{}, error) {
+	// Some expressions, like a division by zero, give Null rather than nil
+	if value == nil || value == Null {
+		return nil, nil
+	}
+
+	converted, err := typ.Convert(value)
+	if err != nil {
+		if strict {
+			return nil, err
+		}
+		return adjustToType(ctx, typ, column, value, err)
+	}
+
+	t, ok := converted.(time.Time)
+	if !ok || !HasZeroInDate(t) {
+		return converted, nil
+	}
+	year, month, day := DateParts(t)
+	zeroDate := year == 0 && month == 0 && day == 0
+	if zeroDate && SqlModeEnabled(ctx, SqlMode_NoZeroDate) {
+		if strict {
+			return nil, ErrInvalidZeroDate.New(value, column)
+		}
+		ctx.Warn(warnTruncatedWrongVal, "Incorrect %s value: '%v' for column '%s'", strings.ToLower(typ.String()), value, column)
+	} else if !zeroDate && SqlModeEnabled(ctx, SqlMode_NoZeroInDate) {
+		if strict {
+			return nil, ErrInvalidZeroDate.New(value, column)
+		}
+		ctx.Warn(warnTruncatedWrongVal, "Incorrect %s value: '%v' for column '%s'", strings.ToLower(typ.String()), value, column)
+		return typ.Zero(), nil
+	}
+	return converted, nil
+}
+
+// adjustToType returns the value the type given can hold closest to the value given, which the type failed to convert
+// with the error given, and adds a warning about it to the session of the context given.
+func adjustToType(ctx *Context, typ Type, column string, value interface{}, convertErr error) (interface{}, error) {
+	switch t := typ.(type) {
+	case numberTypeImpl:
+		if ErrOutOfRange.Is(convertErr) || isNumeric(value) {
+			if f, err := cast.ToFloat64E(value); err == nil {
+				ctx.Warn(warnDataOutOfRange, "Out of range value for column '%s'", column)
+				return t.Convert(clampNumber(t, f))
+			}
+		}
+	case decimalType:
+		if ErrConvertToDecimalLimit.Is(convertErr) {
+			if dec, err := decimal.NewFromString(cast.ToString(value)); err == nil {
+				ctx.Warn(warnDataOutOfRange, "Out of range value for column '%s'", column)
+				max := t.exclusiveUpperBound.Sub(decimal.New(1, -int32(t.scale)))
+				if dec.IsNegative() {
+					max = max.Neg()
+				}
+				return t.Convert(max)
+			}
+		}
+	case stringType:
+		if ErrLengthBeyondLimit.Is(convertErr) {
+			ctx.Warn(warnDataTruncated, "Data truncated for column '%s'", column)
+			return t.Convert(truncateString(cast.ToString(value), t))
+		}
+	}
+
+	ctx.Warn(warnIncorrectValueType, "Incorrect %s value: '%v' for column '%s'", strings.ToLower(typ.String()), value, column)
+	return typ.Zero(), nil
+}
+
+// isNumeric returns whether the value given is a number, which a number type fails to convert when it's out of range.
+func isNumeric(value interface{}) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, decimal.Decimal:
+		return true
+	default:
+		return false
+	}
+}
+
+// clampNumber returns the value of the number type given closest to the number given.
+func clampNumber(t numberTypeImpl, num float64) interface{} {
+	clamp := func(min, max float64) float64 {
+		return math.Max(min, math.Min(max, num))
+	}
+
+	switch t.baseType {
+	case sqltypes.Int8:
+		return int64(clamp(math.MinInt8, math.MaxInt8))
+	case sqltypes.Uint8:
+		return uint64(clamp(0, math.MaxUint8))
+	case sqltypes.Int16:
+		return int64(clamp(math.MinInt16, math.MaxInt16))
+	case sqltypes.Uint16:
+		return uint64(clamp(0, math.MaxUint16))
+	case sqltypes.Int24:
+		return int64(clamp(-1<<23, 1<<23-1))
+	case sqltypes.Uint24:
+		return uint64(clamp(0, 1<<24-1))
+	case sqltypes.Int32:
+		return int64(clamp(math.MinInt32, math.MaxInt32))
+	case sqltypes.Uint32:
+		return uint64(clamp(0, math.MaxUint32))
+	case sqltypes.Int64:
+		if num >= math.MaxInt64 {
+			return int64(math.MaxInt64)
+		} else if num <= math.MinInt64 {
+			return int64(math.MinInt64)
+		}
+		return int64(num)
+	case sqltypes.Uint64:
+		if num >= math.MaxUint64 {
+			return uint64(math.MaxUint64)
+		}
+		return uint64(clamp(0, math.MaxUint64))
+	case sqltypes.Float32:
+		return clamp(-math.MaxFloat32, math.MaxFloat32)
+	default:
+		return num
+	}
+}
+
+// truncateString returns the longest prefix of the string given the string type given can hold, which doesn't split
+// any of its characters.
+func truncateString(s string, t stringType) string {
+	max := t.charLength
+	if t.baseType == sqltypes.Text {
+		max = t.MaxByteLength()
+	}
+	if int64(len(s)) <= max {
+		return s
+	}
+
+	end := int(max)
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
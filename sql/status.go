@@ -0,0 +1,192 @@
+package sql
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// StatusProvider provides some of the status variables shown by SHOW STATUS, which are counters and other values
+// describing the operation of the server.
+type StatusProvider interface {
+	// Status returns the current values of the status variables of the provider by name.
+	Status() map[string]interface{}
+}
+
+// Status variables the engine and the server keep. Those in SessionStatusVariables are counted for each session as
+// well as globally, the rest only globally.
+const (
+	StatusQuestions             = "Questions"
+	StatusQueries               = "Queries"
+	StatusComSelect             = "Com_select"
+	StatusComInsert             = "Com_insert"
+	StatusComUpdate             = "Com_update"
+	StatusComDelete             = "Com_delete"
+	StatusComReplace            = "Com_replace"
+	StatusHandlerReadFirst      = "Handler_read_first"
+	StatusHandlerReadKey        = "Handler_read_key"
+	StatusHandlerReadNext       = "Handler_read_next"
+	StatusHandlerReadRndNext    = "Handler_read_rnd_next"
+	StatusHandlerWrite          = "Handler_write"
+	StatusHandlerUpdate         = "Handler_update"
+	StatusHandlerDelete         = "Handler_delete"
+	StatusCreatedTmpTables      = "Created_tmp_tables"
+	StatusCreatedTmpDiskTables  = "Created_tmp_disk_tables"
+	StatusSelectScan            = "Select_scan"
+	StatusConnections           = "Connections"
+	StatusThreadsConnected      = "Threads_connected"
+	StatusMaxUsedConnections    = "Max_used_connections"
+	StatusInnodbRowsRead        = "Innodb_rows_read"
+	StatusInnodbRowsInserted    = "Innodb_rows_inserted"
+	StatusInnodbRowsUpdated     = "Innodb_rows_updated"
+	StatusInnodbRowsDeleted     = "Innodb_rows_deleted"
+	StatusInnodbBufferPoolReads = "Innodb_buffer_pool_reads"
+	// StatusInnodbBufferPoolReadRequests is always 0, as there's no buffer
+	// pool, for the tools that compute its hit ratio.
+	StatusInnodbBufferPoolReadRequests = "Innodb_buffer_pool_read_requests"
+	// StatusConnectionErrorsMaxConnections is the number of connections
+	// refused because the server had max_connections connections.
+	StatusConnectionErrorsMaxConnections = "Connection_errors_max_connections"
+	// StatusAbortedClients is the number of connections aborted because
+	// their client didn't read the results of a query for
+	// net_write_timeout seconds.
+	StatusAbortedClients = "Aborted_clients"
+)
+
+// SessionStatusVariables are the status variables counted for each session, which SHOW SESSION STATUS shows the value
+// of in the current session.
+var SessionStatusVariables = []string{
+	StatusQuestions,
+	StatusQueries,
+	StatusComSelect,
+	StatusComInsert,
+	StatusComUpdate,
+	StatusComDelete,
+	StatusComReplace,
+	StatusHandlerReadFirst,
+	StatusHandlerReadKey,
+	StatusHandlerReadNext,
+	StatusHandlerReadRndNext,
+	StatusHandlerWrite,
+	StatusHandlerUpdate,
+	StatusHandlerDelete,
+	StatusCreatedTmpTables,
+	StatusCreatedTmpDiskTables,
+	StatusSelectScan,
+}
+
+// GlobalStatusVariables are the status variables only counted globally.
+var GlobalStatusVariables = []string{
+	StatusAbortedClients,
+	StatusConnections,
+	StatusConnectionErrorsMaxConnections,
+	StatusThreadsConnected,
+	StatusMaxUsedConnections,
+	StatusInnodbRowsRead,
+	StatusInnodbRowsInserted,
+	StatusInnodbRowsUpdated,
+	StatusInnodbRowsDeleted,
+	StatusInnodbBufferPoolReads,
+	StatusInnodbBufferPoolReadRequests,
+}
+
+// StatusVariables holds the values of status variables that are counters, by name. It's safe for concurrent use.
+type StatusVariables struct {
+	mu     sync.RWMutex
+	values map[string]*int64
+}
+
+var _ StatusProvider = (*StatusVariables)(nil)
+
+// NewStatusVariables creates StatusVariables holding the variables given, which are shown as 0 until they change.
+func NewStatusVariables(names ...string) *StatusVariables {
+	s := &StatusVariables{values: make(map[string]*int64, len(names))}
+	for _, name := range names {
+		s.values[name] = new(int64)
+	}
+	return s
+}
+
+func (s *StatusVariables) value(name string) *int64 {
+	s.mu.RLock()
+	v, ok := s.values[name]
+	s.mu.RUnlock()
+	if ok {
+		return v
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok = s.values[name]; !ok {
+		v = new(int64)
+		s.values[name] = v
+	}
+	return v
+}
+
+// Add adds delta to a variable and returns its new value. Nil StatusVariables ignore it.
+func (s *StatusVariables) Add(name string, delta int64) int64 {
+	if s == nil {
+		return 0
+	}
+	return atomic.AddInt64(s.value(name), delta)
+}
+
+// SetMax sets a variable to the value given if it's greater than its current one.
+func (s *StatusVariables) SetMax(name string, value int64) {
+	if s == nil {
+		return
+	}
+
+	v := s.value(name)
+	for {
+		current := atomic.LoadInt64(v)
+		if value <= current || atomic.CompareAndSwapInt64(v, current, value) {
+			return
+		}
+	}
+}
+
+// Get returns the value of a variable, which is 0 if it never changed.
+func (s *StatusVariables) Get(name string) int64 {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if v, ok := s.values[name]; ok {
+		return atomic.LoadInt64(v)
+	}
+	return 0
+}
+
+// Status implements the StatusProvider interface.
+func (s *StatusVariables) Status() map[string]interface{} {
+	if s == nil {
+		return make(map[string]interface{})
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result = make(map[string]interface{}, len(s.values))
+	for name, v := range s.values {
+		result[name] = atomic.LoadInt64(v)
+	}
+	return result
+}
+
+// StatusSession is a Session that counts its own status variables.
+type StatusSession interface {
+	Session
+	// StatusVariables returns the status variables of the session.
+	StatusVariables() *StatusVariables
+}
+
+// SessionStatus returns the status variables of the session of the context given, or nil if it doesn't count them.
+func SessionStatus(ctx *Context) *StatusVariables {
+	if s, ok := ctx.Session.(StatusSession); ok {
+		return s.StatusVariables()
+	}
+	return nil
+}
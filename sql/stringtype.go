@@ -195,6 +195,10 @@ func (t stringType) Compare(a interface{}, b interface{}) (int, error) {
 		bs = bi.(string)
 	}
 
+	// Strings of the default collation are compared by their bytes, which is how the indexes of the engine order them
+	if t.collation != Collation_Default && t.collation.IsCaseInsensitive() {
+		return strings.Compare(strings.ToLower(as), strings.ToLower(bs)), nil
+	}
 	return strings.Compare(as, bs), nil
 }
 
@@ -205,7 +209,7 @@ func (t stringType) Convert(v interface{}) (interface{}, error) {
 	}
 
 	if ti, ok := v.(time.Time); ok {
-		v = ti.Format(TimestampDatetimeLayout)
+		v = FormatTime(ti, TimestampDatetimeLayout)
 	}
 
 	val, err := cast.ToStringE(v)
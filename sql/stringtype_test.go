@@ -60,6 +60,11 @@ func TestStringCompare(t *testing.T) {
 		{MustCreateBinary(sqltypes.VarBinary, 10), false, 1, 1},
 		{MustCreateBinary(sqltypes.VarBinary, 10), 0, 1, -1},
 		{MustCreateBinary(sqltypes.VarBinary, 10), []byte("254"), 254, 0},
+
+		// Collations
+		{MustCreateString(sqltypes.VarChar, 10, Collation_utf8mb4_general_ci), "abc", "ABC", 0},
+		{MustCreateString(sqltypes.VarChar, 10, Collation_utf8mb4_general_ci), "abc", "ABD", -1},
+		{MustCreateString(sqltypes.VarChar, 10, Collation_utf8mb4_bin), "abc", "ABC", 1},
 	}
 
 	for _, test := range tests {
@@ -295,6 +300,8 @@ func TestStringConvert(t *testing.T) {
 		{MustCreateStringWithDefaults(sqltypes.VarChar, 7), float64(11583.5), "11583.5", false},
 		{MustCreateStringWithDefaults(sqltypes.Char, 4), []byte("abcd"), "abcd", false},
 		{MustCreateStringWithDefaults(sqltypes.VarChar, 40), time.Date(2019, 12, 12, 12, 12, 12, 0, time.UTC), "2019-12-12 12:12:12", false},
+		{MustCreateStringWithDefaults(sqltypes.VarChar, 40), zeroTime, "0000-00-00 00:00:00", false},
+		{MustCreateStringWithDefaults(sqltypes.VarChar, 40), time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), "0000-01-01 00:00:00", false},
 
 		{MustCreateBinary(sqltypes.Binary, 3), "abcd", nil, true},
 		{MustCreateBinary(sqltypes.Blob, 3), strings.Repeat("0", tinyTextBlobMax+1), nil, true},
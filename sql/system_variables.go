@@ -0,0 +1,486 @@
+package sql
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SystemVariableScope is where a system variable has a value: globally, in each session, or both, in which case the
+// global value is the one new sessions start with.
+type SystemVariableScope byte
+
+const (
+	// SystemVariableScope_Global is the scope of variables with a global value only.
+	SystemVariableScope_Global SystemVariableScope = iota + 1
+	// SystemVariableScope_Session is the scope of variables with a value in each session only.
+	SystemVariableScope_Session
+	// SystemVariableScope_Both is the scope of variables with a global value and a value in each session.
+	SystemVariableScope_Both
+)
+
+// SystemVariable is the definition of a system variable.
+type SystemVariable struct {
+	// Name of the variable, in lowercase.
+	Name  string
+	Scope SystemVariableScope
+	// Dynamic is whether the variable can be set with SET, or it's read only.
+	Dynamic bool
+	Type    Type
+	// Default is the value of the variable until it's set.
+	Default interface{}
+	// Validate, if set, returns the value to set the variable to given one converted to its type, or false if the
+	// value isn't valid for the variable.
+	Validate func(value interface{}) (interface{}, bool)
+	// Value, if set, returns the global value of a read only variable that shows the state of the process, such as
+	// its GTIDs, which is the same in all the registries.
+	Value func() interface{}
+}
+
+// HasGlobal returns whether the variable has a global value.
+func (v SystemVariable) HasGlobal() bool {
+	return v.Scope != SystemVariableScope_Session
+}
+
+// HasSession returns whether the variable has a value in each session.
+func (v SystemVariable) HasSession() bool {
+	return v.Scope != SystemVariableScope_Global
+}
+
+// SystemVariableRegistry holds the system variables that exist and their global values. It's safe for concurrent use.
+type SystemVariableRegistry struct {
+	mu     sync.RWMutex
+	vars   map[string]SystemVariable
+	global map[string]interface{}
+}
+
+// NewSystemVariables creates a SystemVariableRegistry holding the system variables of the engine, with their default
+// values.
+func NewSystemVariables() *SystemVariableRegistry {
+	return NewSystemVariableRegistry(defaultSystemVariables()...)
+}
+
+// NewSystemVariableRegistry creates a SystemVariableRegistry holding the variables given.
+func NewSystemVariableRegistry(vars ...SystemVariable) *SystemVariableRegistry {
+	r := &SystemVariableRegistry{
+		vars:   make(map[string]SystemVariable),
+		global: make(map[string]interface{}),
+	}
+	r.Register(vars...)
+	return r
+}
+
+// Register adds variables to the registry, replacing the ones with the same name. Their global values are their
+// defaults.
+func (r *SystemVariableRegistry) Register(vars ...SystemVariable) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, v := range vars {
+		v.Name = strings.ToLower(v.Name)
+		r.vars[v.Name] = v
+		if v.HasGlobal() {
+			r.global[v.Name] = v.Default
+		} else {
+			delete(r.global, v.Name)
+		}
+	}
+}
+
+// Lookup returns the variable with the name given, if it exists.
+func (r *SystemVariableRegistry) Lookup(name string) (SystemVariable, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.vars[strings.ToLower(name)]
+	return v, ok
+}
+
+// All returns all the variables of the registry, sorted by name.
+func (r *SystemVariableRegistry) All() []SystemVariable {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	vars := make([]SystemVariable, 0, len(r.vars))
+	for _, v := range r.vars {
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+	return vars
+}
+
+// Global returns the global value of a variable, or false if it doesn't exist or has no global value.
+func (r *SystemVariableRegistry) Global(name string) (interface{}, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name = strings.ToLower(name)
+	if v, ok := r.vars[name]; ok && v.Value != nil {
+		return v.Value(), true
+	}
+	v, ok := r.global[name]
+	return v, ok
+}
+
+// SetGlobal sets the global value of a variable, which the sessions created from then on start with.
+func (r *SystemVariableRegistry) SetGlobal(name string, value interface{}) error {
+	v, value, err := r.Convert(name, true, value)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.global[v.Name] = value
+	return nil
+}
+
+// SetPersisted sets the global value of a variable to the one persisted for it, which read only variables can have
+// too.
+func (r *SystemVariableRegistry) SetPersisted(name string, value interface{}) error {
+	v, value, err := r.ConvertPersisted(name, value)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.global[v.Name] = value
+	return nil
+}
+
+// Convert checks that a variable can be set globally or in a session, and returns the variable along with the value
+// given converted to its type and validated.
+func (r *SystemVariableRegistry) Convert(name string, global bool, value interface{}) (SystemVariable, interface{}, error) {
+	return r.convert(name, global, false, value)
+}
+
+// ConvertPersisted checks that a variable can be persisted with SET PERSIST_ONLY, which read only variables can be,
+// and returns the variable along with the value given converted to its type and validated.
+func (r *SystemVariableRegistry) ConvertPersisted(name string, value interface{}) (SystemVariable, interface{}, error) {
+	return r.convert(name, true, true, value)
+}
+
+func (r *SystemVariableRegistry) convert(name string, global, readOnly bool, value interface{}) (SystemVariable, interface{}, error) {
+	v, ok := r.Lookup(name)
+	switch {
+	case !ok:
+		return v, nil, ErrUnknownSystemVariable.New(name)
+	case !v.Dynamic && !readOnly:
+		return v, nil, ErrSystemVariableReadOnly.New(v.Name)
+	case global && !v.HasGlobal():
+		return v, nil, ErrSystemVariableSessionOnly.New(v.Name)
+	case !global && !v.HasSession():
+		return v, nil, ErrSystemVariableGlobalOnly.New(v.Name)
+	}
+
+	converted, err := v.Type.Convert(value)
+	if err != nil {
+		return v, nil, ErrInvalidSystemVariableValue.New(v.Name, value)
+	}
+
+	if v.Validate != nil && converted != nil {
+		if converted, ok = v.Validate(converted); !ok {
+			return v, nil, ErrInvalidSystemVariableValue.New(v.Name, value)
+		}
+	}
+	return v, converted, nil
+}
+
+// SessionDefaults returns the values sessions start with of the variables with a value in each session: their global
+// values, or their defaults if they have none.
+func (r *SystemVariableRegistry) SessionDefaults() map[string]TypedValue {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	config := make(map[string]TypedValue, len(r.vars))
+	for name, v := range r.vars {
+		if !v.HasSession() {
+			continue
+		}
+
+		value := v.Default
+		if global, ok := r.global[name]; ok {
+			value = global
+		}
+		config[name] = TypedValue{v.Type, value}
+	}
+	return config
+}
+
+// InitSession sets the variables of a new session, which start with their defaults, to their global values where
+// they have been changed.
+func (r *SystemVariableRegistry) InitSession(ctx context.Context, s Session) error {
+	r.mu.RLock()
+	values := make(map[string]TypedValue)
+	for name, v := range r.vars {
+		if global, ok := r.global[name]; ok && v.HasSession() && global != v.Default {
+			values[name] = TypedValue{v.Type, global}
+		}
+	}
+	r.mu.RUnlock()
+
+	for name, v := range values {
+		if err := s.Set(ctx, name, v.Typ, v.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// boolVariable validates the values of the variables that are ON (1) or OFF (0).
+func boolVariable(value interface{}) (interface{}, bool) {
+	n, ok := value.(int8)
+	return n, ok && (n == 0 || n == 1)
+}
+
+// rangeVariable returns a function validating that the values of integer variables are between min and max.
+func rangeVariable(min, max int64) func(interface{}) (interface{}, bool) {
+	return func(value interface{}) (interface{}, bool) {
+		n, ok := value.(int64)
+		return n, ok && n >= min && n <= max
+	}
+}
+
+// enumVariable returns a function validating that the values of string variables are one of the values given,
+// regardless of case, which are used in uppercase.
+func enumVariable(values ...string) func(interface{}) (interface{}, bool) {
+	return func(value interface{}) (interface{}, bool) {
+		s, ok := value.(string)
+		if !ok {
+			return value, false
+		}
+
+		s = strings.ToUpper(s)
+		for _, v := range values {
+			if s == v {
+				return s, true
+			}
+		}
+		return s, false
+	}
+}
+
+// optimizerSwitchFlags are the flags of optimizer_switch, with their default values.
+var optimizerSwitchFlags = []struct {
+	name string
+	on   bool
+}{
+	{"index_merge", true},
+	{"index_merge_union", true},
+	{"index_merge_sort_union", true},
+	{"index_merge_intersection", true},
+	{"engine_condition_pushdown", true},
+	{"index_condition_pushdown", true},
+	{"mrr", true},
+	{"mrr_cost_based", true},
+	{"block_nested_loop", true},
+	{"batched_key_access", false},
+	{"materialization", true},
+	{"semijoin", true},
+	{"loosescan", true},
+	{"firstmatch", true},
+	{"duplicateweedout", true},
+	{"subquery_materialization_cost_based", true},
+	{"use_index_extensions", true},
+	{"condition_fanout_filter", true},
+	{"derived_merge", true},
+	{"use_invisible_indexes", false},
+	{"skip_scan", true},
+	{"hash_join", true},
+}
+
+// formatOptimizerSwitch returns the value of optimizer_switch with the flags given on, and the others off.
+func formatOptimizerSwitch(on map[string]bool) string {
+	flags := make([]string, len(optimizerSwitchFlags))
+	for i, flag := range optimizerSwitchFlags {
+		value := "off"
+		if on[flag.name] {
+			value = "on"
+		}
+		flags[i] = flag.name + "=" + value
+	}
+	return strings.Join(flags, ",")
+}
+
+// optimizerSwitchDefaults returns whether each flag of optimizer_switch is on by default.
+func optimizerSwitchDefaults() map[string]bool {
+	on := make(map[string]bool)
+	for _, flag := range optimizerSwitchFlags {
+		on[flag.name] = flag.on
+	}
+	return on
+}
+
+// optimizerSwitchVariable validates a value of optimizer_switch, a list of flag=value separated by commas, where value is
+// on, off or default. It returns the value with every flag, the ones not in the list with their default values.
+func optimizerSwitchVariable(value interface{}) (interface{}, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return value, false
+	}
+
+	defaults, on := optimizerSwitchDefaults(), optimizerSwitchDefaults()
+	for _, setting := range strings.Split(s, ",") {
+		setting = strings.ToLower(strings.TrimSpace(setting))
+		if setting == "default" {
+			on = optimizerSwitchDefaults()
+			continue
+		}
+
+		parts := strings.SplitN(setting, "=", 2)
+		if len(parts) != 2 {
+			return s, false
+		}
+		name, v := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if _, ok := defaults[name]; !ok {
+			return s, false
+		}
+
+		switch v {
+		case "on":
+			on[name] = true
+		case "off":
+			on[name] = false
+		case "default":
+			on[name] = defaults[name]
+		default:
+			return s, false
+		}
+	}
+	return formatOptimizerSwitch(on), true
+}
+
+// OptimizerSwitch returns whether the flag of optimizer_switch given is on in the session of the context given.
+func OptimizerSwitch(ctx *Context, flag string) bool {
+	_, value := ctx.Get("optimizer_switch")
+	s, _ := value.(string)
+	for _, setting := range strings.Split(s, ",") {
+		if setting == flag+"=on" {
+			return true
+		}
+	}
+	return false
+}
+
+// ForeignKeyChecks returns whether the session of the context given checks foreign keys, which it doesn't while its
+// foreign_key_checks variable is 0, as while dumps are loaded. Neither the engine nor the tables that check foreign keys
+// themselves should check them or apply their referential actions then.
+func ForeignKeyChecks(ctx *Context) bool {
+	_, value := ctx.Get("foreign_key_checks")
+	n, ok := value.(int8)
+	return !ok || n != 0
+}
+
+// AutoIncrementSequence returns the values of auto_increment_increment and auto_increment_offset, which make
+// AUTO_INCREMENT columns generate the values offset, offset + increment, offset + 2 * increment and so on. Like in
+// MySQL, the offset is ignored when it's greater than the increment.
+func AutoIncrementSequence(ctx *Context) (increment, offset int64) {
+	increment, offset = 1, 1
+	_, value := ctx.Get("auto_increment_increment")
+	if n, ok := value.(int64); ok {
+		increment = n
+	}
+	_, value = ctx.Get("auto_increment_offset")
+	if n, ok := value.(int64); ok && n <= increment {
+		offset = n
+	}
+	return increment, offset
+}
+
+func nonNegativeFloat(value interface{}) (interface{}, bool) {
+	f, ok := value.(float64)
+	return f, ok && f >= 0
+}
+
+// serverUUID is the default @@server_uuid, which identifies the process in the GTIDs of its binary logs.
+var serverUUID = newServerUUID()
+
+// newServerUUID returns a random version 4 UUID.
+func newServerUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func defaultSystemVariables() []SystemVariable {
+	hostname, _ := os.Hostname()
+	charset := Collation_Default.CharacterSet().String()
+	collation := Collation_Default.String()
+
+	return []SystemVariable{
+		{Name: "auto_increment_increment", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int64, Default: int64(1), Validate: rangeVariable(1, math.MaxUint16)},
+		{Name: "auto_increment_offset", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int64, Default: int64(1), Validate: rangeVariable(1, math.MaxUint16)},
+		{Name: "autocommit", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: 0, Validate: boolVariable},
+		{Name: "binlog_checksum", Scope: SystemVariableScope_Global, Type: LongText, Default: "CRC32"},
+		{Name: "binlog_format", Scope: SystemVariableScope_Both, Type: LongText, Default: "ROW"},
+		{Name: "binlog_row_image", Scope: SystemVariableScope_Both, Type: LongText, Default: "FULL"},
+		{Name: "character_set_client", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: charset},
+		{Name: "character_set_connection", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: charset},
+		{Name: "character_set_database", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: charset},
+		{Name: "character_set_results", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: charset},
+		{Name: "character_set_server", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: charset},
+		{Name: "character_set_system", Scope: SystemVariableScope_Global, Type: LongText, Default: CharacterSet_utf8.String()},
+		{Name: "collation_connection", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: collation},
+		{Name: "collation_database", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: collation},
+		{Name: "collation_server", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: collation},
+		{Name: "default_storage_engine", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: "InnoDB"},
+		{Name: "foreign_key_checks", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(1), Validate: boolVariable},
+		{Name: "general_log", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(0), Validate: boolVariable},
+		{Name: "gtid_executed", Scope: SystemVariableScope_Global, Type: LongText, Default: "", Value: executedGTIDs},
+		{Name: "gtid_mode", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int32, Default: int32(0)},
+		{Name: "gtid_purged", Scope: SystemVariableScope_Global, Type: LongText, Default: "", Value: purgedGTIDs},
+		{Name: "hostname", Scope: SystemVariableScope_Global, Type: LongText, Default: hostname},
+		{Name: "inmemory_joins", Scope: SystemVariableScope_Session, Dynamic: true, Type: Int8, Default: nil, Validate: boolVariable},
+		{Name: "init_connect", Scope: SystemVariableScope_Global, Dynamic: true, Type: LongText, Default: ""},
+		{Name: "innodb_lock_wait_timeout", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int64, Default: int64(50), Validate: rangeVariable(1, 1073741824)},
+		{Name: "interactive_timeout", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int64, Default: int64(28800), Validate: rangeVariable(1, 31536000)},
+		{Name: "license", Scope: SystemVariableScope_Global, Type: LongText, Default: "GPL"},
+		{Name: "lock_wait_timeout", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int64, Default: int64(31536000), Validate: rangeVariable(1, 31536000)},
+		{Name: "long_query_time", Scope: SystemVariableScope_Both, Dynamic: true, Type: Float64, Default: float64(10), Validate: nonNegativeFloat},
+		{Name: "lower_case_table_names", Scope: SystemVariableScope_Global, Type: Int32, Default: int32(0)},
+		{Name: "max_allowed_packet", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int32, Default: math.MaxInt32},
+		{Name: "max_connections", Scope: SystemVariableScope_Global, Dynamic: true, Type: Int64, Default: int64(151), Validate: rangeVariable(1, 100000)},
+		{Name: "max_user_connections", Scope: SystemVariableScope_Global, Dynamic: true, Type: Int64, Default: int64(0), Validate: rangeVariable(0, math.MaxUint32)},
+		{Name: "max_execution_time", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int64, Default: int64(0), Validate: rangeVariable(0, math.MaxUint32)},
+		{Name: "ndbinfo_version", Scope: SystemVariableScope_Both, Type: LongText, Default: ""},
+		{Name: "net_read_timeout", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int64, Default: int64(30), Validate: rangeVariable(1, 31536000)},
+		{Name: "net_write_timeout", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int64, Default: int64(60), Validate: rangeVariable(1, 31536000)},
+		{Name: "optimizer_switch", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: formatOptimizerSwitch(optimizerSwitchDefaults()), Validate: optimizerSwitchVariable},
+		{Name: "protocol_version", Scope: SystemVariableScope_Global, Type: Int32, Default: int32(10)},
+		{Name: "server_id", Scope: SystemVariableScope_Global, Dynamic: true, Type: Int64, Default: int64(1), Validate: rangeVariable(0, math.MaxUint32)},
+		{Name: "server_uuid", Scope: SystemVariableScope_Global, Type: LongText, Default: serverUUID},
+		{Name: "session_track_gtids", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: "OFF", Validate: enumVariable("OFF", "OWN_GTID", "ALL_GTIDS")},
+		{Name: "session_track_schema", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(1), Validate: boolVariable},
+		{Name: "session_track_state_change", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(0), Validate: boolVariable},
+		{Name: "session_track_system_variables", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: "time_zone,autocommit,character_set_client,character_set_results,character_set_connection"},
+		{Name: "session_track_transaction_info", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: "OFF", Validate: enumVariable("OFF", "STATE", "CHARACTERISTICS")},
+		{Name: "slow_query_log", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(0), Validate: boolVariable},
+		{Name: "sql_auto_is_null", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(0), Validate: boolVariable},
+		{Name: "sql_mode", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: defaultSqlMode},
+		{Name: "sql_notes", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(1), Validate: boolVariable},
+		{Name: "sql_quote_show_create", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(1), Validate: boolVariable},
+		{Name: "sql_safe_updates", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(0), Validate: boolVariable},
+		{Name: "sql_select_limit", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int32, Default: math.MaxInt32},
+		{Name: "sql_warnings", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(0), Validate: boolVariable},
+		{Name: "system_time_zone", Scope: SystemVariableScope_Both, Type: LongText, Default: time.Now().UTC().Location().String()},
+		{Name: "time_zone", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: "SYSTEM"},
+		{Name: "transaction_isolation", Scope: SystemVariableScope_Both, Dynamic: true, Type: LongText, Default: "READ UNCOMMITTED", Validate: enumVariable(
+			"READ UNCOMMITTED", "READ-UNCOMMITTED", "READ COMMITTED", "READ-COMMITTED",
+			"REPEATABLE READ", "REPEATABLE-READ", "SERIALIZABLE",
+		)},
+		{Name: "transaction_read_only", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(0), Validate: boolVariable},
+		{Name: "unique_checks", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(1), Validate: boolVariable},
+		{Name: "version", Scope: SystemVariableScope_Both, Type: LongText, Default: ""},
+		{Name: "version_comment", Scope: SystemVariableScope_Both, Type: LongText, Default: ""},
+		{Name: "wait_timeout", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int64, Default: int64(28800), Validate: rangeVariable(1, 31536000)},
+	}
+}
@@ -0,0 +1,87 @@
+package sql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemVariableRegistry(t *testing.T) {
+	require := require.New(t)
+
+	r := NewSystemVariableRegistry(
+		SystemVariable{Name: "Both", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int64, Default: int64(1), Validate: rangeVariable(1, 10)},
+		SystemVariable{Name: "global", Scope: SystemVariableScope_Global, Dynamic: true, Type: LongText, Default: "a"},
+		SystemVariable{Name: "session", Scope: SystemVariableScope_Session, Dynamic: true, Type: Int8, Default: int8(0), Validate: boolVariable},
+		SystemVariable{Name: "read_only", Scope: SystemVariableScope_Both, Type: LongText, Default: "b"},
+	)
+
+	require.Equal(map[string]TypedValue{
+		"both":      {Int64, int64(1)},
+		"session":   {Int8, int8(0)},
+		"read_only": {LongText, "b"},
+	}, r.SessionDefaults())
+
+	require.NoError(r.SetGlobal("BOTH", "5"))
+	v, ok := r.Global("both")
+	require.True(ok)
+	require.Equal(int64(5), v)
+	require.Equal(TypedValue{Int64, int64(5)}, r.SessionDefaults()["both"])
+
+	_, ok = r.Global("session")
+	require.False(ok)
+
+	_, value, err := r.Convert("session", false, 1)
+	require.NoError(err)
+	require.Equal(int8(1), value)
+
+	for _, tt := range []struct {
+		name   string
+		global bool
+		value  interface{}
+		err    string
+	}{
+		{"unknown", false, 1, ErrUnknownSystemVariable.New("unknown").Error()},
+		{"read_only", false, "c", ErrSystemVariableReadOnly.New("read_only").Error()},
+		{"session", true, 1, ErrSystemVariableSessionOnly.New("session").Error()},
+		{"global", false, "b", ErrSystemVariableGlobalOnly.New("global").Error()},
+		{"both", true, 11, ErrInvalidSystemVariableValue.New("both", 11).Error()},
+		{"session", false, 2, ErrInvalidSystemVariableValue.New("session", 2).Error()},
+	} {
+		_, _, err := r.Convert(tt.name, tt.global, tt.value)
+		require.EqualError(err, tt.err)
+	}
+
+	r.Register(SystemVariable{Name: "session", Scope: SystemVariableScope_Both, Dynamic: true, Type: Int8, Default: int8(1)})
+	v, ok = r.Global("session")
+	require.True(ok)
+	require.Equal(int8(1), v)
+}
+
+func TestOptimizerSwitchVariable(t *testing.T) {
+	require := require.New(t)
+
+	defaults := formatOptimizerSwitch(optimizerSwitchDefaults())
+	require.Contains(defaults, "use_invisible_indexes=off")
+
+	for _, tt := range []struct {
+		value    string
+		expected string
+		ok       bool
+	}{
+		{"use_invisible_indexes=on", strings.Replace(defaults, "use_invisible_indexes=off", "use_invisible_indexes=on", 1), true},
+		{"USE_INVISIBLE_INDEXES = ON, hash_join=off", strings.Replace(strings.Replace(defaults, "use_invisible_indexes=off", "use_invisible_indexes=on", 1), "hash_join=on", "hash_join=off", 1), true},
+		{"use_invisible_indexes=on,default", defaults, true},
+		{"use_invisible_indexes=on,use_invisible_indexes=default", defaults, true},
+		{"unknown=on", "", false},
+		{"use_invisible_indexes=yes", "", false},
+		{"use_invisible_indexes", "", false},
+	} {
+		value, ok := optimizerSwitchVariable(tt.value)
+		require.Equal(tt.ok, ok, tt.value)
+		if tt.ok {
+			require.Equal(tt.expected, value, tt.value)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names of the table options of CREATE TABLE that the engine knows about. Integrators can find any other option
+// given, by the uppercase name it was given with.
+const (
+	TableOption_AutoIncrement = "AUTO_INCREMENT"
+	TableOption_CharacterSet  = "CHARACTER SET"
+	TableOption_Collate       = "COLLATE"
+	TableOption_Comment       = "COMMENT"
+	TableOption_Engine        = "ENGINE"
+	TableOption_RowFormat     = "ROW_FORMAT"
+)
+
+// TableOption is an option of CREATE TABLE, such as ENGINE=InnoDB or COMMENT='text'.
+type TableOption struct {
+	// Name is the uppercase name of the option. The optional DEFAULT before the character set and collation options
+	// is left out, and CHARSET is given as CHARACTER SET.
+	Name string
+	// Value is the value of the option, without the quotes of string values.
+	Value string
+	// Quoted is true if the value was given as a string.
+	Quoted bool
+}
+
+// String returns the option as it's given in CREATE TABLE.
+func (o TableOption) String() string {
+	if o.Quoted {
+		return fmt.Sprintf("%s='%s'", o.Name, strings.ReplaceAll(o.Value, "'", "''"))
+	}
+	return fmt.Sprintf("%s=%s", o.Name, o.Value)
+}
+
+// TableOptions are the options of CREATE TABLE, in the order they were given.
+type TableOptions []TableOption
+
+// Get returns the value of the last option with the name given, and whether the options have one.
+func (o TableOptions) Get(name string) (string, bool) {
+	for i := len(o) - 1; i >= 0; i-- {
+		if strings.EqualFold(o[i].Name, name) {
+			return o[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// String returns the options as they're given in CREATE TABLE.
+func (o TableOptions) String() string {
+	options := make([]string, len(o))
+	for i, option := range o {
+		options[i] = option.String()
+	}
+	return strings.Join(options, " ")
+}
@@ -307,6 +307,35 @@ func ColumnTypeToType(ct *sqlparser.ColumnType) (Type, error) {
 	return nil, fmt.Errorf("type not yet implemented: %v", ct.Type)
 }
 
+// CollatedType returns the type given with the collation given, if it's a string type of characters, an ENUM or a SET,
+// or the type given otherwise. Strings keep their length in characters, but the TEXT types keep their length in bytes.
+func CollatedType(t Type, collation Collation) (Type, error) {
+	switch t := t.(type) {
+	case StringType:
+		if t.Collation() == Collation_binary || t.Collation() == collation {
+			return t, nil
+		}
+		length := t.MaxCharacterLength()
+		if t.Type() == sqltypes.Text {
+			maxBytes := longTextBlobMax
+			for _, max := range []int64{tinyTextBlobMax, textBlobMax, mediumTextBlobMax} {
+				if t.MaxByteLength() <= max {
+					maxBytes = max
+					break
+				}
+			}
+			length = maxBytes / collation.CharacterSet().MaxLength()
+		}
+		return CreateString(t.Type(), length, collation)
+	case EnumType:
+		return CreateEnumType(t.Values(), collation)
+	case SetType:
+		return CreateSetType(t.Values(), collation)
+	default:
+		return t, nil
+	}
+}
+
 func ConvertToBool(v interface{}) (bool, error) {
 	switch b := v.(type) {
 	case bool:
@@ -346,7 +375,7 @@ func ConvertToBool(v interface{}) (bool, error) {
 		}
 		return true, nil
 	case time.Time:
-		if b.UnixNano() == 0 {
+		if b.UnixNano() == 0 || IsZeroTime(b) {
 			return false, nil
 		}
 		return true, nil
@@ -0,0 +1,95 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+var (
+	// ErrUserManagementNotSupported is returned when an account management statement is executed and the
+	// authentication method in use does not manage its own users.
+	ErrUserManagementNotSupported = errors.NewKind("the authentication method does not support account management")
+
+	// ErrUserAlreadyExists is returned by a UserManager when creating an account that already exists.
+	ErrUserAlreadyExists = errors.NewKind("user %s already exists")
+
+	// ErrUserNotFound is returned by a UserManager when the account given does not exist.
+	ErrUserNotFound = errors.NewKind("user %s does not exist")
+
+	// ErrUserOperationFailed is returned when an account management statement fails for some of its accounts,
+	// mirroring MySQL's ER_CANNOT_USER.
+	ErrUserOperationFailed = errors.NewKind("Operation %s failed for %s")
+)
+
+// Account identifies a user account by its user name and the host it connects from. As in MySQL, the host may
+// contain the wildcards % and _.
+type Account struct {
+	Name string
+	Host string
+}
+
+// String returns the account in the 'name'@'host' form used by MySQL.
+func (a Account) String() string {
+	return fmt.Sprintf("'%s'@'%s'", a.Name, a.Host)
+}
+
+// Equal returns whether both accounts are the same. User names are case sensitive and host names are not.
+func (a Account) Equal(other Account) bool {
+	return a.Name == other.Name && strings.EqualFold(a.Host, other.Host)
+}
+
+// TLSRequirement holds the transport requirements of an account, given by the REQUIRE clause of CREATE USER.
+type TLSRequirement struct {
+	// SSL requires the account to connect using TLS.
+	SSL bool
+	// X509 requires the account to present a valid client certificate.
+	X509 bool
+	// Subject, if not empty, requires the client certificate to have this subject, in the /C=.../CN=... form.
+	Subject string
+	// Issuer, if not empty, requires the client certificate to be issued by this subject, in the /C=.../CN=... form.
+	Issuer string
+}
+
+// String returns the requirement as a REQUIRE clause, or an empty string if there's no requirement.
+func (r TLSRequirement) String() string {
+	var options []string
+	if r.Subject != "" {
+		options = append(options, "SUBJECT "+quoteString(r.Subject))
+	}
+	if r.Issuer != "" {
+		options = append(options, "ISSUER "+quoteString(r.Issuer))
+	}
+
+	switch {
+	case len(options) > 0:
+		return "REQUIRE " + strings.Join(options, " AND ")
+	case r.X509:
+		return "REQUIRE X509"
+	case r.SSL:
+		return "REQUIRE SSL"
+	default:
+		return ""
+	}
+}
+
+func quoteString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// UserManager is implemented by authentication methods that store their own users, making it possible to manage
+// them with CREATE USER, DROP USER and SET PASSWORD statements.
+type UserManager interface {
+	// CreateUser creates a new account with the password given, in clear text, and the transport requirements
+	// given. It must return an error if the account already exists, and ErrPasswordPolicy if the password is not
+	// strong enough.
+	CreateUser(ctx *Context, account Account, password string, require TLSRequirement) error
+	// DropUser removes an account. It must return an error if the account does not exist.
+	DropUser(ctx *Context, account Account) error
+	// SetPassword changes the password of an existing account to the one given, in clear text. It must return
+	// ErrPasswordPolicy if the password is not strong enough.
+	SetPassword(ctx *Context, account Account, password string) error
+	// CurrentAccount returns the account the session of the context given authenticated as.
+	CurrentAccount(ctx *Context) (Account, error)
+}
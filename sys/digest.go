@@ -0,0 +1,94 @@
+package sys
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+)
+
+// operators are the texts of the tokens the tokenizer returns without them.
+var operators = map[int]string{
+	sqlparser.OR:                      "OR",
+	sqlparser.AND:                     "AND",
+	sqlparser.LE:                      "<=",
+	sqlparser.GE:                      ">=",
+	sqlparser.NE:                      "!=",
+	sqlparser.NULL_SAFE_EQUAL:         "<=>",
+	sqlparser.SHIFT_LEFT:              "<<",
+	sqlparser.SHIFT_RIGHT:             ">>",
+	sqlparser.JSON_EXTRACT_OP:         "->",
+	sqlparser.JSON_UNQUOTE_EXTRACT_OP: "->>",
+}
+
+// Digest returns the normalized text of a query and its digest, the SHA-256
+// hash of the text, as MySQL computes them to group the statistics of the
+// queries that only differ in their literals. Literals are replaced with ?,
+// lists of them with (...), identifiers are quoted and keywords uppercased.
+func Digest(query string) (text, digest string) {
+	var tokens []string
+	tkn := sqlparser.NewStringTokenizer(query)
+	for {
+		typ, val := tkn.Scan()
+		if typ == 0 || typ == ';' {
+			break
+		}
+
+		var tok string
+		switch typ {
+		case sqlparser.COMMENT:
+			continue
+		case sqlparser.STRING, sqlparser.INTEGRAL, sqlparser.FLOAT, sqlparser.HEXNUM, sqlparser.HEX,
+			sqlparser.BIT_LITERAL, sqlparser.VALUE_ARG, sqlparser.LIST_ARG:
+			tok = "?"
+		case sqlparser.ID:
+			tok = "`" + strings.ReplaceAll(string(val), "`", "``") + "`"
+		case sqlparser.LEX_ERROR:
+			tok = string(val)
+		default:
+			if op, ok := operators[typ]; ok && val == nil {
+				tok = op
+			} else if val != nil {
+				tok = strings.ToUpper(string(val))
+			} else {
+				tok = string(rune(typ))
+			}
+		}
+
+		tokens = append(tokens, tok)
+		tokens = collapseList(tokens)
+	}
+
+	text = strings.Join(tokens, " ")
+	hash := sha256.Sum256([]byte(text))
+	return text, hex.EncodeToString(hash[:])
+}
+
+// collapseList replaces the list of literals of an IN or VALUES clause the
+// tokens end with, if any, with (...), and consecutive lists of rows with
+// (...) /* , ... */.
+func collapseList(tokens []string) []string {
+	n := len(tokens)
+	if n == 0 || tokens[n-1] != ")" {
+		return tokens
+	}
+
+	i := n - 2
+	for i >= 0 && (tokens[i] == "?" || tokens[i] == ",") {
+		i--
+	}
+	if i < 1 || tokens[i] != "(" || tokens[n-2] != "?" {
+		return tokens
+	}
+
+	switch tokens[i-1] {
+	case "IN", "VALUES", "VALUE":
+		return append(tokens[:i], "(...)")
+	case ",":
+		if i >= 2 && (tokens[i-2] == "(...)" || tokens[i-2] == "(...) /* , ... */") {
+			return append(tokens[:i-2], "(...) /* , ... */")
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,77 @@
+package sys
+
+import (
+	"fmt"
+	"time"
+)
+
+// statementTruncateLen is the length statements are truncated to in the
+// formatted views, the default of sys.statement_truncate_len.
+const statementTruncateLen = 64
+
+var picoTimeUnits = []struct {
+	picos uint64
+	unit  string
+}{
+	{604800000000000000, "w"},
+	{86400000000000000, "d"},
+	{3600000000000000, "h"},
+	{60000000000000, "min"},
+	{1000000000000, "s"},
+	{1000000000, "ms"},
+	{1000000, "us"},
+	{1000, "ns"},
+}
+
+// picos returns a duration in picoseconds, the unit performance_schema
+// reports times in.
+func picos(d time.Duration) uint64 {
+	if d < 0 {
+		return 0
+	}
+	return uint64(d) * 1000
+}
+
+// formatPicoTime formats a time in picoseconds in human readable units, as
+// sys.format_time does.
+func formatPicoTime(picos uint64) string {
+	for _, u := range picoTimeUnits {
+		if picos >= u.picos {
+			return fmt.Sprintf("%.2f %s", float64(picos)/float64(u.picos), u.unit)
+		}
+	}
+	return fmt.Sprintf("%d ps", picos)
+}
+
+var byteUnits = []struct {
+	bytes uint64
+	unit  string
+}{
+	{1 << 60, "EiB"},
+	{1 << 50, "PiB"},
+	{1 << 40, "TiB"},
+	{1 << 30, "GiB"},
+	{1 << 20, "MiB"},
+	{1 << 10, "KiB"},
+}
+
+// formatBytes formats a number of bytes in human readable units, as
+// sys.format_bytes does.
+func formatBytes(bytes uint64) string {
+	for _, u := range byteUnits {
+		if bytes >= u.bytes {
+			return fmt.Sprintf("%.2f %s", float64(bytes)/float64(u.bytes), u.unit)
+		}
+	}
+	return fmt.Sprintf("%d bytes", bytes)
+}
+
+// formatStatement truncates a statement to be displayed, as
+// sys.format_statement does, keeping its start and its end.
+func formatStatement(s string) string {
+	if len(s) <= statementTruncateLen {
+		return s
+	}
+	half := statementTruncateLen/2 - 2
+	return s[:half] + " ... " + s[len(s)-half:]
+}
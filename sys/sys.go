@@ -0,0 +1,465 @@
+// Package sys implements the most used views of the sys schema of MySQL,
+// statement_analysis, schema_table_statistics and host_summary, along with
+// their x$ variants, so that the administration tools that query them keep
+// working. The statistics the views show are the ones a Collector gathers
+// from the queries the engine runs and the connections the server accepts,
+// instead of the performance_schema tables MySQL computes them from.
+package sys
+
+import (
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// MaxDigests is the number of statement digests a Collector keeps statistics
+// of, the default of performance_schema_digests_size. The statistics of
+// statements with other digests are aggregated in a row with a NULL digest.
+const MaxDigests = 10000
+
+// Collector gathers the statistics of queries and connections the views of
+// the database it returns show. A nil *Collector gathers nothing.
+type Collector struct {
+	mu         sync.Mutex
+	statements map[statementKey]*statementStats
+	tables     map[tableKey]*tableStats
+	hosts      map[string]*hostStats
+}
+
+type statementKey struct {
+	db, digest string
+}
+
+type statementStats struct {
+	db, digest, text string
+
+	execCount, errCount, warnCount uint64
+	fullScans                      uint64
+	totalLatency, maxLatency       time.Duration
+	rowsSent, rowsExamined         uint64
+	rowsAffected                   uint64
+	firstSeen, lastSeen            time.Time
+}
+
+type tableKey struct {
+	schema, name string
+}
+
+type tableStats struct {
+	schema, name string
+
+	rowsFetched, rowsInserted, rowsUpdated, rowsDeleted       uint64
+	fetchLatency, insertLatency, updateLatency, deleteLatency time.Duration
+}
+
+type hostStats struct {
+	host string
+
+	statements, tableScans uint64
+	latency                time.Duration
+	current, total         uint64
+	users                  map[string]struct{}
+}
+
+// NewCollector creates a Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		statements: make(map[statementKey]*statementStats),
+		tables:     make(map[tableKey]*tableStats),
+		hosts:      make(map[string]*hostStats),
+	}
+}
+
+// Connect records that the client of the context given has connected.
+func (c *Collector) Connect(ctx *sql.Context) {
+	if c == nil {
+		return
+	}
+
+	client := ctx.Client()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h := c.host(client.Address)
+	h.current++
+	h.total++
+	h.users[client.User] = struct{}{}
+}
+
+// Disconnect records that the client of the context given has disconnected.
+func (c *Collector) Disconnect(ctx *sql.Context) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if h := c.host(ctx.Client().Address); h.current > 0 {
+		h.current--
+	}
+}
+
+// QueryFailed records a query that failed before returning its rows.
+func (c *Collector) QueryFailed(ctx *sql.Context, query string, start time.Time, err error) {
+	if c == nil {
+		return
+	}
+
+	c.record(ctx, execution{
+		query:   query,
+		start:   start,
+		latency: time.Since(start),
+		failed:  true,
+	})
+}
+
+// TrackQuery returns an iterator that records the statistics of a query
+// once its rows have been read and it's closed. The processes given are the
+// ones the rows read by the query are counted in, and the parsed and the
+// analyzed nodes the query's, to tell the tables it used.
+func (c *Collector) TrackQuery(
+	ctx *sql.Context,
+	processes *sql.ProcessList,
+	query string,
+	parsed, analyzed sql.Node,
+	start time.Time,
+	iter sql.RowIter,
+) sql.RowIter {
+	if c == nil {
+		return iter
+	}
+
+	return &trackedIter{
+		c:         c,
+		ctx:       ctx,
+		processes: processes,
+		e: execution{
+			query:    query,
+			start:    start,
+			tables:   queryTables(ctx, parsed),
+			target:   targetTable(ctx, parsed),
+			fullScan: fullScan(analyzed),
+		},
+		iter: iter,
+	}
+}
+
+// execution holds what's known about an execution of a query once it has
+// finished.
+type execution struct {
+	query    string
+	start    time.Time
+	latency  time.Duration
+	failed   bool
+	warnings uint16
+
+	// tables are the tables the query uses by lowercase name, and target the
+	// one it writes to, if any.
+	tables   map[string]tableKey
+	target   *tableKey
+	fullScan bool
+
+	rowsSent, rowsAffected uint64
+	rowsRead               map[string]int64
+}
+
+func (c *Collector) record(ctx *sql.Context, e execution) {
+	text, digest := Digest(e.query)
+	db := ctx.GetCurrentDatabase()
+	end := e.start.Add(e.latency)
+
+	var rowsExamined uint64
+	for _, n := range e.rowsRead {
+		rowsExamined += uint64(n)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := statementKey{db, digest}
+	s, ok := c.statements[key]
+	if !ok {
+		if len(c.statements) >= MaxDigests {
+			key = statementKey{}
+			text, digest = "", ""
+			s = c.statements[key]
+		}
+		if s == nil {
+			s = &statementStats{db: key.db, digest: digest, text: text, firstSeen: e.start}
+			c.statements[key] = s
+		}
+	}
+
+	s.execCount++
+	if e.failed {
+		s.errCount++
+	}
+	s.warnCount += uint64(e.warnings)
+	if e.fullScan {
+		s.fullScans++
+	}
+	s.totalLatency += e.latency
+	if e.latency > s.maxLatency {
+		s.maxLatency = e.latency
+	}
+	s.rowsSent += e.rowsSent
+	s.rowsExamined += rowsExamined
+	s.rowsAffected += e.rowsAffected
+	s.lastSeen = end
+
+	h := c.host(ctx.Client().Address)
+	h.statements++
+	h.latency += e.latency
+	if e.fullScan {
+		h.tableScans++
+	}
+
+	if e.failed {
+		return
+	}
+
+	for name, n := range e.rowsRead {
+		key, ok := e.tables[strings.ToLower(name)]
+		if !ok || !instrumented(key.schema) {
+			continue
+		}
+		t := c.table(key)
+		t.rowsFetched += uint64(n)
+		if e.target == nil || *e.target != key {
+			t.fetchLatency += e.latency
+		}
+	}
+
+	if e.target != nil && instrumented(e.target.schema) {
+		t := c.table(*e.target)
+		switch queryCommand(e.query) {
+		case "insert", "replace":
+			t.rowsInserted += e.rowsAffected
+			t.insertLatency += e.latency
+		case "update":
+			t.rowsUpdated += e.rowsAffected
+			t.updateLatency += e.latency
+		case "delete":
+			t.rowsDeleted += e.rowsAffected
+			t.deleteLatency += e.latency
+		}
+	}
+}
+
+// host returns the statistics of the host of the address given, which are
+// created if missing. The collector must be locked.
+func (c *Collector) host(address string) *hostStats {
+	host := address
+	if h, _, err := net.SplitHostPort(address); err == nil {
+		host = h
+	}
+	if host == "" {
+		host = "background"
+	}
+
+	h, ok := c.hosts[host]
+	if !ok {
+		h = &hostStats{host: host, users: make(map[string]struct{})}
+		c.hosts[host] = h
+	}
+	return h
+}
+
+// instrumented returns whether the statistics of the tables of a schema are
+// gathered, which they aren't for the views of sys and information_schema.
+func instrumented(schema string) bool {
+	return schema != DatabaseName && schema != "information_schema"
+}
+
+// table returns the statistics of a table, which are created if missing.
+// The collector must be locked.
+func (c *Collector) table(key tableKey) *tableStats {
+	t, ok := c.tables[key]
+	if !ok {
+		t = &tableStats{schema: key.schema, name: key.name}
+		c.tables[key] = t
+	}
+	return t
+}
+
+// snapshot returns a copy of the statistics gathered so far.
+func (c *Collector) snapshot() ([]statementStats, []tableStats, []hostStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	statements := make([]statementStats, 0, len(c.statements))
+	for _, s := range c.statements {
+		statements = append(statements, *s)
+	}
+	sort.Slice(statements, func(i, j int) bool {
+		return statements[i].totalLatency > statements[j].totalLatency
+	})
+
+	tables := make([]tableStats, 0, len(c.tables))
+	for _, t := range c.tables {
+		tables = append(tables, *t)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].totalLatency() > tables[j].totalLatency()
+	})
+
+	hosts := make([]hostStats, 0, len(c.hosts))
+	for _, h := range c.hosts {
+		hosts = append(hosts, *h)
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].latency > hosts[j].latency
+	})
+
+	return statements, tables, hosts
+}
+
+func (t tableStats) totalLatency() time.Duration {
+	return t.fetchLatency + t.insertLatency + t.updateLatency + t.deleteLatency
+}
+
+// queryTables returns the tables a parsed query uses, by lowercase name.
+func queryTables(ctx *sql.Context, parsed sql.Node) map[string]tableKey {
+	tables := make(map[string]tableKey)
+	if parsed == nil {
+		return tables
+	}
+
+	plan.Inspect(parsed, func(n sql.Node) bool {
+		switch n := n.(type) {
+		case *plan.UnresolvedTable:
+			key := unresolvedTable(ctx, n)
+			tables[key.name] = *key
+		}
+		return true
+	})
+	return tables
+}
+
+// targetTable returns the table a parsed query writes to, if any.
+func targetTable(ctx *sql.Context, parsed sql.Node) *tableKey {
+	switch n := parsed.(type) {
+	case *plan.InsertInto:
+		return unresolvedTable(ctx, n.Left())
+	case *plan.Update, *plan.DeleteFrom:
+		var key *tableKey
+		plan.Inspect(n, func(n sql.Node) bool {
+			if t, ok := n.(*plan.UnresolvedTable); ok && key == nil {
+				key = unresolvedTable(ctx, t)
+			}
+			return key == nil
+		})
+		return key
+	default:
+		return nil
+	}
+}
+
+func unresolvedTable(ctx *sql.Context, n sql.Node) *tableKey {
+	t, ok := n.(*plan.UnresolvedTable)
+	if !ok {
+		return nil
+	}
+
+	schema := t.Database
+	if schema == "" {
+		schema = ctx.GetCurrentDatabase()
+	}
+	return &tableKey{schema: strings.ToLower(schema), name: strings.ToLower(t.Name())}
+}
+
+// fullScan returns whether an analyzed query reads a table without using an
+// index.
+func fullScan(analyzed sql.Node) bool {
+	if analyzed == nil {
+		return false
+	}
+
+	var scan bool
+	plan.Inspect(analyzed, func(n sql.Node) bool {
+		switch n := n.(type) {
+		case *plan.InsertInto:
+			// The table rows are inserted into isn't read.
+			scan = fullScan(n.Right())
+			return false
+		case *plan.IndexedTableAccess:
+			return false
+		case *plan.DecoratedNode:
+			if n.DecorationType == plan.DecorationTypeIndexedAccess {
+				return false
+			}
+		case *plan.ResolvedTable:
+			if n.Name() != "" && !strings.EqualFold(n.Name(), "dual") {
+				scan = true
+			}
+		}
+		return !scan
+	})
+	return scan
+}
+
+// queryCommand returns the first keyword of a query in lowercase.
+func queryCommand(query string) string {
+	fields := strings.Fields(strings.TrimLeft(query, "("))
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
+
+// trackedIter records the statistics of a query once it's closed.
+type trackedIter struct {
+	c         *Collector
+	ctx       *sql.Context
+	processes *sql.ProcessList
+	e         execution
+	iter      sql.RowIter
+	done      bool
+}
+
+func (i *trackedIter) Next() (sql.Row, error) {
+	row, err := i.iter.Next()
+	if err != nil {
+		if err != io.EOF {
+			i.e.failed = true
+		}
+		return nil, err
+	}
+
+	if len(row) == 1 {
+		if ok, isOk := row[0].(sql.OkResult); isOk {
+			i.e.rowsAffected += ok.RowsAffected
+			return row, nil
+		}
+	}
+	i.e.rowsSent++
+	return row, nil
+}
+
+func (i *trackedIter) Close() error {
+	if !i.done {
+		// The rows read are counted in the process of the query, which is
+		// gone once it's closed.
+		i.e.rowsRead = i.processes.RowsRead(i.ctx.Pid())
+	}
+
+	err := i.iter.Close()
+	if !i.done {
+		i.done = true
+		i.e.latency = time.Since(i.e.start)
+		i.e.warnings = i.ctx.WarningCount()
+		if err != nil {
+			i.e.failed = true
+		}
+		i.c.record(i.ctx, i.e)
+	}
+	return err
+}
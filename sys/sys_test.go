@@ -0,0 +1,111 @@
+package sys_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+	"github.com/dolthub/go-mysql-server/sys"
+)
+
+func sysEngine(t *testing.T, c *sys.Collector) *sqle.Engine {
+	db := memory.NewDatabase("mydb")
+	db.AddTable("a", memory.NewTable("a", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "a"},
+	}))
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	catalog.AddDatabase(c.Database())
+
+	a := analyzer.NewBuilder(catalog).Build()
+	return sqle.New(catalog, a, &sqle.Config{Sys: c})
+}
+
+func newContext() *sql.Context {
+	session := sql.NewSessionWithClient("localhost:3306", sql.Client{User: "root", Address: "127.0.0.1:1234"}, 1)
+	ctx := sql.NewContext(context.Background(), sql.WithSession(session))
+	ctx.SetCurrentDatabase("mydb")
+	return ctx
+}
+
+func query(t *testing.T, e *sqle.Engine, ctx *sql.Context, q string) []sql.Row {
+	_, iter, err := e.Query(ctx, q)
+	require.NoError(t, err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(t, err)
+	return rows
+}
+
+func TestDigest(t *testing.T) {
+	require := require.New(t)
+
+	text, digest := sys.Digest("select * from a where i = 1 and s = 'foo' /* comment */")
+	require.Equal("SELECT * FROM `a` WHERE `i` = ? AND `s` = ?", text)
+	require.Len(digest, 64)
+
+	other, otherDigest := sys.Digest("SELECT  *  FROM a WHERE i = 42 AND s = \"bar\"")
+	require.Equal(text, other)
+	require.Equal(digest, otherDigest)
+
+	text, _ = sys.Digest("INSERT INTO a VALUES (1, 'a'), (2, 'b'), (3, 'c')")
+	require.Equal("INSERT INTO `a` VALUES (...) /* , ... */", text)
+
+	text, _ = sys.Digest("SELECT * FROM a WHERE i IN (1, 2, 3) AND j >= 4")
+	require.Equal("SELECT * FROM `a` WHERE `i` IN (...) AND `j` >= ?", text)
+
+	text, _ = sys.Digest("SELECT substr('abc', 1, 2)")
+	require.Equal("SELECT SUBSTR ( ? , ? , ? )", text)
+}
+
+func TestViews(t *testing.T) {
+	require := require.New(t)
+
+	c := sys.NewCollector()
+	e := sysEngine(t, c)
+	ctx := newContext()
+	c.Connect(ctx)
+
+	query(t, e, ctx, "INSERT INTO a VALUES (1), (2), (3)")
+	query(t, e, ctx, "SELECT * FROM a WHERE i = 1")
+	query(t, e, ctx, "SELECT * FROM a WHERE i = 2")
+	query(t, e, ctx, "DELETE FROM a WHERE i = 3")
+	_, _, err := e.Query(ctx, "SELECT * FROM nope")
+	require.Error(err)
+
+	rows := query(t, e, ctx, "SELECT query, db, full_scan, exec_count, err_count, rows_sent, rows_examined, rows_affected FROM sys.statement_analysis ORDER BY query")
+	require.Equal([]sql.Row{
+		{"DELETE FROM `a` WHERE `i` = ?", "mydb", "*", uint64(1), uint64(0), uint64(0), uint64(3), uint64(1)},
+		{"INSERT INTO `a` VALUES (...) /* , ... */", "mydb", "", uint64(1), uint64(0), uint64(0), uint64(0), uint64(3)},
+		{"SELECT * FROM `a` WHERE `i` = ?", "mydb", "*", uint64(2), uint64(0), uint64(2), uint64(6), uint64(0)},
+		{"SELECT * FROM `nope`", "mydb", "", uint64(1), uint64(1), uint64(0), uint64(0), uint64(0)},
+	}, rows)
+
+	rows = query(t, e, ctx, "SELECT COUNT(*) FROM sys.`x$statement_analysis` WHERE total_latency > 0 AND LENGTH(digest) = 64")
+	require.Equal([]sql.Row{{int64(5)}}, rows)
+
+	rows = query(t, e, ctx, "SELECT table_schema, table_name, rows_fetched, rows_inserted, rows_deleted FROM sys.schema_table_statistics")
+	require.Equal([]sql.Row{{"mydb", "a", uint64(9), uint64(3), uint64(1)}}, rows)
+
+	rows = query(t, e, ctx, "SELECT host, statements, table_scans, current_connections, total_connections, unique_users FROM sys.host_summary")
+	require.Equal([]sql.Row{{"127.0.0.1", uint64(8), uint64(6), uint64(1), uint64(1), uint64(1)}}, rows)
+
+	c.Disconnect(ctx)
+	rows = query(t, e, ctx, "SELECT current_connections, total_connections FROM sys.`x$host_summary`")
+	require.Equal([]sql.Row{{uint64(0), uint64(1)}}, rows)
+}
+
+func TestNilCollector(t *testing.T) {
+	var c *sys.Collector
+	ctx := newContext()
+	c.Connect(ctx)
+	c.Disconnect(ctx)
+
+	iter := sql.RowsToRowIter()
+	require.Equal(t, iter, c.TrackQuery(ctx, nil, "SELECT 1", nil, nil, ctx.QueryTime(), iter))
+}
@@ -0,0 +1,365 @@
+package sys
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+const (
+	// DatabaseName is the name of the sys database.
+	DatabaseName = "sys"
+	// StatementAnalysisTableName is the name of the statement_analysis view.
+	StatementAnalysisTableName = "statement_analysis"
+	// SchemaTableStatisticsTableName is the name of the
+	// schema_table_statistics view.
+	SchemaTableStatisticsTableName = "schema_table_statistics"
+	// HostSummaryTableName is the name of the host_summary view.
+	HostSummaryTableName = "host_summary"
+
+	// rawPrefix is the prefix of the names of the views that show raw values
+	// instead of formatted ones.
+	rawPrefix = "x$"
+)
+
+var (
+	varchar64  = sql.MustCreateStringWithDefaults(sqltypes.VarChar, 64)
+	varchar255 = sql.MustCreateStringWithDefaults(sqltypes.VarChar, 255)
+)
+
+// latencyType is the type of the latency columns of views: text formatted
+// times, or picoseconds in the x$ views.
+func latencyType(raw bool) sql.Type {
+	if raw {
+		return sql.Uint64
+	}
+	return sql.Text
+}
+
+// bytesType is the type of the columns of bytes of views: text formatted
+// sizes, or numbers of bytes in the x$ views.
+func bytesType(raw bool) sql.Type {
+	return latencyType(raw)
+}
+
+// latency returns the value of a latency column.
+func latency(raw bool, d time.Duration) interface{} {
+	if raw {
+		return picos(d)
+	}
+	return formatPicoTime(picos(d))
+}
+
+// bytesValue returns the value of a column of bytes.
+func bytesValue(raw bool, bytes uint64) interface{} {
+	if raw {
+		return bytes
+	}
+	return formatBytes(bytes)
+}
+
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func avg(total, count uint64) uint64 {
+	if count == 0 {
+		return 0
+	}
+	return (total + count/2) / count
+}
+
+func statementAnalysisSchema(name string, raw bool) sql.Schema {
+	return sql.Schema{
+		{Name: "query", Type: sql.LongText, Nullable: true, Source: name},
+		{Name: "db", Type: varchar64, Nullable: true, Source: name},
+		{Name: "full_scan", Type: sql.MustCreateStringWithDefaults(sqltypes.VarChar, 1), Source: name},
+		{Name: "exec_count", Type: sql.Uint64, Source: name},
+		{Name: "err_count", Type: sql.Uint64, Source: name},
+		{Name: "warn_count", Type: sql.Uint64, Source: name},
+		{Name: "total_latency", Type: latencyType(raw), Source: name},
+		{Name: "max_latency", Type: latencyType(raw), Source: name},
+		{Name: "avg_latency", Type: latencyType(raw), Source: name},
+		{Name: "lock_latency", Type: latencyType(raw), Source: name},
+		{Name: "rows_sent", Type: sql.Uint64, Source: name},
+		{Name: "rows_sent_avg", Type: sql.Uint64, Source: name},
+		{Name: "rows_examined", Type: sql.Uint64, Source: name},
+		{Name: "rows_examined_avg", Type: sql.Uint64, Source: name},
+		{Name: "rows_affected", Type: sql.Uint64, Source: name},
+		{Name: "rows_affected_avg", Type: sql.Uint64, Source: name},
+		{Name: "tmp_tables", Type: sql.Uint64, Source: name},
+		{Name: "tmp_disk_tables", Type: sql.Uint64, Source: name},
+		{Name: "rows_sorted", Type: sql.Uint64, Source: name},
+		{Name: "sort_merge_passes", Type: sql.Uint64, Source: name},
+		{Name: "digest", Type: varchar64, Nullable: true, Source: name},
+		{Name: "first_seen", Type: sql.Datetime, Source: name},
+		{Name: "last_seen", Type: sql.Datetime, Source: name},
+	}
+}
+
+func statementAnalysisRows(c *Collector, raw bool) []sql.Row {
+	statements, _, _ := c.snapshot()
+	rows := make([]sql.Row, len(statements))
+	for i, s := range statements {
+		query := s.text
+		if !raw {
+			query = formatStatement(query)
+		}
+
+		var fullScan string
+		if s.fullScans > 0 {
+			fullScan = "*"
+		}
+
+		rows[i] = sql.NewRow(
+			nullable(query),
+			nullable(s.db),
+			fullScan,
+			s.execCount,
+			s.errCount,
+			s.warnCount,
+			latency(raw, s.totalLatency),
+			latency(raw, s.maxLatency),
+			latency(raw, time.Duration(avg(uint64(s.totalLatency), s.execCount))),
+			latency(raw, 0),
+			s.rowsSent,
+			avg(s.rowsSent, s.execCount),
+			s.rowsExamined,
+			avg(s.rowsExamined, s.execCount),
+			s.rowsAffected,
+			avg(s.rowsAffected, s.execCount),
+			uint64(0),
+			uint64(0),
+			uint64(0),
+			uint64(0),
+			nullable(s.digest),
+			s.firstSeen,
+			s.lastSeen,
+		)
+	}
+	return rows
+}
+
+func schemaTableStatisticsSchema(name string, raw bool) sql.Schema {
+	return sql.Schema{
+		{Name: "table_schema", Type: varchar64, Source: name},
+		{Name: "table_name", Type: varchar64, Source: name},
+		{Name: "total_latency", Type: latencyType(raw), Source: name},
+		{Name: "rows_fetched", Type: sql.Uint64, Source: name},
+		{Name: "fetch_latency", Type: latencyType(raw), Source: name},
+		{Name: "rows_inserted", Type: sql.Uint64, Source: name},
+		{Name: "insert_latency", Type: latencyType(raw), Source: name},
+		{Name: "rows_updated", Type: sql.Uint64, Source: name},
+		{Name: "update_latency", Type: latencyType(raw), Source: name},
+		{Name: "rows_deleted", Type: sql.Uint64, Source: name},
+		{Name: "delete_latency", Type: latencyType(raw), Source: name},
+		{Name: "io_read_requests", Type: sql.Uint64, Source: name},
+		{Name: "io_read", Type: bytesType(raw), Source: name},
+		{Name: "io_read_latency", Type: latencyType(raw), Source: name},
+		{Name: "io_write_requests", Type: sql.Uint64, Source: name},
+		{Name: "io_write", Type: bytesType(raw), Source: name},
+		{Name: "io_write_latency", Type: latencyType(raw), Source: name},
+		{Name: "io_misc_requests", Type: sql.Uint64, Source: name},
+		{Name: "io_misc_latency", Type: latencyType(raw), Source: name},
+	}
+}
+
+func schemaTableStatisticsRows(c *Collector, raw bool) []sql.Row {
+	_, tables, _ := c.snapshot()
+	rows := make([]sql.Row, len(tables))
+	for i, t := range tables {
+		rows[i] = sql.NewRow(
+			t.schema,
+			t.name,
+			latency(raw, t.totalLatency()),
+			t.rowsFetched,
+			latency(raw, t.fetchLatency),
+			t.rowsInserted,
+			latency(raw, t.insertLatency),
+			t.rowsUpdated,
+			latency(raw, t.updateLatency),
+			t.rowsDeleted,
+			latency(raw, t.deleteLatency),
+			uint64(0),
+			bytesValue(raw, 0),
+			latency(raw, 0),
+			uint64(0),
+			bytesValue(raw, 0),
+			latency(raw, 0),
+			uint64(0),
+			latency(raw, 0),
+		)
+	}
+	return rows
+}
+
+func hostSummarySchema(name string, raw bool) sql.Schema {
+	return sql.Schema{
+		{Name: "host", Type: varchar255, Source: name},
+		{Name: "statements", Type: sql.Uint64, Source: name},
+		{Name: "statement_latency", Type: latencyType(raw), Source: name},
+		{Name: "statement_avg_latency", Type: latencyType(raw), Source: name},
+		{Name: "table_scans", Type: sql.Uint64, Source: name},
+		{Name: "file_ios", Type: sql.Uint64, Source: name},
+		{Name: "file_io_latency", Type: latencyType(raw), Source: name},
+		{Name: "current_connections", Type: sql.Uint64, Source: name},
+		{Name: "total_connections", Type: sql.Uint64, Source: name},
+		{Name: "unique_users", Type: sql.Uint64, Source: name},
+		{Name: "current_memory", Type: bytesType(raw), Source: name},
+		{Name: "total_memory_allocated", Type: bytesType(raw), Source: name},
+	}
+}
+
+func hostSummaryRows(c *Collector, raw bool) []sql.Row {
+	_, _, hosts := c.snapshot()
+	rows := make([]sql.Row, len(hosts))
+	for i, h := range hosts {
+		rows[i] = sql.NewRow(
+			h.host,
+			h.statements,
+			latency(raw, h.latency),
+			latency(raw, time.Duration(avg(uint64(h.latency), h.statements))),
+			h.tableScans,
+			uint64(0),
+			latency(raw, 0),
+			h.current,
+			h.total,
+			uint64(len(h.users)),
+			bytesValue(raw, 0),
+			bytesValue(raw, 0),
+		)
+	}
+	return rows
+}
+
+// Database is the sys database, holding the views of the statistics a
+// Collector gathers.
+type Database struct {
+	tables map[string]*view
+}
+
+var _ sql.Database = (*Database)(nil)
+
+// Database returns the sys database showing the statistics the collector
+// gathers, to be added to the catalog.
+func (c *Collector) Database() *Database {
+	views := []struct {
+		name   string
+		schema func(name string, raw bool) sql.Schema
+		rows   func(c *Collector, raw bool) []sql.Row
+	}{
+		{StatementAnalysisTableName, statementAnalysisSchema, statementAnalysisRows},
+		{SchemaTableStatisticsTableName, schemaTableStatisticsSchema, schemaTableStatisticsRows},
+		{HostSummaryTableName, hostSummarySchema, hostSummaryRows},
+	}
+
+	db := &Database{tables: make(map[string]*view)}
+	for _, v := range views {
+		for _, raw := range []bool{false, true} {
+			name, rows := v.name, v.rows
+			if raw {
+				name = rawPrefix + name
+			}
+			raw := raw
+			db.tables[name] = &view{
+				name:   name,
+				schema: v.schema(name, raw),
+				rows: func() []sql.Row {
+					return rows(c, raw)
+				},
+			}
+		}
+	}
+	return db
+}
+
+// Name implements the sql.Database interface.
+func (d *Database) Name() string {
+	return DatabaseName
+}
+
+// GetTableInsensitive implements the sql.Database interface.
+func (d *Database) GetTableInsensitive(ctx *sql.Context, tblName string) (sql.Table, bool, error) {
+	t, ok := d.tables[strings.ToLower(tblName)]
+	if !ok {
+		return nil, false, nil
+	}
+	return t, true, nil
+}
+
+// GetTableNames implements the sql.Database interface.
+func (d *Database) GetTableNames(ctx *sql.Context) ([]string, error) {
+	names := make([]string, 0, len(d.tables))
+	for name := range d.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// view is a read only table of the sys database, whose rows are computed
+// from the statistics of a Collector each time it's read.
+type view struct {
+	name   string
+	schema sql.Schema
+	rows   func() []sql.Row
+}
+
+var _ sql.Table = (*view)(nil)
+
+// Name implements the sql.Table interface.
+func (v *view) Name() string {
+	return v.name
+}
+
+// String implements the sql.Table interface.
+func (v *view) String() string {
+	return v.name
+}
+
+// Schema implements the sql.Table interface.
+func (v *view) Schema() sql.Schema {
+	return v.schema
+}
+
+// Partitions implements the sql.Table interface.
+func (v *view) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &partitionIter{}, nil
+}
+
+// PartitionRows implements the sql.Table interface.
+func (v *view) PartitionRows(*sql.Context, sql.Partition) (sql.RowIter, error) {
+	return sql.RowsToRowIter(v.rows()...), nil
+}
+
+// partition is the single partition of a view.
+type partition struct{}
+
+// Key implements the sql.Partition interface.
+func (partition) Key() []byte { return []byte("sys") }
+
+type partitionIter struct {
+	done bool
+}
+
+// Next implements the sql.PartitionIter interface.
+func (i *partitionIter) Next() (sql.Partition, error) {
+	if i.done {
+		return nil, io.EOF
+	}
+	i.done = true
+	return partition{}, nil
+}
+
+// Close implements the sql.PartitionIter interface.
+func (i *partitionIter) Close() error {
+	return nil
+}
@@ -0,0 +1,341 @@
+// Package tracing lets go-mysql-server trace queries with an OpenTelemetry
+// tracer. The engine starts its spans with the opentracing API, so NewTracer
+// returns an opentracing tracer that starts them with an OpenTelemetry
+// tracer instead, and propagates their context in the W3C trace context
+// format that OpenTelemetry instrumentations use.
+//
+// To keep go-mysql-server free of the OpenTelemetry SDK, the OpenTelemetry
+// tracer is taken as a Tracer, the few methods of the OpenTelemetry trace
+// API that the engine needs, which an integrator implements on top of a
+// go.opentelemetry.io/otel/trace.Tracer.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+const (
+	// TraceparentHeader is the key of the W3C trace context of a span.
+	TraceparentHeader = "traceparent"
+	// TracestateHeader is the key of the vendor specific trace state of a
+	// span.
+	TracestateHeader = "tracestate"
+)
+
+// SpanContext is the context of an OpenTelemetry span that is propagated to
+// its children, as defined by the W3C trace context.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+	TraceState string
+	// Remote is whether the span was started by another process, such as
+	// the client that sent the query.
+	Remote bool
+}
+
+// IsValid returns whether the span context has a trace and a span ID.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// ForeachBaggageItem implements the opentracing.SpanContext interface. The
+// OpenTelemetry span contexts have no baggage.
+func (sc SpanContext) ForeachBaggageItem(func(k, v string) bool) {}
+
+// Span is the subset of the go.opentelemetry.io/otel/trace.Span API that the
+// engine uses.
+type Span interface {
+	// SpanContext returns the context of the span.
+	SpanContext() SpanContext
+	// SetName sets the name of the span.
+	SetName(name string)
+	// SetAttribute sets an attribute of the span.
+	SetAttribute(key string, value interface{})
+	// AddEvent adds an event with the given attributes to the span.
+	AddEvent(name string, attributes map[string]interface{})
+	// RecordError records an error of the span, and sets its status to
+	// error.
+	RecordError(err error)
+	// End ends the span at the given time.
+	End(timestamp time.Time)
+}
+
+// Tracer is the subset of the go.opentelemetry.io/otel/trace.Tracer API that
+// the engine uses.
+type Tracer interface {
+	// Start starts a span at the given time. If parent is valid, the span
+	// is its child, otherwise it is the root span of a new trace.
+	Start(ctx context.Context, name string, parent SpanContext, timestamp time.Time) Span
+}
+
+// NewTracer returns an opentracing tracer that starts its spans with the
+// given OpenTelemetry tracer. It's meant to be used as the tracer of
+// server.Config and sql.WithTracer.
+//
+// The context of its spans is injected in and extracted from TextMap and
+// HTTPHeaders carriers with the traceparent and tracestate keys, so the
+// root span of a query that carries the context of the span of the client
+// in its comments, as sqlcommenter does, is the child of that span.
+func NewTracer(t Tracer) opentracing.Tracer {
+	return &tracer{t}
+}
+
+type tracer struct {
+	tracer Tracer
+}
+
+var _ opentracing.Tracer = (*tracer)(nil)
+
+func (t *tracer) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	var options opentracing.StartSpanOptions
+	for _, opt := range opts {
+		opt.Apply(&options)
+	}
+
+	var parent SpanContext
+	for _, ref := range options.References {
+		if sc, ok := ref.ReferencedContext.(SpanContext); ok && sc.IsValid() {
+			parent = sc
+			break
+		}
+	}
+
+	if options.StartTime.IsZero() {
+		options.StartTime = time.Now()
+	}
+
+	s := &span{
+		tracer: t,
+		span:   t.tracer.Start(context.Background(), operationName, parent, options.StartTime),
+	}
+	for k, v := range options.Tags {
+		s.SetTag(k, v)
+	}
+	return s
+}
+
+func (t *tracer) Inject(sm opentracing.SpanContext, format interface{}, carrier interface{}) error {
+	sc, ok := sm.(SpanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+
+	w, ok := textMapCarrier(format, carrier).(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+
+	if !sc.IsValid() {
+		return nil
+	}
+
+	w.Set(TraceparentHeader, FormatTraceparent(sc))
+	if sc.TraceState != "" {
+		w.Set(TracestateHeader, sc.TraceState)
+	}
+	return nil
+}
+
+func (t *tracer) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
+	r, ok := textMapCarrier(format, carrier).(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+
+	var traceparent, tracestate string
+	err := r.ForeachKey(func(key, val string) error {
+		switch strings.ToLower(key) {
+		case TraceparentHeader:
+			traceparent = val
+		case TracestateHeader:
+			tracestate = val
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if traceparent == "" {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+
+	sc, err := ParseTraceparent(traceparent)
+	if err != nil {
+		return nil, opentracing.ErrSpanContextCorrupted
+	}
+	sc.TraceState = tracestate
+	sc.Remote = true
+	return sc, nil
+}
+
+// textMapCarrier returns the carrier if the format is TextMap or
+// HTTPHeaders, which both carry string keys and values.
+func textMapCarrier(format interface{}, carrier interface{}) interface{} {
+	switch format {
+	case opentracing.TextMap:
+		return carrier
+	case opentracing.HTTPHeaders:
+		if h, ok := carrier.(http.Header); ok {
+			return opentracing.HTTPHeadersCarrier(h)
+		}
+		return carrier
+	default:
+		return nil
+	}
+}
+
+// FormatTraceparent returns the traceparent value of a span context.
+func FormatTraceparent(sc SpanContext) string {
+	return fmt.Sprintf("00-%s-%s-%02x", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), sc.TraceFlags)
+}
+
+// ParseTraceparent parses a traceparent value of the W3C trace context. The
+// values of future versions are parsed as version 00, as the specification
+// requires.
+func ParseTraceparent(traceparent string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 ||
+		len(parts[0]) != 2 || parts[0] == "ff" ||
+		(parts[0] == "00" && len(parts) != 4) {
+		return sc, fmt.Errorf("invalid traceparent: %q", traceparent)
+	}
+
+	var version, flags [1]byte
+	if !decodeHex(version[:], parts[0]) ||
+		!decodeHex(sc.TraceID[:], parts[1]) ||
+		!decodeHex(sc.SpanID[:], parts[2]) ||
+		!decodeHex(flags[:], parts[3]) ||
+		!sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent: %q", traceparent)
+	}
+	sc.TraceFlags = flags[0]
+	return sc, nil
+}
+
+// decodeHex decodes a lowercase hex string of exactly len(dst) bytes.
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+type span struct {
+	tracer  *tracer
+	span    Span
+	baggage map[string]string
+}
+
+var _ opentracing.Span = (*span)(nil)
+
+func (s *span) Finish() {
+	s.span.End(time.Now())
+}
+
+func (s *span) FinishWithOptions(opts opentracing.FinishOptions) {
+	for _, r := range opts.LogRecords {
+		s.logFields(r.Fields...)
+	}
+	if opts.FinishTime.IsZero() {
+		opts.FinishTime = time.Now()
+	}
+	s.span.End(opts.FinishTime)
+}
+
+func (s *span) Context() opentracing.SpanContext {
+	return s.span.SpanContext()
+}
+
+func (s *span) SetOperationName(operationName string) opentracing.Span {
+	s.span.SetName(operationName)
+	return s
+}
+
+func (s *span) SetTag(key string, value interface{}) opentracing.Span {
+	if err, ok := value.(error); ok && key == "error" {
+		s.span.RecordError(err)
+		return s
+	}
+	s.span.SetAttribute(key, value)
+	return s
+}
+
+func (s *span) LogFields(fields ...log.Field) {
+	s.logFields(fields...)
+}
+
+func (s *span) logFields(fields ...log.Field) {
+	if len(fields) == 0 {
+		return
+	}
+
+	name := "log"
+	attributes := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		switch f.Key() {
+		case "event":
+			name = fmt.Sprint(f.Value())
+		case "error", "error.object":
+			if err, ok := f.Value().(error); ok {
+				s.span.RecordError(err)
+				continue
+			}
+			attributes[f.Key()] = f.Value()
+		default:
+			attributes[f.Key()] = f.Value()
+		}
+	}
+
+	if len(attributes) > 0 || name != "log" {
+		s.span.AddEvent(name, attributes)
+	}
+}
+
+func (s *span) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := log.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		s.logFields(log.Error(err))
+		return
+	}
+	s.logFields(fields...)
+}
+
+func (s *span) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	if s.baggage == nil {
+		s.baggage = make(map[string]string)
+	}
+	s.baggage[restrictedKey] = value
+	return s
+}
+
+func (s *span) BaggageItem(restrictedKey string) string {
+	return s.baggage[restrictedKey]
+}
+
+func (s *span) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+func (s *span) LogEvent(event string) {
+	s.logFields(log.String("event", event))
+}
+
+func (s *span) LogEventWithPayload(event string, payload interface{}) {
+	s.logFields(log.String("event", event), log.Object("payload", payload))
+}
+
+func (s *span) Log(data opentracing.LogData) {
+	s.logFields(data.ToLogRecord().Fields...)
+}
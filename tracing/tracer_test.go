@@ -0,0 +1,134 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/require"
+)
+
+// testTracer is a Tracer that records the spans it starts.
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string, parent SpanContext, timestamp time.Time) Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sc := SpanContext{TraceID: parent.TraceID, TraceFlags: parent.TraceFlags, TraceState: parent.TraceState}
+	if !parent.IsValid() {
+		sc.TraceID = [16]byte{byte(len(t.spans) + 1)}
+	}
+	sc.SpanID = [8]byte{byte(len(t.spans) + 1)}
+
+	s := &testSpan{name: name, parent: parent, context: sc, attributes: make(map[string]interface{})}
+	t.spans = append(t.spans, s)
+	return s
+}
+
+type testSpan struct {
+	name       string
+	parent     SpanContext
+	context    SpanContext
+	attributes map[string]interface{}
+	events     []string
+	errors     []error
+	ended      bool
+}
+
+func (s *testSpan) SpanContext() SpanContext { return s.context }
+func (s *testSpan) SetName(name string)      { s.name = name }
+func (s *testSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+func (s *testSpan) AddEvent(name string, attributes map[string]interface{}) {
+	s.events = append(s.events, name)
+}
+func (s *testSpan) RecordError(err error)   { s.errors = append(s.errors, err) }
+func (s *testSpan) End(timestamp time.Time) { s.ended = true }
+
+func TestTraceparent(t *testing.T) {
+	require := require.New(t)
+
+	sc, err := ParseTraceparent("00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01")
+	require.NoError(err)
+	require.Equal(byte(0x5b), sc.TraceID[0])
+	require.Equal(byte(0xc5), sc.SpanID[0])
+	require.Equal(byte(1), sc.TraceFlags)
+	require.Equal("00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01", FormatTraceparent(sc))
+
+	// Future versions may add fields.
+	_, err = ParseTraceparent("01-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01-ab")
+	require.NoError(err)
+
+	for _, invalid := range []string{
+		"",
+		"00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2",
+		"00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01-ab",
+		"ff-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01",
+		"00-5BD66EF5095369C7B0D1F8F4BD33716A-c532cb4098ac3dd2-01",
+		"00-00000000000000000000000000000000-c532cb4098ac3dd2-01",
+		"00-5bd66ef5095369c7b0d1f8f4bd33716a-0000000000000000-01",
+		"00-5bd66ef5095369c7b0d1f8f4bd3371-c532cb4098ac3dd2-01",
+		"00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-1",
+	} {
+		_, err := ParseTraceparent(invalid)
+		require.Error(err, invalid)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	require := require.New(t)
+
+	tt := new(testTracer)
+	tracer := NewTracer(tt)
+
+	parent, err := tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier{
+		"traceparent": "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01",
+		"tracestate":  "congo=t61rcWkgMzE",
+	})
+	require.NoError(err)
+
+	root := tracer.StartSpan("query", opentracing.ChildOf(parent), opentracing.Tag{Key: "db.statement", Value: "SELECT 1"})
+	child := tracer.StartSpan("rule", opentracing.ChildOf(root.Context()))
+	child.SetTag("error", errors.New("failed"))
+	child.LogKV("event", "retry", "attempt", 2)
+	child.SetOperationName("rule(resolve_tables)")
+	child.Finish()
+	root.Finish()
+
+	require.Len(tt.spans, 2)
+	r, c := tt.spans[0], tt.spans[1]
+
+	require.Equal("query", r.name)
+	require.True(r.parent.Remote)
+	require.Equal(parent, r.parent)
+	require.Equal("SELECT 1", r.attributes["db.statement"])
+	require.Equal(r.parent.TraceID, r.context.TraceID)
+	require.True(r.ended)
+
+	require.Equal("rule(resolve_tables)", c.name)
+	require.Equal(r.context, c.parent)
+	require.False(c.parent.Remote)
+	require.Len(c.errors, 1)
+	require.Equal([]string{"retry"}, c.events)
+	require.True(c.ended)
+
+	carrier := make(opentracing.TextMapCarrier)
+	require.NoError(tracer.Inject(child.Context(), opentracing.TextMap, carrier))
+	require.Equal(FormatTraceparent(c.context), carrier["traceparent"])
+	require.Equal("congo=t61rcWkgMzE", carrier["tracestate"])
+
+	_, err = tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier{})
+	require.Equal(opentracing.ErrSpanContextNotFound, err)
+	_, err = tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier{"traceparent": "invalid"})
+	require.Equal(opentracing.ErrSpanContextCorrupted, err)
+	_, err = tracer.Extract(opentracing.Binary, nil)
+	require.Equal(opentracing.ErrInvalidCarrier, err)
+}